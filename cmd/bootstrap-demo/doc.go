@@ -14,6 +14,7 @@
 //   - Custom configuration through command-line flags
 //   - Automatic content generation including world, factions, characters, quests
 //   - Quick-start scenario generation for immediate gameplay
+//   - Live progress bar on stderr while content is generated
 //
 // # Usage
 //
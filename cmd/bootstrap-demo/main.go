@@ -35,6 +35,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -53,6 +54,13 @@ var timeNow = time.Now
 // It defaults to time.Since but can be overridden in tests for reproducibility.
 var timeSince = time.Since
 
+// progressOutput is where the live generation progress bar is drawn.
+// It defaults to os.Stderr so the bar does not mix with the results on stdout.
+var progressOutput io.Writer = os.Stderr
+
+// progressBarWidth is the number of cells in the progress bar.
+const progressBarWidth = 30
+
 type DemoConfig struct {
 	TemplateName     string
 	GameLength       string
@@ -295,6 +303,7 @@ func runBootstrapDemo(config *DemoConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
+	bootstrap.SetProgressFunc(newProgressBar(progressOutput))
 	generatedWorld, err := bootstrap.GenerateCompleteGame(ctx)
 	if err != nil {
 		return fmt.Errorf("game generation failed: %w", err)
@@ -313,6 +322,25 @@ func runBootstrapDemo(config *DemoConfig) error {
 	return nil
 }
 
+// newProgressBar returns a progress callback that redraws a single-line
+// progress bar on w, ending the line once generation completes.
+func newProgressBar(w io.Writer) pcg.ProgressFunc {
+	return func(update pcg.ProgressUpdate) {
+		fmt.Fprintf(w, "\r%s", renderProgressBar(update))
+		if update.Stage == pcg.ProgressStageComplete {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// renderProgressBar formats a progress update as "[#####.....]  50% stage".
+// The stage name is padded so shorter names overwrite longer previous ones.
+func renderProgressBar(update pcg.ProgressUpdate) string {
+	filled := int(update.Percent / 100 * progressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled)
+	return fmt.Sprintf("[%s] %3.0f%% %-20s", bar, update.Percent, update.Stage)
+}
+
 // convertToBootstrapConfig transforms a DemoConfig with string-based settings
 // into a pcg.BootstrapConfig with proper enum types. It validates and converts
 // GameLength (short/medium/long), ComplexityLevel (simple/standard/advanced),
@@ -920,3 +920,33 @@ func TestTimeMeasurementReproducibility(t *testing.T) {
 	assert.Equal(t, expectedDuration, duration)
 	assert.Equal(t, fixedStart, startTime)
 }
+
+// TestRenderProgressBar tests progress bar formatting.
+func TestRenderProgressBar(t *testing.T) {
+	bar := renderProgressBar(pcg.ProgressUpdate{Stage: "quests", Percent: 50})
+	assert.Equal(t, "[###############...............]  50% quests              ", bar)
+
+	bar = renderProgressBar(pcg.ProgressUpdate{Stage: pcg.ProgressStageComplete, Percent: 100})
+	assert.Contains(t, bar, "[##############################] 100% complete")
+}
+
+// TestNewProgressBar tests that bootstrap progress is drawn on a single line.
+func TestNewProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	tempDir := t.TempDir()
+
+	bootstrapConfig := pcg.DefaultBootstrapConfig()
+	bootstrapConfig.DataDirectory = tempDir
+	bootstrapConfig.WorldSeed = 42
+
+	bootstrap := pcg.NewBootstrap(bootstrapConfig, game.NewWorld(), logrus.New())
+	bootstrap.SetProgressFunc(newProgressBar(&buf))
+
+	_, err := bootstrap.GenerateCompleteGame(context.Background())
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "\r[")
+	assert.Contains(t, output, "100% complete")
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")), "the bar is redrawn in place and ends with one newline")
+}
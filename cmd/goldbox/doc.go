@@ -0,0 +1,42 @@
+// Package main provides the unified goldbox command line interface.
+//
+// The goldbox binary consolidates the standalone demo programs into a single
+// tool with subcommands. All functionality lives in pkg/cli so that it can
+// also be embedded programmatically.
+//
+// # Usage
+//
+//	go run ./cmd/goldbox [global flags] <command> [command flags]
+//
+// # Commands
+//
+//	bootstrap   Generate a complete zero-configuration game
+//	dungeon     Generate a multi-level dungeon complex
+//	generate    Generate terrain, a level, a quest or a dungeon
+//	heatmap     Analyse the difficulty pacing of a generated dungeon or world
+//	metrics     Exercise the PCG manager and report quality metrics
+//	validate    Validate a JSON content file against PCG rules
+//	serve       Run the JSON-RPC game server
+//	worldgen    Generate an overworld campaign setting
+//
+// # Global Flags
+//
+//	-format string   Output format: text or json (default "text")
+//	-verbose         Enable debug logging
+//
+// # Exit Codes
+//
+//	0   Success
+//	1   Command failed (including content that fails validation)
+//	2   Invalid command line usage
+//
+// # Examples
+//
+//	go run ./cmd/goldbox dungeon -seed 42 -levels 5
+//	go run ./cmd/goldbox -format json worldgen -climate arctic
+//	go run ./cmd/goldbox -format json generate terrain -biome swamp -width 60
+//	go run ./cmd/goldbox generate quest -type escort -player-level 8
+//	go run ./cmd/goldbox heatmap -source dungeon -levels 5
+//	go run ./cmd/goldbox validate -type quests quest.json
+//	go run ./cmd/goldbox serve -port 8080
+package main
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"goldbox-rpg/pkg/cli"
+
+	_ "modernc.org/sqlite" // driver of the sqlite persistence backend
+)
+
+func main() {
+	cli.Main()
+}
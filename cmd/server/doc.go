@@ -36,6 +36,14 @@
 //   - ENABLE_DEV_MODE: Development mode flag (default: true)
 //   - ENABLE_PERSISTENCE: Auto-save game state (default: true)
 //   - DATA_DIR: Persistence directory (default: ./data)
+//   - PERSISTENCE_BACKEND: Storage backend: file, sqlite or memory (default: file)
+//   - GOLDBOX_PERSISTENCE_MODE: snapshot, or event-sourced to also log
+//     changes between auto-saves (default: snapshot); PERSISTENCE_MODE is
+//     read when it is unset
+//   - BACKUP_COUNT: Rotated backups kept per saved document, 0 disables (default: 5)
+//   - BACKUP_MAX_AGE: Remove backups older than this (default: 168h)
+//   - BACKUP_INTERVAL: Minimum time between backups of a document (default: 10m)
+//   - BACKUP_VERIFY_INTERVAL: Backup integrity check interval (default: 1h)
 //
 // # Usage
 //
@@ -10,9 +10,11 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite" // driver of the sqlite persistence backend
 
 	"goldbox-rpg/pkg/config"
 	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/logging"
 	"goldbox-rpg/pkg/pcg"
 	"goldbox-rpg/pkg/retry"
 	"goldbox-rpg/pkg/server"
@@ -79,6 +81,7 @@ func loadAndConfigureSystem() *config.Config {
 	}
 
 	configureLogging(cfg.LogLevel)
+	configureSubsystemLogging(cfg.LogLevels)
 	logStartupInfo(cfg)
 	return cfg
 }
@@ -93,6 +96,19 @@ func configureLogging(logLevel string) {
 	logrus.SetLevel(level)
 }
 
+// configureSubsystemLogging applies per-subsystem log level overrides on top
+// of the global level set by configureLogging.
+func configureSubsystemLogging(subsystemLevels string) {
+	if subsystemLevels == "" {
+		return
+	}
+	if err := logging.Configure(logrus.GetLevel().String(), subsystemLevels); err != nil {
+		logrus.WithError(err).Warn("Invalid subsystem log levels, ignoring them")
+		return
+	}
+	logrus.WithField("subsystemLevels", subsystemLevels).Info("Subsystem log levels configured")
+}
+
 // logStartupInfo logs server startup information.
 func logStartupInfo(cfg *config.Config) {
 	logrus.WithFields(logrus.Fields{
@@ -123,7 +139,12 @@ func initializeServer(cfg *config.Config) (*server.RPCServer, net.Listener) {
 func executeServerLifecycle(cfg *config.Config, srv *server.RPCServer, listener net.Listener) {
 	sigChan, errChan := setupShutdownHandling()
 	startServerAsync(srv, listener, errChan)
+
+	// Reload the configuration on SIGHUP until shutdown
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	go srv.WatchConfig(watchCtx)
 	waitForShutdownSignal(sigChan, errChan)
+	stopWatching()
 	performGracefulShutdown(cfg, listener, srv)
 }
 
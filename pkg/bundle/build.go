@@ -0,0 +1,191 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg/items"
+	"goldbox-rpg/pkg/persistence"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Builder assembles a bundle. Add content, then Write or WriteFile it.
+//
+// Builder is not safe for concurrent use.
+type Builder struct {
+	manifest Manifest
+	files    map[string][]byte
+}
+
+// NewBuilder creates a builder for a bundle. The name is also the file
+// name the bundle is installed under, so it may only hold letters, digits,
+// dots, dashes and underscores.
+func NewBuilder(name, version string) *Builder {
+	return &Builder{
+		manifest: Manifest{
+			Format:    FormatVersion,
+			Name:      name,
+			Version:   version,
+			CreatedAt: time.Now().UTC().Truncate(time.Second),
+		},
+		files: make(map[string][]byte),
+	}
+}
+
+// SetDescription sets the description and author of the bundle
+func (b *Builder) SetDescription(description, author string) {
+	b.manifest.Description = description
+	b.manifest.Author = author
+}
+
+// AddMap adds a level, stored under its ID
+func (b *Builder) AddMap(level *game.Level) error {
+	if level == nil {
+		return fmt.Errorf("cannot add a nil map")
+	}
+	return b.Add(ContentMaps, level.ID, level)
+}
+
+// AddQuest adds a quest, stored under its ID
+func (b *Builder) AddQuest(quest *game.Quest) error {
+	if quest == nil {
+		return fmt.Errorf("cannot add a nil quest")
+	}
+	return b.Add(ContentQuests, quest.ID, quest)
+}
+
+// AddNPC adds an NPC, stored under its ID. Its dialog is stored with it;
+// AddDialogue stores dialogue apart from the NPC it belongs to.
+func (b *Builder) AddNPC(npc *game.NPC) error {
+	if npc == nil {
+		return fmt.Errorf("cannot add a nil NPC")
+	}
+	return b.Add(ContentNPCs, npc.GetID(), npc)
+}
+
+// AddLootTable adds a loot table under its name
+func (b *Builder) AddLootTable(name string, table *items.LootTable) error {
+	if table == nil {
+		return fmt.Errorf("cannot add a nil loot table")
+	}
+	return b.Add(ContentLootTables, name, table)
+}
+
+// AddDialogue adds the dialogue of the NPC with ID npcID, which replaces
+// the NPC's dialog when the bundle is mounted
+func (b *Builder) AddDialogue(npcID string, entries []game.DialogEntry) error {
+	return b.Add(ContentDialogue, npcID, entries)
+}
+
+// Add stores content of a content type under id, encoded as YAML
+func (b *Builder) Add(contentType ContentType, id string, content interface{}) error {
+	if !contentTypes[contentType] {
+		return fmt.Errorf("unknown content type %q", contentType)
+	}
+	if !validName.MatchString(id) {
+		return fmt.Errorf("invalid %s ID %q", contentType, id)
+	}
+	filePath := contentPath(contentType, id)
+	if _, exists := b.files[filePath]; exists {
+		return fmt.Errorf("%s %s is already in the bundle", contentType, id)
+	}
+	if len(b.files) >= MaxFiles {
+		return fmt.Errorf("bundle cannot hold more than %d files", MaxFiles)
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s %s: %w", contentType, id, err)
+	}
+	if len(data) > MaxFileSize {
+		return fmt.Errorf("%s %s is larger than %d bytes", contentType, id, MaxFileSize)
+	}
+
+	sum := sha256.Sum256(data)
+	b.files[filePath] = data
+	b.manifest.Files = append(b.manifest.Files, FileEntry{
+		Path:   filePath,
+		Type:   contentType,
+		ID:     id,
+		Size:   int64(len(data)),
+		SHA256: hex.EncodeToString(sum[:]),
+	})
+	return nil
+}
+
+// Manifest returns the manifest of the bundle as built so far
+func (b *Builder) Manifest() Manifest {
+	manifest := b.manifest
+	manifest.Files = sortedEntries(b.manifest.Files)
+	return manifest
+}
+
+// Write writes the bundle to w, signed with key unless key is nil
+func (b *Builder) Write(w io.Writer, key ed25519.PrivateKey) error {
+	manifest := b.Manifest()
+	if err := manifest.validate(); err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+	if key != nil {
+		public, ok := key.Public().(ed25519.PublicKey)
+		if !ok || len(key) != ed25519.PrivateKeySize {
+			return fmt.Errorf("bundle signing key must be an ed25519 private key")
+		}
+		manifest.Signer = KeyID(public)
+	}
+	manifestData, err := yaml.Marshal(&manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	archive := zip.NewWriter(w)
+	write := func(name string, data []byte) error {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: manifest.CreatedAt}
+		fw, err := archive.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return nil
+	}
+
+	if err := write(ManifestFile, manifestData); err != nil {
+		return err
+	}
+	if key != nil {
+		signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifestData))
+		if err := write(SignatureFile, []byte(signature+"\n")); err != nil {
+			return err
+		}
+	}
+	for _, entry := range manifest.Files {
+		if err := write(entry.Path, b.files[entry.Path]); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return nil
+}
+
+// WriteFile writes the bundle to path atomically, signed with key unless
+// key is nil
+func (b *Builder) WriteFile(path string, key ed25519.PrivateKey) error {
+	var buf bytes.Buffer
+	if err := b.Write(&buf, key); err != nil {
+		return err
+	}
+	return persistence.AtomicWriteFile(path, buf.Bytes(), 0o644)
+}
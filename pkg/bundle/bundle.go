@@ -0,0 +1,381 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg/items"
+	"goldbox-rpg/pkg/resilience"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bundle file layout
+const (
+	// Extension is the file extension of bundles
+	Extension = ".gbox"
+
+	// ManifestFile is the path of the manifest inside a bundle
+	ManifestFile = "manifest.yaml"
+
+	// SignatureFile is the path of the manifest's signature inside a
+	// signed bundle
+	SignatureFile = "manifest.sig"
+
+	// FormatVersion is the manifest format this package writes and reads
+	FormatVersion = 1
+)
+
+// Limits on what Read accepts, so a hostile bundle cannot exhaust memory
+const (
+	// MaxFiles is the most content files a bundle may hold
+	MaxFiles = 4096
+
+	// MaxFileSize is the largest a single file of a bundle may be
+	MaxFileSize = 32 << 20
+)
+
+// ContentType is the kind of content a bundle file holds
+type ContentType string
+
+const (
+	ContentMaps       ContentType = "maps"        // game.Level
+	ContentQuests     ContentType = "quests"      // game.Quest
+	ContentNPCs       ContentType = "npcs"        // game.NPC
+	ContentLootTables ContentType = "loot_tables" // items.LootTable
+	ContentDialogue   ContentType = "dialogue"    // []game.DialogEntry, by NPC ID
+)
+
+// contentTypes holds every content type a bundle may hold
+var contentTypes = map[ContentType]bool{
+	ContentMaps:       true,
+	ContentQuests:     true,
+	ContentNPCs:       true,
+	ContentLootTables: true,
+	ContentDialogue:   true,
+}
+
+// validName matches bundle names and content IDs
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+var (
+	// ErrInvalidBundle is returned for files that are not well-formed
+	// bundles
+	ErrInvalidBundle = errors.New("invalid content bundle")
+
+	// ErrTampered is returned when a bundle's files do not match its
+	// manifest. It wraps resilience.ErrIntegrityViolation.
+	ErrTampered = fmt.Errorf("%w: content bundle does not match its manifest", resilience.ErrIntegrityViolation)
+
+	// ErrUnsigned is returned by Verify for unsigned bundles unless they
+	// are allowed
+	ErrUnsigned = errors.New("content bundle is not signed")
+
+	// ErrUntrusted is returned by Verify when no trusted key made a
+	// bundle's signature
+	ErrUntrusted = errors.New("content bundle signature is not from a trusted key")
+)
+
+// Manifest describes a bundle and lists its files
+type Manifest struct {
+	Format      int         `yaml:"format"`
+	Name        string      `yaml:"name"`
+	Version     string      `yaml:"version"`
+	Description string      `yaml:"description,omitempty"`
+	Author      string      `yaml:"author,omitempty"`
+	CreatedAt   time.Time   `yaml:"created_at"`
+	Signer      string      `yaml:"signer,omitempty"` // KeyID of the signing key
+	Files       []FileEntry `yaml:"files"`
+}
+
+// FileEntry describes one content file of a bundle
+type FileEntry struct {
+	Path   string      `yaml:"path"`
+	Type   ContentType `yaml:"type"`
+	ID     string      `yaml:"id"`
+	Size   int64       `yaml:"size"`
+	SHA256 string      `yaml:"sha256"`
+}
+
+// Bundle is a bundle read into memory
+type Bundle struct {
+	Manifest Manifest
+
+	manifest  []byte
+	signature []byte
+	files     map[string][]byte
+}
+
+// Content is the decoded content of a bundle
+type Content struct {
+	Maps       []*game.Level
+	Quests     []*game.Quest
+	NPCs       []*game.NPC
+	LootTables map[string]*items.LootTable
+	Dialogue   map[string][]game.DialogEntry // By NPC ID
+}
+
+// VerifyOptions sets which bundles Verify accepts
+type VerifyOptions struct {
+	// TrustedKeys are the public keys whose signatures are accepted
+	TrustedKeys []ed25519.PublicKey
+
+	// AllowUnsigned accepts bundles without a signature
+	AllowUnsigned bool
+}
+
+// Open reads the bundle at path. The bundle is not verified.
+func Open(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+	}
+	b, err := Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// Read reads a bundle of size bytes from r. The manifest must be well
+// formed and list only files of known content types at their expected
+// paths; whether the files match it is left to Verify.
+func Read(r io.ReaderAt, size int64) (*Bundle, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if len(archive.File) > MaxFiles+2 {
+		return nil, fmt.Errorf("%w: more than %d files", ErrInvalidBundle, MaxFiles)
+	}
+
+	b := &Bundle{files: make(map[string][]byte, len(archive.File))}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if _, seen := b.files[file.Name]; seen {
+			return nil, fmt.Errorf("%w: %s appears twice", ErrInvalidBundle, file.Name)
+		}
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		b.files[file.Name] = data
+	}
+
+	b.manifest = b.files[ManifestFile]
+	if b.manifest == nil {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidBundle, ManifestFile)
+	}
+	if err := yaml.Unmarshal(b.manifest, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("%w: malformed manifest: %v", ErrInvalidBundle, err)
+	}
+	if err := b.Manifest.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if sig, signed := b.files[SignatureFile]; signed {
+		if b.signature, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig))); err != nil {
+			return nil, fmt.Errorf("%w: malformed signature: %v", ErrInvalidBundle, err)
+		}
+	}
+	delete(b.files, ManifestFile)
+	delete(b.files, SignatureFile)
+	return b, nil
+}
+
+// readZipFile reads a file of a bundle, refusing files over MaxFileSize
+func readZipFile(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > MaxFileSize {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidBundle, file.Name, MaxFileSize)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, file.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, file.Name, err)
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidBundle, file.Name, MaxFileSize)
+	}
+	return data, nil
+}
+
+// validate checks the manifest's format, name and file list
+func (m *Manifest) validate() error {
+	if m.Format != FormatVersion {
+		return fmt.Errorf("unsupported manifest format %d, expected %d", m.Format, FormatVersion)
+	}
+	if !validName.MatchString(m.Name) {
+		return fmt.Errorf("invalid bundle name %q", m.Name)
+	}
+	if m.Version == "" {
+		return fmt.Errorf("bundle %s has no version", m.Name)
+	}
+	if len(m.Files) > MaxFiles {
+		return fmt.Errorf("more than %d files", MaxFiles)
+	}
+
+	seen := make(map[string]bool, len(m.Files))
+	for _, entry := range m.Files {
+		if !contentTypes[entry.Type] {
+			return fmt.Errorf("%s has unknown content type %q", entry.Path, entry.Type)
+		}
+		if !validName.MatchString(entry.ID) {
+			return fmt.Errorf("%s has invalid ID %q", entry.Path, entry.ID)
+		}
+		if entry.Path != contentPath(entry.Type, entry.ID) {
+			return fmt.Errorf("%s %s must be at %s, not %s", entry.Type, entry.ID, contentPath(entry.Type, entry.ID), entry.Path)
+		}
+		if seen[entry.Path] {
+			return fmt.Errorf("%s is listed twice", entry.Path)
+		}
+		seen[entry.Path] = true
+	}
+	return nil
+}
+
+// contentPath returns the path of a content file inside a bundle
+func contentPath(contentType ContentType, id string) string {
+	return path.Join(string(contentType), id+".yaml")
+}
+
+// Signed reports whether the bundle carries a signature
+func (b *Bundle) Signed() bool {
+	return b.signature != nil
+}
+
+// Verify checks every file of the bundle matches its manifest entry, that
+// the bundle holds no files the manifest does not list, and that its
+// signature is from a trusted key.
+//
+// Returns:
+//   - error: ErrTampered if the files do not match the manifest,
+//     ErrUnsigned or ErrUntrusted if the signature is missing or not
+//     trusted
+func (b *Bundle) Verify(opts VerifyOptions) error {
+	listed := make(map[string]bool, len(b.Manifest.Files))
+	for _, entry := range b.Manifest.Files {
+		listed[entry.Path] = true
+		data, present := b.files[entry.Path]
+		if !present {
+			return fmt.Errorf("%w: %s is missing", ErrTampered, entry.Path)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != entry.Size || hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return fmt.Errorf("%w: %s was modified", ErrTampered, entry.Path)
+		}
+	}
+	for name := range b.files {
+		if !listed[name] {
+			return fmt.Errorf("%w: %s is not in the manifest", ErrTampered, name)
+		}
+	}
+
+	if b.signature == nil {
+		if opts.AllowUnsigned {
+			return nil
+		}
+		return fmt.Errorf("%w: %s %s", ErrUnsigned, b.Manifest.Name, b.Manifest.Version)
+	}
+	for _, key := range opts.TrustedKeys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, b.manifest, b.signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s %s signed by %q", ErrUntrusted, b.Manifest.Name, b.Manifest.Version, b.Manifest.Signer)
+}
+
+// Content decodes the bundle's files. Call Verify first.
+func (b *Bundle) Content() (*Content, error) {
+	content := &Content{
+		LootTables: make(map[string]*items.LootTable),
+		Dialogue:   make(map[string][]game.DialogEntry),
+	}
+
+	for _, entry := range b.Manifest.Files {
+		data := b.files[entry.Path]
+		var err error
+		switch entry.Type {
+		case ContentMaps:
+			level := &game.Level{}
+			if err = yaml.Unmarshal(data, level); err == nil {
+				content.Maps = append(content.Maps, level)
+			}
+		case ContentQuests:
+			quest := &game.Quest{}
+			if err = yaml.Unmarshal(data, quest); err == nil {
+				content.Quests = append(content.Quests, quest)
+			}
+		case ContentNPCs:
+			npc := &game.NPC{}
+			if err = yaml.Unmarshal(data, npc); err == nil {
+				content.NPCs = append(content.NPCs, npc)
+			}
+		case ContentLootTables:
+			table := &items.LootTable{}
+			if err = yaml.Unmarshal(data, table); err == nil {
+				content.LootTables[entry.ID] = table
+			}
+		case ContentDialogue:
+			var entries []game.DialogEntry
+			if err = yaml.Unmarshal(data, &entries); err == nil {
+				content.Dialogue[entry.ID] = entries
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", entry.Path, err)
+		}
+	}
+	return content, nil
+}
+
+// KeyID returns a short fingerprint of a public key: the first 8 bytes of
+// its SHA-256 digest in hex
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// EncodePublicKey returns a public key in the base64 form ParsePublicKey
+// reads
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey reads a base64 encoded ed25519 public key
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("malformed bundle key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("bundle key must be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// sortedEntries returns the manifest's files in path order
+func sortedEntries(files []FileEntry) []FileEntry {
+	sorted := append([]FileEntry(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	return sorted
+}
@@ -0,0 +1,250 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg/items"
+	"goldbox-rpg/pkg/resilience"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBuilder returns a builder holding one piece of every content type
+func testBuilder(t *testing.T) *Builder {
+	t.Helper()
+	b := NewBuilder("crypt-of-ages", "1.0.0")
+	b.SetDescription("A haunted crypt", "tester")
+
+	level := &game.Level{ID: "crypt_1", Name: "Crypt", Width: 2, Height: 1,
+		Tiles: [][]game.Tile{{{Type: game.TileFloor, Walkable: true}, {Type: game.TileWall}}}}
+	npc := &game.NPC{Character: game.Character{ID: "old_tom", Name: "Old Tom"}, Faction: "villagers"}
+	dialog := []game.DialogEntry{{ID: "greet", Text: "Mind the bones."}}
+
+	require.NoError(t, b.AddMap(level))
+	require.NoError(t, b.AddQuest(&game.Quest{ID: "lost_ring", Title: "The Lost Ring"}))
+	require.NoError(t, b.AddNPC(npc))
+	require.NoError(t, b.AddLootTable("crypt", &items.LootTable{
+		Rolls:   items.LootRange{Min: 1, Max: 1},
+		Entries: []items.LootEntry{{Item: "dagger", Weight: 1}},
+	}))
+	require.NoError(t, b.AddDialogue("old_tom", dialog))
+	return b
+}
+
+// build writes a bundle and reads it back
+func build(t *testing.T, b *Builder, key ed25519.PrivateKey) *Bundle {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, b.Write(&buf, key))
+	bundle, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return bundle
+}
+
+func TestBundle_SignedRoundTrip(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	b := build(t, testBuilder(t), private)
+	assert.True(t, b.Signed())
+	assert.Equal(t, KeyID(public), b.Manifest.Signer)
+	require.NoError(t, b.Verify(VerifyOptions{TrustedKeys: []ed25519.PublicKey{public}}))
+
+	content, err := b.Content()
+	require.NoError(t, err)
+	require.Len(t, content.Maps, 1)
+	assert.Equal(t, "crypt_1", content.Maps[0].ID)
+	assert.True(t, content.Maps[0].Tiles[0][0].Walkable)
+	require.Len(t, content.Quests, 1)
+	assert.Equal(t, "The Lost Ring", content.Quests[0].Title)
+	require.Len(t, content.NPCs, 1)
+	assert.Equal(t, "Old Tom", content.NPCs[0].Name)
+	assert.Equal(t, 1, content.LootTables["crypt"].Rolls.Max)
+	assert.Equal(t, "Mind the bones.", content.Dialogue["old_tom"][0].Text)
+}
+
+func TestBundle_Signatures(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	other, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	signed := build(t, testBuilder(t), private)
+	assert.ErrorIs(t, signed.Verify(VerifyOptions{TrustedKeys: []ed25519.PublicKey{other}}), ErrUntrusted)
+	assert.ErrorIs(t, signed.Verify(VerifyOptions{AllowUnsigned: true}), ErrUntrusted,
+		"allowing unsigned bundles does not accept untrusted signatures")
+
+	unsigned := build(t, testBuilder(t), nil)
+	assert.False(t, unsigned.Signed())
+	assert.ErrorIs(t, unsigned.Verify(VerifyOptions{TrustedKeys: []ed25519.PublicKey{public}}), ErrUnsigned)
+	assert.NoError(t, unsigned.Verify(VerifyOptions{AllowUnsigned: true}))
+}
+
+// rewrite copies a bundle, passing each file through edit. Files edit
+// returns nil for are dropped.
+func rewrite(t *testing.T, data []byte, edit func(name string, data []byte) []byte) []byte {
+	t.Helper()
+	in, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	out := zip.NewWriter(&buf)
+	for _, file := range in.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		contents, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+
+		if contents = edit(file.Name, contents); contents == nil {
+			continue
+		}
+		w, err := out.Create(file.Name)
+		require.NoError(t, err)
+		_, err = w.Write(contents)
+		require.NoError(t, err)
+	}
+	require.NoError(t, out.Close())
+	return buf.Bytes()
+}
+
+func TestBundle_Tampered(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, testBuilder(t).Write(&buf, private))
+	opts := VerifyOptions{TrustedKeys: []ed25519.PublicKey{public}}
+
+	tests := []struct {
+		name string
+		edit func(name string, data []byte) []byte
+	}{
+		{"modified file", func(name string, data []byte) []byte {
+			if name == "npcs/old_tom.yaml" {
+				return bytes.Replace(data, []byte("Old Tom"), []byte("Old Tim"), 1)
+			}
+			return data
+		}},
+		{"missing file", func(name string, data []byte) []byte {
+			if name == "quests/lost_ring.yaml" {
+				return nil
+			}
+			return data
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := rewrite(t, buf.Bytes(), tt.edit)
+			b, err := Read(bytes.NewReader(data), int64(len(data)))
+			require.NoError(t, err)
+			err = b.Verify(opts)
+			assert.ErrorIs(t, err, ErrTampered)
+			assert.ErrorIs(t, err, resilience.ErrIntegrityViolation)
+		})
+	}
+
+	t.Run("unlisted file", func(t *testing.T) {
+		in, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		var extra bytes.Buffer
+		out := zip.NewWriter(&extra)
+		for _, file := range in.File {
+			require.NoError(t, out.Copy(file))
+		}
+		w, err := out.Create("npcs/stranger.yaml")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("char_name: Stranger\n"))
+		require.NoError(t, err)
+		require.NoError(t, out.Close())
+
+		b, err := Read(bytes.NewReader(extra.Bytes()), int64(extra.Len()))
+		require.NoError(t, err)
+		assert.ErrorIs(t, b.Verify(opts), ErrTampered)
+	})
+
+	t.Run("modified manifest", func(t *testing.T) {
+		data := rewrite(t, buf.Bytes(), func(name string, data []byte) []byte {
+			if name == ManifestFile {
+				return bytes.Replace(data, []byte("version: 1.0.0"), []byte("version: 1.0.1"), 1)
+			}
+			return data
+		})
+		b, err := Read(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		assert.ErrorIs(t, b.Verify(opts), ErrUntrusted, "the signature covers the manifest")
+	})
+}
+
+func TestRead_Invalid(t *testing.T) {
+	_, err := Read(bytes.NewReader([]byte("not a zip")), 9)
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	var buf bytes.Buffer
+	require.NoError(t, testBuilder(t).Write(&buf, nil))
+	escaping := rewrite(t, buf.Bytes(), func(name string, data []byte) []byte {
+		if name == ManifestFile {
+			return bytes.Replace(data, []byte("path: npcs/old_tom.yaml"), []byte("path: ../old_tom.yaml"), 1)
+		}
+		return data
+	})
+	_, err = Read(bytes.NewReader(escaping), int64(len(escaping)))
+	assert.ErrorIs(t, err, ErrInvalidBundle, "files must sit at the path of their type and ID")
+}
+
+func TestBuilder_Add(t *testing.T) {
+	b := NewBuilder("test", "1")
+	assert.Error(t, b.Add("spells", "fireball", "boom"))
+	assert.Error(t, b.Add(ContentQuests, "../escape", &game.Quest{}))
+	require.NoError(t, b.AddQuest(&game.Quest{ID: "q1"}))
+	assert.Error(t, b.AddQuest(&game.Quest{ID: "q1"}), "IDs are unique within a content type")
+	assert.Error(t, NewBuilder("bad name", "1").Write(io.Discard, nil))
+}
+
+func TestInstallAndFind(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	opts := VerifyOptions{TrustedKeys: []ed25519.PublicKey{public}}
+
+	src := t.TempDir()
+	dir := t.TempDir()
+	v1 := filepath.Join(src, "v1.gbox")
+	require.NoError(t, testBuilder(t).WriteFile(v1, private))
+	v2Builder := testBuilder(t)
+	v2Builder.manifest.Version = "2.0.0"
+	v2 := filepath.Join(src, "v2.gbox")
+	require.NoError(t, v2Builder.WriteFile(v2, private))
+
+	manifest, err := Install(v1, dir, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", manifest.Version)
+	manifest, err = Install(v2, dir, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", manifest.Version)
+
+	installed, err := Open(filepath.Join(dir, "crypt-of-ages.gbox"))
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", installed.Manifest.Version, "installing replaces the earlier version")
+
+	unsigned := filepath.Join(src, "unsigned.gbox")
+	require.NoError(t, NewBuilder("unsigned", "1").WriteFile(unsigned, nil))
+	_, err = Install(unsigned, dir, opts)
+	assert.ErrorIs(t, err, ErrUnsigned)
+	assert.NoFileExists(t, filepath.Join(dir, "unsigned.gbox"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(src, "notes.txt"), []byte("x"), 0o644))
+	found, err := Find([]string{src, filepath.Join(dir, "crypt-of-ages.gbox")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{unsigned, v1, v2, filepath.Join(dir, "crypt-of-ages.gbox")}, found)
+
+	_, err = Find([]string{filepath.Join(src, "missing")})
+	assert.Error(t, err)
+}
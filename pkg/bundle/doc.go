@@ -0,0 +1,47 @@
+// Package bundle packages generated or authored game content as .gbox
+// bundles that can be shared between servers.
+//
+// A bundle is a zip archive holding a manifest.yaml and one YAML file per
+// piece of content, grouped in a directory per content type:
+//
+//	manifest.yaml
+//	manifest.sig             (signed bundles only)
+//	maps/crypt_1.yaml        game.Level
+//	quests/lost_ring.yaml    game.Quest
+//	npcs/old_tom.yaml        game.NPC
+//	loot_tables/crypt.yaml   items.LootTable
+//	dialogue/old_tom.yaml    []game.DialogEntry of the NPC with that ID
+//
+// The manifest names the bundle and lists every file with its content type,
+// ID, size and SHA-256 digest, so a bundle whose files were changed, added
+// or removed fails Verify with ErrTampered.
+//
+// # Building
+//
+//	b := bundle.NewBuilder("crypt-of-ages", "1.0.0")
+//	b.AddMap(level)
+//	b.AddNPC(npc)
+//	b.AddDialogue(npc.ID, npc.Dialog)
+//	err := b.WriteFile("crypt-of-ages.gbox", privateKey)
+//
+// # Signatures
+//
+// A bundle built with an ed25519 private key carries manifest.sig, the
+// signature of the manifest. Since the manifest holds the digest of every
+// file, the signature covers the whole bundle. Verify accepts a signed
+// bundle only if one of VerifyOptions.TrustedKeys made the signature, and
+// an unsigned bundle only if VerifyOptions.AllowUnsigned is set.
+//
+// # Installing
+//
+// Install verifies a bundle and copies it into a bundle directory as
+// <name>.gbox, replacing an earlier version of the same bundle. Find lists
+// the bundles of files and directories, and Open, Verify and Content read
+// one back:
+//
+//	b, err := bundle.Open(path)
+//	if err == nil {
+//	    err = b.Verify(bundle.VerifyOptions{TrustedKeys: keys})
+//	}
+//	content, err := b.Content()
+package bundle
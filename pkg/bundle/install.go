@@ -0,0 +1,63 @@
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"goldbox-rpg/pkg/persistence"
+)
+
+// Install verifies the bundle at path and copies it into dir as
+// <name>.gbox, replacing any installed version of the same bundle.
+//
+// Returns:
+//   - *Manifest: The installed bundle's manifest
+//   - error: The bundle cannot be read or fails verification, or the copy
+//     cannot be written
+func Install(path, dir string, opts VerifyOptions) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+	}
+	b, err := Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := b.Verify(opts); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	target := filepath.Join(dir, b.Manifest.Name+Extension)
+	if err := persistence.AtomicWriteFile(target, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to install bundle %s: %w", b.Manifest.Name, err)
+	}
+	return &b.Manifest, nil
+}
+
+// Find returns the bundle files among paths. A directory stands for the
+// .gbox files directly inside it, in name order; files are returned as
+// given.
+func Find(paths []string) ([]string, error) {
+	var found []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find bundles in %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			found = append(found, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, "*"+Extension))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list bundles in %s: %w", path, err)
+		}
+		sort.Strings(matches)
+		found = append(found, matches...)
+	}
+	return found, nil
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrBudgetExceeded is returned by the pcg-bench subcommand when a
+// benchmark case exceeds one of its performance budgets.
+var ErrBudgetExceeded = errors.New("performance budgets exceeded")
+
+// BenchOptions configures a generation benchmark run.
+type BenchOptions struct {
+	Seed int64
+	// Budgets is a YAML file declaring the benchmark matrix and budgets.
+	Budgets string
+	// Iterations overrides the iteration count from the budget file when
+	// positive.
+	Iterations int
+	Logger     *logrus.Logger
+}
+
+// BenchResult reports a benchmark run and the performance score it leaves
+// in the quality metrics.
+type BenchResult struct {
+	Seed             int64                `json:"seed"`
+	Report           *pcg.BenchmarkReport `json:"report"`
+	PerformanceScore float64              `json:"performance_score"`
+}
+
+// DefaultBenchOptions returns options that run the budgets shipped in
+// data/pcg.
+func DefaultBenchOptions() BenchOptions {
+	return BenchOptions{
+		Seed:    12345,
+		Budgets: "data/pcg/bench_budgets.yaml",
+	}
+}
+
+// RunBench benchmarks the built-in generators across the matrix declared in
+// the budget file and checks the results against its budgets.
+func RunBench(ctx context.Context, opts BenchOptions) (*BenchResult, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+	}
+
+	budgets, err := pcg.LoadBenchmarkBudgets(opts.Budgets)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Iterations > 0 {
+		budgets.Iterations = opts.Iterations
+	}
+
+	manager, err := NewPCGManager(game.NewWorld(), logger, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := manager.RunBenchmark(ctx, budgets)
+	if err != nil {
+		return nil, fmt.Errorf("benchmark failed: %w", err)
+	}
+
+	return &BenchResult{
+		Seed:             opts.Seed,
+		Report:           report,
+		PerformanceScore: manager.GenerateQualityReport().ComponentScores["performance"],
+	}, nil
+}
+
+// runBench implements the pcg-bench subcommand.
+func runBench(ctx context.Context, env *Env, args []string) error {
+	opts := DefaultBenchOptions()
+
+	fs := newFlagSet(env, "pcg-bench")
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "Seed for deterministic generation")
+	fs.StringVar(&opts.Budgets, "budgets", opts.Budgets, "YAML file declaring the benchmark matrix and budgets")
+	fs.IntVar(&opts.Iterations, "iterations", 0, "Runs per case (default from the budget file)")
+	if err := parseFlags(fs, args, false); err != nil {
+		return err
+	}
+	if opts.Iterations < 0 {
+		return fmt.Errorf("%w: iterations must not be negative", ErrUsage)
+	}
+	opts.Logger = env.Logger
+
+	result, err := RunBench(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := env.Emit(result, func(w io.Writer) {
+		report := result.Report
+		fmt.Fprintf(w, "PCG benchmark (seed %d, %d iterations per case)\n", result.Seed, report.Iterations)
+		for _, r := range report.Results {
+			fmt.Fprintf(w, "  %-36s p50 %-12v p95 %-12v %d allocs/op %d B/op\n",
+				r.Name(), r.P50, r.P95, r.AllocsPerOp, r.BytesPerOp)
+		}
+		for _, v := range report.Violations {
+			fmt.Fprintf(w, "  OVER BUDGET %s %s: %d > %d\n", v.Case, v.Metric, v.Actual, v.Limit)
+		}
+		fmt.Fprintf(w, "  Performance score: %.2f\n", result.PerformanceScore)
+	}); err != nil {
+		return err
+	}
+
+	if !result.Report.Passed {
+		return fmt.Errorf("%w: %d of %d cases", ErrBudgetExceeded, result.Report.FailedCases(), len(result.Report.Results))
+	}
+	return nil
+}
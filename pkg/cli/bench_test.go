@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShippedBenchBudgetsLoad(t *testing.T) {
+	budgets, err := pcg.LoadBenchmarkBudgets(filepath.Join("..", "..", DefaultBenchOptions().Budgets))
+	require.NoError(t, err)
+	assert.NotEmpty(t, budgets.Cases())
+	assert.NotEmpty(t, budgets.Budgets)
+}
+
+func TestBenchCommandReportsExceededBudgets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budgets.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+iterations: 2
+matrix:
+  terrain:
+    sizes: [10]
+    difficulties: [1]
+budgets:
+  - content_type: terrain
+    p50: 1ns
+`), 0o644))
+
+	code, stdout, stderr := runCLI(t, "-format", "json", "pcg-bench", "-budgets", path, "-seed", "3")
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, stderr, ErrBudgetExceeded.Error())
+
+	var result BenchResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, int64(3), result.Seed)
+	require.Len(t, result.Report.Results, 1)
+	assert.Equal(t, 2, result.Report.Results[0].Iterations)
+	require.Len(t, result.Report.Violations, 1)
+	assert.Equal(t, "p50", result.Report.Violations[0].Metric)
+	assert.Less(t, result.PerformanceScore, 1.0)
+}
+
+func TestBenchCommandRejectsNegativeIterations(t *testing.T) {
+	code, _, stderr := runCLI(t, "pcg-bench", "-iterations", "-1")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "must not be negative")
+}
@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BootstrapOptions configures zero-configuration game generation.
+type BootstrapOptions struct {
+	// Template names an entry in bootstrap_templates.yaml; when set it
+	// overrides the individual settings below.
+	Template string
+	// TemplateDir is the data directory containing pcg/bootstrap_templates.yaml.
+	TemplateDir      string
+	GameLength       pcg.GameLengthType
+	Complexity       pcg.ComplexityType
+	Genre            pcg.GenreType
+	MaxPlayers       int
+	StartingLevel    int
+	Seed             int64
+	OutputDir        string
+	EnableQuickStart bool
+	// Clean removes OutputDir before generation.
+	Clean bool
+	// Profile replays an exported bootstrap profile, given as a file path
+	// or a world sharing code; it overrides the template and settings.
+	Profile string
+	// ExportProfile writes the profile of the run to this path when set.
+	ExportProfile string
+	// Concurrency limits how many content stages run at once (0 = one per CPU).
+	Concurrency int
+	// StageTimeout limits each content stage (0 = pcg.DefaultBootstrapStageTimeout).
+	StageTimeout time.Duration
+	Timeout      time.Duration
+	Logger       *logrus.Logger
+}
+
+// BootstrapResult describes a completed bootstrap run.
+type BootstrapResult struct {
+	Config    *pcg.BootstrapConfig `json:"config"`
+	Duration  time.Duration        `json:"duration_ns"`
+	Files     []string             `json:"files"`
+	ShareCode string               `json:"share_code"` // Regenerates the same world with -profile
+}
+
+// DefaultBootstrapOptions returns options matching pcg.DefaultBootstrapConfig.
+func DefaultBootstrapOptions() BootstrapOptions {
+	defaults := pcg.DefaultBootstrapConfig()
+	return BootstrapOptions{
+		TemplateDir:      "data",
+		GameLength:       defaults.GameLength,
+		Complexity:       defaults.ComplexityLevel,
+		Genre:            defaults.GenreVariant,
+		MaxPlayers:       defaults.MaxPlayers,
+		StartingLevel:    defaults.StartingLevel,
+		OutputDir:        "generated",
+		EnableQuickStart: defaults.EnableQuickStart,
+		Timeout:          2 * time.Minute,
+	}
+}
+
+// validGameLengths, validComplexities and validGenres list the accepted
+// bootstrap enum values.
+var (
+	validGameLengths = map[pcg.GameLengthType]bool{
+		pcg.GameLengthShort: true, pcg.GameLengthMedium: true, pcg.GameLengthLong: true,
+	}
+	validComplexities = map[pcg.ComplexityType]bool{
+		pcg.ComplexitySimple: true, pcg.ComplexityStandard: true, pcg.ComplexityAdvanced: true,
+	}
+	validGenres = map[pcg.GenreType]bool{
+		pcg.GenreClassicFantasy: true, pcg.GenreGrimdark: true, pcg.GenreHighMagic: true, pcg.GenreLowFantasy: true,
+	}
+)
+
+// resolveConfig validates the options and builds the bootstrap configuration.
+func (o BootstrapOptions) resolveConfig() (*pcg.BootstrapConfig, error) {
+	if o.OutputDir == "" {
+		return nil, fmt.Errorf("output directory must not be empty")
+	}
+
+	if o.Profile != "" {
+		profile, err := loadProfile(o.Profile)
+		if err != nil {
+			return nil, err
+		}
+		cfg := profile.Config
+		cfg.DataDirectory = o.OutputDir
+		return &cfg, nil
+	}
+
+	if o.Template != "" {
+		cfg, err := pcg.LoadBootstrapTemplate(o.Template, o.TemplateDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load template %s: %w", o.Template, err)
+		}
+		cfg.DataDirectory = o.OutputDir
+		if o.Seed != 0 {
+			cfg.WorldSeed = o.Seed
+		}
+		return cfg, nil
+	}
+
+	if !validGameLengths[o.GameLength] {
+		return nil, fmt.Errorf("invalid game length %q: must be one of short, medium, long", o.GameLength)
+	}
+	if !validComplexities[o.Complexity] {
+		return nil, fmt.Errorf("invalid complexity level %q: must be one of simple, standard, advanced", o.Complexity)
+	}
+	if !validGenres[o.Genre] {
+		return nil, fmt.Errorf("invalid genre variant %q: must be one of classic_fantasy, grimdark, high_magic, low_fantasy", o.Genre)
+	}
+	if o.MaxPlayers < 1 {
+		return nil, fmt.Errorf("max players must be at least 1, got %d", o.MaxPlayers)
+	}
+	if o.StartingLevel < 1 {
+		return nil, fmt.Errorf("starting level must be at least 1, got %d", o.StartingLevel)
+	}
+
+	return &pcg.BootstrapConfig{
+		GameLength:       o.GameLength,
+		ComplexityLevel:  o.Complexity,
+		GenreVariant:     o.Genre,
+		MaxPlayers:       o.MaxPlayers,
+		StartingLevel:    o.StartingLevel,
+		WorldSeed:        o.Seed,
+		EnableQuickStart: o.EnableQuickStart,
+		DataDirectory:    o.OutputDir,
+	}, nil
+}
+
+// Bootstrap generates a complete game into opts.OutputDir and returns a
+// summary of the generated files.
+func Bootstrap(ctx context.Context, opts BootstrapOptions) (*BootstrapResult, error) {
+	cfg, err := opts.resolveConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Clean {
+		if err := os.RemoveAll(opts.OutputDir); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to clean output directory: %w", err)
+		}
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	bootstrap := pcg.NewBootstrap(cfg, game.NewWorld(), opts.Logger)
+	if opts.Profile != "" {
+		profile, err := loadProfile(opts.Profile)
+		if err != nil {
+			return nil, err
+		}
+		if bootstrap, err = pcg.NewBootstrapFromProfile(profile, opts.OutputDir, game.NewWorld(), opts.Logger); err != nil {
+			return nil, err
+		}
+	}
+
+	bootstrap.SetConcurrency(opts.Concurrency)
+	bootstrap.SetStageTimeout(opts.StageTimeout)
+
+	start := time.Now()
+	if _, err := bootstrap.GenerateCompleteGame(ctx); err != nil {
+		return nil, fmt.Errorf("game generation failed: %w", err)
+	}
+	duration := time.Since(start)
+
+	profile, err := bootstrap.ExportProfile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export bootstrap profile: %w", err)
+	}
+	shareCode, err := profile.ShareCode()
+	if err != nil {
+		return nil, err
+	}
+	if opts.ExportProfile != "" {
+		if err := profile.Save(opts.ExportProfile); err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := listFiles(opts.OutputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list generated files: %w", err)
+	}
+
+	resolved := profile.Config
+	resolved.DataDirectory = opts.OutputDir
+	return &BootstrapResult{
+		Config:    &resolved,
+		Duration:  duration,
+		Files:     files,
+		ShareCode: shareCode,
+	}, nil
+}
+
+// loadProfile reads a bootstrap profile from a world sharing code or, for
+// anything else, a profile file.
+func loadProfile(source string) (*pcg.BootstrapProfile, error) {
+	if strings.HasPrefix(source, pcg.ShareCodePrefix) {
+		return pcg.ParseShareCode(source)
+	}
+	return pcg.LoadBootstrapProfile(source)
+}
+
+// listFiles returns all regular files under dir relative to dir.
+func listFiles(dir string) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// runBootstrap implements the bootstrap subcommand.
+func runBootstrap(ctx context.Context, env *Env, args []string) error {
+	opts := DefaultBootstrapOptions()
+	var gameLength, complexity, genre string
+	var listTemplates bool
+
+	fs := newFlagSet(env, "bootstrap")
+	fs.StringVar(&opts.Template, "template", "", "Template name from bootstrap_templates.yaml (overrides other options)")
+	fs.StringVar(&opts.TemplateDir, "template-dir", opts.TemplateDir, "Data directory containing pcg/bootstrap_templates.yaml")
+	fs.BoolVar(&listTemplates, "list-templates", false, "List available templates and exit")
+	fs.StringVar(&gameLength, "length", string(opts.GameLength), "Game length: short, medium, long")
+	fs.StringVar(&complexity, "complexity", string(opts.Complexity), "Complexity level: simple, standard, advanced")
+	fs.StringVar(&genre, "genre", string(opts.Genre), "Genre variant: classic_fantasy, grimdark, high_magic, low_fantasy")
+	fs.IntVar(&opts.MaxPlayers, "players", opts.MaxPlayers, "Maximum number of players")
+	fs.IntVar(&opts.StartingLevel, "level", opts.StartingLevel, "Starting character level")
+	fs.Int64Var(&opts.Seed, "seed", 0, "World seed for deterministic generation (0 = random)")
+	fs.StringVar(&opts.OutputDir, "output", opts.OutputDir, "Output directory for generated files")
+	fs.BoolVar(&opts.EnableQuickStart, "quick", opts.EnableQuickStart, "Enable quick start scenario")
+	fs.BoolVar(&opts.Clean, "clean", false, "Remove the output directory before generating")
+	fs.StringVar(&opts.Profile, "profile", "", "Replay a bootstrap profile file or world sharing code (overrides other options)")
+	fs.StringVar(&opts.ExportProfile, "export-profile", "", "Write the bootstrap profile of the run to this file")
+	fs.IntVar(&opts.Concurrency, "concurrency", 0, "Content stages generated at once (0 = one per CPU)")
+	fs.DurationVar(&opts.StageTimeout, "stage-timeout", pcg.DefaultBootstrapStageTimeout, "Maximum time per content stage")
+	fs.DurationVar(&opts.Timeout, "timeout", opts.Timeout, "Maximum generation time")
+	if err := parseFlags(fs, args, false); err != nil {
+		return err
+	}
+
+	if listTemplates {
+		templates, err := pcg.ListAvailableTemplates(opts.TemplateDir)
+		if err != nil {
+			return fmt.Errorf("failed to list templates: %w", err)
+		}
+		return env.Emit(map[string]interface{}{"templates": templates}, func(w io.Writer) {
+			fmt.Fprintf(w, "Available bootstrap templates (%d found):\n", len(templates))
+			for _, name := range templates {
+				fmt.Fprintf(w, "  - %s\n", name)
+			}
+		})
+	}
+
+	opts.GameLength = pcg.GameLengthType(gameLength)
+	opts.Complexity = pcg.ComplexityType(complexity)
+	opts.Genre = pcg.GenreType(genre)
+	opts.Logger = env.Logger
+
+	if _, err := opts.resolveConfig(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+
+	result, err := Bootstrap(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		fmt.Fprintln(w, "Bootstrap complete")
+		fmt.Fprintf(w, "  Game length:    %s\n", result.Config.GameLength)
+		fmt.Fprintf(w, "  Complexity:     %s\n", result.Config.ComplexityLevel)
+		fmt.Fprintf(w, "  Genre:          %s\n", result.Config.GenreVariant)
+		fmt.Fprintf(w, "  Max players:    %d\n", result.Config.MaxPlayers)
+		fmt.Fprintf(w, "  Starting level: %d\n", result.Config.StartingLevel)
+		fmt.Fprintf(w, "  Output:         %s\n", result.Config.DataDirectory)
+		fmt.Fprintf(w, "  Duration:       %v\n", result.Duration)
+		fmt.Fprintf(w, "  World seed:     %d\n", result.Config.WorldSeed)
+		fmt.Fprintf(w, "  Files:          %d\n", len(result.Files))
+		for _, file := range result.Files {
+			fmt.Fprintf(w, "    - %s\n", file)
+		}
+		fmt.Fprintf(w, "  Share code:     %s\n", result.ShareCode)
+	})
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapGeneratesFiles(t *testing.T) {
+	opts := DefaultBootstrapOptions()
+	opts.OutputDir = filepath.Join(t.TempDir(), "out")
+	opts.Seed = 99
+	opts.GameLength = pcg.GameLengthShort
+
+	result, err := Bootstrap(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Contains(t, result.Files, "pcg/bootstrap_config.yaml")
+	assert.Equal(t, int64(99), result.Config.WorldSeed)
+}
+
+func TestBootstrapOptionsValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*BootstrapOptions)
+	}{
+		{"bad length", func(o *BootstrapOptions) { o.GameLength = "epic" }},
+		{"bad complexity", func(o *BootstrapOptions) { o.Complexity = "hard" }},
+		{"bad genre", func(o *BootstrapOptions) { o.Genre = "scifi" }},
+		{"no players", func(o *BootstrapOptions) { o.MaxPlayers = 0 }},
+		{"bad level", func(o *BootstrapOptions) { o.StartingLevel = 0 }},
+		{"no output", func(o *BootstrapOptions) { o.OutputDir = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultBootstrapOptions()
+			tt.modify(&opts)
+			_, err := opts.resolveConfig()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBootstrapCommandUsageError(t *testing.T) {
+	code, _, stderr := runCLI(t, "bootstrap", "-length", "forever", "-output", t.TempDir())
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "invalid game length")
+}
+
+func TestBootstrapReplaysProfile(t *testing.T) {
+	opts := DefaultBootstrapOptions()
+	opts.OutputDir = filepath.Join(t.TempDir(), "original")
+	opts.Complexity = pcg.ComplexityAdvanced
+	opts.ExportProfile = filepath.Join(t.TempDir(), "world.yaml")
+
+	original, err := Bootstrap(context.Background(), opts)
+	require.NoError(t, err)
+	require.NotZero(t, original.Config.WorldSeed, "the random seed is resolved")
+	require.NotEmpty(t, original.ShareCode)
+
+	for _, profile := range []string{original.ShareCode, opts.ExportProfile} {
+		replay := DefaultBootstrapOptions()
+		replay.OutputDir = filepath.Join(t.TempDir(), "replay")
+		replay.Profile = profile
+
+		result, err := Bootstrap(context.Background(), replay)
+		require.NoError(t, err)
+		assert.Equal(t, original.Config.WorldSeed, result.Config.WorldSeed)
+		assert.Equal(t, pcg.ComplexityAdvanced, result.Config.ComplexityLevel)
+		assert.Equal(t, original.ShareCode, result.ShareCode)
+	}
+
+	replay := DefaultBootstrapOptions()
+	replay.Profile = pcg.ShareCodePrefix + "garbage"
+	_, err = replay.resolveConfig()
+	assert.ErrorContains(t, err, "invalid world sharing code")
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Exit codes returned by Run. Automation can rely on these values to
+// distinguish invalid invocations from runtime failures.
+const (
+	ExitOK      = 0 // Command completed successfully
+	ExitFailure = 1 // Command ran but failed
+	ExitUsage   = 2 // Invalid command line usage
+)
+
+// ErrUsage is wrapped by errors caused by invalid command line usage.
+// Run maps it to ExitUsage.
+var ErrUsage = errors.New("usage error")
+
+// Env carries the shared state for a single CLI invocation.
+type Env struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	Format OutputFormat
+	Logger *logrus.Logger
+}
+
+// Command describes a goldbox subcommand.
+type Command struct {
+	// Name is the word used to select the command on the command line.
+	Name string
+	// Summary is a one-line description shown in usage output.
+	Summary string
+	// Run parses the command's own flags from args and executes it.
+	Run func(ctx context.Context, env *Env, args []string) error
+}
+
+// Commands returns all registered subcommands sorted by name.
+func Commands() []Command {
+	commands := []Command{
+		{Name: "bootstrap", Summary: "Generate a complete zero-configuration game", Run: runBootstrap},
+		{Name: "dungeon", Summary: "Generate a multi-level dungeon complex", Run: runDungeon},
+		{Name: "generate", Summary: "Generate terrain, a level, a quest or a dungeon", Run: runGenerate},
+		{Name: "heatmap", Summary: "Analyse the difficulty pacing of a generated dungeon or world", Run: runHeatmap},
+		{Name: "pcg-bench", Summary: "Benchmark PCG generators against performance budgets", Run: runBench},
+		{Name: "metrics", Summary: "Exercise the PCG manager and report quality metrics", Run: runMetrics},
+		{Name: "validate", Summary: "Validate a JSON content file against PCG rules", Run: runValidate},
+		{Name: "serve", Summary: "Run the JSON-RPC game server", Run: runServe},
+		{Name: "worldgen", Summary: "Generate an overworld campaign setting", Run: runWorldgen},
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+// findCommand returns the command registered under name.
+func findCommand(name string) (Command, bool) {
+	for _, cmd := range Commands() {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// Main runs the CLI with the process arguments and exits with the result code.
+func Main() {
+	os.Exit(Run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Run parses the global flags from args, dispatches to the selected
+// subcommand, and returns the process exit code.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("goldbox", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	format := global.String("format", string(FormatText), "Output format: text or json")
+	verbose := global.Bool("verbose", false, "Enable debug logging")
+	global.Usage = func() { printUsage(stderr, global) }
+
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	outputFormat, err := ParseOutputFormat(*format)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return ExitUsage
+	}
+
+	rest := global.Args()
+	if len(rest) == 0 {
+		printUsage(stderr, global)
+		return ExitUsage
+	}
+
+	cmd, ok := findCommand(rest[0])
+	if !ok {
+		fmt.Fprintf(stderr, "Error: unknown command %q\n\n", rest[0])
+		printUsage(stderr, global)
+		return ExitUsage
+	}
+
+	env := &Env{
+		Stdout: stdout,
+		Stderr: stderr,
+		Format: outputFormat,
+		Logger: newLogger(stderr, *verbose),
+	}
+
+	if err := cmd.Run(ctx, env, rest[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		if errors.Is(err, ErrUsage) {
+			return ExitUsage
+		}
+		return ExitFailure
+	}
+
+	return ExitOK
+}
+
+// printUsage writes the top-level help text.
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: goldbox [global flags] <command> [command flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range Commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Fprintln(w, "\nGlobal flags:")
+	global.SetOutput(w)
+	global.PrintDefaults()
+	fmt.Fprintln(w, "\nRun 'goldbox <command> -h' for command-specific flags.")
+}
+
+// newLogger creates the logger shared by all subcommands. Logs always go to
+// stderr so that JSON output on stdout stays machine-readable.
+func newLogger(w io.Writer, verbose bool) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(w)
+	logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	if verbose {
+		logger.SetLevel(logrus.DebugLevel)
+	} else {
+		logger.SetLevel(logrus.WarnLevel)
+	}
+	return logger
+}
+
+// newFlagSet creates a flag set for a subcommand that reports errors
+// instead of exiting the process.
+func newFlagSet(env *Env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("goldbox "+name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	return fs
+}
+
+// parseFlags parses a subcommand flag set and rejects stray positional
+// arguments unless allowArgs is set.
+func parseFlags(fs *flag.FlagSet, args []string, allowArgs bool) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	if !allowArgs && fs.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected arguments: %s", ErrUsage, strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCLI executes the CLI in-process and captures its output.
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCommandsSortedAndUnique(t *testing.T) {
+	commands := Commands()
+	require.NotEmpty(t, commands)
+
+	seen := make(map[string]bool)
+	for i, cmd := range commands {
+		assert.False(t, seen[cmd.Name], "duplicate command %s", cmd.Name)
+		seen[cmd.Name] = true
+		assert.NotEmpty(t, cmd.Summary)
+		assert.NotNil(t, cmd.Run)
+		if i > 0 {
+			assert.Less(t, commands[i-1].Name, cmd.Name)
+		}
+	}
+
+	for _, name := range []string{"bootstrap", "dungeon", "generate", "metrics", "validate", "serve", "worldgen"} {
+		assert.True(t, seen[name], "missing command %s", name)
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{"no command", nil, ExitUsage, "Usage: goldbox"},
+		{"unknown command", []string{"frobnicate"}, ExitUsage, `unknown command "frobnicate"`},
+		{"bad format", []string{"-format", "xml", "dungeon"}, ExitUsage, "invalid output format"},
+		{"bad global flag", []string{"-nope"}, ExitUsage, "flag provided but not defined"},
+		{"bad command flag", []string{"dungeon", "-nope"}, ExitUsage, "flag provided but not defined"},
+		{"stray argument", []string{"metrics", "extra"}, ExitUsage, "unexpected arguments"},
+		{"help", []string{"-h"}, ExitOK, "Commands:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, tt.args...)
+			assert.Equal(t, tt.wantCode, code)
+			assert.Contains(t, stderr, tt.wantErr)
+		})
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	format, err := ParseOutputFormat("json")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = ParseOutputFormat("yaml")
+	assert.Error(t, err)
+}
+
+func TestEmitFormats(t *testing.T) {
+	var buf bytes.Buffer
+	env := &Env{Stdout: &buf, Format: FormatJSON}
+	require.NoError(t, env.Emit(map[string]int{"count": 3}, nil))
+
+	var decoded map[string]int
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, 3, decoded["count"])
+
+	buf.Reset()
+	env.Format = FormatText
+	require.NoError(t, env.Emit(nil, func(w io.Writer) {
+		w.Write([]byte("hello"))
+	}))
+	assert.Equal(t, "hello", strings.TrimSpace(buf.String()))
+}
@@ -0,0 +1,67 @@
+// Package cli implements the unified goldbox command line interface.
+//
+// The package consolidates the functionality previously spread across the
+// individual cmd/*-demo programs into a single dispatcher with subcommands,
+// shared flag handling, and structured output. The cmd/goldbox binary is a
+// thin wrapper around Main; embedders can call the same library-level entry
+// points directly without going through flag parsing.
+//
+// # Subcommands
+//
+//	goldbox [global flags] <command> [command flags]
+//
+//	bootstrap   Generate a complete zero-configuration game
+//	dungeon     Generate a multi-level dungeon complex
+//	generate    Generate terrain, a level, a quest or a dungeon
+//	metrics     Exercise the PCG manager and report quality metrics
+//	pcg-bench   Benchmark PCG generators against performance budgets
+//	validate    Validate a JSON content file against PCG rules
+//	serve       Run the JSON-RPC game server
+//	worldgen    Generate an overworld campaign setting
+//
+// The bootstrap command prints a world sharing code for each run. Passing it,
+// or a file written with -export-profile, to -profile regenerates the same
+// world.
+//
+// The generate command takes the kind of content as its first argument:
+//
+//	goldbox -format json generate terrain -biome swamp -width 60 -height 30
+//	goldbox generate level -theme undead -min-rooms 6
+//	goldbox generate level -constraint count:shop:1:1 -constraint farthest:boss:entrance
+//	goldbox generate quest -type escort -player-level 8
+//	goldbox generate dungeon -levels 3
+//
+// Levels take any number of -constraint flags: count:<type>:<min>[:<max>],
+// farthest:<type>:<other> and adjacent:<type>:<other>[:<n>]. The generator
+// repairs the room layout to meet them, or fails naming each constraint it
+// could not meet.
+//
+// The same seed always yields the same content, so generation can be
+// scripted and its output diffed.
+//
+// # Global Flags
+//
+//	-format string   Output format: text or json (default "text")
+//	-verbose         Enable debug logging
+//
+// # Output Modes
+//
+// Every subcommand produces a result value. In text mode the result is
+// rendered as a human-readable summary; in json mode the result is encoded
+// as a single JSON document on stdout so automation can consume it.
+//
+// # Library Usage
+//
+// Each subcommand is backed by an exported function taking an options
+// struct, for example:
+//
+//	result, err := cli.GenerateDungeon(ctx, cli.DefaultDungeonOptions())
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(result.Dungeon.Name)
+//
+// Run can be used to execute a full command line in-process:
+//
+//	code := cli.Run(ctx, []string{"-format", "json", "worldgen"}, os.Stdout, os.Stderr)
+package cli
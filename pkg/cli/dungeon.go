@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DungeonOptions configures multi-level dungeon generation.
+type DungeonOptions struct {
+	Seed          int64
+	Difficulty    int
+	PlayerLevel   int
+	LevelCount    int
+	LevelWidth    int
+	LevelHeight   int
+	RoomsPerLevel int
+	Theme         pcg.LevelTheme
+	Connectivity  pcg.ConnectivityLevel
+	Density       float64
+	Timeout       time.Duration
+	Logger        *logrus.Logger
+}
+
+// DungeonResult holds a generated dungeon and a compact summary of it.
+type DungeonResult struct {
+	Summary  DungeonSummary      `json:"summary"`
+	Dungeon  *pcg.DungeonComplex `json:"dungeon,omitempty"`
+	Duration time.Duration       `json:"duration_ns"`
+}
+
+// DungeonSummary condenses a dungeon complex for reporting.
+type DungeonSummary struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Seed        int64                `json:"seed"`
+	Theme       pcg.LevelTheme       `json:"theme"`
+	Connections int                  `json:"connections"`
+	Levels      []DungeonLevelReport `json:"levels"`
+}
+
+// DungeonLevelReport summarizes one level of a dungeon complex.
+type DungeonLevelReport struct {
+	Level      int                  `json:"level"`
+	Difficulty int                  `json:"difficulty"`
+	Rooms      int                  `json:"rooms"`
+	RoomTypes  map[pcg.RoomType]int `json:"room_types"`
+}
+
+// DefaultDungeonOptions returns the settings used by the original dungeon demo.
+func DefaultDungeonOptions() DungeonOptions {
+	return DungeonOptions{
+		Seed:          12345,
+		Difficulty:    2,
+		PlayerLevel:   3,
+		LevelCount:    3,
+		LevelWidth:    40,
+		LevelHeight:   30,
+		RoomsPerLevel: 6,
+		Theme:         pcg.ThemeClassic,
+		Connectivity:  pcg.ConnectivityModerate,
+		Density:       0.6,
+		Timeout:       30 * time.Second,
+	}
+}
+
+// GenerateDungeon builds a dungeon complex from opts.
+func GenerateDungeon(ctx context.Context, opts DungeonOptions) (*DungeonResult, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+	}
+
+	world := game.NewWorld()
+	base := pcg.GenerationParams{
+		Seed:        opts.Seed,
+		Difficulty:  opts.Difficulty,
+		PlayerLevel: opts.PlayerLevel,
+		WorldState:  world,
+		Timeout:     opts.Timeout,
+		Constraints: make(map[string]interface{}),
+	}
+
+	params := base
+	params.Constraints = map[string]interface{}{
+		"dungeon_params": pcg.DungeonParams{
+			GenerationParams: base,
+			LevelCount:       opts.LevelCount,
+			LevelWidth:       opts.LevelWidth,
+			LevelHeight:      opts.LevelHeight,
+			RoomsPerLevel:    opts.RoomsPerLevel,
+			Theme:            opts.Theme,
+			Connectivity:     opts.Connectivity,
+			Density:          opts.Density,
+			Difficulty: pcg.DifficultyProgression{
+				BaseDifficulty:  opts.Difficulty,
+				ScalingFactor:   1.5,
+				MaxDifficulty:   10,
+				ProgressionType: "linear",
+			},
+		},
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	generated, err := pcg.NewDungeonGenerator(logger).Generate(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("dungeon generation failed: %w", err)
+	}
+	duration := time.Since(start)
+
+	dungeon, ok := generated.(*pcg.DungeonComplex)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: expected *pcg.DungeonComplex, got %T", generated)
+	}
+
+	return &DungeonResult{
+		Summary:  summarizeDungeon(dungeon, opts.Seed),
+		Dungeon:  dungeon,
+		Duration: duration,
+	}, nil
+}
+
+// summarizeDungeon produces a DungeonSummary with levels in ascending order.
+func summarizeDungeon(dungeon *pcg.DungeonComplex, seed int64) DungeonSummary {
+	summary := DungeonSummary{
+		ID:          dungeon.ID,
+		Name:        dungeon.Name,
+		Seed:        seed,
+		Theme:       dungeon.Theme,
+		Connections: len(dungeon.Connections),
+		Levels:      make([]DungeonLevelReport, 0, len(dungeon.Levels)),
+	}
+
+	for _, level := range dungeon.Levels {
+		report := DungeonLevelReport{
+			Level:      level.Level,
+			Difficulty: level.Difficulty,
+			Rooms:      len(level.Rooms),
+			RoomTypes:  make(map[pcg.RoomType]int),
+		}
+		for _, room := range level.Rooms {
+			report.RoomTypes[room.Type]++
+		}
+		summary.Levels = append(summary.Levels, report)
+	}
+
+	sort.Slice(summary.Levels, func(i, j int) bool {
+		return summary.Levels[i].Level < summary.Levels[j].Level
+	})
+	return summary
+}
+
+// runDungeon implements the dungeon subcommand.
+func runDungeon(ctx context.Context, env *Env, args []string) error {
+	opts := DefaultDungeonOptions()
+	var theme, connectivity string
+	var full bool
+
+	fs := newFlagSet(env, "dungeon")
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "Seed for reproducible generation")
+	fs.IntVar(&opts.Difficulty, "difficulty", opts.Difficulty, "Base difficulty (1-10)")
+	fs.IntVar(&opts.PlayerLevel, "player-level", opts.PlayerLevel, "Average party level (1-20)")
+	fs.IntVar(&opts.LevelCount, "levels", opts.LevelCount, "Number of dungeon levels")
+	fs.IntVar(&opts.LevelWidth, "width", opts.LevelWidth, "Level width in tiles")
+	fs.IntVar(&opts.LevelHeight, "height", opts.LevelHeight, "Level height in tiles")
+	fs.IntVar(&opts.RoomsPerLevel, "rooms", opts.RoomsPerLevel, "Target rooms per level")
+	fs.StringVar(&theme, "theme", string(opts.Theme), "Level theme (classic, horror, natural, mechanical, ...)")
+	fs.StringVar(&connectivity, "connectivity", string(opts.Connectivity), "Connectivity: low, moderate, high, complete")
+	fs.Float64Var(&opts.Density, "density", opts.Density, "Feature density (0.0-1.0)")
+	fs.DurationVar(&opts.Timeout, "timeout", opts.Timeout, "Maximum generation time")
+	fs.BoolVar(&full, "full", false, "Include the full dungeon structure in JSON output")
+	if err := parseFlags(fs, args, false); err != nil {
+		return err
+	}
+
+	opts.Theme = pcg.LevelTheme(theme)
+	opts.Connectivity = pcg.ConnectivityLevel(connectivity)
+	opts.Logger = env.Logger
+
+	result, err := GenerateDungeon(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if !full {
+		result.Dungeon = nil
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		s := result.Summary
+		fmt.Fprintf(w, "Dungeon: %s (ID: %s)\n", s.Name, s.ID)
+		fmt.Fprintf(w, "  Seed:        %d\n", s.Seed)
+		fmt.Fprintf(w, "  Theme:       %s\n", s.Theme)
+		fmt.Fprintf(w, "  Levels:      %d\n", len(s.Levels))
+		fmt.Fprintf(w, "  Connections: %d\n", s.Connections)
+		fmt.Fprintf(w, "  Duration:    %v\n", result.Duration)
+		for _, level := range s.Levels {
+			fmt.Fprintf(w, "  Level %d: %d rooms, difficulty %d\n", level.Level, level.Rooms, level.Difficulty)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func smallDungeonOptions() DungeonOptions {
+	opts := DefaultDungeonOptions()
+	opts.LevelCount = 2
+	opts.LevelWidth = 30
+	opts.LevelHeight = 25
+	opts.RoomsPerLevel = 3
+	return opts
+}
+
+func TestGenerateDungeon(t *testing.T) {
+	result, err := GenerateDungeon(context.Background(), smallDungeonOptions())
+	require.NoError(t, err)
+	require.NotNil(t, result.Dungeon)
+
+	assert.Equal(t, int64(12345), result.Summary.Seed)
+	require.Len(t, result.Summary.Levels, 2)
+	assert.Equal(t, 1, result.Summary.Levels[0].Level)
+	assert.Equal(t, 2, result.Summary.Levels[1].Level)
+	for _, level := range result.Summary.Levels {
+		assert.Equal(t, level.Rooms, len(result.Dungeon.Levels[level.Level].Rooms))
+	}
+}
+
+func TestDungeonCommandJSON(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "-format", "json", "dungeon", "-levels", "2", "-rooms", "3", "-width", "30", "-height", "25")
+	require.Equal(t, ExitOK, code, stderr)
+
+	var result DungeonResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Len(t, result.Summary.Levels, 2)
+	assert.Nil(t, result.Dungeon, "full dungeon omitted without -full")
+}
+
+func TestDungeonCommandText(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "dungeon", "-levels", "2", "-rooms", "3", "-width", "30", "-height", "25")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, "Dungeon:")
+	assert.Contains(t, stdout, "Level 1:")
+}
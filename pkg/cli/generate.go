@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Content kinds produced by the generate subcommand. Dungeons are generated
+// by GenerateDungeon.
+const (
+	KindTerrain = "terrain" // Biome terrain map
+	KindLevel   = "level"   // Room and corridor dungeon level
+	KindQuest   = "quest"   // Quest with objectives and rewards
+	KindDungeon = "dungeon" // Multi-level dungeon complex
+)
+
+// GenerateOptions configures the generation of a single piece of content.
+// Fields that do not apply to Kind are ignored.
+type GenerateOptions struct {
+	Kind        string
+	Seed        int64
+	Difficulty  int
+	PlayerLevel int
+	Width       int                    // Terrain width in tiles
+	Height      int                    // Terrain height in tiles
+	Biome       pcg.BiomeType          // Terrain biome
+	MinRooms    int                    // Fewest rooms of a level
+	MaxRooms    int                    // Most rooms of a level
+	Theme       pcg.LevelTheme         // Level theme
+	Constraints []pcg.LayoutConstraint // Layout constraints on a level's rooms
+	QuestType   pcg.QuestType          // Quest type
+	Timeout     time.Duration
+	Logger      *logrus.Logger
+}
+
+// GenerateResult holds a generated piece of content. Content is a
+// *game.GameMap for terrain, a *game.Level for levels and a *game.Quest for
+// quests.
+type GenerateResult struct {
+	Kind     string        `json:"kind"`
+	Seed     int64         `json:"seed"`
+	Content  interface{}   `json:"content"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// DefaultGenerateOptions returns the settings of the generate subcommand
+// for kind.
+func DefaultGenerateOptions(kind string) GenerateOptions {
+	return GenerateOptions{
+		Kind:        kind,
+		Seed:        12345,
+		Difficulty:  5,
+		PlayerLevel: 5,
+		Width:       40,
+		Height:      20,
+		Biome:       pcg.BiomeCave,
+		MinRooms:    4,
+		MaxRooms:    8,
+		Theme:       pcg.ThemeClassic,
+		QuestType:   pcg.QuestTypeKill,
+		Timeout:     30 * time.Second,
+	}
+}
+
+// GenerateContent generates one piece of terrain, a level or a quest with a
+// PCG manager seeded from opts.Seed.
+func GenerateContent(ctx context.Context, opts GenerateOptions) (*GenerateResult, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+	}
+
+	manager, err := NewPCGManager(game.NewWorld(), logger, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	var content interface{}
+	switch opts.Kind {
+	case KindTerrain:
+		content, err = manager.GenerateTerrainForLevel(ctx, "generated_terrain", opts.Width, opts.Height, opts.Biome, opts.Difficulty)
+	case KindLevel:
+		content, err = manager.GenerateDungeonLevel(ctx, "generated_level", opts.MinRooms, opts.MaxRooms, opts.Theme, opts.Difficulty, opts.Constraints...)
+	case KindQuest:
+		content, err = manager.GenerateQuestForArea(ctx, "generated_area", opts.QuestType, opts.PlayerLevel)
+	default:
+		return nil, fmt.Errorf("%w: unknown content kind %q", ErrUsage, opts.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s generation failed: %w", opts.Kind, err)
+	}
+
+	return &GenerateResult{
+		Kind:     opts.Kind,
+		Seed:     opts.Seed,
+		Content:  content,
+		Duration: time.Since(start),
+	}, nil
+}
+
+// generateKinds lists the kinds accepted by the generate subcommand
+var generateKinds = []struct {
+	name    string
+	summary string
+}{
+	{KindDungeon, "Multi-level dungeon complex (same flags as the dungeon command)"},
+	{KindLevel, "Room and corridor dungeon level"},
+	{KindQuest, "Quest with objectives and rewards"},
+	{KindTerrain, "Biome terrain map"},
+}
+
+// printGenerateUsage writes the help text of the generate subcommand
+func printGenerateUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: goldbox generate <kind> [flags]")
+	fmt.Fprintln(w, "\nKinds:")
+	for _, kind := range generateKinds {
+		fmt.Fprintf(w, "  %-10s %s\n", kind.name, kind.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'goldbox generate <kind> -h' for kind-specific flags.")
+}
+
+// runGenerate implements the generate subcommand.
+func runGenerate(ctx context.Context, env *Env, args []string) error {
+	if len(args) == 0 {
+		printGenerateUsage(env.Stderr)
+		return fmt.Errorf("%w: generate needs a content kind", ErrUsage)
+	}
+	switch args[0] {
+	case "-h", "-help", "--help":
+		printGenerateUsage(env.Stderr)
+		return flag.ErrHelp
+	case KindDungeon:
+		return runDungeon(ctx, env, args[1:])
+	case KindTerrain, KindLevel, KindQuest:
+	default:
+		printGenerateUsage(env.Stderr)
+		return fmt.Errorf("%w: unknown content kind %q", ErrUsage, args[0])
+	}
+
+	opts := DefaultGenerateOptions(args[0])
+	var biome, theme, questType string
+	fs := newFlagSet(env, "generate "+opts.Kind)
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "Seed for reproducible generation")
+	fs.IntVar(&opts.Difficulty, "difficulty", opts.Difficulty, "Difficulty (1-20)")
+	fs.DurationVar(&opts.Timeout, "timeout", opts.Timeout, "Maximum generation time")
+	switch opts.Kind {
+	case KindTerrain:
+		fs.IntVar(&opts.Width, "width", opts.Width, "Width in tiles")
+		fs.IntVar(&opts.Height, "height", opts.Height, "Height in tiles")
+		fs.StringVar(&biome, "biome", string(opts.Biome), "Biome (cave, dungeon, forest, mountain, swamp, desert, ...)")
+	case KindLevel:
+		fs.IntVar(&opts.MinRooms, "min-rooms", opts.MinRooms, "Fewest rooms")
+		fs.IntVar(&opts.MaxRooms, "max-rooms", opts.MaxRooms, "Most rooms")
+		fs.StringVar(&theme, "theme", string(opts.Theme), "Level theme (classic, horror, natural, undead, ...)")
+		fs.Func("constraint", "Layout constraint, repeatable (count:shop:1:1, farthest:boss:entrance, adjacent:secret:treasure:2)", func(value string) error {
+			constraint, err := pcg.ParseLayoutConstraint(value)
+			if err != nil {
+				return err
+			}
+			opts.Constraints = append(opts.Constraints, constraint)
+			return nil
+		})
+	case KindQuest:
+		fs.IntVar(&opts.PlayerLevel, "player-level", opts.PlayerLevel, "Level of the questing party (1-20)")
+		fs.StringVar(&questType, "type", string(opts.QuestType), "Quest type (fetch, kill, escort, explore, ...)")
+	}
+	if err := parseFlags(fs, args[1:], false); err != nil {
+		return err
+	}
+	if biome != "" {
+		opts.Biome = pcg.BiomeType(biome)
+	}
+	if theme != "" {
+		opts.Theme = pcg.LevelTheme(theme)
+	}
+	if questType != "" {
+		opts.QuestType = pcg.QuestType(questType)
+	}
+	opts.Logger = env.Logger
+
+	result, err := GenerateContent(ctx, opts)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%s generation timed out after %v", opts.Kind, opts.Timeout)
+		}
+		return err
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		switch content := result.Content.(type) {
+		case *game.GameMap:
+			fmt.Fprintf(w, "Terrain: %dx%d %s (seed %d, %v)\n", content.Width, content.Height, opts.Biome, result.Seed, result.Duration)
+			for _, row := range content.Tiles {
+				var line strings.Builder
+				for _, tile := range row {
+					line.WriteByte(terrainGlyph(tile.Walkable))
+				}
+				fmt.Fprintln(w, line.String())
+			}
+		case *game.Level:
+			fmt.Fprintf(w, "Level: %s (%dx%d, seed %d, %v)\n", content.Name, content.Width, content.Height, result.Seed, result.Duration)
+			if report, ok := content.Properties["constraints"].(pcg.ConstraintReport); ok {
+				for _, constraint := range report.Results {
+					fmt.Fprintf(w, "  Constraint met: %s (%s)\n", constraint.Constraint, constraint.Detail)
+				}
+				for _, repair := range report.Repairs {
+					fmt.Fprintf(w, "  Repaired: %s\n", repair)
+				}
+			}
+			for _, row := range content.Tiles {
+				var line strings.Builder
+				for _, tile := range row {
+					line.WriteByte(terrainGlyph(tile.Walkable))
+				}
+				fmt.Fprintln(w, line.String())
+			}
+		case *game.Quest:
+			fmt.Fprintf(w, "Quest: %s (ID: %s, seed %d, %v)\n", content.Title, content.ID, result.Seed, result.Duration)
+			fmt.Fprintf(w, "  %s\n", content.Description)
+			for _, objective := range content.Objectives {
+				fmt.Fprintf(w, "  - %s\n", objective.Description)
+			}
+			for _, reward := range content.Rewards {
+				fmt.Fprintf(w, "  Reward: %s %d\n", reward.Type, reward.Value)
+			}
+		}
+	})
+}
+
+// terrainGlyph returns the map character of a walkable or solid tile
+func terrainGlyph(walkable bool) byte {
+	if walkable {
+		return '.'
+	}
+	return '#'
+}
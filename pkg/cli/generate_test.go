@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateContent(t *testing.T) {
+	opts := DefaultGenerateOptions(KindTerrain)
+	opts.Width, opts.Height = 30, 15
+	first, err := GenerateContent(context.Background(), opts)
+	require.NoError(t, err)
+	second, err := GenerateContent(context.Background(), opts)
+	require.NoError(t, err)
+
+	terrain, ok := first.Content.(*game.GameMap)
+	require.True(t, ok, "terrain is a *game.GameMap")
+	assert.Equal(t, 30, terrain.Width)
+	assert.Equal(t, 15, terrain.Height)
+	assert.Equal(t, terrain.Tiles, second.Content.(*game.GameMap).Tiles, "the same seed yields the same terrain")
+
+	_, err = GenerateContent(context.Background(), DefaultGenerateOptions("castle"))
+	assert.ErrorIs(t, err, ErrUsage)
+}
+
+func TestGenerateCommandJSON(t *testing.T) {
+	tests := []struct {
+		kind string
+		args []string
+		key  string
+	}{
+		{KindTerrain, []string{"-width", "30", "-height", "15"}, "tiles"},
+		{KindLevel, []string{"-min-rooms", "3", "-max-rooms", "5"}, "Tiles"},
+		{KindQuest, []string{"-type", "fetch"}, "Objectives"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			args := append([]string{"-format", "json", "generate", tt.kind}, tt.args...)
+			code, stdout, stderr := runCLI(t, args...)
+			require.Equal(t, ExitOK, code, stderr)
+
+			var result struct {
+				Kind    string                     `json:"kind"`
+				Seed    int64                      `json:"seed"`
+				Content map[string]json.RawMessage `json:"content"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+			assert.Equal(t, tt.kind, result.Kind)
+			assert.Equal(t, int64(12345), result.Seed)
+			assert.Contains(t, result.Content, tt.key)
+		})
+	}
+}
+
+func TestGenerateCommandText(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "generate", "terrain", "-width", "30", "-height", "15")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, "Terrain: 30x15 cave")
+	assert.Contains(t, stdout, "#")
+
+	code, stdout, stderr = runCLI(t, "generate", "quest", "-type", "kill")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, "quest_kill_", "the quest has the requested type")
+
+	code, stdout, stderr = runCLI(t, "generate", "dungeon", "-levels", "2", "-rooms", "3", "-width", "30", "-height", "25")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, "Dungeon:")
+}
+
+func TestGenerateCommandUsage(t *testing.T) {
+	code, _, stderr := runCLI(t, "generate")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "Usage: goldbox generate <kind>")
+
+	code, _, stderr = runCLI(t, "generate", "castle")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, `unknown content kind "castle"`)
+
+	code, _, stderr = runCLI(t, "generate", "quest", "-width", "10")
+	assert.Equal(t, ExitUsage, code, "terrain flags are not accepted for quests")
+	assert.Contains(t, stderr, "flag provided but not defined")
+}
+
+func TestGenerateCommandConstraints(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "generate", "level", "-min-rooms", "10", "-max-rooms", "12",
+		"-constraint", "count:shop:1:1", "-constraint", "farthest:boss:entrance")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, "Constraint met: exactly 1 shop room")
+	assert.Contains(t, stdout, "Constraint met: boss room farthest from the entrance room")
+	assert.Contains(t, stdout, "Repaired: ")
+
+	code, _, stderr = runCLI(t, "generate", "level", "-constraint", "count:exit:2")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "exit rooms are fixed by the layout")
+
+	code, _, stderr = runCLI(t, "generate", "level", "-min-rooms", "4", "-max-rooms", "4",
+		"-constraint", "count:shop:3", "-constraint", "adjacent:secret:treasure:3")
+	assert.NotEqual(t, ExitOK, code)
+	assert.Contains(t, stderr, "layout constraints violated")
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"goldbox-rpg/pkg/pcg"
+)
+
+// HeatmapOptions configures difficulty heatmap analysis of a generated
+// dungeon or overworld.
+type HeatmapOptions struct {
+	Source  string
+	Dungeon DungeonOptions
+	World   WorldOptions
+}
+
+// DefaultHeatmapOptions analyses the dungeon of DefaultDungeonOptions.
+func DefaultHeatmapOptions() HeatmapOptions {
+	return HeatmapOptions{
+		Source:  pcg.HeatmapSourceDungeon,
+		Dungeon: DefaultDungeonOptions(),
+		World:   DefaultWorldOptions(),
+	}
+}
+
+// Heatmap generates the dungeon or overworld described by opts and
+// returns its difficulty heatmap.
+func Heatmap(ctx context.Context, opts HeatmapOptions) (*pcg.DifficultyHeatmap, error) {
+	switch opts.Source {
+	case pcg.HeatmapSourceDungeon:
+		result, err := GenerateDungeon(ctx, opts.Dungeon)
+		if err != nil {
+			return nil, err
+		}
+		return pcg.DungeonHeatmap(result.Dungeon), nil
+	case pcg.HeatmapSourceWorld:
+		result, err := GenerateWorld(ctx, opts.World)
+		if err != nil {
+			return nil, err
+		}
+		return pcg.WorldHeatmap(result.World), nil
+	default:
+		return nil, fmt.Errorf("unknown heatmap source %q", opts.Source)
+	}
+}
+
+// runHeatmap implements the heatmap subcommand.
+func runHeatmap(ctx context.Context, env *Env, args []string) error {
+	opts := DefaultHeatmapOptions()
+	var seed int64
+	var difficulty int
+	var theme string
+
+	fs := newFlagSet(env, "heatmap")
+	fs.StringVar(&opts.Source, "source", opts.Source, "Content to analyse: dungeon or world")
+	fs.Int64Var(&seed, "seed", opts.Dungeon.Seed, "Seed for reproducible generation")
+	fs.IntVar(&difficulty, "difficulty", opts.Dungeon.Difficulty, "Base dungeon difficulty or world danger level")
+	fs.IntVar(&opts.Dungeon.LevelCount, "levels", opts.Dungeon.LevelCount, "Number of dungeon levels")
+	fs.IntVar(&opts.Dungeon.RoomsPerLevel, "rooms", opts.Dungeon.RoomsPerLevel, "Target rooms per dungeon level")
+	fs.StringVar(&theme, "theme", string(opts.Dungeon.Theme), "Dungeon level theme")
+	fs.IntVar(&opts.World.Regions, "regions", opts.World.Regions, "Number of world regions")
+	if err := parseFlags(fs, args, false); err != nil {
+		return err
+	}
+	if opts.Source != pcg.HeatmapSourceDungeon && opts.Source != pcg.HeatmapSourceWorld {
+		return fmt.Errorf("%w: -source must be dungeon or world", ErrUsage)
+	}
+
+	opts.Dungeon.Seed, opts.World.Seed = seed, seed
+	opts.Dungeon.Difficulty, opts.World.DangerLevel = difficulty, difficulty
+	opts.Dungeon.Theme = pcg.LevelTheme(theme)
+	opts.Dungeon.Logger, opts.World.Logger = env.Logger, env.Logger
+
+	heatmap, err := Heatmap(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return env.Emit(heatmap, func(w io.Writer) {
+		fmt.Fprintf(w, "Difficulty heatmap: %s %s\n", heatmap.Source, heatmap.ID)
+		fmt.Fprintf(w, "  %-24s %5s %10s %6s %8s %6s\n", "Region", "Level", "Difficulty", "Budget", "Traps/100", "Loot")
+		for _, region := range heatmap.Regions {
+			fmt.Fprintf(w, "  %-24s %5d %10d %6d %8.1f %6d\n",
+				region.ID, region.Level, region.Difficulty, region.EncounterBudget, region.TrapDensity, region.LootValue)
+		}
+		p := heatmap.Pacing
+		fmt.Fprintf(w, "  Pacing: score %.2f, %d steps, mean step %.1f, max jump %.1f, %d spikes, %d regressions\n",
+			p.Score, p.Steps, p.MeanStep, p.MaxJump, p.Spikes, p.Regressions)
+	})
+}
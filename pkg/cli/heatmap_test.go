@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeatmapDungeon(t *testing.T) {
+	opts := DefaultHeatmapOptions()
+	opts.Dungeon = smallDungeonOptions()
+
+	heatmap, err := Heatmap(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, pcg.HeatmapSourceDungeon, heatmap.Source)
+	assert.NotEmpty(t, heatmap.Regions)
+	assert.Len(t, heatmap.Pacing.LevelCurve, 2)
+}
+
+func TestHeatmapCommandJSON(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "-format", "json", "heatmap", "-source", "world", "-regions", "4")
+	require.Equal(t, ExitOK, code, stderr)
+
+	var heatmap pcg.DifficultyHeatmap
+	require.NoError(t, json.Unmarshal([]byte(stdout), &heatmap))
+	assert.Equal(t, pcg.HeatmapSourceWorld, heatmap.Source)
+	assert.Len(t, heatmap.Regions, 4)
+}
+
+func TestHeatmapCommandText(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "heatmap", "-levels", "2", "-rooms", "3")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, "Difficulty heatmap: dungeon")
+	assert.Contains(t, stdout, "Pacing: score")
+}
+
+func TestHeatmapCommandRejectsUnknownSource(t *testing.T) {
+	code, _, _ := runCLI(t, "heatmap", "-source", "cave")
+	assert.Equal(t, ExitUsage, code)
+}
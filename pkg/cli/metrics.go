@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/items"
+	"goldbox-rpg/pkg/pcg/levels"
+	"goldbox-rpg/pkg/pcg/quests"
+	"goldbox-rpg/pkg/pcg/terrain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MetricsOptions configures the metrics subcommand workload.
+type MetricsOptions struct {
+	Seed        int64
+	NumTerrains int
+	NumItems    int
+	NumQuests   int
+	Logger      *logrus.Logger
+}
+
+// MetricsResult reports generation performance and content quality.
+type MetricsResult struct {
+	Seed             int64                          `json:"seed"`
+	TotalGenerations int64                          `json:"total_generations"`
+	ContentTypes     map[pcg.ContentType]TypeReport `json:"content_types"`
+	QualityReport    *pcg.QualityReport             `json:"quality_report"`
+}
+
+// TypeReport summarizes generation metrics for one content type.
+type TypeReport struct {
+	Generations int64         `json:"generations"`
+	Errors      int64         `json:"errors"`
+	AverageTime time.Duration `json:"average_time_ns"`
+}
+
+// DefaultMetricsOptions returns a small workload that completes quickly.
+func DefaultMetricsOptions() MetricsOptions {
+	return MetricsOptions{
+		Seed:        12345,
+		NumTerrains: 3,
+		NumItems:    5,
+		NumQuests:   3,
+	}
+}
+
+// NewPCGManager creates a PCG manager for world with the built-in terrain,
+// level, item, and quest generators registered under the names the manager
+// expects.
+func NewPCGManager(world *game.World, logger *logrus.Logger, seed int64) (*pcg.PCGManager, error) {
+	manager := pcg.NewPCGManager(world, logger)
+	manager.InitializeWithSeed(seed)
+
+	registry := manager.GetRegistry()
+	generators := []struct {
+		name      string
+		generator pcg.Generator
+	}{
+		{"cellular_automata", terrain.NewCellularAutomataGenerator()},
+		{"room_corridor", levels.NewRoomCorridorGenerator()},
+		{"template_based", items.NewTemplateBasedGenerator()},
+		{"objective_based", quests.NewObjectiveBasedGenerator()},
+	}
+
+	for _, g := range generators {
+		if err := registry.RegisterGenerator(g.name, g.generator); err != nil {
+			return nil, fmt.Errorf("failed to register %s generator: %w", g.name, err)
+		}
+	}
+
+	return manager, nil
+}
+
+// CollectMetrics runs a generation workload through a PCG manager and
+// returns the resulting performance and quality metrics.
+func CollectMetrics(ctx context.Context, opts MetricsOptions) (*MetricsResult, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+	}
+
+	manager, err := NewPCGManager(game.NewWorld(), logger, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < opts.NumTerrains; i++ {
+		levelID := fmt.Sprintf("level_%d", i+1)
+		if _, err := manager.GenerateTerrainForLevel(ctx, levelID, 30, 30, pcg.BiomeCave, 5); err != nil {
+			return nil, fmt.Errorf("generating terrain for %s: %w", levelID, err)
+		}
+	}
+
+	for i := 0; i < opts.NumItems; i++ {
+		locationID := fmt.Sprintf("location_%d", i+1)
+		if _, err := manager.GenerateItemsForLocation(ctx, locationID, 3, pcg.RarityCommon, pcg.RarityRare, 5); err != nil {
+			return nil, fmt.Errorf("generating items for %s: %w", locationID, err)
+		}
+	}
+
+	for i := 0; i < opts.NumQuests; i++ {
+		areaID := fmt.Sprintf("area_%d", i+1)
+		if _, err := manager.GenerateQuestForArea(ctx, areaID, pcg.QuestTypeFetch, 5); err != nil {
+			return nil, fmt.Errorf("generating quest for %s: %w", areaID, err)
+		}
+	}
+
+	metrics := manager.GetMetrics()
+	result := &MetricsResult{
+		Seed:          opts.Seed,
+		ContentTypes:  make(map[pcg.ContentType]TypeReport),
+		QualityReport: manager.GenerateQualityReport(),
+	}
+
+	for _, contentType := range []pcg.ContentType{pcg.ContentTypeTerrain, pcg.ContentTypeItems, pcg.ContentTypeQuests} {
+		report := TypeReport{
+			Generations: metrics.GetGenerationCount(contentType),
+			Errors:      metrics.GetErrorCount(contentType),
+			AverageTime: metrics.GetAverageTiming(contentType),
+		}
+		result.TotalGenerations += report.Generations
+		result.ContentTypes[contentType] = report
+	}
+
+	return result, nil
+}
+
+// runMetrics implements the metrics subcommand.
+func runMetrics(ctx context.Context, env *Env, args []string) error {
+	opts := DefaultMetricsOptions()
+
+	fs := newFlagSet(env, "metrics")
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "Seed for deterministic generation")
+	fs.IntVar(&opts.NumTerrains, "terrains", opts.NumTerrains, "Number of terrain maps to generate")
+	fs.IntVar(&opts.NumItems, "items", opts.NumItems, "Number of item batches to generate")
+	fs.IntVar(&opts.NumQuests, "quests", opts.NumQuests, "Number of quests to generate")
+	if err := parseFlags(fs, args, false); err != nil {
+		return err
+	}
+	if opts.NumTerrains < 0 || opts.NumItems < 0 || opts.NumQuests < 0 {
+		return fmt.Errorf("%w: generation counts must not be negative", ErrUsage)
+	}
+	opts.Logger = env.Logger
+
+	result, err := CollectMetrics(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		fmt.Fprintf(w, "PCG metrics (seed %d)\n", result.Seed)
+		fmt.Fprintf(w, "  Total generations: %d\n", result.TotalGenerations)
+
+		types := make([]string, 0, len(result.ContentTypes))
+		for contentType := range result.ContentTypes {
+			types = append(types, string(contentType))
+		}
+		sort.Strings(types)
+		for _, name := range types {
+			report := result.ContentTypes[pcg.ContentType(name)]
+			fmt.Fprintf(w, "  %-8s %d generations, %d errors, avg %v\n", name, report.Generations, report.Errors, report.AverageTime)
+		}
+
+		if result.QualityReport != nil {
+			fmt.Fprintf(w, "  Quality score: %.2f (grade %s)\n", result.QualityReport.OverallScore, result.QualityReport.QualityGrade)
+		}
+	})
+}
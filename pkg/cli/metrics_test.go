@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPCGManagerRegistersGenerators(t *testing.T) {
+	manager, err := NewPCGManager(game.NewWorld(), nil, 7)
+	require.NoError(t, err)
+
+	generators := manager.GetRegistry().ListAllGenerators()
+	assert.Contains(t, generators[pcg.ContentTypeTerrain], "cellular_automata")
+	assert.Contains(t, generators[pcg.ContentTypeItems], "template_based")
+	assert.Contains(t, generators[pcg.ContentTypeQuests], "objective_based")
+	assert.Contains(t, generators[pcg.ContentTypeLevels], "room_corridor")
+}
+
+func TestCollectMetrics(t *testing.T) {
+	opts := MetricsOptions{Seed: 1, NumTerrains: 2, NumItems: 1, NumQuests: 1}
+	result, err := CollectMetrics(context.Background(), opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), result.ContentTypes[pcg.ContentTypeTerrain].Generations)
+	assert.Equal(t, int64(1), result.ContentTypes[pcg.ContentTypeItems].Generations)
+	assert.GreaterOrEqual(t, result.TotalGenerations, int64(3))
+	assert.NotNil(t, result.QualityReport)
+}
+
+func TestMetricsCommandRejectsNegativeCounts(t *testing.T) {
+	code, _, stderr := runCLI(t, "metrics", "-terrains", "-1")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "must not be negative")
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// OutputFormat selects how command results are rendered on stdout.
+type OutputFormat string
+
+const (
+	FormatText OutputFormat = "text" // Human-readable summary
+	FormatJSON OutputFormat = "json" // Single indented JSON document
+)
+
+// ParseOutputFormat converts a flag value into an OutputFormat.
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	switch OutputFormat(value) {
+	case FormatText, FormatJSON:
+		return OutputFormat(value), nil
+	default:
+		return "", fmt.Errorf("invalid output format %q: must be text or json", value)
+	}
+}
+
+// Emit writes a command result in the configured format. In JSON mode the
+// result value is encoded directly; in text mode the supplied renderer is
+// called with the output writer.
+func (e *Env) Emit(result interface{}, text func(w io.Writer)) error {
+	if e.Format == FormatJSON {
+		encoder := json.NewEncoder(e.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+		return nil
+	}
+
+	text(e.Stdout)
+	return nil
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/retry"
+	"goldbox-rpg/pkg/server"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ServeOptions configures the game server. Zero values fall back to the
+// environment-driven settings from config.Load.
+type ServeOptions struct {
+	Port   int
+	WebDir string
+	// SkipBootstrap disables zero-configuration generation when no game
+	// data is present in the data directory.
+	SkipBootstrap bool
+	Logger        *logrus.Logger
+	// Ready, if set, is called with the listening address once the server
+	// accepts connections.
+	Ready func(addr net.Addr)
+}
+
+// Serve runs the JSON-RPC game server until ctx is cancelled, then saves
+// state (when persistence is enabled) and shuts down gracefully.
+func Serve(ctx context.Context, opts ServeOptions) error {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if opts.Port != 0 {
+		cfg.ServerPort = opts.Port
+	}
+	if opts.WebDir != "" {
+		cfg.WebDir = opts.WebDir
+	}
+
+	if !opts.SkipBootstrap && !pcg.DetectConfigurationPresence(cfg.DataDir) {
+		logger.Info("no existing configuration detected, running zero-configuration bootstrap")
+		bootstrapConfig := pcg.DefaultBootstrapConfig()
+		bootstrapConfig.DataDirectory = cfg.DataDir
+
+		bootstrapCtx, cancel := context.WithTimeout(ctx, cfg.BootstrapTimeout)
+		_, err := pcg.NewBootstrap(bootstrapConfig, game.NewWorld(), logger).GenerateCompleteGame(bootstrapCtx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("bootstrap game generation failed: %w", err)
+		}
+	}
+
+	srv, err := server.NewRPCServer(cfg.WebDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize server: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.ServerPort))
+	if err != nil {
+		return fmt.Errorf("failed to start listener: %w", err)
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.Serve(listener)
+	}()
+	go srv.WatchConfig(ctx)
+
+	logger.WithField("address", listener.Addr()).Info("server listening")
+	if opts.Ready != nil {
+		opts.Ready(listener.Addr())
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down server")
+	case serveErr = <-errChan:
+		logger.WithError(serveErr).Error("server stopped unexpectedly")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if cfg.EnablePersistence {
+		if err := retry.FileSystemRetrier.Execute(shutdownCtx, func(ctx context.Context) error {
+			return srv.SaveState()
+		}); err != nil {
+			logger.WithError(err).Error("failed to save game state during shutdown")
+		}
+	}
+
+	if err := listener.Close(); err != nil {
+		logger.WithError(err).Debug("error closing listener")
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.WithError(err).Warn("error during server shutdown")
+	}
+	if err := srv.Close(); err != nil {
+		logger.WithError(err).Warn("error closing server resources")
+	}
+
+	return serveErr
+}
+
+// runServe implements the serve subcommand.
+func runServe(ctx context.Context, env *Env, args []string) error {
+	opts := ServeOptions{}
+
+	fs := newFlagSet(env, "serve")
+	fs.IntVar(&opts.Port, "port", 0, "Listen port (default from SERVER_PORT)")
+	fs.StringVar(&opts.WebDir, "web-dir", "", "Static web directory (default from WEB_DIR)")
+	fs.BoolVar(&opts.SkipBootstrap, "no-bootstrap", false, "Skip zero-configuration bootstrap")
+	if err := parseFlags(fs, args, false); err != nil {
+		return err
+	}
+
+	if env.Logger.GetLevel() < logrus.InfoLevel {
+		env.Logger.SetLevel(logrus.InfoLevel)
+	}
+	opts.Logger = env.Logger
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	if err := Serve(ctx, opts); err != nil {
+		return err
+	}
+	env.Logger.WithField("uptime", time.Since(start)).Info("server shutdown completed")
+	return nil
+}
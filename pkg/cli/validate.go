@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrValidationFailed is returned by the validate subcommand when content
+// fails an error or critical severity rule.
+var ErrValidationFailed = errors.New("content failed validation")
+
+// ValidateOptions configures content validation.
+type ValidateOptions struct {
+	ContentType pcg.ContentType
+	// Fix applies registered fallback handlers to failing content.
+	Fix bool
+	// RulesConfig is a YAML validator configuration selecting rule packs
+	// and adjusting rules; empty runs the built-in rules unchanged.
+	RulesConfig string
+	Logger      *logrus.Logger
+}
+
+// ValidateResult reports the outcome of validating one content document.
+type ValidateResult struct {
+	ContentType pcg.ContentType `json:"content_type"`
+	Valid       bool            `json:"valid"`
+	Results     []pcg.Result    `json:"results"`
+	Fixed       interface{}     `json:"fixed,omitempty"`
+}
+
+// decodeContent unmarshals JSON into the concrete type validated for contentType.
+func decodeContent(contentType pcg.ContentType, data []byte) (interface{}, error) {
+	var target interface{}
+	switch contentType {
+	case pcg.ContentTypeCharacters:
+		target = &game.Character{}
+	case pcg.ContentTypeQuests:
+		target = &game.Quest{}
+	case pcg.ContentTypeDungeon:
+		target = &pcg.DungeonComplex{}
+	case pcg.ContentTypeDialogue:
+		target = &pcg.GeneratedDialogue{}
+	case pcg.ContentTypeFactions:
+		target = &pcg.GeneratedFactionSystem{}
+	case pcg.ContentTypeWorld:
+		target = &pcg.GeneratedWorld{}
+	default:
+		return nil, fmt.Errorf("unsupported content type for validation: %s", contentType)
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return nil, fmt.Errorf("failed to decode %s content: %w", contentType, err)
+	}
+	return target, nil
+}
+
+// ValidateContent decodes a JSON document and runs the PCG content
+// validator over it.
+func ValidateContent(ctx context.Context, data []byte, opts ValidateOptions) (*ValidateResult, error) {
+	content, err := decodeContent(opts.ContentType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	validator := pcg.NewContentValidator(opts.Logger)
+	if opts.RulesConfig != "" {
+		config, err := pcg.LoadValidatorConfig(opts.RulesConfig)
+		if err != nil {
+			return nil, err
+		}
+		if err := validator.ApplyConfig(config); err != nil {
+			return nil, fmt.Errorf("invalid validator config: %w", err)
+		}
+	}
+
+	var results []pcg.Result
+	var fixed interface{}
+	if opts.Fix {
+		fixed, results, err = validator.ValidateAndFix(ctx, opts.ContentType, content)
+	} else {
+		results, err = validator.ValidateContent(ctx, opts.ContentType, content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	result := &ValidateResult{
+		ContentType: opts.ContentType,
+		Valid:       true,
+		Results:     results,
+		Fixed:       fixed,
+	}
+	for _, r := range results {
+		if !r.Passed && (r.Severity == pcg.SeverityError || r.Severity == pcg.SeverityCritical) {
+			result.Valid = false
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// runValidate implements the validate subcommand. The content file is the
+// single positional argument; "-" reads from stdin.
+func runValidate(ctx context.Context, env *Env, args []string) error {
+	opts := ValidateOptions{}
+	var contentType string
+
+	fs := newFlagSet(env, "validate")
+	fs.StringVar(&contentType, "type", "", "Content type: characters, quests, dungeon, dialogue, factions, world")
+	fs.BoolVar(&opts.Fix, "fix", false, "Apply fallback handlers to failing content")
+	fs.StringVar(&opts.RulesConfig, "rules", "", "YAML file enabling rule packs and adjusting rules")
+	if err := parseFlags(fs, args, true); err != nil {
+		return err
+	}
+	if contentType == "" {
+		return fmt.Errorf("%w: -type is required", ErrUsage)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected exactly one content file argument", ErrUsage)
+	}
+
+	var data []byte
+	var err error
+	if path := fs.Arg(0); path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+
+	opts.ContentType = pcg.ContentType(contentType)
+	opts.Logger = env.Logger
+
+	result, err := ValidateContent(ctx, data, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := env.Emit(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Validating %s content: %d rules checked\n", result.ContentType, len(result.Results))
+		for _, r := range result.Results {
+			if r.Passed {
+				fmt.Fprintf(w, "  PASS %s\n", r.Message)
+			} else {
+				fmt.Fprintf(w, "  FAIL %s (severity: %s)\n", r.Message, r.Severity)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+
+	if !result.Valid {
+		return ErrValidationFailed
+	}
+	return nil
+}
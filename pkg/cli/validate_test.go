@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateContentCharacter(t *testing.T) {
+	valid := []byte(`{"id":"c1","name":"Aria","strength":15,"dexterity":14,"constitution":13,"intelligence":12,"wisdom":11,"charisma":16}`)
+	result, err := ValidateContent(context.Background(), valid, ValidateOptions{ContentType: pcg.ContentTypeCharacters})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.NotEmpty(t, result.Results)
+
+	invalid := []byte(`{"id":"c2","name":"","strength":50,"dexterity":1}`)
+	result, err = ValidateContent(context.Background(), invalid, ValidateOptions{ContentType: pcg.ContentTypeCharacters, Fix: true})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.NotNil(t, result.Fixed)
+}
+
+func TestValidateContentErrors(t *testing.T) {
+	_, err := ValidateContent(context.Background(), []byte(`{}`), ValidateOptions{ContentType: pcg.ContentTypeTerrain})
+	assert.Error(t, err)
+
+	_, err = ValidateContent(context.Background(), []byte(`not json`), ValidateOptions{ContentType: pcg.ContentTypeQuests})
+	assert.Error(t, err)
+}
+
+func TestValidateCommandExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	questPath := filepath.Join(dir, "quest.json")
+	require.NoError(t, os.WriteFile(questPath, []byte(`{"id":"q1","title":"Lost","objectives":[]}`), 0o644))
+
+	code, stdout, _ := runCLI(t, "validate", "-type", "quests", questPath)
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, stdout, "FAIL")
+
+	rulesPath := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte("rules:\n  quest_has_objectives:\n    severity: warning\n"), 0o644))
+	code, _, _ = runCLI(t, "validate", "-type", "quests", "-rules", rulesPath, questPath)
+	assert.Equal(t, ExitOK, code)
+
+	code, _, stderr := runCLI(t, "validate", questPath)
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "-type is required")
+}
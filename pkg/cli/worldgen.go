@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WorldOptions configures overworld generation.
+type WorldOptions struct {
+	Seed              int64
+	Width             int
+	Height            int
+	Regions           int
+	Settlements       int
+	Landmarks         int
+	Climate           pcg.ClimateType
+	Connectivity      pcg.ConnectivityLevel
+	PopulationDensity float64
+	MagicLevel        int
+	DangerLevel       int
+	Timeout           time.Duration
+	Logger            *logrus.Logger
+}
+
+// WorldResult holds a generated overworld and summary counts.
+type WorldResult struct {
+	World       *pcg.GeneratedWorld `json:"world"`
+	Regions     int                 `json:"regions"`
+	Settlements int                 `json:"settlements"`
+	Landmarks   int                 `json:"landmarks"`
+	TravelPaths int                 `json:"travel_paths"`
+	Duration    time.Duration       `json:"duration_ns"`
+}
+
+// DefaultWorldOptions returns a medium-sized temperate world.
+func DefaultWorldOptions() WorldOptions {
+	return WorldOptions{
+		Seed:              12345,
+		Width:             100,
+		Height:            100,
+		Regions:           6,
+		Settlements:       12,
+		Landmarks:         4,
+		Climate:           pcg.ClimateTemperate,
+		Connectivity:      pcg.ConnectivityModerate,
+		PopulationDensity: 1.0,
+		MagicLevel:        5,
+		DangerLevel:       5,
+		Timeout:           30 * time.Second,
+	}
+}
+
+// GenerateWorld builds an overworld campaign setting from opts.
+func GenerateWorld(ctx context.Context, opts WorldOptions) (*WorldResult, error) {
+	base := pcg.GenerationParams{
+		Seed:        opts.Seed,
+		Difficulty:  opts.DangerLevel,
+		PlayerLevel: 1,
+		WorldState:  game.NewWorld(),
+		Timeout:     opts.Timeout,
+		Constraints: make(map[string]interface{}),
+	}
+
+	params := base
+	params.Constraints = map[string]interface{}{
+		"world_params": pcg.WorldParams{
+			GenerationParams:  base,
+			WorldWidth:        opts.Width,
+			WorldHeight:       opts.Height,
+			RegionCount:       opts.Regions,
+			SettlementCount:   opts.Settlements,
+			LandmarkCount:     opts.Landmarks,
+			Climate:           opts.Climate,
+			Connectivity:      opts.Connectivity,
+			PopulationDensity: opts.PopulationDensity,
+			MagicLevel:        opts.MagicLevel,
+			DangerLevel:       opts.DangerLevel,
+		},
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	generated, err := pcg.NewWorldGenerator(opts.Logger).Generate(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("world generation failed: %w", err)
+	}
+
+	world, ok := generated.(*pcg.GeneratedWorld)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: expected *pcg.GeneratedWorld, got %T", generated)
+	}
+
+	return &WorldResult{
+		World:       world,
+		Regions:     len(world.Regions),
+		Settlements: len(world.Settlements),
+		Landmarks:   len(world.Landmarks),
+		TravelPaths: len(world.TravelPaths),
+		Duration:    time.Since(start),
+	}, nil
+}
+
+// runWorldgen implements the worldgen subcommand.
+func runWorldgen(ctx context.Context, env *Env, args []string) error {
+	opts := DefaultWorldOptions()
+	var climate, connectivity string
+
+	fs := newFlagSet(env, "worldgen")
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "Seed for reproducible generation")
+	fs.IntVar(&opts.Width, "width", opts.Width, "World width (50-1000)")
+	fs.IntVar(&opts.Height, "height", opts.Height, "World height (50-1000)")
+	fs.IntVar(&opts.Regions, "regions", opts.Regions, "Number of regions")
+	fs.IntVar(&opts.Settlements, "settlements", opts.Settlements, "Target number of settlements")
+	fs.IntVar(&opts.Landmarks, "landmarks", opts.Landmarks, "Number of landmarks")
+	fs.StringVar(&climate, "climate", string(opts.Climate), "Climate: temperate, arctic, tropical, arid, mountain")
+	fs.StringVar(&connectivity, "connectivity", string(opts.Connectivity), "Travel route density")
+	fs.Float64Var(&opts.PopulationDensity, "population", opts.PopulationDensity, "Population density (0.1-10.0)")
+	fs.IntVar(&opts.MagicLevel, "magic", opts.MagicLevel, "Magic level (1-10)")
+	fs.IntVar(&opts.DangerLevel, "danger", opts.DangerLevel, "Danger level (1-20)")
+	fs.DurationVar(&opts.Timeout, "timeout", opts.Timeout, "Maximum generation time")
+	if err := parseFlags(fs, args, false); err != nil {
+		return err
+	}
+
+	opts.Climate = pcg.ClimateType(climate)
+	opts.Connectivity = pcg.ConnectivityLevel(connectivity)
+	opts.Logger = env.Logger
+
+	result, err := GenerateWorld(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		fmt.Fprintf(w, "World: %s (ID: %s)\n", result.World.Name, result.World.ID)
+		fmt.Fprintf(w, "  Size:         %dx%d\n", result.World.Width, result.World.Height)
+		fmt.Fprintf(w, "  Climate:      %s\n", result.World.Climate)
+		fmt.Fprintf(w, "  Regions:      %d\n", result.Regions)
+		fmt.Fprintf(w, "  Settlements:  %d\n", result.Settlements)
+		fmt.Fprintf(w, "  Landmarks:    %d\n", result.Landmarks)
+		fmt.Fprintf(w, "  Travel paths: %d\n", result.TravelPaths)
+		fmt.Fprintf(w, "  Duration:     %v\n", result.Duration)
+	})
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateWorld(t *testing.T) {
+	result, err := GenerateWorld(context.Background(), DefaultWorldOptions())
+	require.NoError(t, err)
+	require.NotNil(t, result.World)
+	assert.Equal(t, len(result.World.Regions), result.Regions)
+	assert.Equal(t, 100, result.World.Width)
+}
+
+func TestGenerateWorldInvalidOptions(t *testing.T) {
+	opts := DefaultWorldOptions()
+	opts.Width = 10
+	_, err := GenerateWorld(context.Background(), opts)
+	assert.Error(t, err)
+}
+
+func TestWorldgenCommandJSON(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "-format", "json", "worldgen", "-climate", "arctic")
+	require.Equal(t, ExitOK, code, stderr)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &decoded))
+	world := decoded["world"].(map[string]interface{})
+	assert.Equal(t, "arctic", world["climate"])
+}
+
+func TestWorldgenCommandFailure(t *testing.T) {
+	code, _, stderr := runCLI(t, "worldgen", "-width", "5")
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, stderr, "world width")
+}
@@ -4,13 +4,18 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"goldbox-rpg/pkg/logging"
 	"goldbox-rpg/pkg/retry"
 
 	"github.com/sirupsen/logrus"
@@ -26,18 +31,31 @@ type Config struct {
 	// instance is shared across goroutines. Use RLock for reads and Lock for writes.
 	mu sync.RWMutex `json:"-"`
 
+	// reload holds the subscribers notified by Reload
+	reload reloadState
+
 	// ServerPort is the port the HTTP server will listen on
 	ServerPort int `json:"server_port"`
 
 	// WebDir is the directory containing static web files
 	WebDir string `json:"web_dir"`
 
+	// ConfigFile is an optional file of KEY=value settings, named like the
+	// environment variables, read on load and on every reload. Its values
+	// take precedence over the environment so they can be changed while the
+	// server runs. Only settable through the CONFIG_FILE environment variable.
+	ConfigFile string `json:"config_file"`
+
 	// SessionTimeout is the duration after which inactive sessions expire
 	SessionTimeout time.Duration `json:"session_timeout"`
 
 	// LogLevel controls the logging verbosity (debug, info, warn, error)
 	LogLevel string `json:"log_level"`
 
+	// LogLevels overrides the level of individual logging subsystems, written
+	// as comma separated subsystem=level pairs (e.g. "pcg=warn,rpc=debug")
+	LogLevels string `json:"log_levels"`
+
 	// AllowedOrigins is a list of allowed WebSocket origins for CORS
 	AllowedOrigins []string `json:"allowed_origins"`
 
@@ -50,6 +68,19 @@ type Config struct {
 	// RequestTimeout is the maximum duration for processing requests
 	RequestTimeout time.Duration `json:"request_timeout"`
 
+	// WebSocket configuration
+
+	// WebSocketCompression offers permessage-deflate to WebSocket clients
+	WebSocketCompression bool `json:"websocket_compression"`
+
+	// WebSocketCompressionLevel is the deflate level of compressed messages,
+	// from -2 (Huffman only) to 9 (best compression)
+	WebSocketCompressionLevel int `json:"websocket_compression_level"`
+
+	// WebSocketCompressionMinSize is the smallest encoded message, in bytes,
+	// that is compressed; smaller messages are sent as they are
+	WebSocketCompressionMinSize int `json:"websocket_compression_min_size"`
+
 	// Performance monitoring configuration
 
 	// EnableProfiling enables pprof profiling endpoints (/debug/pprof)
@@ -67,6 +98,20 @@ type Config struct {
 	// AlertingInterval is how often performance alerts are checked
 	AlertingInterval time.Duration `json:"alerting_interval"`
 
+	// HealthCheckInterval is how often the component health checks run in
+	// the background to update load shedding (0 runs them only on /health)
+	HealthCheckInterval time.Duration `json:"health_check_interval"`
+
+	// LoadSheddingEnabled rejects a share of game requests while the
+	// health checks report the server degraded or unhealthy
+	LoadSheddingEnabled bool `json:"load_shedding_enabled"`
+
+	// LoadShedDegradedRatio is the share of requests rejected while degraded
+	LoadShedDegradedRatio float64 `json:"load_shed_degraded_ratio"`
+
+	// LoadShedUnhealthyRatio is the share of requests rejected while unhealthy
+	LoadShedUnhealthyRatio float64 `json:"load_shed_unhealthy_ratio"`
+
 	// Rate limiting configuration
 
 	// RateLimitEnabled enables rate limiting middleware
@@ -81,6 +126,19 @@ type Config struct {
 	// RateLimitCleanupInterval is how often to clean up expired rate limiters
 	RateLimitCleanupInterval time.Duration `json:"rate_limit_cleanup_interval"`
 
+	// SessionRateLimitEnabled enables per-session rate limiting of RPC calls,
+	// which unlike per-IP limiting does not penalize players behind a shared NAT
+	SessionRateLimitEnabled bool `json:"session_rate_limit_enabled"`
+
+	// SessionRateLimitRequestsPerSecond is the number of tokens each session regains per second
+	SessionRateLimitRequestsPerSecond float64 `json:"session_rate_limit_requests_per_second"`
+
+	// SessionRateLimitBurst is the token capacity of each session's bucket
+	SessionRateLimitBurst int `json:"session_rate_limit_burst"`
+
+	// SessionRateLimitMethodWeights overrides the number of tokens a call to an RPC method takes
+	SessionRateLimitMethodWeights map[string]int `json:"session_rate_limit_method_weights"`
+
 	// Retry configuration
 
 	// RetryEnabled enables retry logic for transient failures
@@ -112,6 +170,85 @@ type Config struct {
 	// EnablePersistence enables automatic game state persistence
 	EnablePersistence bool `json:"enable_persistence"`
 
+	// PersistenceBackend selects the storage backend: "file", "sqlite" or "memory"
+	PersistenceBackend string `json:"persistence_backend"`
+
+	// PersistenceMode selects how progress between auto-saves is kept:
+	// "snapshot" saves only the full state, "event-sourced" also appends
+	// session changes to a write-ahead log replayed on startup and saves
+	// the full state after calls that change shared world state
+	PersistenceMode string `json:"persistence_mode"`
+
+	// SQLiteDriver is the database/sql driver name used by the sqlite backend
+	SQLiteDriver string `json:"sqlite_driver"`
+
+	// SQLiteDSN is the sqlite data source name (defaults to DataDir/gamestate.db)
+	SQLiteDSN string `json:"sqlite_dsn"`
+
+	// BackupCount is how many rotated backups are kept per saved document (0 disables backups)
+	BackupCount int `json:"backup_count"`
+
+	// BackupMaxAge removes backups older than this, keeping the newest (0 keeps backups regardless of age)
+	BackupMaxAge time.Duration `json:"backup_max_age"`
+
+	// BackupInterval is the minimum time between backups of the same document
+	BackupInterval time.Duration `json:"backup_interval"`
+
+	// BackupVerifyInterval is how often stored backups are checked for corruption (0 disables checks)
+	BackupVerifyInterval time.Duration `json:"backup_verify_interval"`
+
+	// SnapshotCount is how many whole-state auto-save snapshots are kept (0 disables snapshots)
+	SnapshotCount int `json:"snapshot_count"`
+
+	// SnapshotInterval is the minimum time between auto-save snapshots
+	SnapshotInterval time.Duration `json:"snapshot_interval"`
+
+	// SnapshotCompactAfter is the age from which snapshots are thinned out (0 disables compaction)
+	SnapshotCompactAfter time.Duration `json:"snapshot_compact_after"`
+
+	// SnapshotCompactSpacing is the minimum time between snapshots kept once they are compacted
+	SnapshotCompactSpacing time.Duration `json:"snapshot_compact_spacing"`
+
+	// StatsAggregationInterval is how often campaign statistics and leaderboards are aggregated
+	StatsAggregationInterval time.Duration `json:"stats_aggregation_interval"`
+
+	// PersistenceSecret signs saved files with HMAC-SHA256 so tampering is
+	// detected on load; empty stores plain YAML. File backend only.
+	PersistenceSecret string `json:"-"`
+
+	// PersistencePreviousSecrets are retired secrets whose files can still
+	// be loaded; they are resealed with PersistenceSecret on startup
+	PersistencePreviousSecrets []string `json:"-"`
+
+	// PersistenceEncrypt also encrypts saved files with AES-256-GCM
+	PersistenceEncrypt bool `json:"persistence_encrypt"`
+
+	// PersistenceAllowUnsealed loads plain YAML files written before a
+	// secret was configured, sealing them on startup
+	PersistenceAllowUnsealed bool `json:"persistence_allow_unsealed"`
+
+	// Session store configuration
+
+	// SessionStoreBackend selects where sessions are shared: "memory" keeps
+	// them in this process, "redis" shares them between server instances
+	SessionStoreBackend string `json:"session_store_backend"`
+
+	// RedisAddr is the host:port of the Redis server used by the redis session store
+	RedisAddr string `json:"redis_addr"`
+
+	// RedisPassword authenticates with the Redis server (empty skips AUTH)
+	RedisPassword string `json:"-"`
+
+	// RedisDB is the Redis database number used by the redis session store
+	RedisDB int `json:"redis_db"`
+
+	// RedisKeyPrefix namespaces the keys and channels of the redis session store
+	RedisKeyPrefix string `json:"redis_key_prefix"`
+
+	// InstanceID identifies this server instance in session affinity
+	// metadata (empty generates a random ID at startup)
+	InstanceID string `json:"instance_id"`
+
 	// Server lifecycle timeouts
 
 	// BootstrapTimeout is the maximum duration for bootstrap game generation
@@ -122,6 +259,266 @@ type Config struct {
 
 	// ShutdownGracePeriod is the grace period after shutdown before forcing exit
 	ShutdownGracePeriod time.Duration `json:"shutdown_grace_period"`
+
+	// Webhook configuration
+
+	// WebhookURLs are the endpoints that receive outbound game event webhooks
+	WebhookURLs []string `json:"webhook_urls"`
+
+	// WebhookSecret is the HMAC-SHA256 key used to sign webhook payloads
+	WebhookSecret string `json:"-"`
+
+	// WebhookEvents selects the events delivered to webhooks (empty selects all)
+	WebhookEvents []string `json:"webhook_events"`
+
+	// WebhookQualityGrade is the lowest acceptable PCG quality grade (A-F);
+	// a quality webhook fires when the grade drops below it
+	WebhookQualityGrade string `json:"webhook_quality_grade"`
+
+	// WebhookTimeout is the maximum duration of a single delivery attempt
+	WebhookTimeout time.Duration `json:"webhook_timeout"`
+
+	// Analytics configuration
+
+	// AnalyticsEnabled streams anonymized gameplay events to the analytics
+	// sinks and feeds quest completions into the PCG quality metrics;
+	// operators opt out by setting it to false
+	AnalyticsEnabled bool `json:"analytics_enabled"`
+
+	// AnalyticsSinks selects where analytics events are exported: stdout,
+	// file and http (empty exports nothing)
+	AnalyticsSinks []string `json:"analytics_sinks"`
+
+	// AnalyticsFile is the JSON lines file written by the file sink
+	// (defaults to DataDir/analytics.jsonl)
+	AnalyticsFile string `json:"analytics_file"`
+
+	// AnalyticsURL is the endpoint the http sink POSTs event batches to
+	AnalyticsURL string `json:"analytics_url"`
+
+	// AnalyticsSalt keys the hash that replaces player IDs in events; empty
+	// picks a random salt at startup, so players cannot be followed across
+	// restarts
+	AnalyticsSalt string `json:"-"`
+
+	// AnalyticsBatchSize is the largest number of events written at once
+	AnalyticsBatchSize int `json:"analytics_batch_size"`
+
+	// AnalyticsFlushInterval is the longest time an event waits for its
+	// batch to fill before it is written
+	AnalyticsFlushInterval time.Duration `json:"analytics_flush_interval"`
+
+	// AnalyticsQueueSize bounds the events waiting to be written; events
+	// recorded while it is full are dropped
+	AnalyticsQueueSize int `json:"analytics_queue_size"`
+
+	// Admin console configuration
+
+	// AdminToken authorizes calls to the admin.* RPC methods; empty
+	// disables them
+	AdminToken string `json:"-"`
+
+	// AdminPermissions lists the admin actions the token may take: spawn,
+	// teleport, grant, generate, combat, inspect, undo, restore, config and
+	// annotate
+	AdminPermissions []string `json:"admin_permissions"`
+
+	// AdminRateLimitRequestsPerSecond is the number of admin calls allowed
+	// per second, separately from player rate limits. Calls with a wrong
+	// token have a limit of the same size of their own.
+	AdminRateLimitRequestsPerSecond float64 `json:"admin_rate_limit_requests_per_second"`
+
+	// AdminRateLimitBurst is the largest burst of admin calls
+	AdminRateLimitBurst int `json:"admin_rate_limit_burst"`
+
+	// Request audit configuration
+
+	// RequestAuditEnabled records sampled RPC requests and their responses
+	// for inspection through admin.listRequests
+	RequestAuditEnabled bool `json:"request_audit_enabled"`
+
+	// RequestAuditSampleRate is the fraction of requests recorded, between
+	// 0 and 1. Failed requests are always recorded.
+	RequestAuditSampleRate float64 `json:"request_audit_sample_rate"`
+
+	// RequestAuditMethodRates sets the sample rate per RPC method (e.g.
+	// "move"), replacing RequestAuditSampleRate
+	RequestAuditMethodRates map[string]float64 `json:"request_audit_method_rates"`
+
+	// RequestAuditBufferSize is the number of recent records kept in memory
+	RequestAuditBufferSize int `json:"request_audit_buffer_size"`
+
+	// RequestAuditRedactSessionIDs replaces session IDs in records with a
+	// stable pseudonym
+	RequestAuditRedactSessionIDs bool `json:"request_audit_redact_session_ids"`
+
+	// RequestAuditRedactPlayerNames removes the names of session players
+	// from records
+	RequestAuditRedactPlayerNames bool `json:"request_audit_redact_player_names"`
+
+	// Combat configuration
+
+	// InitiativeMode selects when combat initiative is rolled: "fixed" rolls
+	// once when combat starts, "per_round" re-rolls at every new round
+	InitiativeMode string `json:"initiative_mode"`
+
+	// Ruleset names the rules profile in data/rulesets (e.g. "adnd1e");
+	// empty selects the built-in rules
+	Ruleset string `json:"ruleset"`
+
+	// ManualLevelUp keeps the levels players earn pending until they train
+	// for them with levelUp, choosing how their hit points are gained and
+	// which new spells they learn
+	ManualLevelUp bool `json:"manual_level_up"`
+
+	// TurnTimeout is how long a combatant has to act before the turn is
+	// skipped; 0 lets turns last indefinitely
+	TurnTimeout time.Duration `json:"turn_timeout"`
+
+	// TurnWarning is the time left on a turn when the combatant is warned
+	// that it is about to be skipped; 0 sends no warning
+	TurnWarning time.Duration `json:"turn_warning"`
+
+	// AFKSkipLimit is the number of turns in a row a player may lose to the
+	// turn timer before being removed from combat; 0 never removes players
+	AFKSkipLimit int `json:"afk_skip_limit"`
+
+	// ZoneOfControl has combatants threaten the squares around them, so
+	// enemies moving out of those squares provoke opportunity attacks;
+	// disable it for simpler movement
+	ZoneOfControl bool `json:"zone_of_control"`
+
+	// MaxCompanions is the number of companions one player may hire
+	MaxCompanions int `json:"max_companions"`
+
+	// MaxPartySize caps the players of a party and their companions
+	// together; a player without a party counts as a party of one
+	MaxPartySize int `json:"max_party_size"`
+
+	// Spellcasting configuration
+
+	// StrictSpellComponents requires the specific materials spells list,
+	// using up the consumed ones, instead of letting any spell component
+	// item stand in for them
+	StrictSpellComponents bool `json:"strict_spell_components"`
+
+	// RandomEncountersEnabled rolls for random encounters as players move
+	// outside combat
+	RandomEncountersEnabled bool `json:"random_encounters_enabled"`
+
+	// EncounterCooldownSteps is the fewest steps a player takes after a
+	// random encounter before the next can occur
+	EncounterCooldownSteps int `json:"encounter_cooldown_steps"`
+
+	// WorldEventsEnabled lets the world event director start world events
+	// such as goblin raids and festivals as game time passes
+	WorldEventsEnabled bool `json:"world_events_enabled"`
+
+	// WorldEventChance is the probability that a world event starts in each
+	// six hours of game time, between 0 and 1
+	WorldEventChance float64 `json:"world_event_chance"`
+
+	// Difficulty scaling configuration
+
+	// DifficultyScalingEnabled lets player difficulty feedback adjust the
+	// difficulty of generated content
+	DifficultyScalingEnabled bool `json:"difficulty_scaling_enabled"`
+
+	// DifficultyMin is the lowest difficulty content is generated at
+	DifficultyMin int `json:"difficulty_min"`
+
+	// DifficultyMax is the highest difficulty content is generated at
+	DifficultyMax int `json:"difficulty_max"`
+
+	// DifficultyMaxAdjustment is the largest number of difficulty levels
+	// feedback may add to or remove from a requested difficulty
+	DifficultyMaxAdjustment int `json:"difficulty_max_adjustment"`
+
+	// DifficultyOverrides sets the largest adjustment per PCG content type
+	// (e.g. "quests"); 0 keeps a content type at its requested difficulty
+	DifficultyOverrides map[string]int `json:"difficulty_overrides"`
+
+	// PCG plugin configuration
+
+	// PCGPlugins lists the plugin executables whose generators are added to
+	// the PCG registry at startup
+	PCGPlugins []string `json:"pcg_plugins"`
+
+	// PCGPluginTimeout is how long a plugin generation may take when its
+	// generator declares no timeout
+	PCGPluginTimeout time.Duration `json:"pcg_plugin_timeout"`
+
+	// PCGPluginMaxTimeout caps the timeout a plugin generator may declare
+	PCGPluginMaxTimeout time.Duration `json:"pcg_plugin_max_timeout"`
+
+	// Content bundle configuration
+
+	// ContentBundles lists the .gbox bundle files, or directories of them,
+	// mounted into the world at startup
+	ContentBundles []string `json:"content_bundles"`
+
+	// ContentBundleKeys lists the base64 ed25519 public keys whose bundle
+	// signatures are trusted
+	ContentBundleKeys []string `json:"-"`
+
+	// ContentBundlesAllowUnsigned mounts bundles that carry no signature
+	ContentBundlesAllowUnsigned bool `json:"content_bundles_allow_unsigned"`
+
+	// PCGRNG is the random number generator algorithm of content
+	// generation: "math" (the default, reproducing earlier seeds), "pcg64"
+	// or "xoshiro256". Worlds keep the algorithm they were created with.
+	PCGRNG string `json:"pcg_rng"`
+
+	// PCG content cache configuration
+
+	// PCGCacheMaxBytes is the encoded size of generated content the PCG
+	// content cache may hold; 0 disables the cache
+	PCGCacheMaxBytes int64 `json:"pcg_cache_max_bytes"`
+
+	// PCGCacheTTL is how long cached content stays valid; 0 keeps it until
+	// evicted
+	PCGCacheTTL time.Duration `json:"pcg_cache_ttl"`
+
+	// PCGCacheContentTypes lists the content types that are cached (e.g.
+	// "terrain")
+	PCGCacheContentTypes []string `json:"pcg_cache_content_types"`
+
+	// PCG quality gate configuration
+
+	// PCGQualityGateEnabled generates content up to PCGQualityGateMaxAttempts
+	// times and keeps the best scoring candidate
+	PCGQualityGateEnabled bool `json:"pcg_quality_gate_enabled"`
+
+	// PCGQualityGateMaxAttempts is the number of candidates generated at
+	// most for one piece of content
+	PCGQualityGateMaxAttempts int `json:"pcg_quality_gate_max_attempts"`
+
+	// PCGQualityGateMinScore is the score between 0 and 1 that accepts a
+	// candidate without generating further ones
+	PCGQualityGateMinScore float64 `json:"pcg_quality_gate_min_score"`
+
+	// PCGQualityGateThresholds sets the accepting score per content type
+	// (e.g. "levels"), replacing PCGQualityGateMinScore
+	PCGQualityGateThresholds map[string]float64 `json:"pcg_quality_gate_thresholds"`
+
+	// Tracing configuration
+
+	// TracingExporter selects where OpenTelemetry spans go: "none" disables
+	// tracing, "stdout" prints spans and "otlp" sends them to a collector
+	TracingExporter string `json:"tracing_exporter"`
+
+	// TracingEndpoint is the host:port of the OTLP/HTTP collector; empty
+	// falls back to the standard OTEL_EXPORTER_OTLP_* variables
+	TracingEndpoint string `json:"tracing_endpoint"`
+
+	// TracingInsecure sends OTLP spans over plain HTTP
+	TracingInsecure bool `json:"tracing_insecure"`
+
+	// TracingServiceName is the service.name reported with every span
+	TracingServiceName string `json:"tracing_service_name"`
+
+	// TracingSampleRatio is the fraction of new traces recorded, 0-1
+	TracingSampleRatio float64 `json:"tracing_sample_ratio"`
 }
 
 // Load creates a new Config instance by reading from environment variables
@@ -133,17 +530,35 @@ func Load() (*Config, error) {
 		"package":  "config",
 	}).Debug("entering Load")
 
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	configFile := os.Getenv("CONFIG_FILE")
+	settings, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	fileSettings = settings
+	defer func() { fileSettings = nil }()
+
 	config := &Config{
 		// Secure defaults for production deployment
 		ServerPort:     getEnvAsInt("SERVER_PORT", 8080),
 		WebDir:         getEnvAsString("WEB_DIR", "./web"),
+		ConfigFile:     configFile,
 		SessionTimeout: getEnvAsDuration("SESSION_TIMEOUT", 30*time.Minute),
 		LogLevel:       getEnvAsString("LOG_LEVEL", "info"),
+		LogLevels:      getEnvAsString("LOG_LEVELS", ""),
 		AllowedOrigins: getEnvAsStringSlice("ALLOWED_ORIGINS", []string{}),
 		MaxRequestSize: getEnvAsInt64("MAX_REQUEST_SIZE", 1*1024*1024), // 1MB default
 		EnableDevMode:  getEnvAsBool("ENABLE_DEV_MODE", true),          // Default to dev mode for easier setup
 		RequestTimeout: getEnvAsDuration("REQUEST_TIMEOUT", 30*time.Second),
 
+		// WebSocket defaults
+		WebSocketCompression:        getEnvAsBool("WEBSOCKET_COMPRESSION", true),        // Offer permessage-deflate
+		WebSocketCompressionLevel:   getEnvAsInt("WEBSOCKET_COMPRESSION_LEVEL", 1),      // flate.BestSpeed
+		WebSocketCompressionMinSize: getEnvAsInt("WEBSOCKET_COMPRESSION_MIN_SIZE", 256), // Skip small messages
+
 		// Performance monitoring defaults
 		EnableProfiling:  getEnvAsBool("ENABLE_PROFILING", false),               // Disabled by default for security
 		ProfilingPort:    getEnvAsInt("PROFILING_PORT", 0),                      // 0 = use same port as main server
@@ -151,12 +566,24 @@ func Load() (*Config, error) {
 		AlertingEnabled:  getEnvAsBool("ALERTING_ENABLED", true),                // Enable alerting by default
 		AlertingInterval: getEnvAsDuration("ALERTING_INTERVAL", 30*time.Second), // Check alerts every 30s
 
+		// Health check and load shedding defaults
+		HealthCheckInterval:    getEnvAsDuration("HEALTH_CHECK_INTERVAL", 15*time.Second), // Probe components every 15s
+		LoadSheddingEnabled:    getEnvAsBool("LOAD_SHEDDING_ENABLED", true),               // Shed load when unwell
+		LoadShedDegradedRatio:  getEnvAsFloat64("LOAD_SHED_DEGRADED_RATIO", 0.25),         // Reject a quarter while degraded
+		LoadShedUnhealthyRatio: getEnvAsFloat64("LOAD_SHED_UNHEALTHY_RATIO", 0.9),         // Reject most while unhealthy
+
 		// Rate limiting defaults
 		RateLimitEnabled:           getEnvAsBool("RATE_LIMIT_ENABLED", false),                      // Disabled by default
 		RateLimitRequestsPerSecond: getEnvAsFloat64("RATE_LIMIT_REQUESTS_PER_SECOND", 5),           // 5 requests per second default
 		RateLimitBurst:             getEnvAsInt("RATE_LIMIT_BURST", 10),                            // 10 requests burst default
 		RateLimitCleanupInterval:   getEnvAsDuration("RATE_LIMIT_CLEANUP_INTERVAL", 1*time.Minute), // 1 minute cleanup interval
 
+		// Per-session rate limiting defaults
+		SessionRateLimitEnabled:           getEnvAsBool("SESSION_RATE_LIMIT_ENABLED", false),             // Disabled by default
+		SessionRateLimitRequestsPerSecond: getEnvAsFloat64("SESSION_RATE_LIMIT_REQUESTS_PER_SECOND", 10), // 10 tokens per second default
+		SessionRateLimitBurst:             getEnvAsInt("SESSION_RATE_LIMIT_BURST", 20),                   // 20 token bucket default
+		SessionRateLimitMethodWeights:     getEnvAsIntMap("SESSION_RATE_LIMIT_METHOD_WEIGHTS"),           // e.g. "castSpell=3,getGameState=1"
+
 		// Retry defaults
 		RetryEnabled:           getEnvAsBool("RETRY_ENABLED", true),                           // Enabled by default
 		RetryMaxAttempts:       getEnvAsInt("RETRY_MAX_ATTEMPTS", 3),                          // 3 attempts default
@@ -166,14 +593,142 @@ func Load() (*Config, error) {
 		RetryJitterPercent:     getEnvAsInt("RETRY_JITTER_PERCENT", 10),                       // 10% jitter
 
 		// Persistence defaults
-		DataDir:           getEnvAsString("DATA_DIR", "./data"),                   // ./data directory default
-		AutoSaveInterval:  getEnvAsDuration("AUTO_SAVE_INTERVAL", 30*time.Second), // 30s auto-save interval
-		EnablePersistence: getEnvAsBool("ENABLE_PERSISTENCE", true),               // Enabled by default
+		DataDir:            getEnvAsString("DATA_DIR", "./data"),                   // ./data directory default
+		AutoSaveInterval:   getEnvAsDuration("AUTO_SAVE_INTERVAL", 30*time.Second), // 30s auto-save interval
+		EnablePersistence:  getEnvAsBool("ENABLE_PERSISTENCE", true),               // Enabled by default
+		PersistenceBackend: getEnvAsString("PERSISTENCE_BACKEND", "file"),          // YAML files in DataDir
+		PersistenceMode:    getEnvAsString("GOLDBOX_PERSISTENCE_MODE", ""),         // Falls back to PERSISTENCE_MODE
+		SQLiteDriver:       getEnvAsString("SQLITE_DRIVER", "sqlite"),              // modernc.org/sqlite driver name
+		SQLiteDSN:          getEnvAsString("SQLITE_DSN", ""),                       // DataDir/gamestate.db
+
+		// Backup defaults
+		BackupCount:          getEnvAsInt("BACKUP_COUNT", 5),                          // 5 backups per document
+		BackupMaxAge:         getEnvAsDuration("BACKUP_MAX_AGE", 7*24*time.Hour),      // 7 days retention
+		BackupInterval:       getEnvAsDuration("BACKUP_INTERVAL", 10*time.Minute),     // At most one backup per 10 minutes
+		BackupVerifyInterval: getEnvAsDuration("BACKUP_VERIFY_INTERVAL", 1*time.Hour), // Hourly integrity checks
+
+		// Snapshot defaults
+		SnapshotCount:          getEnvAsInt("SNAPSHOT_COUNT", 24),                         // 24 snapshots kept
+		SnapshotInterval:       getEnvAsDuration("SNAPSHOT_INTERVAL", 15*time.Minute),     // At most one snapshot per 15 minutes
+		SnapshotCompactAfter:   getEnvAsDuration("SNAPSHOT_COMPACT_AFTER", 6*time.Hour),   // Thin out snapshots older than 6 hours
+		SnapshotCompactSpacing: getEnvAsDuration("SNAPSHOT_COMPACT_SPACING", 1*time.Hour), // to one per hour
+
+		// Campaign statistics defaults
+		StatsAggregationInterval: getEnvAsDuration("STATS_AGGREGATION_INTERVAL", time.Minute), // Leaderboards refreshed every minute
+
+		// Save sealing defaults
+		PersistenceSecret:          getEnvAsString("PERSISTENCE_SECRET", ""),                 // Plain YAML unless a secret is configured
+		PersistencePreviousSecrets: getEnvAsStringSlice("PERSISTENCE_PREVIOUS_SECRETS", nil), // No rotation in progress
+		PersistenceEncrypt:         getEnvAsBool("PERSISTENCE_ENCRYPT", false),               // Signed but readable
+		PersistenceAllowUnsealed:   getEnvAsBool("PERSISTENCE_ALLOW_UNSEALED", false),        // Unsealed files are tampering
+
+		// Session store defaults
+		SessionStoreBackend: getEnvAsString("SESSION_STORE_BACKEND", "memory"), // Sessions local to this instance
+		RedisAddr:           getEnvAsString("REDIS_ADDR", "localhost:6379"),    // Default Redis port
+		RedisPassword:       getEnvAsString("REDIS_PASSWORD", ""),              // No AUTH by default
+		RedisDB:             getEnvAsInt("REDIS_DB", 0),                        // Default database
+		RedisKeyPrefix:      getEnvAsString("REDIS_KEY_PREFIX", "goldbox:"),    // Shared by all instances of a deployment
+		InstanceID:          getEnvAsString("INSTANCE_ID", ""),                 // Random per process
 
 		// Server lifecycle timeout defaults
 		BootstrapTimeout:    getEnvAsDuration("BOOTSTRAP_TIMEOUT", 60*time.Second),    // 60s bootstrap timeout
 		ShutdownTimeout:     getEnvAsDuration("SHUTDOWN_TIMEOUT", 30*time.Second),     // 30s shutdown timeout
 		ShutdownGracePeriod: getEnvAsDuration("SHUTDOWN_GRACE_PERIOD", 1*time.Second), // 1s grace period
+
+		// Webhook defaults
+		WebhookURLs:         getEnvAsStringSlice("WEBHOOK_URLS", []string{}),     // Disabled unless URLs are configured
+		WebhookSecret:       getEnvAsString("WEBHOOK_SECRET", ""),                // Unsigned unless a secret is configured
+		WebhookEvents:       getEnvAsStringSlice("WEBHOOK_EVENTS", []string{}),   // All events by default
+		WebhookQualityGrade: getEnvAsString("WEBHOOK_QUALITY_GRADE", "C"),        // Notify when quality drops below C
+		WebhookTimeout:      getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second), // 10s per delivery attempt
+
+		// Analytics defaults
+		AnalyticsEnabled:       getEnvAsBool("ANALYTICS_ENABLED", true),                      // Opt out with false
+		AnalyticsSinks:         getEnvAsStringSlice("ANALYTICS_SINKS", []string{}),           // Nothing exported unless sinks are configured
+		AnalyticsFile:          getEnvAsString("ANALYTICS_FILE", ""),                         // DataDir/analytics.jsonl
+		AnalyticsURL:           getEnvAsString("ANALYTICS_URL", ""),                          // Needed by the http sink
+		AnalyticsSalt:          getEnvAsString("ANALYTICS_SALT", ""),                         // Random per run
+		AnalyticsBatchSize:     getEnvAsInt("ANALYTICS_BATCH_SIZE", 100),                     // Up to 100 events per write
+		AnalyticsFlushInterval: getEnvAsDuration("ANALYTICS_FLUSH_INTERVAL", 10*time.Second), // Partial batches written every 10s
+		AnalyticsQueueSize:     getEnvAsInt("ANALYTICS_QUEUE_SIZE", 1024),                    // 1024 pending events
+
+		// Admin console defaults
+		AdminToken:                      getEnvAsString("ADMIN_TOKEN", ""),                                            // Admin methods disabled
+		AdminPermissions:                getEnvAsStringSlice("ADMIN_PERMISSIONS", slices.Clone(adminPermissionNames)), // Every admin action
+		AdminRateLimitRequestsPerSecond: getEnvAsFloat64("ADMIN_RATE_LIMIT_REQUESTS_PER_SECOND", 2),                   // 2 calls per second
+		AdminRateLimitBurst:             getEnvAsInt("ADMIN_RATE_LIMIT_BURST", 10),                                    // Bursts of 10 calls
+
+		// Request audit defaults
+		RequestAuditEnabled:           getEnvAsBool("REQUEST_AUDIT_ENABLED", true),             // Recent requests kept for admins
+		RequestAuditSampleRate:        getEnvAsFloat64("REQUEST_AUDIT_SAMPLE_RATE", 0.1),       // One request in ten
+		RequestAuditMethodRates:       getEnvAsFloat64Map("REQUEST_AUDIT_METHOD_RATES"),        // e.g. "move=0.01,joinGame=1"
+		RequestAuditBufferSize:        getEnvAsInt("REQUEST_AUDIT_BUFFER_SIZE", 500),           // Last 500 records
+		RequestAuditRedactSessionIDs:  getEnvAsBool("REQUEST_AUDIT_REDACT_SESSION_IDS", true),  // Pseudonymous sessions
+		RequestAuditRedactPlayerNames: getEnvAsBool("REQUEST_AUDIT_REDACT_PLAYER_NAMES", true), // No player names
+
+		// Combat defaults
+		InitiativeMode: getEnvAsString("INITIATIVE_MODE", "fixed"),       // Roll initiative once per combat
+		Ruleset:        getEnvAsString("RULESET", ""),                    // Built-in rules
+		ManualLevelUp:  getEnvAsBool("MANUAL_LEVEL_UP", false),           // Level up as soon as earned
+		TurnTimeout:    getEnvAsDuration("TURN_TIMEOUT", 60*time.Second), // A minute per turn
+		TurnWarning:    getEnvAsDuration("TURN_WARNING", 15*time.Second), // Warned 15s before the skip
+		AFKSkipLimit:   getEnvAsInt("AFK_SKIP_LIMIT", 3),                 // Removed after 3 skipped turns
+		ZoneOfControl:  getEnvAsBool("ZONE_OF_CONTROL", true),            // Leaving a threatened square provokes
+		MaxCompanions:  getEnvAsInt("MAX_COMPANIONS", 2),                 // Two henchmen per player
+		MaxPartySize:   getEnvAsInt("MAX_PARTY_SIZE", 8),                 // Six heroes and two henchmen
+
+		// Spellcasting defaults
+		StrictSpellComponents: getEnvAsBool("STRICT_SPELL_COMPONENTS", false), // Any component item serves
+
+		// Random encounter defaults
+		RandomEncountersEnabled: getEnvAsBool("RANDOM_ENCOUNTERS_ENABLED", false), // Encounters only where placed
+		EncounterCooldownSteps:  getEnvAsInt("ENCOUNTER_COOLDOWN_STEPS", 20),      // At least 20 steps apart
+
+		// World event defaults
+		WorldEventsEnabled: getEnvAsBool("WORLD_EVENTS_ENABLED", false), // A static world
+		WorldEventChance:   getEnvAsFloat64("WORLD_EVENT_CHANCE", 0.25), // About one event a day
+
+		// Difficulty scaling defaults
+		DifficultyScalingEnabled: getEnvAsBool("DIFFICULTY_SCALING_ENABLED", true), // Follow player feedback
+		DifficultyMin:            getEnvAsInt("DIFFICULTY_MIN", 1),                 // Full difficulty range
+		DifficultyMax:            getEnvAsInt("DIFFICULTY_MAX", 20),
+		DifficultyMaxAdjustment:  getEnvAsInt("DIFFICULTY_MAX_ADJUSTMENT", 3), // At most 3 levels either way
+		DifficultyOverrides:      getEnvAsIntMap("DIFFICULTY_OVERRIDES"),      // e.g. "quests=1,terrain=0"
+
+		// PCG plugin defaults
+		PCGPlugins:          getEnvAsStringSlice("PCG_PLUGINS", []string{}),             // Built-in generators only
+		PCGPluginTimeout:    getEnvAsDuration("PCG_PLUGIN_TIMEOUT", 10*time.Second),     // 10s per generation
+		PCGPluginMaxTimeout: getEnvAsDuration("PCG_PLUGIN_MAX_TIMEOUT", 30*time.Second), // No generator beyond 30s
+
+		// Content bundle defaults
+		ContentBundles:              getEnvAsStringSlice("CONTENT_BUNDLES", []string{}),     // No bundles mounted
+		ContentBundleKeys:           getEnvAsStringSlice("CONTENT_BUNDLE_KEYS", []string{}), // No signer trusted
+		ContentBundlesAllowUnsigned: getEnvAsBool("CONTENT_BUNDLES_ALLOW_UNSIGNED", false),  // Signed bundles only
+
+		// PCG content cache defaults
+		PCGRNG:               getEnvAsString("PCG_RNG", "math"),                                             // Legacy math/rand sequences
+		PCGCacheMaxBytes:     getEnvAsInt64("PCG_CACHE_MAX_BYTES", 64<<20),                                  // 64 MiB
+		PCGCacheTTL:          getEnvAsDuration("PCG_CACHE_TTL", 10*time.Minute),                             // Regenerate after 10 minutes
+		PCGCacheContentTypes: getEnvAsStringSlice("PCG_CACHE_CONTENT_TYPES", []string{"terrain", "levels"}), // Content copied into the world
+
+		// PCG quality gate defaults
+		PCGQualityGateEnabled:     getEnvAsBool("PCG_QUALITY_GATE_ENABLED", false),    // First result of each generator
+		PCGQualityGateMaxAttempts: getEnvAsInt("PCG_QUALITY_GATE_MAX_ATTEMPTS", 3),    // At most 3 candidates
+		PCGQualityGateMinScore:    getEnvAsFloat64("PCG_QUALITY_GATE_MIN_SCORE", 0.8), // No more than one warning
+		PCGQualityGateThresholds:  getEnvAsFloat64Map("PCG_QUALITY_GATE_THRESHOLDS"),  // e.g. "levels=0.9,quests=0.6"
+
+		// Tracing defaults
+		TracingExporter:    getEnvAsString("TRACING_EXPORTER", "none"), // Tracing off
+		TracingEndpoint:    getEnvAsString("TRACING_ENDPOINT", ""),
+		TracingInsecure:    getEnvAsBool("TRACING_INSECURE", false),
+		TracingServiceName: getEnvAsString("TRACING_SERVICE_NAME", "goldbox-rpg"),
+		TracingSampleRatio: getEnvAsFloat64("TRACING_SAMPLE_RATIO", 1.0), // Record every trace
+	}
+
+	// The persistence mode was first read without the GOLDBOX_ prefix, and
+	// that name still works when the prefixed one is unset
+	if config.PersistenceMode == "" {
+		config.PersistenceMode = getEnvAsString("PERSISTENCE_MODE", "snapshot") // Full-state auto-saves only
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -230,12 +785,91 @@ func (c *Config) validate() error {
 		return err
 	}
 
+	if err := c.validateHealthConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateWebhookConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateAnalyticsConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validatePersistenceConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateSessionStoreConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateDifficultyConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validatePCGPluginConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateContentBundleConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validatePCGCacheConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validatePCGQualityGateConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateTracingConfig(); err != nil {
+		return err
+	}
+
+	switch c.InitiativeMode {
+	case "fixed", "per_round":
+	default:
+		return fmt.Errorf("initiative mode must be one of fixed, per_round, got %q", c.InitiativeMode)
+	}
+
+	if err := c.validateTurnTimerConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateCompanionConfig(); err != nil {
+		return err
+	}
+
+	if c.EncounterCooldownSteps < 0 {
+		return fmt.Errorf("encounter cooldown steps cannot be negative, got %d", c.EncounterCooldownSteps)
+	}
+
+	if err := c.validateAdminConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateRequestAuditConfig(); err != nil {
+		return err
+	}
+
+	if c.WorldEventChance < 0 || c.WorldEventChance > 1 {
+		return fmt.Errorf("world event chance must be between 0 and 1, got %v", c.WorldEventChance)
+	}
+
+	if strings.ContainsAny(c.Ruleset, `/\.`) {
+		return fmt.Errorf("ruleset must be a profile name without a path or extension, got %q", c.Ruleset)
+	}
+
 	return nil
 }
 
 // validateServerSettings checks server port and log level configuration.
-// Ensures the server port is within valid range (1-65535) and log level
-// is one of the supported values (debug, info, warn, error).
+// Ensures the server port is within valid range (1-65535), log level
+// is one of the supported values (debug, info, warn, error), and
+// subsystem log levels parse.
 func (c *Config) validateServerSettings() error {
 	// Validate server port range
 	if c.ServerPort < 1 || c.ServerPort > 65535 {
@@ -255,6 +889,10 @@ func (c *Config) validateServerSettings() error {
 		return fmt.Errorf("log level must be one of %v, got %s", validLogLevels, c.LogLevel)
 	}
 
+	if _, err := logging.ParseLevels(c.LogLevels); err != nil {
+		return fmt.Errorf("invalid subsystem log levels: %w", err)
+	}
+
 	return nil
 }
 
@@ -287,6 +925,14 @@ func (c *Config) validateSecuritySettings() error {
 		return fmt.Errorf("allowed origins must be specified when dev mode is disabled")
 	}
 
+	// The levels accepted by compress/flate
+	if c.WebSocketCompressionLevel < -2 || c.WebSocketCompressionLevel > 9 {
+		return fmt.Errorf("websocket compression level must be between -2 and 9, got %d", c.WebSocketCompressionLevel)
+	}
+	if c.WebSocketCompressionMinSize < 0 {
+		return fmt.Errorf("websocket compression min size cannot be negative, got %d", c.WebSocketCompressionMinSize)
+	}
+
 	return nil
 }
 
@@ -303,6 +949,52 @@ func (c *Config) validateRateLimitConfig() error {
 		}
 	}
 
+	if c.SessionRateLimitEnabled {
+		if c.SessionRateLimitRequestsPerSecond <= 0 {
+			return fmt.Errorf("session rate limit requests per second must be greater than 0 when session rate limiting is enabled")
+		}
+		if c.SessionRateLimitBurst <= 0 {
+			return fmt.Errorf("session rate limit burst must be greater than 0 when session rate limiting is enabled")
+		}
+		for method, weight := range c.SessionRateLimitMethodWeights {
+			// A weight above the burst could never be paid for
+			if weight < 0 || weight > c.SessionRateLimitBurst {
+				return fmt.Errorf("session rate limit weight for %s must be between 0 and the burst of %d, got %d", method, c.SessionRateLimitBurst, weight)
+			}
+		}
+	}
+
+	return nil
+}
+
+// adminPermissionNames are the admin actions an admin token can be
+// permitted, each covering a group of admin.* methods
+var adminPermissionNames = []string{"spawn", "teleport", "grant", "generate", "combat", "inspect", "undo", "restore", "config", "annotate"}
+
+// minAdminTokenLength is the shortest admin token accepted
+const minAdminTokenLength = 32
+
+// validateAdminConfig checks the admin console settings when an admin
+// token is configured. Short tokens are rejected, since the token is the
+// only credential of the admin methods.
+func (c *Config) validateAdminConfig() error {
+	if c.AdminToken == "" {
+		return nil
+	}
+	if len(c.AdminToken) < minAdminTokenLength {
+		return fmt.Errorf("admin token must be at least %d characters long", minAdminTokenLength)
+	}
+	for _, permission := range c.AdminPermissions {
+		if !slices.Contains(adminPermissionNames, permission) {
+			return fmt.Errorf("unknown admin permission %q, must be one of %s", permission, strings.Join(adminPermissionNames, ", "))
+		}
+	}
+	if c.AdminRateLimitRequestsPerSecond <= 0 {
+		return fmt.Errorf("admin rate limit requests per second must be greater than 0 when an admin token is set")
+	}
+	if c.AdminRateLimitBurst <= 0 {
+		return fmt.Errorf("admin rate limit burst must be greater than 0 when an admin token is set")
+	}
 	return nil
 }
 
@@ -331,6 +1023,295 @@ func (c *Config) validateRetryConfig() error {
 	return nil
 }
 
+// validateHealthConfig ensures the health check interval is not negative
+// and the load shedding ratios are fractions of the requests.
+func (c *Config) validateHealthConfig() error {
+	if c.HealthCheckInterval < 0 {
+		return fmt.Errorf("health check interval cannot be negative, got %v", c.HealthCheckInterval)
+	}
+	if c.LoadShedDegradedRatio < 0 || c.LoadShedDegradedRatio > 1 {
+		return fmt.Errorf("load shed degraded ratio must be between 0 and 1, got %v", c.LoadShedDegradedRatio)
+	}
+	if c.LoadShedUnhealthyRatio < 0 || c.LoadShedUnhealthyRatio > 1 {
+		return fmt.Errorf("load shed unhealthy ratio must be between 0 and 1, got %v", c.LoadShedUnhealthyRatio)
+	}
+	return nil
+}
+
+// validatePersistenceConfig ensures a known storage backend and mode are
+// selected, that the sqlite backend has a driver name to open, and that the
+// backup retention settings are not negative.
+func (c *Config) validatePersistenceConfig() error {
+	switch c.PersistenceBackend {
+	case "file", "memory":
+	case "sqlite":
+		if c.SQLiteDriver == "" {
+			return fmt.Errorf("sqlite driver must be set when the sqlite persistence backend is selected")
+		}
+	default:
+		return fmt.Errorf("persistence backend must be one of file, sqlite, memory, got %q", c.PersistenceBackend)
+	}
+
+	switch c.PersistenceMode {
+	case "snapshot":
+	case "event-sourced":
+		if c.PersistenceBackend == "memory" {
+			return fmt.Errorf("event-sourced persistence needs a durable backend, not memory")
+		}
+	default:
+		return fmt.Errorf("persistence mode must be one of snapshot, event-sourced, got %q", c.PersistenceMode)
+	}
+
+	if c.BackupCount < 0 {
+		return fmt.Errorf("backup count cannot be negative, got %d", c.BackupCount)
+	}
+	if c.BackupMaxAge < 0 || c.BackupInterval < 0 || c.BackupVerifyInterval < 0 {
+		return fmt.Errorf("backup durations cannot be negative")
+	}
+
+	if c.SnapshotCount < 0 {
+		return fmt.Errorf("snapshot count cannot be negative, got %d", c.SnapshotCount)
+	}
+	if c.SnapshotInterval < 0 || c.SnapshotCompactAfter < 0 || c.SnapshotCompactSpacing < 0 {
+		return fmt.Errorf("snapshot durations cannot be negative")
+	}
+
+	if c.StatsAggregationInterval <= 0 {
+		return fmt.Errorf("stats aggregation interval must be positive, got %v", c.StatsAggregationInterval)
+	}
+
+	return c.validatePersistenceSealing()
+}
+
+// minPersistenceSecretLength is the shortest save sealing secret accepted
+const minPersistenceSecretLength = 16
+
+// validatePersistenceSealing ensures save sealing is only configured for
+// the file backend, with secrets long enough to derive keys from.
+func (c *Config) validatePersistenceSealing() error {
+	if c.PersistenceSecret == "" {
+		if len(c.PersistencePreviousSecrets) > 0 || c.PersistenceEncrypt {
+			return fmt.Errorf("persistence secret must be set to encrypt or rotate saved files")
+		}
+		return nil
+	}
+	if c.PersistenceBackend != "file" {
+		return fmt.Errorf("saved files can only be sealed by the file backend, not %s", c.PersistenceBackend)
+	}
+	for _, secret := range append([]string{c.PersistenceSecret}, c.PersistencePreviousSecrets...) {
+		if len(secret) < minPersistenceSecretLength {
+			return fmt.Errorf("persistence secrets must be at least %d characters long", minPersistenceSecretLength)
+		}
+	}
+	return nil
+}
+
+// validateSessionStoreConfig ensures a known session store backend is
+// selected and that the redis backend has a server to connect to.
+func (c *Config) validateSessionStoreConfig() error {
+	switch c.SessionStoreBackend {
+	case "memory":
+	case "redis":
+		if c.RedisAddr == "" {
+			return fmt.Errorf("redis address must be set when the redis session store is selected")
+		}
+		if c.RedisDB < 0 {
+			return fmt.Errorf("redis database cannot be negative, got %d", c.RedisDB)
+		}
+	default:
+		return fmt.Errorf("session store backend must be one of memory, redis, got %q", c.SessionStoreBackend)
+	}
+	return nil
+}
+
+// validateDifficultyConfig ensures the difficulty bounds lie within the
+// 1-20 generation range and adjustments are not negative.
+func (c *Config) validateDifficultyConfig() error {
+	if c.DifficultyMin < 1 || c.DifficultyMax > 20 || c.DifficultyMin > c.DifficultyMax {
+		return fmt.Errorf("difficulty bounds must satisfy 1 <= min <= max <= 20, got %d-%d", c.DifficultyMin, c.DifficultyMax)
+	}
+	if c.DifficultyMaxAdjustment < 0 {
+		return fmt.Errorf("difficulty max adjustment cannot be negative, got %d", c.DifficultyMaxAdjustment)
+	}
+	for contentType, limit := range c.DifficultyOverrides {
+		if limit < 0 {
+			return fmt.Errorf("difficulty override for %s cannot be negative, got %d", contentType, limit)
+		}
+	}
+	return nil
+}
+
+// validatePCGPluginConfig ensures plugin generations have a time limit.
+func (c *Config) validatePCGPluginConfig() error {
+	if c.PCGPluginTimeout <= 0 || c.PCGPluginMaxTimeout <= 0 {
+		return fmt.Errorf("PCG plugin timeouts must be positive, got %v and %v", c.PCGPluginTimeout, c.PCGPluginMaxTimeout)
+	}
+	if c.PCGPluginTimeout > c.PCGPluginMaxTimeout {
+		return fmt.Errorf("PCG plugin timeout cannot exceed the max timeout, got %v and %v", c.PCGPluginTimeout, c.PCGPluginMaxTimeout)
+	}
+	return nil
+}
+
+// validateContentBundleConfig ensures every trusted bundle key is a base64
+// ed25519 public key.
+func (c *Config) validateContentBundleConfig() error {
+	for _, key := range c.ContentBundleKeys {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(decoded) != ed25519.PublicKeySize {
+			return fmt.Errorf("content bundle keys must be base64 ed25519 public keys, got %q", key)
+		}
+	}
+	return nil
+}
+
+// validatePCGCacheConfig ensures a known generation RNG is selected and the
+// content cache limits are not negative.
+func (c *Config) validatePCGCacheConfig() error {
+	switch c.PCGRNG {
+	case "math", "pcg64", "xoshiro256":
+	default:
+		return fmt.Errorf("PCG RNG must be one of math, pcg64, xoshiro256, got %q", c.PCGRNG)
+	}
+	if c.PCGCacheMaxBytes < 0 {
+		return fmt.Errorf("PCG cache max bytes cannot be negative, got %d", c.PCGCacheMaxBytes)
+	}
+	if c.PCGCacheTTL < 0 {
+		return fmt.Errorf("PCG cache TTL cannot be negative, got %v", c.PCGCacheTTL)
+	}
+	return nil
+}
+
+// validateRequestAuditConfig ensures every sample rate lies between 0 and 1
+// and an enabled audit keeps at least one record.
+func (c *Config) validateRequestAuditConfig() error {
+	if c.RequestAuditSampleRate < 0 || c.RequestAuditSampleRate > 1 {
+		return fmt.Errorf("request audit sample rate must be between 0 and 1, got %v", c.RequestAuditSampleRate)
+	}
+	for method, rate := range c.RequestAuditMethodRates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("request audit sample rate for %s must be between 0 and 1, got %v", method, rate)
+		}
+	}
+	if c.RequestAuditEnabled && c.RequestAuditBufferSize < 1 {
+		return fmt.Errorf("request audit buffer size must be at least 1, got %d", c.RequestAuditBufferSize)
+	}
+	return nil
+}
+
+// validatePCGQualityGateConfig ensures at least one candidate is generated
+// and every score threshold lies between 0 and 1.
+func (c *Config) validatePCGQualityGateConfig() error {
+	if c.PCGQualityGateMaxAttempts < 1 {
+		return fmt.Errorf("PCG quality gate max attempts must be at least 1, got %d", c.PCGQualityGateMaxAttempts)
+	}
+	if c.PCGQualityGateMinScore < 0 || c.PCGQualityGateMinScore > 1 {
+		return fmt.Errorf("PCG quality gate min score must be between 0 and 1, got %v", c.PCGQualityGateMinScore)
+	}
+	for contentType, score := range c.PCGQualityGateThresholds {
+		if score < 0 || score > 1 {
+			return fmt.Errorf("PCG quality gate threshold for %s must be between 0 and 1, got %v", contentType, score)
+		}
+	}
+	return nil
+}
+
+// validateTurnTimerConfig ensures the turn timer settings are not negative
+// and that warnings come before the turn times out.
+func (c *Config) validateTurnTimerConfig() error {
+	if c.TurnTimeout < 0 || c.TurnWarning < 0 {
+		return fmt.Errorf("turn timeout and warning cannot be negative, got %v and %v", c.TurnTimeout, c.TurnWarning)
+	}
+	if c.TurnTimeout > 0 && c.TurnWarning >= c.TurnTimeout {
+		return fmt.Errorf("turn warning must be shorter than the turn timeout, got %v and %v", c.TurnWarning, c.TurnTimeout)
+	}
+	if c.AFKSkipLimit < 0 {
+		return fmt.Errorf("AFK skip limit cannot be negative, got %d", c.AFKSkipLimit)
+	}
+	return nil
+}
+
+// validateCompanionConfig ensures companions can be counted against a
+// party that holds at least its player.
+func (c *Config) validateCompanionConfig() error {
+	if c.MaxCompanions < 0 {
+		return fmt.Errorf("max companions cannot be negative, got %d", c.MaxCompanions)
+	}
+	if c.MaxPartySize < 1 {
+		return fmt.Errorf("max party size must be at least 1, got %d", c.MaxPartySize)
+	}
+	return nil
+}
+
+// validateTracingConfig ensures a known span exporter is selected and the
+// sample ratio is a fraction.
+func (c *Config) validateTracingConfig() error {
+	switch c.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		return fmt.Errorf("tracing exporter must be one of none, stdout, otlp, got %q", c.TracingExporter)
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1, got %g", c.TracingSampleRatio)
+	}
+	return nil
+}
+
+// validateWebhookConfig ensures webhook endpoints are absolute HTTP(S) URLs
+// and that the quality grade threshold and delivery timeout are usable.
+func (c *Config) validateWebhookConfig() error {
+	for _, rawURL := range c.WebhookURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", rawURL)
+		}
+	}
+
+	switch c.WebhookQualityGrade {
+	case "A", "B", "C", "D", "F":
+	default:
+		return fmt.Errorf("invalid webhook quality grade %q: must be one of A, B, C, D, F", c.WebhookQualityGrade)
+	}
+
+	if len(c.WebhookURLs) > 0 && c.WebhookTimeout <= 0 {
+		return fmt.Errorf("webhook timeout must be greater than 0 when webhooks are configured")
+	}
+
+	return nil
+}
+
+// validateAnalyticsConfig ensures the analytics sinks are known and have
+// what they need, and that batching and the queue are usable.
+func (c *Config) validateAnalyticsConfig() error {
+	if !c.AnalyticsEnabled {
+		return nil
+	}
+
+	for _, sink := range c.AnalyticsSinks {
+		switch sink {
+		case "stdout", "file":
+		case "http":
+			parsed, err := url.Parse(c.AnalyticsURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("invalid analytics URL %q: the http sink needs an absolute http or https URL", c.AnalyticsURL)
+			}
+		default:
+			return fmt.Errorf("invalid analytics sink %q: must be one of stdout, file, http", sink)
+		}
+	}
+
+	if c.AnalyticsBatchSize <= 0 {
+		return fmt.Errorf("analytics batch size must be greater than 0")
+	}
+	if c.AnalyticsFlushInterval <= 0 {
+		return fmt.Errorf("analytics flush interval must be greater than 0")
+	}
+	if c.AnalyticsQueueSize <= 0 {
+		return fmt.Errorf("analytics queue size must be greater than 0")
+	}
+
+	return nil
+}
+
 // OriginAllowed checks if the given origin is allowed for WebSocket connections.
 // In development mode, all origins are allowed. In production mode, only explicitly
 // allowed origins are permitted. This method is thread-safe.
@@ -358,6 +1339,9 @@ func (c *Config) OriginAllowed(origin string) bool {
 // by the retry package. The returned configuration can be used directly with
 // retry.NewRetrier() to create a retrier instance.
 func (c *Config) GetRetryConfig() retry.RetryConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	return retry.RetryConfig{
 		MaxAttempts:       c.RetryMaxAttempts,
 		InitialDelay:      c.RetryInitialDelay,
@@ -368,17 +1352,26 @@ func (c *Config) GetRetryConfig() retry.RetryConfig {
 	}
 }
 
+// GetSessionTimeout returns the duration after which inactive sessions
+// expire. Use it rather than the field while the configuration may be
+// reloaded. This method is thread-safe.
+func (c *Config) GetSessionTimeout() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SessionTimeout
+}
+
 // Helper functions for environment variable parsing with type safety and defaults
 
 func getEnvAsString(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupSetting(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
 func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupSetting(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		}
@@ -387,7 +1380,7 @@ func getEnvAsInt(key string, defaultValue int) int {
 }
 
 func getEnvAsInt64(key string, defaultValue int64) int64 {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupSetting(key); value != "" {
 		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
 			return intValue
 		}
@@ -396,7 +1389,7 @@ func getEnvAsInt64(key string, defaultValue int64) int64 {
 }
 
 func getEnvAsBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupSetting(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
 			return boolValue
 		}
@@ -405,7 +1398,7 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 }
 
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupSetting(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
 			return duration
 		}
@@ -414,7 +1407,7 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 }
 
 func getEnvAsStringSlice(key string, defaultValue []string) []string {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupSetting(key); value != "" {
 		// Split by comma and trim whitespace
 		parts := strings.Split(value, ",")
 		result := make([]string, 0, len(parts))
@@ -428,8 +1421,51 @@ func getEnvAsStringSlice(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getEnvAsIntMap parses comma separated key=value pairs with integer values,
+// skipping malformed pairs. It returns nil when the variable is unset.
+func getEnvAsIntMap(key string) map[string]int {
+	value := lookupSetting(key)
+	if value == "" {
+		return nil
+	}
+
+	result := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		name, number, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		intValue, err := strconv.Atoi(strings.TrimSpace(number))
+		if !found || name == "" || err != nil {
+			continue
+		}
+		result[name] = intValue
+	}
+	return result
+}
+
+// getEnvAsFloat64Map parses comma separated key=value pairs with float
+// values, skipping malformed pairs. It returns nil when the variable is
+// unset.
+func getEnvAsFloat64Map(key string) map[string]float64 {
+	value := lookupSetting(key)
+	if value == "" {
+		return nil
+	}
+
+	result := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		name, number, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		floatValue, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if !found || name == "" || err != nil {
+			continue
+		}
+		result[name] = floatValue
+	}
+	return result
+}
+
 func getEnvAsFloat64(key string, defaultValue float64) float64 {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupSetting(key); value != "" {
 		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
 			return floatValue
 		}
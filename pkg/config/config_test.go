@@ -1,7 +1,10 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -71,6 +74,47 @@ func TestLoad(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "subsystem log levels",
+			envVars: map[string]string{
+				"LOG_LEVELS": "pcg=warn, rpc=debug",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, "pcg=warn, rpc=debug", config.LogLevels)
+			},
+		},
+		{
+			name: "invalid subsystem log levels",
+			envVars: map[string]string{
+				"LOG_LEVELS": "pcg=loud",
+			},
+			expectError: true,
+		},
+		{
+			name: "session rate limiting",
+			envVars: map[string]string{
+				"SESSION_RATE_LIMIT_ENABLED":        "true",
+				"SESSION_RATE_LIMIT_BURST":          "10",
+				"SESSION_RATE_LIMIT_METHOD_WEIGHTS": "castSpell=4, getGameState=1,bogus",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.True(t, config.SessionRateLimitEnabled)
+				assert.Equal(t, 10.0, config.SessionRateLimitRequestsPerSecond)
+				assert.Equal(t, 10, config.SessionRateLimitBurst)
+				assert.Equal(t, map[string]int{"castSpell": 4, "getGameState": 1}, config.SessionRateLimitMethodWeights)
+			},
+		},
+		{
+			name: "session rate limit weight above burst",
+			envVars: map[string]string{
+				"SESSION_RATE_LIMIT_ENABLED":        "true",
+				"SESSION_RATE_LIMIT_BURST":          "2",
+				"SESSION_RATE_LIMIT_METHOD_WEIGHTS": "castSpell=3",
+			},
+			expectError: true,
+		},
 		{
 			name: "session timeout too short",
 			envVars: map[string]string{
@@ -291,10 +335,12 @@ func TestGetEnvHelpers(t *testing.T) {
 // clearTestEnv removes all environment variables that might affect tests
 func clearTestEnv() {
 	testVars := []string{
-		"SERVER_PORT", "WEB_DIR", "SESSION_TIMEOUT", "LOG_LEVEL",
+		"SERVER_PORT", "WEB_DIR", "SESSION_TIMEOUT", "LOG_LEVEL", "LOG_LEVELS",
 		"ALLOWED_ORIGINS", "MAX_REQUEST_SIZE", "ENABLE_DEV_MODE", "REQUEST_TIMEOUT",
 		"TEST_STRING", "TEST_INT", "TEST_INT_INVALID", "TEST_INT64", "TEST_BOOL",
 		"TEST_DURATION", "TEST_SLICE", "TEST_SLICE_WHITESPACE", "TEST_SLICE_EMPTY",
+		"SESSION_RATE_LIMIT_ENABLED", "SESSION_RATE_LIMIT_REQUESTS_PER_SECOND",
+		"SESSION_RATE_LIMIT_BURST", "SESSION_RATE_LIMIT_METHOD_WEIGHTS",
 	}
 
 	for _, v := range testVars {
@@ -467,6 +513,949 @@ func clearLifecycleTimeoutEnv() {
 }
 
 // TestConfig_OriginAllowed_ThreadSafety tests that OriginAllowed is safe for concurrent access
+func TestLoad_WebhookConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		envVars     map[string]string
+		expectError bool
+		validate    func(t *testing.T, config *Config)
+	}{
+		{
+			name:        "webhooks disabled by default",
+			envVars:     map[string]string{},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Empty(t, config.WebhookURLs)
+				assert.Empty(t, config.WebhookEvents)
+				assert.Equal(t, "C", config.WebhookQualityGrade)
+				assert.Equal(t, 10*time.Second, config.WebhookTimeout)
+			},
+		},
+		{
+			name: "webhooks from environment",
+			envVars: map[string]string{
+				"WEBHOOK_URLS":          "https://discord.example.com/api/webhooks/1, http://localhost:9000/hook",
+				"WEBHOOK_SECRET":        "s3cret",
+				"WEBHOOK_EVENTS":        "player_died,boss_defeated",
+				"WEBHOOK_QUALITY_GRADE": "B",
+				"WEBHOOK_TIMEOUT":       "5s",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, []string{"https://discord.example.com/api/webhooks/1", "http://localhost:9000/hook"}, config.WebhookURLs)
+				assert.Equal(t, "s3cret", config.WebhookSecret)
+				assert.Equal(t, []string{"player_died", "boss_defeated"}, config.WebhookEvents)
+				assert.Equal(t, "B", config.WebhookQualityGrade)
+				assert.Equal(t, 5*time.Second, config.WebhookTimeout)
+			},
+		},
+		{
+			name: "relative webhook URL",
+			envVars: map[string]string{
+				"WEBHOOK_URLS": "/hooks/local",
+			},
+			expectError: true,
+		},
+		{
+			name: "unsupported webhook scheme",
+			envVars: map[string]string{
+				"WEBHOOK_URLS": "ftp://example.com/hook",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid quality grade",
+			envVars: map[string]string{
+				"WEBHOOK_QUALITY_GRADE": "E",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean environment
+			clearTestEnv()
+			clearWebhookEnv()
+
+			// Set test environment variables
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+				defer os.Unsetenv(key)
+			}
+
+			config, err := Load()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, config)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, config)
+				if tt.validate != nil {
+					tt.validate(t, config)
+				}
+			}
+		})
+	}
+}
+
+// clearWebhookEnv removes webhook environment variables
+func clearWebhookEnv() {
+	webhookVars := []string{
+		"WEBHOOK_URLS", "WEBHOOK_SECRET", "WEBHOOK_EVENTS", "WEBHOOK_QUALITY_GRADE", "WEBHOOK_TIMEOUT",
+	}
+	for _, v := range webhookVars {
+		os.Unsetenv(v)
+	}
+}
+
+func TestLoad_AnalyticsConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		envVars     map[string]string
+		expectError bool
+		validate    func(t *testing.T, config *Config)
+	}{
+		{
+			name:    "analytics enabled without sinks by default",
+			envVars: map[string]string{},
+			validate: func(t *testing.T, config *Config) {
+				assert.True(t, config.AnalyticsEnabled)
+				assert.Empty(t, config.AnalyticsSinks)
+				assert.Equal(t, 100, config.AnalyticsBatchSize)
+				assert.Equal(t, 10*time.Second, config.AnalyticsFlushInterval)
+				assert.Equal(t, 1024, config.AnalyticsQueueSize)
+			},
+		},
+		{
+			name: "analytics from environment",
+			envVars: map[string]string{
+				"ANALYTICS_SINKS":          "stdout,file,http",
+				"ANALYTICS_FILE":           "/var/log/goldbox/analytics.jsonl",
+				"ANALYTICS_URL":            "https://analytics.example.com/ingest",
+				"ANALYTICS_SALT":           "pepper",
+				"ANALYTICS_BATCH_SIZE":     "20",
+				"ANALYTICS_FLUSH_INTERVAL": "1m",
+			},
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, []string{"stdout", "file", "http"}, config.AnalyticsSinks)
+				assert.Equal(t, "/var/log/goldbox/analytics.jsonl", config.AnalyticsFile)
+				assert.Equal(t, "https://analytics.example.com/ingest", config.AnalyticsURL)
+				assert.Equal(t, "pepper", config.AnalyticsSalt)
+				assert.Equal(t, 20, config.AnalyticsBatchSize)
+				assert.Equal(t, time.Minute, config.AnalyticsFlushInterval)
+			},
+		},
+		{
+			name: "opted out analytics are not validated",
+			envVars: map[string]string{
+				"ANALYTICS_ENABLED": "false",
+				"ANALYTICS_SINKS":   "kafka",
+			},
+			validate: func(t *testing.T, config *Config) {
+				assert.False(t, config.AnalyticsEnabled)
+			},
+		},
+		{
+			name: "unknown analytics sink",
+			envVars: map[string]string{
+				"ANALYTICS_SINKS": "kafka",
+			},
+			expectError: true,
+		},
+		{
+			name: "http sink without URL",
+			envVars: map[string]string{
+				"ANALYTICS_SINKS": "http",
+			},
+			expectError: true,
+		},
+		{
+			name: "empty batches",
+			envVars: map[string]string{
+				"ANALYTICS_BATCH_SIZE": "0",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearTestEnv()
+			clearAnalyticsEnv()
+
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+				defer os.Unsetenv(key)
+			}
+
+			config, err := Load()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, config)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, config)
+				tt.validate(t, config)
+			}
+		})
+	}
+}
+
+// clearAnalyticsEnv removes analytics environment variables
+func clearAnalyticsEnv() {
+	analyticsVars := []string{
+		"ANALYTICS_ENABLED", "ANALYTICS_SINKS", "ANALYTICS_FILE", "ANALYTICS_URL", "ANALYTICS_SALT",
+		"ANALYTICS_BATCH_SIZE", "ANALYTICS_FLUSH_INTERVAL", "ANALYTICS_QUEUE_SIZE",
+	}
+	for _, v := range analyticsVars {
+		os.Unsetenv(v)
+	}
+}
+
+func TestLoad_PersistenceBackend(t *testing.T) {
+	tests := []struct {
+		name        string
+		envVars     map[string]string
+		expectError bool
+		validate    func(t *testing.T, config *Config)
+	}{
+		{
+			name:        "file backend by default",
+			envVars:     map[string]string{},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, "file", config.PersistenceBackend)
+				assert.Equal(t, "snapshot", config.PersistenceMode)
+				assert.Equal(t, "sqlite", config.SQLiteDriver)
+				assert.Empty(t, config.SQLiteDSN)
+				assert.Equal(t, 5, config.BackupCount)
+				assert.Equal(t, 10*time.Minute, config.BackupInterval)
+			},
+		},
+		{
+			name: "sqlite backend from environment",
+			envVars: map[string]string{
+				"PERSISTENCE_BACKEND": "sqlite",
+				"SQLITE_DRIVER":       "sqlite3",
+				"SQLITE_DSN":          "/var/lib/goldbox/state.db",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, "sqlite", config.PersistenceBackend)
+				assert.Equal(t, "sqlite3", config.SQLiteDriver)
+				assert.Equal(t, "/var/lib/goldbox/state.db", config.SQLiteDSN)
+			},
+		},
+		{
+			name: "unknown backend",
+			envVars: map[string]string{
+				"PERSISTENCE_BACKEND": "postgres",
+			},
+			expectError: true,
+		},
+		{
+			name: "event-sourced mode from environment",
+			envVars: map[string]string{
+				"PERSISTENCE_MODE": "event-sourced",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, "event-sourced", config.PersistenceMode)
+			},
+		},
+		{
+			name: "event-sourced mode from prefixed environment",
+			envVars: map[string]string{
+				"GOLDBOX_PERSISTENCE_MODE": "event-sourced",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, "event-sourced", config.PersistenceMode)
+			},
+		},
+		{
+			name: "prefixed persistence mode takes precedence",
+			envVars: map[string]string{
+				"GOLDBOX_PERSISTENCE_MODE": "snapshot",
+				"PERSISTENCE_MODE":         "event-sourced",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, "snapshot", config.PersistenceMode)
+			},
+		},
+		{
+			name: "event-sourced mode with memory backend",
+			envVars: map[string]string{
+				"PERSISTENCE_BACKEND": "memory",
+				"PERSISTENCE_MODE":    "event-sourced",
+			},
+			expectError: true,
+		},
+		{
+			name: "unknown persistence mode",
+			envVars: map[string]string{
+				"PERSISTENCE_MODE": "journal",
+			},
+			expectError: true,
+		},
+		{
+			name: "backup retention from environment",
+			envVars: map[string]string{
+				"BACKUP_COUNT":           "10",
+				"BACKUP_MAX_AGE":         "48h",
+				"BACKUP_INTERVAL":        "1m",
+				"BACKUP_VERIFY_INTERVAL": "0s",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, 10, config.BackupCount)
+				assert.Equal(t, 48*time.Hour, config.BackupMaxAge)
+				assert.Equal(t, time.Minute, config.BackupInterval)
+				assert.Zero(t, config.BackupVerifyInterval)
+			},
+		},
+		{
+			name: "negative backup count",
+			envVars: map[string]string{
+				"BACKUP_COUNT": "-1",
+			},
+			expectError: true,
+		},
+		{
+			name: "snapshot rotation from environment",
+			envVars: map[string]string{
+				"SNAPSHOT_COUNT":           "48",
+				"SNAPSHOT_INTERVAL":        "5m",
+				"SNAPSHOT_COMPACT_AFTER":   "0s",
+				"SNAPSHOT_COMPACT_SPACING": "2h",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, 48, config.SnapshotCount)
+				assert.Equal(t, 5*time.Minute, config.SnapshotInterval)
+				assert.Zero(t, config.SnapshotCompactAfter)
+				assert.Equal(t, 2*time.Hour, config.SnapshotCompactSpacing)
+			},
+		},
+		{
+			name: "negative snapshot interval",
+			envVars: map[string]string{
+				"SNAPSHOT_INTERVAL": "-1m",
+			},
+			expectError: true,
+		},
+		{
+			name: "stats aggregation interval from environment",
+			envVars: map[string]string{
+				"STATS_AGGREGATION_INTERVAL": "5m",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, 5*time.Minute, config.StatsAggregationInterval)
+			},
+		},
+		{
+			name: "zero stats aggregation interval",
+			envVars: map[string]string{
+				"STATS_AGGREGATION_INTERVAL": "0s",
+			},
+			expectError: true,
+		},
+		{
+			name: "save sealing from environment",
+			envVars: map[string]string{
+				"PERSISTENCE_SECRET":           "current-secret-0123456789",
+				"PERSISTENCE_PREVIOUS_SECRETS": "retired-secret-0123456789",
+				"PERSISTENCE_ENCRYPT":          "true",
+			},
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, "current-secret-0123456789", config.PersistenceSecret)
+				assert.Equal(t, []string{"retired-secret-0123456789"}, config.PersistencePreviousSecrets)
+				assert.True(t, config.PersistenceEncrypt)
+				assert.False(t, config.PersistenceAllowUnsealed)
+			},
+		},
+		{
+			name: "encryption without a secret",
+			envVars: map[string]string{
+				"PERSISTENCE_ENCRYPT": "true",
+			},
+			expectError: true,
+		},
+		{
+			name: "short persistence secret",
+			envVars: map[string]string{
+				"PERSISTENCE_SECRET": "short",
+			},
+			expectError: true,
+		},
+		{
+			name: "save sealing with sqlite backend",
+			envVars: map[string]string{
+				"PERSISTENCE_BACKEND": "sqlite",
+				"PERSISTENCE_SECRET":  "current-secret-0123456789",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean environment
+			clearTestEnv()
+			clearPersistenceEnv()
+
+			// Set test environment variables
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+				defer os.Unsetenv(key)
+			}
+
+			config, err := Load()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, config)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, config)
+				if tt.validate != nil {
+					tt.validate(t, config)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_InitiativeMode(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("INITIATIVE_MODE")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "fixed", config.InitiativeMode)
+
+	os.Setenv("INITIATIVE_MODE", "per_round")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "per_round", config.InitiativeMode)
+
+	os.Setenv("INITIATIVE_MODE", "group")
+	_, err = Load()
+	assert.ErrorContains(t, err, "initiative mode")
+}
+
+func TestLoad_Ruleset(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("RULESET")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.Ruleset)
+
+	os.Setenv("RULESET", "adnd2e")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "adnd2e", config.Ruleset)
+
+	os.Setenv("RULESET", "../secrets/adnd2e.yaml")
+	_, err = Load()
+	assert.ErrorContains(t, err, "ruleset")
+}
+
+func TestLoad_ManualLevelUp(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("MANUAL_LEVEL_UP")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.ManualLevelUp)
+
+	os.Setenv("MANUAL_LEVEL_UP", "true")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.True(t, config.ManualLevelUp)
+}
+
+func TestLoad_ZoneOfControl(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("ZONE_OF_CONTROL")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.True(t, config.ZoneOfControl)
+
+	os.Setenv("ZONE_OF_CONTROL", "false")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.False(t, config.ZoneOfControl)
+}
+
+func TestLoad_StrictSpellComponents(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("STRICT_SPELL_COMPONENTS")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.StrictSpellComponents)
+
+	os.Setenv("STRICT_SPELL_COMPONENTS", "true")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.True(t, config.StrictSpellComponents)
+}
+
+func TestLoad_PCGPlugins(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("PCG_PLUGINS")
+	defer os.Unsetenv("PCG_PLUGIN_TIMEOUT")
+	defer os.Unsetenv("PCG_PLUGIN_MAX_TIMEOUT")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.PCGPlugins)
+	assert.Equal(t, 10*time.Second, config.PCGPluginTimeout)
+	assert.Equal(t, 30*time.Second, config.PCGPluginMaxTimeout)
+
+	os.Setenv("PCG_PLUGINS", "/opt/plugins/bounties,/opt/plugins/ruins")
+	os.Setenv("PCG_PLUGIN_TIMEOUT", "2s")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/opt/plugins/bounties", "/opt/plugins/ruins"}, config.PCGPlugins)
+	assert.Equal(t, 2*time.Second, config.PCGPluginTimeout)
+
+	os.Setenv("PCG_PLUGIN_TIMEOUT", "1m")
+	_, err = Load()
+	assert.ErrorContains(t, err, "cannot exceed the max timeout")
+}
+
+func TestLoad_ContentBundles(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("CONTENT_BUNDLES")
+	defer os.Unsetenv("CONTENT_BUNDLE_KEYS")
+	defer os.Unsetenv("CONTENT_BUNDLES_ALLOW_UNSIGNED")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.ContentBundles)
+	assert.Empty(t, config.ContentBundleKeys)
+	assert.False(t, config.ContentBundlesAllowUnsigned)
+
+	key := base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize))
+	os.Setenv("CONTENT_BUNDLES", "data/bundles,/opt/crypt.gbox")
+	os.Setenv("CONTENT_BUNDLE_KEYS", key)
+	os.Setenv("CONTENT_BUNDLES_ALLOW_UNSIGNED", "true")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data/bundles", "/opt/crypt.gbox"}, config.ContentBundles)
+	assert.Equal(t, []string{key}, config.ContentBundleKeys)
+	assert.True(t, config.ContentBundlesAllowUnsigned)
+
+	os.Setenv("CONTENT_BUNDLE_KEYS", "bm90IGEga2V5")
+	_, err = Load()
+	assert.ErrorContains(t, err, "content bundle keys")
+}
+
+func TestLoad_PCGCache(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("PCG_CACHE_MAX_BYTES")
+	defer os.Unsetenv("PCG_CACHE_TTL")
+	defer os.Unsetenv("PCG_CACHE_CONTENT_TYPES")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(64<<20), config.PCGCacheMaxBytes)
+	assert.Equal(t, 10*time.Minute, config.PCGCacheTTL)
+	assert.Equal(t, []string{"terrain", "levels"}, config.PCGCacheContentTypes)
+
+	os.Setenv("PCG_CACHE_MAX_BYTES", "1048576")
+	os.Setenv("PCG_CACHE_TTL", "0s")
+	os.Setenv("PCG_CACHE_CONTENT_TYPES", "levels,monsters")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<20), config.PCGCacheMaxBytes)
+	assert.Zero(t, config.PCGCacheTTL)
+	assert.Equal(t, []string{"levels", "monsters"}, config.PCGCacheContentTypes)
+
+	os.Setenv("PCG_CACHE_MAX_BYTES", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "cannot be negative")
+}
+
+func TestLoad_PCGQualityGate(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("PCG_QUALITY_GATE_ENABLED")
+	defer os.Unsetenv("PCG_QUALITY_GATE_MAX_ATTEMPTS")
+	defer os.Unsetenv("PCG_QUALITY_GATE_MIN_SCORE")
+	defer os.Unsetenv("PCG_QUALITY_GATE_THRESHOLDS")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.PCGQualityGateEnabled)
+	assert.Equal(t, 3, config.PCGQualityGateMaxAttempts)
+	assert.Equal(t, 0.8, config.PCGQualityGateMinScore)
+	assert.Nil(t, config.PCGQualityGateThresholds)
+
+	os.Setenv("PCG_QUALITY_GATE_ENABLED", "true")
+	os.Setenv("PCG_QUALITY_GATE_MAX_ATTEMPTS", "5")
+	os.Setenv("PCG_QUALITY_GATE_MIN_SCORE", "0.6")
+	os.Setenv("PCG_QUALITY_GATE_THRESHOLDS", "levels=0.9, quests=bad")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.True(t, config.PCGQualityGateEnabled)
+	assert.Equal(t, 5, config.PCGQualityGateMaxAttempts)
+	assert.Equal(t, 0.6, config.PCGQualityGateMinScore)
+	assert.Equal(t, map[string]float64{"levels": 0.9}, config.PCGQualityGateThresholds)
+
+	os.Setenv("PCG_QUALITY_GATE_THRESHOLDS", "levels=1.5")
+	_, err = Load()
+	assert.ErrorContains(t, err, "between 0 and 1")
+
+	os.Unsetenv("PCG_QUALITY_GATE_THRESHOLDS")
+	os.Setenv("PCG_QUALITY_GATE_MAX_ATTEMPTS", "0")
+	_, err = Load()
+	assert.ErrorContains(t, err, "at least 1")
+}
+
+func TestLoad_RequestAudit(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("REQUEST_AUDIT_ENABLED")
+	defer os.Unsetenv("REQUEST_AUDIT_SAMPLE_RATE")
+	defer os.Unsetenv("REQUEST_AUDIT_METHOD_RATES")
+	defer os.Unsetenv("REQUEST_AUDIT_BUFFER_SIZE")
+	defer os.Unsetenv("REQUEST_AUDIT_REDACT_PLAYER_NAMES")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.True(t, config.RequestAuditEnabled)
+	assert.Equal(t, 0.1, config.RequestAuditSampleRate)
+	assert.Nil(t, config.RequestAuditMethodRates)
+	assert.Equal(t, 500, config.RequestAuditBufferSize)
+	assert.True(t, config.RequestAuditRedactSessionIDs)
+	assert.True(t, config.RequestAuditRedactPlayerNames)
+
+	os.Setenv("REQUEST_AUDIT_SAMPLE_RATE", "1")
+	os.Setenv("REQUEST_AUDIT_METHOD_RATES", "move=0.01,joinGame=1")
+	os.Setenv("REQUEST_AUDIT_BUFFER_SIZE", "50")
+	os.Setenv("REQUEST_AUDIT_REDACT_PLAYER_NAMES", "false")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, config.RequestAuditSampleRate)
+	assert.Equal(t, map[string]float64{"move": 0.01, "joinGame": 1}, config.RequestAuditMethodRates)
+	assert.Equal(t, 50, config.RequestAuditBufferSize)
+	assert.False(t, config.RequestAuditRedactPlayerNames)
+
+	os.Setenv("REQUEST_AUDIT_METHOD_RATES", "move=2")
+	_, err = Load()
+	assert.ErrorContains(t, err, "between 0 and 1")
+
+	os.Unsetenv("REQUEST_AUDIT_METHOD_RATES")
+	os.Setenv("REQUEST_AUDIT_BUFFER_SIZE", "0")
+	_, err = Load()
+	assert.ErrorContains(t, err, "at least 1")
+
+	os.Setenv("REQUEST_AUDIT_ENABLED", "false")
+	_, err = Load()
+	assert.NoError(t, err, "a disabled audit keeps no records")
+}
+
+func TestLoad_HealthChecks(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("HEALTH_CHECK_INTERVAL")
+	defer os.Unsetenv("LOAD_SHEDDING_ENABLED")
+	defer os.Unsetenv("LOAD_SHED_DEGRADED_RATIO")
+	defer os.Unsetenv("LOAD_SHED_UNHEALTHY_RATIO")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Second, config.HealthCheckInterval)
+	assert.True(t, config.LoadSheddingEnabled)
+	assert.Equal(t, 0.25, config.LoadShedDegradedRatio)
+	assert.Equal(t, 0.9, config.LoadShedUnhealthyRatio)
+
+	os.Setenv("HEALTH_CHECK_INTERVAL", "0s")
+	os.Setenv("LOAD_SHEDDING_ENABLED", "false")
+	os.Setenv("LOAD_SHED_DEGRADED_RATIO", "0")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Zero(t, config.HealthCheckInterval)
+	assert.False(t, config.LoadSheddingEnabled)
+	assert.Zero(t, config.LoadShedDegradedRatio)
+
+	os.Setenv("LOAD_SHED_UNHEALTHY_RATIO", "1.5")
+	_, err = Load()
+	assert.ErrorContains(t, err, "load shed unhealthy ratio")
+}
+
+func TestLoad_PCGRNG(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("PCG_RNG")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "math", config.PCGRNG)
+
+	os.Setenv("PCG_RNG", "pcg64")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "pcg64", config.PCGRNG)
+
+	os.Setenv("PCG_RNG", "crypto")
+	_, err = Load()
+	assert.ErrorContains(t, err, "PCG RNG must be one of")
+}
+
+func TestLoad_TurnTimer(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("TURN_TIMEOUT")
+	defer os.Unsetenv("TURN_WARNING")
+	defer os.Unsetenv("AFK_SKIP_LIMIT")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 60*time.Second, config.TurnTimeout)
+	assert.Equal(t, 15*time.Second, config.TurnWarning)
+	assert.Equal(t, 3, config.AFKSkipLimit)
+
+	os.Setenv("TURN_TIMEOUT", "0")
+	os.Setenv("AFK_SKIP_LIMIT", "0")
+	config, err = Load()
+	require.NoError(t, err, "a zero timeout disables the timer and its warning")
+	assert.Zero(t, config.TurnTimeout)
+	assert.Zero(t, config.AFKSkipLimit)
+
+	os.Setenv("TURN_TIMEOUT", "10s")
+	_, err = Load()
+	assert.ErrorContains(t, err, "turn warning")
+
+	os.Setenv("TURN_WARNING", "5s")
+	os.Setenv("AFK_SKIP_LIMIT", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "AFK skip limit")
+}
+
+func TestLoad_Companions(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("MAX_COMPANIONS")
+	defer os.Unsetenv("MAX_PARTY_SIZE")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, config.MaxCompanions)
+	assert.Equal(t, 8, config.MaxPartySize)
+
+	os.Setenv("MAX_COMPANIONS", "0")
+	config, err = Load()
+	require.NoError(t, err, "zero companions disables hiring")
+	assert.Zero(t, config.MaxCompanions)
+
+	os.Setenv("MAX_COMPANIONS", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "max companions")
+
+	os.Setenv("MAX_COMPANIONS", "2")
+	os.Setenv("MAX_PARTY_SIZE", "0")
+	_, err = Load()
+	assert.ErrorContains(t, err, "max party size")
+}
+
+func TestLoad_RandomEncounters(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("RANDOM_ENCOUNTERS_ENABLED")
+	defer os.Unsetenv("ENCOUNTER_COOLDOWN_STEPS")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.RandomEncountersEnabled)
+	assert.Equal(t, 20, config.EncounterCooldownSteps)
+
+	os.Setenv("RANDOM_ENCOUNTERS_ENABLED", "true")
+	os.Setenv("ENCOUNTER_COOLDOWN_STEPS", "5")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.True(t, config.RandomEncountersEnabled)
+	assert.Equal(t, 5, config.EncounterCooldownSteps)
+
+	os.Setenv("ENCOUNTER_COOLDOWN_STEPS", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "encounter cooldown")
+}
+
+func TestLoad_WebSocketCompression(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("WEBSOCKET_COMPRESSION")
+	defer os.Unsetenv("WEBSOCKET_COMPRESSION_LEVEL")
+	defer os.Unsetenv("WEBSOCKET_COMPRESSION_MIN_SIZE")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.True(t, config.WebSocketCompression)
+	assert.Equal(t, 1, config.WebSocketCompressionLevel)
+	assert.Equal(t, 256, config.WebSocketCompressionMinSize)
+
+	os.Setenv("WEBSOCKET_COMPRESSION", "false")
+	os.Setenv("WEBSOCKET_COMPRESSION_LEVEL", "9")
+	os.Setenv("WEBSOCKET_COMPRESSION_MIN_SIZE", "0")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.False(t, config.WebSocketCompression)
+	assert.Equal(t, 9, config.WebSocketCompressionLevel)
+	assert.Equal(t, 0, config.WebSocketCompressionMinSize)
+
+	os.Setenv("WEBSOCKET_COMPRESSION_LEVEL", "10")
+	_, err = Load()
+	assert.ErrorContains(t, err, "websocket compression level")
+
+	os.Setenv("WEBSOCKET_COMPRESSION_LEVEL", "1")
+	os.Setenv("WEBSOCKET_COMPRESSION_MIN_SIZE", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "websocket compression min size")
+}
+
+func TestLoad_WorldEvents(t *testing.T) {
+	clearTestEnv()
+	defer os.Unsetenv("WORLD_EVENTS_ENABLED")
+	defer os.Unsetenv("WORLD_EVENT_CHANCE")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.WorldEventsEnabled)
+	assert.Equal(t, 0.25, config.WorldEventChance)
+
+	os.Setenv("WORLD_EVENTS_ENABLED", "true")
+	os.Setenv("WORLD_EVENT_CHANCE", "0.5")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.True(t, config.WorldEventsEnabled)
+	assert.Equal(t, 0.5, config.WorldEventChance)
+
+	os.Setenv("WORLD_EVENT_CHANCE", "1.5")
+	_, err = Load()
+	assert.ErrorContains(t, err, "world event chance")
+}
+
+func TestLoad_AdminConsole(t *testing.T) {
+	clearTestEnv()
+	defer func() {
+		for _, v := range []string{"ADMIN_TOKEN", "ADMIN_PERMISSIONS", "ADMIN_RATE_LIMIT_REQUESTS_PER_SECOND", "ADMIN_RATE_LIMIT_BURST"} {
+			os.Unsetenv(v)
+		}
+	}()
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.AdminToken)
+	assert.Equal(t, []string{"spawn", "teleport", "grant", "generate", "combat", "inspect", "undo", "restore", "config", "annotate"}, config.AdminPermissions)
+	assert.Equal(t, 2.0, config.AdminRateLimitRequestsPerSecond)
+	assert.Equal(t, 10, config.AdminRateLimitBurst)
+
+	os.Setenv("ADMIN_TOKEN", "too-short")
+	_, err = Load()
+	assert.ErrorContains(t, err, "admin token must be at least")
+
+	os.Setenv("ADMIN_TOKEN", strings.Repeat("k", 32))
+	os.Setenv("ADMIN_PERMISSIONS", "inspect,teleport")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inspect", "teleport"}, config.AdminPermissions)
+
+	os.Setenv("ADMIN_PERMISSIONS", "inspect,delete_world")
+	_, err = Load()
+	assert.ErrorContains(t, err, "unknown admin permission")
+
+	os.Setenv("ADMIN_PERMISSIONS", "inspect")
+	os.Setenv("ADMIN_RATE_LIMIT_BURST", "0")
+	_, err = Load()
+	assert.ErrorContains(t, err, "admin rate limit burst")
+}
+
+func TestLoad_DifficultyScaling(t *testing.T) {
+	clearTestEnv()
+	defer func() {
+		for _, v := range []string{"DIFFICULTY_MIN", "DIFFICULTY_MAX", "DIFFICULTY_MAX_ADJUSTMENT", "DIFFICULTY_OVERRIDES"} {
+			os.Unsetenv(v)
+		}
+	}()
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.True(t, config.DifficultyScalingEnabled)
+	assert.Equal(t, 1, config.DifficultyMin)
+	assert.Equal(t, 20, config.DifficultyMax)
+	assert.Equal(t, 3, config.DifficultyMaxAdjustment)
+	assert.Empty(t, config.DifficultyOverrides)
+
+	os.Setenv("DIFFICULTY_MIN", "3")
+	os.Setenv("DIFFICULTY_MAX", "15")
+	os.Setenv("DIFFICULTY_OVERRIDES", "quests=1,terrain=0")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3, config.DifficultyMin)
+	assert.Equal(t, 15, config.DifficultyMax)
+	assert.Equal(t, map[string]int{"quests": 1, "terrain": 0}, config.DifficultyOverrides)
+
+	os.Setenv("DIFFICULTY_MAX", "2")
+	_, err = Load()
+	assert.ErrorContains(t, err, "difficulty bounds")
+
+	os.Setenv("DIFFICULTY_MAX", "15")
+	os.Setenv("DIFFICULTY_MAX_ADJUSTMENT", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "difficulty max adjustment")
+}
+
+func TestLoad_SessionStore(t *testing.T) {
+	clearTestEnv()
+	defer func() {
+		for _, v := range []string{"SESSION_STORE_BACKEND", "REDIS_ADDR", "REDIS_DB", "REDIS_KEY_PREFIX", "INSTANCE_ID"} {
+			os.Unsetenv(v)
+		}
+	}()
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "memory", config.SessionStoreBackend)
+	assert.Equal(t, "localhost:6379", config.RedisAddr)
+	assert.Equal(t, "goldbox:", config.RedisKeyPrefix)
+	assert.Empty(t, config.InstanceID)
+
+	os.Setenv("SESSION_STORE_BACKEND", "redis")
+	os.Setenv("REDIS_ADDR", "redis.internal:6380")
+	os.Setenv("REDIS_DB", "2")
+	os.Setenv("INSTANCE_ID", "game-1")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "redis", config.SessionStoreBackend)
+	assert.Equal(t, "redis.internal:6380", config.RedisAddr)
+	assert.Equal(t, 2, config.RedisDB)
+	assert.Equal(t, "game-1", config.InstanceID)
+
+	os.Setenv("REDIS_DB", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "redis database")
+
+	os.Setenv("SESSION_STORE_BACKEND", "memcached")
+	_, err = Load()
+	assert.ErrorContains(t, err, "session store backend")
+}
+
+// clearPersistenceEnv removes persistence backend environment variables
+func clearPersistenceEnv() {
+	for _, v := range []string{
+		"PERSISTENCE_BACKEND", "PERSISTENCE_MODE", "GOLDBOX_PERSISTENCE_MODE", "SQLITE_DRIVER", "SQLITE_DSN",
+		"BACKUP_COUNT", "BACKUP_MAX_AGE", "BACKUP_INTERVAL", "BACKUP_VERIFY_INTERVAL",
+		"SNAPSHOT_COUNT", "SNAPSHOT_INTERVAL", "SNAPSHOT_COMPACT_AFTER", "SNAPSHOT_COMPACT_SPACING",
+		"PERSISTENCE_SECRET", "PERSISTENCE_PREVIOUS_SECRETS", "PERSISTENCE_ENCRYPT", "PERSISTENCE_ALLOW_UNSEALED",
+	} {
+		os.Unsetenv(v)
+	}
+}
+
 func TestConfig_OriginAllowed_ThreadSafety(t *testing.T) {
 	config := &Config{
 		EnableDevMode:  false,
@@ -490,3 +1479,38 @@ func TestConfig_OriginAllowed_ThreadSafety(t *testing.T) {
 		<-done
 	}
 }
+
+func TestLoad_Tracing(t *testing.T) {
+	clearTestEnv()
+	defer func() {
+		for _, v := range []string{"TRACING_EXPORTER", "TRACING_ENDPOINT", "TRACING_INSECURE", "TRACING_SAMPLE_RATIO"} {
+			os.Unsetenv(v)
+		}
+	}()
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "none", config.TracingExporter)
+	assert.Equal(t, "goldbox-rpg", config.TracingServiceName)
+	assert.Equal(t, 1.0, config.TracingSampleRatio)
+
+	os.Setenv("TRACING_EXPORTER", "otlp")
+	os.Setenv("TRACING_ENDPOINT", "collector:4318")
+	os.Setenv("TRACING_INSECURE", "true")
+	os.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "otlp", config.TracingExporter)
+	assert.Equal(t, "collector:4318", config.TracingEndpoint)
+	assert.True(t, config.TracingInsecure)
+	assert.Equal(t, 0.25, config.TracingSampleRatio)
+
+	os.Setenv("TRACING_SAMPLE_RATIO", "1.5")
+	_, err = Load()
+	assert.ErrorContains(t, err, "tracing sample ratio")
+
+	os.Setenv("TRACING_SAMPLE_RATIO", "1")
+	os.Setenv("TRACING_EXPORTER", "zipkin")
+	_, err = Load()
+	assert.ErrorContains(t, err, "tracing exporter")
+}
@@ -42,6 +42,9 @@
 // Persistence:
 //   - GOLDBOX_DATA_DIR: Data storage directory (default: "data")
 //   - GOLDBOX_AUTO_SAVE_INTERVAL: Auto-save frequency (default: 5m)
+//   - GOLDBOX_PERSISTENCE_MODE: snapshot, or event-sourced to also log
+//     changes between auto-saves (default: snapshot). PERSISTENCE_MODE is
+//     read when it is unset.
 //
 // # Validation
 //
@@ -51,6 +54,18 @@
 //   - Rate limit values must be positive
 //   - Retry configuration must be sensible
 //
+// # Live Reload
+//
+// CONFIG_FILE may name a file of KEY=value settings that take precedence
+// over the environment. Reload re-reads both, validates the result and
+// applies the reloadable settings (session timeout, log levels, rate limits,
+// retry policy and combat turn timer), notifying the functions registered
+// with Subscribe.
+// Watch reloads on SIGHUP:
+//
+//	cfg.Subscribe(func(cfg *config.Config, change config.ConfigChange) { ... })
+//	go cfg.Watch(ctx)
+//
 // # CORS Support
 //
 // Use OriginAllowed to check WebSocket origins:
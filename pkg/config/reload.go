@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// loadMu serializes Load, which exposes the config file settings to the
+	// getEnvAs* helpers through fileSettings
+	loadMu sync.Mutex
+
+	// fileSettings holds the settings read from the config file while Load
+	// runs
+	fileSettings map[string]string
+)
+
+// reloadableSettings are the settings Reload applies to a running server.
+// Other settings are read once at startup and need a restart to change.
+var reloadableSettings = map[string]bool{
+	"session_timeout":                        true,
+	"log_level":                              true,
+	"log_levels":                             true,
+	"rate_limit_requests_per_second":         true,
+	"rate_limit_burst":                       true,
+	"session_rate_limit_requests_per_second": true,
+	"session_rate_limit_burst":               true,
+	"session_rate_limit_method_weights":      true,
+	"retry_enabled":                          true,
+	"retry_max_attempts":                     true,
+	"retry_initial_delay":                    true,
+	"retry_max_delay":                        true,
+	"retry_backoff_multiplier":               true,
+	"retry_jitter_percent":                   true,
+	"turn_timeout":                           true,
+	"turn_warning":                           true,
+	"afk_skip_limit":                         true,
+}
+
+// ConfigChange describes what a reload changed, naming settings by their
+// JSON names.
+type ConfigChange struct {
+	// Applied lists the changed settings now in effect
+	Applied []string `json:"applied"`
+
+	// Pending lists the changed settings that take effect only after a
+	// restart; the running configuration keeps their old values
+	Pending []string `json:"pending"`
+}
+
+// Has reports whether the reload applied a change to setting.
+func (c ConfigChange) Has(setting string) bool {
+	for _, applied := range c.Applied {
+		if applied == setting {
+			return true
+		}
+	}
+	return false
+}
+
+// ReloadSubscriber is notified after a reload applied changes. cfg is the
+// running configuration, already updated; subscribers should read it
+// through its thread-safe getters.
+type ReloadSubscriber func(cfg *Config, change ConfigChange)
+
+// reloadState holds the subscribers of a Config
+type reloadState struct {
+	mu          sync.Mutex
+	subscribers []ReloadSubscriber
+}
+
+// Subscribe registers fn to be called after every reload that applied at
+// least one change. Subscribers run in registration order on the
+// goroutine that called Reload.
+func (c *Config) Subscribe(fn ReloadSubscriber) {
+	c.reload.mu.Lock()
+	defer c.reload.mu.Unlock()
+	c.reload.subscribers = append(c.reload.subscribers, fn)
+}
+
+// Reload re-reads the environment and config file and validates the
+// result. If it is valid, the reloadable settings that changed are applied
+// and the subscribers notified; otherwise the running configuration is left
+// unchanged and the validation error returned. Reloads are serialized.
+func (c *Config) Reload() (ConfigChange, error) {
+	c.reload.mu.Lock()
+	defer c.reload.mu.Unlock()
+
+	fresh, err := Load()
+	if err != nil {
+		return ConfigChange{}, err
+	}
+
+	change := c.apply(fresh)
+	logrus.WithFields(logrus.Fields{
+		"function": "Reload",
+		"package":  "config",
+		"applied":  change.Applied,
+		"pending":  change.Pending,
+	}).Info("configuration reloaded")
+
+	if len(change.Applied) > 0 {
+		for _, fn := range c.reload.subscribers {
+			fn(c, change)
+		}
+	}
+	return change, nil
+}
+
+// apply copies the reloadable settings of fresh that differ from c into c
+// and reports every setting that differs.
+func (c *Config) apply(fresh *Config) ConfigChange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var change ConfigChange
+	current := reflect.ValueOf(c).Elem()
+	updated := reflect.ValueOf(fresh).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		if !field.IsExported() || reflect.DeepEqual(current.Field(i).Interface(), updated.Field(i).Interface()) {
+			continue
+		}
+
+		setting := settingName(field)
+		if !reloadableSettings[setting] {
+			change.Pending = append(change.Pending, setting)
+			continue
+		}
+		current.Field(i).Set(updated.Field(i))
+		change.Applied = append(change.Applied, setting)
+	}
+	return change
+}
+
+// settingName returns the JSON name of a Config field, or the field name
+// for fields hidden from JSON.
+func settingName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// Watch reloads the configuration each time the process receives SIGHUP,
+// until ctx is cancelled. Failed reloads are logged and leave the running
+// configuration unchanged.
+func (c *Config) Watch(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if _, err := c.Reload(); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"function": "Watch",
+					"package":  "config",
+					"error":    err.Error(),
+				}).Error("configuration reload rejected, keeping the running configuration")
+			}
+		}
+	}
+}
+
+// readConfigFile reads KEY=value settings from path, one per line. Blank
+// lines and lines starting with # are ignored, and values may be quoted. An
+// empty path means no config file.
+func readConfigFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	settings := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("config file %s line %d: expected KEY=value", path, lineNumber)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		settings[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return settings, nil
+}
+
+// lookupSetting returns the value of the setting named key, from the config
+// file if it sets key and from the environment otherwise.
+func lookupSetting(key string) string {
+	if value, exists := fileSettings[key]; exists {
+		return value
+	}
+	return os.Getenv(key)
+}
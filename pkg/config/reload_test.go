@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes content to a config file and points CONFIG_FILE at it
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "goldbox.env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	t.Setenv("SESSION_TIMEOUT", "10m")
+	t.Setenv("LOG_LEVEL", "warn")
+	path := writeConfigFile(t, `
+# Settings changed while the server runs
+SESSION_TIMEOUT = 45m
+LOG_LEVELS="pcg=debug"
+`)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, path, cfg.ConfigFile)
+	assert.Equal(t, 45*time.Minute, cfg.SessionTimeout, "the file takes precedence over the environment")
+	assert.Equal(t, "pcg=debug", cfg.LogLevels, "quotes are removed")
+	assert.Equal(t, "warn", cfg.LogLevel, "settings missing from the file come from the environment")
+
+	writeConfigFile(t, "SESSION_TIMEOUT\n")
+	_, err = Load()
+	assert.ErrorContains(t, err, "line 1: expected KEY=value")
+
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.env"))
+	_, err = Load()
+	assert.ErrorContains(t, err, "failed to read config file")
+}
+
+func TestConfig_Reload(t *testing.T) {
+	path := writeConfigFile(t, "SESSION_TIMEOUT=30m\nSESSION_RATE_LIMIT_BURST=20\n")
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	var notified []ConfigChange
+	cfg.Subscribe(func(current *Config, change ConfigChange) {
+		assert.Same(t, cfg, current)
+		notified = append(notified, change)
+	})
+
+	change, err := cfg.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, change.Applied)
+	assert.Empty(t, notified, "subscribers are only told about changes")
+
+	require.NoError(t, os.WriteFile(path, []byte("SESSION_TIMEOUT=5m\nSESSION_RATE_LIMIT_BURST=40\nSERVER_PORT=9090\n"), 0o600))
+	change, err = cfg.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session_timeout", "session_rate_limit_burst"}, change.Applied)
+	assert.Equal(t, []string{"server_port"}, change.Pending)
+	assert.True(t, change.Has("session_timeout"))
+	assert.False(t, change.Has("server_port"))
+	assert.Equal(t, 5*time.Minute, cfg.GetSessionTimeout())
+	assert.Equal(t, 40, cfg.SessionRateLimitBurst)
+	assert.Equal(t, 8080, cfg.ServerPort, "settings pending a restart keep their value")
+	require.Len(t, notified, 1)
+	assert.Equal(t, change, notified[0])
+
+	// An invalid configuration is rejected and the running one kept
+	require.NoError(t, os.WriteFile(path, []byte("SESSION_TIMEOUT=5s\nLOG_LEVEL=loud\n"), 0o600))
+	_, err = cfg.Reload()
+	assert.ErrorContains(t, err, "invalid configuration")
+	assert.Equal(t, 5*time.Minute, cfg.GetSessionTimeout())
+	assert.Len(t, notified, 1)
+}
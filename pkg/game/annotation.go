@@ -0,0 +1,91 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// AnnotationKind names what a map annotation marks.
+type AnnotationKind string
+
+// Map annotation kinds
+const (
+	AnnotationNote   AnnotationKind = "note"   // Free text pinned to a tile
+	AnnotationMarker AnnotationKind = "marker" // A symbol on a tile, such as an ambush or a hidden cache
+	AnnotationRegion AnnotationKind = "region" // A label over a rectangle of tiles
+)
+
+// AnnotationVisibility names who may see a map annotation.
+type AnnotationVisibility string
+
+// Map annotation visibilities
+const (
+	AnnotationGMOnly  AnnotationVisibility = "gm"      // Only the game master
+	AnnotationPlayers AnnotationVisibility = "players" // The game master and every player
+)
+
+// MapAnnotation is a note, marker or region label a game master attaches
+// to a level of the map. Notes and markers sit on the tile at X, Y;
+// regions cover Width by Height tiles from it.
+type MapAnnotation struct {
+	ID         string               `yaml:"id" json:"id"`
+	Kind       AnnotationKind       `yaml:"kind" json:"kind"`
+	Level      int                  `yaml:"level" json:"level"`
+	X          int                  `yaml:"x" json:"x"`
+	Y          int                  `yaml:"y" json:"y"`
+	Width      int                  `yaml:"width,omitempty" json:"width,omitempty"`   // Regions only, in tiles
+	Height     int                  `yaml:"height,omitempty" json:"height,omitempty"` // Regions only, in tiles
+	Text       string               `yaml:"text,omitempty" json:"text,omitempty"`
+	Symbol     string               `yaml:"symbol,omitempty" json:"symbol,omitempty"` // Markers only, such as "skull" or "chest"
+	Visibility AnnotationVisibility `yaml:"visibility" json:"visibility"`
+	Author     string               `yaml:"author,omitempty" json:"author,omitempty"`
+	CreatedAt  time.Time            `yaml:"created_at" json:"created_at"`
+}
+
+// Validate checks that the annotation has a known kind and visibility,
+// something to show and, given its level, that it lies on the level.
+// Markers need a symbol or text, notes and regions text, and regions a
+// size; notes and markers must not have one.
+func (a MapAnnotation) Validate(level *Level) error {
+	switch a.Kind {
+	case AnnotationNote, AnnotationRegion:
+		if a.Text == "" {
+			return fmt.Errorf("a %s annotation needs text", a.Kind)
+		}
+	case AnnotationMarker:
+		if a.Text == "" && a.Symbol == "" {
+			return fmt.Errorf("a marker annotation needs a symbol or text")
+		}
+	default:
+		return fmt.Errorf("unknown annotation kind %q", a.Kind)
+	}
+
+	switch a.Visibility {
+	case AnnotationGMOnly, AnnotationPlayers:
+	default:
+		return fmt.Errorf("unknown annotation visibility %q", a.Visibility)
+	}
+
+	if a.Kind == AnnotationRegion {
+		if a.Width < 1 || a.Height < 1 {
+			return fmt.Errorf("a region annotation needs a positive width and height, got %dx%d", a.Width, a.Height)
+		}
+	} else if a.Width != 0 || a.Height != 0 {
+		return fmt.Errorf("only region annotations have a size")
+	}
+
+	if level == nil {
+		return nil
+	}
+	width, height := max(a.Width, 1), max(a.Height, 1)
+	if a.X < 0 || a.Y < 0 || a.X+width > level.Width || a.Y+height > level.Height {
+		return fmt.Errorf("annotation at (%d,%d) size %dx%d lies outside the %dx%d level", a.X, a.Y, width, height, level.Width, level.Height)
+	}
+	return nil
+}
+
+// VisibleTo reports whether the annotation may be shown to the game master
+// (gm true) or to players.
+func (a MapAnnotation) VisibleTo(gm bool) bool {
+	return gm || a.Visibility == AnnotationPlayers
+}
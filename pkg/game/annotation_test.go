@@ -0,0 +1,37 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestMapAnnotation_Validate(t *testing.T) {
+	level := &Level{ID: "crypt", Width: 10, Height: 8}
+	tests := []struct {
+		name       string
+		annotation MapAnnotation
+		wantErr    bool
+	}{
+		{"note", MapAnnotation{Kind: AnnotationNote, X: 9, Y: 7, Text: "Lich", Visibility: AnnotationGMOnly}, false},
+		{"marker with symbol", MapAnnotation{Kind: AnnotationMarker, X: 1, Y: 1, Symbol: "skull", Visibility: AnnotationPlayers}, false},
+		{"region", MapAnnotation{Kind: AnnotationRegion, X: 6, Y: 4, Width: 4, Height: 4, Text: "Ossuary", Visibility: AnnotationPlayers}, false},
+		{"note without text", MapAnnotation{Kind: AnnotationNote, Visibility: AnnotationGMOnly}, true},
+		{"marker without symbol or text", MapAnnotation{Kind: AnnotationMarker, Visibility: AnnotationGMOnly}, true},
+		{"region without size", MapAnnotation{Kind: AnnotationRegion, Text: "Hall", Visibility: AnnotationGMOnly}, true},
+		{"note with size", MapAnnotation{Kind: AnnotationNote, Width: 2, Height: 2, Text: "Hall", Visibility: AnnotationGMOnly}, true},
+		{"region off the level", MapAnnotation{Kind: AnnotationRegion, X: 7, Y: 4, Width: 4, Height: 4, Text: "Ossuary", Visibility: AnnotationGMOnly}, true},
+		{"unknown kind", MapAnnotation{Kind: "arrow", Text: "North", Visibility: AnnotationGMOnly}, true},
+		{"unknown visibility", MapAnnotation{Kind: AnnotationNote, Text: "Lich", Visibility: "party"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.annotation.Validate(level); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	secret := MapAnnotation{Visibility: AnnotationGMOnly}
+	if secret.VisibleTo(false) || !secret.VisibleTo(true) {
+		t.Error("GM-only annotations are shown to the game master alone")
+	}
+}
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Background describes a character's life before adventuring. A background
+// grants skill bonuses, starting items and faction standing at character
+// creation, and names the kind of personal hook quest that opens the
+// character's story.
+type Background string
+
+const (
+	// BackgroundNone is used for characters created without a background.
+	BackgroundNone Background = ""
+
+	BackgroundSoldier Background = "soldier" // Veteran of the city watch
+	BackgroundScholar Background = "scholar" // Student of the arcane college
+	BackgroundOutcast Background = "outcast" // Exile surviving at the edges
+	BackgroundNoble   Background = "noble"   // Heir of a minor noble house
+
+	// BackgroundRandom asks the character creator to pick one of the
+	// selectable backgrounds with its seeded random number generator.
+	BackgroundRandom Background = "random"
+)
+
+// BackgroundConfig defines what a background grants a new character.
+//
+// Fields:
+//   - Background: The background identifier
+//   - Name: Human-readable display name
+//   - Description: Short description of the character's past
+//   - SkillBonuses: Skill name to starting bonus
+//   - StartingItems: Item database IDs added to the inventory
+//   - FactionReputation: Faction ID to starting reputation
+//   - HookQuestType: Quest type of the personal hook quest, matching pcg.QuestType
+type BackgroundConfig struct {
+	Background        Background     `yaml:"background_id"`
+	Name              string         `yaml:"background_name"`
+	Description       string         `yaml:"background_description"`
+	SkillBonuses      map[string]int `yaml:"background_skill_bonuses"`
+	StartingItems     []string       `yaml:"background_starting_items"`
+	FactionReputation map[string]int `yaml:"background_faction_reputation"`
+	HookQuestType     string         `yaml:"background_hook_quest_type"`
+}
+
+// backgroundConfigs holds the definition of every selectable background.
+var backgroundConfigs = map[Background]BackgroundConfig{
+	BackgroundSoldier: {
+		Background:        BackgroundSoldier,
+		Name:              "Soldier",
+		Description:       "Served with the city watch before taking up the adventuring life",
+		SkillBonuses:      map[string]int{"Combat Tactics": 2, "Athletics": 1},
+		StartingItems:     []string{"item_rank_insignia"},
+		FactionReputation: map[string]int{"city_watch": 20, "thieves_guild": -10},
+		HookQuestType:     "kill",
+	},
+	BackgroundScholar: {
+		Background:        BackgroundScholar,
+		Name:              "Scholar",
+		Description:       "Studied lost lore at the arcane college",
+		SkillBonuses:      map[string]int{"Arcane Knowledge": 2, "History": 1},
+		StartingItems:     []string{"item_scholars_tome"},
+		FactionReputation: map[string]int{"arcane_college": 20},
+		HookQuestType:     "explore",
+	},
+	BackgroundOutcast: {
+		Background:        BackgroundOutcast,
+		Name:              "Outcast",
+		Description:       "Driven from home and forced to survive alone",
+		SkillBonuses:      map[string]int{"Survival": 2, "Stealth": 1},
+		StartingItems:     []string{"item_tattered_cloak"},
+		FactionReputation: map[string]int{"thieves_guild": 10, "noble_houses": -20},
+		HookQuestType:     "fetch",
+	},
+	BackgroundNoble: {
+		Background:        BackgroundNoble,
+		Name:              "Noble",
+		Description:       "Born to a minor noble house with a name to uphold",
+		SkillBonuses:      map[string]int{"Leadership": 2, "Etiquette": 1},
+		StartingItems:     []string{"item_signet_ring"},
+		FactionReputation: map[string]int{"noble_houses": 20, "city_watch": 5},
+		HookQuestType:     "escort",
+	},
+}
+
+// Backgrounds returns the selectable backgrounds in a fixed order, so that
+// random selection from a seeded generator is reproducible.
+func Backgrounds() []Background {
+	return []Background{BackgroundSoldier, BackgroundScholar, BackgroundOutcast, BackgroundNoble}
+}
+
+// GetBackgroundConfig returns the definition of a selectable background.
+// The returned config is a copy and may be modified by the caller.
+func GetBackgroundConfig(background Background) (BackgroundConfig, bool) {
+	config, exists := backgroundConfigs[background]
+	if !exists {
+		return BackgroundConfig{}, false
+	}
+
+	config.SkillBonuses = copyIntMap(config.SkillBonuses)
+	config.FactionReputation = copyIntMap(config.FactionReputation)
+	config.StartingItems = append([]string(nil), config.StartingItems...)
+	return config, true
+}
+
+// ParseBackground converts a background name to a Background. The empty
+// string selects no background and "random" defers the choice to the
+// character creator.
+func ParseBackground(name string) (Background, error) {
+	background := Background(strings.ToLower(strings.TrimSpace(name)))
+	if background == BackgroundNone || background == BackgroundRandom {
+		return background, nil
+	}
+	if _, exists := backgroundConfigs[background]; !exists {
+		return BackgroundNone, fmt.Errorf("invalid background: %s", name)
+	}
+	return background, nil
+}
+
+// copyIntMap returns an independent copy of m, or nil if m is nil.
+func copyIntMap(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]int, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
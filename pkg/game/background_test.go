@@ -0,0 +1,110 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCharacterCreator_CreateCharacter_Background(t *testing.T) {
+	creator := NewCharacterCreatorWithSeed(7)
+
+	result := creator.CreateCharacter(CharacterCreationConfig{
+		Name:            "Veteran",
+		Class:           ClassFighter,
+		AttributeMethod: "standard",
+		Background:      BackgroundSoldier,
+	})
+	if !result.Success {
+		t.Fatalf("Character creation failed: %v", result.Errors)
+	}
+
+	if result.Background == nil || result.Background.Background != BackgroundSoldier {
+		t.Fatalf("Expected soldier background in result, got %+v", result.Background)
+	}
+	if result.PlayerData.Background != BackgroundSoldier {
+		t.Errorf("Expected player background soldier, got %q", result.PlayerData.Background)
+	}
+	if result.PlayerData.Skills["Combat Tactics"] != 2 {
+		t.Errorf("Expected Combat Tactics bonus 2, got %d", result.PlayerData.Skills["Combat Tactics"])
+	}
+	if result.PlayerData.Reputation["city_watch"] != 20 {
+		t.Errorf("Expected city_watch reputation 20, got %d", result.PlayerData.Reputation["city_watch"])
+	}
+
+	found := false
+	for _, item := range result.PlayerData.Inventory {
+		if item.ID == "item_rank_insignia" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected background item in inventory")
+	}
+
+	// Modifying the character must not leak into the shared definitions
+	result.PlayerData.Skills["Combat Tactics"] = 99
+	config, _ := GetBackgroundConfig(BackgroundSoldier)
+	if config.SkillBonuses["Combat Tactics"] != 2 {
+		t.Error("Background definition was modified through the character")
+	}
+}
+
+func TestCharacterCreator_CreateCharacter_RandomBackgroundIsSeeded(t *testing.T) {
+	create := func(seed int64) *Player {
+		result := NewCharacterCreatorWithSeed(seed).CreateCharacter(CharacterCreationConfig{
+			Name:            "Wanderer",
+			Class:           ClassFighter,
+			AttributeMethod: "standard",
+			Background:      BackgroundRandom,
+		})
+		if !result.Success {
+			t.Fatalf("Character creation failed: %v", result.Errors)
+		}
+		return result.PlayerData
+	}
+
+	first, second := create(1234), create(1234)
+	if first.Background == BackgroundNone || first.Background == BackgroundRandom {
+		t.Fatalf("Expected a concrete background, got %q", first.Background)
+	}
+	if first.Background != second.Background || !reflect.DeepEqual(first.Skills, second.Skills) {
+		t.Errorf("Same seed produced different backgrounds: %q and %q", first.Background, second.Background)
+	}
+
+	seen := map[Background]bool{}
+	for seed := int64(0); seed < 50; seed++ {
+		seen[create(seed).Background] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Expected random backgrounds to vary across seeds, got %v", seen)
+	}
+}
+
+func TestParseBackground(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Background
+		wantErr  bool
+	}{
+		{"", BackgroundNone, false},
+		{"Noble", BackgroundNoble, false},
+		{" random ", BackgroundRandom, false},
+		{"pirate", BackgroundNone, true},
+	}
+
+	for _, tt := range tests {
+		background, err := ParseBackground(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBackground(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if background != tt.expected {
+			t.Errorf("ParseBackground(%q) = %q, want %q", tt.input, background, tt.expected)
+		}
+	}
+
+	for _, background := range Backgrounds() {
+		if _, exists := GetBackgroundConfig(background); !exists {
+			t.Errorf("Background %q has no definition", background)
+		}
+	}
+}
@@ -75,8 +75,17 @@ type Character struct {
 	Inventory []Item                 `yaml:"char_inventory"` // Carried items
 	Gold      int                    `yaml:"char_gold"`      // Currency amount
 
+	// Background and skills
+	Background Background     `yaml:"char_background,omitempty"` // Life before adventuring
+	Skills     map[string]int `yaml:"char_skills,omitempty"`     // Skill name to bonus
+	Deity      string         `yaml:"char_deity,omitempty"`      // Patron deity ID
+
+	// Appearance
+	Portrait *Portrait `yaml:"char_portrait,omitempty"` // Portrait and token descriptors for clients
+
 	// Effect management
-	EffectManager *EffectManager `yaml:"-"` // Manages active effects on character
+	EffectManager *EffectManager `yaml:"-"`                          // Manages active effects on character
+	Resistances   Resistances    `yaml:"char_resistances,omitempty"` // Resistance, immunity and vulnerability to effects and damage
 
 	active bool     `yaml:"char_active"` // Whether character is active in game
 	tags   []string `yaml:"char_tags"`   // Special attributes or markers
@@ -124,6 +133,11 @@ func (c *Character) Clone() *Character {
 		Equipment:       make(map[EquipmentSlot]Item),
 		Inventory:       make([]Item, len(c.Inventory)),
 		Gold:            c.Gold,
+		Background:      c.Background,
+		Skills:          copyIntMap(c.Skills),
+		Deity:           c.Deity,
+		Portrait:        c.Portrait.Clone(),
+		Resistances:     c.Resistances.Clone(),
 		active:          c.active,
 		tags:            make([]string, len(c.tags)),
 	}
@@ -727,6 +741,51 @@ func (c *Character) GetTags() []string {
 	return append([]string{}, c.tags...) // Return copy to prevent modification
 }
 
+// AddTag adds tag to the character's tags. Adding a tag the character
+// already has is a no-op.
+//
+// Parameters:
+//
+//	tag - The tag to add, e.g. "boss"
+func (c *Character) AddTag(tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, existing := range c.tags {
+		if existing == tag {
+			return
+		}
+	}
+	c.tags = append(c.tags, tag)
+}
+
+// RemoveTag removes tag from the character's tags, if present.
+//
+// Parameters:
+//
+//	tag - The tag to remove
+func (c *Character) RemoveTag(tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, existing := range c.tags {
+		if existing == tag {
+			c.tags = append(c.tags[:i:i], c.tags[i+1:]...)
+			return
+		}
+	}
+}
+
+// HasTag reports whether the character has tag.
+func (c *Character) HasTag(tag string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, existing := range c.tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // ToJSON serializes the Character struct to JSON format with thread safety.
 //
 // This method acquires a read lock on the character to ensure safe concurrent access
@@ -1447,12 +1506,40 @@ func (c *Character) GetEffectManager() *EffectManager {
 
 // EffectHolder interface implementation - delegates to EffectManager
 
-// AddEffect applies an effect to this character
+// AddEffect applies an effect to this character, reduced by its
+// resistances. Immunity and reflection are reported as errors.
 func (c *Character) AddEffect(effect *Effect) error {
+	resolution, err := c.ResolveEffect(effect)
+	if err != nil {
+		return err
+	}
+	return resolution.err(effect.Type)
+}
+
+// ResolveEffect applies an effect to this character, reduced by its
+// resistances, and reports how the application was resolved.
+func (c *Character) ResolveEffect(effect *Effect) (EffectResolution, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.ensureEffectManager()
-	return c.EffectManager.AddEffect(effect)
+	return c.EffectManager.ResolveEffect(effect, c.Resistances.Against(effect))
+}
+
+// DispelEffects removes up to count of this character's effects that
+// dispelType can dispel, highest priority first, and returns their IDs.
+func (c *Character) DispelEffects(dispelType DispelType, count int) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ensureEffectManager()
+	return c.EffectManager.DispelEffects(dispelType, count)
+}
+
+// ResistDamage returns damage of damageType after this character's
+// resistance to it.
+func (c *Character) ResistDamage(damageType DamageType, damage int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Resistances.ReduceDamage(damageType, damage)
 }
 
 // RemoveEffect removes an effect from this character
@@ -1572,6 +1659,7 @@ func (c *Character) AddExperience(xp int64) (bool, error) {
 	newLevel := c.calculateLevelFromExperience()
 	if newLevel > oldLevel {
 		c.Level = newLevel
+		c.THAC0 = rulesetTHAC0(c.Class, newLevel, c.THAC0)
 		// Emit level up event using the existing event system
 		if defaultEventSystem != nil {
 			emitLevelUpEvent(c.ID, oldLevel, newLevel)
@@ -1659,8 +1747,12 @@ func (c *Character) calculateLevelFromExperience() int {
 }
 
 // getExperienceRequiredForLevel returns the total experience needed for a given level
-// Returns -1 if level is beyond maximum
+// Returns -1 if level is beyond maximum. The active ruleset's XP thresholds
+// replace the built-in table when one is set.
 func (c *Character) getExperienceRequiredForLevel(level int) int64 {
+	if rules := ActiveRuleset(); rules != nil {
+		return rules.ExperienceForLevel(c.Class, level)
+	}
 	if level <= 1 {
 		return 0
 	}
@@ -3,6 +3,7 @@ package game
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 )
 
@@ -13,10 +14,13 @@ import (
 // Fields:
 //   - Name: The desired name for the character (must be unique and non-empty)
 //   - Class: The character class selection from available CharacterClass enum
+//   - Classes: All classes of a multi-classed character (optional, replaces Class)
 //   - AttributeMethod: Method for generating attributes ("roll", "pointbuy", "standard")
 //   - CustomAttributes: Optional custom attribute values (used with "custom" method)
 //   - StartingEquipment: Whether to equip character with class-appropriate gear
 //   - StartingGold: Amount of starting gold (0 = use class default)
+//   - Deity: ID of the patron deity in Pantheon (optional, picked for clerics and paladins)
+//   - Pantheon: Deities of the world the character is created in
 //
 // Related types:
 //   - CharacterClass: Enum defining available character classes
@@ -25,10 +29,14 @@ import (
 type CharacterCreationConfig struct {
 	Name              string                 `yaml:"creation_name"`               // Character name
 	Class             CharacterClass         `yaml:"creation_class"`              // Character class
+	Classes           []CharacterClass       `yaml:"creation_classes,omitempty"`  // Classes of a multi-classed character
 	AttributeMethod   string                 `yaml:"creation_attr_method"`        // Attribute generation method
 	CustomAttributes  map[string]int         `yaml:"creation_custom_attrs"`       // Custom attribute values
 	StartingEquipment bool                   `yaml:"creation_starting_equipment"` // Include starting equipment
 	StartingGold      int                    `yaml:"creation_starting_gold"`      // Starting gold amount
+	Background        Background             `yaml:"creation_background"`         // Background, or BackgroundRandom
+	Deity             string                 `yaml:"creation_deity,omitempty"`    // Patron deity ID
+	Pantheon          *Pantheon              `yaml:"-"`                           // Deities to choose from
 	AdditionalData    map[string]interface{} `yaml:"creation_additional_data"`    // Additional character data
 }
 
@@ -47,14 +55,16 @@ type CharacterCreationConfig struct {
 //   - Character: The created character instance
 //   - CharacterCreationConfig: Input configuration used for creation
 type CharacterCreationResult struct {
-	Character      *Character     `yaml:"result_character"`       // Created character
-	Success        bool           `yaml:"result_success"`         // Creation success status
-	Errors         []string       `yaml:"result_errors"`          // Error messages
-	Warnings       []string       `yaml:"result_warnings"`        // Warning messages
-	CreationTime   time.Time      `yaml:"result_creation_time"`   // When created
-	GeneratedStats map[string]int `yaml:"result_generated_stats"` // Final attribute values
-	StartingItems  []Item         `yaml:"result_starting_items"`  // Starting equipment
-	PlayerData     *Player        `yaml:"result_player_data"`     // Player-specific data if applicable
+	Character      *Character        `yaml:"result_character"`       // Created character
+	Success        bool              `yaml:"result_success"`         // Creation success status
+	Errors         []string          `yaml:"result_errors"`          // Error messages
+	Warnings       []string          `yaml:"result_warnings"`        // Warning messages
+	CreationTime   time.Time         `yaml:"result_creation_time"`   // When created
+	GeneratedStats map[string]int    `yaml:"result_generated_stats"` // Final attribute values
+	StartingItems  []Item            `yaml:"result_starting_items"`  // Starting equipment
+	PlayerData     *Player           `yaml:"result_player_data"`     // Player-specific data if applicable
+	Background     *BackgroundConfig `yaml:"result_background"`      // Applied background, if any
+	Deity          *Deity            `yaml:"result_deity"`           // Patron deity, if any
 }
 
 // CharacterCreator handles the creation of new characters with validation and configuration.
@@ -146,9 +156,24 @@ func (cc *CharacterCreator) CreateCharacter(config CharacterCreationConfig) Char
 
 	character := cc.buildBaseCharacter(config, attributes)
 	cc.calculateDerivedStats(character, config.Class)
+	if len(config.Classes) > 0 {
+		cc.applyMultiClassStats(character, config.Classes)
+	}
 
 	cc.applyStartingEquipment(config, character, &result)
+	if err := cc.applyBackground(config, character, &result); err != nil {
+		return result
+	}
+	if err := cc.applyDeity(config, character, &result); err != nil {
+		return result
+	}
 	player := cc.createPlayerData(character)
+	for _, class := range config.Classes {
+		player.Classes = append(player.Classes, ClassLevel{Class: class, Level: 1})
+	}
+	if result.Background != nil {
+		player.Reputation = copyIntMap(result.Background.FactionReputation)
+	}
 
 	cc.finalizeCreationResult(character, player, attributes, &result)
 	return result
@@ -183,14 +208,39 @@ func (cc *CharacterCreator) processAttributeGeneration(config CharacterCreationC
 		return nil, err
 	}
 
-	if err := cc.validateClassRequirements(config.Class, attributes); err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("class requirements not met: %v", err))
-		return nil, err
+	for _, class := range creationClasses(config) {
+		if err := cc.validateClassRequirements(class, attributes); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("class requirements not met: %v", err))
+			return nil, err
+		}
 	}
 
 	return attributes, nil
 }
 
+// creationClasses returns every class the character is created with
+func creationClasses(config CharacterCreationConfig) []CharacterClass {
+	if len(config.Classes) > 0 {
+		return config.Classes
+	}
+	return []CharacterClass{config.Class}
+}
+
+// applyMultiClassStats averages the starting hit points of a multi-classed
+// character over its classes and gives it the best THAC0 among them.
+func (cc *CharacterCreator) applyMultiClassStats(character *Character, classes []CharacterClass) {
+	character.MaxHP = max(averageBaseHP(classes, character.Constitution), 1)
+	character.HP = character.MaxHP
+	for _, class := range classes {
+		character.THAC0 = min(character.THAC0, rulesetTHAC0(class, character.Level, character.THAC0))
+	}
+	names := make([]string, len(classes))
+	for i, class := range classes {
+		names[i] = class.String()
+	}
+	character.Description = fmt.Sprintf("A %s adventurer", strings.Join(names, "/"))
+}
+
 // buildBaseCharacter creates a new Character instance with the specified configuration and attributes.
 func (cc *CharacterCreator) buildBaseCharacter(config CharacterCreationConfig, attributes map[string]int) *Character {
 	return &Character{
@@ -223,6 +273,107 @@ func (cc *CharacterCreator) applyStartingEquipment(config CharacterCreationConfi
 	}
 }
 
+// applyBackground grants the configured background's skill bonuses and
+// starting items to the character. BackgroundRandom picks a background with
+// the creator's seeded generator, so the choice is reproducible from the seed.
+func (cc *CharacterCreator) applyBackground(config CharacterCreationConfig, character *Character, result *CharacterCreationResult) error {
+	background := config.Background
+	if background == BackgroundNone {
+		return nil
+	}
+	if background == BackgroundRandom {
+		choices := Backgrounds()
+		background = choices[cc.rng.Intn(len(choices))]
+	}
+
+	backgroundConfig, exists := GetBackgroundConfig(background)
+	if !exists {
+		err := fmt.Errorf("invalid background: %s", background)
+		result.Errors = append(result.Errors, err.Error())
+		return err
+	}
+
+	character.Background = background
+	character.Skills = copyIntMap(backgroundConfig.SkillBonuses)
+	for _, itemID := range backgroundConfig.StartingItems {
+		item, exists := cc.itemDatabase[itemID]
+		if !exists {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("background item not found: %s", itemID))
+			continue
+		}
+		character.Inventory = append(character.Inventory, item)
+		result.StartingItems = append(result.StartingItems, item)
+	}
+
+	result.Background = &backgroundConfig
+	return nil
+}
+
+// applyDeity binds the character to a patron deity of config.Pantheon.
+// Clerics and paladins without a chosen deity get one picked with the
+// creator's seeded generator; paladins may only serve good deities. Divine
+// characters created with starting equipment also receive the deity's holy
+// symbol. Without a pantheon no deity is assigned.
+func (cc *CharacterCreator) applyDeity(config CharacterCreationConfig, character *Character, result *CharacterCreationResult) error {
+	classes := config.Classes
+	if len(classes) == 0 {
+		classes = []CharacterClass{config.Class}
+	}
+	divine, patronClass := false, config.Class
+	for _, class := range classes {
+		if RequiresDeity(class) {
+			divine = true
+		}
+		if class == ClassPaladin {
+			patronClass = ClassPaladin
+		}
+	}
+
+	var deity *Deity
+	switch {
+	case config.Deity != "":
+		if config.Pantheon == nil {
+			err := fmt.Errorf("no pantheon to choose deity %s from", config.Deity)
+			result.Errors = append(result.Errors, err.Error())
+			return err
+		}
+		chosen, exists := config.Pantheon.Get(config.Deity)
+		if !exists {
+			err := fmt.Errorf("invalid deity: %s", config.Deity)
+			result.Errors = append(result.Errors, err.Error())
+			return err
+		}
+		if patronClass == ClassPaladin && chosen.Alignment != DeityGood {
+			err := fmt.Errorf("paladins cannot serve %s deity %s", chosen.Alignment, chosen.ID)
+			result.Errors = append(result.Errors, err.Error())
+			return err
+		}
+		deity = chosen
+	case divine && config.Pantheon != nil:
+		patrons := config.Pantheon.Patrons(patronClass)
+		if len(patrons) == 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("no deity in the pantheon accepts %s", patronClass))
+			return nil
+		}
+		deity = &patrons[cc.rng.Intn(len(patrons))]
+	default:
+		return nil
+	}
+
+	patron := *deity
+	patron.Domains = append([]string(nil), deity.Domains...)
+	patron.Dogma = append([]string(nil), deity.Dogma...)
+	character.Deity = patron.ID
+	if divine && config.StartingEquipment {
+		symbol := holySymbolItem(&patron)
+		character.Inventory = append(character.Inventory, symbol)
+		result.StartingItems = append(result.StartingItems, symbol)
+	}
+
+	result.Deity = &patron
+	return nil
+}
+
 // createPlayerData creates player-specific data associated with the character.
 func (cc *CharacterCreator) createPlayerData(character *Character) *Player {
 	return &Player{
@@ -438,10 +589,20 @@ func (cc *CharacterCreator) validateConfig(config CharacterCreationConfig) error
 		return fmt.Errorf("invalid attribute method: %s", config.AttributeMethod)
 	}
 
+	if len(config.Classes) > 0 {
+		if config.Classes[0] != config.Class {
+			return fmt.Errorf("class must be the first of the multi-class classes")
+		}
+		if err := ValidateMultiClass(config.Classes); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // validateClassRequirements checks if generated attributes meet class requirements.
+// The class restrictions of the active ruleset replace the built-in requirements.
 //
 // Parameters:
 //   - class: Character class to validate against
@@ -455,6 +616,10 @@ func (cc *CharacterCreator) validateClassRequirements(class CharacterClass, attr
 		return fmt.Errorf("unknown character class: %v", class)
 	}
 
+	if rules := ActiveRuleset(); rules != nil {
+		return rules.CheckClass(class, attributes)
+	}
+
 	if attributes["strength"] < classConfig.Requirements.MinStr {
 		return fmt.Errorf("insufficient strength for %s (need %d, have %d)",
 			class.String(), classConfig.Requirements.MinStr, attributes["strength"])
@@ -512,8 +677,8 @@ func (cc *CharacterCreator) calculateDerivedStats(character *Character, class Ch
 	dexBonus := (character.Dexterity - 10) / 2
 	character.ArmorClass = 10 + dexBonus
 
-	// Calculate THAC0 (simplified)
-	character.THAC0 = 20 // Base for level 1 character
+	// Calculate THAC0 from the active ruleset's attack matrix (simplified without one)
+	character.THAC0 = rulesetTHAC0(class, character.Level, 20) // Base for level 1 character
 
 	// Initialize action points based on level and dexterity (level 1 for new characters)
 	character.MaxActionPoints = calculateMaxActionPoints(character.Level, character.Dexterity)
@@ -728,4 +893,37 @@ func (cc *CharacterCreator) initializeItemDatabase() {
 		Weight: 10,
 		Value:  10,
 	}
+
+	// Background keepsakes
+	cc.itemDatabase["item_rank_insignia"] = Item{
+		ID:     "item_rank_insignia",
+		Name:   "Rank Insignia",
+		Type:   "equipment",
+		Weight: 1,
+		Value:  5,
+	}
+
+	cc.itemDatabase["item_scholars_tome"] = Item{
+		ID:     "item_scholars_tome",
+		Name:   "Scholar's Tome",
+		Type:   "equipment",
+		Weight: 3,
+		Value:  25,
+	}
+
+	cc.itemDatabase["item_tattered_cloak"] = Item{
+		ID:     "item_tattered_cloak",
+		Name:   "Tattered Cloak",
+		Type:   "equipment",
+		Weight: 1,
+		Value:  1,
+	}
+
+	cc.itemDatabase["item_signet_ring"] = Item{
+		ID:     "item_signet_ring",
+		Name:   "Signet Ring",
+		Type:   "equipment",
+		Weight: 1,
+		Value:  50,
+	}
 }
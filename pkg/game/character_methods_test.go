@@ -499,6 +499,29 @@ func TestCharacter_GetTags(t *testing.T) {
 }
 
 // TestCharacter_ToJSON tests the ToJSON method
+// TestCharacter_AddRemoveTag tests tag management on characters
+func TestCharacter_AddRemoveTag(t *testing.T) {
+	character := &Character{tags: []string{"player_character"}}
+
+	character.AddTag("boss")
+	character.AddTag("boss")
+	if got := character.GetTags(); len(got) != 2 || got[1] != "boss" {
+		t.Errorf("AddTag() tags = %v, want [player_character boss]", got)
+	}
+	if !character.HasTag("boss") {
+		t.Error("HasTag(boss) = false, want true")
+	}
+
+	character.RemoveTag("player_character")
+	character.RemoveTag("missing")
+	if got := character.GetTags(); len(got) != 1 || got[0] != "boss" {
+		t.Errorf("RemoveTag() tags = %v, want [boss]", got)
+	}
+	if character.HasTag("player_character") {
+		t.Error("HasTag(player_character) = true after RemoveTag")
+	}
+}
+
 func TestCharacter_ToJSON(t *testing.T) {
 	tests := []struct {
 		name        string
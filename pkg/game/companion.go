@@ -0,0 +1,296 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Companion loyalty and morale, both on a 0-100 scale
+const (
+	MaxCompanionLoyalty = 100
+	MaxCompanionMorale  = 100
+
+	// CompanionDesertionLoyalty is the loyalty at or below which a
+	// companion leaves its employer
+	CompanionDesertionLoyalty = 10
+
+	// CompanionShakenMorale is the morale below which a companion will not
+	// attack, holding a defensive stance on its turns instead
+	CompanionShakenMorale = 25
+
+	// DefaultCompanionShare is the share of experience and gold a
+	// companion takes, relative to its employer's full share
+	DefaultCompanionShare = 0.5
+)
+
+// CompanionTreatment is something that happens to a companion or that its
+// employer does, moving its loyalty and morale.
+type CompanionTreatment string
+
+const (
+	// TreatmentHired is the wage paid when the companion is hired
+	TreatmentHired CompanionTreatment = "hired"
+	// TreatmentPaid is a share of a reward paid out
+	TreatmentPaid CompanionTreatment = "paid"
+	// TreatmentRested is a rest taken with the employer
+	TreatmentRested CompanionTreatment = "rested"
+	// TreatmentVictory is a fight won alongside the employer
+	TreatmentVictory CompanionTreatment = "victory"
+	// TreatmentWounded is being brought below half hit points
+	TreatmentWounded CompanionTreatment = "wounded"
+	// TreatmentAllyDied is the death of the employer or a fellow companion
+	TreatmentAllyDied CompanionTreatment = "ally_died"
+	// TreatmentAbandoned is a fellow companion dismissed by the employer
+	TreatmentAbandoned CompanionTreatment = "abandoned"
+)
+
+// companionTreatmentEffects holds the loyalty and morale change of each
+// treatment before personality adjusts it
+var companionTreatmentEffects = map[CompanionTreatment][2]int{
+	TreatmentHired:     {5, 5},
+	TreatmentPaid:      {2, 2},
+	TreatmentRested:    {0, 15},
+	TreatmentVictory:   {3, 10},
+	TreatmentWounded:   {0, -10},
+	TreatmentAllyDied:  {-5, -20},
+	TreatmentAbandoned: {-8, -5},
+}
+
+// Companion is an NPC hired to fight alongside a player. Its loyalty
+// decides whether it stays with its employer and its morale whether it
+// fights; both move with its treatment, scaled by its personality:
+//   - brave companions lose morale at half the rate, cowardly ones at twice
+//   - loyal companions lose loyalty at half the rate, treacherous ones at
+//     twice
+//   - greedy companions want a full share of rewards, and being paid wins
+//     their loyalty twice as fast
+//
+// A companion whose loyalty falls to CompanionDesertionLoyalty deserts, and
+// one whose morale is below CompanionShakenMorale is shaken and will not
+// attack.
+//
+// Companion is safe for concurrent use.
+type Companion struct {
+	mu          sync.RWMutex `yaml:"-"`
+	NPC         *NPC         `yaml:"companion_npc"`         // The companion in the world
+	EmployerID  string       `yaml:"companion_employer"`    // ID of the hiring player
+	Wage        int          `yaml:"companion_wage"`        // Gold paid to hire the companion
+	Share       float64      `yaml:"companion_share"`       // Share of rewards relative to the employer's
+	Loyalty     int          `yaml:"companion_loyalty"`     // Willingness to stay, 0-100
+	Morale      int          `yaml:"companion_morale"`      // Willingness to fight, 0-100
+	Temperament string       `yaml:"companion_temperament"` // General disposition
+	Traits      []string     `yaml:"companion_traits"`      // Personality traits, such as "brave"
+	Experience  int64        `yaml:"companion_experience"`  // Experience earned in service
+	GoldEarned  int          `yaml:"companion_gold_earned"` // Gold earned in service
+}
+
+// NewCompanion creates a companion for npc before it is hired. Greedy
+// companions take a full share of rewards, others DefaultCompanionShare.
+//
+// Parameters:
+//   - npc: The companion's character
+//   - wage: Gold it costs to hire
+//   - loyalty, morale: Starting values, clamped to 0-100
+//   - temperament, traits: Personality, such as from the NPC generator
+//
+// Returns:
+//   - *Companion: The companion, without an employer
+//   - error: npc is nil or the wage is negative
+func NewCompanion(npc *NPC, wage, loyalty, morale int, temperament string, traits []string) (*Companion, error) {
+	if npc == nil {
+		return nil, fmt.Errorf("companion needs a character")
+	}
+	if wage < 0 {
+		return nil, fmt.Errorf("companion wage cannot be negative: %d", wage)
+	}
+
+	c := &Companion{
+		NPC:         npc,
+		Wage:        wage,
+		Share:       DefaultCompanionShare,
+		Loyalty:     clampCompanionScore(loyalty),
+		Morale:      clampCompanionScore(morale),
+		Temperament: temperament,
+		Traits:      append([]string(nil), traits...),
+	}
+	if c.hasTrait("greedy") {
+		c.Share = 1
+	}
+	return c, nil
+}
+
+// GetID returns the ID of the companion's character.
+func (c *Companion) GetID() string {
+	return c.NPC.GetID()
+}
+
+// GetEmployerID returns the ID of the player who hired the companion.
+func (c *Companion) GetEmployerID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.EmployerID
+}
+
+// Hire records employerID as the companion's employer.
+func (c *Companion) Hire(employerID string) {
+	c.mu.Lock()
+	c.EmployerID = employerID
+	c.mu.Unlock()
+	c.Treat(TreatmentHired)
+}
+
+// Treat applies a treatment, adjusted by personality, and returns the
+// resulting loyalty and morale.
+func (c *Companion) Treat(treatment CompanionTreatment) (loyalty, morale int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	effect := companionTreatmentEffects[treatment]
+	loyaltyDelta, moraleDelta := effect[0], effect[1]
+	switch {
+	case loyaltyDelta < 0 && c.hasTrait("loyal"):
+		loyaltyDelta /= 2
+	case loyaltyDelta < 0 && c.hasTrait("treacherous"):
+		loyaltyDelta *= 2
+	case loyaltyDelta > 0 && treatment == TreatmentPaid && c.hasTrait("greedy"):
+		loyaltyDelta *= 2
+	}
+	switch {
+	case moraleDelta < 0 && c.hasTrait("brave"):
+		moraleDelta /= 2
+	case moraleDelta < 0 && c.hasTrait("cowardly"):
+		moraleDelta *= 2
+	}
+
+	c.Loyalty = clampCompanionScore(c.Loyalty + loyaltyDelta)
+	c.Morale = clampCompanionScore(c.Morale + moraleDelta)
+	return c.Loyalty, c.Morale
+}
+
+// Deserts reports whether the companion's loyalty has fallen far enough
+// for it to leave its employer.
+func (c *Companion) Deserts() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Loyalty <= CompanionDesertionLoyalty
+}
+
+// IsShaken reports whether the companion's morale is too low to attack.
+func (c *Companion) IsShaken() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Morale < CompanionShakenMorale
+}
+
+// GetShare returns the companion's share of rewards.
+func (c *Companion) GetShare() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Share
+}
+
+// ReceiveShare pays the companion its share of a reward, which may level
+// up its character, and counts as TreatmentPaid.
+func (c *Companion) ReceiveShare(experience int64, gold int) error {
+	if experience < 0 || gold < 0 {
+		return fmt.Errorf("reward share cannot be negative: %d experience, %d gold", experience, gold)
+	}
+	if experience > 0 {
+		if _, err := c.NPC.Character.AddExperience(experience); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.Experience += experience
+	c.GoldEarned += gold
+	c.mu.Unlock()
+	c.NPC.Character.mu.Lock()
+	c.NPC.Character.Gold += gold
+	c.NPC.Character.mu.Unlock()
+
+	c.Treat(TreatmentPaid)
+	return nil
+}
+
+// Heal restores up to hp hit points to the companion, never beyond its
+// maximum, and returns its hit points afterwards.
+func (c *Companion) Heal(hp int) int {
+	char := &c.NPC.Character
+	char.mu.Lock()
+	defer char.mu.Unlock()
+
+	char.HP += hp
+	if char.HP > char.MaxHP {
+		char.HP = char.MaxHP
+	}
+	return char.HP
+}
+
+// Snapshot returns the companion's state for RPC responses.
+func (c *Companion) Snapshot() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	char := &c.NPC.Character
+	return map[string]interface{}{
+		"companion_id": char.GetID(),
+		"name":         char.GetName(),
+		"class":        char.Class,
+		"level":        char.Level,
+		"hp":           char.HP,
+		"max_hp":       char.MaxHP,
+		"wage":         c.Wage,
+		"share":        c.Share,
+		"loyalty":      c.Loyalty,
+		"morale":       c.Morale,
+		"shaken":       c.Morale < CompanionShakenMorale,
+		"temperament":  c.Temperament,
+		"traits":       append([]string(nil), c.Traits...),
+		"experience":   c.Experience,
+		"gold_earned":  c.GoldEarned,
+	}
+}
+
+// hasTrait reports whether the companion has a personality trait
+func (c *Companion) hasTrait(trait string) bool {
+	for _, t := range c.Traits {
+		if t == trait {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitReward divides amount between an employer and companions with the
+// given shares, the employer holding a share of 1. Rounding leftovers go
+// to the employer.
+//
+// Returns:
+//   - int: The employer's part
+//   - []int: Each companion's part, in the order of shares
+func SplitReward(amount int, shares []float64) (int, []int) {
+	total := 1.0
+	for _, share := range shares {
+		total += share
+	}
+
+	parts := make([]int, len(shares))
+	remaining := amount
+	for i, share := range shares {
+		parts[i] = int(float64(amount) * share / total)
+		remaining -= parts[i]
+	}
+	return remaining, parts
+}
+
+// clampCompanionScore keeps a loyalty or morale value within 0-100
+func clampCompanionScore(score int) int {
+	switch {
+	case score < 0:
+		return 0
+	case score > MaxCompanionLoyalty:
+		return MaxCompanionLoyalty
+	}
+	return score
+}
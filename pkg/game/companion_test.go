@@ -0,0 +1,128 @@
+package game
+
+import "testing"
+
+func newTestCompanion(t *testing.T, traits ...string) *Companion {
+	t.Helper()
+	npc := &NPC{Character: Character{ID: "hench-1", Name: "Bran", Level: 1, HP: 10, MaxHP: 10}}
+	companion, err := NewCompanion(npc, 50, 50, 50, "steady", traits)
+	if err != nil {
+		t.Fatalf("NewCompanion: %v", err)
+	}
+	return companion
+}
+
+func TestNewCompanion_Validates(t *testing.T) {
+	if _, err := NewCompanion(nil, 10, 50, 50, "", nil); err == nil {
+		t.Error("expected an error for a missing character")
+	}
+	if _, err := NewCompanion(&NPC{}, -1, 50, 50, "", nil); err == nil {
+		t.Error("expected an error for a negative wage")
+	}
+
+	companion, err := NewCompanion(&NPC{}, 10, 150, -5, "", nil)
+	if err != nil {
+		t.Fatalf("NewCompanion: %v", err)
+	}
+	if companion.Loyalty != MaxCompanionLoyalty || companion.Morale != 0 {
+		t.Errorf("expected scores clamped to 100 and 0, got %d and %d", companion.Loyalty, companion.Morale)
+	}
+	if companion.Share != DefaultCompanionShare {
+		t.Errorf("expected the default share, got %v", companion.Share)
+	}
+}
+
+func TestCompanion_TreatScalesWithPersonality(t *testing.T) {
+	tests := []struct {
+		trait     string
+		treatment CompanionTreatment
+		loyalty   int
+		morale    int
+	}{
+		{"", TreatmentAllyDied, 45, 30},
+		{"brave", TreatmentAllyDied, 45, 40},
+		{"cowardly", TreatmentAllyDied, 45, 10},
+		{"loyal", TreatmentAllyDied, 48, 30},
+		{"treacherous", TreatmentAllyDied, 40, 30},
+		{"greedy", TreatmentPaid, 54, 52},
+		{"", TreatmentVictory, 53, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.trait+"/"+string(tt.treatment), func(t *testing.T) {
+			companion := newTestCompanion(t, tt.trait)
+			loyalty, morale := companion.Treat(tt.treatment)
+			if loyalty != tt.loyalty || morale != tt.morale {
+				t.Errorf("expected loyalty %d morale %d, got %d and %d", tt.loyalty, tt.morale, loyalty, morale)
+			}
+		})
+	}
+}
+
+func TestCompanion_DesertsAndShaken(t *testing.T) {
+	companion := newTestCompanion(t, "treacherous", "cowardly")
+	if companion.Deserts() || companion.IsShaken() {
+		t.Fatal("a fresh companion should neither desert nor be shaken")
+	}
+
+	companion.Treat(TreatmentAllyDied)
+	if !companion.IsShaken() {
+		t.Errorf("a cowardly companion should be shaken by a death, morale %d", companion.Morale)
+	}
+	for i := 0; i < 3; i++ {
+		companion.Treat(TreatmentAllyDied)
+	}
+	if !companion.Deserts() {
+		t.Errorf("a treacherous companion should desert after repeated deaths, loyalty %d", companion.Loyalty)
+	}
+}
+
+func TestCompanion_ReceiveShare(t *testing.T) {
+	companion := newTestCompanion(t)
+	if err := companion.ReceiveShare(-1, 0); err == nil {
+		t.Error("expected an error for a negative share")
+	}
+
+	if err := companion.ReceiveShare(2500, 30); err != nil {
+		t.Fatalf("ReceiveShare: %v", err)
+	}
+	if companion.NPC.Experience != 2500 || companion.NPC.Gold != 30 {
+		t.Errorf("expected the character to receive 2500 XP and 30 gold, got %d and %d",
+			companion.NPC.Experience, companion.NPC.Gold)
+	}
+	if companion.NPC.Level < 2 {
+		t.Errorf("expected the companion to level up, still level %d", companion.NPC.Level)
+	}
+	if companion.Experience != 2500 || companion.GoldEarned != 30 || companion.Loyalty != 52 {
+		t.Errorf("unexpected service record %d XP, %d gold, loyalty %d",
+			companion.Experience, companion.GoldEarned, companion.Loyalty)
+	}
+}
+
+func TestSplitReward(t *testing.T) {
+	player, parts := SplitReward(100, []float64{0.5, 0.5})
+	if player != 50 || parts[0] != 25 || parts[1] != 25 {
+		t.Errorf("expected 50/25/25, got %d/%v", player, parts)
+	}
+
+	player, parts = SplitReward(10, []float64{1, 0.5})
+	if player+parts[0]+parts[1] != 10 || parts[0] != 4 || parts[1] != 2 {
+		t.Errorf("expected the whole reward split 4/4/2, got %d/%v", player, parts)
+	}
+
+	player, parts = SplitReward(7, nil)
+	if player != 7 || len(parts) != 0 {
+		t.Errorf("expected the player to keep everything, got %d/%v", player, parts)
+	}
+}
+
+func TestCompanion_Heal(t *testing.T) {
+	companion := newTestCompanion(t)
+	companion.NPC.HP = 4
+	if hp := companion.Heal(3); hp != 7 {
+		t.Errorf("expected 7 hit points, got %d", hp)
+	}
+	if hp := companion.Heal(50); hp != companion.NPC.MaxHP {
+		t.Errorf("healing should stop at %d, got %d", companion.NPC.MaxHP, hp)
+	}
+}
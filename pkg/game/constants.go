@@ -46,6 +46,8 @@ const (
 	EffectBleeding       EffectType = "bleeding"
 	EffectStun           EffectType = "stun"
 	EffectRoot           EffectType = "root"
+	EffectSilence        EffectType = "silence" // Blocks verbal spell components
+	EffectBound          EffectType = "bound"   // Blocks somatic spell components
 	EffectStatBoost      EffectType = "stat_boost"
 	EffectStatPenalty    EffectType = "stat_penalty"
 
@@ -177,6 +179,7 @@ const (
 const (
 	ItemTypeWeapon = "weapon"
 	ItemTypeArmor  = "armor"
+	ItemTypeKey    = "key" // Unlocks the door whose KeyID is the key's item ID
 )
 
 // DefaultWorld constants define the dimensions of the default test world.
@@ -189,8 +192,12 @@ const (
 // Action Point constants define the cost of different actions in combat.
 // Simple system: 2 points per turn, 1 for move, 1 for attack/spell.
 const (
-	ActionPointsPerTurn = 2 // Total action points available per turn
-	ActionCostMove      = 1 // Cost to move one tile
-	ActionCostAttack    = 1 // Cost to perform a melee/ranged attack
-	ActionCostSpell     = 1 // Cost to cast a spell
+	ActionPointsPerTurn  = 2 // Total action points available per turn
+	ActionCostMove       = 1 // Cost to move one tile
+	ActionCostAttack     = 1 // Cost to perform a melee/ranged attack
+	ActionCostSpell      = 1 // Cost to cast a spell
+	ActionCostDisarmTrap = 2 // Cost of one attempt to disarm a trap
+	ActionCostOpenDoor   = 1 // Cost to open or close a door
+	ActionCostPickLock   = 2 // Cost of one attempt to pick a lock
+	ActionCostInteract   = 1 // Cost to use a feature such as a lever
 )
@@ -0,0 +1,108 @@
+package game
+
+import "fmt"
+
+// DeityAlignment is the moral stance of a deity. Paladins may only serve
+// good deities.
+type DeityAlignment string
+
+const (
+	DeityGood    DeityAlignment = "good"    // Protects and heals
+	DeityNeutral DeityAlignment = "neutral" // Keeps balance or tends nature
+	DeityEvil    DeityAlignment = "evil"    // Rules through fear and ruin
+)
+
+// HolySymbolItemID is the inventory item ID of the holy symbol a cleric
+// receives at character creation. The item carries the deity's ID in its
+// properties as "deity:<id>".
+const HolySymbolItemID = "item_holy_symbol"
+
+// Deity is a god of a pantheon that clerics and paladins may serve.
+//
+// Fields:
+//   - ID: Unique identifier within the pantheon
+//   - Name, Title: Display name and epithet
+//   - Alignment: Moral stance, restricting which classes may serve
+//   - Domains: Spheres of influence such as war, healing or death
+//   - Dogma: Tenets the faithful are expected to follow
+//   - FavoredWeapon: Weapon the deity's champions carry
+//   - HolySymbol: Description of the deity's sacred emblem
+type Deity struct {
+	ID            string         `yaml:"deity_id"`
+	Name          string         `yaml:"deity_name"`
+	Title         string         `yaml:"deity_title"`
+	Alignment     DeityAlignment `yaml:"deity_alignment"`
+	Domains       []string       `yaml:"deity_domains"`
+	Dogma         []string       `yaml:"deity_dogma"`
+	FavoredWeapon string         `yaml:"deity_favored_weapon"`
+	HolySymbol    string         `yaml:"deity_holy_symbol"`
+}
+
+// Pantheon is the set of deities worshipped in a world, generated for its
+// genre.
+type Pantheon struct {
+	Genre   string  `yaml:"pantheon_genre"`
+	Deities []Deity `yaml:"pantheon_deities"`
+}
+
+// Get returns the deity with the given ID.
+func (p *Pantheon) Get(id string) (*Deity, bool) {
+	if p == nil {
+		return nil, false
+	}
+	for i := range p.Deities {
+		if p.Deities[i].ID == id {
+			return &p.Deities[i], true
+		}
+	}
+	return nil, false
+}
+
+// Patrons returns the deities a character of the given class may serve,
+// in pantheon order. Paladins are limited to good deities; every other
+// class may serve any deity.
+func (p *Pantheon) Patrons(class CharacterClass) []Deity {
+	if p == nil {
+		return nil
+	}
+	patrons := make([]Deity, 0, len(p.Deities))
+	for _, deity := range p.Deities {
+		if class == ClassPaladin && deity.Alignment != DeityGood {
+			continue
+		}
+		patrons = append(patrons, deity)
+	}
+	return patrons
+}
+
+// Clone returns a deep copy of the pantheon, or nil if p is nil.
+func (p *Pantheon) Clone() *Pantheon {
+	if p == nil {
+		return nil
+	}
+	clone := &Pantheon{Genre: p.Genre, Deities: make([]Deity, len(p.Deities))}
+	for i, deity := range p.Deities {
+		deity.Domains = append([]string(nil), deity.Domains...)
+		deity.Dogma = append([]string(nil), deity.Dogma...)
+		clone.Deities[i] = deity
+	}
+	return clone
+}
+
+// RequiresDeity reports whether characters of class draw their power from
+// a patron deity.
+func RequiresDeity(class CharacterClass) bool {
+	return class == ClassCleric || class == ClassPaladin
+}
+
+// holySymbolItem returns the holy symbol item of deity.
+func holySymbolItem(deity *Deity) Item {
+	return Item{
+		ID:         HolySymbolItemID,
+		Name:       fmt.Sprintf("Holy Symbol of %s", deity.Name),
+		Type:       "equipment",
+		Weight:     1,
+		Value:      25,
+		Properties: []string{"holy_symbol", "deity:" + deity.ID},
+	}
+}
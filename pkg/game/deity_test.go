@@ -0,0 +1,121 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+// devoutAttributes meet the requirements of every divine class
+var devoutAttributes = map[string]int{
+	"strength": 14, "dexterity": 12, "constitution": 14,
+	"intelligence": 10, "wisdom": 15, "charisma": 15,
+}
+
+// testPantheon returns a pantheon with one deity of each alignment
+func testPantheon() *Pantheon {
+	return &Pantheon{
+		Genre: "classic_fantasy",
+		Deities: []Deity{
+			{ID: "deity_sol", Name: "Sol", Alignment: DeityGood, Domains: []string{"light"}, HolySymbol: "golden rising sun"},
+			{ID: "deity_vael", Name: "Vael", Alignment: DeityNeutral, Domains: []string{"nature"}},
+			{ID: "deity_mor", Name: "Mor", Alignment: DeityEvil, Domains: []string{"death"}},
+		},
+	}
+}
+
+func TestPantheon_Patrons(t *testing.T) {
+	pantheon := testPantheon()
+
+	if got := len(pantheon.Patrons(ClassCleric)); got != 3 {
+		t.Errorf("Expected clerics to serve any of 3 deities, got %d", got)
+	}
+	paladin := pantheon.Patrons(ClassPaladin)
+	if len(paladin) != 1 || paladin[0].ID != "deity_sol" {
+		t.Errorf("Expected paladins to serve only deity_sol, got %+v", paladin)
+	}
+
+	clone := pantheon.Clone()
+	clone.Deities[0].Domains[0] = "war"
+	if pantheon.Deities[0].Domains[0] != "light" {
+		t.Error("Clone shares domains with the original")
+	}
+}
+
+func TestCharacterCreator_CreateCharacter_Deity(t *testing.T) {
+	tests := []struct {
+		name      string
+		class     CharacterClass
+		deity     string
+		pantheon  *Pantheon
+		wantDeity string
+		wantError string
+	}{
+		{name: "chosen deity", class: ClassCleric, deity: "deity_mor", pantheon: testPantheon(), wantDeity: "deity_mor"},
+		{name: "paladin picks good deity", class: ClassPaladin, pantheon: testPantheon(), wantDeity: "deity_sol"},
+		{name: "paladin refuses evil deity", class: ClassPaladin, deity: "deity_mor", pantheon: testPantheon(), wantError: "paladins cannot serve"},
+		{name: "unknown deity", class: ClassCleric, deity: "deity_none", pantheon: testPantheon(), wantError: "invalid deity"},
+		{name: "deity without pantheon", class: ClassCleric, deity: "deity_sol", wantError: "no pantheon"},
+		{name: "cleric without pantheon", class: ClassCleric},
+		{name: "fighter gets no deity", class: ClassFighter, pantheon: testPantheon()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewCharacterCreatorWithSeed(11).CreateCharacter(CharacterCreationConfig{
+				Name:             "Devout",
+				Class:            tt.class,
+				AttributeMethod:  "custom",
+				CustomAttributes: devoutAttributes,
+				Deity:            tt.deity,
+				Pantheon:         tt.pantheon,
+			})
+
+			if tt.wantError != "" {
+				if result.Success || len(result.Errors) == 0 || !strings.Contains(result.Errors[0], tt.wantError) {
+					t.Fatalf("Expected error containing %q, got success=%v errors=%v", tt.wantError, result.Success, result.Errors)
+				}
+				return
+			}
+			if !result.Success {
+				t.Fatalf("Character creation failed: %v", result.Errors)
+			}
+			if result.PlayerData.Deity != tt.wantDeity {
+				t.Errorf("Expected deity %q, got %q", tt.wantDeity, result.PlayerData.Deity)
+			}
+			if (result.Deity != nil) != (tt.wantDeity != "") {
+				t.Errorf("Expected result deity only when one is assigned, got %+v", result.Deity)
+			}
+		})
+	}
+}
+
+func TestCharacterCreator_CreateCharacter_HolySymbol(t *testing.T) {
+	result := NewCharacterCreatorWithSeed(3).CreateCharacter(CharacterCreationConfig{
+		Name:              "Acolyte",
+		Class:             ClassCleric,
+		AttributeMethod:   "custom",
+		CustomAttributes:  devoutAttributes,
+		StartingEquipment: true,
+		Deity:             "deity_sol",
+		Pantheon:          testPantheon(),
+	})
+	if !result.Success {
+		t.Fatalf("Character creation failed: %v", result.Errors)
+	}
+
+	var symbol *Item
+	for i := range result.PlayerData.Inventory {
+		if result.PlayerData.Inventory[i].ID == HolySymbolItemID {
+			symbol = &result.PlayerData.Inventory[i]
+		}
+	}
+	if symbol == nil {
+		t.Fatal("Expected the cleric to carry a holy symbol")
+	}
+	if symbol.Name != "Holy Symbol of Sol" {
+		t.Errorf("Expected holy symbol of Sol, got %q", symbol.Name)
+	}
+	if len(symbol.Properties) != 2 || symbol.Properties[1] != "deity:deity_sol" {
+		t.Errorf("Expected the symbol to name its deity, got %v", symbol.Properties)
+	}
+}
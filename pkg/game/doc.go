@@ -14,12 +14,48 @@
 //	char := game.NewCharacter("Hero", game.Fighter)
 //	player := game.NewPlayer(char)
 //
+// A CharacterCreator can give new characters a Background (soldier, scholar,
+// outcast or noble) that grants skill bonuses, a starting item and faction
+// reputation. BackgroundRandom picks one with the creator's seeded generator,
+// so NewCharacterCreatorWithSeed reproduces the same choice.
+//
+// Clerics and paladins serve a patron Deity of the world's Pantheon, passed
+// to the creator in CharacterCreationConfig. Without a chosen deity one is
+// picked with the seeded generator; paladins may only serve good deities.
+// Divine characters created with starting equipment carry the deity's holy
+// symbol.
+//
 // # Combat System
 //
 // Combat uses THAC0-based hit calculations with armor class defense, action points
 // for turn management, and initiative ordering. The TurnManager coordinates
 // turn-based combat flow.
 //
+// # Ruleset Profiles
+//
+// A RulesetProfile holds a rule flavor's attack matrices, saving throw
+// tables, experience thresholds, class restrictions and friendly fire rule,
+// loaded from YAML such as data/rulesets/adnd1e.yaml. Once selected with
+// SetActiveRuleset it drives level progression, THAC0, class eligibility
+// and who area spells spare; without one the built-in rules apply.
+//
+//	profile, err := game.LoadRulesetProfile("data/rulesets/adnd2e.yaml")
+//	game.SetActiveRuleset(profile)
+//	save := profile.SavingThrow(game.ClassMage, 3, game.SaveSpell)
+//
+// # Level Training
+//
+// Players advance as soon as they earn a level's experience, unless
+// SetManualLevelUp is on; single-classed players then keep the level
+// pending until a LevelUpService trains them for it. Training previews the
+// level's choices, rolls the class's hit die or takes its average, and
+// applies the hit points, THAC0, spell slots, chosen spells and weapon
+// proficiency slots together, so an invalid choice changes nothing.
+//
+//	service := game.NewLevelUpService(spellManager, nil)
+//	preview, err := service.Preview(player)
+//	result, err := service.LevelUp(player, game.LevelUpChoices{HPMethod: game.HPRoll})
+//
 // # Effect System
 //
 // Effects represent status conditions with duration, magnitude, and tick-based updates.
@@ -29,22 +65,190 @@
 //	effect := game.NewEffect(game.EffectPoison, 5, 3) // 5 damage, 3 turns
 //	char.AddEffect(effect)
 //
+// Characters carry Resistances by effect type and damage type; 1 is immunity
+// and negative values are vulnerabilities. An effect's StackingPolicy decides
+// whether reapplying its type refreshes, stacks or is ignored, and
+// Character.ResolveEffect reports the outcome as an EffectResolution.
+// DispelEffects removes removable effects by dispel type, highest priority
+// first.
+//
 // # Spell System
 //
 // Spells are organized by school (Abjuration, Evocation, Conjuration, etc.) with
 // configurable range, damage/healing dice, area effects, and components.
 // The SpellManager handles spell library and casting mechanics.
 //
+// Casting follows Vancian rules. SpellSlotsFor gives the spells of each level
+// a class can memorize; Player.MemorizeSpells fills the slots, RestoreSpells
+// readies them after a full rest, and ExpendSpell uses one up when it is
+// cast. Cantrips need no slot.
+//
+// Besides their verbal, somatic and material components, spells may list
+// the specific SpellMaterials they are cast with. Character.MissingMaterials
+// reports what a caster lacks and ConsumeMaterials uses up the consumed
+// ones. The EffectSilence and EffectBound effects keep a caster from
+// providing verbal and somatic components.
+//
+// Spells may also declare effect scripts (area damage, summoning, teleport,
+// wall creation) that are resolved by name through a SpellEffectRegistry at
+// cast time. New behaviors are added by registering a handler:
+//
+//	spellManager.EffectRegistry().Register("polymorph", func(cast *game.SpellCast) (*game.SpellEffectResult, error) {
+//		...
+//	})
+//
+// Area spells carry an AreaTemplate: a circle around the target point, or a
+// burst, cone or line from the caster. World.ResolveArea finds the tiles
+// in line of sight of the template's origin and the creatures on them
+// through the spatial index; creatures behind an obstacle such as a wall
+// segment have cover and take half damage. The active ruleset's
+// FriendlyFire rule decides whether the caster and its allies are spared.
+//
 // # World Management
 //
 // The World type serves as the primary game state container, managing multiple
 // dungeon levels, entities, and time tracking. It uses spatial indexing via
 // SpatialIndex for efficient entity location queries and area searches.
+// SpatialIndex is a quadtree that splits and merges cells as objects come
+// and go; TrackMovement re-indexes objects from movement events, and
+// RebuildSpatialIndex rebuilds the indexes in bulk.
 //
 //	world := game.NewWorld()
 //	world.AddEntity(player)
 //	nearby := world.GetEntitiesInRange(position, 10)
 //
+// MapAnnotation is a game master's note, marker or region label on a level.
+// Its visibility keeps it to the game master or shares it with players;
+// Validate checks it lies on its level.
+//
+// # Line of Sight and Threat Assessment
+//
+// World.HasLineOfSight traces a line across a level's tiles and stops at
+// tiles that block sight. World.VisibleHostiles combines it with a
+// ThreatTable, whose behavior and status effect weights decide which NPCs
+// are hostile and estimate how dangerous each one is to the viewer:
+//
+//	threats := world.VisibleHostiles(&player.Character, game.DefaultSightRange, nil)
+//
+// # Light and Darkness
+//
+// Every tile has a LightLevel, bright unless set. World.CanSee, which
+// VisibleHostiles uses, sees bright positions at any range, dim ones within
+// DimSightRange and dark ones only within the viewer's Infravision, from a
+// "race:<name>" tag, its class or an "infravision:N" tag. Light sources
+// carried in the world, registered with SetCarriedLights, light the tiles
+// around their holders. LightSourceOf tells how torches, lanterns and items
+// with a "light_radius:N" property burn:
+//
+//	if source, ok := game.LightSourceOf(&item); ok {
+//		world.SetCarriedLights(map[string]int{player.GetID(): source.Radius})
+//	}
+//
+// # Traps
+//
+// A Trap fires on creatures that meet its trigger (pressure plate, tripwire,
+// proximity or touch), dealing dice damage and applying an effect. Characters
+// spot traps with Wisdom checks and disarm the ones they have spotted with
+// Dexterity checks, each helped by the matching skill; thieves add half their
+// level. A disarm attempt costs ActionCostDisarmTrap action points, and a
+// badly failed one sets the trap off:
+//
+//	if check, _ := trap.AttemptDetection(&player.Character, roller); check.Success {
+//		check, result, err := trap.AttemptDisarm(&player.Character, roller)
+//		...
+//	}
+//
+// # Doors
+//
+// A Door blocks movement unless it is open. A locked door opens for a
+// character carrying the item named by its KeyID, or once its lock is
+// picked with a Dexterity check helped by the Open Locks skill; failing
+// that check badly jams the lock. Secret doors stay hidden until a Wisdom
+// check with the Find Secret Doors skill finds them. Keys lie in the world
+// as KeyObject values until picked up:
+//
+//	if _, err := door.Open(&player.Character); err != nil {
+//		check, err := door.AttemptPickLock(&player.Character, roller)
+//		...
+//	}
+//
+// # Interactive Features
+//
+// An Interactable is a world object players act on with verbs. Feature
+// implements it for levers, altars, fountains and bookshelves: each
+// Interaction names a verb, an optional ability check against a DC, and
+// the InteractionEffect values of success and failure, such as healing,
+// experience or a condition. One-time interactions are spent once they
+// succeed. Trigger effects name a mechanism, such as a door, for the
+// world's owner to work with Door.Trigger:
+//
+//	result, err := feature.Interact(&player.Character, "pull", roller)
+//	for _, id := range result.Triggered {
+//		...
+//	}
+//
+// # Merchants
+//
+// A Merchant is an NPC with a stock of items and a gold pool. Its prices
+// move in the player's favour with Charisma and with reputation in the
+// merchant's faction, and SellToPlayer and BuyFromPlayer exchange gold and
+// the item in one step, so a failed trade leaves both parties unchanged.
+// Restock puts back the items a merchant has sold and tops up its gold.
+//
+// # Companions
+//
+// A Companion is an NPC hired by a player. Its loyalty and morale move with
+// CompanionTreatment values, scaled by personality traits such as brave or
+// treacherous; Deserts and IsShaken report when they have fallen too far.
+// SplitReward divides a reward between an employer and their companions'
+// shares, and ReceiveShare pays a companion, levelling up its character.
+//
+// Generated NPCs carry BehaviorModifiers derived from their personality:
+// how brave, greedy and honest they are, the health at which they hold
+// back from a fight, and whether they go for the weakest opponent.
+//
+// # Quest Consequences
+//
+// A Quest may declare QuestConsequence values that change the world when it
+// is completed or failed: an NPC dies, the player's standing with a faction
+// shifts, a level or room is locked, or a follow-up quest is unlocked.
+// World.ApplyQuestOutcome executes the consequences of an outcome together
+// with the change to the quest log and undoes them all if any step fails:
+//
+//	applied, err := world.ApplyQuestOutcome(player, quest, game.QuestFailed, func() error {
+//		return player.FailQuest(quest.ID)
+//	})
+//
+// ValidateMove rejects moves into a locked area.
+//
+// Quests and their objectives may carry a TimeLimitHours. The caller
+// passes the game clock to Player.UpdateQuestDeadlines, which starts each
+// deadline the first time it sees it, warns once when a quarter of the
+// limit is left and reports quests past a deadline for the caller to fail
+// with their consequences.
+//
+// # NPC Relationships
+//
+// The world's RelationshipGraph records how each NPC feels about the
+// players and NPCs it knows, as trust, fear and debt, and remembers the
+// RelationshipEvent values that moved them: attacks on it or its faction
+// (World.RecordAttack), trades, and the outcome of quests it gave
+// (Quest.GiverID). The graph is saved with the world. NPC.SelectDialog
+// picks the dialog node an NPC speaks and the responses open to the
+// listener by the relationship conditions of its nodes, such as min_trust
+// or attitude, and QuestAvailable decides whether a giver offers a quest:
+//
+//	rel := world.RelationshipGraph().Get(npc.ID, player.ID)
+//	entry, responses, err := npc.SelectDialog(rel, "")
+//
+// # Entity IDs
+//
+// IDs start with a namespace prefix such as npc_, item_ or quest_.
+// Procedurally generated content takes SeededID IDs, derived from its seed,
+// so content regenerated from a saved seed keeps its IDs; entities created
+// at runtime take random IDs. An IDService issues both kinds and reports
+// ErrIDCollision when an ID is already in use.
+//
 // # Thread Safety
 //
 // All core types support concurrent access via sync.RWMutex protection.
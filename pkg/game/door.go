@@ -0,0 +1,414 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DoorState describes whether a door can be passed.
+type DoorState string
+
+const (
+	// DoorOpen lets creatures through
+	DoorOpen DoorState = "open"
+	// DoorClosed blocks movement until opened
+	DoorClosed DoorState = "closed"
+	// DoorLocked needs its key or a picked lock before it opens
+	DoorLocked DoorState = "locked"
+	// DoorSecret is hidden in the wall until a search finds it
+	DoorSecret DoorState = "secret"
+)
+
+// Skills that improve door checks. Bonuses come from Character.Skills.
+const (
+	SkillOpenLocks       = "Open Locks"
+	SkillFindSecretDoors = "Find Secret Doors"
+)
+
+// LockJamFailureMargin is how far below the lock DC a pick must fall to
+// jam the lock.
+const LockJamFailureMargin = 5
+
+// DefaultDoorSearchDC is the search DC of secret doors that set none.
+const DefaultDoorSearchDC = 15
+
+// Door is a passage between two areas that blocks movement unless open.
+//
+// A locked door opens for anyone carrying the item named by KeyID, or once
+// its lock has been picked with a Dexterity check against LockDC. Picking
+// adds the Open Locks skill bonus, and thieves add half their level,
+// rounded up. Missing the DC by LockJamFailureMargin or more jams the lock,
+// after which only the key opens it. A secret door is found with a Wisdom
+// check against SearchDC adding the Find Secret Doors skill; once found it
+// is closed and opens like any other door.
+//
+// Door is safe for concurrent use.
+type Door struct {
+	mu          sync.RWMutex `yaml:"-"`
+	ID          string       `yaml:"door_id"`                    // Unique identifier
+	Name        string       `yaml:"door_name"`                  // Display name
+	Description string       `yaml:"door_description,omitempty"` // Description shown to players
+	Position    Position     `yaml:"door_position"`              // Location in the game world
+
+	State    DoorState `yaml:"door_state"`               // Whether the door can be passed
+	LockDC   int       `yaml:"door_lock_dc,omitempty"`   // Difficulty of picking the lock, 0 if it cannot be picked
+	KeyID    string    `yaml:"door_key_id,omitempty"`    // Item ID of the key that unlocks the door
+	SearchDC int       `yaml:"door_search_dc,omitempty"` // Difficulty of finding a secret door
+	Jammed   bool      `yaml:"door_jammed,omitempty"`    // Whether a failed pick jammed the lock
+}
+
+// DoorCheck is the result of a lock picking or search check.
+type DoorCheck struct {
+	Roll    int  `json:"roll"`    // The d20 result
+	Bonus   int  `json:"bonus"`   // Ability, skill and class bonus
+	Total   int  `json:"total"`   // Roll plus bonus
+	DC      int  `json:"dc"`      // Difficulty the total was compared against
+	Success bool `json:"success"` // Whether the total met the DC
+}
+
+// Validate checks that the door's state and lock are consistent.
+func (d *Door) Validate() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.ID == "" {
+		return fmt.Errorf("door ID cannot be empty")
+	}
+	switch d.State {
+	case DoorOpen, DoorClosed, DoorSecret:
+	case DoorLocked:
+		if d.KeyID == "" && d.LockDC < 1 {
+			return fmt.Errorf("locked door %s has neither a key nor a lock that can be picked", d.ID)
+		}
+	default:
+		return fmt.Errorf("door %s has unknown state %q", d.ID, d.State)
+	}
+	if d.LockDC < 0 || d.SearchDC < 0 {
+		return fmt.Errorf("door %s has a negative DC", d.ID)
+	}
+	return nil
+}
+
+// GetState returns the door's state.
+func (d *Door) GetState() DoorState {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.State
+}
+
+// GetKeyID returns the item ID of the door's key, or "" if it has none.
+func (d *Door) GetKeyID() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.KeyID
+}
+
+// IsJammed reports whether a failed pick has jammed the door's lock.
+func (d *Door) IsJammed() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.Jammed
+}
+
+// Open opens the door for c. A locked door opens only if c carries its
+// key, and the result reports whether the key was used.
+func (d *Door) Open(c *Character) (usedKey bool, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch d.State {
+	case DoorOpen:
+		return false, fmt.Errorf("door %s is already open", d.ID)
+	case DoorSecret:
+		return false, fmt.Errorf("door %s has not been found", d.ID)
+	case DoorLocked:
+		if d.KeyID == "" || !c.HasItem(d.KeyID) {
+			return false, fmt.Errorf("door %s is locked", d.ID)
+		}
+		usedKey = true
+	}
+
+	d.State = DoorOpen
+	logrus.WithFields(logrus.Fields{
+		"function":     "Open",
+		"package":      "game",
+		"door_id":      d.ID,
+		"character_id": c.ID,
+		"used_key":     usedKey,
+	}).Debug("door opened")
+	return usedKey, nil
+}
+
+// Close closes an open door. Closed doors are not locked again.
+func (d *Door) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.State != DoorOpen {
+		return fmt.Errorf("door %s is not open", d.ID)
+	}
+	d.State = DoorClosed
+	return nil
+}
+
+// Trigger works the door as a mechanism linked to it does, such as a
+// lever: an open door closes, and a closed or locked one opens whether or
+// not anyone carries its key. Secret doors stay hidden.
+func (d *Door) Trigger() (DoorState, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch d.State {
+	case DoorSecret:
+		return d.State, fmt.Errorf("door %s has not been found", d.ID)
+	case DoorOpen:
+		d.State = DoorClosed
+	default:
+		d.State = DoorOpen
+	}
+	return d.State, nil
+}
+
+// AttemptPickLock rolls c's attempt to pick the door's lock. A success
+// leaves the door closed but unlocked; missing the DC by
+// LockJamFailureMargin or more jams the lock for good.
+func (d *Door) AttemptPickLock(c *Character, roller *DiceRoller) (DoorCheck, error) {
+	d.mu.RLock()
+	state, jammed, dc := d.State, d.Jammed, d.LockDC
+	d.mu.RUnlock()
+
+	if state != DoorLocked {
+		return DoorCheck{}, fmt.Errorf("door %s is not locked", d.ID)
+	}
+	if jammed || dc < 1 {
+		return DoorCheck{}, fmt.Errorf("the lock of door %s cannot be picked", d.ID)
+	}
+
+	roll, err := roller.Roll("1d20")
+	if err != nil {
+		return DoorCheck{}, fmt.Errorf("failed to roll lock picking check: %w", err)
+	}
+	check := doorCheck(roll.Final, trapCheckBonus(c, c.Dexterity, SkillOpenLocks), dc)
+
+	d.mu.Lock()
+	switch {
+	case check.Success:
+		d.State = DoorClosed
+	case check.DC-check.Total >= LockJamFailureMargin:
+		d.Jammed = true
+	}
+	d.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "AttemptPickLock",
+		"package":      "game",
+		"door_id":      d.ID,
+		"character_id": c.ID,
+		"total":        check.Total,
+		"dc":           check.DC,
+		"success":      check.Success,
+	}).Debug("lock picking attempted")
+
+	return check, nil
+}
+
+// AttemptSearch rolls c's search for a secret door. A success reveals the
+// door, closed, to everyone.
+func (d *Door) AttemptSearch(c *Character, roller *DiceRoller) (DoorCheck, error) {
+	d.mu.RLock()
+	state, dc := d.State, d.SearchDC
+	d.mu.RUnlock()
+
+	if state != DoorSecret {
+		return DoorCheck{}, fmt.Errorf("door %s is not hidden", d.ID)
+	}
+	if dc < 1 {
+		dc = DefaultDoorSearchDC
+	}
+
+	roll, err := roller.Roll("1d20")
+	if err != nil {
+		return DoorCheck{}, fmt.Errorf("failed to roll search check: %w", err)
+	}
+	check := doorCheck(roll.Final, trapCheckBonus(c, c.Wisdom, SkillFindSecretDoors), dc)
+	if check.Success {
+		d.mu.Lock()
+		d.State = DoorClosed
+		d.mu.Unlock()
+	}
+	return check, nil
+}
+
+// doorCheck compares a roll plus bonus against dc
+func doorCheck(roll, bonus, dc int) DoorCheck {
+	total := roll + bonus
+	return DoorCheck{
+		Roll:    roll,
+		Bonus:   bonus,
+		Total:   total,
+		DC:      dc,
+		Success: total >= dc,
+	}
+}
+
+// NewKeyItem creates the key item that unlocks a door.
+func NewKeyItem(keyID, doorName string) Item {
+	return Item{
+		ID:         keyID,
+		Name:       fmt.Sprintf("Key to the %s", doorName),
+		Type:       ItemTypeKey,
+		Weight:     0,
+		Value:      1,
+		Properties: []string{"key"},
+	}
+}
+
+// KeyObject is a door key lying in the world until a player picks it up.
+// It shares its ID with the key item it holds.
+type KeyObject struct {
+	mu       sync.RWMutex `yaml:"-"`
+	Item     Item         `yaml:"key_item"`     // The key, as it enters an inventory
+	Position Position     `yaml:"key_position"` // Where the key lies
+}
+
+// NewKeyObject creates the key of a door lying at pos.
+func NewKeyObject(keyID, doorName string, pos Position) *KeyObject {
+	return &KeyObject{Item: NewKeyItem(keyID, doorName), Position: pos}
+}
+
+// FromJSON implements GameObject.
+func (k *KeyObject) FromJSON(data []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return json.Unmarshal(data, k)
+}
+
+// ToJSON implements GameObject.
+func (k *KeyObject) ToJSON() ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return json.Marshal(k)
+}
+
+// GetID implements GameObject.
+func (k *KeyObject) GetID() string {
+	return k.Item.ID
+}
+
+// GetName implements GameObject.
+func (k *KeyObject) GetName() string {
+	return k.Item.Name
+}
+
+// GetDescription implements GameObject.
+func (k *KeyObject) GetDescription() string {
+	return k.Item.Name
+}
+
+// GetPosition implements GameObject.
+func (k *KeyObject) GetPosition() Position {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.Position
+}
+
+// SetPosition implements GameObject.
+func (k *KeyObject) SetPosition(pos Position) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.Position = pos
+	return nil
+}
+
+// GetHealth implements GameObject. Keys have no health.
+func (k *KeyObject) GetHealth() int {
+	return 0
+}
+
+// SetHealth implements GameObject. Keys have no health, so this is a no-op.
+func (k *KeyObject) SetHealth(health int) {}
+
+// IsActive implements GameObject. Keys are always active.
+func (k *KeyObject) IsActive() bool {
+	return true
+}
+
+// GetTags implements GameObject.
+func (k *KeyObject) GetTags() []string {
+	return []string{"key"}
+}
+
+// IsObstacle implements GameObject. Keys never block movement.
+func (k *KeyObject) IsObstacle() bool {
+	return false
+}
+
+// FromJSON implements GameObject.
+func (d *Door) FromJSON(data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return json.Unmarshal(data, d)
+}
+
+// ToJSON implements GameObject.
+func (d *Door) ToJSON() ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return json.Marshal(d)
+}
+
+// GetID implements GameObject.
+func (d *Door) GetID() string {
+	return d.ID
+}
+
+// GetName implements GameObject.
+func (d *Door) GetName() string {
+	return d.Name
+}
+
+// GetDescription implements GameObject.
+func (d *Door) GetDescription() string {
+	return d.Description
+}
+
+// GetPosition implements GameObject.
+func (d *Door) GetPosition() Position {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.Position
+}
+
+// SetPosition implements GameObject.
+func (d *Door) SetPosition(pos Position) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Position = pos
+	return nil
+}
+
+// GetHealth implements GameObject. Doors have no health.
+func (d *Door) GetHealth() int {
+	return 0
+}
+
+// SetHealth implements GameObject. Doors have no health, so this is a no-op.
+func (d *Door) SetHealth(health int) {}
+
+// IsActive implements GameObject. Doors are always active.
+func (d *Door) IsActive() bool {
+	return true
+}
+
+// GetTags implements GameObject.
+func (d *Door) GetTags() []string {
+	return []string{"door", string(d.GetState())}
+}
+
+// IsObstacle implements GameObject. Doors block movement unless open.
+func (d *Door) IsObstacle() bool {
+	return d.GetState() != DoorOpen
+}
@@ -0,0 +1,132 @@
+package game
+
+import "testing"
+
+func newTestDoor(state DoorState) *Door {
+	return &Door{
+		ID:       "door-1",
+		Name:     "Iron Door",
+		Position: Position{X: 3, Y: 4},
+		State:    state,
+		LockDC:   15,
+		KeyID:    "door-1_key",
+	}
+}
+
+func TestDoor_Validate(t *testing.T) {
+	if err := newTestDoor(DoorLocked).Validate(); err != nil {
+		t.Fatalf("expected valid door, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*Door)
+	}{
+		{"missing ID", func(d *Door) { d.ID = "" }},
+		{"unknown state", func(d *Door) { d.State = "ajar" }},
+		{"lock without key or DC", func(d *Door) { d.KeyID, d.LockDC = "", 0 }},
+		{"negative DC", func(d *Door) { d.SearchDC = -1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			door := newTestDoor(DoorLocked)
+			tt.mutate(door)
+			if err := door.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestDoor_OpenAndClose(t *testing.T) {
+	door := newTestDoor(DoorLocked)
+	fighter := newTestTrapCharacter("fighter", ClassFighter)
+
+	if _, err := door.Open(fighter); err == nil {
+		t.Fatal("a locked door should not open without its key")
+	}
+	if !door.IsObstacle() {
+		t.Error("a locked door should block movement")
+	}
+
+	fighter.Inventory = append(fighter.Inventory, NewKeyItem(door.KeyID, door.Name))
+	usedKey, err := door.Open(fighter)
+	if err != nil {
+		t.Fatalf("Open with key failed: %v", err)
+	}
+	if !usedKey || door.GetState() != DoorOpen || door.IsObstacle() {
+		t.Errorf("expected the key to open the door, got state %s", door.GetState())
+	}
+	if _, err := door.Open(fighter); err == nil {
+		t.Error("opening an open door should fail")
+	}
+
+	if err := door.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if door.GetState() != DoorClosed {
+		t.Errorf("state = %s, want closed", door.GetState())
+	}
+	if usedKey, err := door.Open(newTestTrapCharacter("other", ClassMage)); err != nil || usedKey {
+		t.Errorf("a closed door should open without a key, got %v", err)
+	}
+}
+
+func TestDoor_AttemptPickLock(t *testing.T) {
+	t.Run("success unlocks", func(t *testing.T) {
+		door := newTestDoor(DoorLocked)
+		thief := newTestTrapCharacter("thief", ClassThief)
+		thief.Skills[SkillOpenLocks] = 20
+
+		check, err := door.AttemptPickLock(thief, NewDiceRollerWithSeed(1))
+		if err != nil {
+			t.Fatalf("AttemptPickLock failed: %v", err)
+		}
+		if !check.Success || door.GetState() != DoorClosed {
+			t.Errorf("expected the lock to open, got check %+v state %s", check, door.GetState())
+		}
+		if _, err := door.AttemptPickLock(thief, NewDiceRollerWithSeed(1)); err == nil {
+			t.Error("picking an unlocked door should fail")
+		}
+	})
+
+	t.Run("bad failure jams", func(t *testing.T) {
+		door := newTestDoor(DoorLocked)
+		door.LockDC = 40
+		fighter := newTestTrapCharacter("fighter", ClassFighter)
+
+		check, err := door.AttemptPickLock(fighter, NewDiceRollerWithSeed(1))
+		if err != nil {
+			t.Fatalf("AttemptPickLock failed: %v", err)
+		}
+		if check.Success || !door.Jammed || door.GetState() != DoorLocked {
+			t.Fatalf("expected a jammed lock, got check %+v", check)
+		}
+		if _, err := door.AttemptPickLock(fighter, NewDiceRollerWithSeed(1)); err == nil {
+			t.Error("a jammed lock should not be picked again")
+		}
+	})
+}
+
+func TestDoor_AttemptSearch(t *testing.T) {
+	door := newTestDoor(DoorSecret)
+	door.KeyID, door.LockDC = "", 0
+	ranger := newTestTrapCharacter("ranger", ClassRanger)
+
+	if _, err := door.Open(ranger); err == nil {
+		t.Error("a secret door should not open before it is found")
+	}
+
+	ranger.Skills[SkillFindSecretDoors] = 20
+	check, err := door.AttemptSearch(ranger, NewDiceRollerWithSeed(3))
+	if err != nil {
+		t.Fatalf("AttemptSearch failed: %v", err)
+	}
+	if check.DC != DefaultDoorSearchDC || !check.Success || door.GetState() != DoorClosed {
+		t.Errorf("expected the door to be found, got check %+v state %s", check, door.GetState())
+	}
+	if _, err := door.AttemptSearch(ranger, NewDiceRollerWithSeed(3)); err == nil {
+		t.Error("searching for a found door should fail")
+	}
+}
@@ -219,6 +219,8 @@ func (em *EffectManager) processDamageEffect(effect *DamageEffect, currentTime t
 	case EffectDamageOverTime:
 	case EffectHealOverTime:
 	case EffectRoot:
+	case EffectSilence:
+	case EffectBound:
 	case EffectStatBoost:
 	case EffectStatPenalty:
 	case EffectStun:
@@ -387,6 +389,8 @@ func (em *EffectManager) processEffectTick(effect *Effect) {
 	case EffectBurning:
 	case EffectPoison:
 	case EffectRoot:
+	case EffectSilence:
+	case EffectBound:
 	case EffectStatBoost:
 	case EffectStatPenalty:
 	case EffectStun:
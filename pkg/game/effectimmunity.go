@@ -122,6 +122,8 @@ func (em *EffectManager) CheckImmunity(effectType EffectType) *ImmunityData {
 // Notable behaviors:
 //   - Thread-safe due to mutex locking
 //   - Only removes effects marked as removable
+//   - Effects of equal priority are removed most recent first, then by ID
+//   - Removed effects are marked inactive, as RemoveEffect does
 //   - Automatically recalculates stats if any effects were removed
 //   - If count exceeds available effects, removes all eligible effects
 //
@@ -143,30 +145,31 @@ func (em *EffectManager) DispelEffects(dispelType DispelType, count int) []strin
 	var candidates []dispelCandidate
 
 	for id, effect := range em.activeEffects {
-		if !effect.DispelInfo.Removable {
-			continue
-		}
-
-		for _, dType := range effect.DispelInfo.Types {
-			if dType == dispelType || dispelType == DispelAll {
-				candidates = append(candidates, dispelCandidate{
-					id:       id,
-					effect:   effect,
-					priority: effect.DispelInfo.Priority,
-				})
-				break
-			}
+		if effect.DispelInfo.CanBeDispelledBy(dispelType) {
+			candidates = append(candidates, dispelCandidate{
+				id:       id,
+				effect:   effect,
+				priority: effect.DispelInfo.Priority,
+			})
 		}
 	}
 
-	// Sort by priority (highest first)
+	// Sort by priority (highest first), then most recent first
 	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].priority > candidates[j].priority
+		a, b := candidates[i], candidates[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		if !a.effect.StartTime.Equal(b.effect.StartTime) {
+			return a.effect.StartTime.After(b.effect.StartTime)
+		}
+		return a.id < b.id
 	})
 
 	// Remove effects
-	removed := make([]string, 0, count)
+	removed := make([]string, 0, max(count, 0))
 	for i := 0; i < len(candidates) && i < count; i++ {
+		candidates[i].effect.IsActive = false
 		delete(em.activeEffects, candidates[i].id)
 		removed = append(removed, candidates[i].id)
 	}
@@ -242,32 +245,58 @@ func CreatePoisonEffectWithDispel(baseDamage float64, duration time.Duration) *D
 // Error cases:
 //   - Returns error if target has complete immunity to the effect type
 //   - Returns error if effect is reflected
-//   - Panics if an unknown immunity type is encountered
+//   - Returns error if an unknown immunity type is encountered
 //
 // Related:
-//   - CheckImmunity() - Called internally to determine immunity status
-//   - applyEffectInternal() - Called to handle actual effect application
+//   - ResolveEffect() - Called internally, reports the outcome instead of an error
 func (em *EffectManager) ApplyEffect(effect *Effect) error {
+	resolution, err := em.ResolveEffect(effect, 0)
+	if err != nil {
+		return err
+	}
+	return resolution.err(effect.Type)
+}
+
+// ResolveEffect applies effect to the target and reports the outcome.
+// resistance is the fraction of the magnitude the target resists, as
+// described by Resistances; the manager's own immunities combine with it.
+//
+// Parameters:
+//   - effect: *Effect - The effect to be applied; its magnitude is reduced by resistance
+//   - resistance: float64 - Resistance of the target, negative for a vulnerability
+//
+// Returns:
+//   - EffectResolution: The outcome, including immunity and reflection
+//   - error: Returns an error only if the effect could not be resolved, such as a
+//     weaker effect applied under StackDefault or an unknown immunity type
+//
+// Related:
+//   - CheckImmunity() - Called internally to determine immunity status
+//   - applyEffectInternal() - Called to handle stacking and actual effect application
+func (em *EffectManager) ResolveEffect(effect *Effect, resistance float64) (EffectResolution, error) {
 	immunity := em.CheckImmunity(effect.Type)
 
 	switch immunity.Type {
 	case ImmunityComplete:
-		return fmt.Errorf("target is immune to %s effects", effect.Type)
-
+		return EffectResolution{Outcome: OutcomeImmune, Resistance: ResistanceImmune}, nil
 	case ImmunityReflect:
-		// Handle reflection logic
-		return fmt.Errorf("effect reflected")
-
+		return EffectResolution{Outcome: OutcomeReflected}, nil
 	case ImmunityPartial:
-		effect.Magnitude *= (1 - immunity.Resistance)
+		resistance = combineResistances(resistance, immunity.Resistance)
 	case ImmunityNone:
 		// No immunity, proceed normally
 	default:
-		return fmt.Errorf("unknown immunity type: %v", immunity.Type)
+		return EffectResolution{}, fmt.Errorf("unknown immunity type: %v", immunity.Type)
+	}
+
+	if resistance >= ResistanceImmune {
+		return EffectResolution{Outcome: OutcomeImmune, Resistance: ResistanceImmune}, nil
 	}
+	effect.Magnitude *= 1 - resistance
 
-	// Continue with normal effect application...
-	return em.applyEffectInternal(effect)
+	resolution, err := em.applyEffectInternal(effect)
+	resolution.Resistance = resistance
+	return resolution, err
 }
 
 // ExampleEffectDispel demonstrates how to create, apply and dispel effects in the game.
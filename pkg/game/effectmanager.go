@@ -562,6 +562,15 @@ func (et EffectType) AllowsStacking() bool {
 	return allowsStacking
 }
 
+// stackingPolicy returns the policy effect is reapplied under. Effects with
+// StackDefault stack when their type AllowsStacking.
+func (e *Effect) stackingPolicy() StackingPolicy {
+	if e.Stacking == StackDefault && e.Type.AllowsStacking() {
+		return StackMagnitude
+	}
+	return e.Stacking
+}
+
 // applyEffectInternal applies an effect to an entity's active effects list, handling stacking
 // and magnitude-based replacement of existing effects.
 //
@@ -569,20 +578,23 @@ func (et EffectType) AllowsStacking() bool {
 //   - effect: *Effect - The effect to be applied. Must not be nil.
 //
 // Returns:
-//   - error: Returns nil on successful application, or an error if:
-//   - A weaker non-stacking effect is applied when a stronger one exists
-//   - The effect parameter is nil
-//
-// Behavior:
-//   - For stackable effects: Increments stack count on existing effect
-//   - For non-stackable effects: Replaces existing if new effect is stronger
+//   - EffectResolution: The outcome and the active effect it resulted in
+//   - error: Returns nil on successful application, or an error if a weaker
+//     effect is applied under StackDefault when a stronger one exists
+//
+// Behavior, when an effect of the same type is active, by the new effect's stacking policy:
+//   - StackMagnitude: Increments the stack count of the existing effect, up to MaxStacks,
+//     then refreshes its duration
+//   - StackRefresh: Restarts the existing effect, keeping the greater magnitude
+//   - StackIgnore: Leaves the existing effect unchanged
+//   - StackDefault: Replaces the existing effect if the new one is stronger
 //   - For new effect types: Adds to active effects list
 //   - Recalculates stats after any changes
 //
 // Related:
 //   - Effect.Type.AllowsStacking()
 //   - EffectManager.recalculateStats()
-func (em *EffectManager) applyEffectInternal(effect *Effect) error {
+func (em *EffectManager) applyEffectInternal(effect *Effect) (EffectResolution, error) {
 	logrus.WithFields(logrus.Fields{
 		"function":    "applyEffectInternal",
 		"package":     "game",
@@ -590,57 +602,80 @@ func (em *EffectManager) applyEffectInternal(effect *Effect) error {
 		"effect_type": effect.Type,
 		"magnitude":   effect.Magnitude,
 		"duration":    effect.Duration,
+		"stacking":    effect.Stacking,
 	}).Debug("function entry - applying effect internally")
 
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
 	// Check for existing effect of same type
-	effectReplaced := false
+	outcome := OutcomeApplied
 	for _, existing := range em.activeEffects {
-		if existing.Type == effect.Type {
+		if existing.Type != effect.Type {
+			continue
+		}
+
+		policy := effect.stackingPolicy()
+		logrus.WithFields(logrus.Fields{
+			"function":           "applyEffectInternal",
+			"package":            "game",
+			"effect_id":          effect.ID,
+			"existing_effect_id": existing.ID,
+			"effect_type":        effect.Type,
+			"stacking":           policy,
+		}).Debug("found existing effect of same type")
+
+		switch {
+		case policy == StackIgnore:
+			return resolutionOf(OutcomeIgnored, existing), nil
+		case policy == StackMagnitude && (effect.MaxStacks <= 0 || existing.Stacks < effect.MaxStacks):
+			existing.Stacks++
+			em.recalculateStats()
+			logrus.WithFields(logrus.Fields{
+				"function":    "applyEffectInternal",
+				"package":     "game",
+				"effect_id":   existing.ID,
+				"effect_type": effect.Type,
+				"new_stacks":  existing.Stacks,
+			}).Debug("stacked effect on existing instance")
+			return resolutionOf(OutcomeStacked, existing), nil
+		case policy == StackMagnitude || policy == StackRefresh:
+			existing.StartTime = time.Now()
+			existing.StartRound = effect.StartRound
+			existing.StartTurn = effect.StartTurn
+			existing.Duration = effect.Duration
+			existing.Magnitude = max(existing.Magnitude, effect.Magnitude)
+			em.recalculateStats()
+			logrus.WithFields(logrus.Fields{
+				"function":    "applyEffectInternal",
+				"package":     "game",
+				"effect_id":   existing.ID,
+				"effect_type": effect.Type,
+				"magnitude":   existing.Magnitude,
+			}).Debug("refreshed existing effect")
+			return resolutionOf(OutcomeRefreshed, existing), nil
+		case effect.Magnitude > existing.Magnitude:
+			// Replace if new effect is stronger
+			existing.IsActive = false
+			delete(em.activeEffects, existing.ID)
+			outcome = OutcomeReplaced
+			logrus.WithFields(logrus.Fields{
+				"function":      "applyEffectInternal",
+				"package":       "game",
+				"old_effect_id": existing.ID,
+				"new_effect_id": effect.ID,
+				"old_magnitude": existing.Magnitude,
+				"new_magnitude": effect.Magnitude,
+			}).Debug("replaced weaker effect with stronger one")
+		default:
 			logrus.WithFields(logrus.Fields{
 				"function":           "applyEffectInternal",
 				"package":            "game",
 				"effect_id":          effect.ID,
-				"existing_effect_id": existing.ID,
-				"effect_type":        effect.Type,
-				"allows_stacking":    effect.Type.AllowsStacking(),
-			}).Debug("found existing effect of same type")
-
-			switch {
-			case effect.Type.AllowsStacking():
-				existing.Stacks++
-				logrus.WithFields(logrus.Fields{
-					"function":    "applyEffectInternal",
-					"package":     "game",
-					"effect_id":   existing.ID,
-					"effect_type": effect.Type,
-					"new_stacks":  existing.Stacks,
-				}).Debug("stacked effect on existing instance")
-				return nil
-			case effect.Magnitude > existing.Magnitude:
-				// Replace if new effect is stronger
-				delete(em.activeEffects, existing.ID)
-				effectReplaced = true
-				logrus.WithFields(logrus.Fields{
-					"function":      "applyEffectInternal",
-					"package":       "game",
-					"old_effect_id": existing.ID,
-					"new_effect_id": effect.ID,
-					"old_magnitude": existing.Magnitude,
-					"new_magnitude": effect.Magnitude,
-				}).Debug("replaced weaker effect with stronger one")
-			default:
-				logrus.WithFields(logrus.Fields{
-					"function":           "applyEffectInternal",
-					"package":            "game",
-					"effect_id":          effect.ID,
-					"existing_magnitude": existing.Magnitude,
-					"new_magnitude":      effect.Magnitude,
-				}).Warn("attempted to apply weaker effect - rejected")
-				return fmt.Errorf("cannot apply weaker effect of same type")
-			}
+				"existing_magnitude": existing.Magnitude,
+				"new_magnitude":      effect.Magnitude,
+			}).Warn("attempted to apply weaker effect - rejected")
+			return EffectResolution{}, fmt.Errorf("cannot apply weaker effect of same type")
 		}
 	}
 
@@ -655,7 +690,7 @@ func (em *EffectManager) applyEffectInternal(effect *Effect) error {
 		"effect_id":    effect.ID,
 		"effect_type":  effect.Type,
 		"start_time":   effect.StartTime,
-		"replaced":     effectReplaced,
+		"outcome":      outcome,
 		"total_active": len(em.activeEffects),
 	}).Debug("added new effect to active effects")
 
@@ -668,7 +703,18 @@ func (em *EffectManager) applyEffectInternal(effect *Effect) error {
 		"effect_id": effect.ID,
 	}).Debug("function exit - effect applied successfully")
 
-	return nil
+	return resolutionOf(outcome, effect), nil
+}
+
+// resolutionOf returns the resolution with outcome that left active as the
+// target's effect. Resistance is filled in by the caller.
+func resolutionOf(outcome EffectOutcome, active *Effect) EffectResolution {
+	return EffectResolution{
+		Outcome:   outcome,
+		EffectID:  active.ID,
+		Magnitude: active.Magnitude,
+		Stacks:    active.Stacks,
+	}
 }
 
 // EffectHolder interface implementation
@@ -1032,3 +1032,32 @@ func TestEffect_IsExpiredWithContext(t *testing.T) {
 		})
 	}
 }
+
+// TestEffect_ExpiresWithin tests effect expiration when game time passes at once
+func TestEffect_ExpiresWithin(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		effect   *Effect
+		elapsed  time.Duration
+		expected bool
+	}{
+		{"Real-time - outlasts", &Effect{StartTime: now, Duration: Duration{RealTime: 2 * time.Hour}}, time.Hour, false},
+		{"Real-time - runs out", &Effect{StartTime: now.Add(-30 * time.Minute), Duration: Duration{RealTime: time.Hour}}, time.Hour, true},
+		{"Round-based - outlasts", &Effect{Duration: Duration{Rounds: 90}}, time.Hour, false},
+		{"Round-based - runs out", &Effect{Duration: Duration{Rounds: 60}}, time.Hour, true},
+		{"Turn-based - outlasts", &Effect{Duration: Duration{Turns: 7}}, time.Hour, false},
+		{"Turn-based - runs out", &Effect{Duration: Duration{Turns: 6}}, time.Hour, true},
+		{"Permanent", &Effect{Duration: Duration{Rounds: -1}}, 24 * time.Hour, false},
+		{"Instant", &Effect{}, time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.effect.ExpiresWithin(now, tt.elapsed); result != tt.expected {
+				t.Errorf("ExpiresWithin() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -55,6 +55,8 @@ type DispelPriority int
 //   - StatAffected: Which stat the effect modifies
 //   - IsActive: Whether effect is currently active
 //   - Stacks: Number of times effect has stacked
+//   - MaxStacks: Cap on Stacks under the StackMagnitude policy
+//   - Stacking: Policy for reapplying an effect of the same type
 //   - Tags: Labels for categorizing/filtering effects
 //   - DispelInfo: Rules for removing/dispelling the effect
 //   - Modifiers: List of stat/attribute modifications
@@ -86,9 +88,11 @@ type Effect struct {
 	TargetID     string `yaml:"effect_target"`
 	StatAffected string `yaml:"effect_stat_affected"`
 
-	IsActive bool     `yaml:"effect_active"`
-	Stacks   int      `yaml:"effect_stacks"`
-	Tags     []string `yaml:"effect_tags"`
+	IsActive  bool           `yaml:"effect_active"`
+	Stacks    int            `yaml:"effect_stacks"`
+	MaxStacks int            `yaml:"effect_max_stacks,omitempty"` // Stack cap under StackMagnitude, 0 for none
+	Stacking  StackingPolicy `yaml:"effect_stacking,omitempty"`   // What reapplying the effect's type does
+	Tags      []string       `yaml:"effect_tags"`
 
 	DispelInfo DispelInfo `yaml:"dispel_info"`
 	Modifiers  []Modifier `yaml:"effect_modifiers"`
@@ -414,6 +418,45 @@ func (e *Effect) IsExpiredWithContext(currentTime time.Time, currentRound, curre
 	return false
 }
 
+// Game time lengths of a combat round and a turn, used to age round- and
+// turn-based effects when game time passes outside combat.
+const (
+	RoundLength = time.Minute
+	TurnLength  = 10 * time.Minute
+)
+
+// ExpiresWithin checks if the effect runs out when elapsed game time passes
+// at once after currentTime, as while the party rests or waits.
+//
+// Parameters:
+//   - currentTime time.Time: The time the game time starts passing
+//   - elapsed time.Duration: The game time passed
+//
+// Returns:
+//   - bool: true if the effect has expired by the end of elapsed
+//
+// Notes:
+// - Real-time effects expire when startTime + duration falls within elapsed
+// - Round- and turn-based effects count each round as RoundLength and each
+// turn as TurnLength from currentTime, so they expire once elapsed covers
+// their whole duration
+// - Negative durations are permanent effects (never expire)
+func (e *Effect) ExpiresWithin(currentTime time.Time, elapsed time.Duration) bool {
+	if e.Duration.RealTime > 0 {
+		return !currentTime.Add(elapsed).Before(e.StartTime.Add(e.Duration.RealTime))
+	}
+	if e.Duration.RealTime < 0 || e.Duration.Rounds < 0 || e.Duration.Turns < 0 {
+		return false
+	}
+	if e.Duration.Rounds > 0 {
+		return elapsed >= time.Duration(e.Duration.Rounds)*RoundLength
+	}
+	if e.Duration.Turns > 0 {
+		return elapsed >= time.Duration(e.Duration.Turns)*TurnLength
+	}
+	return true
+}
+
 // ShouldTick determines if the effect should trigger based on its tick rate.
 // It checks if enough real time has elapsed since the effect started for the next tick to occur.
 //
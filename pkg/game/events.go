@@ -2,6 +2,7 @@ package game
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 // EventType represents different types of game events
@@ -81,6 +82,7 @@ type EventHandler func(event GameEvent)
 type EventSystem struct {
 	mu       sync.RWMutex                 `yaml:"mutex,omitempty"`          // Mutex for thread safety
 	handlers map[EventType][]EventHandler `yaml:"event_handlers,omitempty"` // Map of event handlers
+	pending  atomic.Int64                 `yaml:"-"`                        // Handlers started but not finished
 }
 
 // EventSystemConfig defines the configuration settings for the event handling system.
@@ -227,10 +229,20 @@ func (es *EventSystem) Emit(event GameEvent) {
 	es.mu.RUnlock()
 
 	for _, handler := range handlers {
-		go handler(event) // Async event handling
+		es.pending.Add(1)
+		go func(handler EventHandler) {
+			defer es.pending.Add(-1)
+			handler(event) // Async event handling
+		}(handler)
 	}
 }
 
+// Pending returns the number of event handlers still running, the depth of
+// the event queue reported by the server's health checks.
+func (es *EventSystem) Pending() int64 {
+	return es.pending.Load()
+}
+
 // emitLevelUpEvent sends a level up event to the default event system when a player levels up.
 // It creates a GameEvent with the level up information and emits it.
 //
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// IDNamespace is the prefix that marks what kind of entity an ID names.
+type IDNamespace string
+
+const (
+	IDNamespaceNPC   IDNamespace = "npc"
+	IDNamespaceItem  IDNamespace = "item"
+	IDNamespaceQuest IDNamespace = "quest"
+)
+
+// Kind returns the namespace for one kind of entity within ns, such as
+// "quest_fetch" for fetch quests. IDNamespaceOf still reports ns for its IDs.
+func (ns IDNamespace) Kind(kind string) IDNamespace {
+	return IDNamespace(string(ns) + "_" + kind)
+}
+
+// ErrIDCollision is returned when an ID is already in use.
+var ErrIDCollision = errors.New("id already in use")
+
+// SeededID derives the ID of generated content from a seed and a name that
+// tells apart the pieces generated from the same seed, such as an index or a
+// template. The same inputs always give the same ID, so content regenerated
+// from a saved seed keeps the IDs that saves and quests refer to.
+//
+// Thread Safety:
+//   - This function is thread-safe and does not modify shared state.
+//
+// Returns an ID of the form "<namespace>_<16 hex digits>".
+func SeededID(namespace IDNamespace, seed int64, name string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%s", namespace, seed, name)))
+	return fmt.Sprintf("%s_%016x", namespace, binary.BigEndian.Uint64(hash[:8]))
+}
+
+// RandomID returns a new random ID in namespace for entities created at
+// runtime, which never need to be regenerated.
+//
+// Thread Safety:
+//   - This function is thread-safe and does not modify shared state.
+//
+// Returns an ID of the form "<namespace>_<UUID v4>".
+func RandomID(namespace IDNamespace) string {
+	return fmt.Sprintf("%s_%s", namespace, uuid.NewString())
+}
+
+// IDNamespaceOf returns the namespace prefix of id, or "" if it has none.
+func IDNamespaceOf(id string) IDNamespace {
+	prefix, _, found := strings.Cut(id, "_")
+	if !found {
+		return ""
+	}
+	return IDNamespace(prefix)
+}
+
+// IDService issues entity IDs and remembers them, so an ID is never handed
+// out twice. Generated content takes seeded IDs, which are reproducible;
+// runtime entities take random ones. IDs loaded from a save are reserved so
+// new IDs cannot collide with them.
+//
+// Thread Safety:
+//   - All methods are safe for concurrent use.
+type IDService struct {
+	mu     sync.Mutex
+	issued map[string]struct{}
+}
+
+// NewIDService creates an ID service with no IDs in use.
+func NewIDService() *IDService {
+	return &IDService{issued: make(map[string]struct{})}
+}
+
+// Seeded issues the seeded ID of name within namespace.
+//
+// Returns:
+//   - string: The ID from SeededID
+//   - error: ErrIDCollision if the ID is already in use, which means the
+//     same content was generated twice or its name is not unique
+func (s *IDService) Seeded(namespace IDNamespace, seed int64, name string) (string, error) {
+	id := SeededID(namespace, seed, name)
+	if err := s.Reserve(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Random issues a new random ID in namespace.
+func (s *IDService) Random(namespace IDNamespace) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		id := RandomID(namespace)
+		if _, exists := s.issued[id]; !exists {
+			s.issued[id] = struct{}{}
+			return id
+		}
+	}
+}
+
+// Reserve marks an existing ID, such as one loaded from a save, as in use.
+// It returns ErrIDCollision if the ID is already in use.
+func (s *IDService) Reserve(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.issued[id]; exists {
+		return fmt.Errorf("%w: %s", ErrIDCollision, id)
+	}
+	s.issued[id] = struct{}{}
+	return nil
+}
+
+// Release frees an ID whose entity was removed, so regenerating the same
+// content can issue it again.
+func (s *IDService) Release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.issued, id)
+}
+
+// InUse reports whether id has been issued or reserved.
+func (s *IDService) InUse(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.issued[id]
+	return exists
+}
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeededID_Deterministic(t *testing.T) {
+	id := SeededID(IDNamespaceNPC, 42, "guard:1")
+	assert.Equal(t, id, SeededID(IDNamespaceNPC, 42, "guard:1"), "same inputs give the same ID")
+	assert.Regexp(t, `^npc_[0-9a-f]{16}$`, id)
+
+	assert.NotEqual(t, id, SeededID(IDNamespaceNPC, 43, "guard:1"))
+	assert.NotEqual(t, id, SeededID(IDNamespaceNPC, 42, "guard:2"))
+	assert.NotEqual(t, strings.TrimPrefix(id, "npc_"), strings.TrimPrefix(SeededID(IDNamespaceItem, 42, "guard:1"), "item_"),
+		"the namespace is part of the derivation")
+
+	fetch := SeededID(IDNamespaceQuest.Kind("fetch"), 7, "")
+	assert.True(t, strings.HasPrefix(fetch, "quest_fetch_"))
+	assert.Equal(t, IDNamespaceQuest, IDNamespaceOf(fetch))
+	assert.Equal(t, IDNamespace(""), IDNamespaceOf("legacy"))
+}
+
+func TestIDService_CollisionDetection(t *testing.T) {
+	ids := NewIDService()
+
+	id, err := ids.Seeded(IDNamespaceItem, 9, "sword:1")
+	require.NoError(t, err)
+	assert.True(t, ids.InUse(id))
+
+	_, err = ids.Seeded(IDNamespaceItem, 9, "sword:1")
+	assert.ErrorIs(t, err, ErrIDCollision, "generating the same content twice collides")
+
+	ids.Release(id)
+	again, err := ids.Seeded(IDNamespaceItem, 9, "sword:1")
+	require.NoError(t, err)
+	assert.Equal(t, id, again, "released IDs can be issued again")
+
+	require.NoError(t, ids.Reserve("npc_saved"))
+	assert.ErrorIs(t, ids.Reserve("npc_saved"), ErrIDCollision)
+}
+
+func TestIDService_RandomIDsAreUnique(t *testing.T) {
+	ids := NewIDService()
+	issued := make(chan string, 200)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				issued <- ids.Random(IDNamespaceNPC)
+			}
+		}()
+	}
+	wg.Wait()
+	close(issued)
+
+	seen := make(map[string]bool)
+	for id := range issued {
+		assert.Equal(t, IDNamespaceNPC, IDNamespaceOf(id))
+		assert.False(t, seen[id], "duplicate ID %s", id)
+		seen[id] = true
+		assert.True(t, ids.InUse(id))
+	}
+	assert.Len(t, seen, 200)
+}
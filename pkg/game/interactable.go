@@ -0,0 +1,410 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Interactable is a world object players can act on with verbs, such as
+// pulling a lever or drinking from a fountain.
+type Interactable interface {
+	GameObject
+
+	// Verbs returns the verbs that can still be used on the object.
+	Verbs() []string
+
+	// Interact performs verb for c and reports what happened.
+	Interact(c *Character, verb string, roller *DiceRoller) (*InteractionResult, error)
+}
+
+// FeatureKind names the kind of an interactive feature.
+type FeatureKind string
+
+const (
+	// FeatureLever works a mechanism each time it is pulled
+	FeatureLever FeatureKind = "lever"
+	// FeatureAltar grants a blessing to those whose prayers are heard
+	FeatureAltar FeatureKind = "altar"
+	// FeatureFountain heals those who drink from it
+	FeatureFountain FeatureKind = "fountain"
+	// FeatureBookshelf holds lore and, sometimes, something tucked away
+	FeatureBookshelf FeatureKind = "bookshelf"
+)
+
+// InteractionEffectType describes what an interaction does.
+type InteractionEffectType string
+
+const (
+	// InteractionHeal restores Dice hit points to the actor
+	InteractionHeal InteractionEffectType = "heal"
+	// InteractionDamage deals Dice damage to the actor
+	InteractionDamage InteractionEffectType = "damage"
+	// InteractionExperience grants Amount experience to the actor
+	InteractionExperience InteractionEffectType = "experience"
+	// InteractionGold gives Amount gold to the actor
+	InteractionGold InteractionEffectType = "gold"
+	// InteractionItem gives Item to the actor
+	InteractionItem InteractionEffectType = "item"
+	// InteractionCondition applies Condition to the actor for Rounds rounds
+	InteractionCondition InteractionEffectType = "condition"
+	// InteractionToggle switches the feature between on and off
+	InteractionToggle InteractionEffectType = "toggle"
+	// InteractionTrigger works the mechanism TargetID, such as a door. The
+	// feature only reports the trigger; whoever owns the world applies it.
+	InteractionTrigger InteractionEffectType = "trigger"
+)
+
+// InteractionEffect is one effect of an interaction. Which fields are used
+// depends on Type.
+type InteractionEffect struct {
+	Type      InteractionEffectType `yaml:"effect_type"`                // What the effect does
+	Dice      string                `yaml:"effect_dice,omitempty"`      // Hit points healed or lost, e.g. "1d8"
+	Amount    int                   `yaml:"effect_amount,omitempty"`    // Experience or gold granted
+	Item      *Item                 `yaml:"effect_item,omitempty"`      // Item given
+	Condition EffectType            `yaml:"effect_condition,omitempty"` // Effect applied
+	Rounds    int                   `yaml:"effect_rounds,omitempty"`    // Duration of the condition
+	Magnitude float64               `yaml:"effect_magnitude,omitempty"` // Strength of the condition
+	TargetID  string                `yaml:"effect_target,omitempty"`    // Object a trigger works
+}
+
+// Interaction is something a character can do with a feature. When DC is
+// set, a d20 roll plus the Ability modifier and Skill bonus must meet it;
+// OnSuccess effects apply when it does, or when there is no check, and
+// OnFailure effects when it does not. An interaction that is not
+// Repeatable is spent the first time it succeeds.
+type Interaction struct {
+	Verb        string              `yaml:"interaction_verb"`                  // What the player does, e.g. "pull"
+	Description string              `yaml:"interaction_description,omitempty"` // Description shown to players
+	Ability     string              `yaml:"interaction_ability,omitempty"`     // Ability of the check, e.g. "wisdom"
+	Skill       string              `yaml:"interaction_skill,omitempty"`       // Skill adding to the check
+	DC          int                 `yaml:"interaction_dc,omitempty"`          // Difficulty, 0 for no check
+	Repeatable  bool                `yaml:"interaction_repeatable,omitempty"`  // Whether it can succeed more than once
+	OnSuccess   []InteractionEffect `yaml:"interaction_on_success,omitempty"`  // Effects of a success
+	OnFailure   []InteractionEffect `yaml:"interaction_on_failure,omitempty"`  // Effects of a failed check
+}
+
+// InteractionCheck is the result of an interaction's ability check.
+type InteractionCheck struct {
+	Roll    int  `json:"roll"`    // The d20 result
+	Bonus   int  `json:"bonus"`   // Ability and skill bonus
+	Total   int  `json:"total"`   // Roll plus bonus
+	DC      int  `json:"dc"`      // Difficulty the total was compared against
+	Success bool `json:"success"` // Whether the total met the DC
+}
+
+// InteractionResult describes what an interaction did.
+type InteractionResult struct {
+	FeatureID  string            `json:"feature_id"`
+	Verb       string            `json:"verb"`
+	Success    bool              `json:"success"`
+	Check      *InteractionCheck `json:"check,omitempty"`
+	Healed     int               `json:"healed,omitempty"`
+	Damage     int               `json:"damage,omitempty"`
+	Experience int               `json:"experience,omitempty"`
+	LeveledUp  bool              `json:"leveled_up,omitempty"`
+	Gold       int               `json:"gold,omitempty"`
+	Items      []Item            `json:"items,omitempty"`
+	Conditions []*Effect         `json:"conditions,omitempty"`
+	Triggered  []string          `json:"triggered,omitempty"`
+	Active     bool              `json:"active"`
+}
+
+// Feature is an interactive fixture of the world, such as a lever, altar,
+// fountain or bookshelf. Its interactions declare the verbs players can
+// use on it, each with its own check, effects and one-time or repeatable
+// use. Features never block movement.
+//
+// Feature is safe for concurrent use.
+type Feature struct {
+	mu           sync.RWMutex    `yaml:"-"`
+	ID           string          `yaml:"feature_id"`                    // Unique identifier
+	Name         string          `yaml:"feature_name"`                  // Display name
+	Description  string          `yaml:"feature_description,omitempty"` // Description shown to players
+	Kind         FeatureKind     `yaml:"feature_kind"`                  // What kind of feature it is
+	Position     Position        `yaml:"feature_position"`              // Location in the game world
+	Interactions []Interaction   `yaml:"feature_interactions"`          // What can be done with it
+	Active       bool            `yaml:"feature_active,omitempty"`      // Whether a toggled feature is on
+	Spent        map[string]bool `yaml:"feature_spent,omitempty"`       // One-time verbs already used
+}
+
+// Validate checks that the feature's interactions can be performed.
+func (f *Feature) Validate() error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.ID == "" {
+		return fmt.Errorf("feature ID cannot be empty")
+	}
+	verbs := make(map[string]bool, len(f.Interactions))
+	for _, interaction := range f.Interactions {
+		if interaction.Verb == "" {
+			return fmt.Errorf("feature %s has an interaction without a verb", f.ID)
+		}
+		if verbs[interaction.Verb] {
+			return fmt.Errorf("feature %s declares %q twice", f.ID, interaction.Verb)
+		}
+		verbs[interaction.Verb] = true
+		if interaction.DC < 0 {
+			return fmt.Errorf("feature %s has a negative DC for %q", f.ID, interaction.Verb)
+		}
+		if interaction.DC > 0 && !isAttributeName(interaction.Ability) {
+			return fmt.Errorf("feature %s checks unknown ability %q for %q", f.ID, interaction.Ability, interaction.Verb)
+		}
+	}
+	return nil
+}
+
+// Verbs implements Interactable.
+func (f *Feature) Verbs() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	verbs := make([]string, 0, len(f.Interactions))
+	for _, interaction := range f.Interactions {
+		if !f.Spent[interaction.Verb] {
+			verbs = append(verbs, interaction.Verb)
+		}
+	}
+	return verbs
+}
+
+// IsOn reports whether a toggled feature, such as a lever, is on.
+func (f *Feature) IsOn() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.Active
+}
+
+// Interact implements Interactable. It rolls the interaction's check, if
+// any, and applies the resulting effects to c.
+func (f *Feature) Interact(c *Character, verb string, roller *DiceRoller) (*InteractionResult, error) {
+	f.mu.RLock()
+	interaction, found := f.interaction(verb)
+	spent := f.Spent[verb]
+	f.mu.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("%s cannot be used to %s", f.Name, verb)
+	}
+	if spent {
+		return nil, fmt.Errorf("%s has already been used to %s", f.Name, verb)
+	}
+
+	result := &InteractionResult{FeatureID: f.ID, Verb: verb, Success: true}
+	if interaction.DC > 0 {
+		roll, err := roller.Roll("1d20")
+		if err != nil {
+			return nil, fmt.Errorf("failed to roll %s check: %w", verb, err)
+		}
+		bonus := interactionCheckBonus(c, interaction.Ability, interaction.Skill)
+		result.Check = &InteractionCheck{
+			Roll:    roll.Final,
+			Bonus:   bonus,
+			Total:   roll.Final + bonus,
+			DC:      interaction.DC,
+			Success: roll.Final+bonus >= interaction.DC,
+		}
+		result.Success = result.Check.Success
+	}
+
+	effects := interaction.OnFailure
+	if result.Success {
+		effects = interaction.OnSuccess
+		f.mu.Lock()
+		if !interaction.Repeatable {
+			if f.Spent[verb] {
+				f.mu.Unlock()
+				return nil, fmt.Errorf("%s has already been used to %s", f.Name, verb)
+			}
+			if f.Spent == nil {
+				f.Spent = make(map[string]bool)
+			}
+			f.Spent[verb] = true
+		}
+		f.mu.Unlock()
+	}
+
+	for _, effect := range effects {
+		if err := f.applyEffect(c, effect, roller, result); err != nil {
+			return nil, err
+		}
+	}
+	result.Active = f.IsOn()
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "Interact",
+		"package":      "game",
+		"feature_id":   f.ID,
+		"character_id": c.ID,
+		"verb":         verb,
+		"success":      result.Success,
+	}).Debug("feature used")
+
+	return result, nil
+}
+
+// interaction returns the interaction with verb (requires the lock)
+func (f *Feature) interaction(verb string) (Interaction, bool) {
+	for _, interaction := range f.Interactions {
+		if interaction.Verb == verb {
+			return interaction, true
+		}
+	}
+	return Interaction{}, false
+}
+
+// applyEffect applies one effect of an interaction to c, recording it in
+// result
+func (f *Feature) applyEffect(c *Character, effect InteractionEffect, roller *DiceRoller, result *InteractionResult) error {
+	switch effect.Type {
+	case InteractionHeal, InteractionDamage:
+		roll, err := roller.Roll(effect.Dice)
+		if err != nil {
+			return fmt.Errorf("failed to roll %s of %s: %w", effect.Type, f.ID, err)
+		}
+		amount := max(roll.Final, 0)
+		if effect.Type == InteractionHeal {
+			before := c.GetHealth()
+			c.SetHealth(before + amount)
+			result.Healed += c.GetHealth() - before
+		} else {
+			c.SetHealth(c.GetHealth() - amount)
+			result.Damage += amount
+		}
+	case InteractionExperience:
+		leveledUp, err := c.AddExperience(int64(effect.Amount))
+		if err != nil {
+			return err
+		}
+		result.Experience += effect.Amount
+		result.LeveledUp = result.LeveledUp || leveledUp
+	case InteractionGold:
+		c.mu.Lock()
+		c.Gold += effect.Amount
+		c.mu.Unlock()
+		result.Gold += effect.Amount
+	case InteractionItem:
+		if effect.Item == nil {
+			return fmt.Errorf("feature %s gives no item", f.ID)
+		}
+		if err := c.AddItemToInventory(*effect.Item); err != nil {
+			return fmt.Errorf("failed to take %s: %w", effect.Item.Name, err)
+		}
+		result.Items = append(result.Items, *effect.Item)
+	case InteractionCondition:
+		condition := NewEffect(effect.Condition, Duration{Rounds: effect.Rounds}, effect.Magnitude)
+		condition.Name = f.Name
+		condition.SourceID = f.ID
+		condition.SourceType = "feature"
+		condition.TargetID = c.ID
+		// A stronger effect of the same type already on the character wins
+		if err := c.AddEffect(condition); err == nil {
+			result.Conditions = append(result.Conditions, condition)
+		}
+	case InteractionToggle:
+		f.mu.Lock()
+		f.Active = !f.Active
+		f.mu.Unlock()
+	case InteractionTrigger:
+		result.Triggered = append(result.Triggered, effect.TargetID)
+	default:
+		return fmt.Errorf("feature %s has unknown effect %q", f.ID, effect.Type)
+	}
+	return nil
+}
+
+// interactionCheckBonus returns c's modifier for ability plus its bonus
+// in skill
+func interactionCheckBonus(c *Character, ability, skill string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	score := 10
+	switch ability {
+	case "strength":
+		score = c.Strength
+	case "dexterity":
+		score = c.Dexterity
+	case "constitution":
+		score = c.Constitution
+	case "intelligence":
+		score = c.Intelligence
+	case "wisdom":
+		score = c.Wisdom
+	case "charisma":
+		score = c.Charisma
+	}
+	return (score-10)/2 + c.Skills[skill]
+}
+
+// FromJSON implements GameObject.
+func (f *Feature) FromJSON(data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return json.Unmarshal(data, f)
+}
+
+// ToJSON implements GameObject.
+func (f *Feature) ToJSON() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return json.Marshal(f)
+}
+
+// GetID implements GameObject.
+func (f *Feature) GetID() string {
+	return f.ID
+}
+
+// GetName implements GameObject.
+func (f *Feature) GetName() string {
+	return f.Name
+}
+
+// GetDescription implements GameObject.
+func (f *Feature) GetDescription() string {
+	return f.Description
+}
+
+// GetPosition implements GameObject.
+func (f *Feature) GetPosition() Position {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.Position
+}
+
+// SetPosition implements GameObject.
+func (f *Feature) SetPosition(pos Position) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Position = pos
+	return nil
+}
+
+// GetHealth implements GameObject. Features have no health.
+func (f *Feature) GetHealth() int {
+	return 0
+}
+
+// SetHealth implements GameObject. Features have no health, so this is a
+// no-op.
+func (f *Feature) SetHealth(health int) {}
+
+// IsActive implements GameObject. Features are always active; see IsOn
+// for the state of a toggled feature.
+func (f *Feature) IsActive() bool {
+	return true
+}
+
+// GetTags implements GameObject.
+func (f *Feature) GetTags() []string {
+	return []string{"feature", string(f.Kind)}
+}
+
+// IsObstacle implements GameObject. Features never block movement.
+func (f *Feature) IsObstacle() bool {
+	return false
+}
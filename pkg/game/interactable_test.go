@@ -0,0 +1,140 @@
+package game
+
+import "testing"
+
+func newTestFeature() *Feature {
+	return &Feature{
+		ID:   "feature-1",
+		Name: "Stone Altar",
+		Kind: FeatureAltar,
+		Interactions: []Interaction{
+			{
+				Verb:      "pray",
+				Ability:   "wisdom",
+				DC:        30,
+				OnSuccess: []InteractionEffect{{Type: InteractionExperience, Amount: 50}},
+				OnFailure: []InteractionEffect{{Type: InteractionDamage, Dice: "1d4"}},
+			},
+			{
+				Verb:       "pull",
+				Repeatable: true,
+				OnSuccess: []InteractionEffect{
+					{Type: InteractionToggle},
+					{Type: InteractionTrigger, TargetID: "door-1"},
+				},
+			},
+			{
+				Verb:      "search",
+				OnSuccess: []InteractionEffect{{Type: InteractionGold, Amount: 25}},
+			},
+		},
+	}
+}
+
+func TestFeature_Validate(t *testing.T) {
+	if err := newTestFeature().Validate(); err != nil {
+		t.Fatalf("expected valid feature, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*Feature)
+	}{
+		{"missing ID", func(f *Feature) { f.ID = "" }},
+		{"missing verb", func(f *Feature) { f.Interactions[0].Verb = "" }},
+		{"duplicate verb", func(f *Feature) { f.Interactions[1].Verb = "pray" }},
+		{"unknown ability", func(f *Feature) { f.Interactions[0].Ability = "luck" }},
+		{"negative DC", func(f *Feature) { f.Interactions[2].DC = -1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feature := newTestFeature()
+			tt.mutate(feature)
+			if err := feature.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestFeature_InteractOneTime(t *testing.T) {
+	feature := newTestFeature()
+	cleric := newTestTrapCharacter("cleric", ClassCleric)
+
+	result, err := feature.Interact(cleric, "search", NewDiceRollerWithSeed(1))
+	if err != nil {
+		t.Fatalf("Interact failed: %v", err)
+	}
+	if !result.Success || result.Gold != 25 || cleric.Gold != 25 {
+		t.Errorf("expected 25 gold from the search, got %+v", result)
+	}
+	if _, err := feature.Interact(cleric, "search", NewDiceRollerWithSeed(1)); err == nil {
+		t.Error("a one-time interaction should be spent after it succeeds")
+	}
+	for _, verb := range feature.Verbs() {
+		if verb == "search" {
+			t.Error("a spent verb should no longer be offered")
+		}
+	}
+}
+
+func TestFeature_InteractFailedCheck(t *testing.T) {
+	feature := newTestFeature()
+	cleric := newTestTrapCharacter("cleric", ClassCleric)
+
+	result, err := feature.Interact(cleric, "pray", NewDiceRollerWithSeed(1))
+	if err != nil {
+		t.Fatalf("Interact failed: %v", err)
+	}
+	if result.Success || result.Check == nil || result.Check.DC != 30 {
+		t.Fatalf("expected a failed DC 30 check, got %+v", result)
+	}
+	if result.Damage < 1 || cleric.GetHealth() != 20-result.Damage {
+		t.Errorf("expected the failure to deal damage, got %d", result.Damage)
+	}
+	if result.Experience != 0 {
+		t.Error("a failed check should not apply the success effects")
+	}
+	if _, err := feature.Interact(cleric, "pray", NewDiceRollerWithSeed(1)); err != nil {
+		t.Errorf("a failed one-time interaction can be tried again, got %v", err)
+	}
+}
+
+func TestFeature_InteractRepeatableToggle(t *testing.T) {
+	feature := newTestFeature()
+	fighter := newTestTrapCharacter("fighter", ClassFighter)
+
+	for i, want := range []bool{true, false, true} {
+		result, err := feature.Interact(fighter, "pull", NewDiceRollerWithSeed(1))
+		if err != nil {
+			t.Fatalf("pull %d failed: %v", i+1, err)
+		}
+		if result.Active != want || feature.IsOn() != want {
+			t.Errorf("pull %d: expected active %v, got %v", i+1, want, result.Active)
+		}
+		if len(result.Triggered) != 1 || result.Triggered[0] != "door-1" {
+			t.Errorf("pull %d: expected door-1 triggered, got %v", i+1, result.Triggered)
+		}
+	}
+
+	if _, err := feature.Interact(fighter, "drink", NewDiceRollerWithSeed(1)); err == nil {
+		t.Error("expected an error for a verb the feature does not offer")
+	}
+}
+
+func TestDoor_Trigger(t *testing.T) {
+	door := newTestDoor(DoorLocked)
+
+	state, err := door.Trigger()
+	if err != nil || state != DoorOpen {
+		t.Fatalf("expected a locked door to open, got %s, %v", state, err)
+	}
+	if state, _ := door.Trigger(); state != DoorClosed {
+		t.Errorf("expected an open door to close, got %s", state)
+	}
+
+	if _, err := newTestDoor(DoorSecret).Trigger(); err == nil {
+		t.Error("a secret door should stay hidden")
+	}
+}
@@ -0,0 +1,351 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync/atomic"
+)
+
+// HPMethod is how the hit points of a new level are determined.
+type HPMethod string
+
+// Hit point methods offered when training for a new level.
+const (
+	HPRoll    HPMethod = "roll"    // Roll the class's hit die
+	HPAverage HPMethod = "average" // Take the rounded-up average of the hit die
+)
+
+// classAdvancement is how a class improves with each level under the
+// built-in rules.
+type classAdvancement struct {
+	hitDie        int // Die rolled for hit points
+	hitDiceLevels int // Last level that rolls the hit die
+	fixedHP       int // Hit points per level after hitDiceLevels, without constitution bonus
+	thac0Points   int // THAC0 improvement ...
+	thac0Levels   int // ... every this many levels
+	profLevels    int // A weapon proficiency slot every this many levels
+}
+
+// classAdvancements follow the warrior, priest, rogue and wizard groups of
+// the Gold Box rules. The hit dice match calculateHealthGain.
+var classAdvancements = map[CharacterClass]classAdvancement{
+	ClassFighter: {hitDie: 10, hitDiceLevels: 9, fixedHP: 3, thac0Points: 1, thac0Levels: 1, profLevels: 3},
+	ClassPaladin: {hitDie: 10, hitDiceLevels: 9, fixedHP: 3, thac0Points: 1, thac0Levels: 1, profLevels: 3},
+	ClassRanger:  {hitDie: 8, hitDiceLevels: 9, fixedHP: 3, thac0Points: 1, thac0Levels: 1, profLevels: 3},
+	ClassCleric:  {hitDie: 8, hitDiceLevels: 9, fixedHP: 2, thac0Points: 2, thac0Levels: 3, profLevels: 4},
+	ClassThief:   {hitDie: 6, hitDiceLevels: 10, fixedHP: 2, thac0Points: 1, thac0Levels: 2, profLevels: 4},
+	ClassMage:    {hitDie: 4, hitDiceLevels: 10, fixedHP: 1, thac0Points: 1, thac0Levels: 3, profLevels: 6},
+}
+
+var manualLevelUp atomic.Bool
+
+// ManualLevelUp reports whether players must train for the levels they
+// earn with a LevelUpService.
+func ManualLevelUp() bool {
+	return manualLevelUp.Load()
+}
+
+// SetManualLevelUp selects whether single-classed players advance as soon
+// as they earn the experience for a level (the default) or keep the level
+// pending until they train for it with a LevelUpService. Multi- and
+// dual-classed players always advance as they earn experience.
+func SetManualLevelUp(enabled bool) {
+	manualLevelUp.Store(enabled)
+}
+
+// builtinTHAC0Bonus returns how much the THAC0 of class has improved from
+// level 1 to level under the built-in rules.
+func builtinTHAC0Bonus(class CharacterClass, level int) int {
+	adv, ok := classAdvancements[class]
+	if !ok || level <= 1 {
+		return 0
+	}
+	return (level - 1) / adv.thac0Levels * adv.thac0Points
+}
+
+// advanceTHAC0 returns the THAC0 of a character of class training from
+// level from to level to with THAC0 current: the active ruleset's attack
+// matrix, or the built-in improvement applied to current. Levels gained
+// automatically keep their THAC0 under the built-in rules.
+func advanceTHAC0(class CharacterClass, from, to, current int) int {
+	if rules := ActiveRuleset(); rules != nil {
+		return rules.THAC0(class, to)
+	}
+	return max(current-(builtinTHAC0Bonus(class, to)-builtinTHAC0Bonus(class, from)), 1)
+}
+
+// SpellSource provides the spells characters can learn when they level up.
+// SpellManager implements it.
+type SpellSource interface {
+	GetAllSpells() []*Spell
+}
+
+// LevelUpPreview describes the next level a player can train for and the
+// choices training offers.
+type LevelUpPreview struct {
+	Class            string   `json:"class"`
+	FromLevel        int      `json:"from_level"`
+	ToLevel          int      `json:"to_level"`
+	Pending          int      `json:"pending"`           // Levels earned but not yet trained, this one included
+	HitDie           int      `json:"hit_die"`           // Die rolled for hit points, 0 past the class's hit dice
+	HPAverage        int      `json:"hp_average"`        // Hit points for taking the average
+	HPMin            int      `json:"hp_min"`            // Least hit points a roll gains
+	HPMax            int      `json:"hp_max"`            // Most hit points a roll gains
+	THAC0            int      `json:"thac0"`             // THAC0 at the new level
+	SpellSlots       []int    `json:"spell_slots"`       // Spell slots at the new level
+	NewSpellLevels   []int    `json:"new_spell_levels"`  // Spell levels first available at the new level
+	SpellChoices     int      `json:"spell_choices"`     // Spells that may be learned
+	AvailableSpells  []string `json:"available_spells"`  // IDs of the spells that may be learned
+	ProficiencySlots int      `json:"proficiency_slots"` // Weapon proficiency slots gained
+}
+
+// LevelUpChoices are a player's choices when training for a level.
+type LevelUpChoices struct {
+	HPMethod HPMethod `json:"hp_method"`        // Defaults to HPAverage
+	Spells   []string `json:"spells,omitempty"` // Spells to learn, at most the preview's SpellChoices
+}
+
+// LevelUpResult reports what training for a level changed.
+type LevelUpResult struct {
+	Class            string   `json:"class"`
+	FromLevel        int      `json:"from_level"`
+	ToLevel          int      `json:"to_level"`
+	Pending          int      `json:"pending"` // Levels still waiting to be trained
+	HPMethod         HPMethod `json:"hp_method"`
+	HPGained         int      `json:"hp_gained"`
+	MaxHP            int      `json:"max_hp"`
+	THAC0            int      `json:"thac0"`
+	SpellSlots       []int    `json:"spell_slots"`
+	LearnedSpells    []string `json:"learned_spells"`
+	ProficiencySlots int      `json:"proficiency_slots"` // Unspent weapon proficiency slots after training
+}
+
+// LevelUpService trains players for the levels they have earned. It
+// computes the pending level-ups of a player, previews the choices the next
+// one offers and applies the hit points, THAC0, spell slots, new spells and
+// proficiency slots of a level together, so a failed choice changes nothing.
+type LevelUpService struct {
+	spells SpellSource
+	roller *DiceRoller
+}
+
+// NewLevelUpService creates a level-up service that offers the spells of
+// spells and rolls hit points with roller. Either may be nil: without a
+// spell source no spells are offered, and without a roller a time-seeded
+// one is used.
+func NewLevelUpService(spells SpellSource, roller *DiceRoller) *LevelUpService {
+	if roller == nil {
+		roller = NewDiceRoller()
+	}
+	return &LevelUpService{spells: spells, roller: roller}
+}
+
+// PendingLevelUps returns how many levels the player has earned the
+// experience for but not yet trained for. It is always 0 for multi- and
+// dual-classed players, who advance as they earn experience.
+func (p *Player) PendingLevelUps() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pendingLevelUps()
+}
+
+// pendingLevelUps implements PendingLevelUps (requires the lock)
+func (p *Player) pendingLevelUps() int {
+	if len(p.Classes) > 0 {
+		return 0
+	}
+	return max(classLevelForExperience(p.Class, p.Experience)-p.Level, 0)
+}
+
+// Preview describes the next level the player can train for.
+//
+// Returns:
+//   - *LevelUpPreview: The next level and its choices
+//   - error: If no level-up is pending
+func (ls *LevelUpService) Preview(p *Player) (*LevelUpPreview, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return ls.preview(p)
+}
+
+// preview implements Preview (requires the lock)
+func (ls *LevelUpService) preview(p *Player) (*LevelUpPreview, error) {
+	pending := p.pendingLevelUps()
+	if pending == 0 {
+		return nil, fmt.Errorf("player %s has no level-up pending", p.ID)
+	}
+
+	from, to := p.Level, p.Level+1
+	preview := &LevelUpPreview{
+		Class:      p.Class.String(),
+		FromLevel:  from,
+		ToLevel:    to,
+		Pending:    pending,
+		THAC0:      advanceTHAC0(p.Class, from, to, p.THAC0),
+		SpellSlots: SpellSlotsFor(p.Class, to),
+	}
+
+	adv, ok := classAdvancements[p.Class]
+	switch {
+	case !ok:
+		preview.HPAverage = max(calculateHealthGain(p.Class, p.Constitution), 1)
+		preview.HPMin, preview.HPMax = preview.HPAverage, preview.HPAverage
+	case to <= adv.hitDiceLevels:
+		bonus := constitutionHPBonus(p.Constitution)
+		preview.HitDie = adv.hitDie
+		preview.HPAverage = max(adv.hitDie/2+1+bonus, 1)
+		preview.HPMin, preview.HPMax = max(1+bonus, 1), max(adv.hitDie+bonus, 1)
+	default:
+		preview.HPAverage, preview.HPMin, preview.HPMax = adv.fixedHP, adv.fixedHP, adv.fixedHP
+	}
+	if ok && to%adv.profLevels == 0 {
+		preview.ProficiencySlots = 1
+	}
+
+	oldSlots := SpellSlotsFor(p.Class, from)
+	for i := len(oldSlots); i < len(preview.SpellSlots); i++ {
+		preview.NewSpellLevels = append(preview.NewSpellLevels, i+1)
+	}
+	preview.SpellChoices = max(sumInts(preview.SpellSlots)-sumInts(oldSlots), 0)
+	if preview.SpellChoices > 0 {
+		preview.AvailableSpells = ls.learnableSpells(p, len(preview.SpellSlots))
+		preview.SpellChoices = min(preview.SpellChoices, len(preview.AvailableSpells))
+	}
+	return preview, nil
+}
+
+// learnableSpells returns the IDs of the spells of level 1 to maxLevel the
+// player does not know, in ID order (requires the lock)
+func (ls *LevelUpService) learnableSpells(p *Player, maxLevel int) []string {
+	if ls.spells == nil {
+		return nil
+	}
+	var ids []string
+	for _, spell := range ls.spells.GetAllSpells() {
+		if spell.Level >= 1 && spell.Level <= maxLevel && !p.knowsSpell(spell.ID) {
+			ids = append(ids, spell.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// LevelUp trains the player for the next pending level with choices. The
+// hit points, THAC0, action points, new spells and proficiency slots of
+// the level are applied together once every choice has been checked, and a
+// level up event is emitted.
+//
+// Returns:
+//   - *LevelUpResult: What the level changed
+//   - error: If no level-up is pending or a choice is invalid
+func (ls *LevelUpService) LevelUp(p *Player, choices LevelUpChoices) (*LevelUpResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	preview, err := ls.preview(p)
+	if err != nil {
+		return nil, err
+	}
+
+	method := choices.HPMethod
+	if method == "" {
+		method = HPAverage
+	}
+	if method != HPRoll && method != HPAverage {
+		return nil, fmt.Errorf("unknown hp method %q", method)
+	}
+
+	spells, err := ls.chooseSpells(preview, choices.Spells)
+	if err != nil {
+		return nil, err
+	}
+
+	hp := preview.HPAverage
+	if method == HPRoll && preview.HitDie > 0 {
+		roll, err := ls.roller.Roll(fmt.Sprintf("1d%d", preview.HitDie))
+		if err != nil {
+			return nil, fmt.Errorf("failed to roll hit points: %w", err)
+		}
+		hp = max(roll.Final+constitutionHPBonus(p.Constitution), 1)
+	}
+
+	p.Level = preview.ToLevel
+	p.MaxHP += hp
+	p.HP += hp
+	p.THAC0 = preview.THAC0
+	p.ProficiencySlots += preview.ProficiencySlots
+	p.KnownSpells = append(p.KnownSpells, spells...)
+	maxActionPoints := calculateMaxActionPoints(p.Level, p.Dexterity)
+	p.MaxActionPoints = maxActionPoints
+	p.ActionPoints = maxActionPoints
+
+	emitLevelUpEvent(p.ID, preview.FromLevel, preview.ToLevel)
+
+	learned := make([]string, len(spells))
+	for i, spell := range spells {
+		learned[i] = spell.ID
+	}
+	return &LevelUpResult{
+		Class:            preview.Class,
+		FromLevel:        preview.FromLevel,
+		ToLevel:          preview.ToLevel,
+		Pending:          preview.Pending - 1,
+		HPMethod:         method,
+		HPGained:         hp,
+		MaxHP:            p.MaxHP,
+		THAC0:            p.THAC0,
+		SpellSlots:       preview.SpellSlots,
+		LearnedSpells:    learned,
+		ProficiencySlots: p.ProficiencySlots,
+	}, nil
+}
+
+// chooseSpells resolves the chosen spell IDs against the spells preview
+// offers
+func (ls *LevelUpService) chooseSpells(preview *LevelUpPreview, ids []string) ([]Spell, error) {
+	if len(ids) > preview.SpellChoices {
+		return nil, fmt.Errorf("level %d allows %d new spells, %d chosen", preview.ToLevel, preview.SpellChoices, len(ids))
+	}
+	spells := make([]Spell, 0, len(ids))
+	for i, id := range ids {
+		if !slices.Contains(preview.AvailableSpells, id) {
+			return nil, fmt.Errorf("spell %s cannot be learned at level %d", id, preview.ToLevel)
+		}
+		if slices.Contains(ids[:i], id) {
+			return nil, fmt.Errorf("spell %s chosen more than once", id)
+		}
+		for _, spell := range ls.spells.GetAllSpells() {
+			if spell.ID == id {
+				spells = append(spells, *spell)
+				break
+			}
+		}
+	}
+	return spells, nil
+}
+
+// knowsSpell implements KnowsSpell (requires the lock)
+func (p *Player) knowsSpell(spellID string) bool {
+	for _, spell := range p.KnownSpells {
+		if spell.ID == spellID {
+			return true
+		}
+	}
+	return false
+}
+
+// constitutionHPBonus returns the hit points a constitution score adds to
+// each hit die, as in calculateHealthGain.
+func constitutionHPBonus(constitution int) int {
+	return (constitution - 10) / 2
+}
+
+// sumInts returns the sum of values.
+func sumInts(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spellList is a SpellSource over a fixed list of spells
+type spellList []*Spell
+
+func (l spellList) GetAllSpells() []*Spell { return l }
+
+// useManualLevelUp makes earned levels wait for training during the test
+func useManualLevelUp(t *testing.T) {
+	t.Helper()
+	SetManualLevelUp(true)
+	t.Cleanup(func() { SetManualLevelUp(false) })
+}
+
+func newLevelingTestService() *LevelUpService {
+	spells := spellList{
+		{ID: "magic_missile", Level: 1},
+		{ID: "sleep", Level: 1},
+		{ID: "web", Level: 2},
+		{ID: "fireball", Level: 3},
+	}
+	return NewLevelUpService(spells, NewDiceRollerWithSeed(7))
+}
+
+func TestManualLevelUp_KeepsLevelsPending(t *testing.T) {
+	useManualLevelUp(t)
+
+	player := &Player{Character: Character{ID: "p1", Class: ClassFighter, MaxHP: 10, HP: 10, THAC0: 20}, Level: 1}
+	require.NoError(t, player.AddExperience(4000))
+	assert.Equal(t, 1, player.Level)
+	assert.Equal(t, 2, player.PendingLevelUps())
+
+	SetManualLevelUp(false)
+	require.NoError(t, player.AddExperience(0))
+	assert.Equal(t, 3, player.Level, "automatic level-ups catch up with the experience")
+	assert.Zero(t, player.PendingLevelUps())
+}
+
+func TestLevelUpService_PreviewAndLevelUp(t *testing.T) {
+	useManualLevelUp(t)
+	service := newLevelingTestService()
+
+	player := &Player{Character: Character{ID: "p1", Class: ClassFighter, Constitution: 14, Dexterity: 12, MaxHP: 10, HP: 10, THAC0: 20}, Level: 1}
+	_, err := service.Preview(player)
+	assert.ErrorContains(t, err, "no level-up pending")
+
+	require.NoError(t, player.AddExperience(4000))
+	preview, err := service.Preview(player)
+	require.NoError(t, err)
+	assert.Equal(t, 2, preview.ToLevel)
+	assert.Equal(t, 2, preview.Pending)
+	assert.Equal(t, 10, preview.HitDie)
+	assert.Equal(t, 8, preview.HPAverage, "half the die plus one, plus the constitution bonus")
+	assert.Equal(t, 3, preview.HPMin)
+	assert.Equal(t, 12, preview.HPMax)
+	assert.Equal(t, 19, preview.THAC0)
+	assert.Zero(t, preview.SpellChoices)
+
+	result, err := service.LevelUp(player, LevelUpChoices{HPMethod: HPAverage})
+	require.NoError(t, err)
+	assert.Equal(t, 2, player.Level)
+	assert.Equal(t, 18, player.MaxHP)
+	assert.Equal(t, 19, player.THAC0)
+	assert.Equal(t, 1, result.Pending)
+
+	result, err = service.LevelUp(player, LevelUpChoices{HPMethod: HPRoll})
+	require.NoError(t, err)
+	assert.Equal(t, 3, player.Level)
+	assert.GreaterOrEqual(t, result.HPGained, 3)
+	assert.LessOrEqual(t, result.HPGained, 12)
+	assert.Equal(t, 1, player.ProficiencySlots, "fighters gain a proficiency slot every third level")
+	assert.Zero(t, result.Pending)
+}
+
+func TestLevelUpService_LearnsSpells(t *testing.T) {
+	useManualLevelUp(t)
+	service := newLevelingTestService()
+
+	player := &Player{Character: Character{ID: "m1", Class: ClassMage, Constitution: 10, MaxHP: 4, HP: 4, THAC0: 20}, Level: 2}
+	player.KnownSpells = []Spell{{ID: "magic_missile", Level: 1}}
+	require.NoError(t, player.AddExperience(4000))
+
+	preview, err := service.Preview(player)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, preview.SpellSlots)
+	assert.Equal(t, []int{2}, preview.NewSpellLevels)
+	assert.Equal(t, 1, preview.SpellChoices)
+	assert.Equal(t, []string{"sleep", "web"}, preview.AvailableSpells)
+
+	_, err = service.LevelUp(player, LevelUpChoices{Spells: []string{"fireball"}})
+	assert.ErrorContains(t, err, "cannot be learned")
+	_, err = service.LevelUp(player, LevelUpChoices{Spells: []string{"sleep", "web"}})
+	assert.ErrorContains(t, err, "allows 1 new spells")
+	_, err = service.LevelUp(player, LevelUpChoices{HPMethod: "max"})
+	assert.ErrorContains(t, err, "unknown hp method")
+	assert.Equal(t, 2, player.Level, "a rejected choice changes nothing")
+	assert.Equal(t, 4, player.MaxHP)
+
+	result, err := service.LevelUp(player, LevelUpChoices{Spells: []string{"web"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, result.LearnedSpells)
+	assert.True(t, player.KnowsSpell("web"))
+	assert.Equal(t, 3, result.HPGained, "mages take the d4 average of 3")
+}
+
+func TestLevelUpService_FixedHitPointsPastHitDice(t *testing.T) {
+	useRuleset(t, "adnd2e")
+	useManualLevelUp(t)
+	service := newLevelingTestService()
+
+	player := &Player{Character: Character{ID: "p1", Class: ClassFighter, Constitution: 18, MaxHP: 90, HP: 90, THAC0: 12}, Level: 9}
+	player.Experience = classExperienceForLevel(ClassFighter, 10)
+	require.Equal(t, 1, player.PendingLevelUps())
+
+	preview, err := service.Preview(player)
+	require.NoError(t, err)
+	assert.Zero(t, preview.HitDie)
+	assert.Equal(t, 3, preview.HPAverage, "no constitution bonus past the hit dice")
+	assert.Equal(t, ActiveRuleset().THAC0(ClassFighter, 10), preview.THAC0)
+}
+
+func TestLevelUpService_IgnoresMultiClassedPlayers(t *testing.T) {
+	useManualLevelUp(t)
+
+	player := &Player{Character: Character{ID: "p1", Class: ClassFighter}, Level: 1}
+	player.Classes = []ClassLevel{{Class: ClassFighter, Level: 1}, {Class: ClassMage, Level: 1}}
+	require.NoError(t, player.AddExperience(8000))
+	assert.Zero(t, player.PendingLevelUps())
+	assert.Equal(t, 3, player.Level, "multi-classed players advance as they earn experience")
+}
@@ -0,0 +1,230 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LightLevel is how brightly a tile is lit. The zero value is bright, so
+// maps made before lighting existed stay fully visible.
+type LightLevel int
+
+const (
+	LightBright LightLevel = iota // Daylight or a lit room
+	LightDim                      // Twilight, visible at short range
+	LightDark                     // Visible only by a light source or infravision
+)
+
+// DimSightRange is the distance, in tiles, a character can see into dim
+// light without a light source.
+const DimSightRange = 5.0
+
+// lightLevelNames holds the text form of each light level
+var lightLevelNames = [...]string{"bright", "dim", "dark"}
+
+// String returns the name of the light level.
+func (l LightLevel) String() string {
+	if l < 0 || int(l) >= len(lightLevelNames) {
+		return fmt.Sprintf("LightLevel(%d)", int(l))
+	}
+	return lightLevelNames[l]
+}
+
+// MarshalText implements encoding.TextMarshaler, so light levels read as
+// names in YAML and JSON.
+func (l LightLevel) MarshalText() ([]byte, error) {
+	if l < 0 || int(l) >= len(lightLevelNames) {
+		return nil, fmt.Errorf("invalid light level %d", int(l))
+	}
+	return []byte(lightLevelNames[l]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *LightLevel) UnmarshalText(text []byte) error {
+	for level, name := range lightLevelNames {
+		if string(text) == name {
+			*l = LightLevel(level)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown light level %q", text)
+}
+
+// LightSource describes how an item lights its surroundings once lit.
+type LightSource struct {
+	Radius    int   `yaml:"radius" json:"radius"`         // Tiles lit around the holder
+	BurnTicks int64 `yaml:"burn_ticks" json:"burn_ticks"` // Game ticks (seconds) it burns
+	Consumed  bool  `yaml:"consumed" json:"consumed"`     // Lighting it uses the item up
+}
+
+// lightSourceKinds are the light sources recognised by item name, checked
+// in order.
+var lightSourceKinds = []struct {
+	kind   string
+	source LightSource
+}{
+	{"lantern", LightSource{Radius: 6, BurnTicks: 4 * 3600}},
+	{"torch", LightSource{Radius: 4, BurnTicks: 3600, Consumed: true}},
+}
+
+// LightSourceOf reports whether item is a light source and how it burns.
+// Torches and lanterns are recognised by name; a "light_radius:N" property
+// makes any item a light source or overrides its radius, and a
+// "burn_ticks:N" property overrides how long it burns.
+func LightSourceOf(item *Item) (LightSource, bool) {
+	var source LightSource
+	found := false
+	name := strings.ToLower(item.Name + " " + item.ID)
+	for _, k := range lightSourceKinds {
+		if strings.Contains(name, k.kind) {
+			source, found = k.source, true
+			break
+		}
+	}
+
+	for _, property := range item.Properties {
+		if value, ok := strings.CutPrefix(property, "light_radius:"); ok {
+			if radius, err := strconv.Atoi(value); err == nil && radius > 0 {
+				source.Radius, found = radius, true
+			}
+		}
+		if value, ok := strings.CutPrefix(property, "burn_ticks:"); ok {
+			if ticks, err := strconv.ParseInt(value, 10, 64); err == nil && ticks > 0 {
+				source.BurnTicks = ticks
+			}
+		}
+	}
+	if found && source.BurnTicks == 0 {
+		source.BurnTicks = 3600
+	}
+	return source, found
+}
+
+// raceInfravision is the infravision range, in tiles, of each race with
+// one. A character's race is given by a "race:<name>" tag.
+var raceInfravision = map[string]int{
+	"dwarf":    6,
+	"elf":      6,
+	"gnome":    6,
+	"half-elf": 6,
+	"half-orc": 6,
+	"halfling": 3,
+}
+
+// classInfravision is the infravision range, in tiles, granted by class
+// training.
+var classInfravision = map[CharacterClass]int{
+	ClassRanger: 3,
+}
+
+// Infravision returns how far, in tiles, c sees in darkness without light:
+// the best of its race's and class's infravision and any "infravision:N"
+// tag.
+func Infravision(c *Character) int {
+	best := classInfravision[c.Class]
+	for _, tag := range c.GetTags() {
+		if race, ok := strings.CutPrefix(tag, "race:"); ok {
+			best = max(best, raceInfravision[strings.ToLower(race)])
+		}
+		if value, ok := strings.CutPrefix(tag, "infravision:"); ok {
+			if tiles, err := strconv.Atoi(value); err == nil {
+				best = max(best, tiles)
+			}
+		}
+	}
+	return best
+}
+
+// SetCarriedLights replaces the light sources carried in the world: the
+// light radius, in tiles, around each holder by object ID.
+func (w *World) SetCarriedLights(lights map[string]int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lights = make(map[string]int, len(lights))
+	for holderID, radius := range lights {
+		if radius > 0 {
+			w.lights[holderID] = radius
+		}
+	}
+}
+
+// CarriedLights returns a copy of the light radius around each holder.
+func (w *World) CarriedLights() map[string]int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	lights := make(map[string]int, len(w.lights))
+	for holderID, radius := range w.lights {
+		lights[holderID] = radius
+	}
+	return lights
+}
+
+// LightAt returns how brightly pos is lit: the light level of its tile,
+// raised to bright within the radius of a carried light source that has a
+// line of sight to it. Positions outside the level's tile map are bright.
+func (w *World) LightAt(pos Position) LightLevel {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.lightAt(pos)
+}
+
+// lightAt implements LightAt. The caller must hold w.mu.
+func (w *World) lightAt(pos Position) LightLevel {
+	light := LightBright
+	if pos.Level >= 0 && pos.Level < len(w.Levels) {
+		tiles := w.Levels[pos.Level].Tiles
+		if pos.Y >= 0 && pos.Y < len(tiles) && pos.X >= 0 && pos.X < len(tiles[pos.Y]) {
+			light = tiles[pos.Y][pos.X].Light
+		}
+	}
+	if light == LightBright {
+		return light
+	}
+
+	for holderID, radius := range w.lights {
+		holder, exists := w.Objects[holderID]
+		if !exists {
+			continue
+		}
+		at := holder.GetPosition()
+		dx, dy := float64(at.X-pos.X), float64(at.Y-pos.Y)
+		if at.Level == pos.Level && math.Sqrt(dx*dx+dy*dy) <= float64(radius) && w.lineOfSight(at, pos) {
+			return LightBright
+		}
+	}
+	return light
+}
+
+// CanSee reports whether viewer can see pos within sightRange tiles
+// (DefaultSightRange if <= 0). The position must be in line of sight and
+// lit well enough: bright positions are seen at any range, dim ones within
+// DimSightRange and dark ones only within the viewer's infravision.
+func (w *World) CanSee(viewer *Character, pos Position, sightRange float64) bool {
+	if sightRange <= 0 {
+		sightRange = DefaultSightRange
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.canSee(viewer, pos, sightRange)
+}
+
+// canSee implements CanSee. The caller must hold w.mu.
+func (w *World) canSee(viewer *Character, pos Position, sightRange float64) bool {
+	from := viewer.Position
+	dx, dy := float64(from.X-pos.X), float64(from.Y-pos.Y)
+	distance := math.Sqrt(dx*dx + dy*dy)
+	if distance > sightRange || !w.lineOfSight(from, pos) {
+		return false
+	}
+
+	switch w.lightAt(pos) {
+	case LightBright:
+		return true
+	case LightDim:
+		return distance <= math.Max(DimSightRange, float64(Infravision(viewer)))
+	default:
+		return distance <= float64(Infravision(viewer))
+	}
+}
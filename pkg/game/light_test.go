@@ -0,0 +1,123 @@
+package game
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// darkenLevel sets every tile of the world's first level to light
+func darkenLevel(world *World, light LightLevel) {
+	for y := range world.Levels[0].Tiles {
+		for x := range world.Levels[0].Tiles[y] {
+			world.Levels[0].Tiles[y][x].Light = light
+		}
+	}
+}
+
+func TestLightLevel_Text(t *testing.T) {
+	data, err := yaml.Marshal(Tile{Type: TileFloor, Light: LightDark})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var tile Tile
+	if err := yaml.Unmarshal(data, &tile); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if tile.Light != LightDark {
+		t.Errorf("Light = %v after a round trip, want dark", tile.Light)
+	}
+
+	var level LightLevel
+	if err := level.UnmarshalText([]byte("gloomy")); err == nil {
+		t.Error("UnmarshalText accepted an unknown light level")
+	}
+}
+
+func TestLightSourceOf(t *testing.T) {
+	tests := []struct {
+		name string
+		item Item
+		want LightSource
+		ok   bool
+	}{
+		{"torch", Item{ID: "torch", Name: "Torch"}, LightSource{Radius: 4, BurnTicks: 3600, Consumed: true}, true},
+		{"lantern", Item{ID: "lantern_1", Name: "Hooded Lantern"}, LightSource{Radius: 6, BurnTicks: 4 * 3600}, true},
+		{"property", Item{ID: "glowstone", Name: "Glowstone", Properties: []string{"light_radius:2", "burn_ticks:600"}}, LightSource{Radius: 2, BurnTicks: 600}, true},
+		{"sword", Item{ID: "sword", Name: "Long Sword"}, LightSource{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LightSourceOf(&tt.item)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("LightSourceOf(%s) = %+v, %v, want %+v, %v", tt.item.Name, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestInfravision(t *testing.T) {
+	fighter := &Character{Class: ClassFighter}
+	if got := Infravision(fighter); got != 0 {
+		t.Errorf("Infravision(human fighter) = %d, want 0", got)
+	}
+	fighter.AddTag("race:dwarf")
+	if got := Infravision(fighter); got != 6 {
+		t.Errorf("Infravision(dwarf fighter) = %d, want 6", got)
+	}
+	if got := Infravision(&Character{Class: ClassRanger}); got != 3 {
+		t.Errorf("Infravision(ranger) = %d, want 3", got)
+	}
+}
+
+func TestWorld_CanSeeInDarkness(t *testing.T) {
+	world := CreateDefaultWorld()
+	viewer := &Character{ID: "hero", Position: Position{X: 2, Y: 2}}
+	near, far := Position{X: 5, Y: 2}, Position{X: 9, Y: 2}
+
+	if !world.CanSee(viewer, far, 0) {
+		t.Fatal("bright tiles are seen at any range")
+	}
+
+	darkenLevel(world, LightDim)
+	if !world.CanSee(viewer, near, 0) || world.CanSee(viewer, far, 0) {
+		t.Error("dim tiles are seen within DimSightRange only")
+	}
+
+	darkenLevel(world, LightDark)
+	if world.CanSee(viewer, near, 0) {
+		t.Error("dark tiles are not seen without light or infravision")
+	}
+	viewer.AddTag("race:elf")
+	if !world.CanSee(viewer, near, 0) || world.CanSee(viewer, far, 0) {
+		t.Error("infravision sees dark tiles within its range")
+	}
+
+	holder := newThreatTestNPC("torchbearer", "friendly", 8, 2, 1)
+	world.Objects[holder.ID] = holder
+	world.SetCarriedLights(map[string]int{holder.ID: 2})
+	if world.LightAt(far) != LightBright || !world.CanSee(viewer, far, 0) {
+		t.Error("a carried light lights the tiles around its holder")
+	}
+	if got := world.Clone().CarriedLights()[holder.ID]; got != 2 {
+		t.Errorf("cloned light radius = %d, want 2", got)
+	}
+}
+
+func TestWorld_VisibleHostilesInDarkness(t *testing.T) {
+	world := CreateDefaultWorld()
+	darkenLevel(world, LightDark)
+	viewer := &Character{ID: "hero", Position: Position{X: 2, Y: 2}, Level: 1}
+	orc := newThreatTestNPC("orc", "aggressive", 6, 2, 1)
+	world.Objects[orc.ID] = orc
+
+	if threats := world.VisibleHostiles(viewer, 0, nil); len(threats) != 0 {
+		t.Errorf("VisibleHostiles found %d threats in the dark, want 0", len(threats))
+	}
+	world.Objects[viewer.ID] = &NPC{Character: *viewer.Clone()}
+	world.SetCarriedLights(map[string]int{viewer.ID: 4})
+	if threats := world.VisibleHostiles(viewer, 0, nil); len(threats) != 1 {
+		t.Errorf("VisibleHostiles found %d threats by torchlight, want 1", len(threats))
+	}
+}
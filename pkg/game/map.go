@@ -8,6 +8,8 @@ type MapTile struct {
 	SpriteY     int  `json:"spriteY"`
 	Walkable    bool `json:"walkable"`
 	Transparent bool `json:"transparent"`
+
+	Light LightLevel `json:"light,omitempty"` // How brightly the tile is lit
 }
 
 // GameMap represents a game map containing a grid of tiles
@@ -0,0 +1,209 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxRemovedObjectsPerLevel bounds how many object removals a level
+// remembers. Clients whose revision predates the oldest forgotten removal
+// get a full resync instead of a delta.
+const MaxRemovedObjectsPerLevel = 1024
+
+// tileKey identifies a tile within a level
+type tileKey struct {
+	X, Y int
+}
+
+// levelChanges records, for one level, the revision at which each tile and
+// object last changed. Every mutation bumps the level's revision.
+type levelChanges struct {
+	revision     uint64             // Latest revision of the level
+	tiles        map[tileKey]uint64 // Revision of each tile's last change
+	objects      map[string]uint64  // Revision of each object's last change
+	removed      map[string]uint64  // Revision at which each object left the level
+	removedFloor uint64             // Newest revision of a forgotten removal
+}
+
+// TileChange is a tile that changed since the requested revision
+type TileChange struct {
+	X    int  `json:"x"`
+	Y    int  `json:"y"`
+	Tile Tile `json:"tile"`
+}
+
+// MapDelta lists what changed on a level since a client's revision.
+//
+// With FullResync set the client's revision was unknown or too old to
+// compute a delta from, and Tiles and Objects hold the complete level
+// instead. Clients should replace their copy of the level and continue
+// from Revision.
+type MapDelta struct {
+	Level      int          `json:"level"`          // Level index
+	Revision   uint64       `json:"revision"`       // Current revision of the level
+	Since      uint64       `json:"since_revision"` // Revision the delta starts from
+	FullResync bool         `json:"full_resync"`    // Whether Tiles and Objects are the whole level
+	Tiles      []TileChange `json:"tiles"`          // Changed tiles, by row then column
+	Objects    []GameObject `json:"objects"`        // Objects added to or changed on the level, by ID
+	Removed    []string     `json:"removed"`        // IDs of objects that left the level, sorted
+}
+
+// levelChangesFor returns the change record of level, creating it if
+// needed (requires the write lock)
+func (w *World) levelChangesFor(level int) *levelChanges {
+	if w.changes == nil {
+		w.changes = make(map[int]*levelChanges)
+	}
+	changes, exists := w.changes[level]
+	if !exists {
+		changes = &levelChanges{
+			revision: 1,
+			tiles:    make(map[tileKey]uint64),
+			objects:  make(map[string]uint64),
+			removed:  make(map[string]uint64),
+		}
+		w.changes[level] = changes
+	}
+	return changes
+}
+
+// recordObjectChange marks an object as added or changed on level
+// (requires the write lock)
+func (w *World) recordObjectChange(objectID string, level int) {
+	changes := w.levelChangesFor(level)
+	changes.revision++
+	changes.objects[objectID] = changes.revision
+	delete(changes.removed, objectID)
+}
+
+// recordObjectRemoval marks an object as gone from level, forgetting the
+// oldest removal once MaxRemovedObjectsPerLevel are remembered (requires the
+// write lock)
+func (w *World) recordObjectRemoval(objectID string, level int) {
+	changes := w.levelChangesFor(level)
+	changes.revision++
+	delete(changes.objects, objectID)
+	changes.removed[objectID] = changes.revision
+
+	if len(changes.removed) <= MaxRemovedObjectsPerLevel {
+		return
+	}
+	oldestID, oldest := "", changes.revision
+	for id, revision := range changes.removed {
+		if revision < oldest {
+			oldestID, oldest = id, revision
+		}
+	}
+	delete(changes.removed, oldestID)
+	changes.removedFloor = oldest
+}
+
+// recordObjectMove marks a moved object as changed, and as removed from its
+// old level when it changed levels (requires the write lock)
+func (w *World) recordObjectMove(objectID string, oldPos, newPos Position) {
+	if oldPos.Level != newPos.Level {
+		w.recordObjectRemoval(objectID, oldPos.Level)
+	}
+	w.recordObjectChange(objectID, newPos.Level)
+}
+
+// SetTile replaces the tile at x, y of a level, such as when a door opens or
+// a trap is disarmed, and bumps the level's revision
+func (w *World) SetTile(level, x, y int, tile Tile) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if level < 0 || level >= len(w.Levels) {
+		return fmt.Errorf("level %d not found", level)
+	}
+	tiles := w.Levels[level].Tiles
+	if y < 0 || y >= len(tiles) || x < 0 || x >= len(tiles[y]) {
+		return fmt.Errorf("tile %d,%d is outside level %d", x, y, level)
+	}
+
+	tiles[y][x] = tile
+	changes := w.levelChangesFor(level)
+	changes.revision++
+	changes.tiles[tileKey{X: x, Y: y}] = changes.revision
+	return nil
+}
+
+// MapRevision returns the current revision of a level. Levels start at
+// revision 1, so a client asking for changes since revision 0 always gets
+// the whole level.
+func (w *World) MapRevision(level int) uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if changes, exists := w.changes[level]; exists {
+		return changes.revision
+	}
+	return 1
+}
+
+// MapDelta returns the tiles and objects of a level that changed after
+// revision since. A since of 0, a revision newer than the level's (such as
+// one from before a server restart) or one older than the oldest remembered
+// removal yields a full resync.
+func (w *World) MapDelta(level int, since uint64) (*MapDelta, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if level < 0 || level >= len(w.Levels) {
+		return nil, fmt.Errorf("level %d not found", level)
+	}
+
+	changes := w.changes[level]
+	delta := &MapDelta{
+		Level:    level,
+		Since:    since,
+		Tiles:    []TileChange{},
+		Objects:  []GameObject{},
+		Removed:  []string{},
+		Revision: 1,
+	}
+	if changes != nil {
+		delta.Revision = changes.revision
+	}
+
+	if since == 0 || since > delta.Revision || (changes != nil && since < changes.removedFloor) {
+		delta.FullResync = true
+		for y, row := range w.Levels[level].Tiles {
+			for x, tile := range row {
+				delta.Tiles = append(delta.Tiles, TileChange{X: x, Y: y, Tile: tile})
+			}
+		}
+		for _, obj := range w.Objects {
+			if obj.GetPosition().Level == level {
+				delta.Objects = append(delta.Objects, obj)
+			}
+		}
+	} else if changes != nil {
+		tiles := w.Levels[level].Tiles
+		for key, revision := range changes.tiles {
+			if revision > since && key.Y < len(tiles) && key.X < len(tiles[key.Y]) {
+				delta.Tiles = append(delta.Tiles, TileChange{X: key.X, Y: key.Y, Tile: tiles[key.Y][key.X]})
+			}
+		}
+		for id, revision := range changes.objects {
+			if obj, exists := w.Objects[id]; exists && revision > since {
+				delta.Objects = append(delta.Objects, obj)
+			}
+		}
+		for id, revision := range changes.removed {
+			if revision > since {
+				delta.Removed = append(delta.Removed, id)
+			}
+		}
+	}
+
+	sort.Slice(delta.Tiles, func(i, j int) bool {
+		a, b := delta.Tiles[i], delta.Tiles[j]
+		return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+	})
+	sort.Slice(delta.Objects, func(i, j int) bool {
+		return delta.Objects[i].GetID() < delta.Objects[j].GetID()
+	})
+	sort.Strings(delta.Removed)
+	return delta, nil
+}
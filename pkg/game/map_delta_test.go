@@ -0,0 +1,139 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+)
+
+// deltaIDs returns the IDs of the objects in a map delta
+func deltaIDs(delta *MapDelta) []string {
+	ids := make([]string, 0, len(delta.Objects))
+	for _, obj := range delta.Objects {
+		ids = append(ids, obj.GetID())
+	}
+	return ids
+}
+
+func TestWorld_MapDelta_TracksChanges(t *testing.T) {
+	world := CreateDefaultWorld()
+	world.Width, world.Height = world.Levels[0].Width, world.Levels[0].Height
+	for _, id := range []string{"a", "b"} {
+		if err := world.AddObject(&Character{ID: id, Position: Position{X: 2, Y: 2}}); err != nil {
+			t.Fatalf("AddObject(%s) failed: %v", id, err)
+		}
+	}
+
+	full, err := world.MapDelta(0, 0)
+	if err != nil {
+		t.Fatalf("MapDelta failed: %v", err)
+	}
+	if !full.FullResync || len(full.Tiles) != world.Levels[0].Width*world.Levels[0].Height {
+		t.Fatalf("revision 0 should return the whole level, got full_resync=%v with %d tiles", full.FullResync, len(full.Tiles))
+	}
+	if full.Revision != 3 || world.MapRevision(0) != 3 {
+		t.Fatalf("Revision = %d, want 3", full.Revision)
+	}
+
+	if err := world.UpdateObjectPosition("a", Position{X: 3, Y: 2}); err != nil {
+		t.Fatalf("UpdateObjectPosition failed: %v", err)
+	}
+	if err := world.SetTile(0, 4, 4, Tile{Type: TileWall, BlocksSight: true}); err != nil {
+		t.Fatalf("SetTile failed: %v", err)
+	}
+	if err := world.RemoveObject("b"); err != nil {
+		t.Fatalf("RemoveObject failed: %v", err)
+	}
+
+	delta, err := world.MapDelta(0, full.Revision)
+	if err != nil {
+		t.Fatalf("MapDelta failed: %v", err)
+	}
+	if delta.FullResync || delta.Revision != 6 {
+		t.Fatalf("got full_resync=%v revision=%d, want a delta at revision 6", delta.FullResync, delta.Revision)
+	}
+	if len(delta.Tiles) != 1 || delta.Tiles[0].X != 4 || delta.Tiles[0].Y != 4 || delta.Tiles[0].Tile.Type != TileWall {
+		t.Errorf("Tiles = %+v, want the wall at 4,4", delta.Tiles)
+	}
+	if ids := deltaIDs(delta); len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("Objects = %v, want [a]", ids)
+	}
+	if len(delta.Removed) != 1 || delta.Removed[0] != "b" {
+		t.Errorf("Removed = %v, want [b]", delta.Removed)
+	}
+
+	current, err := world.MapDelta(0, delta.Revision)
+	if err != nil {
+		t.Fatalf("MapDelta failed: %v", err)
+	}
+	if current.FullResync || len(current.Tiles)+len(current.Objects)+len(current.Removed) != 0 {
+		t.Errorf("an up to date client should get an empty delta, got %+v", current)
+	}
+}
+
+func TestWorld_MapDelta_FullResync(t *testing.T) {
+	world := CreateDefaultWorld()
+	if err := world.AddObject(&Character{ID: "a", Position: Position{X: 2, Y: 2}}); err != nil {
+		t.Fatalf("AddObject failed: %v", err)
+	}
+
+	delta, err := world.MapDelta(0, 99)
+	if err != nil {
+		t.Fatalf("MapDelta failed: %v", err)
+	}
+	if !delta.FullResync || len(delta.Objects) != 1 {
+		t.Errorf("a revision from the future should resync the level, got %+v", delta)
+	}
+
+	for i := 0; i <= MaxRemovedObjectsPerLevel; i++ {
+		obj := &Character{ID: fmt.Sprintf("gone_%d", i), Position: Position{X: 1, Y: 1}}
+		if err := world.AddObject(obj); err != nil {
+			t.Fatalf("AddObject failed: %v", err)
+		}
+		if err := world.RemoveObject(obj.ID); err != nil {
+			t.Fatalf("RemoveObject failed: %v", err)
+		}
+	}
+	delta, err = world.MapDelta(0, 2)
+	if err != nil {
+		t.Fatalf("MapDelta failed: %v", err)
+	}
+	if !delta.FullResync {
+		t.Error("a revision older than the forgotten removals should resync the level")
+	}
+
+	if _, err := world.MapDelta(5, 1); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if err := world.SetTile(0, -1, 0, Tile{}); err == nil {
+		t.Error("expected an error for a tile outside the level")
+	}
+}
+
+func TestWorld_MapDelta_LevelChange(t *testing.T) {
+	world := CreateDefaultWorld()
+	world.Levels = append(world.Levels, world.Levels[0])
+	if err := world.AddObject(&Character{ID: "a", Position: Position{X: 2, Y: 2}}); err != nil {
+		t.Fatalf("AddObject failed: %v", err)
+	}
+
+	obj := world.Objects["a"]
+	oldPos := obj.GetPosition()
+	newPos := Position{X: 2, Y: 2, Level: 1}
+	if err := obj.SetPosition(newPos); err != nil {
+		t.Fatalf("SetPosition failed: %v", err)
+	}
+	world.HandleMovement(GameEvent{
+		Type:     EventMovement,
+		SourceID: "a",
+		Data:     map[string]interface{}{"old_position": oldPos, "new_position": newPos},
+	})
+
+	upper, _ := world.MapDelta(0, 2)
+	if len(upper.Removed) != 1 || upper.Removed[0] != "a" || len(upper.Objects) != 0 {
+		t.Errorf("level 0 delta = %+v, want a removed", upper)
+	}
+	lower, _ := world.MapDelta(1, 0)
+	if ids := deltaIDs(lower); len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("level 1 objects = %v, want [a]", ids)
+	}
+}
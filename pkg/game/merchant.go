@@ -0,0 +1,267 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Merchant pricing defaults. Players buy at BuyMarkup times an item's value
+// and sell at SellRatio times its value, before the price adjustment.
+const (
+	DefaultMerchantBuyMarkup = 1.0
+	DefaultMerchantSellRatio = 0.5
+)
+
+// Merchant price adjustment factors. Each point of Charisma above or below
+// 10 and each point of standing with the merchant's faction moves prices in
+// the player's favour or against it, up to MaxMerchantPriceAdjustment.
+const (
+	MerchantCharismaStep       = 0.02
+	MerchantReputationStep     = 0.002
+	MaxMerchantPriceAdjustment = 0.3
+)
+
+// Merchant is an NPC that trades items for gold. Its stock is the
+// embedded character's inventory and its gold pool the character's gold,
+// so both are guarded by the character's lock.
+//
+// Prices depend on the trading player's Charisma and on their reputation
+// with the merchant's faction, see PriceAdjustment. A player can never sell
+// an item back for as much as they would pay for it.
+//
+// Trades lock the merchant before the player and move gold and the item
+// together, so a failed trade changes neither party.
+type Merchant struct {
+	NPC       `yaml:",inline"`
+	BuyMarkup float64 `yaml:"merchant_buy_markup"` // Multiplier on item value for players buying
+	SellRatio float64 `yaml:"merchant_sell_ratio"` // Multiplier on item value for players selling
+
+	// MarketFactor scales buying and selling prices alike, for example
+	// while a world event disrupts trade. Zero counts as 1.
+	MarketFactor float64 `yaml:"merchant_market_factor,omitempty"`
+}
+
+// Trade describes a completed purchase or sale.
+type Trade struct {
+	MerchantID   string `json:"merchant_id"`
+	PlayerID     string `json:"player_id"`
+	Item         Item   `json:"item"`
+	Price        int    `json:"price"`
+	PlayerGold   int    `json:"player_gold"`   // Player's gold after the trade
+	MerchantGold int    `json:"merchant_gold"` // Merchant's gold after the trade
+}
+
+// NewMerchant creates a merchant with the default price ratios.
+//
+// Parameters:
+//   - id, name: Identity of the merchant NPC
+//   - faction: Faction whose reputation adjusts the merchant's prices
+//   - pos: Where the merchant stands
+//   - gold: Starting gold pool used to buy from players
+//   - stock: Items for sale
+func NewMerchant(id, name, faction string, pos Position, gold int, stock []Item) *Merchant {
+	return &Merchant{
+		NPC: NPC{
+			Character: Character{
+				ID:        id,
+				Name:      name,
+				Position:  pos,
+				Charisma:  10,
+				Gold:      gold,
+				Inventory: append([]Item(nil), stock...),
+				active:    true,
+				tags:      []string{"merchant"},
+			},
+			Behavior: "merchant",
+			Faction:  faction,
+		},
+		BuyMarkup: DefaultMerchantBuyMarkup,
+		SellRatio: DefaultMerchantSellRatio,
+	}
+}
+
+// PriceAdjustment returns the fraction prices move in the player's favour:
+// positive values lower what the player pays and raise what they are paid.
+func (m *Merchant) PriceAdjustment(player *Player) float64 {
+	player.mu.RLock()
+	defer player.mu.RUnlock()
+	return m.priceAdjustment(player)
+}
+
+// BuyPrice returns what the player would pay for item.
+func (m *Merchant) BuyPrice(item Item, player *Player) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.buyPrice(item, m.PriceAdjustment(player))
+}
+
+// SellPrice returns what the merchant would pay the player for item.
+func (m *Merchant) SellPrice(item Item, player *Player) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sellPrice(item, m.PriceAdjustment(player))
+}
+
+// SetMarketFactor sets the multiplier on the merchant's prices, see
+// MarketFactor.
+func (m *Merchant) SetMarketFactor(factor float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.MarketFactor = factor
+}
+
+// GetStock returns a copy of the items the merchant has for sale.
+func (m *Merchant) GetStock() []Item {
+	return m.GetInventory()
+}
+
+// GetGold returns the merchant's gold pool.
+func (m *Merchant) GetGold() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.Gold
+}
+
+// Restock puts back the items of stock the merchant has sold, matched by
+// ID, and tops the merchant's gold pool up to gold. Items bought from
+// players stay for sale. It returns the number of items put back.
+func (m *Merchant) Restock(stock []Item, gold int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	held := make(map[string]bool, len(m.Inventory))
+	for _, item := range m.Inventory {
+		held[item.ID] = true
+	}
+	restocked := 0
+	for _, item := range stock {
+		if !held[item.ID] {
+			m.Inventory = append(m.Inventory, item)
+			restocked++
+		}
+	}
+	if m.Gold < gold {
+		m.Gold = gold
+	}
+	return restocked
+}
+
+// SellToPlayer sells the stocked item to the player. The player must be
+// able to afford and carry it.
+func (m *Merchant) SellToPlayer(player *Player, itemID string) (*Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	index := findItemIndex(m.Inventory, itemID)
+	if index < 0 {
+		return nil, fmt.Errorf("merchant %s does not stock item %s", m.ID, itemID)
+	}
+	item := m.Inventory[index]
+
+	price := m.buyPrice(item, m.priceAdjustment(player))
+	if player.Gold < price {
+		return nil, fmt.Errorf("insufficient gold to buy %s (need %d, have %d)", item.Name, price, player.Gold)
+	}
+	if player.calculateTotalWeight()+item.Weight > player.calculateMaxCarryingCapacity() {
+		return nil, fmt.Errorf("buying %s would exceed carrying capacity", item.Name)
+	}
+
+	m.Inventory = append(m.Inventory[:index], m.Inventory[index+1:]...)
+	player.Inventory = append(player.Inventory, item)
+	player.Gold -= price
+	m.Gold += price
+
+	return m.recordTrade("SellToPlayer", player, item, price), nil
+}
+
+// BuyFromPlayer buys an item from the player's inventory. Equipped items
+// must be unequipped first, and the merchant must have the gold to pay.
+func (m *Merchant) BuyFromPlayer(player *Player, itemID string) (*Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	index := findItemIndex(player.Inventory, itemID)
+	if index < 0 {
+		return nil, fmt.Errorf("item not found in inventory: %s", itemID)
+	}
+	item := player.Inventory[index]
+
+	price := m.sellPrice(item, m.priceAdjustment(player))
+	if m.Gold < price {
+		return nil, fmt.Errorf("merchant %s cannot afford %s (need %d, has %d)", m.ID, item.Name, price, m.Gold)
+	}
+
+	player.Inventory = append(player.Inventory[:index], player.Inventory[index+1:]...)
+	m.Inventory = append(m.Inventory, item)
+	m.Gold -= price
+	player.Gold += price
+
+	return m.recordTrade("BuyFromPlayer", player, item, price), nil
+}
+
+// recordTrade logs a completed trade and describes it (requires both locks)
+func (m *Merchant) recordTrade(function string, player *Player, item Item, price int) *Trade {
+	logrus.WithFields(logrus.Fields{
+		"function":    function,
+		"package":     "game",
+		"merchant_id": m.ID,
+		"player_id":   player.ID,
+		"item_id":     item.ID,
+		"price":       price,
+	}).Info("merchant trade completed")
+
+	return &Trade{
+		MerchantID:   m.ID,
+		PlayerID:     player.ID,
+		Item:         item,
+		Price:        price,
+		PlayerGold:   player.Gold,
+		MerchantGold: m.Gold,
+	}
+}
+
+// priceAdjustment computes the player's price adjustment (requires the
+// player's lock)
+func (m *Merchant) priceAdjustment(player *Player) float64 {
+	adjustment := float64(player.Charisma-10)*MerchantCharismaStep +
+		float64(player.Reputation[m.Faction])*MerchantReputationStep
+	return math.Max(-MaxMerchantPriceAdjustment, math.Min(MaxMerchantPriceAdjustment, adjustment))
+}
+
+// marketFactor returns the multiplier on prices (requires the merchant's
+// lock)
+func (m *Merchant) marketFactor() float64 {
+	if m.MarketFactor <= 0 {
+		return 1
+	}
+	return m.MarketFactor
+}
+
+// buyPrice is the price a player pays for item, never less than 1 gold
+func (m *Merchant) buyPrice(item Item, adjustment float64) int {
+	price := float64(item.Value) * m.BuyMarkup * m.marketFactor() * (1 - adjustment)
+	return max(1, int(math.Round(price)))
+}
+
+// sellPrice is the price a player is paid for item, kept below the buy
+// price so items cannot be traded back and forth for profit
+func (m *Merchant) sellPrice(item Item, adjustment float64) int {
+	price := int(math.Round(float64(item.Value) * m.SellRatio * m.marketFactor() * (1 + adjustment)))
+	return max(0, min(price, m.buyPrice(item, adjustment)-1))
+}
+
+// findItemIndex returns the index of the item with itemID, or -1
+func findItemIndex(items []Item, itemID string) int {
+	for i, item := range items {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,174 @@
+package game
+
+import "testing"
+
+func newTestMerchant() *Merchant {
+	return NewMerchant("merchant-1", "Trader", "merchants_guild", Position{}, 100, []Item{
+		{ID: "sword", Name: "Sword", Value: 50, Weight: 5},
+		{ID: "anvil", Name: "Anvil", Value: 10, Weight: 500},
+	})
+}
+
+func newTestCustomer(gold, charisma int) *Player {
+	return &Player{
+		Character: Character{
+			ID:       "player-1",
+			Charisma: charisma,
+			Strength: 10,
+			Gold:     gold,
+		},
+		Reputation: map[string]int{},
+	}
+}
+
+func TestMerchant_Prices(t *testing.T) {
+	merchant := newTestMerchant()
+	sword := Item{ID: "sword", Value: 50}
+
+	average := newTestCustomer(0, 10)
+	if price := merchant.BuyPrice(sword, average); price != 50 {
+		t.Errorf("buy price = %d, want 50", price)
+	}
+	if price := merchant.SellPrice(sword, average); price != 25 {
+		t.Errorf("sell price = %d, want 25", price)
+	}
+
+	// Charisma 15 and standing 50 give 0.1 + 0.1 = 20% in the player's favour
+	charming := newTestCustomer(0, 15)
+	charming.Reputation["merchants_guild"] = 50
+	if price := merchant.BuyPrice(sword, charming); price != 40 {
+		t.Errorf("buy price = %d, want 40", price)
+	}
+	if price := merchant.SellPrice(sword, charming); price != 30 {
+		t.Errorf("sell price = %d, want 30", price)
+	}
+
+	// Adjustment is capped
+	hated := newTestCustomer(0, 3)
+	hated.Reputation["merchants_guild"] = -100
+	if adj := merchant.PriceAdjustment(hated); adj != -MaxMerchantPriceAdjustment {
+		t.Errorf("adjustment = %v, want %v", adj, -MaxMerchantPriceAdjustment)
+	}
+
+	// Generous sell ratios never allow a profit on resale
+	merchant.SellRatio = 2
+	if buy, sell := merchant.BuyPrice(sword, average), merchant.SellPrice(sword, average); sell >= buy {
+		t.Errorf("sell price %d should stay below buy price %d", sell, buy)
+	}
+}
+
+func TestMerchant_MarketFactor(t *testing.T) {
+	merchant := newTestMerchant()
+	sword := Item{ID: "sword", Value: 50}
+	average := newTestCustomer(0, 10)
+
+	merchant.SetMarketFactor(1.5)
+	if price := merchant.BuyPrice(sword, average); price != 75 {
+		t.Errorf("buy price = %d, want 75", price)
+	}
+	if price := merchant.SellPrice(sword, average); price != 38 {
+		t.Errorf("sell price = %d, want 38", price)
+	}
+
+	merchant.SetMarketFactor(0)
+	if price := merchant.BuyPrice(sword, average); price != 50 {
+		t.Errorf("buy price without a factor = %d, want 50", price)
+	}
+}
+
+func TestMerchant_SellToPlayer(t *testing.T) {
+	merchant := newTestMerchant()
+	player := newTestCustomer(60, 10)
+
+	trade, err := merchant.SellToPlayer(player, "sword")
+	if err != nil {
+		t.Fatalf("SellToPlayer failed: %v", err)
+	}
+	if trade.Price != 50 || trade.PlayerGold != 10 || trade.MerchantGold != 150 {
+		t.Errorf("unexpected trade %+v", trade)
+	}
+	if _, index := player.FindItemInInventory("sword"); index < 0 {
+		t.Error("player should own the sword")
+	}
+	if len(merchant.GetStock()) != 1 {
+		t.Error("merchant should no longer stock the sword")
+	}
+
+	if _, err := merchant.SellToPlayer(player, "sword"); err == nil {
+		t.Error("expected error buying an item the merchant no longer stocks")
+	}
+
+	player.Gold = 1000
+	if _, err := merchant.SellToPlayer(player, "anvil"); err == nil {
+		t.Error("expected error buying an item too heavy to carry")
+	}
+	if player.Gold != 1000 || len(merchant.GetStock()) != 1 {
+		t.Error("failed trade should change neither party")
+	}
+}
+
+func TestMerchant_SellToPlayer_InsufficientGold(t *testing.T) {
+	merchant := newTestMerchant()
+	player := newTestCustomer(49, 10)
+
+	if _, err := merchant.SellToPlayer(player, "sword"); err == nil {
+		t.Fatal("expected error without enough gold")
+	}
+	if player.Gold != 49 || merchant.Gold != 100 || len(player.Inventory) != 0 {
+		t.Error("failed trade should change neither party")
+	}
+}
+
+func TestMerchant_BuyFromPlayer(t *testing.T) {
+	merchant := newTestMerchant()
+	player := newTestCustomer(0, 10)
+	player.Inventory = []Item{{ID: "gem", Name: "Gem", Value: 100}, {ID: "crown", Name: "Crown", Value: 1000}}
+
+	trade, err := merchant.BuyFromPlayer(player, "gem")
+	if err != nil {
+		t.Fatalf("BuyFromPlayer failed: %v", err)
+	}
+	if trade.Price != 50 || player.Gold != 50 || merchant.Gold != 50 {
+		t.Errorf("unexpected trade %+v", trade)
+	}
+	if len(merchant.GetStock()) != 3 {
+		t.Error("merchant should stock the gem")
+	}
+
+	if _, err := merchant.BuyFromPlayer(player, "crown"); err == nil {
+		t.Error("expected error when the merchant cannot afford the item")
+	}
+	if _, err := merchant.BuyFromPlayer(player, "missing"); err == nil {
+		t.Error("expected error selling an item the player does not have")
+	}
+	if len(player.Inventory) != 1 || player.Gold != 50 {
+		t.Error("failed trades should change neither party")
+	}
+}
+
+func TestMerchant_Restock(t *testing.T) {
+	merchant := newTestMerchant()
+	opening := merchant.GetStock()
+	player := newTestCustomer(60, 10)
+	player.Inventory = []Item{{ID: "dagger", Name: "Dagger", Value: 10, Weight: 1}}
+
+	if _, err := merchant.SellToPlayer(player, "sword"); err != nil {
+		t.Fatalf("SellToPlayer failed: %v", err)
+	}
+	if _, err := merchant.BuyFromPlayer(player, "dagger"); err != nil {
+		t.Fatalf("BuyFromPlayer failed: %v", err)
+	}
+	merchant.Gold = 20
+
+	if restocked := merchant.Restock(opening, 100); restocked != 1 {
+		t.Errorf("Restock() = %d, want 1", restocked)
+	}
+	if len(merchant.GetStock()) != 3 || merchant.GetGold() != 100 {
+		t.Errorf("restocked merchant has %d items and %d gold, want 3 and 100", len(merchant.GetStock()), merchant.GetGold())
+	}
+
+	merchant.Gold = 500
+	if restocked := merchant.Restock(opening, 100); restocked != 0 || merchant.GetGold() != 500 {
+		t.Errorf("restocking a full shop changed it: %d items put back, %d gold", restocked, merchant.GetGold())
+	}
+}
@@ -0,0 +1,468 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Dual-class attribute minimums: every prime requisite of the class being
+// left must be at least DualClassCurrentMinimum, and every prime requisite
+// of the new class at least DualClassNewMinimum.
+const (
+	DualClassCurrentMinimum = 15
+	DualClassNewMinimum     = 17
+)
+
+// ClassLevel is the progress of a character in one of several classes.
+//
+// A multi-classed character advances in all its classes at once, the
+// experience it earns being split evenly between them. A dual-classed
+// character gives up its former class for a new one: the former class
+// keeps its level, earns no more experience and cannot be used until the
+// new class exceeds its level.
+type ClassLevel struct {
+	Class      CharacterClass `yaml:"class_type"`             // The class
+	Level      int            `yaml:"class_level"`            // Level attained in the class
+	Experience int64          `yaml:"class_experience"`       // Experience earned in the class
+	Former     bool           `yaml:"class_former,omitempty"` // Given up by dual-classing
+}
+
+// multiClassCombinations are the class combinations a character may be
+// created with, following the Gold Box games.
+var multiClassCombinations = [][]CharacterClass{
+	{ClassFighter, ClassCleric},
+	{ClassFighter, ClassThief},
+	{ClassFighter, ClassMage},
+	{ClassCleric, ClassMage},
+	{ClassCleric, ClassRanger},
+	{ClassMage, ClassThief},
+	{ClassFighter, ClassMage, ClassThief},
+	{ClassFighter, ClassMage, ClassCleric},
+}
+
+// classPrimeRequisites are the attributes that matter most to each class.
+var classPrimeRequisites = map[CharacterClass][]string{
+	ClassFighter: {"strength"},
+	ClassMage:    {"intelligence"},
+	ClassCleric:  {"wisdom"},
+	ClassThief:   {"dexterity"},
+	ClassRanger:  {"strength", "dexterity", "wisdom"},
+	ClassPaladin: {"strength", "charisma"},
+}
+
+// ValidateMultiClass reports whether classes may be combined into one
+// multi-classed character. Order does not matter.
+func ValidateMultiClass(classes []CharacterClass) error {
+	if len(classes) < 2 {
+		return fmt.Errorf("a multi-classed character needs at least two classes")
+	}
+	for _, combination := range multiClassCombinations {
+		if len(combination) == len(classes) && containsAllClasses(combination, classes) {
+			return nil
+		}
+	}
+	return fmt.Errorf("classes %v cannot be combined", classes)
+}
+
+// PrimeRequisites returns the prime requisite attributes of class.
+func PrimeRequisites(class CharacterClass) []string {
+	return slices.Clone(classPrimeRequisites[class])
+}
+
+// containsAllClasses reports whether set holds every class of classes once
+func containsAllClasses(set, classes []CharacterClass) bool {
+	for i, class := range classes {
+		if !slices.Contains(set, class) || slices.Contains(classes[:i], class) {
+			return false
+		}
+	}
+	return true
+}
+
+// averageBaseHP returns the mean of the level 1 hit points of classes.
+func averageBaseHP(classes []CharacterClass, constitution int) int {
+	total := 0
+	for _, class := range classes {
+		total += calculateHealthGain(class, constitution)
+	}
+	return total / len(classes)
+}
+
+// classLevelForExperience returns the level reached in class with xp
+// experience under the active ruleset, or the built-in table without one.
+func classLevelForExperience(class CharacterClass, xp int64) int {
+	if rules := ActiveRuleset(); rules != nil {
+		return rules.LevelForExperience(class, xp)
+	}
+	return calculateLevel(xp)
+}
+
+// classExperienceForLevel returns the experience needed to reach level in
+// class, or -1 when the class cannot reach it.
+func classExperienceForLevel(class CharacterClass, level int) int64 {
+	if rules := ActiveRuleset(); rules != nil {
+		return rules.ExperienceForLevel(class, level)
+	}
+	if level <= 1 {
+		return 0
+	}
+	if level > len(experienceThresholds) {
+		return -1
+	}
+	return experienceThresholds[level-1]
+}
+
+// playerAttributes returns the ability scores of p keyed by attribute name
+// (requires the lock)
+func (p *Player) playerAttributes() map[string]int {
+	return map[string]int{
+		"strength":     p.Strength,
+		"dexterity":    p.Dexterity,
+		"constitution": p.Constitution,
+		"intelligence": p.Intelligence,
+		"wisdom":       p.Wisdom,
+		"charisma":     p.Charisma,
+	}
+}
+
+// IsMultiClassed reports whether the player advances in several classes
+// at once.
+func (p *Player) IsMultiClassed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.earningClasses()) > 1
+}
+
+// IsDualClassed reports whether the player has given up a class for
+// another.
+func (p *Player) IsDualClassed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.isDualClassed()
+}
+
+// isDualClassed reports whether any class is a former one (requires the
+// lock)
+func (p *Player) isDualClassed() bool {
+	return slices.ContainsFunc(p.Classes, func(cl ClassLevel) bool { return cl.Former })
+}
+
+// GetClasses returns a copy of the player's class levels. Players created
+// with a single class and never dual-classed have none.
+func (p *Player) GetClasses() []ClassLevel {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Clone(p.Classes)
+}
+
+// ActiveClasses returns the classes whose abilities the player can use: all
+// of a multi-classed player's classes, and the former class of a
+// dual-classed player only once the new class has exceeded its level.
+func (p *Player) ActiveClasses() []CharacterClass {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.activeClasses()
+}
+
+// activeClasses implements ActiveClasses (requires the lock)
+func (p *Player) activeClasses() []CharacterClass {
+	if len(p.Classes) == 0 {
+		return []CharacterClass{p.Class}
+	}
+	current := p.currentClassLevel()
+	classes := make([]CharacterClass, 0, len(p.Classes))
+	for _, cl := range p.Classes {
+		if !cl.Former || current > cl.Level {
+			classes = append(classes, cl.Class)
+		}
+	}
+	return classes
+}
+
+// earningClasses returns the indexes of the classes that earn experience
+// (requires the lock)
+func (p *Player) earningClasses() []int {
+	var indexes []int
+	for i, cl := range p.Classes {
+		if !cl.Former {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// currentClassLevel returns the highest level among the classes still
+// earning experience (requires the lock)
+func (p *Player) currentClassLevel() int {
+	level := 0
+	for _, i := range p.earningClasses() {
+		level = max(level, p.Classes[i].Level)
+	}
+	return level
+}
+
+// formerClassLevel returns the highest level of the player's former
+// classes (requires the lock)
+func (p *Player) formerClassLevel() int {
+	level := 0
+	for _, cl := range p.Classes {
+		if cl.Former {
+			level = max(level, cl.Level)
+		}
+	}
+	return level
+}
+
+// ClassLevelOf returns the player's level in class, or 0 when the player
+// does not have the class.
+func (p *Player) ClassLevelOf(class CharacterClass) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.Classes) == 0 {
+		if p.Class == class {
+			return p.Level
+		}
+		return 0
+	}
+	for _, cl := range p.Classes {
+		if cl.Class == class {
+			return cl.Level
+		}
+	}
+	return 0
+}
+
+// CasterLevel returns the level at which the player learns and casts
+// spells. Players with several classes cast at the highest level among
+// their active spellcasting classes, or 0 without one; other players at
+// their character level.
+func (p *Player) CasterLevel() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.casterLevel()
+}
+
+// casterLevel implements CasterLevel (requires the lock)
+func (p *Player) casterLevel() int {
+	if len(p.Classes) == 0 {
+		return p.Level
+	}
+	level := 0
+	for _, class := range p.activeClasses() {
+		for _, cl := range p.Classes {
+			if cl.Class == class && classCanCast(class, cl.Level) {
+				level = max(level, cl.Level)
+			}
+		}
+	}
+	return level
+}
+
+// classCanCast reports whether a character of class and level can cast
+// spells, following D&D-style classes where only certain classes are
+// spellcasters
+func classCanCast(class CharacterClass, level int) bool {
+	switch class {
+	case ClassMage, ClassCleric:
+		return true
+	case ClassPaladin:
+		return level >= 9 // Paladins get spells at level 9
+	case ClassRanger:
+		return level >= 8 // Rangers get spells at level 8
+	default:
+		return false
+	}
+}
+
+// ClassProgress describes the player's standing in one class.
+type ClassProgress struct {
+	Class      string `json:"class"`
+	Level      int    `json:"level"`
+	Experience int64  `json:"experience"`
+	NextLevel  int64  `json:"next_level_experience"` // Class experience for the next level, -1 at the maximum
+	Former     bool   `json:"former,omitempty"`
+	Active     bool   `json:"active"`
+	Shares     int    `json:"experience_shares"` // Classes the player's experience is split between
+}
+
+// GetClassProgress returns the player's level and experience in each
+// class. A multi-classed player's experience is split between its classes,
+// so it needs experience_shares times a class's next_level_experience in
+// total experience to reach the next level in all of them.
+func (p *Player) GetClassProgress() []ClassProgress {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	classes := p.Classes
+	if len(classes) == 0 {
+		classes = []ClassLevel{{Class: p.Class, Level: p.Level, Experience: p.Experience}}
+	}
+	shares := max(len(p.earningClasses()), 1)
+	active := p.activeClasses()
+
+	progress := make([]ClassProgress, 0, len(classes))
+	for _, cl := range classes {
+		next := int64(-1)
+		if !cl.Former {
+			next = classExperienceForLevel(cl.Class, cl.Level+1)
+		}
+		progress = append(progress, ClassProgress{
+			Class:      cl.Class.String(),
+			Level:      cl.Level,
+			Experience: cl.Experience,
+			NextLevel:  next,
+			Former:     cl.Former,
+			Active:     slices.Contains(active, cl.Class),
+			Shares:     shares,
+		})
+	}
+	return progress
+}
+
+// addClassExperience splits exp evenly between the classes still earning
+// experience, any remainder going to the first, and advances each class
+// that reaches a new level (requires the lock)
+func (p *Player) addClassExperience(exp int64) error {
+	earning := p.earningClasses()
+	if len(earning) == 0 {
+		return nil
+	}
+
+	share := exp / int64(len(earning))
+	remainder := exp % int64(len(earning))
+	for n, i := range earning {
+		cl := &p.Classes[i]
+		cl.Experience += share
+		if n == 0 {
+			cl.Experience += remainder
+		}
+		if newLevel := classLevelForExperience(cl.Class, cl.Experience); newLevel > cl.Level {
+			p.advanceClass(i, newLevel)
+		}
+	}
+
+	if newLevel := p.currentClassLevel(); newLevel > p.Level {
+		oldLevel := p.Level
+		p.Level = newLevel
+		emitLevelUpEvent(p.ID, oldLevel, newLevel)
+	}
+	return nil
+}
+
+// advanceClass raises class i to newLevel. Multi-classed players gain the
+// class's hit points divided by their number of classes; dual-classed
+// players gain none until the new class exceeds the former one (requires
+// the lock).
+func (p *Player) advanceClass(i, newLevel int) {
+	cl := &p.Classes[i]
+	classes := len(p.earningClasses())
+	for level := cl.Level + 1; level <= newLevel; level++ {
+		if p.isDualClassed() && level <= p.formerClassLevel() {
+			continue
+		}
+		gain := max(calculateHealthGain(cl.Class, p.Constitution)/classes, 1)
+		p.MaxHP += gain
+		p.HP += gain
+	}
+	cl.Level = newLevel
+
+	p.THAC0 = p.bestTHAC0()
+	maxActionPoints := calculateMaxActionPoints(max(newLevel, p.Level), p.Dexterity)
+	p.MaxActionPoints = maxActionPoints
+	p.ActionPoints = maxActionPoints
+}
+
+// bestTHAC0 returns the best THAC0 among the player's active classes under
+// the active ruleset, or the current THAC0 without one (requires the lock)
+func (p *Player) bestTHAC0() int {
+	best := p.THAC0
+	for _, class := range p.activeClasses() {
+		best = min(best, rulesetTHAC0(class, p.classLevel(class), best))
+	}
+	return best
+}
+
+// classLevel returns the level of class, or the character level for
+// single-classed players (requires the lock)
+func (p *Player) classLevel(class CharacterClass) int {
+	for _, cl := range p.Classes {
+		if cl.Class == class {
+			return cl.Level
+		}
+	}
+	return p.Level
+}
+
+// checkDualClass reports whether the player may give up its class for
+// newClass (requires the lock)
+func (p *Player) checkDualClass(newClass CharacterClass) error {
+	if len(p.earningClasses()) > 1 {
+		return fmt.Errorf("multi-classed characters cannot change class")
+	}
+	if p.isDualClassed() {
+		return fmt.Errorf("%s has already changed class", p.Name)
+	}
+	if p.Class == newClass {
+		return fmt.Errorf("%s is already a %s", p.Name, newClass)
+	}
+	attributes := p.playerAttributes()
+	for _, attribute := range classPrimeRequisites[p.Class] {
+		if attributes[attribute] < DualClassCurrentMinimum {
+			return fmt.Errorf("insufficient %s to leave %s (need %d, have %d)",
+				attribute, p.Class, DualClassCurrentMinimum, attributes[attribute])
+		}
+	}
+	requisites, ok := classPrimeRequisites[newClass]
+	if !ok {
+		return fmt.Errorf("unknown character class: %v", newClass)
+	}
+	for _, attribute := range requisites {
+		if attributes[attribute] < DualClassNewMinimum {
+			return fmt.Errorf("insufficient %s to become a %s (need %d, have %d)",
+				attribute, newClass, DualClassNewMinimum, attributes[attribute])
+		}
+	}
+	return nil
+}
+
+// dualClass gives up the player's class for newClass, which starts at
+// level 1 (requires the lock)
+func (p *Player) dualClass(newClass CharacterClass) {
+	if len(p.Classes) == 0 {
+		p.Classes = []ClassLevel{{Class: p.Class, Level: p.Level, Experience: p.Experience}}
+	}
+	for i := range p.Classes {
+		p.Classes[i].Former = true
+	}
+	p.Classes = append(p.Classes, ClassLevel{Class: newClass, Level: 1})
+	p.Class = newClass
+}
+
+// ChangeClass dual-classes player into class: the player's current class
+// becomes a former class and the new class starts at level 1. The player
+// must not be multi-classed or already dual-classed, must meet the new
+// class's requirements, have at least DualClassCurrentMinimum in the prime
+// requisites of the current class and at least DualClassNewMinimum in
+// those of the new class.
+//
+// Parameters:
+//   - player: The player changing class
+//   - class: The new class
+//
+// Returns:
+//   - error: Why the player cannot take the new class, or nil
+func (cc *CharacterCreator) ChangeClass(player *Player, class CharacterClass) error {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	if err := player.checkDualClass(class); err != nil {
+		return err
+	}
+	if err := cc.validateClassRequirements(class, player.playerAttributes()); err != nil {
+		return fmt.Errorf("class requirements not met: %w", err)
+	}
+
+	player.dualClass(class)
+	return nil
+}
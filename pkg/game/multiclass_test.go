@@ -0,0 +1,165 @@
+package game
+
+import (
+	"testing"
+)
+
+// multiClassAttributes meets the requirements of every class.
+var multiClassAttributes = map[string]int{
+	"strength":     17,
+	"dexterity":    14,
+	"constitution": 14,
+	"intelligence": 17,
+	"wisdom":       13,
+	"charisma":     10,
+}
+
+func TestValidateMultiClass(t *testing.T) {
+	tests := []struct {
+		name    string
+		classes []CharacterClass
+		wantErr bool
+	}{
+		{"fighter mage", []CharacterClass{ClassFighter, ClassMage}, false},
+		{"order does not matter", []CharacterClass{ClassMage, ClassFighter}, false},
+		{"three classes", []CharacterClass{ClassThief, ClassFighter, ClassMage}, false},
+		{"single class", []CharacterClass{ClassFighter}, true},
+		{"disallowed pair", []CharacterClass{ClassPaladin, ClassMage}, true},
+		{"duplicate class", []CharacterClass{ClassFighter, ClassFighter}, true},
+		{"extra class", []CharacterClass{ClassFighter, ClassMage, ClassRanger}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMultiClass(tt.classes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMultiClass(%v) error = %v, wantErr %v", tt.classes, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCharacterCreator_CreateCharacter_MultiClass(t *testing.T) {
+	creator := NewCharacterCreator()
+	result := creator.CreateCharacter(CharacterCreationConfig{
+		Name:             "Tanis",
+		Class:            ClassFighter,
+		Classes:          []CharacterClass{ClassFighter, ClassMage},
+		AttributeMethod:  "custom",
+		CustomAttributes: multiClassAttributes,
+	})
+	if !result.Success {
+		t.Fatalf("Character creation failed: %v", result.Errors)
+	}
+
+	player := result.PlayerData
+	if !player.IsMultiClassed() || player.IsDualClassed() {
+		t.Errorf("IsMultiClassed = %v, IsDualClassed = %v, want a multi-classed player",
+			player.IsMultiClassed(), player.IsDualClassed())
+	}
+	wantHP := averageBaseHP([]CharacterClass{ClassFighter, ClassMage}, 14)
+	if player.MaxHP != wantHP {
+		t.Errorf("MaxHP = %d, want the average %d", player.MaxHP, wantHP)
+	}
+	if player.ClassLevelOf(ClassMage) != 1 || player.CasterLevel() != 1 {
+		t.Errorf("mage level = %d, caster level = %d, want 1", player.ClassLevelOf(ClassMage), player.CasterLevel())
+	}
+
+	// Experience is split: 4000 gives each class 2000, reaching level 2
+	if err := player.AddExperience(4001); err != nil {
+		t.Fatalf("AddExperience failed: %v", err)
+	}
+	classes := player.GetClasses()
+	if classes[0].Experience != 2001 || classes[1].Experience != 2000 {
+		t.Errorf("class experience = %d/%d, want 2001/2000", classes[0].Experience, classes[1].Experience)
+	}
+	if classes[0].Level != 2 || classes[1].Level != 2 || player.Level != 2 {
+		t.Errorf("levels = %d/%d (character %d), want 2", classes[0].Level, classes[1].Level, player.Level)
+	}
+	if player.MaxHP <= wantHP {
+		t.Errorf("MaxHP = %d, want it to grow on level up", player.MaxHP)
+	}
+}
+
+func TestCharacterCreator_CreateCharacter_MultiClassInvalid(t *testing.T) {
+	creator := NewCharacterCreator()
+	weak := copyIntMap(multiClassAttributes)
+	weak["intelligence"] = 9
+
+	tests := []struct {
+		name       string
+		class      CharacterClass
+		classes    []CharacterClass
+		attributes map[string]int
+	}{
+		{"disallowed combination", ClassPaladin, []CharacterClass{ClassPaladin, ClassThief}, multiClassAttributes},
+		{"class not first", ClassMage, []CharacterClass{ClassFighter, ClassMage}, multiClassAttributes},
+		{"requirements of second class", ClassFighter, []CharacterClass{ClassFighter, ClassMage}, weak},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := creator.CreateCharacter(CharacterCreationConfig{
+				Name:             "Invalid",
+				Class:            tt.class,
+				Classes:          tt.classes,
+				AttributeMethod:  "custom",
+				CustomAttributes: tt.attributes,
+			})
+			if result.Success {
+				t.Error("expected character creation to fail")
+			}
+		})
+	}
+}
+
+func TestCharacterCreator_ChangeClass(t *testing.T) {
+	creator := NewCharacterCreator()
+	player := &Player{
+		Character: Character{
+			Name: "Sturm", Class: ClassFighter, Level: 4, MaxHP: 30, HP: 30, THAC0: 17,
+			Strength: 17, Dexterity: 12, Constitution: 14, Intelligence: 17, Wisdom: 10, Charisma: 10,
+		},
+		Level:      4,
+		Experience: 8000,
+	}
+
+	if err := creator.ChangeClass(player, ClassCleric); err == nil {
+		t.Error("expected an error without the wisdom to become a cleric")
+	}
+	if err := creator.ChangeClass(player, ClassMage); err != nil {
+		t.Fatalf("ChangeClass failed: %v", err)
+	}
+	if player.Class != ClassMage || !player.IsDualClassed() {
+		t.Fatalf("Class = %v, IsDualClassed = %v, want a dual-classed mage", player.Class, player.IsDualClassed())
+	}
+	if active := player.ActiveClasses(); len(active) != 1 || active[0] != ClassMage {
+		t.Errorf("ActiveClasses = %v, want only the new class", active)
+	}
+	if err := creator.ChangeClass(player, ClassThief); err == nil {
+		t.Error("expected an error changing class twice")
+	}
+
+	// Reaching the former class's level grants no hit points
+	if err := player.AddExperience(8000); err != nil {
+		t.Fatalf("AddExperience failed: %v", err)
+	}
+	if player.ClassLevelOf(ClassMage) != 4 || player.ClassLevelOf(ClassFighter) != 4 || player.MaxHP != 30 {
+		t.Errorf("mage %d, fighter %d, MaxHP %d, want both level 4 and no new hit points",
+			player.ClassLevelOf(ClassMage), player.ClassLevelOf(ClassFighter), player.MaxHP)
+	}
+
+	// Exceeding it restores the former class
+	if err := player.AddExperience(8000); err != nil {
+		t.Fatalf("AddExperience failed: %v", err)
+	}
+	if active := player.ActiveClasses(); len(active) != 2 {
+		t.Errorf("ActiveClasses = %v, want the former class back", active)
+	}
+	if player.MaxHP <= 30 || player.CasterLevel() != 5 {
+		t.Errorf("MaxHP = %d, CasterLevel = %d, want new hit points and caster level 5", player.MaxHP, player.CasterLevel())
+	}
+	if player.ClassLevelOf(ClassFighter) != 4 {
+		t.Errorf("former class level = %d, want it to stay 4", player.ClassLevelOf(ClassFighter))
+	}
+}
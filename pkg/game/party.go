@@ -0,0 +1,530 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// RewardPolicy determines how a shared quest's rewards are divided among
+// the party members who complete it together.
+//
+// Policies:
+//   - RewardPolicyEven: gold and experience are divided evenly
+//   - RewardPolicyFull: every participant receives the full rewards
+//   - RewardPolicyContribution: gold and experience are divided in
+//     proportion to the objective progress each participant contributed
+//
+// Item rewards cannot be divided; under the even and contribution policies
+// they go to the participant who contributed the most progress.
+type RewardPolicy string
+
+const (
+	RewardPolicyEven         RewardPolicy = "even"
+	RewardPolicyFull         RewardPolicy = "full"
+	RewardPolicyContribution RewardPolicy = "contribution"
+)
+
+// Reasons a party member is left out when a quest is shared
+const (
+	ShareSkipAlreadyCompleted = "already_completed"
+	ShareSkipFailed           = "failed"
+)
+
+// SharedQuest tracks a quest shared across a party.
+//
+// Fields:
+//   - QuestID: ID of the shared quest
+//   - SharedBy: ID of the member who shared it
+//   - Participants: IDs of members whose quest logs are kept in sync
+//   - Contributions: Objective progress contributed by each participant
+type SharedQuest struct {
+	QuestID       string         `yaml:"shared_quest_id"`
+	SharedBy      string         `yaml:"shared_by"`
+	Participants  []string       `yaml:"shared_participants"`
+	Contributions map[string]int `yaml:"shared_contributions"`
+}
+
+// QuestShareResult reports how each party member was affected by ShareQuest.
+//
+// Fields:
+//   - QuestID: ID of the shared quest
+//   - Joined: Members who received the quest
+//   - Merged: Members who already had the quest active and now share progress
+//   - Skipped: Members left out, mapped to the reason
+type QuestShareResult struct {
+	QuestID string            `json:"quest_id"`
+	Joined  []string          `json:"joined"`
+	Merged  []string          `json:"merged"`
+	Skipped map[string]string `json:"skipped"`
+}
+
+// Party is a group of players that can share quests. Shared quests keep the
+// objective progress of every participant's quest log in sync and divide the
+// rewards according to the party's RewardPolicy.
+//
+// Conflicts are resolved by these rules:
+//   - A member who already completed or failed the quest is skipped when it
+//     is shared and never receives its rewards again.
+//   - A member who already has the quest active joins the shared state; each
+//     objective starts from the highest progress among the participants.
+//   - Shared progress never goes backwards. An update lower than the current
+//     shared progress is ignored.
+//   - Completing a shared quest completes it for every participant. A
+//     participant whose own log can no longer be completed is left out of the
+//     reward split.
+//   - A member who leaves the party keeps their quest log but stops sharing.
+//
+// Thread Safety: Party methods are safe for concurrent use. Player quest logs
+// are updated through the Player's own thread-safe methods.
+type Party struct {
+	mu       sync.RWMutex
+	ID       string                  `yaml:"party_id"`
+	LeaderID string                  `yaml:"party_leader"`
+	Members  []string                `yaml:"party_members"`
+	Policy   RewardPolicy            `yaml:"party_reward_policy"`
+	Quests   map[string]*SharedQuest `yaml:"party_quests"`
+}
+
+// NewParty creates a party led by leaderID. An empty policy defaults to
+// RewardPolicyEven.
+func NewParty(id, leaderID string, policy RewardPolicy) (*Party, error) {
+	if id == "" {
+		return nil, fmt.Errorf("party ID cannot be empty")
+	}
+	if leaderID == "" {
+		return nil, fmt.Errorf("party leader cannot be empty")
+	}
+	if policy == "" {
+		policy = RewardPolicyEven
+	}
+	if !policy.IsValid() {
+		return nil, fmt.Errorf("unknown reward policy: %s", policy)
+	}
+
+	return &Party{
+		ID:       id,
+		LeaderID: leaderID,
+		Members:  []string{leaderID},
+		Policy:   policy,
+		Quests:   make(map[string]*SharedQuest),
+	}, nil
+}
+
+// IsValid reports whether the policy is one of the defined reward policies
+func (rp RewardPolicy) IsValid() bool {
+	switch rp {
+	case RewardPolicyEven, RewardPolicyFull, RewardPolicyContribution:
+		return true
+	}
+	return false
+}
+
+// AddMember adds a player to the party
+func (p *Party) AddMember(playerID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if playerID == "" {
+		return fmt.Errorf("player ID cannot be empty")
+	}
+	if containsID(p.Members, playerID) {
+		return fmt.Errorf("player %s is already in party %s", playerID, p.ID)
+	}
+
+	p.Members = append(p.Members, playerID)
+	return nil
+}
+
+// RemoveMember removes a player from the party and from every shared quest.
+// If the leader leaves, leadership passes to the longest-standing member.
+func (p *Party) RemoveMember(playerID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !containsID(p.Members, playerID) {
+		return fmt.Errorf("player %s is not in party %s", playerID, p.ID)
+	}
+
+	p.Members = removeID(p.Members, playerID)
+	for questID, shared := range p.Quests {
+		shared.Participants = removeID(shared.Participants, playerID)
+		if len(shared.Participants) == 0 {
+			delete(p.Quests, questID)
+		}
+	}
+
+	if p.LeaderID == playerID && len(p.Members) > 0 {
+		p.LeaderID = p.Members[0]
+	}
+	return nil
+}
+
+// HasMember reports whether the player belongs to the party
+func (p *Party) HasMember(playerID string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return containsID(p.Members, playerID)
+}
+
+// GetMembers returns a copy of the party member IDs
+func (p *Party) GetMembers() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	members := make([]string, len(p.Members))
+	copy(members, p.Members)
+	return members
+}
+
+// GetLeaderID returns the ID of the current party leader
+func (p *Party) GetLeaderID() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.LeaderID
+}
+
+// GetSharedQuests returns copies of all shared quest states ordered by quest ID
+func (p *Party) GetSharedQuests() []*SharedQuest {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	quests := make([]*SharedQuest, 0, len(p.Quests))
+	for _, shared := range p.Quests {
+		quests = append(quests, shared.clone())
+	}
+	sort.Slice(quests, func(i, j int) bool {
+		return quests[i].QuestID < quests[j].QuestID
+	})
+	return quests
+}
+
+// GetSharedQuest returns a copy of the shared state for a quest
+func (p *Party) GetSharedQuest(questID string) (*SharedQuest, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	shared, exists := p.Quests[questID]
+	if !exists {
+		return nil, false
+	}
+	return shared.clone(), true
+}
+
+// IsSharedWith reports whether the player participates in the shared quest
+func (p *Party) IsSharedWith(questID, playerID string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	shared, exists := p.Quests[questID]
+	return exists && containsID(shared.Participants, playerID)
+}
+
+// ShareQuest shares an active quest from the sharer's quest log with the
+// given party members. Sharing a quest that is already shared adds any new
+// members to it. Objective progress is then synchronized across all
+// participants as described on Party.
+//
+// Parameters:
+//   - questID: ID of the quest in the sharer's quest log
+//   - sharer: The member sharing the quest
+//   - members: Party members to share with; the sharer and non-members are ignored
+//
+// Returns:
+//   - *QuestShareResult: Which members joined, merged, or were skipped
+//   - error: If the sharer is not a member or the quest is not active
+func (p *Party) ShareQuest(questID string, sharer *Player, members []*Player) (*QuestShareResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if sharer == nil || !containsID(p.Members, sharer.ID) {
+		return nil, fmt.Errorf("sharer is not a member of party %s", p.ID)
+	}
+
+	quest, err := sharer.GetQuest(questID)
+	if err != nil {
+		return nil, err
+	}
+	if quest.Status != QuestActive {
+		return nil, fmt.Errorf("quest %s is not active", questID)
+	}
+
+	shared, exists := p.Quests[questID]
+	if !exists {
+		shared = &SharedQuest{
+			QuestID:       questID,
+			SharedBy:      sharer.ID,
+			Participants:  []string{sharer.ID},
+			Contributions: make(map[string]int),
+		}
+	} else if !containsID(shared.Participants, sharer.ID) {
+		shared.Participants = append(shared.Participants, sharer.ID)
+	}
+
+	result := &QuestShareResult{QuestID: questID, Skipped: make(map[string]string)}
+	participants := map[string]*Player{sharer.ID: sharer}
+
+	for _, member := range members {
+		if member == nil || member.ID == sharer.ID || !containsID(p.Members, member.ID) {
+			continue
+		}
+
+		existing, err := member.GetQuest(questID)
+		switch {
+		case err != nil:
+			if err := member.StartQuest(cloneQuest(*quest)); err != nil {
+				return nil, fmt.Errorf("failed to share quest with %s: %w", member.ID, err)
+			}
+			result.Joined = append(result.Joined, member.ID)
+		case existing.Status == QuestCompleted:
+			result.Skipped[member.ID] = ShareSkipAlreadyCompleted
+			continue
+		case existing.Status == QuestFailed:
+			result.Skipped[member.ID] = ShareSkipFailed
+			continue
+		case !containsID(shared.Participants, member.ID):
+			result.Merged = append(result.Merged, member.ID)
+		}
+
+		if !containsID(shared.Participants, member.ID) {
+			shared.Participants = append(shared.Participants, member.ID)
+		}
+		participants[member.ID] = member
+	}
+
+	// Start every objective from the highest progress among participants
+	progress := make([]int, len(quest.Objectives))
+	for _, player := range participants {
+		log, err := player.GetQuest(questID)
+		if err != nil {
+			continue
+		}
+		for i := range progress {
+			if i < len(log.Objectives) && log.Objectives[i].Progress > progress[i] {
+				progress[i] = log.Objectives[i].Progress
+			}
+		}
+	}
+	for _, player := range participants {
+		for i, value := range progress {
+			if err := player.UpdateQuestObjective(questID, i, value); err != nil {
+				return nil, fmt.Errorf("failed to synchronize quest progress for %s: %w", player.ID, err)
+			}
+		}
+	}
+
+	p.Quests[questID] = shared
+	return result, nil
+}
+
+// RecordProgress applies an objective update made by a participant to every
+// participant's quest log and credits the increase to the contributor.
+//
+// Parameters:
+//   - memberID: The participant who made progress
+//   - questID: ID of the shared quest
+//   - objectiveIndex: Index of the objective (0-based)
+//   - progress: The new progress value reported by the participant
+//   - players: Participants currently available, keyed by player ID
+//
+// Returns:
+//   - int: The shared progress of the objective after the update
+//   - error: If the quest is not shared with memberID or the update is invalid
+func (p *Party) RecordProgress(memberID, questID string, objectiveIndex, progress int, players map[string]*Player) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	shared, exists := p.Quests[questID]
+	if !exists || !containsID(shared.Participants, memberID) {
+		return 0, fmt.Errorf("quest %s is not shared with %s", questID, memberID)
+	}
+
+	contributor, ok := players[memberID]
+	if !ok {
+		return 0, fmt.Errorf("player %s is not available", memberID)
+	}
+	quest, err := contributor.GetQuest(questID)
+	if err != nil {
+		return 0, err
+	}
+	if objectiveIndex < 0 || objectiveIndex >= len(quest.Objectives) {
+		return 0, fmt.Errorf("objective index %d is out of bounds for quest %s", objectiveIndex, questID)
+	}
+
+	objective := quest.Objectives[objectiveIndex]
+	if progress <= objective.Progress {
+		// Shared progress never goes backwards
+		return objective.Progress, nil
+	}
+
+	newProgress := progress
+	if newProgress > objective.Required {
+		newProgress = objective.Required
+	}
+	shared.Contributions[memberID] += newProgress - objective.Progress
+
+	for _, id := range shared.Participants {
+		player, ok := players[id]
+		if !ok {
+			continue
+		}
+		if err := player.UpdateQuestObjective(questID, objectiveIndex, newProgress); err != nil {
+			return 0, fmt.Errorf("failed to synchronize quest progress for %s: %w", id, err)
+		}
+	}
+
+	return newProgress, nil
+}
+
+// CompleteQuest completes a shared quest for every available participant and
+// divides its rewards according to the party's policy. The completing member
+// must be able to complete the quest; other participants who cannot (for
+// example because their log changed outside the party) are left out.
+//
+// Returns:
+//   - map[string][]QuestReward: Rewards granted to each participant
+//   - error: If the quest is not shared with memberID or cannot be completed
+func (p *Party) CompleteQuest(memberID, questID string, players map[string]*Player) (map[string][]QuestReward, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	shared, exists := p.Quests[questID]
+	if !exists || !containsID(shared.Participants, memberID) {
+		return nil, fmt.Errorf("quest %s is not shared with %s", questID, memberID)
+	}
+
+	completer, ok := players[memberID]
+	if !ok {
+		return nil, fmt.Errorf("player %s is not available", memberID)
+	}
+	rewards, err := completer.CompleteQuest(questID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := []string{memberID}
+	for _, id := range shared.Participants {
+		player, ok := players[id]
+		if id == memberID || !ok {
+			continue
+		}
+		if _, err := player.CompleteQuest(questID); err != nil {
+			continue
+		}
+		recipients = append(recipients, id)
+	}
+
+	delete(p.Quests, questID)
+	return SplitQuestRewards(rewards, recipients, shared.Contributions, p.Policy), nil
+}
+
+// SplitQuestRewards divides quest rewards among recipients according to the
+// policy. Recipients are ranked by contribution (ties broken by ID); the
+// highest-ranked recipient receives item rewards and any indivisible
+// remainder goes to recipients in rank order. Recipients whose share is
+// zero receive no reward entry for it.
+func SplitQuestRewards(rewards []QuestReward, recipients []string, contributions map[string]int, policy RewardPolicy) map[string][]QuestReward {
+	split := make(map[string][]QuestReward, len(recipients))
+	if len(recipients) == 0 {
+		return split
+	}
+
+	ranked := make([]string, len(recipients))
+	copy(ranked, recipients)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		ci, cj := contributions[ranked[i]], contributions[ranked[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	totalContribution := 0
+	for _, id := range ranked {
+		totalContribution += contributions[id]
+	}
+
+	for _, reward := range rewards {
+		if policy == RewardPolicyFull {
+			for _, id := range ranked {
+				split[id] = append(split[id], reward)
+			}
+			continue
+		}
+
+		if reward.Type == "item" {
+			split[ranked[0]] = append(split[ranked[0]], reward)
+			continue
+		}
+
+		shares := make([]int, len(ranked))
+		allocated := 0
+		for i, id := range ranked {
+			if policy == RewardPolicyContribution && totalContribution > 0 {
+				shares[i] = reward.Value * contributions[id] / totalContribution
+			} else {
+				shares[i] = reward.Value / len(ranked)
+			}
+			allocated += shares[i]
+		}
+		for i := 0; allocated < reward.Value; i = (i + 1) % len(ranked) {
+			shares[i]++
+			allocated++
+		}
+
+		for i, id := range ranked {
+			if shares[i] == 0 {
+				continue
+			}
+			share := reward
+			share.Value = shares[i]
+			split[id] = append(split[id], share)
+		}
+	}
+
+	return split
+}
+
+// clone returns a deep copy of the shared quest state
+func (sq *SharedQuest) clone() *SharedQuest {
+	clone := &SharedQuest{
+		QuestID:       sq.QuestID,
+		SharedBy:      sq.SharedBy,
+		Participants:  make([]string, len(sq.Participants)),
+		Contributions: make(map[string]int, len(sq.Contributions)),
+	}
+	copy(clone.Participants, sq.Participants)
+	for id, amount := range sq.Contributions {
+		clone.Contributions[id] = amount
+	}
+	return clone
+}
+
+// cloneQuest returns a copy of the quest that shares no slices with the original
+func cloneQuest(quest Quest) Quest {
+	clone := quest
+	clone.Objectives = make([]QuestObjective, len(quest.Objectives))
+	copy(clone.Objectives, quest.Objectives)
+	clone.Rewards = make([]QuestReward, len(quest.Rewards))
+	copy(clone.Rewards, quest.Rewards)
+	return clone
+}
+
+func containsID(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeID(ids []string, id string) []string {
+	result := ids[:0]
+	for _, existing := range ids {
+		if existing != id {
+			result = append(result, existing)
+		}
+	}
+	return result
+}
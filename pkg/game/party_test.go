@@ -0,0 +1,214 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newPartyTestPlayer(id string) *Player {
+	return &Player{Character: Character{ID: id, Name: id}, Level: 1}
+}
+
+func newPartyTestQuest() Quest {
+	return Quest{
+		ID:    "rats",
+		Title: "Cellar Rats",
+		Objectives: []QuestObjective{
+			{Description: "Kill rats", Required: 10},
+			{Description: "Report back", Required: 1},
+		},
+		Rewards: []QuestReward{
+			{Type: "gold", Value: 100},
+			{Type: "exp", Value: 30},
+			{Type: "item", Value: 1, ItemID: "rat_tail"},
+		},
+	}
+}
+
+func newTestParty(t *testing.T, policy RewardPolicy, players ...*Player) *Party {
+	t.Helper()
+	party, err := NewParty("party-1", players[0].ID, policy)
+	if err != nil {
+		t.Fatalf("NewParty failed: %v", err)
+	}
+	for _, player := range players[1:] {
+		if err := party.AddMember(player.ID); err != nil {
+			t.Fatalf("AddMember failed: %v", err)
+		}
+	}
+	return party
+}
+
+func TestNewParty(t *testing.T) {
+	party, err := NewParty("p", "leader", "")
+	if err != nil {
+		t.Fatalf("NewParty failed: %v", err)
+	}
+	if party.Policy != RewardPolicyEven {
+		t.Errorf("Expected default policy even, got %s", party.Policy)
+	}
+
+	if _, err := NewParty("p", "leader", RewardPolicy("winner_takes_all")); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+
+	if err := party.AddMember("leader"); err == nil {
+		t.Error("Expected error adding an existing member")
+	}
+	if err := party.AddMember("second"); err != nil {
+		t.Fatalf("AddMember failed: %v", err)
+	}
+	if err := party.RemoveMember("leader"); err != nil {
+		t.Fatalf("RemoveMember failed: %v", err)
+	}
+	if party.LeaderID != "second" {
+		t.Errorf("Expected leadership to pass to second, got %s", party.LeaderID)
+	}
+}
+
+func TestPartyShareQuest(t *testing.T) {
+	alice, bob, carol, dave := newPartyTestPlayer("alice"), newPartyTestPlayer("bob"), newPartyTestPlayer("carol"), newPartyTestPlayer("dave")
+	party := newTestParty(t, RewardPolicyEven, alice, bob, carol, dave)
+
+	if err := alice.StartQuest(newPartyTestQuest()); err != nil {
+		t.Fatalf("StartQuest failed: %v", err)
+	}
+	if err := alice.UpdateQuestObjective("rats", 0, 2); err != nil {
+		t.Fatalf("UpdateQuestObjective failed: %v", err)
+	}
+
+	// Carol already has the quest with more progress; Dave already finished it
+	if err := carol.StartQuest(newPartyTestQuest()); err != nil {
+		t.Fatalf("StartQuest failed: %v", err)
+	}
+	if err := carol.UpdateQuestObjective("rats", 0, 5); err != nil {
+		t.Fatalf("UpdateQuestObjective failed: %v", err)
+	}
+	if err := dave.StartQuest(newPartyTestQuest()); err != nil {
+		t.Fatalf("StartQuest failed: %v", err)
+	}
+	dave.QuestLog[0].Status = QuestCompleted
+
+	result, err := party.ShareQuest("rats", alice, []*Player{alice, bob, carol, dave})
+	if err != nil {
+		t.Fatalf("ShareQuest failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(result.Joined, []string{"bob"}) {
+		t.Errorf("Expected bob to join, got %v", result.Joined)
+	}
+	if !reflect.DeepEqual(result.Merged, []string{"carol"}) {
+		t.Errorf("Expected carol to merge, got %v", result.Merged)
+	}
+	if result.Skipped["dave"] != ShareSkipAlreadyCompleted {
+		t.Errorf("Expected dave to be skipped, got %v", result.Skipped)
+	}
+
+	for _, player := range []*Player{alice, bob, carol} {
+		quest, err := player.GetQuest("rats")
+		if err != nil {
+			t.Fatalf("GetQuest failed for %s: %v", player.ID, err)
+		}
+		if quest.Objectives[0].Progress != 5 {
+			t.Errorf("Expected %s to start from the highest progress 5, got %d", player.ID, quest.Objectives[0].Progress)
+		}
+	}
+
+	// Shared quest logs must not alias each other
+	bob.QuestLog[0].Objectives[1].Description = "changed"
+	if alice.QuestLog[0].Objectives[1].Description == "changed" {
+		t.Error("Shared quest objectives alias the sharer's quest log")
+	}
+
+	if party.IsSharedWith("rats", "dave") {
+		t.Error("Members who already completed the quest must not participate")
+	}
+}
+
+func TestPartyRecordProgressAndComplete(t *testing.T) {
+	alice, bob := newPartyTestPlayer("alice"), newPartyTestPlayer("bob")
+	party := newTestParty(t, RewardPolicyContribution, alice, bob)
+	players := map[string]*Player{"alice": alice, "bob": bob}
+
+	if err := alice.StartQuest(newPartyTestQuest()); err != nil {
+		t.Fatalf("StartQuest failed: %v", err)
+	}
+	if _, err := party.ShareQuest("rats", alice, []*Player{bob}); err != nil {
+		t.Fatalf("ShareQuest failed: %v", err)
+	}
+
+	progress, err := party.RecordProgress("bob", "rats", 0, 8, players)
+	if err != nil {
+		t.Fatalf("RecordProgress failed: %v", err)
+	}
+	if progress != 8 {
+		t.Errorf("Expected shared progress 8, got %d", progress)
+	}
+
+	// Lower reports never roll shared progress back
+	if progress, _ := party.RecordProgress("alice", "rats", 0, 3, players); progress != 8 {
+		t.Errorf("Expected progress to stay at 8, got %d", progress)
+	}
+
+	if _, err := party.RecordProgress("alice", "rats", 0, 15, players); err != nil {
+		t.Fatalf("RecordProgress failed: %v", err)
+	}
+	if _, err := party.RecordProgress("alice", "rats", 1, 1, players); err != nil {
+		t.Fatalf("RecordProgress failed: %v", err)
+	}
+
+	shared, _ := party.GetSharedQuest("rats")
+	if shared.Contributions["bob"] != 8 || shared.Contributions["alice"] != 3 {
+		t.Errorf("Unexpected contributions: %v", shared.Contributions)
+	}
+
+	bobQuest, _ := bob.GetQuest("rats")
+	if !bobQuest.Objectives[0].Completed || !bobQuest.Objectives[1].Completed {
+		t.Error("Expected progress to propagate to every participant")
+	}
+
+	rewards, err := party.CompleteQuest("alice", "rats", players)
+	if err != nil {
+		t.Fatalf("CompleteQuest failed: %v", err)
+	}
+
+	// Bob contributed 8 of 11 progress and takes the item
+	expectedBob := []QuestReward{{Type: "gold", Value: 73}, {Type: "exp", Value: 22}, {Type: "item", Value: 1, ItemID: "rat_tail"}}
+	expectedAlice := []QuestReward{{Type: "gold", Value: 27}, {Type: "exp", Value: 8}}
+	if !reflect.DeepEqual(rewards["bob"], expectedBob) {
+		t.Errorf("Unexpected rewards for bob: %v", rewards["bob"])
+	}
+	if !reflect.DeepEqual(rewards["alice"], expectedAlice) {
+		t.Errorf("Unexpected rewards for alice: %v", rewards["alice"])
+	}
+
+	if len(bob.GetCompletedQuests()) != 1 {
+		t.Error("Expected the quest to be completed for bob as well")
+	}
+	if _, shared := party.GetSharedQuest("rats"); shared {
+		t.Error("Completed quests should no longer be shared")
+	}
+}
+
+func TestSplitQuestRewards(t *testing.T) {
+	rewards := []QuestReward{{Type: "gold", Value: 10}, {Type: "item", Value: 1, ItemID: "gem"}}
+
+	even := SplitQuestRewards(rewards, []string{"c", "a", "b"}, nil, RewardPolicyEven)
+	if even["a"][0].Value != 4 || even["b"][0].Value != 3 || even["c"][0].Value != 3 {
+		t.Errorf("Unexpected even split: %v", even)
+	}
+	if len(even["a"]) != 2 || even["a"][1].ItemID != "gem" {
+		t.Errorf("Expected the item to go to the first ranked member: %v", even)
+	}
+
+	full := SplitQuestRewards(rewards, []string{"a", "b"}, nil, RewardPolicyFull)
+	if !reflect.DeepEqual(full["a"], rewards) || !reflect.DeepEqual(full["b"], rewards) {
+		t.Errorf("Expected every member to get full rewards: %v", full)
+	}
+
+	// Without recorded contributions the contribution policy splits evenly
+	contribution := SplitQuestRewards(rewards, []string{"a", "b"}, map[string]int{}, RewardPolicyContribution)
+	if contribution["a"][0].Value != 5 || contribution["b"][0].Value != 5 {
+		t.Errorf("Unexpected contribution split: %v", contribution)
+	}
+}
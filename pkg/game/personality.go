@@ -0,0 +1,35 @@
+package game
+
+// BehaviorModifiers adjust how an NPC acts on its turns, derived from the
+// personality the NPC generator gave it. Bravery, greed and honesty are
+// scored from 0 to 1, with 0.5 an unremarkable middle.
+//
+// Example:
+//
+//	npc.Modifiers = &BehaviorModifiers{Bravery: 0.2, RetreatHealth: 0.4, TargetWeakest: true}
+//	if npc.Modifiers.Retreats(npc.HP, npc.MaxHP) {
+//	    // hold back instead of attacking
+//	}
+type BehaviorModifiers struct {
+	Bravery float64 `yaml:"bravery" json:"bravery"` // Willingness to face danger
+	Greed   float64 `yaml:"greed" json:"greed"`     // Desire for wealth
+	Honesty float64 `yaml:"honesty" json:"honesty"` // Tendency to tell the truth
+
+	// RetreatHealth is the fraction of maximum hit points below which the
+	// NPC holds back from attacking, 0 to fight to the end
+	RetreatHealth float64 `yaml:"retreat_health" json:"retreat_health"`
+	// TargetWeakest makes the NPC attack the weakest opponent rather than
+	// the nearest
+	TargetWeakest bool `yaml:"target_weakest" json:"target_weakest"`
+	// Bribable NPCs can be talked round with gold
+	Bribable bool `yaml:"bribable" json:"bribable"`
+}
+
+// Retreats reports whether an NPC with these modifiers and hp of maxHP hit
+// points is hurt badly enough to hold back. A nil receiver never retreats.
+func (m *BehaviorModifiers) Retreats(hp, maxHP int) bool {
+	if m == nil || maxHP <= 0 || m.RetreatHealth <= 0 {
+		return false
+	}
+	return float64(hp) < m.RetreatHealth*float64(maxHP)
+}
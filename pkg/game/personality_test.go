@@ -0,0 +1,20 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBehaviorModifiers_Retreats(t *testing.T) {
+	coward := &BehaviorModifiers{Bravery: 0.1, RetreatHealth: 0.5}
+	assert.False(t, coward.Retreats(10, 20), "at half health it still fights")
+	assert.True(t, coward.Retreats(9, 20))
+
+	hero := &BehaviorModifiers{Bravery: 1}
+	assert.False(t, hero.Retreats(1, 20), "fights to the end")
+
+	var none *BehaviorModifiers
+	assert.False(t, none.Retreats(1, 20))
+	assert.False(t, coward.Retreats(0, 0), "no hit points to lose")
+}
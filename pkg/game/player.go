@@ -2,6 +2,7 @@ package game
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/sirupsen/logrus"
 )
@@ -21,7 +22,10 @@ import (
 //   - Level: Current experience level of the player (1 or greater)
 //   - Experience: Total experience points accumulated
 //   - QuestLog: Slice of active and completed quests
+//   - QuestChains: Multi-quest arcs whose quests unlock as earlier ones end
+//   - Classes: Per-class levels of multi- and dual-classed players
 //   - KnownSpells: Slice of spells the player has learned and can cast
+//   - Memorized: Spells prepared in the player's spell slots
 //
 // Related types:
 //   - Character: Base character attributes
@@ -30,10 +34,16 @@ import (
 //   - Spell: Spell structure
 type Player struct {
 	Character   `yaml:",inline"` // Base character attributes (includes Class)
-	Level       int              `yaml:"player_level"`      // Current experience level
-	Experience  int64            `yaml:"player_experience"` // Total experience points (int64 to prevent overflow)
-	QuestLog    []Quest          `yaml:"player_quests"`     // Active and completed quests
-	KnownSpells []Spell          `yaml:"player_spells"`     // Learned/available spells
+	Level       int              `yaml:"player_level"`                  // Current experience level
+	Experience  int64            `yaml:"player_experience"`             // Total experience points (int64 to prevent overflow)
+	QuestLog    []Quest          `yaml:"player_quests"`                 // Active and completed quests
+	KnownSpells []Spell          `yaml:"player_spells"`                 // Learned/available spells
+	Reputation  map[string]int   `yaml:"player_reputation,omitempty"`   // Faction ID to standing
+	QuestChains []QuestChain     `yaml:"player_quest_chains,omitempty"` // Multi-quest arcs being tracked
+	Classes     []ClassLevel     `yaml:"player_classes,omitempty"`      // Per-class progress when multi- or dual-classed
+	Memorized   []MemorizedSpell `yaml:"player_memorized,omitempty"`    // Spells prepared in spell slots
+
+	ProficiencySlots int `yaml:"player_proficiency_slots,omitempty"` // Unspent weapon proficiency slots
 }
 
 // GetHP returns the player's current hit points.
@@ -232,8 +242,9 @@ func (p *Player) Clone() *Player {
 	}
 
 	clone := &Player{
-		Level:      p.Level,
-		Experience: p.Experience,
+		Level:            p.Level,
+		Experience:       p.Experience,
+		ProficiencySlots: p.ProficiencySlots,
 	}
 
 	// Clone base Character data
@@ -247,6 +258,17 @@ func (p *Player) Clone() *Player {
 	clone.KnownSpells = make([]Spell, len(p.KnownSpells))
 	copy(clone.KnownSpells, p.KnownSpells)
 
+	clone.Reputation = copyIntMap(p.Reputation)
+	clone.Classes = slices.Clone(p.Classes)
+	clone.Memorized = slices.Clone(p.Memorized)
+
+	if p.QuestChains != nil {
+		clone.QuestChains = make([]QuestChain, len(p.QuestChains))
+		for i, chain := range p.QuestChains {
+			clone.QuestChains[i] = chain.clone()
+		}
+	}
+
 	return clone
 }
 
@@ -304,7 +326,11 @@ type PlayerProgressData struct {
 //
 // Related:
 //   - calculateLevel(): Used to determine if player should level up
+//   - ActiveRuleset(): Its XP thresholds replace calculateLevel when set
 //   - levelUp(): Called when experience gain triggers a level increase
+//   - ManualLevelUp(): When set, earned levels wait for a LevelUpService
+//   - addClassExperience(): Splits experience between the classes of
+//     multi- and dual-classed players
 func (p *Player) AddExperience(exp int64) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -319,9 +345,21 @@ func (p *Player) AddExperience(exp int64) error {
 	}
 
 	p.Experience += exp
+	if len(p.Classes) > 0 {
+		return p.addClassExperience(exp)
+	}
+
+	// Earned levels wait for training when level-ups are manual
+	if ManualLevelUp() {
+		return nil
+	}
 
 	// Check for level up
-	if newLevel := calculateLevel(p.Experience); newLevel > p.Level {
+	newLevel := calculateLevel(p.Experience)
+	if rules := ActiveRuleset(); rules != nil {
+		newLevel = rules.LevelForExperience(p.Class, p.Experience)
+	}
+	if newLevel > p.Level {
 		return p.levelUp(newLevel)
 	}
 
@@ -353,6 +391,11 @@ func (p *Player) levelUp(newLevel int) error {
 	p.MaxHP += healthGain
 	p.HP += healthGain
 
+	p.THAC0 = rulesetTHAC0(p.Class, newLevel, p.THAC0)
+	if adv, ok := classAdvancements[p.Class]; ok {
+		p.ProficiencySlots += newLevel/adv.profLevels - oldLevel/adv.profLevels
+	}
+
 	// Update action points based on new level and dexterity
 	newMaxActionPoints := calculateMaxActionPoints(newLevel, p.Character.Dexterity)
 	p.Character.MaxActionPoints = newMaxActionPoints
@@ -462,8 +505,9 @@ func (p *Player) CompleteQuest(questID string) ([]QuestReward, error) {
 				}
 			}
 
-			// Mark quest as completed
+			// Mark quest as completed and unlock what it leads to
 			p.QuestLog[i].Status = QuestCompleted
+			p.advanceQuestChainsLocked(questID)
 
 			return quest.Rewards, nil
 		}
@@ -556,8 +600,9 @@ func (p *Player) FailQuest(questID string) error {
 				return fmt.Errorf("quest %s is already failed", questID)
 			}
 
-			// Mark quest as failed
+			// Mark quest as failed, along with chain quests that needed it
 			p.QuestLog[i].Status = QuestFailed
+			p.advanceQuestChainsLocked(questID)
 			return nil
 		}
 	}
@@ -652,18 +697,44 @@ func (p *Player) GetQuestLog() []Quest {
 	return result
 }
 
+// removeQuest drops a quest from the quest log, undoing StartQuest.
+func (p *Player) removeQuest(questID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if i := p.questIndexLocked(questID); i >= 0 {
+		p.QuestLog = append(p.QuestLog[:i], p.QuestLog[i+1:]...)
+	}
+}
+
+// GetReputation returns the player's standing with a faction, 0 if the
+// player has none.
+func (p *Player) GetReputation(factionID string) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.Reputation[factionID]
+}
+
+// AdjustReputation changes the player's standing with a faction by change
+// and returns the new standing.
+func (p *Player) AdjustReputation(factionID string, change int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Reputation == nil {
+		p.Reputation = make(map[string]int)
+	}
+	p.Reputation[factionID] += change
+	return p.Reputation[factionID]
+}
+
 // KnowsSpell checks if the player has learned a specific spell
 // Returns true if the spell is in the player's KnownSpells list
 func (p *Player) KnowsSpell(spellID string) bool {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-
-	for _, spell := range p.KnownSpells {
-		if spell.ID == spellID {
-			return true
-		}
-	}
-	return false
+	return p.knowsSpell(spellID)
 }
 
 // LearnSpell adds a new spell to the player's known spells if they don't already know it
@@ -684,9 +755,9 @@ func (p *Player) LearnSpell(spell Spell) error {
 		return fmt.Errorf("class %s cannot cast spells", p.Class.String())
 	}
 
-	// Check if player's level is sufficient for the spell
-	if p.Level < spell.Level {
-		return fmt.Errorf("player level %d insufficient for spell level %d", p.Level, spell.Level)
+	// Check if player's caster level is sufficient for the spell
+	if level := p.casterLevel(); level < spell.Level {
+		return fmt.Errorf("player level %d insufficient for spell level %d", level, spell.Level)
 	}
 
 	// Add the spell to known spells
@@ -694,19 +765,13 @@ func (p *Player) LearnSpell(spell Spell) error {
 	return nil
 }
 
-// canCastSpells determines if the player's class can cast spells
-// Based on D&D-style classes where only certain classes are spellcasters
+// canCastSpells determines if any of the player's active classes can cast
+// spells at the level reached in it
 func (p *Player) canCastSpells() bool {
-	switch p.Class {
-	case ClassMage:
-		return true
-	case ClassCleric:
-		return true
-	case ClassPaladin:
-		return p.Level >= 9 // Paladins get spells at level 9
-	case ClassRanger:
-		return p.Level >= 8 // Rangers get spells at level 8
-	default:
-		return false
+	for _, class := range p.activeClasses() {
+		if classCanCast(class, p.classLevel(class)) {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,66 @@
+package game
+
+import "slices"
+
+// Portrait describes how clients draw a character's portrait and map token.
+// It holds descriptors rather than images: the same portrait always renders
+// as the same avatar, and a portrait generated again from its Seed differs
+// only where the character itself has changed, such as its equipment.
+//
+// Fields:
+//   - Seed: Seed the portrait was generated from
+//   - Archetype: Base figure to draw, e.g. "warrior" or "robed_caster"
+//   - Palette: Colors of the figure
+//   - Tags: Visual features such as "scarred" or "veteran"
+//   - Silhouette: Outline hints from the character's gear, e.g. "helm" or "shield"
+//   - Token: How the character's map token is framed
+type Portrait struct {
+	Seed       int64           `yaml:"portrait_seed" json:"seed"`
+	Archetype  string          `yaml:"portrait_archetype" json:"archetype"`
+	Palette    PortraitPalette `yaml:"portrait_palette" json:"palette"`
+	Tags       []string        `yaml:"portrait_tags,omitempty" json:"tags,omitempty"`
+	Silhouette []string        `yaml:"portrait_silhouette,omitempty" json:"silhouette,omitempty"`
+	Token      TokenStyle      `yaml:"portrait_token" json:"token"`
+}
+
+// PortraitPalette holds the colors of a portrait as "#rrggbb" strings.
+type PortraitPalette struct {
+	Skin      string `yaml:"palette_skin" json:"skin"`           // Skin or hide
+	Hair      string `yaml:"palette_hair" json:"hair"`           // Hair, fur or feathers
+	Eyes      string `yaml:"palette_eyes" json:"eyes"`           // Eye color
+	Primary   string `yaml:"palette_primary" json:"primary"`     // Main clothing color
+	Secondary string `yaml:"palette_secondary" json:"secondary"` // Trim and accent color
+}
+
+// TokenStyle describes the frame of a character's map token.
+type TokenStyle struct {
+	Shape  string `yaml:"token_shape" json:"shape"`   // "circle", "square" or "hex"
+	Border string `yaml:"token_border" json:"border"` // Border color as "#rrggbb"
+	Size   int    `yaml:"token_size" json:"size"`     // Width in tiles
+}
+
+// Clone returns a deep copy of the portrait, or nil for a nil portrait.
+func (p *Portrait) Clone() *Portrait {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	clone.Tags = slices.Clone(p.Tags)
+	clone.Silhouette = slices.Clone(p.Silhouette)
+	return &clone
+}
+
+// GetPortrait returns a copy of the character's portrait, or nil if it has
+// none yet.
+func (c *Character) GetPortrait() *Portrait {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Portrait.Clone()
+}
+
+// SetPortrait replaces the character's portrait.
+func (c *Character) SetPortrait(portrait *Portrait) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Portrait = portrait.Clone()
+}
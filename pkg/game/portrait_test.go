@@ -0,0 +1,36 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCharacter_PortraitIsCopied(t *testing.T) {
+	portrait := &Portrait{
+		Seed:       3,
+		Archetype:  "rogue",
+		Tags:       []string{"scarred"},
+		Silhouette: []string{"dagger"},
+		Token:      TokenStyle{Shape: "circle", Border: "#1a1a1a", Size: 1},
+	}
+	character := &Character{ID: "thief"}
+	if character.GetPortrait() != nil {
+		t.Fatal("new character should have no portrait")
+	}
+
+	character.SetPortrait(portrait)
+	portrait.Tags[0] = "changed"
+	got := character.GetPortrait()
+	if got.Tags[0] != "scarred" {
+		t.Errorf("SetPortrait kept the caller's tags: %v", got.Tags)
+	}
+
+	got.Silhouette[0] = "changed"
+	clone := character.Clone()
+	if !reflect.DeepEqual(clone.GetPortrait(), character.GetPortrait()) {
+		t.Errorf("Clone portrait = %+v, want %+v", clone.Portrait, character.Portrait)
+	}
+	if clone.Portrait == character.Portrait {
+		t.Error("Clone shares the portrait with the original")
+	}
+}
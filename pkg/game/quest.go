@@ -23,6 +23,30 @@ type Quest struct {
 	Status      QuestStatus      `yaml:"quest_status"`      // Current quest state
 	Objectives  []QuestObjective `yaml:"quest_objectives"`  // List of quest goals
 	Rewards     []QuestReward    `yaml:"quest_rewards"`     // Rewards for completion
+
+	// Consequences change the world when the quest is completed or failed
+	Consequences []QuestConsequence `yaml:"quest_consequences,omitempty"`
+
+	// GiverID is the NPC offering the quest. Completing or failing it
+	// changes how the giver feels about the player, and the giver only
+	// offers it to players it trusts at least MinGiverTrust.
+	GiverID       string `yaml:"quest_giver_id,omitempty"`
+	MinGiverTrust int    `yaml:"quest_min_giver_trust,omitempty"`
+
+	// TimeLimitHours, when set, gives the player that many game hours from
+	// accepting the quest to complete it. Deadline is the game tick the
+	// time runs out, set by UpdateQuestDeadlines.
+	TimeLimitHours int   `yaml:"quest_time_limit_hours,omitempty"`
+	Deadline       int64 `yaml:"quest_deadline,omitempty"`
+	DeadlineWarned bool  `yaml:"quest_deadline_warned,omitempty"`
+}
+
+// clone returns a copy of the quest that shares no slices with it.
+func (q Quest) clone() Quest {
+	q.Objectives = append([]QuestObjective(nil), q.Objectives...)
+	q.Rewards = append([]QuestReward(nil), q.Rewards...)
+	q.Consequences = append([]QuestConsequence(nil), q.Consequences...)
+	return q
 }
 
 // QuestStatus represents the current state of a quest in the game.
@@ -63,6 +87,12 @@ type QuestObjective struct {
 	Progress    int    `yaml:"objective_progress"`    // Current completion amount
 	Required    int    `yaml:"objective_required"`    // Amount needed for completion
 	Completed   bool   `yaml:"objective_completed"`   // Whether objective is done
+
+	// TimeLimitHours, when set, gives the player that many game hours from
+	// accepting the quest to finish the objective, see Quest.TimeLimitHours
+	TimeLimitHours int   `yaml:"objective_time_limit_hours,omitempty"`
+	Deadline       int64 `yaml:"objective_deadline,omitempty"`
+	DeadlineWarned bool  `yaml:"objective_deadline_warned,omitempty"`
 }
 
 // QuestReward represents a reward that can be awarded to a player for completing a quest.
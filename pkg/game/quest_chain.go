@@ -0,0 +1,222 @@
+package game
+
+import "fmt"
+
+// QuestChain is a multi-quest story arc. Its steps form a dependency graph:
+// each step unlocks once its prerequisites end with the required outcomes,
+// so a chain can branch on whether an earlier quest succeeded or failed.
+// The same NPCs recur across the steps of a chain.
+//
+// Fields:
+//   - ID: Unique identifier for the chain
+//   - Title: Display name of the story arc
+//   - NPCs: Shared cast of quest givers appearing throughout the chain
+//   - Steps: Quests of the chain, listed so prerequisites come first
+type QuestChain struct {
+	ID    string           `yaml:"chain_id"`    // Unique chain identifier
+	Title string           `yaml:"chain_title"` // Display title of the arc
+	NPCs  []string         `yaml:"chain_npcs"`  // NPCs shared across the chain
+	Steps []QuestChainStep `yaml:"chain_steps"` // Quests in dependency order
+}
+
+// QuestChainStep is one quest of a chain and the conditions that unlock it.
+// A step without prerequisites starts as soon as the chain does.
+type QuestChainStep struct {
+	Quest         Quest               `yaml:"step_quest"`         // Quest started when unlocked
+	Giver         string              `yaml:"step_giver"`         // NPC who offers the quest
+	Prerequisites []QuestPrerequisite `yaml:"step_prerequisites"` // All must be met to unlock
+}
+
+// QuestPrerequisite requires an earlier quest of the chain to end with a
+// given outcome, either QuestCompleted or QuestFailed.
+type QuestPrerequisite struct {
+	QuestID string      `yaml:"prerequisite_quest_id"` // Earlier quest in the chain
+	Outcome QuestStatus `yaml:"prerequisite_outcome"`  // Required final status
+}
+
+// Validate checks that quest IDs are unique, that every prerequisite refers
+// to an earlier step with a final outcome, and that at least one step can
+// start the chain.
+func (qc *QuestChain) Validate() error {
+	if qc.ID == "" {
+		return fmt.Errorf("quest chain ID cannot be empty")
+	}
+	if len(qc.Steps) == 0 {
+		return fmt.Errorf("quest chain %s has no steps", qc.ID)
+	}
+
+	seen := make(map[string]bool, len(qc.Steps))
+	roots := 0
+	for _, step := range qc.Steps {
+		if step.Quest.ID == "" {
+			return fmt.Errorf("quest chain %s has a quest without an ID", qc.ID)
+		}
+		if seen[step.Quest.ID] {
+			return fmt.Errorf("quest chain %s contains quest %s twice", qc.ID, step.Quest.ID)
+		}
+		for _, prerequisite := range step.Prerequisites {
+			// Requiring earlier steps keeps the graph acyclic
+			if !seen[prerequisite.QuestID] {
+				return fmt.Errorf("quest %s requires %s, which is not an earlier step of chain %s", step.Quest.ID, prerequisite.QuestID, qc.ID)
+			}
+			if prerequisite.Outcome != QuestCompleted && prerequisite.Outcome != QuestFailed {
+				return fmt.Errorf("quest %s requires %s to be completed or failed", step.Quest.ID, prerequisite.QuestID)
+			}
+		}
+		if len(step.Prerequisites) == 0 {
+			roots++
+		}
+		seen[step.Quest.ID] = true
+	}
+
+	if roots == 0 {
+		return fmt.Errorf("quest chain %s has no starting quest", qc.ID)
+	}
+	return nil
+}
+
+// Contains reports whether questID is a step of the chain.
+func (qc *QuestChain) Contains(questID string) bool {
+	for _, step := range qc.Steps {
+		if step.Quest.ID == questID {
+			return true
+		}
+	}
+	return false
+}
+
+// clone returns a copy of the chain that shares no slices with it.
+func (qc QuestChain) clone() QuestChain {
+	qc.NPCs = append([]string(nil), qc.NPCs...)
+	steps := make([]QuestChainStep, len(qc.Steps))
+	for i, step := range qc.Steps {
+		step.Quest = step.Quest.clone()
+		step.Prerequisites = append([]QuestPrerequisite(nil), step.Prerequisites...)
+		steps[i] = step
+	}
+	qc.Steps = steps
+	return qc
+}
+
+// StartQuestChain adds a quest chain to the player and starts its opening
+// quests. Later quests join the quest log as their prerequisites resolve
+// through CompleteQuest and FailQuest.
+//
+// Parameters:
+//   - chain: The QuestChain to track
+//
+// Returns:
+//   - error: Returns error if the chain is invalid, already tracked, or one of
+//     its quests is already in the quest log
+func (p *Player) StartQuestChain(chain QuestChain) error {
+	if err := chain.Validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, existing := range p.QuestChains {
+		if existing.ID == chain.ID {
+			return fmt.Errorf("quest chain %s is already being tracked", chain.ID)
+		}
+	}
+	for _, step := range chain.Steps {
+		if p.questIndexLocked(step.Quest.ID) >= 0 {
+			return fmt.Errorf("quest %s already exists in quest log", step.Quest.ID)
+		}
+	}
+
+	chain = chain.clone()
+	p.QuestChains = append(p.QuestChains, chain)
+	p.advanceQuestChainLocked(&p.QuestChains[len(p.QuestChains)-1])
+	return nil
+}
+
+// GetQuestChain returns a copy of a tracked quest chain.
+//
+// Parameters:
+//   - chainID: The unique identifier of the chain
+//
+// Returns:
+//   - *QuestChain: Copy of the chain
+//   - error: Returns error if the chain is not being tracked
+func (p *Player) GetQuestChain(chainID string) (*QuestChain, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, chain := range p.QuestChains {
+		if chain.ID == chainID {
+			chainCopy := chain.clone()
+			return &chainCopy, nil
+		}
+	}
+	return nil, fmt.Errorf("quest chain %s not found", chainID)
+}
+
+// advanceQuestChainsLocked re-evaluates every chain containing questID after
+// that quest ended. The caller must hold p.mu.
+func (p *Player) advanceQuestChainsLocked(questID string) {
+	for i := range p.QuestChains {
+		if p.QuestChains[i].Contains(questID) {
+			p.advanceQuestChainLocked(&p.QuestChains[i])
+		}
+	}
+}
+
+// advanceQuestChainLocked adds every step of chain whose prerequisites have
+// resolved to the quest log: as active when all were met, or as failed when
+// one can no longer be met. Failures cascade to the steps that depend on
+// them. The caller must hold p.mu.
+func (p *Player) advanceQuestChainLocked(chain *QuestChain) {
+	for changed := true; changed; {
+		changed = false
+		for _, step := range chain.Steps {
+			if p.questIndexLocked(step.Quest.ID) >= 0 {
+				continue
+			}
+
+			status, resolved := p.prerequisiteStatusLocked(step.Prerequisites)
+			if !resolved {
+				continue
+			}
+			quest := step.Quest
+			quest.Status = status
+			p.QuestLog = append(p.QuestLog, quest)
+			changed = true
+		}
+	}
+}
+
+// prerequisiteStatusLocked returns QuestActive if every prerequisite is met
+// and QuestFailed if any can no longer be met. resolved is false while a
+// prerequisite quest is still unfinished. The caller must hold p.mu.
+func (p *Player) prerequisiteStatusLocked(prerequisites []QuestPrerequisite) (status QuestStatus, resolved bool) {
+	resolved = true
+	for _, prerequisite := range prerequisites {
+		i := p.questIndexLocked(prerequisite.QuestID)
+		if i < 0 {
+			resolved = false
+			continue
+		}
+		switch outcome := p.QuestLog[i].Status; {
+		case outcome == prerequisite.Outcome:
+		case outcome == QuestCompleted || outcome == QuestFailed:
+			return QuestFailed, true
+		default:
+			resolved = false
+		}
+	}
+	return QuestActive, resolved
+}
+
+// questIndexLocked returns the index of questID in the quest log, or -1.
+// The caller must hold p.mu.
+func (p *Player) questIndexLocked(questID string) int {
+	for i, quest := range p.QuestLog {
+		if quest.ID == questID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,124 @@
+package game
+
+import "testing"
+
+// newTestQuestChain builds a chain where "intro" leads to "main" on success
+// and to "rescue" on failure, and "finale" follows "main".
+func newTestQuestChain() QuestChain {
+	objective := []QuestObjective{{Description: "Do it", Required: 1}}
+	return QuestChain{
+		ID:    "chain",
+		Title: "Test Chain",
+		NPCs:  []string{"Elder"},
+		Steps: []QuestChainStep{
+			{Quest: Quest{ID: "intro", Objectives: objective}},
+			{Quest: Quest{ID: "main", Objectives: objective}, Prerequisites: []QuestPrerequisite{{QuestID: "intro", Outcome: QuestCompleted}}},
+			{Quest: Quest{ID: "rescue", Objectives: objective}, Prerequisites: []QuestPrerequisite{{QuestID: "intro", Outcome: QuestFailed}}},
+			{Quest: Quest{ID: "finale", Objectives: objective}, Prerequisites: []QuestPrerequisite{{QuestID: "main", Outcome: QuestCompleted}}},
+		},
+	}
+}
+
+func questStatuses(p *Player) map[string]QuestStatus {
+	statuses := make(map[string]QuestStatus)
+	for _, quest := range p.GetQuestLog() {
+		statuses[quest.ID] = quest.Status
+	}
+	return statuses
+}
+
+func TestQuestChain_Validate(t *testing.T) {
+	valid := newTestQuestChain()
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid chain, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*QuestChain)
+	}{
+		{"missing ID", func(c *QuestChain) { c.ID = "" }},
+		{"no steps", func(c *QuestChain) { c.Steps = nil }},
+		{"duplicate quest", func(c *QuestChain) { c.Steps[1].Quest.ID = "intro" }},
+		{"forward prerequisite", func(c *QuestChain) {
+			c.Steps[1].Prerequisites = []QuestPrerequisite{{QuestID: "finale", Outcome: QuestCompleted}}
+		}},
+		{"unfinished outcome", func(c *QuestChain) { c.Steps[1].Prerequisites[0].Outcome = QuestActive }},
+		{"no starting quest", func(c *QuestChain) {
+			c.Steps = c.Steps[1:]
+			c.Steps[0].Prerequisites = []QuestPrerequisite{{QuestID: "main", Outcome: QuestCompleted}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newTestQuestChain()
+			tt.mutate(&chain)
+			if err := chain.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestPlayer_QuestChainUnlocksOnCompletion(t *testing.T) {
+	player := &Player{}
+	if err := player.StartQuestChain(newTestQuestChain()); err != nil {
+		t.Fatalf("StartQuestChain failed: %v", err)
+	}
+
+	statuses := questStatuses(player)
+	if len(statuses) != 1 || statuses["intro"] != QuestActive {
+		t.Fatalf("expected only intro to be active, got %v", statuses)
+	}
+
+	if err := player.UpdateQuestObjective("intro", 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := player.CompleteQuest("intro"); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses = questStatuses(player)
+	if statuses["main"] != QuestActive {
+		t.Errorf("expected main to unlock, got %v", statuses)
+	}
+	if statuses["rescue"] != QuestFailed {
+		t.Errorf("expected the failure branch to close, got %v", statuses)
+	}
+	if _, exists := statuses["finale"]; exists {
+		t.Error("finale should wait for main")
+	}
+
+	if err := player.StartQuestChain(newTestQuestChain()); err == nil {
+		t.Error("expected an error tracking the same chain twice")
+	}
+}
+
+func TestPlayer_QuestChainFailureCascades(t *testing.T) {
+	player := &Player{}
+	if err := player.StartQuestChain(newTestQuestChain()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := player.FailQuest("intro"); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := questStatuses(player)
+	if statuses["rescue"] != QuestActive {
+		t.Errorf("expected the failure branch to unlock, got %v", statuses)
+	}
+	if statuses["main"] != QuestFailed || statuses["finale"] != QuestFailed {
+		t.Errorf("expected downstream quests to fail, got %v", statuses)
+	}
+
+	chain, err := player.GetQuestChain("chain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.Steps[0].Quest.ID = "changed"
+	if original, _ := player.GetQuestChain("chain"); original.Steps[0].Quest.ID != "intro" {
+		t.Error("GetQuestChain should return a copy")
+	}
+}
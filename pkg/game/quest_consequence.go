@@ -0,0 +1,273 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ConsequenceType identifies the world mutation a quest consequence makes.
+type ConsequenceType string
+
+const (
+	// ConsequenceNPCDeath kills the target NPC
+	ConsequenceNPCDeath ConsequenceType = "npc_death"
+	// ConsequenceReputation changes the player's standing with the target
+	// faction by Amount
+	ConsequenceReputation ConsequenceType = "reputation"
+	// ConsequenceAreaLockout locks the target area, a level or room ID, so
+	// no player can move into it
+	ConsequenceAreaLockout ConsequenceType = "area_lockout"
+	// ConsequenceUnlockQuest adds the FollowUp quest to the player's quest log
+	ConsequenceUnlockQuest ConsequenceType = "unlock_quest"
+)
+
+// QuestConsequence is a change to the world that a quest makes when it ends
+// with the outcome On, either QuestCompleted or QuestFailed. The
+// consequences of an outcome execute together through ApplyQuestOutcome.
+//
+// Fields:
+//   - Type: The mutation to make
+//   - On: The outcome that triggers it
+//   - Target: NPC ID, faction ID or area ID, depending on Type
+//   - Amount: Reputation change of a ConsequenceReputation
+//   - FollowUp: Quest added by a ConsequenceUnlockQuest
+//   - Description: Text shown to the player
+type QuestConsequence struct {
+	Type        ConsequenceType `yaml:"consequence_type" json:"type"`
+	On          QuestStatus     `yaml:"consequence_on" json:"on"`
+	Target      string          `yaml:"consequence_target,omitempty" json:"target,omitempty"`
+	Amount      int             `yaml:"consequence_amount,omitempty" json:"amount,omitempty"`
+	FollowUp    *Quest          `yaml:"consequence_follow_up,omitempty" json:"follow_up,omitempty"`
+	Description string          `yaml:"consequence_description,omitempty" json:"description,omitempty"`
+}
+
+// Validate checks that the consequence is complete for its type.
+func (c QuestConsequence) Validate() error {
+	if c.On != QuestCompleted && c.On != QuestFailed {
+		return fmt.Errorf("%s consequence must trigger on completion or failure", c.Type)
+	}
+
+	switch c.Type {
+	case ConsequenceNPCDeath, ConsequenceAreaLockout:
+		if c.Target == "" {
+			return fmt.Errorf("%s consequence has no target", c.Type)
+		}
+	case ConsequenceReputation:
+		if c.Target == "" {
+			return fmt.Errorf("reputation consequence has no faction")
+		}
+		if c.Amount == 0 {
+			return fmt.Errorf("reputation consequence for %s changes nothing", c.Target)
+		}
+	case ConsequenceUnlockQuest:
+		if c.FollowUp == nil || c.FollowUp.ID == "" {
+			return fmt.Errorf("unlock_quest consequence has no follow-up quest")
+		}
+	default:
+		return fmt.Errorf("unknown quest consequence type %q", c.Type)
+	}
+	return nil
+}
+
+// ConsequencesFor returns the consequences the quest has when it ends with
+// outcome.
+func (q *Quest) ConsequencesFor(outcome QuestStatus) []QuestConsequence {
+	var consequences []QuestConsequence
+	for _, consequence := range q.Consequences {
+		if consequence.On == outcome {
+			consequences = append(consequences, consequence)
+		}
+	}
+	return consequences
+}
+
+// ApplyQuestOutcome ends a quest for player with outcome and executes the
+// quest's consequences for that outcome as one transaction. commit records
+// the outcome itself, typically through Player.CompleteQuest or FailQuest.
+// If a consequence or commit fails, the consequences already executed are
+// undone and the error is returned. Once the outcome is recorded, the
+// quest's giver, if any, remembers it in the world's relationship graph.
+//
+// Consequences that have already come about, such as the death of an NPC
+// who is already dead, are skipped.
+//
+// Parameters:
+//   - player: The player ending the quest
+//   - quest: The quest as it is in the player's log; nil executes only commit
+//   - outcome: QuestCompleted or QuestFailed
+//   - commit: Records the outcome in the quest log
+//
+// Returns:
+//   - []QuestConsequence: The consequences that were executed
+//   - error: The error of the failing consequence or of commit
+func (w *World) ApplyQuestOutcome(player *Player, quest *Quest, outcome QuestStatus, commit func() error) ([]QuestConsequence, error) {
+	if quest == nil {
+		return nil, commit()
+	}
+
+	var undo []func()
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	var applied []QuestConsequence
+	for _, consequence := range quest.ConsequencesFor(outcome) {
+		revert, err := w.applyQuestConsequence(player, quest.ID, consequence)
+		if err != nil {
+			rollback()
+			return nil, fmt.Errorf("quest %s %s consequence failed: %w", quest.ID, consequence.Type, err)
+		}
+		if revert != nil {
+			undo = append(undo, revert)
+			applied = append(applied, consequence)
+		}
+	}
+
+	if err := commit(); err != nil {
+		rollback()
+		return nil, err
+	}
+	w.recordQuestGiver(player, quest, outcome)
+	return applied, nil
+}
+
+// recordQuestGiver changes how the quest's giver, if it has one, feels
+// about the player who completed or failed it
+func (w *World) recordQuestGiver(player *Player, quest *Quest, outcome QuestStatus) {
+	if quest.GiverID == "" || player == nil {
+		return
+	}
+	event := RelationshipQuestFailed
+	if outcome == QuestCompleted {
+		event = RelationshipQuestCompleted
+	}
+	if _, err := w.RelationshipGraph().Record(quest.GiverID, player.GetID(), event); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "recordQuestGiver",
+			"quest_id": quest.ID,
+			"giver_id": quest.GiverID,
+		}).WithError(err).Warn("failed to record quest outcome with its giver")
+	}
+}
+
+// applyQuestConsequence executes one consequence and returns the function
+// that undoes it, or nil if there was nothing to do.
+func (w *World) applyQuestConsequence(player *Player, questID string, consequence QuestConsequence) (func(), error) {
+	if err := consequence.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch consequence.Type {
+	case ConsequenceNPCDeath:
+		npc := w.findNPC(consequence.Target)
+		if npc == nil {
+			return nil, fmt.Errorf("NPC %s not found", consequence.Target)
+		}
+		health, active := npc.GetHealth(), npc.IsActive()
+		if health <= 0 {
+			return nil, nil
+		}
+		npc.SetHealth(0)
+		npc.SetActive(false)
+		return func() {
+			npc.SetHealth(health)
+			npc.SetActive(active)
+		}, nil
+
+	case ConsequenceReputation:
+		player.AdjustReputation(consequence.Target, consequence.Amount)
+		return func() { player.AdjustReputation(consequence.Target, -consequence.Amount) }, nil
+
+	case ConsequenceAreaLockout:
+		if !w.LockArea(consequence.Target, questID) {
+			return nil, nil
+		}
+		return func() { w.UnlockArea(consequence.Target) }, nil
+
+	default: // ConsequenceUnlockQuest
+		followUp := consequence.FollowUp.clone()
+		if _, err := player.GetQuest(followUp.ID); err == nil {
+			return nil, nil
+		}
+		if err := player.StartQuest(followUp); err != nil {
+			return nil, err
+		}
+		return func() { player.removeQuest(followUp.ID) }, nil
+	}
+}
+
+// findNPC returns the NPC with id, or nil if the world has none.
+func (w *World) findNPC(id string) *NPC {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if npc, ok := w.NPCs[id]; ok {
+		return npc
+	}
+	npc, _ := w.Objects[id].(*NPC)
+	return npc
+}
+
+// LockArea locks an area, a level or room ID, so that no player can move
+// into it. questID records the quest that caused the lockout. It returns
+// false if the area was already locked.
+func (w *World) LockArea(areaID, questID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, locked := w.LockedAreas[areaID]; locked {
+		return false
+	}
+	if w.LockedAreas == nil {
+		w.LockedAreas = make(map[string]string)
+	}
+	w.LockedAreas[areaID] = questID
+	return true
+}
+
+// UnlockArea reopens a locked area. It returns false if the area was not
+// locked.
+func (w *World) UnlockArea(areaID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, locked := w.LockedAreas[areaID]; !locked {
+		return false
+	}
+	delete(w.LockedAreas, areaID)
+	return true
+}
+
+// IsAreaLocked reports whether an area is locked.
+func (w *World) IsAreaLocked(areaID string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	_, locked := w.LockedAreas[areaID]
+	return locked
+}
+
+// lockedAreaAt returns the locked level or room containing pos, if any.
+func (w *World) lockedAreaAt(pos Position) (string, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(w.LockedAreas) == 0 || pos.Level < 0 || pos.Level >= len(w.Levels) {
+		return "", false
+	}
+	level := &w.Levels[pos.Level]
+	if _, locked := w.LockedAreas[level.ID]; locked && level.ID != "" {
+		return level.ID, true
+	}
+	if pos.Y < 0 || pos.Y >= len(level.Tiles) || pos.X < 0 || pos.X >= len(level.Tiles[pos.Y]) {
+		return "", false
+	}
+	roomID, _ := level.Tiles[pos.Y][pos.X].Properties["room_id"].(string)
+	if _, locked := w.LockedAreas[roomID]; locked && roomID != "" {
+		return roomID, true
+	}
+	return "", false
+}
@@ -0,0 +1,192 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// newConsequenceTestWorld returns a world with one living NPC, "elder",
+// and a player holding an active "defend" quest whose objectives are done.
+func newConsequenceTestWorld(consequences ...QuestConsequence) (*World, *Player, *Quest) {
+	world := NewWorld()
+	world.Width, world.Height = 10, 10
+	world.Levels = []Level{{ID: "village", Width: 10, Height: 10}}
+	world.NPCs["elder"] = &NPC{Character: Character{ID: "elder", Name: "Elder", HP: 8, MaxHP: 8}}
+
+	player := &Player{Character: Character{ID: "hero", Name: "Hero"}}
+	quest := Quest{
+		ID:           "defend",
+		Objectives:   []QuestObjective{{Description: "Hold the gate", Required: 1, Progress: 1, Completed: true}},
+		Consequences: consequences,
+	}
+	if err := player.StartQuest(quest); err != nil {
+		panic(err)
+	}
+	started, _ := player.GetQuest("defend")
+	return world, player, started
+}
+
+func TestQuestConsequence_Validate(t *testing.T) {
+	followUp := &Quest{ID: "next"}
+	valid := []QuestConsequence{
+		{Type: ConsequenceNPCDeath, On: QuestFailed, Target: "elder"},
+		{Type: ConsequenceReputation, On: QuestCompleted, Target: "city_watch", Amount: 10},
+		{Type: ConsequenceAreaLockout, On: QuestFailed, Target: "village"},
+		{Type: ConsequenceUnlockQuest, On: QuestCompleted, FollowUp: followUp},
+	}
+	for _, consequence := range valid {
+		if err := consequence.Validate(); err != nil {
+			t.Errorf("%s: unexpected error %v", consequence.Type, err)
+		}
+	}
+
+	invalid := []QuestConsequence{
+		{Type: ConsequenceNPCDeath, On: QuestActive, Target: "elder"},
+		{Type: ConsequenceNPCDeath, On: QuestFailed},
+		{Type: ConsequenceReputation, On: QuestCompleted, Target: "city_watch"},
+		{Type: ConsequenceAreaLockout, On: QuestFailed},
+		{Type: ConsequenceUnlockQuest, On: QuestCompleted},
+		{Type: "earthquake", On: QuestFailed, Target: "village"},
+	}
+	for _, consequence := range invalid {
+		if err := consequence.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", consequence.Type)
+		}
+	}
+}
+
+func TestApplyQuestOutcome_ExecutesConsequencesOfOutcome(t *testing.T) {
+	world, player, quest := newConsequenceTestWorld(
+		QuestConsequence{Type: ConsequenceReputation, On: QuestCompleted, Target: "city_watch", Amount: 15},
+		QuestConsequence{Type: ConsequenceUnlockQuest, On: QuestCompleted, FollowUp: &Quest{
+			ID: "pursue", Objectives: []QuestObjective{{Description: "Chase the raiders", Required: 1}},
+		}},
+		QuestConsequence{Type: ConsequenceNPCDeath, On: QuestFailed, Target: "elder"},
+	)
+
+	applied, err := world.ApplyQuestOutcome(player, quest, QuestCompleted, func() error {
+		_, err := player.CompleteQuest(quest.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ApplyQuestOutcome failed: %v", err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("expected 2 consequences applied, got %d", len(applied))
+	}
+	if got := player.GetReputation("city_watch"); got != 15 {
+		t.Errorf("expected reputation 15, got %d", got)
+	}
+	if followUp, err := player.GetQuest("pursue"); err != nil || followUp.Status != QuestActive {
+		t.Errorf("expected follow-up quest to be active, got %v, %v", followUp, err)
+	}
+	if world.NPCs["elder"].GetHealth() != 8 {
+		t.Error("failure consequences must not run on completion")
+	}
+}
+
+func TestApplyQuestOutcome_FailureLocksAreaAndKillsNPC(t *testing.T) {
+	world, player, quest := newConsequenceTestWorld(
+		QuestConsequence{Type: ConsequenceNPCDeath, On: QuestFailed, Target: "elder"},
+		QuestConsequence{Type: ConsequenceAreaLockout, On: QuestFailed, Target: "village"},
+	)
+
+	applied, err := world.ApplyQuestOutcome(player, quest, QuestFailed, func() error {
+		return player.FailQuest(quest.ID)
+	})
+	if err != nil {
+		t.Fatalf("ApplyQuestOutcome failed: %v", err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("expected 2 consequences applied, got %d", len(applied))
+	}
+	if world.NPCs["elder"].GetHealth() != 0 {
+		t.Error("expected the elder to die")
+	}
+	if !world.IsAreaLocked("village") {
+		t.Fatal("expected the village to be locked")
+	}
+	if err := world.ValidateMove(player, Position{X: 1, Y: 1, Level: 0}); err == nil {
+		t.Error("expected moves into a locked area to be rejected")
+	}
+}
+
+func TestApplyQuestOutcome_RollsBackOnFailure(t *testing.T) {
+	consequences := []QuestConsequence{
+		{Type: ConsequenceReputation, On: QuestFailed, Target: "city_watch", Amount: -20},
+		{Type: ConsequenceAreaLockout, On: QuestFailed, Target: "village"},
+		{Type: ConsequenceUnlockQuest, On: QuestFailed, FollowUp: &Quest{ID: "atone"}},
+		{Type: ConsequenceNPCDeath, On: QuestFailed, Target: "elder"},
+	}
+
+	t.Run("consequence error", func(t *testing.T) {
+		missing := append(append([]QuestConsequence(nil), consequences...),
+			QuestConsequence{Type: ConsequenceNPCDeath, On: QuestFailed, Target: "ghost"})
+		world, player, quest := newConsequenceTestWorld(missing...)
+
+		committed := false
+		_, err := world.ApplyQuestOutcome(player, quest, QuestFailed, func() error {
+			committed = true
+			return nil
+		})
+		if err == nil {
+			t.Fatal("expected error for a missing NPC")
+		}
+		if committed {
+			t.Error("commit must not run after a consequence fails")
+		}
+		assertConsequencesUndone(t, world, player)
+	})
+
+	t.Run("commit error", func(t *testing.T) {
+		world, player, quest := newConsequenceTestWorld(consequences...)
+		commitErr := errors.New("quest log is sealed")
+
+		_, err := world.ApplyQuestOutcome(player, quest, QuestFailed, func() error { return commitErr })
+		if !errors.Is(err, commitErr) {
+			t.Fatalf("expected commit error, got %v", err)
+		}
+		assertConsequencesUndone(t, world, player)
+	})
+}
+
+func assertConsequencesUndone(t *testing.T, world *World, player *Player) {
+	t.Helper()
+	if got := player.GetReputation("city_watch"); got != 0 {
+		t.Errorf("expected reputation restored to 0, got %d", got)
+	}
+	if world.IsAreaLocked("village") {
+		t.Error("expected area lockout undone")
+	}
+	if _, err := player.GetQuest("atone"); err == nil {
+		t.Error("expected follow-up quest removed")
+	}
+	if world.NPCs["elder"].GetHealth() != 8 {
+		t.Error("expected NPC restored")
+	}
+	if quest, _ := player.GetQuest("defend"); quest.Status != QuestActive {
+		t.Errorf("expected quest still active, got %v", quest.Status)
+	}
+}
+
+func TestApplyQuestOutcome_SkipsConsequencesAlreadyInEffect(t *testing.T) {
+	world, player, quest := newConsequenceTestWorld(
+		QuestConsequence{Type: ConsequenceNPCDeath, On: QuestFailed, Target: "elder"},
+		QuestConsequence{Type: ConsequenceAreaLockout, On: QuestFailed, Target: "village"},
+	)
+	world.NPCs["elder"].SetHealth(0)
+	world.LockArea("village", "earlier")
+
+	applied, err := world.ApplyQuestOutcome(player, quest, QuestFailed, func() error {
+		return player.FailQuest(quest.ID)
+	})
+	if err != nil {
+		t.Fatalf("ApplyQuestOutcome failed: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("expected no consequences applied, got %v", applied)
+	}
+	if world.LockedAreas["village"] != "earlier" {
+		t.Error("an existing lockout must keep its quest")
+	}
+}
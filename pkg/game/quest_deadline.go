@@ -0,0 +1,105 @@
+package game
+
+// DeadlineWarningFraction is the share of a time limit left when the
+// player is warned that a quest or objective deadline approaches.
+const DeadlineWarningFraction = 0.25
+
+// DeadlineNoticeKind tells whether a deadline approaches or has passed.
+type DeadlineNoticeKind string
+
+const (
+	// DeadlineWarning reports that a deadline is near
+	DeadlineWarning DeadlineNoticeKind = "warning"
+	// DeadlineExpired reports that a deadline has passed, failing the quest
+	DeadlineExpired DeadlineNoticeKind = "expired"
+)
+
+// QuestDeadlineNotice reports a quest or objective deadline that
+// UpdateQuestDeadlines found approaching or passed.
+type QuestDeadlineNotice struct {
+	QuestID   string             `json:"quest_id"`
+	Objective int                `json:"objective"` // Objective index, -1 for the quest's own deadline
+	Kind      DeadlineNoticeKind `json:"kind"`
+	Deadline  int64              `json:"deadline"` // Game tick the time runs out
+}
+
+// UpdateQuestDeadlines tracks the time limits of the player's active quests
+// and their unfinished objectives against the game clock. A time limit
+// without a deadline starts now, so the clock runs from the first update
+// after the quest is accepted.
+//
+// Parameters:
+//   - now: The current game tick
+//   - ticksPerHour: Game ticks in an hour
+//
+// Returns:
+//   - []QuestDeadlineNotice: One DeadlineExpired notice for each quest whose
+//     own or objective deadline has passed, which the caller fails, and one
+//     DeadlineWarning the first time less than DeadlineWarningFraction of a
+//     time limit remains
+func (p *Player) UpdateQuestDeadlines(now, ticksPerHour int64) []QuestDeadlineNotice {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var notices []QuestDeadlineNotice
+	for i := range p.QuestLog {
+		quest := &p.QuestLog[i]
+		if quest.Status != QuestActive {
+			continue
+		}
+
+		notice, expired := checkDeadline(quest.ID, -1, quest.TimeLimitHours, &quest.Deadline, &quest.DeadlineWarned, now, ticksPerHour)
+		if expired {
+			notices = append(notices, notice)
+			continue
+		}
+		var warnings []QuestDeadlineNotice
+		if notice.Kind == DeadlineWarning {
+			warnings = append(warnings, notice)
+		}
+		for j := range quest.Objectives {
+			objective := &quest.Objectives[j]
+			if objective.Completed {
+				continue
+			}
+			notice, expired = checkDeadline(quest.ID, j, objective.TimeLimitHours, &objective.Deadline, &objective.DeadlineWarned, now, ticksPerHour)
+			if expired {
+				break
+			}
+			if notice.Kind == DeadlineWarning {
+				warnings = append(warnings, notice)
+			}
+		}
+		if expired {
+			notices = append(notices, notice)
+			continue
+		}
+		notices = append(notices, warnings...)
+	}
+	return notices
+}
+
+// checkDeadline starts a time limit's deadline if needed and reports it
+// passed, or returns a warning notice the first time it is near. The
+// notice has no kind when there is nothing to report.
+func checkDeadline(questID string, objective, limitHours int, deadline *int64, warned *bool, now, ticksPerHour int64) (QuestDeadlineNotice, bool) {
+	notice := QuestDeadlineNotice{QuestID: questID, Objective: objective}
+	if limitHours <= 0 {
+		return notice, false
+	}
+	limit := int64(limitHours) * ticksPerHour
+	if *deadline == 0 {
+		*deadline = now + limit
+	}
+	notice.Deadline = *deadline
+
+	if now >= *deadline {
+		notice.Kind = DeadlineExpired
+		return notice, true
+	}
+	if !*warned && float64(*deadline-now) <= float64(limit)*DeadlineWarningFraction {
+		*warned = true
+		notice.Kind = DeadlineWarning
+	}
+	return notice, false
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newDeadlineTestPlayer returns a player with an active "rescue" quest of
+// 10 hours whose second objective must be done within 4 hours.
+func newDeadlineTestPlayer() *Player {
+	player := &Player{Character: Character{ID: "hero", Name: "Hero"}}
+	quest := Quest{
+		ID:             "rescue",
+		TimeLimitHours: 10,
+		Objectives: []QuestObjective{
+			{Description: "Find the captive", Required: 1},
+			{Description: "Reach the ford", Required: 1, TimeLimitHours: 4},
+		},
+	}
+	if err := player.StartQuest(quest); err != nil {
+		panic(err)
+	}
+	return player
+}
+
+func TestUpdateQuestDeadlines(t *testing.T) {
+	player := newDeadlineTestPlayer()
+
+	if notices := player.UpdateQuestDeadlines(100, 10); len(notices) != 0 {
+		t.Fatalf("starting the clock reported %v", notices)
+	}
+	quest, _ := player.GetQuest("rescue")
+	if quest.Deadline != 200 || quest.Objectives[1].Deadline != 140 {
+		t.Fatalf("deadlines = %d and %d, want 200 and 140", quest.Deadline, quest.Objectives[1].Deadline)
+	}
+	if quest.Objectives[0].Deadline != 0 {
+		t.Error("an objective without a time limit has no deadline")
+	}
+
+	// A quarter of the objective's 40 ticks is left
+	want := []QuestDeadlineNotice{{QuestID: "rescue", Objective: 1, Kind: DeadlineWarning, Deadline: 140}}
+	if notices := player.UpdateQuestDeadlines(130, 10); !reflect.DeepEqual(notices, want) {
+		t.Errorf("notices = %v, want %v", notices, want)
+	}
+	if notices := player.UpdateQuestDeadlines(135, 10); len(notices) != 0 {
+		t.Errorf("warned twice: %v", notices)
+	}
+
+	want = []QuestDeadlineNotice{{QuestID: "rescue", Objective: 1, Kind: DeadlineExpired, Deadline: 140}}
+	if notices := player.UpdateQuestDeadlines(140, 10); !reflect.DeepEqual(notices, want) {
+		t.Errorf("notices = %v, want %v", notices, want)
+	}
+}
+
+func TestUpdateQuestDeadlines_QuestLimit(t *testing.T) {
+	player := newDeadlineTestPlayer()
+	player.UpdateQuestDeadlines(0, 10)
+	if err := player.UpdateQuestObjective("rescue", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The finished objective's deadline no longer matters
+	want := []QuestDeadlineNotice{{QuestID: "rescue", Objective: -1, Kind: DeadlineWarning, Deadline: 100}}
+	if notices := player.UpdateQuestDeadlines(80, 10); !reflect.DeepEqual(notices, want) {
+		t.Errorf("notices = %v, want %v", notices, want)
+	}
+	want = []QuestDeadlineNotice{{QuestID: "rescue", Objective: -1, Kind: DeadlineExpired, Deadline: 100}}
+	if notices := player.UpdateQuestDeadlines(150, 10); !reflect.DeepEqual(notices, want) {
+		t.Errorf("notices = %v, want %v", notices, want)
+	}
+
+	if err := player.FailQuest("rescue"); err != nil {
+		t.Fatal(err)
+	}
+	if notices := player.UpdateQuestDeadlines(200, 10); len(notices) != 0 {
+		t.Errorf("a failed quest has no deadline: %v", notices)
+	}
+}
@@ -0,0 +1,441 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Relationship score ranges. Trust and debt run from -100 to 100, fear
+// from 0 to 100.
+const (
+	MaxRelationshipTrust = 100
+	MinRelationshipTrust = -100
+	MaxRelationshipFear  = 100
+	MaxRelationshipDebt  = 100
+	MinRelationshipDebt  = -100
+
+	// RelationshipMemorySize is how many events an NPC remembers about
+	// each character it knows
+	RelationshipMemorySize = 10
+)
+
+// RelationshipEvent is something a character does to or for an NPC, moving
+// how the NPC feels about them.
+type RelationshipEvent string
+
+const (
+	// RelationshipHelped is aid given to the NPC
+	RelationshipHelped RelationshipEvent = "helped"
+	// RelationshipHarmed is an attack on the NPC
+	RelationshipHarmed RelationshipEvent = "harmed"
+	// RelationshipThreatened is a threat made to the NPC
+	RelationshipThreatened RelationshipEvent = "threatened"
+	// RelationshipAllyHarmed is an attack on a member of the NPC's faction
+	RelationshipAllyHarmed RelationshipEvent = "ally_harmed"
+	// RelationshipAllyKilled is the death of a member of the NPC's faction
+	RelationshipAllyKilled RelationshipEvent = "ally_killed"
+	// RelationshipTraded is a purchase from or sale to the NPC
+	RelationshipTraded RelationshipEvent = "traded"
+	// RelationshipPaid is gold given to the NPC, settling what is owed
+	RelationshipPaid RelationshipEvent = "paid"
+	// RelationshipQuestCompleted is a quest the NPC gave, completed
+	RelationshipQuestCompleted RelationshipEvent = "quest_completed"
+	// RelationshipQuestFailed is a quest the NPC gave, failed
+	RelationshipQuestFailed RelationshipEvent = "quest_failed"
+)
+
+// relationshipEventEffects holds the trust, fear and debt change of each
+// event
+var relationshipEventEffects = map[RelationshipEvent][3]int{
+	RelationshipHelped:         {10, 0, 5},
+	RelationshipHarmed:         {-25, 15, 0},
+	RelationshipThreatened:     {-10, 20, 0},
+	RelationshipAllyHarmed:     {-10, 5, 0},
+	RelationshipAllyKilled:     {-30, 20, 0},
+	RelationshipTraded:         {2, 0, 0},
+	RelationshipPaid:           {5, 0, 10},
+	RelationshipQuestCompleted: {15, 0, 20},
+	RelationshipQuestFailed:    {-15, 0, -10},
+}
+
+// RelationshipAttitude sums up how an NPC feels about someone
+type RelationshipAttitude string
+
+const (
+	AttitudeFriendly RelationshipAttitude = "friendly"
+	AttitudeNeutral  RelationshipAttitude = "neutral"
+	AttitudeWary     RelationshipAttitude = "wary"
+	AttitudeAfraid   RelationshipAttitude = "afraid"
+	AttitudeHostile  RelationshipAttitude = "hostile"
+)
+
+// Dialog condition types checked against the speaker's relationship with
+// the listener. Their values are whole numbers, except
+// DialogConditionAttitude, whose value is a RelationshipAttitude.
+const (
+	DialogConditionMinTrust = "min_trust"
+	DialogConditionMaxTrust = "max_trust"
+	DialogConditionMinFear  = "min_fear"
+	DialogConditionMaxFear  = "max_fear"
+	DialogConditionMinDebt  = "min_debt"
+	DialogConditionMaxDebt  = "max_debt"
+	DialogConditionAttitude = "attitude"
+)
+
+// RelationshipMemory is one event an NPC remembers
+type RelationshipMemory struct {
+	Event RelationshipEvent `yaml:"memory_event"`
+	At    time.Time         `yaml:"memory_at"`
+}
+
+// Relationship is how an NPC feels about a player or another NPC:
+//   - Trust, from -100 to 100, is how far the NPC relies on them
+//   - Fear, from 0 to 100, is how far the NPC is intimidated by them
+//   - Debt, from -100 to 100, is what the NPC owes them; negative when
+//     they owe the NPC
+type Relationship struct {
+	Trust    int                  `yaml:"relationship_trust"`
+	Fear     int                  `yaml:"relationship_fear"`
+	Debt     int                  `yaml:"relationship_debt"`
+	Memories []RelationshipMemory `yaml:"relationship_memories,omitempty"` // Recent events, oldest first
+}
+
+// Attitude sums up the relationship. An NPC that fears someone more than
+// it trusts them is afraid of them, and otherwise its trust decides.
+func (r Relationship) Attitude() RelationshipAttitude {
+	switch {
+	case r.Fear >= 50 && r.Fear > r.Trust:
+		return AttitudeAfraid
+	case r.Trust <= -50:
+		return AttitudeHostile
+	case r.Trust < -10:
+		return AttitudeWary
+	case r.Trust >= 40:
+		return AttitudeFriendly
+	}
+	return AttitudeNeutral
+}
+
+// Satisfies reports whether the relationship meets the relationship
+// conditions among conds. Other conditions are left to the systems that
+// know them and do not count against the relationship.
+func (r Relationship) Satisfies(conds []DialogCondition) bool {
+	for _, cond := range conds {
+		if cond.Type == DialogConditionAttitude {
+			if fmt.Sprint(cond.Value) != string(r.Attitude()) {
+				return false
+			}
+			continue
+		}
+
+		var score int
+		var atLeast bool
+		switch cond.Type {
+		case DialogConditionMinTrust:
+			score, atLeast = r.Trust, true
+		case DialogConditionMaxTrust:
+			score = r.Trust
+		case DialogConditionMinFear:
+			score, atLeast = r.Fear, true
+		case DialogConditionMaxFear:
+			score = r.Fear
+		case DialogConditionMinDebt:
+			score, atLeast = r.Debt, true
+		case DialogConditionMaxDebt:
+			score = r.Debt
+		default:
+			continue
+		}
+		limit, ok := conditionNumber(cond.Value)
+		if !ok || (atLeast && score < limit) || (!atLeast && score > limit) {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionNumber reads a whole-number condition value, which is an int
+// when loaded from YAML and a float64 when decoded from JSON
+func conditionNumber(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// apply moves the scores by an event's effects and remembers it
+func (r *Relationship) apply(event RelationshipEvent, at time.Time) {
+	effect := relationshipEventEffects[event]
+	r.Trust = clampRelationshipScore(r.Trust+effect[0], MinRelationshipTrust, MaxRelationshipTrust)
+	r.Fear = clampRelationshipScore(r.Fear+effect[1], 0, MaxRelationshipFear)
+	r.Debt = clampRelationshipScore(r.Debt+effect[2], MinRelationshipDebt, MaxRelationshipDebt)
+
+	r.Memories = append(r.Memories, RelationshipMemory{Event: event, At: at})
+	if len(r.Memories) > RelationshipMemorySize {
+		r.Memories = r.Memories[len(r.Memories)-RelationshipMemorySize:]
+	}
+}
+
+// clone returns a copy that shares no memories with r
+func (r Relationship) clone() Relationship {
+	r.Memories = append([]RelationshipMemory(nil), r.Memories...)
+	return r
+}
+
+// clampRelationshipScore keeps a score within its range
+func clampRelationshipScore(score, min, max int) int {
+	if score < min {
+		return min
+	}
+	if score > max {
+		return max
+	}
+	return score
+}
+
+// RelationshipGraph holds how each NPC feels about the players and other
+// NPCs it knows. Relationships are directed: an NPC may trust a player who
+// fears it. A pair without a relationship is neutral.
+//
+// RelationshipGraph is safe for concurrent use.
+type RelationshipGraph struct {
+	mu    sync.RWMutex                        `yaml:"-"`
+	Edges map[string]map[string]*Relationship `yaml:"relationship_edges"` // NPC ID -> other ID -> relationship
+}
+
+// NewRelationshipGraph creates an empty relationship graph
+func NewRelationshipGraph() *RelationshipGraph {
+	return &RelationshipGraph{Edges: make(map[string]map[string]*Relationship)}
+}
+
+// Get returns a copy of how the NPC feels about other
+func (g *RelationshipGraph) Get(npcID, otherID string) Relationship {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if rel, ok := g.Edges[npcID][otherID]; ok {
+		return rel.clone()
+	}
+	return Relationship{}
+}
+
+// Set replaces how the NPC feels about other, clamping the scores to
+// their ranges. Generators use it to seed relationships.
+func (g *RelationshipGraph) Set(npcID, otherID string, rel Relationship) {
+	rel = rel.clone()
+	rel.Trust = clampRelationshipScore(rel.Trust, MinRelationshipTrust, MaxRelationshipTrust)
+	rel.Fear = clampRelationshipScore(rel.Fear, 0, MaxRelationshipFear)
+	rel.Debt = clampRelationshipScore(rel.Debt, MinRelationshipDebt, MaxRelationshipDebt)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	*g.edge(npcID, otherID) = rel
+}
+
+// Record applies an event other caused to the NPC's relationship with
+// them.
+//
+// Parameters:
+//   - npcID: The NPC the event happened to
+//   - otherID: The player or NPC responsible
+//   - event: What happened
+//
+// Returns:
+//   - Relationship: A copy of the updated relationship
+//   - error: The event is unknown or the NPC and other are the same
+func (g *RelationshipGraph) Record(npcID, otherID string, event RelationshipEvent) (Relationship, error) {
+	if _, known := relationshipEventEffects[event]; !known {
+		return Relationship{}, fmt.Errorf("unknown relationship event: %s", event)
+	}
+	if npcID == otherID {
+		return Relationship{}, fmt.Errorf("%s cannot have a relationship with itself", npcID)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	rel := g.edge(npcID, otherID)
+	rel.apply(event, time.Now())
+	return rel.clone(), nil
+}
+
+// Of returns copies of the NPC's relationships by the ID of the other
+// character.
+func (g *RelationshipGraph) Of(npcID string) map[string]Relationship {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	rels := make(map[string]Relationship, len(g.Edges[npcID]))
+	for otherID, rel := range g.Edges[npcID] {
+		rels[otherID] = rel.clone()
+	}
+	return rels
+}
+
+// QuestAvailable reports whether the quest's giver will offer it to the
+// player. Quests without a giver are always available. A giver offers its
+// quest to a player it trusts at least the quest's MinGiverTrust, or to
+// one it owes a favour, but never to a player it is hostile to.
+func (g *RelationshipGraph) QuestAvailable(quest *Quest, playerID string) bool {
+	if quest.GiverID == "" {
+		return true
+	}
+	rel := g.Get(quest.GiverID, playerID)
+	if rel.Attitude() == AttitudeHostile {
+		return false
+	}
+	return rel.Trust >= quest.MinGiverTrust || rel.Debt > 0
+}
+
+// Clone returns a deep copy of the graph; a nil graph clones to nil
+func (g *RelationshipGraph) Clone() *RelationshipGraph {
+	if g == nil {
+		return nil
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	clone := NewRelationshipGraph()
+	for npcID, edges := range g.Edges {
+		clone.Edges[npcID] = make(map[string]*Relationship, len(edges))
+		for otherID, rel := range edges {
+			copied := rel.clone()
+			clone.Edges[npcID][otherID] = &copied
+		}
+	}
+	return clone
+}
+
+// edge returns the relationship of npcID with otherID, creating it.
+// Callers must hold the write lock.
+func (g *RelationshipGraph) edge(npcID, otherID string) *Relationship {
+	if g.Edges == nil {
+		g.Edges = make(map[string]map[string]*Relationship)
+	}
+	edges, ok := g.Edges[npcID]
+	if !ok {
+		edges = make(map[string]*Relationship)
+		g.Edges[npcID] = edges
+	}
+	rel, ok := edges[otherID]
+	if !ok {
+		rel = &Relationship{}
+		edges[otherID] = rel
+	}
+	return rel
+}
+
+// SelectDialog chooses the dialog node the NPC speaks to someone it has
+// the given relationship with. Without a dialogID it opens the
+// conversation with the first node whose relationship conditions rel
+// meets; with one it continues with that node, if rel meets its
+// conditions. Responses leading to a node rel does not meet are left out,
+// so how the NPC feels about the listener decides which branches of its
+// dialog tree they can reach.
+//
+// Parameters:
+//   - rel: The NPC's relationship with the listener
+//   - dialogID: The node to continue with, or "" to open
+//
+// Returns:
+//   - *DialogEntry: The chosen node
+//   - []DialogResponse: The responses the listener may give
+//   - error: No node is available or the named node is unknown or closed
+func (n *NPC) SelectDialog(rel Relationship, dialogID string) (*DialogEntry, []DialogResponse, error) {
+	var entry *DialogEntry
+	for i := range n.Dialog {
+		candidate := &n.Dialog[i]
+		if dialogID == "" && rel.Satisfies(candidate.Conditions) {
+			entry = candidate
+			break
+		}
+		if dialogID != "" && candidate.ID == dialogID {
+			if !rel.Satisfies(candidate.Conditions) {
+				return nil, nil, fmt.Errorf("%s will not say %s while %s", n.GetName(), dialogID, rel.Attitude())
+			}
+			entry = candidate
+			break
+		}
+	}
+	if entry == nil {
+		if dialogID == "" {
+			return nil, nil, fmt.Errorf("%s has nothing to say", n.GetName())
+		}
+		return nil, nil, fmt.Errorf("%s has no dialog %s", n.GetName(), dialogID)
+	}
+
+	responses := make([]DialogResponse, 0, len(entry.Responses))
+	for _, response := range entry.Responses {
+		if next := n.dialogEntry(response.NextDialog); next != nil && !rel.Satisfies(next.Conditions) {
+			continue
+		}
+		responses = append(responses, response)
+	}
+	return entry, responses, nil
+}
+
+// dialogEntry returns the NPC's dialog node with id, or nil
+func (n *NPC) dialogEntry(id string) *DialogEntry {
+	if id == "" {
+		return nil
+	}
+	for i := range n.Dialog {
+		if n.Dialog[i].ID == id {
+			return &n.Dialog[i]
+		}
+	}
+	return nil
+}
+
+// RecordAttack makes an NPC remember being attacked by attackerID, and
+// the living members of its faction remember the attack on one of their
+// own, or its death if the attack killed it.
+func (w *World) RecordAttack(attackerID string, npc *NPC) {
+	graph := w.RelationshipGraph()
+	if _, err := graph.Record(npc.GetID(), attackerID, RelationshipHarmed); err != nil {
+		return
+	}
+	if npc.Faction == "" {
+		return
+	}
+
+	event := RelationshipAllyHarmed
+	if npc.GetHealth() <= 0 {
+		event = RelationshipAllyKilled
+	}
+	for _, member := range w.factionMembers(npc.Faction) {
+		if member.GetID() != npc.GetID() && member.GetID() != attackerID && member.IsActive() {
+			graph.Record(member.GetID(), attackerID, event)
+		}
+	}
+}
+
+// factionMembers returns the world's NPCs of a faction
+func (w *World) factionMembers(faction string) []*NPC {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var members []*NPC
+	add := func(npc *NPC) {
+		if npc.Faction == faction && !seen[npc.GetID()] {
+			seen[npc.GetID()] = true
+			members = append(members, npc)
+		}
+	}
+	for _, npc := range w.NPCs {
+		add(npc)
+	}
+	for _, obj := range w.Objects {
+		if npc, ok := obj.(*NPC); ok {
+			add(npc)
+		}
+	}
+	return members
+}
@@ -0,0 +1,184 @@
+package game
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRelationshipGraph_Record(t *testing.T) {
+	graph := NewRelationshipGraph()
+
+	rel, err := graph.Record("npc-1", "player-1", RelationshipHarmed)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if rel.Trust != -25 || rel.Fear != 15 {
+		t.Errorf("harmed relationship = trust %d fear %d, want -25 and 15", rel.Trust, rel.Fear)
+	}
+	if got := graph.Get("player-1", "npc-1"); got.Trust != 0 {
+		t.Errorf("relationships are directed, but the reverse edge has trust %d", got.Trust)
+	}
+
+	for i := 0; i < RelationshipMemorySize+5; i++ {
+		rel, _ = graph.Record("npc-1", "player-1", RelationshipThreatened)
+	}
+	if rel.Trust != MinRelationshipTrust || rel.Fear != MaxRelationshipFear {
+		t.Errorf("scores were not clamped: trust %d fear %d", rel.Trust, rel.Fear)
+	}
+	if len(rel.Memories) != RelationshipMemorySize {
+		t.Errorf("remembered %d events, want %d", len(rel.Memories), RelationshipMemorySize)
+	}
+
+	if _, err := graph.Record("npc-1", "player-1", "bribed"); err == nil {
+		t.Error("expected an unknown event to be refused")
+	}
+	if _, err := graph.Record("npc-1", "npc-1", RelationshipHelped); err == nil {
+		t.Error("expected an NPC to have no relationship with itself")
+	}
+}
+
+func TestRelationship_Attitude(t *testing.T) {
+	tests := []struct {
+		rel  Relationship
+		want RelationshipAttitude
+	}{
+		{Relationship{}, AttitudeNeutral},
+		{Relationship{Trust: 60}, AttitudeFriendly},
+		{Relationship{Trust: -20}, AttitudeWary},
+		{Relationship{Trust: -70}, AttitudeHostile},
+		{Relationship{Trust: -70, Fear: 60}, AttitudeAfraid},
+		{Relationship{Trust: 80, Fear: 60}, AttitudeFriendly},
+	}
+	for _, tt := range tests {
+		if got := tt.rel.Attitude(); got != tt.want {
+			t.Errorf("Attitude(%+v) = %s, want %s", tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestNPC_SelectDialog(t *testing.T) {
+	npc := &NPC{
+		Character: Character{Name: "Innkeeper"},
+		Dialog: []DialogEntry{
+			{
+				ID:         "cower",
+				Text:       "Take what you want, just leave me be!",
+				Conditions: []DialogCondition{{Type: DialogConditionAttitude, Value: "afraid"}},
+			},
+			{
+				ID:   "greet",
+				Text: "Welcome, traveller.",
+				Responses: []DialogResponse{
+					{Text: "Any work?", NextDialog: "secret"},
+					{Text: "Farewell."},
+				},
+				Conditions: []DialogCondition{{Type: "quest_complete", Value: "q-1"}},
+			},
+			{
+				ID:         "secret",
+				Text:       "There is a smuggler's tunnel under the cellar.",
+				Conditions: []DialogCondition{{Type: DialogConditionMinTrust, Value: 40}, {Type: DialogConditionMaxFear, Value: float64(30)}},
+			},
+		},
+	}
+
+	entry, responses, err := npc.SelectDialog(Relationship{}, "")
+	if err != nil {
+		t.Fatalf("SelectDialog failed: %v", err)
+	}
+	if entry.ID != "greet" || len(responses) != 1 {
+		t.Errorf("a stranger gets %s with %d responses, want greet with only the farewell", entry.ID, len(responses))
+	}
+	if _, _, err := npc.SelectDialog(Relationship{}, "secret"); err == nil {
+		t.Error("expected the secret to stay closed to a stranger")
+	}
+
+	entry, responses, _ = npc.SelectDialog(Relationship{Trust: 50}, "")
+	if entry.ID != "greet" || len(responses) != 2 {
+		t.Errorf("a trusted friend gets %s with %d responses, want greet with both", entry.ID, len(responses))
+	}
+
+	entry, _, _ = npc.SelectDialog(Relationship{Trust: -40, Fear: 70}, "")
+	if entry.ID != "cower" {
+		t.Errorf("a feared player gets %s, want cower", entry.ID)
+	}
+}
+
+func TestRelationshipGraph_QuestAvailable(t *testing.T) {
+	graph := NewRelationshipGraph()
+	quest := &Quest{ID: "q-1", GiverID: "npc-1", MinGiverTrust: 20}
+
+	if graph.QuestAvailable(quest, "player-1") {
+		t.Error("expected the quest to need trust")
+	}
+	graph.Set("npc-1", "player-1", Relationship{Debt: 10})
+	if !graph.QuestAvailable(quest, "player-1") {
+		t.Error("expected a giver in the player's debt to offer the quest")
+	}
+	graph.Set("npc-1", "player-1", Relationship{Trust: -80, Debt: 10})
+	if graph.QuestAvailable(quest, "player-1") {
+		t.Error("expected a hostile giver to refuse")
+	}
+	if !graph.QuestAvailable(&Quest{ID: "q-2"}, "player-1") {
+		t.Error("expected quests without a giver to be available")
+	}
+}
+
+func TestWorld_RelationshipsSaved(t *testing.T) {
+	world := NewWorld()
+	player := &Player{Character: Character{ID: "player-1", Name: "Aldric"}}
+	if err := player.StartQuest(Quest{ID: "q-1", GiverID: "npc-1", Status: QuestActive}); err != nil {
+		t.Fatalf("StartQuest failed: %v", err)
+	}
+	quest, _ := player.GetQuest("q-1")
+	if _, err := world.ApplyQuestOutcome(player, quest, QuestCompleted, func() error {
+		_, err := player.CompleteQuest("q-1")
+		return err
+	}); err != nil {
+		t.Fatalf("ApplyQuestOutcome failed: %v", err)
+	}
+
+	data, err := yaml.Marshal(world)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	loaded := NewWorld()
+	if err := yaml.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	rel := loaded.RelationshipGraph().Get("npc-1", "player-1")
+	if rel.Trust != 15 || rel.Debt != 20 || len(rel.Memories) != 1 {
+		t.Errorf("loaded relationship = %+v, want the completed quest remembered", rel)
+	}
+	if clone := loaded.Clone(); clone.Relationships == loaded.Relationships {
+		t.Error("expected the clone to have its own relationships")
+	}
+}
+
+func TestWorld_RecordAttack(t *testing.T) {
+	world := NewWorld()
+	victim := &NPC{Character: Character{ID: "guard-1", Name: "Watchman", HP: 0, active: true}, Faction: "guard"}
+	ally := &NPC{Character: Character{ID: "guard-2", Name: "Sergeant", HP: 10, active: true}, Faction: "guard"}
+	stranger := &NPC{Character: Character{ID: "thief-1", Name: "Cutpurse", HP: 10, active: true}, Faction: "thieves"}
+	world.NPCs[victim.ID] = victim
+	for _, npc := range []*NPC{ally, stranger} {
+		if err := world.AddObject(npc); err != nil {
+			t.Fatalf("AddObject failed: %v", err)
+		}
+	}
+
+	world.RecordAttack("player-1", victim)
+
+	graph := world.RelationshipGraph()
+	if rel := graph.Get("guard-1", "player-1"); rel.Trust != -25 {
+		t.Errorf("victim's trust = %d, want -25", rel.Trust)
+	}
+	if rel := graph.Get("guard-2", "player-1"); rel.Trust != -30 || rel.Fear != 20 {
+		t.Errorf("ally's view = %+v, want the killing of a faction member remembered", rel)
+	}
+	if rel := graph.Get("thief-1", "player-1"); rel.Trust != 0 {
+		t.Errorf("other factions should not care, but trust = %d", rel.Trust)
+	}
+}
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"fmt"
+	"math"
+)
+
+// ResistanceImmune is the resistance at and above which a character is
+// immune: the effect or damage is negated entirely.
+const ResistanceImmune = 1.0
+
+// Resistances describes how well a character withstands status effects and
+// damage. Each value is the fraction of an effect's magnitude or of damage
+// that is resisted: 0.5 halves it, ResistanceImmune or more negates it, and a
+// negative value is a vulnerability, -0.5 increasing it by half.
+//
+// Effect resistances are keyed by effect type and damage resistances by
+// damage type. An effect that deals damage is checked against both, and the
+// two combine multiplicatively.
+type Resistances struct {
+	Effects map[EffectType]float64 `yaml:"effects,omitempty" json:"effects,omitempty"`
+	Damage  map[DamageType]float64 `yaml:"damage,omitempty" json:"damage,omitempty"`
+}
+
+// Clone returns a deep copy of the resistances.
+func (r Resistances) Clone() Resistances {
+	clone := Resistances{}
+	if r.Effects != nil {
+		clone.Effects = make(map[EffectType]float64, len(r.Effects))
+		for effectType, value := range r.Effects {
+			clone.Effects[effectType] = value
+		}
+	}
+	if r.Damage != nil {
+		clone.Damage = make(map[DamageType]float64, len(r.Damage))
+		for damageType, value := range r.Damage {
+			clone.Damage[damageType] = value
+		}
+	}
+	return clone
+}
+
+// Against returns the resistance to effect, combining the resistance to
+// its type with the resistance to its damage type.
+func (r Resistances) Against(effect *Effect) float64 {
+	resistance := r.Effects[effect.Type]
+	if effect.DamageType != "" {
+		resistance = combineResistances(resistance, r.Damage[effect.DamageType])
+	}
+	return resistance
+}
+
+// ReduceDamage returns damage of damageType after resistance, rounded to the
+// nearest point. Immunity reduces it to 0.
+func (r Resistances) ReduceDamage(damageType DamageType, damage int) int {
+	resistance := r.Damage[damageType]
+	if resistance >= ResistanceImmune {
+		return 0
+	}
+	return int(math.Round(float64(damage) * (1 - resistance)))
+}
+
+// combineResistances returns the resistance of applying a and b in turn.
+// Immunity to either wins over any vulnerability to the other.
+func combineResistances(a, b float64) float64 {
+	if a >= ResistanceImmune || b >= ResistanceImmune {
+		return ResistanceImmune
+	}
+	return 1 - (1-a)*(1-b)
+}
+
+// StackingPolicy controls what happens when an effect is applied to a
+// target that already has an active effect of the same type.
+type StackingPolicy string
+
+const (
+	// StackDefault keeps the behavior of the effect's type: types that
+	// AllowsStacking add a stack, others are replaced by a stronger effect
+	// and reject a weaker one.
+	StackDefault StackingPolicy = ""
+	// StackRefresh restarts the existing effect's duration, keeping the
+	// greater of the two magnitudes.
+	StackRefresh StackingPolicy = "refresh"
+	// StackMagnitude adds a stack to the existing effect, multiplying its
+	// magnitude, up to the effect's MaxStacks. At the cap the duration is
+	// refreshed instead.
+	StackMagnitude StackingPolicy = "stack"
+	// StackIgnore leaves the existing effect unchanged and drops the new one.
+	StackIgnore StackingPolicy = "ignore"
+)
+
+// IsValid reports whether p is a known stacking policy.
+func (p StackingPolicy) IsValid() bool {
+	switch p {
+	case StackDefault, StackRefresh, StackMagnitude, StackIgnore:
+		return true
+	default:
+		return false
+	}
+}
+
+// EffectOutcome is the result of applying an effect to a target.
+type EffectOutcome string
+
+const (
+	OutcomeApplied   EffectOutcome = "applied"   // Added as a new effect
+	OutcomeReplaced  EffectOutcome = "replaced"  // Added, replacing a weaker effect of its type
+	OutcomeStacked   EffectOutcome = "stacked"   // Added a stack to an existing effect
+	OutcomeRefreshed EffectOutcome = "refreshed" // Restarted an existing effect's duration
+	OutcomeIgnored   EffectOutcome = "ignored"   // Dropped, as its policy ignores reapplication
+	OutcomeImmune    EffectOutcome = "immune"    // Negated by the target's immunity
+	OutcomeReflected EffectOutcome = "reflected" // Turned back by the target
+)
+
+// EffectResolution describes how an effect application was resolved.
+type EffectResolution struct {
+	Outcome EffectOutcome `json:"outcome"`
+
+	// EffectID is the active effect the application resulted in: the new
+	// effect, or the existing one it stacked on, refreshed or was ignored for
+	EffectID string `json:"effect_id,omitempty"`
+
+	// Resistance is the fraction of the magnitude the target resisted,
+	// negative for a vulnerability
+	Resistance float64 `json:"resistance"`
+
+	// Magnitude is the magnitude of the active effect, after resistance
+	Magnitude float64 `json:"magnitude"`
+
+	// Stacks is the stack count of the active effect
+	Stacks int `json:"stacks"`
+}
+
+// Applied reports whether the effect took hold on the target, as a new
+// effect or by changing an existing one.
+func (r EffectResolution) Applied() bool {
+	switch r.Outcome {
+	case OutcomeApplied, OutcomeReplaced, OutcomeStacked, OutcomeRefreshed:
+		return true
+	default:
+		return false
+	}
+}
+
+// err returns the error EffectHolder.AddEffect reports for a resolution:
+// immunity and reflection are errors, other outcomes are not.
+func (r EffectResolution) err(effectType EffectType) error {
+	switch r.Outcome {
+	case OutcomeImmune:
+		return fmt.Errorf("target is immune to %s effects", effectType)
+	case OutcomeReflected:
+		return fmt.Errorf("effect reflected")
+	default:
+		return nil
+	}
+}
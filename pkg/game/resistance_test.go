@@ -0,0 +1,155 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResistances_Against(t *testing.T) {
+	resistances := Resistances{
+		Effects: map[EffectType]float64{EffectBurning: 0.5, EffectStun: ResistanceImmune},
+		Damage:  map[DamageType]float64{DamageFire: -0.5, DamageFrost: 1.5},
+	}
+
+	assert.Zero(t, resistances.Against(NewEffect(EffectRoot, Duration{Rounds: 1}, 1)))
+	assert.Equal(t, ResistanceImmune, resistances.Against(NewEffect(EffectStun, Duration{Rounds: 1}, 1)))
+
+	burning := CreateDamageEffect(EffectBurning, DamageFire, 10, time.Minute)
+	assert.InDelta(t, 0.25, resistances.Against(burning), 1e-9, "half resisted, then half again as vulnerable")
+
+	frostbite := CreateDamageEffect(EffectDamageOverTime, DamageFrost, 10, time.Minute)
+	assert.Equal(t, ResistanceImmune, resistances.Against(frostbite))
+
+	assert.Equal(t, 15, resistances.ReduceDamage(DamageFire, 10))
+	assert.Equal(t, 0, resistances.ReduceDamage(DamageFrost, 10))
+	assert.Equal(t, 10, resistances.ReduceDamage(DamagePhysical, 10))
+
+	clone := resistances.Clone()
+	clone.Effects[EffectBurning] = 0
+	assert.Equal(t, 0.5, resistances.Effects[EffectBurning], "clones are independent")
+}
+
+func TestCharacter_ResolveEffect(t *testing.T) {
+	character := &Character{ID: "hero", HP: 20, MaxHP: 20, Strength: 12, Resistances: Resistances{
+		Effects: map[EffectType]float64{EffectStun: ResistanceImmune, EffectStatPenalty: -1},
+	}}
+
+	resolution, err := character.ResolveEffect(NewEffect(EffectStun, Duration{Rounds: 2}, 1))
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeImmune, resolution.Outcome)
+	assert.False(t, resolution.Applied())
+	assert.False(t, character.HasEffect(EffectStun))
+	assert.EqualError(t, character.AddEffect(NewEffect(EffectStun, Duration{Rounds: 2}, 1)), "target is immune to stun effects")
+
+	resolution, err = character.ResolveEffect(NewEffect(EffectStatPenalty, Duration{Rounds: 2}, 2))
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeApplied, resolution.Outcome)
+	assert.Equal(t, -1.0, resolution.Resistance)
+	assert.Equal(t, 4.0, resolution.Magnitude, "vulnerability doubles the magnitude")
+}
+
+func TestEffectManager_StackingPolicies(t *testing.T) {
+	newRoot := func(magnitude float64, policy StackingPolicy) *Effect {
+		effect := NewEffect(EffectRoot, Duration{Rounds: 3}, magnitude)
+		effect.Stacking = policy
+		return effect
+	}
+
+	t.Run("stack up to the cap, then refresh", func(t *testing.T) {
+		em := NewEffectManager(NewDefaultStats())
+		first := newRoot(1, StackMagnitude)
+		first.MaxStacks = 2
+		require.NoError(t, em.ApplyEffect(first))
+
+		second := newRoot(1, StackMagnitude)
+		second.MaxStacks = 2
+		resolution, err := em.ResolveEffect(second, 0)
+		require.NoError(t, err)
+		assert.Equal(t, OutcomeStacked, resolution.Outcome)
+		assert.Equal(t, first.ID, resolution.EffectID)
+		assert.Equal(t, 2, resolution.Stacks)
+
+		third := newRoot(1, StackMagnitude)
+		third.MaxStacks = 2
+		resolution, err = em.ResolveEffect(third, 0)
+		require.NoError(t, err)
+		assert.Equal(t, OutcomeRefreshed, resolution.Outcome)
+		assert.Equal(t, 2, resolution.Stacks)
+	})
+
+	t.Run("refresh keeps the greater magnitude", func(t *testing.T) {
+		em := NewEffectManager(NewDefaultStats())
+		first := newRoot(3, StackRefresh)
+		require.NoError(t, em.ApplyEffect(first))
+		first.StartTime = time.Now().Add(-time.Hour)
+
+		resolution, err := em.ResolveEffect(newRoot(1, StackRefresh), 0)
+		require.NoError(t, err)
+		assert.Equal(t, OutcomeRefreshed, resolution.Outcome)
+		assert.Equal(t, 3.0, resolution.Magnitude)
+		assert.WithinDuration(t, time.Now(), first.StartTime, time.Second)
+		assert.Len(t, em.GetEffects(), 1)
+	})
+
+	t.Run("ignore leaves the existing effect", func(t *testing.T) {
+		em := NewEffectManager(NewDefaultStats())
+		first := newRoot(1, StackDefault)
+		require.NoError(t, em.ApplyEffect(first))
+
+		resolution, err := em.ResolveEffect(newRoot(5, StackIgnore), 0)
+		require.NoError(t, err)
+		assert.Equal(t, OutcomeIgnored, resolution.Outcome)
+		assert.Equal(t, first.ID, resolution.EffectID)
+		assert.Equal(t, 1.0, em.GetEffects()[0].Magnitude)
+	})
+
+	t.Run("default replaces a weaker effect and rejects a stronger one", func(t *testing.T) {
+		em := NewEffectManager(NewDefaultStats())
+		first := newRoot(1, StackDefault)
+		require.NoError(t, em.ApplyEffect(first))
+
+		stronger := newRoot(2, StackDefault)
+		resolution, err := em.ResolveEffect(stronger, 0)
+		require.NoError(t, err)
+		assert.Equal(t, OutcomeReplaced, resolution.Outcome)
+		assert.False(t, first.IsActive)
+
+		_, err = em.ResolveEffect(newRoot(1, StackDefault), 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("manager immunities combine with resistance", func(t *testing.T) {
+		em := NewEffectManager(NewDefaultStats())
+		resolution, err := em.ResolveEffect(CreateDamageEffect(EffectPoison, DamagePoison, 8, time.Minute), 0.5)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.625, resolution.Resistance, 1e-9)
+		assert.InDelta(t, 3.0, resolution.Magnitude, 1e-9)
+
+		em.AddImmunity(EffectRoot, ImmunityData{Type: ImmunityReflect})
+		resolution, err = em.ResolveEffect(newRoot(1, StackDefault), 0)
+		require.NoError(t, err)
+		assert.Equal(t, OutcomeReflected, resolution.Outcome)
+	})
+}
+
+func TestCharacter_DispelEffects(t *testing.T) {
+	character := &Character{ID: "hero", HP: 20, MaxHP: 20}
+	magic := DispelInfo{Priority: DispelPriorityNormal, Types: []DispelType{DispelMagic}, Removable: true}
+
+	older := NewEffectWithDispel(EffectStatBoost, Duration{Rounds: 5}, 1, magic)
+	require.NoError(t, character.AddEffect(older))
+	newer := NewEffectWithDispel(EffectRoot, Duration{Rounds: 5}, 1, magic)
+	require.NoError(t, character.AddEffect(newer))
+	newer.StartTime = older.StartTime.Add(time.Second)
+	curse := NewEffectWithDispel(EffectStatPenalty, Duration{Rounds: 5}, 1,
+		DispelInfo{Priority: DispelPriorityHigh, Types: []DispelType{DispelCurse}, Removable: true})
+	require.NoError(t, character.AddEffect(curse))
+
+	assert.Equal(t, []string{newer.ID}, character.DispelEffects(DispelMagic, 1), "most recent first among equals")
+	assert.False(t, newer.IsActive)
+	assert.Equal(t, []string{curse.ID, older.ID}, character.DispelEffects(DispelAll, 5), "highest priority first")
+	assert.Empty(t, character.GetEffects())
+}
@@ -0,0 +1,411 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SaveCategory identifies a column of the saving throw tables.
+type SaveCategory string
+
+// Saving throw categories, in the order of the classic tables.
+const (
+	SaveParalyzation  SaveCategory = "paralyzation"  // Paralyzation, poison or death magic
+	SaveRod           SaveCategory = "rod"           // Rod, staff or wand
+	SavePetrification SaveCategory = "petrification" // Petrification or polymorph
+	SaveBreath        SaveCategory = "breath"        // Breath weapon
+	SaveSpell         SaveCategory = "spell"         // Spell
+)
+
+// FriendlyFire is a ruleset's rule for who an area spell spares of the
+// caster's own side.
+type FriendlyFire string
+
+// Friendly fire rules. Without a ruleset profile FriendlyFireSpareCaster
+// applies.
+const (
+	FriendlyFireAll         FriendlyFire = "all"          // Everyone in the area, the caster included
+	FriendlyFireSpareCaster FriendlyFire = "spare_caster" // Everyone in the area but the caster
+	FriendlyFireNone        FriendlyFire = "none"         // Only those in the area who are not the caster's allies
+)
+
+// spares reports whether the rule spares a creature in the area.
+func (f FriendlyFire) spares(isCaster, ally bool) bool {
+	switch f {
+	case FriendlyFireAll:
+		return false
+	case FriendlyFireNone:
+		return isCaster || ally
+	default:
+		return isCaster
+	}
+}
+
+// rulesetDefaultClass is the table key used for classes without a table of
+// their own.
+const rulesetDefaultClass = "default"
+
+// AttackRow is one row of an attack matrix: the THAC0 of characters up to
+// MaxLevel. The last row of a matrix also covers every higher level.
+type AttackRow struct {
+	MaxLevel int `yaml:"max_level"`
+	THAC0    int `yaml:"thac0"`
+}
+
+// SaveRow is one row of a saving throw table: the numbers a d20 must meet
+// or beat for characters up to MaxLevel. The last row of a table also
+// covers every higher level.
+type SaveRow struct {
+	MaxLevel      int `yaml:"max_level"`
+	Paralyzation  int `yaml:"paralyzation"`
+	Rod           int `yaml:"rod"`
+	Petrification int `yaml:"petrification"`
+	Breath        int `yaml:"breath"`
+	Spell         int `yaml:"spell"`
+}
+
+// ClassRestriction limits who may take a class under a ruleset.
+type ClassRestriction struct {
+	Unavailable   bool           `yaml:"unavailable,omitempty"`    // The class cannot be chosen
+	MinAttributes map[string]int `yaml:"min_attributes,omitempty"` // Minimum scores keyed by attribute, such as "strength"
+	MaxLevel      int            `yaml:"max_level,omitempty"`      // Highest attainable level (0 = the XP table's)
+}
+
+// RulesetProfile is a rule flavor such as AD&D 1st or 2nd edition: its
+// attack matrices, saving throw tables, experience thresholds and class
+// restrictions, and how area spells treat the caster's side. Tables are
+// keyed by lowercase class name, and a "default" entry applies to classes
+// without their own.
+//
+// A profile is selected server-wide with SetActiveRuleset. Without one the
+// built-in rules apply.
+type RulesetProfile struct {
+	Name              string                      `yaml:"name"`
+	Description       string                      `yaml:"description,omitempty"`
+	AttackMatrix      map[string][]AttackRow      `yaml:"attack_matrix"`
+	SavingThrows      map[string][]SaveRow        `yaml:"saving_throws"`
+	XPThresholds      map[string][]int64          `yaml:"xp_thresholds"` // Total XP for level 2, 3, ...
+	ClassRestrictions map[string]ClassRestriction `yaml:"class_restrictions,omitempty"`
+	FriendlyFire      FriendlyFire                `yaml:"friendly_fire,omitempty"` // Empty means FriendlyFireSpareCaster
+}
+
+var (
+	rulesetMu     sync.RWMutex
+	activeRuleset *RulesetProfile
+)
+
+// ActiveRuleset returns the server's ruleset profile, or nil when the
+// built-in rules apply.
+func ActiveRuleset() *RulesetProfile {
+	rulesetMu.RLock()
+	defer rulesetMu.RUnlock()
+	return activeRuleset
+}
+
+// SetActiveRuleset selects the ruleset profile used by character creation,
+// level progression and THAC0 calculation. Passing nil restores the
+// built-in rules.
+func SetActiveRuleset(profile *RulesetProfile) {
+	rulesetMu.Lock()
+	defer rulesetMu.Unlock()
+	activeRuleset = profile
+}
+
+// ActiveFriendlyFire returns the friendly fire rule of the active ruleset,
+// or FriendlyFireSpareCaster when it sets none.
+func ActiveFriendlyFire() FriendlyFire {
+	if rules := ActiveRuleset(); rules != nil && rules.FriendlyFire != "" {
+		return rules.FriendlyFire
+	}
+	return FriendlyFireSpareCaster
+}
+
+// LoadRulesetProfile reads and validates a ruleset profile from a YAML
+// file.
+//
+// Parameters:
+//   - path: Path of the profile file
+//
+// Returns:
+//   - *RulesetProfile: The loaded profile
+//   - error: A read or parse failure, or an invalid table
+func LoadRulesetProfile(path string) (*RulesetProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ruleset file: %w", err)
+	}
+
+	var profile RulesetProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse ruleset file %s: %w", path, err)
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid ruleset file %s: %w", path, err)
+	}
+	return &profile, nil
+}
+
+// Validate checks that every table is keyed by a known class and that its
+// rows are in ascending level order. Every class must be covered by an
+// attack matrix, a saving throw table and XP thresholds, either its own or
+// the default one.
+func (r *RulesetProfile) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("ruleset name is required")
+	}
+
+	for _, key := range sortedKeys(r.AttackMatrix) {
+		rows := r.AttackMatrix[key]
+		if err := validateClassKey(key, true); err != nil {
+			return fmt.Errorf("attack matrix: %w", err)
+		}
+		levels := make([]int, len(rows))
+		for i, row := range rows {
+			levels[i] = row.MaxLevel
+		}
+		if err := validateLevelRows(levels); err != nil {
+			return fmt.Errorf("attack matrix for %s: %w", key, err)
+		}
+	}
+
+	for _, key := range sortedKeys(r.SavingThrows) {
+		rows := r.SavingThrows[key]
+		if err := validateClassKey(key, true); err != nil {
+			return fmt.Errorf("saving throws: %w", err)
+		}
+		levels := make([]int, len(rows))
+		for i, row := range rows {
+			levels[i] = row.MaxLevel
+		}
+		if err := validateLevelRows(levels); err != nil {
+			return fmt.Errorf("saving throws for %s: %w", key, err)
+		}
+	}
+
+	for _, key := range sortedKeys(r.XPThresholds) {
+		if err := validateClassKey(key, true); err != nil {
+			return fmt.Errorf("xp thresholds: %w", err)
+		}
+		prev := int64(0)
+		for i, xp := range r.XPThresholds[key] {
+			if xp <= prev {
+				return fmt.Errorf("xp thresholds for %s must be positive and ascending (level %d)", key, i+2)
+			}
+			prev = xp
+		}
+	}
+
+	for _, key := range sortedKeys(r.ClassRestrictions) {
+		if err := validateClassKey(key, false); err != nil {
+			return fmt.Errorf("class restrictions: %w", err)
+		}
+		for attribute := range r.ClassRestrictions[key].MinAttributes {
+			if !isAttributeName(attribute) {
+				return fmt.Errorf("class restrictions for %s: unknown attribute %q", key, attribute)
+			}
+		}
+	}
+
+	switch r.FriendlyFire {
+	case "", FriendlyFireAll, FriendlyFireSpareCaster, FriendlyFireNone:
+	default:
+		return fmt.Errorf("unknown friendly fire rule %q", r.FriendlyFire)
+	}
+
+	for _, class := range allClasses() {
+		if _, ok := classTable(r.AttackMatrix, class); !ok {
+			return fmt.Errorf("no attack matrix covers %s", class)
+		}
+		if _, ok := classTable(r.SavingThrows, class); !ok {
+			return fmt.Errorf("no saving throw table covers %s", class)
+		}
+		if _, ok := classTable(r.XPThresholds, class); !ok {
+			return fmt.Errorf("no xp thresholds cover %s", class)
+		}
+	}
+	return nil
+}
+
+// THAC0 returns the number a character of class and level needs to hit
+// armor class 0.
+func (r *RulesetProfile) THAC0(class CharacterClass, level int) int {
+	rows, _ := classTable(r.AttackMatrix, class)
+	for _, row := range rows {
+		if level <= row.MaxLevel {
+			return row.THAC0
+		}
+	}
+	return rows[len(rows)-1].THAC0
+}
+
+// SavingThrow returns the number a character of class and level must meet
+// or beat on a d20 to save against category, or 20 for an unknown
+// category.
+func (r *RulesetProfile) SavingThrow(class CharacterClass, level int, category SaveCategory) int {
+	rows, _ := classTable(r.SavingThrows, class)
+	row := rows[len(rows)-1]
+	for _, candidate := range rows {
+		if level <= candidate.MaxLevel {
+			row = candidate
+			break
+		}
+	}
+
+	switch category {
+	case SaveParalyzation:
+		return row.Paralyzation
+	case SaveRod:
+		return row.Rod
+	case SavePetrification:
+		return row.Petrification
+	case SaveBreath:
+		return row.Breath
+	case SaveSpell:
+		return row.Spell
+	default:
+		return 20
+	}
+}
+
+// MaxLevel returns the highest level a class can reach: the end of its XP
+// table, or the class restriction's cap if lower.
+func (r *RulesetProfile) MaxLevel(class CharacterClass) int {
+	thresholds, _ := classTable(r.XPThresholds, class)
+	maxLevel := len(thresholds) + 1
+	if restriction, ok := r.ClassRestrictions[classKey(class)]; ok && restriction.MaxLevel > 0 && restriction.MaxLevel < maxLevel {
+		maxLevel = restriction.MaxLevel
+	}
+	return maxLevel
+}
+
+// ExperienceForLevel returns the total experience a character of class
+// needs to reach level, or -1 beyond the class's maximum level.
+func (r *RulesetProfile) ExperienceForLevel(class CharacterClass, level int) int64 {
+	if level <= 1 {
+		return 0
+	}
+	if level > r.MaxLevel(class) {
+		return -1
+	}
+	thresholds, _ := classTable(r.XPThresholds, class)
+	return thresholds[level-2]
+}
+
+// LevelForExperience returns the level a character of class has reached
+// with xp total experience.
+func (r *RulesetProfile) LevelForExperience(class CharacterClass, xp int64) int {
+	level := 1
+	for next := r.ExperienceForLevel(class, level+1); next != -1 && xp >= next; next = r.ExperienceForLevel(class, level+1) {
+		level++
+	}
+	return level
+}
+
+// CheckClass reports whether a character with the given attributes may
+// take class under this ruleset.
+//
+// Parameters:
+//   - class: The class being chosen
+//   - attributes: Ability scores keyed by lowercase attribute name
+//
+// Returns:
+//   - error: Why the class is not available, or nil
+func (r *RulesetProfile) CheckClass(class CharacterClass, attributes map[string]int) error {
+	restriction, ok := r.ClassRestrictions[classKey(class)]
+	if !ok {
+		return nil
+	}
+	if restriction.Unavailable {
+		return fmt.Errorf("%s is not available under the %s ruleset", class, r.Name)
+	}
+	for _, attribute := range sortedKeys(restriction.MinAttributes) {
+		if need := restriction.MinAttributes[attribute]; attributes[attribute] < need {
+			return fmt.Errorf("insufficient %s for %s under the %s ruleset (need %d, have %d)",
+				attribute, class, r.Name, need, attributes[attribute])
+		}
+	}
+	return nil
+}
+
+// rulesetTHAC0 returns the THAC0 of class at level under the active
+// ruleset, or fallback under the built-in rules.
+func rulesetTHAC0(class CharacterClass, level, fallback int) int {
+	if rules := ActiveRuleset(); rules != nil {
+		return rules.THAC0(class, level)
+	}
+	return fallback
+}
+
+// classTable returns the table entry for class, falling back to the
+// default entry.
+func classTable[T any](tables map[string][]T, class CharacterClass) ([]T, bool) {
+	if rows, ok := tables[classKey(class)]; ok && len(rows) > 0 {
+		return rows, true
+	}
+	rows, ok := tables[rulesetDefaultClass]
+	return rows, ok && len(rows) > 0
+}
+
+// classKey returns the table key of a class.
+func classKey(class CharacterClass) string {
+	return strings.ToLower(class.String())
+}
+
+// allClasses returns every character class.
+func allClasses() []CharacterClass {
+	return []CharacterClass{ClassFighter, ClassMage, ClassCleric, ClassThief, ClassRanger, ClassPaladin}
+}
+
+// validateClassKey checks that a table key names a class, or is the
+// default entry where one is allowed.
+func validateClassKey(key string, allowDefault bool) error {
+	if allowDefault && key == rulesetDefaultClass {
+		return nil
+	}
+	for _, class := range allClasses() {
+		if key == classKey(class) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown class %q", key)
+}
+
+// validateLevelRows checks that a table has rows and that their maximum
+// levels ascend from at least 1.
+func validateLevelRows(maxLevels []int) error {
+	if len(maxLevels) == 0 {
+		return fmt.Errorf("table has no rows")
+	}
+	prev := 0
+	for _, maxLevel := range maxLevels {
+		if maxLevel <= prev {
+			return fmt.Errorf("row max_level %d must be greater than %d", maxLevel, prev)
+		}
+		prev = maxLevel
+	}
+	return nil
+}
+
+// isAttributeName reports whether name is a lowercase ability score name.
+func isAttributeName(name string) bool {
+	switch name {
+	case "strength", "dexterity", "constitution", "intelligence", "wisdom", "charisma":
+		return true
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useRuleset activates the named profile from data/rulesets for the test
+func useRuleset(t *testing.T, name string) *RulesetProfile {
+	t.Helper()
+	profile, err := LoadRulesetProfile(filepath.Join("..", "..", "data", "rulesets", name+".yaml"))
+	require.NoError(t, err)
+	SetActiveRuleset(profile)
+	t.Cleanup(func() { SetActiveRuleset(nil) })
+	return profile
+}
+
+func TestLoadRulesetProfile_ShippedProfiles(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "data", "rulesets", "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		profile, err := LoadRulesetProfile(file)
+		require.NoError(t, err, file)
+		assert.Equal(t, filepath.Base(file), profile.Name+".yaml")
+	}
+}
+
+func TestRulesetProfile_Tables(t *testing.T) {
+	first, err := LoadRulesetProfile(filepath.Join("..", "..", "data", "rulesets", "adnd1e.yaml"))
+	require.NoError(t, err)
+	second, err := LoadRulesetProfile(filepath.Join("..", "..", "data", "rulesets", "adnd2e.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 16, first.THAC0(ClassFighter, 5))
+	assert.Equal(t, 4, first.THAC0(ClassFighter, 30), "the last row covers higher levels")
+	assert.Equal(t, 21, first.THAC0(ClassMage, 1))
+	assert.Equal(t, 16, second.THAC0(ClassFighter, 5))
+	assert.Equal(t, 20, second.THAC0(ClassMage, 1))
+
+	assert.Equal(t, 11, second.SavingThrow(ClassFighter, 5, SaveParalyzation))
+	assert.Equal(t, 12, second.SavingThrow(ClassMage, 1, SaveSpell))
+	assert.Equal(t, 20, second.SavingThrow(ClassMage, 1, SaveCategory("gaze")))
+
+	assert.Equal(t, int64(0), second.ExperienceForLevel(ClassThief, 1))
+	assert.Equal(t, int64(1250), second.ExperienceForLevel(ClassThief, 2))
+	assert.Equal(t, int64(-1), second.ExperienceForLevel(ClassThief, 14))
+	assert.Equal(t, 5, second.LevelForExperience(ClassFighter, 16000))
+	assert.Equal(t, 4, first.LevelForExperience(ClassFighter, 16000))
+}
+
+func TestRulesetProfile_DefaultTablesAndRestrictions(t *testing.T) {
+	house, err := LoadRulesetProfile(filepath.Join("..", "..", "data", "rulesets", "house.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, house.THAC0(ClassFighter, 3), house.THAC0(ClassMage, 3))
+	assert.Equal(t, 10, house.MaxLevel(ClassThief))
+	assert.Equal(t, 10, house.LevelForExperience(ClassThief, 10_000_000))
+
+	assert.ErrorContains(t, house.CheckClass(ClassPaladin, nil), "not available")
+	assert.ErrorContains(t, house.CheckClass(ClassMage, map[string]int{"intelligence": 11}), "insufficient intelligence")
+	assert.NoError(t, house.CheckClass(ClassMage, map[string]int{"intelligence": 12}))
+	assert.NoError(t, house.CheckClass(ClassFighter, nil))
+}
+
+func TestRulesetProfile_Validate(t *testing.T) {
+	valid := func() *RulesetProfile {
+		return &RulesetProfile{
+			Name:         "test",
+			AttackMatrix: map[string][]AttackRow{"default": {{MaxLevel: 5, THAC0: 20}}},
+			SavingThrows: map[string][]SaveRow{"default": {{MaxLevel: 5, Spell: 15}}},
+			XPThresholds: map[string][]int64{"default": {1000, 2000}},
+		}
+	}
+	require.NoError(t, valid().Validate())
+
+	tests := []struct {
+		name    string
+		mutate  func(r *RulesetProfile)
+		message string
+	}{
+		{"no name", func(r *RulesetProfile) { r.Name = "" }, "name is required"},
+		{"unknown class", func(r *RulesetProfile) { r.AttackMatrix["bard"] = []AttackRow{{MaxLevel: 1, THAC0: 20}} }, `unknown class "bard"`},
+		{"rows out of order", func(r *RulesetProfile) {
+			r.SavingThrows["mage"] = []SaveRow{{MaxLevel: 5}, {MaxLevel: 3}}
+		}, "saving throws for mage"},
+		{"descending xp", func(r *RulesetProfile) { r.XPThresholds["thief"] = []int64{2000, 1000} }, "xp thresholds for thief"},
+		{"class not covered", func(r *RulesetProfile) {
+			delete(r.AttackMatrix, "default")
+			r.AttackMatrix["fighter"] = []AttackRow{{MaxLevel: 1, THAC0: 20}}
+		}, "no attack matrix covers Mage"},
+		{"unknown attribute", func(r *RulesetProfile) {
+			r.ClassRestrictions = map[string]ClassRestriction{"mage": {MinAttributes: map[string]int{"luck": 12}}}
+		}, `unknown attribute "luck"`},
+		{"default restriction", func(r *RulesetProfile) {
+			r.ClassRestrictions = map[string]ClassRestriction{"default": {MaxLevel: 5}}
+		}, `unknown class "default"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := valid()
+			tt.mutate(profile)
+			assert.ErrorContains(t, profile.Validate(), tt.message)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "broken.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("name: broken\n"), 0o644))
+	_, err := LoadRulesetProfile(path)
+	assert.ErrorContains(t, err, "invalid ruleset file")
+}
+
+func TestRulesetProfile_DrivesProgression(t *testing.T) {
+	useRuleset(t, "adnd2e")
+
+	player := &Player{Character: Character{ID: "p1", Class: ClassFighter, Level: 1, THAC0: 20}}
+	require.NoError(t, player.AddExperience(2000))
+	assert.Equal(t, 2, player.Level)
+	assert.Equal(t, 19, player.THAC0)
+
+	char := &Character{ID: "c1", Class: ClassThief, Level: 1}
+	assert.Equal(t, int64(1250), char.GetExperienceToNextLevel())
+	leveled, err := char.AddExperience(2500)
+	require.NoError(t, err)
+	assert.True(t, leveled)
+	assert.Equal(t, 3, char.Level)
+	assert.Equal(t, 19, char.THAC0)
+
+	SetActiveRuleset(nil)
+	builtIn := &Player{Character: Character{ID: "p2", Class: ClassFighter, Level: 1, THAC0: 20}}
+	require.NoError(t, builtIn.AddExperience(2000))
+	assert.Equal(t, 2, builtIn.Level)
+	assert.Equal(t, 20, builtIn.THAC0, "the built-in rules leave THAC0 alone")
+}
+
+func TestRulesetProfile_ReplacesClassRequirements(t *testing.T) {
+	useRuleset(t, "house")
+	creator := NewCharacterCreatorWithSeed(1)
+	attributes := map[string]int{
+		"strength": 18, "dexterity": 12, "constitution": 12,
+		"intelligence": 12, "wisdom": 12, "charisma": 18,
+	}
+
+	result := creator.CreateCharacter(CharacterCreationConfig{
+		Name: "Sir Test", Class: ClassPaladin, AttributeMethod: "custom", CustomAttributes: attributes,
+	})
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Errors[0], "not available under the house ruleset")
+
+	attributes["dexterity"] = 8 // below the built-in thief requirement, which house rules drop
+	result = creator.CreateCharacter(CharacterCreationConfig{
+		Name: "Quickfingers", Class: ClassThief, AttributeMethod: "custom", CustomAttributes: attributes,
+	})
+	require.True(t, result.Success, result.Errors)
+	assert.Equal(t, 19, result.Character.THAC0)
+}
@@ -1,18 +1,27 @@
 package game
 
 import (
+	"container/heap"
 	"fmt"
-	"math"
 	"sync"
 )
 
-// SpatialIndex provides efficient spatial queries for game objects
-// Implements an R-tree-like spatial data structure optimized for 2D game worlds
+// spatialNodeCapacity is the number of objects a quadtree leaf holds before
+// it splits into quadrants. A subtree holding no more than this collapses
+// back into a single leaf when objects leave it.
+const spatialNodeCapacity = 8
+
+// SpatialIndex provides efficient spatial queries for game objects.
+// Implements a quadtree: leaves split into four quadrants as they fill and
+// merge again as they empty, down to cells of cellSize. Each object's leaf
+// is tracked by ID, so removals and position updates touch only the
+// object's own branch instead of searching the tree.
 type SpatialIndex struct {
-	mu       sync.RWMutex
-	root     *SpatialNode
-	cellSize int
-	bounds   Rectangle
+	mu        sync.RWMutex
+	root      *SpatialNode
+	cellSize  int
+	bounds    Rectangle
+	locations map[string]*SpatialNode // Leaf holding each object, by ID
 }
 
 // SpatialNode represents a node in the spatial index tree
@@ -20,6 +29,8 @@ type SpatialNode struct {
 	bounds   Rectangle
 	objects  []GameObject
 	children []*SpatialNode
+	parent   *SpatialNode
+	count    int // Objects in this node's subtree
 	isLeaf   bool
 }
 
@@ -47,10 +58,12 @@ func NewSpatialIndex(width, height, cellSize int) *SpatialIndex {
 			isLeaf:  true,
 			objects: make([]GameObject, 0),
 		},
+		locations: make(map[string]*SpatialNode),
 	}
 }
 
-// Insert adds a game object to the spatial index
+// Insert adds a game object to the spatial index. Inserting an object that
+// is already indexed moves it to its current position.
 func (si *SpatialIndex) Insert(obj GameObject) error {
 	si.mu.Lock()
 	defer si.mu.Unlock()
@@ -60,6 +73,9 @@ func (si *SpatialIndex) Insert(obj GameObject) error {
 		return fmt.Errorf("object position %v is outside spatial index bounds", pos)
 	}
 
+	if leaf, exists := si.locations[obj.GetID()]; exists {
+		si.detach(leaf, obj.GetID())
+	}
 	return si.insertNode(si.root, obj)
 }
 
@@ -68,7 +84,57 @@ func (si *SpatialIndex) Remove(objectID string) error {
 	si.mu.Lock()
 	defer si.mu.Unlock()
 
-	return si.removeNode(si.root, objectID)
+	leaf, exists := si.locations[objectID]
+	if !exists {
+		return fmt.Errorf("object %s not found", objectID)
+	}
+	si.detach(leaf, objectID)
+	return nil
+}
+
+// Rebuild replaces the contents of the index with objects, building the
+// tree in a single pass rather than splitting leaves insert by insert.
+// Objects outside the index bounds are left out and reported in the
+// returned error; all others are indexed. When objects repeats an ID the
+// last occurrence wins, as with Insert.
+func (si *SpatialIndex) Rebuild(objects []GameObject) error {
+	indexed := make([]GameObject, 0, len(objects))
+	positions := make(map[string]int, len(objects))
+	var outside []string
+
+	for _, obj := range objects {
+		if !si.contains(si.bounds, obj.GetPosition()) {
+			outside = append(outside, obj.GetID())
+			continue
+		}
+		if i, seen := positions[obj.GetID()]; seen {
+			indexed[i] = obj
+			continue
+		}
+		positions[obj.GetID()] = len(indexed)
+		indexed = append(indexed, obj)
+	}
+
+	locations := make(map[string]*SpatialNode, len(indexed))
+	root := si.buildNode(si.bounds, nil, indexed, locations)
+
+	si.mu.Lock()
+	si.root = root
+	si.locations = locations
+	si.mu.Unlock()
+
+	if len(outside) > 0 {
+		return fmt.Errorf("%d objects outside spatial index bounds: %v", len(outside), outside)
+	}
+	return nil
+}
+
+// Len returns the number of indexed objects
+func (si *SpatialIndex) Len() int {
+	si.mu.RLock()
+	defer si.mu.RUnlock()
+
+	return si.root.count
 }
 
 // GetObjectsInRange returns all objects within a rectangular area
@@ -103,13 +169,7 @@ func (si *SpatialIndex) GetObjectsInRadius(center Position, radius float64) []Ga
 	result := make([]GameObject, 0, len(candidates)) // Pre-allocate to avoid reallocation
 
 	for _, obj := range candidates {
-		objPos := obj.GetPosition()
-		// Use distance squared to avoid expensive sqrt operation
-		dx := float64(center.X - objPos.X)
-		dy := float64(center.Y - objPos.Y)
-		distanceSquared := dx*dx + dy*dy
-
-		if distanceSquared <= radiusSquared {
+		if distanceSquared(center, obj.GetPosition()) <= radiusSquared {
 			result = append(result, obj)
 		}
 	}
@@ -117,36 +177,41 @@ func (si *SpatialIndex) GetObjectsInRadius(center Position, radius float64) []Ga
 	return result
 }
 
-// GetNearestObjects returns the k nearest objects to a given position
+// GetNearestObjects returns the k nearest objects to a given position,
+// nearest first. Nodes are visited best-first by their distance from
+// center, so only the branches that can hold one of the k nearest objects
+// are searched.
 func (si *SpatialIndex) GetNearestObjects(center Position, k int) []GameObject {
 	si.mu.RLock()
 	defer si.mu.RUnlock()
 
-	// Start with a small radius and expand as needed
-	radius := float64(si.cellSize)
-	maxRadius := float64(maxInt(si.bounds.MaxX-si.bounds.MinX, si.bounds.MaxY-si.bounds.MinY))
-
-	for radius <= maxRadius {
-		objects := si.GetObjectsInRadius(center, radius)
-		if len(objects) >= k {
-			// Sort by distance and return k nearest
-			si.sortByDistance(objects, center)
-			if len(objects) > k {
-				return objects[:k]
+	if k <= 0 {
+		return []GameObject{}
+	}
+
+	result := make([]GameObject, 0, minInt(k, si.root.count))
+	queue := &nearestQueue{}
+	queue.push(nearestEntry{node: si.root, distance: boundsDistanceSquared(si.root.bounds, center)})
+
+	for queue.Len() > 0 && len(result) < k {
+		entry := heap.Pop(queue).(nearestEntry)
+		switch {
+		case entry.obj != nil:
+			result = append(result, entry.obj)
+		case entry.node.isLeaf:
+			for _, obj := range entry.node.objects {
+				queue.push(nearestEntry{obj: obj, distance: distanceSquared(center, obj.GetPosition())})
+			}
+		default:
+			for _, child := range entry.node.children {
+				if child.count > 0 {
+					queue.push(nearestEntry{node: child, distance: boundsDistanceSquared(child.bounds, center)})
+				}
 			}
-			return objects
 		}
-		radius *= 2
 	}
 
-	// If we still don't have enough, return all objects sorted by distance
-	var allObjects []GameObject
-	si.queryNode(si.root, si.bounds, &allObjects)
-	si.sortByDistance(allObjects, center)
-	if len(allObjects) > k {
-		return allObjects[:k]
-	}
-	return allObjects
+	return result
 }
 
 // GetObjectsAt returns all objects at an exact position (optimized for single-cell queries)
@@ -169,7 +234,10 @@ func (si *SpatialIndex) GetObjectsAt(pos Position) []GameObject {
 	return result
 }
 
-// Update moves an object to a new position in the spatial index
+// Update moves an object to a new position in the spatial index. The
+// object's own position should already be newPos. An object that stays
+// within its leaf is left in place; otherwise it is moved to the leaf
+// covering its new position.
 func (si *SpatialIndex) Update(objectID string, newPos Position) error {
 	si.mu.Lock()
 	defer si.mu.Unlock()
@@ -179,13 +247,15 @@ func (si *SpatialIndex) Update(objectID string, newPos Position) error {
 		return fmt.Errorf("new position %v is outside spatial index bounds", newPos)
 	}
 
-	// Find and remove the object
-	var obj GameObject
-	if err := si.removeNodeWithObject(si.root, objectID, &obj); err != nil {
-		return fmt.Errorf("object %s not found for update: %w", objectID, err)
+	leaf, exists := si.locations[objectID]
+	if !exists {
+		return fmt.Errorf("object %s not found for update", objectID)
 	}
 
-	// Re-insert at new position (object position should already be updated by caller)
+	obj := si.detachIfMoved(leaf, objectID)
+	if obj == nil {
+		return nil
+	}
 	return si.insertNode(si.root, obj)
 }
 
@@ -199,6 +269,7 @@ func (si *SpatialIndex) Clear() {
 		isLeaf:  true,
 		objects: make([]GameObject, 0),
 	}
+	si.locations = make(map[string]*SpatialNode)
 }
 
 // GetStats returns statistics about the spatial index
@@ -208,6 +279,9 @@ func (si *SpatialIndex) GetStats() SpatialIndexStats {
 
 	stats := SpatialIndexStats{}
 	si.collectStats(si.root, &stats, 0)
+	if stats.LeafNodes > 0 {
+		stats.AvgObjectsPerLeaf = float64(stats.TotalObjects) / float64(stats.LeafNodes)
+	}
 	return stats
 }
 
@@ -222,6 +296,8 @@ type SpatialIndexStats struct {
 
 // Private helper methods
 
+// insertNode adds obj to the leaf under node that covers its position,
+// splitting the leaf if it overflows
 func (si *SpatialIndex) insertNode(node *SpatialNode, obj GameObject) error {
 	pos := obj.GetPosition()
 
@@ -229,73 +305,141 @@ func (si *SpatialIndex) insertNode(node *SpatialNode, obj GameObject) error {
 		return fmt.Errorf("object position %v outside node bounds %v", pos, node.bounds)
 	}
 
-	if node.isLeaf {
-		node.objects = append(node.objects, obj)
-
-		// Split if node becomes too full
-		if len(node.objects) > 8 && si.canSplit(node.bounds) {
-			si.splitNode(node)
+	for !node.isLeaf {
+		child := si.childFor(node, pos)
+		if child == nil {
+			return fmt.Errorf("no suitable child node found for position %v", pos)
 		}
-		return nil
+		node = child
 	}
 
-	// Find best child node
-	for _, child := range node.children {
-		if si.contains(child.bounds, pos) {
-			return si.insertNode(child, obj)
-		}
+	node.objects = append(node.objects, obj)
+	si.locations[obj.GetID()] = node
+	for n := node; n != nil; n = n.parent {
+		n.count++
 	}
 
-	return fmt.Errorf("no suitable child node found for position %v", pos)
+	// Split if node becomes too full
+	if len(node.objects) > spatialNodeCapacity && si.canSplit(node.bounds) {
+		si.splitNode(node)
+	}
+	return nil
 }
 
-func (si *SpatialIndex) removeNode(node *SpatialNode, objectID string) error {
-	if node.isLeaf {
-		for i, obj := range node.objects {
-			if obj.GetID() == objectID {
-				// Remove object by swapping with last element
-				node.objects[i] = node.objects[len(node.objects)-1]
-				node.objects = node.objects[:len(node.objects)-1]
+// detachIfMoved removes the object from leaf and returns it when its
+// position has left the leaf's bounds; it returns nil if the object can
+// stay where it is
+func (si *SpatialIndex) detachIfMoved(leaf *SpatialNode, objectID string) GameObject {
+	for _, obj := range leaf.objects {
+		if obj.GetID() == objectID {
+			if si.contains(leaf.bounds, obj.GetPosition()) {
 				return nil
 			}
+			return si.detach(leaf, objectID)
 		}
-		return fmt.Errorf("object %s not found", objectID)
 	}
+	return nil
+}
 
-	// Recursively search children
-	for _, child := range node.children {
-		if err := si.removeNode(child, objectID); err == nil {
-			return nil
+// detach removes an object from its leaf, updates the subtree counts and
+// collapses branches that have become sparse
+func (si *SpatialIndex) detach(leaf *SpatialNode, objectID string) GameObject {
+	var removed GameObject
+	for i, obj := range leaf.objects {
+		if obj.GetID() == objectID {
+			removed = obj
+			// Remove object by swapping with last element
+			leaf.objects[i] = leaf.objects[len(leaf.objects)-1]
+			leaf.objects = leaf.objects[:len(leaf.objects)-1]
+			break
 		}
 	}
+	delete(si.locations, objectID)
+	if removed == nil {
+		return nil
+	}
+
+	for n := leaf; n != nil; n = n.parent {
+		n.count--
+	}
+
+	// Collapse the highest ancestor that now fits in a single leaf
+	var sparse *SpatialNode
+	for n := leaf.parent; n != nil && n.count <= spatialNodeCapacity; n = n.parent {
+		sparse = n
+	}
+	if sparse != nil {
+		si.mergeNode(sparse)
+	}
+
+	return removed
+}
 
-	return fmt.Errorf("object %s not found in any child", objectID)
+// mergeNode turns an internal node back into a leaf holding every object
+// of its subtree
+func (si *SpatialIndex) mergeNode(node *SpatialNode) {
+	objects := make([]GameObject, 0, node.count)
+	si.collectObjects(node, &objects)
+
+	node.children = nil
+	node.objects = objects
+	node.isLeaf = true
+	for _, obj := range objects {
+		si.locations[obj.GetID()] = node
+	}
 }
 
-func (si *SpatialIndex) removeNodeWithObject(node *SpatialNode, objectID string, obj *GameObject) error {
+// collectObjects appends every object in node's subtree to result
+func (si *SpatialIndex) collectObjects(node *SpatialNode, result *[]GameObject) {
 	if node.isLeaf {
-		for i, o := range node.objects {
-			if o.GetID() == objectID {
-				*obj = o
-				node.objects[i] = node.objects[len(node.objects)-1]
-				node.objects = node.objects[:len(node.objects)-1]
-				return nil
-			}
+		*result = append(*result, node.objects...)
+		return
+	}
+	for _, child := range node.children {
+		si.collectObjects(child, result)
+	}
+}
+
+// buildNode builds the subtree covering bounds for objects, all of which
+// lie within bounds, recording each object's leaf in locations
+func (si *SpatialIndex) buildNode(bounds Rectangle, parent *SpatialNode, objects []GameObject, locations map[string]*SpatialNode) *SpatialNode {
+	node := &SpatialNode{
+		bounds: bounds,
+		parent: parent,
+		count:  len(objects),
+		isLeaf: true,
+	}
+
+	if len(objects) <= spatialNodeCapacity || !si.canSplit(bounds) {
+		node.objects = append(make([]GameObject, 0, len(objects)), objects...)
+		for _, obj := range objects {
+			locations[obj.GetID()] = node
 		}
-		return fmt.Errorf("object %s not found", objectID)
+		return node
 	}
 
-	for _, child := range node.children {
-		if err := si.removeNodeWithObject(child, objectID, obj); err == nil {
-			return nil
+	quadrants := si.quadrants(bounds)
+	parts := make([][]GameObject, len(quadrants))
+	for _, obj := range objects {
+		pos := obj.GetPosition()
+		for i, quadrant := range quadrants {
+			if si.contains(quadrant, pos) {
+				parts[i] = append(parts[i], obj)
+				break
+			}
 		}
 	}
 
-	return fmt.Errorf("object %s not found in any child", objectID)
+	node.isLeaf = false
+	node.children = make([]*SpatialNode, len(quadrants))
+	for i, quadrant := range quadrants {
+		node.children[i] = si.buildNode(quadrant, node, parts[i], locations)
+	}
+	return node
 }
 
 func (si *SpatialIndex) queryNode(node *SpatialNode, rect Rectangle, result *[]GameObject) {
-	if !si.intersects(node.bounds, rect) {
+	if node.count == 0 || !si.intersects(node.bounds, rect) {
 		return
 	}
 
@@ -319,38 +463,64 @@ func (si *SpatialIndex) splitNode(node *SpatialNode) {
 		return
 	}
 
-	bounds := node.bounds
-	midX := (bounds.MinX + bounds.MaxX) / 2
-	midY := (bounds.MinY + bounds.MaxY) / 2
-
 	// Create four child nodes
-	node.children = []*SpatialNode{
-		{bounds: Rectangle{bounds.MinX, bounds.MinY, midX, midY}, isLeaf: true, objects: make([]GameObject, 0)},
-		{bounds: Rectangle{midX, bounds.MinY, bounds.MaxX, midY}, isLeaf: true, objects: make([]GameObject, 0)},
-		{bounds: Rectangle{bounds.MinX, midY, midX, bounds.MaxY}, isLeaf: true, objects: make([]GameObject, 0)},
-		{bounds: Rectangle{midX, midY, bounds.MaxX, bounds.MaxY}, isLeaf: true, objects: make([]GameObject, 0)},
+	quadrants := si.quadrants(node.bounds)
+	node.children = make([]*SpatialNode, len(quadrants))
+	for i, quadrant := range quadrants {
+		node.children[i] = &SpatialNode{bounds: quadrant, parent: node, isLeaf: true, objects: make([]GameObject, 0)}
 	}
 
 	// Redistribute objects to children
 	for _, obj := range node.objects {
-		pos := obj.GetPosition()
-		for _, child := range node.children {
-			if si.contains(child.bounds, pos) {
-				child.objects = append(child.objects, obj)
-				break
-			}
-		}
+		child := si.childFor(node, obj.GetPosition())
+		child.objects = append(child.objects, obj)
+		child.count++
+		si.locations[obj.GetID()] = child
 	}
 
 	// Clear parent objects and mark as non-leaf
 	node.objects = nil
 	node.isLeaf = false
+
+	// A quadrant that received every object may itself need splitting
+	for _, child := range node.children {
+		if len(child.objects) > spatialNodeCapacity && si.canSplit(child.bounds) {
+			si.splitNode(child)
+		}
+	}
+}
+
+// quadrants divides bounds into four quadrants. Quadrants share their
+// middle row and column; objects on them belong to the first quadrant
+// that contains them.
+func (si *SpatialIndex) quadrants(bounds Rectangle) []Rectangle {
+	midX := (bounds.MinX + bounds.MaxX) / 2
+	midY := (bounds.MinY + bounds.MaxY) / 2
+
+	return []Rectangle{
+		{bounds.MinX, bounds.MinY, midX, midY},
+		{midX, bounds.MinY, bounds.MaxX, midY},
+		{bounds.MinX, midY, midX, bounds.MaxY},
+		{midX, midY, bounds.MaxX, bounds.MaxY},
+	}
+}
+
+// childFor returns the child of node that pos belongs to
+func (si *SpatialIndex) childFor(node *SpatialNode, pos Position) *SpatialNode {
+	for _, child := range node.children {
+		if si.contains(child.bounds, pos) {
+			return child
+		}
+	}
+	return nil
 }
 
 func (si *SpatialIndex) canSplit(bounds Rectangle) bool {
+	// Cells narrower than one tile cannot be divided further
+	minSize := maxInt(si.cellSize, 1)
 	width := bounds.MaxX - bounds.MinX
 	height := bounds.MaxY - bounds.MinY
-	return width > si.cellSize && height > si.cellSize
+	return width > minSize && height > minSize
 }
 
 func (si *SpatialIndex) contains(rect Rectangle, pos Position) bool {
@@ -363,24 +533,6 @@ func (si *SpatialIndex) intersects(rect1, rect2 Rectangle) bool {
 		rect1.MinY <= rect2.MaxY && rect1.MaxY >= rect2.MinY
 }
 
-func (si *SpatialIndex) distance(pos1, pos2 Position) float64 {
-	dx := float64(pos1.X - pos2.X)
-	dy := float64(pos1.Y - pos2.Y)
-	return math.Sqrt(dx*dx + dy*dy)
-}
-
-func (si *SpatialIndex) sortByDistance(objects []GameObject, center Position) {
-	for i := 0; i < len(objects)-1; i++ {
-		for j := i + 1; j < len(objects); j++ {
-			dist1 := si.distance(center, objects[i].GetPosition())
-			dist2 := si.distance(center, objects[j].GetPosition())
-			if dist1 > dist2 {
-				objects[i], objects[j] = objects[j], objects[i]
-			}
-		}
-	}
-}
-
 func (si *SpatialIndex) collectStats(node *SpatialNode, stats *SpatialIndexStats, depth int) {
 	stats.TotalNodes++
 	if depth > stats.MaxDepth {
@@ -397,4 +549,62 @@ func (si *SpatialIndex) collectStats(node *SpatialNode, stats *SpatialIndexStats
 	}
 }
 
-// maxInt function is now defined in utils.go
+// distanceSquared returns the squared distance between two positions
+func distanceSquared(pos1, pos2 Position) float64 {
+	dx := float64(pos1.X - pos2.X)
+	dy := float64(pos1.Y - pos2.Y)
+	return dx*dx + dy*dy
+}
+
+// boundsDistanceSquared returns the squared distance from pos to the
+// nearest point of rect, zero if rect contains pos
+func boundsDistanceSquared(rect Rectangle, pos Position) float64 {
+	dx := maxInt(maxInt(rect.MinX-pos.X, 0), pos.X-rect.MaxX)
+	dy := maxInt(maxInt(rect.MinY-pos.Y, 0), pos.Y-rect.MaxY)
+	return float64(dx*dx + dy*dy)
+}
+
+// nearestEntry is a node or object waiting in a nearest-neighbour search
+type nearestEntry struct {
+	node     *SpatialNode
+	obj      GameObject
+	distance float64 // Squared distance from the search center
+	seq      int     // Push order, keeps equally distant objects stable
+}
+
+// nearestQueue is a min-heap of nearest-neighbour search entries
+type nearestQueue struct {
+	entries []nearestEntry
+	pushed  int
+}
+
+func (q *nearestQueue) push(entry nearestEntry) {
+	entry.seq = q.pushed
+	q.pushed++
+	heap.Push(q, entry)
+}
+
+func (q *nearestQueue) Len() int { return len(q.entries) }
+
+func (q *nearestQueue) Less(i, j int) bool {
+	a, b := q.entries[i], q.entries[j]
+	if a.distance != b.distance {
+		return a.distance < b.distance
+	}
+	// Objects before nodes at the same distance: a node's objects are
+	// never nearer than the node itself
+	if (a.obj != nil) != (b.obj != nil) {
+		return a.obj != nil
+	}
+	return a.seq < b.seq
+}
+
+func (q *nearestQueue) Swap(i, j int) { q.entries[i], q.entries[j] = q.entries[j], q.entries[i] }
+
+func (q *nearestQueue) Push(x interface{}) { q.entries = append(q.entries, x.(nearestEntry)) }
+
+func (q *nearestQueue) Pop() interface{} {
+	last := q.entries[len(q.entries)-1]
+	q.entries = q.entries[:len(q.entries)-1]
+	return last
+}
@@ -289,6 +289,190 @@ func BenchmarkGetObjectsInRadius(b *testing.B) {
 	}
 }
 
+func TestSpatialIndex_SplitAndCollapse(t *testing.T) {
+	index := NewSpatialIndex(100, 100, 10)
+
+	for i := 0; i < 40; i++ {
+		index.Insert(&TestGameObject{id: fmt.Sprintf("obj%d", i), position: Position{X: (i * 13) % 100, Y: (i * 29) % 100}})
+	}
+	if stats := index.GetStats(); stats.LeafNodes < 4 {
+		t.Fatalf("Expected the index to split, got %d leaves", stats.LeafNodes)
+	}
+
+	for i := 0; i < 35; i++ {
+		if err := index.Remove(fmt.Sprintf("obj%d", i)); err != nil {
+			t.Fatalf("Failed to remove obj%d: %v", i, err)
+		}
+	}
+
+	stats := index.GetStats()
+	if stats.TotalNodes != 1 || stats.TotalObjects != 5 {
+		t.Errorf("Expected a single leaf with 5 objects after removals, got %d nodes and %d objects", stats.TotalNodes, stats.TotalObjects)
+	}
+	if index.Len() != 5 {
+		t.Errorf("Expected Len 5, got %d", index.Len())
+	}
+	if objects := index.GetObjectsInRange(Rectangle{MinX: 0, MinY: 0, MaxX: 99, MaxY: 99}); len(objects) != 5 {
+		t.Errorf("Expected 5 objects to remain queryable, got %d", len(objects))
+	}
+}
+
+func TestSpatialIndex_InsertExistingObject(t *testing.T) {
+	index := NewSpatialIndex(100, 100, 10)
+	obj := &TestGameObject{id: "obj1", position: Position{X: 10, Y: 10}}
+
+	index.Insert(obj)
+	obj.position = Position{X: 70, Y: 20}
+	if err := index.Insert(obj); err != nil {
+		t.Fatalf("Failed to re-insert object: %v", err)
+	}
+
+	if index.Len() != 1 {
+		t.Errorf("Re-inserting should not duplicate the object, got %d objects", index.Len())
+	}
+	if objects := index.GetObjectsAt(Position{X: 10, Y: 10}); len(objects) != 0 {
+		t.Errorf("Expected old position to be empty, got %d objects", len(objects))
+	}
+}
+
+func TestSpatialIndex_Rebuild(t *testing.T) {
+	index := NewSpatialIndex(100, 100, 10)
+	index.Insert(&TestGameObject{id: "stale", position: Position{X: 1, Y: 1}})
+
+	objects := make([]GameObject, 0, 50)
+	for i := 0; i < 50; i++ {
+		objects = append(objects, &TestGameObject{id: fmt.Sprintf("obj%d", i), position: Position{X: i * 2, Y: 99 - i}})
+	}
+	objects = append(objects, &TestGameObject{id: "outside", position: Position{X: 150, Y: 5}})
+
+	if err := index.Rebuild(objects); err == nil {
+		t.Error("Expected an error reporting the object outside the bounds")
+	}
+	if index.Len() != 50 {
+		t.Errorf("Expected 50 indexed objects, got %d", index.Len())
+	}
+	if objects := index.GetObjectsAt(Position{X: 1, Y: 1}); len(objects) != 0 {
+		t.Error("Rebuild should drop objects that are no longer listed")
+	}
+
+	// The rebuilt tree supports incremental updates
+	moved := objects[10].(*TestGameObject)
+	moved.position = Position{X: 5, Y: 5}
+	if err := index.Update(moved.id, moved.position); err != nil {
+		t.Fatalf("Failed to update rebuilt object: %v", err)
+	}
+	if found := index.GetObjectsAt(Position{X: 5, Y: 5}); len(found) != 1 || found[0].GetID() != moved.id {
+		t.Errorf("Expected %s at its new position, got %v", moved.id, found)
+	}
+}
+
+func TestSpatialIndex_NearestMatchesBruteForce(t *testing.T) {
+	index := NewSpatialIndex(500, 500, 16)
+	var objects []*TestGameObject
+	for i := 0; i < 300; i++ {
+		obj := &TestGameObject{id: fmt.Sprintf("obj%d", i), position: Position{X: (i * 37) % 500, Y: (i * 91) % 500}}
+		objects = append(objects, obj)
+		index.Insert(obj)
+	}
+
+	for _, center := range []Position{{X: 0, Y: 0}, {X: 250, Y: 250}, {X: 499, Y: 17}} {
+		nearest := index.GetNearestObjects(center, 10)
+		if len(nearest) != 10 {
+			t.Fatalf("Expected 10 nearest objects, got %d", len(nearest))
+		}
+
+		// The 10th nearest distance must match a brute-force scan
+		closer := 0
+		limit := distanceSquared(center, nearest[9].GetPosition())
+		for _, obj := range objects {
+			if distanceSquared(center, obj.position) < limit {
+				closer++
+			}
+		}
+		if closer > 9 {
+			t.Errorf("Found %d objects closer to %v than the 10th nearest", closer, center)
+		}
+		for i := 1; i < len(nearest); i++ {
+			if distanceSquared(center, nearest[i-1].GetPosition()) > distanceSquared(center, nearest[i].GetPosition()) {
+				t.Errorf("Nearest objects around %v are not sorted by distance", center)
+			}
+		}
+	}
+
+	if nearest := index.GetNearestObjects(Position{X: 1, Y: 1}, 0); len(nearest) != 0 {
+		t.Errorf("Expected no objects for k=0, got %d", len(nearest))
+	}
+}
+
+// populateBenchmarkIndex fills an index with a grid of objects
+func populateBenchmarkIndex(b *testing.B, size, count int) (*SpatialIndex, []*TestGameObject) {
+	b.Helper()
+	index := NewSpatialIndex(size, size, 16)
+	objects := make([]*TestGameObject, count)
+	for i := range objects {
+		objects[i] = &TestGameObject{
+			id:       fmt.Sprintf("obj_%d", i),
+			position: Position{X: (i * 7919) % size, Y: (i * 104729) % size},
+		}
+		if err := index.Insert(objects[i]); err != nil {
+			b.Fatalf("Failed to insert object %d: %v", i, err)
+		}
+	}
+	return index, objects
+}
+
+// BenchmarkGetNearestObjects tests the performance of k-nearest queries on a large map
+func BenchmarkGetNearestObjects(b *testing.B) {
+	index, _ := populateBenchmarkIndex(b, 4000, 20000)
+	center := Position{X: 2000, Y: 2000}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = index.GetNearestObjects(center, 10)
+	}
+}
+
+// BenchmarkGetObjectsInRadius_LargeMap tests radius queries on a large, crowded map
+func BenchmarkGetObjectsInRadius_LargeMap(b *testing.B) {
+	index, _ := populateBenchmarkIndex(b, 4000, 20000)
+	center := Position{X: 2000, Y: 2000}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = index.GetObjectsInRadius(center, 60)
+	}
+}
+
+// BenchmarkSpatialIndex_Update tests the cost of re-indexing moving objects
+func BenchmarkSpatialIndex_Update(b *testing.B) {
+	index, objects := populateBenchmarkIndex(b, 4000, 20000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		obj := objects[i%len(objects)]
+		obj.position.X = (obj.position.X + 1) % 4000
+		if err := index.Update(obj.id, obj.position); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSpatialIndex_Rebuild tests bulk rebuilding of a large index
+func BenchmarkSpatialIndex_Rebuild(b *testing.B) {
+	index, objects := populateBenchmarkIndex(b, 4000, 20000)
+	all := make([]GameObject, len(objects))
+	for i, obj := range objects {
+		all[i] = obj
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := index.Rebuild(all); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 // TestGameObject is a simple implementation for testing
 type TestGameObject struct {
 	id          string
@@ -16,8 +16,11 @@ package game
 //   - DamageDice: Dice expression for damage (e.g., "3d6+2")
 //   - HealingDice: Dice expression for healing (e.g., "2d4+1")
 //   - AreaEffect: Whether the spell affects an area
+//   - Area: Shape and size of the area of effect, if the spell has a template
 //   - SaveType: Type of saving throw required
 //   - EffectKeywords: Tags describing spell effects
+//   - EffectScripts: Scripted effects resolved by a SpellEffectRegistry at cast time
+//   - Materials: Specific material components, checked in strict component mode
 //
 // Related types:
 //   - SpellSchool: Enum defining valid magic schools
@@ -35,8 +38,12 @@ type Spell struct {
 	DamageDice     string           `yaml:"damage_dice"`       // Damage dice expression
 	HealingDice    string           `yaml:"healing_dice"`      // Healing dice expression
 	AreaEffect     bool             `yaml:"area_effect"`       // Whether spell affects an area
+	Area           *AreaTemplate    `yaml:"area,omitempty"`    // Area of effect template
 	SaveType       string           `yaml:"save_type"`         // Required saving throw type
 	EffectKeywords []string         `yaml:"effect_keywords"`   // Tags describing spell effects
+
+	EffectScripts []SpellEffectScript `yaml:"effect_scripts,omitempty"`  // Scripted effects resolved at cast time
+	Materials     []SpellMaterial     `yaml:"spell_materials,omitempty"` // Specific material components
 }
 
 // SpellSchool represents the different schools of magic available in the game
@@ -0,0 +1,288 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// AreaShape names the geometry of an area spell's template.
+type AreaShape string
+
+// Area template shapes. Circles spread from the target point; bursts,
+// cones and lines spread from the caster.
+const (
+	AreaCircle AreaShape = "circle" // Every tile within Size of the target point
+	AreaBurst  AreaShape = "burst"  // Every tile within Size of the caster
+	AreaCone   AreaShape = "cone"   // A quarter circle of radius Size opening towards the target
+	AreaLine   AreaShape = "line"   // A line of Size tiles from the caster towards the target
+)
+
+// AreaTemplate is the area of effect of a spell.
+//
+// Example YAML:
+//
+//	area:
+//	  shape: cone
+//	  size: 6
+type AreaTemplate struct {
+	Shape AreaShape `yaml:"shape" json:"shape"`
+	Size  int       `yaml:"size" json:"size"` // Radius of circles, bursts and cones, or length of lines, in tiles
+}
+
+// Validate checks that the template has a known shape and a positive size.
+func (t AreaTemplate) Validate() error {
+	switch t.Shape {
+	case AreaCircle, AreaBurst, AreaCone, AreaLine:
+	default:
+		return fmt.Errorf("unknown area shape %q", t.Shape)
+	}
+	if t.Size < 1 {
+		return fmt.Errorf("area size must be positive, got %d", t.Size)
+	}
+	return nil
+}
+
+// Origin returns the point the area spreads from: the target point for
+// circles and the caster's position for every other shape.
+func (t AreaTemplate) Origin(caster, target Position) Position {
+	origin := caster
+	if t.Shape == AreaCircle {
+		origin = target
+	}
+	origin.Facing = 0
+	return origin
+}
+
+// Tiles returns the positions the template covers, ordered by row and then
+// column, without regard to walls. Cones and lines aim from the caster
+// towards target, or the caster's facing when target is the caster's own
+// tile.
+func (t AreaTemplate) Tiles(caster, target Position) []Position {
+	origin := t.Origin(caster, target)
+	size := t.Size
+	var tiles []Position
+
+	switch t.Shape {
+	case AreaCircle, AreaBurst:
+		for dy := -size; dy <= size; dy++ {
+			for dx := -size; dx <= size; dx++ {
+				if dx*dx+dy*dy <= size*size {
+					tiles = append(tiles, Position{X: origin.X + dx, Y: origin.Y + dy, Level: origin.Level})
+				}
+			}
+		}
+	case AreaCone:
+		aimX, aimY := areaAim(caster, target)
+		for dy := -size; dy <= size; dy++ {
+			for dx := -size; dx <= size; dx++ {
+				distSq := float64(dx*dx + dy*dy)
+				dot := float64(dx)*aimX + float64(dy)*aimY
+				// Within 45 degrees of the aim: cos² >= 1/2
+				if distSq == 0 || distSq > float64(size*size) || dot <= 0 || 2*dot*dot < distSq {
+					continue
+				}
+				tiles = append(tiles, Position{X: origin.X + dx, Y: origin.Y + dy, Level: origin.Level})
+			}
+		}
+	case AreaLine:
+		aimX, aimY := areaAim(caster, target)
+		seen := make(map[Position]bool, size)
+		for step := 1; step <= size; step++ {
+			pos := Position{
+				X:     origin.X + int(math.Round(aimX*float64(step))),
+				Y:     origin.Y + int(math.Round(aimY*float64(step))),
+				Level: origin.Level,
+			}
+			if !seen[pos] {
+				seen[pos] = true
+				tiles = append(tiles, pos)
+			}
+		}
+	}
+
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i].Y != tiles[j].Y {
+			return tiles[i].Y < tiles[j].Y
+		}
+		return tiles[i].X < tiles[j].X
+	})
+	return tiles
+}
+
+// areaAim returns the unit vector from caster towards target, or along the
+// caster's facing when they share a tile.
+func areaAim(caster, target Position) (float64, float64) {
+	dx, dy := float64(target.X-caster.X), float64(target.Y-caster.Y)
+	if dx == 0 && dy == 0 {
+		switch caster.Facing {
+		case DirectionEast:
+			return 1, 0
+		case DirectionSouth:
+			return 0, 1
+		case DirectionWest:
+			return -1, 0
+		default:
+			return 0, -1
+		}
+	}
+	length := math.Hypot(dx, dy)
+	return dx / length, dy / length
+}
+
+// AreaTarget is a creature inside a resolved area of effect.
+type AreaTarget struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Position Position `json:"position"`
+	Ally     bool     `json:"ally"`  // The caster or one of its allies
+	Cover    bool     `json:"cover"` // An obstacle between it and the origin halves the effect
+
+	object GameObject
+}
+
+// Object returns the creature itself.
+func (t AreaTarget) Object() GameObject {
+	return t.object
+}
+
+// AreaResolution is an area template resolved against the world: the tiles
+// the effect reaches and the creatures it affects.
+type AreaResolution struct {
+	Template     AreaTemplate `json:"template"`
+	Origin       Position     `json:"origin"`
+	FriendlyFire FriendlyFire `json:"friendly_fire"`
+	Tiles        []Position   `json:"tiles"`            // Tiles in line of sight of the origin
+	Targets      []AreaTarget `json:"targets"`          // Creatures the effect reaches
+	Spared       []string     `json:"spared,omitempty"` // IDs of creatures in the area spared by the friendly fire rule
+}
+
+// AreaAllies returns the default ally test for caster's area spells: player
+// characters are allied with each other, and NPCs with the caster whose
+// faction is the caster's ID, such as its summons.
+func AreaAllies(caster GameObject) func(GameObject) bool {
+	_, casterIsPlayer := caster.(*Player)
+	return func(obj GameObject) bool {
+		switch o := obj.(type) {
+		case *Player:
+			return casterIsPlayer
+		case *NPC:
+			return o.Faction != "" && o.Faction == caster.GetID()
+		}
+		return false
+	}
+}
+
+// ResolveArea resolves template, cast by caster at target, against the
+// world. Tiles behind walls, as seen from the template's origin, are not
+// reached. Creatures are found through the spatial index; a creature with
+// an obstacle such as a wall segment between it and the origin has cover. Creatures that rule
+// spares are listed in Spared rather than Targets.
+//
+// Parameters:
+//   - template: The area of effect
+//   - caster: The caster, or nil for an area without one
+//   - target: The target point of the cast
+//   - rule: Which of the caster's side the area spares
+//   - isAlly: Reports the caster's allies; AreaAllies(caster) when nil
+//
+// Returns:
+//   - *AreaResolution: The tiles and creatures in the area
+func (w *World) ResolveArea(template AreaTemplate, caster GameObject, target Position, rule FriendlyFire, isAlly func(GameObject) bool) *AreaResolution {
+	casterPos := target
+	if caster != nil {
+		casterPos = caster.GetPosition()
+		if isAlly == nil {
+			isAlly = AreaAllies(caster)
+		}
+	}
+	origin := template.Origin(casterPos, target)
+
+	resolution := &AreaResolution{Template: template, Origin: origin, FriendlyFire: rule, Tiles: []Position{}, Targets: []AreaTarget{}}
+	reached := make(map[Position]bool)
+	bounds := Rectangle{MinX: origin.X, MinY: origin.Y, MaxX: origin.X, MaxY: origin.Y}
+
+	w.mu.RLock()
+	for _, tile := range template.Tiles(casterPos, target) {
+		if origin.Level >= 0 && origin.Level < len(w.Levels) && blocksSight(&w.Levels[origin.Level], tile.X, tile.Y) {
+			continue
+		}
+		if !w.lineOfSight(origin, tile) {
+			continue
+		}
+		reached[tile] = true
+		resolution.Tiles = append(resolution.Tiles, tile)
+		bounds.MinX, bounds.MinY = min(bounds.MinX, tile.X), min(bounds.MinY, tile.Y)
+		bounds.MaxX, bounds.MaxY = max(bounds.MaxX, tile.X), max(bounds.MaxY, tile.Y)
+	}
+	w.mu.RUnlock()
+
+	objects := w.GetObjectsInRange(bounds)
+	sort.Slice(objects, func(i, j int) bool { return objects[i].GetID() < objects[j].GetID() })
+	for _, obj := range objects {
+		pos := obj.GetPosition()
+		if obj.GetHealth() <= 0 || !reached[Position{X: pos.X, Y: pos.Y, Level: pos.Level}] {
+			continue
+		}
+
+		isCaster := caster != nil && obj.GetID() == caster.GetID()
+		ally := isCaster || (isAlly != nil && isAlly(obj))
+		if rule.spares(isCaster, ally) {
+			resolution.Spared = append(resolution.Spared, obj.GetID())
+			continue
+		}
+		resolution.Targets = append(resolution.Targets, AreaTarget{
+			ID:       obj.GetID(),
+			Name:     obj.GetName(),
+			Position: pos,
+			Ally:     ally,
+			Cover:    w.hasCover(origin, pos, obj.GetID()),
+			object:   obj,
+		})
+	}
+	return resolution
+}
+
+// hasCover reports whether an obstacle, such as a wall segment or a closed
+// door, stands on the line between origin and pos. Players and NPCs do not
+// give cover.
+func (w *World) hasCover(origin, pos Position, targetID string) bool {
+	x, y := origin.X, origin.Y
+	dx, dy := abs(pos.X-origin.X), -abs(pos.Y-origin.Y)
+	sx, sy := 1, 1
+	if origin.X > pos.X {
+		sx = -1
+	}
+	if origin.Y > pos.Y {
+		sy = -1
+	}
+	err := dx + dy
+	for x != pos.X || y != pos.Y {
+		if x != origin.X || y != origin.Y {
+			for _, obj := range w.GetObjectsAt(Position{X: x, Y: y, Level: origin.Level}) {
+				if givesCover(obj) && obj.GetID() != targetID {
+					return true
+				}
+			}
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+	}
+	return false
+}
+
+// givesCover reports whether obj shields what stands behind it.
+func givesCover(obj GameObject) bool {
+	switch obj.(type) {
+	case *Player, *NPC:
+		return false
+	}
+	return obj.IsObstacle()
+}
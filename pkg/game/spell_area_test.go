@@ -0,0 +1,158 @@
+package game
+
+import (
+	"testing"
+)
+
+// newAreaTestWorld creates a 20x20 world with an open level, a wall tile at
+// (10,3) and a player caster at (5,5)
+func newAreaTestWorld(t *testing.T) (*World, *Player) {
+	t.Helper()
+	world, caster := newSpellEffectTestWorld(t)
+	level := Level{ID: "arena", Width: 20, Height: 20, Tiles: make([][]Tile, 20)}
+	for y := range level.Tiles {
+		level.Tiles[y] = make([]Tile, 20)
+		for x := range level.Tiles[y] {
+			level.Tiles[y][x] = NewFloorTile()
+		}
+	}
+	level.Tiles[3][10] = NewWallTile()
+	world.Levels = []Level{level}
+	return world, caster
+}
+
+func TestAreaTemplate_Tiles(t *testing.T) {
+	caster := Position{X: 5, Y: 5}
+	target := Position{X: 9, Y: 5}
+
+	if got := len(AreaTemplate{Shape: AreaCircle, Size: 1}.Tiles(caster, target)); got != 5 {
+		t.Errorf("radius 1 circle covers %d tiles, want 5", got)
+	}
+	for _, tile := range (AreaTemplate{Shape: AreaCircle, Size: 2}).Tiles(caster, target) {
+		if dx, dy := tile.X-target.X, tile.Y-target.Y; dx*dx+dy*dy > 4 {
+			t.Errorf("circle tile %v is outside the radius around the target", tile)
+		}
+	}
+	for _, tile := range (AreaTemplate{Shape: AreaBurst, Size: 2}).Tiles(caster, target) {
+		if dx, dy := tile.X-caster.X, tile.Y-caster.Y; dx*dx+dy*dy > 4 {
+			t.Errorf("burst tile %v is outside the radius around the caster", tile)
+		}
+	}
+
+	for _, tile := range (AreaTemplate{Shape: AreaCone, Size: 4}).Tiles(caster, target) {
+		if tile.X <= caster.X || abs(tile.Y-caster.Y) > tile.X-caster.X {
+			t.Errorf("cone tile %v is outside the quarter circle opening east", tile)
+		}
+	}
+
+	facingSouth := Position{X: 5, Y: 5, Facing: DirectionSouth}
+	line := AreaTemplate{Shape: AreaLine, Size: 3}.Tiles(facingSouth, facingSouth)
+	want := []Position{{X: 5, Y: 6}, {X: 5, Y: 7}, {X: 5, Y: 8}}
+	if len(line) != len(want) {
+		t.Fatalf("line covers %v, want %v", line, want)
+	}
+	for i := range want {
+		if line[i] != want[i] {
+			t.Errorf("line tile %d = %v, want %v", i, line[i], want[i])
+		}
+	}
+
+	if err := (AreaTemplate{Shape: "square", Size: 2}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown shape")
+	}
+	if err := (AreaTemplate{Shape: AreaCone}).Validate(); err == nil {
+		t.Error("Validate accepted a template without a size")
+	}
+}
+
+func TestWorld_ResolveArea(t *testing.T) {
+	world, caster := newAreaTestWorld(t)
+	orc := &NPC{Character: Character{ID: "orc", Name: "Orc", Position: Position{X: 8, Y: 5}, HP: 10}}
+	hidden := &NPC{Character: Character{ID: "hidden", Name: "Hidden Orc", Position: Position{X: 11, Y: 2}, HP: 10}}
+	sheltered := &NPC{Character: Character{ID: "sheltered", Name: "Sheltered Orc", Position: Position{X: 8, Y: 8}, HP: 10}}
+	wall := &Character{ID: "wall", Name: "Wall of Force", Position: Position{X: 8, Y: 7}, HP: 30}
+	summon := &NPC{Character: Character{ID: "wolf", Name: "Wolf", Position: Position{X: 7, Y: 5}, HP: 10}, Faction: caster.ID}
+	for _, obj := range []GameObject{orc, hidden, sheltered, wall, summon} {
+		if err := world.AddObject(obj); err != nil {
+			t.Fatalf("AddObject failed: %v", err)
+		}
+	}
+
+	area := world.ResolveArea(AreaTemplate{Shape: AreaCircle, Size: 4}, caster, Position{X: 8, Y: 4}, FriendlyFireSpareCaster, nil)
+	targets := make(map[string]AreaTarget)
+	for _, target := range area.Targets {
+		targets[target.ID] = target
+	}
+	if _, ok := targets["orc"]; !ok {
+		t.Errorf("the orc in the open is not in the area: %+v", area.Targets)
+	}
+	if _, ok := targets["hidden"]; ok {
+		t.Error("the wall tile shelters the orc behind it from the blast")
+	}
+	if !targets["sheltered"].Cover || targets["orc"].Cover {
+		t.Error("only the orc behind the wall of force has cover")
+	}
+	if !targets["wolf"].Ally || targets["orc"].Ally {
+		t.Error("the caster's summon is its ally and the orc is not")
+	}
+	if len(area.Spared) != 1 || area.Spared[0] != caster.ID {
+		t.Errorf("spare_caster spares %v, want the caster", area.Spared)
+	}
+
+	area = world.ResolveArea(AreaTemplate{Shape: AreaCircle, Size: 4}, caster, Position{X: 8, Y: 4}, FriendlyFireNone, nil)
+	if len(area.Spared) != 2 {
+		t.Errorf("none spares %v, want the caster and its summon", area.Spared)
+	}
+	area = world.ResolveArea(AreaTemplate{Shape: AreaCircle, Size: 4}, caster, Position{X: 8, Y: 4}, FriendlyFireAll, nil)
+	if len(area.Spared) != 0 || len(area.Targets) != len(targets)+1 {
+		t.Errorf("all spares %v and reaches %d creatures, want nobody spared", area.Spared, len(area.Targets))
+	}
+}
+
+func TestAreaDamageEffect_Template(t *testing.T) {
+	world, caster := newAreaTestWorld(t)
+	caster.Position.Facing = DirectionEast
+	ahead := &NPC{Character: Character{ID: "ahead", Position: Position{X: 8, Y: 5}, HP: 30, MaxHP: 30}}
+	behind := &NPC{Character: Character{ID: "behind", Position: Position{X: 3, Y: 5}, HP: 30, MaxHP: 30}}
+	for _, obj := range []GameObject{ahead, behind} {
+		if err := world.AddObject(obj); err != nil {
+			t.Fatalf("AddObject failed: %v", err)
+		}
+	}
+
+	spell := newScriptedSpell(SpellEffectAreaDamage, nil)
+	spell.Area = &AreaTemplate{Shape: AreaCone, Size: 4}
+	results, err := NewSpellEffectRegistry().Resolve(&SpellCast{
+		Spell:    spell,
+		Caster:   caster,
+		Position: caster.Position,
+		World:    world,
+		Dice:     NewDiceRollerWithSeed(1),
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if affected := results[0].Affected; len(affected) != 1 || affected[0] != "ahead" {
+		t.Errorf("cone facing east hit %v, want only the creature ahead", affected)
+	}
+	if behind.HP != 30 {
+		t.Error("the creature behind the caster must not be hit")
+	}
+}
+
+func TestRulesetProfile_FriendlyFire(t *testing.T) {
+	SetActiveRuleset(nil)
+	if got := ActiveFriendlyFire(); got != FriendlyFireSpareCaster {
+		t.Errorf("built-in friendly fire = %s, want spare_caster", got)
+	}
+
+	profile := *useRuleset(t, "adnd1e")
+	if got := ActiveFriendlyFire(); got != FriendlyFireAll {
+		t.Errorf("adnd1e friendly fire = %s, want all", got)
+	}
+
+	profile.FriendlyFire = "sometimes"
+	if err := profile.Validate(); err == nil {
+		t.Error("Validate accepted an unknown friendly fire rule")
+	}
+}
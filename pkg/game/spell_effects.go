@@ -0,0 +1,466 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Built-in spell effect handler names
+const (
+	SpellEffectAreaDamage = "area_damage" // Damages every creature within a radius
+	SpellEffectSummon     = "summon"      // Summons allied creatures
+	SpellEffectTeleport   = "teleport"    // Moves the caster to the target position
+	SpellEffectCreateWall = "create_wall" // Creates a line of obstacle wall segments
+)
+
+// SpellEffectScript declares a scripted effect on a spell definition. The
+// handler is looked up by name in a SpellEffectRegistry when the spell is
+// cast, so new behaviors only need a registered handler and spell data.
+//
+// Example YAML:
+//
+//	effect_scripts:
+//	  - handler: area_damage
+//	    params:
+//	      radius: 4
+type SpellEffectScript struct {
+	Handler string                 `yaml:"handler"` // Registered handler name
+	Params  map[string]interface{} `yaml:"params"`  // Handler specific parameters
+}
+
+// SpellCast describes a single cast passed to spell effect handlers.
+type SpellCast struct {
+	Spell    *Spell
+	Caster   *Player
+	TargetID string
+	Position Position               // Target position of the cast
+	World    *World                 // World the cast takes place in
+	Dice     *DiceRoller            // Dice roller; GlobalDiceRoller when nil
+	Params   map[string]interface{} // Parameters of the script being resolved
+
+	// Allies reports the caster's allies for friendly fire. When nil,
+	// AreaAllies decides.
+	Allies func(GameObject) bool
+
+	// ApplyDamage applies damage to a target. When nil, handlers reduce the
+	// target's health directly.
+	ApplyDamage func(target GameObject, damage int, damageType string) error
+}
+
+// SpellEffectResult reports the outcome of a single effect script.
+type SpellEffectResult struct {
+	Handler  string                 `json:"handler"`
+	Affected []string               `json:"affected,omitempty"` // IDs of objects affected by the effect
+	Created  []string               `json:"created,omitempty"`  // IDs of objects created by the effect
+	Damage   int                    `json:"damage,omitempty"`   // Damage dealt to each affected object
+	Data     map[string]interface{} `json:"data,omitempty"`     // Handler specific details
+}
+
+// SpellEffectHandler resolves a scripted spell effect.
+type SpellEffectHandler func(cast *SpellCast) (*SpellEffectResult, error)
+
+// SpellEffectRegistry maps effect script names to handlers.
+type SpellEffectRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]SpellEffectHandler
+}
+
+// NewSpellEffectRegistry creates a registry with the built-in handlers
+// (area_damage, summon, teleport and create_wall) registered.
+func NewSpellEffectRegistry() *SpellEffectRegistry {
+	registry := &SpellEffectRegistry{handlers: make(map[string]SpellEffectHandler)}
+	registry.handlers[SpellEffectAreaDamage] = areaDamageEffect
+	registry.handlers[SpellEffectSummon] = summonEffect
+	registry.handlers[SpellEffectTeleport] = teleportEffect
+	registry.handlers[SpellEffectCreateWall] = createWallEffect
+	return registry
+}
+
+// Register adds a handler under name. Names must be unique.
+func (r *SpellEffectRegistry) Register(name string, handler SpellEffectHandler) error {
+	if name == "" {
+		return fmt.Errorf("spell effect handler name cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("spell effect handler %s cannot be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.handlers[name]; exists {
+		return fmt.Errorf("spell effect handler %s already registered", name)
+	}
+	r.handlers[name] = handler
+	return nil
+}
+
+// Has reports whether a handler is registered under name.
+func (r *SpellEffectRegistry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.handlers[name]
+	return exists
+}
+
+// Names returns the registered handler names in sorted order.
+func (r *SpellEffectRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve runs the effect scripts of cast.Spell in order. Every script's
+// handler is looked up before any runs, so a spell naming an unknown
+// handler fails without partial effects.
+func (r *SpellEffectRegistry) Resolve(cast *SpellCast) ([]*SpellEffectResult, error) {
+	scripts := cast.Spell.EffectScripts
+
+	r.mu.RLock()
+	handlers := make([]SpellEffectHandler, len(scripts))
+	for i, script := range scripts {
+		handler, exists := r.handlers[script.Handler]
+		if !exists {
+			r.mu.RUnlock()
+			return nil, fmt.Errorf("unknown spell effect handler: %s", script.Handler)
+		}
+		handlers[i] = handler
+	}
+	r.mu.RUnlock()
+
+	results := make([]*SpellEffectResult, 0, len(scripts))
+	for i, script := range scripts {
+		cast.Params = script.Params
+		result, err := handlers[i](cast)
+		if err != nil {
+			return results, fmt.Errorf("spell effect %s failed: %w", script.Handler, err)
+		}
+		result.Handler = script.Handler
+		results = append(results, result)
+
+		logrus.WithFields(logrus.Fields{
+			"function": "Resolve",
+			"package":  "game",
+			"spell_id": cast.Spell.ID,
+			"handler":  script.Handler,
+			"affected": len(result.Affected),
+			"created":  len(result.Created),
+		}).Debug("resolved spell effect")
+	}
+	return results, nil
+}
+
+// dice returns the dice roller for the cast.
+func (cast *SpellCast) dice() *DiceRoller {
+	if cast.Dice != nil {
+		return cast.Dice
+	}
+	return GlobalDiceRoller
+}
+
+// damage applies damage to target through ApplyDamage when set.
+func (cast *SpellCast) damage(target GameObject, amount int, damageType string) error {
+	if cast.ApplyDamage != nil {
+		return cast.ApplyDamage(target, amount, damageType)
+	}
+	health := target.GetHealth() - amount
+	if health < 0 {
+		health = 0
+	}
+	target.SetHealth(health)
+	return nil
+}
+
+// ParamInt returns the integer parameter key, or def when it is missing.
+func (cast *SpellCast) ParamInt(key string, def int) int {
+	switch value := cast.Params[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	default:
+		return def
+	}
+}
+
+// ParamFloat returns the numeric parameter key, or def when it is missing.
+func (cast *SpellCast) ParamFloat(key string, def float64) float64 {
+	switch value := cast.Params[key].(type) {
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case float64:
+		return value
+	default:
+		return def
+	}
+}
+
+// ParamString returns the string parameter key, or def when it is missing.
+func (cast *SpellCast) ParamString(key, def string) string {
+	if value, ok := cast.Params[key].(string); ok && value != "" {
+		return value
+	}
+	return def
+}
+
+// ParamBool returns the boolean parameter key, or def when it is missing.
+func (cast *SpellCast) ParamBool(key string, def bool) bool {
+	if value, ok := cast.Params[key].(bool); ok {
+		return value
+	}
+	return def
+}
+
+// areaDamageEffect damages every creature in the spell's area template, or
+// when the spell has none, within "radius" (default 1) of the cast
+// position. Damage is rolled once from "dice" (default the spell's damage
+// dice) and dealt as "damage_type" (default the spell's damage type);
+// creatures with cover take half. The active ruleset's friendly fire rule
+// decides who of the caster's side is spared, and "include_caster" spares
+// nobody.
+func areaDamageEffect(cast *SpellCast) (*SpellEffectResult, error) {
+	if cast.World == nil {
+		return nil, fmt.Errorf("area damage requires a world")
+	}
+
+	expression := cast.ParamString("dice", cast.Spell.DamageDice)
+	if expression == "" {
+		return nil, fmt.Errorf("area damage requires damage dice")
+	}
+	template := AreaTemplate{Shape: AreaCircle, Size: cast.ParamInt("radius", 1)}
+	if cast.Spell.Area != nil {
+		template = *cast.Spell.Area
+	}
+	if err := template.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid area: %w", err)
+	}
+	roll, err := cast.dice().Roll(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll damage dice: %w", err)
+	}
+	damageType := cast.ParamString("damage_type", cast.Spell.DamageType)
+	if damageType == "" {
+		damageType = "magical"
+	}
+	rule := ActiveFriendlyFire()
+	if cast.ParamBool("include_caster", false) {
+		rule = FriendlyFireAll
+	}
+
+	var caster GameObject
+	if cast.Caster != nil {
+		caster = cast.Caster
+	}
+	area := cast.World.ResolveArea(template, caster, cast.Position, rule, cast.Allies)
+
+	result := &SpellEffectResult{
+		Damage: roll.Final,
+		Data: map[string]interface{}{
+			"shape":         template.Shape,
+			"size":          template.Size,
+			"damage_type":   damageType,
+			"damage_roll":   roll.String(),
+			"friendly_fire": rule,
+		},
+	}
+	var covered []string
+	for _, target := range area.Targets {
+		damage := roll.Final
+		if target.Cover {
+			damage /= 2
+			covered = append(covered, target.ID)
+		}
+		if err := cast.damage(target.Object(), damage, damageType); err != nil {
+			return nil, fmt.Errorf("failed to damage %s: %w", target.ID, err)
+		}
+		result.Affected = append(result.Affected, target.ID)
+	}
+	if len(covered) > 0 {
+		result.Data["cover"] = covered
+	}
+	if len(area.Spared) > 0 {
+		result.Data["spared"] = area.Spared
+	}
+	return result, nil
+}
+
+// summonEffect places "count" (default 1) creatures named "name" (default
+// "Summoned Creature") with "hp" hit points on open positions around the
+// cast position. Summoned creatures are tagged "summoned" and belong to the
+// caster's faction.
+func summonEffect(cast *SpellCast) (*SpellEffectResult, error) {
+	if cast.World == nil {
+		return nil, fmt.Errorf("summon requires a world")
+	}
+
+	count := cast.ParamInt("count", 1)
+	if count < 1 {
+		return nil, fmt.Errorf("summon count must be positive, got %d", count)
+	}
+	level := cast.ParamInt("level", max(cast.Spell.Level, 1))
+	hp := cast.ParamInt("hp", level*8)
+	name := cast.ParamString("name", "Summoned Creature")
+
+	positions := openPositionsNear(cast.World, cast.Position, count)
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("no room to summon near %v", cast.Position)
+	}
+
+	faction := ""
+	if cast.Caster != nil {
+		faction = cast.Caster.GetID()
+	}
+
+	result := &SpellEffectResult{Data: map[string]interface{}{"name": name}}
+	for _, pos := range positions {
+		creature := &NPC{
+			Character: Character{
+				ID:       NewUID(),
+				Name:     name,
+				Position: pos,
+				HP:       hp,
+				MaxHP:    hp,
+				Level:    level,
+			},
+			Behavior: "summoned",
+			Faction:  faction,
+		}
+		creature.SetActive(true)
+		creature.AddTag("summoned")
+		if err := cast.World.AddObject(creature); err != nil {
+			return nil, fmt.Errorf("failed to place summoned creature: %w", err)
+		}
+		result.Created = append(result.Created, creature.ID)
+	}
+	return result, nil
+}
+
+// teleportEffect moves the caster to the cast position. The destination
+// must be within "max_distance" (default the spell's range, 0 for
+// unlimited) and not blocked by an obstacle.
+func teleportEffect(cast *SpellCast) (*SpellEffectResult, error) {
+	if cast.World == nil || cast.Caster == nil {
+		return nil, fmt.Errorf("teleport requires a world and a caster")
+	}
+
+	from := cast.Caster.GetPosition()
+	to := cast.Position
+	to.Facing = from.Facing
+
+	maxDistance := cast.ParamFloat("max_distance", float64(cast.Spell.Range))
+	distance := math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y))
+	if maxDistance > 0 && (distance > maxDistance || to.Level != from.Level) {
+		return nil, fmt.Errorf("teleport destination out of range")
+	}
+	if err := cast.World.ValidateMove(cast.Caster, to); err != nil {
+		return nil, fmt.Errorf("invalid teleport destination: %w", err)
+	}
+
+	if err := cast.World.UpdateObjectPosition(cast.Caster.GetID(), to); err != nil {
+		// Casters not tracked by the world only need their own position updated
+		if err := cast.Caster.SetPosition(to); err != nil {
+			return nil, fmt.Errorf("failed to move caster: %w", err)
+		}
+	}
+
+	return &SpellEffectResult{
+		Affected: []string{cast.Caster.GetID()},
+		Data:     map[string]interface{}{"from": from, "to": to},
+	}, nil
+}
+
+// createWallEffect creates a wall of "length" (default 3) segments centered
+// on the cast position, running "horizontal" (default) or "vertical" per
+// the "orientation" parameter. Each segment is an obstacle with "hp" hit
+// points (default 10 per spell level) tagged "wall". Segments that would be
+// out of bounds or overlap an obstacle are skipped.
+func createWallEffect(cast *SpellCast) (*SpellEffectResult, error) {
+	if cast.World == nil {
+		return nil, fmt.Errorf("wall creation requires a world")
+	}
+
+	length := cast.ParamInt("length", 3)
+	if length < 1 {
+		return nil, fmt.Errorf("wall length must be positive, got %d", length)
+	}
+	orientation := cast.ParamString("orientation", "horizontal")
+	if orientation != "horizontal" && orientation != "vertical" {
+		return nil, fmt.Errorf("unknown wall orientation: %s", orientation)
+	}
+	hp := cast.ParamInt("hp", max(cast.Spell.Level, 1)*10)
+	name := cast.ParamString("name", cast.Spell.Name)
+
+	result := &SpellEffectResult{Data: map[string]interface{}{"orientation": orientation, "length": length}}
+	for i := 0; i < length; i++ {
+		offset := i - length/2
+		pos := cast.Position
+		if orientation == "horizontal" {
+			pos.X += offset
+		} else {
+			pos.Y += offset
+		}
+		if !positionOpen(cast.World, pos) {
+			continue
+		}
+
+		segment := &Character{ID: NewUID(), Name: name, Position: pos, HP: hp, MaxHP: hp}
+		segment.SetActive(true)
+		segment.AddTag("wall")
+		if err := cast.World.AddObject(segment); err != nil {
+			return nil, fmt.Errorf("failed to place wall segment: %w", err)
+		}
+		result.Created = append(result.Created, segment.ID)
+	}
+
+	if len(result.Created) == 0 {
+		return nil, fmt.Errorf("no room for a wall at %v", cast.Position)
+	}
+	return result, nil
+}
+
+// positionOpen reports whether pos is within world bounds and free of obstacles.
+func positionOpen(world *World, pos Position) bool {
+	if !world.isPositionWithinBounds(pos) {
+		return false
+	}
+	for _, obj := range world.GetObjectsAt(pos) {
+		if obj.IsObstacle() {
+			return false
+		}
+	}
+	return true
+}
+
+// openPositionsNear returns up to count open positions, searching outward
+// from center in rings of increasing distance.
+func openPositionsNear(world *World, center Position, count int) []Position {
+	const maxRadius = 3
+
+	var positions []Position
+	for radius := 0; radius <= maxRadius && len(positions) < count; radius++ {
+		for dy := -radius; dy <= radius && len(positions) < count; dy++ {
+			for dx := -radius; dx <= radius && len(positions) < count; dx++ {
+				if dx != -radius && dx != radius && dy != -radius && dy != radius {
+					continue // Interior of the ring was searched at a smaller radius
+				}
+				pos := Position{X: center.X + dx, Y: center.Y + dy, Level: center.Level}
+				if positionOpen(world, pos) {
+					positions = append(positions, pos)
+				}
+			}
+		}
+	}
+	return positions
+}
@@ -0,0 +1,235 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+// newSpellEffectTestWorld creates a world with a caster at (5,5)
+func newSpellEffectTestWorld(t *testing.T) (*World, *Player) {
+	t.Helper()
+	world := NewWorldWithSize(20, 20, 5)
+	caster := &Player{Character: Character{ID: "caster", Name: "Caster", Position: Position{X: 5, Y: 5}, HP: 20, MaxHP: 20}}
+	if err := world.AddObject(caster); err != nil {
+		t.Fatalf("AddObject failed: %v", err)
+	}
+	return world, caster
+}
+
+func newScriptedSpell(handler string, params map[string]interface{}) *Spell {
+	return &Spell{
+		ID:            "test_" + handler,
+		Name:          "Test " + handler,
+		Level:         3,
+		Range:         10,
+		DamageDice:    "2d6",
+		DamageType:    "cold",
+		EffectScripts: []SpellEffectScript{{Handler: handler, Params: params}},
+	}
+}
+
+func TestSpellEffectRegistry_Register(t *testing.T) {
+	registry := NewSpellEffectRegistry()
+
+	for _, name := range []string{SpellEffectAreaDamage, SpellEffectSummon, SpellEffectTeleport, SpellEffectCreateWall} {
+		if !registry.Has(name) {
+			t.Errorf("Expected built-in handler %s", name)
+		}
+	}
+
+	handler := func(cast *SpellCast) (*SpellEffectResult, error) { return &SpellEffectResult{}, nil }
+	if err := registry.Register("polymorph", handler); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Register("polymorph", handler); err == nil {
+		t.Error("Expected error registering a duplicate handler")
+	}
+	if err := registry.Register("", handler); err == nil {
+		t.Error("Expected error registering an unnamed handler")
+	}
+	if err := registry.Register("nothing", nil); err == nil {
+		t.Error("Expected error registering a nil handler")
+	}
+
+	names := registry.Names()
+	if len(names) != 5 || names[2] != "polymorph" {
+		t.Errorf("Unexpected handler names: %v", names)
+	}
+}
+
+func TestSpellEffectRegistry_ResolveCustomHandler(t *testing.T) {
+	registry := NewSpellEffectRegistry()
+	calls := 0
+	if err := registry.Register("blink", func(cast *SpellCast) (*SpellEffectResult, error) {
+		calls++
+		return &SpellEffectResult{Data: map[string]interface{}{"hops": cast.ParamInt("hops", 1)}}, nil
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	spell := &Spell{ID: "blink", EffectScripts: []SpellEffectScript{
+		{Handler: "blink", Params: map[string]interface{}{"hops": 3}},
+		{Handler: "blink"},
+	}}
+	results, err := registry.Resolve(&SpellCast{Spell: spell})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Handler != "blink" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if results[0].Data["hops"] != 3 || results[1].Data["hops"] != 1 {
+		t.Errorf("Expected per-script params, got %v and %v", results[0].Data, results[1].Data)
+	}
+
+	// Unknown handlers fail before any script runs
+	calls = 0
+	spell.EffectScripts = append(spell.EffectScripts, SpellEffectScript{Handler: "unknown"})
+	if _, err := registry.Resolve(&SpellCast{Spell: spell}); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Expected unknown handler error, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no effects to run, got %d", calls)
+	}
+}
+
+func TestAreaDamageEffect(t *testing.T) {
+	world, caster := newSpellEffectTestWorld(t)
+	near := &Character{ID: "near", Position: Position{X: 9, Y: 9}, HP: 30, MaxHP: 30}
+	far := &Character{ID: "far", Position: Position{X: 15, Y: 15}, HP: 30, MaxHP: 30}
+	for _, obj := range []GameObject{near, far} {
+		if err := world.AddObject(obj); err != nil {
+			t.Fatalf("AddObject failed: %v", err)
+		}
+	}
+
+	spell := newScriptedSpell(SpellEffectAreaDamage, map[string]interface{}{"radius": 5})
+	results, err := NewSpellEffectRegistry().Resolve(&SpellCast{
+		Spell:    spell,
+		Caster:   caster,
+		Position: Position{X: 7, Y: 7},
+		World:    world,
+		Dice:     NewDiceRollerWithSeed(1),
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	result := results[0]
+	if len(result.Affected) != 1 || result.Affected[0] != "near" {
+		t.Fatalf("Expected only the nearby creature to be hit, got %v", result.Affected)
+	}
+	if near.HP != 30-result.Damage || result.Damage < 2 {
+		t.Errorf("Expected near to take %d damage, HP is %d", result.Damage, near.HP)
+	}
+	if caster.HP != 20 || far.HP != 30 {
+		t.Error("Caster and distant creatures must not be damaged")
+	}
+}
+
+func TestSummonEffect(t *testing.T) {
+	world, caster := newSpellEffectTestWorld(t)
+
+	spell := newScriptedSpell(SpellEffectSummon, map[string]interface{}{"count": 3, "name": "Wolf", "hp": 11})
+	results, err := NewSpellEffectRegistry().Resolve(&SpellCast{Spell: spell, Caster: caster, Position: caster.Position, World: world})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	created := results[0].Created
+	if len(created) != 3 {
+		t.Fatalf("Expected 3 summoned creatures, got %d", len(created))
+	}
+	for _, id := range created {
+		npc, ok := world.Objects[id].(*NPC)
+		if !ok {
+			t.Fatalf("Expected summoned NPC %s in world", id)
+		}
+		if npc.Name != "Wolf" || npc.HP != 11 || npc.Faction != "caster" || !npc.HasTag("summoned") {
+			t.Errorf("Unexpected summoned creature: %+v", npc)
+		}
+		if npc.Position == caster.Position {
+			t.Error("Summoned creatures must not share the caster's square")
+		}
+	}
+}
+
+func TestTeleportEffect(t *testing.T) {
+	world, caster := newSpellEffectTestWorld(t)
+	registry := NewSpellEffectRegistry()
+	spell := newScriptedSpell(SpellEffectTeleport, nil)
+
+	if _, err := registry.Resolve(&SpellCast{Spell: spell, Caster: caster, Position: Position{X: 19, Y: 19}, World: world}); err == nil {
+		t.Error("Expected destinations beyond the spell range to fail")
+	}
+
+	blocker := &Character{ID: "blocker", Position: Position{X: 8, Y: 5}, HP: 5}
+	if err := world.AddObject(blocker); err != nil {
+		t.Fatalf("AddObject failed: %v", err)
+	}
+	if _, err := registry.Resolve(&SpellCast{Spell: spell, Caster: caster, Position: blocker.Position, World: world}); err == nil {
+		t.Error("Expected blocked destinations to fail")
+	}
+
+	if _, err := registry.Resolve(&SpellCast{Spell: spell, Caster: caster, Position: Position{X: 5, Y: 12}, World: world}); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if caster.Position.X != 5 || caster.Position.Y != 12 {
+		t.Errorf("Expected caster at (5,12), got %v", caster.Position)
+	}
+	if objects := world.GetObjectsAt(Position{X: 5, Y: 12}); len(objects) != 1 {
+		t.Error("Expected the world's spatial grid to track the teleport")
+	}
+}
+
+func TestCreateWallEffect(t *testing.T) {
+	world, caster := newSpellEffectTestWorld(t)
+	registry := NewSpellEffectRegistry()
+
+	// The caster stands in the middle of the wall line
+	spell := newScriptedSpell(SpellEffectCreateWall, map[string]interface{}{"length": 3, "orientation": "vertical"})
+	results, err := registry.Resolve(&SpellCast{Spell: spell, Caster: caster, Position: Position{X: 5, Y: 4}, World: world})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if len(results[0].Created) != 2 {
+		t.Fatalf("Expected 2 wall segments around the caster, got %d", len(results[0].Created))
+	}
+	for _, id := range results[0].Created {
+		segment := world.Objects[id]
+		if !segment.IsObstacle() || segment.GetHealth() != 30 || segment.GetPosition().X != 5 {
+			t.Errorf("Unexpected wall segment %+v", segment)
+		}
+	}
+	if err := world.ValidateMove(caster, Position{X: 5, Y: 3}); err == nil {
+		t.Error("Expected the wall to block movement")
+	}
+
+	spell.EffectScripts[0].Params = map[string]interface{}{"orientation": "diagonal"}
+	if _, err := registry.Resolve(&SpellCast{Spell: spell, Caster: caster, Position: Position{X: 10, Y: 10}, World: world}); err == nil {
+		t.Error("Expected unknown orientation to fail")
+	}
+}
+
+func TestSpellManager_LoadsEffectScripts(t *testing.T) {
+	sm := NewSpellManager("../../data/spells")
+	if err := sm.LoadSpells(); err != nil {
+		t.Fatalf("LoadSpells failed: %v", err)
+	}
+
+	for _, spellID := range []string{"dimension_door", "monster_summoning_i", "wall_of_force", "ice_storm"} {
+		spell, err := sm.GetSpell(spellID)
+		if err != nil {
+			t.Fatalf("GetSpell(%s) failed: %v", spellID, err)
+		}
+		for _, script := range spell.EffectScripts {
+			if !sm.EffectRegistry().Has(script.Handler) {
+				t.Errorf("Spell %s uses unregistered handler %s", spellID, script.Handler)
+			}
+		}
+	}
+
+	if err := sm.validateSpell(&Spell{ID: "x", Name: "X", EffectScripts: []SpellEffectScript{{}}}); err == nil {
+		t.Error("Expected effect scripts without a handler to be rejected")
+	}
+}
@@ -16,7 +16,8 @@ import (
 // SpellManager handles loading, saving, and managing spells from YAML files
 type SpellManager struct {
 	spellsDir string
-	spells    map[string]*Spell // Map of spell ID to spell
+	spells    map[string]*Spell    // Map of spell ID to spell
+	effects   *SpellEffectRegistry // Handlers for scripted spell effects
 }
 
 // NewSpellManager creates a new SpellManager instance
@@ -24,9 +25,16 @@ func NewSpellManager(spellsDir string) *SpellManager {
 	return &SpellManager{
 		spellsDir: spellsDir,
 		spells:    make(map[string]*Spell),
+		effects:   NewSpellEffectRegistry(),
 	}
 }
 
+// EffectRegistry returns the registry resolving scripted spell effects.
+// Register custom handlers on it to add spell behaviors.
+func (sm *SpellManager) EffectRegistry() *SpellEffectRegistry {
+	return sm.effects
+}
+
 // LoadSpells loads all spell files from the spells directory with circuit breaker protection
 func (sm *SpellManager) LoadSpells() error {
 	ctx := context.Background()
@@ -103,6 +111,16 @@ func (sm *SpellManager) validateSpell(spell *Spell) error {
 	if spell.Duration < 0 {
 		return fmt.Errorf("spell duration cannot be negative")
 	}
+	if spell.Area != nil {
+		if err := spell.Area.Validate(); err != nil {
+			return fmt.Errorf("spell area: %w", err)
+		}
+	}
+	for i, script := range spell.EffectScripts {
+		if script.Handler == "" {
+			return fmt.Errorf("effect script %d has no handler", i)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SpellMaterial is a specific material component a spell is cast with, such
+// as the bat guano of a fireball. Item matches inventory items by ID or,
+// ignoring case, by name.
+//
+// Example YAML:
+//
+//	spell_materials:
+//	  - item: Bat Guano
+//	    consumed: true
+type SpellMaterial struct {
+	Item     string `yaml:"item" json:"item"`
+	Count    int    `yaml:"count,omitempty" json:"count,omitempty"`       // Items needed; 0 means 1
+	Consumed bool   `yaml:"consumed,omitempty" json:"consumed,omitempty"` // Whether casting uses the items up
+}
+
+// Needed returns the number of items the material calls for
+func (m SpellMaterial) Needed() int {
+	if m.Count < 1 {
+		return 1
+	}
+	return m.Count
+}
+
+// matches reports whether item is one of the material's items
+func (m SpellMaterial) matches(item Item) bool {
+	return item.ID == m.Item || strings.EqualFold(item.Name, m.Item)
+}
+
+// MissingMaterial describes a material component a character does not carry
+// enough of.
+type MissingMaterial struct {
+	Item   string `json:"item"`
+	Needed int    `json:"needed"`
+	Have   int    `json:"have"`
+}
+
+// MissingMaterials returns the materials the character does not carry enough
+// of, in the order given. Each inventory item counts toward one material
+// only, so two materials naming the same item need both counts.
+//
+// Thread safety: This method is thread-safe using read mutex locking
+func (c *Character) MissingMaterials(materials []SpellMaterial) []MissingMaterial {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, missing := c.matchMaterials(materials)
+	return missing
+}
+
+// ConsumeMaterials removes the items of the consumed materials from the
+// inventory. Nothing is removed when any material is missing.
+//
+// Returns:
+//   - []Item: The removed items
+//   - error: A material is missing
+//
+// Thread safety: This method is thread-safe using mutex locking
+func (c *Character) ConsumeMaterials(materials []SpellMaterial) ([]Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	consumed, missing := c.matchMaterials(materials)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing material component: %s", missing[0].Item)
+	}
+	if len(consumed) == 0 {
+		return nil, nil
+	}
+
+	removed := make([]Item, 0, len(consumed))
+	kept := make([]Item, 0, len(c.Inventory)-len(consumed))
+	for i, item := range c.Inventory {
+		if consumed[i] {
+			removed = append(removed, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	c.Inventory = kept
+	return removed, nil
+}
+
+// matchMaterials assigns inventory items to materials in inventory order.
+// The caller must hold c.mu.
+//
+// Returns:
+//   - map[int]bool: Inventory indexes of the items assigned to consumed materials
+//   - []MissingMaterial: The materials that could not be assigned enough items
+func (c *Character) matchMaterials(materials []SpellMaterial) (map[int]bool, []MissingMaterial) {
+	used := make(map[int]bool)
+	consumed := make(map[int]bool)
+	var missing []MissingMaterial
+
+	for _, material := range materials {
+		needed := material.Needed()
+		have := 0
+		for i, item := range c.Inventory {
+			if have == needed {
+				break
+			}
+			if used[i] || !material.matches(item) {
+				continue
+			}
+			used[i] = true
+			if material.Consumed {
+				consumed[i] = true
+			}
+			have++
+		}
+		if have < needed {
+			missing = append(missing, MissingMaterial{Item: material.Item, Needed: needed, Have: have})
+		}
+	}
+	return consumed, missing
+}
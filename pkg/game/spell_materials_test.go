@@ -0,0 +1,79 @@
+package game
+
+import (
+	"testing"
+)
+
+func newMaterialsTestCharacter() *Character {
+	return &Character{
+		ID: "test-caster",
+		Inventory: []Item{
+			{ID: "item_1", Name: "Bat Guano", Type: "SpellComponent"},
+			{ID: "item_2", Name: "Pearl", Type: "SpellComponent"},
+			{ID: "item_3", Name: "bat guano", Type: "SpellComponent"},
+			{ID: "item_4", Name: "Iron Sword", Type: "weapon"},
+		},
+	}
+}
+
+func TestCharacter_MissingMaterials(t *testing.T) {
+	tests := []struct {
+		name      string
+		materials []SpellMaterial
+		want      []MissingMaterial
+	}{
+		{"no materials", nil, nil},
+		{"by name ignoring case", []SpellMaterial{{Item: "BAT GUANO", Count: 2}}, nil},
+		{"by ID", []SpellMaterial{{Item: "item_2"}}, nil},
+		{"not enough", []SpellMaterial{{Item: "Pearl", Count: 3}}, []MissingMaterial{{Item: "Pearl", Needed: 3, Have: 1}}},
+		{"not carried", []SpellMaterial{{Item: "Diamond"}}, []MissingMaterial{{Item: "Diamond", Needed: 1, Have: 0}}},
+		{
+			"items count once",
+			[]SpellMaterial{{Item: "Pearl"}, {Item: "item_2"}},
+			[]MissingMaterial{{Item: "item_2", Needed: 1, Have: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newMaterialsTestCharacter().MissingMaterials(tt.materials)
+			if len(got) != len(tt.want) {
+				t.Fatalf("MissingMaterials() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("MissingMaterials()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCharacter_ConsumeMaterials(t *testing.T) {
+	character := newMaterialsTestCharacter()
+
+	removed, err := character.ConsumeMaterials([]SpellMaterial{
+		{Item: "Bat Guano", Consumed: true},
+		{Item: "Pearl"},
+	})
+	if err != nil {
+		t.Fatalf("ConsumeMaterials() error = %v", err)
+	}
+	if len(removed) != 1 || removed[0].ID != "item_1" {
+		t.Errorf("ConsumeMaterials() removed %+v, want only item_1", removed)
+	}
+	if len(character.Inventory) != 3 || !character.HasItem("item_2") {
+		t.Errorf("materials that are not consumed stay in the inventory, got %+v", character.Inventory)
+	}
+
+	_, err = character.ConsumeMaterials([]SpellMaterial{
+		{Item: "Bat Guano", Consumed: true},
+		{Item: "Diamond", Consumed: true},
+	})
+	if err == nil {
+		t.Fatal("ConsumeMaterials() expected an error for a missing material")
+	}
+	if len(character.Inventory) != 3 {
+		t.Errorf("a failed ConsumeMaterials() removed items, %d remain", len(character.Inventory))
+	}
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+)
+
+// spellSlotTables lists, for each spellcasting class, the spells of each
+// level a character can memorize, starting at the class level the class
+// gains spells. Index 0 of a row is first-level spells. Characters above
+// the last row use it.
+var spellSlotTables = map[CharacterClass]struct {
+	firstLevel int
+	rows       [][]int
+}{
+	ClassMage: {1, [][]int{
+		{1}, {2}, {2, 1}, {3, 2}, {4, 2, 1}, {4, 2, 2}, {4, 3, 2, 1}, {4, 3, 3, 2}, {4, 3, 3, 2, 1}, {4, 4, 3, 2, 2},
+	}},
+	ClassCleric: {1, [][]int{
+		{1}, {2}, {2, 1}, {3, 2}, {3, 3, 1}, {3, 3, 2}, {3, 3, 2, 1}, {3, 3, 3, 2}, {4, 4, 3, 2, 1}, {4, 4, 3, 3, 2},
+	}},
+	ClassPaladin: {9, [][]int{{1}, {2}, {2, 1}, {2, 2}, {2, 2, 1}}},
+	ClassRanger:  {8, [][]int{{1}, {2}, {2, 1}, {2, 2}, {2, 2, 1}}},
+}
+
+// SpellSlotsFor returns the spells of each level a character of class and
+// level can memorize, index 0 being first-level spells. It returns nil for
+// classes and levels without spells.
+func SpellSlotsFor(class CharacterClass, level int) []int {
+	table, exists := spellSlotTables[class]
+	if !exists || level < table.firstLevel {
+		return nil
+	}
+	row := min(level-table.firstLevel, len(table.rows)-1)
+	return slices.Clone(table.rows[row])
+}
+
+// MemorizedSpell is a spell a player has prepared in one of its spell
+// slots. Casting the spell uses it up until the player rests again.
+type MemorizedSpell struct {
+	SpellID string `yaml:"memorized_spell_id" json:"spell_id"`
+	Level   int    `yaml:"memorized_level" json:"level"`
+	Ready   bool   `yaml:"memorized_ready" json:"ready"` // Learned during a full rest and not yet cast
+}
+
+// SpellSlots returns the spells of each level the player can memorize,
+// index 0 being first-level spells. A multi-classed player has the slots of
+// all of its active spellcasting classes.
+func (p *Player) SpellSlots() []int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.spellSlots()
+}
+
+// spellSlots implements SpellSlots (requires the lock)
+func (p *Player) spellSlots() []int {
+	var slots []int
+	for _, class := range p.activeClasses() {
+		for i, count := range SpellSlotsFor(class, p.classLevel(class)) {
+			if i == len(slots) {
+				slots = append(slots, 0)
+			}
+			slots[i] += count
+		}
+	}
+	return slots
+}
+
+// GetMemorizedSpells returns a copy of the spells the player has memorized.
+func (p *Player) GetMemorizedSpells() []MemorizedSpell {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Clone(p.Memorized)
+}
+
+// MemorizeSpells replaces the player's memorized spells with spellIDs. A
+// spell may be listed more than once to memorize several copies. Every
+// spell must be known and of a level the player has slots for; cantrips
+// need no slot and cannot be memorized. The new spells are not ready to
+// cast until the player completes a full rest.
+//
+// Returns an error, leaving the memorized spells unchanged, if a spell is
+// unknown, is a cantrip, or would take more slots of its level than the
+// player has.
+func (p *Player) MemorizeSpells(spellIDs []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	slots := p.spellSlots()
+	used := make([]int, len(slots))
+	memorized := make([]MemorizedSpell, 0, len(spellIDs))
+	for _, id := range spellIDs {
+		index := slices.IndexFunc(p.KnownSpells, func(spell Spell) bool { return spell.ID == id })
+		if index < 0 {
+			return fmt.Errorf("spell %s is not known", id)
+		}
+		level := p.KnownSpells[index].Level
+		if level < 1 {
+			return fmt.Errorf("spell %s is a cantrip and needs no memorization", id)
+		}
+		if level > len(slots) || used[level-1] == slots[level-1] {
+			return fmt.Errorf("no level %d spell slot left for %s", level, id)
+		}
+		used[level-1]++
+		memorized = append(memorized, MemorizedSpell{SpellID: id, Level: level})
+	}
+
+	p.Memorized = memorized
+	return nil
+}
+
+// CanCastMemorized reports whether the player has spell ready to cast:
+// cantrips always are, other spells need a ready memorized copy.
+func (p *Player) CanCastMemorized(spell Spell) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if spell.Level < 1 || p.readySpell(spell.ID) >= 0 {
+		return nil
+	}
+	return fmt.Errorf("spell %s is not memorized", spell.ID)
+}
+
+// ExpendSpell uses up a ready memorized copy of spell after it was cast.
+// Cantrips are not memorized and cost nothing.
+func (p *Player) ExpendSpell(spell Spell) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if spell.Level < 1 {
+		return nil
+	}
+	index := p.readySpell(spell.ID)
+	if index < 0 {
+		return fmt.Errorf("spell %s is not memorized", spell.ID)
+	}
+	p.Memorized[index].Ready = false
+	return nil
+}
+
+// readySpell returns the index of a ready memorized copy of spellID, or -1
+// (requires the lock)
+func (p *Player) readySpell(spellID string) int {
+	return slices.IndexFunc(p.Memorized, func(m MemorizedSpell) bool {
+		return m.SpellID == spellID && m.Ready
+	})
+}
+
+// RestoreSpells readies every memorized spell after a full rest.
+func (p *Player) RestoreSpells() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range p.Memorized {
+		p.Memorized[i].Ready = true
+	}
+}
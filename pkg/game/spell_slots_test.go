@@ -0,0 +1,57 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpellSlotsFor(t *testing.T) {
+	assert.Equal(t, []int{1}, SpellSlotsFor(ClassMage, 1))
+	assert.Equal(t, []int{4, 2, 1}, SpellSlotsFor(ClassMage, 5))
+	assert.Equal(t, []int{4, 4, 3, 3, 2}, SpellSlotsFor(ClassCleric, 20), "levels past the table use its last row")
+	assert.Nil(t, SpellSlotsFor(ClassPaladin, 8), "paladins gain spells at level 9")
+	assert.Equal(t, []int{1}, SpellSlotsFor(ClassPaladin, 9))
+	assert.Nil(t, SpellSlotsFor(ClassFighter, 10))
+
+	slots := SpellSlotsFor(ClassMage, 3)
+	slots[0] = 99
+	assert.Equal(t, []int{2, 1}, SpellSlotsFor(ClassMage, 3), "callers get a copy")
+
+	multi := &Player{Classes: []ClassLevel{{Class: ClassCleric, Level: 3}, {Class: ClassMage, Level: 2}}}
+	assert.Equal(t, []int{4, 1}, multi.SpellSlots(), "multi-classed players add up their slots")
+}
+
+func TestPlayer_MemorizeAndCastSpells(t *testing.T) {
+	missile := Spell{ID: "magic_missile", Level: 1}
+	web := Spell{ID: "web", Level: 2}
+	light := Spell{ID: "light", Level: 0}
+	player := &Player{Character: Character{Class: ClassMage, Intelligence: 16}, Level: 3}
+	for _, spell := range []Spell{missile, web, light} {
+		require.NoError(t, player.LearnSpell(spell))
+	}
+
+	assert.ErrorContains(t, player.MemorizeSpells([]string{"fireball"}), "not known")
+	assert.ErrorContains(t, player.MemorizeSpells([]string{"light"}), "cantrip")
+	assert.ErrorContains(t, player.MemorizeSpells([]string{"web", "web"}), "no level 2 spell slot")
+	assert.Empty(t, player.GetMemorizedSpells(), "failed memorization changes nothing")
+
+	require.NoError(t, player.MemorizeSpells([]string{"magic_missile", "magic_missile", "web"}))
+	assert.Len(t, player.GetMemorizedSpells(), 3)
+	assert.NoError(t, player.CanCastMemorized(light), "cantrips need no slot")
+	assert.Error(t, player.CanCastMemorized(missile), "spells are ready only after resting")
+
+	player.RestoreSpells()
+	require.NoError(t, player.CanCastMemorized(missile))
+	require.NoError(t, player.ExpendSpell(missile))
+	require.NoError(t, player.ExpendSpell(missile))
+	assert.Error(t, player.CanCastMemorized(missile), "both copies are used up")
+	assert.Error(t, player.ExpendSpell(missile))
+	assert.NoError(t, player.CanCastMemorized(web))
+
+	clone := player.Clone()
+	player.RestoreSpells()
+	assert.Error(t, clone.CanCastMemorized(missile), "clones do not share memorized spells")
+	assert.NoError(t, player.CanCastMemorized(missile))
+}
@@ -0,0 +1,291 @@
+package game
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultSightRange is the distance, in tiles, a character can see when no
+// other range is given.
+const DefaultSightRange = 10.0
+
+// Threat level labels derived from ThreatAssessment.Danger.
+const (
+	ThreatTrivial  = "trivial"
+	ThreatLow      = "low"
+	ThreatModerate = "moderate"
+	ThreatHigh     = "high"
+	ThreatDeadly   = "deadly"
+)
+
+// ThreatTable holds the AI weights used to judge hostility and estimate how
+// dangerous an NPC is. Weights multiply a base danger of 1.0 for an
+// uninjured NPC of the viewer's level.
+type ThreatTable struct {
+	// BehaviorWeights scales danger by NPC behavior (e.g. "aggressive").
+	// Behaviors not listed use a weight of 1.0.
+	BehaviorWeights map[string]float64 `yaml:"behavior_weights"`
+
+	// EffectWeights scales danger for each active status effect.
+	EffectWeights map[EffectType]float64 `yaml:"effect_weights"`
+
+	// PeacefulBehaviors are behaviors that are not hostile unless the NPC
+	// is tagged "hostile".
+	PeacefulBehaviors map[string]bool `yaml:"peaceful_behaviors"`
+}
+
+// DefaultThreatTable returns the built-in threat weights.
+func DefaultThreatTable() *ThreatTable {
+	return &ThreatTable{
+		BehaviorWeights: map[string]float64{
+			"berserk":    1.75,
+			"aggressive": 1.5,
+			"hunter":     1.25,
+			"patrol":     1.0,
+			"guard":      1.0,
+			"defensive":  0.75,
+			"cowardly":   0.5,
+		},
+		EffectWeights: map[EffectType]float64{
+			EffectStun:        0.25,
+			EffectRoot:        0.75,
+			EffectSilence:     0.9,
+			EffectBound:       0.9,
+			EffectStatPenalty: 0.8,
+			EffectPoison:      0.9,
+			EffectBurning:     0.9,
+			EffectBleeding:    0.9,
+			EffectStatBoost:   1.25,
+		},
+		PeacefulBehaviors: map[string]bool{
+			"friendly": true,
+			"merchant": true,
+			"vendor":   true,
+			"neutral":  true,
+			"passive":  true,
+		},
+	}
+}
+
+// ThreatAssessment describes a visible hostile NPC from a viewer's point of view.
+type ThreatAssessment struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Position      Position  `json:"position"`
+	Distance      float64   `json:"distance"`
+	Level         int       `json:"level"`
+	HP            int       `json:"hp"`
+	MaxHP         int       `json:"max_hp"`
+	Behavior      string    `json:"behavior"`
+	Faction       string    `json:"faction"`
+	StatusEffects []string  `json:"status_effects"`
+	Facing        Direction `json:"facing"`
+	FacingViewer  bool      `json:"facing_viewer"` // Target faces the viewer (no flanking bonus)
+	Danger        float64   `json:"danger"`        // Estimated danger relative to the viewer
+	ThreatLevel   string    `json:"threat_level"`  // Danger bucketed into a label
+}
+
+// IsHostile reports whether npc is an enemy of viewer. NPCs summoned by the
+// viewer (faction equal to the viewer's ID) are allies; NPCs with a peaceful
+// behavior are neutral unless tagged "hostile"; all others are hostile.
+func (t *ThreatTable) IsHostile(viewer *Character, npc *NPC) bool {
+	if npc.Faction != "" && npc.Faction == viewer.ID {
+		return false
+	}
+	if npc.HasTag("hostile") {
+		return true
+	}
+	return !t.PeacefulBehaviors[npc.Behavior]
+}
+
+// Assess computes the threat npc poses to viewer.
+//
+// Danger starts from the NPC's level relative to the viewer's, is reduced
+// for wounded NPCs (down to half at 0 HP), and is then scaled by the
+// behavior weight and the weight of every active status effect.
+func (t *ThreatTable) Assess(viewer *Character, npc *NPC) ThreatAssessment {
+	from, to := viewer.Position, npc.Position
+
+	danger := float64(max(npc.Level, 1)) / float64(max(viewer.Level, 1))
+	if npc.MaxHP > 0 {
+		health := math.Max(0, math.Min(1, float64(npc.HP)/float64(npc.MaxHP)))
+		danger *= 0.5 + 0.5*health
+	}
+	if weight, ok := t.BehaviorWeights[npc.Behavior]; ok {
+		danger *= weight
+	}
+
+	statuses := []string{}
+	for _, effect := range npc.GetEffects() {
+		if effect == nil || !effect.IsActive {
+			continue
+		}
+		statuses = append(statuses, string(effect.Type))
+		if weight, ok := t.EffectWeights[effect.Type]; ok {
+			danger *= weight
+		}
+	}
+	danger = math.Round(danger*100) / 100
+
+	dx, dy := float64(from.X-to.X), float64(from.Y-to.Y)
+	return ThreatAssessment{
+		ID:            npc.ID,
+		Name:          npc.Name,
+		Position:      to,
+		Distance:      math.Round(math.Sqrt(dx*dx+dy*dy)*100) / 100,
+		Level:         npc.Level,
+		HP:            npc.HP,
+		MaxHP:         npc.MaxHP,
+		Behavior:      npc.Behavior,
+		Faction:       npc.Faction,
+		StatusEffects: statuses,
+		Facing:        to.Facing,
+		FacingViewer:  to.Facing == directionTowards(to, from),
+		Danger:        danger,
+		ThreatLevel:   threatLevel(danger),
+	}
+}
+
+// threatLevel buckets a danger estimate into a label.
+func threatLevel(danger float64) string {
+	switch {
+	case danger < 0.5:
+		return ThreatTrivial
+	case danger < 1.0:
+		return ThreatLow
+	case danger < 1.5:
+		return ThreatModerate
+	case danger < 2.5:
+		return ThreatHigh
+	default:
+		return ThreatDeadly
+	}
+}
+
+// directionTowards returns the cardinal direction that best points from
+// one position to another. North is towards smaller Y.
+func directionTowards(from, to Position) Direction {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	if abs(dx) >= abs(dy) {
+		if dx >= 0 {
+			return DirectionEast
+		}
+		return DirectionWest
+	}
+	if dy < 0 {
+		return DirectionNorth
+	}
+	return DirectionSouth
+}
+
+// HasLineOfSight reports whether an unobstructed straight line connects two
+// positions on the same level. Tiles that block sight between the endpoints
+// break the line; positions outside the level's tile map do not.
+func (w *World) HasLineOfSight(from, to Position) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.lineOfSight(from, to)
+}
+
+// lineOfSight walks the Bresenham line between two positions. The caller
+// must hold w.mu.
+func (w *World) lineOfSight(from, to Position) bool {
+	if from.Level != to.Level {
+		return false
+	}
+	if from.Level < 0 || from.Level >= len(w.Levels) {
+		return true
+	}
+	level := &w.Levels[from.Level]
+
+	x, y := from.X, from.Y
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := 1, 1
+	if from.X > to.X {
+		sx = -1
+	}
+	if from.Y > to.Y {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		if x == to.X && y == to.Y {
+			return true
+		}
+		if (x != from.X || y != from.Y) && blocksSight(level, x, y) {
+			return false
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+// blocksSight reports whether the tile at x, y obstructs line of sight.
+func blocksSight(level *Level, x, y int) bool {
+	if y < 0 || y >= len(level.Tiles) || x < 0 || x >= len(level.Tiles[y]) {
+		return false
+	}
+	return level.Tiles[y][x].BlocksSight
+}
+
+// VisibleHostiles returns threat assessments for the living hostile NPCs
+// that viewer can see within sightRange tiles, most dangerous first and
+// nearest first among equals. NPCs in dim or dark places are only seen as
+// CanSee allows.
+//
+// Parameters:
+//   - viewer: The character looking for enemies
+//   - sightRange: Maximum distance in tiles (DefaultSightRange if <= 0)
+//   - table: Hostility and danger weights (DefaultThreatTable if nil)
+func (w *World) VisibleHostiles(viewer *Character, sightRange float64, table *ThreatTable) []ThreatAssessment {
+	if sightRange <= 0 {
+		sightRange = DefaultSightRange
+	}
+	if table == nil {
+		table = DefaultThreatTable()
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	threats := []ThreatAssessment{}
+	for _, obj := range w.Objects {
+		npc, ok := obj.(*NPC)
+		if !ok || npc.ID == viewer.ID || npc.HP <= 0 {
+			continue
+		}
+		if !w.canSee(viewer, npc.Position, sightRange) {
+			continue
+		}
+		if !table.IsHostile(viewer, npc) {
+			continue
+		}
+		threats = append(threats, table.Assess(viewer, npc))
+	}
+
+	sort.Slice(threats, func(i, j int) bool {
+		if threats[i].Danger != threats[j].Danger {
+			return threats[i].Danger > threats[j].Danger
+		}
+		if threats[i].Distance != threats[j].Distance {
+			return threats[i].Distance < threats[j].Distance
+		}
+		return threats[i].ID < threats[j].ID
+	})
+	return threats
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
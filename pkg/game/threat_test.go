@@ -0,0 +1,124 @@
+package game
+
+import (
+	"testing"
+)
+
+// newThreatTestNPC creates an NPC at x, y on level 0 facing north.
+func newThreatTestNPC(id, behavior string, x, y, level int) *NPC {
+	return &NPC{
+		Character: Character{
+			ID:       id,
+			Name:     id,
+			Position: Position{X: x, Y: y, Facing: DirectionNorth},
+			Level:    level,
+			HP:       10,
+			MaxHP:    10,
+		},
+		Behavior: behavior,
+	}
+}
+
+func TestWorld_HasLineOfSight(t *testing.T) {
+	world := CreateDefaultWorld()
+	world.Levels[0].Tiles[5][5] = Tile{Type: TileWall, BlocksSight: true}
+
+	tests := []struct {
+		name     string
+		from, to Position
+		want     bool
+	}{
+		{"open floor", Position{X: 2, Y: 2}, Position{X: 8, Y: 2}, true},
+		{"blocked by wall", Position{X: 5, Y: 2}, Position{X: 5, Y: 8}, false},
+		{"diagonal past wall", Position{X: 2, Y: 2}, Position{X: 8, Y: 3}, true},
+		{"target is the wall", Position{X: 5, Y: 2}, Position{X: 5, Y: 5}, true},
+		{"different levels", Position{X: 2, Y: 2}, Position{X: 2, Y: 3, Level: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := world.HasLineOfSight(tt.from, tt.to); got != tt.want {
+				t.Errorf("HasLineOfSight(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorld_VisibleHostiles(t *testing.T) {
+	world := CreateDefaultWorld()
+	world.Levels[0].Tiles[5][5] = Tile{Type: TileWall, BlocksSight: true}
+	viewer := &Character{ID: "hero", Position: Position{X: 5, Y: 2}, Level: 2}
+
+	orc := newThreatTestNPC("orc", "aggressive", 8, 2, 2)
+	goblin := newThreatTestNPC("goblin", "", 3, 2, 1)
+	stunned := newThreatTestNPC("ogre", "", 5, 3, 4)
+	if err := stunned.AddEffect(NewEffect(EffectStun, Duration{Rounds: 2}, 1)); err != nil {
+		t.Fatalf("AddEffect failed: %v", err)
+	}
+	hidden := newThreatTestNPC("hidden", "aggressive", 5, 8, 5)
+	merchant := newThreatTestNPC("merchant", "merchant", 6, 2, 1)
+	summon := newThreatTestNPC("summon", "summoned", 4, 3, 1)
+	summon.Faction = "hero"
+	dead := newThreatTestNPC("dead", "aggressive", 7, 2, 3)
+	dead.HP = 0
+	far := newThreatTestNPC("far", "aggressive", 48, 2, 3)
+
+	for _, npc := range []*NPC{orc, goblin, stunned, hidden, merchant, summon, dead, far} {
+		world.Objects[npc.ID] = npc
+	}
+
+	threats := world.VisibleHostiles(viewer, 0, nil)
+
+	var ids []string
+	for _, threat := range threats {
+		ids = append(ids, threat.ID)
+	}
+	want := []string{"orc", "ogre", "goblin"}
+	if len(ids) != len(want) {
+		t.Fatalf("VisibleHostiles() ids = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("VisibleHostiles() ids = %v, want %v", ids, want)
+		}
+	}
+
+	orcThreat := threats[0]
+	if orcThreat.Danger != 1.5 || orcThreat.ThreatLevel != ThreatHigh {
+		t.Errorf("orc danger = %v (%s), want 1.5 (%s)", orcThreat.Danger, orcThreat.ThreatLevel, ThreatHigh)
+	}
+	if orcThreat.Distance != 3 {
+		t.Errorf("orc distance = %v, want 3", orcThreat.Distance)
+	}
+	if orcThreat.FacingViewer {
+		t.Error("orc faces north and should not be facing a viewer to its west")
+	}
+
+	ogreThreat := threats[1]
+	if ogreThreat.Danger != 0.5 {
+		t.Errorf("stunned ogre danger = %v, want 0.5", ogreThreat.Danger)
+	}
+	if len(ogreThreat.StatusEffects) != 1 || ogreThreat.StatusEffects[0] != string(EffectStun) {
+		t.Errorf("ogre status effects = %v, want [stun]", ogreThreat.StatusEffects)
+	}
+	if !ogreThreat.FacingViewer {
+		t.Error("ogre faces north towards the viewer")
+	}
+}
+
+func TestThreatTable_AssessWoundedNPC(t *testing.T) {
+	table := DefaultThreatTable()
+	viewer := &Character{ID: "hero", Level: 1}
+	npc := newThreatTestNPC("troll", "", 1, 0, 2)
+	npc.HP = 0
+
+	if got := table.Assess(viewer, npc).Danger; got != 1 {
+		t.Errorf("Assess() danger = %v, want 1 for a level 2 NPC at 0 HP", got)
+	}
+
+	npc.AddTag("hostile")
+	npc.Behavior = "merchant"
+	if !table.IsHostile(viewer, npc) {
+		t.Error("NPCs tagged hostile are hostile regardless of behavior")
+	}
+}
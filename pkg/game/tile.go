@@ -25,6 +25,7 @@ type TileType int
 // - Dangerous: Indicates if the tile can cause damage
 // - DamageType: Classification of damage (e.g., "fire", "poison")
 // - Damage: Integer amount of damage dealt per turn if dangerous
+// - Light: How brightly the tile is lit (bright unless set)
 //
 // Note: Properties map allows for dynamic extension of tile attributes
 // without modifying the core structure.
@@ -43,6 +44,8 @@ type Tile struct {
 	Dangerous   bool   `yaml:"tile_dangerous"`    // Whether causes damage
 	DamageType  string `yaml:"tile_damage_type"`  // Type of damage dealt
 	Damage      int    `yaml:"tile_damage"`       // Amount of damage per turn
+
+	Light LightLevel `yaml:"tile_light,omitempty"` // How brightly the tile is lit
 }
 
 // RGB represents a color in RGB format
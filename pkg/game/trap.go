@@ -0,0 +1,399 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TrapTrigger describes what sets a trap off.
+type TrapTrigger string
+
+const (
+	// TrapTriggerPressurePlate fires when a creature steps on the trap's tile
+	TrapTriggerPressurePlate TrapTrigger = "pressure_plate"
+	// TrapTriggerTripwire fires when a creature walks through the trap's tile
+	TrapTriggerTripwire TrapTrigger = "tripwire"
+	// TrapTriggerProximity fires when a creature comes within TriggerRadius tiles
+	TrapTriggerProximity TrapTrigger = "proximity"
+	// TrapTriggerTouch fires only when the trapped object is handled, never on movement
+	TrapTriggerTouch TrapTrigger = "touch"
+)
+
+// Skills that improve trap checks. Bonuses come from Character.Skills.
+const (
+	SkillTrapDetection = "Trap Detection"
+	SkillDisarmTraps   = "Disarm Traps"
+)
+
+// TrapCriticalFailureMargin is how far below the DC a disarm check must
+// fall to set the trap off.
+const TrapCriticalFailureMargin = 5
+
+// Trap is a hidden hazard in the game world. A trap stays hidden from a
+// character until that character detects it, fires on the creature that
+// meets its trigger condition, and can be disarmed once detected.
+//
+// Detection is a Wisdom check and disarming a Dexterity check, each adding
+// the matching skill bonus; thieves add half their level, rounded up, to
+// both. Disarming costs ActionCostDisarmTrap action points per attempt, and
+// failing by TrapCriticalFailureMargin or more sets the trap off.
+//
+// Trap is safe for concurrent use.
+type Trap struct {
+	mu          sync.RWMutex `yaml:"-"`
+	ID          string       `yaml:"trap_id"`          // Unique identifier
+	Name        string       `yaml:"trap_name"`        // Display name
+	Description string       `yaml:"trap_description"` // Description shown once detected
+	Position    Position     `yaml:"trap_position"`    // Location in the game world
+
+	Trigger       TrapTrigger `yaml:"trap_trigger"`                  // What sets the trap off
+	TriggerRadius int         `yaml:"trap_trigger_radius,omitempty"` // Range of proximity triggers in tiles
+	DetectionDC   int         `yaml:"trap_detection_dc"`             // Difficulty of spotting the trap
+	DisarmDC      int         `yaml:"trap_disarm_dc"`                // Difficulty of disarming the trap
+
+	Damage          string     `yaml:"trap_damage,omitempty"`           // Damage dice, e.g. "2d6"
+	DamageType      DamageType `yaml:"trap_damage_type,omitempty"`      // Type of the damage dealt
+	Effect          EffectType `yaml:"trap_effect,omitempty"`           // Effect applied to the victim
+	EffectRounds    int        `yaml:"trap_effect_rounds,omitempty"`    // Duration of the effect
+	EffectMagnitude float64    `yaml:"trap_effect_magnitude,omitempty"` // Strength of the effect
+	Rearms          bool       `yaml:"trap_rearms,omitempty"`           // Whether the trap stays armed after firing
+
+	Armed      bool            `yaml:"trap_armed"`                 // Whether the trap can fire
+	DetectedBy map[string]bool `yaml:"trap_detected_by,omitempty"` // Characters who have spotted the trap
+}
+
+// TrapCheck is the result of a detection or disarm check.
+type TrapCheck struct {
+	Roll    int  `json:"roll"`    // The d20 result
+	Bonus   int  `json:"bonus"`   // Ability, skill and class bonus
+	Total   int  `json:"total"`   // Roll plus bonus
+	DC      int  `json:"dc"`      // Difficulty the total was compared against
+	Success bool `json:"success"` // Whether the total met the DC
+}
+
+// TrapResult describes what a trap did to its victim when it fired.
+type TrapResult struct {
+	TrapID     string     `json:"trap_id"`
+	TargetID   string     `json:"target_id"`
+	Damage     int        `json:"damage"`
+	DamageType DamageType `json:"damage_type,omitempty"`
+	Effect     *Effect    `json:"effect,omitempty"`
+}
+
+// Validate checks that the trap can be detected, disarmed and fired.
+func (t *Trap) Validate() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.ID == "" {
+		return fmt.Errorf("trap ID cannot be empty")
+	}
+	switch t.Trigger {
+	case TrapTriggerPressurePlate, TrapTriggerTripwire, TrapTriggerTouch:
+	case TrapTriggerProximity:
+		if t.TriggerRadius < 1 {
+			return fmt.Errorf("proximity trap %s needs a trigger radius of at least 1", t.ID)
+		}
+	default:
+		return fmt.Errorf("trap %s has unknown trigger %q", t.ID, t.Trigger)
+	}
+	if t.DetectionDC < 1 || t.DisarmDC < 1 {
+		return fmt.Errorf("trap %s needs positive detection and disarm DCs", t.ID)
+	}
+	if t.Damage == "" && t.Effect == "" {
+		return fmt.Errorf("trap %s has neither damage nor an effect", t.ID)
+	}
+	if t.Damage != "" {
+		if _, err := CalculateDiceAverage(t.Damage); err != nil {
+			return fmt.Errorf("trap %s has invalid damage %q: %w", t.ID, t.Damage, err)
+		}
+	}
+	return nil
+}
+
+// IsDetectedBy reports whether the character has spotted the trap.
+func (t *Trap) IsDetectedBy(characterID string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.DetectedBy[characterID]
+}
+
+// IsArmed reports whether the trap can still fire.
+func (t *Trap) IsArmed() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.Armed
+}
+
+// TriggeredBy reports whether a creature moving to pos sets the trap off.
+// Touch traps never fire on movement.
+func (t *Trap) TriggeredBy(pos Position) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if !t.Armed || pos.Level != t.Position.Level {
+		return false
+	}
+	switch t.Trigger {
+	case TrapTriggerPressurePlate, TrapTriggerTripwire:
+		return pos.X == t.Position.X && pos.Y == t.Position.Y
+	case TrapTriggerProximity:
+		dx, dy := pos.X-t.Position.X, pos.Y-t.Position.Y
+		return dx*dx+dy*dy <= t.TriggerRadius*t.TriggerRadius
+	default:
+		return false
+	}
+}
+
+// PassiveDetection checks whether the character notices the trap without
+// searching, using 10 in place of the roll. A success is remembered.
+func (t *Trap) PassiveDetection(c *Character) TrapCheck {
+	check := t.check(10, trapCheckBonus(c, c.Wisdom, SkillTrapDetection), t.DetectionDC)
+	if check.Success {
+		t.markDetected(c.ID)
+	}
+	return check
+}
+
+// AttemptDetection rolls an active search for the trap. A success is
+// remembered, so the character can then disarm or avoid the trap.
+func (t *Trap) AttemptDetection(c *Character, roller *DiceRoller) (TrapCheck, error) {
+	roll, err := roller.Roll("1d20")
+	if err != nil {
+		return TrapCheck{}, fmt.Errorf("failed to roll detection check: %w", err)
+	}
+
+	check := t.check(roll.Final, trapCheckBonus(c, c.Wisdom, SkillTrapDetection), t.DetectionDC)
+	if check.Success {
+		t.markDetected(c.ID)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "AttemptDetection",
+		"package":      "game",
+		"trap_id":      t.ID,
+		"character_id": c.ID,
+		"total":        check.Total,
+		"dc":           check.DC,
+		"success":      check.Success,
+	}).Debug("trap detection attempted")
+
+	return check, nil
+}
+
+// AttemptDisarm spends ActionCostDisarmTrap action points to try to disarm
+// a trap the character has detected. A success disarms the trap for good;
+// missing the DC by TrapCriticalFailureMargin or more sets it off on the
+// character, and the result describes what it did.
+func (t *Trap) AttemptDisarm(c *Character, roller *DiceRoller) (TrapCheck, *TrapResult, error) {
+	if !t.IsArmed() {
+		return TrapCheck{}, nil, fmt.Errorf("trap %s is not armed", t.ID)
+	}
+	if !t.IsDetectedBy(c.ID) {
+		return TrapCheck{}, nil, fmt.Errorf("trap %s has not been detected by %s", t.ID, c.ID)
+	}
+	if !c.ConsumeActionPoints(ActionCostDisarmTrap) {
+		return TrapCheck{}, nil, fmt.Errorf("insufficient action points to disarm trap (need %d, have %d)",
+			ActionCostDisarmTrap, c.GetActionPoints())
+	}
+
+	roll, err := roller.Roll("1d20")
+	if err != nil {
+		return TrapCheck{}, nil, fmt.Errorf("failed to roll disarm check: %w", err)
+	}
+
+	check := t.check(roll.Final, trapCheckBonus(c, c.Dexterity, SkillDisarmTraps), t.DisarmDC)
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "AttemptDisarm",
+		"package":      "game",
+		"trap_id":      t.ID,
+		"character_id": c.ID,
+		"total":        check.Total,
+		"dc":           check.DC,
+		"success":      check.Success,
+	}).Debug("trap disarm attempted")
+
+	if check.Success {
+		t.mu.Lock()
+		t.Armed = false
+		t.mu.Unlock()
+		return check, nil, nil
+	}
+	if check.DC-check.Total < TrapCriticalFailureMargin {
+		return check, nil, nil
+	}
+
+	result, err := t.Fire(c, roller)
+	return check, result, err
+}
+
+// Fire sets the trap off on target, dealing its damage and applying its
+// effect. The result carries no effect if the target already has a stronger
+// one of the same type. Traps that do not rearm are disarmed afterwards.
+func (t *Trap) Fire(target *Character, roller *DiceRoller) (*TrapResult, error) {
+	t.mu.Lock()
+	if !t.Armed {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("trap %s is not armed", t.ID)
+	}
+	if !t.Rearms {
+		t.Armed = false
+	}
+	id, name, damage, damageType := t.ID, t.Name, t.Damage, t.DamageType
+	effectType, rounds, magnitude := t.Effect, t.EffectRounds, t.EffectMagnitude
+	t.mu.Unlock()
+
+	result := &TrapResult{
+		TrapID:     id,
+		TargetID:   target.ID,
+		DamageType: damageType,
+	}
+
+	if damage != "" {
+		roll, err := roller.Roll(damage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to roll trap damage: %w", err)
+		}
+		result.Damage = max(roll.Final, 0)
+		target.SetHealth(target.GetHealth() - result.Damage)
+	}
+
+	if effectType != "" {
+		effect := NewEffect(effectType, Duration{Rounds: rounds}, magnitude)
+		effect.Name = name
+		effect.DamageType = damageType
+		effect.SourceID = id
+		effect.SourceType = "trap"
+		effect.TargetID = target.ID
+		// A stronger effect of the same type already on the target wins;
+		// the damage has landed either way
+		if err := target.AddEffect(effect); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":  "Fire",
+				"package":   "game",
+				"trap_id":   id,
+				"target_id": target.ID,
+				"error":     err.Error(),
+			}).Debug("trap effect not applied")
+		} else {
+			result.Effect = effect
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "Fire",
+		"package":   "game",
+		"trap_id":   t.ID,
+		"target_id": target.ID,
+		"damage":    result.Damage,
+		"effect":    effectType,
+	}).Info("trap fired")
+
+	return result, nil
+}
+
+// check compares a roll plus bonus against dc
+func (t *Trap) check(roll, bonus, dc int) TrapCheck {
+	total := roll + bonus
+	return TrapCheck{
+		Roll:    roll,
+		Bonus:   bonus,
+		Total:   total,
+		DC:      dc,
+		Success: total >= dc,
+	}
+}
+
+// markDetected remembers that the character has spotted the trap
+func (t *Trap) markDetected(characterID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.DetectedBy == nil {
+		t.DetectedBy = make(map[string]bool)
+	}
+	t.DetectedBy[characterID] = true
+}
+
+// trapCheckBonus returns the ability modifier plus the skill bonus of a
+// trap check, adding half the level, rounded up, for thieves.
+func trapCheckBonus(c *Character, ability int, skill string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	bonus := (ability-10)/2 + c.Skills[skill]
+	if c.Class == ClassThief {
+		bonus += (c.Level + 1) / 2
+	}
+	return bonus
+}
+
+// FromJSON implements GameObject.
+func (t *Trap) FromJSON(data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return json.Unmarshal(data, t)
+}
+
+// ToJSON implements GameObject.
+func (t *Trap) ToJSON() ([]byte, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return json.Marshal(t)
+}
+
+// GetID implements GameObject.
+func (t *Trap) GetID() string {
+	return t.ID
+}
+
+// GetName implements GameObject.
+func (t *Trap) GetName() string {
+	return t.Name
+}
+
+// GetDescription implements GameObject.
+func (t *Trap) GetDescription() string {
+	return t.Description
+}
+
+// GetPosition implements GameObject.
+func (t *Trap) GetPosition() Position {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.Position
+}
+
+// SetPosition implements GameObject.
+func (t *Trap) SetPosition(pos Position) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Position = pos
+	return nil
+}
+
+// GetHealth implements GameObject. Traps have no health.
+func (t *Trap) GetHealth() int {
+	return 0
+}
+
+// SetHealth implements GameObject. Traps have no health, so this is a no-op.
+func (t *Trap) SetHealth(health int) {}
+
+// IsActive implements GameObject. A trap is active while armed.
+func (t *Trap) IsActive() bool {
+	return t.IsArmed()
+}
+
+// GetTags implements GameObject.
+func (t *Trap) GetTags() []string {
+	return []string{"trap", string(t.Trigger)}
+}
+
+// IsObstacle implements GameObject. Traps never block movement.
+func (t *Trap) IsObstacle() bool {
+	return false
+}
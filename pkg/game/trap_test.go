@@ -0,0 +1,228 @@
+package game
+
+import "testing"
+
+func newTestTrap() *Trap {
+	return &Trap{
+		ID:              "trap-1",
+		Name:            "Poison Needle",
+		Position:        Position{X: 5, Y: 5},
+		Trigger:         TrapTriggerPressurePlate,
+		DetectionDC:     12,
+		DisarmDC:        14,
+		Damage:          "1d6",
+		DamageType:      DamagePoison,
+		Effect:          EffectPoison,
+		EffectRounds:    3,
+		EffectMagnitude: 2,
+		Armed:           true,
+	}
+}
+
+func newTestTrapCharacter(id string, class CharacterClass) *Character {
+	return &Character{
+		ID:           id,
+		Class:        class,
+		Level:        3,
+		Dexterity:    14,
+		Wisdom:       12,
+		HP:           20,
+		MaxHP:        20,
+		ActionPoints: ActionPointsPerTurn,
+		Skills:       map[string]int{},
+	}
+}
+
+func TestTrap_Validate(t *testing.T) {
+	if err := newTestTrap().Validate(); err != nil {
+		t.Fatalf("expected valid trap, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*Trap)
+	}{
+		{"missing ID", func(tr *Trap) { tr.ID = "" }},
+		{"unknown trigger", func(tr *Trap) { tr.Trigger = "gaze" }},
+		{"proximity without radius", func(tr *Trap) { tr.Trigger = TrapTriggerProximity }},
+		{"zero DC", func(tr *Trap) { tr.DisarmDC = 0 }},
+		{"no payload", func(tr *Trap) { tr.Damage, tr.Effect = "", "" }},
+		{"bad damage", func(tr *Trap) { tr.Damage = "lots" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trap := newTestTrap()
+			tt.mutate(trap)
+			if err := trap.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestTrap_TriggeredBy(t *testing.T) {
+	plate := newTestTrap()
+	if !plate.TriggeredBy(Position{X: 5, Y: 5}) {
+		t.Error("pressure plate should fire when stepped on")
+	}
+	if plate.TriggeredBy(Position{X: 5, Y: 6}) {
+		t.Error("pressure plate should not fire from a neighbouring tile")
+	}
+	if plate.TriggeredBy(Position{X: 5, Y: 5, Level: 1}) {
+		t.Error("trap should not fire on another level")
+	}
+
+	proximity := newTestTrap()
+	proximity.Trigger = TrapTriggerProximity
+	proximity.TriggerRadius = 2
+	if !proximity.TriggeredBy(Position{X: 6, Y: 6}) {
+		t.Error("proximity trap should fire within its radius")
+	}
+	if proximity.TriggeredBy(Position{X: 8, Y: 5}) {
+		t.Error("proximity trap should not fire outside its radius")
+	}
+
+	touch := newTestTrap()
+	touch.Trigger = TrapTriggerTouch
+	if touch.TriggeredBy(Position{X: 5, Y: 5}) {
+		t.Error("touch trap should never fire on movement")
+	}
+
+	plate.Armed = false
+	if plate.TriggeredBy(Position{X: 5, Y: 5}) {
+		t.Error("disarmed trap should not fire")
+	}
+}
+
+func TestTrap_Detection(t *testing.T) {
+	trap := newTestTrap()
+	fighter := newTestTrapCharacter("fighter", ClassFighter)
+	thief := newTestTrapCharacter("thief", ClassThief)
+	thief.Skills[SkillTrapDetection] = 2
+
+	// Fighter: 10 + 1 (Wisdom) = 11 misses DC 12
+	if check := trap.PassiveDetection(fighter); check.Success || check.Total != 11 {
+		t.Errorf("fighter passive check = %+v, want failure at 11", check)
+	}
+	// Thief: 10 + 1 (Wisdom) + 2 (skill) + 2 (level 3) = 15
+	if check := trap.PassiveDetection(thief); !check.Success || check.Total != 15 {
+		t.Errorf("thief passive check = %+v, want success at 15", check)
+	}
+
+	if trap.IsDetectedBy(fighter.ID) {
+		t.Error("failed detection should not be remembered")
+	}
+	if !trap.IsDetectedBy(thief.ID) {
+		t.Error("successful detection should be remembered")
+	}
+
+	check, err := trap.AttemptDetection(fighter, NewDiceRollerWithSeed(1))
+	if err != nil {
+		t.Fatalf("AttemptDetection failed: %v", err)
+	}
+	if check.Roll < 1 || check.Roll > 20 || check.Total != check.Roll+check.Bonus {
+		t.Errorf("unexpected detection check %+v", check)
+	}
+	if trap.IsDetectedBy(fighter.ID) != check.Success {
+		t.Error("detection state should match the check result")
+	}
+}
+
+func TestTrap_AttemptDisarm(t *testing.T) {
+	t.Run("requires detection", func(t *testing.T) {
+		trap := newTestTrap()
+		thief := newTestTrapCharacter("thief", ClassThief)
+		if _, _, err := trap.AttemptDisarm(thief, NewDiceRollerWithSeed(1)); err == nil {
+			t.Error("expected error disarming an undetected trap")
+		}
+		if thief.ActionPoints != ActionPointsPerTurn {
+			t.Error("refused attempt should not cost action points")
+		}
+	})
+
+	t.Run("costs action points", func(t *testing.T) {
+		trap := newTestTrap()
+		thief := newTestTrapCharacter("thief", ClassThief)
+		trap.markDetected(thief.ID)
+
+		if _, _, err := trap.AttemptDisarm(thief, NewDiceRollerWithSeed(1)); err != nil {
+			t.Fatalf("AttemptDisarm failed: %v", err)
+		}
+		if thief.ActionPoints != ActionPointsPerTurn-ActionCostDisarmTrap {
+			t.Errorf("action points = %d, want %d", thief.ActionPoints, ActionPointsPerTurn-ActionCostDisarmTrap)
+		}
+
+		trap.Armed = true
+		if _, _, err := trap.AttemptDisarm(thief, NewDiceRollerWithSeed(1)); err == nil {
+			t.Error("expected error without enough action points")
+		}
+	})
+
+	t.Run("success disarms", func(t *testing.T) {
+		trap := newTestTrap()
+		thief := newTestTrapCharacter("thief", ClassThief)
+		thief.Skills[SkillDisarmTraps] = 20
+		trap.markDetected(thief.ID)
+
+		check, result, err := trap.AttemptDisarm(thief, NewDiceRollerWithSeed(1))
+		if err != nil {
+			t.Fatalf("AttemptDisarm failed: %v", err)
+		}
+		if !check.Success || result != nil || trap.IsArmed() {
+			t.Errorf("expected a clean disarm, got check %+v result %+v", check, result)
+		}
+	})
+
+	t.Run("critical failure fires the trap", func(t *testing.T) {
+		trap := newTestTrap()
+		trap.DisarmDC = 40
+		fighter := newTestTrapCharacter("fighter", ClassFighter)
+		trap.markDetected(fighter.ID)
+
+		check, result, err := trap.AttemptDisarm(fighter, NewDiceRollerWithSeed(1))
+		if err != nil {
+			t.Fatalf("AttemptDisarm failed: %v", err)
+		}
+		if check.Success || result == nil {
+			t.Fatalf("expected the trap to fire, got check %+v", check)
+		}
+		if fighter.GetHealth() != 20-result.Damage {
+			t.Errorf("health = %d, want %d", fighter.GetHealth(), 20-result.Damage)
+		}
+	})
+}
+
+func TestTrap_Fire(t *testing.T) {
+	trap := newTestTrap()
+	victim := newTestTrapCharacter("victim", ClassFighter)
+
+	result, err := trap.Fire(victim, NewDiceRollerWithSeed(7))
+	if err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+	if result.Damage < 1 || result.Damage > 6 {
+		t.Errorf("damage = %d, want 1d6", result.Damage)
+	}
+	if victim.GetHealth() != 20-result.Damage {
+		t.Errorf("health = %d, want %d", victim.GetHealth(), 20-result.Damage)
+	}
+	if result.Effect == nil || result.Effect.Type != EffectPoison || result.Effect.SourceType != "trap" {
+		t.Errorf("expected a poison effect from the trap, got %+v", result.Effect)
+	}
+	if trap.IsArmed() {
+		t.Error("trap that does not rearm should be disarmed after firing")
+	}
+	if _, err := trap.Fire(victim, NewDiceRollerWithSeed(7)); err == nil {
+		t.Error("expected error firing a disarmed trap")
+	}
+
+	rearming := newTestTrap()
+	rearming.Rearms = true
+	if _, err := rearming.Fire(victim, NewDiceRollerWithSeed(7)); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+	if !rearming.IsArmed() {
+		t.Error("rearming trap should stay armed")
+	}
+}
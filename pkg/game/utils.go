@@ -32,6 +32,10 @@ func isValidPosition(pos Position, width, height, maxLevel int) bool {
 		pos.X < width && pos.Y < height && pos.Level < maxLevel
 }
 
+// experienceThresholds is the total experience needed for each level of the
+// built-in progression, starting at level 1
+var experienceThresholds = []int64{0, 2000, 4000, 8000, 16000, 32000, 64000}
+
 // calculateLevel determines the character level based on experience points using a D&D-style progression system.
 //
 // Thread Safety:
@@ -62,11 +66,8 @@ func calculateLevel(exp int64) int {
 		return 0
 	}
 
-	// Implement D&D-style level progression
-	// Level 1: 0-1999 XP, Level 2: 2000-3999 XP, etc.
-	levels := []int64{0, 2000, 4000, 8000, 16000, 32000, 64000}
-
 	// Find the highest threshold that the experience meets or exceeds
+	levels := experienceThresholds
 	currentLevel := 1
 	for i := 1; i < len(levels); i++ {
 		if exp >= levels[i] {
@@ -2,25 +2,50 @@ package game
 
 import (
 	"fmt"
+	"maps"
 	"math"
-	"sync"
+	"slices"
+	"sort"
+
+	"goldbox-rpg/pkg/lockstat"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 )
 
+// worldLock names the World lock in lock contention statistics
+type worldLock struct{}
+
+func (worldLock) LockName() string { return "world" }
+
 // World manages the game state and all game objects
 // Contains the complete state of the game world including all entities and maps
 type World struct {
-	mu           sync.RWMutex          `yaml:"-"`                  // Protects concurrent access
+	mu lockstat.RWMutex[worldLock] `yaml:"-"` // Protects concurrent access
+
 	Levels       []Level               `yaml:"world_levels"`       // All game levels/maps
 	CurrentTime  GameTime              `yaml:"world_current_time"` // Current game time
-	Objects      map[string]GameObject `yaml:"world_objects"`      // All game objects by ID
+	Objects      map[string]GameObject `yaml:"-"`                  // All game objects by ID, saved by MarshalYAML
 	Players      map[string]*Player    `yaml:"world_players"`      // Active players by ID
 	NPCs         map[string]*NPC       `yaml:"world_npcs"`         // Non-player characters by ID
-	SpatialGrid  map[Position][]string `yaml:"world_spatial_grid"` // Legacy spatial index (for compatibility)
+	SpatialGrid  map[Position][]string `yaml:"-"`                  // Legacy spatial index (for compatibility), rebuilt on load
 	SpatialIndex *SpatialIndex         `yaml:"-"`                  // Advanced spatial indexing system
 	Width        int                   `yaml:"world_width"`        // Width of the world
 	Height       int                   `yaml:"world_height"`       // Height of the world
+
+	// LockedAreas maps the level and room IDs locked by quest consequences
+	// to the quest that locked them
+	LockedAreas map[string]string `yaml:"world_locked_areas,omitempty"`
+
+	// Relationships holds how NPCs feel about players and each other;
+	// use RelationshipGraph, which creates it on first use
+	Relationships *RelationshipGraph `yaml:"world_relationships,omitempty"`
+
+	// Pantheon holds the deities worshipped in the world
+	Pantheon *Pantheon `yaml:"world_pantheon,omitempty"`
+
+	lights  map[string]int        // Light radius carried by each holder, see SetCarriedLights
+	changes map[int]*levelChanges // Per-level revisions for map deltas
 }
 
 // Update applies a set of updates to the World state
@@ -101,6 +126,7 @@ func (w *World) updateObjects(value interface{}) error {
 		w.Objects[id] = obj
 		pos := obj.GetPosition()
 		w.SpatialGrid[pos] = append(w.SpatialGrid[pos], obj.GetID())
+		w.recordObjectChange(id, pos.Level)
 
 		// Update advanced spatial index if available
 		if w.SpatialIndex != nil {
@@ -216,6 +242,25 @@ func (w *World) Clone() *World {
 		clone.NPCs[k] = v
 	}
 
+	// Copy locked areas
+	if w.LockedAreas != nil {
+		clone.LockedAreas = make(map[string]string, len(w.LockedAreas))
+		for k, v := range w.LockedAreas {
+			clone.LockedAreas[k] = v
+		}
+	}
+
+	// Copy carried lights
+	if w.lights != nil {
+		clone.lights = make(map[string]int, len(w.lights))
+		for k, v := range w.lights {
+			clone.lights[k] = v
+		}
+	}
+
+	clone.Relationships = w.Relationships.Clone()
+	clone.Pantheon = w.Pantheon.Clone()
+
 	// Copy spatial grid
 	for k, v := range w.SpatialGrid {
 		gridCopy := make([]string, len(v))
@@ -226,18 +271,146 @@ func (w *World) Clone() *World {
 	// Clone spatial index by rebuilding it with all objects
 	if w.SpatialIndex != nil {
 		clone.SpatialIndex = NewSpatialIndex(w.Width, w.Height, w.SpatialIndex.cellSize)
-		for _, obj := range clone.Objects {
-			if err := clone.SpatialIndex.Insert(obj); err != nil {
-				// Log error but continue cloning other objects for robustness
-				// In production, this would use proper logging (logrus)
-				continue
-			}
+		if err := clone.SpatialIndex.Rebuild(clone.objectList()); err != nil {
+			// Objects outside the index are still cloned
+			logrus.WithFields(logrus.Fields{
+				"function": "Clone",
+				"package":  "game",
+				"error":    err,
+			}).Warn("some objects could not be added to the cloned spatial index")
 		}
 	}
 
 	return clone
 }
 
+// worldDocument has World's fields without its YAML methods, so they can
+// encode and decode the plain fields without recursing
+type worldDocument World
+
+// savedWorld is the YAML document of a World. Objects are saved with their
+// kind, since the GameObject interface cannot be decoded on its own.
+type savedWorld struct {
+	*worldDocument `yaml:",inline"`
+	Objects        map[string]savedObject `yaml:"world_objects"`
+}
+
+// savedObject is a world object tagged with its concrete type
+type savedObject struct {
+	Kind   string     `yaml:"kind"`
+	Object GameObject `yaml:"object"`
+}
+
+// objectKinds creates an empty object of each kind world objects are saved as
+var objectKinds = map[string]func() GameObject{
+	"player":    func() GameObject { return &Player{} },
+	"npc":       func() GameObject { return &NPC{} },
+	"character": func() GameObject { return &Character{} },
+	"item":      func() GameObject { return &Item{} },
+	"trap":      func() GameObject { return &Trap{} },
+	"door":      func() GameObject { return &Door{} },
+	"key":       func() GameObject { return &KeyObject{} },
+	"feature":   func() GameObject { return &Feature{} },
+}
+
+// objectKind returns the kind obj is saved as
+func objectKind(obj GameObject) (string, error) {
+	switch obj.(type) {
+	case *Player:
+		return "player", nil
+	case *NPC:
+		return "npc", nil
+	case *Character:
+		return "character", nil
+	case *Item:
+		return "item", nil
+	case *Trap:
+		return "trap", nil
+	case *Door:
+		return "door", nil
+	case *KeyObject:
+		return "key", nil
+	case *Feature:
+		return "feature", nil
+	}
+	return "", fmt.Errorf("cannot save world object %s of type %T", obj.GetID(), obj)
+}
+
+// UnmarshalYAML decodes a saved object into a new object of its kind.
+func (o *savedObject) UnmarshalYAML(value *yaml.Node) error {
+	var doc struct {
+		Kind   string    `yaml:"kind"`
+		Object yaml.Node `yaml:"object"`
+	}
+	if err := value.Decode(&doc); err != nil {
+		return err
+	}
+	newObject, ok := objectKinds[doc.Kind]
+	if !ok {
+		return fmt.Errorf("unknown world object kind %q at line %d", doc.Kind, value.Line)
+	}
+	obj := newObject()
+	if err := doc.Object.Decode(obj); err != nil {
+		return fmt.Errorf("failed to decode %s at line %d: %w", doc.Kind, value.Line, err)
+	}
+	o.Kind, o.Object = doc.Kind, obj
+	return nil
+}
+
+// MarshalYAML saves the world with each object tagged by its kind.
+func (w *World) MarshalYAML() (interface{}, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	objects := make(map[string]savedObject, len(w.Objects))
+	for id, obj := range w.Objects {
+		kind, err := objectKind(obj)
+		if err != nil {
+			return nil, err
+		}
+		objects[id] = savedObject{Kind: kind, Object: obj}
+	}
+	return savedWorld{worldDocument: (*worldDocument)(w), Objects: objects}, nil
+}
+
+// UnmarshalYAML restores a world saved by MarshalYAML. Saved players and
+// NPCs that are also world objects are shared rather than duplicated, and
+// the spatial grid and index, if any, are rebuilt from the objects.
+func (w *World) UnmarshalYAML(value *yaml.Node) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	doc := savedWorld{worldDocument: (*worldDocument)(w)}
+	if err := value.Decode(&doc); err != nil {
+		return err
+	}
+
+	w.Objects = make(map[string]GameObject, len(doc.Objects))
+	w.SpatialGrid = make(map[Position][]string, len(doc.Objects))
+	for id, saved := range doc.Objects {
+		obj := saved.Object
+		if player, ok := w.Players[id]; ok && saved.Kind == "player" {
+			obj = player
+		} else if npc, ok := w.NPCs[id]; ok && saved.Kind == "npc" {
+			obj = npc
+		}
+		w.Objects[id] = obj
+		pos := obj.GetPosition()
+		w.SpatialGrid[pos] = append(w.SpatialGrid[pos], id)
+	}
+
+	if w.SpatialIndex != nil {
+		if err := w.SpatialIndex.Rebuild(w.objectList()); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "UnmarshalYAML",
+				"package":  "game",
+				"error":    err,
+			}).Warn("some loaded objects could not be added to the spatial index")
+		}
+	}
+	return nil
+}
+
 // WorldState represents the serializable state of the world
 // Used for saving/loading game state
 type WorldState struct {
@@ -290,6 +463,18 @@ func NewWorldWithSize(width, height, cellSize int) *World {
 	}
 }
 
+// RelationshipGraph returns the world's NPC relationships, creating an
+// empty graph if the world has none yet.
+func (w *World) RelationshipGraph() *RelationshipGraph {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.Relationships == nil {
+		w.Relationships = NewRelationshipGraph()
+	}
+	return w.Relationships
+}
+
 // AddObject safely adds a GameObject to the world
 func (w *World) AddObject(obj GameObject) error {
 	w.mu.Lock()
@@ -299,11 +484,18 @@ func (w *World) AddObject(obj GameObject) error {
 		return fmt.Errorf("object with ID %s already exists", obj.GetID())
 	}
 
+	if w.Objects == nil {
+		w.Objects = make(map[string]GameObject)
+	}
+	if w.SpatialGrid == nil {
+		w.SpatialGrid = make(map[Position][]string)
+	}
 	w.Objects[obj.GetID()] = obj
 
 	// Update legacy spatial grid for compatibility
 	pos := obj.GetPosition()
 	w.SpatialGrid[pos] = append(w.SpatialGrid[pos], obj.GetID())
+	w.recordObjectChange(obj.GetID(), pos.Level)
 
 	// Update advanced spatial index
 	if w.SpatialIndex != nil {
@@ -317,6 +509,64 @@ func (w *World) AddObject(obj GameObject) error {
 	return nil
 }
 
+// GetObject safely returns the object with the given ID
+func (w *World) GetObject(objectID string) (GameObject, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	obj, exists := w.Objects[objectID]
+	return obj, exists
+}
+
+// AddLevels safely appends levels to the world. It returns the number of
+// levels the world had before, which TruncateLevels takes to undo the
+// addition.
+func (w *World) AddLevels(levels ...Level) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	count := len(w.Levels)
+	w.Levels = append(w.Levels, levels...)
+	return count
+}
+
+// TruncateLevels safely removes every level after the first n
+func (w *World) TruncateLevels(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n >= 0 && n < len(w.Levels) {
+		w.Levels = w.Levels[:n]
+	}
+}
+
+// LevelSnapshot returns a copy of the level at index, whose tile rows can
+// be read without the world lock, along with the objects on it sorted by
+// ID.
+func (w *World) LevelSnapshot(index int) (*Level, []GameObject, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if index < 0 || index >= len(w.Levels) {
+		return nil, nil, fmt.Errorf("level %d not found", index)
+	}
+
+	level := w.Levels[index]
+	level.Tiles = make([][]Tile, len(w.Levels[index].Tiles))
+	for y, row := range w.Levels[index].Tiles {
+		level.Tiles[y] = slices.Clone(row)
+	}
+
+	var objects []GameObject
+	for _, obj := range w.Objects {
+		if obj.GetPosition().Level == index {
+			objects = append(objects, obj)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].GetID() < objects[j].GetID() })
+	return &level, objects, nil
+}
+
 // GetObjectsAt returns all objects at a given position
 func (w *World) GetObjectsAt(pos Position) []GameObject {
 	w.mu.RLock()
@@ -347,6 +597,11 @@ func (w *World) ValidateMove(player *Player, newPos Position) error {
 		}
 	}
 
+	// Check if a quest consequence has locked the area
+	if area, locked := w.lockedAreaAt(newPos); locked {
+		return fmt.Errorf("area %s is locked", area)
+	}
+
 	// Additional validation logic can be added here (e.g., checking player abilities)
 
 	return nil
@@ -358,10 +613,32 @@ func (w *World) isPositionWithinBounds(pos Position) bool {
 	return pos.X >= 0 && pos.X < w.Width && pos.Y >= 0 && pos.Y < w.Height
 }
 
-// Serialize returns a map representation of the World state
+// RoomAt returns the ID of the room containing pos, as recorded in the
+// "room_id" property of generated level tiles, or "" outside any room.
+func (w *World) RoomAt(pos Position) string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if pos.Level < 0 || pos.Level >= len(w.Levels) {
+		return ""
+	}
+	level := &w.Levels[pos.Level]
+	if pos.Y < 0 || pos.Y >= len(level.Tiles) || pos.X < 0 || pos.X >= len(level.Tiles[pos.Y]) {
+		return ""
+	}
+	roomID, _ := level.Tiles[pos.Y][pos.X].Properties["room_id"].(string)
+	return roomID
+}
+
+// Serialize returns a map representation of the World state. The objects
+// map is a copy, so callers can encode it without holding the world lock.
 func (w *World) Serialize() map[string]interface{} {
+	w.mu.RLock()
+	objects := maps.Clone(w.Objects)
+	w.mu.RUnlock()
+
 	return map[string]interface{}{
-		"objects": w.Objects,
+		"objects": objects,
 	}
 }
 
@@ -468,6 +745,7 @@ func (w *World) UpdateObjectPosition(objectID string, newPos Position) error {
 	}
 
 	w.updateLegacySpatialGrid(objectID, oldPos, newPos)
+	w.recordObjectMove(objectID, oldPos, newPos)
 
 	if err := w.updateAdvancedSpatialIndex(objectID, newPos); err != nil {
 		return err
@@ -553,6 +831,7 @@ func (w *World) RemoveObject(objectID string) error {
 
 	// Remove from objects map
 	delete(w.Objects, objectID)
+	w.recordObjectRemoval(objectID, pos.Level)
 
 	// Remove from legacy spatial grid
 	if objects, exists := w.SpatialGrid[pos]; exists {
@@ -577,6 +856,83 @@ func (w *World) RemoveObject(objectID string) error {
 	return nil
 }
 
+// RebuildSpatialIndex rebuilds the legacy spatial grid and the advanced
+// spatial index, if any, from the current positions of all objects. Use it
+// after objects have been moved or loaded without going through the world,
+// such as after restoring a saved world whose index was not persisted.
+func (w *World) RebuildSpatialIndex() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.SpatialGrid = make(map[Position][]string, len(w.Objects))
+	for id, obj := range w.Objects {
+		w.addObjectToSpatialGrid(id, obj.GetPosition())
+	}
+
+	if w.SpatialIndex != nil {
+		if err := w.SpatialIndex.Rebuild(w.objectList()); err != nil {
+			return fmt.Errorf("failed to rebuild spatial index: %w", err)
+		}
+	}
+	return nil
+}
+
+// TrackMovement keeps the world's spatial indexes current as objects move,
+// by handling the EventMovement events emitted by es. See HandleMovement.
+func (w *World) TrackMovement(es *EventSystem) {
+	es.Subscribe(EventMovement, w.HandleMovement)
+}
+
+// HandleMovement re-indexes the object that moved in a movement event. The
+// object's position must already have been changed; the event's
+// "old_position" data tells where it was indexed before. Events for
+// objects that are not in the world are ignored.
+//
+// Movement events are delivered asynchronously and may arrive out of
+// order, so the object is always indexed at its current position rather
+// than the position carried by the event.
+func (w *World) HandleMovement(event GameEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	obj, exists := w.Objects[event.SourceID]
+	if !exists {
+		return
+	}
+	pos := obj.GetPosition()
+
+	oldPos, ok := event.Data["old_position"].(Position)
+	if ok && oldPos != pos {
+		w.removeObjectFromSpatialGrid(event.SourceID, oldPos)
+	}
+	if !ok {
+		oldPos = pos
+	}
+	w.recordObjectMove(event.SourceID, oldPos, pos)
+	if !slices.Contains(w.SpatialGrid[pos], event.SourceID) {
+		w.addObjectToSpatialGrid(event.SourceID, pos)
+	}
+
+	if err := w.updateAdvancedSpatialIndex(event.SourceID, pos); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "HandleMovement",
+			"package":   "game",
+			"object_id": event.SourceID,
+			"position":  pos,
+			"error":     err,
+		}).Warn("failed to re-index moved object")
+	}
+}
+
+// objectList returns the world's objects as a slice (requires the lock)
+func (w *World) objectList() []GameObject {
+	objects := make([]GameObject, 0, len(w.Objects))
+	for _, obj := range w.Objects {
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
 // GetSpatialIndexStats returns performance statistics for the spatial indexing system
 func (w *World) GetSpatialIndexStats() *SpatialIndexStats {
 	w.mu.RLock()
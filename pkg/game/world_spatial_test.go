@@ -1,6 +1,7 @@
 package game
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -268,3 +269,58 @@ func TestWorld_LegacyCompatibility(t *testing.T) {
 		t.Error("Expected spatial index to be initialized with NewWorldWithSize")
 	}
 }
+
+func TestWorld_HandleMovement(t *testing.T) {
+	world := NewWorldWithSize(100, 100, 10)
+	npc := &NPC{Character: Character{ID: "npc1", Position: Position{X: 5, Y: 5}}}
+	if err := world.AddObject(npc); err != nil {
+		t.Fatalf("Failed to add NPC: %v", err)
+	}
+
+	// Movement applied outside the world, as the move handler does
+	oldPos := npc.GetPosition()
+	npc.SetPosition(Position{X: 60, Y: 40})
+	event := GameEvent{
+		Type:     EventMovement,
+		SourceID: "npc1",
+		Data:     map[string]interface{}{"old_position": oldPos, "new_position": npc.GetPosition()},
+	}
+	world.HandleMovement(event)
+	world.HandleMovement(event) // Redelivery must not duplicate the object
+
+	if objects := world.GetObjectsAt(Position{X: 60, Y: 40}); len(objects) != 1 {
+		t.Errorf("Expected 1 object at the new position in the grid, got %d", len(objects))
+	}
+	if objects := world.GetObjectsAt(oldPos); len(objects) != 0 {
+		t.Errorf("Expected old grid position to be empty, got %d objects", len(objects))
+	}
+	if objects := world.GetObjectsInRadius(Position{X: 60, Y: 40}, 1); len(objects) != 1 {
+		t.Errorf("Expected the spatial index to find the moved NPC, got %d objects", len(objects))
+	}
+
+	world.HandleMovement(GameEvent{Type: EventMovement, SourceID: "unknown"})
+}
+
+func TestWorld_RebuildSpatialIndex(t *testing.T) {
+	world := NewWorldWithSize(100, 100, 10)
+	for i := 0; i < 20; i++ {
+		world.AddObject(&NPC{Character: Character{ID: fmt.Sprintf("npc%d", i), Position: Position{X: i, Y: i}}})
+	}
+
+	// Objects moved directly leave both indexes stale
+	for _, obj := range world.Objects {
+		pos := obj.GetPosition()
+		obj.SetPosition(Position{X: pos.X + 50, Y: pos.Y})
+	}
+
+	if err := world.RebuildSpatialIndex(); err != nil {
+		t.Fatalf("RebuildSpatialIndex failed: %v", err)
+	}
+
+	if objects := world.GetObjectsAt(Position{X: 53, Y: 3}); len(objects) != 1 {
+		t.Errorf("Expected the grid to be rebuilt, got %d objects at (53,3)", len(objects))
+	}
+	if objects := world.GetObjectsInRange(Rectangle{MinX: 50, MinY: 0, MaxX: 69, MaxY: 19}); len(objects) != 20 {
+		t.Errorf("Expected 20 objects in the rebuilt index, got %d", len(objects))
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // TestNewWorld tests the creation of a new World instance
@@ -143,6 +145,40 @@ func TestWorld_AddObject(t *testing.T) {
 	}
 }
 
+// TestWorld_AddLevels tests appending and truncating levels
+func TestWorld_AddLevels(t *testing.T) {
+	world := &World{}
+
+	if count := world.AddLevels(Level{ID: "one"}); count != 0 {
+		t.Errorf("AddLevels() = %d, want 0", count)
+	}
+	count := world.AddLevels(Level{ID: "two"}, Level{ID: "three"})
+	if count != 1 || len(world.Levels) != 3 {
+		t.Fatalf("AddLevels() = %d with %d levels, want 1 with 3", count, len(world.Levels))
+	}
+
+	world.TruncateLevels(count)
+	if len(world.Levels) != 1 || world.Levels[0].ID != "one" {
+		t.Errorf("TruncateLevels() left %v", world.Levels)
+	}
+}
+
+// TestWorld_AddObjectInitializesMaps tests adding to a world built without maps
+func TestWorld_AddObjectInitializesMaps(t *testing.T) {
+	world := &World{}
+	item := &Item{ID: "item-1", Name: "Dagger"}
+
+	if err := world.AddObject(item); err != nil {
+		t.Fatalf("AddObject() error = %v", err)
+	}
+	if obj, exists := world.GetObject("item-1"); !exists || obj != GameObject(item) {
+		t.Errorf("GetObject() = %v, %v", obj, exists)
+	}
+	if len(world.GetObjectsAt(Position{})) != 1 {
+		t.Error("object missing from the spatial grid")
+	}
+}
+
 // TestWorld_GetObjectsAt tests retrieving objects at a specific position
 func TestWorld_GetObjectsAt(t *testing.T) {
 	world := NewWorld()
@@ -754,6 +790,72 @@ func TestWorld_Serialize(t *testing.T) {
 	}
 }
 
+// TestWorld_YAMLRoundTrip tests that world objects survive a save and load
+func TestWorld_YAMLRoundTrip(t *testing.T) {
+	world := NewWorldWithSize(20, 20, 5)
+	player := &Player{Character: Character{ID: "player1", Name: "Hero", Position: Position{X: 1, Y: 2}, HP: 7}}
+	world.Players[player.ID] = player
+	objects := []GameObject{
+		player,
+		&NPC{Character: Character{ID: "npc1", Name: "Merchant", Position: Position{X: 3, Y: 3}}, Faction: "guild"},
+		&Character{ID: "wall1", Name: "Wall of Stone", Position: Position{X: 4, Y: 4}},
+		&Item{ID: "item1", Name: "Dagger", Type: "weapon", Position: Position{X: 5, Y: 5}},
+		&Trap{ID: "trap1", Name: "Pit", Position: Position{X: 6, Y: 6}, Armed: true},
+		&Door{ID: "door1", Name: "Door", Position: Position{X: 7, Y: 7}, State: DoorLocked, KeyID: "key1"},
+		NewKeyObject("key1", "Door", Position{X: 8, Y: 8}),
+	}
+	for _, obj := range objects {
+		if err := world.AddObject(obj); err != nil {
+			t.Fatalf("AddObject(%s) error = %v", obj.GetID(), err)
+		}
+	}
+
+	data, err := yaml.Marshal(world)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	loaded := NewWorldWithSize(20, 20, 5)
+	if err := yaml.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if len(loaded.Objects) != len(objects) {
+		t.Fatalf("loaded %d objects, want %d", len(loaded.Objects), len(objects))
+	}
+	for _, obj := range objects {
+		got, exists := loaded.GetObject(obj.GetID())
+		if !exists {
+			t.Fatalf("object %s not loaded", obj.GetID())
+		}
+		if reflect.TypeOf(got) != reflect.TypeOf(obj) || got.GetPosition() != obj.GetPosition() {
+			t.Errorf("object %s loaded as %T at %v", obj.GetID(), got, got.GetPosition())
+		}
+	}
+	if got, _ := loaded.GetObject("player1"); got != GameObject(loaded.Players["player1"]) {
+		t.Error("the loaded player object should be shared with Players")
+	}
+	if trap, _ := loaded.GetObject("trap1"); !trap.(*Trap).Armed {
+		t.Error("trap fields should be restored")
+	}
+	if door, _ := loaded.GetObject("door1"); door.(*Door).GetState() != DoorLocked {
+		t.Error("door fields should be restored")
+	}
+	if key, _ := loaded.GetObject("key1"); key.(*KeyObject).Item.Type != ItemTypeKey {
+		t.Error("key items should be restored")
+	}
+	if len(loaded.GetObjectsInRange(Rectangle{MinX: 0, MinY: 0, MaxX: 19, MaxY: 19})) != len(objects) {
+		t.Error("loaded objects should be in the spatial index")
+	}
+	if ids := loaded.SpatialGrid[Position{X: 3, Y: 3}]; len(ids) != 1 || ids[0] != "npc1" {
+		t.Errorf("spatial grid at 3,3 = %v, want [npc1]", ids)
+	}
+
+	world.Objects["mock"] = &MockObstacle{id: "mock"}
+	if _, err := yaml.Marshal(world); err == nil {
+		t.Error("Marshal() should reject objects of unknown types")
+	}
+}
+
 // MockObstacle is a test helper that implements GameObject interface
 type MockObstacle struct {
 	id         string
@@ -106,6 +106,9 @@ type NPC struct {
 	Faction   string           `yaml:"npc_faction"`    // Allegiance group
 	Dialog    []DialogEntry    `yaml:"npc_dialog"`     // Conversation options
 	LootTable []LootEntry      `yaml:"npc_loot_table"` // Droppable items
+
+	// Modifiers adjust the NPC's AI by its personality, nil for none
+	Modifiers *BehaviorModifiers `yaml:"npc_modifiers,omitempty"`
 }
 
 // DialogEntry represents a single dialog interaction node in the game's conversation system.
@@ -0,0 +1,30 @@
+// Package lockstat provides mutexes that can report how long callers wait
+// to acquire them, for finding lock contention under load.
+//
+// Mutex and RWMutex behave like their sync counterparts and are usable as
+// zero values. A lock is named by a type parameter, so every lock of a
+// struct type is reported under the same name without a constructor:
+//
+//	type worldLock struct{}
+//
+//	func (worldLock) LockName() string { return "world" }
+//
+//	type World struct {
+//	    mu lockstat.RWMutex[worldLock]
+//	}
+//
+// # Build Tags
+//
+// Wait times are only recorded in binaries built with the lockprofile tag:
+//
+//	go build -tags lockprofile ./cmd/server
+//
+// Without it Enabled is false and the mutexes are plain wrappers the
+// compiler inlines, so production builds pay nothing for them.
+//
+// # Reading Wait Times
+//
+// Snapshot returns a cumulative histogram of the wait times of each lock
+// and acquisition mode ("read" or "write"), bucketed by Buckets. The server
+// exports it as the goldbox_lock_wait_seconds Prometheus histogram.
+package lockstat
@@ -0,0 +1,149 @@
+package lockstat
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Name names the locks of a type in wait time statistics
+type Name interface {
+	LockName() string
+}
+
+// Acquisition modes reported in WaitStats
+const (
+	ModeRead  = "read"
+	ModeWrite = "write"
+)
+
+// Buckets are the upper bounds of the wait time histogram buckets. Waits
+// longer than the last bound are only counted in the total.
+var Buckets = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// Mutex is a sync.Mutex whose wait times are recorded under N's name in
+// builds with the lockprofile tag
+type Mutex[N Name] struct {
+	mu sync.Mutex
+}
+
+// Unlock unlocks m
+func (m *Mutex[N]) Unlock() {
+	m.mu.Unlock()
+}
+
+// RWMutex is a sync.RWMutex whose wait times are recorded under N's name
+// in builds with the lockprofile tag
+type RWMutex[N Name] struct {
+	mu sync.RWMutex
+}
+
+// Unlock unlocks m for writing
+func (m *RWMutex[N]) Unlock() {
+	m.mu.Unlock()
+}
+
+// RUnlock undoes a single RLock call
+func (m *RWMutex[N]) RUnlock() {
+	m.mu.RUnlock()
+}
+
+// WaitStats is the wait time histogram of one lock and acquisition mode
+type WaitStats struct {
+	Lock string
+	Mode string
+
+	// Count is the number of acquisitions
+	Count uint64
+
+	// Total is the time spent waiting over all acquisitions
+	Total time.Duration
+
+	// Buckets holds, for each bound in Buckets, the number of acquisitions
+	// that waited no longer than it
+	Buckets []uint64
+}
+
+// waitKey identifies the statistics of a lock and mode
+type waitKey struct {
+	lock string
+	mode string
+}
+
+// waitHistogram accumulates the wait times of a lock and mode
+type waitHistogram struct {
+	count   atomic.Uint64
+	total   atomic.Int64
+	buckets []atomic.Uint64 // Not cumulative; Snapshot sums them
+}
+
+// waits maps waitKeys to their *waitHistogram
+var waits sync.Map
+
+// record adds a wait of d acquiring lock in mode
+func record(lock, mode string, d time.Duration) {
+	key := waitKey{lock: lock, mode: mode}
+	h, ok := waits.Load(key)
+	if !ok {
+		h, _ = waits.LoadOrStore(key, &waitHistogram{buckets: make([]atomic.Uint64, len(Buckets))})
+	}
+	hist := h.(*waitHistogram)
+
+	hist.count.Add(1)
+	hist.total.Add(int64(d))
+	for i, bound := range Buckets {
+		if d <= bound {
+			hist.buckets[i].Add(1)
+			break
+		}
+	}
+}
+
+// Snapshot returns the wait time statistics recorded so far, ordered by
+// lock name and mode. It is empty unless Enabled.
+func Snapshot() []WaitStats {
+	var stats []WaitStats
+	waits.Range(func(k, v interface{}) bool {
+		key := k.(waitKey)
+		hist := v.(*waitHistogram)
+		s := WaitStats{
+			Lock:    key.lock,
+			Mode:    key.mode,
+			Count:   hist.count.Load(),
+			Total:   time.Duration(hist.total.Load()),
+			Buckets: make([]uint64, len(Buckets)),
+		}
+		var cumulative uint64
+		for i := range hist.buckets {
+			cumulative += hist.buckets[i].Load()
+			s.Buckets[i] = cumulative
+		}
+		stats = append(stats, s)
+		return true
+	})
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Lock != stats[j].Lock {
+			return stats[i].Lock < stats[j].Lock
+		}
+		return stats[i].Mode < stats[j].Mode
+	})
+	return stats
+}
+
+// Reset discards the recorded statistics
+func Reset() {
+	waits.Range(func(k, _ interface{}) bool {
+		waits.Delete(k)
+		return true
+	})
+}
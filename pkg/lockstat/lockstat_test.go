@@ -0,0 +1,93 @@
+package lockstat
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLock struct{}
+
+func (testLock) LockName() string { return "test" }
+
+func TestSnapshot(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	record("b", ModeWrite, 0)
+	record("b", ModeWrite, 5*time.Millisecond)
+	record("b", ModeWrite, 2*time.Second)
+	record("a", ModeRead, 50*time.Microsecond)
+
+	stats := Snapshot()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "a", stats[0].Lock, "ordered by lock name")
+	assert.Equal(t, ModeRead, stats[0].Mode)
+
+	b := stats[1]
+	assert.Equal(t, uint64(3), b.Count)
+	assert.Equal(t, 2*time.Second+5*time.Millisecond, b.Total)
+	assert.Equal(t, []uint64{1, 1, 1, 1, 2, 2, 2}, b.Buckets, "buckets are cumulative; waits past the last bound only count in the total")
+
+	Reset()
+	assert.Empty(t, Snapshot())
+}
+
+func TestRWMutex_RecordsContention(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var mu RWMutex[testLock]
+	mu.Lock()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mu.RLock()
+		mu.RUnlock()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	mu.Unlock()
+	wg.Wait()
+
+	stats := Snapshot()
+	if !Enabled {
+		assert.Empty(t, stats, "nothing is recorded without the lockprofile tag")
+		return
+	}
+	require.Len(t, stats, 2)
+	assert.Equal(t, WaitStats{Lock: "test", Mode: ModeRead}, WaitStats{Lock: stats[0].Lock, Mode: stats[0].Mode})
+	assert.GreaterOrEqual(t, stats[0].Total, 10*time.Millisecond, "the reader waited for the writer")
+	assert.Equal(t, uint64(1), stats[1].Count)
+	assert.Equal(t, uint64(1), stats[1].Buckets[0], "the uncontended write lock did not wait")
+}
+
+func TestMutex(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var mu Mutex[testLock]
+	counter := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				mu.Lock()
+				counter++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 800, counter)
+	if Enabled {
+		require.Len(t, Snapshot(), 1)
+		assert.Equal(t, uint64(800), Snapshot()[0].Count)
+	}
+}
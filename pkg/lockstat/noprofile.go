@@ -0,0 +1,22 @@
+//go:build !lockprofile
+
+package lockstat
+
+// Enabled reports whether wait times are recorded, which they are only in
+// builds with the lockprofile tag
+const Enabled = false
+
+// Lock locks m
+func (m *Mutex[N]) Lock() {
+	m.mu.Lock()
+}
+
+// Lock locks m for writing
+func (m *RWMutex[N]) Lock() {
+	m.mu.Lock()
+}
+
+// RLock locks m for reading
+func (m *RWMutex[N]) RLock() {
+	m.mu.RLock()
+}
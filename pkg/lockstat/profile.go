@@ -0,0 +1,48 @@
+//go:build lockprofile
+
+package lockstat
+
+import "time"
+
+// Enabled reports whether wait times are recorded, which they are in builds
+// with the lockprofile tag
+const Enabled = true
+
+// Lock locks m, recording how long it waited
+func (m *Mutex[N]) Lock() {
+	if m.mu.TryLock() {
+		record(lockName[N](), ModeWrite, 0)
+		return
+	}
+	start := time.Now()
+	m.mu.Lock()
+	record(lockName[N](), ModeWrite, time.Since(start))
+}
+
+// Lock locks m for writing, recording how long it waited
+func (m *RWMutex[N]) Lock() {
+	if m.mu.TryLock() {
+		record(lockName[N](), ModeWrite, 0)
+		return
+	}
+	start := time.Now()
+	m.mu.Lock()
+	record(lockName[N](), ModeWrite, time.Since(start))
+}
+
+// RLock locks m for reading, recording how long it waited
+func (m *RWMutex[N]) RLock() {
+	if m.mu.TryRLock() {
+		record(lockName[N](), ModeRead, 0)
+		return
+	}
+	start := time.Now()
+	m.mu.RLock()
+	record(lockName[N](), ModeRead, time.Since(start))
+}
+
+// lockName returns the name of the locks named by N
+func lockName[N Name]() string {
+	var name N
+	return name.LockName()
+}
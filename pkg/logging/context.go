@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fieldsKey is the context key for request-scoped log fields.
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx whose request-scoped log fields
+// include fields, added to any that ctx already carries.
+func ContextWithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	merged := make(logrus.Fields, len(fields))
+	for key, value := range FieldsFromContext(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the request-scoped log fields stored in ctx.
+// The returned map must not be modified.
+func FieldsFromContext(ctx context.Context) logrus.Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	return fields
+}
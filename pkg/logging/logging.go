@@ -0,0 +1,232 @@
+// Package logging is a facade over logrus that gives each subsystem a named
+// logger with its own level, carries request-scoped fields in contexts, and
+// samples high-frequency debug logs.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Standard field names shared by every subsystem, so log lines for the same
+// request can be correlated across subsystems.
+const (
+	FieldSubsystem     = "subsystem"
+	FieldTraceID       = "trace_id"
+	FieldCorrelationID = "correlation_id"
+	FieldOTelTraceID   = "otel_trace_id"
+	FieldSpanID        = "span_id"
+	FieldSessionID     = "session_id"
+	FieldMethod        = "rpc_method"
+	FieldSampled       = "sampled_out"
+)
+
+// registry holds every subsystem logger and the level overrides configured
+// for them.
+var registry = struct {
+	sync.Mutex
+	loggers   map[string]*Logger
+	overrides map[string]logrus.Level
+}{
+	loggers:   make(map[string]*Logger),
+	overrides: make(map[string]logrus.Level),
+}
+
+// Logger is the logger of one subsystem. Until its level is overridden it
+// logs through the standard logrus logger; afterwards it uses a logger of
+// its own that shares the standard logger's output, formatter and hooks.
+type Logger struct {
+	name       string
+	logger     *logrus.Logger
+	overridden atomic.Bool
+	sampler    *Sampler
+}
+
+// For returns the logger of the named subsystem, creating it on first use.
+func For(subsystem string) *Logger {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if l, exists := registry.loggers[subsystem]; exists {
+		return l
+	}
+
+	l := &Logger{
+		name: subsystem,
+		logger: &logrus.Logger{
+			Out:       standardOutput{},
+			Formatter: standardFormatter{},
+			Hooks:     logrus.StandardLogger().Hooks,
+		},
+		sampler: NewSampler(DefaultSamplePeriod, DefaultSampleBurst, DefaultSampleEvery),
+	}
+	l.applyLocked()
+	registry.loggers[subsystem] = l
+	return l
+}
+
+// applyLocked switches the logger to its configured level. The caller must
+// hold the registry lock.
+func (l *Logger) applyLocked() {
+	level, exists := registry.overrides[l.name]
+	if exists {
+		l.logger.ReportCaller = logrus.StandardLogger().ReportCaller
+		l.logger.SetLevel(level)
+	}
+	l.overridden.Store(exists)
+}
+
+// base returns the logrus logger entries are created from.
+func (l *Logger) base() *logrus.Logger {
+	if l.overridden.Load() {
+		return l.logger
+	}
+	return logrus.StandardLogger()
+}
+
+// Name returns the subsystem name.
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// Level returns the level the subsystem currently logs at.
+func (l *Logger) Level() logrus.Level {
+	return l.base().GetLevel()
+}
+
+// Entry returns an entry tagged with the subsystem name.
+func (l *Logger) Entry() *logrus.Entry {
+	return l.base().WithField(FieldSubsystem, l.name)
+}
+
+// WithField returns a subsystem entry with one extra field.
+func (l *Logger) WithField(key string, value interface{}) *logrus.Entry {
+	return l.Entry().WithField(key, value)
+}
+
+// WithFields returns a subsystem entry with extra fields.
+func (l *Logger) WithFields(fields logrus.Fields) *logrus.Entry {
+	return l.Entry().WithFields(fields)
+}
+
+// WithContext returns a subsystem entry carrying the request-scoped fields
+// stored in ctx by ContextWithFields.
+func (l *Logger) WithContext(ctx context.Context) *logrus.Entry {
+	return l.Entry().WithFields(FieldsFromContext(ctx)).WithContext(ctx)
+}
+
+// Sample returns a subsystem entry for a high-frequency log line identified
+// by key, or an entry that discards everything when the sampler drops it.
+// Kept entries report how many lines were dropped since the previous one.
+// Keys name call sites and should not vary per request.
+func (l *Logger) Sample(key string) *logrus.Entry {
+	return l.SampleEntry(l.Entry(), key)
+}
+
+// SampleEntry is Sample for an entry that already carries fields.
+func (l *Logger) SampleEntry(entry *logrus.Entry, key string) *logrus.Entry {
+	keep, dropped := l.sampler.Allow(key)
+	if !keep {
+		return discard
+	}
+	if dropped > 0 {
+		entry = entry.WithField(FieldSampled, dropped)
+	}
+	return entry
+}
+
+// SetSampling replaces the subsystem's sampling policy. Call it while
+// setting up, before the subsystem logs.
+func (l *Logger) SetSampling(sampler *Sampler) {
+	l.sampler = sampler
+}
+
+// SetLevel overrides the level of a subsystem, whether or not its logger
+// exists yet.
+func SetLevel(subsystem string, level logrus.Level) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.overrides[subsystem] = level
+	if l, exists := registry.loggers[subsystem]; exists {
+		l.applyLocked()
+	}
+}
+
+// Configure sets the level of the standard logger, which subsystems without
+// an override follow, and replaces the overrides with those in spec (see
+// ParseLevels).
+func Configure(defaultLevel, spec string) error {
+	level, err := logrus.ParseLevel(defaultLevel)
+	if err != nil {
+		return err
+	}
+	overrides, err := ParseLevels(spec)
+	if err != nil {
+		return err
+	}
+
+	logrus.SetLevel(level)
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.overrides = overrides
+	for _, l := range registry.loggers {
+		l.applyLocked()
+	}
+	return nil
+}
+
+// ParseLevels parses per-subsystem levels written as comma separated
+// subsystem=level pairs, such as "pcg=warn,server=debug".
+func ParseLevels(spec string) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid subsystem level %q, expected subsystem=level", pair)
+		}
+		level, err := logrus.ParseLevel(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid level for subsystem %s: %w", name, err)
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+// discard is the entry returned for sampled out log lines.
+var discard = logrus.NewEntry(&logrus.Logger{
+	Out:       io.Discard,
+	Formatter: new(logrus.TextFormatter),
+	Hooks:     make(logrus.LevelHooks),
+	Level:     logrus.PanicLevel,
+})
+
+// standardOutput writes to whatever the standard logger currently writes
+// to, so redirecting it also redirects every subsystem.
+type standardOutput struct{}
+
+func (standardOutput) Write(p []byte) (int, error) {
+	return logrus.StandardLogger().Out.Write(p)
+}
+
+// standardFormatter formats entries with the standard logger's formatter.
+type standardFormatter struct{}
+
+func (standardFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return logrus.StandardLogger().Formatter.Format(entry)
+}
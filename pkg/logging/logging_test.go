@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// captureOutput redirects the standard logger to a buffer for the test.
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	std := logrus.StandardLogger()
+	out, level := std.Out, std.GetLevel()
+	t.Cleanup(func() {
+		std.SetOutput(out)
+		std.SetLevel(level)
+	})
+
+	var buf bytes.Buffer
+	std.SetOutput(&buf)
+	return &buf
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels(" pcg=warn, rpc = debug ,")
+	if err != nil {
+		t.Fatalf("ParseLevels failed: %v", err)
+	}
+	if levels["pcg"] != logrus.WarnLevel || levels["rpc"] != logrus.DebugLevel || len(levels) != 2 {
+		t.Errorf("unexpected levels %v", levels)
+	}
+
+	for _, spec := range []string{"pcg", "=debug", "pcg=loud"} {
+		if _, err := ParseLevels(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestLogger_SubsystemLevels(t *testing.T) {
+	buf := captureOutput(t)
+	logrus.SetLevel(logrus.InfoLevel)
+
+	quiet := For("test_quiet")
+	loud := For("test_loud")
+	if For("test_quiet") != quiet {
+		t.Error("For should return the same logger for a subsystem")
+	}
+
+	if err := Configure("info", "test_quiet=error,test_loud=debug"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Configure(logrus.InfoLevel.String(), "") })
+
+	quiet.Entry().Warn("quiet warning")
+	loud.Entry().Debug("loud debug")
+	For("test_default").Entry().Debug("default debug")
+
+	output := buf.String()
+	if strings.Contains(output, "quiet warning") {
+		t.Error("warnings should be filtered for test_quiet")
+	}
+	if !strings.Contains(output, "loud debug") || !strings.Contains(output, "subsystem=test_loud") {
+		t.Errorf("expected debug output tagged with the subsystem, got %q", output)
+	}
+	if strings.Contains(output, "default debug") {
+		t.Error("subsystems without an override should follow the standard level")
+	}
+
+	// Dropping the override makes the subsystem follow the standard logger again
+	if err := Configure("warn", ""); err != nil {
+		t.Fatal(err)
+	}
+	if loud.Level() != logrus.WarnLevel {
+		t.Errorf("expected warn level, got %s", loud.Level())
+	}
+}
+
+func TestLogger_WithContext(t *testing.T) {
+	buf := captureOutput(t)
+
+	ctx := ContextWithFields(context.Background(), logrus.Fields{FieldTraceID: "trace-1"})
+	ctx = ContextWithFields(ctx, logrus.Fields{FieldSessionID: "session-1"})
+
+	For("test_context").WithContext(ctx).Info("handled")
+
+	output := buf.String()
+	for _, want := range []string{"trace_id=trace-1", "session_id=session-1", "subsystem=test_context"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in %q", want, output)
+		}
+	}
+
+	if len(FieldsFromContext(context.Background())) != 0 {
+		t.Error("expected no fields in an empty context")
+	}
+}
+
+func TestSampler_Allow(t *testing.T) {
+	now := time.Now()
+	sampler := NewSampler(time.Second, 2, 3)
+	sampler.now = func() time.Time { return now }
+
+	var kept []int
+	dropped := 0
+	for i := 1; i <= 8; i++ {
+		keep, d := sampler.Allow("key")
+		if keep {
+			kept = append(kept, i)
+			dropped += d
+		}
+	}
+	// Two in the burst, then every third: lines 5 and 8
+	if len(kept) != 4 || kept[2] != 5 || kept[3] != 8 {
+		t.Errorf("unexpected kept lines %v", kept)
+	}
+	if dropped != 4 {
+		t.Errorf("expected 4 dropped lines to be reported, got %d", dropped)
+	}
+
+	if keep, _ := sampler.Allow("other"); !keep {
+		t.Error("keys should be sampled independently")
+	}
+
+	now = now.Add(time.Second)
+	if keep, _ := sampler.Allow("key"); !keep {
+		t.Error("a new period should start a new burst")
+	}
+}
+
+func TestLogger_Sample(t *testing.T) {
+	buf := captureOutput(t)
+	logrus.SetLevel(logrus.DebugLevel)
+
+	logger := For("test_sample")
+	logger.SetSampling(NewSampler(time.Hour, 1, 0))
+
+	for i := 0; i < 5; i++ {
+		logger.Sample("loop").Debug("tick")
+	}
+	if count := strings.Count(buf.String(), "tick"); count != 1 {
+		t.Errorf("expected one sampled line, got %d", count)
+	}
+}
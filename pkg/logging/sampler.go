@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"sync"
+	"time"
+)
+
+// Default sampling policy: in each second, the first 10 lines for a key are
+// logged, then every 100th.
+const (
+	DefaultSamplePeriod = time.Second
+	DefaultSampleBurst  = 10
+	DefaultSampleEvery  = 100
+)
+
+// Sampler thins out repetitive log lines. Within each period, the first
+// burst lines for a key are kept, then only every every-th one.
+type Sampler struct {
+	period time.Duration
+	burst  int
+	every  int
+	now    func() time.Time
+
+	mu     sync.Mutex
+	counts map[string]*sampleCount
+}
+
+// sampleCount tracks the lines seen for one key in the current period.
+type sampleCount struct {
+	start   time.Time
+	seen    int
+	dropped int
+}
+
+// NewSampler creates a sampler. An every of 0 drops all lines past the
+// burst.
+func NewSampler(period time.Duration, burst, every int) *Sampler {
+	return &Sampler{
+		period: period,
+		burst:  burst,
+		every:  every,
+		now:    time.Now,
+		counts: make(map[string]*sampleCount),
+	}
+}
+
+// Allow reports whether the next line for key should be logged and, if so,
+// how many lines for key were dropped since the last one that was.
+func (s *Sampler) Allow(key string) (keep bool, dropped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	count, exists := s.counts[key]
+	if !exists {
+		count = &sampleCount{start: now}
+		s.counts[key] = count
+	} else if now.Sub(count.start) >= s.period {
+		count.start, count.seen = now, 0
+	}
+
+	count.seen++
+	past := count.seen - s.burst
+	if past > 0 && (s.every <= 0 || past%s.every != 0) {
+		count.dropped++
+		return false, 0
+	}
+
+	dropped, count.dropped = count.dropped, 0
+	return true, dropped
+}
@@ -0,0 +1,330 @@
+package pcg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BenchmarkBudgets declares the generation matrix the benchmark harness runs
+// and the performance budgets each case must meet. It is usually loaded from
+// YAML:
+//
+//	iterations: 5
+//	matrix:
+//	  terrain:
+//	    sizes: [20, 40]
+//	    difficulties: [1, 10]
+//	budgets:
+//	  - content_type: terrain
+//	    size: 40
+//	    p95: 50ms
+//	    allocs_per_op: 100000
+type BenchmarkBudgets struct {
+	Iterations int                                 `yaml:"iterations"` // Runs per case; percentiles are taken across them
+	Matrix     map[ContentType]BenchmarkDimensions `yaml:"matrix"`     // Sizes and difficulties by content type
+	Budgets    []PerformanceBudget                 `yaml:"budgets"`    // Limits applied to matching cases
+}
+
+// BenchmarkDimensions lists the sizes and difficulties benchmarked for one
+// content type. Every size is run at every difficulty.
+//
+// The meaning of size depends on the content type: the width and height of
+// terrain, the maximum room count of levels, and the number of items or
+// quests generated per run.
+type BenchmarkDimensions struct {
+	Sizes        []int `yaml:"sizes"`
+	Difficulties []int `yaml:"difficulties"`
+}
+
+// PerformanceBudget limits the cost of generating one content type. A
+// budget applies to every case of its content type whose size and
+// difficulty match; zero size or difficulty matches any, and zero limits are
+// not checked. All matching budgets apply to a case.
+type PerformanceBudget struct {
+	ContentType ContentType   `yaml:"content_type" json:"content_type"`
+	Size        int           `yaml:"size,omitempty" json:"size,omitempty"`
+	Difficulty  int           `yaml:"difficulty,omitempty" json:"difficulty,omitempty"`
+	P50         time.Duration `yaml:"p50,omitempty" json:"p50_ns,omitempty"`
+	P95         time.Duration `yaml:"p95,omitempty" json:"p95_ns,omitempty"`
+	AllocsPerOp uint64        `yaml:"allocs_per_op,omitempty" json:"allocs_per_op,omitempty"`
+	BytesPerOp  uint64        `yaml:"bytes_per_op,omitempty" json:"bytes_per_op,omitempty"`
+}
+
+// BenchmarkCase is one cell of the benchmark matrix
+type BenchmarkCase struct {
+	ContentType ContentType `json:"content_type"`
+	Size        int         `json:"size"`
+	Difficulty  int         `json:"difficulty"`
+}
+
+// Name identifies the case in reports, e.g. "terrain/size=40/difficulty=10"
+func (c BenchmarkCase) Name() string {
+	return fmt.Sprintf("%s/size=%d/difficulty=%d", c.ContentType, c.Size, c.Difficulty)
+}
+
+// BenchmarkResult holds the measurements of one benchmark case. Allocation
+// figures are averaged over the iterations.
+type BenchmarkResult struct {
+	BenchmarkCase
+	Iterations  int           `json:"iterations"`
+	Errors      int           `json:"errors"`
+	P50         time.Duration `json:"p50_ns"`
+	P95         time.Duration `json:"p95_ns"`
+	AllocsPerOp uint64        `json:"allocs_per_op"`
+	BytesPerOp  uint64        `json:"bytes_per_op"`
+}
+
+// BenchmarkViolation records a case that exceeded a budget. Limit and
+// Actual are in nanoseconds for the p50 and p95 metrics.
+type BenchmarkViolation struct {
+	Case   string `json:"case"`
+	Metric string `json:"metric"` // p50, p95, allocs_per_op, bytes_per_op or errors
+	Limit  uint64 `json:"limit"`
+	Actual uint64 `json:"actual"`
+}
+
+// BenchmarkReport is the structured outcome of a benchmark run
+type BenchmarkReport struct {
+	Timestamp  time.Time            `json:"timestamp"`
+	Iterations int                  `json:"iterations"`
+	Results    []BenchmarkResult    `json:"results"`
+	Violations []BenchmarkViolation `json:"violations"`
+	Passed     bool                 `json:"passed"`
+}
+
+// FailedCases returns the number of cases with at least one violation
+func (r *BenchmarkReport) FailedCases() int {
+	failed := make(map[string]bool)
+	for _, violation := range r.Violations {
+		failed[violation.Case] = true
+	}
+	return len(failed)
+}
+
+// benchmarkRunner generates one run's worth of content for a case through
+// the manager, so generation is recorded in the manager's metrics
+type benchmarkRunner func(ctx context.Context, manager *PCGManager, id string, size, difficulty int) error
+
+// benchmarkRunners are the content types the harness can benchmark
+var benchmarkRunners = map[ContentType]benchmarkRunner{
+	ContentTypeTerrain: func(ctx context.Context, manager *PCGManager, id string, size, difficulty int) error {
+		_, err := manager.GenerateTerrainForLevel(ctx, id, size, size, BiomeCave, difficulty)
+		return err
+	},
+	ContentTypeLevels: func(ctx context.Context, manager *PCGManager, id string, size, difficulty int) error {
+		_, err := manager.GenerateDungeonLevel(ctx, id, max(1, size/2), size, ThemeClassic, difficulty)
+		return err
+	},
+	ContentTypeItems: func(ctx context.Context, manager *PCGManager, id string, size, difficulty int) error {
+		_, err := manager.GenerateItemsForLocation(ctx, id, size, RarityCommon, RarityRare, difficulty)
+		return err
+	},
+	ContentTypeQuests: func(ctx context.Context, manager *PCGManager, id string, size, difficulty int) error {
+		for i := 0; i < size; i++ {
+			if _, err := manager.GenerateQuestForArea(ctx, fmt.Sprintf("%s_%d", id, i), QuestTypeFetch, difficulty); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// LoadBenchmarkBudgets reads and validates benchmark budgets from a YAML file
+func LoadBenchmarkBudgets(path string) (*BenchmarkBudgets, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read benchmark budgets %s: %w", path, err)
+	}
+
+	var budgets BenchmarkBudgets
+	if err := yaml.Unmarshal(data, &budgets); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML from %s: %w", path, err)
+	}
+	if err := budgets.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid benchmark budgets %s: %w", path, err)
+	}
+	return &budgets, nil
+}
+
+// Validate checks that the matrix only names content types the harness can
+// run and that sizes, difficulties and iterations are usable
+func (b *BenchmarkBudgets) Validate() error {
+	if b.Iterations < 1 {
+		return fmt.Errorf("iterations must be at least 1")
+	}
+	if len(b.Matrix) == 0 {
+		return fmt.Errorf("matrix is empty")
+	}
+	for contentType, dimensions := range b.Matrix {
+		if _, exists := benchmarkRunners[contentType]; !exists {
+			return fmt.Errorf("content type %s cannot be benchmarked", contentType)
+		}
+		if len(dimensions.Sizes) == 0 || len(dimensions.Difficulties) == 0 {
+			return fmt.Errorf("%s needs at least one size and difficulty", contentType)
+		}
+		for _, size := range dimensions.Sizes {
+			if size < 1 {
+				return fmt.Errorf("%s size %d must be positive", contentType, size)
+			}
+		}
+		for _, difficulty := range dimensions.Difficulties {
+			if difficulty < 1 || difficulty > 20 {
+				return fmt.Errorf("%s difficulty %d must be between 1 and 20", contentType, difficulty)
+			}
+		}
+	}
+	for i, budget := range b.Budgets {
+		if _, exists := b.Matrix[budget.ContentType]; !exists {
+			return fmt.Errorf("budget %d names content type %q outside the matrix", i, budget.ContentType)
+		}
+		if budget.P50 < 0 || budget.P95 < 0 {
+			return fmt.Errorf("budget %d has a negative duration", i)
+		}
+	}
+	return nil
+}
+
+// Cases expands the matrix into its cases, ordered by content type, size
+// and difficulty
+func (b *BenchmarkBudgets) Cases() []BenchmarkCase {
+	var cases []BenchmarkCase
+	for contentType, dimensions := range b.Matrix {
+		for _, size := range dimensions.Sizes {
+			for _, difficulty := range dimensions.Difficulties {
+				cases = append(cases, BenchmarkCase{ContentType: contentType, Size: size, Difficulty: difficulty})
+			}
+		}
+	}
+	sort.Slice(cases, func(i, j int) bool {
+		if cases[i].ContentType != cases[j].ContentType {
+			return cases[i].ContentType < cases[j].ContentType
+		}
+		if cases[i].Size != cases[j].Size {
+			return cases[i].Size < cases[j].Size
+		}
+		return cases[i].Difficulty < cases[j].Difficulty
+	})
+	return cases
+}
+
+// Check compares a result against every matching budget and returns the
+// violations. A case with failed generations always violates.
+func (b *BenchmarkBudgets) Check(result BenchmarkResult) []BenchmarkViolation {
+	name := result.Name()
+	var violations []BenchmarkViolation
+	if result.Errors > 0 {
+		violations = append(violations, BenchmarkViolation{Case: name, Metric: "errors", Actual: uint64(result.Errors)})
+	}
+
+	for _, budget := range b.Budgets {
+		if budget.ContentType != result.ContentType ||
+			(budget.Size != 0 && budget.Size != result.Size) ||
+			(budget.Difficulty != 0 && budget.Difficulty != result.Difficulty) {
+			continue
+		}
+
+		limits := []struct {
+			metric string
+			limit  uint64
+			actual uint64
+		}{
+			{"p50", uint64(budget.P50), uint64(result.P50)},
+			{"p95", uint64(budget.P95), uint64(result.P95)},
+			{"allocs_per_op", budget.AllocsPerOp, result.AllocsPerOp},
+			{"bytes_per_op", budget.BytesPerOp, result.BytesPerOp},
+		}
+		for _, l := range limits {
+			if l.limit > 0 && l.actual > l.limit {
+				violations = append(violations, BenchmarkViolation{Case: name, Metric: l.metric, Limit: l.limit, Actual: l.actual})
+			}
+		}
+	}
+	return violations
+}
+
+// RunBenchmark runs every case of the budget matrix through the manager,
+// checks the measurements against the budgets and records the outcome in
+// the performance component of the manager's quality metrics. Generators
+// for the benchmarked content types must be registered. Each iteration uses
+// its own content ID, so results depend only on the manager's seed.
+func (pcg *PCGManager) RunBenchmark(ctx context.Context, budgets *BenchmarkBudgets) (*BenchmarkReport, error) {
+	if err := budgets.Validate(); err != nil {
+		return nil, err
+	}
+
+	report := &BenchmarkReport{
+		Timestamp:  time.Now(),
+		Iterations: budgets.Iterations,
+		Results:    make([]BenchmarkResult, 0),
+		Violations: make([]BenchmarkViolation, 0),
+	}
+
+	for _, benchCase := range budgets.Cases() {
+		result, err := pcg.benchmarkCase(ctx, benchCase, budgets.Iterations)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, result)
+		report.Violations = append(report.Violations, budgets.Check(result)...)
+	}
+	report.Passed = len(report.Violations) == 0
+
+	pcg.qualityMetrics.RecordBenchmark(report)
+	pcg.logger.WithField("cases", len(report.Results)).
+		WithField("violations", len(report.Violations)).
+		Info("PCG benchmark completed")
+
+	return report, nil
+}
+
+// benchmarkCase measures iterations runs of a single case. Only context
+// cancellation aborts the case; generation errors are counted.
+func (pcg *PCGManager) benchmarkCase(ctx context.Context, benchCase BenchmarkCase, iterations int) (BenchmarkResult, error) {
+	runner := benchmarkRunners[benchCase.ContentType]
+	durations := make([]time.Duration, 0, iterations)
+	result := BenchmarkResult{BenchmarkCase: benchCase, Iterations: iterations}
+
+	var before, after runtime.MemStats
+	var mallocs, bytes uint64
+	for i := 0; i < iterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		id := fmt.Sprintf("bench_%s_%d_%d_%d", benchCase.ContentType, benchCase.Size, benchCase.Difficulty, i)
+
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		err := runner(ctx, pcg, id, benchCase.Size, benchCase.Difficulty)
+		durations = append(durations, time.Since(start))
+		runtime.ReadMemStats(&after)
+
+		if err != nil {
+			result.Errors++
+		}
+		mallocs += after.Mallocs - before.Mallocs
+		bytes += after.TotalAlloc - before.TotalAlloc
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	result.P50 = durationPercentile(durations, 50)
+	result.P95 = durationPercentile(durations, 95)
+	result.AllocsPerOp = mallocs / uint64(iterations)
+	result.BytesPerOp = bytes / uint64(iterations)
+	return result, nil
+}
+
+// durationPercentile returns the nearest-rank percentile of sorted durations
+func durationPercentile(sorted []time.Duration, percentile float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	return sorted[max(0, min(rank, len(sorted))-1)]
+}
@@ -0,0 +1,160 @@
+package pcg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// benchTerrainGenerator returns an empty map of the requested size
+type benchTerrainGenerator struct{}
+
+func (benchTerrainGenerator) Generate(ctx context.Context, params GenerationParams) (interface{}, error) {
+	width, _ := params.Constraints["width"].(int)
+	height, _ := params.Constraints["height"].(int)
+	return &game.GameMap{Width: width, Height: height, Tiles: make([][]game.MapTile, height)}, nil
+}
+
+func (benchTerrainGenerator) GetType() ContentType                   { return ContentTypeTerrain }
+func (benchTerrainGenerator) GetVersion() string                     { return "1.0.0" }
+func (benchTerrainGenerator) Validate(params GenerationParams) error { return nil }
+
+func TestLoadBenchmarkBudgets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budgets.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+iterations: 3
+matrix:
+  terrain:
+    sizes: [20]
+    difficulties: [1, 5]
+budgets:
+  - content_type: terrain
+    p95: 50ms
+    allocs_per_op: 1000
+`), 0o644))
+
+	budgets, err := LoadBenchmarkBudgets(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, budgets.Iterations)
+	require.Len(t, budgets.Budgets, 1)
+	assert.Equal(t, 50*time.Millisecond, budgets.Budgets[0].P95)
+	assert.Equal(t, []BenchmarkCase{
+		{ContentType: ContentTypeTerrain, Size: 20, Difficulty: 1},
+		{ContentType: ContentTypeTerrain, Size: 20, Difficulty: 5},
+	}, budgets.Cases())
+
+	require.NoError(t, os.WriteFile(path, []byte("iterations: 1\nmatrix:\n  weather:\n    sizes: [1]\n    difficulties: [1]\n"), 0o644))
+	_, err = LoadBenchmarkBudgets(path)
+	assert.ErrorContains(t, err, "cannot be benchmarked")
+}
+
+func TestBenchmarkBudgets_Validate(t *testing.T) {
+	dimensions := map[ContentType]BenchmarkDimensions{ContentTypeItems: {Sizes: []int{1}, Difficulties: []int{1}}}
+	tests := []struct {
+		name    string
+		budgets BenchmarkBudgets
+	}{
+		{"no iterations", BenchmarkBudgets{Matrix: dimensions}},
+		{"empty matrix", BenchmarkBudgets{Iterations: 1}},
+		{"zero size", BenchmarkBudgets{Iterations: 1, Matrix: map[ContentType]BenchmarkDimensions{
+			ContentTypeItems: {Sizes: []int{0}, Difficulties: []int{1}},
+		}}},
+		{"difficulty out of range", BenchmarkBudgets{Iterations: 1, Matrix: map[ContentType]BenchmarkDimensions{
+			ContentTypeItems: {Sizes: []int{1}, Difficulties: []int{21}},
+		}}},
+		{"budget outside matrix", BenchmarkBudgets{Iterations: 1, Matrix: dimensions, Budgets: []PerformanceBudget{
+			{ContentType: ContentTypeTerrain, P50: time.Millisecond},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.budgets.Validate())
+		})
+	}
+}
+
+func TestBenchmarkBudgets_Check(t *testing.T) {
+	budgets := BenchmarkBudgets{Budgets: []PerformanceBudget{
+		{ContentType: ContentTypeTerrain, P95: 10 * time.Millisecond},
+		{ContentType: ContentTypeTerrain, Size: 80, AllocsPerOp: 100},
+		{ContentType: ContentTypeTerrain, Difficulty: 20, P50: time.Millisecond},
+		{ContentType: ContentTypeItems, P50: time.Nanosecond},
+	}}
+	result := BenchmarkResult{
+		BenchmarkCase: BenchmarkCase{ContentType: ContentTypeTerrain, Size: 80, Difficulty: 5},
+		P50:           5 * time.Millisecond,
+		P95:           20 * time.Millisecond,
+		AllocsPerOp:   50,
+	}
+
+	violations := budgets.Check(result)
+	require.Len(t, violations, 1, "only the matching budgets apply")
+	assert.Equal(t, BenchmarkViolation{
+		Case:   "terrain/size=80/difficulty=5",
+		Metric: "p95",
+		Limit:  uint64(10 * time.Millisecond),
+		Actual: uint64(20 * time.Millisecond),
+	}, violations[0])
+
+	result.Errors = 2
+	result.P95 = time.Millisecond
+	violations = budgets.Check(result)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "errors", violations[0].Metric)
+}
+
+func TestDurationPercentile(t *testing.T) {
+	assert.Zero(t, durationPercentile(nil, 50))
+
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, time.Duration(5), durationPercentile(sorted, 50))
+	assert.Equal(t, time.Duration(10), durationPercentile(sorted, 95))
+	assert.Equal(t, time.Duration(1), durationPercentile(sorted[:1], 95))
+}
+
+func TestPCGManager_RunBenchmark(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	manager.InitializeWithSeed(1)
+	require.NoError(t, manager.GetRegistry().RegisterGenerator("cellular_automata", benchTerrainGenerator{}))
+
+	budgets := &BenchmarkBudgets{
+		Iterations: 4,
+		Matrix: map[ContentType]BenchmarkDimensions{
+			ContentTypeTerrain: {Sizes: []int{10, 20}, Difficulties: []int{3}},
+			ContentTypeItems:   {Sizes: []int{2}, Difficulties: []int{3}},
+		},
+		Budgets: []PerformanceBudget{{ContentType: ContentTypeTerrain, P95: time.Minute}},
+	}
+
+	report, err := manager.RunBenchmark(context.Background(), budgets)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, ContentTypeItems, report.Results[0].ContentType)
+	for _, result := range report.Results {
+		assert.Equal(t, 4, result.Iterations)
+		assert.LessOrEqual(t, result.P50, result.P95)
+	}
+	assert.Equal(t, int64(8), manager.GetMetrics().GetGenerationCount(ContentTypeTerrain))
+
+	// No item generator is registered, so the item case fails
+	assert.False(t, report.Passed)
+	assert.Equal(t, 1, report.FailedCases())
+	assert.Equal(t, 4, report.Results[0].Errors)
+
+	quality := manager.GenerateQualityReport()
+	assert.False(t, quality.ThresholdStatus["performance_budgets"])
+	assert.Less(t, quality.ComponentScores["performance"], 1.0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = manager.RunBenchmark(ctx, budgets)
+	assert.ErrorIs(t, err, context.Canceled)
+}
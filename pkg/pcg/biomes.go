@@ -0,0 +1,336 @@
+package pcg
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"goldbox-rpg/pkg/game"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BiomeDefinition defines the characteristics of a biome
+type BiomeDefinition struct {
+	Type              BiomeType          `yaml:"type,omitempty" json:"type"`
+	DefaultDensity    float64            `yaml:"default_density" json:"default_density"`
+	WaterLevelRange   [2]float64         `yaml:"water_level_range" json:"water_level_range"`
+	RoughnessRange    [2]float64         `yaml:"roughness_range" json:"roughness_range"`
+	ConnectivityLevel ConnectivityLevel  `yaml:"connectivity_level" json:"connectivity_level"`
+	Features          []TerrainFeature   `yaml:"features" json:"features"`
+	TileDistribution  map[string]float64 `yaml:"tile_distribution" json:"tile_distribution"`
+	Light             game.LightLevel    `yaml:"light,omitempty" json:"light,omitempty"` // How brightly generated tiles are lit
+}
+
+// clone returns a deep copy of the definition
+func (bd *BiomeDefinition) clone() *BiomeDefinition {
+	result := *bd
+	result.Features = append([]TerrainFeature(nil), bd.Features...)
+	result.TileDistribution = make(map[string]float64, len(bd.TileDistribution))
+	for tile, share := range bd.TileDistribution {
+		result.TileDistribution[tile] = share
+	}
+	return &result
+}
+
+// BiomeCollection represents the root structure of a biome YAML file.
+// Biomes are keyed by type, so a file can redefine a built-in biome or add
+// a custom one.
+type BiomeCollection struct {
+	Biomes map[BiomeType]*BiomeDefinition `yaml:"biomes"`
+}
+
+// builtinBiomes holds the biome definitions used when no biome file
+// overrides them
+var builtinBiomes = map[BiomeType]*BiomeDefinition{
+	BiomeCave: {
+		Type:              BiomeCave,
+		DefaultDensity:    0.45,
+		WaterLevelRange:   [2]float64{0.0, 0.1},
+		RoughnessRange:    [2]float64{0.6, 0.8},
+		ConnectivityLevel: ConnectivityModerate,
+		Features:          []TerrainFeature{FeatureStalactites, FeatureUndergroundRiver},
+		TileDistribution: map[string]float64{
+			"wall":  0.45,
+			"floor": 0.50,
+			"water": 0.03,
+			"deep":  0.02,
+		},
+		Light: game.LightDark,
+	},
+	BiomeDungeon: {
+		Type:              BiomeDungeon,
+		DefaultDensity:    0.40,
+		WaterLevelRange:   [2]float64{0.0, 0.05},
+		RoughnessRange:    [2]float64{0.3, 0.5},
+		ConnectivityLevel: ConnectivityHigh,
+		Features:          []TerrainFeature{FeatureSecretDoors, FeatureTraps},
+		TileDistribution: map[string]float64{
+			"wall":   0.40,
+			"floor":  0.55,
+			"door":   0.03,
+			"secret": 0.02,
+		},
+		Light: game.LightDim,
+	},
+	BiomeForest: {
+		Type:              BiomeForest,
+		DefaultDensity:    0.35,
+		WaterLevelRange:   [2]float64{0.05, 0.15},
+		RoughnessRange:    [2]float64{0.4, 0.7},
+		ConnectivityLevel: ConnectivityModerate,
+		Features:          []TerrainFeature{FeatureTrees, FeatureStreams},
+		TileDistribution: map[string]float64{
+			"trees": 0.35,
+			"grass": 0.50,
+			"water": 0.10,
+			"rocks": 0.05,
+		},
+	},
+	BiomeMountain: {
+		Type:              BiomeMountain,
+		DefaultDensity:    0.60,
+		WaterLevelRange:   [2]float64{0.0, 0.05},
+		RoughnessRange:    [2]float64{0.7, 0.9},
+		ConnectivityLevel: ConnectivityLow,
+		Features:          []TerrainFeature{FeatureCliffs, FeatureCrevasses},
+		TileDistribution: map[string]float64{
+			"rock": 0.60,
+			"path": 0.25,
+			"snow": 0.10,
+			"ice":  0.05,
+		},
+	},
+	BiomeSwamp: {
+		Type:              BiomeSwamp,
+		DefaultDensity:    0.30,
+		WaterLevelRange:   [2]float64{0.25, 0.40},
+		RoughnessRange:    [2]float64{0.2, 0.4},
+		ConnectivityLevel: ConnectivityLow,
+		Features:          []TerrainFeature{FeatureBogs, FeatureVines},
+		TileDistribution: map[string]float64{
+			"mud":   0.30,
+			"water": 0.35,
+			"reeds": 0.25,
+			"solid": 0.10,
+		},
+	},
+	BiomeDesert: {
+		Type:              BiomeDesert,
+		DefaultDensity:    0.15,
+		WaterLevelRange:   [2]float64{0.0, 0.02},
+		RoughnessRange:    [2]float64{0.1, 0.3},
+		ConnectivityLevel: ConnectivityHigh,
+		Features:          []TerrainFeature{FeatureDunes, FeatureOasis},
+		TileDistribution: map[string]float64{
+			"sand":   0.85,
+			"rock":   0.10,
+			"water":  0.02,
+			"cactus": 0.03,
+		},
+	},
+}
+
+// validConnectivityLevels lists the connectivity levels a biome may use
+var validConnectivityLevels = map[ConnectivityLevel]bool{
+	ConnectivityNone:     true,
+	ConnectivityLow:      true,
+	ConnectivityMinimal:  true,
+	ConnectivityModerate: true,
+	ConnectivityHigh:     true,
+	ConnectivityComplete: true,
+}
+
+// tileDistributionTolerance is how far the shares of a tile distribution
+// may sum away from 1
+const tileDistributionTolerance = 0.01
+
+// BiomeRegistry holds the biome definitions used by terrain generation.
+// It starts with the built-in biomes; YAML files can redefine them or add
+// custom biomes, and can be reloaded while the server runs. A reload that
+// fails validation leaves the previous definitions in place.
+//
+// BiomeRegistry is safe for concurrent use.
+type BiomeRegistry struct {
+	mu     sync.RWMutex
+	biomes map[BiomeType]*BiomeDefinition
+	paths  []string
+}
+
+// defaultBiomes is the registry terrain generators read from
+var defaultBiomes = NewBiomeRegistry()
+
+// Biomes returns the process-wide biome registry used by terrain generation.
+func Biomes() *BiomeRegistry {
+	return defaultBiomes
+}
+
+// NewBiomeRegistry creates a registry holding the built-in biomes.
+func NewBiomeRegistry() *BiomeRegistry {
+	return &BiomeRegistry{biomes: builtinBiomeCopies()}
+}
+
+// builtinBiomeCopies returns copies of the built-in biomes that can be
+// stored in a registry
+func builtinBiomeCopies() map[BiomeType]*BiomeDefinition {
+	biomes := make(map[BiomeType]*BiomeDefinition, len(builtinBiomes))
+	for biomeType, def := range builtinBiomes {
+		biomes[biomeType] = def.clone()
+	}
+	return biomes
+}
+
+// LoadFromFile loads biomes from a YAML file and merges them with the
+// biomes already registered. The file is remembered so Reload can re-read it;
+// loading the same file again does not add it twice.
+func (br *BiomeRegistry) LoadFromFile(path string) error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	biomes := make(map[BiomeType]*BiomeDefinition, len(br.biomes))
+	for biomeType, def := range br.biomes {
+		biomes[biomeType] = def
+	}
+
+	if err := readBiomes(path, biomes); err != nil {
+		return err
+	}
+	if err := validateBiomes(biomes); err != nil {
+		return fmt.Errorf("invalid biomes in %s: %w", path, err)
+	}
+
+	br.biomes = biomes
+	if !slices.Contains(br.paths, path) {
+		br.paths = append(br.paths, path)
+	}
+	return nil
+}
+
+// Reload rebuilds the registry from the built-in biomes and every file
+// previously passed to LoadFromFile. The new definitions replace the old
+// ones only if all files parse and validate.
+func (br *BiomeRegistry) Reload() error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	biomes, err := br.rebuild()
+	if err != nil {
+		return err
+	}
+
+	br.biomes = biomes
+	return nil
+}
+
+// rebuild reads the built-in biomes and every loaded file into a new set of
+// definitions without installing it. The caller must hold br.mu.
+func (br *BiomeRegistry) rebuild() (map[BiomeType]*BiomeDefinition, error) {
+	biomes := builtinBiomeCopies()
+	for _, path := range br.paths {
+		if err := readBiomes(path, biomes); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateBiomes(biomes); err != nil {
+		return nil, fmt.Errorf("invalid biomes: %w", err)
+	}
+	return biomes, nil
+}
+
+// Get returns a copy of the definition of a biome
+func (br *BiomeRegistry) Get(biome BiomeType) (*BiomeDefinition, bool) {
+	br.mu.RLock()
+	defer br.mu.RUnlock()
+
+	def, exists := br.biomes[biome]
+	if !exists {
+		return nil, false
+	}
+	return def.clone(), true
+}
+
+// Types returns the types of all registered biomes in sorted order
+func (br *BiomeRegistry) Types() []BiomeType {
+	br.mu.RLock()
+	defer br.mu.RUnlock()
+
+	types := make([]BiomeType, 0, len(br.biomes))
+	for biomeType := range br.biomes {
+		types = append(types, biomeType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// readBiomes parses a biome file into biomes, overwriting any biomes of the
+// same type
+func readBiomes(path string, biomes map[BiomeType]*BiomeDefinition) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read biome file %s: %w", path, err)
+	}
+
+	var collection BiomeCollection
+	if err := yaml.Unmarshal(data, &collection); err != nil {
+		return fmt.Errorf("failed to parse YAML from %s: %w", path, err)
+	}
+
+	for biomeType, def := range collection.Biomes {
+		if def != nil {
+			def.Type = biomeType
+			biomes[biomeType] = def
+		}
+	}
+	return nil
+}
+
+// validateBiomes checks that every biome has usable generation parameters
+// and a tile distribution that sums to 1
+func validateBiomes(biomes map[BiomeType]*BiomeDefinition) error {
+	for biomeType, def := range biomes {
+		if biomeType == "" {
+			return fmt.Errorf("biome with empty type")
+		}
+		if def.DefaultDensity < 0 || def.DefaultDensity > 1 {
+			return fmt.Errorf("biome %s default_density must be between 0 and 1", biomeType)
+		}
+		if err := validateUnitRange(def.WaterLevelRange); err != nil {
+			return fmt.Errorf("biome %s water_level_range: %w", biomeType, err)
+		}
+		if err := validateUnitRange(def.RoughnessRange); err != nil {
+			return fmt.Errorf("biome %s roughness_range: %w", biomeType, err)
+		}
+		if !validConnectivityLevels[def.ConnectivityLevel] {
+			return fmt.Errorf("biome %s has unknown connectivity_level %q", biomeType, def.ConnectivityLevel)
+		}
+
+		if len(def.TileDistribution) == 0 {
+			return fmt.Errorf("biome %s has no tile_distribution", biomeType)
+		}
+		total := 0.0
+		for tile, share := range def.TileDistribution {
+			if share < 0 {
+				return fmt.Errorf("biome %s has negative share for tile %s", biomeType, tile)
+			}
+			total += share
+		}
+		if math.Abs(total-1) > tileDistributionTolerance {
+			return fmt.Errorf("biome %s tile_distribution sums to %.2f, expected 1", biomeType, total)
+		}
+	}
+	return nil
+}
+
+// validateUnitRange checks a min/max pair within [0, 1]
+func validateUnitRange(r [2]float64) error {
+	if r[0] < 0 || r[1] > 1 {
+		return fmt.Errorf("values must be between 0 and 1")
+	}
+	if r[0] > r[1] {
+		return fmt.Errorf("min %.2f is greater than max %.2f", r[0], r[1])
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package pcg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDefinitionFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+const customBiomeYAML = `
+biomes:
+  glacier:
+    default_density: 0.5
+    water_level_range: [0.0, 0.1]
+    roughness_range: [0.5, 0.7]
+    connectivity_level: low
+    features: [crevasses]
+    tile_distribution:
+      ice: 0.7
+      snow: 0.3
+  desert:
+    default_density: 0.2
+    water_level_range: [0.0, 0.02]
+    roughness_range: [0.1, 0.3]
+    connectivity_level: high
+    features: [dunes]
+    tile_distribution:
+      sand: 1.0
+`
+
+func TestBiomeRegistry_LoadCustomBiomes(t *testing.T) {
+	registry := NewBiomeRegistry()
+	require.NoError(t, registry.LoadFromFile(writeDefinitionFile(t, "biomes.yaml", customBiomeYAML)))
+
+	glacier, exists := registry.Get("glacier")
+	require.True(t, exists)
+	assert.Equal(t, BiomeType("glacier"), glacier.Type, "type is taken from the key")
+	assert.Equal(t, 0.7, glacier.TileDistribution["ice"])
+
+	desert, exists := registry.Get(BiomeDesert)
+	require.True(t, exists)
+	assert.Equal(t, 0.2, desert.DefaultDensity, "file redefines the built-in biome")
+
+	_, exists = registry.Get(BiomeCave)
+	assert.True(t, exists, "built-in biomes not in the file remain")
+	assert.Contains(t, registry.Types(), BiomeType("glacier"))
+}
+
+func TestBiomeRegistry_GetReturnsCopy(t *testing.T) {
+	registry := NewBiomeRegistry()
+
+	def, _ := registry.Get(BiomeCave)
+	def.TileDistribution["wall"] = 1
+	def.Features[0] = FeatureOasis
+
+	again, _ := registry.Get(BiomeCave)
+	assert.Equal(t, 0.45, again.TileDistribution["wall"])
+	assert.Equal(t, FeatureStalactites, again.Features[0])
+	assert.Equal(t, 0.45, builtinBiomes[BiomeCave].TileDistribution["wall"])
+}
+
+func TestBiomeRegistry_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "distribution does not sum to one",
+			content: `
+biomes:
+  bad:
+    default_density: 0.5
+    connectivity_level: low
+    tile_distribution: {rock: 0.5}
+`,
+		},
+		{
+			name: "density out of range",
+			content: `
+biomes:
+  bad:
+    default_density: 1.5
+    connectivity_level: low
+    tile_distribution: {rock: 1.0}
+`,
+		},
+		{
+			name: "inverted range",
+			content: `
+biomes:
+  bad:
+    default_density: 0.5
+    water_level_range: [0.4, 0.1]
+    connectivity_level: low
+    tile_distribution: {rock: 1.0}
+`,
+		},
+		{
+			name: "unknown connectivity",
+			content: `
+biomes:
+  bad:
+    default_density: 0.5
+    connectivity_level: sometimes
+    tile_distribution: {rock: 1.0}
+`,
+		},
+		{
+			name:    "malformed yaml",
+			content: "biomes: [",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewBiomeRegistry()
+			err := registry.LoadFromFile(writeDefinitionFile(t, "biomes.yaml", tt.content))
+			assert.Error(t, err)
+
+			_, exists := registry.Get("bad")
+			assert.False(t, exists, "rejected files leave the registry unchanged")
+		})
+	}
+}
+
+func TestBiomeRegistry_Reload(t *testing.T) {
+	registry := NewBiomeRegistry()
+	path := writeDefinitionFile(t, "biomes.yaml", customBiomeYAML)
+	require.NoError(t, registry.LoadFromFile(path))
+	require.NoError(t, registry.LoadFromFile(path))
+	assert.Len(t, registry.paths, 1, "loading a file twice remembers it once")
+
+	// Dropping a definition from the file restores the built-in one
+	require.NoError(t, os.WriteFile(path, []byte(`
+biomes:
+  glacier:
+    default_density: 0.6
+    connectivity_level: low
+    tile_distribution: {ice: 1.0}
+`), 0o644))
+	require.NoError(t, registry.Reload())
+
+	glacier, _ := registry.Get("glacier")
+	assert.Equal(t, 0.6, glacier.DefaultDensity)
+	desert, _ := registry.Get(BiomeDesert)
+	assert.Equal(t, 0.15, desert.DefaultDensity)
+
+	// An invalid edit is rejected and the previous definitions stay active
+	require.NoError(t, os.WriteFile(path, []byte(`
+biomes:
+  glacier:
+    default_density: 0.6
+    connectivity_level: low
+    tile_distribution: {ice: 0.2}
+`), 0o644))
+	assert.Error(t, registry.Reload())
+
+	glacier, exists := registry.Get("glacier")
+	require.True(t, exists)
+	assert.Equal(t, 1.0, glacier.TileDistribution["ice"])
+}
@@ -78,7 +78,15 @@ type Bootstrap struct {
 	pcgManager     *PCGManager
 	logger         *logrus.Logger
 	world          *game.World
-	generatedFiles map[string]string // Tracks generated configuration files
+	generatedFiles map[string]string  // Tracks generated configuration files
+	progress       ProgressFunc       // Optional progress callback
+	consistency    *ConsistencyReport // Cross-content checks of the last run
+	concurrency    int                // Content stages run at once (0 = one per CPU)
+	stageTimeout   time.Duration      // Limit per content stage (0 = DefaultBootstrapStageTimeout)
+
+	worldSeed     int64             // Seed the last run resolved WorldSeed to
+	contentHashes map[string]string // SHA-256 of each content file written, by path relative to DataDirectory
+	profile       *BootstrapProfile // Profile being replayed, if any
 }
 
 // NewBootstrap creates a new bootstrap system with the specified configuration
@@ -98,6 +106,19 @@ func NewBootstrap(config *BootstrapConfig, world *game.World, logger *logrus.Log
 	}
 }
 
+// SetProgressFunc registers a callback that receives stage/percentage
+// updates while GenerateCompleteGame runs. Content stages run in parallel,
+// but the callback is never called concurrently and percentages only rise.
+// Pass nil to disable reporting.
+func (b *Bootstrap) SetProgressFunc(fn ProgressFunc) {
+	b.progress = fn
+}
+
+// reportProgress forwards a bootstrap progress update to the registered callback.
+func (b *Bootstrap) reportProgress(stage string, percent float64) {
+	GenerationParams{Progress: b.progress}.ReportProgress(ContentTypeWorld, stage, percent)
+}
+
 // LoadBootstrapTemplate loads a named template from the bootstrap_templates.yaml file
 // If the template file doesn't exist or the template name isn't found, returns the default config
 func LoadBootstrapTemplate(templateName, dataDir string) (*BootstrapConfig, error) {
@@ -231,14 +252,20 @@ func (b *Bootstrap) GenerateCompleteGame(ctx context.Context) (*game.World, erro
 		"world_seed": worldSeed,
 	}).Debug("initializing PCG manager with seed")
 	b.pcgManager.InitializeWithSeed(worldSeed)
+	if b.profile != nil {
+		b.pcgManager.GetSeedManager().LoadState(b.profile.Seeds)
+	}
+	b.worldSeed = worldSeed
+	b.contentHashes = make(map[string]string)
+	b.reportProgress("seed", 5)
 
-	// Generate core game components with simple placeholder data
-	// In a full implementation, these would use the PCG generators
+	// Generate the game content through the dependency-aware pipeline
 	logrus.WithFields(logrus.Fields{
-		"function": "GenerateCompleteGame",
-		"package":  "pcg",
-	}).Debug("generating simple game content")
-	if err := b.generateSimpleGameContent(); err != nil {
+		"function":    "GenerateCompleteGame",
+		"package":     "pcg",
+		"concurrency": b.pipelineConcurrency(),
+	}).Debug("running content pipeline")
+	if err := b.runContentPipeline(ctx, b.contentStages()); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"function": "GenerateCompleteGame",
 			"package":  "pcg",
@@ -261,6 +288,7 @@ func (b *Bootstrap) GenerateCompleteGame(ctx context.Context) (*game.World, erro
 			}).Error("failed to generate starting scenario")
 			return nil, fmt.Errorf("failed to generate starting scenario: %w", err)
 		}
+		b.reportProgress("starting_scenario", 85)
 	}
 
 	// Save generated configuration files
@@ -277,6 +305,26 @@ func (b *Bootstrap) GenerateCompleteGame(ctx context.Context) (*game.World, erro
 		return nil, fmt.Errorf("failed to save generated configuration: %w", err)
 	}
 
+	if err := b.verifyProfile(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "GenerateCompleteGame",
+			"package":  "pcg",
+			"error":    err,
+		}).Error("generated content does not match the imported profile")
+		return nil, err
+	}
+
+	if err := b.validateConsistency(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "GenerateCompleteGame",
+			"package":  "pcg",
+			"error":    err,
+		}).Error("generated content is inconsistent")
+		return nil, fmt.Errorf("generated content is inconsistent: %w", err)
+	}
+	b.reportProgress("consistency", 95)
+	b.reportProgress(ProgressStageComplete, 100)
+
 	duration := time.Since(startTime)
 	b.logger.WithFields(logrus.Fields{
 		"duration":        duration,
@@ -295,43 +343,6 @@ func (b *Bootstrap) GenerateCompleteGame(ctx context.Context) (*game.World, erro
 	return b.world, nil
 }
 
-// generateSimpleGameContent creates basic game content for immediate play
-func (b *Bootstrap) generateSimpleGameContent() error {
-	b.logger.Debug("Generating simple game content for immediate play")
-
-	// Generate basic world structure
-	worldData := b.createBasicWorld()
-	b.storeGeneratedContent("world", worldData)
-
-	// Generate basic faction system
-	factionData := b.createBasicFactions()
-	b.storeGeneratedContent("factions", factionData)
-
-	// Generate basic NPCs
-	characterData := b.createBasicCharacters()
-	b.storeGeneratedContent("characters", characterData)
-
-	// Generate basic quests
-	questData := b.createBasicQuests()
-	b.storeGeneratedContent("quests", questData)
-
-	// Generate basic dialogue
-	dialogueData := b.createBasicDialogue()
-	b.storeGeneratedContent("dialogue", dialogueData)
-
-	// Note: Spells and items are generated and written to YAML files
-	// in the main Run() method for immediate server compatibility
-	// but we still track them for testing
-	spellData := b.generateBasicSpells()
-	b.storeGeneratedContent("spells", spellData)
-
-	itemData := b.generateBasicItems()
-	b.storeGeneratedContent("items", itemData)
-
-	b.logger.Debug("Simple game content generation completed")
-	return nil
-}
-
 // createBasicWorld generates a simple world structure
 func (b *Bootstrap) createBasicWorld() interface{} {
 	regionCount := b.getRegionCountForLength()
@@ -362,6 +373,54 @@ func (b *Bootstrap) createBasicFactions() interface{} {
 	return factions
 }
 
+// generatePantheon generates the deities of the genre variant and gives
+// them to the world, for clerics and paladins to serve
+func (b *Bootstrap) generatePantheon(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+	params := GenerationParams{
+		Seed:        b.pcgManager.GetSeedManager().VersionedContextSeed(ContentTypeDeities, "pantheon"),
+		Difficulty:  1,
+		Constraints: map[string]interface{}{"genre": string(b.config.GenreVariant)},
+	}
+	content, err := NewDeityGenerator(b.logger).Generate(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate pantheon: %w", err)
+	}
+
+	pantheon := content.(*game.Pantheon)
+	if b.world != nil {
+		b.world.Pantheon = pantheon
+	}
+	return pantheon, nil
+}
+
+// createBasicTerrain generates simple terrain for each region of world
+func (b *Bootstrap) createBasicTerrain(world interface{}) interface{} {
+	regionCount := b.getRegionCountForLength()
+	if data, ok := world.(map[string]interface{}); ok {
+		if regions, ok := data["regions"].(int); ok {
+			regionCount = regions
+		}
+	}
+
+	biomes := []string{"plains", "forest", "hills", "mountains", "swamp"}
+	if b.config.GenreVariant == GenreGrimdark {
+		biomes = []string{"wasteland", "swamp", "dead_forest", "badlands", "mountains"}
+	}
+	regionBiomes := make([]string, regionCount)
+	for i := range regionBiomes {
+		regionBiomes[i] = biomes[i%len(biomes)]
+	}
+
+	terrain := map[string]interface{}{
+		"regions":     regionCount,
+		"biomes":      regionBiomes,
+		"road_count":  regionCount * 2,
+		"river_count": (regionCount + 1) / 2,
+	}
+
+	return terrain
+}
+
 // createBasicCharacters generates simple NPC data
 func (b *Bootstrap) createBasicCharacters() interface{} {
 	npcCount := b.getNPCCountForComplexity()
@@ -561,7 +620,7 @@ func (b *Bootstrap) saveSpellFiles() error {
 		return fmt.Errorf("failed to marshal cantrips: %w", err)
 	}
 
-	if err := os.WriteFile(filepath.Join(spellsDir, "cantrips.yaml"), cantripData, 0o644); err != nil {
+	if err := b.writeContentFile(filepath.Join(spellsDir, "cantrips.yaml"), cantripData); err != nil {
 		return fmt.Errorf("failed to write cantrips.yaml: %w", err)
 	}
 
@@ -609,7 +668,7 @@ func (b *Bootstrap) saveSpellFiles() error {
 		return fmt.Errorf("failed to marshal level1 spells: %w", err)
 	}
 
-	if err := os.WriteFile(filepath.Join(spellsDir, "level1.yaml"), level1Data, 0o644); err != nil {
+	if err := b.writeContentFile(filepath.Join(spellsDir, "level1.yaml"), level1Data); err != nil {
 		return fmt.Errorf("failed to write level1.yaml: %w", err)
 	}
 
@@ -658,7 +717,7 @@ func (b *Bootstrap) saveSpellFiles() error {
 		return fmt.Errorf("failed to marshal level2 spells: %w", err)
 	}
 
-	if err := os.WriteFile(filepath.Join(spellsDir, "level2.yaml"), level2Data, 0o644); err != nil {
+	if err := b.writeContentFile(filepath.Join(spellsDir, "level2.yaml"), level2Data); err != nil {
 		return fmt.Errorf("failed to write level2.yaml: %w", err)
 	}
 
@@ -669,7 +728,23 @@ func (b *Bootstrap) saveSpellFiles() error {
 func (b *Bootstrap) saveItemFiles() error {
 	itemsDir := filepath.Join(b.config.DataDirectory, "items")
 
-	items := []map[string]interface{}{
+	items := bootstrapItems()
+
+	itemData, err := yaml.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("failed to marshal items: %w", err)
+	}
+
+	if err := b.writeContentFile(filepath.Join(itemsDir, "items.yaml"), itemData); err != nil {
+		return fmt.Errorf("failed to write items.yaml: %w", err)
+	}
+
+	return nil
+}
+
+// bootstrapItems returns the starter items saveItemFiles writes
+func bootstrapItems() []map[string]interface{} {
+	return []map[string]interface{}{
 		{
 			"item_id":     "sword",
 			"name":        "Sword",
@@ -774,19 +849,30 @@ func (b *Bootstrap) saveItemFiles() error {
 			"description": "Dried food suitable for long journeys.",
 		},
 	}
+}
 
-	itemData, err := yaml.Marshal(items)
-	if err != nil {
-		return fmt.Errorf("failed to marshal items: %w", err)
+// validateConsistency checks the references between the generated content
+// and fails bootstrap when any of them do not resolve.
+func (b *Bootstrap) validateConsistency() error {
+	var itemIDs []string
+	for _, item := range bootstrapItems() {
+		itemIDs = append(itemIDs, item["item_id"].(string))
 	}
 
-	if err := os.WriteFile(filepath.Join(itemsDir, "items.yaml"), itemData, 0o644); err != nil {
-		return fmt.Errorf("failed to write items.yaml: %w", err)
+	report := b.pcgManager.ValidateWorldConsistency(WorldContent{World: b.world, ItemIDs: itemIDs})
+	b.consistency = report
+	if errors := report.Errors(); len(errors) > 0 {
+		return fmt.Errorf("%d unresolved references, first: %s", len(errors), errors[0].Message)
 	}
-
 	return nil
 }
 
+// ConsistencyReport returns the world consistency report of the last
+// GenerateCompleteGame run, or nil before one completes its checks.
+func (b *Bootstrap) ConsistencyReport() *ConsistencyReport {
+	return b.consistency
+}
+
 func (b *Bootstrap) saveBootstrapConfig() error {
 	configPath := filepath.Join(b.config.DataDirectory, "pcg", "bootstrap_config.yaml")
 
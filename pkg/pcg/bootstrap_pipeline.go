@@ -0,0 +1,177 @@
+package pcg
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultBootstrapStageTimeout is how long a single content stage may run
+// unless SetStageTimeout sets another limit
+const DefaultBootstrapStageTimeout = 30 * time.Second
+
+// Progress percentages covered by the content pipeline; the seed is
+// reported before it and the starting scenario after it
+const (
+	pipelineStartPercent = 5
+	pipelineEndPercent   = 80
+)
+
+// contentStage is one step of the bootstrap content pipeline
+type contentStage struct {
+	name      string
+	dependsOn []string // Stages whose output this stage reads
+	generate  func(ctx context.Context, inputs map[string]interface{}) (interface{}, error)
+}
+
+// SetConcurrency limits how many content stages GenerateCompleteGame runs
+// at once. Zero or less uses one per available CPU.
+func (b *Bootstrap) SetConcurrency(n int) {
+	b.concurrency = n
+}
+
+// SetStageTimeout limits how long each content stage may run. Zero or less
+// uses DefaultBootstrapStageTimeout.
+func (b *Bootstrap) SetStageTimeout(timeout time.Duration) {
+	b.stageTimeout = timeout
+}
+
+// contentStages returns the bootstrap content pipeline in dependency order:
+// the world first, then factions and terrain, then the characters and
+// quests that reference them, and finally the dialogue of those characters
+// and quests. Spells and items depend on nothing and run alongside.
+func (b *Bootstrap) contentStages() []contentStage {
+	independent := func(create func() interface{}) func(context.Context, map[string]interface{}) (interface{}, error) {
+		return func(context.Context, map[string]interface{}) (interface{}, error) {
+			return create(), nil
+		}
+	}
+
+	return []contentStage{
+		{name: "world", generate: independent(b.createBasicWorld)},
+		{name: "spells", generate: independent(b.generateBasicSpells)},
+		{name: "items", generate: independent(b.generateBasicItems)},
+		{name: "factions", dependsOn: []string{"world"}, generate: independent(b.createBasicFactions)},
+		{name: "pantheon", dependsOn: []string{"world"}, generate: b.generatePantheon},
+		{
+			name:      "terrain",
+			dependsOn: []string{"world"},
+			generate: func(_ context.Context, inputs map[string]interface{}) (interface{}, error) {
+				return b.createBasicTerrain(inputs["world"]), nil
+			},
+		},
+		{name: "characters", dependsOn: []string{"factions", "terrain"}, generate: independent(b.createBasicCharacters)},
+		{name: "quests", dependsOn: []string{"factions", "terrain"}, generate: independent(b.createBasicQuests)},
+		{name: "dialogue", dependsOn: []string{"characters", "quests"}, generate: independent(b.createBasicDialogue)},
+	}
+}
+
+// runContentPipeline runs the content stages, each as soon as the stages
+// it depends on have finished, with at most the configured number running
+// at once. Each stage is stored and reported as it completes. The first
+// stage to fail or time out cancels the rest.
+//
+// Stages must be listed after the stages they depend on; this also
+// guarantees that a stage holding a concurrency slot while it waits for
+// its dependencies never blocks them from running.
+func (b *Bootstrap) runContentPipeline(ctx context.Context, stages []contentStage) error {
+	done := make(map[string]chan struct{}, len(stages))
+	for _, stage := range stages {
+		for _, dep := range stage.dependsOn {
+			if _, listed := done[dep]; !listed {
+				return fmt.Errorf("stage %s depends on %s, which does not run before it", stage.name, dep)
+			}
+		}
+		done[stage.name] = make(chan struct{})
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]interface{}, len(stages))
+	completed := 0
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(b.pipelineConcurrency())
+	for _, stage := range stages {
+		group.Go(func() error {
+			for _, dep := range stage.dependsOn {
+				select {
+				case <-done[dep]:
+				case <-groupCtx.Done():
+					return groupCtx.Err()
+				}
+			}
+
+			mu.Lock()
+			inputs := make(map[string]interface{}, len(stage.dependsOn))
+			for _, dep := range stage.dependsOn {
+				inputs[dep] = results[dep]
+			}
+			mu.Unlock()
+
+			data, err := b.runContentStage(groupCtx, stage, inputs)
+			if err != nil {
+				return fmt.Errorf("%s stage failed: %w", stage.name, err)
+			}
+
+			// Progress is reported under the lock so updates arrive one at
+			// a time with rising percentages
+			mu.Lock()
+			results[stage.name] = data
+			b.storeGeneratedContent(stage.name, data)
+			completed++
+			b.reportProgress(stage.name, pipelineStartPercent+float64(pipelineEndPercent-pipelineStartPercent)*float64(completed)/float64(len(stages)))
+			mu.Unlock()
+
+			close(done[stage.name])
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+// runContentStage runs one stage, giving up when the stage timeout passes
+// or ctx is cancelled. A stage that overruns is abandoned; its result is
+// discarded when it eventually returns.
+func (b *Bootstrap) runContentStage(ctx context.Context, stage contentStage, inputs map[string]interface{}) (interface{}, error) {
+	timeout := b.stageTimeout
+	if timeout <= 0 {
+		timeout = DefaultBootstrapStageTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type stageResult struct {
+		data interface{}
+		err  error
+	}
+	finished := make(chan stageResult, 1)
+	start := time.Now()
+	go func() {
+		data, err := stage.generate(ctx, inputs)
+		finished <- stageResult{data: data, err: err}
+	}()
+
+	select {
+	case result := <-finished:
+		b.logger.WithFields(logrus.Fields{
+			"stage":    stage.name,
+			"duration": time.Since(start),
+		}).Debug("Bootstrap content stage completed")
+		return result.data, result.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("stopped after %v: %w", time.Since(start).Round(time.Millisecond), ctx.Err())
+	}
+}
+
+// pipelineConcurrency returns how many stages may run at once
+func (b *Bootstrap) pipelineConcurrency() int {
+	if b.concurrency > 0 {
+		return b.concurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
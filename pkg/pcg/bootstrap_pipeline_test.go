@@ -0,0 +1,121 @@
+package pcg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPipelineBootstrap() *Bootstrap {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	return NewBootstrap(DefaultBootstrapConfig(), game.NewWorld(), logger)
+}
+
+func TestRunContentPipeline_DependencyOrder(t *testing.T) {
+	bootstrap := newTestPipelineBootstrap()
+	bootstrap.SetConcurrency(4)
+
+	var mu sync.Mutex
+	finished := make(map[string]bool)
+	var running, peak int32
+	stage := func(name string, deps ...string) contentStage {
+		return contentStage{
+			name:      name,
+			dependsOn: deps,
+			generate: func(_ context.Context, inputs map[string]interface{}) (interface{}, error) {
+				now := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					old := atomic.LoadInt32(&peak)
+					if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+
+				mu.Lock()
+				defer mu.Unlock()
+				for _, dep := range deps {
+					if !finished[dep] || inputs[dep] != dep {
+						return nil, errors.New(name + " ran before " + dep)
+					}
+				}
+				finished[name] = true
+				return name, nil
+			},
+		}
+	}
+
+	err := bootstrap.runContentPipeline(context.Background(), []contentStage{
+		stage("world"),
+		stage("factions", "world"),
+		stage("terrain", "world"),
+		stage("characters", "factions", "terrain"),
+		stage("quests", "factions", "terrain"),
+		stage("dialogue", "characters", "quests"),
+	})
+	require.NoError(t, err)
+	assert.Len(t, finished, 6)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak), "independent stages run side by side")
+}
+
+func TestRunContentPipeline_StageTimeout(t *testing.T) {
+	bootstrap := newTestPipelineBootstrap()
+	bootstrap.SetStageTimeout(10 * time.Millisecond)
+
+	ran := false
+	err := bootstrap.runContentPipeline(context.Background(), []contentStage{
+		{name: "world", generate: func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+			time.Sleep(time.Second)
+			return nil, nil
+		}},
+		{name: "factions", dependsOn: []string{"world"}, generate: func(context.Context, map[string]interface{}) (interface{}, error) {
+			ran = true
+			return nil, nil
+		}},
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.False(t, ran, "stages after a failed stage are cancelled")
+}
+
+func TestRunContentPipeline_UnorderedDependency(t *testing.T) {
+	err := newTestPipelineBootstrap().runContentPipeline(context.Background(), []contentStage{
+		{name: "dialogue", dependsOn: []string{"characters"}},
+		{name: "characters"},
+	})
+	assert.Error(t, err)
+}
+
+func TestGenerateCompleteGame_SingleStageAtATime(t *testing.T) {
+	config := DefaultBootstrapConfig()
+	config.DataDirectory = t.TempDir()
+	config.WorldSeed = 42
+	bootstrap := NewBootstrap(config, game.NewWorld(), logrus.New())
+	bootstrap.SetConcurrency(1)
+
+	var updates []ProgressUpdate
+	bootstrap.SetProgressFunc(recordProgress(&updates))
+	_, err := bootstrap.GenerateCompleteGame(context.Background())
+	require.NoError(t, err)
+
+	assertMonotonicProgress(t, updates)
+	stages := make(map[string]bool)
+	for _, update := range updates {
+		stages[update.Stage] = true
+	}
+	for _, stage := range bootstrap.contentStages() {
+		assert.True(t, stages[stage.name], "progress reported for %s", stage.name)
+		assert.Contains(t, bootstrap.generatedFiles, stage.name)
+	}
+}
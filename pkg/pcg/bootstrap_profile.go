@@ -0,0 +1,225 @@
+package pcg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// BootstrapProfileVersion is the format version of exported bootstrap
+// profiles. Profiles of other versions are rejected on import.
+const BootstrapProfileVersion = 1
+
+// ShareCodePrefix starts every world sharing code
+const ShareCodePrefix = "gbw1-"
+
+// maxShareCodeSize bounds the decompressed size of a sharing code
+const maxShareCodeSize = 1 << 20
+
+// BootstrapProfile records everything needed to regenerate a bootstrapped
+// world exactly: the resolved configuration, with the seed actually used,
+// and the full seed tree of the run. Importing a profile on another machine
+// reproduces the same content, which the fingerprint verifies.
+type BootstrapProfile struct {
+	Version int `yaml:"profile_version"`
+
+	// Config is the resolved configuration. Its DataDirectory is left empty,
+	// since where content is written is up to the importer.
+	Config BootstrapConfig `yaml:"config"`
+
+	// Seeds is the seed tree of the run: the base seed, every derived
+	// context seed and the generator version of each content type
+	Seeds SaveableState `yaml:"seeds"`
+
+	// Fingerprint is a SHA-256 over the generated content files
+	Fingerprint string `yaml:"fingerprint"`
+}
+
+// ExportProfile returns the profile of the last GenerateCompleteGame run.
+func (b *Bootstrap) ExportProfile() (*BootstrapProfile, error) {
+	if b.contentHashes == nil {
+		return nil, fmt.Errorf("no game has been generated to export")
+	}
+
+	config := *b.config
+	config.WorldSeed = b.worldSeed
+	config.DataDirectory = ""
+
+	return &BootstrapProfile{
+		Version:     BootstrapProfileVersion,
+		Config:      config,
+		Seeds:       b.pcgManager.GetSeedManager().GetSaveableState(),
+		Fingerprint: b.contentFingerprint(),
+	}, nil
+}
+
+// NewBootstrapFromProfile creates a bootstrap that replays profile into
+// dataDir. GenerateCompleteGame fails if the content it generates does not
+// match the profile's fingerprint, as when the generators have changed.
+func NewBootstrapFromProfile(profile *BootstrapProfile, dataDir string, world *game.World, logger *logrus.Logger) (*Bootstrap, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	config := profile.Config
+	config.DataDirectory = dataDir
+	bootstrap := NewBootstrap(&config, world, logger)
+	bootstrap.profile = profile
+	return bootstrap, nil
+}
+
+// Validate checks that the profile can be replayed.
+func (p *BootstrapProfile) Validate() error {
+	if p.Version != BootstrapProfileVersion {
+		return fmt.Errorf("unsupported bootstrap profile version %d, expected %d", p.Version, BootstrapProfileVersion)
+	}
+	if p.Config.WorldSeed == 0 {
+		return fmt.Errorf("bootstrap profile has no world seed")
+	}
+	if p.Seeds.BaseSeed != p.Config.WorldSeed {
+		return fmt.Errorf("bootstrap profile seed tree is rooted at %d, not the world seed %d", p.Seeds.BaseSeed, p.Config.WorldSeed)
+	}
+	return nil
+}
+
+// Save writes the profile to path as YAML.
+func (p *BootstrapProfile) Save(path string) error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bootstrap profile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write bootstrap profile: %w", err)
+	}
+	return nil
+}
+
+// LoadBootstrapProfile reads a profile written by Save.
+func LoadBootstrapProfile(path string) (*BootstrapProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bootstrap profile: %w", err)
+	}
+
+	var profile BootstrapProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap profile: %w", err)
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// ShareCode encodes the profile as a single line of text, a world sharing
+// code players can paste to generate the same world.
+func (p *BootstrapProfile) ShareCode() (string, error) {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal bootstrap profile: %w", err)
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return "", fmt.Errorf("failed to compress bootstrap profile: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress bootstrap profile: %w", err)
+	}
+	return ShareCodePrefix + base64.RawURLEncoding.EncodeToString(compressed.Bytes()), nil
+}
+
+// ParseShareCode decodes a sharing code made by ShareCode.
+func ParseShareCode(code string) (*BootstrapProfile, error) {
+	encoded, found := strings.CutPrefix(strings.TrimSpace(code), ShareCodePrefix)
+	if !found {
+		return nil, fmt.Errorf("not a world sharing code: missing %q prefix", ShareCodePrefix)
+	}
+
+	compressed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid world sharing code: %w", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid world sharing code: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxShareCodeSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid world sharing code: %w", err)
+	}
+	if len(data) > maxShareCodeSize {
+		return nil, fmt.Errorf("world sharing code exceeds %d bytes", maxShareCodeSize)
+	}
+
+	var profile BootstrapProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("invalid world sharing code: %w", err)
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// writeContentFile writes a generated content file and records its hash
+// for the profile fingerprint.
+func (b *Bootstrap) writeContentFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+
+	relative, err := filepath.Rel(b.config.DataDirectory, path)
+	if err != nil {
+		relative = path
+	}
+	sum := sha256.Sum256(data)
+	b.contentHashes[filepath.ToSlash(relative)] = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// contentFingerprint hashes the paths and hashes of the content files
+// written by the last run, independent of where they were written.
+func (b *Bootstrap) contentFingerprint() string {
+	paths := make([]string, 0, len(b.contentHashes))
+	for path := range b.contentHashes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	hasher := sha256.New()
+	for _, path := range paths {
+		fmt.Fprintf(hasher, "%s\x00%s\n", path, b.contentHashes[path])
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// verifyProfile checks the content generated from an imported profile
+// against the profile's fingerprint.
+func (b *Bootstrap) verifyProfile() error {
+	if b.profile == nil || b.profile.Fingerprint == "" {
+		return nil
+	}
+	if fingerprint := b.contentFingerprint(); fingerprint != b.profile.Fingerprint {
+		return fmt.Errorf("generated world does not match the bootstrap profile: fingerprint %s, expected %s",
+			fingerprint, b.profile.Fingerprint)
+	}
+	return nil
+}
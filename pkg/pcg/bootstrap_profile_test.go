@@ -0,0 +1,113 @@
+package pcg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportTestProfile bootstraps a world with a random seed and exports its
+// profile
+func exportTestProfile(t *testing.T) *BootstrapProfile {
+	t.Helper()
+	config := DefaultBootstrapConfig()
+	config.DataDirectory = t.TempDir()
+	config.GameLength = GameLengthShort
+
+	bootstrap := NewBootstrap(config, game.NewWorldWithSize(10, 10, 1), nil)
+	_, err := bootstrap.ExportProfile()
+	require.Error(t, err, "nothing to export before generation")
+
+	_, err = bootstrap.GenerateCompleteGame(context.Background())
+	require.NoError(t, err)
+	profile, err := bootstrap.ExportProfile()
+	require.NoError(t, err)
+	return profile
+}
+
+func TestBootstrapProfile_Export(t *testing.T) {
+	profile := exportTestProfile(t)
+
+	assert.Equal(t, BootstrapProfileVersion, profile.Version)
+	assert.NotZero(t, profile.Config.WorldSeed, "the random seed is resolved")
+	assert.Equal(t, profile.Config.WorldSeed, profile.Seeds.BaseSeed)
+	assert.Equal(t, CurrentSeedVersions, profile.Seeds.SeedVersions)
+	assert.Empty(t, profile.Config.DataDirectory)
+	assert.Len(t, profile.Fingerprint, 64)
+}
+
+func TestBootstrapProfile_Replay(t *testing.T) {
+	profile := exportTestProfile(t)
+	profile.Seeds.SeedVersions[ContentTypeTerrain] = LegacySeedVersion
+
+	path := filepath.Join(t.TempDir(), "profiles", "world.yaml")
+	require.NoError(t, profile.Save(path))
+	loaded, err := LoadBootstrapProfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, profile, loaded)
+
+	dataDir := t.TempDir()
+	bootstrap, err := NewBootstrapFromProfile(loaded, dataDir, game.NewWorldWithSize(10, 10, 1), nil)
+	require.NoError(t, err)
+	_, err = bootstrap.GenerateCompleteGame(context.Background())
+	require.NoError(t, err)
+
+	replayed, err := bootstrap.ExportProfile()
+	require.NoError(t, err)
+	assert.Equal(t, profile.Fingerprint, replayed.Fingerprint)
+	assert.Equal(t, profile.Config.WorldSeed, replayed.Config.WorldSeed)
+	assert.Equal(t, LegacySeedVersion, bootstrap.pcgManager.GetSeedManager().SeedVersion(ContentTypeTerrain),
+		"generator versions come from the profile")
+	assert.FileExists(t, filepath.Join(dataDir, "items", "items.yaml"))
+}
+
+func TestBootstrapProfile_DetectsDivergence(t *testing.T) {
+	profile := exportTestProfile(t)
+	profile.Fingerprint = "0000"
+
+	bootstrap, err := NewBootstrapFromProfile(profile, t.TempDir(), game.NewWorldWithSize(10, 10, 1), nil)
+	require.NoError(t, err)
+	_, err = bootstrap.GenerateCompleteGame(context.Background())
+	assert.ErrorContains(t, err, "does not match the bootstrap profile")
+}
+
+func TestBootstrapProfile_ShareCode(t *testing.T) {
+	profile := exportTestProfile(t)
+
+	code, err := profile.ShareCode()
+	require.NoError(t, err)
+	assert.True(t, len(code) > len(ShareCodePrefix))
+	assert.NotContains(t, code, "\n")
+
+	decoded, err := ParseShareCode(" " + code + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, profile, decoded)
+
+	_, err = ParseShareCode("not-a-code")
+	assert.ErrorContains(t, err, "prefix")
+	_, err = ParseShareCode(ShareCodePrefix + "!!!")
+	assert.ErrorContains(t, err, "invalid world sharing code")
+}
+
+func TestBootstrapProfile_Validate(t *testing.T) {
+	profile := exportTestProfile(t)
+
+	unsupported := *profile
+	unsupported.Version = 99
+	assert.ErrorContains(t, unsupported.Validate(), "unsupported")
+
+	unrooted := *profile
+	unrooted.Seeds.BaseSeed++
+	assert.ErrorContains(t, unrooted.Validate(), "rooted")
+
+	path := filepath.Join(t.TempDir(), "bad.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("profile_version: [\n"), 0o644))
+	_, err := LoadBootstrapProfile(path)
+	assert.ErrorContains(t, err, "failed to parse")
+}
@@ -180,6 +180,7 @@ func TestBootstrap_GenerateCompleteGame(t *testing.T) {
 			// Verify that basic content was generated
 			assert.Contains(t, bootstrap.generatedFiles, "world")
 			assert.Contains(t, bootstrap.generatedFiles, "factions")
+			assert.Contains(t, bootstrap.generatedFiles, "terrain")
 			assert.Contains(t, bootstrap.generatedFiles, "characters")
 			assert.Contains(t, bootstrap.generatedFiles, "quests")
 			assert.Contains(t, bootstrap.generatedFiles, "dialogue")
@@ -352,9 +353,8 @@ func TestBootstrap_ContextTimeout(t *testing.T) {
 
 	_, err = bootstrap.GenerateCompleteGame(ctx)
 
-	// The function should complete quickly despite the timeout context
-	// since it doesn't actually check the context in the current simple implementation
-	assert.NoError(t, err)
+	// The content pipeline stops as soon as the context expires
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
 }
 
 // Benchmark tests
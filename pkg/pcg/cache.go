@@ -0,0 +1,270 @@
+package pcg
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Content cache defaults
+const (
+	DefaultContentCacheMaxBytes = 64 << 20 // 64 MiB of encoded content
+	DefaultContentCacheTTL      = 10 * time.Minute
+)
+
+// ContentCacheConfig controls which generated content a ContentCache keeps
+// and for how long.
+type ContentCacheConfig struct {
+	MaxBytes int64                // Encoded size the cache may hold; 0 disables it
+	TTL      time.Duration        // How long an entry stays valid; 0 keeps entries until evicted
+	Enabled  map[ContentType]bool // Content types that are cached
+}
+
+// DefaultContentCacheConfig caches terrain and levels, whose content is
+// copied rather than modified when it enters the world.
+func DefaultContentCacheConfig() ContentCacheConfig {
+	return ContentCacheConfig{
+		MaxBytes: DefaultContentCacheMaxBytes,
+		TTL:      DefaultContentCacheTTL,
+		Enabled: map[ContentType]bool{
+			ContentTypeTerrain: true,
+			ContentTypeLevels:  true,
+		},
+	}
+}
+
+// ContentCacheStats describes the contents of a ContentCache.
+type ContentCacheStats struct {
+	Entries     int   `json:"entries"`     // Cached results
+	Bytes       int64 `json:"bytes"`       // Encoded size of the cached results
+	MaxBytes    int64 `json:"max_bytes"`   // Configured size limit
+	Evictions   int64 `json:"evictions"`   // Entries dropped to stay within MaxBytes
+	Expirations int64 `json:"expirations"` // Entries dropped after their TTL
+}
+
+// ContentCache keeps generated content keyed by content type, generator,
+// seed and a hash of the generation parameters, so generating the same
+// content again returns the earlier result. Entries expire after the TTL,
+// and the least recently used entries are evicted to keep the encoded size
+// of the cache within MaxBytes. Each lookup of an enabled content type is
+// recorded as a cache hit or miss in the GenerationMetrics.
+//
+// Cached content is shared between callers and must not be modified. It is
+// safe for concurrent use.
+type ContentCache struct {
+	mu          sync.Mutex
+	config      ContentCacheConfig
+	entries     map[string]*list.Element
+	lru         *list.List // *cacheEntry, most recently used first
+	size        int64
+	evictions   int64
+	expirations int64
+	metrics     *GenerationMetrics
+	now         func() time.Time
+}
+
+// cacheEntry is one cached generation result
+type cacheEntry struct {
+	key         string
+	contentType ContentType
+	content     interface{}
+	size        int64
+	expires     time.Time
+}
+
+// NewContentCache creates a content cache recording hits and misses in
+// metrics, which may be nil.
+func NewContentCache(config ContentCacheConfig, metrics *GenerationMetrics) *ContentCache {
+	return &ContentCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		metrics: metrics,
+		now:     time.Now,
+	}
+}
+
+// ContentCacheKey derives the cache key of content generated by the named
+// generator with params. Parameters that do not affect the content, such
+// as the world state, timeout and progress callback, are left out.
+func ContentCacheKey(contentType ContentType, generatorName string, params GenerationParams) (string, error) {
+	encoded, err := json.Marshal(struct {
+		Difficulty  int                    `json:"difficulty"`
+		PlayerLevel int                    `json:"player_level"`
+		Constraints map[string]interface{} `json:"constraints"`
+	}{params.Difficulty, params.PlayerLevel, params.Constraints})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash generation parameters: %w", err)
+	}
+	hash := sha256.Sum256(encoded)
+	return fmt.Sprintf("%s:%s:%d:%s", contentType, generatorName, params.Seed, hex.EncodeToString(hash[:12])), nil
+}
+
+// Enabled reports whether content of contentType is cached.
+func (cc *ContentCache) Enabled(contentType ContentType) bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.config.MaxBytes > 0 && cc.config.Enabled[contentType]
+}
+
+// Get returns the content cached under key and records a hit, or records
+// a miss.
+func (cc *ContentCache) Get(key string) (interface{}, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	element, exists := cc.entries[key]
+	if exists && cc.expiredLocked(element.Value.(*cacheEntry)) {
+		cc.removeLocked(element)
+		cc.expirations++
+		exists = false
+	}
+	if !exists {
+		cc.recordLocked(false)
+		return nil, false
+	}
+
+	cc.lru.MoveToFront(element)
+	cc.recordLocked(true)
+	return element.Value.(*cacheEntry).content, true
+}
+
+// Put caches content of contentType under key, evicting the least recently
+// used entries to make room. Content that cannot be encoded, or is larger
+// than the whole cache, is not cached.
+func (cc *ContentCache) Put(contentType ContentType, key string, content interface{}) error {
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("failed to measure %s content: %w", contentType, err)
+	}
+	size := int64(len(encoded))
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if !cc.config.Enabled[contentType] || size > cc.config.MaxBytes {
+		return nil
+	}
+	if element, exists := cc.entries[key]; exists {
+		cc.removeLocked(element)
+	}
+
+	entry := &cacheEntry{key: key, contentType: contentType, content: content, size: size}
+	if cc.config.TTL > 0 {
+		entry.expires = cc.now().Add(cc.config.TTL)
+	}
+	cc.entries[key] = cc.lru.PushFront(entry)
+	cc.size += size
+	cc.evictLocked()
+	return nil
+}
+
+// Invalidate drops every cached entry of contentType, such as when its
+// generators change.
+func (cc *ContentCache) Invalidate(contentType ContentType) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	for element := cc.lru.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*cacheEntry).contentType == contentType {
+			cc.removeLocked(element)
+		}
+		element = next
+	}
+}
+
+// Clear drops every cached entry.
+func (cc *ContentCache) Clear() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.entries = make(map[string]*list.Element)
+	cc.lru.Init()
+	cc.size = 0
+}
+
+// SetConfig replaces the cache configuration, dropping entries of content
+// types no longer cached and evicting entries beyond the new size limit.
+func (cc *ContentCache) SetConfig(config ContentCacheConfig) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.config = config
+	for element := cc.lru.Front(); element != nil; {
+		next := element.Next()
+		if !config.Enabled[element.Value.(*cacheEntry).contentType] {
+			cc.removeLocked(element)
+		}
+		element = next
+	}
+	cc.evictLocked()
+}
+
+// GetConfig returns the cache configuration.
+func (cc *ContentCache) GetConfig() ContentCacheConfig {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.config
+}
+
+// Stats returns the current size and eviction counts of the cache.
+func (cc *ContentCache) Stats() ContentCacheStats {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	return ContentCacheStats{
+		Entries:     cc.lru.Len(),
+		Bytes:       cc.size,
+		MaxBytes:    cc.config.MaxBytes,
+		Evictions:   cc.evictions,
+		Expirations: cc.expirations,
+	}
+}
+
+// evictLocked drops expired entries, then the least recently used ones
+// until the cache fits within MaxBytes. The caller must hold cc.mu.
+func (cc *ContentCache) evictLocked() {
+	for element := cc.lru.Back(); element != nil; {
+		prev := element.Prev()
+		if cc.expiredLocked(element.Value.(*cacheEntry)) {
+			cc.removeLocked(element)
+			cc.expirations++
+		}
+		element = prev
+	}
+	for cc.size > cc.config.MaxBytes && cc.lru.Len() > 0 {
+		cc.removeLocked(cc.lru.Back())
+		cc.evictions++
+	}
+}
+
+// expiredLocked reports whether entry has outlived the TTL. The caller
+// must hold cc.mu.
+func (cc *ContentCache) expiredLocked(entry *cacheEntry) bool {
+	return !entry.expires.IsZero() && !cc.now().Before(entry.expires)
+}
+
+// removeLocked drops an entry. The caller must hold cc.mu.
+func (cc *ContentCache) removeLocked(element *list.Element) {
+	entry := cc.lru.Remove(element).(*cacheEntry)
+	delete(cc.entries, entry.key)
+	cc.size -= entry.size
+}
+
+// recordLocked records a lookup in the generation metrics. The caller
+// must hold cc.mu.
+func (cc *ContentCache) recordLocked(hit bool) {
+	if cc.metrics == nil {
+		return
+	}
+	if hit {
+		cc.metrics.RecordCacheHit()
+	} else {
+		cc.metrics.RecordCacheMiss()
+	}
+}
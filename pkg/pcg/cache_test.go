@@ -0,0 +1,224 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// countingGenerator returns its seed as content and counts its calls
+type countingGenerator struct {
+	calls int
+}
+
+func (g *countingGenerator) Generate(ctx context.Context, params GenerationParams) (interface{}, error) {
+	g.calls++
+	return map[string]interface{}{"seed": params.Seed}, nil
+}
+
+func (g *countingGenerator) GetType() ContentType            { return ContentTypeLevels }
+func (g *countingGenerator) GetVersion() string              { return "1.0.0" }
+func (g *countingGenerator) Validate(GenerationParams) error { return nil }
+
+func newTestContentCache(maxBytes int64, ttl time.Duration) (*ContentCache, *GenerationMetrics) {
+	metrics := NewGenerationMetrics()
+	cache := NewContentCache(ContentCacheConfig{
+		MaxBytes: maxBytes,
+		TTL:      ttl,
+		Enabled:  map[ContentType]bool{ContentTypeLevels: true},
+	}, metrics)
+	return cache, metrics
+}
+
+func TestContentCache_GetRecordsHitsAndMisses(t *testing.T) {
+	cache, metrics := newTestContentCache(1024, 0)
+
+	if _, hit := cache.Get("levels:a"); hit {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	if err := cache.Put(ContentTypeLevels, "levels:a", "content"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	content, hit := cache.Get("levels:a")
+	if !hit || content != "content" {
+		t.Fatalf("expected cached content, got %v, %v", content, hit)
+	}
+	if metrics.CacheHits != 1 || metrics.CacheMisses != 1 {
+		t.Errorf("hits = %d, misses = %d, want 1 and 1", metrics.CacheHits, metrics.CacheMisses)
+	}
+}
+
+func TestContentCache_TTL(t *testing.T) {
+	cache, _ := newTestContentCache(1024, time.Minute)
+	now := time.Unix(1000, 0)
+	cache.now = func() time.Time { return now }
+
+	if err := cache.Put(ContentTypeLevels, "levels:a", "content"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	now = now.Add(59 * time.Second)
+	if _, hit := cache.Get("levels:a"); !hit {
+		t.Fatal("entry expired before its TTL")
+	}
+	now = now.Add(time.Second)
+	if _, hit := cache.Get("levels:a"); hit {
+		t.Fatal("entry outlived its TTL")
+	}
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Bytes != 0 || stats.Expirations != 1 {
+		t.Errorf("unexpected stats after expiry: %+v", stats)
+	}
+}
+
+func TestContentCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Each "xxxxxxxx" entry encodes to 10 bytes
+	cache, _ := newTestContentCache(25, 0)
+
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Put(ContentTypeLevels, key, "xxxxxxxx"); err != nil {
+			t.Fatalf("Put %s failed: %v", key, err)
+		}
+	}
+	cache.Get("a")
+	if err := cache.Put(ContentTypeLevels, "c", "xxxxxxxx"); err != nil {
+		t.Fatalf("Put c failed: %v", err)
+	}
+
+	if _, hit := cache.Get("b"); hit {
+		t.Error("the least recently used entry should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, hit := cache.Get(key); !hit {
+			t.Errorf("entry %s should still be cached", key)
+		}
+	}
+	if stats := cache.Stats(); stats.Bytes != 20 || stats.Evictions != 1 {
+		t.Errorf("unexpected stats after eviction: %+v", stats)
+	}
+
+	if err := cache.Put(ContentTypeLevels, "huge", "this content is larger than the whole cache"); err != nil {
+		t.Fatalf("Put huge failed: %v", err)
+	}
+	if _, hit := cache.Get("huge"); hit {
+		t.Error("content larger than the cache should not be cached")
+	}
+}
+
+func TestContentCache_EnabledTypes(t *testing.T) {
+	cache, _ := newTestContentCache(1024, 0)
+
+	if cache.Enabled(ContentTypeItems) {
+		t.Error("items should not be cached")
+	}
+	if err := cache.Put(ContentTypeItems, "items:a", "sword"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, hit := cache.Get("items:a"); hit {
+		t.Error("content of a disabled type should not be cached")
+	}
+
+	if err := cache.Put(ContentTypeLevels, "levels:a", "level"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	cache.SetConfig(ContentCacheConfig{MaxBytes: 1024, Enabled: map[ContentType]bool{ContentTypeItems: true}})
+	if cache.Enabled(ContentTypeLevels) {
+		t.Error("levels should no longer be cached")
+	}
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("disabling a type should drop its entries, %d left", stats.Entries)
+	}
+}
+
+func TestContentCache_Invalidate(t *testing.T) {
+	cache, _ := newTestContentCache(1024, 0)
+	cache.SetConfig(ContentCacheConfig{
+		MaxBytes: 1024,
+		Enabled:  map[ContentType]bool{ContentTypeLevels: true, ContentTypeTerrain: true},
+	})
+
+	cache.Put(ContentTypeLevels, "levels:a", "level")
+	cache.Put(ContentTypeTerrain, "terrain:a", "terrain")
+	cache.Invalidate(ContentTypeLevels)
+
+	if _, hit := cache.Get("levels:a"); hit {
+		t.Error("invalidated entry should be dropped")
+	}
+	if _, hit := cache.Get("terrain:a"); !hit {
+		t.Error("entries of other types should be kept")
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("unexpected stats after Clear: %+v", stats)
+	}
+}
+
+func TestContentCacheKey(t *testing.T) {
+	params := GenerationParams{
+		Seed:        42,
+		Difficulty:  5,
+		PlayerLevel: 3,
+		Constraints: map[string]interface{}{"room_count": 6},
+		Timeout:     time.Second,
+	}
+	key, err := ContentCacheKey(ContentTypeLevels, "room_corridor", params)
+	if err != nil {
+		t.Fatalf("ContentCacheKey failed: %v", err)
+	}
+
+	same := params
+	same.Timeout = time.Minute
+	if other, _ := ContentCacheKey(ContentTypeLevels, "room_corridor", same); other != key {
+		t.Error("the timeout should not change the key")
+	}
+
+	changed := params
+	changed.Constraints = map[string]interface{}{"room_count": 7}
+	if other, _ := ContentCacheKey(ContentTypeLevels, "room_corridor", changed); other == key {
+		t.Error("different constraints should change the key")
+	}
+	if other, _ := ContentCacheKey(ContentTypeLevels, "cellular", params); other == key {
+		t.Error("a different generator should change the key")
+	}
+
+	unencodable := params
+	unencodable.Constraints = map[string]interface{}{"callback": func() {}}
+	if _, err := ContentCacheKey(ContentTypeLevels, "room_corridor", unencodable); err == nil {
+		t.Error("expected an error for constraints that cannot be encoded")
+	}
+}
+
+func TestRegistry_GenerateContentUsesCache(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	registry := NewRegistry(logger)
+	cache, metrics := newTestContentCache(1024, 0)
+	registry.SetCache(cache)
+
+	generator := &countingGenerator{}
+	if err := registry.RegisterGenerator("counting", generator); err != nil {
+		t.Fatalf("RegisterGenerator failed: %v", err)
+	}
+
+	params := GenerationParams{Seed: 7, Difficulty: 1, PlayerLevel: 1}
+	for i := 0; i < 2; i++ {
+		if _, err := registry.GenerateContent(context.Background(), ContentTypeLevels, "counting", params); err != nil {
+			t.Fatalf("GenerateContent failed: %v", err)
+		}
+	}
+	if generator.calls != 1 {
+		t.Errorf("generator called %d times, want 1", generator.calls)
+	}
+	if metrics.CacheHits != 1 || metrics.CacheMisses != 1 {
+		t.Errorf("hits = %d, misses = %d, want 1 and 1", metrics.CacheHits, metrics.CacheMisses)
+	}
+
+	params.Seed = 8
+	if _, err := registry.GenerateContent(context.Background(), ContentTypeLevels, "counting", params); err != nil {
+		t.Fatalf("GenerateContent failed: %v", err)
+	}
+	if generator.calls != 2 {
+		t.Errorf("a new seed should generate new content, generator called %d times", generator.calls)
+	}
+}
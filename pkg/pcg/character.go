@@ -19,9 +19,10 @@ func init() {
 // NPCGenerator creates NPCs with procedural personalities and motivations
 // Generates cohesive character profiles that enhance narrative depth and world immersion
 type NPCGenerator struct {
-	version string
-	logger  *logrus.Logger
-	rng     *rand.Rand
+	version       string
+	logger        *logrus.Logger
+	rng           *rand.Rand
+	personalities *PersonalityGenerator
 }
 
 // NewNPCGenerator creates a new character generator instance
@@ -40,9 +41,10 @@ func NewNPCGenerator(logger *logrus.Logger) *NPCGenerator {
 	}
 
 	generator := &NPCGenerator{
-		version: "1.0.0",
-		logger:  logger,
-		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		version:       "1.0.0",
+		logger:        logger,
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		personalities: NewPersonalityGenerator(),
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -82,7 +84,7 @@ func (cg *NPCGenerator) Generate(ctx context.Context, params GenerationParams) (
 	}).Info("setting up deterministic generation with seed")
 
 	// Use seed for deterministic generation
-	rng := rand.New(rand.NewSource(params.Seed))
+	rng := NewRNG(params.Seed, "npcs")
 	cg.rng = rng
 
 	characterParams, ok := params.Constraints["character_params"].(CharacterParams)
@@ -192,7 +194,7 @@ func (cg *NPCGenerator) GenerateNPC(ctx context.Context, characterType Character
 	}).Debug("entering GenerateNPC")
 
 	// Use seed for deterministic generation
-	rng := rand.New(rand.NewSource(params.Seed))
+	rng := NewRNG(params.Seed, "npcs")
 	cg.rng = rng
 
 	logrus.WithFields(logrus.Fields{
@@ -242,7 +244,9 @@ func (cg *NPCGenerator) GenerateNPC(ctx context.Context, characterType Character
 		Faction:   params.Faction,
 		Dialog:    cg.generateDialog(personality, params),
 		LootTable: cg.generateLootTable(characterType, params),
+		Modifiers: personality.Modifiers,
 	}
+	npc.Portrait = GeneratePortrait(params.Seed, &npc.Character)
 
 	logrus.WithFields(logrus.Fields{
 		"function":       "GenerateNPC",
@@ -314,7 +318,11 @@ func (cg *NPCGenerator) GenerateNPCGroup(ctx context.Context, groupType NPCGroup
 	return npcs, nil
 }
 
-// GeneratePersonality creates personality traits and motivations
+// GeneratePersonality creates personality traits and motivations. The
+// strongest of the bravery, greed and honesty traits, the voice and the
+// behavior modifiers come from the personality generator, for the genre
+// in the "genre" constraint and the culture in the "culture" constraint or
+// else params.Faction.
 func (cg *NPCGenerator) GeneratePersonality(ctx context.Context, character *game.Character, params CharacterParams) (*PersonalityProfile, error) {
 	profile := &PersonalityProfile{
 		Alignment:   params.Alignment,
@@ -324,12 +332,28 @@ func (cg *NPCGenerator) GeneratePersonality(ctx context.Context, character *game
 		Speech:      cg.generateSpeechPattern(params),
 	}
 
+	genre, _ := params.Constraints["genre"].(string)
+	culture, _ := params.Constraints["culture"].(string)
+	if culture == "" {
+		culture = params.Faction
+	}
+	rolled := cg.personalities.Generate(params.Seed, GenreType(genre), culture)
+	profile.Scores = rolled.Scores
+	profile.Modifiers = rolled.Modifiers
+	profile.Speech.Formality = rolled.Speech.Formality
+	profile.Speech.Vocabulary = rolled.Speech.Vocabulary
+	profile.Speech.Diction = rolled.Speech.Diction
+	if rolled.Temperament != "stoic" {
+		profile.Temperament = rolled.Temperament
+	}
+
 	// Generate personality traits
-	traits, err := cg.generatePersonalityTraits(params.UniqueTraits, params)
+	axisCount := min(len(rolled.Traits), max(params.UniqueTraits, 0))
+	traits, err := cg.generatePersonalityTraits(params.UniqueTraits-axisCount, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate personality traits: %w", err)
 	}
-	profile.Traits = traits
+	profile.Traits = append(rolled.Traits[:axisCount:axisCount], traits...)
 
 	// Generate motivations
 	motivations, err := cg.generateMotivations(params.MotivationCount, params)
@@ -341,6 +365,12 @@ func (cg *NPCGenerator) GeneratePersonality(ctx context.Context, character *game
 	return profile, nil
 }
 
+// GetPersonalityGenerator returns the generator of personality traits,
+// whose genre and faction culture templates can be changed
+func (cg *NPCGenerator) GetPersonalityGenerator() *PersonalityGenerator {
+	return cg.personalities
+}
+
 // GetType returns the content type for character generation
 func (cg *NPCGenerator) GetType() ContentType {
 	return ContentTypeCharacters
@@ -396,7 +426,7 @@ func (cg *NPCGenerator) generateBaseCharacter(params CharacterParams) (*game.Cha
 
 	// Create character with generated attributes
 	character := &game.Character{
-		ID:           fmt.Sprintf("npc_%d", cg.rng.Int63()),
+		ID:           game.SeededID(game.IDNamespaceNPC, cg.rng.Int63(), name),
 		Name:         name,
 		Description:  description,
 		Class:        cg.selectCharacterClass(params.CharacterType),
@@ -544,9 +574,10 @@ func (cg *NPCGenerator) generateCatchphrase(params CharacterParams) string {
 	return catchphrases[cg.rng.Intn(len(catchphrases))]
 }
 
+// generatePersonalityTraits picks count traits other than those of the
+// personality axes, which the personality generator scores
 func (cg *NPCGenerator) generatePersonalityTraits(count int, params CharacterParams) ([]PersonalityTrait, error) {
 	allTraits := []string{
-		"brave", "cowardly", "honest", "deceitful", "generous", "greedy",
 		"patient", "impatient", "wise", "foolish", "kind", "cruel",
 		"ambitious", "lazy", "loyal", "treacherous", "humble", "arrogant",
 		"creative", "mundane", "curious", "incurious", "optimistic", "pessimistic",
@@ -881,8 +912,11 @@ func (cg *NPCGenerator) calculateArmorClass(char *game.Character) int {
 }
 
 func (cg *NPCGenerator) calculateTHAC0(char *game.Character) int {
-	// Classic D&D THAC0 calculation
+	// Classic D&D THAC0 calculation, or the active ruleset's attack matrix
 	baseTHAC0 := 20 - char.Level
+	if rules := game.ActiveRuleset(); rules != nil {
+		baseTHAC0 = rules.THAC0(char.Class, char.Level)
+	}
 	strMod := cg.getModifier(char.Strength)
 	return baseTHAC0 - strMod
 }
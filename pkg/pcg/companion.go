@@ -0,0 +1,79 @@
+package pcg
+
+import (
+	"context"
+	"fmt"
+
+	"goldbox-rpg/pkg/game"
+)
+
+// companionTypes are the character types offered for hire as companions
+var companionTypes = []CharacterType{
+	CharacterTypeGuard,
+	CharacterTypeMage,
+	CharacterTypeCleric,
+	CharacterTypeRogue,
+}
+
+// companionWagePerLevel is the gold a companion asks per character level
+const companionWagePerLevel = 50
+
+// GenerateCompanion generates a hireable companion: a fighter, mage,
+// cleric or thief within a level of playerLevel, with a personality whose
+// traits shape its loyalty and morale. Starting loyalty ranges 40-69 and
+// morale 50-79, and the wage is 50 gold per level. The same seed always
+// yields the same companion.
+//
+// Parameters:
+//   - ctx: Generation context
+//   - seed: Seed for deterministic generation
+//   - playerLevel: Level of the hiring player
+//
+// Returns:
+//   - *game.Companion: The companion, not yet hired
+//   - error: Generation failed
+func GenerateCompanion(ctx context.Context, seed int64, playerLevel int) (*game.Companion, error) {
+	if playerLevel < 1 {
+		playerLevel = 1
+	}
+	rng := NewRNG(seed, "companions")
+
+	characterType := companionTypes[rng.Intn(len(companionTypes))]
+	params := CharacterParams{
+		GenerationParams: GenerationParams{
+			Seed:        seed,
+			Difficulty:  (playerLevel - 1) * 4,
+			PlayerLevel: playerLevel,
+		},
+		CharacterType:    characterType,
+		PersonalityDepth: 2,
+		MotivationCount:  1,
+		BackgroundType:   BackgroundMilitary,
+		SocialClass:      SocialClassPeasant,
+		AgeRange:         AgeRangeAdult,
+		Faction:          "companions",
+		UniqueTraits:     2,
+	}
+
+	generator := NewNPCGenerator(nil)
+	npc, err := generator.GenerateNPC(ctx, characterType, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate companion: %w", err)
+	}
+	personality, err := generator.GeneratePersonality(ctx, &npc.Character, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate companion personality: %w", err)
+	}
+
+	traits := make([]string, 0, len(personality.Traits))
+	for _, trait := range personality.Traits {
+		traits = append(traits, trait.Name)
+	}
+
+	return game.NewCompanion(npc,
+		npc.Level*companionWagePerLevel,
+		40+rng.Intn(30),
+		50+rng.Intn(30),
+		personality.Temperament,
+		traits)
+}
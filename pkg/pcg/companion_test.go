@@ -0,0 +1,31 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCompanion(t *testing.T) {
+	companion, err := GenerateCompanion(context.Background(), 77, 3)
+	require.NoError(t, err)
+	require.NotNil(t, companion.NPC)
+
+	assert.Empty(t, companion.EmployerID, "a generated companion is not yet hired")
+	assert.InDelta(t, 3, companion.NPC.Level, 1, "companions are within a level of the player")
+	assert.Equal(t, companion.NPC.Level*companionWagePerLevel, companion.Wage)
+	assert.GreaterOrEqual(t, companion.Loyalty, 40)
+	assert.Less(t, companion.Loyalty, 70)
+	assert.GreaterOrEqual(t, companion.Morale, 50)
+	assert.Less(t, companion.Morale, 80)
+	assert.Len(t, companion.Traits, 2)
+	assert.NotEmpty(t, companion.Temperament)
+
+	again, err := GenerateCompanion(context.Background(), 77, 3)
+	require.NoError(t, err)
+	assert.Equal(t, companion.NPC.Name, again.NPC.Name)
+	assert.Equal(t, companion.Traits, again.Traits)
+	assert.Equal(t, companion.Loyalty, again.Loyalty)
+}
@@ -0,0 +1,347 @@
+package pcg
+
+import (
+	"fmt"
+	"sort"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ConsistencyCheck names a kind of cross-content reference
+type ConsistencyCheck string
+
+const (
+	CheckQuestTarget      ConsistencyCheck = "quest_target"      // Quest objective targets and item rewards
+	CheckDungeonExit      ConsistencyCheck = "dungeon_exit"      // Dungeon entrances and level connections
+	CheckOverworldLink    ConsistencyCheck = "overworld_link"    // Travel paths and settlement links
+	CheckFactionReference ConsistencyCheck = "faction_reference" // Faction IDs named by other content
+	CheckLootItem         ConsistencyCheck = "loot_item"         // Items dropped by loot tables and NPCs
+)
+
+// LootReferences lists the items loot tables drop. The item loot table
+// registry implements it, so its tables can be checked without this
+// package depending on the items package.
+type LootReferences interface {
+	// ItemReferences returns the item templates each table drops, by table name
+	ItemReferences() map[string][]string
+}
+
+// WorldContent is the generated content of a world whose cross references
+// are checked together. Every field is optional; references into content
+// that is missing are reported as unresolved.
+type WorldContent struct {
+	World     *game.World             // Levels, NPCs and objects in play
+	Overworld *GeneratedWorld         // Regions, settlements, landmarks and travel paths
+	Dungeons  []*DungeonComplex       // Dungeon complexes and their level connections
+	Factions  *GeneratedFactionSystem // Factions, territories and their relations
+
+	// DungeonEntrances maps a dungeon ID to the overworld node, a region,
+	// settlement or landmark ID, its entrance leads to
+	DungeonEntrances map[string]string
+
+	Quests []*game.Quest
+	// QuestTargets maps a quest ID to the entity and location IDs its
+	// objectives target, which game.Quest does not record
+	QuestTargets map[string][]string
+
+	Loot LootReferences
+	// ItemIDs are the item IDs and item templates content may reference in
+	// addition to the items in World. Item references are only checked when
+	// ItemIDs is set.
+	ItemIDs []string
+}
+
+// ConsistencyIssue is a reference that does not resolve
+type ConsistencyIssue struct {
+	Check     ConsistencyCheck   `json:"check"`
+	Severity  ValidationSeverity `json:"severity"`
+	Source    string             `json:"source"`    // ID of the content holding the reference
+	Reference string             `json:"reference"` // The ID that did not resolve
+	Message   string             `json:"message"`
+}
+
+// ConsistencyReport is the structured outcome of a world consistency check
+type ConsistencyReport struct {
+	Checked map[ConsistencyCheck]int `json:"checked"` // References checked per check
+	Issues  []ConsistencyIssue       `json:"issues"`
+}
+
+// Consistent reports whether no issue is an error
+func (r *ConsistencyReport) Consistent() bool {
+	return len(r.Errors()) == 0
+}
+
+// Errors returns the issues that are errors or worse
+func (r *ConsistencyReport) Errors() []ConsistencyIssue {
+	var errors []ConsistencyIssue
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError || issue.Severity == SeverityCritical {
+			errors = append(errors, issue)
+		}
+	}
+	return errors
+}
+
+// ValidateWorldConsistency checks the references between pieces of
+// generated content: quest targets and rewards exist, dungeon entrances
+// lead to overworld nodes and level connections to levels of their
+// dungeon, faction IDs resolve and loot tables drop known items.
+//
+// The check only reads content, so it is safe to run on content in play;
+// World is read through a snapshot.
+func (pcg *PCGManager) ValidateWorldConsistency(content WorldContent) *ConsistencyReport {
+	checker := newConsistencyChecker(content)
+	checker.checkQuests(content)
+	checker.checkDungeons(content)
+	checker.checkOverworld(content.Overworld)
+	checker.checkFactions(content)
+	checker.checkLoot(content)
+
+	report := checker.report
+	pcg.logger.WithFields(logrus.Fields{
+		"checked": report.Checked,
+		"issues":  len(report.Issues),
+		"errors":  len(report.Errors()),
+	}).Info("world consistency validated")
+	return report
+}
+
+// consistencyChecker holds the IDs references may resolve to
+type consistencyChecker struct {
+	report    *ConsistencyReport
+	world     *game.World     // Snapshot of content.World, nil without one
+	entities  map[string]bool // Levels, objects, NPCs, dungeons and overworld nodes
+	overworld map[string]bool // Regions, settlements and landmarks
+	factions  map[string]bool
+	items     map[string]bool // nil when items are not checked
+}
+
+func newConsistencyChecker(content WorldContent) *consistencyChecker {
+	c := &consistencyChecker{
+		report:    &ConsistencyReport{Checked: make(map[ConsistencyCheck]int)},
+		entities:  make(map[string]bool),
+		overworld: make(map[string]bool),
+		factions:  make(map[string]bool),
+	}
+	if content.ItemIDs != nil {
+		c.items = make(map[string]bool, len(content.ItemIDs))
+		for _, id := range content.ItemIDs {
+			c.items[id] = true
+		}
+	}
+
+	if content.World != nil {
+		c.world = content.World.Clone()
+		for _, level := range c.world.Levels {
+			c.entities[level.ID] = true
+		}
+		for id, obj := range c.world.Objects {
+			c.entities[id] = true
+			if _, isItem := obj.(*game.Item); isItem && c.items != nil {
+				c.items[id] = true
+			}
+		}
+		for id := range c.world.NPCs {
+			c.entities[id] = true
+		}
+	}
+	for _, dungeon := range content.Dungeons {
+		c.entities[dungeon.ID] = true
+	}
+	if world := content.Overworld; world != nil {
+		for _, region := range world.Regions {
+			c.overworld[region.ID] = true
+		}
+		for _, settlement := range world.Settlements {
+			c.overworld[settlement.ID] = true
+		}
+		for _, landmark := range world.Landmarks {
+			c.overworld[landmark.ID] = true
+		}
+		for id := range c.overworld {
+			c.entities[id] = true
+		}
+	}
+	if content.Factions != nil {
+		for _, faction := range content.Factions.Factions {
+			c.factions[faction.ID] = true
+		}
+	}
+	return c
+}
+
+// expect counts a reference and reports it unless it resolves
+func (c *consistencyChecker) expect(check ConsistencyCheck, severity ValidationSeverity, resolved bool, source, reference, format string, args ...interface{}) {
+	c.report.Checked[check]++
+	if resolved {
+		return
+	}
+	c.report.Issues = append(c.report.Issues, ConsistencyIssue{
+		Check:     check,
+		Severity:  severity,
+		Source:    source,
+		Reference: reference,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// checkQuests checks quest objective targets and item rewards
+func (c *consistencyChecker) checkQuests(content WorldContent) {
+	quests := make(map[string]bool, len(content.Quests))
+	for _, quest := range content.Quests {
+		quests[quest.ID] = true
+		for _, reward := range quest.Rewards {
+			if reward.Type == "item" && c.items != nil {
+				c.expect(CheckQuestTarget, SeverityError, c.items[reward.ItemID], quest.ID, reward.ItemID,
+					"quest %s rewards unknown item %s", quest.ID, reward.ItemID)
+			}
+		}
+	}
+
+	for _, questID := range sortedKeys(content.QuestTargets) {
+		c.expect(CheckQuestTarget, SeverityWarning, quests[questID], questID, questID,
+			"targets recorded for unknown quest %s", questID)
+		for _, target := range content.QuestTargets[questID] {
+			c.expect(CheckQuestTarget, SeverityError, c.entities[target], questID, target,
+				"quest %s targets %s, which is not in the world", questID, target)
+		}
+	}
+}
+
+// checkDungeons checks dungeon entrances and the connections between levels
+func (c *consistencyChecker) checkDungeons(content WorldContent) {
+	for _, dungeon := range content.Dungeons {
+		if entrance, exists := content.DungeonEntrances[dungeon.ID]; exists {
+			c.expect(CheckDungeonExit, SeverityError, c.overworld[entrance], dungeon.ID, entrance,
+				"dungeon %s exits to %s, which is not an overworld node", dungeon.ID, entrance)
+		} else if content.Overworld != nil {
+			c.expect(CheckDungeonExit, SeverityWarning, false, dungeon.ID, "",
+				"dungeon %s has no exit to the overworld", dungeon.ID)
+		}
+
+		for _, connection := range dungeon.Connections {
+			for _, level := range []int{connection.FromLevel, connection.ToLevel} {
+				_, exists := dungeon.Levels[level]
+				c.expect(CheckDungeonExit, SeverityError, exists, dungeon.ID, fmt.Sprint(level),
+					"dungeon %s connects level %d to level %d, but has no level %d",
+					dungeon.ID, connection.FromLevel, connection.ToLevel, level)
+			}
+		}
+		for _, levelNum := range sortedKeys(dungeon.Levels) {
+			for _, point := range dungeon.Levels[levelNum].Connections {
+				_, exists := dungeon.Levels[point.TargetLevel]
+				c.expect(CheckDungeonExit, SeverityError, exists, dungeon.ID, fmt.Sprint(point.TargetLevel),
+					"dungeon %s level %d has a %s to missing level %d", dungeon.ID, levelNum, point.Type, point.TargetLevel)
+			}
+		}
+	}
+
+	for _, dungeonID := range sortedKeys(content.DungeonEntrances) {
+		c.expect(CheckDungeonExit, SeverityWarning, c.entities[dungeonID], dungeonID, dungeonID,
+			"entrance recorded for unknown dungeon %s", dungeonID)
+	}
+}
+
+// checkOverworld checks travel paths and settlement links between
+// overworld nodes
+func (c *consistencyChecker) checkOverworld(world *GeneratedWorld) {
+	if world == nil {
+		return
+	}
+	for _, path := range world.TravelPaths {
+		for _, node := range []string{path.From, path.To} {
+			c.expect(CheckOverworldLink, SeverityError, c.overworld[node], path.ID, node,
+				"travel path %s ends at unknown node %s", path.ID, node)
+		}
+	}
+	for _, settlement := range world.Settlements {
+		if settlement.RegionID != "" {
+			c.expect(CheckOverworldLink, SeverityError, c.overworld[settlement.RegionID], settlement.ID, settlement.RegionID,
+				"settlement %s lies in unknown region %s", settlement.ID, settlement.RegionID)
+		}
+		for _, link := range settlement.Connections {
+			c.expect(CheckOverworldLink, SeverityError, c.overworld[link], settlement.ID, link,
+				"settlement %s links to unknown node %s", settlement.ID, link)
+		}
+	}
+}
+
+// checkFactions checks the faction IDs named by territories, faction
+// relations and NPCs
+func (c *consistencyChecker) checkFactions(content WorldContent) {
+	expect := func(source, factionID, what string) {
+		c.expect(CheckFactionReference, SeverityError, c.factions[factionID], source, factionID,
+			"%s %s names unknown faction %s", what, source, factionID)
+	}
+
+	if system := content.Factions; system != nil {
+		territories := make(map[string]bool, len(system.Territories))
+		for _, territory := range system.Territories {
+			territories[territory.ID] = true
+			expect(territory.ID, territory.ControllerID, "territory")
+		}
+		for _, relationship := range system.Relationships {
+			expect(relationship.ID, relationship.Faction1ID, "relationship")
+			expect(relationship.ID, relationship.Faction2ID, "relationship")
+		}
+		for _, deal := range system.TradeDeals {
+			expect(deal.ID, deal.Faction1ID, "trade deal")
+			expect(deal.ID, deal.Faction2ID, "trade deal")
+		}
+		for _, conflict := range system.Conflicts {
+			for _, factionID := range conflict.Factions {
+				expect(conflict.ID, factionID, "conflict")
+			}
+			if conflict.Territory != "" {
+				c.expect(CheckFactionReference, SeverityError, territories[conflict.Territory], conflict.ID, conflict.Territory,
+					"conflict %s disputes unknown territory %s", conflict.ID, conflict.Territory)
+			}
+		}
+	}
+
+	// NPC factions are free-form when no faction system was generated
+	if c.world == nil || content.Factions == nil {
+		return
+	}
+	for _, id := range sortedKeys(c.world.NPCs) {
+		if faction := c.world.NPCs[id].Faction; faction != "" {
+			expect(id, faction, "NPC")
+		}
+	}
+}
+
+// checkLoot checks the items dropped by loot tables and NPC loot
+func (c *consistencyChecker) checkLoot(content WorldContent) {
+	if c.items == nil {
+		return
+	}
+	if content.Loot != nil {
+		references := content.Loot.ItemReferences()
+		for _, table := range sortedKeys(references) {
+			for _, item := range references[table] {
+				c.expect(CheckLootItem, SeverityError, c.items[item], table, item,
+					"loot table %s drops unknown item %s", table, item)
+			}
+		}
+	}
+	if c.world != nil {
+		for _, id := range sortedKeys(c.world.NPCs) {
+			for _, entry := range c.world.NPCs[id].LootTable {
+				c.expect(CheckLootItem, SeverityError, c.items[entry.ItemID], id, entry.ItemID,
+					"NPC %s drops unknown item %s", id, entry.ItemID)
+			}
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in order, so reports are stable
+func sortedKeys[K int | string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -0,0 +1,129 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLoot is a loot table source with fixed item references
+type fakeLoot map[string][]string
+
+func (l fakeLoot) ItemReferences() map[string][]string { return l }
+
+// consistentContent returns content whose references all resolve
+func consistentContent(t *testing.T) WorldContent {
+	t.Helper()
+	world := game.NewWorldWithSize(10, 10, 1)
+	world.NPCs["npc_guard"] = &game.NPC{
+		Character: game.Character{ID: "npc_guard"},
+		Faction:   "faction_watch",
+		LootTable: []game.LootEntry{{ItemID: "sword", Chance: 0.5}},
+	}
+
+	return WorldContent{
+		World: world,
+		Overworld: &GeneratedWorld{
+			Regions: []*Region{{ID: "region_1"}},
+			Settlements: []*Settlement{
+				{ID: "settlement_1", RegionID: "region_1", Connections: []string{"settlement_2"}},
+				{ID: "settlement_2", RegionID: "region_1", Connections: []string{"settlement_1"}},
+			},
+			TravelPaths: []*TravelPath{{ID: "path_1", From: "settlement_1", To: "settlement_2"}},
+		},
+		Dungeons: []*DungeonComplex{{
+			ID:          "dungeon_crypt",
+			Levels:      map[int]*DungeonLevel{1: {Level: 1, Connections: []ConnectionPoint{{TargetLevel: 2}}}, 2: {Level: 2}},
+			Connections: []LevelConnection{{FromLevel: 1, ToLevel: 2}},
+		}},
+		DungeonEntrances: map[string]string{"dungeon_crypt": "settlement_2"},
+		Factions: &GeneratedFactionSystem{
+			Factions:      []*Faction{{ID: "faction_watch"}, {ID: "faction_thieves"}},
+			Relationships: []*FactionRelationship{{ID: "rel_1", Faction1ID: "faction_watch", Faction2ID: "faction_thieves"}},
+			Territories:   []*Territory{{ID: "territory_docks", ControllerID: "faction_thieves"}},
+			Conflicts:     []*Conflict{{ID: "conflict_1", Factions: []string{"faction_watch", "faction_thieves"}, Territory: "territory_docks"}},
+		},
+		Quests: []*game.Quest{{
+			ID:      "quest_escort",
+			Rewards: []game.QuestReward{{Type: "item", ItemID: "sword"}, {Type: "gold", Value: 10}},
+		}},
+		QuestTargets: map[string][]string{"quest_escort": {"npc_guard", "dungeon_crypt"}},
+		Loot:         fakeLoot{"combat": {"sword"}},
+		ItemIDs:      []string{"sword"},
+	}
+}
+
+func TestValidateWorldConsistency_Consistent(t *testing.T) {
+	manager := NewPCGManager(nil, nil)
+	report := manager.ValidateWorldConsistency(consistentContent(t))
+
+	assert.True(t, report.Consistent(), "issues: %+v", report.Issues)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, 4, report.Checked[CheckQuestTarget])
+	assert.Equal(t, 5, report.Checked[CheckDungeonExit])
+	assert.Equal(t, 6, report.Checked[CheckOverworldLink])
+	assert.Equal(t, 7, report.Checked[CheckFactionReference])
+	assert.Equal(t, 2, report.Checked[CheckLootItem])
+}
+
+func TestValidateWorldConsistency_ReportsDanglingReferences(t *testing.T) {
+	content := consistentContent(t)
+	content.QuestTargets["quest_escort"] = append(content.QuestTargets["quest_escort"], "npc_missing")
+	content.DungeonEntrances["dungeon_crypt"] = "settlement_9"
+	content.Dungeons[0].Connections = append(content.Dungeons[0].Connections, LevelConnection{FromLevel: 2, ToLevel: 3})
+	content.Overworld.TravelPaths[0].To = "settlement_9"
+	content.Factions.Territories[0].ControllerID = "faction_gone"
+	content.Loot = fakeLoot{"combat": {"sword", "halberd"}}
+
+	report := NewPCGManager(nil, nil).ValidateWorldConsistency(content)
+	assert.False(t, report.Consistent())
+
+	unresolved := make(map[ConsistencyCheck][]string)
+	for _, issue := range report.Errors() {
+		unresolved[issue.Check] = append(unresolved[issue.Check], issue.Reference)
+	}
+	assert.Equal(t, map[ConsistencyCheck][]string{
+		CheckQuestTarget:      {"npc_missing"},
+		CheckDungeonExit:      {"settlement_9", "3"},
+		CheckOverworldLink:    {"settlement_9"},
+		CheckFactionReference: {"faction_gone"},
+		CheckLootItem:         {"halberd"},
+	}, unresolved)
+}
+
+func TestValidateWorldConsistency_Warnings(t *testing.T) {
+	content := consistentContent(t)
+	delete(content.DungeonEntrances, "dungeon_crypt")
+	content.QuestTargets["quest_unknown"] = nil
+	content.ItemIDs = nil
+	content.Quests[0].Rewards[0].ItemID = "unchecked"
+
+	report := NewPCGManager(nil, nil).ValidateWorldConsistency(content)
+	assert.True(t, report.Consistent(), "warnings do not make content inconsistent")
+	require.Len(t, report.Issues, 2)
+	for _, issue := range report.Issues {
+		assert.Equal(t, SeverityWarning, issue.Severity)
+	}
+	assert.Zero(t, report.Checked[CheckLootItem], "items are not checked without an item catalog")
+}
+
+func TestBootstrap_ValidatesConsistency(t *testing.T) {
+	config := DefaultBootstrapConfig()
+	config.DataDirectory = t.TempDir()
+	config.WorldSeed = 7
+
+	world := game.NewWorldWithSize(10, 10, 1)
+	bootstrap := NewBootstrap(config, world, nil)
+	_, err := bootstrap.GenerateCompleteGame(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, bootstrap.ConsistencyReport())
+	assert.True(t, bootstrap.ConsistencyReport().Consistent())
+
+	world.NPCs["npc_looter"] = &game.NPC{LootTable: []game.LootEntry{{ItemID: "vorpal_blade"}}}
+	_, err = NewBootstrap(config, world, nil).GenerateCompleteGame(context.Background())
+	assert.ErrorContains(t, err, "vorpal_blade")
+}
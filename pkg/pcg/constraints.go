@@ -0,0 +1,240 @@
+package pcg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConstraintKind names the rule a LayoutConstraint places on the rooms of a
+// generated level
+type ConstraintKind string
+
+const (
+	// ConstraintCount bounds how many rooms of a type a level has
+	ConstraintCount ConstraintKind = "count"
+	// ConstraintFarthest puts a room of a type as many corridors away from
+	// the rooms of another type as the layout allows
+	ConstraintFarthest ConstraintKind = "farthest"
+	// ConstraintAdjacent requires rooms of a type to share a corridor with
+	// a room of another type
+	ConstraintAdjacent ConstraintKind = "adjacent"
+)
+
+// layoutRoomTypes are the room types constraints may name
+var layoutRoomTypes = map[RoomType]bool{
+	RoomTypeEntrance: true,
+	RoomTypeExit:     true,
+	RoomTypeCombat:   true,
+	RoomTypeTreasure: true,
+	RoomTypePuzzle:   true,
+	RoomTypeBoss:     true,
+	RoomTypeSecret:   true,
+	RoomTypeShop:     true,
+	RoomTypeRest:     true,
+	RoomTypeTrap:     true,
+	RoomTypeStory:    true,
+}
+
+// LayoutConstraint is a hard rule a designer places on the room layout of a
+// generated level, such as "exactly one shop per level", "boss room at max
+// distance from entrance" or "at least 2 secret rooms adjacent to
+// treasure". The level generator repairs its layout to meet its
+// constraints, or fails with a ConstraintError explaining which it could
+// not meet.
+//
+// Entrance and exit rooms are fixed by the layout, so constraints may name
+// them as the other room type but not as the constrained one.
+type LayoutConstraint struct {
+	Kind     ConstraintKind `yaml:"kind" json:"kind"`                       // The rule to enforce
+	RoomType RoomType       `yaml:"room_type" json:"room_type"`             // The constrained room type
+	Other    RoomType       `yaml:"other,omitempty" json:"other,omitempty"` // Farthest from, or adjacent to, this type
+	Min      int            `yaml:"min,omitempty" json:"min,omitempty"`     // Fewest rooms counted, or adjacent
+	Max      *int           `yaml:"max,omitempty" json:"max,omitempty"`     // Most rooms counted; nil is unbounded
+}
+
+// Validate checks that the constraint is well formed
+func (c LayoutConstraint) Validate() error {
+	if !layoutRoomTypes[c.RoomType] {
+		return fmt.Errorf("unknown room type %q", c.RoomType)
+	}
+	if c.RoomType == RoomTypeEntrance || c.RoomType == RoomTypeExit {
+		return fmt.Errorf("%s rooms are fixed by the layout and cannot be constrained", c.RoomType)
+	}
+
+	switch c.Kind {
+	case ConstraintCount:
+		if c.Other != "" {
+			return fmt.Errorf("count constraints take no other room type")
+		}
+		if c.Min < 0 {
+			return fmt.Errorf("minimum count must not be negative")
+		}
+		if c.Max != nil && *c.Max < c.Min {
+			return fmt.Errorf("maximum count %d is below minimum count %d", *c.Max, c.Min)
+		}
+	case ConstraintFarthest, ConstraintAdjacent:
+		if !layoutRoomTypes[c.Other] {
+			return fmt.Errorf("unknown other room type %q", c.Other)
+		}
+		if c.Other == c.RoomType {
+			return fmt.Errorf("%s constraints need two different room types", c.Kind)
+		}
+		if c.Max != nil {
+			return fmt.Errorf("%s constraints take no maximum", c.Kind)
+		}
+		if c.Kind == ConstraintFarthest && c.Min != 0 {
+			return fmt.Errorf("farthest constraints take no minimum")
+		}
+		if c.Kind == ConstraintAdjacent && c.Min < 1 {
+			return fmt.Errorf("adjacent constraints need a minimum of at least 1")
+		}
+	default:
+		return fmt.Errorf("unknown constraint kind %q", c.Kind)
+	}
+	return nil
+}
+
+// String describes the constraint for reports, such as "exactly 1 shop
+// room" or "at least 2 secret rooms adjacent to a treasure room"
+func (c LayoutConstraint) String() string {
+	switch c.Kind {
+	case ConstraintCount:
+		switch {
+		case c.Max == nil:
+			return fmt.Sprintf("at least %d %s %s", c.Min, c.RoomType, roomNoun(c.Min))
+		case *c.Max == c.Min:
+			return fmt.Sprintf("exactly %d %s %s", c.Min, c.RoomType, roomNoun(c.Min))
+		case c.Min == 0:
+			return fmt.Sprintf("at most %d %s %s", *c.Max, c.RoomType, roomNoun(*c.Max))
+		default:
+			return fmt.Sprintf("%d to %d %s rooms", c.Min, *c.Max, c.RoomType)
+		}
+	case ConstraintFarthest:
+		return fmt.Sprintf("%s room farthest from the %s room", c.RoomType, c.Other)
+	case ConstraintAdjacent:
+		return fmt.Sprintf("at least %d %s %s adjacent to a %s room", c.Min, c.RoomType, roomNoun(c.Min), c.Other)
+	default:
+		return string(c.Kind)
+	}
+}
+
+// roomNoun is "room" or "rooms", as n requires
+func roomNoun(n int) string {
+	if n == 1 {
+		return "room"
+	}
+	return "rooms"
+}
+
+// ParseLayoutConstraint parses the compact form of a constraint used on the
+// command line:
+//
+//	count:<type>:<min>[:<max>]    count:shop:1:1 - exactly one shop
+//	farthest:<type>:<other>       farthest:boss:entrance
+//	adjacent:<type>:<other>[:<n>] adjacent:secret:treasure:2
+//
+// An empty maximum, as in count:combat:3:, leaves the count unbounded, as
+// does leaving it out; adjacent constraints require one room by default.
+func ParseLayoutConstraint(s string) (LayoutConstraint, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 3 {
+		return LayoutConstraint{}, fmt.Errorf("constraint %q: want kind:room_type:...", s)
+	}
+	c := LayoutConstraint{Kind: ConstraintKind(parts[0]), RoomType: RoomType(parts[1])}
+
+	number := func(field, value string) (int, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("constraint %q: invalid %s %q", s, field, value)
+		}
+		return n, nil
+	}
+
+	var err error
+	switch c.Kind {
+	case ConstraintCount:
+		if len(parts) > 4 {
+			return LayoutConstraint{}, fmt.Errorf("constraint %q: want count:type:min[:max]", s)
+		}
+		if c.Min, err = number("minimum", parts[2]); err != nil {
+			return LayoutConstraint{}, err
+		}
+		if len(parts) == 4 && parts[3] != "" {
+			max, err := number("maximum", parts[3])
+			if err != nil {
+				return LayoutConstraint{}, err
+			}
+			c.Max = &max
+		}
+	case ConstraintFarthest:
+		if len(parts) != 3 {
+			return LayoutConstraint{}, fmt.Errorf("constraint %q: want farthest:type:other", s)
+		}
+		c.Other = RoomType(parts[2])
+	case ConstraintAdjacent:
+		if len(parts) > 4 {
+			return LayoutConstraint{}, fmt.Errorf("constraint %q: want adjacent:type:other[:min]", s)
+		}
+		c.Other = RoomType(parts[2])
+		c.Min = 1
+		if len(parts) == 4 {
+			if c.Min, err = number("minimum", parts[3]); err != nil {
+				return LayoutConstraint{}, err
+			}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return LayoutConstraint{}, fmt.Errorf("constraint %q: %w", s, err)
+	}
+	return c, nil
+}
+
+// ConstraintResult tells whether a generated level meets one constraint,
+// and why
+type ConstraintResult struct {
+	Constraint LayoutConstraint `yaml:"constraint" json:"constraint"`           // The constraint checked
+	Satisfied  bool             `yaml:"satisfied" json:"satisfied"`             // Whether the level meets it
+	Detail     string           `yaml:"detail" json:"detail"`                   // What was found
+	Rooms      []string         `yaml:"rooms,omitempty" json:"rooms,omitempty"` // IDs of the rooms concerned
+}
+
+// ConstraintReport is the outcome of enforcing the layout constraints of a
+// level. Generated levels carry it in their "constraints" property.
+type ConstraintReport struct {
+	Results []ConstraintResult `yaml:"results" json:"results"`                     // One result per constraint, in order
+	Repairs []string           `yaml:"repairs,omitempty" json:"repairs,omitempty"` // Room type changes made to meet them
+}
+
+// Satisfied reports whether the level meets every constraint
+func (r ConstraintReport) Satisfied() bool {
+	return len(r.Violations()) == 0
+}
+
+// Violations returns the results of the constraints the level does not meet
+func (r ConstraintReport) Violations() []ConstraintResult {
+	var violations []ConstraintResult
+	for _, result := range r.Results {
+		if !result.Satisfied {
+			violations = append(violations, result)
+		}
+	}
+	return violations
+}
+
+// ConstraintError is returned by level generation when the layout cannot be
+// repaired to meet its constraints. Its report explains each violation.
+type ConstraintError struct {
+	Report ConstraintReport
+}
+
+// Error lists the violated constraints and what was found instead
+func (e *ConstraintError) Error() string {
+	violations := e.Report.Violations()
+	explained := make([]string, len(violations))
+	for i, violation := range violations {
+		explained[i] = fmt.Sprintf("%s: %s", violation.Constraint, violation.Detail)
+	}
+	return fmt.Sprintf("layout constraints violated: %s", strings.Join(explained, "; "))
+}
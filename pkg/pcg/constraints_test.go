@@ -0,0 +1,67 @@
+package pcg
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayoutConstraint(t *testing.T) {
+	one, three := 1, 3
+	tests := []struct {
+		input string
+		want  LayoutConstraint
+		text  string
+	}{
+		{"count:shop:1:1", LayoutConstraint{Kind: ConstraintCount, RoomType: RoomTypeShop, Min: 1, Max: &one}, "exactly 1 shop room"},
+		{"count:combat:3", LayoutConstraint{Kind: ConstraintCount, RoomType: RoomTypeCombat, Min: 3}, "at least 3 combat rooms"},
+		{"count:trap:0:3", LayoutConstraint{Kind: ConstraintCount, RoomType: RoomTypeTrap, Max: &three}, "at most 3 trap rooms"},
+		{"count:rest:1:", LayoutConstraint{Kind: ConstraintCount, RoomType: RoomTypeRest, Min: 1}, "at least 1 rest room"},
+		{"farthest:boss:entrance", LayoutConstraint{Kind: ConstraintFarthest, RoomType: RoomTypeBoss, Other: RoomTypeEntrance}, "boss room farthest from the entrance room"},
+		{"adjacent:secret:treasure:2", LayoutConstraint{Kind: ConstraintAdjacent, RoomType: RoomTypeSecret, Other: RoomTypeTreasure, Min: 2}, "at least 2 secret rooms adjacent to a treasure room"},
+		{"adjacent:rest:shop", LayoutConstraint{Kind: ConstraintAdjacent, RoomType: RoomTypeRest, Other: RoomTypeShop, Min: 1}, "at least 1 rest room adjacent to a shop room"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLayoutConstraint(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.text, got.String())
+		})
+	}
+
+	for _, input := range []string{
+		"count:shop",              // too short
+		"count:shop:two",          // not a number
+		"count:shop:2:1",          // maximum below minimum
+		"count:entrance:1:1",      // fixed room type
+		"count:dragon:1",          // unknown room type
+		"farthest:boss:boss",      // same room types
+		"farthest:boss:exit:1",    // too long
+		"adjacent:secret:vault",   // unknown other room type
+		"adjacent:secret:shop:0",  // needs at least one
+		"nearest:boss:entrance",   // unknown kind
+		"count:shop:1:1:extra:xx", // too long
+	} {
+		_, err := ParseLayoutConstraint(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestConstraintError(t *testing.T) {
+	one := 1
+	report := ConstraintReport{Results: []ConstraintResult{
+		{Constraint: LayoutConstraint{Kind: ConstraintCount, RoomType: RoomTypeShop, Min: 1, Max: &one}, Detail: "found 0"},
+		{Constraint: LayoutConstraint{Kind: ConstraintFarthest, RoomType: RoomTypeBoss, Other: RoomTypeEntrance}, Satisfied: true},
+	}}
+	assert.False(t, report.Satisfied())
+	require.Len(t, report.Violations(), 1)
+
+	err := fmt.Errorf("level validation failed: %w", &ConstraintError{Report: report})
+	var violated *ConstraintError
+	require.True(t, errors.As(err, &violated))
+	assert.Equal(t, "layout constraints violated: exactly 1 shop room: found 0", violated.Error())
+}
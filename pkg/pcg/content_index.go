@@ -0,0 +1,288 @@
+package pcg
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+)
+
+// DefaultContentIndexCapacity is the number of entries a content index
+// keeps before dropping the oldest
+const DefaultContentIndexCapacity = 10000
+
+// Content kinds recorded in a content index. A generated level is indexed
+// along with each of its rooms.
+const (
+	ContentKindTerrain = "terrain"
+	ContentKindItem    = "item"
+	ContentKindLevel   = "level"
+	ContentKindRoom    = "room"
+	ContentKindQuest   = "quest"
+)
+
+// ContentEntry is a generated artifact recorded in a ContentIndex, with the
+// tags it can be searched by
+type ContentEntry struct {
+	ID          string      `json:"id"`                    // Artifact identifier; rooms are "<level id>/<room id>"
+	Kind        string      `json:"kind"`                  // One of the ContentKind constants
+	ContentType ContentType `json:"content_type"`          // Content type that produced the artifact
+	ParentID    string      `json:"parent_id,omitempty"`   // Containing artifact, such as a room's level
+	LocationID  string      `json:"location_id,omitempty"` // Location the artifact was generated for
+	Name        string      `json:"name"`                  // Display name
+	Description string      `json:"description"`           // Searchable description
+	Biome       BiomeType   `json:"biome,omitempty"`       // Biome, for terrain
+	Theme       LevelTheme  `json:"theme,omitempty"`       // Theme, for levels and rooms
+	Difficulty  int         `json:"difficulty"`            // Challenge rating
+	Faction     string      `json:"faction,omitempty"`     // Faction present, if any
+	Rarity      RarityTier  `json:"rarity,omitempty"`      // Rarity, for items
+	Tags        []string    `json:"tags,omitempty"`        // Further tags, such as room or quest type
+	GeneratedAt time.Time   `json:"generated_at"`          // When the artifact was indexed
+}
+
+// ContentQuery selects entries from a ContentIndex. Zero-valued fields do
+// not filter; every set field must match.
+type ContentQuery struct {
+	Kind          string      `json:"kind,omitempty"`
+	ContentType   ContentType `json:"content_type,omitempty"`
+	LocationID    string      `json:"location_id,omitempty"`
+	Biome         BiomeType   `json:"biome,omitempty"`
+	Theme         LevelTheme  `json:"theme,omitempty"`
+	Faction       string      `json:"faction,omitempty"`
+	Rarity        RarityTier  `json:"rarity,omitempty"`
+	Tags          []string    `json:"tags,omitempty"`           // Entries must carry all of these tags
+	MinDifficulty int         `json:"min_difficulty,omitempty"` // Inclusive lower bound
+	MaxDifficulty int         `json:"max_difficulty,omitempty"` // Inclusive upper bound
+	Text          string      `json:"text,omitempty"`           // Words that must all appear in the name, description or tags
+	Limit         int         `json:"limit,omitempty"`          // Maximum results (0 = all)
+}
+
+// ContentIndex records generated content with its tags for search by GM
+// tools. Recording an ID again replaces the earlier entry. It is safe for
+// concurrent use.
+type ContentIndex struct {
+	mu       sync.RWMutex
+	entries  map[string]*ContentEntry
+	order    []string // IDs, oldest first
+	capacity int
+	now      func() time.Time
+}
+
+// NewContentIndex creates a content index holding up to capacity entries,
+// or DefaultContentIndexCapacity when capacity is not positive
+func NewContentIndex(capacity int) *ContentIndex {
+	if capacity <= 0 {
+		capacity = DefaultContentIndexCapacity
+	}
+	return &ContentIndex{
+		entries:  make(map[string]*ContentEntry),
+		capacity: capacity,
+		now:      time.Now,
+	}
+}
+
+// Record adds an entry to the index, replacing any entry with the same ID.
+// The oldest entry is dropped when the index is full.
+func (ci *ContentIndex) Record(entry ContentEntry) {
+	if entry.ID == "" {
+		return
+	}
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	if entry.GeneratedAt.IsZero() {
+		entry.GeneratedAt = ci.now()
+	}
+	if _, exists := ci.entries[entry.ID]; exists {
+		ci.removeLocked(entry.ID)
+	}
+	ci.entries[entry.ID] = &entry
+	ci.order = append(ci.order, entry.ID)
+
+	for len(ci.order) > ci.capacity {
+		delete(ci.entries, ci.order[0])
+		ci.order = ci.order[1:]
+	}
+}
+
+// Query returns the entries matching q, most recently generated first
+func (ci *ContentIndex) Query(q ContentQuery) []ContentEntry {
+	words := strings.Fields(strings.ToLower(q.Text))
+
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+
+	results := make([]ContentEntry, 0)
+	for i := len(ci.order) - 1; i >= 0; i-- {
+		entry := ci.entries[ci.order[i]]
+		if !q.matches(entry, words) {
+			continue
+		}
+		results = append(results, *entry)
+		if q.Limit > 0 && len(results) == q.Limit {
+			break
+		}
+	}
+	return results
+}
+
+// Len returns the number of indexed entries
+func (ci *ContentIndex) Len() int {
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+	return len(ci.order)
+}
+
+// IndexTerrain records terrain generated for a location
+func (ci *ContentIndex) IndexTerrain(locationID string, biome BiomeType, difficulty int, gameMap *game.GameMap) {
+	if gameMap == nil {
+		return
+	}
+	ci.Record(ContentEntry{
+		ID:          "terrain/" + locationID,
+		Kind:        ContentKindTerrain,
+		ContentType: ContentTypeTerrain,
+		LocationID:  locationID,
+		Name:        fmt.Sprintf("%s terrain", biome),
+		Description: fmt.Sprintf("%dx%d %s terrain for %s", gameMap.Width, gameMap.Height, biome, locationID),
+		Biome:       biome,
+		Difficulty:  difficulty,
+	})
+}
+
+// IndexItems records items generated for a location. Item rarity is read
+// from the "rarity:" property set by the item generators.
+func (ci *ContentIndex) IndexItems(locationID string, difficulty int, items []*game.Item) {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		entry := ContentEntry{
+			ID:          item.ID,
+			Kind:        ContentKindItem,
+			ContentType: ContentTypeItems,
+			LocationID:  locationID,
+			Name:        item.Name,
+			Description: strings.TrimSpace(item.Type + " " + strings.Join(item.Properties, " ")),
+			Difficulty:  difficulty,
+			Tags:        []string{item.Type},
+		}
+		for _, property := range item.Properties {
+			if rarity, ok := strings.CutPrefix(property, "rarity:"); ok {
+				entry.Rarity = RarityTier(rarity)
+			}
+		}
+		ci.Record(entry)
+	}
+}
+
+// IndexLevel records a generated level and each room listed in its "rooms"
+// property
+func (ci *ContentIndex) IndexLevel(locationID string, theme LevelTheme, difficulty int, level *game.Level) {
+	if level == nil {
+		return
+	}
+	rooms, _ := level.Properties["rooms"].([]RoomSite)
+
+	ci.Record(ContentEntry{
+		ID:          level.ID,
+		Kind:        ContentKindLevel,
+		ContentType: ContentTypeLevels,
+		LocationID:  locationID,
+		Name:        level.Name,
+		Description: fmt.Sprintf("%s level with %d rooms", theme, len(rooms)),
+		Theme:       theme,
+		Difficulty:  difficulty,
+	})
+
+	for _, room := range rooms {
+		ci.Record(ContentEntry{
+			ID:          level.ID + "/" + room.RoomID,
+			Kind:        ContentKindRoom,
+			ContentType: ContentTypeLevels,
+			ParentID:    level.ID,
+			LocationID:  locationID,
+			Name:        fmt.Sprintf("%s room %s", room.Type, room.RoomID),
+			Description: fmt.Sprintf("%s %s room in %s", theme, room.Type, level.Name),
+			Theme:       theme,
+			Difficulty:  room.Difficulty,
+			Faction:     room.Faction,
+			Tags:        []string{string(room.Type)},
+		})
+	}
+}
+
+// IndexQuest records a quest generated for an area
+func (ci *ContentIndex) IndexQuest(areaID string, questType QuestType, difficulty int, quest *game.Quest) {
+	if quest == nil {
+		return
+	}
+	ci.Record(ContentEntry{
+		ID:          quest.ID,
+		Kind:        ContentKindQuest,
+		ContentType: ContentTypeQuests,
+		LocationID:  areaID,
+		Name:        quest.Title,
+		Description: quest.Description,
+		Difficulty:  difficulty,
+		Tags:        []string{string(questType)},
+	})
+}
+
+// removeLocked drops an entry; the caller holds the write lock
+func (ci *ContentIndex) removeLocked(id string) {
+	delete(ci.entries, id)
+	for i, existing := range ci.order {
+		if existing == id {
+			ci.order = append(ci.order[:i], ci.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// matches reports whether entry passes every filter of q. words are the
+// lowercased words of q.Text.
+func (q ContentQuery) matches(entry *ContentEntry, words []string) bool {
+	switch {
+	case q.Kind != "" && entry.Kind != q.Kind,
+		q.ContentType != "" && entry.ContentType != q.ContentType,
+		q.LocationID != "" && entry.LocationID != q.LocationID,
+		q.Biome != "" && entry.Biome != q.Biome,
+		q.Theme != "" && entry.Theme != q.Theme,
+		q.Faction != "" && entry.Faction != q.Faction,
+		q.Rarity != "" && entry.Rarity != q.Rarity,
+		q.MinDifficulty > 0 && entry.Difficulty < q.MinDifficulty,
+		q.MaxDifficulty > 0 && entry.Difficulty > q.MaxDifficulty:
+		return false
+	}
+
+	for _, tag := range q.Tags {
+		if !containsFold(entry.Tags, tag) {
+			return false
+		}
+	}
+
+	if len(words) == 0 {
+		return true
+	}
+	text := strings.ToLower(entry.Name + " " + entry.Description + " " + strings.Join(entry.Tags, " "))
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether values holds value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contentIDs returns the IDs of entries in order
+func contentIDs(entries []ContentEntry) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+func TestContentIndex_QueryFilters(t *testing.T) {
+	index := NewContentIndex(0)
+	index.IndexLevel("crypt", ThemeUndead, 9, &game.Level{
+		ID:   "crypt_1",
+		Name: "Bone Halls",
+		Properties: map[string]interface{}{"rooms": []RoomSite{
+			{RoomID: "r1", Type: RoomTypeEntrance, Difficulty: 2},
+			{RoomID: "r2", Type: RoomTypeBoss, Difficulty: 9},
+			{RoomID: "r3", Type: RoomTypeShop, Difficulty: 3, Faction: "merchants_guild"},
+		}},
+	})
+	index.IndexLevel("keep", ThemeClassic, 9, &game.Level{
+		ID:         "keep_1",
+		Name:       "Old Keep",
+		Properties: map[string]interface{}{"rooms": []RoomSite{{RoomID: "r1", Type: RoomTypeBoss, Difficulty: 8}}},
+	})
+	index.IndexItems("crypt", 4, []*game.Item{
+		{ID: "item_1", Name: "Frost Brand", Type: "weapon", Properties: []string{"slashing", "rarity:epic"}},
+		{ID: "item_2", Name: "Leather Cap", Type: "armor", Properties: []string{"rarity:common"}},
+	})
+	index.IndexTerrain("moor", BiomeSwamp, 3, &game.GameMap{Width: 20, Height: 10})
+	index.IndexQuest("crypt", QuestTypeKill, 6, &game.Quest{ID: "quest_1", Title: "Silence the Lich", Description: "Destroy the lich below the chapel"})
+
+	assert.Equal(t, 10, index.Len())
+	assert.Equal(t, []string{"crypt_1/r2"}, contentIDs(index.Query(ContentQuery{
+		Kind: ContentKindRoom, Theme: ThemeUndead, Tags: []string{"boss"}, MinDifficulty: 8,
+	})))
+	assert.Equal(t, []string{"keep_1/r1", "crypt_1/r2"}, contentIDs(index.Query(ContentQuery{Tags: []string{"BOSS"}})))
+	assert.Equal(t, []string{"crypt_1/r3"}, contentIDs(index.Query(ContentQuery{Faction: "merchants_guild"})))
+	assert.Equal(t, []string{"item_1"}, contentIDs(index.Query(ContentQuery{Rarity: RarityEpic})))
+	assert.Equal(t, []string{"terrain/moor"}, contentIDs(index.Query(ContentQuery{Biome: BiomeSwamp})))
+	assert.Equal(t, []string{"quest_1"}, contentIDs(index.Query(ContentQuery{Text: "lich CHAPEL"})))
+	assert.Empty(t, index.Query(ContentQuery{Text: "lich dragon"}))
+	assert.Equal(t, []string{"crypt_1/r3", "crypt_1/r1"}, contentIDs(index.Query(ContentQuery{LocationID: "crypt", ContentType: ContentTypeLevels, MaxDifficulty: 3})))
+	assert.Len(t, index.Query(ContentQuery{Kind: ContentKindRoom, Limit: 2}), 2)
+}
+
+func TestContentIndex_ReplacesAndEvicts(t *testing.T) {
+	index := NewContentIndex(2)
+	index.Record(ContentEntry{ID: "a", Name: "first"})
+	index.Record(ContentEntry{ID: "b"})
+	index.Record(ContentEntry{ID: "a", Name: "second"})
+
+	entries := index.Query(ContentQuery{})
+	assert.Equal(t, []string{"a", "b"}, contentIDs(entries), "a re-recorded entry becomes the newest")
+	assert.Equal(t, "second", entries[0].Name)
+	assert.False(t, entries[0].GeneratedAt.IsZero())
+
+	index.Record(ContentEntry{ID: "c"})
+	index.Record(ContentEntry{})
+	assert.Equal(t, []string{"c", "a"}, contentIDs(index.Query(ContentQuery{})))
+}
+
+func TestPCGManager_IndexesGeneratedContent(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	require.NoError(t, manager.GetRegistry().RegisterGenerator("objective_based", NewQuestGenerator(logrus.New())))
+	defer manager.GetJobQueue().Close()
+
+	quest, err := manager.GenerateQuestForArea(context.Background(), "old_mill", QuestTypeFetch, 3)
+	require.NoError(t, err)
+
+	entries := manager.GetContentIndex().Query(ContentQuery{Kind: ContentKindQuest, LocationID: "old_mill"})
+	require.Len(t, entries, 1)
+	assert.Equal(t, quest.ID, entries[0].ID)
+	assert.Equal(t, quest.Title, entries[0].Name)
+	assert.Contains(t, entries[0].Tags, string(QuestTypeFetch))
+
+	_, err = manager.GenerateTerrainForLevel(context.Background(), "nowhere", 10, 10, BiomeCave, 3)
+	require.Error(t, err, "no terrain generator is registered")
+	assert.Equal(t, 1, manager.GetContentIndex().Len(), "failed generations are not indexed")
+}
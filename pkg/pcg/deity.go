@@ -0,0 +1,373 @@
+package pcg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DeityGenerator creates the pantheon of a world: deities with domains,
+// dogma, favored weapons and holy symbols, shaped by the genre variant.
+// Grimdark pantheons are small and mostly cruel, high magic ones large and
+// arcane. Every pantheon has at least one good deity for paladins to serve.
+type DeityGenerator struct {
+	version string
+	logger  *logrus.Logger
+}
+
+// pantheonProfile defines how a genre shapes its pantheon
+type pantheonProfile struct {
+	minDeities int
+	maxDeities int
+	// alignmentWeights are the relative chances of good, neutral and evil
+	alignmentWeights [3]int
+	domains          []string
+	titles           []string
+}
+
+// pantheonProfiles holds the pantheon shape of every genre variant
+var pantheonProfiles = map[GenreType]pantheonProfile{
+	GenreClassicFantasy: {
+		minDeities:       5,
+		maxDeities:       7,
+		alignmentWeights: [3]int{4, 3, 3},
+		domains:          []string{"war", "healing", "knowledge", "nature", "trickery", "light", "death", "tempest", "forge"},
+		titles:           []string{"the Radiant", "the Just", "Lord of %s", "Keeper of %s", "the Watcher", "Mother of %s"},
+	},
+	GenreGrimdark: {
+		minDeities:       3,
+		maxDeities:       5,
+		alignmentWeights: [3]int{1, 2, 5},
+		domains:          []string{"death", "war", "plague", "decay", "trickery", "tempest", "blood", "shadow"},
+		titles:           []string{"the Hungering", "the Pale", "Lord of %s", "the Last Mercy", "Bringer of %s"},
+	},
+	GenreHighMagic: {
+		minDeities:       6,
+		maxDeities:       9,
+		alignmentWeights: [3]int{3, 3, 2},
+		domains:          []string{"arcane", "knowledge", "light", "tempest", "nature", "stars", "time", "healing", "forge"},
+		titles:           []string{"the Luminous", "Weaver of %s", "the Eternal", "Sovereign of %s", "the Ever-Turning"},
+	},
+	GenreLowFantasy: {
+		minDeities:       2,
+		maxDeities:       4,
+		alignmentWeights: [3]int{3, 4, 2},
+		domains:          []string{"harvest", "hearth", "war", "death", "nature", "sea"},
+		titles:           []string{"the Old Father", "the Grey Mother", "Warden of %s", "the Quiet One"},
+	},
+}
+
+// deityDomain describes what serving a domain means
+type deityDomain struct {
+	weapon string
+	emblem string
+	tenets []string
+}
+
+// deityDomains holds the weapon, emblem and tenets of every domain
+var deityDomains = map[string]deityDomain{
+	"war":       {"longsword", "crossed blades", []string{"Face every foe without fear", "Victory belongs to the prepared"}},
+	"healing":   {"mace", "open hand", []string{"Mend the wounded, whoever they are", "Suffering is never deserved"}},
+	"knowledge": {"quarterstaff", "unfurled scroll", []string{"Record what you learn", "Ignorance is the first enemy"}},
+	"nature":    {"scimitar", "oak leaf", []string{"Take only what the land can spare", "Protect the wild places"}},
+	"trickery":  {"dagger", "masked face", []string{"Every rule has a loophole", "Laugh at the proud"}},
+	"light":     {"morningstar", "rising sun", []string{"Bring light to dark places", "Destroy the undead"}},
+	"death":     {"scythe", "skull", []string{"All things end in their time", "Honor the dead and their rest"}},
+	"tempest":   {"warhammer", "lightning bolt", []string{"Fear the sky and respect the sea", "Strike like the storm"}},
+	"forge":     {"warhammer", "anvil", []string{"Craft with patience", "A well-made thing outlasts its maker"}},
+	"plague":    {"flail", "weeping eye", []string{"Sickness culls the weak", "Spread the gift of rot"}},
+	"decay":     {"sickle", "withered rose", []string{"Everything rots", "Hasten the end of what is old"}},
+	"blood":     {"greataxe", "crimson drop", []string{"Power is paid for in blood", "Offer the fallen to the altar"}},
+	"shadow":    {"shortsword", "black moon", []string{"Secrets are strength", "Walk where no light reaches"}},
+	"arcane":    {"quarterstaff", "eight-pointed star", []string{"Magic is to be mastered", "Share the art with the worthy"}},
+	"stars":     {"spear", "silver comet", []string{"Read the omens in the sky", "Guide the lost home"}},
+	"time":      {"halberd", "hourglass", []string{"Nothing is wasted but time", "Keep the old ways and the new"}},
+	"harvest":   {"sickle", "sheaf of wheat", []string{"Share the harvest", "Work the land and it feeds you"}},
+	"hearth":    {"club", "burning hearth", []string{"Guard home and kin", "Welcome the stranger at the door"}},
+	"sea":       {"trident", "cresting wave", []string{"Pay the sea its due", "Never abandon a ship in need"}},
+}
+
+// alignmentTenets holds a tenet every deity of an alignment shares
+var alignmentTenets = map[game.DeityAlignment]string{
+	game.DeityGood:    "Protect those who cannot protect themselves",
+	game.DeityNeutral: "Keep the balance between all things",
+	game.DeityEvil:    "The strong rule and the weak obey",
+}
+
+// symbolColors are the colors of holy symbols
+var symbolColors = []string{"golden", "silver", "iron", "black", "white", "crimson", "azure", "green"}
+
+// deityNameParts are the syllables deity names are built from
+var deityNameParts = [2][]string{
+	{"Aer", "Bal", "Cor", "Dun", "El", "Hes", "Ith", "Kor", "Lath", "Mor", "Ny", "Or", "Sel", "Tyr", "Ul", "Vor", "Yl", "Zar"},
+	{"ath", "ene", "ion", "ira", "oth", "us", "andra", "gar", "mir", "os", "une", "eth", "ara", "ul"},
+}
+
+// NewDeityGenerator creates a new deity generator instance
+func NewDeityGenerator(logger *logrus.Logger) *DeityGenerator {
+	if logger == nil {
+		logger = logrus.New()
+	}
+
+	return &DeityGenerator{
+		version: "1.0.0",
+		logger:  logger,
+	}
+}
+
+// Generate creates a pantheon for the genre in the "genre" constraint,
+// classic fantasy by default. Returns a *game.Pantheon.
+func (dg *DeityGenerator) Generate(ctx context.Context, params GenerationParams) (interface{}, error) {
+	if err := dg.Validate(params); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	genre := GenreClassicFantasy
+	if name, ok := params.Constraints["genre"].(string); ok && name != "" {
+		genre = GenreType(name)
+	}
+	profile := pantheonProfiles[genre]
+	rng := NewRNG(params.Seed, "deities")
+
+	count := profile.minDeities + rng.Intn(profile.maxDeities-profile.minDeities+1)
+	pantheon := &game.Pantheon{Genre: string(genre), Deities: make([]game.Deity, 0, count)}
+	names := make(map[string]bool, count)
+	for i := 0; i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pantheon.Deities = append(pantheon.Deities, dg.generateDeity(profile, names, rng))
+	}
+
+	// Paladins need a good deity to serve
+	if len(pantheon.Patrons(game.ClassPaladin)) == 0 {
+		dg.setAlignment(&pantheon.Deities[0], game.DeityGood)
+	}
+
+	dg.logger.WithFields(logrus.Fields{
+		"seed":    params.Seed,
+		"genre":   genre,
+		"deities": len(pantheon.Deities),
+	}).Info("generated pantheon")
+
+	return pantheon, nil
+}
+
+// generateDeity creates one deity of a pantheon with a name not in names
+func (dg *DeityGenerator) generateDeity(profile pantheonProfile, names map[string]bool, rng *rand.Rand) game.Deity {
+	name := dg.generateName(rng)
+	for attempts := 0; names[name] && attempts < 20; attempts++ {
+		name = dg.generateName(rng)
+	}
+	if names[name] {
+		name = fmt.Sprintf("%s %s", name, romanNumeral(len(names)+1))
+	}
+	names[name] = true
+
+	domainCount := 1 + rng.Intn(2)
+	domains := make([]string, 0, domainCount)
+	for _, index := range rng.Perm(len(profile.domains))[:domainCount] {
+		domains = append(domains, profile.domains[index])
+	}
+	primary := deityDomains[domains[0]]
+
+	title := profile.titles[rng.Intn(len(profile.titles))]
+	if strings.Contains(title, "%s") {
+		title = fmt.Sprintf(title, strings.ToUpper(domains[0][:1])+domains[0][1:])
+	}
+
+	deity := game.Deity{
+		ID:            "deity_" + strings.ToLower(strings.ReplaceAll(name, " ", "_")),
+		Name:          name,
+		Title:         title,
+		Domains:       domains,
+		FavoredWeapon: primary.weapon,
+		HolySymbol:    fmt.Sprintf("%s %s", symbolColors[rng.Intn(len(symbolColors))], primary.emblem),
+	}
+	for _, domain := range domains {
+		tenets := deityDomains[domain].tenets
+		deity.Dogma = append(deity.Dogma, tenets[rng.Intn(len(tenets))])
+	}
+	dg.setAlignment(&deity, dg.chooseAlignment(profile, rng))
+	return deity
+}
+
+// setAlignment sets the alignment of deity and the matching tenet, which is
+// always the last of its dogma
+func (dg *DeityGenerator) setAlignment(deity *game.Deity, alignment game.DeityAlignment) {
+	if deity.Alignment != "" && len(deity.Dogma) > 0 {
+		deity.Dogma = deity.Dogma[:len(deity.Dogma)-1]
+	}
+	deity.Alignment = alignment
+	deity.Dogma = append(deity.Dogma, alignmentTenets[alignment])
+}
+
+// chooseAlignment picks an alignment by the profile's weights
+func (dg *DeityGenerator) chooseAlignment(profile pantheonProfile, rng *rand.Rand) game.DeityAlignment {
+	alignments := [3]game.DeityAlignment{game.DeityGood, game.DeityNeutral, game.DeityEvil}
+	total := 0
+	for _, weight := range profile.alignmentWeights {
+		total += weight
+	}
+	roll := rng.Intn(total)
+	for i, weight := range profile.alignmentWeights {
+		if roll < weight {
+			return alignments[i]
+		}
+		roll -= weight
+	}
+	return game.DeityNeutral
+}
+
+// generateName builds a deity name from two syllables
+func (dg *DeityGenerator) generateName(rng *rand.Rand) string {
+	return deityNameParts[0][rng.Intn(len(deityNameParts[0]))] + deityNameParts[1][rng.Intn(len(deityNameParts[1]))]
+}
+
+// romanNumeral returns n (1-10) as a roman numeral
+func romanNumeral(n int) string {
+	numerals := []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X"}
+	if n < 1 || n > len(numerals) {
+		return fmt.Sprint(n)
+	}
+	return numerals[n-1]
+}
+
+// GetType returns the content type for deity generation
+func (dg *DeityGenerator) GetType() ContentType {
+	return ContentTypeDeities
+}
+
+// GetVersion returns the generator version
+func (dg *DeityGenerator) GetVersion() string {
+	return dg.version
+}
+
+// Validate checks if the provided parameters are valid for deity generation
+func (dg *DeityGenerator) Validate(params GenerationParams) error {
+	if params.Seed == 0 {
+		return fmt.Errorf("seed cannot be zero")
+	}
+
+	if genre, ok := params.Constraints["genre"].(string); ok && genre != "" {
+		if _, known := pantheonProfiles[GenreType(genre)]; !known {
+			return fmt.Errorf("unknown genre %q", genre)
+		}
+	}
+
+	return nil
+}
+
+// SettlementTemple is a temple of a deity in a settlement
+type SettlementTemple struct {
+	DeityID string   `json:"deity_id"`
+	Name    string   `json:"name"`
+	Rooms   []string `json:"rooms"`
+}
+
+// templeRooms are the rooms every temple has
+var templeRooms = []string{"nave", "altar", "vestry"}
+
+// domainRooms are the rooms a temple adds for its deity's domains
+var domainRooms = map[string]string{
+	"war":       "armory",
+	"healing":   "infirmary",
+	"knowledge": "scriptorium",
+	"nature":    "sacred grove",
+	"trickery":  "hidden chapel",
+	"light":     "sun court",
+	"death":     "ossuary",
+	"tempest":   "bell tower",
+	"forge":     "holy forge",
+	"plague":    "quarantine ward",
+	"decay":     "crypt",
+	"blood":     "sacrificial pit",
+	"shadow":    "undercroft",
+	"arcane":    "ritual circle",
+	"stars":     "observatory",
+	"time":      "clock chamber",
+	"harvest":   "granary",
+	"hearth":    "common hall",
+	"sea":       "shrine of tides",
+}
+
+// PlaceTemples dedicates the temples of settlements offering the temple
+// service to deities of pantheon. Deities are assigned in turn from a
+// seeded shuffle, so every deity gets a temple before any gets a second.
+// Each temple has the common rooms plus one per domain of its deity.
+func PlaceTemples(world *GeneratedWorld, pantheon *game.Pantheon, seed int64) {
+	if world == nil || pantheon == nil || len(pantheon.Deities) == 0 {
+		return
+	}
+
+	rng := NewRNG(seed, "temples")
+	order := rng.Perm(len(pantheon.Deities))
+	next := 0
+	for _, settlement := range world.Settlements {
+		if !settlement.hasService(ServiceTemple) {
+			continue
+		}
+		deity := pantheon.Deities[order[next%len(order)]]
+		next++
+
+		rooms := append([]string(nil), templeRooms...)
+		for _, domain := range deity.Domains {
+			if room, ok := domainRooms[domain]; ok {
+				rooms = append(rooms, room)
+			}
+		}
+		settlement.Temples = append(settlement.Temples, SettlementTemple{
+			DeityID: deity.ID,
+			Name:    fmt.Sprintf("Temple of %s", deity.Name),
+			Rooms:   rooms,
+		})
+	}
+}
+
+// hasService reports whether the settlement offers service
+func (s *Settlement) hasService(service ServiceType) bool {
+	for _, offered := range s.Services {
+		if offered == service {
+			return true
+		}
+	}
+	return false
+}
+
+// GeneratePantheon generates the pantheon of the world for the manager's
+// genre variant. The pantheon depends only on the world seed and genre.
+func (pcg *PCGManager) GeneratePantheon(ctx context.Context) (*game.Pantheon, error) {
+	startTime := time.Now()
+
+	params := GenerationParams{
+		Seed:        pcg.seedManager.VersionedContextSeed(ContentTypeDeities, "pantheon"),
+		Difficulty:  1,
+		WorldState:  pcg.world,
+		Timeout:     5 * time.Second,
+		Constraints: make(map[string]interface{}),
+		Progress:    ProgressFromContext(ctx),
+	}
+	if genre := pcg.getGenre(); genre != "" {
+		params.Constraints["genre"] = string(genre)
+	}
+
+	content, err := pcg.registry.GenerateContent(ctx, ContentTypeDeities, "default", params)
+	var pantheon *game.Pantheon
+	if err == nil {
+		var ok bool
+		if pantheon, ok = content.(*game.Pantheon); !ok {
+			err = fmt.Errorf("deity generator returned %T, expected *game.Pantheon", content)
+		}
+	}
+	if err == nil {
+		params.ReportProgress(ContentTypeDeities, ProgressStageComplete, 100)
+	}
+	pcg.recordGeneration(ContentTypeDeities, pantheon, time.Since(startTime), err)
+	return pantheon, err
+}
@@ -0,0 +1,117 @@
+package pcg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+)
+
+func TestDeityGenerator_Generate(t *testing.T) {
+	dg := NewDeityGenerator(nil)
+
+	for genre, profile := range pantheonProfiles {
+		t.Run(string(genre), func(t *testing.T) {
+			params := GenerationParams{Seed: 42, Difficulty: 1, Constraints: map[string]interface{}{"genre": string(genre)}}
+			content, err := dg.Generate(context.Background(), params)
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+			pantheon := content.(*game.Pantheon)
+
+			if pantheon.Genre != string(genre) {
+				t.Errorf("expected genre %s, got %s", genre, pantheon.Genre)
+			}
+			if n := len(pantheon.Deities); n < profile.minDeities || n > profile.maxDeities {
+				t.Errorf("expected %d-%d deities, got %d", profile.minDeities, profile.maxDeities, n)
+			}
+			if len(pantheon.Patrons(game.ClassPaladin)) == 0 {
+				t.Error("pantheon has no good deity for paladins")
+			}
+
+			ids := make(map[string]bool)
+			for _, deity := range pantheon.Deities {
+				if ids[deity.ID] {
+					t.Errorf("duplicate deity ID %s", deity.ID)
+				}
+				ids[deity.ID] = true
+				if len(deity.Domains) == 0 || deity.FavoredWeapon == "" || deity.HolySymbol == "" || deity.Title == "" {
+					t.Errorf("incomplete deity %+v", deity)
+				}
+				if len(deity.Dogma) != len(deity.Domains)+1 {
+					t.Errorf("expected a tenet per domain plus one for the alignment, got %v", deity.Dogma)
+				}
+			}
+
+			again, _ := dg.Generate(context.Background(), params)
+			if !reflect.DeepEqual(pantheon, again) {
+				t.Error("same seed produced a different pantheon")
+			}
+		})
+	}
+}
+
+func TestDeityGenerator_Validate(t *testing.T) {
+	dg := NewDeityGenerator(nil)
+
+	if err := dg.Validate(GenerationParams{Seed: 0}); err == nil {
+		t.Error("expected error for zero seed")
+	}
+	if err := dg.Validate(GenerationParams{Seed: 1, Constraints: map[string]interface{}{"genre": "space_opera"}}); err == nil {
+		t.Error("expected error for unknown genre")
+	}
+	if err := dg.Validate(GenerationParams{Seed: 1}); err != nil {
+		t.Errorf("unexpected error without genre: %v", err)
+	}
+}
+
+func TestPlaceTemples(t *testing.T) {
+	pantheon := &game.Pantheon{Deities: []game.Deity{
+		{ID: "deity_a", Name: "A", Domains: []string{"war"}},
+		{ID: "deity_b", Name: "B", Domains: []string{"death", "shadow"}},
+	}}
+	world := &GeneratedWorld{Settlements: []*Settlement{
+		{ID: "s1", Services: []ServiceType{ServiceTemple}},
+		{ID: "s2", Services: []ServiceType{ServiceInn}},
+		{ID: "s3", Services: []ServiceType{ServiceShop, ServiceTemple}},
+	}}
+
+	PlaceTemples(world, pantheon, 7)
+
+	if len(world.Settlements[1].Temples) != 0 {
+		t.Error("settlement without the temple service got a temple")
+	}
+	served := make(map[string]bool)
+	for _, settlement := range []*Settlement{world.Settlements[0], world.Settlements[2]} {
+		if len(settlement.Temples) != 1 {
+			t.Fatalf("expected one temple in %s, got %d", settlement.ID, len(settlement.Temples))
+		}
+		temple := settlement.Temples[0]
+		served[temple.DeityID] = true
+		deity, _ := pantheon.Get(temple.DeityID)
+		if len(temple.Rooms) != len(templeRooms)+len(deity.Domains) {
+			t.Errorf("expected common and domain rooms, got %v", temple.Rooms)
+		}
+	}
+	if len(served) != 2 {
+		t.Errorf("expected every deity to get a temple, got %v", served)
+	}
+}
+
+func TestPCGManager_GeneratePantheon(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	manager.InitializeWithSeed(99)
+	if err := manager.RegisterDefaultGenerators(); err != nil {
+		t.Fatalf("RegisterDefaultGenerators failed: %v", err)
+	}
+	manager.SetGenre(GenreGrimdark)
+
+	pantheon, err := manager.GeneratePantheon(context.Background())
+	if err != nil {
+		t.Fatalf("GeneratePantheon failed: %v", err)
+	}
+	if pantheon.Genre != string(GenreGrimdark) {
+		t.Errorf("expected the manager's genre, got %s", pantheon.Genre)
+	}
+}
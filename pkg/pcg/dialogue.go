@@ -154,7 +154,7 @@ func (dg *DialogueGenerator) Generate(ctx context.Context, params GenerationPara
 
 	// Set up RNG with seed for deterministic generation
 	if params.Seed != 0 {
-		dg.rng = rand.New(rand.NewSource(params.Seed))
+		dg.rng = NewRNG(params.Seed, "dialogue")
 	}
 
 	// Extract dialogue-specific parameters
@@ -428,6 +428,13 @@ func (dg *DialogueGenerator) applyPersonalityToText(text string, personality Per
 		text = dg.makeTextCasual(text)
 	}
 
+	// Open with a remark in the character's voice
+	if personality.Speech.Diction != "" && dg.rng.Float64() < 0.4 {
+		if interjection := voiceInterjection(personality.Speech.Diction, dg.rng.Intn); interjection != "" {
+			text = interjection + " " + text
+		}
+	}
+
 	// Add catchphrase if present
 	if personality.Speech.Catchphrase != "" && dg.rng.Float64() < 0.3 {
 		text = text + " " + personality.Speech.Catchphrase
@@ -472,8 +479,12 @@ func (dg *DialogueGenerator) applyTraitToText(text, traitName string) string {
 
 	if strings.Contains(lowerTrait, "arrogant") {
 		text = "Obviously, " + strings.ToLower(text[:1]) + text[1:]
-	} else if strings.Contains(lowerTrait, "nervous") {
+	} else if strings.Contains(lowerTrait, "nervous") || strings.Contains(lowerTrait, "cowardly") {
 		text = text + "... if you don't mind me saying."
+	} else if strings.Contains(lowerTrait, "greedy") {
+		text = text + " For the right price, of course."
+	} else if strings.Contains(lowerTrait, "deceitful") {
+		text = "Trust me. " + text
 	} else if strings.Contains(lowerTrait, "cheerful") {
 		text = text + "!"
 	}
@@ -671,7 +682,7 @@ func (dg *DialogueGenerator) getPersonalityKey(personality PersonalityProfile) s
 		return "hostile"
 	} else if formality > 0.7 {
 		return "formal"
-	} else if dg.hasTraitType(personality, "mysterious") {
+	} else if dg.hasTraitType(personality, "mysterious") || dg.getTraitIntensity(personality, "deceitful") > 0.7 {
 		return "mysterious"
 	}
 	return "casual"
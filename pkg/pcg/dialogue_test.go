@@ -426,6 +426,16 @@ func TestDialogueGenerator_TextTransformations(t *testing.T) {
 			},
 			contains: "Obviously",
 		},
+		{
+			name:      "GreedyTrait_PriceAdded",
+			inputText: "I know the way.",
+			personality: PersonalityProfile{
+				Traits: []PersonalityTrait{
+					{Name: "greedy", Intensity: 0.9, Description: "Very greedy"},
+				},
+			},
+			contains: "For the right price",
+		},
 	}
 
 	for _, tc := range tests {
@@ -436,6 +446,19 @@ func TestDialogueGenerator_TextTransformations(t *testing.T) {
 	}
 }
 
+func TestDialogueGenerator_VoiceInterjection(t *testing.T) {
+	generator := NewDialogueGenerator(logrus.New())
+	personality := PersonalityProfile{Speech: SpeechPattern{Diction: "archaic"}}
+
+	opened := false
+	for i := 0; i < 50 && !opened; i++ {
+		result := generator.applyPersonalityToText("The road is long.", personality)
+		assert.True(t, strings.HasSuffix(result, "The road is long."))
+		opened = strings.HasPrefix(result, "Hark!") || strings.HasPrefix(result, "Verily.")
+	}
+	assert.True(t, opened, "archaic speakers open with archaic remarks")
+}
+
 func TestDialogueGenerator_DialogueTypeInference(t *testing.T) {
 	generator := NewDialogueGenerator(logrus.New())
 
@@ -0,0 +1,406 @@
+package pcg
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxReportedTileChanges caps the individual tile changes listed in a
+// TileDiff; the counts always cover every tile.
+const MaxReportedTileChanges = 100
+
+// DiffKind describes how an entry changed between two versions of content
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// DiffGenerator shows what a parameter change would do to content that has
+// already been generated. It regenerates content with the manager's
+// generators under the new parameters and compares it with the stored
+// version. Regenerated content is discarded: it is not integrated into the
+// world, recorded in the manager's metrics or reported to observers.
+//
+// Pass the seed the stored content was generated with in the parameters to
+// isolate the effect of the other parameters.
+type DiffGenerator struct {
+	manager *PCGManager
+}
+
+// ContentDiff is the change summary for one piece of content. Only the
+// section for the content type is set: Tiles for terrain and levels, Items
+// for items and Quest for quests.
+type ContentDiff struct {
+	ContentType ContentType `json:"content_type"`
+	Generator   string      `json:"generator"`
+	Seed        int64       `json:"seed"`
+	Tiles       *TileDiff   `json:"tiles,omitempty"`
+	Items       *ItemDiff   `json:"items,omitempty"`
+	Quest       *QuestDiff  `json:"quest,omitempty"`
+}
+
+// TileDiff compares two tile grids. Tiles are compared where the grids
+// overlap; a change of size is reported by the dimensions.
+type TileDiff struct {
+	OldWidth           int          `json:"old_width"`
+	OldHeight          int          `json:"old_height"`
+	NewWidth           int          `json:"new_width"`
+	NewHeight          int          `json:"new_height"`
+	Compared           int          `json:"compared"`            // Tiles present in both grids
+	Changed            int          `json:"changed"`             // Compared tiles that differ
+	WalkabilityChanged int          `json:"walkability_changed"` // Changed tiles that became walkable or blocked
+	Changes            []TileChange `json:"changes"`             // First MaxReportedTileChanges changed tiles
+}
+
+// TileChange describes one changed tile
+type TileChange struct {
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+	Old         string `json:"old"`
+	New         string `json:"new"`
+	OldWalkable bool   `json:"old_walkable"`
+	NewWalkable bool   `json:"new_walkable"`
+}
+
+// ItemDiff compares two item lists. Generated item IDs are random, so items
+// are matched by their position in the list.
+type ItemDiff struct {
+	OldCount int              `json:"old_count"`
+	NewCount int              `json:"new_count"`
+	Changes  []ItemStatChange `json:"changes"`
+}
+
+// ItemStatChange describes an item added or removed at Index, or one stat of
+// the item at Index that changed. Stat is empty for added and removed items.
+type ItemStatChange struct {
+	Index int         `json:"index"`
+	Kind  DiffKind    `json:"kind"`
+	Item  string      `json:"item"` // Name of the new item, or of the old one if removed
+	Stat  string      `json:"stat,omitempty"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// QuestDiff compares two quests. Objectives are matched by position.
+type QuestDiff struct {
+	OldTitle       string            `json:"old_title"`
+	NewTitle       string            `json:"new_title"`
+	Objectives     []ObjectiveChange `json:"objectives"`
+	RewardsChanged bool              `json:"rewards_changed"`
+}
+
+// ObjectiveChange describes an objective added, removed or changed at Index
+type ObjectiveChange struct {
+	Index       int      `json:"index"`
+	Kind        DiffKind `json:"kind"`
+	Old         string   `json:"old,omitempty"`
+	New         string   `json:"new,omitempty"`
+	OldRequired int      `json:"old_required,omitempty"`
+	NewRequired int      `json:"new_required,omitempty"`
+}
+
+// DiffGenerator returns a diff generator using the manager's generators
+func (pcg *PCGManager) DiffGenerator() *DiffGenerator {
+	return &DiffGenerator{manager: pcg}
+}
+
+// DiffTerrain regenerates terrain under params and compares it with stored
+func (dg *DiffGenerator) DiffTerrain(ctx context.Context, stored *game.GameMap, generatorName string, params TerrainParams) (*ContentDiff, error) {
+	params.Constraints = withEmbeddedParams(params.Constraints, "terrain_params", func(constraints map[string]interface{}) interface{} {
+		embedded := params
+		embedded.Constraints = constraints
+		return embedded
+	})
+
+	regenerated, err := dg.manager.factory.GenerateTerrain(ctx, generatorName, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate terrain: %w", err)
+	}
+
+	diff := &ContentDiff{ContentType: ContentTypeTerrain, Generator: generatorName, Seed: params.Seed, Tiles: CompareTerrain(stored, regenerated)}
+	dg.logDiff(diff)
+	return diff, nil
+}
+
+// DiffLevel regenerates a level under params and compares its tiles with
+// stored
+func (dg *DiffGenerator) DiffLevel(ctx context.Context, stored *game.Level, generatorName string, params LevelParams) (*ContentDiff, error) {
+	params.Constraints = withEmbeddedParams(params.Constraints, "level_params", func(constraints map[string]interface{}) interface{} {
+		embedded := params
+		embedded.Constraints = constraints
+		return embedded
+	})
+
+	regenerated, err := dg.manager.factory.GenerateLevel(ctx, generatorName, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate level: %w", err)
+	}
+
+	diff := &ContentDiff{ContentType: ContentTypeLevels, Generator: generatorName, Seed: params.Seed, Tiles: CompareLevelTiles(stored, regenerated)}
+	dg.logDiff(diff)
+	return diff, nil
+}
+
+// DiffItems regenerates items under params and compares their stats with
+// stored
+func (dg *DiffGenerator) DiffItems(ctx context.Context, stored []*game.Item, generatorName string, params ItemParams) (*ContentDiff, error) {
+	regenerated, err := dg.manager.factory.GenerateItems(ctx, generatorName, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate items: %w", err)
+	}
+
+	diff := &ContentDiff{ContentType: ContentTypeItems, Generator: generatorName, Seed: params.Seed, Items: CompareItems(stored, regenerated)}
+	dg.logDiff(diff)
+	return diff, nil
+}
+
+// DiffQuest regenerates a quest under params and compares its objectives
+// with stored
+func (dg *DiffGenerator) DiffQuest(ctx context.Context, stored *game.Quest, generatorName string, params QuestParams) (*ContentDiff, error) {
+	params.Constraints = withEmbeddedParams(params.Constraints, "quest_params", func(constraints map[string]interface{}) interface{} {
+		embedded := params
+		embedded.Constraints = constraints
+		return embedded
+	})
+
+	regenerated, err := dg.manager.factory.GenerateQuest(ctx, generatorName, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate quest: %w", err)
+	}
+
+	diff := &ContentDiff{ContentType: ContentTypeQuests, Generator: generatorName, Seed: params.Seed, Quest: CompareQuests(stored, regenerated)}
+	dg.logDiff(diff)
+	return diff, nil
+}
+
+// logDiff logs the summary of a computed diff
+func (dg *DiffGenerator) logDiff(diff *ContentDiff) {
+	dg.manager.logger.WithFields(logrus.Fields{
+		"content_type": diff.ContentType,
+		"generator":    diff.Generator,
+		"seed":         diff.Seed,
+		"changed":      diff.Changed(),
+	}).Info(diff.Summary())
+}
+
+// withEmbeddedParams returns a copy of constraints holding the typed
+// parameters under key, as the manager passes them to generators. The
+// embedded parameters get their own copy of the constraints so they do not
+// contain themselves. Parameters the caller embedded already are kept.
+func withEmbeddedParams(constraints map[string]interface{}, key string, embed func(map[string]interface{}) interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(constraints)+1)
+	inner := make(map[string]interface{}, len(constraints))
+	for k, v := range constraints {
+		result[k] = v
+		inner[k] = v
+	}
+	if _, exists := result[key]; !exists {
+		result[key] = embed(inner)
+	}
+	return result
+}
+
+// Changed reports whether the regenerated content differs from the stored
+// version
+func (d *ContentDiff) Changed() bool {
+	switch {
+	case d.Tiles != nil:
+		return d.Tiles.Changed > 0 || d.Tiles.OldWidth != d.Tiles.NewWidth || d.Tiles.OldHeight != d.Tiles.NewHeight
+	case d.Items != nil:
+		return len(d.Items.Changes) > 0
+	case d.Quest != nil:
+		return d.Quest.OldTitle != d.Quest.NewTitle || len(d.Quest.Objectives) > 0 || d.Quest.RewardsChanged
+	}
+	return false
+}
+
+// Summary describes the diff in one line
+func (d *ContentDiff) Summary() string {
+	if !d.Changed() {
+		return fmt.Sprintf("%s unchanged", d.ContentType)
+	}
+
+	var parts []string
+	switch {
+	case d.Tiles != nil:
+		t := d.Tiles
+		if t.OldWidth != t.NewWidth || t.OldHeight != t.NewHeight {
+			parts = append(parts, fmt.Sprintf("resized from %dx%d to %dx%d", t.OldWidth, t.OldHeight, t.NewWidth, t.NewHeight))
+		}
+		parts = append(parts, fmt.Sprintf("%d of %d tiles changed (%d walkability changes)", t.Changed, t.Compared, t.WalkabilityChanged))
+	case d.Items != nil:
+		counts := make(map[DiffKind]int)
+		for _, change := range d.Items.Changes {
+			counts[change.Kind]++
+		}
+		parts = append(parts, fmt.Sprintf("%d items added, %d removed, %d stat changes", counts[DiffAdded], counts[DiffRemoved], counts[DiffChanged]))
+	case d.Quest != nil:
+		if d.Quest.OldTitle != d.Quest.NewTitle {
+			parts = append(parts, "title changed")
+		}
+		if len(d.Quest.Objectives) > 0 {
+			parts = append(parts, fmt.Sprintf("%d objective changes", len(d.Quest.Objectives)))
+		}
+		if d.Quest.RewardsChanged {
+			parts = append(parts, "rewards changed")
+		}
+	}
+	return fmt.Sprintf("%s changed: %s", d.ContentType, strings.Join(parts, ", "))
+}
+
+// CompareTerrain compares the tiles of two terrain maps
+func CompareTerrain(old, regenerated *game.GameMap) *TileDiff {
+	diff := &TileDiff{Changes: []TileChange{}}
+	if old != nil {
+		diff.OldWidth, diff.OldHeight = old.Width, old.Height
+	}
+	if regenerated != nil {
+		diff.NewWidth, diff.NewHeight = regenerated.Width, regenerated.Height
+	}
+	if old == nil || regenerated == nil {
+		return diff
+	}
+
+	for y := 0; y < min(old.Height, regenerated.Height); y++ {
+		for x := 0; x < min(old.Width, regenerated.Width); x++ {
+			before, after := old.GetTile(x, y), regenerated.GetTile(x, y)
+			if before == nil || after == nil {
+				continue
+			}
+			diff.record(x, y, *before != *after,
+				fmt.Sprintf("sprite %d,%d", before.SpriteX, before.SpriteY), fmt.Sprintf("sprite %d,%d", after.SpriteX, after.SpriteY),
+				before.Walkable, after.Walkable)
+		}
+	}
+	return diff
+}
+
+// CompareLevelTiles compares the tiles of two levels by type and
+// walkability
+func CompareLevelTiles(old, regenerated *game.Level) *TileDiff {
+	diff := &TileDiff{Changes: []TileChange{}}
+	if old != nil {
+		diff.OldWidth, diff.OldHeight = old.Width, old.Height
+	}
+	if regenerated != nil {
+		diff.NewWidth, diff.NewHeight = regenerated.Width, regenerated.Height
+	}
+	if old == nil || regenerated == nil {
+		return diff
+	}
+
+	for y := 0; y < min(len(old.Tiles), len(regenerated.Tiles)); y++ {
+		for x := 0; x < min(len(old.Tiles[y]), len(regenerated.Tiles[y])); x++ {
+			before, after := old.Tiles[y][x], regenerated.Tiles[y][x]
+			diff.record(x, y, before.Type != after.Type || before.Walkable != after.Walkable,
+				fmt.Sprintf("type %d", before.Type), fmt.Sprintf("type %d", after.Type),
+				before.Walkable, after.Walkable)
+		}
+	}
+	return diff
+}
+
+// record counts a compared tile and lists it if it changed
+func (d *TileDiff) record(x, y int, changed bool, before, after string, oldWalkable, newWalkable bool) {
+	d.Compared++
+	if !changed {
+		return
+	}
+	d.Changed++
+	if oldWalkable != newWalkable {
+		d.WalkabilityChanged++
+	}
+	if len(d.Changes) < MaxReportedTileChanges {
+		d.Changes = append(d.Changes, TileChange{X: x, Y: y, Old: before, New: after, OldWalkable: oldWalkable, NewWalkable: newWalkable})
+	}
+}
+
+// CompareItems compares two item lists stat by stat
+func CompareItems(old, regenerated []*game.Item) *ItemDiff {
+	diff := &ItemDiff{OldCount: len(old), NewCount: len(regenerated), Changes: []ItemStatChange{}}
+
+	for i := 0; i < max(len(old), len(regenerated)); i++ {
+		switch {
+		case i >= len(old):
+			diff.Changes = append(diff.Changes, ItemStatChange{Index: i, Kind: DiffAdded, Item: regenerated[i].Name})
+		case i >= len(regenerated):
+			diff.Changes = append(diff.Changes, ItemStatChange{Index: i, Kind: DiffRemoved, Item: old[i].Name})
+		default:
+			diff.Changes = append(diff.Changes, compareItemStats(i, old[i], regenerated[i])...)
+		}
+	}
+	return diff
+}
+
+// compareItemStats lists the stats that differ between two items
+func compareItemStats(index int, old, regenerated *game.Item) []ItemStatChange {
+	stats := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"name", old.Name, regenerated.Name},
+		{"type", old.Type, regenerated.Type},
+		{"damage", old.Damage, regenerated.Damage},
+		{"ac", old.AC, regenerated.AC},
+		{"weight", old.Weight, regenerated.Weight},
+		{"value", old.Value, regenerated.Value},
+	}
+
+	var changes []ItemStatChange
+	for _, stat := range stats {
+		if stat.old != stat.new {
+			changes = append(changes, ItemStatChange{Index: index, Kind: DiffChanged, Item: regenerated.Name, Stat: stat.name, Old: stat.old, New: stat.new})
+		}
+	}
+	if !slices.Equal(old.Properties, regenerated.Properties) {
+		changes = append(changes, ItemStatChange{Index: index, Kind: DiffChanged, Item: regenerated.Name, Stat: "properties", Old: old.Properties, New: regenerated.Properties})
+	}
+	return changes
+}
+
+// CompareQuests compares the title, objectives and rewards of two quests
+func CompareQuests(old, regenerated *game.Quest) *QuestDiff {
+	if old == nil {
+		old = &game.Quest{}
+	}
+	if regenerated == nil {
+		regenerated = &game.Quest{}
+	}
+
+	diff := &QuestDiff{
+		OldTitle:       old.Title,
+		NewTitle:       regenerated.Title,
+		Objectives:     []ObjectiveChange{},
+		RewardsChanged: !slices.Equal(old.Rewards, regenerated.Rewards),
+	}
+
+	for i := 0; i < max(len(old.Objectives), len(regenerated.Objectives)); i++ {
+		switch {
+		case i >= len(old.Objectives):
+			objective := regenerated.Objectives[i]
+			diff.Objectives = append(diff.Objectives, ObjectiveChange{Index: i, Kind: DiffAdded, New: objective.Description, NewRequired: objective.Required})
+		case i >= len(regenerated.Objectives):
+			objective := old.Objectives[i]
+			diff.Objectives = append(diff.Objectives, ObjectiveChange{Index: i, Kind: DiffRemoved, Old: objective.Description, OldRequired: objective.Required})
+		default:
+			before, after := old.Objectives[i], regenerated.Objectives[i]
+			if before.Description != after.Description || before.Required != after.Required {
+				diff.Objectives = append(diff.Objectives, ObjectiveChange{
+					Index: i, Kind: DiffChanged,
+					Old: before.Description, New: after.Description,
+					OldRequired: before.Required, NewRequired: after.Required,
+				})
+			}
+		}
+	}
+	return diff
+}
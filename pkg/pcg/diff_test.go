@@ -0,0 +1,159 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stripeTerrainGenerator blocks the columns left of Density times the width,
+// so terrain changes predictably with the density parameter
+type stripeTerrainGenerator struct{}
+
+func (stripeTerrainGenerator) Generate(ctx context.Context, params GenerationParams) (interface{}, error) {
+	terrainParams := params.Constraints["terrain_params"].(TerrainParams)
+	width, height := params.Constraints["width"].(int), params.Constraints["height"].(int)
+
+	gameMap := &game.GameMap{Width: width, Height: height, Tiles: make([][]game.MapTile, height)}
+	for y := range gameMap.Tiles {
+		gameMap.Tiles[y] = make([]game.MapTile, width)
+		for x := range gameMap.Tiles[y] {
+			walkable := float64(x) >= terrainParams.Density*float64(width)
+			gameMap.Tiles[y][x] = game.MapTile{Walkable: walkable, Transparent: walkable}
+		}
+	}
+	return gameMap, nil
+}
+
+func (stripeTerrainGenerator) GetType() ContentType            { return ContentTypeTerrain }
+func (stripeTerrainGenerator) GetVersion() string              { return "1.0.0" }
+func (stripeTerrainGenerator) Validate(GenerationParams) error { return nil }
+
+func stripeTerrainParams(density float64) TerrainParams {
+	return TerrainParams{
+		GenerationParams: GenerationParams{
+			Seed:        7,
+			Constraints: map[string]interface{}{"width": 10, "height": 4},
+		},
+		Density: density,
+	}
+}
+
+func TestDiffGenerator_DiffTerrain(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	require.NoError(t, manager.GetRegistry().RegisterGenerator("stripes", stripeTerrainGenerator{}))
+
+	stored, err := manager.factory.GenerateTerrain(context.Background(), "stripes", stripeTerrainParamsEmbedded(0.2))
+	require.NoError(t, err)
+
+	diff, err := manager.DiffGenerator().DiffTerrain(context.Background(), stored, "stripes", stripeTerrainParams(0.2))
+	require.NoError(t, err)
+	assert.False(t, diff.Changed())
+	assert.Equal(t, "terrain unchanged", diff.Summary())
+
+	diff, err = manager.DiffGenerator().DiffTerrain(context.Background(), stored, "stripes", stripeTerrainParams(0.5))
+	require.NoError(t, err)
+	require.True(t, diff.Changed())
+	assert.Equal(t, int64(7), diff.Seed)
+	assert.Equal(t, 40, diff.Tiles.Compared)
+	assert.Equal(t, 12, diff.Tiles.Changed, "columns 2-4 become walls")
+	assert.Equal(t, 12, diff.Tiles.WalkabilityChanged)
+	assert.Equal(t, TileChange{X: 2, Y: 0, Old: "sprite 0,0", New: "sprite 0,0", OldWalkable: true, NewWalkable: false}, diff.Tiles.Changes[0])
+	assert.Equal(t, "terrain changed: 12 of 40 tiles changed (12 walkability changes)", diff.Summary())
+
+	assert.Zero(t, manager.GetMetrics().GetGenerationCount(ContentTypeTerrain), "diffs are not recorded as generations")
+}
+
+// stripeTerrainParamsEmbedded returns the parameters as the manager passes
+// them to generators
+func stripeTerrainParamsEmbedded(density float64) TerrainParams {
+	params := stripeTerrainParams(density)
+	params.Constraints = withEmbeddedParams(params.Constraints, "terrain_params", func(constraints map[string]interface{}) interface{} {
+		embedded := params
+		embedded.Constraints = constraints
+		return embedded
+	})
+	return params
+}
+
+func TestDiffGenerator_DiffQuest(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	require.NoError(t, manager.RegisterDefaultGenerators())
+
+	params := QuestParams{
+		GenerationParams: GenerationParams{Seed: 99, Difficulty: 3, PlayerLevel: 3, Constraints: map[string]interface{}{}},
+		QuestType:        QuestTypeKill,
+		MinObjectives:    1,
+		MaxObjectives:    1,
+		RewardTier:       RarityCommon,
+		Narrative:        NarrativeLinear,
+	}
+	storedParams := params
+	storedParams.Constraints = map[string]interface{}{"quest_params": params}
+	stored, err := manager.factory.GenerateQuest(context.Background(), "default", storedParams)
+	require.NoError(t, err)
+
+	diff, err := manager.DiffGenerator().DiffQuest(context.Background(), stored, "default", params)
+	require.NoError(t, err)
+	assert.False(t, diff.Changed(), diff.Summary())
+
+	moreObjectives := params
+	moreObjectives.MinObjectives, moreObjectives.MaxObjectives = 3, 3
+	diff, err = manager.DiffGenerator().DiffQuest(context.Background(), stored, "default", moreObjectives)
+	require.NoError(t, err)
+	require.True(t, diff.Changed())
+	assert.Equal(t, []DiffKind{DiffAdded, DiffAdded}, []DiffKind{diff.Quest.Objectives[len(diff.Quest.Objectives)-2].Kind, diff.Quest.Objectives[len(diff.Quest.Objectives)-1].Kind})
+
+	diff, err = manager.DiffGenerator().DiffQuest(context.Background(), nil, "default", params)
+	require.NoError(t, err)
+	require.True(t, diff.Changed())
+	require.NotEmpty(t, diff.Quest.Objectives)
+	assert.Equal(t, DiffAdded, diff.Quest.Objectives[0].Kind)
+}
+
+func TestCompareItems(t *testing.T) {
+	old := []*game.Item{
+		{ID: "a", Name: "Sword", Type: "weapon", Damage: "1d8", Value: 10, Properties: []string{"sharp"}},
+		{ID: "b", Name: "Shield", Type: "armor", AC: 1},
+	}
+	regenerated := []*game.Item{
+		{ID: "c", Name: "Sword", Type: "weapon", Damage: "1d10", Value: 15, Properties: []string{"sharp"}},
+		{ID: "d", Name: "Shield", Type: "armor", AC: 1},
+		{ID: "e", Name: "Potion", Type: "consumable"},
+	}
+
+	diff := CompareItems(old, regenerated)
+	assert.Equal(t, []ItemStatChange{
+		{Index: 0, Kind: DiffChanged, Item: "Sword", Stat: "damage", Old: "1d8", New: "1d10"},
+		{Index: 0, Kind: DiffChanged, Item: "Sword", Stat: "value", Old: 10, New: 15},
+		{Index: 2, Kind: DiffAdded, Item: "Potion"},
+	}, diff.Changes, "item IDs are not compared")
+
+	summary := (&ContentDiff{ContentType: ContentTypeItems, Items: diff}).Summary()
+	assert.Equal(t, "items changed: 1 items added, 0 removed, 2 stat changes", summary)
+
+	assert.Empty(t, CompareItems(old, old).Changes)
+	assert.Equal(t, DiffRemoved, CompareItems(old, old[:1]).Changes[0].Kind)
+}
+
+func TestCompareLevelTiles(t *testing.T) {
+	level := func(types ...game.TileType) *game.Level {
+		tiles := make([]game.Tile, len(types))
+		for i, tileType := range types {
+			tiles[i] = game.Tile{Type: tileType, Walkable: tileType == game.TileFloor}
+		}
+		return &game.Level{Width: len(types), Height: 1, Tiles: [][]game.Tile{tiles}}
+	}
+
+	diff := CompareLevelTiles(level(game.TileFloor, game.TileWall), level(game.TileFloor, game.TileFloor, game.TileWall))
+	assert.Equal(t, 2, diff.Compared)
+	assert.Equal(t, 1, diff.Changed)
+	assert.Equal(t, 3, diff.NewWidth)
+
+	summary := (&ContentDiff{ContentType: ContentTypeLevels, Tiles: diff}).Summary()
+	assert.Equal(t, "levels changed: resized from 2x1 to 3x1, 1 of 2 tiles changed (1 walkability changes)", summary)
+}
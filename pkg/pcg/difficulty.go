@@ -0,0 +1,251 @@
+package pcg
+
+import (
+	"math"
+	"sync"
+)
+
+// Difficulty director defaults
+const (
+	// DefaultDifficultyWindow is the number of recent difficulty ratings the
+	// director averages, overall and per content type
+	DefaultDifficultyWindow = 20
+
+	// DefaultDifficultyMinSamples is the number of ratings needed before the
+	// director adjusts difficulty
+	DefaultDifficultyMinSamples = 3
+
+	// DefaultDifficultyMaxAdjustment is the largest number of difficulty
+	// levels the director adds to or removes from a base difficulty
+	DefaultDifficultyMaxAdjustment = 3
+
+	// MinDifficulty and MaxDifficulty bound every generation difficulty
+	MinDifficulty = 1
+	MaxDifficulty = 20
+)
+
+// Player difficulty ratings run from 1 (far too easy) to 5 (far too hard);
+// targetDifficultyRating means the content felt right.
+const (
+	minDifficultyRating    = 1
+	maxDifficultyRating    = 5
+	targetDifficultyRating = 3
+)
+
+// DifficultyDirectorConfig controls how player feedback moves generation
+// difficulty
+type DifficultyDirectorConfig struct {
+	Enabled       bool                `json:"enabled"`        // Apply feedback adjustments at all
+	Window        int                 `json:"window"`         // Recent ratings averaged
+	MinSamples    int                 `json:"min_samples"`    // Ratings needed before adjusting
+	MaxAdjustment int                 `json:"max_adjustment"` // Largest adjustment in difficulty levels
+	Min           int                 `json:"min"`            // Lowest effective difficulty
+	Max           int                 `json:"max"`            // Highest effective difficulty
+	Overrides     map[ContentType]int `json:"overrides"`      // Per content type largest adjustment (0 freezes the type)
+}
+
+// DefaultDifficultyDirectorConfig returns the default director settings
+func DefaultDifficultyDirectorConfig() DifficultyDirectorConfig {
+	return DifficultyDirectorConfig{
+		Enabled:       true,
+		Window:        DefaultDifficultyWindow,
+		MinSamples:    DefaultDifficultyMinSamples,
+		MaxAdjustment: DefaultDifficultyMaxAdjustment,
+		Min:           MinDifficulty,
+		Max:           MaxDifficulty,
+	}
+}
+
+// DifficultyDirector turns the rolling difficulty ratings of player feedback
+// into an adjustment applied to the difficulty of everything generated.
+// Content rated too easy is generated harder and content rated too hard
+// easier, up to the configured maximum adjustment. Content types with
+// enough ratings of their own are adjusted by those, others by the ratings
+// of all content.
+type DifficultyDirector struct {
+	mu      sync.RWMutex
+	config  DifficultyDirectorConfig
+	overall []int
+	byType  map[ContentType][]int
+}
+
+// NewDifficultyDirector creates a director with config. Unset window,
+// sample and bound settings take their defaults.
+func NewDifficultyDirector(config DifficultyDirectorConfig) *DifficultyDirector {
+	return &DifficultyDirector{
+		config: normalizeDifficultyConfig(config),
+		byType: make(map[ContentType][]int),
+	}
+}
+
+// normalizeDifficultyConfig fills unset settings with their defaults
+func normalizeDifficultyConfig(config DifficultyDirectorConfig) DifficultyDirectorConfig {
+	if config.Window <= 0 {
+		config.Window = DefaultDifficultyWindow
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = DefaultDifficultyMinSamples
+	}
+	if config.MaxAdjustment < 0 {
+		config.MaxAdjustment = 0
+	}
+	if config.Min <= 0 {
+		config.Min = MinDifficulty
+	}
+	if config.Max <= 0 || config.Max > MaxDifficulty {
+		config.Max = MaxDifficulty
+	}
+	if config.Min > config.Max {
+		config.Min = config.Max
+	}
+	overrides := make(map[ContentType]int, len(config.Overrides))
+	for contentType, limit := range config.Overrides {
+		overrides[contentType] = max(limit, 0)
+	}
+	config.Overrides = overrides
+	return config
+}
+
+// SetConfig replaces the director settings. Recorded ratings are kept, up
+// to the new window.
+func (d *DifficultyDirector) SetConfig(config DifficultyDirectorConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.config = normalizeDifficultyConfig(config)
+	d.overall = lastRatings(d.overall, d.config.Window)
+	for contentType, ratings := range d.byType {
+		d.byType[contentType] = lastRatings(ratings, d.config.Window)
+	}
+}
+
+// GetConfig returns a copy of the director settings
+func (d *DifficultyDirector) GetConfig() DifficultyDirectorConfig {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	config := d.config
+	config.Overrides = make(map[ContentType]int, len(d.config.Overrides))
+	for contentType, limit := range d.config.Overrides {
+		config.Overrides[contentType] = limit
+	}
+	return config
+}
+
+// RecordFeedback adds the difficulty rating of feedback to the rolling
+// windows. Ratings outside 1-5 are ignored.
+func (d *DifficultyDirector) RecordFeedback(feedback PlayerFeedback) {
+	if feedback.Difficulty < minDifficultyRating || feedback.Difficulty > maxDifficultyRating {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.overall = lastRatings(append(d.overall, feedback.Difficulty), d.config.Window)
+	if feedback.ContentType != "" {
+		d.byType[feedback.ContentType] = lastRatings(append(d.byType[feedback.ContentType], feedback.Difficulty), d.config.Window)
+	}
+}
+
+// Reset forgets all recorded ratings
+func (d *DifficultyDirector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.overall = nil
+	d.byType = make(map[ContentType][]int)
+}
+
+// GetEffectiveDifficulty returns base adjusted by the ratings of all
+// content and clamped to the configured bounds
+func (d *DifficultyDirector) GetEffectiveDifficulty(base int) int {
+	return d.GetEffectiveDifficultyFor("", base)
+}
+
+// GetEffectiveDifficultyFor returns base adjusted for contentType and
+// clamped to the configured bounds. An empty content type uses the ratings
+// of all content.
+func (d *DifficultyDirector) GetEffectiveDifficultyFor(contentType ContentType, base int) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	effective := base + d.adjustmentLocked(contentType)
+	return min(max(effective, d.config.Min), d.config.Max)
+}
+
+// Adjustment returns the number of difficulty levels currently added to
+// contentType, negative when content is generated easier
+func (d *DifficultyDirector) Adjustment(contentType ContentType) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.adjustmentLocked(contentType)
+}
+
+// adjustmentLocked computes the adjustment for contentType. The caller must
+// hold d.mu.
+func (d *DifficultyDirector) adjustmentLocked(contentType ContentType) int {
+	if !d.config.Enabled {
+		return 0
+	}
+
+	limit := d.config.MaxAdjustment
+	if override, exists := d.config.Overrides[contentType]; exists {
+		limit = override
+	}
+	if limit == 0 {
+		return 0
+	}
+
+	ratings := d.byType[contentType]
+	if contentType == "" || len(ratings) < d.config.MinSamples {
+		ratings = d.overall
+	}
+	if len(ratings) < d.config.MinSamples {
+		return 0
+	}
+
+	total := 0
+	for _, rating := range ratings {
+		total += rating
+	}
+	mean := float64(total) / float64(len(ratings))
+
+	// A mean rating at either end of the scale moves difficulty by the full
+	// limit; ratings are inverted since "too easy" calls for harder content
+	deviation := (targetDifficultyRating - mean) / (targetDifficultyRating - minDifficultyRating)
+	adjustment := int(math.Round(deviation * float64(limit)))
+	return min(max(adjustment, -limit), limit)
+}
+
+// GetStats returns the current adjustments and rating counts
+func (d *DifficultyDirector) GetStats() map[string]interface{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	adjustments := make(map[string]int, len(d.byType))
+	samples := make(map[string]int, len(d.byType))
+	for contentType, ratings := range d.byType {
+		adjustments[string(contentType)] = d.adjustmentLocked(contentType)
+		samples[string(contentType)] = len(ratings)
+	}
+
+	return map[string]interface{}{
+		"enabled":        d.config.Enabled,
+		"adjustment":     d.adjustmentLocked(""),
+		"samples":        len(d.overall),
+		"adjustments":    adjustments,
+		"type_samples":   samples,
+		"min_difficulty": d.config.Min,
+		"max_difficulty": d.config.Max,
+		"max_adjustment": d.config.MaxAdjustment,
+	}
+}
+
+// lastRatings keeps the last window ratings
+func lastRatings(ratings []int, window int) []int {
+	if len(ratings) <= window {
+		return ratings
+	}
+	return append([]int(nil), ratings[len(ratings)-window:]...)
+}
@@ -0,0 +1,90 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rate records count difficulty ratings of contentType
+func rate(director *DifficultyDirector, contentType ContentType, difficulty, count int) {
+	for i := 0; i < count; i++ {
+		director.RecordFeedback(PlayerFeedback{ContentType: contentType, Difficulty: difficulty})
+	}
+}
+
+func TestDifficultyDirector_FollowsFeedback(t *testing.T) {
+	director := NewDifficultyDirector(DefaultDifficultyDirectorConfig())
+	assert.Equal(t, 10, director.GetEffectiveDifficulty(10), "no feedback, no adjustment")
+
+	rate(director, ContentTypeLevels, 1, 2)
+	assert.Equal(t, 10, director.GetEffectiveDifficulty(10), "too few ratings")
+
+	rate(director, ContentTypeLevels, 1, 1)
+	assert.Equal(t, 13, director.GetEffectiveDifficultyFor(ContentTypeLevels, 10), "far too easy raises by the full adjustment")
+	assert.Equal(t, 13, director.GetEffectiveDifficultyFor(ContentTypeQuests, 10), "types without ratings follow all content")
+
+	rate(director, ContentTypeQuests, 5, 3)
+	assert.Equal(t, 7, director.GetEffectiveDifficultyFor(ContentTypeQuests, 10))
+	assert.Equal(t, 13, director.GetEffectiveDifficultyFor(ContentTypeLevels, 10))
+	assert.Equal(t, 10, director.GetEffectiveDifficulty(10), "ratings balance out overall")
+
+	rate(director, ContentTypeQuests, 7, 5)
+	assert.Equal(t, -3, director.Adjustment(ContentTypeQuests), "out of range ratings are ignored")
+}
+
+func TestDifficultyDirector_RollingWindow(t *testing.T) {
+	config := DefaultDifficultyDirectorConfig()
+	config.Window = 4
+	director := NewDifficultyDirector(config)
+
+	rate(director, ContentTypeItems, 5, 4)
+	assert.Equal(t, -3, director.Adjustment(ContentTypeItems))
+
+	rate(director, ContentTypeItems, 3, 4)
+	assert.Equal(t, 0, director.Adjustment(ContentTypeItems), "old ratings fall out of the window")
+}
+
+func TestDifficultyDirector_ClampingAndOverrides(t *testing.T) {
+	config := DefaultDifficultyDirectorConfig()
+	config.Min = 2
+	config.Max = 12
+	config.Overrides = map[ContentType]int{ContentTypeTerrain: 0, ContentTypeQuests: 1}
+	director := NewDifficultyDirector(config)
+
+	rate(director, ContentTypeLevels, 1, 3)
+	assert.Equal(t, 12, director.GetEffectiveDifficultyFor(ContentTypeLevels, 11))
+	assert.Equal(t, 2, director.GetEffectiveDifficultyFor(ContentTypeLevels, -5))
+	assert.Equal(t, 8, director.GetEffectiveDifficultyFor(ContentTypeTerrain, 8), "terrain is frozen")
+	assert.Equal(t, 9, director.GetEffectiveDifficultyFor(ContentTypeQuests, 8), "quests move one level at most")
+
+	config.Enabled = false
+	director.SetConfig(config)
+	assert.Equal(t, 8, director.GetEffectiveDifficultyFor(ContentTypeLevels, 8))
+	assert.Equal(t, 12, director.GetEffectiveDifficultyFor(ContentTypeLevels, 15), "bounds apply while disabled")
+}
+
+func TestPCGManager_FeedbackAdjustsGeneration(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	require.NoError(t, manager.GetRegistry().RegisterGenerator("objective_based", NewQuestGenerator(logrus.New())))
+	defer manager.GetJobQueue().Close()
+
+	for i := 0; i < 3; i++ {
+		manager.RecordPlayerFeedback(PlayerFeedback{ContentType: ContentTypeQuests, ContentID: "q", Rating: 4, Difficulty: 1, Enjoyment: 4})
+	}
+
+	_, err := manager.GeneratePersonalQuest(context.Background(), 42, QuestTypeFetch, 4)
+	require.NoError(t, err)
+
+	entries := manager.GetContentIndex().Query(ContentQuery{Kind: ContentKindQuest})
+	require.Len(t, entries, 1)
+	assert.Equal(t, 7, entries[0].Difficulty)
+
+	stats := manager.GetGenerationStatistics()["difficulty"].(map[string]interface{})
+	assert.Equal(t, 3, stats["adjustment"])
+}
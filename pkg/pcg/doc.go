@@ -28,6 +28,10 @@
 //   - Quests: Multi-objective quest chains with narrative
 //   - NPCs: Characters with personalities and behaviors
 //   - Factions: Groups with relationships and reputations
+//   - Monsters: Bestiary stat blocks (hit dice, armor class, THAC0,
+//     attacks, special abilities, treasure type) scaled to difficulty and
+//     chosen by biome; combat rooms are populated with them
+//   - Deities: Pantheons of gods for the genre variant, see Pantheons
 //
 // # Generator Registry
 //
@@ -51,6 +55,23 @@
 //   - Multiplayer synchronization
 //   - Bug reproduction
 //
+// # Random Number Generators
+//
+// Generators draw from NewRNG, or from their SeedManager, whose algorithm
+// SetRNGAlgorithm selects (PCG_RNG). The default math/rand algorithm
+// reproduces content from seeds created before the choice existed. The
+// utils.RNGPCG64 and utils.RNGXoshiro256 algorithms give every generator
+// its own stream, so generators sharing a seed draw unrelated numbers, and
+// support jump-ahead: CreateChunkRNG hands each chunk of content generated
+// in parallel a non-overlapping part of one stream.
+//
+//	pcg.SetRNGAlgorithm(utils.RNGPCG64)
+//	rng := seedMgr.CreateChunkRNG(pcg.ContentTypeTerrain, "region", params, chunk)
+//
+// A world records its algorithm in its saved seed state and keeps it. The
+// utils.CryptoSource and utils.SecureToken read crypto/rand for values that
+// must not be predictable.
+//
 // # Validation
 //
 // Content is validated before world integration:
@@ -63,17 +84,64 @@
 //	    }
 //	}
 //
+// ValidateWorldConsistency checks the references between pieces of content
+// instead: quest targets and item rewards, dungeon entrances and level
+// connections, overworld links, faction IDs and the items loot tables drop.
+// It returns a ConsistencyReport listing every reference that does not
+// resolve; Bootstrap runs it before completing and fails on errors.
+//
+//	report := manager.ValidateWorldConsistency(pcg.WorldContent{World: world, Quests: quests, Loot: lootTables})
+//	if !report.Consistent() {
+//	    log.Error(report.Errors())
+//	}
+//
 // # World Integration
 //
 // Generated content integrates safely with game world:
 //
-//	err := manager.IntegrateContent(ctx, content)
+//	err := manager.IntegrateContentIntoWorld(content, locationID)
 //
 // Integration handles:
 //   - Spatial indexing updates
 //   - Entity registration
 //   - Event emission
 //
+// Integration is transactional. To integrate several pieces of content
+// atomically, stage them on an Integration and commit once:
+//
+//	ix := manager.BeginIntegration(ctx, locationID)
+//	defer ix.Rollback()
+//	if err := ix.Stage(level); err != nil {
+//	    return err
+//	}
+//	if err := ix.Stage(items); err != nil {
+//	    return err
+//	}
+//	return ix.Commit()
+//
+// Nothing reaches the world before Commit. A failed commit undoes any
+// changes already applied, and the integration rolls back automatically
+// when staged content fails validation or ctx is cancelled.
+//
+// BeginWorldIntegration commits into a world other than the one the manager
+// generates against, such as a server's live world. Terrain maps can be
+// staged directly; they are converted to levels with LevelFromTerrain.
+// A DungeonComplex stages all its levels, converted with LevelsFromDungeon,
+// and committing it adds its stairs and other connections to the manager's
+// travel graph.
+//
+// # Regeneration Impact
+//
+// DiffGenerator shows what a parameter change would do to stored content.
+// It regenerates the content with the same seed under the new parameters
+// and reports tile, item stat and quest objective changes without touching
+// the world or the metrics:
+//
+//	diff, err := manager.DiffGenerator().DiffLevel(ctx, stored, "room_corridor", params)
+//	if diff.Changed() {
+//	    log.Info(diff.Summary())
+//	}
+//
 // # Metrics
 //
 // Performance and quality metrics for monitoring:
@@ -81,6 +149,212 @@
 //	metrics := manager.GetMetrics()
 //	// Generation times, cache hits, validation failures
 //
+// # Content Cache
+//
+// Repeated generations with the same content type, generator, seed and
+// parameters return the earlier result from the manager's ContentCache.
+// The cache evicts least recently used entries to stay within a byte
+// limit, expires entries after a TTL, and records hits and misses in the
+// metrics. Only the enabled content types are cached, terrain and levels
+// by default, and cached content must be treated as read-only:
+//
+//	cache := manager.GetContentCache()
+//	cache.SetConfig(pcg.DefaultContentCacheConfig())
+//	stats := cache.Stats()
+//
+// # Quality Gate
+//
+// With the manager's QualityGate enabled, the Generate* methods generate
+// a piece of content up to MaxAttempts times, score each candidate from 0
+// to 1 with Validator.ScoreContent and keep the best, stopping early once
+// a candidate reaches the threshold of its content type. Later candidates
+// derive their seeds from the location, so the selection is reproducible:
+//
+//	gate := manager.GetQualityGate()
+//	gate.SetConfig(pcg.QualityGateConfig{Enabled: true, MaxAttempts: 3, MinScore: 0.8})
+//	rejectionRate := gate.Stats()[pcg.ContentTypeLevels].RejectionRate
+//
+// # Difficulty Director
+//
+// Player feedback recorded with RecordPlayerFeedback also reaches the
+// manager's DifficultyDirector, which averages recent difficulty ratings
+// (1 far too easy, 5 far too hard) and shifts the difficulty every
+// Generate* method generates at, within configured bounds:
+//
+//	director := manager.GetDifficultyDirector()
+//	difficulty := director.GetEffectiveDifficultyFor(pcg.ContentTypeLevels, 8)
+//
+// Overrides cap the adjustment per content type; an override of 0 keeps a
+// content type at its requested difficulty.
+//
+// # Progress Reporting
+//
+// Generators report stage/percentage progress through the optional
+// GenerationParams.Progress callback. Callers of the Manager attach a
+// callback to the context instead:
+//
+//	ctx = pcg.WithProgress(ctx, func(u pcg.ProgressUpdate) {
+//		fmt.Printf("%s: %s %.0f%%\n", u.ContentType, u.Stage, u.Percent)
+//	})
+//
+// Every successful generation ends with a ProgressStageComplete update at
+// 100%. Bootstrap.SetProgressFunc reports progress for a full bootstrap run.
+//
+// # Bootstrap Pipeline
+//
+// GenerateCompleteGame runs its content stages as a dependency-aware
+// pipeline: the world first, then factions and terrain, then characters
+// and quests, then dialogue, with spells and items alongside. Each stage
+// starts as soon as the stages it reads have finished:
+//
+//	bootstrap.SetConcurrency(4)                 // stages at once, default one per CPU
+//	bootstrap.SetStageTimeout(10 * time.Second) // per stage, default 30s
+//
+// A stage that fails or overruns its timeout, or cancellation of the
+// context, stops the whole run. Each completed stage sends a progress
+// update; updates are never concurrent.
+//
+// # Bootstrap Profiles
+//
+// After GenerateCompleteGame, Bootstrap.ExportProfile returns a
+// BootstrapProfile: the resolved configuration with the seed actually used,
+// the full seed tree and a fingerprint of the generated content. A profile
+// saved to a file or encoded as a world sharing code replays the same world
+// elsewhere; the replay fails if its content does not match the fingerprint.
+//
+//	profile, _ := bootstrap.ExportProfile()
+//	code, _ := profile.ShareCode()
+//
+//	shared, err := pcg.ParseShareCode(code)
+//	replay, err := pcg.NewBootstrapFromProfile(shared, "data", game.NewWorld(), logger)
+//	world, err := replay.GenerateCompleteGame(ctx)
+//
+// # Background Jobs
+//
+// Long generations run as jobs on the manager's JobQueue, which runs a
+// limited number at a time in submission order:
+//
+//	jobID, err := manager.SubmitGenerationJob(pcg.JobSpec{
+//		ContentType: pcg.ContentTypeLevels,
+//		Run: func(ctx context.Context) (interface{}, error) {
+//			return manager.GenerateDungeonLevel(ctx, "depths", 10, 30, pcg.ThemeClassic, 12)
+//		},
+//	})
+//	job, err := manager.GetGenerationJob(jobID) // Status, progress and result
+//	err = manager.CancelGenerationJob(jobID)
+//
+// The context passed to Run records progress into the job and is canceled
+// when the job is canceled or times out.
+//
+// # Content Index
+//
+// The manager records everything its Generate* methods produce in a
+// ContentIndex, tagged with biome, theme, difficulty, faction and rarity.
+// Levels are indexed along with each room listed in their "rooms"
+// property, and items by the "rarity:" property the item generators add:
+//
+//	rooms := manager.GetContentIndex().Query(pcg.ContentQuery{
+//		Kind:          pcg.ContentKindRoom,
+//		Theme:         pcg.ThemeUndead,
+//		Tags:          []string{string(pcg.RoomTypeBoss)},
+//		MinDifficulty: 8,
+//	})
+//
+// # Difficulty Heatmaps
+//
+// DungeonHeatmap and WorldHeatmap measure the encounter budget, trap
+// density and loot value of each room or region of generated content, and
+// score how smoothly its difficulty progresses: from level to level of a
+// dungeon, and between bordering regions of an overworld. Recording the
+// pacing score in the quality metrics adds a "pacing" component score to
+// quality reports:
+//
+//	heatmap := pcg.DungeonHeatmap(dungeon)
+//	manager.GetQualityMetrics().RecordPacing(dungeon.ID, heatmap.Pacing.Score)
+//
+// # NPC Personalities
+//
+// PersonalityGenerator scores generated NPCs on the bravery, greed and
+// honesty axes, gives them a voice style and derives the
+// game.BehaviorModifiers the companion AI acts on. Scores are drawn from
+// PersonalityTemplate distributions: the default is shifted by the genre
+// variant's template, then by the faction culture's. NPCGenerator takes
+// the genre from the "genre" constraint and the culture from the "culture"
+// constraint or the NPC's faction:
+//
+//	pg := npcs.GetPersonalityGenerator()
+//	pg.SetCultureTemplate("iron_legion", pcg.PersonalityTemplate{
+//		Traits: map[pcg.PersonalityAxis]pcg.TraitDistribution{pcg.AxisBravery: {Mean: 0.9, Spread: 0.1}},
+//		Voices: map[string]int{"gruff": 1},
+//	})
+//	profile := pg.Generate(seed, pcg.GenreGrimdark, "iron_legion")
+//
+// The dialogue generator opens lines in the profile's voice (its
+// Speech.Diction) and colors them by its strongest traits.
+//
+// # NPC Relationships
+//
+// FactionGenerator.SeedRelationships gives generated NPCs their starting
+// relationships from the politics of their factions: members trust each
+// other and their leaders, and members of rival factions distrust and fear
+// each other as far as their factions do:
+//
+//	seeded := factions.SeedRelationships(system, npcs, world.RelationshipGraph())
+//
+// # Pantheons
+//
+// DeityGenerator creates the pantheon of a world for its genre variant:
+// deities with an alignment, domains, dogma, a favored weapon and a holy
+// symbol. Grimdark pantheons are small and cruel, high magic ones large and
+// arcane, and every pantheon has a good deity for paladins to serve.
+// PlaceTemples dedicates settlement temples to the deities, with rooms for
+// their domains; WorldGenerator does so when given a "pantheon" constraint.
+// The bootstrap pipeline's pantheon stage stores the pantheon in the world:
+//
+//	manager.SetGenre(pcg.GenreGrimdark)
+//	pantheon, err := manager.GeneratePantheon(ctx)
+//	pcg.PlaceTemples(generatedWorld, pantheon, seed)
+//
+// # Layout Constraints
+//
+// Designers pass hard rules on the rooms of a generated level as
+// LayoutConstraint values: how many rooms of a type it has, a room placed as
+// far from another as the layout allows, or rooms sharing a corridor with
+// another type. The level generator repairs its layout to meet them, and
+// fails with a *ConstraintError whose report explains each violation when
+// it cannot:
+//
+//	one := 1
+//	level, err := manager.GenerateDungeonLevel(ctx, "depths", 6, 10, pcg.ThemeClassic, 8,
+//		pcg.LayoutConstraint{Kind: pcg.ConstraintCount, RoomType: pcg.RoomTypeShop, Min: 1, Max: &one},
+//		pcg.LayoutConstraint{Kind: pcg.ConstraintFarthest, RoomType: pcg.RoomTypeBoss, Other: pcg.RoomTypeEntrance},
+//	)
+//	var violated *pcg.ConstraintError
+//	if errors.As(err, &violated) {
+//		for _, result := range violated.Report.Violations() {
+//			fmt.Println(result.Constraint, result.Detail)
+//		}
+//	}
+//
+// ParseLayoutConstraint reads the compact form the CLI takes, such as
+// "count:shop:1:1" or "adjacent:secret:treasure:2".
+//
+// # Travel Routes
+//
+// A TravelGraph plans routes that cross levels. It joins places, such as
+// the ends of dungeon stairs and overworld settlements, with links that take
+// a set time to travel, and walks between places on the same level at the
+// level's step duration:
+//
+//	graph := manager.GetTravelGraph()
+//	graph.AddWorld(generatedWorld, 0)
+//	graph.Connect(entrance, game.Position{X: 1, Y: 1, Level: 1}, string(pcg.ConnectionStairs), time.Minute, false)
+//	route, err := graph.Route(from, to)
+//
+// Routes prefer the fastest way, then the least dangerous, so travellers
+// keep to roads where they save time. ErrNoTravelRoute means the levels of
+// the two positions are not connected.
+//
 // # Subpackages
 //
 // Specialized generators in subpackages:
@@ -88,5 +362,5 @@
 //   - pcg/items: Equipment generation with enchantments
 //   - pcg/levels: Room and corridor dungeon layouts
 //   - pcg/quests: Quest objective and narrative generation
-//   - pcg/utils: Pathfinding, noise, and utility functions
+//   - pcg/utils: Pathfinding, noise, random number generators and utility functions
 package pcg
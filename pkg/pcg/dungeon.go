@@ -66,6 +66,37 @@ const (
 	ConnectionTunnel   ConnectionType = "tunnel"
 )
 
+// validConnectionTypes lists the connection types themes may weight
+var validConnectionTypes = map[ConnectionType]bool{
+	ConnectionStairs:   true,
+	ConnectionElevator: true,
+	ConnectionPortal:   true,
+	ConnectionPit:      true,
+	ConnectionLadder:   true,
+	ConnectionTunnel:   true,
+}
+
+// defaultConnectionWeights favors stairs; themes override individual weights
+var defaultConnectionWeights = map[ConnectionType]int{
+	ConnectionStairs: 60,
+	ConnectionLadder: 20,
+	ConnectionPit:    10,
+	ConnectionTunnel: 5,
+}
+
+// defaultRoomTypeWeights are the weights of the room types placed after the
+// entrance; themes override individual weights
+var defaultRoomTypeWeights = map[RoomType]int{
+	RoomTypeCombat:   40,
+	RoomTypeTreasure: 15,
+	RoomTypePuzzle:   10,
+	RoomTypeShop:     5,
+	RoomTypeRest:     5,
+	RoomTypeTrap:     10,
+	RoomTypeStory:    10,
+	RoomTypeSecret:   5,
+}
+
 // DifficultyProgression defines how difficulty scales across levels
 type DifficultyProgression struct {
 	BaseDifficulty  int     `json:"base_difficulty"`
@@ -109,7 +140,7 @@ func (dg *DungeonGenerator) Generate(ctx context.Context, params GenerationParam
 	}
 
 	// Initialize RNG with provided seed for deterministic generation
-	dg.rng = rand.New(rand.NewSource(params.Seed))
+	dg.rng = NewRNG(params.Seed, "dungeon")
 
 	dg.logger.WithFields(logrus.Fields{
 		"levels":     dungeonParams.LevelCount,
@@ -123,6 +154,7 @@ func (dg *DungeonGenerator) Generate(ctx context.Context, params GenerationParam
 	}
 
 	dg.logger.WithField("dungeon_id", dungeon.ID).Info("dungeon complex generation completed")
+	params.ReportProgress(ContentTypeDungeon, ProgressStageComplete, 100)
 	return dungeon, nil
 }
 
@@ -141,20 +173,20 @@ func (dg *DungeonGenerator) generateDungeonComplex(ctx context.Context, params G
 
 	// Generate individual levels
 	for level := 1; level <= dungeonParams.LevelCount; level++ {
-		levelDifficulty := dg.calculateLevelDifficulty(level, dungeonParams.Difficulty)
-
-		dungeonLevel, err := dg.generateDungeonLevel(ctx, level, levelDifficulty, params, dungeonParams)
+		dungeonLevel, err := dg.generateLevel(ctx, level, params, dungeonParams)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate level %d: %w", level, err)
 		}
 
 		dungeon.Levels[level] = dungeonLevel
+		params.ReportProgress(ContentTypeDungeon, fmt.Sprintf("level_%d", level), 90*float64(level)/float64(dungeonParams.LevelCount))
 	}
 
 	// Create connections between levels
-	if err := dg.createLevelConnections(dungeon, dungeonParams); err != nil {
+	if err := dg.createLevelConnections(dungeon, params, dungeonParams); err != nil {
 		return nil, fmt.Errorf("failed to create level connections: %w", err)
 	}
+	params.ReportProgress(ContentTypeDungeon, "connections", 95)
 
 	// Add metadata for debugging and validation
 	dungeon.Metadata["total_rooms"] = dg.countTotalRooms(dungeon)
@@ -164,6 +196,62 @@ func (dg *DungeonGenerator) generateDungeonComplex(ctx context.Context, params G
 	return dungeon, nil
 }
 
+// GenerateLevel creates one level of the dungeon complex described by
+// params, identical to the level Generate would produce for the same seed
+// before level connections are added. Each level draws from its own seed,
+// so levels can be generated, discarded and regenerated independently.
+//
+// Parameters:
+//   - ctx: Cancels generation
+//   - params: The dungeon's generation parameters, with dungeon_params in
+//     Constraints
+//   - level: The level number, from 1 to the dungeon's level count
+//
+// Returns:
+//   - *DungeonLevel: The generated level
+//   - error: Invalid parameters or level number
+func (dg *DungeonGenerator) GenerateLevel(ctx context.Context, params GenerationParams, level int) (*DungeonLevel, error) {
+	if err := dg.Validate(params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+	dungeonParams := params.Constraints["dungeon_params"].(DungeonParams)
+	if level < 1 || level > dungeonParams.LevelCount {
+		return nil, fmt.Errorf("level %d is outside the dungeon's levels 1-%d", level, dungeonParams.LevelCount)
+	}
+	return dg.generateLevel(ctx, level, params, dungeonParams)
+}
+
+// generateLevel creates a level from its own seed, leaving the dungeon-wide
+// random sequence untouched
+func (dg *DungeonGenerator) generateLevel(ctx context.Context, level int, params GenerationParams, dungeonParams DungeonParams) (*DungeonLevel, error) {
+	var dungeonLevel *DungeonLevel
+	var err error
+	dg.withSeed(levelSeed(params.Seed, level), func() {
+		levelDifficulty := dg.calculateLevelDifficulty(level, dungeonParams.Difficulty)
+		dungeonLevel, err = dg.generateDungeonLevel(ctx, level, levelDifficulty, params, dungeonParams)
+	})
+	return dungeonLevel, err
+}
+
+// withSeed runs fn with the generator drawing from a fresh sequence seeded
+// with seed, then restores the previous sequence
+func (dg *DungeonGenerator) withSeed(seed int64, fn func()) {
+	previous := dg.rng
+	dg.rng = NewRNG(seed, "dungeon")
+	defer func() { dg.rng = previous }()
+	fn()
+}
+
+// levelSeed derives the seed of one dungeon level
+func levelSeed(seed int64, level int) int64 {
+	return seed + int64(level)
+}
+
+// connectionSeed derives the seed of the connection between two levels
+func connectionSeed(seed int64, fromLevel, toLevel int) int64 {
+	return seed ^ (int64(fromLevel)<<32 | int64(toLevel)) ^ 0x5bd1e995
+}
+
 // generateDungeonLevel creates a single level with basic room layout
 func (dg *DungeonGenerator) generateDungeonLevel(ctx context.Context, levelNum, difficulty int, params GenerationParams, dungeonParams DungeonParams) (*DungeonLevel, error) {
 	// Create a basic game map for this level
@@ -205,8 +293,7 @@ func (dg *DungeonGenerator) generateDungeonLevel(ctx context.Context, levelNum,
 
 	// Add level-specific properties
 	dungeonLevel.Properties["room_count"] = len(rooms)
-	dungeonLevel.Properties["generated_at"] = time.Now()
-	dungeonLevel.Properties["level_seed"] = params.Seed + int64(levelNum)
+	dungeonLevel.Properties["level_seed"] = levelSeed(params.Seed, levelNum)
 
 	return dungeonLevel, nil
 }
@@ -372,43 +459,26 @@ func (dg *DungeonGenerator) determineRoomType(index int, theme LevelTheme) RoomT
 		return RoomTypeEntrance
 	}
 
-	// Use weighted random selection for other room types
-	weights := map[RoomType]int{
-		RoomTypeCombat:   40,
-		RoomTypeTreasure: 15,
-		RoomTypePuzzle:   10,
-		RoomTypeShop:     5,
-		RoomTypeRest:     5,
-		RoomTypeTrap:     10,
-		RoomTypeStory:    10,
-		RoomTypeSecret:   5,
-	}
-
-	// Adjust weights based on theme
-	switch theme {
-	case ThemeHorror:
-		weights[RoomTypeTrap] = 20
-		weights[RoomTypeCombat] = 50
-	case ThemeMagical:
-		weights[RoomTypePuzzle] = 20
-		weights[RoomTypeShop] = 10
-	}
-
-	return dg.weightedRandomRoomType(weights)
+	// Use weighted random selection for other room types, adjusted by the theme
+	return dg.weightedRandomRoomType(themeDefinition(theme).RoomTypeWeights())
 }
 
 // weightedRandomRoomType selects a room type using weighted random selection
 func (dg *DungeonGenerator) weightedRandomRoomType(weights map[RoomType]int) RoomType {
+	roomTypes := make([]RoomType, 0, len(weights))
 	totalWeight := 0
-	for _, weight := range weights {
+	for roomType, weight := range weights {
+		roomTypes = append(roomTypes, roomType)
 		totalWeight += weight
 	}
+	// Walk the weights in a fixed order so a seed always picks the same type
+	sort.Slice(roomTypes, func(i, j int) bool { return roomTypes[i] < roomTypes[j] })
 
 	randomValue := dg.rng.Intn(totalWeight)
 	currentWeight := 0
 
-	for roomType, weight := range weights {
-		currentWeight += weight
+	for _, roomType := range roomTypes {
+		currentWeight += weights[roomType]
 		if randomValue < currentWeight {
 			return roomType
 		}
@@ -418,7 +488,7 @@ func (dg *DungeonGenerator) weightedRandomRoomType(weights map[RoomType]int) Roo
 }
 
 // createLevelConnections establishes connections between dungeon levels
-func (dg *DungeonGenerator) createLevelConnections(dungeon *DungeonComplex, params DungeonParams) error {
+func (dg *DungeonGenerator) createLevelConnections(dungeon *DungeonComplex, params GenerationParams, dungeonParams DungeonParams) error {
 	levels := make([]int, 0, len(dungeon.Levels))
 	for levelNum := range dungeon.Levels {
 		levels = append(levels, levelNum)
@@ -430,9 +500,9 @@ func (dg *DungeonGenerator) createLevelConnections(dungeon *DungeonComplex, para
 		fromLevel := levels[i]
 		toLevel := levels[i+1]
 
-		connection, err := dg.createLevelConnection(dungeon.Levels[fromLevel], dungeon.Levels[toLevel], params)
+		connection, err := dg.connectLevels(dungeon.Levels[fromLevel], dungeon.Levels[toLevel], params.Seed, dungeonParams)
 		if err != nil {
-			return fmt.Errorf("failed to create connection from level %d to %d: %w", fromLevel, toLevel, err)
+			return err
 		}
 
 		dungeon.Connections = append(dungeon.Connections, *connection)
@@ -440,7 +510,7 @@ func (dg *DungeonGenerator) createLevelConnections(dungeon *DungeonComplex, para
 
 	// Add occasional skip connections for complexity (e.g., level 1 to level 3)
 	if len(levels) > 3 && dg.rng.Float64() < 0.3 {
-		skipConnection, err := dg.createSkipConnection(dungeon, levels, params)
+		skipConnection, err := dg.createSkipConnection(dungeon, levels, dungeonParams)
 		if err == nil {
 			dungeon.Connections = append(dungeon.Connections, *skipConnection)
 		}
@@ -449,6 +519,42 @@ func (dg *DungeonGenerator) createLevelConnections(dungeon *DungeonComplex, para
 	return nil
 }
 
+// ConnectLevels creates the connection between two levels of a dungeon
+// and adds its connection points to both levels. The connection is drawn
+// from its own seed, so connecting the same pair of levels always gives
+// the same result.
+//
+// Parameters:
+//   - params: The dungeon's generation parameters, with dungeon_params in
+//     Constraints
+//   - fromLevel: The upper level
+//   - toLevel: The lower level
+//
+// Returns:
+//   - *LevelConnection: The connection
+//   - error: Invalid parameters, or one of the levels has no room able to
+//     hold a connection
+func (dg *DungeonGenerator) ConnectLevels(params GenerationParams, fromLevel, toLevel *DungeonLevel) (*LevelConnection, error) {
+	dungeonParams, ok := params.Constraints["dungeon_params"].(DungeonParams)
+	if !ok {
+		return nil, fmt.Errorf("invalid parameters: expected dungeon_params in constraints")
+	}
+	return dg.connectLevels(fromLevel, toLevel, params.Seed, dungeonParams)
+}
+
+// connectLevels creates a connection from the connection's own seed
+func (dg *DungeonGenerator) connectLevels(fromLevel, toLevel *DungeonLevel, seed int64, params DungeonParams) (*LevelConnection, error) {
+	var connection *LevelConnection
+	var err error
+	dg.withSeed(connectionSeed(seed, fromLevel.Level, toLevel.Level), func() {
+		connection, err = dg.createLevelConnection(fromLevel, toLevel, params)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection from level %d to %d: %w", fromLevel.Level, toLevel.Level, err)
+	}
+	return connection, nil
+}
+
 // createLevelConnection creates a connection between two adjacent levels
 func (dg *DungeonGenerator) createLevelConnection(fromLevel, toLevel *DungeonLevel, params DungeonParams) (*LevelConnection, error) {
 	// Find suitable positions for connections in both levels
@@ -538,21 +644,22 @@ func (dg *DungeonGenerator) findConnectionPosition(level *DungeonLevel, connType
 
 // Helper methods
 
+// themeDefinition returns the registered definition of theme, falling back
+// to the classic theme for themes that are not registered
+func themeDefinition(theme LevelTheme) *ThemeDefinition {
+	if def, exists := Themes().Get(theme); exists {
+		return def
+	}
+	return builtinThemes[ThemeClassic]
+}
+
 // generateDungeonName creates a thematic name for the dungeon
 func (dg *DungeonGenerator) generateDungeonName(theme LevelTheme) string {
-	prefixes := map[LevelTheme][]string{
-		ThemeClassic:    {"Ancient", "Forgotten", "Lost", "Hidden"},
-		ThemeHorror:     {"Cursed", "Haunted", "Nightmare", "Shadow"},
-		ThemeNatural:    {"Living", "Verdant", "Root", "Grove"},
-		ThemeMechanical: {"Clockwork", "Steam", "Gear", "Iron"},
-		ThemeMagical:    {"Arcane", "Crystal", "Ethereal", "Mystic"},
-		ThemeUndead:     {"Bone", "Death", "Tomb", "Crypt"},
-		ThemeElemental:  {"Elemental", "Primal", "Storm", "Flame"},
-	}
+	prefixes := themeDefinition(theme).NamePrefixes
 
 	suffixes := []string{"Depths", "Chambers", "Caverns", "Halls", "Passages", "Tunnels", "Labyrinth", "Dungeon"}
 
-	prefix := prefixes[theme][dg.rng.Intn(len(prefixes[theme]))]
+	prefix := prefixes[dg.rng.Intn(len(prefixes))]
 	suffix := suffixes[dg.rng.Intn(len(suffixes))]
 
 	return fmt.Sprintf("%s %s", prefix, suffix)
@@ -573,42 +680,25 @@ func (dg *DungeonGenerator) calculateLevelDifficulty(level int, progression Diff
 
 // chooseConnectionType selects appropriate connection type based on context
 func (dg *DungeonGenerator) chooseConnectionType(fromLevel, toLevel *DungeonLevel, theme LevelTheme) ConnectionType {
-	// Default to stairs for most themes
-	weights := map[ConnectionType]int{
-		ConnectionStairs: 60,
-		ConnectionLadder: 20,
-		ConnectionPit:    10,
-		ConnectionTunnel: 5,
-	}
-
-	// Adjust weights based on theme
-	switch theme {
-	case ThemeMechanical:
-		weights[ConnectionElevator] = 30
-		weights[ConnectionStairs] = 40
-	case ThemeMagical:
-		weights[ConnectionPortal] = 25
-		weights[ConnectionStairs] = 45
-	case ThemeNatural:
-		weights[ConnectionLadder] = 40
-		weights[ConnectionTunnel] = 20
-	}
-
-	return dg.weightedRandomConnection(weights)
+	// Stairs by default, adjusted by the theme
+	return dg.weightedRandomConnection(themeDefinition(theme).ConnectionTypeWeights())
 }
 
 // weightedRandomConnection selects a connection type using weighted random selection
 func (dg *DungeonGenerator) weightedRandomConnection(weights map[ConnectionType]int) ConnectionType {
+	connTypes := make([]ConnectionType, 0, len(weights))
 	totalWeight := 0
-	for _, weight := range weights {
+	for connType, weight := range weights {
+		connTypes = append(connTypes, connType)
 		totalWeight += weight
 	}
+	sort.Slice(connTypes, func(i, j int) bool { return connTypes[i] < connTypes[j] })
 
 	randomValue := dg.rng.Intn(totalWeight)
 	currentWeight := 0
 
-	for connType, weight := range weights {
-		currentWeight += weight
+	for _, connType := range connTypes {
+		currentWeight += weights[connType]
 		if randomValue < currentWeight {
 			return connType
 		}
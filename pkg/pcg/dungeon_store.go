@@ -0,0 +1,336 @@
+package pcg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelCachePolicy bounds the dungeon levels a DungeonLevelStore keeps in
+// memory. Evicted levels stay persisted and are loaded again on the next
+// visit.
+type LevelCachePolicy struct {
+	MaxResident int           // Most levels kept in memory (0 = unlimited)
+	IdleTTL     time.Duration // Evict levels not visited for this long (0 = never)
+}
+
+// DefaultLevelCachePolicy keeps the current level and its neighbours in
+// memory and drops levels left alone for ten minutes.
+func DefaultLevelCachePolicy() LevelCachePolicy {
+	return LevelCachePolicy{
+		MaxResident: 3,
+		IdleTTL:     10 * time.Minute,
+	}
+}
+
+// dungeonManifest is the persisted record of a lazily generated dungeon
+type dungeonManifest struct {
+	Seed        int64             `yaml:"seed"`
+	LevelCount  int               `yaml:"level_count"`
+	Connections []LevelConnection `yaml:"connections"`
+}
+
+// residentLevel is a level held in memory
+type residentLevel struct {
+	level   *DungeonLevel
+	visited time.Time
+}
+
+// DungeonLevelStore generates the levels of a dungeon complex one at a
+// time as they are visited instead of generating the whole complex up
+// front. Each level is persisted when it is generated, kept in memory while
+// it is visited recently, and loaded back from the store after eviction.
+// A level whose record is missing or unreadable is regenerated from the
+// dungeon seed, including its connections, so it comes back unchanged.
+//
+// Levels are connected to the level above them the first time they are
+// generated; the skip connections of eagerly generated complexes are not
+// created.
+//
+// DungeonLevelStore is safe for concurrent use.
+type DungeonLevelStore struct {
+	mu          sync.Mutex
+	store       persistence.Store
+	prefix      string
+	generator   *DungeonGenerator
+	params      GenerationParams
+	levelCount  int
+	policy      LevelCachePolicy
+	connections []LevelConnection
+	resident    map[int]*residentLevel
+	now         func() time.Time
+}
+
+// NewDungeonLevelStore opens the dungeon stored under prefix, creating its
+// manifest if the dungeon is new.
+//
+// Parameters:
+//   - store: Where levels are persisted
+//   - prefix: Key prefix of the dungeon's records, such as "dungeons/crypt/"
+//   - params: The dungeon's generation parameters, with dungeon_params in
+//     Constraints
+//   - policy: Bounds on the levels kept in memory
+//
+// Returns:
+//   - *DungeonLevelStore: The store, with no level in memory
+//   - error: Invalid parameters, a manifest for a different seed, or a
+//     persistence failure
+func NewDungeonLevelStore(store persistence.Store, prefix string, params GenerationParams, policy LevelCachePolicy) (*DungeonLevelStore, error) {
+	generator := NewDungeonGenerator(logrus.StandardLogger())
+	if err := generator.Validate(params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+
+	s := &DungeonLevelStore{
+		store:      store,
+		prefix:     prefix,
+		generator:  generator,
+		params:     params,
+		levelCount: params.Constraints["dungeon_params"].(DungeonParams).LevelCount,
+		policy:     policy,
+		resident:   make(map[int]*residentLevel),
+		now:        time.Now,
+	}
+
+	if store.Exists(s.manifestKey()) {
+		var manifest dungeonManifest
+		if err := store.Load(s.manifestKey(), &manifest); err != nil {
+			return nil, fmt.Errorf("failed to load dungeon manifest: %w", err)
+		}
+		if manifest.Seed != params.Seed || manifest.LevelCount != s.levelCount {
+			return nil, fmt.Errorf("dungeon under %q was generated with seed %d and %d levels, not seed %d and %d levels",
+				prefix, manifest.Seed, manifest.LevelCount, params.Seed, s.levelCount)
+		}
+		s.connections = manifest.Connections
+		return s, nil
+	}
+
+	if err := s.saveManifest(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Level returns a level of the dungeon, loading or generating it if it is
+// not in memory, and records the visit. Other levels may be evicted from
+// memory as a result.
+//
+// Parameters:
+//   - ctx: Cancels generation
+//   - level: The level number, from 1 to the dungeon's level count
+//
+// Returns:
+//   - *DungeonLevel: The level, shared with later callers until evicted
+//   - error: An invalid level number, or a generation or persistence failure
+func (s *DungeonLevelStore) Level(ctx context.Context, level int) (*DungeonLevel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dungeonLevel, err := s.levelLocked(ctx, level)
+	if err != nil {
+		return nil, err
+	}
+	s.resident[level].visited = s.now()
+	s.evictLocked(level)
+	return dungeonLevel, nil
+}
+
+// Connections returns the connections created between the levels generated
+// so far, in creation order.
+func (s *DungeonLevelStore) Connections() []LevelConnection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]LevelConnection(nil), s.connections...)
+}
+
+// Resident returns the numbers of the levels currently in memory, in
+// ascending order.
+func (s *DungeonLevelStore) Resident() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levels := make([]int, 0, len(s.resident))
+	for level := range s.resident {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	return levels
+}
+
+// EvictIdle drops the levels that have not been visited within the
+// policy's IdleTTL from memory.
+//
+// Returns:
+//   - int: The number of levels evicted
+func (s *DungeonLevelStore) EvictIdle() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.resident)
+	s.evictLocked(0)
+	return before - len(s.resident)
+}
+
+// levelLocked returns a level, loading or generating it and connecting it
+// to the level above. The caller must hold s.mu.
+func (s *DungeonLevelStore) levelLocked(ctx context.Context, level int) (*DungeonLevel, error) {
+	if level < 1 || level > s.levelCount {
+		return nil, fmt.Errorf("level %d is outside the dungeon's levels 1-%d", level, s.levelCount)
+	}
+	if r, exists := s.resident[level]; exists {
+		return r.level, nil
+	}
+
+	dungeonLevel, err := s.loadLevel(ctx, level)
+	if err != nil {
+		return nil, err
+	}
+	s.resident[level] = &residentLevel{level: dungeonLevel, visited: s.now()}
+
+	if level > 1 && !s.connectedLocked(level-1, level) {
+		if err := s.connectLocked(ctx, level); err != nil {
+			delete(s.resident, level)
+			return nil, err
+		}
+	}
+	return dungeonLevel, nil
+}
+
+// loadLevel reads a level from the store, regenerating it if its record is
+// missing or unreadable
+func (s *DungeonLevelStore) loadLevel(ctx context.Context, level int) (*DungeonLevel, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "loadLevel",
+		"prefix":   s.prefix,
+		"level":    level,
+	})
+
+	key := s.levelKey(level)
+	if s.store.Exists(key) {
+		var dungeonLevel DungeonLevel
+		err := s.store.Load(key, &dungeonLevel)
+		if err == nil {
+			return &dungeonLevel, nil
+		}
+		logger.WithError(err).Warn("failed to load dungeon level, regenerating it")
+	}
+
+	dungeonLevel, err := s.generator.GenerateLevel(ctx, s.params, level)
+	if err != nil {
+		return nil, err
+	}
+	for _, conn := range s.connections {
+		switch level {
+		case conn.FromLevel:
+			dungeonLevel.Connections = append(dungeonLevel.Connections, connectionPoint(conn.FromPosition, conn.Type, conn.ToLevel))
+		case conn.ToLevel:
+			dungeonLevel.Connections = append(dungeonLevel.Connections, connectionPoint(conn.ToPosition, conn.Type, conn.FromLevel))
+		}
+	}
+	if err := s.store.Save(key, dungeonLevel); err != nil {
+		return nil, fmt.Errorf("failed to save dungeon level %d: %w", level, err)
+	}
+	logger.Info("generated dungeon level")
+	return dungeonLevel, nil
+}
+
+// connectLocked connects a newly generated level to the level above it and
+// persists both levels and the manifest. The caller must hold s.mu.
+func (s *DungeonLevelStore) connectLocked(ctx context.Context, level int) error {
+	upper, err := s.levelLocked(ctx, level-1)
+	if err != nil {
+		return err
+	}
+	lower := s.resident[level].level
+
+	conn, err := s.generator.ConnectLevels(s.params, upper, lower)
+	if err != nil {
+		return err
+	}
+	s.connections = append(s.connections, *conn)
+
+	if err := s.store.SaveBatch(map[string]interface{}{
+		s.levelKey(level - 1): upper,
+		s.levelKey(level):     lower,
+		s.manifestKey():       s.manifest(),
+	}); err != nil {
+		return fmt.Errorf("failed to save connection from level %d to %d: %w", level-1, level, err)
+	}
+	return nil
+}
+
+// connectedLocked reports whether two levels have been connected
+func (s *DungeonLevelStore) connectedLocked(fromLevel, toLevel int) bool {
+	for _, conn := range s.connections {
+		if conn.FromLevel == fromLevel && conn.ToLevel == toLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// evictLocked drops idle levels, then the least recently visited levels
+// beyond MaxResident. The level being visited is never evicted.
+func (s *DungeonLevelStore) evictLocked(visiting int) {
+	now := s.now()
+	if s.policy.IdleTTL > 0 {
+		for level, r := range s.resident {
+			if level != visiting && now.Sub(r.visited) > s.policy.IdleTTL {
+				delete(s.resident, level)
+			}
+		}
+	}
+
+	for s.policy.MaxResident > 0 && len(s.resident) > s.policy.MaxResident {
+		oldest := 0
+		for level, r := range s.resident {
+			if level != visiting && (oldest == 0 || r.visited.Before(s.resident[oldest].visited)) {
+				oldest = level
+			}
+		}
+		if oldest == 0 {
+			return
+		}
+		delete(s.resident, oldest)
+	}
+}
+
+func (s *DungeonLevelStore) manifest() dungeonManifest {
+	return dungeonManifest{
+		Seed:        s.params.Seed,
+		LevelCount:  s.levelCount,
+		Connections: s.connections,
+	}
+}
+
+func (s *DungeonLevelStore) saveManifest() error {
+	if err := s.store.Save(s.manifestKey(), s.manifest()); err != nil {
+		return fmt.Errorf("failed to save dungeon manifest: %w", err)
+	}
+	return nil
+}
+
+func (s *DungeonLevelStore) manifestKey() string {
+	return s.prefix + "manifest.yaml"
+}
+
+func (s *DungeonLevelStore) levelKey(level int) string {
+	return fmt.Sprintf("%slevel_%02d.yaml", s.prefix, level)
+}
+
+// connectionPoint builds the connection point a level holds for conn
+func connectionPoint(pos game.Position, connType ConnectionType, targetLevel int) ConnectionPoint {
+	return ConnectionPoint{
+		Position:    pos,
+		Type:        connType,
+		TargetLevel: targetLevel,
+		Properties:  make(map[string]interface{}),
+	}
+}
@@ -0,0 +1,175 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lazyDungeonParams returns the parameters of a small four-level dungeon
+func lazyDungeonParams(seed int64) GenerationParams {
+	return GenerationParams{
+		Seed:       seed,
+		Difficulty: 3,
+		WorldState: &game.World{},
+		Constraints: map[string]interface{}{
+			"dungeon_params": DungeonParams{
+				LevelCount:    4,
+				LevelWidth:    40,
+				LevelHeight:   40,
+				RoomsPerLevel: 5,
+				Theme:         ThemeClassic,
+				Connectivity:  ConnectivityModerate,
+				Density:       0.5,
+				Difficulty: DifficultyProgression{
+					BaseDifficulty:  2,
+					ScalingFactor:   1.5,
+					MaxDifficulty:   10,
+					ProgressionType: "linear",
+				},
+			},
+		},
+	}
+}
+
+// assertSameLevel compares the generated content of two levels
+func assertSameLevel(t *testing.T, want, got *DungeonLevel) {
+	t.Helper()
+	assert.Equal(t, want.Level, got.Level)
+	assert.Equal(t, want.Map, got.Map)
+	assert.Equal(t, want.Rooms, got.Rooms)
+	assert.Equal(t, want.Connections, got.Connections)
+}
+
+func TestDungeonGenerator_GenerateLevelMatchesGenerate(t *testing.T) {
+	params := lazyDungeonParams(4242)
+	result, err := NewDungeonGenerator(nil).Generate(context.Background(), params)
+	require.NoError(t, err)
+	dungeon := result.(*DungeonComplex)
+
+	generator := NewDungeonGenerator(nil)
+	for levelNum, eager := range dungeon.Levels {
+		level, err := generator.GenerateLevel(context.Background(), params, levelNum)
+		require.NoError(t, err)
+		assert.Equal(t, eager.Map, level.Map, "level %d map", levelNum)
+		assert.Equal(t, eager.Rooms, level.Rooms, "level %d rooms", levelNum)
+	}
+
+	_, err = generator.GenerateLevel(context.Background(), params, 5)
+	assert.Error(t, err)
+}
+
+func TestDungeonLevelStore_GeneratesLevelsOnDescent(t *testing.T) {
+	params := lazyDungeonParams(777)
+	store := persistence.NewMemoryStore()
+	levels, err := NewDungeonLevelStore(store, "dungeons/crypt/", params, LevelCachePolicy{MaxResident: 2})
+	require.NoError(t, err)
+
+	assert.False(t, store.Exists("dungeons/crypt/level_01.yaml"))
+
+	for _, n := range []int{1, 2, 3} {
+		level, err := levels.Level(context.Background(), n)
+		require.NoError(t, err)
+		assert.Equal(t, n, level.Level)
+	}
+
+	assert.Equal(t, []int{2, 3}, levels.Resident(), "the least recently visited level is evicted")
+	assert.True(t, store.Exists("dungeons/crypt/level_03.yaml"))
+	assert.False(t, store.Exists("dungeons/crypt/level_04.yaml"), "levels below the deepest visit are not generated")
+
+	// The lazily generated levels and connections match an eager generation
+	result, err := NewDungeonGenerator(nil).Generate(context.Background(), params)
+	require.NoError(t, err)
+	eager := result.(*DungeonComplex)
+	require.Len(t, levels.Connections(), 2)
+	assert.Equal(t, eager.Connections[:2], levels.Connections())
+	level1, err := levels.Level(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, eager.Levels[1].Map, level1.Map)
+	assert.Equal(t, eager.Levels[1].Rooms, level1.Rooms)
+}
+
+func TestDungeonLevelStore_DescendingDeepConnectsLevelsAbove(t *testing.T) {
+	levels, err := NewDungeonLevelStore(persistence.NewMemoryStore(), "", lazyDungeonParams(99), LevelCachePolicy{})
+	require.NoError(t, err)
+
+	level, err := levels.Level(context.Background(), 4)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2, 3, 4}, levels.Resident())
+	require.Len(t, level.Connections, 1)
+	assert.Equal(t, 3, level.Connections[0].TargetLevel)
+	assert.Len(t, levels.Connections(), 3)
+
+	_, err = levels.Level(context.Background(), 0)
+	assert.Error(t, err)
+}
+
+func TestDungeonLevelStore_ReloadsAndRegeneratesEvictedLevels(t *testing.T) {
+	store, err := persistence.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	levels, err := NewDungeonLevelStore(store, "dungeons/deep/", lazyDungeonParams(31337), LevelCachePolicy{MaxResident: 1})
+	require.NoError(t, err)
+
+	_, err = levels.Level(context.Background(), 2)
+	require.NoError(t, err)
+	_, err = levels.Level(context.Background(), 3)
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, levels.Resident())
+
+	// Level 2 gained its connection to level 3 after the first visit
+	first, err := levels.Level(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, first.Connections, 2)
+
+	_, err = levels.Level(context.Background(), 3)
+	require.NoError(t, err)
+	reloaded, err := levels.Level(context.Background(), 2)
+	require.NoError(t, err)
+	assertSameLevel(t, first, reloaded)
+
+	_, err = levels.Level(context.Background(), 3)
+	require.NoError(t, err)
+	require.NoError(t, store.Delete("dungeons/deep/level_02.yaml"))
+	regenerated, err := levels.Level(context.Background(), 2)
+	require.NoError(t, err)
+	assertSameLevel(t, first, regenerated)
+	assert.True(t, store.Exists("dungeons/deep/level_02.yaml"))
+
+	// A reopened store resumes the dungeon from its manifest
+	reopened, err := NewDungeonLevelStore(store, "dungeons/deep/", lazyDungeonParams(31337), LevelCachePolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, levels.Connections(), reopened.Connections())
+	assert.Empty(t, reopened.Resident())
+
+	_, err = NewDungeonLevelStore(store, "dungeons/deep/", lazyDungeonParams(1), LevelCachePolicy{})
+	assert.ErrorContains(t, err, "seed 31337")
+}
+
+func TestDungeonLevelStore_EvictsIdleLevels(t *testing.T) {
+	levels, err := NewDungeonLevelStore(persistence.NewMemoryStore(), "", lazyDungeonParams(5), LevelCachePolicy{IdleTTL: time.Minute})
+	require.NoError(t, err)
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	levels.now = func() time.Time { return clock }
+
+	_, err = levels.Level(context.Background(), 1)
+	require.NoError(t, err)
+	clock = clock.Add(45 * time.Second)
+	_, err = levels.Level(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, levels.Resident())
+
+	clock = clock.Add(30 * time.Second)
+	assert.Equal(t, 1, levels.EvictIdle())
+	assert.Equal(t, []int{2}, levels.Resident())
+
+	clock = clock.Add(time.Hour)
+	assert.Equal(t, 1, levels.EvictIdle())
+	assert.Empty(t, levels.Resident())
+}
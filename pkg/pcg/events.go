@@ -24,6 +24,8 @@ const (
 	EventPCGContentRequest
 	// EventPCGSystemHealth is emitted for system health monitoring
 	EventPCGSystemHealth
+	// EventPCGContentIntegrated is emitted when an integration commits
+	EventPCGContentIntegrated
 )
 
 // PCGEventData contains common data structures for PCG events
@@ -41,7 +41,7 @@ func (fg *FactionGenerator) Generate(ctx context.Context, params GenerationParam
 	}
 
 	// Use seed for deterministic generation
-	rng := rand.New(rand.NewSource(params.Seed))
+	rng := NewRNG(params.Seed, "factions")
 	fg.rng = rng
 
 	factionParams, ok := params.Constraints["faction_params"].(FactionParams)
@@ -651,3 +651,96 @@ func (fg *FactionGenerator) generateConflictCause() string {
 	}
 	return causes[fg.rng.Intn(len(causes))]
 }
+
+// Seeded NPC relationship scores
+const (
+	seedFactionmateTrust = 30 // Members of the same faction
+	seedLeaderTrust      = 50 // Members for their faction's leader
+	seedLeaderFear       = 20
+	seedRivalTrustScale  = 40 // Per point of opinion between factions
+	seedRivalFearScale   = 50 // Per point of hostility between factions
+)
+
+// SeedRelationships seeds how generated NPCs feel about each other from
+// the faction system they belong to, so that the relationship graph
+// starts out reflecting faction politics before game events move it.
+// Each NPC's Faction is matched against the ID or name of a faction:
+//   - members of the same faction trust each other, and their leaders,
+//     the NPCs named after a faction leader, more and with some fear
+//   - members of different factions trust each other as far as the
+//     factions' opinion and trust of each other go, and fear each other
+//     as far as the factions are hostile
+//
+// NPCs outside any faction, and pairs whose factions have no recorded
+// relationship, are left neutral.
+//
+// Parameters:
+//   - system: The generated faction system
+//   - npcs: The NPCs to seed
+//   - graph: The relationship graph to seed, such as the world's
+//
+// Returns:
+//   - int: The number of relationships seeded
+func (fg *FactionGenerator) SeedRelationships(system *GeneratedFactionSystem, npcs []*game.NPC, graph *game.RelationshipGraph) int {
+	if system == nil || graph == nil {
+		return 0
+	}
+
+	factions := make(map[string]*Faction, len(system.Factions)*2)
+	for _, faction := range system.Factions {
+		factions[faction.ID] = faction
+		factions[faction.Name] = faction
+	}
+	relations := make(map[[2]string]*FactionRelationship, len(system.Relationships)*2)
+	for _, rel := range system.Relationships {
+		relations[[2]string{rel.Faction1ID, rel.Faction2ID}] = rel
+		relations[[2]string{rel.Faction2ID, rel.Faction1ID}] = rel
+	}
+
+	seeded := 0
+	for _, npc := range npcs {
+		own, ok := factions[npc.Faction]
+		if !ok {
+			continue
+		}
+		for _, other := range npcs {
+			theirs, ok := factions[other.Faction]
+			if !ok || other.GetID() == npc.GetID() {
+				continue
+			}
+
+			var rel game.Relationship
+			if own == theirs {
+				rel.Trust = seedFactionmateTrust
+				if isFactionLeader(theirs, other) {
+					rel.Trust, rel.Fear = seedLeaderTrust, seedLeaderFear
+				}
+			} else {
+				relation, ok := relations[[2]string{own.ID, theirs.ID}]
+				if !ok {
+					continue
+				}
+				rel.Trust = int(math.Round(seedRivalTrustScale * (relation.Opinion + relation.TrustLevel - 0.5)))
+				rel.Fear = int(math.Round(seedRivalFearScale * relation.Hostility))
+			}
+			graph.Set(npc.GetID(), other.GetID(), rel)
+			seeded++
+		}
+	}
+
+	fg.logger.WithFields(logrus.Fields{
+		"npcs":          len(npcs),
+		"relationships": seeded,
+	}).Debug("seeded NPC relationships from factions")
+	return seeded
+}
+
+// isFactionLeader reports whether npc is one of the faction's leaders
+func isFactionLeader(faction *Faction, npc *game.NPC) bool {
+	for _, leader := range faction.Leaders {
+		if leader.Name == npc.GetName() || leader.ID == npc.GetID() {
+			return true
+		}
+	}
+	return false
+}
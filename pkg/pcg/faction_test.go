@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"goldbox-rpg/pkg/game"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -482,3 +484,42 @@ func BenchmarkFactionGenerator_LargeSystem(b *testing.B) {
 		}
 	}
 }
+
+func TestFactionGenerator_SeedRelationships(t *testing.T) {
+	fg := NewFactionGenerator(nil)
+	system := &GeneratedFactionSystem{
+		Factions: []*Faction{
+			{ID: "guard", Name: "Town Guard", Leaders: []*FactionLeader{{Name: "Captain Vale"}}},
+			{ID: "thieves", Name: "Thieves Guild"},
+		},
+		Relationships: []*FactionRelationship{
+			{Faction1ID: "guard", Faction2ID: "thieves", Opinion: -0.8, TrustLevel: 0.1, Hostility: 0.9},
+		},
+	}
+	npc := func(id, name, faction string) *game.NPC {
+		return &game.NPC{Character: game.Character{ID: id, Name: name}, Faction: faction}
+	}
+	captain := npc("captain", "Captain Vale", "guard")
+	guard := npc("guard-1", "Watchman", "Town Guard")
+	thief := npc("thief-1", "Cutpurse", "thieves")
+	hermit := npc("hermit", "Hermit", "")
+
+	graph := game.NewRelationshipGraph()
+	if seeded := fg.SeedRelationships(system, []*game.NPC{captain, guard, thief, hermit}, graph); seeded != 6 {
+		t.Errorf("seeded %d relationships, want 6", seeded)
+	}
+
+	if rel := graph.Get("guard-1", "captain"); rel.Trust != seedLeaderTrust || rel.Fear != seedLeaderFear {
+		t.Errorf("guard's view of the captain = %+v, want leader trust and fear", rel)
+	}
+	if rel := graph.Get("captain", "guard-1"); rel.Trust != seedFactionmateTrust {
+		t.Errorf("captain's trust of the guard = %d, want %d", rel.Trust, seedFactionmateTrust)
+	}
+	rel := graph.Get("thief-1", "guard-1")
+	if rel.Trust >= 0 || rel.Fear != 45 {
+		t.Errorf("thief's view of the guard = %+v, want distrust and fear from the factions' hostility", rel)
+	}
+	if len(graph.Of("hermit")) != 0 {
+		t.Error("NPCs outside any faction should stay neutral")
+	}
+}
@@ -0,0 +1,259 @@
+package pcg
+
+import (
+	"math"
+	"sort"
+
+	"goldbox-rpg/pkg/game"
+)
+
+// Heatmap sources
+const (
+	HeatmapSourceDungeon = "dungeon"
+	HeatmapSourceWorld   = "world"
+)
+
+// pacingSpikeThreshold is the difficulty change between neighbouring steps
+// of a progression that counts as a spike
+const pacingSpikeThreshold = 3.0
+
+// difficultyExperience is the encounter budget of one point of difficulty
+// in regions and rooms without placed monsters
+const difficultyExperience = 50
+
+// HeatmapRegion is one cell of a difficulty heatmap: a dungeon room or an
+// overworld region.
+type HeatmapRegion struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name,omitempty"`
+	Type            string    `json:"type"`
+	Level           int       `json:"level"`
+	Bounds          Rectangle `json:"bounds"`
+	Difficulty      int       `json:"difficulty"`
+	EncounterBudget int       `json:"encounter_budget"` // Experience of the creatures placed there
+	Traps           int       `json:"traps"`
+	TrapDensity     float64   `json:"trap_density"` // Traps per 100 tiles
+	LootValue       int       `json:"loot_value"`   // Value of the treasure placed there
+}
+
+// PacingReport scores how smoothly difficulty rises across a progression
+// of levels or neighbouring regions.
+type PacingReport struct {
+	Steps       int       `json:"steps"`                 // Transitions between neighbouring levels or regions
+	MeanStep    float64   `json:"mean_step"`             // Average absolute difficulty change per step
+	MaxJump     float64   `json:"max_jump"`              // Largest absolute difficulty change
+	Spikes      int       `json:"spikes"`                // Steps changing by pacingSpikeThreshold or more
+	Regressions int       `json:"regressions"`           // Dungeon levels easier than the one above
+	Score       float64   `json:"score"`                 // 0 (erratic) to 1 (smooth)
+	LevelCurve  []float64 `json:"level_curve,omitempty"` // Average difficulty of each dungeon level, in order
+}
+
+// DifficultyHeatmap is the difficulty analysis of a generated dungeon or
+// overworld, with regions ordered by level and then generation order.
+type DifficultyHeatmap struct {
+	Source  string          `json:"source"`
+	ID      string          `json:"id"`
+	Regions []HeatmapRegion `json:"regions"`
+	Totals  HeatmapRegion   `json:"totals"`
+	Pacing  PacingReport    `json:"pacing"`
+}
+
+// DungeonHeatmap analyses every room of a dungeon complex. Encounter
+// budgets add up the experience of each room's monsters, trap density
+// counts trap features per 100 tiles, and loot value adds up the treasure
+// value and the items of loot and treasure chests. Pacing follows the
+// average room difficulty of each level from the top down.
+func DungeonHeatmap(dungeon *DungeonComplex) *DifficultyHeatmap {
+	heatmap := &DifficultyHeatmap{Source: HeatmapSourceDungeon, Regions: []HeatmapRegion{}}
+	if dungeon == nil {
+		return heatmap
+	}
+	heatmap.ID = dungeon.ID
+
+	levels := make([]int, 0, len(dungeon.Levels))
+	for number := range dungeon.Levels {
+		levels = append(levels, number)
+	}
+	sort.Ints(levels)
+
+	curve := make([]float64, 0, len(levels))
+	for _, number := range levels {
+		level := dungeon.Levels[number]
+		if level == nil || len(level.Rooms) == 0 {
+			continue
+		}
+		total := 0
+		for _, room := range level.Rooms {
+			region := roomHeatmapRegion(room, number, level.Difficulty)
+			heatmap.Regions = append(heatmap.Regions, region)
+			total += region.Difficulty
+		}
+		curve = append(curve, float64(total)/float64(len(level.Rooms)))
+	}
+
+	heatmap.Totals = heatmapTotals(heatmap.Regions)
+	heatmap.Pacing = levelPacing(curve)
+	return heatmap
+}
+
+// WorldHeatmap analyses every region of an overworld. Regions carry no
+// placed encounters, so their encounter budget and loot value scale with
+// their difficulty. Pacing compares each pair of bordering
+// regions.
+func WorldHeatmap(world *GeneratedWorld) *DifficultyHeatmap {
+	heatmap := &DifficultyHeatmap{Source: HeatmapSourceWorld, Regions: []HeatmapRegion{}}
+	if world == nil {
+		return heatmap
+	}
+	heatmap.ID = world.ID
+
+	var pairs [][2]float64
+	for i, region := range world.Regions {
+		heatmap.Regions = append(heatmap.Regions, HeatmapRegion{
+			ID:              region.ID,
+			Name:            region.Name,
+			Type:            string(region.Biome),
+			Bounds:          region.Bounds,
+			Difficulty:      region.Difficulty,
+			EncounterBudget: region.Difficulty * difficultyExperience,
+			LootValue:       region.Difficulty * 100,
+		})
+		for _, other := range world.Regions[i+1:] {
+			if regionsBorder(region.Bounds, other.Bounds) {
+				pairs = append(pairs, [2]float64{float64(region.Difficulty), float64(other.Difficulty)})
+			}
+		}
+	}
+
+	heatmap.Totals = heatmapTotals(heatmap.Regions)
+	heatmap.Pacing = scorePacing(pairs)
+	return heatmap
+}
+
+// roomHeatmapRegion measures one dungeon room. Rooms without a rating of
+// their own take the difficulty of their level.
+func roomHeatmapRegion(room *RoomLayout, level, levelDifficulty int) HeatmapRegion {
+	region := HeatmapRegion{
+		ID:         room.ID,
+		Type:       string(room.Type),
+		Level:      level,
+		Bounds:     room.Bounds,
+		Difficulty: room.Difficulty,
+	}
+	if region.Difficulty == 0 {
+		region.Difficulty = levelDifficulty
+	}
+
+	if monsters, ok := room.Properties["monsters"].([]*MonsterStatBlock); ok {
+		for _, monster := range monsters {
+			if monster != nil {
+				region.EncounterBudget += monster.Experience
+			}
+		}
+	} else if count, ok := room.Properties["enemy_count"].(int); ok {
+		region.EncounterBudget = count * region.Difficulty * difficultyExperience
+	}
+
+	if value, ok := room.Properties["treasure_value"].(int); ok {
+		region.LootValue += value
+	}
+	region.LootValue += itemsValue(room.Properties["loot"])
+	for _, feature := range room.Features {
+		switch feature.Type {
+		case "trap":
+			region.Traps++
+		case "treasure_chest":
+			region.LootValue += itemsValue(feature.Properties["items"])
+		}
+	}
+
+	if area := room.Bounds.Width * room.Bounds.Height; area > 0 {
+		region.TrapDensity = float64(region.Traps) * 100 / float64(area)
+	}
+	return region
+}
+
+// itemsValue adds up the value of resolved loot, stored as []*game.Item
+func itemsValue(loot interface{}) int {
+	items, _ := loot.([]*game.Item)
+	total := 0
+	for _, item := range items {
+		if item != nil {
+			total += item.Value
+		}
+	}
+	return total
+}
+
+// heatmapTotals sums the regions of a heatmap; its Difficulty is the
+// highest region difficulty and its TrapDensity covers the combined area
+func heatmapTotals(regions []HeatmapRegion) HeatmapRegion {
+	totals := HeatmapRegion{ID: "totals", Type: "totals"}
+	area := 0
+	for _, region := range regions {
+		totals.EncounterBudget += region.EncounterBudget
+		totals.Traps += region.Traps
+		totals.LootValue += region.LootValue
+		if region.Difficulty > totals.Difficulty {
+			totals.Difficulty = region.Difficulty
+		}
+		area += region.Bounds.Width * region.Bounds.Height
+	}
+	if area > 0 {
+		totals.TrapDensity = float64(totals.Traps) * 100 / float64(area)
+	}
+	return totals
+}
+
+// levelPacing scores the difficulty curve of a dungeon's levels, counting
+// levels easier than the one before as regressions
+func levelPacing(curve []float64) PacingReport {
+	pairs := make([][2]float64, 0, len(curve))
+	for i := 1; i < len(curve); i++ {
+		pairs = append(pairs, [2]float64{curve[i-1], curve[i]})
+	}
+	report := scorePacing(pairs)
+	for _, pair := range pairs {
+		if pair[1] < pair[0] {
+			report.Regressions++
+		}
+	}
+	if report.Steps > 0 {
+		report.Score = math.Max(0, report.Score-0.5*float64(report.Regressions)/float64(report.Steps))
+	}
+	report.LevelCurve = curve
+	return report
+}
+
+// scorePacing scores pairs of neighbouring difficulties. A progression
+// without spikes whose steps average one point or less scores 1; spikes
+// cost up to 0.6 and large average steps up to 0.4. Fewer than one step
+// scores 1.
+func scorePacing(pairs [][2]float64) PacingReport {
+	report := PacingReport{Steps: len(pairs), Score: 1}
+	if len(pairs) == 0 {
+		return report
+	}
+
+	total := 0.0
+	for _, pair := range pairs {
+		jump := math.Abs(pair[1] - pair[0])
+		total += jump
+		report.MaxJump = math.Max(report.MaxJump, jump)
+		if jump >= pacingSpikeThreshold {
+			report.Spikes++
+		}
+	}
+	report.MeanStep = total / float64(len(pairs))
+
+	spikePenalty := 0.6 * float64(report.Spikes) / float64(len(pairs))
+	stepPenalty := 0.4 * math.Min(1, math.Max(0, report.MeanStep-1)/(pacingSpikeThreshold-1))
+	report.Score = math.Max(0, 1-spikePenalty-stepPenalty)
+	return report
+}
+
+// regionsBorder reports whether two rectangles overlap or share an edge
+func regionsBorder(a, b Rectangle) bool {
+	return a.X <= b.X+b.Width && b.X <= a.X+a.Width &&
+		a.Y <= b.Y+b.Height && b.Y <= a.Y+a.Height
+}
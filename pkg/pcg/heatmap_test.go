@@ -0,0 +1,131 @@
+package pcg
+
+import (
+	"math"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+)
+
+// heatmapTestDungeon returns a two-level dungeon with one combat, trap and
+// treasure room
+func heatmapTestDungeon(secondLevelDifficulty int) *DungeonComplex {
+	return &DungeonComplex{
+		ID: "crypt",
+		Levels: map[int]*DungeonLevel{
+			1: {Level: 1, Difficulty: 2, Rooms: []*RoomLayout{
+				{
+					ID: "combat", Type: RoomTypeCombat, Bounds: Rectangle{Width: 10, Height: 10}, Difficulty: 2,
+					Properties: map[string]interface{}{
+						"monsters": []*MonsterStatBlock{{Experience: 35}, {Experience: 65}},
+						"loot":     []*game.Item{{Value: 40}},
+					},
+				},
+				{
+					ID: "trap", Type: RoomTypeTrap, Bounds: Rectangle{Width: 5, Height: 4},
+					Features:   []RoomFeature{{Type: "trap"}, {Type: "trap"}},
+					Properties: map[string]interface{}{},
+				},
+			}},
+			2: {Level: 2, Difficulty: secondLevelDifficulty, Rooms: []*RoomLayout{
+				{
+					ID: "treasure", Type: RoomTypeTreasure, Bounds: Rectangle{Width: 6, Height: 6}, Difficulty: secondLevelDifficulty,
+					Features: []RoomFeature{{Type: "treasure_chest", Properties: map[string]interface{}{
+						"items": []*game.Item{{Value: 25}, {Value: 75}},
+					}}},
+					Properties: map[string]interface{}{"treasure_value": 300},
+				},
+			}},
+		},
+	}
+}
+
+func TestDungeonHeatmap_MeasuresRooms(t *testing.T) {
+	heatmap := DungeonHeatmap(heatmapTestDungeon(3))
+
+	if heatmap.Source != HeatmapSourceDungeon || heatmap.ID != "crypt" {
+		t.Fatalf("unexpected heatmap source %q and id %q", heatmap.Source, heatmap.ID)
+	}
+	if len(heatmap.Regions) != 3 {
+		t.Fatalf("expected 3 regions, got %d", len(heatmap.Regions))
+	}
+
+	combat, trap, treasure := heatmap.Regions[0], heatmap.Regions[1], heatmap.Regions[2]
+	if combat.EncounterBudget != 100 || combat.LootValue != 40 {
+		t.Errorf("combat room: budget %d loot %d, expected 100 and 40", combat.EncounterBudget, combat.LootValue)
+	}
+	if trap.Traps != 2 || trap.TrapDensity != 10 {
+		t.Errorf("trap room: %d traps at density %v, expected 2 at 10", trap.Traps, trap.TrapDensity)
+	}
+	if trap.Difficulty != 2 {
+		t.Errorf("an unrated room takes its level's difficulty, got %d", trap.Difficulty)
+	}
+	if treasure.Level != 2 || treasure.LootValue != 400 {
+		t.Errorf("treasure room: level %d loot %d, expected 2 and 400", treasure.Level, treasure.LootValue)
+	}
+
+	if heatmap.Totals.EncounterBudget != 100 || heatmap.Totals.LootValue != 440 || heatmap.Totals.Traps != 2 {
+		t.Errorf("unexpected totals %+v", heatmap.Totals)
+	}
+}
+
+func TestDungeonHeatmap_Pacing(t *testing.T) {
+	smooth := DungeonHeatmap(heatmapTestDungeon(3)).Pacing
+	if smooth.Steps != 1 || smooth.Spikes != 0 || smooth.Regressions != 0 || smooth.Score != 1 {
+		t.Errorf("a one point rise should pace perfectly, got %+v", smooth)
+	}
+
+	spiked := DungeonHeatmap(heatmapTestDungeon(9)).Pacing
+	if spiked.Spikes != 1 || spiked.MaxJump != 7 || spiked.Score >= smooth.Score {
+		t.Errorf("a seven point jump should be a spike, got %+v", spiked)
+	}
+
+	regressed := DungeonHeatmap(heatmapTestDungeon(1)).Pacing
+	if regressed.Regressions != 1 || regressed.Score >= smooth.Score {
+		t.Errorf("an easier lower level should be a regression, got %+v", regressed)
+	}
+}
+
+func TestWorldHeatmap_ComparesBorderingRegions(t *testing.T) {
+	world := &GeneratedWorld{
+		ID: "realm",
+		Regions: []*Region{
+			{ID: "west", Bounds: Rectangle{X: 0, Y: 0, Width: 10, Height: 10}, Difficulty: 2},
+			{ID: "east", Bounds: Rectangle{X: 10, Y: 0, Width: 10, Height: 10}, Difficulty: 3},
+			{ID: "far", Bounds: Rectangle{X: 50, Y: 50, Width: 10, Height: 10}, Difficulty: 10},
+		},
+	}
+
+	heatmap := WorldHeatmap(world)
+	if len(heatmap.Regions) != 3 {
+		t.Fatalf("expected 3 regions, got %d", len(heatmap.Regions))
+	}
+	if heatmap.Pacing.Steps != 1 || heatmap.Pacing.Score != 1 {
+		t.Errorf("only the bordering regions should be compared, got %+v", heatmap.Pacing)
+	}
+	if heatmap.Regions[2].EncounterBudget != 10*difficultyExperience {
+		t.Errorf("unexpected encounter budget %d", heatmap.Regions[2].EncounterBudget)
+	}
+}
+
+func TestContentQualityMetrics_RecordPacing(t *testing.T) {
+	metrics := NewContentQualityMetrics()
+	if _, exists := metrics.GenerateQualityReport().ComponentScores["pacing"]; exists {
+		t.Fatal("pacing should not be reported before any content is analysed")
+	}
+
+	metrics.RecordPacing("a", 1)
+	metrics.RecordPacing("b", 0.2)
+	report := metrics.GenerateQualityReport()
+	if score := report.ComponentScores["pacing"]; math.Abs(score-0.6) > 1e-9 {
+		t.Errorf("expected the average pacing score 0.6, got %v", score)
+	}
+
+	found := false
+	for _, recommendation := range report.Recommendations {
+		found = found || recommendation == "Smooth difficulty spikes between neighbouring levels and regions"
+	}
+	if !found {
+		t.Error("expected a pacing recommendation for a low score")
+	}
+}
@@ -0,0 +1,397 @@
+package pcg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrIntegrationDone is returned when staging into, committing, or rolling
+// back an integration that has already been committed or rolled back.
+var ErrIntegrationDone = errors.New("integration has already been committed or rolled back")
+
+// integrationState tracks the lifecycle of an Integration.
+type integrationState int
+
+const (
+	integrationPending integrationState = iota
+	integrationCommitted
+	integrationRolledBack
+)
+
+// Integration stages generated content for atomic insertion into the game
+// world. Nothing is written to the world until Commit; if any step of the
+// commit fails, every change already applied is undone. A pending integration
+// is rolled back automatically when its context is cancelled or when staged
+// content fails validation.
+//
+// Usage:
+//
+//	ix := manager.BeginIntegration(ctx, "dungeon_entrance")
+//	defer ix.Rollback()
+//	if err := ix.Stage(level); err != nil {
+//		return err
+//	}
+//	return ix.Commit()
+type Integration struct {
+	mu         sync.Mutex
+	manager    *PCGManager
+	ctx        context.Context
+	stopCancel func() bool
+	world      *game.World
+	locationID string
+	state      integrationState
+	cause      error
+	levels     []*game.Level
+	items      []*game.Item
+	dungeons   []stagedDungeon
+	events     []game.GameEvent
+}
+
+// stagedDungeon is a dungeon complex staged for integration, whose levels
+// start at the given index of the integration's levels
+type stagedDungeon struct {
+	dungeon *DungeonComplex
+	first   int
+}
+
+// BeginIntegration starts a transactional integration of content into the
+// world at locationID. Cancelling ctx rolls back the integration unless it
+// has already been committed.
+func (pcg *PCGManager) BeginIntegration(ctx context.Context, locationID string) *Integration {
+	return pcg.BeginWorldIntegration(ctx, pcg.world, locationID)
+}
+
+// BeginWorldIntegration is like BeginIntegration but commits into world
+// instead of the world the manager generates against, such as the live world
+// of a running server.
+func (pcg *PCGManager) BeginWorldIntegration(ctx context.Context, world *game.World, locationID string) *Integration {
+	ix := &Integration{
+		manager:    pcg,
+		ctx:        ctx,
+		world:      world,
+		locationID: locationID,
+	}
+	ix.stopCancel = context.AfterFunc(ctx, func() {
+		ix.abort(ctx.Err())
+	})
+	return ix
+}
+
+// Stage validates content and queues it for integration. Supported content
+// is *game.Level, *game.GameMap (added as a level with the integration's
+// location ID, see LevelFromTerrain), *DungeonComplex (see LevelsFromDungeon),
+// *game.Item and []*game.Item. Content that fails validation rolls back the
+// whole integration.
+func (ix *Integration) Stage(content interface{}) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if err := ix.checkPending(); err != nil {
+		return err
+	}
+
+	var levels []*game.Level
+	var items []*game.Item
+	var dungeon *DungeonComplex
+	switch v := content.(type) {
+	case *game.Level:
+		levels = append(levels, v)
+	case *game.GameMap:
+		levels = append(levels, LevelFromTerrain(ix.locationID, v))
+	case *DungeonComplex:
+		levels = LevelsFromDungeon(ix.locationID, v)
+		dungeon = v
+	case *game.Item:
+		items = append(items, v)
+	case []*game.Item:
+		items = append(items, v...)
+	default:
+		return fmt.Errorf("unsupported content type for integration: %T", content)
+	}
+
+	for _, level := range levels {
+		if err := ix.validate(level); err != nil {
+			ix.rollbackLocked(err)
+			return err
+		}
+	}
+	for _, item := range items {
+		if err := ix.validate(item); err != nil {
+			ix.rollbackLocked(err)
+			return err
+		}
+	}
+
+	if dungeon != nil {
+		ix.dungeons = append(ix.dungeons, stagedDungeon{dungeon: dungeon, first: len(ix.levels)})
+	}
+	ix.levels = append(ix.levels, levels...)
+	ix.items = append(ix.items, items...)
+	return nil
+}
+
+// StageEvent queues an event to be emitted on the manager's event system
+// once the integration commits. Staged events are discarded on rollback.
+func (ix *Integration) StageEvent(event game.GameEvent) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if err := ix.checkPending(); err != nil {
+		return err
+	}
+
+	ix.events = append(ix.events, event)
+	return nil
+}
+
+// Commit applies all staged content to the world through its locking
+// methods, so items are registered in the object map, spatial grid, spatial
+// index and change tracking, and then emits staged events followed by an
+// EventPCGContentIntegrated event. If any step fails, or the context has been
+// cancelled, the changes already applied are undone and an error is returned.
+func (ix *Integration) Commit() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if err := ix.checkPending(); err != nil {
+		return err
+	}
+	ix.stopCancel()
+
+	if err := ix.ctx.Err(); err != nil {
+		ix.rollbackLocked(err)
+		return fmt.Errorf("integration cancelled: %w", err)
+	}
+
+	world := ix.world
+	if world == nil {
+		err := fmt.Errorf("no world available for integration")
+		ix.rollbackLocked(err)
+		return err
+	}
+
+	var undo []func()
+	revert := func(cause error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+		ix.rollbackLocked(cause)
+		return cause
+	}
+
+	base := 0
+	if len(ix.levels) > 0 {
+		levels := make([]game.Level, 0, len(ix.levels))
+		for _, level := range ix.levels {
+			levels = append(levels, *level)
+		}
+		count := world.AddLevels(levels...)
+		undo = append(undo, func() { world.TruncateLevels(count) })
+		base = count
+	}
+
+	for _, item := range ix.items {
+		// AddObject keeps the object when only the spatial index fails, so
+		// undo whatever of this item made it into the world
+		undo = append(undo, func() {
+			if obj, exists := world.GetObject(item.ID); exists && obj == game.GameObject(item) {
+				_ = world.RemoveObject(item.ID)
+			}
+		})
+		if err := world.AddObject(item); err != nil {
+			return revert(fmt.Errorf("failed to add item %s: %w", item.ID, err))
+		}
+	}
+
+	// Nothing can fail past this point, so the travel graph needs no undo
+	for _, staged := range ix.dungeons {
+		ix.manager.travel.AddDungeon(staged.dungeon, base+staged.first)
+	}
+
+	ix.state = integrationCommitted
+	ix.emitEvents()
+
+	ix.manager.logger.WithFields(logrus.Fields{
+		"location": ix.locationID,
+		"levels":   len(ix.levels),
+		"items":    len(ix.items),
+	}).Info("Integrated generated content into world")
+
+	return nil
+}
+
+// Rollback discards all staged content and events. It returns
+// ErrIntegrationDone if the integration has already finished, so it is safe
+// to defer after BeginIntegration.
+func (ix *Integration) Rollback() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.state != integrationPending {
+		return ErrIntegrationDone
+	}
+	ix.stopCancel()
+	ix.rollbackLocked(nil)
+	return nil
+}
+
+// abort rolls back a pending integration with the given cause.
+func (ix *Integration) abort(cause error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.state == integrationPending {
+		ix.rollbackLocked(cause)
+	}
+}
+
+// checkPending returns an error describing why the integration can no
+// longer be used. Callers must hold ix.mu.
+func (ix *Integration) checkPending() error {
+	if ix.state == integrationPending {
+		return nil
+	}
+	if ix.cause != nil {
+		return fmt.Errorf("%w: %w", ErrIntegrationDone, ix.cause)
+	}
+	return ErrIntegrationDone
+}
+
+// rollbackLocked discards staged state. Callers must hold ix.mu.
+func (ix *Integration) rollbackLocked(cause error) {
+	ix.state = integrationRolledBack
+	ix.cause = cause
+	ix.levels = nil
+	ix.items = nil
+	ix.dungeons = nil
+	ix.events = nil
+
+	entry := ix.manager.logger.WithField("location", ix.locationID)
+	if cause != nil {
+		entry = entry.WithError(cause)
+	}
+	entry.Debug("Rolled back content integration")
+}
+
+// validate runs the manager's content validator and logs warnings.
+func (ix *Integration) validate(content interface{}) error {
+	result, err := ix.manager.ValidateGeneratedContent(content)
+	if err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if !result.IsValid() {
+		return fmt.Errorf("content validation failed: %v", result.Errors)
+	}
+
+	if result.HasWarnings() {
+		ix.manager.logger.WithFields(logrus.Fields{
+			"location": ix.locationID,
+			"warnings": result.Warnings,
+		}).Warn("Generated content has validation warnings")
+	}
+
+	return nil
+}
+
+// emitEvents publishes staged events and the integration summary event.
+func (ix *Integration) emitEvents() {
+	eventSystem := ix.manager.getEventSystem()
+	if eventSystem == nil {
+		return
+	}
+
+	for _, event := range ix.events {
+		eventSystem.Emit(event)
+	}
+
+	levelIDs := make([]string, 0, len(ix.levels))
+	for _, level := range ix.levels {
+		levelIDs = append(levelIDs, level.ID)
+	}
+	itemIDs := make([]string, 0, len(ix.items))
+	for _, item := range ix.items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+
+	eventSystem.Emit(game.GameEvent{
+		Type:     EventPCGContentIntegrated,
+		SourceID: "pcg_manager",
+		TargetID: ix.locationID,
+		Data: map[string]interface{}{
+			"location_id": ix.locationID,
+			"level_ids":   levelIDs,
+			"item_ids":    itemIDs,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// LevelFromTerrain converts a generated terrain map into a world level.
+// Walkable tiles become floors and the rest walls; transparency is kept, so
+// non-walkable transparent tiles such as water do not block line of sight.
+func LevelFromTerrain(levelID string, terrain *game.GameMap) *game.Level {
+	level := &game.Level{
+		ID:         levelID,
+		Name:       levelID,
+		Width:      terrain.Width,
+		Height:     terrain.Height,
+		Tiles:      make([][]game.Tile, len(terrain.Tiles)),
+		Properties: map[string]interface{}{"source": "terrain"},
+	}
+
+	for y, row := range terrain.Tiles {
+		level.Tiles[y] = make([]game.Tile, len(row))
+		for x, mapTile := range row {
+			tile := game.NewWallTile()
+			if mapTile.Walkable {
+				tile = game.NewFloorTile()
+			}
+			tile.Transparent = mapTile.Transparent
+			tile.BlocksSight = !mapTile.Transparent
+			tile.Light = mapTile.Light
+			level.Tiles[y][x] = tile
+		}
+	}
+
+	return level
+}
+
+// LevelsFromDungeon converts the levels of a dungeon complex into world
+// levels in level order, named after levelID and the level number, such as
+// "crypt_level_2". The tiles of level connections become stairs.
+func LevelsFromDungeon(levelID string, dungeon *DungeonComplex) []*game.Level {
+	numbers := make([]int, 0, len(dungeon.Levels))
+	for number := range dungeon.Levels {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	levels := make([]*game.Level, 0, len(numbers))
+	for _, number := range numbers {
+		dungeonLevel := dungeon.Levels[number]
+		level := LevelFromTerrain(fmt.Sprintf("%s_level_%d", levelID, number), dungeonLevel.Map)
+		level.Properties["source"] = "dungeon"
+		level.Properties["dungeon_id"] = dungeon.ID
+		for _, connection := range dungeonLevel.Connections {
+			x, y := connection.Position.X, connection.Position.Y
+			if y >= 0 && y < len(level.Tiles) && x >= 0 && x < len(level.Tiles[y]) {
+				stairs := game.NewFloorTile()
+				stairs.Type = game.TileStairs
+				stairs.Sprite = "stairs"
+				stairs.Light = level.Tiles[y][x].Light
+				level.Tiles[y][x] = stairs
+			}
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
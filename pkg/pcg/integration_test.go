@@ -0,0 +1,213 @@
+package pcg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIntegrationTestLevel(id string) *game.Level {
+	tiles := make([][]game.Tile, 2)
+	for y := range tiles {
+		tiles[y] = make([]game.Tile, 2)
+	}
+	return &game.Level{ID: id, Name: "Level " + id, Width: 2, Height: 2, Tiles: tiles}
+}
+
+func newIntegrationTestItem(id string) *game.Item {
+	return &game.Item{ID: id, Name: "Item " + id, Type: "misc", Value: 1}
+}
+
+func TestIntegrationCommit(t *testing.T) {
+	world := game.NewWorld()
+	manager := NewPCGManager(world, nil)
+	eventSystem := game.NewEventSystem()
+	manager.SetEventSystem(eventSystem)
+
+	integrated := make(chan game.GameEvent, 1)
+	eventSystem.Subscribe(EventPCGContentIntegrated, func(event game.GameEvent) {
+		integrated <- event
+	})
+
+	ix := manager.BeginIntegration(context.Background(), "town")
+	require.NoError(t, ix.Stage(newIntegrationTestLevel("level_1")))
+	require.NoError(t, ix.Stage([]*game.Item{newIntegrationTestItem("a"), newIntegrationTestItem("b")}))
+
+	assert.Empty(t, world.Levels, "nothing is applied before commit")
+	assert.Empty(t, world.Objects)
+
+	require.NoError(t, ix.Commit())
+	assert.Len(t, world.Levels, 1)
+	assert.Contains(t, world.Objects, "a")
+	assert.Contains(t, world.Objects, "b")
+	assert.Len(t, world.GetObjectsAt(game.Position{}), 2, "items are added to the spatial grid")
+
+	select {
+	case event := <-integrated:
+		assert.Equal(t, "town", event.TargetID)
+		assert.Equal(t, []string{"a", "b"}, event.Data["item_ids"])
+	case <-time.After(time.Second):
+		t.Fatal("integration event was not emitted")
+	}
+
+	assert.ErrorIs(t, ix.Commit(), ErrIntegrationDone)
+	assert.ErrorIs(t, ix.Rollback(), ErrIntegrationDone)
+}
+
+func TestIntegrationValidationFailureRollsBack(t *testing.T) {
+	world := game.NewWorld()
+	manager := NewPCGManager(world, nil)
+
+	ix := manager.BeginIntegration(context.Background(), "town")
+	require.NoError(t, ix.Stage(newIntegrationTestItem("a")))
+
+	err := ix.Stage(&game.Item{ID: "bad"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "content validation failed")
+
+	err = ix.Commit()
+	assert.ErrorIs(t, err, ErrIntegrationDone)
+	assert.Empty(t, world.Objects, "staged content is discarded")
+}
+
+func TestIntegrationCommitUndoesPartialChanges(t *testing.T) {
+	world := game.NewWorld()
+	existing := newIntegrationTestItem("b")
+	world.Objects["b"] = existing
+	manager := NewPCGManager(world, nil)
+
+	ix := manager.BeginIntegration(context.Background(), "town")
+	require.NoError(t, ix.Stage(newIntegrationTestLevel("level_1")))
+	require.NoError(t, ix.Stage([]*game.Item{newIntegrationTestItem("a"), newIntegrationTestItem("b")}))
+
+	err := ix.Commit()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	assert.Empty(t, world.Levels)
+	assert.NotContains(t, world.Objects, "a")
+	assert.Same(t, existing, world.Objects["b"])
+	assert.Empty(t, world.GetObjectsAt(game.Position{}), "undone items leave the spatial grid")
+}
+
+func TestIntegrationCommitConcurrentWithWorldAccess(t *testing.T) {
+	world := game.NewWorld()
+	manager := NewPCGManager(world, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			world.GetObjectsAt(game.Position{})
+			world.AddLevels()
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		ix := manager.BeginIntegration(context.Background(), "town")
+		require.NoError(t, ix.Stage(newIntegrationTestLevel("level")))
+		require.NoError(t, ix.Stage(newIntegrationTestItem(string(rune('a'+i)))))
+		require.NoError(t, ix.Commit())
+	}
+	<-done
+	assert.Len(t, world.Levels, 10)
+}
+
+func TestIntegrationContextCancellation(t *testing.T) {
+	world := game.NewWorld()
+	manager := NewPCGManager(world, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ix := manager.BeginIntegration(ctx, "town")
+	require.NoError(t, ix.Stage(newIntegrationTestItem("a")))
+
+	cancel()
+
+	err := ix.Commit()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Empty(t, world.Objects)
+}
+
+func TestIntegrationRollback(t *testing.T) {
+	world := game.NewWorld()
+	manager := NewPCGManager(world, nil)
+
+	ix := manager.BeginIntegration(context.Background(), "town")
+	require.NoError(t, ix.Stage(newIntegrationTestItem("a")))
+	require.NoError(t, ix.StageEvent(game.GameEvent{Type: EventPCGContentGenerated}))
+
+	require.NoError(t, ix.Rollback())
+	assert.ErrorIs(t, ix.Rollback(), ErrIntegrationDone)
+	assert.ErrorIs(t, ix.Stage(newIntegrationTestItem("b")), ErrIntegrationDone)
+	assert.Empty(t, world.Objects)
+}
+
+func TestIntegrateContentIntoWorldIsAtomic(t *testing.T) {
+	world := game.NewWorld()
+	manager := NewPCGManager(world, nil)
+
+	err := manager.IntegrateContentIntoWorld([]*game.Item{newIntegrationTestItem("a"), {ID: "bad"}}, "town")
+	require.Error(t, err)
+	assert.Empty(t, world.Objects)
+
+	require.NoError(t, manager.IntegrateContentIntoWorld(newIntegrationTestItem("a"), "town"))
+	assert.Contains(t, world.Objects, "a")
+
+	err = manager.IntegrateContentIntoWorld("not content", "town")
+	assert.Contains(t, err.Error(), "unsupported content type")
+}
+
+func TestBeginWorldIntegrationStagesTerrain(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	target := game.NewWorld()
+
+	terrain := &game.GameMap{
+		Width:  2,
+		Height: 1,
+		Tiles: [][]game.MapTile{{
+			{Walkable: true, Transparent: true},
+			{Walkable: false, Transparent: true},
+		}},
+	}
+
+	ix := manager.BeginWorldIntegration(context.Background(), target, "marsh")
+	require.NoError(t, ix.Stage(terrain))
+	require.NoError(t, ix.Commit())
+
+	require.Len(t, target.Levels, 1, "content goes to the given world")
+	assert.Empty(t, manager.world.Levels)
+
+	level := target.Levels[0]
+	assert.Equal(t, "marsh", level.ID)
+	assert.Equal(t, game.TileFloor, level.Tiles[0][0].Type)
+	assert.Equal(t, game.TileWall, level.Tiles[0][1].Type)
+	assert.False(t, level.Tiles[0][1].BlocksSight, "transparent obstacles do not block sight")
+}
+
+func TestBeginWorldIntegrationStagesDungeon(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	target := game.NewWorld()
+	target.AddLevels(*newIntegrationTestLevel("surface"))
+
+	ix := manager.BeginWorldIntegration(context.Background(), target, "crypt")
+	require.NoError(t, ix.Stage(travelTestDungeon()))
+	assert.Empty(t, manager.GetTravelGraph().Places(), "nothing is linked before commit")
+	require.NoError(t, ix.Commit())
+
+	require.Len(t, target.Levels, 4)
+	assert.Equal(t, "crypt_level_1", target.Levels[1].ID)
+	assert.Equal(t, "crypt_level_3", target.Levels[3].ID)
+	assert.Equal(t, game.TileStairs, target.Levels[1].Tiles[1][4].Type, "connections become stairs")
+	assert.Equal(t, game.TileFloor, target.Levels[1].Tiles[1][1].Type)
+
+	route, err := manager.GetTravelGraph().Route(game.Position{X: 0, Y: 0, Level: 1}, game.Position{X: 0, Y: 0, Level: 3})
+	require.NoError(t, err, "the dungeon's connections join the levels it was added at")
+	assert.Equal(t, 3, route.Legs[len(route.Legs)-1].To.Level)
+}
@@ -111,6 +111,10 @@ const (
 	ContentTypeDialogue   ContentType = "dialogue"
 	ContentTypeReputation ContentType = "reputation"
 	ContentTypeWorld      ContentType = "world"
+	ContentTypeWeather    ContentType = "weather"
+	ContentTypeCombat     ContentType = "combat"
+	ContentTypeMonsters   ContentType = "monsters"
+	ContentTypeDeities    ContentType = "deities"
 )
 
 // GenerationParams provides common parameters for all generators
@@ -122,6 +126,7 @@ type GenerationParams struct {
 	Constraints map[string]interface{} `yaml:"constraints"`  // Generator-specific constraints
 	Metadata    map[string]interface{} `yaml:"metadata"`     // Additional context data
 	Timeout     time.Duration          `yaml:"timeout"`      // Maximum generation time
+	Progress    ProgressFunc           `yaml:"-"`            // Optional stage/percentage progress callback
 }
 
 // TerrainParams provides terrain-specific generation parameters
@@ -156,6 +161,10 @@ type LevelParams struct {
 	LevelTheme       LevelTheme    `yaml:"level_theme"`    // Thematic constraints
 	HasBoss          bool          `yaml:"has_boss"`       // Whether to include a boss room
 	SecretRooms      int           `yaml:"secret_rooms"`   // Number of secret rooms
+
+	// LayoutConstraints are hard rules on the room layout; a level that
+	// cannot be repaired to meet them fails with a ConstraintError
+	LayoutConstraints []LayoutConstraint `yaml:"layout_constraints,omitempty"`
 }
 
 // QuestParams provides quest-specific generation parameters
@@ -3,6 +3,7 @@ package items
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 
 	"goldbox-rpg/pkg/game"
 
@@ -31,7 +32,7 @@ func NewEnchantmentSystem() *EnchantmentSystem {
 
 // SetSeed sets the random seed for enchantment generation
 func (es *EnchantmentSystem) SetSeed(seed int64) {
-	es.rng = rand.New(rand.NewSource(seed))
+	es.rng = pcg.NewRNG(seed, "enchantments")
 }
 
 // ApplyEnchantments adds procedural enchantments to an item
@@ -111,6 +112,11 @@ func (es *EnchantmentSystem) GetAvailableEnchantments(itemType string, minLevel,
 		}
 	}
 
+	// Sort so seeded selection does not depend on map iteration order
+	sort.Slice(available, func(i, j int) bool {
+		return available[i].Name < available[j].Name
+	})
+
 	return available
 }
 
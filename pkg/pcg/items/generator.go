@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
 
 	"goldbox-rpg/pkg/game"
 	"goldbox-rpg/pkg/pcg"
@@ -16,6 +17,8 @@ type TemplateBasedGenerator struct {
 	registry  *ItemTemplateRegistry
 	enchants  *EnchantmentSystem
 	rng       *rand.Rand
+	seed      int64 // Seed of rng, from which item IDs are derived
+	generated int   // Items generated since the seed was set
 }
 
 // NewTemplateBasedGenerator creates a new template-based item generator
@@ -37,7 +40,8 @@ func NewTemplateBasedGenerator() *TemplateBasedGenerator {
 
 // SetSeed sets the random seed for deterministic generation
 func (tbg *TemplateBasedGenerator) SetSeed(seed int64) {
-	tbg.rng = rand.New(rand.NewSource(seed))
+	tbg.rng = pcg.NewRNG(seed, "items")
+	tbg.seed, tbg.generated = seed, 0
 	tbg.enchants.SetSeed(seed + 1) // Offset for enchantment system
 }
 
@@ -92,7 +96,7 @@ func (tbg *TemplateBasedGenerator) GenerateItem(ctx context.Context, template pc
 
 	// Create base item
 	item := &game.Item{
-		ID:   generateItemID(),
+		ID:   tbg.nextItemID(template.BaseType),
 		Type: template.BaseType,
 	}
 
@@ -123,6 +127,9 @@ func (tbg *TemplateBasedGenerator) GenerateItem(ctx context.Context, template pc
 	// Set appropriate value and weight
 	tbg.calculateValueAndWeight(item, &template, rarity)
 
+	// Record the rarity so generated items can be searched by it
+	item.Properties = append(item.Properties, "rarity:"+string(rarity))
+
 	return item, nil
 }
 
@@ -167,7 +174,16 @@ func (tbg *TemplateBasedGenerator) GenerateItemSet(ctx context.Context, setType
 
 // applyStatRanges applies template stat ranges to item
 func (tbg *TemplateBasedGenerator) applyStatRanges(item *game.Item, ranges map[string]pcg.StatRange, playerLevel int) error {
-	for statName, statRange := range ranges {
+	// Roll stats in a fixed order so seeded generation is reproducible
+	statNames := make([]string, 0, len(ranges))
+	for statName := range ranges {
+		statNames = append(statNames, statName)
+	}
+	sort.Strings(statNames)
+
+	for _, statName := range statNames {
+		statRange := ranges[statName]
+
 		// Calculate base value within range
 		baseValue := statRange.Min + tbg.rng.Intn(statRange.Max-statRange.Min+1)
 
@@ -309,9 +325,11 @@ func (tbg *TemplateBasedGenerator) Validate(params pcg.GenerationParams) error {
 	return nil
 }
 
-// generateItemID creates a unique identifier for items
-func generateItemID() string {
-	return fmt.Sprintf("item_%d", rand.Int63())
+// nextItemID derives the ID of the next item from the generator's seed, so
+// items regenerated from the same seed keep their IDs
+func (tbg *TemplateBasedGenerator) nextItemID(baseType string) string {
+	tbg.generated++
+	return game.SeededID(game.IDNamespaceItem, tbg.seed, fmt.Sprintf("%s:%d", baseType, tbg.generated))
 }
 
 // selectRandomRarity selects a random rarity within the given range
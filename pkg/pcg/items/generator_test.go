@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"goldbox-rpg/pkg/game"
 	"goldbox-rpg/pkg/pcg"
 )
 
@@ -339,6 +340,15 @@ func TestDeterministicGeneration(t *testing.T) {
 	// Note: This is a basic check - full determinism would require
 	// deeper comparison of item properties
 	if result1 == nil || result2 == nil {
-		t.Error("One or both results are nil")
+		t.Fatal("One or both results are nil")
+	}
+
+	// Items regenerated from the same seed keep their IDs
+	id1, id2 := result1.(*game.Item).ID, result2.(*game.Item).ID
+	if id1 != id2 {
+		t.Errorf("Expected the same item ID from the same seed, got %s and %s", id1, id2)
+	}
+	if game.IDNamespaceOf(id1) != game.IDNamespaceItem {
+		t.Errorf("Expected an item_ ID, got %s", id1)
 	}
 }
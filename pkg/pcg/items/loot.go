@@ -0,0 +1,539 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rarityOrder lists rarity tiers from least to most valuable
+var rarityOrder = []pcg.RarityTier{
+	pcg.RarityCommon,
+	pcg.RarityUncommon,
+	pcg.RarityRare,
+	pcg.RarityEpic,
+	pcg.RarityLegendary,
+	pcg.RarityArtifact,
+}
+
+// LootRange is an inclusive min/max range used for roll and drop counts
+type LootRange struct {
+	Min int `yaml:"min"`
+	Max int `yaml:"max"`
+}
+
+// LootEntry is a single weighted outcome of a loot table roll.
+// An entry drops either an item generated from the named template, the
+// result of rolling a nested table, or nothing when both are empty.
+type LootEntry struct {
+	Item      string         `yaml:"item,omitempty"`
+	Table     string         `yaml:"table,omitempty"`
+	Weight    int            `yaml:"weight"`
+	Count     LootRange      `yaml:"count,omitempty"`
+	MinLevel  int            `yaml:"min_level,omitempty"`
+	MaxLevel  int            `yaml:"max_level,omitempty"`
+	MinRarity pcg.RarityTier `yaml:"min_rarity,omitempty"`
+	MaxRarity pcg.RarityTier `yaml:"max_rarity,omitempty"`
+}
+
+// LootTable defines a set of weighted entries rolled one or more times.
+// A table without rolls is rolled once; entries without a count drop once.
+//
+// RarityWeights sets the relative chance of each rarity tier for items the
+// table drops. LevelScaling shifts those weights toward rarer tiers as the
+// level rises: the weight of the tier at index i (common = 0) is multiplied
+// by 1 + LevelScaling*level*i.
+type LootTable struct {
+	Rolls           LootRange                  `yaml:"rolls"`
+	RarityWeights   map[pcg.RarityTier]float64 `yaml:"rarity_weights,omitempty"`
+	LevelScaling    float64                    `yaml:"level_scaling,omitempty"`
+	EnchantmentRate float64                    `yaml:"enchantment_rate,omitempty"`
+	Entries         []LootEntry                `yaml:"entries"`
+}
+
+// LootTableCollection represents the root structure of a loot table YAML file
+type LootTableCollection struct {
+	Tables map[string]*LootTable `yaml:"loot_tables"`
+}
+
+// LootTableRegistry holds named loot tables and resolves them into items.
+// Tables are loaded from YAML and can be reloaded while the server runs;
+// a reload that fails validation leaves the previous tables in place.
+//
+// LootTableRegistry is safe for concurrent use.
+type LootTableRegistry struct {
+	mu        sync.RWMutex
+	tables    map[string]*LootTable
+	paths     []string
+	added     map[string]*LootTable // Tables from AddTables, kept across Reload
+	templates *ItemTemplateRegistry
+}
+
+// NewLootTableRegistry creates an empty loot table registry. Item entries
+// are resolved against templates; when templates is nil the built-in
+// default templates are used.
+func NewLootTableRegistry(templates *ItemTemplateRegistry) *LootTableRegistry {
+	if templates == nil {
+		templates = NewItemTemplateRegistry()
+		templates.LoadDefaultTemplates()
+	}
+
+	return &LootTableRegistry{
+		tables:    make(map[string]*LootTable),
+		templates: templates,
+	}
+}
+
+// LoadFromFile loads loot tables from a YAML file and merges them with the
+// tables already registered. The file is remembered so Reload can re-read it.
+func (ltr *LootTableRegistry) LoadFromFile(path string) error {
+	ltr.mu.Lock()
+	defer ltr.mu.Unlock()
+
+	tables := make(map[string]*LootTable, len(ltr.tables))
+	for name, table := range ltr.tables {
+		tables[name] = table
+	}
+
+	if err := readLootTables(path, tables); err != nil {
+		return err
+	}
+	if err := ltr.validate(tables); err != nil {
+		return fmt.Errorf("invalid loot tables in %s: %w", path, err)
+	}
+
+	ltr.tables = tables
+	ltr.paths = append(ltr.paths, path)
+	return nil
+}
+
+// Reload re-reads every file previously passed to LoadFromFile. The new
+// tables replace the old ones only if all files parse and validate.
+func (ltr *LootTableRegistry) Reload() error {
+	ltr.mu.Lock()
+	defer ltr.mu.Unlock()
+
+	tables := make(map[string]*LootTable)
+	for _, path := range ltr.paths {
+		if err := readLootTables(path, tables); err != nil {
+			return err
+		}
+	}
+	for name, table := range ltr.added {
+		tables[name] = table
+	}
+	if err := ltr.validate(tables); err != nil {
+		return fmt.Errorf("invalid loot tables: %w", err)
+	}
+
+	ltr.tables = tables
+	return nil
+}
+
+// AddTables merges tables, such as those of a content bundle, with the
+// tables already registered. Tables of the same name are replaced, and the
+// added tables are kept when the files are reloaded.
+func (ltr *LootTableRegistry) AddTables(source string, added map[string]*LootTable) error {
+	ltr.mu.Lock()
+	defer ltr.mu.Unlock()
+
+	tables := make(map[string]*LootTable, len(ltr.tables)+len(added))
+	for name, table := range ltr.tables {
+		tables[name] = table
+	}
+	for name, table := range added {
+		if table == nil {
+			return fmt.Errorf("loot table %s from %s is empty", name, source)
+		}
+		tables[name] = table
+	}
+	if err := ltr.validate(tables); err != nil {
+		return fmt.Errorf("invalid loot tables in %s: %w", source, err)
+	}
+
+	ltr.tables = tables
+	if ltr.added == nil {
+		ltr.added = make(map[string]*LootTable, len(added))
+	}
+	for name, table := range added {
+		ltr.added[name] = table
+	}
+	return nil
+}
+
+// GetTable returns the named loot table
+func (ltr *LootTableRegistry) GetTable(name string) (*LootTable, bool) {
+	ltr.mu.RLock()
+	defer ltr.mu.RUnlock()
+
+	table, exists := ltr.tables[name]
+	return table, exists
+}
+
+// TableNames returns the names of all registered tables in sorted order
+func (ltr *LootTableRegistry) TableNames() []string {
+	ltr.mu.RLock()
+	defer ltr.mu.RUnlock()
+
+	names := make([]string, 0, len(ltr.tables))
+	for name := range ltr.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ItemReferences returns the item templates each table drops, by table
+// name. It implements pcg.LootReferences, so world consistency checks can
+// confirm that the templates exist.
+func (ltr *LootTableRegistry) ItemReferences() map[string][]string {
+	ltr.mu.RLock()
+	defer ltr.mu.RUnlock()
+
+	references := make(map[string][]string, len(ltr.tables))
+	for name, table := range ltr.tables {
+		for _, entry := range table.Entries {
+			if entry.Item != "" {
+				references[name] = append(references[name], entry.Item)
+			}
+		}
+	}
+	return references
+}
+
+// TemplateNames returns the base types of the item templates loot tables
+// generate items from, in sorted order.
+func (ltr *LootTableRegistry) TemplateNames() []string {
+	names := make([]string, 0, len(ltr.templates.templates))
+	for name := range ltr.templates.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveLootTable rolls the named table for the given level and generates
+// the resulting items. Results are deterministic for a given rng state.
+func (ltr *LootTableRegistry) ResolveLootTable(ctx context.Context, name string, level int, rng *rand.Rand) ([]*game.Item, error) {
+	if rng == nil {
+		return nil, fmt.Errorf("random generator not initialized")
+	}
+	if level < 1 {
+		level = 1
+	}
+
+	// Snapshot the table set so a concurrent reload cannot change it mid-roll
+	ltr.mu.RLock()
+	tables := ltr.tables
+	ltr.mu.RUnlock()
+
+	generator := &TemplateBasedGenerator{
+		version:   "1.0.0",
+		templates: make(map[string]*pcg.ItemTemplate),
+		registry:  ltr.templates,
+		enchants:  NewEnchantmentSystem(),
+	}
+	generator.SetSeed(rng.Int63())
+
+	resolver := &lootResolver{
+		tables:    tables,
+		generator: generator,
+		level:     level,
+		rng:       rng,
+	}
+
+	var items []*game.Item
+	if err := resolver.resolve(ctx, name, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// readLootTables parses a loot table file into tables, overwriting any
+// tables with the same name
+func readLootTables(path string, tables map[string]*LootTable) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read loot table file %s: %w", path, err)
+	}
+
+	var collection LootTableCollection
+	if err := yaml.Unmarshal(data, &collection); err != nil {
+		return fmt.Errorf("failed to parse YAML from %s: %w", path, err)
+	}
+
+	for name, table := range collection.Tables {
+		if table != nil {
+			tables[name] = table
+		}
+	}
+	return nil
+}
+
+// validate checks table structure, template and table references, and
+// rejects nested tables that refer back to themselves
+func (ltr *LootTableRegistry) validate(tables map[string]*LootTable) error {
+	for name, table := range tables {
+		if err := validateLootRange(table.Rolls); err != nil {
+			return fmt.Errorf("table %s rolls: %w", name, err)
+		}
+		if len(table.Entries) == 0 {
+			return fmt.Errorf("table %s has no entries", name)
+		}
+		for rarity, weight := range table.RarityWeights {
+			if rarityIndex(rarity) < 0 {
+				return fmt.Errorf("table %s has unknown rarity %q", name, rarity)
+			}
+			if weight < 0 {
+				return fmt.Errorf("table %s has negative weight for rarity %s", name, rarity)
+			}
+		}
+
+		totalWeight := 0
+		for i, entry := range table.Entries {
+			if entry.Weight < 0 {
+				return fmt.Errorf("table %s entry %d has negative weight", name, i)
+			}
+			totalWeight += entry.Weight
+
+			if entry.Item != "" && entry.Table != "" {
+				return fmt.Errorf("table %s entry %d sets both item and table", name, i)
+			}
+			if entry.Item != "" {
+				if _, exists := ltr.templates.templates[entry.Item]; !exists {
+					return fmt.Errorf("table %s entry %d references unknown item template %s", name, i, entry.Item)
+				}
+			}
+			if entry.Table != "" {
+				if _, exists := tables[entry.Table]; !exists {
+					return fmt.Errorf("table %s entry %d references unknown table %s", name, i, entry.Table)
+				}
+			}
+			if entry.Count != (LootRange{}) {
+				if err := validateLootRange(entry.Count); err != nil {
+					return fmt.Errorf("table %s entry %d count: %w", name, i, err)
+				}
+			}
+			if entry.MinRarity != "" && rarityIndex(entry.MinRarity) < 0 {
+				return fmt.Errorf("table %s entry %d has unknown min_rarity %q", name, i, entry.MinRarity)
+			}
+			if entry.MaxRarity != "" && rarityIndex(entry.MaxRarity) < 0 {
+				return fmt.Errorf("table %s entry %d has unknown max_rarity %q", name, i, entry.MaxRarity)
+			}
+		}
+		if totalWeight == 0 {
+			return fmt.Errorf("table %s has no positive entry weights", name)
+		}
+	}
+
+	// Detect cycles between nested tables
+	visiting := make(map[string]bool)
+	done := make(map[string]bool)
+	var visit func(name string) error
+	visit = func(name string) error {
+		if done[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("table %s is nested within itself", name)
+		}
+		visiting[name] = true
+		for _, entry := range tables[name].Entries {
+			if entry.Table != "" {
+				if err := visit(entry.Table); err != nil {
+					return err
+				}
+			}
+		}
+		visiting[name] = false
+		done[name] = true
+		return nil
+	}
+	for name := range tables {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateLootRange(r LootRange) error {
+	if r.Min < 0 {
+		return fmt.Errorf("min must not be negative")
+	}
+	if r.Max < r.Min {
+		return fmt.Errorf("max must be greater than or equal to min")
+	}
+	return nil
+}
+
+// rarityIndex returns the position of rarity in rarityOrder, or -1
+func rarityIndex(rarity pcg.RarityTier) int {
+	for i, tier := range rarityOrder {
+		if tier == rarity {
+			return i
+		}
+	}
+	return -1
+}
+
+// lootResolver carries the state of a single ResolveLootTable call
+type lootResolver struct {
+	tables    map[string]*LootTable
+	generator *TemplateBasedGenerator
+	level     int
+	rng       *rand.Rand
+}
+
+// resolve rolls the named table and appends the dropped items
+func (lr *lootResolver) resolve(ctx context.Context, name string, items *[]*game.Item) error {
+	table, exists := lr.tables[name]
+	if !exists {
+		return fmt.Errorf("loot table not found: %s", name)
+	}
+
+	rolls := 1
+	if table.Rolls != (LootRange{}) {
+		rolls = lr.rollRange(table.Rolls)
+	}
+	for i := 0; i < rolls; i++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("loot resolution cancelled: %w", err)
+		}
+
+		entry, ok := lr.selectEntry(table)
+		if !ok {
+			continue
+		}
+
+		count := 1
+		if entry.Count != (LootRange{}) {
+			count = lr.rollRange(entry.Count)
+		}
+
+		for j := 0; j < count; j++ {
+			switch {
+			case entry.Table != "":
+				if err := lr.resolve(ctx, entry.Table, items); err != nil {
+					return err
+				}
+			case entry.Item != "":
+				item, err := lr.generateItem(ctx, table, entry)
+				if err != nil {
+					return fmt.Errorf("table %s: %w", name, err)
+				}
+				*items = append(*items, item)
+			}
+		}
+	}
+
+	return nil
+}
+
+// selectEntry picks a weighted entry among those eligible at the current level
+func (lr *lootResolver) selectEntry(table *LootTable) (LootEntry, bool) {
+	total := 0
+	for _, entry := range table.Entries {
+		if lr.eligible(entry) {
+			total += entry.Weight
+		}
+	}
+	if total == 0 {
+		return LootEntry{}, false
+	}
+
+	roll := lr.rng.Intn(total)
+	for _, entry := range table.Entries {
+		if !lr.eligible(entry) {
+			continue
+		}
+		if roll < entry.Weight {
+			return entry, true
+		}
+		roll -= entry.Weight
+	}
+	return LootEntry{}, false
+}
+
+func (lr *lootResolver) eligible(entry LootEntry) bool {
+	if entry.MinLevel > 0 && lr.level < entry.MinLevel {
+		return false
+	}
+	if entry.MaxLevel > 0 && lr.level > entry.MaxLevel {
+		return false
+	}
+	return true
+}
+
+// generateItem creates an item for an item entry with a rarity rolled from
+// the table weights and clamped to the entry bounds
+func (lr *lootResolver) generateItem(ctx context.Context, table *LootTable, entry LootEntry) (*game.Item, error) {
+	rarity := clampRarity(lr.selectRarity(table), entry)
+	template, err := lr.generator.registry.GetTemplate(entry.Item, rarity)
+	if err != nil {
+		return nil, err
+	}
+
+	params := pcg.ItemParams{
+		GenerationParams: pcg.GenerationParams{PlayerLevel: lr.level},
+		MinRarity:        rarity,
+		MaxRarity:        rarity,
+		EnchantmentRate:  table.EnchantmentRate,
+		LevelScaling:     true,
+	}
+	return lr.generator.GenerateItem(ctx, *template, params)
+}
+
+// selectRarity rolls a rarity tier from the table's level-scaled weights
+func (lr *lootResolver) selectRarity(table *LootTable) pcg.RarityTier {
+	weights := make([]float64, len(rarityOrder))
+	total := 0.0
+	for i, tier := range rarityOrder {
+		weight := table.RarityWeights[tier]
+		weight *= 1 + table.LevelScaling*float64(lr.level*i)
+		if weight < 0 {
+			weight = 0
+		}
+		weights[i] = weight
+		total += weight
+	}
+	if total == 0 {
+		return pcg.RarityCommon
+	}
+
+	roll := lr.rng.Float64() * total
+	for i, weight := range weights {
+		if roll < weight {
+			return rarityOrder[i]
+		}
+		roll -= weight
+	}
+	return rarityOrder[len(rarityOrder)-1]
+}
+
+// clampRarity limits rarity to the entry's min_rarity and max_rarity bounds
+func clampRarity(rarity pcg.RarityTier, entry LootEntry) pcg.RarityTier {
+	if entry.MinRarity != "" && rarityIndex(rarity) < rarityIndex(entry.MinRarity) {
+		return entry.MinRarity
+	}
+	if entry.MaxRarity != "" && rarityIndex(rarity) > rarityIndex(entry.MaxRarity) {
+		return entry.MaxRarity
+	}
+	return rarity
+}
+
+func (lr *lootResolver) rollRange(r LootRange) int {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + lr.rng.Intn(r.Max-r.Min+1)
+}
@@ -0,0 +1,326 @@
+package items
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg"
+)
+
+const testLootTables = `
+loot_tables:
+  chest:
+    rolls: {min: 2, max: 2}
+    rarity_weights:
+      common: 1
+    entries:
+      - table: potions
+        weight: 1
+  potions:
+    entries:
+      - item: potion
+        weight: 1
+        count: {min: 2, max: 2}
+`
+
+func writeLootFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "loot_tables.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write loot file: %v", err)
+	}
+	return path
+}
+
+func TestLootTableRegistry_ResolveNestedTables(t *testing.T) {
+	registry := NewLootTableRegistry(nil)
+	if err := registry.LoadFromFile(writeLootFile(t, testLootTables)); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	items, err := registry.ResolveLootTable(context.Background(), "chest", 1, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("ResolveLootTable failed: %v", err)
+	}
+
+	// Two rolls of the nested table, each dropping two potions
+	if len(items) != 4 {
+		t.Fatalf("Expected 4 items, got %d", len(items))
+	}
+	for _, item := range items {
+		if item.Type != "consumable" {
+			t.Errorf("Expected consumable, got %s", item.Type)
+		}
+	}
+
+	if _, err := registry.ResolveLootTable(context.Background(), "missing", 1, rand.New(rand.NewSource(1))); err == nil {
+		t.Error("Expected error for unknown table")
+	}
+}
+
+func TestLootTableRegistry_ItemReferences(t *testing.T) {
+	registry := NewLootTableRegistry(nil)
+	if err := registry.LoadFromFile(writeLootFile(t, testLootTables)); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	references := registry.ItemReferences()
+	if len(references) != 1 || len(references["potions"]) != 1 || references["potions"][0] != "potion" {
+		t.Errorf("Expected only potions to drop potion, got %v", references)
+	}
+
+	var lootReferences pcg.LootReferences = registry
+	if _, exists := lootReferences.ItemReferences()["chest"]; exists {
+		t.Error("Nested table entries are not item references")
+	}
+	if names := registry.TemplateNames(); !slices.Contains(names, "potion") {
+		t.Errorf("Expected the potion template, got %v", names)
+	}
+}
+
+func TestLootTableRegistry_Deterministic(t *testing.T) {
+	registry := NewLootTableRegistry(nil)
+	if err := registry.LoadFromFile(filepath.Join("..", "..", "..", "data", "pcg", "items", "loot_tables.yaml")); err != nil {
+		t.Fatalf("Failed to load bundled loot tables: %v", err)
+	}
+
+	first, err := registry.ResolveLootTable(context.Background(), "treasure", 8, rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatalf("ResolveLootTable failed: %v", err)
+	}
+	second, err := registry.ResolveLootTable(context.Background(), "treasure", 8, rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatalf("ResolveLootTable failed: %v", err)
+	}
+
+	if len(first) != len(second) {
+		t.Fatalf("Expected same drop count, got %d and %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i].Name != second[i].Name || first[i].Value != second[i].Value {
+			t.Errorf("Drop %d differs: %+v vs %+v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestLootTableRegistry_LevelGatesAndRarity(t *testing.T) {
+	registry := NewLootTableRegistry(nil)
+	path := writeLootFile(t, `
+loot_tables:
+  hoard:
+    rarity_weights:
+      common: 1
+    entries:
+      - item: sword
+        weight: 1
+        min_level: 10
+        min_rarity: epic
+      - weight: 1
+        max_level: 9
+`)
+	if err := registry.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 20; i++ {
+		items, err := registry.ResolveLootTable(context.Background(), "hoard", 5, rng)
+		if err != nil {
+			t.Fatalf("ResolveLootTable failed: %v", err)
+		}
+		if len(items) != 0 {
+			t.Fatalf("Expected no drops below min_level, got %d", len(items))
+		}
+	}
+
+	items, err := registry.ResolveLootTable(context.Background(), "hoard", 12, rng)
+	if err != nil {
+		t.Fatalf("ResolveLootTable failed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Expected one drop at level 12, got %d", len(items))
+	}
+	if items[0].Type != "weapon" {
+		t.Errorf("Expected a sword, got %+v", items[0])
+	}
+
+	entry := LootEntry{MinRarity: pcg.RarityEpic, MaxRarity: pcg.RarityLegendary}
+	if got := clampRarity(pcg.RarityCommon, entry); got != pcg.RarityEpic {
+		t.Errorf("Expected rarity raised to epic, got %s", got)
+	}
+	if got := clampRarity(pcg.RarityArtifact, entry); got != pcg.RarityLegendary {
+		t.Errorf("Expected rarity lowered to legendary, got %s", got)
+	}
+}
+
+func TestLootTable_SelectRarityLevelScaling(t *testing.T) {
+	table := &LootTable{
+		RarityWeights: map[pcg.RarityTier]float64{pcg.RarityCommon: 10, pcg.RarityRare: 1},
+		LevelScaling:  0.5,
+	}
+
+	countRare := func(level int) int {
+		resolver := &lootResolver{level: level, rng: rand.New(rand.NewSource(9))}
+		rare := 0
+		for i := 0; i < 1000; i++ {
+			if resolver.selectRarity(table) == pcg.RarityRare {
+				rare++
+			}
+		}
+		return rare
+	}
+
+	low, high := countRare(1), countRare(20)
+	if high <= low {
+		t.Errorf("Expected more rare drops at higher level, got %d at level 1 and %d at level 20", low, high)
+	}
+}
+
+func TestLootTableRegistry_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "unknown template",
+			content: `
+loot_tables:
+  bad:
+    entries:
+      - item: wand
+        weight: 1
+`,
+			wantErr: "unknown item template",
+		},
+		{
+			name: "unknown nested table",
+			content: `
+loot_tables:
+  bad:
+    entries:
+      - table: nowhere
+        weight: 1
+`,
+			wantErr: "unknown table",
+		},
+		{
+			name: "cycle",
+			content: `
+loot_tables:
+  a:
+    entries:
+      - table: b
+        weight: 1
+  b:
+    entries:
+      - table: a
+        weight: 1
+`,
+			wantErr: "nested within itself",
+		},
+		{
+			name: "no weight",
+			content: `
+loot_tables:
+  bad:
+    entries:
+      - item: sword
+        weight: 0
+`,
+			wantErr: "no positive entry weights",
+		},
+		{
+			name: "bad rarity",
+			content: `
+loot_tables:
+  bad:
+    rarity_weights:
+      mythical: 1
+    entries:
+      - item: sword
+        weight: 1
+`,
+			wantErr: "unknown rarity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewLootTableRegistry(nil)
+			err := registry.LoadFromFile(writeLootFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if len(registry.TableNames()) != 0 {
+				t.Error("Invalid files must not register tables")
+			}
+		})
+	}
+}
+
+func TestLootTableRegistry_Reload(t *testing.T) {
+	registry := NewLootTableRegistry(nil)
+	path := writeLootFile(t, testLootTables)
+	if err := registry.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	// A broken edit is rejected and the previous tables stay active
+	if err := os.WriteFile(path, []byte("loot_tables:\n  chest:\n    entries: []\n"), 0o644); err != nil {
+		t.Fatalf("Failed to rewrite loot file: %v", err)
+	}
+	if err := registry.Reload(); err == nil {
+		t.Fatal("Expected reload of invalid tables to fail")
+	}
+	if names := registry.TableNames(); len(names) != 2 {
+		t.Fatalf("Expected previous tables to remain, got %v", names)
+	}
+
+	// A valid edit replaces the tables, dropping ones no longer defined
+	if err := os.WriteFile(path, []byte("loot_tables:\n  bones:\n    entries:\n      - weight: 1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to rewrite loot file: %v", err)
+	}
+	if err := registry.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if names := registry.TableNames(); len(names) != 1 || names[0] != "bones" {
+		t.Errorf("Expected only the bones table after reload, got %v", names)
+	}
+}
+
+func TestLootTableRegistry_AddTables(t *testing.T) {
+	registry := NewLootTableRegistry(nil)
+	if err := registry.LoadFromFile(writeLootFile(t, testLootTables)); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	crypt := &LootTable{Rolls: LootRange{Min: 1, Max: 1}, Entries: []LootEntry{{Table: "potions", Weight: 1}}}
+	if err := registry.AddTables("crypt.gbox", map[string]*LootTable{"crypt": crypt}); err != nil {
+		t.Fatalf("AddTables failed: %v", err)
+	}
+	if _, ok := registry.GetTable("crypt"); !ok {
+		t.Fatal("Expected the added table to be registered")
+	}
+
+	// Added tables must validate against the tables already registered
+	broken := &LootTable{Entries: []LootEntry{{Table: "missing", Weight: 1}}}
+	if err := registry.AddTables("broken.gbox", map[string]*LootTable{"broken": broken}); err == nil {
+		t.Fatal("Expected a table referring to a missing table to be rejected")
+	}
+	if _, ok := registry.GetTable("broken"); ok {
+		t.Error("Expected the rejected table not to be registered")
+	}
+
+	if err := registry.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if names := registry.TableNames(); len(names) != 3 {
+		t.Errorf("Expected added tables to survive a reload, got %v", names)
+	}
+}
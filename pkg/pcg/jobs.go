@@ -0,0 +1,338 @@
+package pcg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job queue defaults
+const (
+	DefaultMaxConcurrentJobs = 2
+	DefaultJobRetention      = time.Hour
+)
+
+// ErrJobNotFound is returned for job IDs the queue does not know, including
+// finished jobs that have passed their retention period.
+var ErrJobNotFound = errors.New("generation job not found")
+
+// ErrJobQueueClosed is returned when submitting to a closed queue
+var ErrJobQueueClosed = errors.New("generation job queue is closed")
+
+// JobStatus is the lifecycle state of a generation job
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// Finished reports whether the status is final
+func (s JobStatus) Finished() bool {
+	return s == JobCompleted || s == JobFailed || s == JobCanceled
+}
+
+// GenerationJobFunc performs the work of a job. ctx is canceled when the
+// job is canceled, times out or the queue closes, and carries a
+// ProgressFunc (see WithProgress) that records the job's progress; pass it
+// to the PCGManager Generate* methods or report through ProgressFromContext.
+type GenerationJobFunc func(ctx context.Context) (interface{}, error)
+
+// JobSpec describes a job to submit
+type JobSpec struct {
+	ContentType ContentType
+	LocationID  string
+	Owner       string            // Optional submitter, e.g. a session ID, for callers to check access
+	Timeout     time.Duration     // Optional limit on the job's run time
+	Run         GenerationJobFunc // The generation work
+	OnProgress  ProgressFunc      // Optional callback for the job's progress updates
+}
+
+// GenerationJob is a snapshot of a job's state
+type GenerationJob struct {
+	ID          string         `json:"job_id"`
+	ContentType ContentType    `json:"content_type"`
+	LocationID  string         `json:"location_id,omitempty"`
+	Owner       string         `json:"-"`
+	Status      JobStatus      `json:"status"`
+	Progress    ProgressUpdate `json:"progress"`
+	Result      interface{}    `json:"result,omitempty"` // Set once the job has completed
+	Error       string         `json:"error,omitempty"`  // Set when the job failed or was canceled
+	SubmittedAt time.Time      `json:"submitted_at"`
+	StartedAt   time.Time      `json:"started_at,omitempty"`
+	FinishedAt  time.Time      `json:"finished_at,omitempty"`
+}
+
+// jobEntry is a job tracked by the queue
+type jobEntry struct {
+	job    GenerationJob
+	spec   JobSpec
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// JobQueue runs generation jobs in the background, at most maxConcurrent at
+// a time and in submission order, so long generations such as complete
+// dungeons do not block their callers. Callers poll a job's status and
+// progress by ID, wait for it, or cancel it.
+//
+// Finished jobs are kept for the retention period so their results can be
+// collected, then dropped.
+//
+// Thread Safety: All methods are safe for concurrent use.
+type JobQueue struct {
+	mu            sync.Mutex
+	jobs          map[string]*jobEntry
+	pending       []*jobEntry
+	running       int
+	maxConcurrent int
+	retention     time.Duration
+	nextID        uint64
+	closed        bool
+	ctx           context.Context
+	cancel        context.CancelFunc
+	logger        *logrus.Logger
+}
+
+// NewJobQueue creates a job queue running at most maxConcurrent jobs at a
+// time. Values below 1 use DefaultMaxConcurrentJobs.
+func NewJobQueue(maxConcurrent int, logger *logrus.Logger) *JobQueue {
+	if maxConcurrent < 1 {
+		maxConcurrent = DefaultMaxConcurrentJobs
+	}
+	if logger == nil {
+		logger = logrus.New()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobQueue{
+		jobs:          make(map[string]*jobEntry),
+		maxConcurrent: maxConcurrent,
+		retention:     DefaultJobRetention,
+		ctx:           ctx,
+		cancel:        cancel,
+		logger:        logger,
+	}
+}
+
+// SetRetention sets how long finished jobs are kept
+func (q *JobQueue) SetRetention(retention time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.retention = retention
+}
+
+// Submit queues a job and returns its ID. The job starts as soon as fewer
+// than the maximum number of jobs are running.
+func (q *JobQueue) Submit(spec JobSpec) (string, error) {
+	if spec.Run == nil {
+		return "", fmt.Errorf("generation job has no work to run")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return "", ErrJobQueueClosed
+	}
+	q.pruneLocked(time.Now())
+
+	q.nextID++
+	entry := &jobEntry{
+		job: GenerationJob{
+			ID:          fmt.Sprintf("job_%d_%d", time.Now().UnixNano(), q.nextID),
+			ContentType: spec.ContentType,
+			LocationID:  spec.LocationID,
+			Owner:       spec.Owner,
+			Status:      JobQueued,
+			SubmittedAt: time.Now(),
+		},
+		spec: spec,
+		done: make(chan struct{}),
+	}
+	q.jobs[entry.job.ID] = entry
+	q.pending = append(q.pending, entry)
+
+	q.logger.WithFields(logrus.Fields{
+		"job_id":       entry.job.ID,
+		"content_type": spec.ContentType,
+		"location_id":  spec.LocationID,
+	}).Info("generation job queued")
+
+	q.startPendingLocked()
+	return entry.job.ID, nil
+}
+
+// Status returns a snapshot of the job
+func (q *JobQueue) Status(jobID string) (GenerationJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.jobs[jobID]
+	if !exists {
+		return GenerationJob{}, ErrJobNotFound
+	}
+	return entry.job, nil
+}
+
+// List returns snapshots of all jobs the queue tracks
+func (q *JobQueue) List() []GenerationJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]GenerationJob, 0, len(q.jobs))
+	for _, entry := range q.jobs {
+		jobs = append(jobs, entry.job)
+	}
+	return jobs
+}
+
+// Cancel cancels a queued or running job. A queued job is canceled at once;
+// a running job is canceled through its context and reported as canceled
+// when its work returns.
+func (q *JobQueue) Cancel(jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.jobs[jobID]
+	if !exists {
+		return ErrJobNotFound
+	}
+
+	switch entry.job.Status {
+	case JobQueued:
+		for i, pending := range q.pending {
+			if pending == entry {
+				q.pending = append(q.pending[:i], q.pending[i+1:]...)
+				break
+			}
+		}
+		q.finishLocked(entry, nil, context.Canceled)
+	case JobRunning:
+		entry.cancel()
+	default:
+		return fmt.Errorf("generation job %s already %s", jobID, entry.job.Status)
+	}
+	return nil
+}
+
+// Wait blocks until the job finishes or ctx is done, and returns the job's
+// final snapshot
+func (q *JobQueue) Wait(ctx context.Context, jobID string) (GenerationJob, error) {
+	q.mu.Lock()
+	entry, exists := q.jobs[jobID]
+	q.mu.Unlock()
+	if !exists {
+		return GenerationJob{}, ErrJobNotFound
+	}
+
+	select {
+	case <-entry.done:
+		return q.Status(jobID)
+	case <-ctx.Done():
+		return GenerationJob{}, ctx.Err()
+	}
+}
+
+// Close cancels all queued and running jobs and rejects new ones. Finished
+// jobs remain available.
+func (q *JobQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	for _, entry := range q.pending {
+		q.finishLocked(entry, nil, ErrJobQueueClosed)
+	}
+	q.pending = nil
+	q.cancel()
+}
+
+// startPendingLocked starts queued jobs while slots are free (requires q.mu)
+func (q *JobQueue) startPendingLocked() {
+	for q.running < q.maxConcurrent && len(q.pending) > 0 {
+		entry := q.pending[0]
+		q.pending = q.pending[1:]
+
+		ctx, cancel := context.WithCancel(q.ctx)
+		if entry.spec.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(q.ctx, entry.spec.Timeout)
+		}
+		entry.cancel = cancel
+		entry.job.Status = JobRunning
+		entry.job.StartedAt = time.Now()
+		q.running++
+
+		go q.run(ctx, entry)
+	}
+}
+
+// run performs a job's work and records the outcome
+func (q *JobQueue) run(ctx context.Context, entry *jobEntry) {
+	ctx = WithProgress(ctx, func(update ProgressUpdate) {
+		q.mu.Lock()
+		entry.job.Progress = update
+		q.mu.Unlock()
+		if entry.spec.OnProgress != nil {
+			entry.spec.OnProgress(update)
+		}
+	})
+
+	result, err := entry.spec.Run(ctx)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	entry.cancel()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running--
+	q.finishLocked(entry, result, err)
+	q.startPendingLocked()
+}
+
+// finishLocked records a job's outcome (requires q.mu)
+func (q *JobQueue) finishLocked(entry *jobEntry, result interface{}, err error) {
+	job := &entry.job
+	job.FinishedAt = time.Now()
+
+	switch {
+	case err == nil:
+		job.Status = JobCompleted
+		job.Result = result
+		job.Progress = ProgressUpdate{ContentType: job.ContentType, Stage: ProgressStageComplete, Percent: 100}
+	case errors.Is(err, context.Canceled) || errors.Is(err, ErrJobQueueClosed):
+		job.Status = JobCanceled
+		job.Error = err.Error()
+	default:
+		job.Status = JobFailed
+		job.Error = err.Error()
+	}
+	close(entry.done)
+
+	q.logger.WithFields(logrus.Fields{
+		"job_id":       job.ID,
+		"content_type": job.ContentType,
+		"status":       job.Status,
+		"error":        job.Error,
+	}).Info("generation job finished")
+}
+
+// pruneLocked drops finished jobs past the retention period (requires q.mu)
+func (q *JobQueue) pruneLocked(now time.Time) {
+	for id, entry := range q.jobs {
+		if entry.job.Status.Finished() && now.Sub(entry.job.FinishedAt) > q.retention {
+			delete(q.jobs, id)
+		}
+	}
+}
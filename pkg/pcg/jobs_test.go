@@ -0,0 +1,191 @@
+package pcg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingJob returns job work that reports progress, signals started and
+// waits for release or cancellation
+func blockingJob(started chan<- string, release <-chan struct{}, name string) GenerationJobFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		ProgressFromContext(ctx)(ProgressUpdate{ContentType: ContentTypeLevels, Stage: "rooms", Percent: 40})
+		started <- name
+		select {
+		case <-release:
+			return name, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func waitJob(t *testing.T, q *JobQueue, jobID string) GenerationJob {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	job, err := q.Wait(ctx, jobID)
+	require.NoError(t, err)
+	return job
+}
+
+func TestJobQueue_ConcurrencyLimitAndOrder(t *testing.T) {
+	q := NewJobQueue(1, nil)
+	defer q.Close()
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	var ids []string
+	for _, name := range []string{"first", "second", "third"} {
+		id, err := q.Submit(JobSpec{ContentType: ContentTypeLevels, Run: blockingJob(started, release, name)})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	assert.Equal(t, "first", <-started)
+	running, err := q.Status(ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, JobRunning, running.Status)
+	assert.Equal(t, 40.0, running.Progress.Percent)
+
+	queued, err := q.Status(ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, JobQueued, queued.Status, "only one job runs at a time")
+
+	close(release)
+	assert.Equal(t, "second", <-started)
+	assert.Equal(t, "third", <-started)
+
+	for i, id := range ids {
+		job := waitJob(t, q, id)
+		assert.Equal(t, JobCompleted, job.Status)
+		assert.Equal(t, []string{"first", "second", "third"}[i], job.Result)
+		assert.Equal(t, ProgressStageComplete, job.Progress.Stage)
+		assert.False(t, job.FinishedAt.Before(job.StartedAt))
+	}
+}
+
+func TestJobQueue_Cancel(t *testing.T) {
+	q := NewJobQueue(1, nil)
+	defer q.Close()
+
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	runningID, err := q.Submit(JobSpec{Run: blockingJob(started, release, "running")})
+	require.NoError(t, err)
+	queuedID, err := q.Submit(JobSpec{Run: blockingJob(started, release, "queued")})
+	require.NoError(t, err)
+	<-started
+
+	require.NoError(t, q.Cancel(queuedID))
+	assert.Equal(t, JobCanceled, waitJob(t, q, queuedID).Status)
+
+	require.NoError(t, q.Cancel(runningID))
+	job := waitJob(t, q, runningID)
+	assert.Equal(t, JobCanceled, job.Status)
+	assert.Equal(t, context.Canceled.Error(), job.Error)
+
+	assert.Error(t, q.Cancel(runningID), "finished jobs cannot be canceled")
+	assert.ErrorIs(t, q.Cancel("missing"), ErrJobNotFound)
+	assert.Empty(t, started, "the canceled queued job never started")
+}
+
+func TestJobQueue_FailureAndTimeout(t *testing.T) {
+	q := NewJobQueue(2, nil)
+	defer q.Close()
+
+	failedID, err := q.Submit(JobSpec{Run: func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("no rooms fit")
+	}})
+	require.NoError(t, err)
+
+	timedOutID, err := q.Submit(JobSpec{Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}})
+	require.NoError(t, err)
+
+	failed := waitJob(t, q, failedID)
+	assert.Equal(t, JobFailed, failed.Status)
+	assert.Equal(t, "no rooms fit", failed.Error)
+
+	assert.Equal(t, JobFailed, waitJob(t, q, timedOutID).Status, "timeouts fail the job")
+
+	_, err = q.Submit(JobSpec{})
+	assert.Error(t, err, "jobs need work to run")
+}
+
+func TestJobQueue_CloseAndRetention(t *testing.T) {
+	q := NewJobQueue(1, nil)
+
+	started := make(chan string, 1)
+	runningID, err := q.Submit(JobSpec{Run: blockingJob(started, nil, "running")})
+	require.NoError(t, err)
+	queuedID, err := q.Submit(JobSpec{Run: blockingJob(started, nil, "queued")})
+	require.NoError(t, err)
+	<-started
+
+	q.Close()
+	assert.Equal(t, JobCanceled, waitJob(t, q, runningID).Status)
+	assert.Equal(t, JobCanceled, waitJob(t, q, queuedID).Status)
+	_, err = q.Submit(JobSpec{Run: blockingJob(started, nil, "late")})
+	assert.ErrorIs(t, err, ErrJobQueueClosed)
+
+	q = NewJobQueue(1, nil)
+	defer q.Close()
+	q.SetRetention(0)
+	doneID, err := q.Submit(JobSpec{Run: func(ctx context.Context) (interface{}, error) { return nil, nil }})
+	require.NoError(t, err)
+	waitJob(t, q, doneID)
+	time.Sleep(time.Millisecond)
+
+	_, err = q.Submit(JobSpec{Run: func(ctx context.Context) (interface{}, error) { return nil, nil }})
+	require.NoError(t, err)
+	_, err = q.Status(doneID)
+	assert.ErrorIs(t, err, ErrJobNotFound, "finished jobs are dropped after the retention period")
+}
+
+func TestPCGManager_SubmitGenerationJob(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	require.NoError(t, manager.GetRegistry().RegisterGenerator("objective_based", NewQuestGenerator(logrus.New())))
+	defer manager.GetJobQueue().Close()
+
+	var mu sync.Mutex
+	var relayed []ProgressUpdate
+	jobID, err := manager.SubmitGenerationJob(JobSpec{
+		ContentType: ContentTypeQuests,
+		Owner:       "session-1",
+		Run: func(ctx context.Context) (interface{}, error) {
+			return manager.GeneratePersonalQuest(ctx, 42, QuestTypeFetch, 3)
+		},
+		OnProgress: func(update ProgressUpdate) {
+			mu.Lock()
+			relayed = append(relayed, update)
+			mu.Unlock()
+		},
+	})
+	require.NoError(t, err)
+
+	job := waitJob(t, manager.GetJobQueue(), jobID)
+	require.Equal(t, JobCompleted, job.Status, job.Error)
+	assert.IsType(t, &game.Quest{}, job.Result)
+	assert.Equal(t, "session-1", job.Owner)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, relayed, "the manager reports progress into the job")
+	assert.Equal(t, ProgressStageComplete, relayed[len(relayed)-1].Stage)
+
+	status, err := manager.GetGenerationJob(jobID)
+	require.NoError(t, err)
+	assert.Equal(t, JobCompleted, status.Status)
+}
@@ -0,0 +1,638 @@
+package levels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"goldbox-rpg/pkg/pcg"
+)
+
+// maxRepairPasses bounds the passes the constraint solver makes over a room
+// layout. Each pass repairs every constraint in turn, and a repair for one
+// constraint can undo another, so the passes repeat until the layout meets
+// them all or a pass changes nothing.
+const maxRepairPasses = 8
+
+// fillerRoomTypes are tried in order for rooms demoted to meet a maximum
+// count, after the level's own room types
+var fillerRoomTypes = []pcg.RoomType{
+	pcg.RoomTypeCombat,
+	pcg.RoomTypeTreasure,
+	pcg.RoomTypePuzzle,
+	pcg.RoomTypeRest,
+	pcg.RoomTypeTrap,
+	pcg.RoomTypeStory,
+}
+
+// validateLayoutConstraints checks that every layout constraint is well
+// formed
+func validateLayoutConstraints(constraints []pcg.LayoutConstraint) error {
+	for i, constraint := range constraints {
+		if err := constraint.Validate(); err != nil {
+			return fmt.Errorf("layout constraint %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// connectionPairs returns the indexes of the rooms createMinimumConnections
+// joins with corridors, in order: each room to the next, and the first to
+// the last once there are more than three rooms.
+func connectionPairs(n int) [][2]int {
+	var pairs [][2]int
+	for i := 0; i < n-1; i++ {
+		pairs = append(pairs, [2]int{i, i + 1})
+	}
+	if n > 3 {
+		pairs = append(pairs, [2]int{0, n - 1})
+	}
+	return pairs
+}
+
+// plannedAdjacency returns the neighbours of each of n rooms once they are
+// connected, so constraints can be repaired before corridors are dug
+func plannedAdjacency(n int) [][]int {
+	adjacency := make([][]int, n)
+	for _, pair := range connectionPairs(n) {
+		adjacency[pair[0]] = append(adjacency[pair[0]], pair[1])
+		adjacency[pair[1]] = append(adjacency[pair[1]], pair[0])
+	}
+	return adjacency
+}
+
+// connectedAdjacency returns the neighbours of each room as connected by
+// ConnectRooms
+func connectedAdjacency(rooms []*pcg.RoomLayout) [][]int {
+	index := make(map[string]int, len(rooms))
+	for i, room := range rooms {
+		index[room.ID] = i
+	}
+	adjacency := make([][]int, len(rooms))
+	for i, room := range rooms {
+		for _, id := range room.Connected {
+			if j, ok := index[id]; ok {
+				adjacency[i] = append(adjacency[i], j)
+			}
+		}
+	}
+	return adjacency
+}
+
+// fixedRoom reports whether room i of n is the entrance or the exit, which
+// are the first and last rooms of every layout and never change type
+func fixedRoom(i, n int) bool {
+	return i == 0 || i == n-1
+}
+
+// fixedType reports whether t is the type of the entrance or the exit
+func fixedType(t pcg.RoomType) bool {
+	return t == pcg.RoomTypeEntrance || t == pcg.RoomTypeExit
+}
+
+// roomsOfType returns the indexes of the rooms of type t
+func roomsOfType(rooms []*pcg.RoomLayout, t pcg.RoomType) []int {
+	var matching []int
+	for i, room := range rooms {
+		if room.Type == t {
+			matching = append(matching, i)
+		}
+	}
+	return matching
+}
+
+// roomIDs returns the IDs of the rooms at indexes
+func roomIDs(rooms []*pcg.RoomLayout, indexes []int) []string {
+	ids := make([]string, len(indexes))
+	for i, index := range indexes {
+		ids[i] = rooms[index].ID
+	}
+	return ids
+}
+
+// nextTo reports whether room i shares a corridor with a room of type t
+func nextTo(rooms []*pcg.RoomLayout, adjacency [][]int, i int, t pcg.RoomType) bool {
+	for _, j := range adjacency[i] {
+		if rooms[j].Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// distancesFrom returns how many corridors separate each room from the
+// nearest room of type t, or -1 for rooms it cannot reach
+func distancesFrom(rooms []*pcg.RoomLayout, adjacency [][]int, t pcg.RoomType) []int {
+	distances := make([]int, len(rooms))
+	var queue []int
+	for i, room := range rooms {
+		distances[i] = -1
+		if room.Type == t {
+			distances[i] = 0
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[current] {
+			if distances[next] < 0 {
+				distances[next] = distances[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return distances
+}
+
+// farthestRooms returns the rooms that could hold the constrained type of a
+// farthest constraint at the greatest distance from its other type, and
+// that distance
+func farthestRooms(rooms []*pcg.RoomLayout, distances []int, c pcg.LayoutConstraint) ([]int, int) {
+	var farthest []int
+	best := -1
+	for i, room := range rooms {
+		if fixedRoom(i, len(rooms)) || room.Type == c.Other || distances[i] < 0 {
+			continue
+		}
+		switch {
+		case distances[i] > best:
+			farthest, best = []int{i}, distances[i]
+		case distances[i] == best:
+			farthest = append(farthest, i)
+		}
+	}
+	return farthest, best
+}
+
+// evaluateLayoutConstraints checks the rooms, joined as adjacency lists,
+// against each constraint
+func evaluateLayoutConstraints(rooms []*pcg.RoomLayout, adjacency [][]int, constraints []pcg.LayoutConstraint) []pcg.ConstraintResult {
+	results := make([]pcg.ConstraintResult, len(constraints))
+	for i, c := range constraints {
+		results[i] = evaluateLayoutConstraint(rooms, adjacency, c)
+	}
+	return results
+}
+
+// evaluateLayoutConstraint checks the rooms against one constraint,
+// explaining what it found
+func evaluateLayoutConstraint(rooms []*pcg.RoomLayout, adjacency [][]int, c pcg.LayoutConstraint) pcg.ConstraintResult {
+	result := pcg.ConstraintResult{Constraint: c}
+	matching := roomsOfType(rooms, c.RoomType)
+
+	switch c.Kind {
+	case pcg.ConstraintCount:
+		result.Rooms = roomIDs(rooms, matching)
+		result.Satisfied = len(matching) >= c.Min && (c.Max == nil || len(matching) <= *c.Max)
+		result.Detail = fmt.Sprintf("found %d", len(matching))
+
+	case pcg.ConstraintAdjacent:
+		var adjacent []int
+		for _, i := range matching {
+			if nextTo(rooms, adjacency, i, c.Other) {
+				adjacent = append(adjacent, i)
+			}
+		}
+		result.Rooms = roomIDs(rooms, adjacent)
+		result.Satisfied = len(adjacent) >= c.Min
+		result.Detail = fmt.Sprintf("%d of %d are adjacent", len(adjacent), len(matching))
+
+	case pcg.ConstraintFarthest:
+		result.Rooms = roomIDs(rooms, matching)
+		distances := distancesFrom(rooms, adjacency, c.Other)
+		farthest, best := farthestRooms(rooms, distances, c)
+		switch {
+		case len(roomsOfType(rooms, c.Other)) == 0:
+			result.Detail = fmt.Sprintf("the level has no %s room", c.Other)
+		case len(matching) == 0:
+			result.Detail = fmt.Sprintf("the level has no %s room", c.RoomType)
+		default:
+			placed := -1
+			for _, i := range matching {
+				if distances[i] > placed {
+					placed = distances[i]
+				}
+			}
+			result.Satisfied = placed == best
+			result.Detail = fmt.Sprintf("the %s room is %d corridors from the nearest %s room, the farthest rooms (%s) are %d",
+				c.RoomType, placed, c.Other, strings.Join(roomIDs(rooms, farthest), ", "), best)
+		}
+	}
+	return result
+}
+
+// repairStrategy is one way of going about the repairs: the order in which
+// the kinds of constraint are repaired, and whether free rooms are taken
+// from the entrance end of the layout or from the exit end
+type repairStrategy struct {
+	order   []pcg.ConstraintKind
+	reverse bool
+}
+
+// repairStrategies are tried in turn until one meets every constraint.
+// Repairs are greedy, and a room taken for one constraint may be the room
+// another needed; trying other orders and the other end of the layout finds
+// most layouts that can be repaired at all.
+var repairStrategies = func() []repairStrategy {
+	orders := [][]pcg.ConstraintKind{
+		{pcg.ConstraintFarthest, pcg.ConstraintAdjacent, pcg.ConstraintCount},
+		{pcg.ConstraintAdjacent, pcg.ConstraintFarthest, pcg.ConstraintCount},
+		{pcg.ConstraintCount, pcg.ConstraintFarthest, pcg.ConstraintAdjacent},
+		{pcg.ConstraintCount, pcg.ConstraintAdjacent, pcg.ConstraintFarthest},
+	}
+	var strategies []repairStrategy
+	for _, reverse := range []bool{false, true} {
+		for _, order := range orders {
+			strategies = append(strategies, repairStrategy{order: order, reverse: reverse})
+		}
+	}
+	return strategies
+}()
+
+// layoutSolver repairs the room types of a planned layout to meet its
+// constraints. Rooms whose type no constraint names are free to change;
+// rooms placed to meet an adjacent or farthest constraint are locked, so
+// later repairs leave them be.
+type layoutSolver struct {
+	rooms       []*pcg.RoomLayout
+	adjacency   [][]int
+	constraints []pcg.LayoutConstraint
+	strategy    repairStrategy
+	referenced  map[pcg.RoomType]bool // Types any constraint names
+	constrained map[pcg.RoomType]bool // Types constraints are placed on
+	filler      pcg.RoomType          // Type given to demoted rooms, "" if there is none
+	locked      map[int]bool
+	repairs     []string
+}
+
+// solveLayoutConstraints repairs the room types of a layout from
+// generateRoomLayout to meet the level's constraints, before the rooms are
+// built and connected. When no strategy meets them all, the layout is left
+// as the strategy violating the fewest constraints repaired it. The
+// returned report lists the repairs made and the constraints the planned
+// layout meets.
+func solveLayoutConstraints(rooms []*pcg.RoomLayout, params pcg.LevelParams) pcg.ConstraintReport {
+	planned := make([]pcg.RoomType, len(rooms))
+	for i, room := range rooms {
+		planned[i] = room.Type
+	}
+
+	var best pcg.ConstraintReport
+	var bestTypes []pcg.RoomType
+	for _, strategy := range repairStrategies {
+		for i, room := range rooms {
+			room.Type = planned[i]
+		}
+		report := newLayoutSolver(rooms, params, strategy).solve()
+		if bestTypes == nil || len(report.Violations()) < len(best.Violations()) {
+			best = report
+			bestTypes = make([]pcg.RoomType, len(rooms))
+			for i, room := range rooms {
+				bestTypes[i] = room.Type
+			}
+		}
+		if best.Satisfied() {
+			break
+		}
+	}
+	for i, room := range rooms {
+		room.Type = bestTypes[i]
+	}
+
+	logger.WithFields(logrus.Fields{
+		"function":    "solveLayoutConstraints",
+		"constraints": len(params.LayoutConstraints),
+		"repairs":     len(best.Repairs),
+		"satisfied":   best.Satisfied(),
+	}).Debug("layout constraints solved")
+
+	return best
+}
+
+// newLayoutSolver prepares to repair rooms with strategy
+func newLayoutSolver(rooms []*pcg.RoomLayout, params pcg.LevelParams, strategy repairStrategy) *layoutSolver {
+	s := &layoutSolver{
+		rooms:       rooms,
+		adjacency:   plannedAdjacency(len(rooms)),
+		constraints: params.LayoutConstraints,
+		strategy:    strategy,
+		referenced:  make(map[pcg.RoomType]bool),
+		constrained: make(map[pcg.RoomType]bool),
+		locked:      make(map[int]bool),
+	}
+	for _, c := range params.LayoutConstraints {
+		s.referenced[c.RoomType] = true
+		s.constrained[c.RoomType] = true
+		if c.Other != "" {
+			s.referenced[c.Other] = true
+		}
+	}
+	for _, t := range append(append([]pcg.RoomType{}, params.RoomTypes...), fillerRoomTypes...) {
+		if !fixedType(t) && !s.referenced[t] {
+			s.filler = t
+			break
+		}
+	}
+	return s
+}
+
+// solve makes repair passes until the layout meets every constraint or a
+// pass changes nothing
+func (s *layoutSolver) solve() pcg.ConstraintReport {
+	for pass := 0; pass < maxRepairPasses; pass++ {
+		if (pcg.ConstraintReport{Results: evaluateLayoutConstraints(s.rooms, s.adjacency, s.constraints)}).Satisfied() {
+			break
+		}
+		repairs := len(s.repairs)
+		s.repairPass()
+		if len(s.repairs) == repairs {
+			break
+		}
+	}
+	return pcg.ConstraintReport{
+		Results: evaluateLayoutConstraints(s.rooms, s.adjacency, s.constraints),
+		Repairs: s.repairs,
+	}
+}
+
+// repairPass repairs each kind of constraint in the order of the strategy.
+// Locks are taken afresh each pass, as repairs may have moved the rooms
+// farthest from a type.
+func (s *layoutSolver) repairPass() {
+	clear(s.locked)
+	for _, kind := range s.strategy.order {
+		for _, c := range s.constraints {
+			if c.Kind != kind {
+				continue
+			}
+			switch kind {
+			case pcg.ConstraintCount:
+				s.repairCount(c)
+			case pcg.ConstraintAdjacent:
+				s.repairAdjacent(c)
+			case pcg.ConstraintFarthest:
+				s.repairFarthest(c)
+			}
+		}
+	}
+}
+
+// scan returns the indexes of rooms in the order the strategy takes them
+func (s *layoutSolver) scan(rooms []int) []int {
+	if !s.strategy.reverse {
+		return rooms
+	}
+	reversed := make([]int, len(rooms))
+	for i, room := range rooms {
+		reversed[len(rooms)-1-i] = room
+	}
+	return reversed
+}
+
+// allRooms returns the indexes of every room
+func (s *layoutSolver) allRooms() []int {
+	indexes := make([]int, len(s.rooms))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// free reports whether room i may change type
+func (s *layoutSolver) free(i int) bool {
+	return !fixedRoom(i, len(s.rooms)) && !s.referenced[s.rooms[i].Type] && !s.locked[i]
+}
+
+// spare reports whether room i may change type when no free room will do:
+// it is not locked, constraints name its type only as the other type, and
+// it is not the last room of its type
+func (s *layoutSolver) spare(i int) bool {
+	room := s.rooms[i]
+	if fixedRoom(i, len(s.rooms)) || s.locked[i] || s.constrained[room.Type] {
+		return false
+	}
+	return len(roomsOfType(s.rooms, room.Type)) > 1
+}
+
+// freeRoom returns the first free room for which accept holds, or failing
+// that the first spare one, or -1
+func (s *layoutSolver) freeRoom(accept func(i int) bool) int {
+	for _, usable := range []func(i int) bool{s.free, s.spare} {
+		for _, i := range s.scan(s.allRooms()) {
+			if usable(i) && accept(i) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// countAllows reports whether adding delta rooms of type t keeps within
+// the maximum of every count constraint on t
+func (s *layoutSolver) countAllows(t pcg.RoomType, delta int) bool {
+	count := len(roomsOfType(s.rooms, t))
+	for _, c := range s.constraints {
+		if c.Kind == pcg.ConstraintCount && c.RoomType == t && c.Max != nil && count+delta > *c.Max {
+			return false
+		}
+	}
+	return true
+}
+
+// retype changes the type of room i, recording the repair and the
+// constraint it serves
+func (s *layoutSolver) retype(i int, t pcg.RoomType, c pcg.LayoutConstraint) {
+	s.repairs = append(s.repairs, fmt.Sprintf("%s: %s -> %s (%s)", s.rooms[i].ID, s.rooms[i].Type, t, c))
+	s.rooms[i].Type = t
+}
+
+// swap exchanges the types of rooms i and j
+func (s *layoutSolver) swap(i, j int, c pcg.LayoutConstraint) {
+	ti, tj := s.rooms[i].Type, s.rooms[j].Type
+	s.retype(i, tj, c)
+	s.retype(j, ti, c)
+}
+
+// repairCount turns free rooms into the constrained type until there are
+// enough, and demotes rooms of the type until there are few enough,
+// sparing locked rooms where it can
+func (s *layoutSolver) repairCount(c pcg.LayoutConstraint) {
+	matching := roomsOfType(s.rooms, c.RoomType)
+	for len(matching) < c.Min {
+		room := s.freeRoom(func(int) bool { return true })
+		if room < 0 {
+			return
+		}
+		s.retype(room, c.RoomType, c)
+		matching = append(matching, room)
+	}
+
+	if c.Max == nil || s.filler == "" {
+		return
+	}
+	for len(matching) > *c.Max {
+		demote := len(matching) - 1
+		for i := len(matching) - 1; i >= 0; i-- {
+			if !s.locked[matching[i]] {
+				demote = i
+				break
+			}
+		}
+		s.retype(matching[demote], s.filler, c)
+		delete(s.locked, matching[demote])
+		matching = append(matching[:demote], matching[demote+1:]...)
+	}
+}
+
+// repairAdjacent moves rooms of the constrained type next to rooms of the
+// other type by swapping them with free rooms there, then turns more free
+// rooms there into the type while count constraints allow. When that is
+// not enough, it turns a free room into the other type to make room, and
+// tries again.
+func (s *layoutSolver) repairAdjacent(c pcg.LayoutConstraint) {
+	for s.placeAdjacent(c) < c.Min {
+		if !s.addOther(c) {
+			return
+		}
+	}
+}
+
+// placeAdjacent places rooms of the constrained type next to rooms of the
+// other type, returning how many are
+func (s *layoutSolver) placeAdjacent(c pcg.LayoutConstraint) int {
+	nextToOther := func(i int) bool { return nextTo(s.rooms, s.adjacency, i, c.Other) }
+
+	adjacent := 0
+	for _, room := range roomsOfType(s.rooms, c.RoomType) {
+		if nextToOther(room) {
+			s.lockAdjacent(room, c.Other)
+			adjacent++
+		}
+	}
+
+	for _, room := range roomsOfType(s.rooms, c.RoomType) {
+		if adjacent >= c.Min {
+			return adjacent
+		}
+		if s.locked[room] || nextToOther(room) {
+			continue
+		}
+		target := s.freeRoom(nextToOther)
+		if target < 0 {
+			break
+		}
+		s.swap(room, target, c)
+		s.lockAdjacent(target, c.Other)
+		adjacent++
+	}
+
+	for adjacent < c.Min && s.countAllows(c.RoomType, 1) {
+		target := s.freeRoom(nextToOther)
+		if target < 0 {
+			break
+		}
+		s.retype(target, c.RoomType, c)
+		s.lockAdjacent(target, c.Other)
+		adjacent++
+	}
+	return adjacent
+}
+
+// lockAdjacent locks room i and its neighbours of type other in place
+func (s *layoutSolver) lockAdjacent(i int, other pcg.RoomType) {
+	s.locked[i] = true
+	for _, j := range s.adjacency[i] {
+		if s.rooms[j].Type == other {
+			s.locked[j] = true
+		}
+	}
+}
+
+// addOther turns the free, or failing that spare, room with the most rooms
+// of the constrained type not yet placed, or rooms that may change, around
+// it into the other type, reporting whether there was one
+func (s *layoutSolver) addOther(c pcg.LayoutConstraint) bool {
+	if !s.countAllows(c.Other, 1) || fixedType(c.Other) {
+		return false
+	}
+
+	best, bestGain := -1, 0
+	for _, usable := range []func(i int) bool{s.free, s.spare} {
+		for _, i := range s.scan(s.allRooms()) {
+			if !usable(i) || s.rooms[i].Type == c.Other {
+				continue
+			}
+			gain := 0
+			for _, j := range s.adjacency[i] {
+				switch {
+				case s.rooms[j].Type == c.RoomType && !s.locked[j]:
+					gain += 2
+				case s.free(j) || s.spare(j):
+					gain++
+				}
+			}
+			if gain > bestGain {
+				best, bestGain = i, gain
+			}
+		}
+		if best >= 0 {
+			break
+		}
+	}
+	if best < 0 {
+		return false
+	}
+	s.retype(best, c.Other, c)
+	s.locked[best] = true
+	return true
+}
+
+// repairFarthest moves a room of the constrained type to a room as far from
+// the other type as any room can be, preferring free rooms to rooms of
+// types constraints name, or turns a free room there into the type when
+// there is none to move
+func (s *layoutSolver) repairFarthest(c pcg.LayoutConstraint) {
+	distances := distancesFrom(s.rooms, s.adjacency, c.Other)
+	farthest, _ := farthestRooms(s.rooms, distances, c)
+	for _, room := range farthest {
+		if s.rooms[room].Type == c.RoomType {
+			s.locked[room] = true
+			return
+		}
+	}
+
+	target := -1
+	for _, room := range s.scan(farthest) {
+		if s.free(room) {
+			target = room
+			break
+		}
+	}
+
+	for _, room := range roomsOfType(s.rooms, c.RoomType) {
+		if s.locked[room] {
+			continue
+		}
+		if target < 0 {
+			for _, candidate := range s.scan(farthest) {
+				if !s.locked[candidate] {
+					target = candidate
+					break
+				}
+			}
+			if target < 0 {
+				return
+			}
+		}
+		s.swap(room, target, c)
+		s.locked[target] = true
+		return
+	}
+
+	if target >= 0 && s.countAllows(c.RoomType, 1) {
+		s.retype(target, c.RoomType, c)
+		s.locked[target] = true
+	}
+}
@@ -0,0 +1,160 @@
+package levels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg"
+)
+
+// designerConstraints are the constraints of the request that introduced
+// them: exactly one shop, the boss room as far from the entrance as
+// possible, and two secret rooms next to treasure
+func designerConstraints() []pcg.LayoutConstraint {
+	one := 1
+	return []pcg.LayoutConstraint{
+		{Kind: pcg.ConstraintCount, RoomType: pcg.RoomTypeShop, Min: 1, Max: &one},
+		{Kind: pcg.ConstraintFarthest, RoomType: pcg.RoomTypeBoss, Other: pcg.RoomTypeEntrance},
+		{Kind: pcg.ConstraintAdjacent, RoomType: pcg.RoomTypeSecret, Other: pcg.RoomTypeTreasure, Min: 2},
+	}
+}
+
+// layoutOf returns rooms of the given types, with IDs as generateRoomLayout
+// assigns them
+func layoutOf(types ...pcg.RoomType) []*pcg.RoomLayout {
+	rooms := make([]*pcg.RoomLayout, len(types))
+	for i, t := range types {
+		rooms[i] = &pcg.RoomLayout{ID: fmt.Sprintf("room_%d", i), Type: t}
+	}
+	return rooms
+}
+
+func TestSolveLayoutConstraints(t *testing.T) {
+	rooms := layoutOf(
+		pcg.RoomTypeEntrance,
+		pcg.RoomTypeCombat,
+		pcg.RoomTypeTreasure,
+		pcg.RoomTypeCombat,
+		pcg.RoomTypeCombat,
+		pcg.RoomTypeBoss,
+		pcg.RoomTypeExit,
+	)
+	report := solveLayoutConstraints(rooms, pcg.LevelParams{LayoutConstraints: designerConstraints()})
+	if !report.Satisfied() {
+		t.Fatalf("layout not repaired: %+v", report.Violations())
+	}
+	if len(report.Repairs) == 0 {
+		t.Error("expected the repairs to be reported")
+	}
+
+	// The rooms form a loop, so rooms 3 and 4 are the farthest from the entrance
+	var types []pcg.RoomType
+	for _, room := range rooms {
+		types = append(types, room.Type)
+	}
+	want := []pcg.RoomType{
+		pcg.RoomTypeEntrance,
+		pcg.RoomTypeSecret,
+		pcg.RoomTypeTreasure,
+		pcg.RoomTypeSecret,
+		pcg.RoomTypeBoss,
+		pcg.RoomTypeShop,
+		pcg.RoomTypeExit,
+	}
+	if fmt.Sprint(types) != fmt.Sprint(want) {
+		t.Errorf("repaired layout = %v, want %v", types, want)
+	}
+}
+
+func TestSolveLayoutConstraints_Demotes(t *testing.T) {
+	none := 0
+	rooms := layoutOf(pcg.RoomTypeEntrance, pcg.RoomTypeTrap, pcg.RoomTypeTrap, pcg.RoomTypeExit)
+	params := pcg.LevelParams{
+		RoomTypes:         []pcg.RoomType{pcg.RoomTypeTrap, pcg.RoomTypeRest},
+		LayoutConstraints: []pcg.LayoutConstraint{{Kind: pcg.ConstraintCount, RoomType: pcg.RoomTypeTrap, Max: &none}},
+	}
+	report := solveLayoutConstraints(rooms, params)
+	if !report.Satisfied() {
+		t.Fatalf("layout not repaired: %+v", report.Violations())
+	}
+	for _, room := range rooms[1:3] {
+		if room.Type != pcg.RoomTypeRest {
+			t.Errorf("%s is %s, want the level's first unconstrained type, rest", room.ID, room.Type)
+		}
+	}
+}
+
+func TestEvaluateLayoutConstraints_Explains(t *testing.T) {
+	rooms := layoutOf(pcg.RoomTypeEntrance, pcg.RoomTypeBoss, pcg.RoomTypeSecret, pcg.RoomTypeCombat, pcg.RoomTypeExit)
+	results := evaluateLayoutConstraints(rooms, plannedAdjacency(len(rooms)), designerConstraints())
+
+	wantDetails := []string{
+		"found 0",
+		"the boss room is 1 corridors from the nearest entrance room, the farthest rooms (room_2, room_3) are 2",
+		"0 of 1 are adjacent",
+	}
+	for i, result := range results {
+		if result.Satisfied {
+			t.Errorf("%s: unexpectedly satisfied", result.Constraint)
+		}
+		if result.Detail != wantDetails[i] {
+			t.Errorf("%s: detail = %q, want %q", result.Constraint, result.Detail, wantDetails[i])
+		}
+	}
+}
+
+func TestRoomCorridorGenerator_GenerateLevel_LayoutConstraints(t *testing.T) {
+	params := pcg.LevelParams{
+		GenerationParams:  pcg.GenerationParams{Seed: 4242, Difficulty: 10},
+		MinRooms:          10,
+		MaxRooms:          12,
+		RoomTypes:         []pcg.RoomType{pcg.RoomTypeCombat, pcg.RoomTypeTreasure},
+		LevelTheme:        pcg.ThemeClassic,
+		HasBoss:           true,
+		LayoutConstraints: designerConstraints(),
+	}
+
+	level, err := NewRoomCorridorGeneratorWithSeed(7).GenerateLevel(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+	report, ok := level.Properties["constraints"].(pcg.ConstraintReport)
+	if !ok || !report.Satisfied() {
+		t.Fatalf("expected a satisfied constraint report, got %+v", level.Properties["constraints"])
+	}
+	shops := 0
+	for _, site := range level.Properties["rooms"].([]pcg.RoomSite) {
+		if site.Type == pcg.RoomTypeShop {
+			shops++
+		}
+	}
+	if shops != 1 {
+		t.Errorf("level has %d shops, want exactly 1", shops)
+	}
+
+	again, err := NewRoomCorridorGeneratorWithSeed(7).GenerateLevel(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+	if fmt.Sprint(again.Properties["rooms"]) != fmt.Sprint(level.Properties["rooms"]) {
+		t.Error("same seed should produce the same repaired rooms")
+	}
+
+	// Four rooms leave no room for two secret rooms next to treasure and a shop
+	params.MinRooms, params.MaxRooms = 4, 4
+	_, err = NewRoomCorridorGeneratorWithSeed(7).GenerateLevel(context.Background(), params)
+	var violated *pcg.ConstraintError
+	if !errors.As(err, &violated) {
+		t.Fatalf("expected a *pcg.ConstraintError, got %v", err)
+	}
+	if len(violated.Report.Violations()) == 0 {
+		t.Error("expected the report to explain the violations")
+	}
+
+	params.LayoutConstraints = []pcg.LayoutConstraint{{Kind: pcg.ConstraintCount, RoomType: pcg.RoomTypeExit, Min: 2}}
+	if _, err := NewRoomCorridorGeneratorWithSeed(7).GenerateLevel(context.Background(), params); err == nil {
+		t.Error("expected constraints on exit rooms to be rejected")
+	}
+}
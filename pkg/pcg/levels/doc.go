@@ -27,7 +27,8 @@
 //   - Secret: Hidden rooms requiring discovery
 //   - Shop: Merchant areas for equipment trading
 //   - Rest: Safe zones for healing and saving
-//   - Trap: Hazard rooms requiring careful navigation
+//   - Trap: Hazard rooms requiring careful navigation, populated with
+//     game.Trap values by the TrapGenerator
 //   - Story: Narrative rooms with lore and dialogue
 //
 // # Creating a Level Generator
@@ -56,6 +57,23 @@
 //	}
 //	level, err := gen.GenerateLevel(ctx, params)
 //
+// # Layout Constraints
+//
+// Levels honour the hard rules in LevelParams.LayoutConstraints. Right after
+// partitioning, a repair pass changes room types to meet them: it turns
+// rooms of types no constraint names into the types constraints need,
+// demotes surplus rooms, and swaps rooms along the planned chain of
+// corridors to place them next to, or as far as possible from, other room
+// types. The first and last rooms, the entrance and the exit, never change.
+// Repairs are greedy, so the pass retries in other orders before giving up.
+// Once the rooms are connected the constraints are checked again; the
+// report is kept in the level's "constraints" property, and a level that
+// still violates any fails with a *pcg.ConstraintError:
+//
+//	params.LayoutConstraints = []pcg.LayoutConstraint{
+//	    {Kind: pcg.ConstraintAdjacent, RoomType: pcg.RoomTypeSecret, Other: pcg.RoomTypeTreasure, Min: 2},
+//	}
+//
 // # Corridor Styles
 //
 // The CorridorPlanner supports multiple connection styles:
@@ -77,6 +95,28 @@
 //   - Sewer: Underground waterways and grates
 //   - Forest: Natural outdoor dungeon variants
 //
+// Every tile of a level takes the light level of its theme through
+// pcg.ApplyThemeLight: undead crypts are dark and horror levels dim.
+//
+// # Wave Function Collapse Interiors
+//
+// WFCGenerator assembles castle and temple interiors from a tile adjacency
+// ruleset instead of BSP rooms. Each ruleset tile is a small square pattern;
+// tiles fit side by side when their touching edges agree, so corridors meet
+// corridors and room walls line up. Rulesets are built in and can be
+// replaced or extended from data/pcg/wfc_rulesets.yaml:
+//
+//	gen := levels.NewWFCGenerator()
+//	if err := gen.LoadRulesets("data/pcg/wfc_rulesets.yaml"); err != nil {
+//	    return err
+//	}
+//	params.Constraints = map[string]interface{}{"wfc_ruleset": "temple", "width": 48, "height": 36}
+//	level, err := gen.GenerateLevel(ctx, params)
+//
+// Layouts are deterministic for a given seed. When propagation reaches a
+// contradiction the generator restarts with the next random stream derived
+// from the seed, giving up after a fixed number of attempts.
+//
 // # Integration with PCG System
 //
 // This package implements the pcg.Generator interface for integration with
@@ -113,4 +153,41 @@
 //	}
 //
 //	// Use level.Tiles, level.Rooms, level.Corridors, etc.
+//
+// # Doors and Keys
+//
+// The room-corridor generator turns the ends of corridors into door tiles
+// and locks one door for every four points of difficulty. A
+// KeyPlacementPlanner places the key of each locked door in a room the
+// player can reach without passing that door, locking doors one at a time
+// and placing each new key where the keys placed so far let the player go,
+// so every level can be solved. The doors and keys are stored in the
+// level's "doors" ([]pcg.DoorSite) and "keys" ([]pcg.KeySite) properties:
+//
+//	plan, err := levels.NewKeyPlacementPlanner(rng).Plan(level, candidates, start, nil, 2, difficulty)
+//	ok := levels.Solvable(level, plan.Doors, plan.Keys, start)
+//
+// # Interactive Features
+//
+// After placing doors, the room-corridor generator gives puzzle, rest, story
+// and secret rooms interactive game.Feature entities: the levers of puzzle
+// rooms, which work the nearest door of their room, and a fountain,
+// bookshelf or altar. A FeatureGenerator declares the verbs of each
+// feature with their checks and effects, and the features are stored in
+// the level's "features" property ([]*game.Feature), each tagging its tile
+// with a "feature_id" property:
+//
+//	features := (&levels.FeatureGenerator{}).GenerateFeatures(level, rooms, doors)
+//
+// # Tiled Export
+//
+// ExportTiled writes a level in the JSON format of the Tiled map editor. The
+// map has a tileset with one tile per game.TileType, a "tiles" layer and
+// object groups for doors, stairs, traps, spawns and items. Traps, NPCs and
+// items come from the world objects passed in; merchants of shop rooms come
+// from the level's "shops" property, and an "annotations" group of GM notes,
+// markers and region labels from its "annotations" property:
+//
+//	level, objects, err := world.LevelSnapshot(0)
+//	tiled, err := levels.ExportTiled(level, 0, objects, pcg.TiledOptions{})
 package levels
@@ -0,0 +1,185 @@
+package levels
+
+import (
+	"fmt"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// FeatureGenerator turns the rooms of a generated level into interactive
+// game.Feature entities players can use with the interactObject RPC:
+//   - each lever of a puzzle room can be pulled, toggling it and working
+//     the nearest door of the room, if any
+//   - rest rooms hold a fountain whose water heals 1d8 hit points
+//   - story rooms hold a bookshelf whose lore grants experience to a
+//     reader passing an Intelligence check, and which hides gold
+//   - secret rooms hold an altar that blesses those passing a Wisdom check
+//     and burns those who fail it
+//
+// Check DCs and rewards scale with the room's difficulty. The same rooms
+// always yield the same features.
+type FeatureGenerator struct{}
+
+// GenerateFeatures creates the features of the rooms of level. Positions
+// are level tiles; the server adds the level's index when it places them.
+//
+// Parameters:
+//   - level: The level, whose ID prefixes the feature IDs
+//   - rooms: The level's rooms
+//   - doors: The level's doors, which levers may be linked to
+//
+// Returns:
+//   - []*game.Feature: One or more features per puzzle, rest, story and
+//     secret room
+func (fg *FeatureGenerator) GenerateFeatures(level *game.Level, rooms []*pcg.RoomLayout, doors []pcg.DoorSite) []*game.Feature {
+	var features []*game.Feature
+	add := func(feature *game.Feature) {
+		feature.ID = fmt.Sprintf("%s_feature_%d", level.ID, len(features))
+		if walkable(level, feature.Position) {
+			features = append(features, feature)
+		}
+	}
+
+	for _, room := range rooms {
+		center := game.Position{X: room.Bounds.X + room.Bounds.Width/2, Y: room.Bounds.Y + room.Bounds.Height/2}
+		switch room.Type {
+		case pcg.RoomTypePuzzle:
+			for _, roomFeature := range room.Features {
+				if roomFeature.Type == "lever" {
+					add(leverFeature(roomFeature.Position, nearestRoomDoor(room, roomFeature.Position, doors)))
+				}
+			}
+		case pcg.RoomTypeRest:
+			add(fountainFeature(center))
+		case pcg.RoomTypeStory:
+			add(bookshelfFeature(game.Position{X: room.Bounds.X + 1, Y: room.Bounds.Y + 1}, room.Difficulty))
+		case pcg.RoomTypeSecret:
+			add(altarFeature(center, room.Difficulty))
+		}
+	}
+	return features
+}
+
+// leverFeature creates a lever that, when doorID is set, works that door
+func leverFeature(pos game.Position, doorID string) *game.Feature {
+	pull := game.Interaction{
+		Verb:        "pull",
+		Description: "Pull the lever",
+		Repeatable:  true,
+		OnSuccess:   []game.InteractionEffect{{Type: game.InteractionToggle}},
+	}
+	if doorID != "" {
+		pull.OnSuccess = append(pull.OnSuccess, game.InteractionEffect{Type: game.InteractionTrigger, TargetID: doorID})
+	}
+	return &game.Feature{
+		Name:         "Lever",
+		Description:  "An iron lever set into the floor",
+		Kind:         game.FeatureLever,
+		Position:     pos,
+		Interactions: []game.Interaction{pull},
+	}
+}
+
+// fountainFeature creates a fountain that heals whoever drinks from it
+func fountainFeature(pos game.Position) *game.Feature {
+	return &game.Feature{
+		Name:        "Fountain",
+		Description: "Clear water wells up in a stone basin",
+		Kind:        game.FeatureFountain,
+		Position:    pos,
+		Interactions: []game.Interaction{{
+			Verb:        "drink",
+			Description: "Drink from the fountain",
+			Repeatable:  true,
+			OnSuccess:   []game.InteractionEffect{{Type: game.InteractionHeal, Dice: "1d8"}},
+		}},
+	}
+}
+
+// bookshelfFeature creates a bookshelf of lore with gold hidden behind
+// its books
+func bookshelfFeature(pos game.Position, difficulty int) *game.Feature {
+	return &game.Feature{
+		Name:        "Bookshelf",
+		Description: "Dusty tomes line the shelves",
+		Kind:        game.FeatureBookshelf,
+		Position:    pos,
+		Interactions: []game.Interaction{
+			{
+				Verb:        "read",
+				Description: "Study the tomes",
+				Ability:     "intelligence",
+				DC:          10 + difficulty/2,
+				OnSuccess:   []game.InteractionEffect{{Type: game.InteractionExperience, Amount: 25 * (difficulty + 1)}},
+			},
+			{
+				Verb:        "search",
+				Description: "Search behind the books",
+				Ability:     "wisdom",
+				DC:          12 + difficulty/2,
+				OnSuccess:   []game.InteractionEffect{{Type: game.InteractionGold, Amount: 10 * (difficulty + 1)}},
+			},
+		},
+	}
+}
+
+// altarFeature creates an altar that blesses the worthy once
+func altarFeature(pos game.Position, difficulty int) *game.Feature {
+	return &game.Feature{
+		Name:        "Altar",
+		Description: "An altar to a forgotten god",
+		Kind:        game.FeatureAltar,
+		Position:    pos,
+		Interactions: []game.Interaction{{
+			Verb:        "pray",
+			Description: "Pray at the altar",
+			Ability:     "wisdom",
+			DC:          10 + difficulty/2,
+			OnSuccess: []game.InteractionEffect{{
+				Type:      game.InteractionCondition,
+				Condition: game.EffectStatBoost,
+				Rounds:    10,
+				Magnitude: 1 + float64(difficulty/5),
+			}},
+			OnFailure: []game.InteractionEffect{{Type: game.InteractionDamage, Dice: "1d6"}},
+		}},
+	}
+}
+
+// nearestRoomDoor returns the ID of the door closest to pos among those on
+// the edge of room, or "" if room has none
+func nearestRoomDoor(room *pcg.RoomLayout, pos game.Position, doors []pcg.DoorSite) string {
+	bounds := room.Bounds
+	doorID, best := "", 0
+	for _, door := range doors {
+		p := door.Position
+		if p.X < bounds.X-1 || p.X > bounds.X+bounds.Width || p.Y < bounds.Y-1 || p.Y > bounds.Y+bounds.Height {
+			continue
+		}
+		dx, dy := p.X-pos.X, p.Y-pos.Y
+		distance := dx*dx + dy*dy
+		if doorID == "" || distance < best {
+			doorID, best = door.DoorID, distance
+		}
+	}
+	return doorID
+}
+
+// placeFeatures generates the interactive features of a level and records
+// them in its "features" property, tagging the tile under each with its
+// "feature_id"
+func placeFeatures(level *game.Level, rooms []*pcg.RoomLayout) {
+	doors, _ := level.Properties["doors"].([]pcg.DoorSite)
+	features := (&FeatureGenerator{}).GenerateFeatures(level, rooms, doors)
+	for _, feature := range features {
+		tile := &level.Tiles[feature.Position.Y][feature.Position.X]
+		if tile.Properties == nil {
+			tile.Properties = make(map[string]interface{})
+		}
+		tile.Properties["feature_id"] = feature.ID
+	}
+	if len(features) > 0 {
+		level.Properties["features"] = features
+	}
+}
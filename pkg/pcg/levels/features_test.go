@@ -0,0 +1,83 @@
+package levels
+
+import (
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+func TestFeatureGenerator_GenerateFeatures(t *testing.T) {
+	level := corridorLevel(
+		"############",
+		"#000#111#22#",
+		"#000.111.22#",
+		"#000#111#22#",
+		"############",
+	)
+	rooms := []*pcg.RoomLayout{
+		{
+			ID:     "room_0",
+			Type:   pcg.RoomTypePuzzle,
+			Bounds: pcg.Rectangle{X: 0, Y: 0, Width: 4, Height: 5},
+			Features: []pcg.RoomFeature{
+				{Type: "lever", Position: game.Position{X: 2, Y: 2}},
+				{Type: "pressure_plate", Position: game.Position{X: 1, Y: 1}},
+			},
+		},
+		{ID: "room_1", Type: pcg.RoomTypeRest, Bounds: pcg.Rectangle{X: 4, Y: 0, Width: 4, Height: 5}},
+		{ID: "room_2", Type: pcg.RoomTypeStory, Bounds: pcg.Rectangle{X: 8, Y: 0, Width: 4, Height: 5}, Difficulty: 4},
+	}
+	doors := []pcg.DoorSite{
+		{DoorID: "test_door_0", Position: game.Position{X: 4, Y: 2}},
+		{DoorID: "test_door_1", Position: game.Position{X: 8, Y: 2}},
+	}
+
+	features := (&FeatureGenerator{}).GenerateFeatures(level, rooms, doors)
+	if len(features) != 3 {
+		t.Fatalf("expected a lever, a fountain and a bookshelf, got %d features", len(features))
+	}
+
+	lever, fountain, bookshelf := features[0], features[1], features[2]
+	if lever.Kind != game.FeatureLever || lever.ID != "test_feature_0" {
+		t.Errorf("expected the lever first, got %s %s", lever.Kind, lever.ID)
+	}
+	effects := lever.Interactions[0].OnSuccess
+	if last := effects[len(effects)-1]; last.Type != game.InteractionTrigger || last.TargetID != "test_door_0" {
+		t.Errorf("expected the lever to work the nearest door, got %+v", effects)
+	}
+	if fountain.Kind != game.FeatureFountain || fountain.Position != (game.Position{X: 6, Y: 2}) {
+		t.Errorf("expected a fountain in the middle of the rest room, got %s at %v", fountain.Kind, fountain.Position)
+	}
+	if bookshelf.Kind != game.FeatureBookshelf || bookshelf.Interactions[0].DC != 12 {
+		t.Errorf("expected a bookshelf with a DC 12 read check, got %s %+v", bookshelf.Kind, bookshelf.Interactions[0])
+	}
+
+	for _, feature := range features {
+		if err := feature.Validate(); err != nil {
+			t.Errorf("generated feature is invalid: %v", err)
+		}
+	}
+}
+
+func TestPlaceFeatures_TagsTiles(t *testing.T) {
+	level := corridorLevel(
+		"#####",
+		"#000#",
+		"#000#",
+		"#000#",
+		"#####",
+	)
+	level.Properties = map[string]interface{}{}
+	rooms := []*pcg.RoomLayout{{ID: "room_0", Type: pcg.RoomTypeSecret, Bounds: pcg.Rectangle{X: 0, Y: 0, Width: 5, Height: 5}}}
+
+	placeFeatures(level, rooms)
+
+	features, ok := level.Properties["features"].([]*game.Feature)
+	if !ok || len(features) != 1 || features[0].Kind != game.FeatureAltar {
+		t.Fatalf("expected an altar in the level's features, got %v", level.Properties["features"])
+	}
+	if id := level.Tiles[2][2].Properties["feature_id"]; id != features[0].ID {
+		t.Errorf("expected the altar's tile to be tagged, got %v", id)
+	}
+}
@@ -7,9 +7,13 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"goldbox-rpg/pkg/game"
 	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/items"
+	"goldbox-rpg/pkg/tracing"
 )
 
 // logger is the package-level logger for level generation tracing
@@ -64,7 +68,7 @@ func NewRoomCorridorGeneratorWithSeed(seed int64) *RoomCorridorGenerator {
 	rcg := &RoomCorridorGenerator{
 		version:        "1.0.0",
 		roomGenerators: make(map[pcg.RoomType]RoomGenerator),
-		rng:            rand.New(rand.NewSource(seed)),
+		rng:            pcg.NewRNG(seed, "levels"),
 	}
 
 	// Register default room generators
@@ -74,7 +78,7 @@ func NewRoomCorridorGeneratorWithSeed(seed int64) *RoomCorridorGenerator {
 
 // registerDefaultRoomGenerators registers the default room generators
 func (rcg *RoomCorridorGenerator) registerDefaultRoomGenerators() {
-	rcg.roomGenerators[pcg.RoomTypeCombat] = &CombatRoomGenerator{}
+	rcg.roomGenerators[pcg.RoomTypeCombat] = &CombatRoomGenerator{monsters: pcg.NewMonsterGenerator(nil)}
 	rcg.roomGenerators[pcg.RoomTypeTreasure] = &TreasureRoomGenerator{}
 	rcg.roomGenerators[pcg.RoomTypePuzzle] = &PuzzleRoomGenerator{}
 	rcg.roomGenerators[pcg.RoomTypeBoss] = &BossRoomGenerator{}
@@ -87,9 +91,17 @@ func (rcg *RoomCorridorGenerator) registerDefaultRoomGenerators() {
 	rcg.roomGenerators[pcg.RoomTypeStory] = &StoryRoomGenerator{}
 }
 
+// SetLootTables makes combat and treasure rooms drop items resolved from the
+// "combat" and "treasure" loot tables. Passing nil restores the default
+// behaviour of describing treasure without concrete items.
+func (rcg *RoomCorridorGenerator) SetLootTables(tables *items.LootTableRegistry) {
+	rcg.roomGenerators[pcg.RoomTypeCombat] = &CombatRoomGenerator{lootTables: tables, monsters: pcg.NewMonsterGenerator(nil)}
+	rcg.roomGenerators[pcg.RoomTypeTreasure] = &TreasureRoomGenerator{lootTables: tables}
+}
+
 // SetSeed sets the random seed for deterministic generation
 func (rcg *RoomCorridorGenerator) SetSeed(seed int64) {
-	rcg.rng = rand.New(rand.NewSource(seed))
+	rcg.rng = pcg.NewRNG(seed, "levels")
 }
 
 // GetType returns the content type this generator produces
@@ -125,7 +137,7 @@ func (rcg *RoomCorridorGenerator) Validate(params pcg.GenerationParams) error {
 		return fmt.Errorf("player level must be between 1 and 20")
 	}
 
-	return nil
+	return validateLayoutConstraints(levelParams.LayoutConstraints)
 }
 
 // Generate implements the Generator interface
@@ -138,18 +150,34 @@ func (rcg *RoomCorridorGenerator) Generate(ctx context.Context, params pcg.Gener
 	// Set the seed for deterministic generation
 	rcg.SetSeed(params.Seed)
 
+	// Progress is a runtime callback, so it is only set on the outer params
+	if levelParams.Progress == nil {
+		levelParams.Progress = params.Progress
+	}
+
 	return rcg.GenerateLevel(ctx, levelParams)
 }
 
 // GenerateLevel creates a complete dungeon level.
 // The function respects context cancellation and will abort generation
 // if the context is cancelled, returning context.Canceled or context.DeadlineExceeded.
-func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.LevelParams) (*game.Level, error) {
+func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.LevelParams) (_ *game.Level, err error) {
 	// Check for context cancellation before starting
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("level generation cancelled before start: %w", err)
 	}
 
+	if err := validateLayoutConstraints(params.LayoutConstraints); err != nil {
+		return nil, err
+	}
+
+	// Each phase is traced as a child of the whole generation
+	ctx, span := tracing.Start(ctx, "pcg.level.generate",
+		attribute.Int64("pcg.seed", params.Seed),
+		attribute.String("pcg.level_theme", string(params.LevelTheme)),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	// Create generation context
 	seedMgr := pcg.NewSeedManager(params.Seed)
 	genCtx := pcg.NewGenerationContext(seedMgr, pcg.ContentTypeLevels, "level_generation", params.GenerationParams)
@@ -158,7 +186,9 @@ func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.
 	width, height := rcg.calculateLevelDimensions(params)
 
 	// 1. Plan room layout using space partitioning
+	_, phase := startLevelPhase(ctx, "partition")
 	roomLayouts, err := rcg.generateRoomLayout(width, height, params, genCtx)
+	tracing.End(phase, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate room layout: %w", err)
 	}
@@ -168,8 +198,19 @@ func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.
 		return nil, fmt.Errorf("level generation cancelled during room layout: %w", err)
 	}
 
+	// 1b. Repair room types to meet the designer's layout constraints
+	var constraintReport pcg.ConstraintReport
+	if len(params.LayoutConstraints) > 0 {
+		_, phase = startLevelPhase(ctx, "constraints")
+		constraintReport = solveLayoutConstraints(roomLayouts, params)
+		tracing.End(phase, nil)
+	}
+	params.ReportProgress(pcg.ContentTypeLevels, "room_layout", 15)
+
 	// 2. Generate individual rooms
+	_, phase = startLevelPhase(ctx, "rooms")
 	err = rcg.generateRooms(roomLayouts, params, genCtx)
+	tracing.End(phase, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate rooms: %w", err)
 	}
@@ -178,9 +219,12 @@ func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("level generation cancelled during room generation: %w", err)
 	}
+	params.ReportProgress(pcg.ContentTypeLevels, "rooms", 40)
 
 	// 3. Create corridor connections
-	corridors, err := rcg.ConnectRooms(ctx, roomLayouts, params)
+	phaseCtx, phase := startLevelPhase(ctx, "corridors")
+	corridors, err := rcg.ConnectRooms(phaseCtx, roomLayouts, params)
+	tracing.End(phase, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect rooms: %w", err)
 	}
@@ -189,9 +233,12 @@ func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("level generation cancelled during corridor connection: %w", err)
 	}
+	params.ReportProgress(pcg.ContentTypeLevels, "corridors", 60)
 
 	// 4. Add special features and encounters
+	_, phase = startLevelPhase(ctx, "features")
 	err = rcg.addSpecialFeatures(roomLayouts, params, genCtx)
+	tracing.End(phase, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add special features: %w", err)
 	}
@@ -200,9 +247,19 @@ func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("level generation cancelled during feature addition: %w", err)
 	}
+	params.ReportProgress(pcg.ContentTypeLevels, "special_features", 75)
 
 	// 5. Validate connectivity and balance
+	_, phase = startLevelPhase(ctx, "validation")
 	err = rcg.validateLevel(roomLayouts, corridors)
+	if err == nil && len(params.LayoutConstraints) > 0 {
+		// Constraints are checked again on the rooms as connected
+		constraintReport.Results = evaluateLayoutConstraints(roomLayouts, connectedAdjacency(roomLayouts), params.LayoutConstraints)
+		if !constraintReport.Satisfied() {
+			err = &pcg.ConstraintError{Report: constraintReport}
+		}
+	}
+	tracing.End(phase, err)
 	if err != nil {
 		return nil, fmt.Errorf("level validation failed: %w", err)
 	}
@@ -211,6 +268,7 @@ func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("level generation cancelled during validation: %w", err)
 	}
+	params.ReportProgress(pcg.ContentTypeLevels, "validation", 85)
 
 	// 6. Convert to game.Level format
 	level, err := rcg.convertToGameLevel(roomLayouts, corridors, width, height, params)
@@ -218,9 +276,29 @@ func (rcg *RoomCorridorGenerator) GenerateLevel(ctx context.Context, params pcg.
 		return nil, fmt.Errorf("failed to convert to game level: %w", err)
 	}
 
+	if len(params.LayoutConstraints) > 0 {
+		level.Properties["constraints"] = constraintReport
+	}
+
+	// 7. Place doors at corridor entrances and lock some of them
+	rng := pcg.NewRNG(seedMgr.DeriveContextSeed(pcg.ContentTypeLevels, "doors"), "levels:doors")
+	if err := placeDoors(level, roomLayouts, corridors, params.Difficulty, rng); err != nil {
+		return nil, fmt.Errorf("failed to place doors: %w", err)
+	}
+
+	// 8. Turn levers, fountains, bookshelves and altars into interactive features
+	placeFeatures(level, roomLayouts)
+	pcg.ApplyThemeLight(level, params.LevelTheme)
+	params.ReportProgress(pcg.ContentTypeLevels, "conversion", 95)
+
 	return level, nil
 }
 
+// startLevelPhase starts the tracing span of one level generation phase
+func startLevelPhase(ctx context.Context, phase string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "pcg.level."+phase, attribute.String("pcg.phase", phase))
+}
+
 // calculateLevelDimensions calculates appropriate dimensions based on room count
 func (rcg *RoomCorridorGenerator) calculateLevelDimensions(params pcg.LevelParams) (width, height int) {
 	roomCount := params.MinRooms + rcg.rng.Intn(params.MaxRooms-params.MinRooms+1)
@@ -228,21 +306,15 @@ func (rcg *RoomCorridorGenerator) calculateLevelDimensions(params pcg.LevelParam
 	// Estimate dimensions based on room count and theme
 	baseSize := 40 + roomCount*8 // Base size scales with room count
 
-	// Theme-specific adjustments
-	switch params.LevelTheme {
-	case pcg.ThemeClassic:
-		width, height = baseSize, baseSize
-	case pcg.ThemeHorror:
-		// Longer, narrower levels for tension
-		width, height = baseSize+20, baseSize-10
-	case pcg.ThemeNatural:
-		// More organic, irregular dimensions
-		width, height = baseSize+rcg.rng.Intn(20)-10, baseSize+rcg.rng.Intn(20)-10
-	case pcg.ThemeMechanical:
-		// Perfect squares for mechanical precision
-		width, height = baseSize, baseSize
-	default:
-		width, height = baseSize, baseSize
+	// Theme-specific adjustments; unregistered themes get square levels
+	width, height = baseSize, baseSize
+	if theme, exists := pcg.Themes().Get(params.LevelTheme); exists {
+		width += theme.WidthOffset
+		height += theme.HeightOffset
+		if theme.SizeJitter > 0 {
+			width += rcg.rng.Intn(2*theme.SizeJitter) - theme.SizeJitter
+			height += rcg.rng.Intn(2*theme.SizeJitter) - theme.SizeJitter
+		}
 	}
 
 	// Ensure minimum dimensions
@@ -468,34 +540,21 @@ type RoomConnection struct {
 	EndRoomIndex   int
 }
 
-// createMinimumConnections creates a minimum set of connections for level connectivity
+// createMinimumConnections creates a minimum set of connections for level
+// connectivity: a chain through the rooms in order, closed into a loop once
+// there are more than three rooms (see connectionPairs)
 func (rcg *RoomCorridorGenerator) createMinimumConnections(rooms []*pcg.RoomLayout) []RoomConnection {
 	var connections []RoomConnection
 
-	// Connect adjacent rooms in a simple chain
-	for i := 0; i < len(rooms)-1; i++ {
-		startPos := rcg.findConnectionPoint(rooms[i])
-		endPos := rcg.findConnectionPoint(rooms[i+1])
-
-		connections = append(connections, RoomConnection{
-			Start:          startPos,
-			End:            endPos,
-			StartRoomIndex: i,
-			EndRoomIndex:   i + 1,
-		})
-	}
-
-	// Add some additional connections for interesting layouts
-	if len(rooms) > 3 {
-		// Connect first and last room for loop
-		startPos := rcg.findConnectionPoint(rooms[0])
-		endPos := rcg.findConnectionPoint(rooms[len(rooms)-1])
+	for _, pair := range connectionPairs(len(rooms)) {
+		startPos := rcg.findConnectionPoint(rooms[pair[0]])
+		endPos := rcg.findConnectionPoint(rooms[pair[1]])
 
 		connections = append(connections, RoomConnection{
 			Start:          startPos,
 			End:            endPos,
-			StartRoomIndex: 0,
-			EndRoomIndex:   len(rooms) - 1,
+			StartRoomIndex: pair[0],
+			EndRoomIndex:   pair[1],
 		})
 	}
 
@@ -661,11 +720,18 @@ func (rcg *RoomCorridorGenerator) convertToGameLevel(rooms []*pcg.RoomLayout, co
 		}
 	}
 
-	// Place room tiles
+	// Place room tiles, tagged with their room so entering it can be detected
 	for _, room := range rooms {
 		for y := 0; y < len(room.Tiles) && room.Bounds.Y+y < height; y++ {
 			for x := 0; x < len(room.Tiles[y]) && room.Bounds.X+x < width; x++ {
-				level.Tiles[room.Bounds.Y+y][room.Bounds.X+x] = room.Tiles[y][x]
+				tile := room.Tiles[y][x]
+				properties := make(map[string]interface{}, len(tile.Properties)+1)
+				for key, value := range tile.Properties {
+					properties[key] = value
+				}
+				properties["room_id"] = room.ID
+				tile.Properties = properties
+				level.Tiles[room.Bounds.Y+y][room.Bounds.X+x] = tile
 			}
 		}
 	}
@@ -690,10 +756,89 @@ func (rcg *RoomCorridorGenerator) convertToGameLevel(rooms []*pcg.RoomLayout, co
 	level.Properties["corridor_count"] = len(corridors)
 	level.Properties["generator"] = "room_corridor"
 	level.Properties["version"] = rcg.version
+	if shops := shopSites(rooms); len(shops) > 0 {
+		level.Properties["shops"] = shops
+	}
+	level.Properties["rooms"] = roomSites(rooms)
 
 	return level, nil
 }
 
+// placeDoors turns the ends of corridors into door tiles and locks one door
+// for every four points of difficulty, recording the doors and their keys
+// in the level's "doors" and "keys" properties. Keys are never placed on
+// room features such as traps.
+func placeDoors(level *game.Level, rooms []*pcg.RoomLayout, corridors []pcg.Corridor, difficulty int, rng *rand.Rand) error {
+	var start game.Position
+	avoid := make(map[game.Position]bool)
+	for _, room := range rooms {
+		if room.Type == pcg.RoomTypeEntrance {
+			start = game.Position{X: room.Bounds.X + room.Bounds.Width/2, Y: room.Bounds.Y + room.Bounds.Height/2}
+		}
+		for _, feature := range room.Features {
+			avoid[feature.Position] = true
+		}
+	}
+
+	candidates := make([]game.Position, 0, 2*len(corridors))
+	for _, corridor := range corridors {
+		candidates = append(candidates, corridor.Start, corridor.End)
+	}
+
+	plan, err := NewKeyPlacementPlanner(rng).Plan(level, candidates, start, avoid, difficulty/4, difficulty)
+	if err != nil {
+		return err
+	}
+	for _, door := range plan.Doors {
+		tile := &level.Tiles[door.Position.Y][door.Position.X]
+		tile.Type = game.TileDoor
+		tile.Properties = map[string]interface{}{"door_id": door.DoorID}
+	}
+	if len(plan.Doors) > 0 {
+		level.Properties["doors"] = plan.Doors
+	}
+	if len(plan.Keys) > 0 {
+		level.Properties["keys"] = plan.Keys
+	}
+	return nil
+}
+
+// shopSites lists the shop rooms of a level with their merchant placement.
+// Merchants stand in the middle of their room.
+func shopSites(rooms []*pcg.RoomLayout) []pcg.ShopSite {
+	var sites []pcg.ShopSite
+	for _, room := range rooms {
+		if room.Type != pcg.RoomTypeShop {
+			continue
+		}
+		faction, _ := room.Properties["merchant_faction"].(string)
+		seed, _ := room.Properties["merchant_seed"].(int64)
+		sites = append(sites, pcg.ShopSite{
+			RoomID:     room.ID,
+			Position:   game.Position{X: room.Bounds.X + room.Bounds.Width/2, Y: room.Bounds.Y + room.Bounds.Height/2},
+			Faction:    faction,
+			Seed:       seed,
+			Difficulty: room.Difficulty,
+		})
+	}
+	return sites
+}
+
+// roomSites lists the rooms of a level with their type and difficulty
+func roomSites(rooms []*pcg.RoomLayout) []pcg.RoomSite {
+	sites := make([]pcg.RoomSite, 0, len(rooms))
+	for _, room := range rooms {
+		faction, _ := room.Properties["merchant_faction"].(string)
+		sites = append(sites, pcg.RoomSite{
+			RoomID:     room.ID,
+			Type:       room.Type,
+			Difficulty: room.Difficulty,
+			Faction:    faction,
+		})
+	}
+	return sites
+}
+
 // GenerateRoom creates a single room with specified constraints
 func (rcg *RoomCorridorGenerator) GenerateRoom(ctx context.Context, bounds pcg.Rectangle, roomType pcg.RoomType, params pcg.LevelParams) (*pcg.RoomLayout, error) {
 	generator, exists := rcg.roomGenerators[roomType]
@@ -2,11 +2,17 @@ package levels
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"goldbox-rpg/pkg/game"
 	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/items"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestNewRoomCorridorGenerator(t *testing.T) {
@@ -279,6 +285,114 @@ func TestRoomCorridorGenerator_GenerateLevel(t *testing.T) {
 	}
 }
 
+func TestRoomCorridorGenerator_GenerateLevel_RecordsShops(t *testing.T) {
+	levelParams := pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 2468, Difficulty: 4},
+		MinRooms:         4,
+		MaxRooms:         4,
+		RoomTypes:        []pcg.RoomType{pcg.RoomTypeShop},
+		LevelTheme:       pcg.ThemeClassic,
+	}
+
+	level, err := NewRoomCorridorGeneratorWithSeed(1).GenerateLevel(context.Background(), levelParams)
+	if err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+
+	shops, ok := level.Properties["shops"].([]pcg.ShopSite)
+	if !ok || len(shops) == 0 {
+		t.Fatalf("expected shop sites in level properties, got %v", level.Properties["shops"])
+	}
+	for _, shop := range shops {
+		if shop.Faction != ShopMerchantFaction || shop.Seed == 0 || shop.RoomID == "" {
+			t.Errorf("incomplete shop site %+v", shop)
+		}
+		if !level.Tiles[shop.Position.Y][shop.Position.X].Walkable {
+			t.Errorf("merchant of %s stands on an unwalkable tile", shop.RoomID)
+		}
+	}
+
+	again, err := NewRoomCorridorGeneratorWithSeed(1).GenerateLevel(context.Background(), levelParams)
+	if err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+	if shops[0] != again.Properties["shops"].([]pcg.ShopSite)[0] {
+		t.Error("same seed should produce the same shops")
+	}
+}
+
+func TestRoomCorridorGenerator_GenerateLevel_ThemeLight(t *testing.T) {
+	tests := []struct {
+		theme pcg.LevelTheme
+		want  game.LightLevel
+	}{
+		{pcg.ThemeClassic, game.LightBright},
+		{pcg.ThemeHorror, game.LightDim},
+		{pcg.ThemeUndead, game.LightDark},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.theme), func(t *testing.T) {
+			level, err := NewRoomCorridorGeneratorWithSeed(1).GenerateLevel(context.Background(), pcg.LevelParams{
+				GenerationParams: pcg.GenerationParams{Seed: 97, Difficulty: 3},
+				MinRooms:         3,
+				MaxRooms:         4,
+				LevelTheme:       tt.theme,
+			})
+			if err != nil {
+				t.Fatalf("GenerateLevel failed: %v", err)
+			}
+			for y := range level.Tiles {
+				for x, tile := range level.Tiles[y] {
+					if tile.Light != tt.want {
+						t.Fatalf("tile %d,%d is %v, want %v", x, y, tile.Light, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRoomCorridorGenerator_GenerateLevel_TracesPhases(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	levelParams := pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 1357, Difficulty: 3},
+		MinRooms:         3,
+		MaxRooms:         5,
+		LevelTheme:       pcg.ThemeClassic,
+	}
+	if _, err := NewRoomCorridorGeneratorWithSeed(1).GenerateLevel(context.Background(), levelParams); err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+
+	var root sdktrace.ReadOnlySpan
+	phases := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if span.Name() == "pcg.level.generate" {
+			root = span
+		} else {
+			phases[span.Name()] = span
+		}
+	}
+	if root == nil {
+		t.Fatal("expected a pcg.level.generate span")
+	}
+	for _, phase := range []string{"partition", "rooms", "corridors", "features", "validation"} {
+		span, ok := phases["pcg.level."+phase]
+		if !ok {
+			t.Errorf("missing span for phase %s", phase)
+			continue
+		}
+		if span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("phase %s is not a child of the generation span", phase)
+		}
+	}
+}
+
 func TestRoomCorridorGenerator_GenerateRoom(t *testing.T) {
 	generator := NewRoomCorridorGenerator()
 
@@ -322,6 +436,38 @@ func TestRoomCorridorGenerator_GenerateRoom(t *testing.T) {
 	}
 }
 
+func TestCombatRoomGenerator_PopulatesMonsters(t *testing.T) {
+	generator := NewRoomCorridorGeneratorWithSeed(1)
+	bounds := pcg.Rectangle{X: 0, Y: 0, Width: 10, Height: 8}
+	levelParams := pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 4242, Difficulty: 9},
+		LevelTheme:       pcg.ThemeUndead,
+	}
+
+	room, err := generator.GenerateRoom(context.Background(), bounds, pcg.RoomTypeCombat, levelParams)
+	if err != nil {
+		t.Fatalf("GenerateRoom failed: %v", err)
+	}
+
+	monsters, ok := room.Properties["monsters"].([]*pcg.MonsterStatBlock)
+	if !ok {
+		t.Fatalf("expected monster stat blocks, got %T", room.Properties["monsters"])
+	}
+	if len(monsters) != room.Properties["enemy_count"].(int) {
+		t.Errorf("expected %v monsters, got %d", room.Properties["enemy_count"], len(monsters))
+	}
+	for _, monster := range monsters {
+		switch monster.Kind {
+		case "skeleton", "zombie", "lich":
+		default:
+			t.Errorf("monster kind %s is not an undead theme enemy", monster.Kind)
+		}
+		if monster.Difficulty != 9 || monster.HitPoints < 1 {
+			t.Errorf("monster %+v not scaled to the room", monster)
+		}
+	}
+}
+
 func TestCalculateLevelDimensions(t *testing.T) {
 	generator := NewRoomCorridorGenerator()
 
@@ -560,6 +706,42 @@ func TestRoomCorridorGenerator_GenerateLevel_DeadlineExceeded(t *testing.T) {
 	}
 }
 
+func TestRoomCorridorGenerator_SetLootTables(t *testing.T) {
+	tables := items.NewLootTableRegistry(nil)
+	if err := tables.LoadFromFile(filepath.Join("..", "..", "..", "data", "pcg", "items", "loot_tables.yaml")); err != nil {
+		t.Fatalf("Failed to load loot tables: %v", err)
+	}
+
+	generator := NewRoomCorridorGeneratorWithSeed(7)
+	generator.SetLootTables(tables)
+
+	bounds := pcg.Rectangle{X: 0, Y: 0, Width: 10, Height: 10}
+	levelParams := pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 7, Difficulty: 8, PlayerLevel: 8},
+		LevelTheme:       pcg.ThemeClassic,
+	}
+
+	room, err := generator.GenerateRoom(context.Background(), bounds, pcg.RoomTypeTreasure, levelParams)
+	if err != nil {
+		t.Fatalf("GenerateRoom failed: %v", err)
+	}
+
+	chests := 0
+	for _, feature := range room.Features {
+		if feature.Type != "treasure_chest" {
+			continue
+		}
+		chests++
+		loot, ok := feature.Properties["items"].([]*game.Item)
+		if !ok || len(loot) == 0 {
+			t.Errorf("Expected chest to hold items from the treasure table, got %v", feature.Properties["items"])
+		}
+	}
+	if chests == 0 {
+		t.Fatal("Expected at least one treasure chest")
+	}
+}
+
 // containsString checks if a string contains a substring
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
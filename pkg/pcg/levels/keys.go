@@ -0,0 +1,204 @@
+package levels
+
+import (
+	"fmt"
+	"math/rand"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// KeyPlacementPlanner turns the entrances of corridors into doors, locks
+// some of them and places the key of each locked door in a room that can
+// be reached before the door.
+//
+// Doors are locked one at a time. After each lock the planner walks the
+// level from the start the way a player would, collecting the keys placed
+// so far and passing the doors they open, and places the new key on a room
+// tile that walk reaches. Every key is therefore reachable without its own
+// door, so the locks can always be opened in the order they were placed.
+// All choices come from the supplied RNG, so the same seed always yields
+// the same doors and keys.
+type KeyPlacementPlanner struct {
+	rng *rand.Rand
+}
+
+// DoorPlan is the doors and keys the planner placed in a level.
+type DoorPlan struct {
+	Doors []pcg.DoorSite
+	Keys  []pcg.KeySite
+}
+
+// NewKeyPlacementPlanner creates a planner drawing from rng.
+func NewKeyPlacementPlanner(rng *rand.Rand) *KeyPlacementPlanner {
+	return &KeyPlacementPlanner{rng: rng}
+}
+
+// Plan places doors at the walkable candidate positions of level and locks
+// up to lockCount of them.
+//
+// Parameters:
+//   - level: The level, whose walkable tiles and "room_id" tile properties
+//     are used
+//   - candidates: Door positions, such as the ends of corridors
+//   - start: Where players enter the level
+//   - avoid: Tiles keys must not be placed on, such as traps
+//   - lockCount: How many doors to lock at most
+//   - difficulty: Scales the lock DCs
+//
+// Returns:
+//   - *DoorPlan: The doors and the keys of the locked ones
+//   - error: If the resulting level could not be solved
+func (kp *KeyPlacementPlanner) Plan(level *game.Level, candidates []game.Position, start game.Position, avoid map[game.Position]bool, lockCount, difficulty int) (*DoorPlan, error) {
+	plan := &DoorPlan{}
+	seen := make(map[game.Position]bool, len(candidates))
+	for _, pos := range candidates {
+		if seen[pos] || pos == start || !walkable(level, pos) {
+			continue
+		}
+		seen[pos] = true
+		plan.Doors = append(plan.Doors, pcg.DoorSite{
+			DoorID:   fmt.Sprintf("%s_door_%d", level.ID, len(plan.Doors)),
+			Position: pos,
+			State:    game.DoorClosed,
+		})
+	}
+
+	order := kp.rng.Perm(len(plan.Doors))
+	for _, i := range order {
+		if len(plan.Keys) >= lockCount {
+			break
+		}
+		door := &plan.Doors[i]
+		door.State = game.DoorLocked
+		door.KeyID = door.DoorID + "_key"
+		door.LockDC = 10 + difficulty/2 + kp.rng.Intn(4)
+
+		spot, ok := kp.keySpot(level, plan, start, avoid)
+		if !ok {
+			// No room is reachable before this door; leave it unlocked
+			door.State, door.KeyID, door.LockDC = game.DoorClosed, "", 0
+			continue
+		}
+		roomID, _ := level.Tiles[spot.Y][spot.X].Properties["room_id"].(string)
+		plan.Keys = append(plan.Keys, pcg.KeySite{
+			KeyID:    door.KeyID,
+			DoorID:   door.DoorID,
+			RoomID:   roomID,
+			Position: spot,
+		})
+	}
+
+	if !Solvable(level, plan.Doors, plan.Keys, start) {
+		return nil, fmt.Errorf("level %s cannot be solved with its locked doors", level.ID)
+	}
+	return plan, nil
+}
+
+// keySpot picks a free room tile reachable with the keys placed so far
+func (kp *KeyPlacementPlanner) keySpot(level *game.Level, plan *DoorPlan, start game.Position, avoid map[game.Position]bool) (game.Position, bool) {
+	taken := make(map[game.Position]bool, len(plan.Doors)+len(plan.Keys))
+	for _, door := range plan.Doors {
+		taken[door.Position] = true
+	}
+	for _, key := range plan.Keys {
+		taken[key.Position] = true
+	}
+
+	var spots []game.Position
+	for _, pos := range explore(level, plan.Doors, plan.Keys, start) {
+		if taken[pos] || avoid[pos] || pos == start {
+			continue
+		}
+		if roomID, _ := level.Tiles[pos.Y][pos.X].Properties["room_id"].(string); roomID != "" {
+			spots = append(spots, pos)
+		}
+	}
+	if len(spots) == 0 {
+		return game.Position{}, false
+	}
+	return spots[kp.rng.Intn(len(spots))], true
+}
+
+// Solvable reports whether a player entering at start can reach every
+// tile they could reach with all doors open, picking up keys on the way
+// and opening the locked doors whose keys they hold.
+func Solvable(level *game.Level, doors []pcg.DoorSite, keys []pcg.KeySite, start game.Position) bool {
+	open := make([]pcg.DoorSite, len(doors))
+	for i, door := range doors {
+		open[i] = pcg.DoorSite{DoorID: door.DoorID, Position: door.Position, State: game.DoorClosed}
+	}
+	return len(explore(level, doors, keys, start)) == len(explore(level, open, nil, start))
+}
+
+// explore returns the tiles reachable from start, in the order they are
+// reached, picking up every key on a reached tile and passing the locked
+// doors those keys open. Closed doors are passed freely.
+func explore(level *game.Level, doors []pcg.DoorSite, keys []pcg.KeySite, start game.Position) []game.Position {
+	locked := make(map[game.Position]string)
+	for _, door := range doors {
+		if door.State == game.DoorLocked {
+			locked[door.Position] = door.KeyID
+		}
+	}
+	keysAt := make(map[game.Position][]string)
+	for _, key := range keys {
+		keysAt[key.Position] = append(keysAt[key.Position], key.KeyID)
+	}
+
+	if !walkable(level, start) {
+		return nil
+	}
+	held := make(map[string]bool)
+	reached := map[game.Position]bool{start: true}
+	order := []game.Position{start}
+	var waiting []game.Position // Locked doors met without their key
+
+	for next := 0; next < len(order); next++ {
+		pos := order[next]
+		for _, keyID := range keysAt[pos] {
+			held[keyID] = true
+		}
+
+		neighbours := []game.Position{
+			{X: pos.X + 1, Y: pos.Y}, {X: pos.X - 1, Y: pos.Y},
+			{X: pos.X, Y: pos.Y + 1}, {X: pos.X, Y: pos.Y - 1},
+		}
+		for _, n := range neighbours {
+			if reached[n] || !walkable(level, n) {
+				continue
+			}
+			if _, isLocked := locked[n]; isLocked {
+				waiting = append(waiting, n)
+				continue
+			}
+			reached[n] = true
+			order = append(order, n)
+		}
+
+		if next == len(order)-1 {
+			// Everything open has been reached; pass the doors now unlocked
+			var still []game.Position
+			for _, door := range waiting {
+				switch {
+				case reached[door]:
+				case held[locked[door]]:
+					reached[door] = true
+					order = append(order, door)
+				default:
+					still = append(still, door)
+				}
+			}
+			waiting = still
+		}
+	}
+	return order
+}
+
+// walkable reports whether pos lies on a walkable tile of level
+func walkable(level *game.Level, pos game.Position) bool {
+	if pos.Y < 0 || pos.Y >= len(level.Tiles) || pos.X < 0 || pos.X >= len(level.Tiles[pos.Y]) {
+		return false
+	}
+	return level.Tiles[pos.Y][pos.X].Walkable
+}
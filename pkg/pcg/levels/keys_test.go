@@ -0,0 +1,146 @@
+package levels
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// corridorLevel builds a level from rows where '.' is floor, '#' wall and
+// digits are floor tiles of the room with that ID
+func corridorLevel(rows ...string) *game.Level {
+	level := &game.Level{ID: "test", Tiles: make([][]game.Tile, len(rows))}
+	for y, row := range rows {
+		level.Tiles[y] = make([]game.Tile, len(row))
+		for x, c := range row {
+			tile := game.Tile{Type: game.TileWall, Properties: map[string]interface{}{}}
+			if c != '#' {
+				tile.Type, tile.Walkable = game.TileFloor, true
+			}
+			if c >= '0' && c <= '9' {
+				tile.Properties["room_id"] = "room_" + string(c)
+			}
+			level.Tiles[y][x] = tile
+		}
+	}
+	return level
+}
+
+func TestKeyPlacementPlanner_KeysPrecedeTheirDoors(t *testing.T) {
+	// Three rooms in a row joined by one-tile passages at x=4 and x=7
+	level := corridorLevel(
+		"##########",
+		"#000#11#2#",
+		"#000.11.2#",
+		"#000#11#2#",
+		"##########",
+	)
+	start := game.Position{X: 1, Y: 1}
+	candidates := []game.Position{{X: 4, Y: 2}, {X: 7, Y: 2}}
+
+	for seed := int64(0); seed < 20; seed++ {
+		plan, err := NewKeyPlacementPlanner(rand.New(rand.NewSource(seed))).Plan(level, candidates, start, nil, 2, 8)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if len(plan.Doors) != 2 || len(plan.Keys) != 2 {
+			t.Fatalf("seed %d: expected 2 locked doors with keys, got %d doors and %d keys", seed, len(plan.Doors), len(plan.Keys))
+		}
+
+		keys := make(map[string]pcg.KeySite)
+		for _, key := range plan.Keys {
+			keys[key.KeyID] = key
+		}
+		first := keys[plan.Doors[0].KeyID]
+		if first.RoomID != "room_0" {
+			t.Errorf("seed %d: key of the first door lies in %s, beyond the door", seed, first.RoomID)
+		}
+		if second := keys[plan.Doors[1].KeyID]; second.RoomID == "room_2" {
+			t.Errorf("seed %d: key of the second door lies beyond it", seed)
+		}
+		for _, door := range plan.Doors {
+			if door.State != game.DoorLocked || door.LockDC < 14 {
+				t.Errorf("seed %d: door %+v is not locked with a difficulty 8 lock", seed, door)
+			}
+		}
+	}
+}
+
+func TestKeyPlacementPlanner_SkipsWallsAndDuplicates(t *testing.T) {
+	level := corridorLevel("#0.1#")
+	candidates := []game.Position{{X: 2, Y: 0}, {X: 0, Y: 0}, {X: 2, Y: 0}}
+
+	plan, err := NewKeyPlacementPlanner(rand.New(rand.NewSource(1))).Plan(level, candidates, game.Position{X: 1, Y: 0}, nil, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Doors) != 1 || plan.Doors[0].State != game.DoorClosed {
+		t.Fatalf("expected one closed door, got %+v", plan.Doors)
+	}
+	if len(plan.Keys) != 0 {
+		t.Errorf("expected no keys without locks, got %+v", plan.Keys)
+	}
+}
+
+func TestSolvable(t *testing.T) {
+	level := corridorLevel("#0.1#")
+	start := game.Position{X: 1, Y: 0}
+	door := pcg.DoorSite{DoorID: "d", Position: game.Position{X: 2, Y: 0}, State: game.DoorLocked, KeyID: "k"}
+
+	if !Solvable(level, []pcg.DoorSite{door}, []pcg.KeySite{{KeyID: "k", Position: start}}, start) {
+		t.Error("a key at the start should open the door")
+	}
+	if Solvable(level, []pcg.DoorSite{door}, []pcg.KeySite{{KeyID: "k", Position: game.Position{X: 3, Y: 0}}}, start) {
+		t.Error("a key behind its own door cannot be reached")
+	}
+}
+
+func TestRoomCorridorGenerator_PlacesSolvableDoors(t *testing.T) {
+	level, err := NewRoomCorridorGenerator().GenerateLevel(context.Background(), pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 777, Difficulty: 12},
+		MinRooms:         6,
+		MaxRooms:         8,
+		CorridorStyle:    pcg.CorridorStraight,
+		LevelTheme:       pcg.ThemeClassic,
+	})
+	if err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+
+	doors, _ := level.Properties["doors"].([]pcg.DoorSite)
+	keys, _ := level.Properties["keys"].([]pcg.KeySite)
+	if len(doors) == 0 {
+		t.Fatal("expected doors at the corridor entrances")
+	}
+	for _, door := range doors {
+		if tile := level.Tiles[door.Position.Y][door.Position.X]; tile.Type != game.TileDoor {
+			t.Errorf("door %s stands on a %v tile", door.DoorID, tile.Type)
+		}
+	}
+	for _, key := range keys {
+		if key.RoomID == "" {
+			t.Errorf("key %s lies outside any room", key.KeyID)
+		}
+	}
+
+	var entrance string
+	for _, room := range level.Properties["rooms"].([]pcg.RoomSite) {
+		if room.Type == pcg.RoomTypeEntrance {
+			entrance = room.RoomID
+		}
+	}
+	for y, row := range level.Tiles {
+		for x, tile := range row {
+			if roomID, _ := tile.Properties["room_id"].(string); roomID == entrance && tile.Type != game.TileDoor {
+				if !Solvable(level, doors, keys, game.Position{X: x, Y: y}) {
+					t.Fatal("the locked doors cannot all be opened from the entrance")
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("entrance room not found")
+}
@@ -1,18 +1,56 @@
 package levels
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 
 	"goldbox-rpg/pkg/game"
 	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/items"
 )
 
+// ShopMerchantFaction is the faction merchants of generated shop rooms trade for
+const ShopMerchantFaction = "merchants_guild"
+
+// Loot table names resolved by room generators when loot tables are configured
+const (
+	CombatLootTable   = "combat"
+	TreasureLootTable = "treasure"
+)
+
+// resolveRoomLoot rolls the named loot table at the given difficulty. It
+// returns nil without consuming randomness when no tables are configured
+// or the table is not defined.
+func resolveRoomLoot(tables *items.LootTableRegistry, name string, difficulty int, rng *rand.Rand) ([]*game.Item, error) {
+	if tables == nil {
+		return nil, nil
+	}
+	if _, exists := tables.GetTable(name); !exists {
+		return nil, nil
+	}
+
+	loot, err := tables.ResolveLootTable(context.Background(), name, difficulty, rng)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s loot: %w", name, err)
+	}
+	return loot, nil
+}
+
 // CombatRoomGenerator creates combat encounter rooms with tactical features.
 // Generated rooms include cover positions, elevated areas, traps, and hazards
 // to create interesting tactical combat scenarios. Enemy types and counts scale
 // with difficulty level, and loot chances increase accordingly.
-type CombatRoomGenerator struct{}
+//
+// The room's enemies are generated as monster stat blocks of the theme's
+// enemy types in the "monsters" property.
+//
+// When loot tables are configured, a room that passes its loot chance drops
+// items from the "combat" table in the "loot" property.
+type CombatRoomGenerator struct {
+	lootTables *items.LootTableRegistry
+	monsters   *pcg.MonsterGenerator
+}
 
 // GenerateRoom creates a combat encounter room with tactical features, enemy spawn
 // configurations, and loot tables scaled by difficulty. The room includes walls,
@@ -63,10 +101,27 @@ func (crg *CombatRoomGenerator) GenerateRoom(bounds pcg.Rectangle, theme pcg.Lev
 	room.Doors = append(room.Doors, crg.generateDoorPositions(bounds, rng)...)
 
 	// Set combat-specific properties
-	room.Properties["enemy_count"] = 2 + difficulty/3
+	enemyCount := 2 + difficulty/3
+	room.Properties["enemy_count"] = enemyCount
 	room.Properties["enemy_types"] = crg.selectEnemyTypes(theme, difficulty)
 	room.Properties["loot_chance"] = 0.3 + float64(difficulty)*0.02
 
+	monsters, err := crg.generateMonsters(theme, difficulty, enemyCount, rng.Int63())
+	if err != nil {
+		return nil, err
+	}
+	room.Properties["monsters"] = monsters
+
+	if crg.lootTables != nil && rng.Float64() < room.Properties["loot_chance"].(float64) {
+		loot, err := resolveRoomLoot(crg.lootTables, CombatLootTable, difficulty, rng)
+		if err != nil {
+			return nil, err
+		}
+		if loot != nil {
+			room.Properties["loot"] = loot
+		}
+	}
+
 	return room, nil
 }
 
@@ -120,26 +175,7 @@ func (crg *CombatRoomGenerator) generateDoorPositions(bounds pcg.Rectangle, rng
 }
 
 func (crg *CombatRoomGenerator) selectEnemyTypes(theme pcg.LevelTheme, difficulty int) []string {
-	var enemies []string
-
-	switch theme {
-	case pcg.ThemeClassic:
-		enemies = []string{"goblin", "orc", "skeleton"}
-	case pcg.ThemeHorror:
-		enemies = []string{"zombie", "wraith", "shadow"}
-	case pcg.ThemeNatural:
-		enemies = []string{"wolf", "bear", "spider"}
-	case pcg.ThemeMechanical:
-		enemies = []string{"construct", "golem", "automaton"}
-	case pcg.ThemeMagical:
-		enemies = []string{"elemental", "sprite", "wisp"}
-	case pcg.ThemeUndead:
-		enemies = []string{"skeleton", "zombie", "lich"}
-	case pcg.ThemeElemental:
-		enemies = []string{"fire_elemental", "water_elemental", "earth_elemental"}
-	default:
-		enemies = []string{"goblin", "orc", "bandit"}
-	}
+	enemies := themeEnemyTypes(theme)
 
 	// Scale with difficulty
 	if difficulty > 10 {
@@ -149,10 +185,45 @@ func (crg *CombatRoomGenerator) selectEnemyTypes(theme pcg.LevelTheme, difficult
 	return enemies
 }
 
+// generateMonsters creates the stat blocks of a room's enemies from the
+// theme's enemy types. Stronger enemies come from scaling the monsters to
+// difficulty rather than from the "elite_" enemy types.
+func (crg *CombatRoomGenerator) generateMonsters(theme pcg.LevelTheme, difficulty, count int, seed int64) ([]*pcg.MonsterStatBlock, error) {
+	generator := crg.monsters
+	if generator == nil {
+		generator = pcg.NewMonsterGenerator(nil)
+	}
+
+	monsters, err := generator.GenerateMonsters(context.Background(), pcg.MonsterParams{
+		GenerationParams: pcg.GenerationParams{Seed: seed, Difficulty: difficulty},
+		Biome:            pcg.BiomeDungeon,
+		Count:            count,
+		Kinds:            themeEnemyTypes(theme),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate monsters: %w", err)
+	}
+	return monsters, nil
+}
+
+// themeEnemyTypes returns the enemy types of theme, or generic enemies for
+// unregistered themes
+func themeEnemyTypes(theme pcg.LevelTheme) []string {
+	if def, exists := pcg.Themes().Get(theme); exists {
+		return append([]string(nil), def.EnemyTypes...)
+	}
+	return []string{"goblin", "orc", "bandit"}
+}
+
 // TreasureRoomGenerator creates treasure and loot rooms with valuable contents.
 // Generated rooms feature ornate decorations, treasure containers with rarity
 // scaled by difficulty, and optional guardians for high-value rooms.
-type TreasureRoomGenerator struct{}
+//
+// When loot tables are configured, each chest also holds items rolled from
+// the "treasure" table in its "items" property.
+type TreasureRoomGenerator struct {
+	lootTables *items.LootTableRegistry
+}
 
 // GenerateRoom creates a treasure room with valuable contents scaled by difficulty.
 // Higher difficulty rooms may have locked/trapped chests, rare loot, and guardian
@@ -206,6 +277,14 @@ func (trg *TreasureRoomGenerator) GenerateRoom(bounds pcg.Rectangle, theme pcg.L
 				"contents": trg.generateTreasureContents(difficulty, rng),
 			},
 		}
+		loot, err := resolveRoomLoot(trg.lootTables, TreasureLootTable, difficulty, rng)
+		if err != nil {
+			return nil, err
+		}
+		if loot != nil {
+			treasure.Properties["items"] = loot
+		}
+
 		room.Features = append(room.Features, treasure)
 	}
 
@@ -325,17 +404,9 @@ func (prg *PuzzleRoomGenerator) GenerateRoom(bounds pcg.Rectangle, theme pcg.Lev
 }
 
 func (prg *PuzzleRoomGenerator) selectPuzzleType(theme pcg.LevelTheme, difficulty int, rng *rand.Rand) string {
-	var puzzles []string
-
-	switch theme {
-	case pcg.ThemeClassic:
-		puzzles = []string{"lever_sequence", "pressure_plates", "riddle"}
-	case pcg.ThemeMechanical:
-		puzzles = []string{"gear_puzzle", "circuit_puzzle", "weight_balance"}
-	case pcg.ThemeMagical:
-		puzzles = []string{"rune_sequence", "elemental_matching", "spell_focus"}
-	default:
-		puzzles = []string{"lever_sequence", "pressure_plates", "riddle"}
+	puzzles := []string{"lever_sequence", "pressure_plates", "riddle"}
+	if def, exists := pcg.Themes().Get(theme); exists && len(def.PuzzleTypes) > 0 {
+		puzzles = def.PuzzleTypes
 	}
 
 	return puzzles[rng.Intn(len(puzzles))]
@@ -473,37 +544,17 @@ func (brg *BossRoomGenerator) GenerateRoom(bounds pcg.Rectangle, theme pcg.Level
 }
 
 func (brg *BossRoomGenerator) selectBossType(theme pcg.LevelTheme, difficulty int) string {
-	switch theme {
-	case pcg.ThemeClassic:
-		return "dragon"
-	case pcg.ThemeHorror:
-		return "abomination"
-	case pcg.ThemeUndead:
-		return "lich"
-	case pcg.ThemeMechanical:
-		return "war_machine"
-	case pcg.ThemeMagical:
-		return "archmage"
-	case pcg.ThemeElemental:
-		return "elemental_lord"
-	default:
-		return "champion"
+	if def, exists := pcg.Themes().Get(theme); exists && def.BossType != "" {
+		return def.BossType
 	}
+	return "champion"
 }
 
 func (brg *BossRoomGenerator) selectHazardType(theme pcg.LevelTheme) string {
-	switch theme {
-	case pcg.ThemeClassic:
-		return "falling_rocks"
-	case pcg.ThemeHorror:
-		return "blood_pools"
-	case pcg.ThemeMagical:
-		return "magic_storm"
-	case pcg.ThemeElemental:
-		return "elemental_eruption"
-	default:
-		return "debris"
+	if def, exists := pcg.Themes().Get(theme); exists && def.HazardType != "" {
+		return def.HazardType
 	}
+	return "debris"
 }
 
 func (brg *BossRoomGenerator) generateEscapeRoutes(bounds pcg.Rectangle) []game.Position {
@@ -563,12 +614,16 @@ type ShopRoomGenerator struct{}
 
 // GenerateRoom creates a safe shop room with a merchant NPC.
 // Default buy prices are at 100% and sell prices at 50% of item value.
+// The merchant's faction and stock seed are stored in the room properties
+// and end up in the level's "shops" property, see pcg.ShopSite.
 func (srg *ShopRoomGenerator) GenerateRoom(bounds pcg.Rectangle, theme pcg.LevelTheme, difficulty int, genCtx *pcg.GenerationContext) (*pcg.RoomLayout, error) {
 	return generateBasicRoom(bounds, "shop", map[string]interface{}{
-		"merchant":    true,
-		"safe_zone":   true,
-		"buy_prices":  1.0,
-		"sell_prices": 0.5,
+		"merchant":         true,
+		"safe_zone":        true,
+		"buy_prices":       1.0,
+		"sell_prices":      0.5,
+		"merchant_faction": ShopMerchantFaction,
+		"merchant_seed":    genCtx.RNG.Int63(),
 	})
 }
 
@@ -588,16 +643,31 @@ func (rrg *RestRoomGenerator) GenerateRoom(bounds pcg.Rectangle, theme pcg.Level
 
 // TrapRoomGenerator creates dangerous trap-filled rooms requiring careful navigation.
 // Generated rooms contain hidden traps with density scaling by difficulty level.
-type TrapRoomGenerator struct{}
+type TrapRoomGenerator struct {
+	traps TrapGenerator
+}
 
 // GenerateRoom creates a dangerous room filled with hidden traps.
 // Trap density scales with difficulty, making higher-level rooms more hazardous.
+// Each trap is placed as a "trap" feature, and the game.Trap values are
+// stored in the "traps" property.
 func (trg *TrapRoomGenerator) GenerateRoom(bounds pcg.Rectangle, theme pcg.LevelTheme, difficulty int, genCtx *pcg.GenerationContext) (*pcg.RoomLayout, error) {
-	return generateBasicRoom(bounds, "trap", map[string]interface{}{
+	room, err := generateBasicRoom(bounds, "trap", map[string]interface{}{
 		"trap_density": difficulty,
 		"hidden_traps": true,
 		"danger_level": "high",
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	traps := trg.traps.GenerateTraps(bounds, theme, difficulty, genCtx.RNG)
+	for _, trap := range traps {
+		room.Features = append(room.Features, trapFeature(trap))
+	}
+	room.Properties["traps"] = traps
+
+	return room, nil
 }
 
 // StoryRoomGenerator creates narrative-focused rooms with lore and story elements.
@@ -0,0 +1,182 @@
+package levels
+
+import (
+	"fmt"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// tiledLevelImage is the default tileset image of exported levels, with
+// one tile per tile type in game.TileType order
+const tiledLevelImage = "tiles.png"
+
+// tiledTileTypes names each game.TileType in the exported tileset
+var tiledTileTypes = []string{
+	game.TileFloor:  "floor",
+	game.TileWall:   "wall",
+	game.TileDoor:   "door",
+	game.TileWater:  "water",
+	game.TileLava:   "lava",
+	game.TilePit:    "pit",
+	game.TileStairs: "stairs",
+}
+
+// ExportTiled converts a level to the JSON map format of the Tiled map
+// editor. The "tiles" layer holds one tileset tile per tile type, with the
+// walkable and transparent flags as tile properties. Object groups list
+// the level's doors and stairs and, of objects, the traps ("traps"),
+// creatures ("spawns") and items ("items") standing on levelIndex. Shop
+// rooms recorded by the generator are added to "spawns" as merchants, and
+// map annotations the caller puts in the level's "annotations" property
+// ([]game.MapAnnotation) make up the "annotations" group.
+//
+// Parameters:
+//   - level: Level to export
+//   - levelIndex: Index of the level in the world, matching object positions
+//   - objects: World objects; those on other levels are skipped
+//   - opts: Tile size and tileset image; zero values take defaults
+//
+// Returns:
+//   - *pcg.TiledMap: Map ready to be written as JSON
+//   - error: The level is empty or its rows do not match its size
+func ExportTiled(level *game.Level, levelIndex int, objects []game.GameObject, opts pcg.TiledOptions) (*pcg.TiledMap, error) {
+	if level == nil {
+		return nil, fmt.Errorf("no level to export")
+	}
+	if len(level.Tiles) != level.Height {
+		return nil, fmt.Errorf("level %s has %d rows, expected %d", level.ID, len(level.Tiles), level.Height)
+	}
+	for y, row := range level.Tiles {
+		if len(row) != level.Width {
+			return nil, fmt.Errorf("level %s row %d has %d tiles, expected %d", level.ID, y, len(row), level.Width)
+		}
+	}
+
+	export, err := pcg.NewTiledExport(level.Width, level.Height, opts, tiledLevelImage, len(tiledTileTypes))
+	if err != nil {
+		return nil, err
+	}
+
+	tiles := make([]pcg.TiledTile, len(tiledTileTypes))
+	for tileType, name := range tiledTileTypes {
+		tiles[tileType] = pcg.TiledTile{ID: tileType, Type: name}
+	}
+	firstGID := export.AddTileset("goldbox_tiles", len(tiles), tiles)
+
+	var doors, stairs []pcg.TiledObject
+	export.AddTileLayer("tiles", func(x, y int) int {
+		tile := level.Tiles[y][x]
+		switch tile.Type {
+		case game.TileDoor:
+			doors = append(doors, export.TileObject(fmt.Sprintf("door_%d_%d", x, y), "door", x, y, tileObjectProperties(tile)))
+		case game.TileStairs:
+			stairs = append(stairs, export.TileObject(fmt.Sprintf("stairs_%d_%d", x, y), "stairs", x, y, tileObjectProperties(tile)))
+		}
+		if int(tile.Type) < 0 || int(tile.Type) >= len(tiledTileTypes) {
+			return 0
+		}
+		return firstGID + int(tile.Type)
+	})
+	export.AddObjectGroup("doors", doors)
+	export.AddObjectGroup("stairs", stairs)
+
+	traps, spawns, items := levelObjects(export, levelIndex, objects)
+	if shops, ok := level.Properties["shops"].([]pcg.ShopSite); ok {
+		for _, shop := range shops {
+			spawns = append(spawns, export.TileObject(shop.RoomID+"_merchant", "merchant", shop.Position.X, shop.Position.Y,
+				map[string]interface{}{"room_id": shop.RoomID, "faction": shop.Faction, "difficulty": shop.Difficulty}))
+		}
+	}
+	export.AddObjectGroup("traps", traps)
+	export.AddObjectGroup("spawns", spawns)
+	export.AddObjectGroup("items", items)
+	if annotations, ok := level.Properties["annotations"].([]game.MapAnnotation); ok {
+		export.AddObjectGroup("annotations", annotationObjects(export, annotations))
+	}
+
+	export.Map.Properties = pcg.TiledProperties(map[string]interface{}{
+		"level_id":   level.ID,
+		"level_name": level.Name,
+	})
+	return export.Map, nil
+}
+
+// tileObjectProperties returns the properties of a door or stairs tile
+// worth keeping on its object
+func tileObjectProperties(tile game.Tile) map[string]interface{} {
+	properties := make(map[string]interface{}, len(tile.Properties)+1)
+	for key, value := range tile.Properties {
+		properties[key] = value
+	}
+	properties["walkable"] = tile.Walkable
+	return properties
+}
+
+// annotationObjects converts map annotations to Tiled objects. Regions
+// span their tiles; notes and markers cover one tile.
+func annotationObjects(export *pcg.TiledExport, annotations []game.MapAnnotation) []pcg.TiledObject {
+	objects := make([]pcg.TiledObject, 0, len(annotations))
+	for _, annotation := range annotations {
+		name := annotation.Text
+		if name == "" {
+			name = annotation.Symbol
+		}
+		obj := export.TileObject(name, string(annotation.Kind), annotation.X, annotation.Y, map[string]interface{}{
+			"annotation_id": annotation.ID,
+			"text":          annotation.Text,
+			"symbol":        annotation.Symbol,
+			"visibility":    string(annotation.Visibility),
+			"author":        annotation.Author,
+		})
+		if annotation.Kind == game.AnnotationRegion {
+			obj.Width = float64(annotation.Width * export.Options.TileWidth)
+			obj.Height = float64(annotation.Height * export.Options.TileHeight)
+		}
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+// levelObjects sorts the objects standing on levelIndex into traps,
+// creature spawns and items
+func levelObjects(export *pcg.TiledExport, levelIndex int, objects []game.GameObject) (traps, spawns, items []pcg.TiledObject) {
+	for _, obj := range objects {
+		pos := obj.GetPosition()
+		if pos.Level != levelIndex {
+			continue
+		}
+
+		switch o := obj.(type) {
+		case *game.Trap:
+			traps = append(traps, export.TileObject(o.GetName(), "trap", pos.X, pos.Y, map[string]interface{}{
+				"trap_id":      o.GetID(),
+				"trigger":      string(o.Trigger),
+				"detection_dc": o.DetectionDC,
+				"disarm_dc":    o.DisarmDC,
+				"damage":       o.Damage,
+				"armed":        o.IsArmed(),
+			}))
+		case *game.Player:
+			spawns = append(spawns, export.TileObject(o.GetName(), "player", pos.X, pos.Y, map[string]interface{}{"object_id": o.GetID()}))
+		case *game.NPC:
+			spawns = append(spawns, export.TileObject(o.GetName(), "npc", pos.X, pos.Y, map[string]interface{}{
+				"object_id": o.GetID(),
+				"faction":   o.Faction,
+			}))
+		case *game.Character:
+			spawns = append(spawns, export.TileObject(o.GetName(), "creature", pos.X, pos.Y, map[string]interface{}{"object_id": o.GetID()}))
+		case *game.Item:
+			items = append(items, export.TileObject(o.Name, "item", pos.X, pos.Y, map[string]interface{}{
+				"object_id": o.GetID(),
+				"item_type": o.Type,
+			}))
+		case *game.KeyObject:
+			items = append(items, export.TileObject(o.GetName(), "item", pos.X, pos.Y, map[string]interface{}{
+				"object_id": o.GetID(),
+				"item_type": o.Item.Type,
+			}))
+		}
+	}
+	return traps, spawns, items
+}
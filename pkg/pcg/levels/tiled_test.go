@@ -0,0 +1,125 @@
+package levels
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tiledLayer returns the layer of tiled named name, or nil
+func tiledLayer(tiled *pcg.TiledMap, name string) *pcg.TiledLayer {
+	for i := range tiled.Layers {
+		if tiled.Layers[i].Name == name {
+			return &tiled.Layers[i]
+		}
+	}
+	return nil
+}
+
+func TestExportTiled(t *testing.T) {
+	level := &game.Level{ID: "vault", Name: "Vault", Width: 3, Height: 2, Tiles: [][]game.Tile{
+		{game.NewWallTile(), {Type: game.TileDoor, Walkable: true, Properties: map[string]interface{}{"room_id": "room_1"}}, game.NewWallTile()},
+		{game.NewFloorTile(), game.NewFloorTile(), {Type: game.TileStairs, Walkable: true}},
+	}}
+	objects := []game.GameObject{
+		&game.Trap{ID: "trap_1", Name: "Spikes", Position: game.Position{X: 1, Y: 1}, Trigger: game.TrapTriggerPressurePlate, DisarmDC: 12, Armed: true},
+		&game.NPC{Character: game.Character{ID: "npc_1", Name: "Guard", Position: game.Position{X: 0, Y: 1}}, Faction: "watch"},
+		&game.Character{ID: "elsewhere", Position: game.Position{X: 0, Y: 0, Level: 1}},
+		&game.Item{ID: "item_1", Name: "Key", Type: "key", Position: game.Position{X: 0, Y: 1}},
+	}
+
+	tiled, err := ExportTiled(level, 0, objects, pcg.TiledOptions{})
+	require.NoError(t, err)
+
+	tiles := tiledLayer(tiled, "tiles")
+	require.NotNil(t, tiles)
+	assert.Equal(t, []int{2, 3, 2, 1, 1, 7}, tiles.Data, "GIDs follow tile types")
+	require.Len(t, tiled.Tilesets, 1)
+	assert.Len(t, tiled.Tilesets[0].Tiles, 7)
+	assert.Equal(t, "door", tiled.Tilesets[0].Tiles[game.TileDoor].Type)
+
+	doors := tiledLayer(tiled, "doors")
+	require.NotNil(t, doors)
+	require.Len(t, doors.Objects, 1)
+	assert.Contains(t, doors.Objects[0].Properties, pcg.TiledProperty{Name: "room_id", Type: "string", Value: "room_1"})
+	assert.NotNil(t, tiledLayer(tiled, "stairs"))
+
+	traps := tiledLayer(tiled, "traps")
+	require.NotNil(t, traps)
+	assert.Equal(t, "Spikes", traps.Objects[0].Name)
+	assert.Contains(t, traps.Objects[0].Properties, pcg.TiledProperty{Name: "disarm_dc", Type: "int", Value: 12})
+
+	spawns := tiledLayer(tiled, "spawns")
+	require.NotNil(t, spawns)
+	require.Len(t, spawns.Objects, 1, "objects on other levels are skipped")
+	assert.Equal(t, "npc", spawns.Objects[0].Type)
+	require.NotNil(t, tiledLayer(tiled, "items"))
+
+	data, err := json.Marshal(tiled)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "orthogonal", decoded["orientation"])
+
+	level.Height = 3
+	_, err = ExportTiled(level, 0, nil, pcg.TiledOptions{})
+	assert.ErrorContains(t, err, "has 2 rows")
+}
+
+func TestExportTiled_GeneratedLevel(t *testing.T) {
+	levelParams := pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 2468, Difficulty: 4},
+		MinRooms:         4,
+		MaxRooms:         4,
+		RoomTypes:        []pcg.RoomType{pcg.RoomTypeShop},
+		LevelTheme:       pcg.ThemeClassic,
+	}
+	level, err := NewRoomCorridorGeneratorWithSeed(1).GenerateLevel(context.Background(), levelParams)
+	require.NoError(t, err)
+
+	tiled, err := ExportTiled(level, 0, nil, pcg.TiledOptions{TilesetImage: "dungeon.png"})
+	require.NoError(t, err)
+	assert.Len(t, tiledLayer(tiled, "tiles").Data, level.Width*level.Height)
+	assert.Equal(t, "dungeon.png", tiled.Tilesets[0].Image)
+
+	spawns := tiledLayer(tiled, "spawns")
+	require.NotNil(t, spawns, "shop rooms place merchants")
+	assert.Equal(t, "merchant", spawns.Objects[0].Type)
+}
+
+func TestExportTiled_Annotations(t *testing.T) {
+	level := &game.Level{ID: "hall", Width: 4, Height: 4, Tiles: make([][]game.Tile, 4)}
+	for y := range level.Tiles {
+		level.Tiles[y] = []game.Tile{game.NewFloorTile(), game.NewFloorTile(), game.NewFloorTile(), game.NewFloorTile()}
+	}
+	level.Properties = map[string]interface{}{"annotations": []game.MapAnnotation{
+		{ID: "a1", Kind: game.AnnotationRegion, X: 1, Y: 1, Width: 2, Height: 3, Text: "Throne room", Visibility: game.AnnotationPlayers},
+		{ID: "a2", Kind: game.AnnotationMarker, X: 0, Y: 3, Symbol: "skull", Visibility: game.AnnotationGMOnly},
+	}}
+
+	tiled, err := ExportTiled(level, 0, nil, pcg.TiledOptions{TileWidth: 16, TileHeight: 16})
+	require.NoError(t, err)
+
+	annotations := tiledLayer(tiled, "annotations")
+	require.NotNil(t, annotations)
+	require.Len(t, annotations.Objects, 2)
+	region := annotations.Objects[0]
+	assert.Equal(t, "Throne room", region.Name)
+	assert.Equal(t, "region", region.Type)
+	assert.Equal(t, 32.0, region.Width, "regions span their tiles")
+	assert.Equal(t, 48.0, region.Height)
+	marker := annotations.Objects[1]
+	assert.Equal(t, "skull", marker.Name, "markers without text are named by their symbol")
+	assert.Contains(t, marker.Properties, pcg.TiledProperty{Name: "visibility", Type: "string", Value: "gm"})
+
+	level.Properties = nil
+	tiled, err = ExportTiled(level, 0, nil, pcg.TiledOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, tiledLayer(tiled, "annotations"))
+}
@@ -0,0 +1,157 @@
+package levels
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// trapTemplate describes one kind of trap the TrapGenerator can place.
+// Damage dice count grows with difficulty; the die size is fixed.
+type trapTemplate struct {
+	name          string
+	trigger       game.TrapTrigger
+	triggerRadius int
+	damageDie     int
+	damageType    game.DamageType
+	effect        game.EffectType
+	effectRounds  int
+	themes        []pcg.LevelTheme // Themes that favour this trap
+}
+
+// trapTemplates lists the traps placed in trap rooms, in selection order
+var trapTemplates = []trapTemplate{
+	{name: "Spiked Pit", trigger: game.TrapTriggerPressurePlate, damageDie: 6, damageType: game.DamagePhysical,
+		themes: []pcg.LevelTheme{pcg.ThemeClassic, pcg.ThemeNatural}},
+	{name: "Poison Needle", trigger: game.TrapTriggerTouch, damageDie: 4, damageType: game.DamagePoison,
+		effect: game.EffectPoison, effectRounds: 3, themes: []pcg.LevelTheme{pcg.ThemeClassic, pcg.ThemeHorror}},
+	{name: "Scything Blade", trigger: game.TrapTriggerTripwire, damageDie: 8, damageType: game.DamagePhysical,
+		effect: game.EffectBleeding, effectRounds: 2, themes: []pcg.LevelTheme{pcg.ThemeMechanical, pcg.ThemeHorror}},
+	{name: "Fire Jet", trigger: game.TrapTriggerPressurePlate, damageDie: 6, damageType: game.DamageFire,
+		effect: game.EffectBurning, effectRounds: 2, themes: []pcg.LevelTheme{pcg.ThemeElemental, pcg.ThemeMechanical}},
+	{name: "Snare", trigger: game.TrapTriggerTripwire, damageDie: 0,
+		effect: game.EffectRoot, effectRounds: 2, themes: []pcg.LevelTheme{pcg.ThemeNatural}},
+	{name: "Lightning Glyph", trigger: game.TrapTriggerProximity, triggerRadius: 1, damageDie: 6, damageType: game.DamageLightning,
+		effect: game.EffectStun, effectRounds: 1, themes: []pcg.LevelTheme{pcg.ThemeMagical, pcg.ThemeElemental}},
+	{name: "Frost Rune", trigger: game.TrapTriggerProximity, triggerRadius: 1, damageDie: 6, damageType: game.DamageFrost,
+		themes: []pcg.LevelTheme{pcg.ThemeMagical, pcg.ThemeUndead}},
+}
+
+// themedTrapWeight is the selection weight of a trap favoured by the
+// room's theme; other traps have weight 1
+const themedTrapWeight = 3
+
+// TrapGenerator creates the traps placed in trap rooms. Trap count, damage
+// and detection and disarm DCs scale with difficulty, and the theme favours
+// fitting trap kinds. All choices come from the supplied RNG, so the same
+// seed always yields the same traps.
+type TrapGenerator struct{}
+
+// GenerateTraps creates traps on distinct floor tiles inside bounds. The
+// outer ring of bounds is treated as wall.
+func (tg *TrapGenerator) GenerateTraps(bounds pcg.Rectangle, theme pcg.LevelTheme, difficulty int, rng *rand.Rand) []*game.Trap {
+	innerWidth, innerHeight := bounds.Width-2, bounds.Height-2
+	if innerWidth < 1 || innerHeight < 1 {
+		return nil
+	}
+
+	count := min(1+difficulty/3+rng.Intn(2), innerWidth*innerHeight)
+	occupied := make(map[game.Position]bool, count)
+	traps := make([]*game.Trap, 0, count)
+
+	for len(traps) < count {
+		pos := game.Position{
+			X: bounds.X + 1 + rng.Intn(innerWidth),
+			Y: bounds.Y + 1 + rng.Intn(innerHeight),
+		}
+		if occupied[pos] {
+			continue
+		}
+		occupied[pos] = true
+		traps = append(traps, tg.generateTrap(pos, theme, difficulty, rng))
+	}
+
+	return traps
+}
+
+// generateTrap builds a single trap at pos
+func (tg *TrapGenerator) generateTrap(pos game.Position, theme pcg.LevelTheme, difficulty int, rng *rand.Rand) *game.Trap {
+	template := tg.selectTemplate(theme, rng)
+
+	trap := &game.Trap{
+		ID:            fmt.Sprintf("trap_%d_%d", pos.X, pos.Y),
+		Name:          template.name,
+		Description:   fmt.Sprintf("A hidden %s", template.name),
+		Position:      pos,
+		Trigger:       template.trigger,
+		TriggerRadius: template.triggerRadius,
+		DetectionDC:   10 + difficulty/2 + rng.Intn(4),
+		DisarmDC:      12 + difficulty/2 + rng.Intn(4),
+		DamageType:    template.damageType,
+		Armed:         true,
+	}
+
+	if template.damageDie > 0 {
+		trap.Damage = fmt.Sprintf("%dd%d", 1+difficulty/4, template.damageDie)
+	}
+	if template.effect != "" {
+		trap.Effect = template.effect
+		trap.EffectRounds = template.effectRounds + difficulty/5
+		trap.EffectMagnitude = float64(1 + difficulty/4)
+	}
+
+	return trap
+}
+
+// selectTemplate picks a trap kind, favouring those that suit the theme
+func (tg *TrapGenerator) selectTemplate(theme pcg.LevelTheme, rng *rand.Rand) trapTemplate {
+	total := 0
+	weights := make([]int, len(trapTemplates))
+	for i, template := range trapTemplates {
+		weights[i] = 1
+		if slices.Contains(template.themes, theme) {
+			weights[i] = themedTrapWeight
+		}
+		total += weights[i]
+	}
+
+	roll := rng.Intn(total)
+	for i, weight := range weights {
+		if roll < weight {
+			return trapTemplates[i]
+		}
+		roll -= weight
+	}
+	return trapTemplates[0]
+}
+
+// trapFeature describes a trap as a room feature
+func trapFeature(trap *game.Trap) pcg.RoomFeature {
+	properties := map[string]interface{}{
+		"trap_id":      trap.ID,
+		"name":         trap.Name,
+		"trigger":      string(trap.Trigger),
+		"detection_dc": trap.DetectionDC,
+		"disarm_dc":    trap.DisarmDC,
+		"hidden":       true,
+	}
+	if trap.Damage != "" {
+		properties["damage"] = trap.Damage
+		properties["damage_type"] = string(trap.DamageType)
+	}
+	if trap.Effect != "" {
+		properties["effect"] = string(trap.Effect)
+	}
+	if trap.TriggerRadius > 0 {
+		properties["trigger_radius"] = trap.TriggerRadius
+	}
+
+	return pcg.RoomFeature{
+		Type:       "trap",
+		Position:   trap.Position,
+		Properties: properties,
+	}
+}
@@ -0,0 +1,95 @@
+package levels
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+func TestTrapGenerator_GenerateTraps(t *testing.T) {
+	var tg TrapGenerator
+	bounds := pcg.Rectangle{X: 10, Y: 20, Width: 8, Height: 6}
+
+	traps := tg.GenerateTraps(bounds, pcg.ThemeMagical, 9, rand.New(rand.NewSource(42)))
+	if len(traps) < 4 {
+		t.Fatalf("expected at least 4 traps at difficulty 9, got %d", len(traps))
+	}
+
+	seen := make(map[game.Position]bool)
+	for _, trap := range traps {
+		if err := trap.Validate(); err != nil {
+			t.Errorf("generated invalid trap: %v", err)
+		}
+		pos := trap.Position
+		if pos.X <= bounds.X || pos.X >= bounds.X+bounds.Width-1 || pos.Y <= bounds.Y || pos.Y >= bounds.Y+bounds.Height-1 {
+			t.Errorf("trap %s at %+v is outside the room floor", trap.ID, pos)
+		}
+		if seen[pos] {
+			t.Errorf("two traps share position %+v", pos)
+		}
+		seen[pos] = true
+		if trap.DetectionDC < 14 || trap.DisarmDC < 16 {
+			t.Errorf("trap %s DCs %d/%d do not scale with difficulty", trap.ID, trap.DetectionDC, trap.DisarmDC)
+		}
+	}
+
+	again := tg.GenerateTraps(bounds, pcg.ThemeMagical, 9, rand.New(rand.NewSource(42)))
+	if !reflect.DeepEqual(trapSummaries(traps), trapSummaries(again)) {
+		t.Error("same seed should produce the same traps")
+	}
+
+	if traps := tg.GenerateTraps(pcg.Rectangle{Width: 2, Height: 2}, pcg.ThemeClassic, 5, rand.New(rand.NewSource(1))); traps != nil {
+		t.Errorf("room without floor should get no traps, got %d", len(traps))
+	}
+}
+
+func TestTrapRoomGenerator_PopulatesTraps(t *testing.T) {
+	generator := NewRoomCorridorGenerator()
+	bounds := pcg.Rectangle{X: 0, Y: 0, Width: 10, Height: 8}
+	levelParams := pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 777, Difficulty: 6},
+		LevelTheme:       pcg.ThemeClassic,
+	}
+
+	room, err := generator.GenerateRoom(context.Background(), bounds, pcg.RoomTypeTrap, levelParams)
+	if err != nil {
+		t.Fatalf("GenerateRoom failed: %v", err)
+	}
+
+	traps, ok := room.Properties["traps"].([]*game.Trap)
+	if !ok || len(traps) == 0 {
+		t.Fatalf("expected traps in room properties, got %v", room.Properties["traps"])
+	}
+
+	trapFeatures := 0
+	for _, feature := range room.Features {
+		if feature.Type == "trap" {
+			trapFeatures++
+		}
+	}
+	if trapFeatures != len(traps) {
+		t.Errorf("expected %d trap features, got %d", len(traps), trapFeatures)
+	}
+
+	again, err := NewRoomCorridorGenerator().GenerateRoom(context.Background(), bounds, pcg.RoomTypeTrap, levelParams)
+	if err != nil {
+		t.Fatalf("GenerateRoom failed: %v", err)
+	}
+	if !reflect.DeepEqual(trapSummaries(traps), trapSummaries(again.Properties["traps"].([]*game.Trap))) {
+		t.Error("same seed should populate the room with the same traps")
+	}
+}
+
+// trapSummaries flattens traps into comparable strings
+func trapSummaries(traps []*game.Trap) []string {
+	summaries := make([]string, len(traps))
+	for i, trap := range traps {
+		data, _ := trap.ToJSON()
+		summaries[i] = string(data)
+	}
+	return summaries
+}
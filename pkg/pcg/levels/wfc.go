@@ -0,0 +1,749 @@
+package levels
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// WFC pattern characters and the tiles they become
+const (
+	wfcWall  = '#'
+	wfcFloor = '.'
+	wfcDoor  = '+'
+	wfcWater = '~'
+)
+
+// Directions used by the WFC adjacency tables, in clockwise order
+const (
+	wfcNorth = iota
+	wfcEast
+	wfcSouth
+	wfcWest
+)
+
+// wfcOffsets holds the cell offset for each direction
+var wfcOffsets = [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// defaultWFCRuleset is used when the generation constraints name no ruleset
+const defaultWFCRuleset = "castle"
+
+// defaultWFCRestarts is how many times generation restarts after a
+// contradiction before giving up
+const defaultWFCRestarts = 20
+
+// WFCTileDefinition describes one tile of a wave function collapse ruleset.
+// The pattern is a square block of level tiles: '#' wall, '.' floor,
+// '+' door and '~' water. Two tiles may sit side by side when their touching
+// edges agree on which tiles are walkable, so the patterns themselves are the
+// adjacency rules.
+type WFCTileDefinition struct {
+	Name      string   `yaml:"name"`
+	Pattern   []string `yaml:"pattern"`
+	Weight    float64  `yaml:"weight"`              // Relative frequency; defaults to 1
+	Rotations int      `yaml:"rotations,omitempty"` // 1, 2 or 4 orientations; defaults to 1
+}
+
+// WFCRuleset is a named set of tiles used to assemble an interior
+type WFCRuleset struct {
+	Name  string              `yaml:"name,omitempty"`
+	Tiles []WFCTileDefinition `yaml:"tiles"`
+}
+
+// WFCRulesetCollection represents the root structure of a WFC ruleset YAML file
+type WFCRulesetCollection struct {
+	Rulesets map[string]*WFCRuleset `yaml:"rulesets"`
+}
+
+// builtinWFCRulesets holds the rulesets available without a ruleset file.
+// Castles favour long corridors between compact rooms; temples favour wide
+// pillared halls and pools.
+var builtinWFCRulesets = map[string]*WFCRuleset{
+	"castle": {
+		Name: "castle",
+		Tiles: []WFCTileDefinition{
+			{Name: "solid", Weight: 6, Pattern: []string{"###", "###", "###"}},
+			{Name: "corridor", Weight: 4, Rotations: 2, Pattern: []string{"#.#", "#.#", "#.#"}},
+			{Name: "corridor_bend", Weight: 2, Rotations: 4, Pattern: []string{"###", "#..", "#.#"}},
+			{Name: "corridor_tee", Weight: 1, Rotations: 4, Pattern: []string{"###", "...", "#.#"}},
+			{Name: "corridor_cross", Weight: 0.5, Pattern: []string{"#.#", "...", "#.#"}},
+			{Name: "room_floor", Weight: 3, Pattern: []string{"...", "...", "..."}},
+			{Name: "room_wall", Weight: 2, Rotations: 4, Pattern: []string{"###", "...", "..."}},
+			{Name: "room_corner", Weight: 1, Rotations: 4, Pattern: []string{"###", "#..", "#.."}},
+			{Name: "room_door", Weight: 1, Rotations: 4, Pattern: []string{"#+#", "...", "..."}},
+		},
+	},
+	"temple": {
+		Name: "temple",
+		Tiles: []WFCTileDefinition{
+			{Name: "solid", Weight: 4, Pattern: []string{"###", "###", "###"}},
+			{Name: "corridor", Weight: 1, Rotations: 2, Pattern: []string{"#.#", "#.#", "#.#"}},
+			{Name: "corridor_bend", Weight: 0.5, Rotations: 4, Pattern: []string{"###", "#..", "#.#"}},
+			{Name: "hall_floor", Weight: 5, Pattern: []string{"...", "...", "..."}},
+			{Name: "hall_pillar", Weight: 2, Pattern: []string{"...", ".#.", "..."}},
+			{Name: "hall_pool", Weight: 0.5, Pattern: []string{"...", ".~.", "..."}},
+			{Name: "hall_wall", Weight: 2, Rotations: 4, Pattern: []string{"###", "...", "..."}},
+			{Name: "hall_corner", Weight: 1, Rotations: 4, Pattern: []string{"###", "#..", "#.."}},
+			{Name: "hall_door", Weight: 0.5, Rotations: 4, Pattern: []string{"#+#", "...", "..."}},
+		},
+	},
+}
+
+// wfcVariant is one orientation of a ruleset tile
+type wfcVariant struct {
+	name    string
+	pattern [][]byte
+	weight  float64
+	edges   [4]string
+}
+
+// compiledWFCRuleset holds the expanded tile variants and, for each variant
+// and direction, which variants may sit next to it
+type compiledWFCRuleset struct {
+	name     string
+	size     int
+	variants []wfcVariant
+	compat   [4][][]bool
+	borderOK [4][]bool
+}
+
+// WFCGenerator assembles structured interiors such as castles and temples
+// using wave function collapse. Each cell of the output is one tile of the
+// chosen ruleset; cells are collapsed lowest-entropy first and constraints
+// are propagated to their neighbours. A contradiction restarts generation
+// with a fresh random stream derived from the seed, so the same seed always
+// produces the same layout.
+//
+// WFCGenerator is not safe for concurrent use while rulesets are being
+// loaded; generation itself does not modify the generator.
+type WFCGenerator struct {
+	version     string
+	rulesets    map[string]*WFCRuleset
+	maxRestarts int
+}
+
+// NewWFCGenerator creates a wave function collapse level generator holding
+// the built-in rulesets
+func NewWFCGenerator() *WFCGenerator {
+	rulesets := make(map[string]*WFCRuleset, len(builtinWFCRulesets))
+	for name, ruleset := range builtinWFCRulesets {
+		rulesets[name] = ruleset
+	}
+	return &WFCGenerator{
+		version:     "1.0.0",
+		rulesets:    rulesets,
+		maxRestarts: defaultWFCRestarts,
+	}
+}
+
+// LoadRulesets loads rulesets from a YAML file and merges them with the
+// rulesets already held, replacing any of the same name. Nothing is merged
+// unless every ruleset in the file compiles.
+func (wg *WFCGenerator) LoadRulesets(path string) error {
+	rulesets, err := LoadWFCRulesets(path)
+	if err != nil {
+		return err
+	}
+	for name, ruleset := range rulesets {
+		wg.rulesets[name] = ruleset
+	}
+	return nil
+}
+
+// Rulesets returns the names of the available rulesets in sorted order
+func (wg *WFCGenerator) Rulesets() []string {
+	names := make([]string, 0, len(wg.rulesets))
+	for name := range wg.rulesets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadWFCRulesets reads and compiles the rulesets in a YAML file
+func LoadWFCRulesets(path string) (map[string]*WFCRuleset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WFC ruleset file %s: %w", path, err)
+	}
+
+	var collection WFCRulesetCollection
+	if err := yaml.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML from %s: %w", path, err)
+	}
+
+	rulesets := make(map[string]*WFCRuleset, len(collection.Rulesets))
+	for name, ruleset := range collection.Rulesets {
+		if ruleset == nil {
+			continue
+		}
+		ruleset.Name = name
+		if _, err := compileWFCRuleset(ruleset); err != nil {
+			return nil, fmt.Errorf("invalid WFC ruleset %s in %s: %w", name, path, err)
+		}
+		rulesets[name] = ruleset
+	}
+	return rulesets, nil
+}
+
+// GetType returns the content type this generator produces
+func (wg *WFCGenerator) GetType() pcg.ContentType {
+	return pcg.ContentTypeLevels
+}
+
+// GetVersion returns the generator version for compatibility checking
+func (wg *WFCGenerator) GetVersion() string {
+	return wg.version
+}
+
+// Validate checks if the provided parameters are valid for this generator
+func (wg *WFCGenerator) Validate(params pcg.GenerationParams) error {
+	if _, ok := params.Constraints["level_params"].(pcg.LevelParams); !ok {
+		return fmt.Errorf("invalid level parameters provided")
+	}
+
+	if params.Difficulty < 1 || params.Difficulty > 20 {
+		return fmt.Errorf("difficulty must be between 1 and 20")
+	}
+
+	name := wfcRulesetName(params.Constraints)
+	ruleset, exists := wg.rulesets[name]
+	if !exists {
+		return fmt.Errorf("unknown WFC ruleset: %s", name)
+	}
+
+	compiled, err := compileWFCRuleset(ruleset)
+	if err != nil {
+		return fmt.Errorf("invalid WFC ruleset %s: %w", name, err)
+	}
+
+	width, height := wfcDimensions(params.Constraints)
+	if width < 3*compiled.size || height < 3*compiled.size {
+		return fmt.Errorf("level must be at least %dx%d tiles for ruleset %s", 3*compiled.size, 3*compiled.size, name)
+	}
+
+	return nil
+}
+
+// Generate implements the Generator interface
+func (wg *WFCGenerator) Generate(ctx context.Context, params pcg.GenerationParams) (interface{}, error) {
+	levelParams, ok := params.Constraints["level_params"].(pcg.LevelParams)
+	if !ok {
+		return nil, fmt.Errorf("invalid level parameters provided")
+	}
+
+	// Ruleset and dimensions are read from the level constraints, so carry
+	// the outer ones across when the level params have none of their own
+	if levelParams.Constraints == nil {
+		levelParams.Constraints = params.Constraints
+	}
+
+	// Progress is a runtime callback, so it is only set on the outer params
+	if levelParams.Progress == nil {
+		levelParams.Progress = params.Progress
+	}
+
+	return wg.GenerateLevel(ctx, levelParams)
+}
+
+// GenerateLevel assembles an interior from the ruleset named by the
+// "wfc_ruleset" constraint, sized by the "width" and "height" constraints.
+// Walkable pockets cut off from the largest area are filled in, so every
+// walkable tile of the result is reachable.
+func (wg *WFCGenerator) GenerateLevel(ctx context.Context, params pcg.LevelParams) (*game.Level, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("level generation cancelled before start: %w", err)
+	}
+
+	name := wfcRulesetName(params.Constraints)
+	ruleset, exists := wg.rulesets[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown WFC ruleset: %s", name)
+	}
+	compiled, err := compileWFCRuleset(ruleset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WFC ruleset %s: %w", name, err)
+	}
+
+	width, height := wfcDimensions(params.Constraints)
+	cols, rows := width/compiled.size, height/compiled.size
+	if cols < 3 || rows < 3 {
+		return nil, fmt.Errorf("level must be at least %dx%d tiles for ruleset %s", 3*compiled.size, 3*compiled.size, name)
+	}
+
+	seedMgr := pcg.NewSeedManager(params.Seed)
+	genCtx := pcg.NewGenerationContext(seedMgr, pcg.ContentTypeLevels, "wfc_"+name, params.GenerationParams)
+
+	var cells []int
+	restarts := 0
+	for attempt := 0; attempt <= wg.maxRestarts; attempt++ {
+		rng := genCtx.GetSubRNG(fmt.Sprintf("attempt_%d", attempt))
+		cells, err = compiled.solve(ctx, cols, rows, rng)
+		if err == nil {
+			break
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("level generation cancelled during collapse: %w", ctxErr)
+		}
+		restarts++
+		logger.WithFields(logrus.Fields{
+			"ruleset": name,
+			"attempt": attempt,
+		}).Debug("wave function collapse hit a contradiction, restarting")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("wave function collapse failed after %d attempts: %w", restarts, err)
+	}
+	params.ReportProgress(pcg.ContentTypeLevels, "collapse", 70)
+
+	level := compiled.render(cells, cols, rows)
+	if !fillUnreachable(level) {
+		return nil, fmt.Errorf("ruleset %s produced a level with no walkable tiles", name)
+	}
+	params.ReportProgress(pcg.ContentTypeLevels, "connectivity", 90)
+
+	level.ID = fmt.Sprintf("generated_level_%d", params.Seed)
+	level.Name = fmt.Sprintf("Generated %s Interior", name)
+	level.Properties["theme"] = params.LevelTheme
+	level.Properties["difficulty"] = params.Difficulty
+	level.Properties["ruleset"] = name
+	level.Properties["restarts"] = restarts
+	level.Properties["generator"] = "wave_function_collapse"
+	level.Properties["version"] = wg.version
+	pcg.ApplyThemeLight(level, params.LevelTheme)
+	params.ReportProgress(pcg.ContentTypeLevels, "conversion", 95)
+
+	return level, nil
+}
+
+// wfcRulesetName returns the ruleset named by the constraints
+func wfcRulesetName(constraints map[string]interface{}) string {
+	if name, ok := constraints["wfc_ruleset"].(string); ok && name != "" {
+		return name
+	}
+	return defaultWFCRuleset
+}
+
+// wfcDimensions returns the level size in tiles named by the constraints
+func wfcDimensions(constraints map[string]interface{}) (width, height int) {
+	width, height = 48, 48
+	if w, ok := constraints["width"].(int); ok {
+		width = w
+	}
+	if h, ok := constraints["height"].(int); ok {
+		height = h
+	}
+	return width, height
+}
+
+// compileWFCRuleset validates a ruleset, expands tile rotations and builds
+// the adjacency tables
+func compileWFCRuleset(ruleset *WFCRuleset) (*compiledWFCRuleset, error) {
+	if len(ruleset.Tiles) == 0 {
+		return nil, fmt.Errorf("ruleset has no tiles")
+	}
+
+	compiled := &compiledWFCRuleset{name: ruleset.Name, size: len(ruleset.Tiles[0].Pattern)}
+	if compiled.size == 0 {
+		return nil, fmt.Errorf("tile %s has an empty pattern", ruleset.Tiles[0].Name)
+	}
+
+	for _, tile := range ruleset.Tiles {
+		if tile.Name == "" {
+			return nil, fmt.Errorf("tile with empty name")
+		}
+		if tile.Weight < 0 {
+			return nil, fmt.Errorf("tile %s has negative weight", tile.Name)
+		}
+		pattern, err := parseWFCPattern(tile, compiled.size)
+		if err != nil {
+			return nil, err
+		}
+
+		weight := tile.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		rotations := tile.Rotations
+		switch rotations {
+		case 0:
+			rotations = 1
+		case 1, 2, 4:
+		default:
+			return nil, fmt.Errorf("tile %s rotations must be 1, 2 or 4", tile.Name)
+		}
+
+		for r := 0; r < rotations; r++ {
+			compiled.variants = append(compiled.variants, wfcVariant{
+				name:    fmt.Sprintf("%s_r%d", tile.Name, r*90),
+				pattern: pattern,
+				weight:  weight / float64(rotations),
+				edges:   wfcEdges(pattern),
+			})
+			pattern = rotateWFCPattern(pattern)
+		}
+	}
+
+	count := len(compiled.variants)
+	for dir := 0; dir < 4; dir++ {
+		opposite := (dir + 2) % 4
+		compiled.compat[dir] = make([][]bool, count)
+		compiled.borderOK[dir] = make([]bool, count)
+		for a, variant := range compiled.variants {
+			compiled.compat[dir][a] = make([]bool, count)
+			for b, other := range compiled.variants {
+				compiled.compat[dir][a][b] = variant.edges[dir] == other.edges[opposite]
+			}
+			compiled.borderOK[dir][a] = isSolidEdge(variant.edges[dir])
+		}
+	}
+
+	// Every side of the interior is enclosed, so some tile must close off
+	// each map edge
+	for dir := 0; dir < 4; dir++ {
+		found := false
+		for _, ok := range compiled.borderOK[dir] {
+			found = found || ok
+		}
+		if !found {
+			return nil, fmt.Errorf("no tile has a solid edge to close the map border")
+		}
+	}
+
+	return compiled, nil
+}
+
+// parseWFCPattern checks a tile pattern and converts it to a byte grid
+func parseWFCPattern(tile WFCTileDefinition, size int) ([][]byte, error) {
+	if len(tile.Pattern) != size {
+		return nil, fmt.Errorf("tile %s pattern must have %d rows", tile.Name, size)
+	}
+	pattern := make([][]byte, size)
+	for y, row := range tile.Pattern {
+		if len(row) != size {
+			return nil, fmt.Errorf("tile %s pattern must be %dx%d", tile.Name, size, size)
+		}
+		for _, c := range []byte(row) {
+			switch c {
+			case wfcWall, wfcFloor, wfcDoor, wfcWater:
+			default:
+				return nil, fmt.Errorf("tile %s pattern has unknown character %q", tile.Name, c)
+			}
+		}
+		pattern[y] = []byte(row)
+	}
+	return pattern, nil
+}
+
+// rotateWFCPattern returns the pattern turned 90 degrees clockwise
+func rotateWFCPattern(pattern [][]byte) [][]byte {
+	size := len(pattern)
+	rotated := make([][]byte, size)
+	for y := 0; y < size; y++ {
+		rotated[y] = make([]byte, size)
+		for x := 0; x < size; x++ {
+			rotated[y][x] = pattern[size-1-x][y]
+		}
+	}
+	return rotated
+}
+
+// wfcEdges returns the walkability signature of each pattern edge. Edges
+// are read left to right or top to bottom, so touching edges of neighbouring
+// tiles compare directly.
+func wfcEdges(pattern [][]byte) [4]string {
+	size := len(pattern)
+	var north, east, south, west []byte
+	for i := 0; i < size; i++ {
+		north = append(north, edgeClass(pattern[0][i]))
+		east = append(east, edgeClass(pattern[i][size-1]))
+		south = append(south, edgeClass(pattern[size-1][i]))
+		west = append(west, edgeClass(pattern[i][0]))
+	}
+	return [4]string{string(north), string(east), string(south), string(west)}
+}
+
+// edgeClass reduces a pattern character to walkable or blocked
+func edgeClass(c byte) byte {
+	if c == wfcFloor || c == wfcDoor {
+		return wfcFloor
+	}
+	return wfcWall
+}
+
+// isSolidEdge reports whether an edge is entirely blocked
+func isSolidEdge(edge string) bool {
+	for i := 0; i < len(edge); i++ {
+		if edge[i] != wfcWall {
+			return false
+		}
+	}
+	return true
+}
+
+// solve collapses a cols x rows grid and returns the chosen variant of each
+// cell, or an error on contradiction
+func (c *compiledWFCRuleset) solve(ctx context.Context, cols, rows int, rng *rand.Rand) ([]int, error) {
+	count := len(c.variants)
+	possible := make([][]bool, cols*rows)
+	remaining := make([]int, cols*rows)
+	for i := range possible {
+		possible[i] = make([]bool, count)
+		x, y := i%cols, i/cols
+		for v := 0; v < count; v++ {
+			possible[i][v] = (y > 0 || c.borderOK[wfcNorth][v]) &&
+				(x < cols-1 || c.borderOK[wfcEast][v]) &&
+				(y < rows-1 || c.borderOK[wfcSouth][v]) &&
+				(x > 0 || c.borderOK[wfcWest][v])
+			if possible[i][v] {
+				remaining[i]++
+			}
+		}
+	}
+
+	// Apply the border restrictions to the interior before collapsing
+	stack := make([]int, 0, cols*rows)
+	for i := range possible {
+		if remaining[i] == 0 {
+			return nil, fmt.Errorf("contradiction at cell (%d,%d)", i%cols, i/cols)
+		}
+		stack = append(stack, i)
+	}
+	if err := c.propagate(possible, remaining, stack, cols, rows); err != nil {
+		return nil, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		cell := c.lowestEntropyCell(possible, remaining, rng)
+		if cell < 0 {
+			break
+		}
+
+		chosen := c.chooseVariant(possible[cell], rng)
+		for v := range possible[cell] {
+			possible[cell][v] = v == chosen
+		}
+		remaining[cell] = 1
+
+		if err := c.propagate(possible, remaining, []int{cell}, cols, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	cells := make([]int, cols*rows)
+	for i, options := range possible {
+		for v, ok := range options {
+			if ok {
+				cells[i] = v
+				break
+			}
+		}
+	}
+	return cells, nil
+}
+
+// lowestEntropyCell returns the uncollapsed cell with the lowest weighted
+// entropy, breaking ties randomly, or -1 once every cell is collapsed
+func (c *compiledWFCRuleset) lowestEntropyCell(possible [][]bool, remaining []int, rng *rand.Rand) int {
+	best := -1
+	bestEntropy := math.Inf(1)
+	for i, options := range possible {
+		if remaining[i] <= 1 {
+			continue
+		}
+
+		sum, sumLog := 0.0, 0.0
+		for v, ok := range options {
+			if ok {
+				w := c.variants[v].weight
+				sum += w
+				sumLog += w * math.Log(w)
+			}
+		}
+		entropy := math.Log(sum) - sumLog/sum + rng.Float64()*1e-6
+		if entropy < bestEntropy {
+			bestEntropy = entropy
+			best = i
+		}
+	}
+	return best
+}
+
+// chooseVariant picks one of the remaining variants by weight
+func (c *compiledWFCRuleset) chooseVariant(options []bool, rng *rand.Rand) int {
+	total := 0.0
+	for v, ok := range options {
+		if ok {
+			total += c.variants[v].weight
+		}
+	}
+
+	roll := rng.Float64() * total
+	last := -1
+	for v, ok := range options {
+		if !ok {
+			continue
+		}
+		last = v
+		roll -= c.variants[v].weight
+		if roll <= 0 {
+			return v
+		}
+	}
+	return last
+}
+
+// propagate removes variants that no longer fit their neighbours, starting
+// from the given cells, until nothing changes
+func (c *compiledWFCRuleset) propagate(possible [][]bool, remaining []int, stack []int, cols, rows int) error {
+	count := len(c.variants)
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := cell%cols, cell/cols
+
+		for dir, offset := range wfcOffsets {
+			nx, ny := x+offset[0], y+offset[1]
+			if nx < 0 || ny < 0 || nx >= cols || ny >= rows {
+				continue
+			}
+			neighbor := ny*cols + nx
+
+			allowed := make([]bool, count)
+			for v, ok := range possible[cell] {
+				if !ok {
+					continue
+				}
+				for b, fits := range c.compat[dir][v] {
+					allowed[b] = allowed[b] || fits
+				}
+			}
+
+			changed := false
+			for b, ok := range possible[neighbor] {
+				if ok && !allowed[b] {
+					possible[neighbor][b] = false
+					remaining[neighbor]--
+					changed = true
+				}
+			}
+			if remaining[neighbor] == 0 {
+				return fmt.Errorf("contradiction at cell (%d,%d)", nx, ny)
+			}
+			if changed {
+				stack = append(stack, neighbor)
+			}
+		}
+	}
+	return nil
+}
+
+// render expands the collapsed cells into level tiles
+func (c *compiledWFCRuleset) render(cells []int, cols, rows int) *game.Level {
+	width, height := cols*c.size, rows*c.size
+	level := &game.Level{
+		Width:      width,
+		Height:     height,
+		Tiles:      make([][]game.Tile, height),
+		Properties: make(map[string]interface{}),
+	}
+	for y := range level.Tiles {
+		level.Tiles[y] = make([]game.Tile, width)
+	}
+
+	for i, v := range cells {
+		cx, cy := i%cols, i/cols
+		for py, row := range c.variants[v].pattern {
+			for px, ch := range row {
+				level.Tiles[cy*c.size+py][cx*c.size+px] = wfcTile(ch)
+			}
+		}
+	}
+	return level
+}
+
+// wfcTile converts a pattern character to a level tile
+func wfcTile(c byte) game.Tile {
+	tile := game.Tile{Properties: make(map[string]interface{})}
+	switch c {
+	case wfcFloor:
+		tile.Type, tile.Walkable, tile.Transparent = game.TileFloor, true, true
+	case wfcDoor:
+		tile.Type, tile.Walkable = game.TileDoor, true
+	case wfcWater:
+		tile.Type, tile.Transparent = game.TileWater, true
+	default:
+		tile.Type, tile.BlocksSight = game.TileWall, true
+	}
+	return tile
+}
+
+// fillUnreachable turns walkable tiles outside the largest connected area
+// into walls. It reports false if the level has no walkable tiles.
+func fillUnreachable(level *game.Level) bool {
+	region := make([][]int, level.Height)
+	for y := range region {
+		region[y] = make([]int, level.Width)
+	}
+
+	sizes := []int{0} // Region 0 means unvisited
+	for y := 0; y < level.Height; y++ {
+		for x := 0; x < level.Width; x++ {
+			if !level.Tiles[y][x].Walkable || region[y][x] != 0 {
+				continue
+			}
+			id := len(sizes)
+			size := 0
+			stack := []game.Position{{X: x, Y: y}}
+			region[y][x] = id
+			for len(stack) > 0 {
+				pos := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				size++
+				for _, offset := range wfcOffsets {
+					nx, ny := pos.X+offset[0], pos.Y+offset[1]
+					if nx < 0 || ny < 0 || nx >= level.Width || ny >= level.Height {
+						continue
+					}
+					if level.Tiles[ny][nx].Walkable && region[ny][nx] == 0 {
+						region[ny][nx] = id
+						stack = append(stack, game.Position{X: nx, Y: ny})
+					}
+				}
+			}
+			sizes = append(sizes, size)
+		}
+	}
+
+	largest := 0
+	for id := 1; id < len(sizes); id++ {
+		if sizes[id] > sizes[largest] {
+			largest = id
+		}
+	}
+	if largest == 0 {
+		return false
+	}
+
+	for y := 0; y < level.Height; y++ {
+		for x := 0; x < level.Width; x++ {
+			if region[y][x] != 0 && region[y][x] != largest {
+				level.Tiles[y][x] = wfcTile(wfcWall)
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,278 @@
+package levels
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+func wfcTestParams(seed int64, ruleset string) pcg.LevelParams {
+	return pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{
+			Seed:        seed,
+			Difficulty:  5,
+			PlayerLevel: 3,
+			Constraints: map[string]interface{}{
+				"wfc_ruleset": ruleset,
+				"width":       30,
+				"height":      24,
+			},
+		},
+		LevelTheme: pcg.ThemeClassic,
+	}
+}
+
+func TestWFCGenerator_GenerateLevel(t *testing.T) {
+	generator := NewWFCGenerator()
+
+	for _, ruleset := range []string{"castle", "temple"} {
+		t.Run(ruleset, func(t *testing.T) {
+			level, err := generator.GenerateLevel(context.Background(), wfcTestParams(42, ruleset))
+			if err != nil {
+				t.Fatalf("GenerateLevel failed: %v", err)
+			}
+
+			if level.Width != 30 || level.Height != 24 {
+				t.Errorf("Expected 30x24 level, got %dx%d", level.Width, level.Height)
+			}
+			if level.Properties["ruleset"] != ruleset {
+				t.Errorf("Expected ruleset property %s, got %v", ruleset, level.Properties["ruleset"])
+			}
+			if level.Properties["generator"] != "wave_function_collapse" {
+				t.Errorf("Unexpected generator property %v", level.Properties["generator"])
+			}
+
+			// The interior is enclosed by walls
+			for x := 0; x < level.Width; x++ {
+				if level.Tiles[0][x].Walkable || level.Tiles[level.Height-1][x].Walkable {
+					t.Fatalf("Border tile at column %d is walkable", x)
+				}
+			}
+			for y := 0; y < level.Height; y++ {
+				if level.Tiles[y][0].Walkable || level.Tiles[y][level.Width-1].Walkable {
+					t.Fatalf("Border tile at row %d is walkable", y)
+				}
+			}
+
+			walkable, reachable := wfcReachability(level)
+			if walkable == 0 {
+				t.Fatal("Level has no walkable tiles")
+			}
+			if reachable != walkable {
+				t.Errorf("Only %d of %d walkable tiles are reachable", reachable, walkable)
+			}
+		})
+	}
+}
+
+func TestWFCGenerator_Deterministic(t *testing.T) {
+	generator := NewWFCGenerator()
+
+	first, err := generator.GenerateLevel(context.Background(), wfcTestParams(7, "castle"))
+	if err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+	second, err := generator.GenerateLevel(context.Background(), wfcTestParams(7, "castle"))
+	if err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+	other, err := generator.GenerateLevel(context.Background(), wfcTestParams(8, "castle"))
+	if err != nil {
+		t.Fatalf("GenerateLevel failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(first.Tiles, second.Tiles) {
+		t.Error("Same seed produced different layouts")
+	}
+	if reflect.DeepEqual(first.Tiles, other.Tiles) {
+		t.Error("Different seeds produced identical layouts")
+	}
+}
+
+func TestWFCGenerator_ContradictionRestarts(t *testing.T) {
+	generator := NewWFCGenerator()
+
+	// Every cell must pair with exactly one neighbour, which an odd number
+	// of cells can never satisfy
+	generator.rulesets["dead_ends"] = &WFCRuleset{
+		Name: "dead_ends",
+		Tiles: []WFCTileDefinition{
+			{Name: "dead_end", Rotations: 4, Pattern: []string{"###", "#.#", "#.#"}},
+		},
+	}
+	params := wfcTestParams(1, "dead_ends")
+	params.Constraints["width"] = 9
+	params.Constraints["height"] = 9
+
+	_, err := generator.GenerateLevel(context.Background(), params)
+	if err == nil || !strings.Contains(err.Error(), "failed after 21 attempts") {
+		t.Errorf("Expected contradiction failure after all restarts, got %v", err)
+	}
+}
+
+func TestWFCGenerator_Generate(t *testing.T) {
+	generator := NewWFCGenerator()
+
+	if _, err := generator.Generate(context.Background(), pcg.GenerationParams{}); err == nil {
+		t.Error("Expected error for missing level parameters")
+	}
+
+	result, err := generator.Generate(context.Background(), pcg.GenerationParams{
+		Seed: 3,
+		Constraints: map[string]interface{}{
+			"level_params": pcg.LevelParams{GenerationParams: pcg.GenerationParams{Seed: 3}},
+			"wfc_ruleset":  "temple",
+			"width":        21,
+			"height":       21,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	level, ok := result.(*game.Level)
+	if !ok {
+		t.Fatalf("Expected *game.Level, got %T", result)
+	}
+	if level.Properties["ruleset"] != "temple" {
+		t.Errorf("Expected temple ruleset, got %v", level.Properties["ruleset"])
+	}
+}
+
+func TestWFCGenerator_Validate(t *testing.T) {
+	generator := NewWFCGenerator()
+	levelParams := pcg.LevelParams{}
+
+	tests := []struct {
+		name        string
+		constraints map[string]interface{}
+		difficulty  int
+		expectError bool
+	}{
+		{"valid", map[string]interface{}{"level_params": levelParams}, 5, false},
+		{"missing level params", map[string]interface{}{}, 5, true},
+		{"bad difficulty", map[string]interface{}{"level_params": levelParams}, 0, true},
+		{"unknown ruleset", map[string]interface{}{"level_params": levelParams, "wfc_ruleset": "pagoda"}, 5, true},
+		{"too small", map[string]interface{}{"level_params": levelParams, "width": 6}, 5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := generator.Validate(pcg.GenerationParams{Difficulty: tt.difficulty, Constraints: tt.constraints})
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestLoadWFCRulesets(t *testing.T) {
+	rulesets, err := LoadWFCRulesets(filepath.Join("..", "..", "..", "data", "pcg", "wfc_rulesets.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load shipped rulesets: %v", err)
+	}
+	for name, builtin := range builtinWFCRulesets {
+		loaded, exists := rulesets[name]
+		if !exists {
+			t.Errorf("Ruleset file is missing built-in ruleset %s", name)
+			continue
+		}
+		if !reflect.DeepEqual(loaded, builtin) {
+			t.Errorf("Ruleset file differs from built-in ruleset %s", name)
+		}
+	}
+
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "custom.yaml")
+	writeFile(t, custom, `rulesets:
+  keep:
+    tiles:
+      - name: solid
+        pattern: ["##", "##"]
+      - name: hall
+        weight: 2
+        pattern: ["..", ".."]
+      - name: wall
+        rotations: 4
+        pattern: ["##", ".."]
+      - name: corner
+        rotations: 4
+        pattern: ["##", "#."]
+`)
+
+	generator := NewWFCGenerator()
+	if err := generator.LoadRulesets(custom); err != nil {
+		t.Fatalf("LoadRulesets failed: %v", err)
+	}
+	if !reflect.DeepEqual(generator.Rulesets(), []string{"castle", "keep", "temple"}) {
+		t.Errorf("Unexpected rulesets %v", generator.Rulesets())
+	}
+	if _, err := generator.GenerateLevel(context.Background(), wfcTestParams(5, "keep")); err != nil {
+		t.Errorf("Custom ruleset failed to generate: %v", err)
+	}
+
+	invalid := map[string]string{
+		"ragged":     `rulesets: {bad: {tiles: [{name: a, pattern: ["###", "##"]}]}}`,
+		"unknown":    `rulesets: {bad: {tiles: [{name: a, pattern: ["#?", "##"]}]}}`,
+		"no border":  `rulesets: {bad: {tiles: [{name: a, pattern: ["..", ".."]}]}}`,
+		"rotations":  `rulesets: {bad: {tiles: [{name: a, rotations: 3, pattern: ["##", "##"]}]}}`,
+		"mixed size": `rulesets: {bad: {tiles: [{name: a, pattern: ["##", "##"]}, {name: b, pattern: ["###", "###", "###"]}]}}`,
+	}
+	for name, content := range invalid {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_")+".yaml")
+		writeFile(t, path, content)
+		if _, err := LoadWFCRulesets(path); err == nil {
+			t.Errorf("Expected error loading %s ruleset", name)
+		}
+	}
+}
+
+// wfcReachability counts the walkable tiles of a level and how many of them
+// are reachable from the first one
+func wfcReachability(level *game.Level) (walkable, reachable int) {
+	var start *game.Position
+	for y := 0; y < level.Height; y++ {
+		for x := 0; x < level.Width; x++ {
+			if level.Tiles[y][x].Walkable {
+				walkable++
+				if start == nil {
+					start = &game.Position{X: x, Y: y}
+				}
+			}
+		}
+	}
+	if start == nil {
+		return 0, 0
+	}
+
+	seen := map[game.Position]bool{*start: true}
+	queue := []game.Position{*start}
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+		reachable++
+		for _, offset := range wfcOffsets {
+			next := game.Position{X: pos.X + offset[0], Y: pos.Y + offset[1]}
+			if next.X < 0 || next.Y < 0 || next.X >= level.Width || next.Y >= level.Height {
+				continue
+			}
+			if level.Tiles[next.Y][next.X].Walkable && !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return walkable, reachable
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
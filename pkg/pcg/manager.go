@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"goldbox-rpg/pkg/game"
@@ -22,8 +23,23 @@ type PCGManager struct {
 	seedManager    *SeedManager
 	metrics        *GenerationMetrics
 	qualityMetrics *ContentQualityMetrics
+	mu             sync.RWMutex
+	observers      []GenerationObserver
+	eventSystem    *game.EventSystem
+	jobs           *JobQueue
+	contentIndex   *ContentIndex
+	cache          *ContentCache
+	difficulty     *DifficultyDirector
+	qualityGate    *QualityGate
+	monsters       *MonsterGenerator
+	travel         *TravelGraph
+	genre          GenreType
 }
 
+// GenerationObserver is notified after each generation run by the manager
+// completes. err is non-nil when the generation failed.
+type GenerationObserver func(contentType ContentType, duration time.Duration, err error)
+
 // NewPCGManager creates a new PCG manager instance
 func NewPCGManager(world *game.World, logger *logrus.Logger) *PCGManager {
 	if logger == nil {
@@ -36,6 +52,8 @@ func NewPCGManager(world *game.World, logger *logrus.Logger) *PCGManager {
 	seedManager := NewSeedManager(0) // Will be set by game initialization
 	metrics := NewGenerationMetrics()
 	qualityMetrics := NewContentQualityMetrics()
+	cache := NewContentCache(DefaultContentCacheConfig(), metrics)
+	registry.SetCache(cache)
 
 	return &PCGManager{
 		registry:       registry,
@@ -46,6 +64,13 @@ func NewPCGManager(world *game.World, logger *logrus.Logger) *PCGManager {
 		seedManager:    seedManager,
 		metrics:        metrics,
 		qualityMetrics: qualityMetrics,
+		jobs:           NewJobQueue(DefaultMaxConcurrentJobs, logger),
+		contentIndex:   NewContentIndex(DefaultContentIndexCapacity),
+		cache:          cache,
+		difficulty:     NewDifficultyDirector(DefaultDifficultyDirectorConfig()),
+		qualityGate:    NewQualityGate(DefaultQualityGateConfig()),
+		monsters:       NewMonsterGenerator(logger),
+		travel:         NewTravelGraph(),
 	}
 }
 
@@ -83,6 +108,18 @@ func (pcg *PCGManager) RegisterDefaultGenerators() error {
 		return fmt.Errorf("failed to register dialogue generator: %w", err)
 	}
 
+	// Register the monster generator
+	monsterGenerator := NewMonsterGenerator(pcg.logger)
+	if err := pcg.registry.RegisterGenerator("default", monsterGenerator); err != nil {
+		return fmt.Errorf("failed to register monster generator: %w", err)
+	}
+
+	// Register the deity generator
+	deityGenerator := NewDeityGenerator(pcg.logger)
+	if err := pcg.registry.RegisterGenerator("default", deityGenerator); err != nil {
+		return fmt.Errorf("failed to register deity generator: %w", err)
+	}
+
 	// Note: Actual generators are registered by the server initialization
 	// to avoid import cycles. This method serves as a placeholder for
 	// future expansion and is called to ensure the system is ready.
@@ -93,10 +130,11 @@ func (pcg *PCGManager) RegisterDefaultGenerators() error {
 // GenerateTerrainForLevel generates terrain for a specific game level
 func (pcg *PCGManager) GenerateTerrainForLevel(ctx context.Context, levelID string, width, height int, biome BiomeType, difficulty int) (*game.GameMap, error) {
 	startTime := time.Now()
+	difficulty = pcg.difficulty.GetEffectiveDifficultyFor(ContentTypeTerrain, difficulty)
 
 	params := TerrainParams{
 		GenerationParams: GenerationParams{
-			Seed:        pcg.seedManager.DeriveContextSeed(ContentTypeTerrain, levelID),
+			Seed:        pcg.seedManager.VersionedContextSeed(ContentTypeTerrain, levelID),
 			Difficulty:  difficulty,
 			PlayerLevel: 1, // Could be derived from world state
 			WorldState:  pcg.world,
@@ -110,21 +148,38 @@ func (pcg *PCGManager) GenerateTerrainForLevel(ctx context.Context, levelID stri
 		Roughness:    0.5,
 	}
 
+	// Registered biomes supply their own generation parameters
+	if def, exists := Biomes().Get(biome); exists {
+		params.Density = def.DefaultDensity
+		params.Connectivity = def.ConnectivityLevel
+		params.WaterLevel = (def.WaterLevelRange[0] + def.WaterLevelRange[1]) / 2
+		params.Roughness = (def.RoughnessRange[0] + def.RoughnessRange[1]) / 2
+	}
+
 	// Add terrain-specific constraints
 	params.Constraints["width"] = width
 	params.Constraints["height"] = height
-	params.Constraints["terrain_params"] = params
 
-	gameMap, err := pcg.factory.GenerateTerrain(ctx, "cellular_automata", params)
+	// Embed a copy with its own constraints map so the params do not contain
+	// themselves; seed derivation formats constraints and would never terminate.
+	embedded := params
+	embedded.Constraints = map[string]interface{}{"width": width, "height": height}
+	params.Progress = ProgressFromContext(ctx)
+
+	content, err := pcg.generateGated(ctx, ContentTypeTerrain, levelID, params.Seed, func(seed int64) (interface{}, error) {
+		params.Seed, embedded.Seed = seed, seed
+		params.Constraints["terrain_params"] = embedded
+		return pcg.factory.GenerateTerrain(ctx, "cellular_automata", params)
+	})
+	gameMap, _ := content.(*game.GameMap)
+	if err == nil {
+		params.ReportProgress(ContentTypeTerrain, ProgressStageComplete, 100)
+	}
 
-	// Record generation metrics
 	duration := time.Since(startTime)
-	pcg.qualityMetrics.RecordContentGeneration(ContentTypeTerrain, gameMap, duration, err)
-
-	if err != nil {
-		pcg.metrics.RecordError(ContentTypeTerrain)
-	} else {
-		pcg.metrics.RecordGeneration(ContentTypeTerrain, duration)
+	pcg.recordGeneration(ContentTypeTerrain, gameMap, duration, err)
+	if err == nil {
+		pcg.contentIndex.IndexTerrain(levelID, biome, difficulty, gameMap)
 	}
 
 	pcg.logger.WithFields(logrus.Fields{
@@ -142,8 +197,8 @@ func (pcg *PCGManager) GenerateItemsForLocation(ctx context.Context, locationID
 
 	params := ItemParams{
 		GenerationParams: GenerationParams{
-			Seed:        pcg.seedManager.DeriveContextSeed(ContentTypeItems, locationID),
-			Difficulty:  pcg.calculateLocationDifficulty(locationID),
+			Seed:        pcg.seedManager.VersionedContextSeed(ContentTypeItems, locationID),
+			Difficulty:  pcg.difficulty.GetEffectiveDifficultyFor(ContentTypeItems, pcg.calculateLocationDifficulty(locationID)),
 			PlayerLevel: playerLevel,
 			WorldState:  pcg.world,
 			Timeout:     10 * time.Second,
@@ -158,17 +213,21 @@ func (pcg *PCGManager) GenerateItemsForLocation(ctx context.Context, locationID
 
 	// Add item count constraint
 	params.Constraints["item_count"] = itemCount
+	params.Progress = ProgressFromContext(ctx)
+
+	content, err := pcg.generateGated(ctx, ContentTypeItems, locationID, params.Seed, func(seed int64) (interface{}, error) {
+		params.Seed = seed
+		return pcg.factory.GenerateItems(ctx, "template_based", params)
+	})
+	items, _ := content.([]*game.Item)
+	if err == nil {
+		params.ReportProgress(ContentTypeItems, ProgressStageComplete, 100)
+	}
 
-	items, err := pcg.factory.GenerateItems(ctx, "template_based", params)
-
-	// Record generation metrics
 	duration := time.Since(startTime)
-	pcg.qualityMetrics.RecordContentGeneration(ContentTypeItems, items, duration, err)
-
-	if err != nil {
-		pcg.metrics.RecordError(ContentTypeItems)
-	} else {
-		pcg.metrics.RecordGeneration(ContentTypeItems, duration)
+	pcg.recordGeneration(ContentTypeItems, items, duration, err)
+	if err == nil {
+		pcg.contentIndex.IndexItems(locationID, params.Difficulty, items)
 	}
 
 	pcg.logger.WithFields(logrus.Fields{
@@ -181,13 +240,18 @@ func (pcg *PCGManager) GenerateItemsForLocation(ctx context.Context, locationID
 	return items, err
 }
 
-// GenerateDungeonLevel generates a complete dungeon level
-func (pcg *PCGManager) GenerateDungeonLevel(ctx context.Context, levelID string, minRooms, maxRooms int, theme LevelTheme, difficulty int) (*game.Level, error) {
+// GenerateDungeonLevel generates a complete dungeon level. Layout
+// constraints, if given, are enforced on its rooms; a level that cannot be
+// repaired to meet them fails with a *ConstraintError.
+func (pcg *PCGManager) GenerateDungeonLevel(ctx context.Context, levelID string, minRooms, maxRooms int, theme LevelTheme, difficulty int, constraints ...LayoutConstraint) (*game.Level, error) {
+	startTime := time.Now()
+	difficulty = pcg.difficulty.GetEffectiveDifficultyFor(ContentTypeLevels, difficulty)
+
 	params := LevelParams{
 		GenerationParams: GenerationParams{
-			Seed:        pcg.seedManager.DeriveContextSeed(ContentTypeLevels, levelID),
+			Seed:        pcg.seedManager.VersionedContextSeed(ContentTypeLevels, levelID),
 			Difficulty:  difficulty,
-			PlayerLevel: pcg.getAveragePartyLevel(),
+			PlayerLevel: difficulty,
 			WorldState:  pcg.world,
 			Timeout:     60 * time.Second,
 			Constraints: make(map[string]interface{}),
@@ -199,21 +263,45 @@ func (pcg *PCGManager) GenerateDungeonLevel(ctx context.Context, levelID string,
 		LevelTheme:    theme,
 		HasBoss:       difficulty >= 10,
 		SecretRooms:   maxRooms / 10,
+
+		LayoutConstraints: constraints,
 	}
 
-	return pcg.factory.GenerateLevel(ctx, "room_corridor", params)
+	// Level generators read their parameters from constraints; embed a copy
+	// with its own constraints map, as for terrain above.
+	embedded := params
+	embedded.Constraints = make(map[string]interface{})
+	params.Progress = ProgressFromContext(ctx)
+
+	content, err := pcg.generateGated(ctx, ContentTypeLevels, levelID, params.Seed, func(seed int64) (interface{}, error) {
+		params.Seed, embedded.Seed = seed, seed
+		params.Constraints["level_params"] = embedded
+		return pcg.factory.GenerateLevel(ctx, "room_corridor", params)
+	})
+	level, _ := content.(*game.Level)
+	if err == nil {
+		params.ReportProgress(ContentTypeLevels, ProgressStageComplete, 100)
+	}
+	pcg.recordGeneration(ContentTypeLevels, level, time.Since(startTime), err)
+	if err == nil {
+		pcg.contentIndex.IndexLevel(levelID, theme, difficulty, level)
+	}
+
+	return level, err
 }
 
 // GenerateQuestForArea generates a quest appropriate for a specific area
 func (pcg *PCGManager) GenerateQuestForArea(ctx context.Context, areaID string, questType QuestType, playerLevel int) (*game.Quest, error) {
+	startTime := time.Now()
+
 	params := QuestParams{
 		GenerationParams: GenerationParams{
-			Seed:        pcg.seedManager.DeriveContextSeed(ContentTypeQuests, areaID),
-			Difficulty:  pcg.calculateAreaDifficulty(areaID),
+			Seed:        pcg.seedManager.VersionedContextSeed(ContentTypeQuests, areaID),
+			Difficulty:  pcg.difficulty.GetEffectiveDifficultyFor(ContentTypeQuests, pcg.calculateAreaDifficulty(areaID)),
 			PlayerLevel: playerLevel,
 			WorldState:  pcg.world,
 			Timeout:     15 * time.Second,
-			Constraints: make(map[string]interface{}),
+			Constraints: map[string]interface{}{"quest_type": string(questType)},
 		},
 		QuestType:     questType,
 		MinObjectives: 1,
@@ -221,8 +309,101 @@ func (pcg *PCGManager) GenerateQuestForArea(ctx context.Context, areaID string,
 		RewardTier:    RarityRare,
 		Narrative:     NarrativeLinear,
 	}
+	if genre := pcg.getGenre(); genre != "" {
+		params.Constraints["genre"] = string(genre)
+		params.Constraints["area"] = areaID
+	}
+	params.Progress = ProgressFromContext(ctx)
+
+	content, err := pcg.generateGated(ctx, ContentTypeQuests, areaID, params.Seed, func(seed int64) (interface{}, error) {
+		params.Seed = seed
+		return pcg.factory.GenerateQuest(ctx, "objective_based", params)
+	})
+	quest, _ := content.(*game.Quest)
+	if err == nil {
+		params.ReportProgress(ContentTypeQuests, ProgressStageComplete, 100)
+	}
+	pcg.recordGeneration(ContentTypeQuests, quest, time.Since(startTime), err)
+	if err == nil {
+		pcg.contentIndex.IndexQuest(areaID, questType, params.Difficulty, quest)
+	}
 
-	return pcg.factory.GenerateQuest(ctx, "objective_based", params)
+	return quest, err
+}
+
+// GeneratePersonalQuest generates a quest tied to a character rather than an
+// area. The quest is derived only from seed, so the same character seed always
+// produces the same quest regardless of the manager's world seed, and so
+// leaves out the quest hooks of the world's deities.
+func (pcg *PCGManager) GeneratePersonalQuest(ctx context.Context, seed int64, questType QuestType, playerLevel int) (*game.Quest, error) {
+	startTime := time.Now()
+
+	params := QuestParams{
+		GenerationParams: GenerationParams{
+			Seed:        seed,
+			Difficulty:  pcg.difficulty.GetEffectiveDifficultyFor(ContentTypeQuests, playerLevel),
+			PlayerLevel: playerLevel,
+			WorldState:  pcg.world,
+			Timeout:     15 * time.Second,
+			Constraints: map[string]interface{}{"deity_hooks": false},
+		},
+		QuestType:     questType,
+		MinObjectives: 1,
+		MaxObjectives: 2,
+		RewardTier:    RarityUncommon,
+		Narrative:     NarrativeLinear,
+	}
+	params.Progress = ProgressFromContext(ctx)
+
+	quest, err := pcg.factory.GenerateQuest(ctx, "objective_based", params)
+	if err == nil {
+		params.ReportProgress(ContentTypeQuests, ProgressStageComplete, 100)
+	}
+	pcg.recordGeneration(ContentTypeQuests, quest, time.Since(startTime), err)
+	if err == nil {
+		pcg.contentIndex.IndexQuest("", questType, params.Difficulty, quest)
+	}
+
+	return quest, err
+}
+
+// GenerateMonsters generates a monster group and records it in the
+// manager's metrics like other generated content
+func (pcg *PCGManager) GenerateMonsters(ctx context.Context, params MonsterParams) ([]*MonsterStatBlock, error) {
+	startTime := time.Now()
+	monsters, err := pcg.monsters.GenerateMonsters(ctx, params)
+	pcg.recordGeneration(ContentTypeMonsters, monsters, time.Since(startTime), err)
+	return monsters, err
+}
+
+// GenerateWithGenerator generates content for a location with a generator
+// chosen by name from the registry, such as one provided by a plugin. The
+// seed and difficulty are derived as for the built-in generators.
+func (pcg *PCGManager) GenerateWithGenerator(ctx context.Context, contentType ContentType, generatorName, locationID string, difficulty int, constraints map[string]interface{}) (interface{}, error) {
+	startTime := time.Now()
+
+	if constraints == nil {
+		constraints = make(map[string]interface{})
+	}
+	params := GenerationParams{
+		Seed:        pcg.seedManager.VersionedContextSeed(contentType, locationID),
+		Difficulty:  pcg.difficulty.GetEffectiveDifficultyFor(contentType, difficulty),
+		PlayerLevel: pcg.getAveragePartyLevel(),
+		WorldState:  pcg.world,
+		Timeout:     30 * time.Second,
+		Constraints: constraints,
+		Progress:    ProgressFromContext(ctx),
+	}
+
+	content, err := pcg.generateGated(ctx, contentType, locationID, params.Seed, func(seed int64) (interface{}, error) {
+		params.Seed = seed
+		return pcg.registry.GenerateContent(ctx, contentType, generatorName, params)
+	})
+	if err == nil {
+		params.ReportProgress(contentType, ProgressStageComplete, 100)
+	}
+	pcg.recordGeneration(contentType, content, time.Since(startTime), err)
+	return content, err
 }
 
 // ValidateGeneratedContent validates content before integration into the world
@@ -241,42 +422,16 @@ func (pcg *PCGManager) ValidateGeneratedContent(content interface{}) (*Validatio
 	}
 }
 
-// IntegrateContentIntoWorld integrates generated content into the game world
+// IntegrateContentIntoWorld integrates generated content into the game world.
+// Content is applied atomically: if any part fails, nothing is integrated.
 func (pcg *PCGManager) IntegrateContentIntoWorld(content interface{}, locationID string) error {
-	// Validate content before integration
-	validationResult, err := pcg.ValidateGeneratedContent(content)
-	if err != nil {
-		return fmt.Errorf("validation failed: %w", err)
-	}
+	ix := pcg.BeginIntegration(context.Background(), locationID)
+	defer ix.Rollback()
 
-	if !validationResult.IsValid() {
-		return fmt.Errorf("content validation failed: %v", validationResult.Errors)
-	}
-
-	// Log warnings if present
-	if validationResult.HasWarnings() {
-		pcg.logger.WithFields(logrus.Fields{
-			"location": locationID,
-			"warnings": validationResult.Warnings,
-		}).Warn("Generated content has validation warnings")
-	}
-
-	// Integrate based on content type
-	switch v := content.(type) {
-	case *game.Level:
-		return pcg.integrateLevelIntoWorld(v, locationID)
-	case *game.Item:
-		return pcg.integrateItemIntoWorld(v, locationID)
-	case []*game.Item:
-		for _, item := range v {
-			if err := pcg.integrateItemIntoWorld(item, locationID); err != nil {
-				return err
-			}
-		}
-		return nil
-	default:
-		return fmt.Errorf("unsupported content type for integration: %T", content)
+	if err := ix.Stage(content); err != nil {
+		return err
 	}
+	return ix.Commit()
 }
 
 // RegenerateContentForLocation regenerates content for a specific location
@@ -317,14 +472,57 @@ func (pcg *PCGManager) GetGenerationStatistics() map[string]interface{} {
 	// Include generation metrics
 	stats["performance_metrics"] = pcg.metrics.GetStats()
 
+	// Include feedback-driven difficulty adjustments
+	stats["difficulty"] = pcg.difficulty.GetStats()
+
+	// Include the content cache's size and evictions
+	stats["cache"] = pcg.cache.Stats()
+
+	// Include the quality gate's rejection rates
+	stats["quality_gate"] = pcg.qualityGate.Stats()
+
 	return stats
 }
 
+// GetSeedManager returns the seed manager used to derive generation seeds
+func (pcg *PCGManager) GetSeedManager() *SeedManager {
+	return pcg.seedManager
+}
+
 // GetRegistry returns the generator registry for external registration
 func (pcg *PCGManager) GetRegistry() *Registry {
 	return pcg.registry
 }
 
+// GetDifficultyDirector returns the director that adjusts generation
+// difficulty from player feedback
+func (pcg *PCGManager) GetDifficultyDirector() *DifficultyDirector {
+	return pcg.difficulty
+}
+
+// GetContentIndex returns the index of content generated by the manager
+func (pcg *PCGManager) GetContentIndex() *ContentIndex {
+	return pcg.contentIndex
+}
+
+// GetContentCache returns the cache of generated content shared by the
+// manager's generations
+func (pcg *PCGManager) GetContentCache() *ContentCache {
+	return pcg.cache
+}
+
+// GetQualityGate returns the gate selecting the best of several generated
+// candidates. It is disabled until configured.
+func (pcg *PCGManager) GetQualityGate() *QualityGate {
+	return pcg.qualityGate
+}
+
+// GetTravelGraph returns the graph of the connections between world levels
+// that integrated dungeon complexes add to
+func (pcg *PCGManager) GetTravelGraph() *TravelGraph {
+	return pcg.travel
+}
+
 // GetMetrics returns the generation metrics instance
 func (pcg *PCGManager) GetMetrics() *GenerationMetrics {
 	return pcg.metrics
@@ -343,6 +541,7 @@ func (pcg *PCGManager) GenerateQualityReport() *QualityReport {
 // RecordPlayerFeedback records player feedback for quality assessment
 func (pcg *PCGManager) RecordPlayerFeedback(feedback PlayerFeedback) {
 	pcg.qualityMetrics.RecordPlayerFeedback(feedback)
+	pcg.difficulty.RecordFeedback(feedback)
 }
 
 // RecordQuestCompletion records quest completion for engagement tracking
@@ -355,61 +554,97 @@ func (pcg *PCGManager) GetOverallQualityScore() float64 {
 	return pcg.qualityMetrics.GetOverallQualityScore()
 }
 
-// ResetMetrics clears all generation metrics
-func (pcg *PCGManager) ResetMetrics() {
-	pcg.metrics.Reset()
-	pcg.logger.Info("PCG generation metrics reset")
+// SetEventSystem sets the event system used to publish integration events
+func (pcg *PCGManager) SetEventSystem(eventSystem *game.EventSystem) {
+	pcg.mu.Lock()
+	defer pcg.mu.Unlock()
+	pcg.eventSystem = eventSystem
 }
 
-// ResetQualityMetrics clears all quality metrics
-func (pcg *PCGManager) ResetQualityMetrics() {
-	pcg.qualityMetrics = NewContentQualityMetrics()
-	pcg.logger.Info("PCG quality metrics reset")
+// SetGenre sets the genre variant of the world. Quests generated for an
+// area then carry the genre's consequences, such as lockouts of the area.
+func (pcg *PCGManager) SetGenre(genre GenreType) {
+	pcg.mu.Lock()
+	defer pcg.mu.Unlock()
+	pcg.genre = genre
 }
 
-// Helper methods for integration
+// getGenre returns the genre variant, or "" if none is set
+func (pcg *PCGManager) getGenre() GenreType {
+	pcg.mu.RLock()
+	defer pcg.mu.RUnlock()
+	return pcg.genre
+}
 
-func (pcg *PCGManager) integrateLevelIntoWorld(level *game.Level, locationID string) error {
-	// Add level to world - World should provide thread-safe methods for this
-	// For now, we'll use a direct approach assuming World has proper synchronization
-	pcg.world.Levels = append(pcg.world.Levels, *level)
+// getEventSystem returns the configured event system, or nil if none is set
+func (pcg *PCGManager) getEventSystem() *game.EventSystem {
+	pcg.mu.RLock()
+	defer pcg.mu.RUnlock()
+	return pcg.eventSystem
+}
 
-	pcg.logger.WithFields(logrus.Fields{
-		"level_id": level.ID,
-		"location": locationID,
-		"width":    level.Width,
-		"height":   level.Height,
-	}).Info("Integrated generated level into world")
+// AddGenerationObserver registers an observer called after every generation
+// run through the manager, e.g. to export timings to a monitoring system.
+func (pcg *PCGManager) AddGenerationObserver(observer GenerationObserver) {
+	pcg.mu.Lock()
+	defer pcg.mu.Unlock()
+	pcg.observers = append(pcg.observers, observer)
+}
 
-	return nil
+// SubmitGenerationJob queues generation work to run in the background and
+// returns the job's ID, see JobQueue. Generate* calls made by the job's work
+// with the context it receives report progress into the job's status.
+func (pcg *PCGManager) SubmitGenerationJob(spec JobSpec) (string, error) {
+	return pcg.jobs.Submit(spec)
 }
 
-func (pcg *PCGManager) integrateItemIntoWorld(item *game.Item, locationID string) error {
-	// Add item to world objects - World should provide thread-safe methods for this
-	if pcg.world.Objects == nil {
-		pcg.world.Objects = make(map[string]game.GameObject)
+// GetGenerationJob returns the status, progress and, once completed, the
+// result of a generation job
+func (pcg *PCGManager) GetGenerationJob(jobID string) (GenerationJob, error) {
+	return pcg.jobs.Status(jobID)
+}
+
+// CancelGenerationJob cancels a queued or running generation job
+func (pcg *PCGManager) CancelGenerationJob(jobID string) error {
+	return pcg.jobs.Cancel(jobID)
+}
+
+// GetJobQueue returns the queue running the manager's generation jobs
+func (pcg *PCGManager) GetJobQueue() *JobQueue {
+	return pcg.jobs
+}
+
+// recordGeneration updates performance and quality metrics for a completed
+// generation and notifies registered observers.
+func (pcg *PCGManager) recordGeneration(contentType ContentType, content interface{}, duration time.Duration, err error) {
+	pcg.qualityMetrics.RecordContentGeneration(contentType, content, duration, err)
+
+	if err != nil {
+		pcg.metrics.RecordError(contentType)
+	} else {
+		pcg.metrics.RecordGeneration(contentType, duration)
 	}
 
-	pcg.world.Objects[item.ID] = item
+	pcg.mu.RLock()
+	observers := pcg.observers
+	pcg.mu.RUnlock()
 
-	// Update spatial index if available
-	if pcg.world.SpatialIndex != nil {
-		if err := pcg.world.SpatialIndex.Insert(item); err != nil {
-			pcg.logger.WithFields(logrus.Fields{
-				"item_id": item.ID,
-				"error":   err.Error(),
-			}).Warn("Failed to add item to spatial index")
-		}
+	for _, observer := range observers {
+		observer(contentType, duration, err)
 	}
+}
 
-	pcg.logger.WithFields(logrus.Fields{
-		"item_id":  item.ID,
-		"location": locationID,
-		"type":     item.Type,
-		"value":    item.Value,
-	}).Info("Integrated generated item into world")
+// ResetMetrics clears all generation metrics
+func (pcg *PCGManager) ResetMetrics() {
+	pcg.metrics.Reset()
+	pcg.qualityGate.Reset()
+	pcg.logger.Info("PCG generation metrics reset")
+}
 
-	return nil
+// ResetQualityMetrics clears all quality metrics
+func (pcg *PCGManager) ResetQualityMetrics() {
+	pcg.qualityMetrics = NewContentQualityMetrics()
+	pcg.logger.Info("PCG quality metrics reset")
 }
 
 // Helper methods for world state analysis
@@ -0,0 +1,32 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPCGManagerGenerationObserver(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	manager.InitializeWithSeed(1)
+
+	var observed []ContentType
+	var lastErr error
+	manager.AddGenerationObserver(func(contentType ContentType, duration time.Duration, err error) {
+		observed = append(observed, contentType)
+		lastErr = err
+	})
+
+	// No generators are registered, so generation fails but is still observed.
+	_, err := manager.GenerateTerrainForLevel(context.Background(), "level_1", 30, 30, BiomeCave, 3)
+	require.Error(t, err)
+
+	assert.Equal(t, []ContentType{ContentTypeTerrain}, observed)
+	assert.Error(t, lastErr)
+	assert.Equal(t, int64(1), manager.GetMetrics().GetErrorCount(ContentTypeTerrain))
+}
@@ -32,6 +32,8 @@ type ContentQualityMetrics struct {
 	qualityThresholds     *QualityThresholds
 	lastQualityAssessment time.Time
 	overallQualityScore   float64
+	lastBenchmark         *BenchmarkReport
+	pacingScores          map[string]float64 // Latest pacing score of each analysed dungeon or world
 }
 
 // VarietyMetrics tracks content uniqueness and diversity
@@ -414,6 +416,19 @@ func (cqm *ContentQualityMetrics) RecordContentAbandonment(contentType ContentTy
 	cqm.engagementMetrics.recordAbandonment(contentType, contentID, timeSpent)
 }
 
+// RecordPacing records the pacing score of a difficulty heatmap of the
+// content with contentID, replacing any earlier score for it. Their average
+// is reported as the "pacing" component score.
+func (cqm *ContentQualityMetrics) RecordPacing(contentID string, score float64) {
+	cqm.mu.Lock()
+	defer cqm.mu.Unlock()
+
+	if cqm.pacingScores == nil {
+		cqm.pacingScores = make(map[string]float64)
+	}
+	cqm.pacingScores[contentID] = score
+}
+
 // GenerateQualityReport creates a comprehensive quality assessment
 func (cqm *ContentQualityMetrics) GenerateQualityReport() *QualityReport {
 	cqm.mu.Lock()
@@ -442,6 +457,16 @@ func (cqm *ContentQualityMetrics) GenerateQualityReport() *QualityReport {
 	report.ComponentScores["engagement"] = engagementScore
 	report.ComponentScores["stability"] = stabilityScore
 
+	// Pacing is reported once content has been analysed, outside the
+	// weighted overall score
+	if len(cqm.pacingScores) > 0 {
+		total := 0.0
+		for _, score := range cqm.pacingScores {
+			total += score
+		}
+		report.ComponentScores["pacing"] = total / float64(len(cqm.pacingScores))
+	}
+
 	// Calculate overall score using weights
 	weights := cqm.qualityThresholds.QualityWeights
 	report.OverallScore = performanceScore*weights.Performance +
@@ -485,6 +510,14 @@ func (cqm *ContentQualityMetrics) GetPerformanceMetrics() *GenerationMetrics {
 	return cqm.performanceMetrics
 }
 
+// RecordBenchmark records the outcome of a benchmark run. Cases that
+// exceeded their budgets lower the performance score until the next run.
+func (cqm *ContentQualityMetrics) RecordBenchmark(report *BenchmarkReport) {
+	cqm.mu.Lock()
+	defer cqm.mu.Unlock()
+	cqm.lastBenchmark = report
+}
+
 // GetValidationMetrics returns the validation metrics instance
 func (cqm *ContentQualityMetrics) GetValidationMetrics() *ValidationMetrics {
 	return cqm.validationMetrics
@@ -495,6 +528,14 @@ func (cqm *ContentQualityMetrics) GetBalanceMetrics() *BalanceMetrics {
 	return cqm.balanceMetrics
 }
 
+// GetSatisfactionScore returns the average player rating recorded for a
+// content type, or 0 when no feedback has been received for it
+func (cqm *ContentQualityMetrics) GetSatisfactionScore(contentType ContentType) float64 {
+	cqm.engagementMetrics.mu.RLock()
+	defer cqm.engagementMetrics.mu.RUnlock()
+	return cqm.engagementMetrics.SatisfactionScores[contentType]
+}
+
 // calculatePerformanceScore computes a performance quality score
 func (cqm *ContentQualityMetrics) calculatePerformanceScore() float64 {
 	stats := cqm.performanceMetrics.GetStats()
@@ -525,6 +566,11 @@ func (cqm *ContentQualityMetrics) calculatePerformanceScore() float64 {
 		}
 	}
 
+	// Penalize for benchmark cases over budget
+	if bench := cqm.lastBenchmark; bench != nil && len(bench.Results) > 0 {
+		score -= 0.5 * float64(bench.FailedCases()) / float64(len(bench.Results))
+	}
+
 	// Reward for cache efficiency
 	cacheRatio := cqm.performanceMetrics.GetCacheHitRatio()
 	if cacheRatio > 80.0 {
@@ -678,6 +724,7 @@ func (cqm *ContentQualityMetrics) checkThresholdCompliance() map[string]bool {
 	// Check performance thresholds
 	status["error_rate"] = cqm.calculatePerformanceScore() >= 0.8
 	status["generation_time"] = true // Checked in performance score
+	status["performance_budgets"] = cqm.lastBenchmark == nil || cqm.lastBenchmark.Passed
 
 	// Check variety thresholds
 	status["uniqueness"] = cqm.calculateVarietyScore() >= cqm.qualityThresholds.MinUniquenessScore
@@ -718,6 +765,10 @@ func (cqm *ContentQualityMetrics) generateRecommendations(scores map[string]floa
 		recommendations = append(recommendations, "Review error handling and system reliability measures")
 	}
 
+	if score, exists := scores["pacing"]; exists && score < 0.7 {
+		recommendations = append(recommendations, "Smooth difficulty spikes between neighbouring levels and regions")
+	}
+
 	return recommendations
 }
 
@@ -0,0 +1,494 @@
+package pcg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Monster generation limits
+const (
+	// MaxMonsterGroupSize is the largest number of monsters generated at once
+	MaxMonsterGroupSize = 20
+
+	// minMonsterArmorClass is the best armor class a scaled monster reaches
+	minMonsterArmorClass = -10
+
+	// minMonsterTHAC0 is the best THAC0 on the monster attack table
+	minMonsterTHAC0 = 3
+)
+
+// MonsterAttack is one attack of a monster's attack routine
+type MonsterAttack struct {
+	Name   string `yaml:"name" json:"name"`     // Attack form, e.g. "bite"
+	Damage string `yaml:"damage" json:"damage"` // Damage dice expression, e.g. "1d8+1"
+}
+
+// MonsterStatBlock is a generated monster with the statistics combat needs
+type MonsterStatBlock struct {
+	ID               string          `yaml:"id" json:"id"`                                         // Unique monster identifier
+	Kind             string          `yaml:"kind" json:"kind"`                                     // Bestiary kind, e.g. "orc"
+	Name             string          `yaml:"name" json:"name"`                                     // Display name
+	Biome            BiomeType       `yaml:"biome" json:"biome"`                                   // Biome the monster was generated for
+	Difficulty       int             `yaml:"difficulty" json:"difficulty"`                         // Difficulty the monster was scaled to
+	HitDice          int             `yaml:"hit_dice" json:"hit_dice"`                             // Hit dice (d8 each)
+	HitPoints        int             `yaml:"hit_points" json:"hit_points"`                         // Rolled hit points
+	ArmorClass       int             `yaml:"armor_class" json:"armor_class"`                       // Descending armor class
+	THAC0            int             `yaml:"thac0" json:"thac0"`                                   // To hit armor class 0
+	Attacks          []MonsterAttack `yaml:"attacks" json:"attacks"`                               // Attacks per round
+	SpecialAbilities []string        `yaml:"special_abilities,omitempty" json:"special_abilities"` // Special attacks and defenses
+	TreasureType     string          `yaml:"treasure_type,omitempty" json:"treasure_type"`         // Treasure type letter, empty for none
+	Experience       int             `yaml:"experience" json:"experience"`                         // Experience award for defeating it
+}
+
+// MonsterParams provides monster-specific generation parameters
+type MonsterParams struct {
+	GenerationParams `yaml:",inline"`
+	Biome            BiomeType `yaml:"biome"` // Biome the monsters live in; empty means dungeon
+	Count            int       `yaml:"count"` // Number of monsters; 0 scales with difficulty
+	Kinds            []string  `yaml:"kinds"` // Bestiary kinds to choose from; empty uses the biome's
+}
+
+// monsterTemplate is a bestiary entry at its natural strength
+type monsterTemplate struct {
+	hitDice    int
+	armorClass int
+	attacks    []MonsterAttack
+	abilities  []string
+	treasure   string
+	biomes     []BiomeType
+}
+
+// bestiary holds the built-in monster kinds. Theme enemy types name these
+// kinds; kinds missing from the bestiary get a generic stat block.
+var bestiary = map[string]monsterTemplate{
+	"goblin": {
+		hitDice: 1, armorClass: 6, treasure: "C",
+		attacks: []MonsterAttack{{Name: "weapon", Damage: "1d6"}},
+		biomes:  []BiomeType{BiomeCave, BiomeDungeon, BiomeForest, BiomeMountain},
+	},
+	"orc": {
+		hitDice: 1, armorClass: 6, treasure: "C",
+		attacks: []MonsterAttack{{Name: "weapon", Damage: "1d8"}},
+		biomes:  []BiomeType{BiomeDungeon, BiomeMountain, BiomePlains, BiomeWasteland},
+	},
+	"bandit": {
+		hitDice: 1, armorClass: 7, treasure: "M",
+		attacks: []MonsterAttack{{Name: "weapon", Damage: "1d6"}},
+		biomes:  []BiomeType{BiomeForest, BiomePlains, BiomeUrban, BiomeCoastal, BiomeDesert},
+	},
+	"giant_rat": {
+		hitDice: 1, armorClass: 7, treasure: "C",
+		attacks:   []MonsterAttack{{Name: "bite", Damage: "1d3"}},
+		abilities: []string{"disease"},
+		biomes:    []BiomeType{BiomeDungeon, BiomeUrban, BiomeSwamp},
+	},
+	"skeleton": {
+		hitDice: 1, armorClass: 7,
+		attacks:   []MonsterAttack{{Name: "weapon", Damage: "1d6"}},
+		abilities: []string{"undead_immunities", "half_damage_edged"},
+		biomes:    []BiomeType{BiomeDungeon, BiomeWasteland},
+	},
+	"zombie": {
+		hitDice: 2, armorClass: 8,
+		attacks:   []MonsterAttack{{Name: "claw", Damage: "1d8"}},
+		abilities: []string{"undead_immunities"},
+		biomes:    []BiomeType{BiomeDungeon, BiomeSwamp, BiomeWasteland},
+	},
+	"wolf": {
+		hitDice: 2, armorClass: 7,
+		attacks: []MonsterAttack{{Name: "bite", Damage: "2d4"}},
+		biomes:  []BiomeType{BiomeForest, BiomePlains, BiomeMountain},
+	},
+	"lizard_man": {
+		hitDice: 2, armorClass: 5, treasure: "D",
+		attacks: []MonsterAttack{{Name: "claw", Damage: "1d2"}, {Name: "claw", Damage: "1d2"}, {Name: "bite", Damage: "1d6"}},
+		biomes:  []BiomeType{BiomeSwamp, BiomeCoastal},
+	},
+	"sprite": {
+		hitDice: 1, armorClass: 6, treasure: "C",
+		attacks:   []MonsterAttack{{Name: "sleep arrow", Damage: "1d4"}},
+		abilities: []string{"sleep", "invisibility"},
+		biomes:    []BiomeType{BiomeForest},
+	},
+	"gnoll": {
+		hitDice: 2, armorClass: 5, treasure: "D",
+		attacks: []MonsterAttack{{Name: "weapon", Damage: "2d4"}},
+		biomes:  []BiomeType{BiomePlains, BiomeDesert, BiomeWasteland},
+	},
+	"shadow": {
+		hitDice: 3, armorClass: 7, treasure: "F",
+		attacks:   []MonsterAttack{{Name: "touch", Damage: "1d4+1"}},
+		abilities: []string{"strength_drain", "undead_immunities", "magic_weapon_to_hit"},
+		biomes:    []BiomeType{BiomeDungeon, BiomeCave},
+	},
+	"automaton": {
+		hitDice: 3, armorClass: 4,
+		attacks:   []MonsterAttack{{Name: "blade", Damage: "1d6"}, {Name: "blade", Damage: "1d6"}},
+		abilities: []string{"construct_immunities"},
+		biomes:    []BiomeType{BiomeDungeon, BiomeUrban},
+	},
+	"sahuagin": {
+		hitDice: 2, armorClass: 5, treasure: "N",
+		attacks: []MonsterAttack{{Name: "trident", Damage: "1d8"}},
+		biomes:  []BiomeType{BiomeCoastal},
+	},
+	"bear": {
+		hitDice: 4, armorClass: 6,
+		attacks:   []MonsterAttack{{Name: "claw", Damage: "1d6"}, {Name: "claw", Damage: "1d6"}, {Name: "bite", Damage: "1d8"}},
+		abilities: []string{"hug"},
+		biomes:    []BiomeType{BiomeForest, BiomeMountain},
+	},
+	"spider": {
+		hitDice: 4, armorClass: 4, treasure: "C",
+		attacks:   []MonsterAttack{{Name: "bite", Damage: "2d4"}},
+		abilities: []string{"poison", "web"},
+		biomes:    []BiomeType{BiomeCave, BiomeForest, BiomeSwamp, BiomeDungeon},
+	},
+	"construct": {
+		hitDice: 4, armorClass: 5,
+		attacks:   []MonsterAttack{{Name: "slam", Damage: "1d10"}},
+		abilities: []string{"construct_immunities"},
+		biomes:    []BiomeType{BiomeDungeon, BiomeUrban},
+	},
+	"giant_scorpion": {
+		hitDice: 5, armorClass: 3, treasure: "D",
+		attacks:   []MonsterAttack{{Name: "pincer", Damage: "1d10"}, {Name: "pincer", Damage: "1d10"}, {Name: "sting", Damage: "1d4"}},
+		abilities: []string{"poison"},
+		biomes:    []BiomeType{BiomeDesert, BiomeCave},
+	},
+	"wraith": {
+		hitDice: 5, armorClass: 4, treasure: "E",
+		attacks:   []MonsterAttack{{Name: "touch", Damage: "1d6"}},
+		abilities: []string{"energy_drain", "undead_immunities", "magic_weapon_to_hit"},
+		biomes:    []BiomeType{BiomeDungeon, BiomeWasteland},
+	},
+	"troll": {
+		hitDice: 6, armorClass: 4, treasure: "Q",
+		attacks:   []MonsterAttack{{Name: "claw", Damage: "1d4+4"}, {Name: "claw", Damage: "1d4+4"}, {Name: "bite", Damage: "2d6"}},
+		abilities: []string{"regeneration"},
+		biomes:    []BiomeType{BiomeMountain, BiomeSwamp, BiomeCave},
+	},
+	"golem": {
+		hitDice: 8, armorClass: 5,
+		attacks:   []MonsterAttack{{Name: "fist", Damage: "2d8"}},
+		abilities: []string{"construct_immunities", "magic_immunity"},
+		biomes:    []BiomeType{BiomeDungeon},
+	},
+	"elemental": {
+		hitDice: 8, armorClass: 2,
+		attacks:   []MonsterAttack{{Name: "slam", Damage: "2d8"}},
+		abilities: []string{"magic_weapon_to_hit"},
+		biomes:    []BiomeType{BiomeMountain, BiomeDesert, BiomeCoastal},
+	},
+	"fire_elemental": {
+		hitDice: 8, armorClass: 2,
+		attacks:   []MonsterAttack{{Name: "burn", Damage: "3d8"}},
+		abilities: []string{"magic_weapon_to_hit", "ignite"},
+		biomes:    []BiomeType{BiomeDesert, BiomeWasteland},
+	},
+	"water_elemental": {
+		hitDice: 8, armorClass: 2,
+		attacks:   []MonsterAttack{{Name: "slam", Damage: "5d6"}},
+		abilities: []string{"magic_weapon_to_hit", "overturn_boats"},
+		biomes:    []BiomeType{BiomeCoastal, BiomeSwamp},
+	},
+	"earth_elemental": {
+		hitDice: 8, armorClass: 2,
+		attacks:   []MonsterAttack{{Name: "slam", Damage: "4d8"}},
+		abilities: []string{"magic_weapon_to_hit", "structure_damage"},
+		biomes:    []BiomeType{BiomeMountain, BiomeCave},
+	},
+	"wisp": {
+		hitDice: 9, armorClass: -8, treasure: "Z",
+		attacks:   []MonsterAttack{{Name: "shock", Damage: "2d8"}},
+		abilities: []string{"invisibility", "spell_immunity"},
+		biomes:    []BiomeType{BiomeSwamp, BiomeForest},
+	},
+	"dragon": {
+		hitDice: 10, armorClass: 0, treasure: "H",
+		attacks:   []MonsterAttack{{Name: "claw", Damage: "1d8"}, {Name: "claw", Damage: "1d8"}, {Name: "bite", Damage: "3d10"}},
+		abilities: []string{"breath_weapon", "fear_aura", "spellcasting"},
+		biomes:    []BiomeType{BiomeMountain, BiomeCave},
+	},
+	"lich": {
+		hitDice: 11, armorClass: 0, treasure: "A",
+		attacks:   []MonsterAttack{{Name: "touch", Damage: "1d10"}},
+		abilities: []string{"paralysis", "spellcasting", "undead_immunities", "magic_weapon_to_hit"},
+		biomes:    []BiomeType{BiomeDungeon},
+	},
+}
+
+// monsterExperience is the experience award by hit dice, and
+// monsterAbilityExperience the bonus per special ability
+var (
+	monsterExperience        = []int{0, 15, 35, 65, 120, 175, 270, 420, 650, 975, 1400, 2000, 3000}
+	monsterAbilityExperience = []int{0, 4, 8, 15, 25, 40, 75, 125, 175, 275, 400, 500, 600}
+)
+
+// MonsterKinds returns the sorted bestiary kinds living in biome, or every
+// kind when biome is empty
+func MonsterKinds(biome BiomeType) []string {
+	var kinds []string
+	for kind, template := range bestiary {
+		if biome == "" || slices.Contains(template.biomes, biome) {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// MonsterGenerator creates monster stat blocks from the bestiary. Monsters
+// are chosen from the kinds living in the requested biome, favouring those
+// whose natural strength suits the difficulty, and are then scaled up or
+// down to it: each step adds or removes hit dice, and stronger monsters
+// gain armor class and damage. Generation is deterministic for a seed.
+type MonsterGenerator struct {
+	version string
+	logger  *logrus.Logger
+}
+
+// NewMonsterGenerator creates a new monster generator instance
+func NewMonsterGenerator(logger *logrus.Logger) *MonsterGenerator {
+	if logger == nil {
+		logger = logrus.New()
+	}
+
+	return &MonsterGenerator{
+		version: "1.0.0",
+		logger:  logger,
+	}
+}
+
+// Generate creates a group of monsters from the "monster_params" constraint,
+// or from params alone for a dungeon group. Returns []*MonsterStatBlock.
+func (mg *MonsterGenerator) Generate(ctx context.Context, params GenerationParams) (interface{}, error) {
+	if err := mg.Validate(params); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	monsterParams, ok := params.Constraints["monster_params"].(MonsterParams)
+	if !ok {
+		monsterParams = MonsterParams{Biome: BiomeDungeon}
+	}
+	monsterParams.GenerationParams = params
+
+	start := time.Now()
+	monsters, err := mg.GenerateMonsters(ctx, monsterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	mg.logger.WithFields(logrus.Fields{
+		"biome":    monsterParams.Biome,
+		"monsters": len(monsters),
+		"duration": time.Since(start),
+	}).Info("monster generation completed")
+
+	return monsters, nil
+}
+
+// GenerateMonsters creates a group of monsters scaled to the difficulty of
+// params, which is clamped to the 1-20 range
+func (mg *MonsterGenerator) GenerateMonsters(ctx context.Context, params MonsterParams) ([]*MonsterStatBlock, error) {
+	biome := params.Biome
+	if biome == "" {
+		biome = BiomeDungeon
+	}
+	difficulty := min(max(params.Difficulty, MinDifficulty), MaxDifficulty)
+
+	count := params.Count
+	if count <= 0 {
+		count = 2 + difficulty/3
+	}
+	count = min(count, MaxMonsterGroupSize)
+
+	kinds := params.Kinds
+	if len(kinds) == 0 {
+		kinds = MonsterKinds(biome)
+	}
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("no monsters live in biome %s", biome)
+	}
+
+	rng := NewRNG(params.Seed, "monsters")
+	monsters := make([]*MonsterStatBlock, 0, count)
+	for i := 0; i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("monster generation cancelled: %w", err)
+		}
+
+		kind := selectMonsterKind(kinds, difficulty, rng)
+		monster := scaleMonster(kind, monsterTemplateFor(kind, difficulty), difficulty, rng)
+		monster.ID = fmt.Sprintf("monster_%d_%d", params.Seed, i+1)
+		monster.Biome = biome
+		monsters = append(monsters, monster)
+	}
+
+	mg.logger.WithFields(logrus.Fields{
+		"biome":      biome,
+		"difficulty": difficulty,
+		"count":      len(monsters),
+	}).Debug("generated monster group")
+
+	return monsters, nil
+}
+
+// GetType returns the content type for monster generation
+func (mg *MonsterGenerator) GetType() ContentType {
+	return ContentTypeMonsters
+}
+
+// GetVersion returns the generator version
+func (mg *MonsterGenerator) GetVersion() string {
+	return mg.version
+}
+
+// Validate checks if the provided parameters are valid for monster generation
+func (mg *MonsterGenerator) Validate(params GenerationParams) error {
+	if params.Difficulty < 1 || params.Difficulty > 20 {
+		return fmt.Errorf("difficulty must be between 1 and 20, got %d", params.Difficulty)
+	}
+
+	if constraint, exists := params.Constraints["monster_params"]; exists {
+		monsterParams, ok := constraint.(MonsterParams)
+		if !ok {
+			return fmt.Errorf("monster_params must be MonsterParams")
+		}
+		if monsterParams.Count < 0 || monsterParams.Count > MaxMonsterGroupSize {
+			return fmt.Errorf("monster count must be between 0 and %d, got %d", MaxMonsterGroupSize, monsterParams.Count)
+		}
+	}
+
+	return nil
+}
+
+// monsterTemplateFor returns the bestiary entry of kind, or a generic
+// fighter of natural strength matching difficulty for unknown kinds
+func monsterTemplateFor(kind string, difficulty int) monsterTemplate {
+	if template, exists := bestiary[kind]; exists {
+		return template
+	}
+	return monsterTemplate{
+		hitDice:    max(1, difficulty/2),
+		armorClass: 7 - difficulty/4,
+		attacks:    []MonsterAttack{{Name: "weapon", Damage: "1d8"}},
+		treasure:   "C",
+	}
+}
+
+// selectMonsterKind picks one of kinds, weighting each by how close its
+// natural strength (two difficulty levels per hit die) is to difficulty
+func selectMonsterKind(kinds []string, difficulty int, rng *rand.Rand) string {
+	weights := make([]int, len(kinds))
+	total := 0
+	for i, kind := range kinds {
+		distance := 2*monsterTemplateFor(kind, difficulty).hitDice - difficulty
+		if distance < 0 {
+			distance = -distance
+		}
+		weights[i] = max(1, 10-distance)
+		total += weights[i]
+	}
+
+	roll := rng.Intn(total)
+	for i, weight := range weights {
+		if roll < weight {
+			return kinds[i]
+		}
+		roll -= weight
+	}
+	return kinds[len(kinds)-1]
+}
+
+// scaleMonster builds the stat block of template scaled to difficulty.
+// Every three difficulty levels away from its natural strength add or
+// remove a hit die, down to half and up to double its hit dice. Each two
+// added hit dice improve armor class and damage by one.
+func scaleMonster(kind string, template monsterTemplate, difficulty int, rng *rand.Rand) *MonsterStatBlock {
+	extra := (difficulty - 2*template.hitDice) / 3
+	extra = min(max(extra, -template.hitDice/2), max(template.hitDice, 2))
+	hitDice := max(1, template.hitDice+extra)
+	bonus := max(extra/2, 0)
+
+	hitPoints := 0
+	for i := 0; i < hitDice; i++ {
+		hitPoints += 1 + rng.Intn(8)
+	}
+
+	attacks := make([]MonsterAttack, len(template.attacks))
+	for i, attack := range template.attacks {
+		attacks[i] = MonsterAttack{Name: attack.Name, Damage: addDamageBonus(attack.Damage, bonus)}
+	}
+
+	name := monsterDisplayName(kind)
+	if extra > 0 {
+		name = "Elite " + name
+	}
+
+	abilities := append([]string(nil), template.abilities...)
+	return &MonsterStatBlock{
+		Kind:             kind,
+		Name:             name,
+		Difficulty:       difficulty,
+		HitDice:          hitDice,
+		HitPoints:        hitPoints,
+		ArmorClass:       max(template.armorClass-bonus, minMonsterArmorClass),
+		THAC0:            monsterTHAC0(hitDice),
+		Attacks:          attacks,
+		SpecialAbilities: abilities,
+		TreasureType:     template.treasure,
+		Experience:       monsterXP(hitDice, len(abilities)),
+	}
+}
+
+// monsterTHAC0 follows the monster attack table: THAC0 improves by two
+// every two hit dice, from 19 at one hit die
+func monsterTHAC0(hitDice int) int {
+	return max(21-2*((hitDice+1)/2), minMonsterTHAC0)
+}
+
+// monsterXP returns the experience award for a monster of hitDice with
+// the given number of special abilities
+func monsterXP(hitDice, abilities int) int {
+	last := len(monsterExperience) - 1
+	if hitDice > last {
+		return monsterExperience[last] + (hitDice-last)*1000 + abilities*monsterAbilityExperience[last]
+	}
+	return monsterExperience[hitDice] + abilities*monsterAbilityExperience[hitDice]
+}
+
+// addDamageBonus adds bonus to the modifier of a dice expression such as
+// "1d4" or "1d4+4"
+func addDamageBonus(damage string, bonus int) string {
+	if bonus == 0 {
+		return damage
+	}
+	dice, modifier, found := strings.Cut(damage, "+")
+	total := bonus
+	if found {
+		var base int
+		fmt.Sscanf(modifier, "%d", &base)
+		total += base
+	}
+	return fmt.Sprintf("%s+%d", dice, total)
+}
+
+// monsterDisplayName turns a kind such as "fire_elemental" into
+// "Fire Elemental"
+func monsterDisplayName(kind string) string {
+	words := strings.Split(kind, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
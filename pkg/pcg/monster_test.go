@@ -0,0 +1,104 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateMonsters runs GenerateMonsters with a fresh generator
+func generateMonsters(t *testing.T, params MonsterParams) []*MonsterStatBlock {
+	t.Helper()
+	monsters, err := NewMonsterGenerator(nil).GenerateMonsters(context.Background(), params)
+	require.NoError(t, err)
+	return monsters
+}
+
+func TestMonsterGenerator_BiomeAndDeterminism(t *testing.T) {
+	params := MonsterParams{
+		GenerationParams: GenerationParams{Seed: 99, Difficulty: 6},
+		Biome:            BiomeSwamp,
+		Count:            8,
+	}
+	monsters := generateMonsters(t, params)
+	require.Len(t, monsters, 8)
+
+	swampKinds := MonsterKinds(BiomeSwamp)
+	for _, monster := range monsters {
+		assert.Contains(t, swampKinds, monster.Kind)
+		assert.Equal(t, BiomeSwamp, monster.Biome)
+		assert.Positive(t, monster.HitPoints)
+		assert.GreaterOrEqual(t, monster.HitPoints, monster.HitDice)
+		assert.LessOrEqual(t, monster.HitPoints, 8*monster.HitDice)
+		assert.NotEmpty(t, monster.Attacks)
+		assert.Equal(t, monsterTHAC0(monster.HitDice), monster.THAC0)
+	}
+
+	assert.Equal(t, monsters, generateMonsters(t, params), "same seed, same monsters")
+}
+
+func TestMonsterGenerator_ScalesWithDifficulty(t *testing.T) {
+	kinds := []string{"goblin"}
+	weak := generateMonsters(t, MonsterParams{GenerationParams: GenerationParams{Seed: 7, Difficulty: 2}, Count: 1, Kinds: kinds})[0]
+	strong := generateMonsters(t, MonsterParams{GenerationParams: GenerationParams{Seed: 7, Difficulty: 20}, Count: 1, Kinds: kinds})[0]
+
+	assert.Equal(t, "Goblin", weak.Name)
+	assert.Equal(t, 1, weak.HitDice)
+	assert.Equal(t, 19, weak.THAC0)
+	assert.Equal(t, "1d6", weak.Attacks[0].Damage)
+
+	assert.Equal(t, "Elite Goblin", strong.Name)
+	assert.Equal(t, 3, strong.HitDice, "goblins at most double their hit dice (minimum +2)")
+	assert.Equal(t, 17, strong.THAC0)
+	assert.Equal(t, 5, strong.ArmorClass)
+	assert.Equal(t, "1d6+1", strong.Attacks[0].Damage)
+	assert.Greater(t, strong.Experience, weak.Experience)
+
+	lich := generateMonsters(t, MonsterParams{GenerationParams: GenerationParams{Seed: 7, Difficulty: 1}, Count: 1, Kinds: []string{"lich"}})[0]
+	assert.Equal(t, 6, lich.HitDice, "monsters shrink to half their hit dice at most")
+	assert.Equal(t, "A", lich.TreasureType)
+	assert.Contains(t, lich.SpecialAbilities, "spellcasting")
+}
+
+func TestMonsterGenerator_UnknownKind(t *testing.T) {
+	monster := generateMonsters(t, MonsterParams{GenerationParams: GenerationParams{Seed: 3, Difficulty: 10}, Count: 1, Kinds: []string{"war_machine"}})[0]
+	assert.Equal(t, "War Machine", monster.Name)
+	assert.Equal(t, 5, monster.HitDice)
+}
+
+func TestMonsterGenerator_GenerateAndValidate(t *testing.T) {
+	generator := NewMonsterGenerator(nil)
+	assert.Equal(t, ContentTypeMonsters, generator.GetType())
+
+	result, err := generator.Generate(context.Background(), GenerationParams{Seed: 5, Difficulty: 9})
+	require.NoError(t, err)
+	monsters := result.([]*MonsterStatBlock)
+	assert.Len(t, monsters, 5)
+	assert.Equal(t, BiomeDungeon, monsters[0].Biome)
+
+	_, err = generator.Generate(context.Background(), GenerationParams{Seed: 5, Difficulty: 0})
+	assert.Error(t, err)
+
+	_, err = generator.Generate(context.Background(), GenerationParams{
+		Seed:        5,
+		Difficulty:  5,
+		Constraints: map[string]interface{}{"monster_params": MonsterParams{Count: MaxMonsterGroupSize + 1}},
+	})
+	assert.Error(t, err)
+}
+
+func TestMonsterKinds(t *testing.T) {
+	assert.Contains(t, MonsterKinds(BiomeDesert), "giant_scorpion")
+	assert.NotContains(t, MonsterKinds(BiomeDesert), "lich")
+	assert.Len(t, MonsterKinds(""), len(bestiary))
+
+	// Every theme's enemy types have bestiary entries
+	for _, theme := range Themes().Types() {
+		def, _ := Themes().Get(theme)
+		for _, enemy := range def.EnemyTypes {
+			assert.Contains(t, bestiary, enemy, "theme %s", theme)
+		}
+	}
+}
@@ -211,7 +211,7 @@ func (ng *NarrativeGenerator) Generate(ctx context.Context, params GenerationPar
 	}
 
 	// Initialize RNG with seed for deterministic generation
-	ng.rng = rand.New(rand.NewSource(params.Seed))
+	ng.rng = NewRNG(params.Seed, "narrative")
 
 	ng.logger.WithFields(logrus.Fields{
 		"narrative_type": narrativeParams.NarrativeType,
@@ -0,0 +1,361 @@
+package pcg
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"goldbox-rpg/pkg/game"
+)
+
+// PersonalityAxis names a personality trait scored from 0 to 1
+type PersonalityAxis string
+
+const (
+	AxisBravery PersonalityAxis = "bravery" // Cowardly at 0, brave at 1
+	AxisGreed   PersonalityAxis = "greed"   // Generous at 0, greedy at 1
+	AxisHonesty PersonalityAxis = "honesty" // Deceitful at 0, honest at 1
+)
+
+// personalityAxes lists the axes in the order they are rolled
+var personalityAxes = []PersonalityAxis{AxisBravery, AxisGreed, AxisHonesty}
+
+// axisTraits holds the trait names of the low and high ends of every axis
+var axisTraits = map[PersonalityAxis][2]string{
+	AxisBravery: {"cowardly", "brave"},
+	AxisGreed:   {"generous", "greedy"},
+	AxisHonesty: {"deceitful", "honest"},
+}
+
+// TraitDistribution is the spread of scores on a personality axis. Scores
+// cluster around Mean and range up to Spread either side of it.
+type TraitDistribution struct {
+	Mean   float64 `yaml:"mean"`
+	Spread float64 `yaml:"spread"`
+}
+
+// VoiceStyle is a way of speaking the dialogue generator gives an NPC
+type VoiceStyle struct {
+	Formality     string   // SpeechPattern formality
+	Vocabulary    string   // SpeechPattern vocabulary
+	Interjections []string // Opening remarks added to lines of dialogue
+	Mannerisms    []string // Speech habits
+}
+
+// voiceStyles holds every voice style by name
+var voiceStyles = map[string]VoiceStyle{
+	"courtly":    {"formal", "complex", []string{"If it please you.", "Well met indeed."}, []string{"bows slightly", "chooses every word"}},
+	"plain":      {"casual", "simple", []string{"Well now.", "Right."}, []string{"speaks plainly"}},
+	"gruff":      {"crude", "simple", []string{"Bah.", "Hmph."}, []string{"speaks loudly", "grunts between words"}},
+	"archaic":    {"archaic", "poetic", []string{"Hark!", "Verily."}, []string{"uses archaic terms"}},
+	"streetwise": {"crude", "moderate", []string{"Listen here.", "Keep this quiet."}, []string{"whispers often", "glances around"}},
+	"scholarly":  {"formal", "technical", []string{"Fascinating.", "As it happens."}, []string{"pauses to think", "cites old books"}},
+	"zealous":    {"pompous", "poetic", []string{"Blessed be.", "The gods are watching."}, []string{"quotes scripture"}},
+}
+
+// PersonalityTemplate sets the trait distributions and voice styles of
+// generated personalities. Voices maps voice style names to their relative
+// chance of being chosen.
+type PersonalityTemplate struct {
+	Traits map[PersonalityAxis]TraitDistribution `yaml:"traits"`
+	Voices map[string]int                        `yaml:"voices"`
+}
+
+// Validate checks the template's distributions lie within 0-1 and its
+// voices are known styles with non-negative weights
+func (t PersonalityTemplate) Validate() error {
+	for axis, dist := range t.Traits {
+		if _, known := axisTraits[axis]; !known {
+			return fmt.Errorf("unknown personality axis %q", axis)
+		}
+		if dist.Mean < 0 || dist.Mean > 1 || dist.Spread < 0 || dist.Spread > 1 {
+			return fmt.Errorf("%s distribution must have a mean and spread within 0-1, got %.2f and %.2f", axis, dist.Mean, dist.Spread)
+		}
+	}
+	for voice, weight := range t.Voices {
+		if _, known := voiceStyles[voice]; !known {
+			return fmt.Errorf("unknown voice style %q", voice)
+		}
+		if weight < 0 {
+			return fmt.Errorf("voice style %q has negative weight %d", voice, weight)
+		}
+	}
+	return nil
+}
+
+// overlay returns base with the axes and voices t sets replacing its own.
+// Voices are replaced as a whole.
+func (t PersonalityTemplate) overlay(base PersonalityTemplate) PersonalityTemplate {
+	merged := PersonalityTemplate{Traits: make(map[PersonalityAxis]TraitDistribution), Voices: base.Voices}
+	for axis, dist := range base.Traits {
+		merged.Traits[axis] = dist
+	}
+	for axis, dist := range t.Traits {
+		merged.Traits[axis] = dist
+	}
+	if len(t.Voices) > 0 {
+		merged.Voices = t.Voices
+	}
+	return merged
+}
+
+// DefaultPersonalityTemplate gives every axis an even spread and favors
+// plain speech
+var DefaultPersonalityTemplate = PersonalityTemplate{
+	Traits: map[PersonalityAxis]TraitDistribution{
+		AxisBravery: {Mean: 0.5, Spread: 0.4},
+		AxisGreed:   {Mean: 0.5, Spread: 0.4},
+		AxisHonesty: {Mean: 0.5, Spread: 0.4},
+	},
+	Voices: map[string]int{"plain": 4, "gruff": 2, "courtly": 1, "streetwise": 1},
+}
+
+// genrePersonalityTemplates holds how every genre variant shifts the
+// default template. Grimdark folk are greedy and rarely honest, high magic
+// ones learned and long-winded.
+var genrePersonalityTemplates = map[GenreType]PersonalityTemplate{
+	GenreClassicFantasy: {
+		Traits: map[PersonalityAxis]TraitDistribution{AxisHonesty: {Mean: 0.6, Spread: 0.35}},
+	},
+	GenreGrimdark: {
+		Traits: map[PersonalityAxis]TraitDistribution{
+			AxisGreed:   {Mean: 0.65, Spread: 0.3},
+			AxisHonesty: {Mean: 0.3, Spread: 0.3},
+		},
+		Voices: map[string]int{"gruff": 4, "streetwise": 3, "plain": 2},
+	},
+	GenreHighMagic: {
+		Voices: map[string]int{"scholarly": 3, "courtly": 3, "archaic": 2, "plain": 1},
+	},
+	GenreLowFantasy: {
+		Traits: map[PersonalityAxis]TraitDistribution{AxisBravery: {Mean: 0.45, Spread: 0.3}},
+		Voices: map[string]int{"plain": 5, "gruff": 3},
+	},
+}
+
+// culturePersonalityTemplates holds the template of every faction culture,
+// by faction type
+var culturePersonalityTemplates = map[string]PersonalityTemplate{
+	string(FactionTypeMilitary): {
+		Traits: map[PersonalityAxis]TraitDistribution{
+			AxisBravery: {Mean: 0.75, Spread: 0.2},
+			AxisHonesty: {Mean: 0.6, Spread: 0.3},
+		},
+		Voices: map[string]int{"gruff": 3, "courtly": 1},
+	},
+	string(FactionTypeEconomic): {
+		Traits: map[PersonalityAxis]TraitDistribution{AxisGreed: {Mean: 0.7, Spread: 0.25}},
+		Voices: map[string]int{"plain": 2, "courtly": 2},
+	},
+	string(FactionTypeReligious): {
+		Traits: map[PersonalityAxis]TraitDistribution{
+			AxisGreed:   {Mean: 0.3, Spread: 0.3},
+			AxisHonesty: {Mean: 0.75, Spread: 0.2},
+		},
+		Voices: map[string]int{"zealous": 3, "archaic": 1},
+	},
+	string(FactionTypeCriminal): {
+		Traits: map[PersonalityAxis]TraitDistribution{
+			AxisGreed:   {Mean: 0.75, Spread: 0.2},
+			AxisHonesty: {Mean: 0.2, Spread: 0.2},
+		},
+		Voices: map[string]int{"streetwise": 4, "gruff": 1},
+	},
+	string(FactionTypeScholarly): {
+		Traits: map[PersonalityAxis]TraitDistribution{AxisBravery: {Mean: 0.35, Spread: 0.25}},
+		Voices: map[string]int{"scholarly": 4, "archaic": 1},
+	},
+	string(FactionTypePolitical): {
+		Traits: map[PersonalityAxis]TraitDistribution{AxisHonesty: {Mean: 0.35, Spread: 0.3}},
+		Voices: map[string]int{"courtly": 4},
+	},
+	string(FactionTypeMercenary): {
+		Traits: map[PersonalityAxis]TraitDistribution{
+			AxisBravery: {Mean: 0.65, Spread: 0.25},
+			AxisGreed:   {Mean: 0.75, Spread: 0.2},
+		},
+		Voices: map[string]int{"gruff": 3, "streetwise": 1},
+	},
+	string(FactionTypeMagical): {
+		Voices: map[string]int{"scholarly": 2, "archaic": 2},
+	},
+}
+
+// PersonalityGenerator rolls NPC personalities from templates: scores on
+// the bravery, greed and honesty axes, a voice style, and the behavior
+// modifiers the AI acts on. The default template is shifted first by the
+// genre variant's template and then by the faction culture's, each
+// replacing the distributions and voices it sets.
+//
+// PersonalityGenerator is safe for concurrent use.
+type PersonalityGenerator struct {
+	mu       sync.RWMutex
+	base     PersonalityTemplate
+	genres   map[GenreType]PersonalityTemplate
+	cultures map[string]PersonalityTemplate
+}
+
+// NewPersonalityGenerator creates a personality generator with the built-in
+// genre and faction culture templates
+func NewPersonalityGenerator() *PersonalityGenerator {
+	pg := &PersonalityGenerator{
+		base:     DefaultPersonalityTemplate,
+		genres:   make(map[GenreType]PersonalityTemplate, len(genrePersonalityTemplates)),
+		cultures: make(map[string]PersonalityTemplate, len(culturePersonalityTemplates)),
+	}
+	for genre, template := range genrePersonalityTemplates {
+		pg.genres[genre] = template
+	}
+	for culture, template := range culturePersonalityTemplates {
+		pg.cultures[culture] = template
+	}
+	return pg
+}
+
+// SetGenreTemplate sets the template of a genre variant
+func (pg *PersonalityGenerator) SetGenreTemplate(genre GenreType, template PersonalityTemplate) error {
+	if err := template.Validate(); err != nil {
+		return fmt.Errorf("invalid template for genre %s: %w", genre, err)
+	}
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	pg.genres[genre] = template
+	return nil
+}
+
+// SetCultureTemplate sets the template of a faction culture, named by a
+// faction type or a faction's own ID
+func (pg *PersonalityGenerator) SetCultureTemplate(culture string, template PersonalityTemplate) error {
+	if err := template.Validate(); err != nil {
+		return fmt.Errorf("invalid template for culture %s: %w", culture, err)
+	}
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	pg.cultures[culture] = template
+	return nil
+}
+
+// Template returns the template personalities of a genre and culture are
+// rolled from. Unknown genres and cultures leave the default unchanged.
+func (pg *PersonalityGenerator) Template(genre GenreType, culture string) PersonalityTemplate {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+	template := PersonalityTemplate{}.overlay(pg.base)
+	if shift, ok := pg.genres[genre]; ok {
+		template = shift.overlay(template)
+	}
+	if shift, ok := pg.cultures[culture]; ok {
+		template = shift.overlay(template)
+	}
+	return template
+}
+
+// Generate rolls a personality for an NPC of a genre and culture. The
+// profile has a trait for every axis, strongest first, the voice style's
+// speech pattern and the behavior modifiers derived from the scores. The
+// same seed, genre and culture always yield the same personality.
+func (pg *PersonalityGenerator) Generate(seed int64, genre GenreType, culture string) *PersonalityProfile {
+	template := pg.Template(genre, culture)
+	rng := NewRNG(seed, "personality")
+
+	scores := make(map[PersonalityAxis]float64, len(personalityAxes))
+	traits := make([]PersonalityTrait, 0, len(personalityAxes))
+	for _, axis := range personalityAxes {
+		dist := template.Traits[axis]
+		// The difference of two uniform rolls clusters scores at the mean
+		score := math.Max(0, math.Min(1, dist.Mean+(rng.Float64()-rng.Float64())*dist.Spread))
+		scores[axis] = score
+		traits = append(traits, axisTrait(axis, score))
+	}
+	sort.SliceStable(traits, func(i, j int) bool { return traits[i].Intensity > traits[j].Intensity })
+
+	voice := pickVoice(template.Voices, rng.Intn)
+	style := voiceStyles[voice]
+	temperament := "stoic"
+	switch {
+	case scores[AxisBravery] >= 0.75:
+		temperament = "bold"
+	case scores[AxisBravery] <= 0.25:
+		temperament = "cautious"
+	}
+
+	return &PersonalityProfile{
+		Traits:      traits,
+		Temperament: temperament,
+		Scores:      scores,
+		Modifiers:   BehaviorModifiersFor(scores),
+		Speech: SpeechPattern{
+			Formality:  style.Formality,
+			Vocabulary: style.Vocabulary,
+			Accent:     "none",
+			Mannerisms: append([]string(nil), style.Mannerisms...),
+			Diction:    voice,
+		},
+	}
+}
+
+// BehaviorModifiersFor derives the AI's behavior modifiers from personality
+// scores. Cowards retreat sooner, down to half their hit points, and pick
+// on the weakest opponent; greedy or dishonest NPCs take bribes.
+func BehaviorModifiersFor(scores map[PersonalityAxis]float64) *game.BehaviorModifiers {
+	bravery, greed, honesty := scores[AxisBravery], scores[AxisGreed], scores[AxisHonesty]
+	return &game.BehaviorModifiers{
+		Bravery:       bravery,
+		Greed:         greed,
+		Honesty:       honesty,
+		RetreatHealth: 0.5 * (1 - bravery),
+		TargetWeakest: bravery < 0.4,
+		Bribable:      greed >= 0.6 || honesty < 0.3,
+	}
+}
+
+// axisTrait names the end of the axis a score leans to. Intensity grows
+// from 0.3 at the middle of the axis to 1 at either end.
+func axisTrait(axis PersonalityAxis, score float64) PersonalityTrait {
+	name := axisTraits[axis][0]
+	if score >= 0.5 {
+		name = axisTraits[axis][1]
+	}
+	return PersonalityTrait{
+		Name:        name,
+		Intensity:   0.3 + 0.7*math.Abs(score-0.5)*2,
+		Description: fmt.Sprintf("Character displays %s behavior", name),
+	}
+}
+
+// pickVoice chooses a voice style by weight, plain if there is none
+func pickVoice(weights map[string]int, intn func(int) int) string {
+	voices := make([]string, 0, len(weights))
+	total := 0
+	for voice, weight := range weights {
+		if weight > 0 {
+			voices = append(voices, voice)
+			total += weight
+		}
+	}
+	if total == 0 {
+		return "plain"
+	}
+	// Walk the weights in a fixed order so a seed always picks the same voice
+	sort.Strings(voices)
+
+	roll := intn(total)
+	for _, voice := range voices {
+		roll -= weights[voice]
+		if roll < 0 {
+			return voice
+		}
+	}
+	return voices[len(voices)-1]
+}
+
+// voiceInterjection returns an opening remark of a voice style, or "" for
+// an unknown style
+func voiceInterjection(voice string, intn func(int) int) string {
+	style, ok := voiceStyles[voice]
+	if !ok || len(style.Interjections) == 0 {
+		return ""
+	}
+	return style.Interjections[intn(len(style.Interjections))]
+}
@@ -0,0 +1,122 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meanScore returns the average score on axis of personalities rolled for
+// a genre and culture
+func meanScore(pg *PersonalityGenerator, genre GenreType, culture string, axis PersonalityAxis) float64 {
+	const rolls = 200
+	total := 0.0
+	for seed := int64(1); seed <= rolls; seed++ {
+		total += pg.Generate(seed, genre, culture).Scores[axis]
+	}
+	return total / rolls
+}
+
+func TestPersonalityGenerator_Generate(t *testing.T) {
+	pg := NewPersonalityGenerator()
+	profile := pg.Generate(42, GenreClassicFantasy, "")
+
+	require.Len(t, profile.Traits, 3)
+	for i, trait := range profile.Traits {
+		assert.GreaterOrEqual(t, trait.Intensity, 0.3)
+		assert.LessOrEqual(t, trait.Intensity, 1.0)
+		if i > 0 {
+			assert.LessOrEqual(t, trait.Intensity, profile.Traits[i-1].Intensity, "strongest traits first")
+		}
+	}
+	for _, axis := range personalityAxes {
+		assert.Contains(t, profile.Scores, axis)
+	}
+	assert.Contains(t, voiceStyles, profile.Speech.Diction)
+	assert.Equal(t, voiceStyles[profile.Speech.Diction].Formality, profile.Speech.Formality)
+	require.NotNil(t, profile.Modifiers)
+	assert.Equal(t, profile.Scores[AxisBravery], profile.Modifiers.Bravery)
+
+	assert.Equal(t, profile, pg.Generate(42, GenreClassicFantasy, ""), "the same seed rolls the same personality")
+}
+
+func TestPersonalityGenerator_GenreAndCulture(t *testing.T) {
+	pg := NewPersonalityGenerator()
+
+	assert.Less(t, meanScore(pg, GenreGrimdark, "", AxisHonesty), meanScore(pg, GenreClassicFantasy, "", AxisHonesty),
+		"grimdark folk are less honest")
+	assert.Greater(t, meanScore(pg, GenreClassicFantasy, string(FactionTypeMilitary), AxisBravery), 0.65,
+		"soldiers are brave")
+	assert.Less(t, meanScore(pg, GenreClassicFantasy, string(FactionTypeCriminal), AxisHonesty), 0.3,
+		"the culture outweighs the genre")
+
+	for seed := int64(1); seed <= 20; seed++ {
+		assert.Contains(t, []string{"streetwise", "gruff"}, pg.Generate(seed, GenreHighMagic, string(FactionTypeCriminal)).Speech.Diction,
+			"criminals speak like criminals, learned world or not")
+	}
+}
+
+func TestPersonalityGenerator_SetTemplates(t *testing.T) {
+	pg := NewPersonalityGenerator()
+	require.NoError(t, pg.SetCultureTemplate("iron_legion", PersonalityTemplate{
+		Traits: map[PersonalityAxis]TraitDistribution{AxisBravery: {Mean: 1}},
+		Voices: map[string]int{"courtly": 1},
+	}))
+
+	profile := pg.Generate(7, GenreGrimdark, "iron_legion")
+	assert.Equal(t, 1.0, profile.Scores[AxisBravery])
+	assert.Equal(t, "bold", profile.Temperament)
+	assert.Equal(t, "courtly", profile.Speech.Diction)
+	assert.Zero(t, profile.Modifiers.RetreatHealth, "the fearless never retreat")
+	assert.Equal(t, genrePersonalityTemplates[GenreGrimdark].Traits[AxisGreed], pg.Template(GenreGrimdark, "iron_legion").Traits[AxisGreed],
+		"axes the culture leaves alone keep the genre's distribution")
+
+	assert.Error(t, pg.SetGenreTemplate(GenreGrimdark, PersonalityTemplate{
+		Traits: map[PersonalityAxis]TraitDistribution{AxisGreed: {Mean: 1.5}},
+	}))
+	assert.Error(t, pg.SetCultureTemplate("bards", PersonalityTemplate{Voices: map[string]int{"sung": 1}}))
+}
+
+func TestBehaviorModifiersFor(t *testing.T) {
+	coward := BehaviorModifiersFor(map[PersonalityAxis]float64{AxisBravery: 0.1, AxisGreed: 0.2, AxisHonesty: 0.8})
+	assert.InDelta(t, 0.45, coward.RetreatHealth, 1e-9)
+	assert.True(t, coward.TargetWeakest)
+	assert.False(t, coward.Bribable)
+
+	crook := BehaviorModifiersFor(map[PersonalityAxis]float64{AxisBravery: 0.6, AxisGreed: 0.4, AxisHonesty: 0.1})
+	assert.False(t, crook.TargetWeakest)
+	assert.True(t, crook.Bribable)
+}
+
+func TestNPCGenerator_PersonalityFromCulture(t *testing.T) {
+	gen := NewNPCGenerator(nil)
+	params := CharacterParams{
+		GenerationParams: GenerationParams{
+			Seed:        99,
+			Constraints: map[string]interface{}{"genre": string(GenreGrimdark)},
+		},
+		CharacterType:    CharacterTypeRogue,
+		PersonalityDepth: 2,
+		MotivationCount:  1,
+		UniqueTraits:     5,
+		Faction:          string(FactionTypeCriminal),
+	}
+
+	npc, err := gen.GenerateNPC(context.Background(), CharacterTypeRogue, params)
+	require.NoError(t, err)
+	personality, err := gen.GeneratePersonality(context.Background(), &npc.Character, params)
+	require.NoError(t, err)
+
+	require.Len(t, personality.Traits, 5)
+	assert.Contains(t, []string{"streetwise", "gruff"}, personality.Speech.Diction)
+	assert.Equal(t, personality.Modifiers, npc.Modifiers, "the NPC carries the modifiers of its personality")
+	names := make(map[string]int)
+	for _, trait := range personality.Traits {
+		names[trait.Name]++
+	}
+	for _, pair := range axisTraits {
+		assert.Equal(t, 1, names[pair[0]]+names[pair[1]], "one trait for each axis")
+	}
+}
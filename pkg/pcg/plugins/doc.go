@@ -0,0 +1,49 @@
+// Package plugins lets external programs provide content generators to the
+// PCG registry without changes to the engine.
+//
+// A plugin is an executable that speaks a line-based JSON protocol over its
+// stdin and stdout. The host writes one Request per line and the plugin
+// answers each with one Response carrying the same ID. Anything the plugin
+// writes to stderr is passed through to the server's stderr.
+//
+// # Handshake
+//
+// The first request is always "handshake". The plugin answers with a
+// Handshake naming itself and declaring its generators: for each one its
+// registry name, content type, version, timeout and the schema of the
+// constraints it accepts:
+//
+//	{"protocol_version": 1, "name": "bounties", "version": "1.0.0",
+//	 "generators": [{"name": "bounty", "content_type": "quests", "version": "1.0.0",
+//	   "timeout_ms": 2000,
+//	   "constraints": {"target": {"type": "string", "required": true, "enum": ["orc", "troll"]}}}]}
+//
+// A plugin that speaks another protocol version, declares no generators or
+// declares a malformed schema is not loaded.
+//
+// # Generation
+//
+// Load starts a plugin and Register adds its generators to a pcg.Registry,
+// where they are used like built-in generators. Before a request is sent,
+// Validate checks the difficulty and the constraints against the declared
+// schema. Generate sends the seed, difficulty, player level, constraints
+// and metadata; the world state stays in the server. The plugin's result is
+// decoded into the type the built-in generators of its content type
+// return, such as *game.Quest for quests or []*game.Item for items.
+//
+// # Timeouts
+//
+// The host enforces the timeouts. Each generation may take the timeout its
+// generator declared, capped by Options.MaxTimeout, or Options.Timeout if
+// it declared none, and never longer than GenerationParams.Timeout. A
+// plugin that does not answer in time is killed and the call fails with
+// ErrPluginTimeout; the next call restarts it and shakes hands again.
+// Calls to one plugin are sent one at a time.
+//
+// # Writing a Plugin
+//
+// Plugins written in Go can use Serve, which handles the protocol and
+// dispatches generate requests to a function per generator. Plugins in
+// other languages implement the three methods: handshake, generate and
+// shutdown.
+package plugins
@@ -0,0 +1,442 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default limits of plugin calls.
+const (
+	DefaultHandshakeTimeout = 5 * time.Second  // Time a plugin has to start and shake hands
+	DefaultTimeout          = 10 * time.Second // Generation time of generators without a timeout of their own
+	DefaultMaxTimeout       = 30 * time.Second // Longest generation time any generator may declare
+)
+
+// ErrPluginTimeout is returned when a plugin does not answer in time. The
+// plugin process is killed and restarted on the next call.
+var ErrPluginTimeout = errors.New("plugin call timed out")
+
+// Options are the limits the host enforces on a plugin.
+//
+// Fields:
+//   - Args: Command line arguments of the plugin executable
+//   - HandshakeTimeout: Time to start and complete the handshake
+//   - Timeout: Generation time of generators that declare none
+//   - MaxTimeout: Cap on the generation time a generator may declare
+//   - Logger: Receives the plugin's lifecycle logs
+type Options struct {
+	Args             []string
+	HandshakeTimeout time.Duration
+	Timeout          time.Duration
+	MaxTimeout       time.Duration
+	Logger           *logrus.Logger
+}
+
+// withDefaults fills in unset limits.
+func (o Options) withDefaults() Options {
+	if o.HandshakeTimeout <= 0 {
+		o.HandshakeTimeout = DefaultHandshakeTimeout
+	}
+	if o.MaxTimeout <= 0 {
+		o.MaxTimeout = DefaultMaxTimeout
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	o.Timeout = min(o.Timeout, o.MaxTimeout)
+	if o.Logger == nil {
+		o.Logger = logrus.StandardLogger()
+	}
+	return o
+}
+
+// Plugin is an external generator process speaking the plugin protocol
+// over its stdin and stdout: one JSON request or response per line. Calls
+// are sent one at a time. A plugin that times out or exits is restarted,
+// and shakes hands again, on the next call.
+type Plugin struct {
+	path   string
+	opts   Options
+	logger *logrus.Entry
+
+	mu        sync.Mutex
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan readResult
+	stopped   chan struct{}
+	nextID    uint64
+	handshake Handshake
+}
+
+// readResult is one line read from a plugin's stdout.
+type readResult struct {
+	line []byte
+	err  error
+}
+
+// Load starts the plugin executable at path and completes the handshake.
+//
+// Parameters:
+//   - ctx: Bounds the start and handshake along with opts.HandshakeTimeout
+//   - path: The plugin executable
+//   - opts: Limits enforced on the plugin
+//
+// Returns:
+//   - *Plugin: The running plugin
+//   - error: If the plugin cannot start or its handshake is invalid
+func Load(ctx context.Context, path string, opts Options) (*Plugin, error) {
+	opts = opts.withDefaults()
+	p := &Plugin{
+		path:   path,
+		opts:   opts,
+		logger: opts.Logger.WithFields(logrus.Fields{"component": "pcg_plugin", "path": path}),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.startLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"plugin":     p.handshake.Name,
+		"version":    p.handshake.Version,
+		"generators": len(p.handshake.Generators),
+	}).Info("loaded PCG plugin")
+	return p, nil
+}
+
+// Name returns the name the plugin declared.
+func (p *Plugin) Name() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.handshake.Name
+}
+
+// Generators returns a pcg.Generator for each generator the plugin
+// declared. Their timeouts are capped by the host's MaxTimeout.
+func (p *Plugin) Generators() []*Generator {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	generators := make([]*Generator, 0, len(p.handshake.Generators))
+	for _, spec := range p.handshake.Generators {
+		timeout := p.opts.Timeout
+		if declared := spec.Timeout(); declared > 0 {
+			timeout = min(declared, p.opts.MaxTimeout)
+		}
+		generators = append(generators, &Generator{plugin: p, spec: spec, timeout: timeout})
+	}
+	return generators
+}
+
+// Register adds the plugin's generators to registry. If one cannot be
+// registered, those already added are removed again.
+func (p *Plugin) Register(registry *pcg.Registry) error {
+	var registered []*Generator
+	for _, generator := range p.Generators() {
+		if err := registry.RegisterGenerator(generator.spec.Name, generator); err != nil {
+			for _, done := range registered {
+				_ = registry.UnregisterGenerator(done.spec.ContentType, done.spec.Name)
+			}
+			return fmt.Errorf("failed to register plugin generator: %w", err)
+		}
+		registered = append(registered, generator)
+	}
+	return nil
+}
+
+// Close asks the plugin to shut down and waits for it to exit, killing it
+// if it does not within the handshake timeout.
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.opts.HandshakeTimeout)
+	defer cancel()
+	if err := p.callLocked(ctx, MethodShutdown, nil, nil); err != nil {
+		p.logger.WithError(err).Warn("plugin did not shut down cleanly")
+	}
+	p.stopLocked()
+	return nil
+}
+
+// startLocked starts the plugin process and shakes hands (requires the lock)
+func (p *Plugin) startLocked(ctx context.Context) error {
+	cmd := exec.Command(p.path, p.opts.Args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin %s: %w", p.path, err)
+	}
+
+	p.cmd, p.stdin = cmd, stdin
+	p.responses = make(chan readResult)
+	p.stopped = make(chan struct{})
+	go readLines(stdout, p.responses, p.stopped)
+
+	ctx, cancel := context.WithTimeout(ctx, p.opts.HandshakeTimeout)
+	defer cancel()
+	var handshake Handshake
+	if err := p.callLocked(ctx, MethodHandshake, map[string]int{"protocol_version": ProtocolVersion}, &handshake); err != nil {
+		p.stopLocked()
+		return fmt.Errorf("plugin %s handshake failed: %w", p.path, err)
+	}
+	if err := handshake.validate(); err != nil {
+		p.stopLocked()
+		return fmt.Errorf("plugin %s handshake rejected: %w", p.path, err)
+	}
+
+	// A restarted plugin must still provide what it declared at load
+	if p.handshake.Name != "" && !sameGenerators(p.handshake, handshake) {
+		p.stopLocked()
+		return fmt.Errorf("plugin %s changed its generators after a restart", p.path)
+	}
+	p.handshake = handshake
+	return nil
+}
+
+// stopLocked kills the plugin process if it is still running (requires
+// the lock)
+func (p *Plugin) stopLocked() {
+	if p.cmd == nil {
+		return
+	}
+	_ = p.stdin.Close()
+	close(p.stopped)
+	done := make(chan struct{})
+	go func(cmd *exec.Cmd) {
+		_ = cmd.Wait()
+		close(done)
+	}(p.cmd)
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		_ = p.cmd.Process.Kill()
+		<-done
+	}
+	p.cmd, p.stdin, p.responses, p.stopped = nil, nil, nil, nil
+}
+
+// call sends a request to the plugin, restarting it first if it is not
+// running, and decodes the result into result.
+func (p *Plugin) call(ctx context.Context, method string, params, result interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd == nil {
+		p.logger.Warn("restarting PCG plugin")
+		if err := p.startLocked(ctx); err != nil {
+			return err
+		}
+	}
+	return p.callLocked(ctx, method, params, result)
+}
+
+// callLocked implements call on a running plugin (requires the lock)
+func (p *Plugin) callLocked(ctx context.Context, method string, params, result interface{}) error {
+	p.nextID++
+	request := Request{ID: p.nextID, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s parameters: %w", method, err)
+		}
+		request.Params = data
+	}
+	line, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		p.stopLocked()
+		return fmt.Errorf("failed to send %s to plugin: %w", method, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.WithField("method", method).Warn("PCG plugin timed out, stopping it")
+			p.stopLocked()
+			return fmt.Errorf("%w: %s: %v", ErrPluginTimeout, method, ctx.Err())
+
+		case read := <-p.responses:
+			if read.err != nil {
+				p.stopLocked()
+				return fmt.Errorf("plugin exited during %s", method)
+			}
+			var response Response
+			if err := json.Unmarshal(read.line, &response); err != nil {
+				p.stopLocked()
+				return fmt.Errorf("plugin sent an invalid response to %s: %w", method, err)
+			}
+			if response.ID != request.ID {
+				// The late answer to a request that timed out
+				continue
+			}
+			if response.Error != "" {
+				return fmt.Errorf("plugin %s failed: %s", method, response.Error)
+			}
+			if result != nil {
+				if err := json.Unmarshal(response.Result, result); err != nil {
+					return fmt.Errorf("plugin sent an invalid %s result: %w", method, err)
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// readLines sends each line of r to lines until r fails or stopped is
+// closed.
+func readLines(r io.Reader, lines chan<- readResult, stopped <-chan struct{}) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			line = nil
+		}
+		select {
+		case lines <- readResult{line: line, err: err}:
+		case <-stopped:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// sameGenerators reports whether two handshakes declare the same
+// generators.
+func sameGenerators(a, b Handshake) bool {
+	if len(a.Generators) != len(b.Generators) {
+		return false
+	}
+	for i := range a.Generators {
+		if a.Generators[i].Name != b.Generators[i].Name || a.Generators[i].ContentType != b.Generators[i].ContentType {
+			return false
+		}
+	}
+	return true
+}
+
+// Generator is a generator provided by a plugin. It implements
+// pcg.Generator: parameters are checked against the declared schema before
+// they are sent, and each generation is cut off after the generator's
+// timeout.
+type Generator struct {
+	plugin  *Plugin
+	spec    GeneratorSpec
+	timeout time.Duration
+}
+
+// Generate asks the plugin for content and decodes it into the type the
+// built-in generators of its content type return.
+func (g *Generator) Generate(ctx context.Context, params pcg.GenerationParams) (interface{}, error) {
+	timeout := g.timeout
+	if params.Timeout > 0 {
+		timeout = min(timeout, params.Timeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var raw json.RawMessage
+	err := g.plugin.call(ctx, MethodGenerate, GenerateParams{
+		Generator:   g.spec.Name,
+		ContentType: g.spec.ContentType,
+		Seed:        params.Seed,
+		Difficulty:  params.Difficulty,
+		PlayerLevel: params.PlayerLevel,
+		Constraints: params.Constraints,
+		Metadata:    params.Metadata,
+	}, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return decodeContent(g.spec.ContentType, raw)
+}
+
+// GetType returns the content type the generator declared.
+func (g *Generator) GetType() pcg.ContentType {
+	return g.spec.ContentType
+}
+
+// GetVersion returns the version the generator declared.
+func (g *Generator) GetVersion() string {
+	return g.spec.Version
+}
+
+// Validate checks the difficulty and the declared constraint schemas
+// without calling the plugin.
+func (g *Generator) Validate(params pcg.GenerationParams) error {
+	if params.Difficulty < 1 || params.Difficulty > 20 {
+		return fmt.Errorf("difficulty must be between 1 and 20")
+	}
+	return checkConstraints(g.spec, params.Constraints)
+}
+
+// Timeout returns how long a generation may take.
+func (g *Generator) Timeout() time.Duration {
+	return g.timeout
+}
+
+// decodeContent decodes plugin output into the Go type of its content
+// type. Content types without a Go type are returned as decoded JSON.
+func decodeContent(contentType pcg.ContentType, raw json.RawMessage) (interface{}, error) {
+	var content interface{}
+	switch contentType {
+	case pcg.ContentTypeQuests:
+		content = &game.Quest{}
+	case pcg.ContentTypeLevels:
+		content = &game.Level{}
+	case pcg.ContentTypeTerrain:
+		content = &game.GameMap{}
+	case pcg.ContentTypeItems:
+		content = &[]*game.Item{}
+	case pcg.ContentTypeMonsters:
+		content = &[]*pcg.MonsterStatBlock{}
+	default:
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil, fmt.Errorf("plugin sent invalid %s content: %w", contentType, err)
+		}
+		return decoded, nil
+	}
+
+	if err := json.Unmarshal(raw, content); err != nil {
+		return nil, fmt.Errorf("plugin sent invalid %s content: %w", contentType, err)
+	}
+	switch v := content.(type) {
+	case *[]*game.Item:
+		return *v, nil
+	case *[]*pcg.MonsterStatBlock:
+		return *v, nil
+	default:
+		return content, nil
+	}
+}
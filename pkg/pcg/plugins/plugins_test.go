@@ -0,0 +1,201 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperEnv makes the test binary act as the plugin it names
+const helperEnv = "GOLDBOX_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(helperEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "bounties":
+		serveTestPlugin(ProtocolVersion)
+	case "outdated":
+		serveTestPlugin(ProtocolVersion + 1)
+	}
+	os.Exit(0)
+}
+
+// serveTestPlugin serves a quest generator and a generator that hangs
+func serveTestPlugin(version int) {
+	maxReward := 500.0
+	handshake := Handshake{
+		Name:    "bounties",
+		Version: "1.0.0",
+		Generators: []GeneratorSpec{
+			{
+				Name: "bounty", ContentType: pcg.ContentTypeQuests, Version: "1.2.0", TimeoutMS: 2000,
+				Constraints: map[string]ParamSpec{
+					"target": {Type: ParamString, Required: true, Enum: []string{"orc", "troll"}},
+					"reward": {Type: ParamInt, Max: &maxReward},
+				},
+			},
+			{Name: "hang", ContentType: pcg.ContentTypeItems, Version: "0.1.0", TimeoutMS: 100},
+		},
+	}
+	generators := map[string]GenerateFunc{
+		"bounty": func(ctx context.Context, params GenerateParams) (interface{}, error) {
+			return game.Quest{
+				ID:    fmt.Sprintf("bounty_%d", params.Seed),
+				Title: fmt.Sprintf("Slay the %s", params.Constraints["target"]),
+			}, nil
+		},
+		"hang": func(ctx context.Context, params GenerateParams) (interface{}, error) {
+			time.Sleep(time.Minute)
+			return nil, nil
+		},
+	}
+
+	if version != ProtocolVersion {
+		// Answer the handshake by hand to declare another version
+		fmt.Printf(`{"id":1,"result":{"protocol_version":%d,"name":"old","generators":[]}}`+"\n", version)
+		return
+	}
+	if err := Serve(handshake, generators); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// loadTestPlugin starts the test binary as the named plugin
+func loadTestPlugin(t *testing.T, name string) (*Plugin, error) {
+	t.Helper()
+	t.Setenv(helperEnv, name)
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	plugin, err := Load(context.Background(), os.Args[0], Options{MaxTimeout: time.Second, Logger: logger})
+	if plugin != nil {
+		t.Cleanup(func() { plugin.Close() })
+	}
+	return plugin, err
+}
+
+func TestPlugin_RegistersDeclaredGenerators(t *testing.T) {
+	plugin, err := loadTestPlugin(t, "bounties")
+	require.NoError(t, err)
+	assert.Equal(t, "bounties", plugin.Name())
+
+	generators := plugin.Generators()
+	require.Len(t, generators, 2)
+	assert.Equal(t, pcg.ContentTypeQuests, generators[0].GetType())
+	assert.Equal(t, "1.2.0", generators[0].GetVersion())
+	assert.Equal(t, time.Second, generators[0].Timeout(), "declared timeouts are capped by the host")
+	assert.Equal(t, 100*time.Millisecond, generators[1].Timeout())
+
+	registry := pcg.NewRegistry(logrus.New())
+	require.NoError(t, plugin.Register(registry))
+	generator, err := registry.GetGenerator(pcg.ContentTypeQuests, "bounty")
+	require.NoError(t, err)
+	assert.Same(t, generators[0].plugin, generator.(*Generator).plugin)
+}
+
+func TestPlugin_GeneratesContent(t *testing.T) {
+	plugin, err := loadTestPlugin(t, "bounties")
+	require.NoError(t, err)
+	bounty := plugin.Generators()[0]
+
+	params := pcg.GenerationParams{Seed: 42, Difficulty: 5, Constraints: map[string]interface{}{"target": "troll"}}
+	require.NoError(t, bounty.Validate(params))
+	content, err := bounty.Generate(context.Background(), params)
+	require.NoError(t, err)
+
+	quest, ok := content.(*game.Quest)
+	require.True(t, ok, "quests decode to *game.Quest, got %T", content)
+	assert.Equal(t, "bounty_42", quest.ID)
+	assert.Equal(t, "Slay the troll", quest.Title)
+}
+
+func TestGenerator_ValidatesDeclaredSchema(t *testing.T) {
+	plugin, err := loadTestPlugin(t, "bounties")
+	require.NoError(t, err)
+	bounty := plugin.Generators()[0]
+
+	tests := []struct {
+		name        string
+		constraints map[string]interface{}
+		want        string
+	}{
+		{"missing required", map[string]interface{}{}, "constraint target is required"},
+		{"not in enum", map[string]interface{}{"target": "dragon"}, "is not one of"},
+		{"wrong type", map[string]interface{}{"target": 3}, "expected a string"},
+		{"above maximum", map[string]interface{}{"target": "orc", "reward": 900}, "above the maximum"},
+		{"not an integer", map[string]interface{}{"target": "orc", "reward": 1.5}, "expected an integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bounty.Validate(pcg.GenerationParams{Difficulty: 5, Constraints: tt.constraints})
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+	assert.Error(t, bounty.Validate(pcg.GenerationParams{Difficulty: 0, Constraints: map[string]interface{}{"target": "orc"}}))
+}
+
+func TestPlugin_KillsAndRestartsOnTimeout(t *testing.T) {
+	plugin, err := loadTestPlugin(t, "bounties")
+	require.NoError(t, err)
+	generators := plugin.Generators()
+
+	start := time.Now()
+	_, err = generators[1].Generate(context.Background(), pcg.GenerationParams{Difficulty: 1})
+	assert.ErrorIs(t, err, ErrPluginTimeout)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	content, err := generators[0].Generate(context.Background(), pcg.GenerationParams{
+		Seed: 7, Difficulty: 1, Constraints: map[string]interface{}{"target": "orc"},
+	})
+	require.NoError(t, err, "the next call restarts the plugin")
+	assert.Equal(t, "bounty_7", content.(*game.Quest).ID)
+}
+
+func TestLoad_RejectsOtherProtocolVersions(t *testing.T) {
+	_, err := loadTestPlugin(t, "outdated")
+	assert.ErrorContains(t, err, "protocol version 2")
+}
+
+func TestLoad_MissingExecutable(t *testing.T) {
+	_, err := Load(context.Background(), "/nonexistent/plugin", Options{})
+	assert.ErrorContains(t, err, "failed to start plugin")
+}
+
+func TestHandshake_Validate(t *testing.T) {
+	lo, hi := 10.0, 1.0
+	tests := []struct {
+		name      string
+		handshake Handshake
+		want      string
+	}{
+		{"no name", Handshake{ProtocolVersion: ProtocolVersion}, "no name"},
+		{"no generators", Handshake{ProtocolVersion: ProtocolVersion, Name: "p"}, "no generators"},
+		{"duplicate", Handshake{ProtocolVersion: ProtocolVersion, Name: "p", Generators: []GeneratorSpec{
+			{Name: "g", ContentType: pcg.ContentTypeItems}, {Name: "g", ContentType: pcg.ContentTypeItems},
+		}}, "twice"},
+		{"negative timeout", Handshake{ProtocolVersion: ProtocolVersion, Name: "p", Generators: []GeneratorSpec{
+			{Name: "g", ContentType: pcg.ContentTypeItems, TimeoutMS: -1},
+		}}, "negative timeout"},
+		{"unknown type", Handshake{ProtocolVersion: ProtocolVersion, Name: "p", Generators: []GeneratorSpec{
+			{Name: "g", ContentType: pcg.ContentTypeItems, Constraints: map[string]ParamSpec{"x": {Type: "date"}}},
+		}}, "unknown type"},
+		{"empty range", Handshake{ProtocolVersion: ProtocolVersion, Name: "p", Generators: []GeneratorSpec{
+			{Name: "g", ContentType: pcg.ContentTypeItems, Constraints: map[string]ParamSpec{"x": {Type: ParamInt, Min: &lo, Max: &hi}}},
+		}}, "exceeds max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.handshake.validate(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"goldbox-rpg/pkg/pcg"
+)
+
+// ProtocolVersion is the version of the plugin protocol. A plugin whose
+// handshake declares another version is not loaded.
+const ProtocolVersion = 1
+
+// Protocol methods sent to a plugin.
+const (
+	MethodHandshake = "handshake" // Declare the plugin and its generators
+	MethodGenerate  = "generate"  // Generate content with one generator
+	MethodShutdown  = "shutdown"  // Exit cleanly
+)
+
+// Request is one line a host writes to a plugin's stdin.
+type Request struct {
+	ID     uint64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is one line a plugin writes to its stdout in answer to the
+// request with the same ID. Error is set when the request failed.
+type Response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Handshake is a plugin's answer to the handshake request.
+type Handshake struct {
+	ProtocolVersion int             `json:"protocol_version"`
+	Name            string          `json:"name"`
+	Version         string          `json:"version"`
+	Generators      []GeneratorSpec `json:"generators"`
+}
+
+// GeneratorSpec declares one generator a plugin provides.
+//
+// Fields:
+//   - Name: Registry name of the generator, unique within its content type
+//   - ContentType: The content type it produces, such as "quests"
+//   - Version: Generator version reported by GetVersion
+//   - TimeoutMS: How long a generation may take; the host caps it
+//   - Constraints: Schema of the constraints it accepts, by key
+type GeneratorSpec struct {
+	Name        string               `json:"name"`
+	ContentType pcg.ContentType      `json:"content_type"`
+	Version     string               `json:"version"`
+	TimeoutMS   int64                `json:"timeout_ms,omitempty"`
+	Constraints map[string]ParamSpec `json:"constraints,omitempty"`
+}
+
+// Timeout returns the generator's declared timeout, or 0 if it declared
+// none.
+func (s GeneratorSpec) Timeout() time.Duration {
+	return time.Duration(s.TimeoutMS) * time.Millisecond
+}
+
+// Parameter types a ParamSpec may declare.
+const (
+	ParamString = "string"
+	ParamInt    = "int"
+	ParamNumber = "number"
+	ParamBool   = "bool"
+)
+
+// ParamSpec is the schema of one generation constraint. Constraints a
+// generator does not declare are passed through unchecked.
+type ParamSpec struct {
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Min      *float64 `json:"min,omitempty"`  // Least value of a number
+	Max      *float64 `json:"max,omitempty"`  // Greatest value of a number
+	Enum     []string `json:"enum,omitempty"` // Allowed values of a string
+}
+
+// GenerateParams are the parameters of a generate request. The world state
+// is not sent to plugins.
+type GenerateParams struct {
+	Generator   string                 `json:"generator"`
+	ContentType pcg.ContentType        `json:"content_type"`
+	Seed        int64                  `json:"seed"`
+	Difficulty  int                    `json:"difficulty"`
+	PlayerLevel int                    `json:"player_level"`
+	Constraints map[string]interface{} `json:"constraints,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// validate checks the handshake of a plugin.
+func (h *Handshake) validate() error {
+	if h.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("plugin speaks protocol version %d, host speaks %d", h.ProtocolVersion, ProtocolVersion)
+	}
+	if h.Name == "" {
+		return fmt.Errorf("plugin declared no name")
+	}
+	if len(h.Generators) == 0 {
+		return fmt.Errorf("plugin %s declared no generators", h.Name)
+	}
+
+	seen := make(map[string]bool, len(h.Generators))
+	for _, spec := range h.Generators {
+		if spec.Name == "" || spec.ContentType == "" {
+			return fmt.Errorf("plugin %s declared a generator without a name or content type", h.Name)
+		}
+		key := string(spec.ContentType) + "/" + spec.Name
+		if seen[key] {
+			return fmt.Errorf("plugin %s declared generator %s twice", h.Name, key)
+		}
+		seen[key] = true
+		if spec.TimeoutMS < 0 {
+			return fmt.Errorf("generator %s has a negative timeout", key)
+		}
+		for name, param := range spec.Constraints {
+			if err := param.validate(); err != nil {
+				return fmt.Errorf("generator %s constraint %s: %w", key, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks that a parameter schema is well formed.
+func (p ParamSpec) validate() error {
+	switch p.Type {
+	case ParamString, ParamInt, ParamNumber, ParamBool:
+	default:
+		return fmt.Errorf("unknown type %q", p.Type)
+	}
+	if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+		return fmt.Errorf("min %v exceeds max %v", *p.Min, *p.Max)
+	}
+	return nil
+}
+
+// checkConstraints checks constraints against the schemas of spec.
+func checkConstraints(spec GeneratorSpec, constraints map[string]interface{}) error {
+	names := make([]string, 0, len(spec.Constraints))
+	for name := range spec.Constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		param := spec.Constraints[name]
+		value, ok := constraints[name]
+		if !ok {
+			if param.Required {
+				return fmt.Errorf("constraint %s is required", name)
+			}
+			continue
+		}
+		if err := param.check(value); err != nil {
+			return fmt.Errorf("constraint %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// check reports whether value satisfies the schema.
+func (p ParamSpec) check(value interface{}) error {
+	switch p.Type {
+	case ParamString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %T", value)
+		}
+		if len(p.Enum) > 0 && !slices.Contains(p.Enum, s) {
+			return fmt.Errorf("%q is not one of %v", s, p.Enum)
+		}
+		return nil
+	case ParamBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a bool, got %T", value)
+		}
+		return nil
+	}
+
+	n, ok := toFloat(value)
+	if !ok {
+		return fmt.Errorf("expected a number, got %T", value)
+	}
+	if p.Type == ParamInt && n != float64(int64(n)) {
+		return fmt.Errorf("expected an integer, got %v", n)
+	}
+	if p.Min != nil && n < *p.Min {
+		return fmt.Errorf("%v is below the minimum %v", n, *p.Min)
+	}
+	if p.Max != nil && n > *p.Max {
+		return fmt.Errorf("%v is above the maximum %v", n, *p.Max)
+	}
+	return nil
+}
+
+// toFloat converts the numeric types constraints are given in.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,99 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// GenerateFunc generates content for one generator of a plugin. The result
+// is sent to the host as JSON.
+type GenerateFunc func(ctx context.Context, params GenerateParams) (interface{}, error)
+
+// Serve runs a plugin over the process's stdin and stdout until the host
+// sends shutdown or closes stdin. Plugin authors call it from main:
+//
+//	func main() {
+//		handshake := plugins.Handshake{Name: "my-quests", Version: "1.0.0", Generators: specs}
+//		if err := plugins.Serve(handshake, map[string]plugins.GenerateFunc{"bounty": generateBounty}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// Parameters:
+//   - handshake: The plugin's declaration; ProtocolVersion is filled in
+//   - generators: The generate function of each declared generator, by name
+//
+// Returns:
+//   - error: If the declaration is invalid or the streams fail
+func Serve(handshake Handshake, generators map[string]GenerateFunc) error {
+	return ServeIO(os.Stdin, os.Stdout, handshake, generators)
+}
+
+// ServeIO is Serve over the given streams.
+func ServeIO(in io.Reader, out io.Writer, handshake Handshake, generators map[string]GenerateFunc) error {
+	handshake.ProtocolVersion = ProtocolVersion
+	if err := handshake.validate(); err != nil {
+		return err
+	}
+	for _, spec := range handshake.Generators {
+		if generators[spec.Name] == nil {
+			return fmt.Errorf("generator %s has no generate function", spec.Name)
+		}
+	}
+
+	reader := bufio.NewReader(in)
+	encoder := json.NewEncoder(out)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+
+		var request Request
+		if err := json.Unmarshal(line, &request); err != nil {
+			return fmt.Errorf("failed to decode request: %w", err)
+		}
+
+		var result interface{}
+		switch request.Method {
+		case MethodHandshake:
+			result, err = handshake, nil
+		case MethodGenerate:
+			result, err = serveGenerate(request.Params, generators)
+		case MethodShutdown:
+			return encoder.Encode(Response{ID: request.ID})
+		default:
+			err = fmt.Errorf("unknown method %q", request.Method)
+		}
+
+		response := Response{ID: request.ID}
+		if err != nil {
+			response.Error = err.Error()
+		} else if response.Result, err = json.Marshal(result); err != nil {
+			response.Error = fmt.Sprintf("failed to encode result: %v", err)
+		}
+		if err := encoder.Encode(response); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+}
+
+// serveGenerate runs the generate function a generate request names.
+func serveGenerate(raw json.RawMessage, generators map[string]GenerateFunc) (interface{}, error) {
+	var params GenerateParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("invalid generate parameters: %w", err)
+	}
+	generate := generators[params.Generator]
+	if generate == nil {
+		return nil, fmt.Errorf("unknown generator %q", params.Generator)
+	}
+	return generate(context.Background(), params)
+}
@@ -0,0 +1,202 @@
+package pcg
+
+import (
+	"strings"
+
+	"goldbox-rpg/pkg/game"
+)
+
+// portraitArchetypes maps classes to the base figure clients draw
+var portraitArchetypes = map[game.CharacterClass]string{
+	game.ClassFighter: "warrior",
+	game.ClassMage:    "robed_caster",
+	game.ClassCleric:  "priest",
+	game.ClassThief:   "rogue",
+	game.ClassRanger:  "scout",
+	game.ClassPaladin: "knight",
+}
+
+// portraitClassColors lists the clothing colors each class favors
+var portraitClassColors = map[game.CharacterClass][]string{
+	game.ClassFighter: {"#8b1e1e", "#5a5a5a", "#6b4423", "#2f4f4f"},
+	game.ClassMage:    {"#2a2a8b", "#4b0082", "#1e3a5f", "#5d3a7a"},
+	game.ClassCleric:  {"#f0e6c8", "#c0c0c0", "#8b7d3a", "#ffffff"},
+	game.ClassThief:   {"#1a1a1a", "#3b3b3b", "#2e2416", "#26332a"},
+	game.ClassRanger:  {"#2e5a1c", "#556b2f", "#6b4423", "#3d4a2a"},
+	game.ClassPaladin: {"#d4af37", "#e8e8e8", "#1e3a8b", "#8b1e1e"},
+}
+
+// Palette choices shared by every class
+var (
+	portraitSkinTones    = []string{"#f6d7c3", "#e8b796", "#d09a72", "#a66e4a", "#7a4b2e", "#4f2f1c"}
+	portraitHairColors   = []string{"#1c1c1c", "#3b2314", "#6f4a2a", "#b5823c", "#e3c27a", "#a33a1e", "#d8d8d8"}
+	portraitEyeColors    = []string{"#3a2a1a", "#5a7a3a", "#3a5a8b", "#7a7a7a", "#8b6a2a"}
+	portraitAccentColors = []string{"#d4af37", "#c0c0c0", "#8b1e1e", "#2a2a8b", "#2e5a1c", "#1a1a1a", "#f0e6c8"}
+)
+
+// portraitFeatures are the visual features a portrait can be tagged with
+var portraitFeatures = []string{
+	"scarred", "bearded", "braided", "tattooed", "weathered",
+	"freckled", "greying", "one_eyed", "pierced",
+}
+
+// portraitWeapons maps weapon name keywords to silhouette hints. Longer
+// keywords come first so "crossbow" is not taken for "bow".
+var portraitWeapons = []struct {
+	keyword string
+	hint    string
+}{
+	{"crossbow", "crossbow"},
+	{"bow", "bow"},
+	{"staff", "staff"},
+	{"dagger", "dagger"},
+	{"axe", "axe"},
+	{"mace", "blunt"},
+	{"hammer", "blunt"},
+	{"spear", "polearm"},
+	{"halberd", "polearm"},
+	{"sword", "sword"},
+	{"blade", "sword"},
+}
+
+// portraitSilhouetteOrder lists the gear categories of silhouette hints in
+// the order they are reported
+var portraitSilhouetteOrder = []string{"head", "cloak", "body", "main_hand", "off_hand"}
+
+// GeneratePortrait creates the portrait and token descriptors of a
+// character. The archetype, palette, features and token come from the seed
+// and the character's class, level and background, so a character keeps its
+// look whenever its portrait is generated again. The silhouette hints follow
+// the character's gear: equipped items first, then carried ones.
+func GeneratePortrait(seed int64, character *game.Character) *game.Portrait {
+	snapshot := character.Clone()
+	rng := NewRNG(seed, "portrait")
+
+	archetype, exists := portraitArchetypes[snapshot.Class]
+	if !exists {
+		archetype = "commoner"
+	}
+	primaries, exists := portraitClassColors[snapshot.Class]
+	if !exists {
+		primaries = portraitAccentColors
+	}
+
+	portrait := &game.Portrait{
+		Seed:      seed,
+		Archetype: archetype,
+		Palette: game.PortraitPalette{
+			Skin:      portraitSkinTones[rng.Intn(len(portraitSkinTones))],
+			Hair:      portraitHairColors[rng.Intn(len(portraitHairColors))],
+			Eyes:      portraitEyeColors[rng.Intn(len(portraitEyeColors))],
+			Primary:   primaries[rng.Intn(len(primaries))],
+			Secondary: portraitAccentColors[rng.Intn(len(portraitAccentColors))],
+		},
+		Silhouette: portraitSilhouette(snapshot),
+	}
+
+	featureCount := 1 + rng.Intn(2)
+	for _, i := range rng.Perm(len(portraitFeatures))[:featureCount] {
+		portrait.Tags = append(portrait.Tags, portraitFeatures[i])
+	}
+	switch {
+	case snapshot.Level >= 10:
+		portrait.Tags = append(portrait.Tags, "legendary")
+	case snapshot.Level >= 5:
+		portrait.Tags = append(portrait.Tags, "veteran")
+	}
+	if snapshot.Background != game.BackgroundNone {
+		portrait.Tags = append(portrait.Tags, string(snapshot.Background))
+	}
+
+	portrait.Token = game.TokenStyle{
+		Shape:  portraitTokenShape(archetype),
+		Border: portrait.Palette.Secondary,
+		Size:   1,
+	}
+	if snapshot.HasTag("boss") {
+		portrait.Token.Size = 2
+	}
+
+	return portrait
+}
+
+// portraitTokenShape frames casters in hexes, armored fighters in squares
+// and everyone else in circles
+func portraitTokenShape(archetype string) string {
+	switch archetype {
+	case "robed_caster", "priest":
+		return "hex"
+	case "warrior", "knight":
+		return "square"
+	default:
+		return "circle"
+	}
+}
+
+// portraitSilhouette returns one hint for each gear category the character
+// fills, taking equipped items before carried ones
+func portraitSilhouette(character *game.Character) []string {
+	hints := make(map[string]string)
+	note := func(category, hint string) {
+		if category != "" && hints[category] == "" {
+			hints[category] = hint
+		}
+	}
+
+	for slot := game.SlotHead; slot <= game.SlotWeaponOff; slot++ {
+		item, equipped := character.Equipment[slot]
+		if !equipped {
+			continue
+		}
+		category, hint := portraitItemHint(item)
+		if slot == game.SlotWeaponOff && category == "main_hand" {
+			category = "off_hand"
+		}
+		note(category, hint)
+	}
+	for _, item := range character.Inventory {
+		note(portraitItemHint(item))
+	}
+
+	silhouette := make([]string, 0, len(hints))
+	for _, category := range portraitSilhouetteOrder {
+		if hint := hints[category]; hint != "" {
+			silhouette = append(silhouette, hint)
+		}
+	}
+	return silhouette
+}
+
+// portraitItemHint returns the gear category an item fills and how it shows
+// in the silhouette, or empty strings for items that do not show
+func portraitItemHint(item game.Item) (category, hint string) {
+	name := strings.ToLower(item.Name)
+	switch {
+	case item.Type == "shield" || strings.Contains(name, "shield"):
+		return "off_hand", "shield"
+	case strings.Contains(name, "cloak") || strings.Contains(name, "cape"):
+		return "cloak", "cloak"
+	case strings.Contains(name, "hood"):
+		return "head", "hood"
+	case strings.Contains(name, "helm") || strings.Contains(name, "crown"):
+		return "head", "helm"
+	case item.Type == "weapon":
+		for _, weapon := range portraitWeapons {
+			if strings.Contains(name, weapon.keyword) {
+				return "main_hand", weapon.hint
+			}
+		}
+		return "main_hand", "weapon"
+	case item.Type == "armor":
+		for _, heavy := range []string{"plate", "mail", "scale", "splint"} {
+			if strings.Contains(name, heavy) {
+				return "body", "heavy_armor"
+			}
+		}
+		if strings.Contains(name, "robe") {
+			return "body", "robes"
+		}
+		return "body", "light_armor"
+	}
+	return "", ""
+}
@@ -0,0 +1,71 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePortrait_DeterministicFromSeed(t *testing.T) {
+	character := &game.Character{
+		ID:         "hero",
+		Class:      game.ClassPaladin,
+		Level:      6,
+		Background: game.BackgroundNoble,
+		Equipment:  map[game.EquipmentSlot]game.Item{},
+	}
+
+	portrait := GeneratePortrait(7, character)
+	assert.Equal(t, int64(7), portrait.Seed)
+	assert.Equal(t, "knight", portrait.Archetype)
+	assert.Contains(t, portraitClassColors[game.ClassPaladin], portrait.Palette.Primary)
+	assert.Contains(t, portrait.Tags, "veteran")
+	assert.Contains(t, portrait.Tags, "noble")
+	assert.Equal(t, game.TokenStyle{Shape: "square", Border: portrait.Palette.Secondary, Size: 1}, portrait.Token)
+	assert.Equal(t, portrait, GeneratePortrait(7, character))
+
+	// New gear changes only the silhouette
+	character.Equipment[game.SlotWeaponMain] = game.Item{Name: "Long Sword", Type: "weapon"}
+	geared := GeneratePortrait(7, character)
+	assert.Equal(t, []string{"sword"}, geared.Silhouette)
+	geared.Silhouette = portrait.Silhouette
+	assert.Equal(t, portrait, geared)
+}
+
+func TestGeneratePortrait_Silhouette(t *testing.T) {
+	character := &game.Character{
+		Class: game.ClassFighter,
+		Equipment: map[game.EquipmentSlot]game.Item{
+			game.SlotHead:       {Name: "Iron Helm", Type: "armor"},
+			game.SlotWeaponMain: {Name: "Battle Axe", Type: "weapon"},
+			game.SlotWeaponOff:  {Name: "Dagger", Type: "weapon"},
+		},
+		Inventory: []game.Item{
+			{Name: "Bow", Type: "weapon"},
+			{Name: "Chain Mail", Type: "armor"},
+			{Name: "Tattered Cloak", Type: "armor"},
+			{Name: "Healing Potion", Type: "consumable"},
+		},
+	}
+
+	// Equipped gear wins over carried gear of the same kind
+	assert.Equal(t, []string{"helm", "cloak", "heavy_armor", "axe", "dagger"}, GeneratePortrait(1, character).Silhouette)
+	assert.Empty(t, GeneratePortrait(1, &game.Character{}).Silhouette)
+}
+
+func TestNPCGenerator_GenerateNPCHasPortrait(t *testing.T) {
+	params := CharacterParams{
+		GenerationParams: GenerationParams{Seed: 321, Difficulty: 3, PlayerLevel: 3},
+		CharacterType:    CharacterTypeGuard,
+	}
+
+	npc, err := NewNPCGenerator(nil).GenerateNPC(context.Background(), CharacterTypeGuard, params)
+	require.NoError(t, err)
+	require.NotNil(t, npc.Portrait)
+	assert.Equal(t, int64(321), npc.Portrait.Seed)
+	assert.Equal(t, GeneratePortrait(321, &npc.Character), npc.GetPortrait())
+}
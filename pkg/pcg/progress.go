@@ -0,0 +1,54 @@
+package pcg
+
+import "context"
+
+// ProgressStageComplete is the stage reported when a generation finishes successfully.
+const ProgressStageComplete = "complete"
+
+// ProgressUpdate reports how far a running generation has advanced.
+type ProgressUpdate struct {
+	ContentType ContentType `json:"content_type"`
+	Stage       string      `json:"stage"`   // Generator-specific stage name
+	Percent     float64     `json:"percent"` // Overall completion, 0-100
+}
+
+// ProgressFunc receives progress updates. It is called synchronously on the
+// generating goroutine, so implementations must return quickly.
+type ProgressFunc func(ProgressUpdate)
+
+// ReportProgress sends a progress update to params.Progress if one is set.
+// Percentages are clamped to 0-100.
+func (params GenerationParams) ReportProgress(contentType ContentType, stage string, percent float64) {
+	if params.Progress == nil {
+		return
+	}
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	params.Progress(ProgressUpdate{ContentType: contentType, Stage: stage, Percent: percent})
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context carrying fn. PCGManager copies it into the
+// GenerationParams it builds, letting callers of the high-level Generate*
+// methods observe progress without new parameters. A ProgressFunc already
+// carried by ctx keeps receiving updates, after fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	if parent := ProgressFromContext(ctx); parent != nil {
+		next := fn
+		fn = func(update ProgressUpdate) {
+			next(update)
+			parent(update)
+		}
+	}
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ProgressFromContext returns the ProgressFunc stored by WithProgress, or nil.
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
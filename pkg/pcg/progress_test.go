@@ -0,0 +1,130 @@
+package pcg
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordProgress returns a ProgressFunc that appends every update to updates
+func recordProgress(updates *[]ProgressUpdate) ProgressFunc {
+	return func(update ProgressUpdate) {
+		*updates = append(*updates, update)
+	}
+}
+
+// assertMonotonicProgress checks updates never go backwards and end at completion
+func assertMonotonicProgress(t *testing.T, updates []ProgressUpdate) {
+	t.Helper()
+	if len(updates) == 0 {
+		t.Fatal("Expected progress updates")
+	}
+	for i := 1; i < len(updates); i++ {
+		if updates[i].Percent < updates[i-1].Percent {
+			t.Errorf("Progress went backwards: %+v after %+v", updates[i], updates[i-1])
+		}
+	}
+	last := updates[len(updates)-1]
+	if last.Stage != ProgressStageComplete || last.Percent != 100 {
+		t.Errorf("Expected final update to be complete at 100%%, got %+v", last)
+	}
+}
+
+func TestGenerationParams_ReportProgress(t *testing.T) {
+	// No callback is a no-op
+	GenerationParams{}.ReportProgress(ContentTypeTerrain, "noop", 50)
+
+	var updates []ProgressUpdate
+	params := GenerationParams{Progress: recordProgress(&updates)}
+	params.ReportProgress(ContentTypeTerrain, "below", -5)
+	params.ReportProgress(ContentTypeTerrain, "above", 150)
+
+	if len(updates) != 2 || updates[0].Percent != 0 || updates[1].Percent != 100 {
+		t.Errorf("Expected clamped percentages, got %+v", updates)
+	}
+	if updates[0].ContentType != ContentTypeTerrain || updates[0].Stage != "below" {
+		t.Errorf("Unexpected update %+v", updates[0])
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	if ProgressFromContext(context.Background()) != nil {
+		t.Error("Expected no progress callback on a plain context")
+	}
+
+	var updates []ProgressUpdate
+	ctx := WithProgress(context.Background(), recordProgress(&updates))
+	ProgressFromContext(ctx)(ProgressUpdate{Stage: "x"})
+	if len(updates) != 1 {
+		t.Errorf("Expected the stored callback to be returned")
+	}
+}
+
+func TestWithProgress_KeepsParentCallback(t *testing.T) {
+	var outer, inner []ProgressUpdate
+	ctx := WithProgress(context.Background(), recordProgress(&outer))
+	ctx = WithProgress(ctx, recordProgress(&inner))
+
+	ProgressFromContext(ctx)(ProgressUpdate{Stage: "x"})
+	if len(inner) != 1 || len(outer) != 1 {
+		t.Errorf("Expected both callbacks to receive the update, got %d and %d", len(inner), len(outer))
+	}
+}
+
+func TestDungeonGenerator_ReportsProgress(t *testing.T) {
+	var updates []ProgressUpdate
+	params := GenerationParams{
+		Seed:     7,
+		Progress: recordProgress(&updates),
+		Constraints: map[string]interface{}{
+			"dungeon_params": DungeonParams{
+				LevelCount:    3,
+				LevelWidth:    40,
+				LevelHeight:   40,
+				RoomsPerLevel: 5,
+				Theme:         ThemeClassic,
+				Connectivity:  ConnectivityModerate,
+				Density:       0.5,
+				Difficulty: DifficultyProgression{
+					BaseDifficulty:  1,
+					ScalingFactor:   1.5,
+					MaxDifficulty:   10,
+					ProgressionType: "linear",
+				},
+			},
+		},
+	}
+
+	if _, err := NewDungeonGenerator(logrus.New()).Generate(context.Background(), params); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	assertMonotonicProgress(t, updates)
+	if updates[0].ContentType != ContentTypeDungeon || updates[0].Stage != "level_1" {
+		t.Errorf("Expected per-level dungeon progress first, got %+v", updates[0])
+	}
+}
+
+func TestBootstrap_ReportsProgress(t *testing.T) {
+	config := DefaultBootstrapConfig()
+	config.DataDirectory = t.TempDir()
+	config.WorldSeed = 42
+
+	var updates []ProgressUpdate
+	bootstrap := NewBootstrap(config, game.NewWorld(), logrus.New())
+	bootstrap.SetProgressFunc(recordProgress(&updates))
+
+	if _, err := bootstrap.GenerateCompleteGame(context.Background()); err != nil {
+		t.Fatalf("GenerateCompleteGame failed: %v", err)
+	}
+
+	assertMonotonicProgress(t, updates)
+	for _, update := range updates {
+		if update.ContentType != ContentTypeWorld {
+			t.Errorf("Expected world progress updates, got %+v", update)
+		}
+	}
+}
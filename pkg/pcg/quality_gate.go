@@ -0,0 +1,364 @@
+package pcg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Quality gate defaults
+const (
+	// DefaultQualityGateMaxAttempts is the number of candidates generated
+	// for one piece of content before the best is taken
+	DefaultQualityGateMaxAttempts = 3
+
+	// DefaultQualityGateMinScore is the score a candidate needs to be
+	// accepted without generating further candidates
+	DefaultQualityGateMinScore = 0.8
+)
+
+// qualityWarningPenalty is the score a candidate loses per validation
+// warning. Candidates with validation errors score 0.
+const qualityWarningPenalty = 0.2
+
+// QualityGateConfig controls rejection sampling of generated content.
+type QualityGateConfig struct {
+	Enabled     bool                    `json:"enabled"`      // Score candidates at all
+	MaxAttempts int                     `json:"max_attempts"` // Candidates generated at most per piece of content
+	MinScore    float64                 `json:"min_score"`    // Score accepting a candidate, 0-1
+	Thresholds  map[ContentType]float64 `json:"thresholds"`   // Per content type score replacing MinScore
+}
+
+// DefaultQualityGateConfig returns the default gate settings. The gate is
+// disabled, so content is the first result of its generator.
+func DefaultQualityGateConfig() QualityGateConfig {
+	return QualityGateConfig{
+		MaxAttempts: DefaultQualityGateMaxAttempts,
+		MinScore:    DefaultQualityGateMinScore,
+	}
+}
+
+// normalizeQualityGateConfig fills unset settings with their defaults and
+// clamps scores to 0-1
+func normalizeQualityGateConfig(config QualityGateConfig) QualityGateConfig {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultQualityGateMaxAttempts
+	}
+	config.MinScore = clampScore(config.MinScore)
+	thresholds := make(map[ContentType]float64, len(config.Thresholds))
+	for contentType, score := range config.Thresholds {
+		thresholds[contentType] = clampScore(score)
+	}
+	config.Thresholds = thresholds
+	return config
+}
+
+// QualityGateStats describes the candidates the gate scored for one
+// content type.
+type QualityGateStats struct {
+	Generations    int64   `json:"generations"`     // Pieces of content generated through the gate
+	Attempts       int64   `json:"attempts"`        // Candidates generated
+	Rejections     int64   `json:"rejections"`      // Candidates scoring below the threshold
+	BelowThreshold int64   `json:"below_threshold"` // Generations whose best candidate missed the threshold
+	RejectionRate  float64 `json:"rejection_rate"`  // Rejections per candidate
+	AverageScore   float64 `json:"average_score"`   // Mean score of the selected candidates
+}
+
+// QualityGate generates a piece of content up to MaxAttempts times, scores
+// each candidate with ScoreContent and keeps the best. Generation stops at
+// the first candidate reaching the content type's threshold; if none does,
+// the best candidate is returned anyway and counted as below threshold.
+// The first candidate uses the requested seed and later ones seeds derived
+// from it, so gated content stays reproducible. It is safe for concurrent
+// use.
+type QualityGate struct {
+	mu         sync.Mutex
+	config     QualityGateConfig
+	stats      map[ContentType]*QualityGateStats
+	scoreTotal map[ContentType]float64
+}
+
+// NewQualityGate creates a quality gate with config. Unset settings take
+// their defaults.
+func NewQualityGate(config QualityGateConfig) *QualityGate {
+	return &QualityGate{
+		config:     normalizeQualityGateConfig(config),
+		stats:      make(map[ContentType]*QualityGateStats),
+		scoreTotal: make(map[ContentType]float64),
+	}
+}
+
+// SetConfig replaces the gate settings. Recorded statistics are kept.
+func (qg *QualityGate) SetConfig(config QualityGateConfig) {
+	qg.mu.Lock()
+	defer qg.mu.Unlock()
+	qg.config = normalizeQualityGateConfig(config)
+}
+
+// GetConfig returns a copy of the gate settings
+func (qg *QualityGate) GetConfig() QualityGateConfig {
+	qg.mu.Lock()
+	defer qg.mu.Unlock()
+
+	config := qg.config
+	config.Thresholds = make(map[ContentType]float64, len(qg.config.Thresholds))
+	for contentType, score := range qg.config.Thresholds {
+		config.Thresholds[contentType] = score
+	}
+	return config
+}
+
+// Enabled reports whether candidates are scored
+func (qg *QualityGate) Enabled() bool {
+	qg.mu.Lock()
+	defer qg.mu.Unlock()
+	return qg.config.Enabled
+}
+
+// Threshold returns the score a candidate of contentType needs to be
+// accepted
+func (qg *QualityGate) Threshold(contentType ContentType) float64 {
+	qg.mu.Lock()
+	defer qg.mu.Unlock()
+	return qg.thresholdLocked(contentType)
+}
+
+// thresholdLocked returns the threshold of contentType. The caller must
+// hold qg.mu.
+func (qg *QualityGate) thresholdLocked(contentType ContentType) float64 {
+	if score, exists := qg.config.Thresholds[contentType]; exists {
+		return score
+	}
+	return qg.config.MinScore
+}
+
+// Stats returns the gate statistics per content type
+func (qg *QualityGate) Stats() map[ContentType]QualityGateStats {
+	qg.mu.Lock()
+	defer qg.mu.Unlock()
+
+	stats := make(map[ContentType]QualityGateStats, len(qg.stats))
+	for contentType, entry := range qg.stats {
+		stats[contentType] = *entry
+	}
+	return stats
+}
+
+// Reset clears the gate statistics
+func (qg *QualityGate) Reset() {
+	qg.mu.Lock()
+	defer qg.mu.Unlock()
+	qg.stats = make(map[ContentType]*QualityGateStats)
+	qg.scoreTotal = make(map[ContentType]float64)
+}
+
+// Generate runs generate with seed and, while the gate is enabled and the
+// candidate scores below the threshold, with the seeds deriveSeed returns
+// for attempts 1 to MaxAttempts-1. It returns the best candidate and its
+// score. A failed attempt ends the run: the best earlier candidate is
+// returned, or the error if there is none. Content generated with the gate
+// disabled is returned unscored with a score of 1.
+func (qg *QualityGate) Generate(ctx context.Context, contentType ContentType, seed int64, deriveSeed func(attempt int) int64, score func(content interface{}) float64, generate func(seed int64) (interface{}, error)) (interface{}, float64, error) {
+	qg.mu.Lock()
+	enabled, attempts, threshold := qg.config.Enabled, qg.config.MaxAttempts, qg.thresholdLocked(contentType)
+	qg.mu.Unlock()
+
+	if !enabled {
+		content, err := generate(seed)
+		return content, 1, err
+	}
+
+	var best interface{}
+	bestScore := -1.0
+	tried, rejected := 0, 0
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if ctx.Err() != nil {
+				break
+			}
+			seed = deriveSeed(attempt)
+		}
+
+		content, err := generate(seed)
+		if err != nil {
+			if best == nil {
+				return nil, 0, err
+			}
+			break
+		}
+		tried++
+
+		candidateScore := score(content)
+		if candidateScore > bestScore {
+			best, bestScore = content, candidateScore
+		}
+		if candidateScore >= threshold {
+			break
+		}
+		rejected++
+	}
+
+	qg.record(contentType, tried, rejected, bestScore, bestScore >= threshold)
+	return best, bestScore, nil
+}
+
+// record adds a gated generation to the statistics of contentType
+func (qg *QualityGate) record(contentType ContentType, attempts, rejections int, score float64, passed bool) {
+	qg.mu.Lock()
+	defer qg.mu.Unlock()
+
+	entry, exists := qg.stats[contentType]
+	if !exists {
+		entry = &QualityGateStats{}
+		qg.stats[contentType] = entry
+	}
+	entry.Generations++
+	entry.Attempts += int64(attempts)
+	entry.Rejections += int64(rejections)
+	if !passed {
+		entry.BelowThreshold++
+	}
+	qg.scoreTotal[contentType] += score
+	entry.RejectionRate = float64(entry.Rejections) / float64(entry.Attempts)
+	entry.AverageScore = qg.scoreTotal[contentType] / float64(entry.Generations)
+}
+
+// ScoreContent rates generated content from 0 (unusable) to 1. Content
+// failing validation scores 0 and every validation warning costs 0.2.
+// Maps and levels are further scaled by the share of their walkable tiles
+// in the largest connected region, so fragmented layouts score lower; item
+// sets score the mean of their items. Content of types the validator does
+// not know scores 1.
+func (v *Validator) ScoreContent(content interface{}) float64 {
+	switch c := content.(type) {
+	case *game.GameMap:
+		if c == nil {
+			return 0
+		}
+		return validationScore(v.ValidateGameMap(c)) * walkableConnectivity(walkableGrid(c.Tiles, func(tile game.MapTile) bool { return tile.Walkable }))
+	case *game.Level:
+		if c == nil {
+			return 0
+		}
+		return validationScore(v.ValidateLevel(c)) * walkableConnectivity(walkableGrid(c.Tiles, func(tile game.Tile) bool { return tile.Walkable }))
+	case *game.Quest:
+		score := validationScore(v.ValidateQuest(c))
+		if c != nil && c.Description == "" {
+			score -= qualityWarningPenalty
+		}
+		return clampScore(score)
+	case *game.Item:
+		return validationScore(v.ValidateItem(c))
+	case []*game.Item:
+		if len(c) == 0 {
+			return 0
+		}
+		total := 0.0
+		for _, item := range c {
+			total += validationScore(v.ValidateItem(item))
+		}
+		return total / float64(len(c))
+	default:
+		return 1
+	}
+}
+
+// validationScore turns a validation result into a score
+func validationScore(result *ValidationResult) float64 {
+	if !result.IsValid() {
+		return 0
+	}
+	return clampScore(1 - qualityWarningPenalty*float64(len(result.Warnings)))
+}
+
+// walkableGrid reduces the tiles of a map or level to whether each is
+// walkable
+func walkableGrid[T any](tiles [][]T, walkable func(tile T) bool) [][]bool {
+	grid := make([][]bool, len(tiles))
+	for y, row := range tiles {
+		grid[y] = make([]bool, len(row))
+		for x, tile := range row {
+			grid[y][x] = walkable(tile)
+		}
+	}
+	return grid
+}
+
+// walkableConnectivity returns the share of walkable tiles that lie in the
+// largest 4-connected walkable region, or 0 without walkable tiles
+func walkableConnectivity(tiles [][]bool) float64 {
+	visited := make([][]bool, len(tiles))
+	for y := range tiles {
+		visited[y] = make([]bool, len(tiles[y]))
+	}
+
+	walkable, largest := 0, 0
+	for y := range tiles {
+		for x := range tiles[y] {
+			if !tiles[y][x] || visited[y][x] {
+				continue
+			}
+			size := floodRegion(tiles, visited, x, y)
+			walkable += size
+			if size > largest {
+				largest = size
+			}
+		}
+	}
+	if walkable == 0 {
+		return 0
+	}
+	return float64(largest) / float64(walkable)
+}
+
+// floodRegion marks the walkable region containing (x, y) as visited and
+// returns its size
+func floodRegion(tiles [][]bool, visited [][]bool, x, y int) int {
+	stack := []game.Position{{X: x, Y: y}}
+	visited[y][x] = true
+	size := 0
+	for len(stack) > 0 {
+		pos := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		size++
+		for _, next := range []game.Position{{X: pos.X + 1, Y: pos.Y}, {X: pos.X - 1, Y: pos.Y}, {X: pos.X, Y: pos.Y + 1}, {X: pos.X, Y: pos.Y - 1}} {
+			if next.Y < 0 || next.Y >= len(tiles) || next.X < 0 || next.X >= len(tiles[next.Y]) {
+				continue
+			}
+			if visited[next.Y][next.X] || !tiles[next.Y][next.X] {
+				continue
+			}
+			visited[next.Y][next.X] = true
+			stack = append(stack, next)
+		}
+	}
+	return size
+}
+
+// clampScore limits score to 0-1
+func clampScore(score float64) float64 {
+	return math.Min(math.Max(score, 0), 1)
+}
+
+// generateGated generates content of contentType for key through the
+// manager's quality gate. Candidates after the first derive their seed from
+// key and the attempt number.
+func (pcg *PCGManager) generateGated(ctx context.Context, contentType ContentType, key string, seed int64, generate func(seed int64) (interface{}, error)) (interface{}, error) {
+	deriveSeed := func(attempt int) int64 {
+		return pcg.seedManager.VersionedContextSeed(contentType, fmt.Sprintf("%s#candidate-%d", key, attempt))
+	}
+	content, score, err := pcg.qualityGate.Generate(ctx, contentType, seed, deriveSeed, pcg.validator.ScoreContent, generate)
+	if err == nil && pcg.qualityGate.Enabled() {
+		pcg.logger.WithFields(logrus.Fields{
+			"content_type": contentType,
+			"key":          key,
+			"score":        score,
+		}).Debug("quality gate selected candidate")
+	}
+	return content, err
+}
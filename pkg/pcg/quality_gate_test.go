@@ -0,0 +1,175 @@
+package pcg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// draftQuestGenerator returns quests without rewards for its first goodFrom
+// calls, recording the seed of every call
+type draftQuestGenerator struct {
+	goodFrom int
+	seeds    []int64
+}
+
+func (g *draftQuestGenerator) Generate(ctx context.Context, params GenerationParams) (interface{}, error) {
+	g.seeds = append(g.seeds, params.Seed)
+	quest := &game.Quest{
+		ID:          "quest_draft",
+		Title:       "Draft",
+		Description: "A quest in the making",
+		Status:      game.QuestNotStarted,
+		Objectives:  []game.QuestObjective{{Description: "Find the map", Required: 1}},
+	}
+	if len(g.seeds) > g.goodFrom {
+		quest.Rewards = []game.QuestReward{{Type: "gold", Value: 10}}
+	}
+	return quest, nil
+}
+
+func (g *draftQuestGenerator) GetType() ContentType            { return ContentTypeQuests }
+func (g *draftQuestGenerator) GetVersion() string              { return "1.0.0" }
+func (g *draftQuestGenerator) Validate(GenerationParams) error { return nil }
+
+func TestValidator_ScoreContent(t *testing.T) {
+	v := NewValidator(false)
+	quest := &game.Quest{
+		ID:          "quest_1",
+		Title:       "Rats",
+		Description: "Clear the cellar",
+		Status:      game.QuestNotStarted,
+		Objectives:  []game.QuestObjective{{Description: "Kill rats", Required: 5}},
+		Rewards:     []game.QuestReward{{Type: "gold", Value: 10}},
+	}
+	assert.Equal(t, 1.0, v.ScoreContent(quest))
+
+	quest.Rewards = nil
+	assert.InDelta(t, 0.8, v.ScoreContent(quest), 1e-9, "each warning costs 0.2")
+
+	quest.ID = ""
+	assert.Zero(t, v.ScoreContent(quest), "invalid content scores 0")
+
+	level := &game.Level{ID: "level_1", Name: "Cellar", Width: 3, Height: 2, Tiles: [][]game.Tile{
+		{{Walkable: true}, {Walkable: true}, {Walkable: true}},
+		{{}, {}, {Walkable: true}},
+	}}
+	assert.Equal(t, 1.0, v.ScoreContent(level))
+	level.Tiles[0][1].Walkable = false // Splits the walkable tiles into regions of one and two
+	assert.InDelta(t, 2.0/3, v.ScoreContent(level), 1e-9, "fragmented levels score lower")
+
+	items := []*game.Item{
+		{ID: "item_1", Name: "Sword", Type: "weapon", Damage: "1d8"},
+		{ID: "item_2", Name: "Blunt", Type: "weapon"},
+	}
+	assert.InDelta(t, 0.9, v.ScoreContent(items), 1e-9, "item sets score the mean of their items")
+
+	assert.Equal(t, 1.0, v.ScoreContent("unscored"))
+}
+
+func TestQualityGate_Generate(t *testing.T) {
+	scores := map[int64]float64{1: 0.3, 2: 0.9, 3: 0.5}
+	score := func(content interface{}) float64 { return scores[content.(int64)] }
+	derive := func(attempt int) int64 { return int64(attempt + 1) }
+	var generated []int64
+	generate := func(seed int64) (interface{}, error) {
+		generated = append(generated, seed)
+		return seed, nil
+	}
+
+	gate := NewQualityGate(DefaultQualityGateConfig())
+	content, _, err := gate.Generate(context.Background(), ContentTypeQuests, 1, derive, score, generate)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), content, "a disabled gate returns the first result")
+	assert.Empty(t, gate.Stats())
+
+	gate.SetConfig(QualityGateConfig{Enabled: true, MaxAttempts: 3, MinScore: 0.8})
+	generated = nil
+	content, best, err := gate.Generate(context.Background(), ContentTypeQuests, 1, derive, score, generate)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), content)
+	assert.Equal(t, 0.9, best)
+	assert.Equal(t, []int64{1, 2}, generated, "generation stops at the first accepted candidate")
+
+	gate.SetConfig(QualityGateConfig{Enabled: true, MaxAttempts: 3, MinScore: 0.8, Thresholds: map[ContentType]float64{ContentTypeQuests: 0.95}})
+	generated = nil
+	content, _, err = gate.Generate(context.Background(), ContentTypeQuests, 1, derive, score, generate)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), content, "the best candidate is kept when none reaches the threshold")
+	assert.Equal(t, []int64{1, 2, 3}, generated)
+
+	stats := gate.Stats()[ContentTypeQuests]
+	assert.Equal(t, QualityGateStats{
+		Generations:    2,
+		Attempts:       5,
+		Rejections:     4,
+		BelowThreshold: 1,
+		RejectionRate:  0.8,
+		AverageScore:   0.9,
+	}, stats)
+
+	gate.Reset()
+	assert.Empty(t, gate.Stats())
+}
+
+func TestQualityGate_GenerateErrors(t *testing.T) {
+	gate := NewQualityGate(QualityGateConfig{Enabled: true, MaxAttempts: 3, MinScore: 1})
+	score := func(interface{}) float64 { return 0.5 }
+	derive := func(attempt int) int64 { return int64(attempt) }
+	failure := errors.New("generator failed")
+
+	_, _, err := gate.Generate(context.Background(), ContentTypeItems, 0, derive, score, func(int64) (interface{}, error) {
+		return nil, failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	content, _, err := gate.Generate(context.Background(), ContentTypeItems, 0, derive, score, func(seed int64) (interface{}, error) {
+		if seed > 0 {
+			return nil, failure
+		}
+		return "first", nil
+	})
+	require.NoError(t, err, "a later failure keeps the earlier candidate")
+	assert.Equal(t, "first", content)
+}
+
+func TestPCGManager_QualityGate(t *testing.T) {
+	manager := NewPCGManager(game.NewWorld(), nil)
+	manager.InitializeWithSeed(7)
+	generator := &draftQuestGenerator{goodFrom: 2}
+	require.NoError(t, manager.GetRegistry().RegisterGenerator("draft", generator))
+
+	quest, err := manager.GenerateWithGenerator(context.Background(), ContentTypeQuests, "draft", "area_1", 1, nil)
+	require.NoError(t, err)
+	assert.Empty(t, quest.(*game.Quest).Rewards, "content is the first result until the gate is enabled")
+	require.Len(t, generator.seeds, 1)
+	firstSeed := generator.seeds[0]
+
+	manager.GetQualityGate().SetConfig(QualityGateConfig{Enabled: true, MaxAttempts: 4, MinScore: 0.9})
+	generator.seeds = nil
+	quest, err = manager.GenerateWithGenerator(context.Background(), ContentTypeQuests, "draft", "area_1", 1, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, quest.(*game.Quest).Rewards, "the gate selects the candidate with rewards")
+	require.Len(t, generator.seeds, 3)
+	assert.Equal(t, firstSeed, generator.seeds[0], "the first candidate uses the requested seed")
+	assert.NotEqual(t, generator.seeds[1], generator.seeds[2])
+
+	stats := manager.GetGenerationStatistics()["quality_gate"].(map[ContentType]QualityGateStats)
+	assert.Equal(t, int64(3), stats[ContentTypeQuests].Attempts)
+	assert.Equal(t, int64(2), stats[ContentTypeQuests].Rejections)
+
+	// Candidate seeds are derived from the location, so the run repeats
+	repeat := &draftQuestGenerator{goodFrom: 2}
+	other := NewPCGManager(game.NewWorld(), nil)
+	other.InitializeWithSeed(7)
+	other.GetQualityGate().SetConfig(manager.GetQualityGate().GetConfig())
+	require.NoError(t, other.GetRegistry().RegisterGenerator("draft", repeat))
+	_, err = other.GenerateWithGenerator(context.Background(), ContentTypeQuests, "draft", "area_1", 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, generator.seeds, repeat.seeds)
+}
@@ -74,7 +74,7 @@ func (qg *QuestGeneratorImpl) Generate(ctx context.Context, params GenerationPar
 	}
 
 	// Use seed for deterministic generation
-	rng := rand.New(rand.NewSource(params.Seed))
+	rng := NewRNG(params.Seed, "quests")
 	qg.rng = rng
 
 	logrus.WithFields(logrus.Fields{
@@ -324,11 +324,10 @@ func (qg *QuestGeneratorImpl) validateQuestParams(params QuestParams) error {
 	return nil
 }
 
-// generateQuestID creates a unique identifier for the quest
+// generateQuestID derives the quest's ID from the generator's random
+// stream, so a quest regenerated from the same seed keeps its ID
 func (qg *QuestGeneratorImpl) generateQuestID(questType QuestType) string {
-	timestamp := time.Now().Unix()
-	randomSuffix := qg.rng.Intn(10000)
-	return fmt.Sprintf("quest_%s_%d_%04d", questType, timestamp, randomSuffix)
+	return game.SeededID(game.IDNamespaceQuest.Kind(string(questType)), qg.rng.Int63(), "")
 }
 
 // generateQuestNarrative creates title and description based on quest type
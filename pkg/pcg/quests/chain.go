@@ -0,0 +1,203 @@
+package quests
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+const (
+	defaultChainStages = 3
+	maxChainStages     = 8
+
+	// chainDifficultyStep is the difficulty added by each stage of a chain
+	chainDifficultyStep = 2
+
+	// chainFallbackChance is the probability that a stage before the finale
+	// offers a fallback quest should its main quest fail
+	chainFallbackChance = 0.4
+)
+
+// chainMiddleTypes are the quest types of the stages between a chain's
+// opening and its finale, which is always a kill quest.
+var chainMiddleTypes = []pcg.QuestType{pcg.QuestTypeFetch, pcg.QuestTypeExplore}
+
+// QuestChainGenerator creates multi-quest story arcs. A chain is a spine of
+// stages, each unlocked by completing the one before it, that escalate in
+// difficulty and end in a kill quest. Stages may branch into a fallback
+// quest that unlocks if the stage fails, closing the arc on a lesser note.
+// A small cast of NPCs gives every quest of the chain, with the patron who
+// opens the arc also giving its finale.
+type QuestChainGenerator struct {
+	version string
+	quests  *ObjectiveBasedGenerator
+}
+
+// NewQuestChainGenerator creates a new quest chain generator
+func NewQuestChainGenerator() *QuestChainGenerator {
+	return &QuestChainGenerator{
+		version: "1.0.0",
+		quests:  NewObjectiveBasedGenerator(),
+	}
+}
+
+// GetType returns the content type this generator produces
+func (qcg *QuestChainGenerator) GetType() pcg.ContentType {
+	return pcg.ContentTypeQuests
+}
+
+// GetVersion returns the generator version for compatibility checking
+func (qcg *QuestChainGenerator) GetVersion() string {
+	return qcg.version
+}
+
+// Validate checks if the provided parameters are valid for this generator
+func (qcg *QuestChainGenerator) Validate(params pcg.GenerationParams) error {
+	if params.Difficulty < 1 || params.Difficulty > 20 {
+		return fmt.Errorf("difficulty must be between 1 and 20")
+	}
+
+	if stages, ok := params.Constraints["chain_stages"].(int); ok && (stages < 2 || stages > maxChainStages) {
+		return fmt.Errorf("chain_stages must be between 2 and %d", maxChainStages)
+	}
+
+	return nil
+}
+
+// Generate implements the Generator interface. The "chain_stages" and
+// "quest_type" constraints set the length of the chain and the type of its
+// opening quest.
+func (qcg *QuestChainGenerator) Generate(ctx context.Context, params pcg.GenerationParams) (interface{}, error) {
+	if err := qcg.Validate(params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+
+	questParams := pcg.QuestParams{
+		GenerationParams: params,
+		QuestType:        pcg.QuestTypeFetch,
+		MinObjectives:    1,
+		MaxObjectives:    3,
+		RewardTier:       pcg.RarityCommon,
+		Narrative:        pcg.NarrativeBranching,
+	}
+	if questType, ok := params.Constraints["quest_type"].(string); ok {
+		questParams.QuestType = pcg.QuestType(questType)
+	}
+
+	stages := defaultChainStages
+	if s, ok := params.Constraints["chain_stages"].(int); ok {
+		stages = s
+	}
+
+	return qcg.GenerateChain(ctx, questParams, stages)
+}
+
+// GenerateChain creates a quest chain with the given number of stages. The
+// opening quest has params.QuestType and params.Difficulty; each later stage
+// is chainDifficultyStep harder, up to the maximum of 20.
+func (qcg *QuestChainGenerator) GenerateChain(ctx context.Context, params pcg.QuestParams, stages int) (*game.QuestChain, error) {
+	if stages < 2 || stages > maxChainStages {
+		return nil, fmt.Errorf("chain must have between 2 and %d stages, got %d", maxChainStages, stages)
+	}
+
+	rng := pcg.NewRNG(params.Seed, "quest_chains")
+	cast := qcg.selectCast(stages, rng)
+	patron := cast[0]
+
+	chain := &game.QuestChain{
+		ID:    fmt.Sprintf("chain_%d", params.Seed),
+		Title: fmt.Sprintf("The %s's Cause", patron),
+		NPCs:  cast,
+	}
+
+	previous := ""
+	for stage := 0; stage < stages; stage++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		last := stage == stages-1
+		giver := patron
+		if !last && stage > 0 && len(cast) > 1 {
+			giver = cast[1+(stage-1)%(len(cast)-1)]
+		}
+
+		questType := chainMiddleTypes[rng.Intn(len(chainMiddleTypes))]
+		switch {
+		case last:
+			questType = pcg.QuestTypeKill
+		case stage == 0 && params.QuestType != "":
+			questType = params.QuestType
+		}
+
+		stageParams := params
+		stageParams.Seed = rng.Int63()
+		stageParams.Difficulty = min(20, params.Difficulty+stage*chainDifficultyStep)
+
+		quest, err := qcg.quests.GenerateQuest(ctx, questType, stageParams)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate stage %d of chain: %w", stage+1, err)
+		}
+		quest.ID = fmt.Sprintf("%s_stage%d", chain.ID, stage+1)
+		quest.Title = fmt.Sprintf("%s (Part %d)", quest.Title, stage+1)
+
+		step := game.QuestChainStep{Quest: *quest, Giver: giver}
+		if previous != "" {
+			step.Prerequisites = []game.QuestPrerequisite{{QuestID: previous, Outcome: game.QuestCompleted}}
+		}
+		chain.Steps = append(chain.Steps, step)
+
+		if !last && rng.Float64() < chainFallbackChance {
+			fallback, err := qcg.generateFallback(ctx, quest.ID, giver, stageParams, rng)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate fallback for stage %d of chain: %w", stage+1, err)
+			}
+			chain.Steps = append(chain.Steps, *fallback)
+		}
+
+		previous = quest.ID
+	}
+
+	if err := chain.Validate(); err != nil {
+		return nil, fmt.Errorf("generated invalid quest chain: %w", err)
+	}
+	return chain, nil
+}
+
+// generateFallback creates the quest offered when the quest failedID fails.
+func (qcg *QuestChainGenerator) generateFallback(ctx context.Context, failedID, giver string, params pcg.QuestParams, rng *rand.Rand) (*game.QuestChainStep, error) {
+	params.Seed = rng.Int63()
+	questType := chainMiddleTypes[rng.Intn(len(chainMiddleTypes))]
+
+	quest, err := qcg.quests.GenerateQuest(ctx, questType, params)
+	if err != nil {
+		return nil, err
+	}
+	quest.ID = failedID + "_fallback"
+	quest.Title = fmt.Sprintf("%s (Second Chance)", quest.Title)
+
+	return &game.QuestChainStep{
+		Quest:         *quest,
+		Giver:         giver,
+		Prerequisites: []game.QuestPrerequisite{{QuestID: failedID, Outcome: game.QuestFailed}},
+	}, nil
+}
+
+// selectCast picks the distinct NPCs who give the quests of a chain, one for
+// every two stages. The first is the patron.
+func (qcg *QuestChainGenerator) selectCast(stages int, rng *rand.Rand) []string {
+	pool := qcg.quests.narrativeEngine.characterPool
+	if len(pool) == 0 {
+		return []string{qcg.quests.narrativeEngine.selectQuestGiver(rng).Archetype}
+	}
+
+	size := min(len(pool), max(1, (stages+1)/2))
+	cast := make([]string, 0, size)
+	for _, i := range rng.Perm(len(pool))[:size] {
+		cast = append(cast, pool[i].Archetype)
+	}
+	return cast
+}
@@ -0,0 +1,131 @@
+package quests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+func newChainParams(seed int64) pcg.QuestParams {
+	return pcg.QuestParams{
+		GenerationParams: pcg.GenerationParams{Seed: seed, Difficulty: 4},
+		QuestType:        pcg.QuestTypeExplore,
+		MinObjectives:    1,
+		MaxObjectives:    2,
+		RewardTier:       pcg.RarityCommon,
+	}
+}
+
+func TestQuestChainGenerator_GenerateChain(t *testing.T) {
+	generator := NewQuestChainGenerator()
+
+	chain, err := generator.GenerateChain(context.Background(), newChainParams(42), 4)
+	if err != nil {
+		t.Fatalf("GenerateChain failed: %v", err)
+	}
+	if err := chain.Validate(); err != nil {
+		t.Fatalf("generated chain is invalid: %v", err)
+	}
+	if len(chain.NPCs) != 2 {
+		t.Errorf("expected a cast of 2 NPCs, got %v", chain.NPCs)
+	}
+
+	// Walk the spine: steps that require their predecessor's completion
+	var spine []game.QuestChainStep
+	for _, step := range chain.Steps {
+		if len(step.Prerequisites) == 0 || step.Prerequisites[0].Outcome == game.QuestCompleted {
+			spine = append(spine, step)
+			continue
+		}
+		if step.Prerequisites[0].Outcome != game.QuestFailed {
+			t.Errorf("unexpected prerequisite on %s", step.Quest.ID)
+		}
+	}
+	if len(spine) != 4 {
+		t.Fatalf("expected 4 spine stages, got %d", len(spine))
+	}
+
+	if spine[0].Giver != chain.NPCs[0] || spine[3].Giver != chain.NPCs[0] {
+		t.Error("expected the patron to open and close the chain")
+	}
+	if spine[1].Giver != chain.NPCs[1] {
+		t.Errorf("expected the middle stages to come from the rest of the cast, got %s", spine[1].Giver)
+	}
+
+	// Later stages are harder, so they pay out more experience on average
+	firstExp, lastExp := spine[0].Quest.Rewards[0].Value, spine[3].Quest.Rewards[0].Value
+	if firstExp > 4*300 || lastExp < 10*100 {
+		t.Errorf("expected escalating rewards, got %d then %d", firstExp, lastExp)
+	}
+}
+
+func TestQuestChainGenerator_Deterministic(t *testing.T) {
+	generator := NewQuestChainGenerator()
+
+	first, err := generator.GenerateChain(context.Background(), newChainParams(7), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := generator.GenerateChain(context.Background(), newChainParams(7), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Error("expected the same seed to produce the same chain")
+	}
+}
+
+func TestQuestChainGenerator_BranchesOnFailure(t *testing.T) {
+	generator := NewQuestChainGenerator()
+
+	// Some seed within a small range is bound to roll a fallback
+	for seed := int64(1); seed <= 20; seed++ {
+		chain, err := generator.GenerateChain(context.Background(), newChainParams(seed), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, step := range chain.Steps {
+			if len(step.Prerequisites) > 0 && step.Prerequisites[0].Outcome == game.QuestFailed {
+				player := &game.Player{}
+				if err := player.StartQuestChain(*chain); err != nil {
+					t.Fatal(err)
+				}
+				if err := player.FailQuest(step.Prerequisites[0].QuestID); err != nil {
+					t.Fatal(err)
+				}
+				quest, err := player.GetQuest(step.Quest.ID)
+				if err != nil || quest.Status != game.QuestActive {
+					t.Errorf("expected the fallback to unlock, got %v, %v", quest, err)
+				}
+				return
+			}
+		}
+	}
+	t.Error("expected at least one chain with a fallback quest")
+}
+
+func TestQuestChainGenerator_Validate(t *testing.T) {
+	generator := NewQuestChainGenerator()
+
+	if err := generator.Validate(pcg.GenerationParams{Difficulty: 0}); err == nil {
+		t.Error("expected an error for difficulty 0")
+	}
+	if err := generator.Validate(pcg.GenerationParams{Difficulty: 5, Constraints: map[string]interface{}{"chain_stages": 1}}); err == nil {
+		t.Error("expected an error for a single stage chain")
+	}
+
+	result, err := generator.Generate(context.Background(), pcg.GenerationParams{
+		Seed:        3,
+		Difficulty:  5,
+		Constraints: map[string]interface{}{"chain_stages": 2, "quest_type": string(pcg.QuestTypeFetch)},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if chain, ok := result.(*game.QuestChain); !ok || chain.Steps[0].Prerequisites != nil {
+		t.Errorf("expected a quest chain starting with an unconditional quest, got %#v", result)
+	}
+}
@@ -0,0 +1,123 @@
+package quests
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// consequenceProfile sets how likely each kind of consequence is for a
+// genre variant and how strongly it moves faction reputation.
+type consequenceProfile struct {
+	reputationChance float32 // Chance of a faction reputation shift
+	reputationGain   [2]int  // Reputation gained on completion
+	reputationLoss   [2]int  // Reputation lost on failure
+	npcDeathChance   float32 // Chance the quest giver dies on failure
+	lockoutChance    float32 // Chance the quest area is locked on failure
+	followUpChance   float32 // Chance completion unlocks a follow-up quest
+}
+
+// consequenceProfiles gives each genre variant its own weight of outcome:
+// grimdark failures are lethal, high magic seals areas and opens new
+// threads, and low fantasy is mostly about standing with the factions.
+var consequenceProfiles = map[pcg.GenreType]consequenceProfile{
+	pcg.GenreClassicFantasy: {
+		reputationChance: 0.8, reputationGain: [2]int{5, 15}, reputationLoss: [2]int{5, 10},
+		npcDeathChance: 0.1, lockoutChance: 0.15, followUpChance: 0.5,
+	},
+	pcg.GenreGrimdark: {
+		reputationChance: 0.9, reputationGain: [2]int{3, 8}, reputationLoss: [2]int{15, 30},
+		npcDeathChance: 0.6, lockoutChance: 0.4, followUpChance: 0.3,
+	},
+	pcg.GenreHighMagic: {
+		reputationChance: 0.6, reputationGain: [2]int{10, 20}, reputationLoss: [2]int{5, 10},
+		npcDeathChance: 0.1, lockoutChance: 0.5, followUpChance: 0.7,
+	},
+	pcg.GenreLowFantasy: {
+		reputationChance: 1.0, reputationGain: [2]int{5, 10}, reputationLoss: [2]int{10, 20},
+		npcDeathChance: 0.2, lockoutChance: 0.05, followUpChance: 0.2,
+	},
+}
+
+// generateConsequences creates the world mutations of a quest for the genre
+// variant given by the "genre" constraint. Targets come from the
+// "quest_giver", "faction" and "area" constraints; a consequence whose
+// target is not given is never generated. Quests without a genre have no
+// consequences.
+func (obg *ObjectiveBasedGenerator) generateConsequences(ctx context.Context, questType pcg.QuestType, params pcg.QuestParams, rng *rand.Rand) ([]game.QuestConsequence, error) {
+	genre, _ := params.Constraints["genre"].(string)
+	profile, ok := consequenceProfiles[pcg.GenreType(genre)]
+	if !ok {
+		return nil, nil
+	}
+
+	questGiver, _ := params.Constraints["quest_giver"].(string)
+	faction, _ := params.Constraints["faction"].(string)
+	area, _ := params.Constraints["area"].(string)
+
+	var consequences []game.QuestConsequence
+
+	if faction != "" && rng.Float32() < profile.reputationChance {
+		gain := profile.reputationGain[0] + rng.Intn(profile.reputationGain[1]-profile.reputationGain[0]+1)
+		loss := profile.reputationLoss[0] + rng.Intn(profile.reputationLoss[1]-profile.reputationLoss[0]+1)
+		consequences = append(consequences,
+			game.QuestConsequence{
+				Type: game.ConsequenceReputation, On: game.QuestCompleted, Target: faction, Amount: gain,
+				Description: fmt.Sprintf("The %s remember your help", faction),
+			},
+			game.QuestConsequence{
+				Type: game.ConsequenceReputation, On: game.QuestFailed, Target: faction, Amount: -loss,
+				Description: fmt.Sprintf("The %s will not forget your failure", faction),
+			},
+		)
+	}
+
+	if questGiver != "" && rng.Float32() < profile.npcDeathChance {
+		consequences = append(consequences, game.QuestConsequence{
+			Type: game.ConsequenceNPCDeath, On: game.QuestFailed, Target: questGiver,
+			Description: "Without your aid, the quest giver does not survive",
+		})
+	}
+
+	if area != "" && rng.Float32() < profile.lockoutChance {
+		consequences = append(consequences, game.QuestConsequence{
+			Type: game.ConsequenceAreaLockout, On: game.QuestFailed, Target: area,
+			Description: "The way is closed to you",
+		})
+	}
+
+	// Follow-ups do not generate follow-ups of their own
+	if allow, ok := params.Constraints["follow_up"].(bool); (!ok || allow) && rng.Float32() < profile.followUpChance {
+		followUp, err := obg.generateFollowUp(ctx, questType, params, rng.Int63())
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate follow-up quest: %w", err)
+		}
+		consequences = append(consequences, game.QuestConsequence{
+			Type: game.ConsequenceUnlockQuest, On: game.QuestCompleted, FollowUp: followUp,
+			Description: "Your success opens a new path",
+		})
+	}
+
+	return consequences, nil
+}
+
+// generateFollowUp creates the quest a completed quest unlocks: one step
+// harder, with the same genre and targets but no follow-up of its own.
+func (obg *ObjectiveBasedGenerator) generateFollowUp(ctx context.Context, questType pcg.QuestType, params pcg.QuestParams, seed int64) (*game.Quest, error) {
+	followParams := params
+	followParams.Seed = seed
+	if followParams.Difficulty < 20 {
+		followParams.Difficulty++
+	}
+
+	followParams.Constraints = make(map[string]interface{}, len(params.Constraints)+1)
+	for key, value := range params.Constraints {
+		followParams.Constraints[key] = value
+	}
+	followParams.Constraints["follow_up"] = false
+
+	return obg.GenerateQuest(ctx, questType, followParams)
+}
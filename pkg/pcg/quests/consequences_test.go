@@ -0,0 +1,179 @@
+package quests
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+func consequenceTestParams(seed int64, genre pcg.GenreType) pcg.QuestParams {
+	return pcg.QuestParams{
+		GenerationParams: pcg.GenerationParams{
+			Seed:       seed,
+			Difficulty: 5,
+			Constraints: map[string]interface{}{
+				"genre":       string(genre),
+				"quest_giver": "npc_elder",
+				"faction":     "city_watch",
+				"area":        "old_crypt",
+			},
+		},
+		QuestType:     pcg.QuestTypeKill,
+		MinObjectives: 1,
+		MaxObjectives: 2,
+		RewardTier:    pcg.RarityCommon,
+	}
+}
+
+func TestGenerateQuest_ConsequencesAreValid(t *testing.T) {
+	generator := NewObjectiveBasedGenerator()
+
+	for genre := range consequenceProfiles {
+		for seed := int64(1); seed <= 20; seed++ {
+			quest, err := generator.GenerateQuest(context.Background(), pcg.QuestTypeKill, consequenceTestParams(seed, genre))
+			if err != nil {
+				t.Fatalf("%s seed %d: %v", genre, seed, err)
+			}
+			for _, consequence := range quest.Consequences {
+				if err := consequence.Validate(); err != nil {
+					t.Errorf("%s seed %d: invalid consequence: %v", genre, seed, err)
+				}
+				if consequence.FollowUp != nil && len(consequence.FollowUp.Consequences) > 0 {
+					for _, nested := range consequence.FollowUp.Consequences {
+						if nested.Type == game.ConsequenceUnlockQuest {
+							t.Errorf("%s seed %d: follow-up quest has its own follow-up", genre, seed)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateQuest_ConsequencesFollowGenre(t *testing.T) {
+	generator := NewObjectiveBasedGenerator()
+	count := func(genre pcg.GenreType, kind game.ConsequenceType) int {
+		total := 0
+		for seed := int64(1); seed <= 200; seed++ {
+			quest, err := generator.GenerateQuest(context.Background(), pcg.QuestTypeKill, consequenceTestParams(seed, genre))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, consequence := range quest.Consequences {
+				if consequence.Type == kind {
+					total++
+				}
+			}
+		}
+		return total
+	}
+
+	if grim, classic := count(pcg.GenreGrimdark, game.ConsequenceNPCDeath), count(pcg.GenreClassicFantasy, game.ConsequenceNPCDeath); grim <= classic {
+		t.Errorf("expected grimdark to kill more quest givers than classic fantasy, got %d vs %d", grim, classic)
+	}
+	if high, low := count(pcg.GenreHighMagic, game.ConsequenceUnlockQuest), count(pcg.GenreLowFantasy, game.ConsequenceUnlockQuest); high <= low {
+		t.Errorf("expected high magic to unlock more follow-ups than low fantasy, got %d vs %d", high, low)
+	}
+}
+
+func TestGenerateQuest_ConsequencesLeaveQuestUnchanged(t *testing.T) {
+	generator := NewObjectiveBasedGenerator()
+	withGenre := consequenceTestParams(42, pcg.GenreGrimdark)
+	withoutGenre := withGenre
+	withoutGenre.Constraints = map[string]interface{}{}
+
+	plain, err := generator.GenerateQuest(context.Background(), pcg.QuestTypeKill, withoutGenre)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plain.Consequences) != 0 {
+		t.Errorf("expected no consequences without a genre, got %v", plain.Consequences)
+	}
+
+	varied, err := generator.GenerateQuest(context.Background(), pcg.QuestTypeKill, withGenre)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if varied.Title != plain.Title || !reflect.DeepEqual(varied.Objectives, plain.Objectives) || !reflect.DeepEqual(varied.Rewards, plain.Rewards) {
+		t.Error("expected consequences not to change the rest of the quest")
+	}
+
+	again, err := generator.GenerateQuest(context.Background(), pcg.QuestTypeKill, withGenre)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(varied.Consequences, again.Consequences) {
+		t.Error("expected consequences to be deterministic for a seed")
+	}
+}
+
+func TestGenerateQuest_ConsequencesSkipMissingTargets(t *testing.T) {
+	generator := NewObjectiveBasedGenerator()
+	params := consequenceTestParams(7, pcg.GenreGrimdark)
+	params.Constraints = map[string]interface{}{"genre": string(pcg.GenreGrimdark), "follow_up": false}
+
+	for seed := int64(1); seed <= 50; seed++ {
+		params.Seed = seed
+		quest, err := generator.GenerateQuest(context.Background(), pcg.QuestTypeKill, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(quest.Consequences) != 0 {
+			t.Fatalf("seed %d: expected no consequences without targets, got %v", seed, quest.Consequences)
+		}
+	}
+}
+
+func TestObjectiveBasedGenerator_ValidateGenre(t *testing.T) {
+	generator := NewObjectiveBasedGenerator()
+	params := pcg.GenerationParams{Difficulty: 5, Constraints: map[string]interface{}{"genre": "space_opera"}}
+	if err := generator.Validate(params); err == nil {
+		t.Error("expected unknown genre to be rejected")
+	}
+
+	params.Constraints["genre"] = string(pcg.GenreHighMagic)
+	if err := generator.Validate(params); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPCGManager_GenerateQuestForAreaUsesGenre(t *testing.T) {
+	manager := pcg.NewPCGManager(game.NewWorld(), nil)
+	manager.InitializeWithSeed(42)
+	if err := manager.GetRegistry().RegisterGenerator("objective_based", NewObjectiveBasedGenerator()); err != nil {
+		t.Fatal(err)
+	}
+	defer manager.GetJobQueue().Close()
+
+	quest, err := manager.GenerateQuestForArea(context.Background(), "old_crypt", pcg.QuestTypeKill, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quest.Consequences) != 0 {
+		t.Errorf("expected no consequences without a genre, got %v", quest.Consequences)
+	}
+
+	manager.SetGenre(pcg.GenreGrimdark)
+	lockouts := 0
+	for i := 0; i < 20; i++ {
+		quest, err := manager.GenerateQuestForArea(context.Background(), fmt.Sprintf("crypt_%d", i), pcg.QuestTypeKill, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, consequence := range quest.Consequences {
+			if consequence.Type == game.ConsequenceAreaLockout {
+				if consequence.Target != fmt.Sprintf("crypt_%d", i) {
+					t.Errorf("expected lockout of the quest's area, got %s", consequence.Target)
+				}
+				lockouts++
+			}
+		}
+	}
+	if lockouts == 0 {
+		t.Error("expected grimdark quests to lock their areas")
+	}
+}
@@ -0,0 +1,189 @@
+package quests
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// InjectDeityHooks adds quest hooks for the deities of pantheon to the
+// generator's template pools: a relic to recover for every deity, a lost
+// shrine to explore, a cult to purge for evil deities, and a priest quest
+// giver for every deity that is not evil. Deities already injected are
+// skipped. Call it while setting up the generator, before it generates
+// quests concurrently. Returns the number of deities injected.
+//
+// All deity hooks of a quest type together take one slot of the type's
+// pool, so a large pantheon does not crowd out other quests. A quest whose
+// first objective is a deity hook is told as that deity's story, and its
+// later objectives serve no other deity. Quests generated with the
+// "deity_hooks" constraint set to false never use deity hooks.
+func (obg *ObjectiveBasedGenerator) InjectDeityHooks(pantheon *game.Pantheon) int {
+	if pantheon == nil {
+		return 0
+	}
+
+	injected := 0
+	for _, deity := range pantheon.Deities {
+		if obg.narrativeEngine.deityHooks[deity.ID] {
+			continue
+		}
+		obg.injectDeityObjectives(deity)
+		obg.narrativeEngine.injectDeityStories(deity)
+		obg.narrativeEngine.deityHooks[deity.ID] = true
+		injected++
+	}
+	return injected
+}
+
+// injectDeityObjectives adds the objective templates of deity's hooks
+func (obg *ObjectiveBasedGenerator) injectDeityObjectives(deity game.Deity) {
+	slug := strings.TrimPrefix(deity.ID, "deity_")
+
+	obg.deityObjectives[pcg.QuestTypeFetch] = append(obg.deityObjectives[pcg.QuestTypeFetch], &ObjectiveTemplate{
+		Type:         "retrieve",
+		Description:  fmt.Sprintf("Recover the stolen relic of %s", deity.Name),
+		Requirements: []string{"exploration", "combat"},
+		Targets:      []string{"relic_of_" + slug},
+		Quantities:   [2]int{1, 1},
+		Rewards:      []string{"exp", "gold", "item"},
+		Deity:        deity.ID,
+	})
+	obg.deityObjectives[pcg.QuestTypeExplore] = append(obg.deityObjectives[pcg.QuestTypeExplore], &ObjectiveTemplate{
+		Type:         "discover",
+		Description:  fmt.Sprintf("Find the lost shrine of %s", deity.Name),
+		Requirements: []string{"movement"},
+		Targets:      []string{"shrine_of_" + slug},
+		Quantities:   [2]int{1, 1},
+		Rewards:      []string{"exp", "gold"},
+		Deity:        deity.ID,
+	})
+	if deity.Alignment == game.DeityEvil {
+		obg.deityObjectives[pcg.QuestTypeKill] = append(obg.deityObjectives[pcg.QuestTypeKill], &ObjectiveTemplate{
+			Type:         "kill",
+			Description:  fmt.Sprintf("Purge the cultists of %s", deity.Name),
+			Requirements: []string{"combat"},
+			Targets:      []string{"cultist_of_" + slug},
+			Quantities:   [2]int{3, 8},
+			Rewards:      []string{"exp", "gold"},
+			Deity:        deity.ID,
+		})
+	}
+}
+
+// deityHookPool returns the deity hook objective templates quests of
+// questType may use with params
+func (obg *ObjectiveBasedGenerator) deityHookPool(questType pcg.QuestType, params pcg.QuestParams) []*ObjectiveTemplate {
+	if enabled, ok := params.Constraints["deity_hooks"].(bool); ok && !enabled {
+		return nil
+	}
+	return obg.deityObjectives[questType]
+}
+
+// selectObjectiveTemplate picks the template of an objective. The first
+// objective draws from templates plus one slot shared by all hooks; later
+// objectives only draw hooks of the deity the quest already serves.
+// Without hooks it draws exactly as from templates alone, so injecting a
+// pantheon leaves quests of other types unchanged.
+func selectObjectiveTemplate(templates, hooks []*ObjectiveTemplate, first bool, deity string, rng *rand.Rand) *ObjectiveTemplate {
+	if len(hooks) == 0 || (!first && deity == "") {
+		return templates[rng.Intn(len(templates))]
+	}
+	if first {
+		if n := rng.Intn(len(templates) + 1); n < len(templates) {
+			return templates[n]
+		}
+		return hooks[rng.Intn(len(hooks))]
+	}
+
+	candidates := append([]*ObjectiveTemplate(nil), templates...)
+	for _, hook := range hooks {
+		if hook.Deity == deity {
+			candidates = append(candidates, hook)
+		}
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
+
+// injectDeityStories adds the story templates and quest giver of deity's
+// hooks
+func (ne *NarrativeEngine) injectDeityStories(deity game.Deity) {
+	name := fmt.Sprintf("%s, %s", deity.Name, deity.Title)
+
+	ne.deityStories[pcg.QuestTypeFetch] = append(ne.deityStories[pcg.QuestTypeFetch], &StoryTemplate{
+		Theme:      "Stolen Relic",
+		Setup:      fmt.Sprintf("A holy relic bearing the %s of %s has been stolen from its temple.", deity.HolySymbol, name),
+		Motivation: fmt.Sprintf("The faithful of %s cannot worship without it.", deity.Name),
+		Climax:     "You wrest the relic from the thieves.",
+		Resolution: fmt.Sprintf("The relic rests once more on the altar of %s.", deity.Name),
+		Characters: []string{"priest", "acolyte"},
+		Locations:  []string{"temple", "ruins", "hideout"},
+		Deity:      deity.ID,
+	})
+	ne.deityStories[pcg.QuestTypeExplore] = append(ne.deityStories[pcg.QuestTypeExplore], &StoryTemplate{
+		Theme:      "Forgotten Shrine",
+		Setup:      fmt.Sprintf("Old texts speak of a shrine to %s, lost for generations.", name),
+		Motivation: fmt.Sprintf("Those who follow %s's teaching, \"%s\", want it found.", deity.Name, firstTenet(deity)),
+		Climax:     fmt.Sprintf("You step into the shrine of %s.", deity.Name),
+		Resolution: "Pilgrims will walk the road to the shrine again.",
+		Characters: []string{"priest", "pilgrim"},
+		Locations:  []string{"shrine", "wilderness", "mountain"},
+		Deity:      deity.ID,
+	})
+
+	if deity.Alignment == game.DeityEvil {
+		ne.deityStories[pcg.QuestTypeKill] = append(ne.deityStories[pcg.QuestTypeKill], &StoryTemplate{
+			Theme:      "Cult of " + deity.Name,
+			Setup:      fmt.Sprintf("Worshippers of %s gather in secret beneath the town.", name),
+			Motivation: fmt.Sprintf("They preach that \"%s\" and the missing are their proof.", strings.ToLower(firstTenet(deity))),
+			Climax:     fmt.Sprintf("The altar of %s is thrown down.", deity.Name),
+			Resolution: "The cult is broken and its victims avenged.",
+			Characters: []string{"town_guard", "worried_villager"},
+			Locations:  []string{"crypt", "sewers", "temple"},
+			Deity:      deity.ID,
+		})
+		return
+	}
+
+	ne.deityGivers[deity.ID] = &NPCTemplate{
+		Archetype:   "Priest of " + deity.Name,
+		Personality: []string{"devout", "earnest"},
+		Motivations: append([]string{"serve " + deity.Name}, deity.Dogma...),
+		Speech:      []string{"reverent", "formal"},
+	}
+}
+
+// deityStoriesFor returns the story templates of deity's hooks for
+// questType, or nil for deity ""
+func (ne *NarrativeEngine) deityStoriesFor(questType pcg.QuestType, deity string) []*StoryTemplate {
+	if deity == "" {
+		return nil
+	}
+	var stories []*StoryTemplate
+	for _, story := range ne.deityStories[questType] {
+		if story.Deity == deity {
+			stories = append(stories, story)
+		}
+	}
+	return stories
+}
+
+// objectiveDeity returns the deity whose hook the main objective is, or ""
+func objectiveDeity(objectives []pcg.QuestObjective) string {
+	if len(objectives) == 0 {
+		return ""
+	}
+	deity, _ := objectives[0].Conditions["deity"].(string)
+	return deity
+}
+
+// firstTenet returns the first tenet of deity's dogma
+func firstTenet(deity game.Deity) string {
+	if len(deity.Dogma) == 0 {
+		return "Obey the will of " + deity.Name
+	}
+	return deity.Dogma[0]
+}
@@ -0,0 +1,88 @@
+package quests
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+func TestObjectiveBasedGenerator_InjectDeityHooks(t *testing.T) {
+	pantheon := &game.Pantheon{Deities: []game.Deity{
+		{ID: "deity_sol", Name: "Sol", Title: "the Radiant", Alignment: game.DeityGood, Dogma: []string{"Bring light to dark places"}},
+		{ID: "deity_mor", Name: "Mor", Title: "the Pale", Alignment: game.DeityEvil},
+	}}
+	obg := NewObjectiveBasedGenerator()
+
+	if injected := obg.InjectDeityHooks(pantheon); injected != 2 {
+		t.Fatalf("expected 2 deities injected, got %d", injected)
+	}
+	if injected := obg.InjectDeityHooks(pantheon); injected != 0 {
+		t.Errorf("expected a second injection to be skipped, got %d", injected)
+	}
+
+	if got := len(obg.deityObjectives[pcg.QuestTypeFetch]); got != 2 {
+		t.Errorf("expected a relic hook per deity, got %d", got)
+	}
+	if got := len(obg.deityObjectives[pcg.QuestTypeKill]); got != 1 {
+		t.Errorf("expected a cult hook for the evil deity only, got %d", got)
+	}
+	if _, ok := obg.narrativeEngine.deityGivers["deity_sol"]; !ok || len(obg.narrativeEngine.deityGivers) != 1 {
+		t.Errorf("expected a priest quest giver for the good deity only, got %v", obg.narrativeEngine.deityGivers)
+	}
+
+	// Deity hook quests tell their deity's story and serve no other deity
+	hooked := 0
+	for seed := int64(1); seed <= 60; seed++ {
+		quest, err := obg.GenerateQuest(context.Background(), pcg.QuestTypeFetch, hookParams(seed, true))
+		if err != nil {
+			t.Fatalf("GenerateQuest failed: %v", err)
+		}
+		if !strings.Contains(quest.Objectives[0].Description, "relic of") {
+			continue
+		}
+		hooked++
+		deity := "Sol"
+		if strings.Contains(quest.Objectives[0].Description, "Mor") {
+			deity = "Mor"
+		}
+		if !strings.HasPrefix(quest.Description, "A holy relic") || !strings.Contains(quest.Description, deity) {
+			t.Errorf("quest for %s tells another story: %s", deity, quest.Description)
+		}
+		for _, objective := range quest.Objectives[1:] {
+			if strings.Contains(objective.Description, "relic of") && !strings.Contains(objective.Description, deity) {
+				t.Errorf("quest for %s serves another deity: %s", deity, objective.Description)
+			}
+		}
+	}
+	if hooked == 0 || hooked == 60 {
+		t.Errorf("expected deity hooks to share the pool with other quests, got %d of 60", hooked)
+	}
+
+	// Disabled hooks leave quests as before the injection
+	plain := NewObjectiveBasedGenerator()
+	for seed := int64(1); seed <= 10; seed++ {
+		want, _ := plain.GenerateQuest(context.Background(), pcg.QuestTypeFetch, hookParams(seed, true))
+		got, _ := obg.GenerateQuest(context.Background(), pcg.QuestTypeFetch, hookParams(seed, false))
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("seed %d: disabled deity hooks changed the quest", seed)
+		}
+	}
+}
+
+// hookParams returns fetch quest parameters for seed with deity hooks
+// enabled or disabled
+func hookParams(seed int64, deityHooks bool) pcg.QuestParams {
+	return pcg.QuestParams{
+		GenerationParams: pcg.GenerationParams{
+			Seed:        seed,
+			Difficulty:  5,
+			Constraints: map[string]interface{}{"deity_hooks": deityHooks},
+		},
+		MinObjectives: 1,
+		MaxObjectives: 3,
+	}
+}
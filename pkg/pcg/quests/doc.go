@@ -6,9 +6,10 @@
 //
 // # Architecture
 //
-// The package consists of three main components:
+// The package consists of four main components:
 //
 //   - ObjectiveBasedGenerator: Creates complete quests using objective templates
+//   - QuestChainGenerator: Links quests into multi-stage story arcs
 //   - ObjectiveGenerator: Generates specific quest objectives (kill, collect, explore, etc.)
 //   - NarrativeEngine: Produces quest stories, dialogue, and lore
 //
@@ -29,6 +30,34 @@
 //	}
 //	result, err := generator.Generate(ctx, params)
 //
+// The "time_limit_hours" constraint gives the quest that many game hours to
+// complete once accepted. Delivery and escort objectives carry time limits
+// of their own from their templates (ObjectiveTemplate.TimeLimitHours).
+//
+// # Quest Chains
+//
+// The QuestChainGenerator builds a game.QuestChain: a spine of stages that
+// escalate in difficulty and end in a kill quest, given by a shared cast of
+// NPCs. A stage may branch into a fallback quest that unlocks only if the
+// stage fails:
+//
+//	generator := quests.NewQuestChainGenerator()
+//	chain, err := generator.GenerateChain(ctx, questParams, 4)
+//	err = player.StartQuestChain(*chain)
+//
+// Once tracked, completing a quest unlocks the quests that follow it, and
+// failing one fails every quest that depended on it.
+//
+// # Consequences
+//
+// When the "genre" constraint names a genre variant, generated quests also
+// carry world consequences. The "quest_giver", "faction" and "area"
+// constraints give their targets, and the genre weights what happens:
+// grimdark failures tend to kill the quest giver, high magic locks areas
+// and unlocks follow-up quests, and low fantasy mostly shifts faction
+// reputation. Follow-up quests are generated one difficulty step higher and
+// never unlock follow-ups of their own.
+//
 // # Objective Types
 //
 // The ObjectiveGenerator supports multiple objective types for different gameplay styles:
@@ -54,6 +83,13 @@
 //   - Start and end dialogue
 //   - Contextual lore elements
 //
+// GenerateRecap tells a play session's timeline of RecapEvents (quests,
+// kills, discoveries and level ups) as a "story so far" recap, with one
+// paragraph per finished quest:
+//
+//	recap := engine.GenerateRecap(events, rng)
+//	fmt.Println(recap.Text)
+//
 // # Templates
 //
 // Quest generation uses configurable templates for objectives and stories:
@@ -66,9 +102,21 @@
 //		Rewards:     []string{"gold", "experience"},
 //	}
 //
+// # Deity Quest Hooks
+//
+// InjectDeityHooks adds quest hooks for a world's deities to the pools: a
+// stolen relic and a lost shrine for every deity and a cult to purge for
+// evil ones, given by the priests of the others. The hooks of a quest type share one
+// slot of its pool, and a quest's objectives serve a single deity. The
+// "deity_hooks" constraint set to false leaves them out, as personal quests
+// do to stay independent of the world seed:
+//
+//	generator.InjectDeityHooks(world.Pantheon)
+//
 // # Thread Safety
 //
-// Quest generators are safe for concurrent use. Each generation call creates its own
+// Quest generators are safe for concurrent use once their deity hooks are
+// injected. Each generation call creates its own
 // RNG state from the provided seed, ensuring deterministic results without shared
 // mutable state.
 //
@@ -13,6 +13,7 @@ import (
 type ObjectiveBasedGenerator struct {
 	version            string
 	objectiveTemplates map[pcg.QuestType][]*ObjectiveTemplate
+	deityObjectives    map[pcg.QuestType][]*ObjectiveTemplate // Objective templates of deity quest hooks
 	narrativeEngine    *NarrativeEngine
 }
 
@@ -24,6 +25,11 @@ type ObjectiveTemplate struct {
 	Targets      []string `yaml:"targets"`
 	Quantities   [2]int   `yaml:"quantities"`
 	Rewards      []string `yaml:"rewards"`
+	Deity        string   `yaml:"deity,omitempty"` // Deity whose quest hook this is
+
+	// TimeLimitHours gives objectives from the template that many game
+	// hours to finish, 0 for no limit
+	TimeLimitHours int `yaml:"time_limit_hours,omitempty"`
 }
 
 // NewObjectiveBasedGenerator creates a new objective-based quest generator
@@ -31,6 +37,7 @@ func NewObjectiveBasedGenerator() *ObjectiveBasedGenerator {
 	obg := &ObjectiveBasedGenerator{
 		version:            "1.0.0",
 		objectiveTemplates: make(map[pcg.QuestType][]*ObjectiveTemplate),
+		deityObjectives:    make(map[pcg.QuestType][]*ObjectiveTemplate),
 		narrativeEngine:    NewNarrativeEngine(),
 	}
 
@@ -70,6 +77,17 @@ func (obg *ObjectiveBasedGenerator) Validate(params pcg.GenerationParams) error
 		return fmt.Errorf("max_objectives must be >= min_objectives")
 	}
 
+	if hours, ok := params.Constraints["time_limit_hours"].(int); ok && hours < 0 {
+		return fmt.Errorf("time_limit_hours cannot be negative")
+	}
+
+	// Validate genre variant if provided
+	if genre, ok := params.Constraints["genre"].(string); ok {
+		if _, known := consequenceProfiles[pcg.GenreType(genre)]; !known {
+			return fmt.Errorf("unknown genre %q", genre)
+		}
+	}
+
 	return nil
 }
 
@@ -115,10 +133,10 @@ func (obg *ObjectiveBasedGenerator) Generate(ctx context.Context, params pcg.Gen
 // GenerateQuest creates a quest with objectives and narrative
 func (obg *ObjectiveBasedGenerator) GenerateQuest(ctx context.Context, questType pcg.QuestType, params pcg.QuestParams) (*game.Quest, error) {
 	// Create deterministic random generator from seed
-	rng := rand.New(rand.NewSource(params.Seed))
+	rng := pcg.NewRNG(params.Seed, "quests")
 
 	// Generate quest ID
-	questID := fmt.Sprintf("quest_%d_%s", params.Seed, questType)
+	questID := game.SeededID(game.IDNamespaceQuest.Kind(string(questType)), params.Seed, "")
 
 	// Generate objectives
 	objectiveCount := rng.Intn(params.MaxObjectives-params.MinObjectives+1) + params.MinObjectives
@@ -139,25 +157,37 @@ func (obg *ObjectiveBasedGenerator) GenerateQuest(ctx context.Context, questType
 		return nil, fmt.Errorf("failed to generate rewards: %w", err)
 	}
 
+	// Generate world consequences last so they leave the rest of the quest
+	// unchanged for a given seed
+	consequences, err := obg.generateConsequences(ctx, questType, params, rng)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert objectives to game format
 	gameObjectives := make([]game.QuestObjective, len(objectives))
 	for i, obj := range objectives {
 		gameObjectives[i] = game.QuestObjective{
-			Description: obj.Description,
-			Progress:    0,
-			Required:    obj.Quantity,
-			Completed:   false,
+			Description:    obj.Description,
+			Progress:       0,
+			Required:       obj.Quantity,
+			Completed:      false,
+			TimeLimitHours: obj.TimeLimitHours,
 		}
 	}
 
 	// Create the quest
 	quest := &game.Quest{
-		ID:          questID,
-		Title:       narrative.Title,
-		Description: narrative.Description,
-		Status:      game.QuestNotStarted,
-		Objectives:  gameObjectives,
-		Rewards:     rewards,
+		ID:           questID,
+		Title:        narrative.Title,
+		Description:  narrative.Description,
+		Status:       game.QuestNotStarted,
+		Objectives:   gameObjectives,
+		Rewards:      rewards,
+		Consequences: consequences,
+	}
+	if hours, ok := params.Constraints["time_limit_hours"].(int); ok {
+		quest.TimeLimitHours = hours
 	}
 
 	return quest, nil
@@ -170,7 +200,7 @@ func (obg *ObjectiveBasedGenerator) GenerateQuestChain(ctx context.Context, chai
 	}
 
 	quests := make([]*game.Quest, 0, chainLength)
-	rng := rand.New(rand.NewSource(params.Seed))
+	rng := pcg.NewRNG(params.Seed, "quest_chains")
 
 	for i := 0; i < chainLength; i++ {
 		// Create modified parameters for this quest in the chain
@@ -201,7 +231,7 @@ func (obg *ObjectiveBasedGenerator) GenerateQuestChain(ctx context.Context, chai
 
 // GenerateObjectives creates quest objectives based on available content
 func (obg *ObjectiveBasedGenerator) GenerateObjectives(ctx context.Context, world *game.World, params pcg.QuestParams) ([]pcg.QuestObjective, error) {
-	rng := rand.New(rand.NewSource(params.Seed))
+	rng := pcg.NewRNG(params.Seed, "objectives")
 	objectiveCount := rng.Intn(params.MaxObjectives-params.MinObjectives+1) + params.MinObjectives
 
 	return obg.generateObjectives(ctx, params.QuestType, objectiveCount, params, rng)
@@ -215,10 +245,12 @@ func (obg *ObjectiveBasedGenerator) generateObjectives(ctx context.Context, ques
 	}
 
 	objectives := make([]pcg.QuestObjective, 0, count)
+	hooks := obg.deityHookPool(questType, params)
+	deity := ""
 
 	for i := 0; i < count; i++ {
 		// Select random template
-		template := templates[rng.Intn(len(templates))]
+		template := selectObjectiveTemplate(templates, hooks, i == 0, deity, rng)
 
 		// Determine quantity based on difficulty and template
 		minQty, maxQty := template.Quantities[0], template.Quantities[1]
@@ -242,15 +274,20 @@ func (obg *ObjectiveBasedGenerator) generateObjectives(ctx context.Context, ques
 		}
 
 		objective := pcg.QuestObjective{
-			ID:          fmt.Sprintf("obj_%d_%d", params.Seed, i),
-			Type:        template.Type,
-			Description: template.Description,
-			Target:      target,
-			Quantity:    quantity,
-			Progress:    0,
-			Complete:    false,
-			Optional:    i >= 1 && rng.Float32() < 0.3, // 30% chance for optional objectives after first
-			Conditions:  make(map[string]interface{}),
+			ID:             fmt.Sprintf("obj_%d_%d", params.Seed, i),
+			Type:           template.Type,
+			Description:    template.Description,
+			Target:         target,
+			Quantity:       quantity,
+			Progress:       0,
+			Complete:       false,
+			Optional:       i >= 1 && rng.Float32() < 0.3, // 30% chance for optional objectives after first
+			Conditions:     make(map[string]interface{}),
+			TimeLimitHours: template.TimeLimitHours,
+		}
+		if template.Deity != "" {
+			deity = template.Deity
+			objective.Conditions["deity"] = deity
 		}
 
 		objectives = append(objectives, objective)
@@ -357,24 +394,26 @@ func (obg *ObjectiveBasedGenerator) initializeDefaultTemplates() {
 	// Delivery quest templates
 	obg.objectiveTemplates[pcg.QuestTypeDelivery] = []*ObjectiveTemplate{
 		{
-			Type:         "deliver",
-			Description:  "Transport the package safely",
-			Requirements: []string{"movement"},
-			Targets:      []string{"merchant", "guard", "scholar", "noble"},
-			Quantities:   [2]int{1, 3},
-			Rewards:      []string{"exp", "gold"},
+			Type:           "deliver",
+			Description:    "Transport the package safely",
+			Requirements:   []string{"movement"},
+			Targets:        []string{"merchant", "guard", "scholar", "noble"},
+			Quantities:     [2]int{1, 3},
+			Rewards:        []string{"exp", "gold"},
+			TimeLimitHours: 72,
 		},
 	}
 
 	// Escort quest templates
 	obg.objectiveTemplates[pcg.QuestTypeEscort] = []*ObjectiveTemplate{
 		{
-			Type:         "escort",
-			Description:  "Guide the traveler to safety",
-			Requirements: []string{"movement", "protection"},
-			Targets:      []string{"merchant", "diplomat", "pilgrim"},
-			Quantities:   [2]int{1, 1},
-			Rewards:      []string{"exp", "gold"},
+			Type:           "escort",
+			Description:    "Guide the traveler to safety",
+			Requirements:   []string{"movement", "protection"},
+			Targets:        []string{"merchant", "diplomat", "pilgrim"},
+			Quantities:     [2]int{1, 1},
+			Rewards:        []string{"exp", "gold"},
+			TimeLimitHours: 48,
 		},
 	}
 }
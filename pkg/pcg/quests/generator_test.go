@@ -108,6 +108,19 @@ func TestObjectiveBasedGenerator_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative time limit",
+			params: pcg.GenerationParams{
+				Seed:        12345,
+				Difficulty:  5,
+				PlayerLevel: 3,
+				Constraints: map[string]interface{}{
+					"time_limit_hours": -1,
+				},
+				Timeout: 30 * time.Second,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -593,3 +606,47 @@ func TestDeterministicGeneration(t *testing.T) {
 		t.Errorf("expected same number of rewards, got %d and %d", len(quest1.Rewards), len(quest2.Rewards))
 	}
 }
+
+func TestObjectiveBasedGenerator_TimeLimits(t *testing.T) {
+	generator := NewObjectiveBasedGenerator()
+	ctx := context.Background()
+
+	params := pcg.GenerationParams{
+		Seed:        12345,
+		Difficulty:  5,
+		PlayerLevel: 3,
+		Constraints: map[string]interface{}{
+			"quest_type":       string(pcg.QuestTypeKill),
+			"time_limit_hours": 120,
+		},
+		Timeout: 30 * time.Second,
+	}
+	result, err := generator.Generate(ctx, params)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	quest := result.(*game.Quest)
+	if quest.TimeLimitHours != 120 {
+		t.Errorf("expected quest time limit 120, got %d", quest.TimeLimitHours)
+	}
+	for i, objective := range quest.Objectives {
+		if objective.TimeLimitHours != 0 {
+			t.Errorf("objective %d: expected no time limit on kill objectives, got %d", i, objective.TimeLimitHours)
+		}
+	}
+
+	objectives, err := generator.GenerateObjectives(ctx, &game.World{}, pcg.QuestParams{
+		GenerationParams: params,
+		QuestType:        pcg.QuestTypeEscort,
+		MinObjectives:    1,
+		MaxObjectives:    2,
+	})
+	if err != nil {
+		t.Fatalf("GenerateObjectives() error = %v", err)
+	}
+	for i, objective := range objectives {
+		if objective.TimeLimitHours != 48 {
+			t.Errorf("objective %d: expected escort time limit 48, got %d", i, objective.TimeLimitHours)
+		}
+	}
+}
@@ -11,6 +11,10 @@ import (
 type NarrativeEngine struct {
 	storyTemplates map[pcg.QuestType][]*StoryTemplate
 	characterPool  []*NPCTemplate
+	recapTemplates map[string][]string                // Recap sentences by RecapEvent kind
+	deityStories   map[pcg.QuestType][]*StoryTemplate // Story templates of deity quest hooks
+	deityGivers    map[string]*NPCTemplate            // Priest quest givers by deity ID
+	deityHooks     map[string]bool                    // Deities whose quest hooks were injected
 }
 
 // StoryTemplate defines narrative structure
@@ -22,6 +26,7 @@ type StoryTemplate struct {
 	Resolution string   `yaml:"resolution"`
 	Characters []string `yaml:"characters"`
 	Locations  []string `yaml:"locations"`
+	Deity      string   `yaml:"deity,omitempty"` // Deity whose quest hook this is
 }
 
 // NPCTemplate defines quest-giver characteristics
@@ -47,6 +52,10 @@ func NewNarrativeEngine() *NarrativeEngine {
 	ne := &NarrativeEngine{
 		storyTemplates: make(map[pcg.QuestType][]*StoryTemplate),
 		characterPool:  make([]*NPCTemplate, 0),
+		recapTemplates: make(map[string][]string),
+		deityStories:   make(map[pcg.QuestType][]*StoryTemplate),
+		deityGivers:    make(map[string]*NPCTemplate),
+		deityHooks:     make(map[string]bool),
 	}
 
 	// Initialize default templates
@@ -58,6 +67,10 @@ func NewNarrativeEngine() *NarrativeEngine {
 // GenerateQuestNarrative creates story context for a quest
 func (ne *NarrativeEngine) GenerateQuestNarrative(questType pcg.QuestType, objectives []pcg.QuestObjective, params pcg.QuestParams, rng *rand.Rand) (*QuestNarrative, error) {
 	templates, exists := ne.storyTemplates[questType]
+	deity := objectiveDeity(objectives)
+	if stories := ne.deityStoriesFor(questType, deity); len(stories) > 0 {
+		templates, exists = stories, true
+	}
 	if !exists || len(templates) == 0 {
 		return nil, fmt.Errorf("no story templates available for quest type: %s", questType)
 	}
@@ -65,8 +78,11 @@ func (ne *NarrativeEngine) GenerateQuestNarrative(questType pcg.QuestType, objec
 	// Select random template
 	template := templates[rng.Intn(len(templates))]
 
-	// Select quest giver
+	// Select quest giver; a deity's priest gives the quests of its hooks
 	questGiver := ne.selectQuestGiver(rng)
+	if priest, ok := ne.deityGivers[deity]; ok {
+		questGiver = priest
+	}
 
 	// Generate title based on quest type and objectives
 	title := ne.generateTitle(questType, objectives, rng)
@@ -266,4 +282,32 @@ func (ne *NarrativeEngine) initializeDefaultTemplates() {
 			Speech:      []string{"verbose", "precise"},
 		},
 	}
+
+	// Recap sentences; {actors} and {subjects} are filled in by GenerateRecap
+	ne.recapTemplates = map[string][]string{
+		RecapQuestStarted: {
+			"{actors} took up the quest {subjects}.",
+			"Answering a call for help, {actors} set out on {subjects}.",
+		},
+		RecapQuestCompleted: {
+			"{actors} saw {subjects} through to the end.",
+			"With {subjects} complete, {actors} claimed their reward.",
+		},
+		RecapQuestFailed: {
+			"{actors} failed in {subjects}.",
+			"Despite their efforts, {subjects} ended in failure for {actors}.",
+		},
+		RecapKill: {
+			"{actors} slew {subjects}.",
+			"Steel rang as {actors} cut down {subjects}.",
+		},
+		RecapDiscovery: {
+			"{actors} discovered {subjects}.",
+			"Searching carefully, {actors} came upon {subjects}.",
+		},
+		RecapLevelUp: {
+			"{actors} grew in power, reaching {subjects}.",
+			"Hardened by their trials, {actors} advanced to {subjects}.",
+		},
+	}
 }
@@ -0,0 +1,144 @@
+package quests
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of RecapEvent
+const (
+	RecapQuestStarted   = "quest_started"
+	RecapQuestCompleted = "quest_completed"
+	RecapQuestFailed    = "quest_failed"
+	RecapKill           = "kill"
+	RecapDiscovery      = "discovery"
+	RecapLevelUp        = "level_up"
+)
+
+// recapTitle heads every session recap
+const recapTitle = "The Story So Far"
+
+// RecapEvent is one notable moment of a play session
+type RecapEvent struct {
+	Kind    string    `yaml:"kind" json:"kind"`       // One of the Recap* kinds
+	Time    time.Time `yaml:"time" json:"time"`       // When it happened
+	Actor   string    `yaml:"actor" json:"actor"`     // Name of the character involved
+	Subject string    `yaml:"subject" json:"subject"` // Quest title, foe slain, thing found or level reached
+}
+
+// SessionRecap is the narrative summary of a play session
+type SessionRecap struct {
+	Title      string       `yaml:"title" json:"title"`
+	Timeline   []RecapEvent `yaml:"timeline" json:"timeline"`     // Events in chronological order
+	Paragraphs []string     `yaml:"paragraphs" json:"paragraphs"` // One per chapter of the story
+	Text       string       `yaml:"text" json:"text"`             // Title and paragraphs, ready to display
+}
+
+// recapMoment is a run of consecutive events of the same kind, told as one
+// sentence
+type recapMoment struct {
+	kind     string
+	actors   []string
+	subjects []string
+	counts   map[string]int
+}
+
+// GenerateRecap tells the events of a session as a "story so far". Events
+// are put in chronological order, consecutive events of the same kind are
+// told in one sentence, and each completed or failed quest closes a
+// paragraph. The same events and rng seed always produce the same text.
+func (ne *NarrativeEngine) GenerateRecap(events []RecapEvent, rng *rand.Rand) *SessionRecap {
+	timeline := append([]RecapEvent(nil), events...)
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+
+	recap := &SessionRecap{Title: recapTitle, Timeline: timeline}
+	var sentences []string
+	for _, moment := range groupRecapMoments(timeline) {
+		sentences = append(sentences, ne.recapSentence(moment, rng))
+		if moment.kind == RecapQuestCompleted || moment.kind == RecapQuestFailed {
+			recap.Paragraphs = append(recap.Paragraphs, strings.Join(sentences, " "))
+			sentences = nil
+		}
+	}
+	if len(sentences) > 0 {
+		recap.Paragraphs = append(recap.Paragraphs, strings.Join(sentences, " "))
+	}
+	if len(recap.Paragraphs) == 0 {
+		recap.Paragraphs = []string{"The story has yet to begin."}
+	}
+
+	recap.Text = recap.Title + "\n\n" + strings.Join(recap.Paragraphs, "\n\n")
+	return recap
+}
+
+// groupRecapMoments merges runs of consecutive events of the same kind,
+// keeping each actor and subject once in order of appearance
+func groupRecapMoments(timeline []RecapEvent) []*recapMoment {
+	var moments []*recapMoment
+	for _, event := range timeline {
+		var moment *recapMoment
+		if n := len(moments); n > 0 && moments[n-1].kind == event.Kind {
+			moment = moments[n-1]
+		} else {
+			moment = &recapMoment{kind: event.Kind, counts: make(map[string]int)}
+			moments = append(moments, moment)
+		}
+		if event.Actor != "" && !containsString(moment.actors, event.Actor) {
+			moment.actors = append(moment.actors, event.Actor)
+		}
+		if moment.counts[event.Subject] == 0 {
+			moment.subjects = append(moment.subjects, event.Subject)
+		}
+		moment.counts[event.Subject]++
+	}
+	return moments
+}
+
+// recapSentence tells a moment with one of the kind's recap templates
+func (ne *NarrativeEngine) recapSentence(moment *recapMoment, rng *rand.Rand) string {
+	subjects := make([]string, len(moment.subjects))
+	for i, subject := range moment.subjects {
+		subjects[i] = subject
+		// Quest events repeat for each party member, so only foes are counted
+		if count := moment.counts[subject]; count > 1 && moment.kind == RecapKill {
+			subjects[i] = fmt.Sprintf("%d %s", count, subject)
+		}
+	}
+
+	templates := ne.recapTemplates[moment.kind]
+	if len(templates) == 0 {
+		templates = []string{"{actors}: {subjects}."}
+	}
+	sentence := strings.NewReplacer(
+		"{actors}", joinRecapNames(moment.actors),
+		"{subjects}", joinRecapNames(subjects),
+	).Replace(templates[rng.Intn(len(templates))])
+	return strings.ToUpper(sentence[:1]) + sentence[1:]
+}
+
+// joinRecapNames lists names as "a", "a and b" or "a, b and c"
+func joinRecapNames(names []string) string {
+	switch len(names) {
+	case 0:
+		return "the party"
+	case 1:
+		return names[0]
+	default:
+		return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
+}
+
+// containsString reports whether values holds value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package quests
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNarrativeEngine_GenerateRecap(t *testing.T) {
+	engine := NewNarrativeEngine()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	// Out of order, as a merged party timeline may be
+	events := []RecapEvent{
+		{Kind: RecapKill, Time: at(2), Actor: "Aria", Subject: "Goblin"},
+		{Kind: RecapQuestStarted, Time: at(1), Actor: "Aria", Subject: "The Goblin Caves"},
+		{Kind: RecapKill, Time: at(3), Actor: "Bron", Subject: "Goblin"},
+		{Kind: RecapKill, Time: at(4), Actor: "Aria", Subject: "Goblin Chief"},
+		{Kind: RecapQuestCompleted, Time: at(5), Actor: "Aria", Subject: "The Goblin Caves"},
+		{Kind: RecapQuestCompleted, Time: at(5), Actor: "Bron", Subject: "The Goblin Caves"},
+		{Kind: RecapDiscovery, Time: at(6), Actor: "Bron", Subject: "a secret door"},
+		{Kind: RecapLevelUp, Time: at(7), Actor: "Aria", Subject: "level 3"},
+	}
+
+	recap := engine.GenerateRecap(events, rand.New(rand.NewSource(1)))
+
+	if recap.Timeline[0].Kind != RecapQuestStarted {
+		t.Errorf("timeline should start with the quest, got %s", recap.Timeline[0].Kind)
+	}
+	if len(recap.Paragraphs) != 2 {
+		t.Fatalf("expected the completed quest to close a paragraph, got %q", recap.Paragraphs)
+	}
+	first := recap.Paragraphs[0]
+	for _, want := range []string{"Aria and Bron", "2 Goblin and Goblin Chief", "The Goblin Caves"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("first paragraph %q should mention %q", first, want)
+		}
+	}
+	if strings.Count(first, "The Goblin Caves") != 2 {
+		t.Errorf("the party's completion should be told once, got %q", first)
+	}
+	if !strings.Contains(recap.Paragraphs[1], "a secret door") || !strings.Contains(recap.Paragraphs[1], "level 3") {
+		t.Errorf("second paragraph %q should tell the discovery and level up", recap.Paragraphs[1])
+	}
+	if !strings.HasPrefix(recap.Text, "The Story So Far\n\n") {
+		t.Errorf("text should open with the title, got %q", recap.Text)
+	}
+
+	again := engine.GenerateRecap(events, rand.New(rand.NewSource(1)))
+	if again.Text != recap.Text {
+		t.Error("the same events and seed should tell the same story")
+	}
+}
+
+func TestNarrativeEngine_GenerateRecapEmpty(t *testing.T) {
+	recap := NewNarrativeEngine().GenerateRecap(nil, rand.New(rand.NewSource(1)))
+	if len(recap.Paragraphs) != 1 || recap.Text == "" {
+		t.Errorf("an empty session should still have a recap, got %+v", recap)
+	}
+}
+
+func TestJoinRecapNames(t *testing.T) {
+	tests := map[string][]string{
+		"the party":     nil,
+		"Aria":          {"Aria"},
+		"Aria and Bron": {"Aria", "Bron"},
+		"A, B and C":    {"A", "B", "C"},
+	}
+	for want, names := range tests {
+		if got := joinRecapNames(names); got != want {
+			t.Errorf("joinRecapNames(%q) = %q, want %q", names, got, want)
+		}
+	}
+}
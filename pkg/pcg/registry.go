@@ -16,6 +16,7 @@ type Registry struct {
 	mu         sync.RWMutex
 	generators map[ContentType]map[string]Generator
 	logger     *logrus.Logger
+	cache      *ContentCache
 }
 
 // NewRegistry creates a new generator registry
@@ -49,6 +50,9 @@ func (r *Registry) RegisterGenerator(name string, generator Generator) error {
 	}
 
 	r.generators[contentType][name] = generator
+	if r.cache != nil {
+		r.cache.Invalidate(contentType)
+	}
 
 	r.logger.WithFields(logrus.Fields{
 		"generator":    name,
@@ -73,6 +77,9 @@ func (r *Registry) UnregisterGenerator(contentType ContentType, name string) err
 	}
 
 	delete(r.generators[contentType], name)
+	if r.cache != nil {
+		r.cache.Invalidate(contentType)
+	}
 
 	r.logger.WithFields(logrus.Fields{
 		"generator":    name,
@@ -82,6 +89,15 @@ func (r *Registry) UnregisterGenerator(contentType ContentType, name string) err
 	return nil
 }
 
+// SetCache makes GenerateContent return cached content for repeated
+// generations of the content types the cache is enabled for. A nil cache
+// turns caching off.
+func (r *Registry) SetCache(cache *ContentCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = cache
+}
+
 // GetGenerator retrieves a specific generator by content type and name
 func (r *Registry) GetGenerator(contentType ContentType, name string) (Generator, error) {
 	r.mu.RLock()
@@ -145,6 +161,27 @@ func (r *Registry) GenerateContent(ctx context.Context, contentType ContentType,
 		return nil, fmt.Errorf("parameter validation failed: %w", err)
 	}
 
+	// Return the earlier result of the same generation when cached
+	r.mu.RLock()
+	cache := r.cache
+	r.mu.RUnlock()
+	var cacheKey string
+	if cache != nil && cache.Enabled(contentType) {
+		key, err := ContentCacheKey(contentType, generatorName, params)
+		if err != nil {
+			r.logger.WithError(err).Warn("Generation parameters cannot be cached")
+		} else if content, hit := cache.Get(key); hit {
+			r.logger.WithFields(logrus.Fields{
+				"generator":    generatorName,
+				"content_type": contentType,
+				"seed":         params.Seed,
+			}).Debug("Returning cached content")
+			return content, nil
+		} else {
+			cacheKey = key
+		}
+	}
+
 	r.logger.WithFields(logrus.Fields{
 		"generator":    generatorName,
 		"content_type": contentType,
@@ -171,6 +208,11 @@ func (r *Registry) GenerateContent(ctx context.Context, contentType ContentType,
 			"generator":    generatorName,
 			"content_type": contentType,
 		}).Info("Content generation completed successfully")
+		if cacheKey != "" {
+			if err := cache.Put(contentType, cacheKey, result); err != nil {
+				r.logger.WithError(err).Warn("Failed to cache generated content")
+			}
+		}
 		return result, nil
 
 	case err := <-errorChan:
@@ -0,0 +1,48 @@
+package pcg
+
+import (
+	"math/rand"
+	"sync"
+
+	"goldbox-rpg/pkg/pcg/utils"
+)
+
+var (
+	rngMu        sync.RWMutex
+	rngAlgorithm = utils.RNGMath
+)
+
+// SetRNGAlgorithm selects the algorithm of the random number generators
+// created by NewRNG and by seed managers created afterwards. utils.RNGMath,
+// the default, reproduces content generated before the algorithm could be
+// chosen; utils.RNGPCG64 and utils.RNGXoshiro256 give every generator its
+// own stream.
+func SetRNGAlgorithm(alg utils.RNGAlgorithm) {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	rngAlgorithm = alg
+}
+
+// GetRNGAlgorithm returns the algorithm selected with SetRNGAlgorithm.
+func GetRNGAlgorithm() utils.RNGAlgorithm {
+	rngMu.RLock()
+	defer rngMu.RUnlock()
+	return rngAlgorithm
+}
+
+// NewRNG creates the random number generator a generator draws from when
+// seeded with seed. The stream names the generator, so generators given
+// the same seed draw unrelated numbers.
+func NewRNG(seed int64, stream string) *rand.Rand {
+	return newStreamRNG(GetRNGAlgorithm(), seed, stream)
+}
+
+// newStreamRNG creates a generator of alg on stream. The math/rand
+// algorithm keeps its legacy sequence and ignores the stream, so existing
+// seeds produce the content they always have.
+func newStreamRNG(alg utils.RNGAlgorithm, seed int64, stream string) *rand.Rand {
+	if alg == utils.RNGMath || alg == "" {
+		return rand.New(rand.NewSource(seed))
+	}
+	return utils.NewRand(alg, seed, stream)
+}
@@ -0,0 +1,57 @@
+package pcg
+
+import (
+	"math/rand"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg/utils"
+)
+
+func TestNewRNG_MathKeepsLegacySequences(t *testing.T) {
+	legacy := rand.New(rand.NewSource(1234))
+	rng := NewRNG(1234, "monsters")
+	for i := 0; i < 10; i++ {
+		if got, want := rng.Int63(), legacy.Int63(); got != want {
+			t.Fatalf("draw %d = %d, want the math/rand sequence %d", i, got, want)
+		}
+	}
+}
+
+func TestSeedManager_RNGAlgorithm(t *testing.T) {
+	SetRNGAlgorithm(utils.RNGPCG64)
+	defer SetRNGAlgorithm(utils.RNGMath)
+
+	sm := NewSeedManager(42)
+	if sm.RNGAlgorithm() != utils.RNGPCG64 {
+		t.Fatalf("RNGAlgorithm() = %s, want pcg64", sm.RNGAlgorithm())
+	}
+
+	params := GenerationParams{Difficulty: 3}
+	first := sm.CreateRNG(ContentTypeTerrain, "region", params).Uint64()
+	if again := sm.CreateRNG(ContentTypeTerrain, "region", params).Uint64(); again != first {
+		t.Errorf("CreateRNG is not reproducible: %d then %d", first, again)
+	}
+	if chunk := sm.CreateChunkRNG(ContentTypeTerrain, "region", params, 0).Uint64(); chunk != first {
+		t.Errorf("chunk 0 should start the stream, got %d want %d", chunk, first)
+	}
+	if chunk := sm.CreateChunkRNG(ContentTypeTerrain, "region", params, 1).Uint64(); chunk == first {
+		t.Error("chunk 1 should draw from a later part of the stream")
+	}
+
+	// A world saved with pcg64 keeps it when the default changes
+	state := sm.GetSaveableState()
+	SetRNGAlgorithm(utils.RNGMath)
+	restored := NewSeedManager(1)
+	restored.LoadState(state)
+	if restored.RNGAlgorithm() != utils.RNGPCG64 {
+		t.Errorf("restored algorithm = %s, want pcg64", restored.RNGAlgorithm())
+	}
+	if got := restored.CreateRNG(ContentTypeTerrain, "region", params).Uint64(); got != first {
+		t.Errorf("restored seed manager drew %d, want %d", got, first)
+	}
+
+	restored.LoadState(SaveableState{BaseSeed: 42})
+	if restored.RNGAlgorithm() != utils.RNGMath {
+		t.Errorf("saves without an algorithm should load as math, got %s", restored.RNGAlgorithm())
+	}
+}
@@ -0,0 +1,200 @@
+package pcg
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ContentRule is a validation check a ContentValidator runs over content of
+// one type. The built-in rules are ValidationRule values; game modules can
+// implement ContentRule directly and contribute their rules in a RulePack.
+type ContentRule interface {
+	// RuleName identifies the rule in configuration, logs and metrics.
+	// Names must be unique across the rules a validator runs.
+	RuleName() string
+
+	// DefaultSeverity is the severity of a failure unless configuration
+	// overrides it.
+	DefaultSeverity() ValidationSeverity
+
+	// Validate checks content and reports the outcome. Content of an
+	// unexpected type should fail with a message saying so.
+	Validate(content interface{}) Result
+}
+
+// RuleName returns the rule's Name
+func (r ValidationRule) RuleName() string {
+	return r.Name
+}
+
+// DefaultSeverity returns the rule's Severity
+func (r ValidationRule) DefaultSeverity() ValidationSeverity {
+	return r.Severity
+}
+
+// Validate runs the rule's Validator
+func (r ValidationRule) Validate(content interface{}) Result {
+	return r.Validator(content)
+}
+
+// RulePack is a named set of validation rules contributed by a game module,
+// for example house rules such as "no cursed items below level 3". Packs are
+// registered once with RegisterRulePack and enabled per validator by name.
+type RulePack struct {
+	Name        string                        // Unique name used to enable the pack
+	Description string                        // What the pack checks
+	Rules       map[ContentType][]ContentRule // Rules by the content type they check
+}
+
+var (
+	rulePacksMu sync.RWMutex
+	rulePacks   = make(map[string]RulePack)
+)
+
+// RegisterRulePack makes a rule pack available to every ContentValidator.
+// Game modules usually call it from an init function.
+func RegisterRulePack(pack RulePack) error {
+	if pack.Name == "" {
+		return fmt.Errorf("rule pack name is required")
+	}
+	for contentType, rules := range pack.Rules {
+		for _, rule := range rules {
+			if rule == nil || rule.RuleName() == "" {
+				return fmt.Errorf("rule pack %s has an unnamed %s rule", pack.Name, contentType)
+			}
+		}
+	}
+
+	rulePacksMu.Lock()
+	defer rulePacksMu.Unlock()
+
+	if _, exists := rulePacks[pack.Name]; exists {
+		return fmt.Errorf("rule pack %s is already registered", pack.Name)
+	}
+	rulePacks[pack.Name] = pack
+	return nil
+}
+
+// RulePacks returns the names of all registered rule packs in sorted order
+func RulePacks() []string {
+	rulePacksMu.RLock()
+	defer rulePacksMu.RUnlock()
+
+	names := make([]string, 0, len(rulePacks))
+	for name := range rulePacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidatorConfig selects the rule packs a ContentValidator runs and
+// adjusts individual rules. It is usually loaded from YAML:
+//
+//	rule_packs: [house_rules]
+//	rules:
+//	  character_name_not_empty:
+//	    severity: warning
+//	  boss_room_two_exits:
+//	    enabled: false
+type ValidatorConfig struct {
+	RulePacks []string              `yaml:"rule_packs"` // Registered packs to enable
+	Rules     map[string]RuleConfig `yaml:"rules"`      // Settings by rule name
+}
+
+// RuleConfig adjusts one validation rule. Unset fields keep the rule's
+// defaults.
+type RuleConfig struct {
+	Enabled  *bool              `yaml:"enabled,omitempty"`  // Whether the rule runs; default true
+	Severity ValidationSeverity `yaml:"severity,omitempty"` // Overrides the rule's default severity
+}
+
+// LoadValidatorConfig reads a validator configuration from a YAML file
+func LoadValidatorConfig(path string) (*ValidatorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read validator config %s: %w", path, err)
+	}
+
+	var config ValidatorConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML from %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// UseRulePack adds the rules of a registered rule pack to the validator.
+// Enabling a pack twice has no further effect.
+func (cv *ContentValidator) UseRulePack(name string) error {
+	rulePacksMu.RLock()
+	pack, exists := rulePacks[name]
+	rulePacksMu.RUnlock()
+	if !exists {
+		return fmt.Errorf("unknown rule pack: %s", name)
+	}
+
+	cv.mu.Lock()
+	if cv.rulePacks[name] {
+		cv.mu.Unlock()
+		return nil
+	}
+	cv.rulePacks[name] = true
+	cv.mu.Unlock()
+
+	for contentType, rules := range pack.Rules {
+		for _, rule := range rules {
+			cv.RegisterRule(contentType, rule)
+		}
+	}
+
+	cv.logger.WithField("rule_pack", name).Info("enabled validation rule pack")
+	return nil
+}
+
+// ApplyConfig enables the configured rule packs and applies the rule
+// settings, replacing any applied before. Settings for rules the validator
+// does not have and unknown severities are rejected so typos do not
+// silently leave a rule unchanged.
+func (cv *ContentValidator) ApplyConfig(config *ValidatorConfig) error {
+	for _, name := range config.RulePacks {
+		if err := cv.UseRulePack(name); err != nil {
+			return err
+		}
+	}
+
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	known := make(map[string]bool)
+	for _, rules := range cv.validationRules {
+		for _, rule := range rules {
+			known[rule.RuleName()] = true
+		}
+	}
+
+	settings := make(map[string]RuleConfig, len(config.Rules))
+	for name, setting := range config.Rules {
+		if !known[name] {
+			return fmt.Errorf("unknown validation rule: %s", name)
+		}
+		if setting.Severity != "" && !validSeverity(setting.Severity) {
+			return fmt.Errorf("rule %s has unknown severity %q", name, setting.Severity)
+		}
+		settings[name] = setting
+	}
+	cv.ruleSettings = settings
+	return nil
+}
+
+// validSeverity reports whether severity is one of the defined levels
+func validSeverity(severity ValidationSeverity) bool {
+	switch severity {
+	case SeverityInfo, SeverityWarning, SeverityError, SeverityCritical:
+		return true
+	}
+	return false
+}
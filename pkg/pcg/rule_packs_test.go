@@ -0,0 +1,104 @@
+package pcg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minimumLevelRule fails characters below a level, standing in for a game
+// module's custom rule
+type minimumLevelRule struct {
+	level int
+}
+
+func (r minimumLevelRule) RuleName() string                    { return "test_minimum_level" }
+func (r minimumLevelRule) DefaultSeverity() ValidationSeverity { return SeverityError }
+
+func (r minimumLevelRule) Validate(content interface{}) Result {
+	char, ok := content.(*game.Character)
+	if !ok {
+		return Result{Passed: false, Message: "content is not a character"}
+	}
+	if char.Level < r.level {
+		return Result{Passed: false, Message: "character level is too low"}
+	}
+	return Result{Passed: true, Message: "character level is high enough"}
+}
+
+func init() {
+	if err := RegisterRulePack(RulePack{
+		Name:  "test_house_rules",
+		Rules: map[ContentType][]ContentRule{ContentTypeCharacters: {minimumLevelRule{level: 3}}},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// findResult returns the result with message, failing the test if absent
+func findResult(t *testing.T, results []Result, message string) Result {
+	t.Helper()
+	for _, result := range results {
+		if result.Message == message {
+			return result
+		}
+	}
+	t.Fatalf("no result with message %q", message)
+	return Result{}
+}
+
+func TestRegisterRulePack(t *testing.T) {
+	assert.Contains(t, RulePacks(), "test_house_rules")
+	assert.Error(t, RegisterRulePack(RulePack{Name: "test_house_rules"}), "duplicate name")
+	assert.Error(t, RegisterRulePack(RulePack{}), "missing name")
+	assert.Error(t, RegisterRulePack(RulePack{
+		Name:  "test_unnamed",
+		Rules: map[ContentType][]ContentRule{ContentTypeCharacters: {ValidationRule{}}},
+	}))
+
+	validator := NewContentValidator(nil)
+	assert.Error(t, validator.UseRulePack("no_such_pack"))
+}
+
+func TestContentValidator_ApplyConfig(t *testing.T) {
+	validator := NewContentValidator(nil)
+	builtin := len(validator.validationRules[ContentTypeCharacters])
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rule_packs: [test_house_rules, test_house_rules]
+rules:
+  test_minimum_level:
+    severity: warning
+  character_name_not_empty:
+    enabled: false
+`), 0o644))
+	config, err := LoadValidatorConfig(path)
+	require.NoError(t, err)
+	require.NoError(t, validator.ApplyConfig(config))
+	assert.Len(t, validator.validationRules[ContentTypeCharacters], builtin+1, "a pack is added once")
+
+	char := &game.Character{ID: "c1", Level: 1, Strength: 12, Dexterity: 12, Constitution: 12, Intelligence: 12, Wisdom: 12, Charisma: 12}
+	results, err := validator.ValidateContent(context.Background(), ContentTypeCharacters, char)
+	require.NoError(t, err)
+	assert.Len(t, results, builtin, "the disabled rule does not run")
+	assert.Equal(t, SeverityWarning, findResult(t, results, "character level is too low").Severity)
+
+	metrics := validator.GetValidationMetrics()
+	ruleMetrics := metrics.GetRuleMetrics()
+	assert.Equal(t, int64(1), ruleMetrics["test_minimum_level"].Executions)
+	assert.Equal(t, int64(1), ruleMetrics["test_minimum_level"].Failures)
+	assert.Zero(t, ruleMetrics["character_attributes_valid"].Failures)
+	assert.NotContains(t, ruleMetrics, "character_name_not_empty")
+
+	// Settings are checked against the validator's rules
+	assert.Error(t, validator.ApplyConfig(&ValidatorConfig{Rules: map[string]RuleConfig{"no_such_rule": {}}}))
+	assert.Error(t, validator.ApplyConfig(&ValidatorConfig{Rules: map[string]RuleConfig{"test_minimum_level": {Severity: "fatal"}}}))
+	assert.Error(t, validator.ApplyConfig(&ValidatorConfig{RulePacks: []string{"no_such_pack"}}))
+}
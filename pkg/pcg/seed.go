@@ -5,14 +5,39 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"goldbox-rpg/pkg/pcg/utils"
 )
 
+// LegacySeedVersion is the seed namespace used before generators were
+// versioned. Seeds in it match DeriveContextSeed, so worlds saved without
+// recorded versions regenerate exactly as they did.
+const LegacySeedVersion = 0
+
+// CurrentSeedVersions is the seed namespace version each content type uses in
+// newly created worlds. Bump a content type's version whenever a generator
+// change would alter what existing seeds produce; saved worlds keep the
+// version they were created with.
+var CurrentSeedVersions = map[ContentType]int{
+	ContentTypeTerrain: 1,
+	ContentTypeItems:   1,
+	ContentTypeLevels:  1,
+	ContentTypeQuests:  1,
+}
+
 // SeedManager provides deterministic seeding for reproducible content generation
 // Follows the established deterministic patterns in the existing dice system
 type SeedManager struct {
 	baseSeed     int64
 	contextSeeds map[string]int64
+	versions     map[ContentType]int
+	algorithm    utils.RNGAlgorithm
+	mu           sync.RWMutex
 }
 
 // NewSeedManager creates a new seed manager with a base seed
@@ -21,14 +46,31 @@ func NewSeedManager(baseSeed int64) *SeedManager {
 		baseSeed = time.Now().UnixNano()
 	}
 
+	versions := make(map[ContentType]int, len(CurrentSeedVersions))
+	for contentType, version := range CurrentSeedVersions {
+		versions[contentType] = version
+	}
+
 	return &SeedManager{
 		baseSeed:     baseSeed,
 		contextSeeds: make(map[string]int64),
+		versions:     versions,
+		algorithm:    GetRNGAlgorithm(),
 	}
 }
 
+// RNGAlgorithm returns the algorithm of the generators this seed manager
+// creates, fixed when it was created or loaded.
+func (sm *SeedManager) RNGAlgorithm() utils.RNGAlgorithm {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.algorithm
+}
+
 // GetBaseSeed returns the base seed used for all generation
 func (sm *SeedManager) GetBaseSeed() int64 {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	return sm.baseSeed
 }
 
@@ -36,7 +78,64 @@ func (sm *SeedManager) GetBaseSeed() int64 {
 // This ensures that the same content type/name combination always produces
 // the same seed, enabling reproducible generation across sessions
 func (sm *SeedManager) DeriveContextSeed(contentType ContentType, name string) int64 {
-	context := fmt.Sprintf("%s:%s", contentType, name)
+	return sm.deriveSeed(fmt.Sprintf("%s:%s", contentType, name))
+}
+
+// GetSeedV derives the seed for a region of contentType at the given
+// coordinates within a generator version's namespace. Different versions
+// produce unrelated seeds for the same region, while LegacySeedVersion
+// matches DeriveContextSeed with the coordinates joined by colons.
+func (sm *SeedManager) GetSeedV(contentType ContentType, version int, coords ...int) int64 {
+	parts := make([]string, len(coords))
+	for i, coord := range coords {
+		parts[i] = strconv.Itoa(coord)
+	}
+	return sm.deriveSeed(versionedContext(contentType, version, strings.Join(parts, ":")))
+}
+
+// RegionSeed derives the seed for a region of contentType using the
+// generator version this world was created with.
+func (sm *SeedManager) RegionSeed(contentType ContentType, coords ...int) int64 {
+	return sm.GetSeedV(contentType, sm.SeedVersion(contentType), coords...)
+}
+
+// VersionedContextSeed is DeriveContextSeed within the generator version this
+// world was created with.
+func (sm *SeedManager) VersionedContextSeed(contentType ContentType, name string) int64 {
+	return sm.deriveSeed(versionedContext(contentType, sm.SeedVersion(contentType), name))
+}
+
+// SeedVersion returns the generator version seeds for contentType are derived
+// with. Content types without a recorded version use LegacySeedVersion.
+func (sm *SeedManager) SeedVersion(contentType ContentType) int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.versions[contentType]
+}
+
+// SetSeedVersion migrates contentType to a different generator version.
+// Content already generated is unaffected, but regenerating it will produce
+// different results.
+func (sm *SeedManager) SetSeedVersion(contentType ContentType, version int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.versions[contentType] = version
+}
+
+// versionedContext builds the seed context for name in a version namespace.
+// The legacy namespace keeps the unversioned format.
+func versionedContext(contentType ContentType, version int, name string) string {
+	if version == LegacySeedVersion {
+		return fmt.Sprintf("%s:%s", contentType, name)
+	}
+	return fmt.Sprintf("%s@v%d:%s", contentType, version, name)
+}
+
+// deriveSeed returns the cached seed for context, hashing it with the base
+// seed on first use.
+func (sm *SeedManager) deriveSeed(context string) int64 {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
 	if seed, exists := sm.contextSeeds[context]; exists {
 		return seed
@@ -65,9 +164,15 @@ func (sm *SeedManager) DeriveParameterSeed(baseSeed int64, params GenerationPara
 		params.Difficulty,
 		params.PlayerLevel)
 
-	// Include any additional constraints that should affect seeding
-	for key, value := range params.Constraints {
-		paramString += fmt.Sprintf(":%s=%v", key, value)
+	// Include any additional constraints that should affect seeding, in key
+	// order so the seed does not depend on map iteration
+	keys := make([]string, 0, len(params.Constraints))
+	for key := range params.Constraints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		paramString += fmt.Sprintf(":%s=%v", key, params.Constraints[key])
 	}
 
 	hasher.Write([]byte(paramString))
@@ -82,7 +187,19 @@ func (sm *SeedManager) CreateRNG(contentType ContentType, name string, params Ge
 	contextSeed := sm.DeriveContextSeed(contentType, name)
 	finalSeed := sm.DeriveParameterSeed(contextSeed, params)
 
-	return rand.New(rand.NewSource(finalSeed))
+	return newStreamRNG(sm.RNGAlgorithm(), finalSeed, fmt.Sprintf("%s:%s", contentType, name))
+}
+
+// CreateChunkRNG creates the generator of one chunk of content generated in
+// parallel, such as a region of a large map. Chunks of the same content
+// draw from non-overlapping parts of one stream with the pcg64 and
+// xoshiro256 algorithms, and from independently seeded generators
+// otherwise.
+func (sm *SeedManager) CreateChunkRNG(contentType ContentType, name string, params GenerationParams, chunk int) *rand.Rand {
+	contextSeed := sm.DeriveContextSeed(contentType, name)
+	finalSeed := sm.DeriveParameterSeed(contextSeed, params)
+
+	return utils.NewChunkRand(sm.RNGAlgorithm(), finalSeed, fmt.Sprintf("%s:%s", contentType, name), chunk)
 }
 
 // CreateSubRNG creates a child RNG for a specific generation phase
@@ -97,31 +214,67 @@ func (sm *SeedManager) CreateSubRNG(parentRNG *rand.Rand, phase string) *rand.Ra
 	hash := hasher.Sum(nil)
 
 	finalSeed := int64(binary.BigEndian.Uint64(hash[:8]))
-	return rand.New(rand.NewSource(finalSeed))
+	return newStreamRNG(sm.RNGAlgorithm(), finalSeed, phase)
 }
 
 // SaveableState represents the state that can be saved/loaded for reproducibility
 type SaveableState struct {
 	BaseSeed     int64            `yaml:"base_seed"`
 	ContextSeeds map[string]int64 `yaml:"context_seeds"`
+	// SeedVersions records the generator version of each content type.
+	// Saves from before versioning have none and load as LegacySeedVersion.
+	SeedVersions map[ContentType]int `yaml:"seed_versions,omitempty"`
+	// RNG is the algorithm of the world's generators. Saves without one
+	// used math/rand.
+	RNG utils.RNGAlgorithm `yaml:"rng,omitempty"`
 }
 
-// GetSaveableState returns the current state for persistence
+// GetSaveableState returns a copy of the current state for persistence
 func (sm *SeedManager) GetSaveableState() SaveableState {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	contextSeeds := make(map[string]int64, len(sm.contextSeeds))
+	for context, seed := range sm.contextSeeds {
+		contextSeeds[context] = seed
+	}
+
+	versions := make(map[ContentType]int, len(sm.versions))
+	for contentType, version := range sm.versions {
+		versions[contentType] = version
+	}
+
 	return SaveableState{
 		BaseSeed:     sm.baseSeed,
-		ContextSeeds: sm.contextSeeds,
+		ContextSeeds: contextSeeds,
+		SeedVersions: versions,
+		RNG:          sm.algorithm,
 	}
 }
 
 // LoadState restores the seed manager from saved state
 func (sm *SeedManager) LoadState(state SaveableState) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
 	sm.baseSeed = state.BaseSeed
 	sm.contextSeeds = make(map[string]int64)
 
 	for context, seed := range state.ContextSeeds {
 		sm.contextSeeds[context] = seed
 	}
+
+	// Only recorded versions carry over; everything else stays on the
+	// generator the save was created with, the legacy one
+	sm.versions = make(map[ContentType]int, len(state.SeedVersions))
+	for contentType, version := range state.SeedVersions {
+		sm.versions[contentType] = version
+	}
+
+	sm.algorithm = state.RNG
+	if sm.algorithm == "" {
+		sm.algorithm = utils.RNGMath
+	}
 }
 
 // GenerationContext provides context and seeded RNG for generators
@@ -602,6 +602,63 @@ func TestGenerationContext_WeightedChoice(t *testing.T) {
 	})
 }
 
+func TestSeedManager_GetSeedV(t *testing.T) {
+	sm := NewSeedManager(12345)
+
+	v1 := sm.GetSeedV(ContentTypeTerrain, 1, 3, 4)
+	if v1 != sm.GetSeedV(ContentTypeTerrain, 1, 3, 4) {
+		t.Error("Same version and coordinates should produce the same seed")
+	}
+	if v1 == sm.GetSeedV(ContentTypeTerrain, 2, 3, 4) {
+		t.Error("Different versions should produce different seeds")
+	}
+	if v1 == sm.GetSeedV(ContentTypeTerrain, 1, 4, 3) {
+		t.Error("Different coordinates should produce different seeds")
+	}
+	if v1 == sm.GetSeedV(ContentTypeItems, 1, 3, 4) {
+		t.Error("Different content types should produce different seeds")
+	}
+
+	legacy := sm.GetSeedV(ContentTypeTerrain, LegacySeedVersion, 3, 4)
+	if legacy != sm.DeriveContextSeed(ContentTypeTerrain, "3:4") {
+		t.Error("Legacy version should match unversioned context seeds")
+	}
+}
+
+func TestSeedManager_SeedVersionMigration(t *testing.T) {
+	sm := NewSeedManager(12345)
+	if sm.SeedVersion(ContentTypeTerrain) != CurrentSeedVersions[ContentTypeTerrain] {
+		t.Errorf("New worlds should use the current version, got %d", sm.SeedVersion(ContentTypeTerrain))
+	}
+	current := sm.RegionSeed(ContentTypeTerrain, 1, 2)
+
+	// A save from before versioning records no versions
+	legacy := NewSeedManager(1)
+	legacy.LoadState(SaveableState{BaseSeed: 12345})
+	if legacy.SeedVersion(ContentTypeTerrain) != LegacySeedVersion {
+		t.Errorf("Unversioned saves should load as legacy, got %d", legacy.SeedVersion(ContentTypeTerrain))
+	}
+	if legacy.VersionedContextSeed(ContentTypeTerrain, "forest") != legacy.DeriveContextSeed(ContentTypeTerrain, "forest") {
+		t.Error("Legacy saves should keep their original seeds")
+	}
+
+	// Versions survive a save and load
+	restored := NewSeedManager(1)
+	restored.LoadState(sm.GetSaveableState())
+	if restored.RegionSeed(ContentTypeTerrain, 1, 2) != current {
+		t.Error("Restored worlds should derive the same region seeds")
+	}
+
+	// Migrating to a new generator changes what the region regenerates to
+	restored.SetSeedVersion(ContentTypeTerrain, 2)
+	if restored.RegionSeed(ContentTypeTerrain, 1, 2) == current {
+		t.Error("Migrated content type should derive new seeds")
+	}
+	if restored.GetSaveableState().SeedVersions[ContentTypeTerrain] != 2 {
+		t.Error("Migration should be saved")
+	}
+}
+
 // Benchmark tests for performance validation
 func BenchmarkSeedManager_DeriveContextSeed(b *testing.B) {
 	sm := NewSeedManager(12345)
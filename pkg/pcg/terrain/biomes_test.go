@@ -1,8 +1,10 @@
 package terrain
 
 import (
+	"context"
 	"testing"
 
+	"goldbox-rpg/pkg/game"
 	"goldbox-rpg/pkg/pcg"
 
 	"github.com/stretchr/testify/assert"
@@ -168,3 +170,28 @@ func TestBiomeDefinitionCompleteness(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyBiomeLight(t *testing.T) {
+	generator := NewCellularAutomataGenerator()
+	for biome, want := range map[pcg.BiomeType]game.LightLevel{
+		pcg.BiomeCave:    game.LightDark,
+		pcg.BiomeDungeon: game.LightDim,
+		pcg.BiomeForest:  game.LightBright,
+	} {
+		params := pcg.TerrainParams{
+			GenerationParams: pcg.GenerationParams{Seed: 7, Difficulty: 3},
+			BiomeType:        biome,
+			Density:          0.45,
+		}
+		gameMap, err := generator.GenerateTerrain(context.Background(), 20, 20, params)
+		require.NoError(t, err, biome)
+		for y := range gameMap.Tiles {
+			for x := range gameMap.Tiles[y] {
+				require.Equal(t, want, gameMap.Tiles[y][x].Light, "%s tile %d,%d", biome, x, y)
+			}
+		}
+
+		level := pcg.LevelFromTerrain(string(biome), gameMap)
+		assert.Equal(t, want, level.Tiles[0][0].Light, "converted levels keep the biome's light")
+	}
+}
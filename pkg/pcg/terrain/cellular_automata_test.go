@@ -1,6 +1,7 @@
 package terrain
 
 import (
+	"context"
 	"testing"
 
 	"goldbox-rpg/pkg/game"
@@ -1281,3 +1282,34 @@ func countTransitions(gameMap *game.GameMap) int {
 	}
 	return count
 }
+
+func TestCellularAutomataGenerator_ReportsProgress(t *testing.T) {
+	var updates []pcg.ProgressUpdate
+	terrainParams := pcg.TerrainParams{
+		GenerationParams: pcg.GenerationParams{Seed: 99, Difficulty: 5},
+		BiomeType:        pcg.BiomeDungeon,
+		Density:          0.45,
+		Connectivity:     pcg.ConnectivityModerate,
+	}
+	params := pcg.GenerationParams{
+		Seed:       99,
+		Difficulty: 5,
+		Progress:   func(update pcg.ProgressUpdate) { updates = append(updates, update) },
+		Constraints: map[string]interface{}{
+			"width":          30,
+			"height":         30,
+			"terrain_params": terrainParams,
+		},
+	}
+
+	_, err := NewCellularAutomataGenerator().Generate(context.Background(), params)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, updates)
+	assert.Equal(t, "initial_layout", updates[0].Stage)
+	assert.Equal(t, "connectivity", updates[len(updates)-1].Stage)
+	for i := 1; i < len(updates); i++ {
+		assert.GreaterOrEqual(t, updates[i].Percent, updates[i-1].Percent)
+		assert.Equal(t, pcg.ContentTypeTerrain, updates[i].ContentType)
+	}
+}
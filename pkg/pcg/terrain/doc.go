@@ -14,6 +14,9 @@
 //   - MazeGenerator: Produces traditional maze structures using recursive backtracking.
 //     Ideal for labyrinths, puzzle dungeons, and structured corridors.
 //
+//   - NoiseTerrainGenerator: Builds outdoor maps from layered Perlin noise. Best
+//     suited for forests, plains, mountains, and coastlines.
+//
 // All generators implement the pcg.TerrainGenerator interface:
 //
 //	type TerrainGenerator interface {
@@ -36,6 +39,8 @@
 //   - Feature placement (decorations, special tiles)
 //   - Connectivity requirements (how connected areas should be)
 //   - Roughness and density parameters
+//   - Light level: caves are dark and dungeons dim, so characters there
+//     need light sources or infravision (see ApplyBiomeLight)
 //
 // # Cellular Automata Generation
 //
@@ -67,6 +72,30 @@
 //
 // Note: Maze dimensions should be odd numbers for proper wall/corridor alignment.
 //
+// # Noise Generation
+//
+// The NoiseTerrainGenerator samples an elevation field and a moisture field
+// from fractal Perlin noise and maps them to tiles through biome-specific
+// elevation bands:
+//
+//	generator := terrain.NewNoiseTerrainGenerator()
+//	params := pcg.TerrainParams{
+//		GenerationParams: pcg.GenerationParams{
+//			Seed:        12345,
+//			Constraints: map[string]interface{}{"octaves": 5, "rivers": 2},
+//		},
+//		BiomeType:    pcg.BiomeCoastal,
+//		Connectivity: pcg.ConnectivityMinimal,
+//	}
+//	gameMap, err := generator.GenerateTerrain(ctx, 80, 60, params)
+//
+// Elevation runs from deep water through shore, grass or forest, hills, and
+// impassable rock to snow. Coastal maps fall away into the sea along one edge,
+// mountain maps are pushed upwards, and rivers are carved downhill from the
+// hills. Disconnected land is then joined using the same connectivity levels as
+// the cellular automata generator. The "octaves", "persistence", "lacunarity",
+// "scale", and "rivers" constraints override DefaultNoiseSettings.
+//
 // # Feature Placement
 //
 // After base terrain generation, biome-specific features are added:
@@ -105,4 +134,14 @@
 //
 //	result, err := manager.GenerateContent(ctx, pcg.ContentTypeTerrain, params)
 //	gameMap := result.(*game.GameMap)
+//
+// # Tiled Export
+//
+// ExportTiled writes a GameMap in the JSON format of the Tiled map editor,
+// placing each tile by its sprite coordinates in an 8x8 "terrain.png"
+// tileset. Door and torch tiles are also listed in the "doors" and "lights"
+// object groups:
+//
+//	tiled, err := terrain.ExportTiled(gameMap, pcg.TiledOptions{TileWidth: 16, TileHeight: 16})
+//	data, err := json.Marshal(tiled)
 package terrain
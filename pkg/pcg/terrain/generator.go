@@ -41,6 +41,11 @@ func (cag *CellularAutomataGenerator) Generate(ctx context.Context, params pcg.G
 		height = 50 // Default height
 	}
 
+	// Progress is a runtime callback, so it is only set on the outer params
+	if terrainParams.Progress == nil {
+		terrainParams.Progress = params.Progress
+	}
+
 	return cag.GenerateTerrain(ctx, width, height, terrainParams)
 }
 
@@ -64,6 +69,7 @@ func (cag *CellularAutomataGenerator) GenerateTerrain(ctx context.Context, width
 
 	// Generate initial random layout
 	cag.generateInitialLayout(gameMap, genCtx, params)
+	params.ReportProgress(pcg.ContentTypeTerrain, "initial_layout", 10)
 
 	// Apply cellular automata iterations
 	iterations := cag.calculateIterations(params.Difficulty)
@@ -76,12 +82,14 @@ func (cag *CellularAutomataGenerator) GenerateTerrain(ctx context.Context, width
 		}
 
 		cag.applyCellularAutomataStep(gameMap, genCtx)
+		params.ReportProgress(pcg.ContentTypeTerrain, "cellular_automata", 10+60*float64(i+1)/float64(iterations))
 	}
 
 	// Post-process the map based on biome and parameters
 	if err := cag.postProcessMap(gameMap, genCtx, params); err != nil {
 		return nil, fmt.Errorf("post-processing failed: %w", err)
 	}
+	params.ReportProgress(pcg.ContentTypeTerrain, "post_processing", 80)
 
 	// Apply connectivity requirements
 	if params.Connectivity != pcg.ConnectivityNone {
@@ -89,7 +97,9 @@ func (cag *CellularAutomataGenerator) GenerateTerrain(ctx context.Context, width
 			return nil, fmt.Errorf("connectivity enforcement failed: %w", err)
 		}
 	}
+	params.ReportProgress(pcg.ContentTypeTerrain, "connectivity", 95)
 
+	ApplyBiomeLight(gameMap, params.BiomeType)
 	return gameMap, nil
 }
 
@@ -503,16 +513,10 @@ func (cag *CellularAutomataGenerator) calculateIterations(difficulty int) int {
 func (cag *CellularAutomataGenerator) adjustParamsForBiome(params pcg.TerrainParams, biome pcg.BiomeType) pcg.TerrainParams {
 	adjusted := params
 
-	switch biome {
-	case pcg.BiomeCave:
-		adjusted.Density = 0.45
-		adjusted.WaterLevel = 0.1
-	case pcg.BiomeDungeon:
-		adjusted.Density = 0.4
-		adjusted.WaterLevel = 0.05
-	case pcg.BiomeSwamp:
-		adjusted.Density = 0.3
-		adjusted.WaterLevel = 0.4
+	// Biomes without a definition keep the caller's parameters
+	if def, exists := pcg.Biomes().Get(biome); exists {
+		adjusted.Density = def.DefaultDensity
+		adjusted.WaterLevel = def.WaterLevelRange[1]
 	}
 
 	return adjusted
@@ -110,6 +110,7 @@ func (mg *MazeGenerator) GenerateTerrain(ctx context.Context, width, height int,
 	if err := mg.applyBiomeSpecificFeatures(gameMap, params, genCtx); err != nil {
 		return nil, fmt.Errorf("failed to apply biome features: %w", err)
 	}
+	ApplyBiomeLight(gameMap, params.BiomeType)
 
 	return gameMap, nil
 }
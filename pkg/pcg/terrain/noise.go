@@ -0,0 +1,527 @@
+package terrain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// NoiseSettings controls the layered noise that shapes outdoor terrain.
+// Settings can be overridden per request through the "octaves",
+// "persistence", "lacunarity", "scale" and "rivers" generation constraints.
+type NoiseSettings struct {
+	Octaves     int     // Number of noise layers summed together
+	Persistence float64 // Amplitude multiplier applied to each successive octave
+	Lacunarity  float64 // Frequency multiplier applied to each successive octave
+	Scale       float64 // Size in tiles of the base octave's features
+	Rivers      int     // Number of rivers to carve; negative uses the biome default
+}
+
+// DefaultNoiseSettings returns the settings used when no overrides are given
+func DefaultNoiseSettings() NoiseSettings {
+	return NoiseSettings{
+		Octaves:     4,
+		Persistence: 0.5,
+		Lacunarity:  2.0,
+		Scale:       24.0,
+		Rivers:      -1,
+	}
+}
+
+// noiseBiomeProfile maps normalized elevation and moisture to tiles for a biome
+type noiseBiomeProfile struct {
+	seaLevel      float64 // Elevation below which tiles are water
+	hillLine      float64 // Elevation above which lowland gives way to hills
+	mountainLine  float64 // Elevation above which tiles are impassable rock
+	snowLine      float64 // Elevation above which rock is capped with snow
+	forestCover   float64 // Share of lowland covered by trees
+	elevationBias float64 // Exponent applied to elevation; below 1 raises terrain
+	coastal       bool    // Whether one map edge falls away into the sea
+	rivers        int     // Default number of rivers
+}
+
+// noiseBiomeProfiles holds the elevation bands for each outdoor biome
+var noiseBiomeProfiles = map[pcg.BiomeType]noiseBiomeProfile{
+	pcg.BiomeForest: {
+		seaLevel: 0.18, hillLine: 0.70, mountainLine: 0.85, snowLine: 0.95,
+		forestCover: 0.65, elevationBias: 1.0, rivers: 2,
+	},
+	pcg.BiomePlains: {
+		seaLevel: 0.15, hillLine: 0.78, mountainLine: 0.90, snowLine: 0.97,
+		forestCover: 0.15, elevationBias: 1.2, rivers: 1,
+	},
+	pcg.BiomeMountain: {
+		seaLevel: 0.08, hillLine: 0.40, mountainLine: 0.62, snowLine: 0.82,
+		forestCover: 0.30, elevationBias: 0.7, rivers: 2,
+	},
+	pcg.BiomeCoastal: {
+		seaLevel: 0.35, hillLine: 0.75, mountainLine: 0.88, snowLine: 0.97,
+		forestCover: 0.25, elevationBias: 1.0, coastal: true, rivers: 1,
+	},
+}
+
+// Sprite coordinates for outdoor tiles. Floor, wall, water, grass and dense
+// vegetation share their coordinates with the cellular automata generator.
+const (
+	spriteGrassX, spriteGrassY     = 6, 0
+	spriteTreesX, spriteTreesY     = 7, 0
+	spriteShallowX, spriteShallowY = 2, 0
+	spriteDeepX, spriteDeepY       = 2, 1
+	spriteSandX, spriteSandY       = 8, 0
+	spriteHillsX, spriteHillsY     = 3, 1
+	spriteRockX, spriteRockY       = 1, 0
+	spriteSnowX, spriteSnowY       = 8, 1
+	shoreWidth                     = 0.04
+)
+
+// NoiseTerrainGenerator implements outdoor terrain generation using layered
+// Perlin noise. An elevation field is mapped to water, shore, lowland, hills
+// and mountains according to the biome, a second moisture field decides where
+// forests grow, and rivers are carved downhill from high ground. Disconnected
+// walkable areas are joined the same way the cellular automata generator
+// joins caves.
+type NoiseTerrainGenerator struct {
+	version   string
+	connector *CellularAutomataGenerator
+}
+
+// NewNoiseTerrainGenerator creates a new noise-based outdoor terrain generator
+func NewNoiseTerrainGenerator() *NoiseTerrainGenerator {
+	return &NoiseTerrainGenerator{
+		version:   "1.0.0",
+		connector: NewCellularAutomataGenerator(),
+	}
+}
+
+// Generate implements the Generator interface
+func (ntg *NoiseTerrainGenerator) Generate(ctx context.Context, params pcg.GenerationParams) (interface{}, error) {
+	terrainParams, ok := params.Constraints["terrain_params"].(pcg.TerrainParams)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid terrain parameters")
+	}
+
+	width, ok := params.Constraints["width"].(int)
+	if !ok {
+		width = 50 // Default width
+	}
+
+	height, ok := params.Constraints["height"].(int)
+	if !ok {
+		height = 50 // Default height
+	}
+
+	// Noise overrides are read from the terrain constraints, so carry the
+	// outer ones across when the terrain params have none of their own
+	if terrainParams.Constraints == nil {
+		terrainParams.Constraints = params.Constraints
+	}
+
+	// Progress is a runtime callback, so it is only set on the outer params
+	if terrainParams.Progress == nil {
+		terrainParams.Progress = params.Progress
+	}
+
+	return ntg.GenerateTerrain(ctx, width, height, terrainParams)
+}
+
+// GenerateTerrain implements the TerrainGenerator interface
+func (ntg *NoiseTerrainGenerator) GenerateTerrain(ctx context.Context, width, height int, params pcg.TerrainParams) (*game.GameMap, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid terrain dimensions: %dx%d", width, height)
+	}
+
+	seedMgr := pcg.NewSeedManager(params.Seed)
+	genCtx := pcg.NewGenerationContext(seedMgr, pcg.ContentTypeTerrain, "noise", params.GenerationParams)
+
+	profile := noiseProfileFor(params.BiomeType)
+	settings := noiseSettingsFromConstraints(params.Constraints, params.Roughness)
+
+	elevation := ntg.elevationField(genCtx, width, height, settings, profile)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	params.ReportProgress(pcg.ContentTypeTerrain, "elevation", 30)
+
+	moisture := ntg.noiseField(genCtx.GetSubRNG("moisture"), width, height, settings)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	params.ReportProgress(pcg.ContentTypeTerrain, "moisture", 50)
+
+	seaLevel := profile.seaLevel
+	if params.WaterLevel > 0 {
+		seaLevel = math.Min(params.WaterLevel, profile.hillLine-shoreWidth)
+	}
+
+	gameMap := &game.GameMap{
+		Width:  width,
+		Height: height,
+		Tiles:  make([][]game.MapTile, height),
+	}
+	for y := 0; y < height; y++ {
+		gameMap.Tiles[y] = make([]game.MapTile, width)
+		for x := 0; x < width; x++ {
+			gameMap.Tiles[y][x] = ntg.tileForElevation(elevation[y][x], moisture[y][x], seaLevel, profile)
+		}
+	}
+	params.ReportProgress(pcg.ContentTypeTerrain, "tile_mapping", 65)
+
+	rivers := settings.Rivers
+	if rivers < 0 {
+		rivers = profile.rivers
+	}
+	ntg.carveRivers(gameMap, elevation, genCtx, rivers, profile)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	params.ReportProgress(pcg.ContentTypeTerrain, "rivers", 80)
+
+	if params.Connectivity != pcg.ConnectivityNone {
+		if err := ntg.connector.ensureConnectivity(gameMap, genCtx, params.Connectivity); err != nil {
+			return nil, fmt.Errorf("connectivity enforcement failed: %w", err)
+		}
+	}
+	params.ReportProgress(pcg.ContentTypeTerrain, "connectivity", 95)
+
+	return gameMap, nil
+}
+
+// GenerateBiome implements the TerrainGenerator interface
+func (ntg *NoiseTerrainGenerator) GenerateBiome(ctx context.Context, biome pcg.BiomeType, bounds pcg.Rectangle, params pcg.TerrainParams) (*game.GameMap, error) {
+	params.BiomeType = biome
+	return ntg.GenerateTerrain(ctx, bounds.Width, bounds.Height, params)
+}
+
+// ValidateConnectivity implements the TerrainGenerator interface
+func (ntg *NoiseTerrainGenerator) ValidateConnectivity(terrain *game.GameMap) bool {
+	validator := pcg.NewValidator(true)
+	result := validator.ValidateGameMap(terrain)
+	return result.IsValid()
+}
+
+// GetType implements the Generator interface
+func (ntg *NoiseTerrainGenerator) GetType() pcg.ContentType {
+	return pcg.ContentTypeTerrain
+}
+
+// GetVersion implements the Generator interface
+func (ntg *NoiseTerrainGenerator) GetVersion() string {
+	return ntg.version
+}
+
+// Validate implements the Generator interface
+func (ntg *NoiseTerrainGenerator) Validate(params pcg.GenerationParams) error {
+	terrainParams, ok := params.Constraints["terrain_params"].(pcg.TerrainParams)
+	if !ok {
+		return fmt.Errorf("terrain_params must be provided in constraints as TerrainParams")
+	}
+
+	result := pcg.NewValidator(false).ValidateTerrainParams(terrainParams)
+	if !result.IsValid() {
+		return fmt.Errorf("validation failed: %v", result.Errors)
+	}
+
+	settings := noiseSettingsFromConstraints(params.Constraints, terrainParams.Roughness)
+	if settings.Octaves < 1 || settings.Octaves > 8 {
+		return fmt.Errorf("octaves must be between 1 and 8, got %d", settings.Octaves)
+	}
+	if settings.Persistence <= 0 || settings.Persistence > 1 {
+		return fmt.Errorf("persistence must be in (0, 1], got %f", settings.Persistence)
+	}
+	if settings.Lacunarity < 1 {
+		return fmt.Errorf("lacunarity must be at least 1, got %f", settings.Lacunarity)
+	}
+	if settings.Scale <= 0 {
+		return fmt.Errorf("scale must be positive, got %f", settings.Scale)
+	}
+
+	return nil
+}
+
+// noiseProfileFor returns the elevation bands for a biome. Biomes without a
+// noise profile are treated as plains.
+func noiseProfileFor(biome pcg.BiomeType) noiseBiomeProfile {
+	if profile, exists := noiseBiomeProfiles[biome]; exists {
+		return profile
+	}
+	return noiseBiomeProfiles[pcg.BiomePlains]
+}
+
+// noiseSettingsFromConstraints applies roughness and any constraint overrides
+// on top of the default noise settings
+func noiseSettingsFromConstraints(constraints map[string]interface{}, roughness float64) NoiseSettings {
+	settings := DefaultNoiseSettings()
+
+	// Rougher terrain keeps more of its fine detail
+	if roughness > 0 {
+		settings.Persistence = 0.3 + 0.4*math.Min(roughness, 1)
+	}
+
+	if octaves, ok := constraints["octaves"].(int); ok {
+		settings.Octaves = octaves
+	}
+	if persistence, ok := constraints["persistence"].(float64); ok {
+		settings.Persistence = persistence
+	}
+	if lacunarity, ok := constraints["lacunarity"].(float64); ok {
+		settings.Lacunarity = lacunarity
+	}
+	if scale, ok := constraints["scale"].(float64); ok {
+		settings.Scale = scale
+	}
+	if rivers, ok := constraints["rivers"].(int); ok {
+		settings.Rivers = rivers
+	}
+
+	return settings
+}
+
+// elevationField builds the normalized elevation map, shaped by the biome
+func (ntg *NoiseTerrainGenerator) elevationField(genCtx *pcg.GenerationContext, width, height int, settings NoiseSettings, profile noiseBiomeProfile) [][]float64 {
+	field := ntg.noiseField(genCtx.GetSubRNG("elevation"), width, height, settings)
+
+	// Coastal maps slope down towards one randomly chosen edge
+	seaEdge := genCtx.RandomIntRange(0, 3)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			e := field[y][x]
+			if profile.elevationBias > 0 && profile.elevationBias != 1 {
+				e = math.Pow(e, profile.elevationBias)
+			}
+			if profile.coastal {
+				e *= coastalFalloff(x, y, width, height, seaEdge)
+			}
+			field[y][x] = e
+		}
+	}
+
+	return field
+}
+
+// coastalFalloff returns a multiplier that drops to zero at the sea edge
+func coastalFalloff(x, y, width, height, edge int) float64 {
+	var distance float64
+	switch edge {
+	case 0:
+		distance = float64(y) / float64(max(height-1, 1))
+	case 1:
+		distance = float64(width-1-x) / float64(max(width-1, 1))
+	case 2:
+		distance = float64(height-1-y) / float64(max(height-1, 1))
+	default:
+		distance = float64(x) / float64(max(width-1, 1))
+	}
+	return math.Min(1, 0.2+distance*1.6)
+}
+
+// noiseField samples fractal noise over the map and rescales it to [0, 1]
+func (ntg *NoiseTerrainGenerator) noiseField(rng *rand.Rand, width, height int, settings NoiseSettings) [][]float64 {
+	noise := newPerlinNoise(rng)
+
+	field := make([][]float64, height)
+	low, high := math.Inf(1), math.Inf(-1)
+	for y := 0; y < height; y++ {
+		field[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			v := noise.fractal(float64(x)/settings.Scale, float64(y)/settings.Scale, settings)
+			field[y][x] = v
+			low = math.Min(low, v)
+			high = math.Max(high, v)
+		}
+	}
+
+	span := high - low
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if span > 0 {
+				field[y][x] = (field[y][x] - low) / span
+			} else {
+				field[y][x] = 0.5
+			}
+		}
+	}
+
+	return field
+}
+
+// tileForElevation maps an elevation and moisture sample to a map tile
+func (ntg *NoiseTerrainGenerator) tileForElevation(elevation, moisture, seaLevel float64, profile noiseBiomeProfile) game.MapTile {
+	switch {
+	case elevation < seaLevel*0.6:
+		return game.MapTile{SpriteX: spriteDeepX, SpriteY: spriteDeepY, Walkable: false, Transparent: true}
+	case elevation < seaLevel:
+		return game.MapTile{SpriteX: spriteShallowX, SpriteY: spriteShallowY, Walkable: false, Transparent: true}
+	case elevation < seaLevel+shoreWidth:
+		return game.MapTile{SpriteX: spriteSandX, SpriteY: spriteSandY, Walkable: true, Transparent: true}
+	case elevation < profile.hillLine:
+		if moisture > 1-profile.forestCover {
+			return game.MapTile{SpriteX: spriteTreesX, SpriteY: spriteTreesY, Walkable: true, Transparent: false}
+		}
+		return game.MapTile{SpriteX: spriteGrassX, SpriteY: spriteGrassY, Walkable: true, Transparent: true}
+	case elevation < profile.mountainLine:
+		return game.MapTile{SpriteX: spriteHillsX, SpriteY: spriteHillsY, Walkable: true, Transparent: true}
+	case elevation < profile.snowLine:
+		return game.MapTile{SpriteX: spriteRockX, SpriteY: spriteRockY, Walkable: false, Transparent: false}
+	default:
+		return game.MapTile{SpriteX: spriteSnowX, SpriteY: spriteSnowY, Walkable: false, Transparent: false}
+	}
+}
+
+// carveRivers runs rivers downhill from high ground until they reach water
+// or the map edge
+func (ntg *NoiseTerrainGenerator) carveRivers(gameMap *game.GameMap, elevation [][]float64, genCtx *pcg.GenerationContext, count int, profile noiseBiomeProfile) {
+	if count <= 0 || gameMap.Width < 3 || gameMap.Height < 3 {
+		return
+	}
+
+	// Rivers rise in the hills; fall back to the highest tiles on flat maps
+	var sources []game.Position
+	for y := 1; y < gameMap.Height-1; y++ {
+		for x := 1; x < gameMap.Width-1; x++ {
+			if elevation[y][x] >= profile.hillLine {
+				sources = append(sources, game.Position{X: x, Y: y})
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return
+	}
+
+	for i := 0; i < count; i++ {
+		source := sources[genCtx.RandomIntRange(0, len(sources)-1)]
+		ntg.carveRiver(gameMap, elevation, source)
+	}
+}
+
+// carveRiver follows the steepest descent from start. When the river pools in
+// a hollow it keeps flowing through the lowest unvisited neighbor, so every
+// river ends at water or the map edge.
+func (ntg *NoiseTerrainGenerator) carveRiver(gameMap *game.GameMap, elevation [][]float64, start game.Position) {
+	dx := []int{0, 1, 0, -1}
+	dy := []int{-1, 0, 1, 0}
+
+	visited := map[game.Position]bool{start: true}
+	pos := start
+	maxLength := gameMap.Width * gameMap.Height
+
+	for step := 0; step < maxLength; step++ {
+		tile := &gameMap.Tiles[pos.Y][pos.X]
+		if isWaterSprite(*tile) && pos != start {
+			return
+		}
+		*tile = game.MapTile{SpriteX: spriteShallowX, SpriteY: spriteShallowY, Walkable: false, Transparent: true}
+
+		if pos.X == 0 || pos.Y == 0 || pos.X == gameMap.Width-1 || pos.Y == gameMap.Height-1 {
+			return
+		}
+
+		next := pos
+		lowest := math.Inf(1)
+		for i := 0; i < 4; i++ {
+			candidate := game.Position{X: pos.X + dx[i], Y: pos.Y + dy[i]}
+			if visited[candidate] {
+				continue
+			}
+			if e := elevation[candidate.Y][candidate.X]; e < lowest {
+				lowest = e
+				next = candidate
+			}
+		}
+		if next == pos {
+			return // Boxed in by its own course
+		}
+
+		visited[next] = true
+		pos = next
+	}
+}
+
+// isWaterSprite reports whether a tile is shallow or deep water
+func isWaterSprite(tile game.MapTile) bool {
+	return tile.SpriteX == spriteShallowX && (tile.SpriteY == spriteShallowY || tile.SpriteY == spriteDeepY)
+}
+
+// perlinNoise is a seeded 2D gradient noise source
+type perlinNoise struct {
+	perm [512]int
+}
+
+// newPerlinNoise builds a permutation table from the given RNG
+func newPerlinNoise(rng *rand.Rand) *perlinNoise {
+	p := &perlinNoise{}
+	table := rng.Perm(256)
+	for i := 0; i < 512; i++ {
+		p.perm[i] = table[i&255]
+	}
+	return p
+}
+
+// fractal sums octaves of noise and returns a value in roughly [-1, 1]
+func (p *perlinNoise) fractal(x, y float64, settings NoiseSettings) float64 {
+	total, amplitude, frequency, norm := 0.0, 1.0, 1.0, 0.0
+	for octave := 0; octave < settings.Octaves; octave++ {
+		total += p.noise(x*frequency, y*frequency) * amplitude
+		norm += amplitude
+		amplitude *= settings.Persistence
+		frequency *= settings.Lacunarity
+	}
+	if norm == 0 {
+		return 0
+	}
+	return total / norm
+}
+
+// noise samples single-octave Perlin noise at (x, y)
+func (p *perlinNoise) noise(x, y float64) float64 {
+	xf, yf := math.Floor(x), math.Floor(y)
+	xi, yi := int(xf)&255, int(yf)&255
+	x, y = x-xf, y-yf
+
+	u, v := fade(x), fade(y)
+
+	aa := p.perm[p.perm[xi]+yi]
+	ab := p.perm[p.perm[xi]+yi+1]
+	ba := p.perm[p.perm[xi+1]+yi]
+	bb := p.perm[p.perm[xi+1]+yi+1]
+
+	return lerp(v,
+		lerp(u, grad(aa, x, y), grad(ba, x-1, y)),
+		lerp(u, grad(ab, x, y-1), grad(bb, x-1, y-1)),
+	)
+}
+
+// fade is the Perlin smoothstep curve 6t^5 - 15t^4 + 10t^3
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+// grad returns the dot product of a hashed gradient direction with (x, y)
+func grad(hash int, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
@@ -0,0 +1,188 @@
+package terrain
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noiseTestParams(biome pcg.BiomeType, seed int64) pcg.TerrainParams {
+	return pcg.TerrainParams{
+		GenerationParams: pcg.GenerationParams{Seed: seed},
+		BiomeType:        biome,
+		Connectivity:     pcg.ConnectivityMinimal,
+	}
+}
+
+func countTiles(gameMap *game.GameMap, match func(game.MapTile) bool) int {
+	count := 0
+	for _, row := range gameMap.Tiles {
+		for _, tile := range row {
+			if match(tile) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestNewNoiseTerrainGenerator(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+
+	assert.Equal(t, "1.0.0", ntg.GetVersion())
+	assert.Equal(t, pcg.ContentTypeTerrain, ntg.GetType())
+}
+
+func TestNoiseTerrainGenerator_GenerateTerrain(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+
+	for _, biome := range []pcg.BiomeType{pcg.BiomeForest, pcg.BiomePlains, pcg.BiomeMountain, pcg.BiomeCoastal} {
+		t.Run(string(biome), func(t *testing.T) {
+			gameMap, err := ntg.GenerateTerrain(context.Background(), 60, 40, noiseTestParams(biome, 42))
+			require.NoError(t, err)
+
+			assert.Equal(t, 60, gameMap.Width)
+			assert.Equal(t, 40, gameMap.Height)
+			require.Len(t, gameMap.Tiles, 40)
+			assert.Len(t, gameMap.Tiles[0], 60)
+
+			assert.Positive(t, countTiles(gameMap, func(tile game.MapTile) bool { return tile.Walkable }))
+			assert.True(t, ntg.ValidateConnectivity(gameMap), "walkable land should be connected")
+		})
+	}
+}
+
+func TestNoiseTerrainGenerator_Deterministic(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+
+	first, err := ntg.GenerateTerrain(context.Background(), 40, 40, noiseTestParams(pcg.BiomeForest, 7))
+	require.NoError(t, err)
+	second, err := ntg.GenerateTerrain(context.Background(), 40, 40, noiseTestParams(pcg.BiomeForest, 7))
+	require.NoError(t, err)
+	other, err := ntg.GenerateTerrain(context.Background(), 40, 40, noiseTestParams(pcg.BiomeForest, 8))
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+}
+
+func TestNoiseTerrainGenerator_BiomeBands(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+	isTrees := func(tile game.MapTile) bool { return tile.SpriteX == spriteTreesX && tile.SpriteY == spriteTreesY }
+	isHighGround := func(tile game.MapTile) bool {
+		return (tile.SpriteX == spriteRockX && tile.SpriteY == spriteRockY) ||
+			(tile.SpriteX == spriteSnowX && tile.SpriteY == spriteSnowY) ||
+			(tile.SpriteX == spriteHillsX && tile.SpriteY == spriteHillsY)
+	}
+
+	params := noiseTestParams(pcg.BiomeForest, 99)
+	params.Connectivity = pcg.ConnectivityNone
+	forest, err := ntg.GenerateTerrain(context.Background(), 50, 50, params)
+	require.NoError(t, err)
+	params.BiomeType = pcg.BiomePlains
+	plains, err := ntg.GenerateTerrain(context.Background(), 50, 50, params)
+	require.NoError(t, err)
+	params.BiomeType = pcg.BiomeMountain
+	mountain, err := ntg.GenerateTerrain(context.Background(), 50, 50, params)
+	require.NoError(t, err)
+
+	assert.Greater(t, countTiles(forest, isTrees), countTiles(plains, isTrees))
+	assert.Greater(t, countTiles(mountain, isHighGround), countTiles(plains, isHighGround))
+}
+
+func TestNoiseTerrainGenerator_CoastalHasSea(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+
+	gameMap, err := ntg.GenerateTerrain(context.Background(), 50, 50, noiseTestParams(pcg.BiomeCoastal, 3))
+	require.NoError(t, err)
+
+	water := countTiles(gameMap, isWaterSprite)
+	assert.Greater(t, water, 50*50/10, "coastal maps should have a sizeable sea")
+}
+
+func TestNoiseTerrainGenerator_Rivers(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+
+	params := noiseTestParams(pcg.BiomeMountain, 11)
+	params.Connectivity = pcg.ConnectivityNone
+	params.Constraints = map[string]interface{}{"rivers": 0}
+	dry, err := ntg.GenerateTerrain(context.Background(), 50, 50, params)
+	require.NoError(t, err)
+
+	params.Constraints = map[string]interface{}{"rivers": 4}
+	wet, err := ntg.GenerateTerrain(context.Background(), 50, 50, params)
+	require.NoError(t, err)
+
+	assert.Greater(t, countTiles(wet, isWaterSprite), countTiles(dry, isWaterSprite))
+}
+
+func TestNoiseTerrainGenerator_Generate(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+
+	_, err := ntg.Generate(context.Background(), pcg.GenerationParams{Constraints: map[string]interface{}{}})
+	assert.EqualError(t, err, "missing or invalid terrain parameters")
+
+	result, err := ntg.Generate(context.Background(), pcg.GenerationParams{
+		Seed: 5,
+		Constraints: map[string]interface{}{
+			"width":          30,
+			"height":         20,
+			"octaves":        2,
+			"terrain_params": noiseTestParams(pcg.BiomePlains, 5),
+		},
+	})
+	require.NoError(t, err)
+	gameMap, ok := result.(*game.GameMap)
+	require.True(t, ok)
+	assert.Equal(t, 30, gameMap.Width)
+	assert.Equal(t, 20, gameMap.Height)
+}
+
+func TestNoiseTerrainGenerator_Validate(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+	valid := noiseTestParams(pcg.BiomeForest, 1)
+	valid.Difficulty = 5
+	valid.PlayerLevel = 3
+
+	assert.NoError(t, ntg.Validate(pcg.GenerationParams{
+		Constraints: map[string]interface{}{"terrain_params": valid},
+	}))
+	assert.Error(t, ntg.Validate(pcg.GenerationParams{Constraints: map[string]interface{}{}}))
+	assert.Error(t, ntg.Validate(pcg.GenerationParams{
+		Constraints: map[string]interface{}{"terrain_params": valid, "octaves": 0},
+	}))
+	assert.Error(t, ntg.Validate(pcg.GenerationParams{
+		Constraints: map[string]interface{}{"terrain_params": valid, "scale": -1.0},
+	}))
+}
+
+func TestNoiseSettingsFromConstraints(t *testing.T) {
+	settings := noiseSettingsFromConstraints(nil, 0)
+	assert.Equal(t, DefaultNoiseSettings(), settings)
+
+	settings = noiseSettingsFromConstraints(map[string]interface{}{
+		"octaves":     6,
+		"persistence": 0.4,
+		"lacunarity":  2.5,
+		"scale":       10.0,
+		"rivers":      3,
+	}, 0.9)
+	assert.Equal(t, NoiseSettings{Octaves: 6, Persistence: 0.4, Lacunarity: 2.5, Scale: 10.0, Rivers: 3}, settings)
+
+	settings = noiseSettingsFromConstraints(nil, 1.0)
+	assert.InDelta(t, 0.7, settings.Persistence, 1e-9)
+}
+
+func TestNoiseTerrainGenerator_ContextCancelled(t *testing.T) {
+	ntg := NewNoiseTerrainGenerator()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ntg.GenerateTerrain(ctx, 20, 20, noiseTestParams(pcg.BiomeForest, 1))
+	assert.ErrorIs(t, err, context.Canceled)
+}
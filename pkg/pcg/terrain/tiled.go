@@ -0,0 +1,68 @@
+package terrain
+
+import (
+	"fmt"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+)
+
+// Sprite sheet layout of the terrain generators' tiles
+const (
+	tiledTerrainImage = "terrain.png"
+	tiledTerrainRows  = 8
+)
+
+// terrainMarkers are the sprites exported as objects as well as tiles, by
+// sprite position
+var terrainMarkers = map[[2]int]string{
+	{4, 0}: "door",
+	{5, 0}: "torch",
+}
+
+// ExportTiled converts a generated terrain map to the JSON map format of
+// the Tiled map editor. Tiles keep their sprite sheet position; doors and
+// torches are also listed in the "doors" and "lights" object groups.
+//
+// Parameters:
+//   - gameMap: Terrain map to export
+//   - opts: Tile size and tileset image; zero values take defaults
+//
+// Returns:
+//   - *pcg.TiledMap: Map ready to be written as JSON
+//   - error: The map is empty or its rows do not match its size
+func ExportTiled(gameMap *game.GameMap, opts pcg.TiledOptions) (*pcg.TiledMap, error) {
+	if gameMap == nil {
+		return nil, fmt.Errorf("no terrain map to export")
+	}
+	if len(gameMap.Tiles) != gameMap.Height {
+		return nil, fmt.Errorf("terrain map has %d rows, expected %d", len(gameMap.Tiles), gameMap.Height)
+	}
+	for y, row := range gameMap.Tiles {
+		if len(row) != gameMap.Width {
+			return nil, fmt.Errorf("terrain map row %d has %d tiles, expected %d", y, len(row), gameMap.Width)
+		}
+	}
+
+	export, err := pcg.NewTiledExport(gameMap.Width, gameMap.Height, opts, tiledTerrainImage, pcg.DefaultTiledTilesetColumns)
+	if err != nil {
+		return nil, err
+	}
+	columns := export.Options.TilesetColumns
+	firstGID := export.AddTileset("terrain", columns*tiledTerrainRows, nil)
+
+	objects := map[string][]pcg.TiledObject{}
+	export.AddTileLayer("terrain", func(x, y int) int {
+		tile := gameMap.Tiles[y][x]
+		if kind, marked := terrainMarkers[[2]int{tile.SpriteX, tile.SpriteY}]; marked {
+			objects[kind] = append(objects[kind], export.TileObject(
+				fmt.Sprintf("%s_%d_%d", kind, x, y), kind, x, y,
+				map[string]interface{}{"walkable": tile.Walkable, "transparent": tile.Transparent}))
+		}
+		return firstGID + tile.SpriteY*columns + tile.SpriteX
+	})
+	export.AddObjectGroup("doors", objects["door"])
+	export.AddObjectGroup("lights", objects["torch"])
+
+	return export.Map, nil
+}
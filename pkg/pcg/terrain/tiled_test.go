@@ -0,0 +1,61 @@
+package terrain
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTiled(t *testing.T) {
+	gen := NewCellularAutomataGenerator()
+	floor, wall := gen.createFloorTile(), gen.createWallTile()
+	door := floor
+	door.SpriteX = 4
+	gameMap := &game.GameMap{Width: 3, Height: 2, Tiles: [][]game.MapTile{
+		{wall, door, wall},
+		{floor, floor, gen.createWaterTile()},
+	}}
+
+	tiled, err := ExportTiled(gameMap, pcg.TiledOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "map", tiled.Type)
+	require.Len(t, tiled.Tilesets, 1)
+	assert.Equal(t, "terrain.png", tiled.Tilesets[0].Image)
+
+	require.Len(t, tiled.Layers, 2)
+	assert.Equal(t, []int{2, 5, 2, 1, 1, 3}, tiled.Layers[0].Data, "GIDs follow sprite sheet positions")
+	doors := tiled.Layers[1]
+	assert.Equal(t, "doors", doors.Name)
+	require.Len(t, doors.Objects, 1)
+	assert.Equal(t, float64(32), doors.Objects[0].X)
+
+	data, err := json.Marshal(tiled)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"orientation":"orthogonal"`)
+
+	gameMap.Tiles = gameMap.Tiles[:1]
+	_, err = ExportTiled(gameMap, pcg.TiledOptions{})
+	assert.ErrorContains(t, err, "has 1 rows")
+}
+
+func TestExportTiled_GeneratedTerrain(t *testing.T) {
+	params := pcg.TerrainParams{
+		GenerationParams: pcg.GenerationParams{Seed: 99, Difficulty: 3, Constraints: map[string]interface{}{}},
+		BiomeType:        pcg.BiomeDungeon,
+		Density:          0.45,
+		Connectivity:     pcg.ConnectivityModerate,
+	}
+	gameMap, err := NewCellularAutomataGenerator().GenerateTerrain(context.Background(), 30, 20, params)
+	require.NoError(t, err)
+
+	tiled, err := ExportTiled(gameMap, pcg.TiledOptions{TileWidth: 16, TileHeight: 16})
+	require.NoError(t, err)
+	assert.Len(t, tiled.Layers[0].Data, 30*20)
+	assert.Equal(t, 16, tiled.TileWidth)
+}
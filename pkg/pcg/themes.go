@@ -0,0 +1,373 @@
+package pcg
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"goldbox-rpg/pkg/game"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ThemeDefinition defines how a level theme shapes dungeon and level
+// generation. Room and connection weights override the default weights of
+// the listed types only; optional fields left empty fall back to the
+// generators' generic choices.
+type ThemeDefinition struct {
+	Type              LevelTheme             `yaml:"type,omitempty" json:"type"`
+	NamePrefixes      []string               `yaml:"name_prefixes" json:"name_prefixes"`
+	EnemyTypes        []string               `yaml:"enemy_types" json:"enemy_types"`
+	PuzzleTypes       []string               `yaml:"puzzle_types,omitempty" json:"puzzle_types,omitempty"`
+	BossType          string                 `yaml:"boss_type,omitempty" json:"boss_type,omitempty"`
+	HazardType        string                 `yaml:"hazard_type,omitempty" json:"hazard_type,omitempty"`
+	RoomWeights       map[RoomType]int       `yaml:"room_weights,omitempty" json:"room_weights,omitempty"`
+	ConnectionWeights map[ConnectionType]int `yaml:"connection_weights,omitempty" json:"connection_weights,omitempty"`
+	WidthOffset       int                    `yaml:"width_offset,omitempty" json:"width_offset,omitempty"`
+	HeightOffset      int                    `yaml:"height_offset,omitempty" json:"height_offset,omitempty"`
+	SizeJitter        int                    `yaml:"size_jitter,omitempty" json:"size_jitter,omitempty"`
+	Light             game.LightLevel        `yaml:"light,omitempty" json:"light,omitempty"` // How brightly generated tiles are lit
+}
+
+// clone returns a deep copy of the definition
+func (td *ThemeDefinition) clone() *ThemeDefinition {
+	result := *td
+	result.NamePrefixes = append([]string(nil), td.NamePrefixes...)
+	result.EnemyTypes = append([]string(nil), td.EnemyTypes...)
+	result.PuzzleTypes = append([]string(nil), td.PuzzleTypes...)
+	if td.RoomWeights != nil {
+		result.RoomWeights = make(map[RoomType]int, len(td.RoomWeights))
+		for roomType, weight := range td.RoomWeights {
+			result.RoomWeights[roomType] = weight
+		}
+	}
+	if td.ConnectionWeights != nil {
+		result.ConnectionWeights = make(map[ConnectionType]int, len(td.ConnectionWeights))
+		for connType, weight := range td.ConnectionWeights {
+			result.ConnectionWeights[connType] = weight
+		}
+	}
+	return &result
+}
+
+// ThemeCollection represents the root structure of a theme YAML file
+type ThemeCollection struct {
+	Themes map[LevelTheme]*ThemeDefinition `yaml:"themes"`
+}
+
+// builtinThemes holds the theme definitions used when no theme file
+// overrides them
+var builtinThemes = map[LevelTheme]*ThemeDefinition{
+	ThemeClassic: {
+		Type:         ThemeClassic,
+		NamePrefixes: []string{"Ancient", "Forgotten", "Lost", "Hidden"},
+		EnemyTypes:   []string{"goblin", "orc", "skeleton"},
+		PuzzleTypes:  []string{"lever_sequence", "pressure_plates", "riddle"},
+		BossType:     "dragon",
+		HazardType:   "falling_rocks",
+	},
+	ThemeHorror: {
+		Type:         ThemeHorror,
+		NamePrefixes: []string{"Cursed", "Haunted", "Nightmare", "Shadow"},
+		EnemyTypes:   []string{"zombie", "wraith", "shadow"},
+		BossType:     "abomination",
+		HazardType:   "blood_pools",
+		RoomWeights:  map[RoomType]int{RoomTypeTrap: 20, RoomTypeCombat: 50},
+		Light:        game.LightDim,
+		// Longer, narrower levels for tension
+		WidthOffset:  20,
+		HeightOffset: -10,
+	},
+	ThemeNatural: {
+		Type:              ThemeNatural,
+		NamePrefixes:      []string{"Living", "Verdant", "Root", "Grove"},
+		EnemyTypes:        []string{"wolf", "bear", "spider"},
+		ConnectionWeights: map[ConnectionType]int{ConnectionLadder: 40, ConnectionTunnel: 20},
+		// More organic, irregular dimensions
+		SizeJitter: 10,
+	},
+	ThemeMechanical: {
+		Type:              ThemeMechanical,
+		NamePrefixes:      []string{"Clockwork", "Steam", "Gear", "Iron"},
+		EnemyTypes:        []string{"construct", "golem", "automaton"},
+		PuzzleTypes:       []string{"gear_puzzle", "circuit_puzzle", "weight_balance"},
+		BossType:          "war_machine",
+		ConnectionWeights: map[ConnectionType]int{ConnectionElevator: 30, ConnectionStairs: 40},
+	},
+	ThemeMagical: {
+		Type:              ThemeMagical,
+		NamePrefixes:      []string{"Arcane", "Crystal", "Ethereal", "Mystic"},
+		EnemyTypes:        []string{"elemental", "sprite", "wisp"},
+		PuzzleTypes:       []string{"rune_sequence", "elemental_matching", "spell_focus"},
+		BossType:          "archmage",
+		HazardType:        "magic_storm",
+		RoomWeights:       map[RoomType]int{RoomTypePuzzle: 20, RoomTypeShop: 10},
+		ConnectionWeights: map[ConnectionType]int{ConnectionPortal: 25, ConnectionStairs: 45},
+	},
+	ThemeUndead: {
+		Type:         ThemeUndead,
+		NamePrefixes: []string{"Bone", "Death", "Tomb", "Crypt"},
+		EnemyTypes:   []string{"skeleton", "zombie", "lich"},
+		BossType:     "lich",
+		Light:        game.LightDark,
+	},
+	ThemeElemental: {
+		Type:         ThemeElemental,
+		NamePrefixes: []string{"Elemental", "Primal", "Storm", "Flame"},
+		EnemyTypes:   []string{"fire_elemental", "water_elemental", "earth_elemental"},
+		BossType:     "elemental_lord",
+		HazardType:   "elemental_eruption",
+	},
+}
+
+// ThemeRegistry holds the level theme definitions used by dungeon and
+// level generation. Like BiomeRegistry it starts with the built-in themes,
+// accepts custom themes from YAML files and reloads them on request.
+//
+// ThemeRegistry is safe for concurrent use.
+type ThemeRegistry struct {
+	mu     sync.RWMutex
+	themes map[LevelTheme]*ThemeDefinition
+	paths  []string
+}
+
+// defaultThemes is the registry level generators read from
+var defaultThemes = NewThemeRegistry()
+
+// Themes returns the process-wide theme registry used by level generation.
+func Themes() *ThemeRegistry {
+	return defaultThemes
+}
+
+// NewThemeRegistry creates a registry holding the built-in themes.
+func NewThemeRegistry() *ThemeRegistry {
+	return &ThemeRegistry{themes: builtinThemeCopies()}
+}
+
+// builtinThemeCopies returns copies of the built-in themes that can be
+// stored in a registry
+func builtinThemeCopies() map[LevelTheme]*ThemeDefinition {
+	themes := make(map[LevelTheme]*ThemeDefinition, len(builtinThemes))
+	for theme, def := range builtinThemes {
+		themes[theme] = def.clone()
+	}
+	return themes
+}
+
+// LoadFromFile loads themes from a YAML file and merges them with the
+// themes already registered. The file is remembered so Reload can re-read it;
+// loading the same file again does not add it twice.
+func (tr *ThemeRegistry) LoadFromFile(path string) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	themes := make(map[LevelTheme]*ThemeDefinition, len(tr.themes))
+	for theme, def := range tr.themes {
+		themes[theme] = def
+	}
+
+	if err := readThemes(path, themes); err != nil {
+		return err
+	}
+	if err := validateThemes(themes); err != nil {
+		return fmt.Errorf("invalid themes in %s: %w", path, err)
+	}
+
+	tr.themes = themes
+	if !slices.Contains(tr.paths, path) {
+		tr.paths = append(tr.paths, path)
+	}
+	return nil
+}
+
+// Reload rebuilds the registry from the built-in themes and every file
+// previously passed to LoadFromFile. The new definitions replace the old
+// ones only if all files parse and validate.
+func (tr *ThemeRegistry) Reload() error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	themes, err := tr.rebuild()
+	if err != nil {
+		return err
+	}
+
+	tr.themes = themes
+	return nil
+}
+
+// rebuild reads the built-in themes and every loaded file into a new set of
+// definitions without installing it. The caller must hold tr.mu.
+func (tr *ThemeRegistry) rebuild() (map[LevelTheme]*ThemeDefinition, error) {
+	themes := builtinThemeCopies()
+	for _, path := range tr.paths {
+		if err := readThemes(path, themes); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateThemes(themes); err != nil {
+		return nil, fmt.Errorf("invalid themes: %w", err)
+	}
+	return themes, nil
+}
+
+// ReloadDefinitions reloads a biome and a theme registry together. The new
+// definitions replace the old ones only if the files of both registries
+// parse and validate, so a rejected theme file also keeps the previous
+// biomes active.
+func ReloadDefinitions(br *BiomeRegistry, tr *ThemeRegistry) error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	biomes, err := br.rebuild()
+	if err != nil {
+		return err
+	}
+	themes, err := tr.rebuild()
+	if err != nil {
+		return err
+	}
+
+	br.biomes = biomes
+	tr.themes = themes
+	return nil
+}
+
+// Get returns a copy of the definition of a theme
+func (tr *ThemeRegistry) Get(theme LevelTheme) (*ThemeDefinition, bool) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	def, exists := tr.themes[theme]
+	if !exists {
+		return nil, false
+	}
+	return def.clone(), true
+}
+
+// Types returns the types of all registered themes in sorted order
+func (tr *ThemeRegistry) Types() []LevelTheme {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	types := make([]LevelTheme, 0, len(tr.themes))
+	for theme := range tr.themes {
+		types = append(types, theme)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// readThemes parses a theme file into themes, overwriting any themes of the
+// same type
+func readThemes(path string, themes map[LevelTheme]*ThemeDefinition) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read theme file %s: %w", path, err)
+	}
+
+	var collection ThemeCollection
+	if err := yaml.Unmarshal(data, &collection); err != nil {
+		return fmt.Errorf("failed to parse YAML from %s: %w", path, err)
+	}
+
+	for theme, def := range collection.Themes {
+		if def != nil {
+			def.Type = theme
+			themes[theme] = def
+		}
+	}
+	return nil
+}
+
+// validateThemes checks that every theme names its dungeons and enemies and
+// that its weight overrides leave something to choose from
+func validateThemes(themes map[LevelTheme]*ThemeDefinition) error {
+	for theme, def := range themes {
+		if theme == "" {
+			return fmt.Errorf("theme with empty type")
+		}
+		if len(def.NamePrefixes) == 0 {
+			return fmt.Errorf("theme %s has no name_prefixes", theme)
+		}
+		if len(def.EnemyTypes) == 0 {
+			return fmt.Errorf("theme %s has no enemy_types", theme)
+		}
+		if def.SizeJitter < 0 {
+			return fmt.Errorf("theme %s size_jitter must not be negative", theme)
+		}
+
+		roomTotal := 0
+		for roomType, weight := range def.RoomTypeWeights() {
+			if weight < 0 {
+				return fmt.Errorf("theme %s has negative weight for room type %s", theme, roomType)
+			}
+			if _, known := defaultRoomTypeWeights[roomType]; !known {
+				return fmt.Errorf("theme %s cannot weight room type %q", theme, roomType)
+			}
+			roomTotal += weight
+		}
+		if roomTotal == 0 {
+			return fmt.Errorf("theme %s room_weights leave no room type", theme)
+		}
+
+		connectionTotal := 0
+		for connType, weight := range def.ConnectionTypeWeights() {
+			if weight < 0 {
+				return fmt.Errorf("theme %s has negative weight for connection type %s", theme, connType)
+			}
+			if !validConnectionTypes[connType] {
+				return fmt.Errorf("theme %s has weight for unknown connection type %q", theme, connType)
+			}
+			connectionTotal += weight
+		}
+		if connectionTotal == 0 {
+			return fmt.Errorf("theme %s connection_weights leave no connection type", theme)
+		}
+	}
+	return nil
+}
+
+// RoomTypeWeights returns the default room type weights with the theme's
+// overrides applied. The entrance room is placed separately.
+func (td *ThemeDefinition) RoomTypeWeights() map[RoomType]int {
+	weights := make(map[RoomType]int, len(defaultRoomTypeWeights))
+	for roomType, weight := range defaultRoomTypeWeights {
+		weights[roomType] = weight
+	}
+	for roomType, weight := range td.RoomWeights {
+		weights[roomType] = weight
+	}
+	return weights
+}
+
+// ConnectionTypeWeights returns the default connection type weights with
+// the theme's overrides applied
+func (td *ThemeDefinition) ConnectionTypeWeights() map[ConnectionType]int {
+	weights := make(map[ConnectionType]int, len(defaultConnectionWeights))
+	for connType, weight := range defaultConnectionWeights {
+		weights[connType] = weight
+	}
+	for connType, weight := range td.ConnectionWeights {
+		weights[connType] = weight
+	}
+	return weights
+}
+
+// ApplyThemeLight darkens every tile of level to the light level of theme.
+// Levels of unregistered or brightly lit themes are left as they are.
+func ApplyThemeLight(level *game.Level, theme LevelTheme) {
+	def, exists := Themes().Get(theme)
+	if !exists || def.Light == game.LightBright {
+		return
+	}
+	for y := range level.Tiles {
+		for x := range level.Tiles[y] {
+			level.Tiles[y][x].Light = def.Light
+		}
+	}
+}
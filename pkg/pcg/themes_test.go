@@ -0,0 +1,141 @@
+package pcg
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThemeRegistry_LoadCustomTheme(t *testing.T) {
+	registry := NewThemeRegistry()
+	path := writeDefinitionFile(t, "themes.yaml", `
+themes:
+  sunken:
+    name_prefixes: [Drowned, Sunken]
+    enemy_types: [merfolk, crab]
+    boss_type: kraken
+    room_weights:
+      combat: 10
+      treasure: 30
+    connection_weights:
+      tunnel: 40
+`)
+	require.NoError(t, registry.LoadFromFile(path))
+
+	sunken, exists := registry.Get("sunken")
+	require.True(t, exists)
+	assert.Equal(t, LevelTheme("sunken"), sunken.Type)
+	assert.Equal(t, "kraken", sunken.BossType)
+
+	rooms := sunken.RoomTypeWeights()
+	assert.Equal(t, 10, rooms[RoomTypeCombat])
+	assert.Equal(t, 30, rooms[RoomTypeTreasure])
+	assert.Equal(t, defaultRoomTypeWeights[RoomTypePuzzle], rooms[RoomTypePuzzle], "unlisted room types keep their default weight")
+
+	connections := sunken.ConnectionTypeWeights()
+	assert.Equal(t, 40, connections[ConnectionTunnel])
+	assert.Equal(t, defaultConnectionWeights[ConnectionStairs], connections[ConnectionStairs])
+
+	_, exists = registry.Get(ThemeClassic)
+	assert.True(t, exists)
+}
+
+func TestThemeRegistry_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "missing enemies",
+			content: "themes:\n  bad:\n    name_prefixes: [Bad]\n",
+		},
+		{
+			name:    "missing name prefixes",
+			content: "themes:\n  bad:\n    enemy_types: [rat]\n",
+		},
+		{
+			name:    "unknown room type",
+			content: "themes:\n  bad:\n    name_prefixes: [Bad]\n    enemy_types: [rat]\n    room_weights: {ballroom: 5}\n",
+		},
+		{
+			name:    "negative connection weight",
+			content: "themes:\n  bad:\n    name_prefixes: [Bad]\n    enemy_types: [rat]\n    connection_weights: {pit: -1}\n",
+		},
+		{
+			name:    "no connection left",
+			content: "themes:\n  bad:\n    name_prefixes: [Bad]\n    enemy_types: [rat]\n    connection_weights: {stairs: 0, ladder: 0, pit: 0, tunnel: 0}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewThemeRegistry()
+			assert.Error(t, registry.LoadFromFile(writeDefinitionFile(t, "themes.yaml", tt.content)))
+
+			_, exists := registry.Get("bad")
+			assert.False(t, exists)
+		})
+	}
+}
+
+func TestThemeRegistry_Reload(t *testing.T) {
+	registry := NewThemeRegistry()
+	path := writeDefinitionFile(t, "themes.yaml", "themes:\n  horror:\n    name_prefixes: [Grim]\n    enemy_types: [ghoul]\n")
+	require.NoError(t, registry.LoadFromFile(path))
+
+	horror, _ := registry.Get(ThemeHorror)
+	assert.Equal(t, []string{"Grim"}, horror.NamePrefixes)
+
+	require.NoError(t, os.WriteFile(path, []byte("themes:\n  horror:\n    name_prefixes: [Dread]\n    enemy_types: [ghoul]\n"), 0o644))
+	require.NoError(t, registry.Reload())
+
+	horror, _ = registry.Get(ThemeHorror)
+	assert.Equal(t, []string{"Dread"}, horror.NamePrefixes)
+}
+
+func TestReloadDefinitions_AllOrNothing(t *testing.T) {
+	biomes := NewBiomeRegistry()
+	biomePath := writeDefinitionFile(t, "biomes.yaml", customBiomeYAML)
+	require.NoError(t, biomes.LoadFromFile(biomePath))
+	themes := NewThemeRegistry()
+	themePath := writeDefinitionFile(t, "themes.yaml", "themes:\n  horror:\n    name_prefixes: [Grim]\n    enemy_types: [ghoul]\n")
+	require.NoError(t, themes.LoadFromFile(themePath))
+
+	// A rejected theme file keeps the edited biomes from taking effect
+	require.NoError(t, os.WriteFile(biomePath, []byte("biomes:\n  glacier:\n    default_density: 0.6\n    connectivity_level: low\n    tile_distribution: {ice: 1.0}\n"), 0o644))
+	require.NoError(t, os.WriteFile(themePath, []byte("themes:\n  horror:\n    name_prefixes: []\n"), 0o644))
+	assert.Error(t, ReloadDefinitions(biomes, themes))
+
+	glacier, _ := biomes.Get("glacier")
+	assert.Equal(t, 0.5, glacier.DefaultDensity)
+	horror, _ := themes.Get(ThemeHorror)
+	assert.Equal(t, []string{"Grim"}, horror.NamePrefixes)
+
+	require.NoError(t, os.WriteFile(themePath, []byte("themes:\n  horror:\n    name_prefixes: [Dread]\n    enemy_types: [ghoul]\n"), 0o644))
+	require.NoError(t, ReloadDefinitions(biomes, themes))
+
+	glacier, _ = biomes.Get("glacier")
+	assert.Equal(t, 0.6, glacier.DefaultDensity)
+	horror, _ = themes.Get(ThemeHorror)
+	assert.Equal(t, []string{"Dread"}, horror.NamePrefixes)
+}
+
+func TestDungeonGenerator_UsesRegisteredThemes(t *testing.T) {
+	path := writeDefinitionFile(t, "themes.yaml", "themes:\n  sunken:\n    name_prefixes: [Drowned]\n    enemy_types: [merfolk]\n    room_weights: {combat: 0, treasure: 0, puzzle: 0, shop: 0, rest: 0, trap: 0, story: 0}\n")
+	require.NoError(t, Themes().LoadFromFile(path))
+	t.Cleanup(func() {
+		Themes().mu.Lock()
+		Themes().themes = builtinThemeCopies()
+		Themes().paths = nil
+		Themes().mu.Unlock()
+	})
+
+	generator := NewDungeonGenerator(nil)
+	assert.Contains(t, generator.generateDungeonName("sunken"), "Drowned")
+	assert.Equal(t, RoomTypeSecret, generator.determineRoomType(1, "sunken"))
+
+	// Unregistered themes fall back to the classic theme
+	assert.NotEmpty(t, generator.generateDungeonName("unregistered"))
+}
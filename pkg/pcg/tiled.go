@@ -0,0 +1,279 @@
+package pcg
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Tiled map format identifiers written into exported maps
+const (
+	TiledFormatVersion = "1.10"
+	TiledOrientation   = "orthogonal"
+	TiledRenderOrder   = "right-down"
+)
+
+// Defaults of TiledOptions
+const (
+	DefaultTiledTileSize       = 32
+	DefaultTiledTilesetColumns = 8
+)
+
+// TiledOptions controls how generated maps are exported to the Tiled map
+// editor's JSON format. Zero values take the defaults.
+type TiledOptions struct {
+	TileWidth      int    `json:"tile_width"`      // Tile width in pixels
+	TileHeight     int    `json:"tile_height"`     // Tile height in pixels
+	TilesetImage   string `json:"tileset_image"`   // Path of the tileset image, relative to the map file
+	TilesetColumns int    `json:"tileset_columns"` // Tiles per row of the tileset image
+}
+
+// withDefaults returns the options with zero values replaced by defaults
+func (o TiledOptions) withDefaults(image string, columns int) TiledOptions {
+	if o.TileWidth <= 0 {
+		o.TileWidth = DefaultTiledTileSize
+	}
+	if o.TileHeight <= 0 {
+		o.TileHeight = DefaultTiledTileSize
+	}
+	if o.TilesetImage == "" {
+		o.TilesetImage = image
+	}
+	if o.TilesetColumns <= 0 {
+		o.TilesetColumns = columns
+	}
+	return o
+}
+
+// TiledMap is a map in the JSON format of the Tiled map editor
+// (https://www.mapeditor.org). Exported maps embed their tileset, so the
+// file opens on its own once the tileset image is next to it.
+type TiledMap struct {
+	Type         string          `json:"type"`
+	Version      string          `json:"version"`
+	Orientation  string          `json:"orientation"`
+	RenderOrder  string          `json:"renderorder"`
+	Width        int             `json:"width"`
+	Height       int             `json:"height"`
+	TileWidth    int             `json:"tilewidth"`
+	TileHeight   int             `json:"tileheight"`
+	Infinite     bool            `json:"infinite"`
+	NextLayerID  int             `json:"nextlayerid"`
+	NextObjectID int             `json:"nextobjectid"`
+	Layers       []TiledLayer    `json:"layers"`
+	Tilesets     []TiledTileset  `json:"tilesets"`
+	Properties   []TiledProperty `json:"properties,omitempty"`
+}
+
+// TiledLayer is a tile layer or an object group of a TiledMap
+type TiledLayer struct {
+	ID        int           `json:"id"`
+	Name      string        `json:"name"`
+	Type      string        `json:"type"` // "tilelayer" or "objectgroup"
+	X         int           `json:"x"`
+	Y         int           `json:"y"`
+	Width     int           `json:"width,omitempty"`
+	Height    int           `json:"height,omitempty"`
+	Opacity   float64       `json:"opacity"`
+	Visible   bool          `json:"visible"`
+	Data      []int         `json:"data,omitempty"`      // Tile GIDs, row by row; 0 is empty
+	DrawOrder string        `json:"draworder,omitempty"` // Object groups only
+	Objects   []TiledObject `json:"objects,omitempty"`
+}
+
+// TiledTileset is a tileset embedded in a TiledMap
+type TiledTileset struct {
+	FirstGID    int         `json:"firstgid"`
+	Name        string      `json:"name"`
+	TileWidth   int         `json:"tilewidth"`
+	TileHeight  int         `json:"tileheight"`
+	TileCount   int         `json:"tilecount"`
+	Columns     int         `json:"columns"`
+	Image       string      `json:"image"`
+	ImageWidth  int         `json:"imagewidth"`
+	ImageHeight int         `json:"imageheight"`
+	Margin      int         `json:"margin"`
+	Spacing     int         `json:"spacing"`
+	Tiles       []TiledTile `json:"tiles,omitempty"`
+}
+
+// TiledTile describes one tile of a tileset
+type TiledTile struct {
+	ID         int             `json:"id"`
+	Type       string          `json:"type,omitempty"`
+	Properties []TiledProperty `json:"properties,omitempty"`
+}
+
+// TiledObject is an object of an object group, placed in pixels
+type TiledObject struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	X          float64         `json:"x"`
+	Y          float64         `json:"y"`
+	Width      float64         `json:"width"`
+	Height     float64         `json:"height"`
+	Rotation   float64         `json:"rotation"`
+	Visible    bool            `json:"visible"`
+	Properties []TiledProperty `json:"properties,omitempty"`
+}
+
+// TiledProperty is a typed custom property
+type TiledProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"` // "string", "int", "float" or "bool"
+	Value interface{} `json:"value"`
+}
+
+// newTiledMap creates an empty orthogonal map of width by height tiles
+// with the tile size of opts, which must already hold its defaults.
+func newTiledMap(width, height int, opts TiledOptions) *TiledMap {
+	return &TiledMap{
+		Type:         "map",
+		Version:      TiledFormatVersion,
+		Orientation:  TiledOrientation,
+		RenderOrder:  TiledRenderOrder,
+		Width:        width,
+		Height:       height,
+		TileWidth:    opts.TileWidth,
+		TileHeight:   opts.TileHeight,
+		NextLayerID:  1,
+		NextObjectID: 1,
+		Layers:       []TiledLayer{},
+		Tilesets:     []TiledTileset{},
+	}
+}
+
+// TiledExport builds a TiledMap. Exporters of the terrain and levels
+// packages use it to lay out tile layers, object groups and the tileset.
+type TiledExport struct {
+	Map     *TiledMap
+	Options TiledOptions
+}
+
+// NewTiledExport starts exporting a map of width by height tiles. Options
+// left zero take the tile size defaults, image and columns.
+func NewTiledExport(width, height int, opts TiledOptions, image string, columns int) (*TiledExport, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("cannot export a %dx%d map", width, height)
+	}
+	opts = opts.withDefaults(image, columns)
+	return &TiledExport{Map: newTiledMap(width, height, opts), Options: opts}, nil
+}
+
+// AddTileset embeds a tileset of tileCount tiles laid out in the options'
+// columns and returns its first GID.
+func (e *TiledExport) AddTileset(name string, tileCount int, tiles []TiledTile) int {
+	firstGID := 1
+	for _, tileset := range e.Map.Tilesets {
+		firstGID = max(firstGID, tileset.FirstGID+tileset.TileCount)
+	}
+
+	columns := e.Options.TilesetColumns
+	rows := (tileCount + columns - 1) / columns
+	e.Map.Tilesets = append(e.Map.Tilesets, TiledTileset{
+		FirstGID:    firstGID,
+		Name:        name,
+		TileWidth:   e.Options.TileWidth,
+		TileHeight:  e.Options.TileHeight,
+		TileCount:   tileCount,
+		Columns:     columns,
+		Image:       e.Options.TilesetImage,
+		ImageWidth:  columns * e.Options.TileWidth,
+		ImageHeight: rows * e.Options.TileHeight,
+		Tiles:       tiles,
+	})
+	return firstGID
+}
+
+// AddTileLayer adds a layer covering the whole map. gid returns the GID of
+// the tile at x, y, or 0 for none.
+func (e *TiledExport) AddTileLayer(name string, gid func(x, y int) int) {
+	data := make([]int, 0, e.Map.Width*e.Map.Height)
+	for y := 0; y < e.Map.Height; y++ {
+		for x := 0; x < e.Map.Width; x++ {
+			data = append(data, gid(x, y))
+		}
+	}
+	e.Map.Layers = append(e.Map.Layers, TiledLayer{
+		ID:      e.nextLayerID(),
+		Name:    name,
+		Type:    "tilelayer",
+		Width:   e.Map.Width,
+		Height:  e.Map.Height,
+		Opacity: 1,
+		Visible: true,
+		Data:    data,
+	})
+}
+
+// AddObjectGroup adds a group of objects, numbering them. Empty groups are
+// left out.
+func (e *TiledExport) AddObjectGroup(name string, objects []TiledObject) {
+	if len(objects) == 0 {
+		return
+	}
+	for i := range objects {
+		objects[i].ID = e.Map.NextObjectID
+		e.Map.NextObjectID++
+	}
+	e.Map.Layers = append(e.Map.Layers, TiledLayer{
+		ID:        e.nextLayerID(),
+		Name:      name,
+		Type:      "objectgroup",
+		Opacity:   1,
+		Visible:   true,
+		DrawOrder: "topdown",
+		Objects:   objects,
+	})
+}
+
+// TileObject returns an object covering the tile at x, y
+func (e *TiledExport) TileObject(name, objectType string, x, y int, properties map[string]interface{}) TiledObject {
+	return TiledObject{
+		Name:       name,
+		Type:       objectType,
+		X:          float64(x * e.Options.TileWidth),
+		Y:          float64(y * e.Options.TileHeight),
+		Width:      float64(e.Options.TileWidth),
+		Height:     float64(e.Options.TileHeight),
+		Visible:    true,
+		Properties: TiledProperties(properties),
+	}
+}
+
+// nextLayerID returns the ID of a new layer
+func (e *TiledExport) nextLayerID() int {
+	id := e.Map.NextLayerID
+	e.Map.NextLayerID++
+	return id
+}
+
+// TiledProperties converts values to typed Tiled properties sorted by
+// name. Values that are not strings, numbers or booleans are written with
+// fmt's default formatting.
+func TiledProperties(values map[string]interface{}) []TiledProperty {
+	if len(values) == 0 {
+		return nil
+	}
+
+	properties := make([]TiledProperty, 0, len(values))
+	for name, value := range values {
+		property := TiledProperty{Name: name, Value: value}
+		switch value.(type) {
+		case bool:
+			property.Type = "bool"
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			property.Type = "int"
+		case float32, float64:
+			property.Type = "float"
+		case string:
+			property.Type = "string"
+		default:
+			property.Type = "string"
+			property.Value = fmt.Sprint(value)
+		}
+		properties = append(properties, property)
+	}
+	sort.Slice(properties, func(i, j int) bool { return properties[i].Name < properties[j].Name })
+	return properties
+}
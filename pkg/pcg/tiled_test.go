@@ -0,0 +1,61 @@
+package pcg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTiledProperties(t *testing.T) {
+	properties := TiledProperties(map[string]interface{}{
+		"name":     "gate",
+		"locked":   true,
+		"dc":       12,
+		"chance":   0.5,
+		"position": struct{ X, Y int }{1, 2},
+	})
+	assert.Equal(t, []TiledProperty{
+		{Name: "chance", Type: "float", Value: 0.5},
+		{Name: "dc", Type: "int", Value: 12},
+		{Name: "locked", Type: "bool", Value: true},
+		{Name: "name", Type: "string", Value: "gate"},
+		{Name: "position", Type: "string", Value: "{1 2}"},
+	}, properties)
+	assert.Nil(t, TiledProperties(nil))
+}
+
+func TestTiledExport(t *testing.T) {
+	_, err := NewTiledExport(0, 4, TiledOptions{}, "tiles.png", 4)
+	assert.Error(t, err)
+
+	export, err := NewTiledExport(3, 2, TiledOptions{TileWidth: 16}, "tiles.png", 4)
+	require.NoError(t, err)
+	assert.Equal(t, 16, export.Map.TileWidth)
+	assert.Equal(t, DefaultTiledTileSize, export.Map.TileHeight)
+
+	first := export.AddTileset("base", 6, nil)
+	second := export.AddTileset("extra", 4, nil)
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 7, second, "tilesets take consecutive GIDs")
+	assert.Equal(t, 64, export.Map.Tilesets[0].ImageWidth)
+	assert.Equal(t, 64, export.Map.Tilesets[0].ImageHeight, "6 tiles in 4 columns take 2 rows")
+
+	export.AddTileLayer("ground", func(x, y int) int { return first + y*3 + x })
+	export.AddObjectGroup("empty", nil)
+	export.AddObjectGroup("doors", []TiledObject{
+		export.TileObject("a", "door", 1, 1, nil),
+		export.TileObject("b", "door", 2, 0, map[string]interface{}{"locked": true}),
+	})
+
+	require.Len(t, export.Map.Layers, 2, "empty object groups are left out")
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, export.Map.Layers[0].Data)
+	doors := export.Map.Layers[1]
+	assert.Equal(t, "objectgroup", doors.Type)
+	assert.Equal(t, 2, doors.ID)
+	assert.Equal(t, []int{1, 2}, []int{doors.Objects[0].ID, doors.Objects[1].ID})
+	assert.Equal(t, 3, export.Map.NextObjectID)
+	assert.Equal(t, 3, export.Map.NextLayerID)
+	assert.Equal(t, float64(16), doors.Objects[0].X)
+	assert.Equal(t, float64(32), doors.Objects[0].Y)
+}
@@ -0,0 +1,543 @@
+package pcg
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+)
+
+// ErrNoTravelRoute is returned by TravelGraph.Route when the destination
+// cannot be reached from the start
+var ErrNoTravelRoute = errors.New("no travel route to destination")
+
+// TravelLegWalk is the kind of a leg walked across a single level, off any
+// road or level connection
+const TravelLegWalk = "walk"
+
+// Default travel times per step. A dungeon step is an exploration turn; an
+// overworld step crosses a region tile on foot.
+const (
+	DefaultDungeonStepDuration   = time.Minute
+	DefaultOverworldStepDuration = time.Hour
+)
+
+// connectionDurations is how long taking a level connection lasts
+var connectionDurations = map[ConnectionType]time.Duration{
+	ConnectionStairs:   time.Minute,
+	ConnectionLadder:   2 * time.Minute,
+	ConnectionElevator: 5 * time.Minute,
+	ConnectionPortal:   0,
+	ConnectionPit:      0,
+	ConnectionTunnel:   10 * time.Minute,
+}
+
+// pathSpeeds scales the overworld step duration on travel paths: roads
+// halve it, while tunnels are no faster than walking cross-country
+var pathSpeeds = map[PathType]float64{
+	PathRoad:   0.5,
+	PathTrail:  0.75,
+	PathRiver:  0.5,
+	PathSea:    0.25,
+	PathBridge: 0.5,
+	PathTunnel: 1,
+}
+
+// TravelPlace is a node of the travel graph: a level connection endpoint,
+// a settlement or a landmark.
+type TravelPlace struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name,omitempty"`
+	Position game.Position `json:"position"`
+}
+
+// TravelLink is an edge of the travel graph between two places, such as a
+// staircase between dungeon levels or a road between settlements.
+type TravelLink struct {
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Kind      string          `json:"kind"`                // ConnectionType or PathType
+	Waypoints []game.Position `json:"waypoints,omitempty"` // Positions passed between the ends of a path
+	Duration  time.Duration   `json:"duration"`
+	Danger    int             `json:"danger,omitempty"`
+	Hazards   []HazardType    `json:"hazards,omitempty"`
+	OneWay    bool            `json:"one_way,omitempty"`
+}
+
+// TravelLeg is one stretch of a route: a walk across a level, a path
+// followed through its waypoints, or a connection taken to another level.
+type TravelLeg struct {
+	Kind       string          `json:"kind"`
+	From       game.Position   `json:"from"`
+	To         game.Position   `json:"to"`
+	Place      string          `json:"place,omitempty"` // Name of the place the leg ends at
+	Waypoints  []game.Position `json:"waypoints,omitempty"`
+	Transition bool            `json:"transition"` // Moves straight to To, as by stairs
+	Steps      int             `json:"steps"`
+	Duration   time.Duration   `json:"duration"`
+	Danger     int             `json:"danger,omitempty"`
+	Hazards    []HazardType    `json:"hazards,omitempty"`
+}
+
+// TravelRoute is the fastest way between two positions through the travel
+// graph.
+type TravelRoute struct {
+	From     game.Position `json:"from"`
+	To       game.Position `json:"to"`
+	Legs     []TravelLeg   `json:"legs"`
+	Steps    int           `json:"steps"`
+	Duration time.Duration `json:"duration"`
+	Danger   int           `json:"danger"`
+	Hazards  []HazardType  `json:"hazards,omitempty"`
+}
+
+// TravelGraph connects the levels of the world for route planning. Places
+// on the same level are reachable from each other on foot; links join them
+// across levels, or faster along overworld paths. Each level has a step
+// duration that walking on it takes.
+//
+// TravelGraph is safe for concurrent use.
+type TravelGraph struct {
+	mu     sync.RWMutex
+	places map[string]*TravelPlace
+	links  map[string][]TravelLink
+	steps  map[int]time.Duration
+}
+
+// NewTravelGraph creates an empty travel graph
+func NewTravelGraph() *TravelGraph {
+	return &TravelGraph{
+		places: make(map[string]*TravelPlace),
+		links:  make(map[string][]TravelLink),
+		steps:  make(map[int]time.Duration),
+	}
+}
+
+// AddPlace adds place to the graph, replacing any place with its ID
+func (g *TravelGraph) AddPlace(place TravelPlace) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.places[place.ID] = &place
+}
+
+// AddLink links two places of the graph, in both directions unless the
+// link is one way
+func (g *TravelGraph) AddLink(link TravelLink) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.addLinkLocked(link)
+}
+
+// addLinkLocked adds link. Callers must hold g.mu.
+func (g *TravelGraph) addLinkLocked(link TravelLink) error {
+	if _, exists := g.places[link.From]; !exists {
+		return fmt.Errorf("unknown place %q", link.From)
+	}
+	if _, exists := g.places[link.To]; !exists {
+		return fmt.Errorf("unknown place %q", link.To)
+	}
+	g.links[link.From] = append(g.links[link.From], link)
+	if !link.OneWay {
+		back := link
+		back.From, back.To = link.To, link.From
+		back.Waypoints = make([]game.Position, len(link.Waypoints))
+		for i, point := range link.Waypoints {
+			back.Waypoints[len(link.Waypoints)-1-i] = point
+		}
+		g.links[link.To] = append(g.links[link.To], back)
+	}
+	return nil
+}
+
+// Connect links two positions directly, adding places for them if needed,
+// such as the overworld entrance of a dungeon and its first level
+func (g *TravelGraph) Connect(from, to game.Position, kind string, duration time.Duration, oneWay bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fromID := g.placeAtLocked(from)
+	toID := g.placeAtLocked(to)
+	_ = g.addLinkLocked(TravelLink{From: fromID, To: toID, Kind: kind, Duration: duration, OneWay: oneWay})
+}
+
+// placeAtLocked returns the ID of the place at pos, adding one if there is
+// none. Callers must hold g.mu.
+func (g *TravelGraph) placeAtLocked(pos game.Position) string {
+	id := positionPlaceID(pos)
+	if _, exists := g.places[id]; exists {
+		return id
+	}
+	for _, place := range g.sortedPlacesLocked() {
+		if samePosition(place.Position, pos) {
+			return place.ID
+		}
+	}
+	g.places[id] = &TravelPlace{ID: id, Position: pos}
+	return id
+}
+
+// SetStepDuration sets how long a step on foot takes on a level
+func (g *TravelGraph) SetStepDuration(level int, duration time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.steps[level] = duration
+}
+
+// StepDuration returns how long a step on foot takes on a level,
+// DefaultDungeonStepDuration unless set
+func (g *TravelGraph) StepDuration(level int) time.Duration {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.stepDurationLocked(level)
+}
+
+// stepDurationLocked returns the step duration of level. Callers must hold
+// g.mu.
+func (g *TravelGraph) stepDurationLocked(level int) time.Duration {
+	if duration, exists := g.steps[level]; exists {
+		return duration
+	}
+	return DefaultDungeonStepDuration
+}
+
+// Places returns the places of the graph ordered by ID
+func (g *TravelGraph) Places() []TravelPlace {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	places := make([]TravelPlace, 0, len(g.places))
+	for _, place := range g.sortedPlacesLocked() {
+		places = append(places, *place)
+	}
+	return places
+}
+
+// sortedPlacesLocked returns the places of the graph ordered by ID, so
+// routes of equal cost are chosen the same way every time. Callers must
+// hold g.mu.
+func (g *TravelGraph) sortedPlacesLocked() []*TravelPlace {
+	places := make([]*TravelPlace, 0, len(g.places))
+	for _, place := range g.places {
+		places = append(places, place)
+	}
+	sort.Slice(places, func(i, j int) bool { return places[i].ID < places[j].ID })
+	return places
+}
+
+// AddDungeon adds the level connections of a dungeon complex whose levels
+// were added to the world from baseLevel on, the first dungeon level at
+// baseLevel. Pits only lead down.
+func (g *TravelGraph) AddDungeon(dungeon *DungeonComplex, baseLevel int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	levels := make([]int, 0, len(dungeon.Levels))
+	for number := range dungeon.Levels {
+		levels = append(levels, number)
+	}
+	sort.Ints(levels)
+	index := make(map[int]int, len(levels))
+	for i, number := range levels {
+		index[number] = baseLevel + i
+		g.steps[baseLevel+i] = DefaultDungeonStepDuration
+	}
+
+	for _, connection := range dungeon.Connections {
+		fromLevel, fromKnown := index[connection.FromLevel]
+		toLevel, toKnown := index[connection.ToLevel]
+		if !fromKnown || !toKnown {
+			continue
+		}
+		from := g.addDungeonPlaceLocked(dungeon, connection.FromPosition, fromLevel, connection.FromLevel)
+		to := g.addDungeonPlaceLocked(dungeon, connection.ToPosition, toLevel, connection.ToLevel)
+		_ = g.addLinkLocked(TravelLink{
+			From:     from,
+			To:       to,
+			Kind:     string(connection.Type),
+			Duration: connectionDurations[connection.Type],
+			OneWay:   connection.Type == ConnectionPit,
+		})
+	}
+}
+
+// addDungeonPlaceLocked adds the place of a connection endpoint on a
+// dungeon level. Callers must hold g.mu.
+func (g *TravelGraph) addDungeonPlaceLocked(dungeon *DungeonComplex, pos game.Position, worldLevel, number int) string {
+	pos = game.Position{X: pos.X, Y: pos.Y, Level: worldLevel}
+	id := fmt.Sprintf("%s:%s", dungeon.ID, positionPlaceID(pos))
+	if _, exists := g.places[id]; !exists {
+		g.places[id] = &TravelPlace{ID: id, Name: fmt.Sprintf("%s level %d", dungeon.Name, number), Position: pos}
+	}
+	return id
+}
+
+// AddWorld adds the settlements, landmarks and travel paths of an
+// overworld placed on the given world level. Walking there off the paths
+// takes DefaultOverworldStepDuration per step.
+func (g *TravelGraph) AddWorld(world *GeneratedWorld, level int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.steps[level] = DefaultOverworldStepDuration
+	at := func(pos game.Position) game.Position {
+		return game.Position{X: pos.X, Y: pos.Y, Level: level}
+	}
+	for _, settlement := range world.Settlements {
+		g.places[settlement.ID] = &TravelPlace{ID: settlement.ID, Name: settlement.Name, Position: at(settlement.Position)}
+	}
+	for _, landmark := range world.Landmarks {
+		g.places[landmark.ID] = &TravelPlace{ID: landmark.ID, Name: landmark.Name, Position: at(landmark.Position)}
+	}
+
+	for _, path := range world.TravelPaths {
+		waypoints := make([]game.Position, 0, len(path.Points))
+		for _, point := range path.Points {
+			waypoints = append(waypoints, at(point))
+		}
+		speed, known := pathSpeeds[path.Type]
+		if !known {
+			speed = 1
+		}
+		steps := waypointSteps(waypoints)
+		_ = g.addLinkLocked(TravelLink{
+			From:      path.From,
+			To:        path.To,
+			Kind:      string(path.Type),
+			Waypoints: waypoints,
+			Duration:  time.Duration(float64(steps) * speed * float64(DefaultOverworldStepDuration)),
+			Danger:    path.Difficulty,
+			Hazards:   path.Hazards,
+		})
+	}
+}
+
+// travelNode is a vertex of the route search: a place, or the start or
+// goal of the route
+type travelNode struct {
+	id       string
+	position game.Position
+}
+
+// Route node IDs of the route ends, which cannot clash with place IDs
+const (
+	travelStartID = "\x00start"
+	travelGoalID  = "\x00goal"
+)
+
+// travelEdge is an edge considered by the route search
+type travelEdge struct {
+	to   travelNode
+	leg  TravelLeg
+	cost time.Duration
+}
+
+// Route plans the fastest route from one position to another: walking on
+// the level of each, and taking links wherever they save time. Among
+// equally fast routes the least dangerous is taken.
+//
+// Returns ErrNoTravelRoute when no links join the levels of the two
+// positions.
+func (g *TravelGraph) Route(from, to game.Position) (*TravelRoute, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	start := travelNode{id: travelStartID, position: from}
+	goal := travelNode{id: travelGoalID, position: to}
+
+	best := map[string]travelCost{start.id: {}}
+	previous := make(map[string]travelStep)
+	queue := &travelQueue{{node: start}}
+	done := make(map[string]bool)
+	places := g.sortedPlacesLocked()
+
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(*travelQueueItem)
+		if done[current.node.id] {
+			continue
+		}
+		done[current.node.id] = true
+		if current.node.id == goal.id {
+			return g.buildRoute(from, to, previous), nil
+		}
+
+		for _, edge := range g.edgesLocked(current.node, goal, places) {
+			cost := best[current.node.id].add(edge)
+			if known, seen := best[edge.to.id]; seen && !cost.less(known) {
+				continue
+			}
+			best[edge.to.id] = cost
+			previous[edge.to.id] = travelStep{from: current.node.id, leg: edge.leg}
+			heap.Push(queue, &travelQueueItem{node: edge.to, cost: cost})
+		}
+	}
+
+	return nil, fmt.Errorf("%w: from level %d to level %d", ErrNoTravelRoute, from.Level, to.Level)
+}
+
+// edgesLocked returns the edges leaving node: walks to the places and goal
+// on its level, and the links of a place. Callers must hold g.mu.
+func (g *TravelGraph) edgesLocked(node, goal travelNode, places []*TravelPlace) []travelEdge {
+	var edges []travelEdge
+	walk := func(to travelNode, name string) {
+		if to.position.Level != node.position.Level || to.id == node.id {
+			return
+		}
+		steps := manhattan(node.position, to.position)
+		duration := time.Duration(steps) * g.stepDurationLocked(node.position.Level)
+		edges = append(edges, travelEdge{
+			to:   to,
+			cost: duration,
+			leg: TravelLeg{
+				Kind:     TravelLegWalk,
+				From:     node.position,
+				To:       to.position,
+				Place:    name,
+				Steps:    steps,
+				Duration: duration,
+			},
+		})
+	}
+
+	walk(goal, "")
+	for _, place := range places {
+		walk(travelNode{id: place.ID, position: place.Position}, place.Name)
+	}
+
+	for _, link := range g.links[node.id] {
+		place := g.places[link.To]
+		leg := TravelLeg{
+			Kind:     link.Kind,
+			From:     node.position,
+			To:       place.Position,
+			Place:    place.Name,
+			Duration: link.Duration,
+			Danger:   link.Danger,
+			Hazards:  link.Hazards,
+		}
+		if len(link.Waypoints) > 0 {
+			leg.Waypoints = link.Waypoints
+			leg.Steps = waypointSteps(append(append([]game.Position{node.position}, link.Waypoints...), place.Position))
+		} else {
+			leg.Transition = true
+			leg.Steps = 1
+		}
+		edges = append(edges, travelEdge{to: travelNode{id: place.ID, position: place.Position}, leg: leg, cost: link.Duration})
+	}
+	return edges
+}
+
+// buildRoute assembles the route ending at the goal from the search's
+// previous steps
+func (g *TravelGraph) buildRoute(from, to game.Position, previous map[string]travelStep) *TravelRoute {
+	route := &TravelRoute{From: from, To: to}
+	for id := travelGoalID; id != travelStartID; {
+		step := previous[id]
+		route.Legs = append(route.Legs, step.leg)
+		id = step.from
+	}
+	for i, j := 0, len(route.Legs)-1; i < j; i, j = i+1, j-1 {
+		route.Legs[i], route.Legs[j] = route.Legs[j], route.Legs[i]
+	}
+
+	hazards := make(map[HazardType]bool)
+	legs := route.Legs[:0]
+	for _, leg := range route.Legs {
+		if leg.Kind == TravelLegWalk && leg.Steps == 0 {
+			continue
+		}
+		legs = append(legs, leg)
+		route.Steps += leg.Steps
+		route.Duration += leg.Duration
+		route.Danger += leg.Danger
+		for _, hazard := range leg.Hazards {
+			if !hazards[hazard] {
+				hazards[hazard] = true
+				route.Hazards = append(route.Hazards, hazard)
+			}
+		}
+	}
+	route.Legs = legs
+	return route
+}
+
+// travelCost orders routes by duration and then danger
+type travelCost struct {
+	duration time.Duration
+	danger   int
+}
+
+func (c travelCost) add(edge travelEdge) travelCost {
+	return travelCost{duration: c.duration + edge.cost, danger: c.danger + edge.leg.Danger}
+}
+
+func (c travelCost) less(other travelCost) bool {
+	if c.duration != other.duration {
+		return c.duration < other.duration
+	}
+	return c.danger < other.danger
+}
+
+// travelStep records how the search reached a node
+type travelStep struct {
+	from string
+	leg  TravelLeg
+}
+
+// travelQueueItem is a node waiting in the route search
+type travelQueueItem struct {
+	node travelNode
+	cost travelCost
+}
+
+// travelQueue is a min-heap of nodes by cost
+type travelQueue []*travelQueueItem
+
+func (q travelQueue) Len() int           { return len(q) }
+func (q travelQueue) Less(i, j int) bool { return q[i].cost.less(q[j].cost) }
+func (q travelQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *travelQueue) Push(x interface{}) {
+	*q = append(*q, x.(*travelQueueItem))
+}
+
+func (q *travelQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// waypointSteps returns the steps walked through points in order
+func waypointSteps(points []game.Position) int {
+	steps := 0
+	for i := 1; i < len(points); i++ {
+		steps += manhattan(points[i-1], points[i])
+	}
+	return steps
+}
+
+// manhattan returns the steps between two positions on a level
+func manhattan(a, b game.Position) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+// samePosition reports whether two positions are the same tile, whatever
+// way they face
+func samePosition(a, b game.Position) bool {
+	return a.X == b.X && a.Y == b.Y && a.Level == b.Level
+}
+
+// positionPlaceID names the place at pos
+func positionPlaceID(pos game.Position) string {
+	return fmt.Sprintf("%d:%d,%d", pos.Level, pos.X, pos.Y)
+}
@@ -0,0 +1,148 @@
+package pcg
+
+import (
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// travelTestDungeon returns a three-level dungeon with stairs from the
+// first level to the second and a pit from the second to the third
+func travelTestDungeon() *DungeonComplex {
+	return &DungeonComplex{
+		ID:   "crypt",
+		Name: "Crypt",
+		Levels: map[int]*DungeonLevel{
+			1: {Level: 1, Map: travelTestMap(6, 6), Connections: []ConnectionPoint{
+				{Position: game.Position{X: 4, Y: 1}, Type: ConnectionStairs, TargetLevel: 2},
+			}},
+			2: {Level: 2, Map: travelTestMap(6, 6), Connections: []ConnectionPoint{
+				{Position: game.Position{X: 1, Y: 1}, Type: ConnectionStairs, TargetLevel: 1},
+				{Position: game.Position{X: 1, Y: 4}, Type: ConnectionPit, TargetLevel: 3},
+			}},
+			3: {Level: 3, Map: travelTestMap(6, 6), Connections: []ConnectionPoint{
+				{Position: game.Position{X: 2, Y: 2}, Type: ConnectionPit, TargetLevel: 2},
+			}},
+		},
+		Connections: []LevelConnection{
+			{FromLevel: 1, ToLevel: 2, FromPosition: game.Position{X: 4, Y: 1}, ToPosition: game.Position{X: 1, Y: 1}, Type: ConnectionStairs},
+			{FromLevel: 2, ToLevel: 3, FromPosition: game.Position{X: 1, Y: 4}, ToPosition: game.Position{X: 2, Y: 2}, Type: ConnectionPit},
+		},
+	}
+}
+
+func travelTestMap(width, height int) *game.GameMap {
+	tiles := make([][]game.MapTile, height)
+	for y := range tiles {
+		tiles[y] = make([]game.MapTile, width)
+		for x := range tiles[y] {
+			tiles[y][x] = game.MapTile{Walkable: true, Transparent: true}
+		}
+	}
+	return &game.GameMap{Width: width, Height: height, Tiles: tiles}
+}
+
+func legKinds(route *TravelRoute) []string {
+	kinds := make([]string, len(route.Legs))
+	for i, leg := range route.Legs {
+		kinds[i] = leg.Kind
+	}
+	return kinds
+}
+
+func TestTravelGraph_RouteAcrossDungeonLevels(t *testing.T) {
+	graph := NewTravelGraph()
+	graph.AddDungeon(travelTestDungeon(), 1)
+
+	route, err := graph.Route(game.Position{X: 1, Y: 1, Level: 1}, game.Position{X: 4, Y: 4, Level: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{TravelLegWalk, "stairs", TravelLegWalk, "pit", TravelLegWalk}, legKinds(route))
+	assert.Equal(t, game.Position{X: 1, Y: 1, Level: 2}, route.Legs[1].To, "dungeon levels start at the base level")
+	assert.True(t, route.Legs[1].Transition)
+	assert.Equal(t, "Crypt level 2", route.Legs[1].Place)
+
+	// 3 steps to the stairs, 3 to the pit and 4 to the goal, one step each
+	// for the stairs and the pit
+	assert.Equal(t, 12, route.Steps)
+	assert.Equal(t, 10*DefaultDungeonStepDuration+time.Minute, route.Duration)
+
+	_, err = graph.Route(game.Position{X: 4, Y: 4, Level: 3}, game.Position{X: 1, Y: 1, Level: 1})
+	assert.ErrorIs(t, err, ErrNoTravelRoute, "pits only lead down")
+
+	back, err := graph.Route(game.Position{X: 3, Y: 3, Level: 2}, game.Position{X: 0, Y: 0, Level: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{TravelLegWalk, "stairs", TravelLegWalk}, legKinds(back), "stairs lead both ways")
+}
+
+func TestTravelGraph_RouteOnOneLevel(t *testing.T) {
+	graph := NewTravelGraph()
+	graph.AddDungeon(travelTestDungeon(), 0)
+
+	route, err := graph.Route(game.Position{X: 0, Y: 0}, game.Position{X: 2, Y: 3})
+	require.NoError(t, err)
+	require.Len(t, route.Legs, 1, "no detour through the stairs")
+	assert.Equal(t, TravelLegWalk, route.Legs[0].Kind)
+	assert.Equal(t, 5, route.Steps)
+
+	route, err = graph.Route(game.Position{X: 2, Y: 3}, game.Position{X: 2, Y: 3})
+	require.NoError(t, err)
+	assert.Empty(t, route.Legs, "already there")
+}
+
+func TestTravelGraph_AddWorldPrefersRoads(t *testing.T) {
+	world := &GeneratedWorld{
+		Settlements: []*Settlement{
+			{ID: "hommlet", Name: "Hommlet", Position: game.Position{X: 0, Y: 0}},
+			{ID: "nulb", Name: "Nulb", Position: game.Position{X: 10, Y: 0}},
+		},
+		Landmarks: []*Landmark{
+			{ID: "moathouse", Name: "Moathouse", Position: game.Position{X: 5, Y: 5}},
+		},
+		TravelPaths: []*TravelPath{{
+			ID:         "road",
+			Type:       PathRoad,
+			From:       "hommlet",
+			To:         "nulb",
+			Points:     []game.Position{{X: 0, Y: 0}, {X: 5, Y: 2}, {X: 10, Y: 0}},
+			Difficulty: 2,
+			Hazards:    []HazardType{HazardBandits},
+		}},
+	}
+	graph := NewTravelGraph()
+	graph.AddWorld(world, 0)
+
+	route, err := graph.Route(game.Position{X: 0, Y: 1}, game.Position{X: 10, Y: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{TravelLegWalk, "road", TravelLegWalk}, legKinds(route))
+	road := route.Legs[1]
+	assert.Equal(t, "Nulb", road.Place)
+	assert.Equal(t, 14, road.Steps, "the road winds through its waypoints")
+	assert.Equal(t, 7*time.Hour, road.Duration, "roads take half the time of walking")
+	assert.Equal(t, 2, route.Danger)
+	assert.Equal(t, []HazardType{HazardBandits}, route.Hazards)
+
+	// The road is no help on the way to the moathouse
+	route, err = graph.Route(game.Position{X: 0, Y: 0}, game.Position{X: 5, Y: 5})
+	require.NoError(t, err)
+	assert.Equal(t, []string{TravelLegWalk}, legKinds(route))
+	assert.Equal(t, 10*DefaultOverworldStepDuration, route.Duration)
+}
+
+func TestTravelGraph_ConnectOverworldToDungeon(t *testing.T) {
+	graph := NewTravelGraph()
+	graph.AddWorld(&GeneratedWorld{}, 0)
+	graph.AddDungeon(travelTestDungeon(), 1)
+	graph.Connect(game.Position{X: 20, Y: 20}, game.Position{X: 0, Y: 0, Level: 1}, string(ConnectionStairs), time.Minute, false)
+
+	route, err := graph.Route(game.Position{X: 20, Y: 18}, game.Position{X: 1, Y: 1, Level: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{TravelLegWalk, "stairs", TravelLegWalk, "stairs"}, legKinds(route))
+	assert.Equal(t, 2*DefaultOverworldStepDuration, route.Legs[0].Duration)
+	assert.Equal(t, DefaultDungeonStepDuration, graph.StepDuration(1))
+	assert.Len(t, graph.Places(), 6)
+}
@@ -19,6 +19,7 @@ const (
 	BiomeCoastal   BiomeType = "coastal"
 	BiomeUrban     BiomeType = "urban"
 	BiomeWasteland BiomeType = "wasteland"
+	BiomePlains    BiomeType = "plains"
 )
 
 // RarityTier represents item rarity levels
@@ -208,6 +209,43 @@ type CorridorFeature struct {
 	Properties map[string]interface{} `yaml:"properties"` // Feature-specific data
 }
 
+// ShopSite records a shop room of a generated level, so a merchant can be
+// stocked for it when the level enters the world
+type ShopSite struct {
+	RoomID     string        `yaml:"room_id"`    // Shop room identifier
+	Position   game.Position `yaml:"position"`   // Where the merchant stands
+	Faction    string        `yaml:"faction"`    // Faction the merchant trades for
+	Seed       int64         `yaml:"seed"`       // Seed for the merchant's stock
+	Difficulty int           `yaml:"difficulty"` // Challenge rating of the room
+}
+
+// DoorSite records a door of a generated level, so the door can be placed
+// in the world when the level enters it. Locked doors name their key.
+type DoorSite struct {
+	DoorID   string         `yaml:"door_id"`          // Door identifier within the level
+	Position game.Position  `yaml:"position"`         // Where the door stands
+	State    game.DoorState `yaml:"state"`            // Closed or locked
+	LockDC   int            `yaml:"lock_dc"`          // Difficulty of picking the lock, if locked
+	KeyID    string         `yaml:"key_id,omitempty"` // Key that unlocks the door, if locked
+}
+
+// KeySite records where the key of a locked door lies in a generated level
+type KeySite struct {
+	KeyID    string        `yaml:"key_id"`   // Item ID of the key
+	DoorID   string        `yaml:"door_id"`  // Door the key unlocks
+	RoomID   string        `yaml:"room_id"`  // Room the key lies in
+	Position game.Position `yaml:"position"` // Where the key lies
+}
+
+// RoomSite records a room of a generated level, so the level's rooms can be
+// found and searched without scanning its tiles
+type RoomSite struct {
+	RoomID     string   `yaml:"room_id"`           // Room identifier
+	Type       RoomType `yaml:"type"`              // Room type classification
+	Difficulty int      `yaml:"difficulty"`        // Challenge rating of the room
+	Faction    string   `yaml:"faction,omitempty"` // Faction present in the room, if any
+}
+
 // ItemTemplate represents a template for procedural item generation
 type ItemTemplate struct {
 	BaseType   string                `yaml:"base_type"`   // Base item type (sword, armor, etc.)
@@ -247,6 +285,10 @@ type QuestObjective struct {
 	Complete    bool                   `yaml:"complete"`    // Completion status
 	Optional    bool                   `yaml:"optional"`    // Whether objective is optional
 	Conditions  map[string]interface{} `yaml:"conditions"`  // Completion conditions
+
+	// TimeLimitHours is the game hours allowed to finish the objective, 0
+	// for no limit
+	TimeLimitHours int `yaml:"time_limit_hours,omitempty"`
 }
 
 // Faction-related types
@@ -525,6 +567,10 @@ type PersonalityProfile struct {
 	Values      []string           `json:"values"`      // What the character values most
 	Fears       []string           `json:"fears"`       // Character's primary fears
 	Speech      SpeechPattern      `json:"speech"`      // How the character speaks
+
+	// Scores and Modifiers are set by the PersonalityGenerator
+	Scores    map[PersonalityAxis]float64 `json:"scores,omitempty"`    // Bravery, greed and honesty, 0-1
+	Modifiers *game.BehaviorModifiers     `json:"modifiers,omitempty"` // Behavior the AI acts on
 }
 
 // SpeechPattern represents how a character communicates
@@ -534,6 +580,7 @@ type SpeechPattern struct {
 	Accent      string   `json:"accent"`      // Regional or cultural accent
 	Mannerisms  []string `json:"mannerisms"`  // Speech habits or quirks
 	Catchphrase string   `json:"catchphrase"` // Signature phrase (optional)
+	Diction     string   `json:"diction"`     // Voice style, such as "courtly" (optional)
 }
 
 // Reputation-related types
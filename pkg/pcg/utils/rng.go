@@ -0,0 +1,327 @@
+package utils
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"math/rand"
+	"strings"
+)
+
+// RNGAlgorithm names a pseudo-random number generator algorithm
+type RNGAlgorithm string
+
+// Supported RNG algorithms
+const (
+	// RNGMath is math/rand's generator, the default. Seeds produce the same
+	// content they always have.
+	RNGMath RNGAlgorithm = "math"
+
+	// RNGPCG64 is PCG-XSL-RR 128/64, with 2^127 independent streams and
+	// jump-ahead by any distance.
+	RNGPCG64 RNGAlgorithm = "pcg64"
+
+	// RNGXoshiro256 is xoshiro256**, with jumps of 2^128 steps for
+	// non-overlapping parallel sequences.
+	RNGXoshiro256 RNGAlgorithm = "xoshiro256"
+
+	// RNGCrypto reads crypto/rand. It cannot be seeded, so it is only for
+	// security-sensitive values that must not be reproducible.
+	RNGCrypto RNGAlgorithm = "crypto"
+)
+
+// ParseRNGAlgorithm returns the algorithm named by name, ignoring case. An
+// empty name selects RNGMath.
+func ParseRNGAlgorithm(name string) (RNGAlgorithm, error) {
+	switch alg := RNGAlgorithm(strings.ToLower(strings.TrimSpace(name))); alg {
+	case "":
+		return RNGMath, nil
+	case RNGMath, RNGPCG64, RNGXoshiro256, RNGCrypto:
+		return alg, nil
+	default:
+		return "", fmt.Errorf("unknown RNG algorithm %q (want math, pcg64, xoshiro256 or crypto)", name)
+	}
+}
+
+// JumpSource is a random source that can skip ahead to a sequence that
+// does not overlap the one it would otherwise produce, so parallel work
+// such as chunk generation can take one jump each.
+type JumpSource interface {
+	rand.Source64
+
+	// Jump advances the source by a fixed, very large number of steps.
+	Jump()
+}
+
+// NewSource creates a source of alg seeded with seed. Sources on different
+// streams are independent even when they share a seed; RNGMath and
+// RNGXoshiro256 mix the stream into the seed, and RNGPCG64 uses it to
+// select one of its streams. RNGCrypto ignores both.
+//
+// Parameters:
+//   - alg: Algorithm of the source
+//   - seed: Seed of the sequence
+//   - stream: Name of the sequence, typically the generator using it; the
+//     empty stream of RNGMath matches rand.NewSource(seed)
+//
+// Returns:
+//   - rand.Source64: The seeded source
+//   - error: The algorithm is unknown
+func NewSource(alg RNGAlgorithm, seed int64, stream string) (rand.Source64, error) {
+	switch alg {
+	case RNGMath, "":
+		if stream != "" {
+			seed = int64(splitMix64(uint64(seed) ^ streamID(stream)))
+		}
+		return rand.NewSource(seed).(rand.Source64), nil
+	case RNGPCG64:
+		return NewPCG64(uint64(seed), streamID(stream)), nil
+	case RNGXoshiro256:
+		return NewXoshiro256(uint64(seed) ^ streamID(stream)), nil
+	case RNGCrypto:
+		return CryptoSource{}, nil
+	default:
+		return nil, fmt.Errorf("unknown RNG algorithm %q", alg)
+	}
+}
+
+// NewRand returns a rand.Rand drawing from NewSource, falling back to
+// math/rand for an unknown algorithm.
+func NewRand(alg RNGAlgorithm, seed int64, stream string) *rand.Rand {
+	source, err := NewSource(alg, seed, stream)
+	if err != nil {
+		source = rand.NewSource(seed).(rand.Source64)
+	}
+	return rand.New(source)
+}
+
+// NewChunkRand returns a rand.Rand for one of several chunks generated in
+// parallel from the same seed and stream. With RNGPCG64 and RNGXoshiro256
+// each chunk's sequence starts chunk jumps into the stream, so chunks never
+// overlap; other algorithms derive an independent seed per chunk.
+func NewChunkRand(alg RNGAlgorithm, seed int64, stream string, chunk int) *rand.Rand {
+	source, err := NewSource(alg, seed, stream)
+	if err != nil {
+		source = rand.NewSource(seed).(rand.Source64)
+	}
+	if jumper, ok := source.(JumpSource); ok {
+		for i := 0; i < chunk; i++ {
+			jumper.Jump()
+		}
+		return rand.New(jumper)
+	}
+	if alg == RNGCrypto {
+		return rand.New(source)
+	}
+	return NewRand(alg, int64(splitMix64(uint64(seed)+uint64(chunk)*0x9e3779b97f4a7c15)), stream)
+}
+
+// streamID hashes a stream name to a 64-bit stream number
+func streamID(stream string) uint64 {
+	if stream == "" {
+		return 0
+	}
+	hasher := fnv.New64a()
+	hasher.Write([]byte(stream))
+	return hasher.Sum64()
+}
+
+// splitMix64 scrambles x, spreading nearby seeds across the whole range
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// uint128 is an unsigned 128-bit integer
+type uint128 struct {
+	hi, lo uint64
+}
+
+// add returns a + b modulo 2^128
+func (a uint128) add(b uint128) uint128 {
+	lo, carry := bits.Add64(a.lo, b.lo, 0)
+	hi, _ := bits.Add64(a.hi, b.hi, carry)
+	return uint128{hi: hi, lo: lo}
+}
+
+// mul returns a * b modulo 2^128
+func (a uint128) mul(b uint128) uint128 {
+	hi, lo := bits.Mul64(a.lo, b.lo)
+	hi += a.hi*b.lo + a.lo*b.hi
+	return uint128{hi: hi, lo: lo}
+}
+
+// pcg64Multiplier is the 128-bit LCG multiplier of PCG64
+var pcg64Multiplier = uint128{hi: 0x2360ed051fc65da4, lo: 0x4385df649fccf645}
+
+// PCG64 is the PCG-XSL-RR 128/64 generator: a 128-bit linear congruential
+// generator whose increment selects one of 2^127 streams, with a
+// permuted 64-bit output. It is not safe for concurrent use.
+type PCG64 struct {
+	state uint128
+	inc   uint128
+}
+
+// NewPCG64 creates a PCG64 generator seeded with seed on stream.
+func NewPCG64(seed, stream uint64) *PCG64 {
+	p := &PCG64{}
+	p.SeedStream(seed, stream)
+	return p
+}
+
+// SeedStream restarts the generator from seed on stream.
+func (p *PCG64) SeedStream(seed, stream uint64) {
+	p.inc = uint128{hi: stream >> 63, lo: stream<<1 | 1}
+	p.state = uint128{}
+	p.step()
+	p.state = p.state.add(uint128{lo: seed})
+	p.step()
+}
+
+// Seed restarts the generator from seed, keeping its stream.
+func (p *PCG64) Seed(seed int64) {
+	stream := p.inc.hi<<63 | p.inc.lo>>1
+	p.SeedStream(uint64(seed), stream)
+}
+
+// step advances the LCG state once
+func (p *PCG64) step() {
+	p.state = p.state.mul(pcg64Multiplier).add(p.inc)
+}
+
+// Uint64 returns the next 64 random bits.
+func (p *PCG64) Uint64() uint64 {
+	p.step()
+	return bits.RotateLeft64(p.state.hi^p.state.lo, -int(p.state.hi>>58))
+}
+
+// Int63 returns a non-negative random int64.
+func (p *PCG64) Int63() int64 {
+	return int64(p.Uint64() >> 1)
+}
+
+// Advance skips delta steps in O(log delta) time.
+func (p *PCG64) Advance(delta uint64) {
+	p.advance(uint128{lo: delta})
+}
+
+// Jump advances the generator by 2^64 steps.
+func (p *PCG64) Jump() {
+	p.advance(uint128{hi: 1})
+}
+
+// advance applies the LCG delta times using Brown's algorithm
+func (p *PCG64) advance(delta uint128) {
+	accMult, accPlus := uint128{lo: 1}, uint128{}
+	curMult, curPlus := pcg64Multiplier, p.inc
+	for delta.hi != 0 || delta.lo != 0 {
+		if delta.lo&1 != 0 {
+			accMult = accMult.mul(curMult)
+			accPlus = accPlus.mul(curMult).add(curPlus)
+		}
+		curPlus = curMult.add(uint128{lo: 1}).mul(curPlus)
+		curMult = curMult.mul(curMult)
+		delta = uint128{hi: delta.hi >> 1, lo: delta.lo>>1 | delta.hi<<63}
+	}
+	p.state = accMult.mul(p.state).add(accPlus)
+}
+
+// Xoshiro256 is the xoshiro256** generator, with jumps of 2^128 steps. It
+// is not safe for concurrent use.
+type Xoshiro256 struct {
+	s [4]uint64
+}
+
+// xoshiro256Jump is the jump polynomial advancing xoshiro256 by 2^128 steps
+var xoshiro256Jump = [4]uint64{0x180ec6d33cfd0aba, 0xd5a61266f0c9392c, 0xa9582618e03fc9aa, 0x39abdc4529b1661c}
+
+// NewXoshiro256 creates a xoshiro256** generator seeded with seed.
+func NewXoshiro256(seed uint64) *Xoshiro256 {
+	x := &Xoshiro256{}
+	x.Seed(int64(seed))
+	return x
+}
+
+// Seed restarts the generator from seed, expanding it to the full state
+// with SplitMix64.
+func (x *Xoshiro256) Seed(seed int64) {
+	state := uint64(seed)
+	for i := range x.s {
+		x.s[i] = splitMix64(state)
+		state += 0x9e3779b97f4a7c15
+	}
+}
+
+// Uint64 returns the next 64 random bits.
+func (x *Xoshiro256) Uint64() uint64 {
+	result := bits.RotateLeft64(x.s[1]*5, 7) * 9
+	t := x.s[1] << 17
+
+	x.s[2] ^= x.s[0]
+	x.s[3] ^= x.s[1]
+	x.s[1] ^= x.s[2]
+	x.s[0] ^= x.s[3]
+	x.s[2] ^= t
+	x.s[3] = bits.RotateLeft64(x.s[3], 45)
+
+	return result
+}
+
+// Int63 returns a non-negative random int64.
+func (x *Xoshiro256) Int63() int64 {
+	return int64(x.Uint64() >> 1)
+}
+
+// Jump advances the generator by 2^128 steps.
+func (x *Xoshiro256) Jump() {
+	var jumped [4]uint64
+	for _, word := range xoshiro256Jump {
+		for b := 0; b < 64; b++ {
+			if word&(1<<uint(b)) != 0 {
+				for i := range jumped {
+					jumped[i] ^= x.s[i]
+				}
+			}
+			x.Uint64()
+		}
+	}
+	x.s = jumped
+}
+
+// CryptoSource is a rand.Source64 reading the operating system's
+// cryptographically secure generator. Seeding it has no effect. It is safe
+// for concurrent use.
+type CryptoSource struct{}
+
+// Seed does nothing; a cryptographic source cannot be reproduced.
+func (CryptoSource) Seed(int64) {}
+
+// Uint64 returns 64 random bits from crypto/rand. It panics if the
+// operating system cannot supply randomness.
+func (CryptoSource) Uint64() uint64 {
+	var buf [8]byte
+	if _, err := cryptorand.Read(buf[:]); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// Int63 returns a non-negative random int64 from crypto/rand.
+func (c CryptoSource) Int63() int64 {
+	return int64(c.Uint64() >> 1)
+}
+
+// SecureToken returns n random bytes from crypto/rand, hex encoded, for
+// values such as resume tokens that must not be guessable.
+func SecureToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := cryptorand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
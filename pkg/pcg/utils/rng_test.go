@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRNGAlgorithm(t *testing.T) {
+	alg, err := ParseRNGAlgorithm("")
+	require.NoError(t, err)
+	assert.Equal(t, RNGMath, alg)
+
+	alg, err = ParseRNGAlgorithm(" PCG64 ")
+	require.NoError(t, err)
+	assert.Equal(t, RNGPCG64, alg)
+
+	_, err = ParseRNGAlgorithm("mersenne")
+	assert.Error(t, err)
+}
+
+func TestNewSource_MathMatchesLegacySeeding(t *testing.T) {
+	legacy := rand.New(rand.NewSource(42))
+	rng := NewRand(RNGMath, 42, "")
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, legacy.Int63(), rng.Int63())
+	}
+}
+
+func TestNewSource_StreamsAreIsolated(t *testing.T) {
+	for _, alg := range []RNGAlgorithm{RNGMath, RNGPCG64, RNGXoshiro256} {
+		t.Run(string(alg), func(t *testing.T) {
+			a, b := NewRand(alg, 7, "terrain"), NewRand(alg, 7, "items")
+			again := NewRand(alg, 7, "terrain")
+
+			same := 0
+			for i := 0; i < 100; i++ {
+				value := a.Uint64()
+				assert.Equal(t, value, again.Uint64(), "a stream is reproducible from its seed")
+				if value == b.Uint64() {
+					same++
+				}
+			}
+			assert.Zero(t, same, "streams sharing a seed should not correlate")
+		})
+	}
+}
+
+func TestPCG64_Advance(t *testing.T) {
+	stepped := NewPCG64(12345, 6)
+	for i := 0; i < 1000; i++ {
+		stepped.Uint64()
+	}
+
+	jumped := NewPCG64(12345, 6)
+	jumped.Advance(1000)
+	assert.Equal(t, stepped.Uint64(), jumped.Uint64())
+
+	// Two half jumps of 2^63 make a jump of 2^64
+	halves := NewPCG64(12345, 6)
+	halves.Advance(1 << 63)
+	halves.Advance(1 << 63)
+	full := NewPCG64(12345, 6)
+	full.Jump()
+	assert.Equal(t, full.Uint64(), halves.Uint64())
+}
+
+func TestXoshiro256_ReferenceOutput(t *testing.T) {
+	x := &Xoshiro256{s: [4]uint64{1, 2, 3, 4}}
+	assert.Equal(t, uint64(11520), x.Uint64())
+	assert.Equal(t, uint64(0), x.Uint64())
+	assert.Equal(t, uint64(1509978240), x.Uint64())
+}
+
+func TestNewChunkRand(t *testing.T) {
+	for _, alg := range []RNGAlgorithm{RNGMath, RNGPCG64, RNGXoshiro256} {
+		t.Run(string(alg), func(t *testing.T) {
+			first := NewChunkRand(alg, 99, "levels", 0).Uint64()
+			assert.Equal(t, first, NewChunkRand(alg, 99, "levels", 0).Uint64(), "chunks are reproducible")
+			assert.NotEqual(t, first, NewChunkRand(alg, 99, "levels", 1).Uint64(), "chunks differ")
+		})
+	}
+	assert.Equal(t, NewRand(RNGPCG64, 99, "levels").Uint64(), NewChunkRand(RNGPCG64, 99, "levels", 0).Uint64(),
+		"chunk 0 is the stream itself")
+}
+
+func TestCryptoSource(t *testing.T) {
+	rng := rand.New(CryptoSource{})
+	assert.NotEqual(t, rng.Uint64(), rng.Uint64())
+	assert.GreaterOrEqual(t, CryptoSource{}.Int63(), int64(0))
+
+	token, err := SecureToken(16)
+	require.NoError(t, err)
+	assert.Len(t, token, 32)
+}
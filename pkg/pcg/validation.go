@@ -84,13 +84,14 @@ func (v *Validator) ValidateGenerationParams(params GenerationParams) *Validatio
 func (v *Validator) ValidateTerrainParams(params TerrainParams) *ValidationResult {
 	result := v.ValidateGenerationParams(params.GenerationParams)
 
-	// Validate biome type
+	// Validate biome type; custom biomes are valid once registered
 	validBiomes := []BiomeType{
 		BiomeForest, BiomeMountain, BiomeDesert, BiomeSwamp,
 		BiomeCave, BiomeDungeon, BiomeCoastal, BiomeUrban, BiomeWasteland,
+		BiomePlains,
 	}
 
-	valid := false
+	_, valid := Biomes().Get(params.BiomeType)
 	for _, validBiome := range validBiomes {
 		if params.BiomeType == validBiome {
 			valid = true
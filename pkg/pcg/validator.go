@@ -29,9 +29,11 @@ import (
 type ContentValidator struct {
 	mu               sync.RWMutex
 	logger           *logrus.Logger
-	validationRules  map[ContentType][]ValidationRule
+	validationRules  map[ContentType][]ContentRule
 	fallbackHandlers map[ContentType]FallbackHandler
 	metrics          *ValidationMetrics
+	rulePacks        map[string]bool       // Names of the rule packs in use
+	ruleSettings     map[string]RuleConfig // Configured settings by rule name
 }
 
 // ValidationRule defines a single validation check for content
@@ -83,6 +85,15 @@ type ValidationMetrics struct {
 	fallbacksTriggered  int64
 	validationDuration  time.Duration
 	ruleExecutionCounts map[string]int64
+	ruleFailureCounts   map[string]int64
+	ruleDurations       map[string]time.Duration
+}
+
+// RuleMetrics summarizes the executions of one validation rule
+type RuleMetrics struct {
+	Executions    int64         // Times the rule ran
+	Failures      int64         // Times the rule did not pass
+	TotalDuration time.Duration // Time spent running the rule
 }
 
 // NewContentValidator creates a new content validator with default rules
@@ -94,9 +105,10 @@ func NewContentValidator(logger *logrus.Logger) *ContentValidator {
 
 	validator := &ContentValidator{
 		logger:           logger,
-		validationRules:  make(map[ContentType][]ValidationRule),
+		validationRules:  make(map[ContentType][]ContentRule),
 		fallbackHandlers: make(map[ContentType]FallbackHandler),
 		metrics:          NewValidationMetrics(),
+		rulePacks:        make(map[string]bool),
 	}
 
 	// Initialize default validation rules for each content type
@@ -110,6 +122,7 @@ func NewContentValidator(logger *logrus.Logger) *ContentValidator {
 func (cv *ContentValidator) ValidateContent(ctx context.Context, contentType ContentType, content interface{}) ([]Result, error) {
 	cv.mu.RLock()
 	rules, exists := cv.validationRules[contentType]
+	settings := cv.ruleSettings
 	cv.mu.RUnlock()
 
 	if !exists {
@@ -132,14 +145,25 @@ func (cv *ContentValidator) ValidateContent(ctx context.Context, contentType Con
 		default:
 		}
 
-		result := rule.Validator(content)
-		result.Severity = rule.Severity // Ensure severity is set from rule
+		name := rule.RuleName()
+		setting := settings[name]
+		if setting.Enabled != nil && !*setting.Enabled {
+			continue
+		}
+
+		ruleStart := time.Now()
+		result := rule.Validate(content)
+		result.Severity = rule.DefaultSeverity() // Ensure severity is set from rule
+		if setting.Severity != "" {
+			result.Severity = setting.Severity
+		}
 
-		cv.metrics.recordRuleExecution(rule.Name)
+		cv.metrics.recordRuleExecution(name)
+		cv.metrics.recordRuleOutcome(name, result.Passed, time.Since(ruleStart))
 
 		if !result.Passed {
 			cv.logger.WithFields(logrus.Fields{
-				"rule":     rule.Name,
+				"rule":     name,
 				"severity": result.Severity,
 				"message":  result.Message,
 			}).Warn("validation rule failed")
@@ -212,6 +236,11 @@ func (cv *ContentValidator) ValidateAndFix(ctx context.Context, contentType Cont
 
 // RegisterValidationRule adds a custom validation rule for a content type
 func (cv *ContentValidator) RegisterValidationRule(contentType ContentType, rule ValidationRule) {
+	cv.RegisterRule(contentType, rule)
+}
+
+// RegisterRule adds a validation rule of any implementation for a content type
+func (cv *ContentValidator) RegisterRule(contentType ContentType, rule ContentRule) {
 	cv.mu.Lock()
 	defer cv.mu.Unlock()
 
@@ -219,8 +248,8 @@ func (cv *ContentValidator) RegisterValidationRule(contentType ContentType, rule
 
 	cv.logger.WithFields(logrus.Fields{
 		"content_type": contentType,
-		"rule_name":    rule.Name,
-		"severity":     rule.Severity,
+		"rule_name":    rule.RuleName(),
+		"severity":     rule.DefaultSeverity(),
 	}).Debug("registered validation rule")
 }
 
@@ -653,6 +682,8 @@ func (cv *ContentValidator) initializeFallbackHandlers() {
 func NewValidationMetrics() *ValidationMetrics {
 	return &ValidationMetrics{
 		ruleExecutionCounts: make(map[string]int64),
+		ruleFailureCounts:   make(map[string]int64),
+		ruleDurations:       make(map[string]time.Duration),
 	}
 }
 
@@ -694,6 +725,17 @@ func (vm *ValidationMetrics) recordRuleExecution(ruleName string) {
 	vm.ruleExecutionCounts[ruleName]++
 }
 
+// recordRuleOutcome records whether a rule passed and how long it took
+func (vm *ValidationMetrics) recordRuleOutcome(ruleName string, passed bool, duration time.Duration) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if !passed {
+		vm.ruleFailureCounts[ruleName]++
+	}
+	vm.ruleDurations[ruleName] += duration
+}
+
 // recordFallback records that a fallback handler was triggered
 func (vm *ValidationMetrics) recordFallback() {
 	vm.mu.Lock()
@@ -711,6 +753,14 @@ func (vm *ValidationMetrics) getStats() ValidationMetrics {
 	for k, v := range vm.ruleExecutionCounts {
 		ruleCounts[k] = v
 	}
+	failureCounts := make(map[string]int64, len(vm.ruleFailureCounts))
+	for k, v := range vm.ruleFailureCounts {
+		failureCounts[k] = v
+	}
+	durations := make(map[string]time.Duration, len(vm.ruleDurations))
+	for k, v := range vm.ruleDurations {
+		durations[k] = v
+	}
 
 	return ValidationMetrics{
 		totalValidations:    vm.totalValidations,
@@ -720,6 +770,8 @@ func (vm *ValidationMetrics) getStats() ValidationMetrics {
 		fallbacksTriggered:  vm.fallbacksTriggered,
 		validationDuration:  vm.validationDuration,
 		ruleExecutionCounts: ruleCounts,
+		ruleFailureCounts:   failureCounts,
+		ruleDurations:       durations,
 	}
 }
 
@@ -735,6 +787,8 @@ func (vm *ValidationMetrics) reset() {
 	vm.fallbacksTriggered = 0
 	vm.validationDuration = 0
 	vm.ruleExecutionCounts = make(map[string]int64)
+	vm.ruleFailureCounts = make(map[string]int64)
+	vm.ruleDurations = make(map[string]time.Duration)
 }
 
 // GetSuccessRate returns the percentage of validations that passed
@@ -773,6 +827,23 @@ func (vm *ValidationMetrics) GetCriticalFailureRate() float64 {
 	return float64(vm.criticalFailures) / float64(vm.totalValidations) * 100.0
 }
 
+// GetRuleMetrics returns the executions, failures and time spent of every
+// rule that has run, by rule name
+func (vm *ValidationMetrics) GetRuleMetrics() map[string]RuleMetrics {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	metrics := make(map[string]RuleMetrics, len(vm.ruleExecutionCounts))
+	for name, executions := range vm.ruleExecutionCounts {
+		metrics[name] = RuleMetrics{
+			Executions:    executions,
+			Failures:      vm.ruleFailureCounts[name],
+			TotalDuration: vm.ruleDurations[name],
+		}
+	}
+	return metrics
+}
+
 // GetTotalValidations returns the total number of validations performed
 func (vm *ValidationMetrics) GetTotalValidations() int64 {
 	vm.mu.RLock()
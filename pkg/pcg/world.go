@@ -64,6 +64,7 @@ type Settlement struct {
 	Connections []string               `json:"connections"`
 	RegionID    string                 `json:"region_id"`
 	Properties  map[string]interface{} `json:"properties"`
+	Temples     []SettlementTemple     `json:"temples,omitempty"`
 }
 
 // TravelPath represents roads, rivers, and other travel routes
@@ -267,7 +268,7 @@ func (wg *WorldGenerator) Generate(ctx context.Context, params GenerationParams)
 	}
 
 	// Initialize RNG with provided seed for deterministic generation
-	wg.rng = rand.New(rand.NewSource(params.Seed))
+	wg.rng = NewRNG(params.Seed, "world")
 
 	wg.logger.WithFields(logrus.Fields{
 		"world_width":  worldParams.WorldWidth,
@@ -321,7 +322,12 @@ func (wg *WorldGenerator) generateWorld(ctx context.Context, params GenerationPa
 		return nil, fmt.Errorf("travel network generation failed: %w", err)
 	}
 
-	// Step 5: Add metadata for debugging and validation
+	// Step 5: Dedicate temples to the deities of the pantheon, if given
+	if pantheon, ok := params.Constraints["pantheon"].(*game.Pantheon); ok {
+		PlaceTemples(world, pantheon, params.Seed)
+	}
+
+	// Step 6: Add metadata for debugging and validation
 	world.Metadata["total_population"] = wg.calculateTotalPopulation(world)
 	world.Metadata["trade_route_count"] = len(world.TravelPaths)
 	world.Metadata["generation_seed"] = params.Seed
@@ -0,0 +1,365 @@
+package persistence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// BackupPrefix is the key prefix under which BackupStore keeps its copies.
+// Keys under this prefix are never backed up themselves.
+const BackupPrefix = "backups/"
+
+// backupTimeFormat names backups so lexical order is chronological order.
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// ErrBackupCorrupt is returned when a backup's contents do not match the
+// checksum recorded when it was taken.
+var ErrBackupCorrupt = errors.New("backup is corrupt")
+
+// BackupPolicy controls which keys BackupStore backs up and how long the
+// copies are retained.
+type BackupPolicy struct {
+	// MaxCount is the number of backups kept per key; older ones are
+	// rotated out. Zero keeps every backup.
+	MaxCount int
+
+	// MaxAge removes backups older than this, except the newest backup of
+	// each key. Zero disables age-based retention.
+	MaxAge time.Duration
+
+	// MinInterval skips taking a new backup of a key if its newest backup
+	// is younger than this, so frequent auto-saves do not rotate the whole
+	// history out within minutes. Zero backs up before every overwrite.
+	MinInterval time.Duration
+
+	// Keys are glob patterns (see path/filepath.Match) selecting the keys to
+	// back up. Empty backs up every key.
+	Keys []string
+}
+
+// BackupInfo describes one stored backup.
+type BackupInfo struct {
+	ID        string    `json:"id"`         // Store key of the backup, used to restore it
+	Key       string    `json:"key"`        // Key the backup is a copy of
+	CreatedAt time.Time `json:"created_at"` // When the copy was taken
+	Checksum  string    `json:"checksum"`   // SHA-256 of the copied YAML
+	Size      int       `json:"size"`       // Size of the copied YAML in bytes
+}
+
+// backupRecord is the stored form of a backup. Data holds the original
+// value's YAML so the checksum covers exactly the bytes that are restored.
+type backupRecord struct {
+	Key       string    `yaml:"key"`
+	CreatedAt time.Time `yaml:"created_at"`
+	Checksum  string    `yaml:"checksum"`
+	Data      string    `yaml:"data"`
+}
+
+// BackupStore wraps a Store and copies a key's current value aside before
+// every overwrite, keeping a rotated, checksummed history that can be
+// verified and restored. Backups are stored in the wrapped store under
+// BackupPrefix, so they work with every backend.
+//
+// BackupStore is safe for concurrent use if the wrapped store is.
+type BackupStore struct {
+	Store
+	policy BackupPolicy
+	now    func() time.Time
+	mu     sync.Mutex // Serializes backup rotation
+}
+
+var _ Store = (*BackupStore)(nil)
+
+// NewBackupStore wraps store with automatic backups governed by policy.
+//
+// Parameters:
+//   - store: The store holding both the live values and their backups
+//   - policy: Retention and selection settings
+//
+// Returns:
+//   - *BackupStore: The wrapping store
+func NewBackupStore(store Store, policy BackupPolicy) *BackupStore {
+	return &BackupStore{
+		Store:  store,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Save backs up the value currently stored under key, then replaces it.
+func (bs *BackupStore) Save(key string, data interface{}) error {
+	if err := bs.backup(key, false); err != nil {
+		return err
+	}
+	return bs.Store.Save(key, data)
+}
+
+// SaveBatch backs up the current value of every key in the batch, then
+// applies the batch. Backups taken for a batch that then fails are kept.
+func (bs *BackupStore) SaveBatch(entries map[string]interface{}) error {
+	for _, key := range sortedKeys(entries) {
+		if err := bs.backup(key, false); err != nil {
+			return err
+		}
+	}
+	return bs.Store.SaveBatch(entries)
+}
+
+// Backups lists stored backups, oldest first.
+//
+// Parameters:
+//   - key: Only list backups of this key; empty lists backups of every key
+//
+// Returns:
+//   - []BackupInfo: The matching backups
+//   - error: Any error listing or reading the backups
+func (bs *BackupStore) Backups(key string) ([]BackupInfo, error) {
+	pattern := BackupPrefix + "*/*.yaml"
+	if key != "" {
+		pattern = backupDir(key) + "/*.yaml"
+	}
+
+	ids, err := bs.Store.List(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	backups := make([]BackupInfo, 0, len(ids))
+	for _, id := range ids {
+		var record backupRecord
+		if err := bs.Store.Load(id, &record); err != nil {
+			return nil, fmt.Errorf("failed to read backup %s: %w", id, err)
+		}
+		backups = append(backups, BackupInfo{
+			ID:        filepathToKey(id),
+			Key:       record.Key,
+			CreatedAt: record.CreatedAt,
+			Checksum:  record.Checksum,
+			Size:      len(record.Data),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			return backups[i].CreatedAt.Before(backups[j].CreatedAt)
+		}
+		return backups[i].ID < backups[j].ID
+	})
+	return backups, nil
+}
+
+// Restore verifies a backup and writes its contents back to the key it was
+// taken from. The value being replaced is itself backed up first, so a
+// restore can be undone.
+//
+// Parameters:
+//   - id: The backup ID as returned by Backups
+//
+// Returns:
+//   - string: The key that was restored
+//   - error: An unknown or corrupt backup, or any error writing the key
+func (bs *BackupStore) Restore(id string) (string, error) {
+	record, err := bs.lookup(id)
+	if err != nil {
+		return "", err
+	}
+
+	var value yaml.Node
+	if err := yaml.Unmarshal([]byte(record.Data), &value); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrBackupCorrupt, id, err)
+	}
+	if err := bs.backup(record.Key, true); err != nil {
+		return "", err
+	}
+	if err := bs.Store.Save(record.Key, &value); err != nil {
+		return "", fmt.Errorf("failed to restore %s: %w", record.Key, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "Restore",
+		"backup":   id,
+		"key":      record.Key,
+	}).Info("backup restored")
+
+	return record.Key, nil
+}
+
+// Open verifies a backup and decodes its contents into data without
+// restoring it, so callers can check a backup is usable before it replaces
+// the live value.
+//
+// Parameters:
+//   - id: The backup ID as returned by Backups
+//   - data: Pointer to the value to decode the backup into
+//
+// Returns:
+//   - string: The key the backup was taken from
+//   - error: An unknown or corrupt backup, or contents data cannot hold
+func (bs *BackupStore) Open(id string, data interface{}) (string, error) {
+	record, err := bs.lookup(id)
+	if err != nil {
+		return "", err
+	}
+	if err := yaml.Unmarshal([]byte(record.Data), data); err != nil {
+		return "", fmt.Errorf("failed to decode backup %s: %w", id, err)
+	}
+	return record.Key, nil
+}
+
+// Verify checks every stored backup against its recorded checksum.
+//
+// Returns:
+//   - int: The number of backups checked
+//   - error: Nil if every backup is intact, otherwise the joined errors of
+//     each corrupt or unreadable backup (matching ErrBackupCorrupt)
+func (bs *BackupStore) Verify() (int, error) {
+	ids, err := bs.Store.List(BackupPrefix + "*/*.yaml")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if _, err := bs.readVerified(filepathToKey(id)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(ids), errors.Join(errs...)
+}
+
+// lookup checks id names a stored backup and returns its verified record.
+func (bs *BackupStore) lookup(id string) (*backupRecord, error) {
+	if !strings.HasPrefix(id, BackupPrefix) || path.Clean(id) != id || !bs.Store.Exists(id) {
+		return nil, fmt.Errorf("backup does not exist: %s", id)
+	}
+	return bs.readVerified(id)
+}
+
+// readVerified loads a backup record and checks its checksum.
+func (bs *BackupStore) readVerified(id string) (*backupRecord, error) {
+	var record backupRecord
+	if err := bs.Store.Load(id, &record); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrBackupCorrupt, id, err)
+	}
+	if record.Key == "" || checksum(record.Data) != record.Checksum {
+		return nil, fmt.Errorf("%w: %s: checksum mismatch", ErrBackupCorrupt, id)
+	}
+	return &record, nil
+}
+
+// backup copies the current value of key aside if the policy selects it,
+// then applies retention to that key's backups. force ignores
+// MinInterval.
+func (bs *BackupStore) backup(key string, force bool) error {
+	if !bs.selects(key) || !bs.Store.Exists(key) {
+		return nil
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	existing, err := bs.Backups(key)
+	if err != nil {
+		return err
+	}
+	now := bs.now().UTC()
+	if n := len(existing); !force && n > 0 && bs.policy.MinInterval > 0 &&
+		now.Sub(existing[n-1].CreatedAt) < bs.policy.MinInterval {
+		return nil
+	}
+
+	var value yaml.Node
+	if err := bs.Store.Load(key, &value); err != nil {
+		return fmt.Errorf("failed to read %s for backup: %w", key, err)
+	}
+	data, err := yaml.Marshal(&value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s for backup: %w", key, err)
+	}
+
+	id := backupDir(key) + "/" + now.Format(backupTimeFormat) + ".yaml"
+	record := backupRecord{
+		Key:       key,
+		CreatedAt: now,
+		Checksum:  checksum(string(data)),
+		Data:      string(data),
+	}
+	if err := bs.Store.Save(id, &record); err != nil {
+		return fmt.Errorf("failed to save backup of %s: %w", key, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "backup",
+		"key":      key,
+		"backup":   id,
+	}).Debug("backup taken")
+
+	existing = append(existing, BackupInfo{ID: id, Key: key, CreatedAt: now})
+	return bs.prune(existing, now)
+}
+
+// prune deletes the backups of one key that fall outside the retention
+// policy. backups must be ordered oldest first.
+func (bs *BackupStore) prune(backups []BackupInfo, now time.Time) error {
+	excess := 0
+	if bs.policy.MaxCount > 0 && len(backups) > bs.policy.MaxCount {
+		excess = len(backups) - bs.policy.MaxCount
+	}
+
+	// The newest backup is always kept
+	for i, backup := range backups[:len(backups)-1] {
+		expired := bs.policy.MaxAge > 0 && now.Sub(backup.CreatedAt) > bs.policy.MaxAge
+		if i >= excess && !expired {
+			continue
+		}
+		if err := bs.Store.Delete(backup.ID); err != nil {
+			return fmt.Errorf("failed to remove old backup %s: %w", backup.ID, err)
+		}
+	}
+	return nil
+}
+
+// selects reports whether the policy backs up key.
+func (bs *BackupStore) selects(key string) bool {
+	if strings.HasPrefix(key, BackupPrefix) {
+		return false
+	}
+	if len(bs.policy.Keys) == 0 {
+		return true
+	}
+	for _, pattern := range bs.policy.Keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// backupDir returns the key prefix holding the backups of key. Slashes in
+// key are escaped so every key's backups sit one level below BackupPrefix.
+func backupDir(key string) string {
+	return BackupPrefix + url.PathEscape(key)
+}
+
+// filepathToKey normalizes a key returned by List, which uses the OS path
+// separator for the file backend, to the slash-separated form.
+func filepathToKey(key string) string {
+	return strings.ReplaceAll(key, "\\", "/")
+}
+
+// checksum returns the hex SHA-256 of data.
+func checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+package persistence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type backupTestState struct {
+	Version int    `yaml:"version"`
+	Name    string `yaml:"name"`
+}
+
+// newBackupTestStore returns a BackupStore whose clock advances one minute
+// per call, so every backup gets a distinct timestamp.
+func newBackupTestStore(store Store, policy BackupPolicy) *BackupStore {
+	bs := NewBackupStore(store, policy)
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bs.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	return bs
+}
+
+func TestBackupStore_RotatesAndRestores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"file": func(t *testing.T) Store {
+			fs, err := NewFileStore(t.TempDir())
+			require.NoError(t, err)
+			return fs
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			bs := newBackupTestStore(open(t), BackupPolicy{MaxCount: 2})
+
+			for version := 1; version <= 4; version++ {
+				require.NoError(t, bs.Save("characters/hero.yaml", backupTestState{Version: version, Name: "Hero"}))
+			}
+
+			backups, err := bs.Backups("characters/hero.yaml")
+			require.NoError(t, err)
+			require.Len(t, backups, 2, "only the newest MaxCount backups are kept")
+			assert.Equal(t, "characters/hero.yaml", backups[0].Key)
+			assert.True(t, backups[0].CreatedAt.Before(backups[1].CreatedAt))
+
+			// The oldest kept backup holds version 2, and opening it leaves
+			// the live value alone
+			var opened backupTestState
+			key, err := bs.Open(backups[0].ID, &opened)
+			require.NoError(t, err)
+			assert.Equal(t, "characters/hero.yaml", key)
+			assert.Equal(t, 2, opened.Version)
+			var current backupTestState
+			require.NoError(t, bs.Load("characters/hero.yaml", &current))
+			assert.Equal(t, 4, current.Version)
+
+			key, err = bs.Restore(backups[0].ID)
+			require.NoError(t, err)
+			assert.Equal(t, "characters/hero.yaml", key)
+
+			var restored backupTestState
+			require.NoError(t, bs.Load("characters/hero.yaml", &restored))
+			assert.Equal(t, 2, restored.Version)
+
+			// The overwritten version 4 was backed up before the restore
+			backups, err = bs.Backups("")
+			require.NoError(t, err)
+			require.Len(t, backups, 2)
+			_, err = bs.Restore(backups[1].ID)
+			require.NoError(t, err)
+			require.NoError(t, bs.Load("characters/hero.yaml", &restored))
+			assert.Equal(t, 4, restored.Version)
+
+			checked, err := bs.Verify()
+			assert.NoError(t, err)
+			assert.Equal(t, 2, checked)
+		})
+	}
+}
+
+func TestBackupStore_RetentionPolicy(t *testing.T) {
+	t.Run("max age keeps the newest backup", func(t *testing.T) {
+		bs := newBackupTestStore(NewMemoryStore(), BackupPolicy{MaxAge: 90 * time.Second})
+		for version := 1; version <= 5; version++ {
+			require.NoError(t, bs.Save("gamestate.yaml", backupTestState{Version: version}))
+		}
+
+		backups, err := bs.Backups("gamestate.yaml")
+		require.NoError(t, err)
+		assert.Len(t, backups, 2, "backups older than MaxAge are removed")
+	})
+
+	t.Run("min interval skips frequent backups", func(t *testing.T) {
+		bs := newBackupTestStore(NewMemoryStore(), BackupPolicy{MinInterval: 150 * time.Second})
+		for version := 1; version <= 6; version++ {
+			require.NoError(t, bs.SaveBatch(map[string]interface{}{
+				"gamestate.yaml": backupTestState{Version: version},
+			}))
+		}
+
+		backups, err := bs.Backups("gamestate.yaml")
+		require.NoError(t, err)
+		assert.Len(t, backups, 2)
+	})
+
+	t.Run("key patterns select what is backed up", func(t *testing.T) {
+		bs := newBackupTestStore(NewMemoryStore(), BackupPolicy{Keys: []string{"characters/*"}})
+		for version := 1; version <= 2; version++ {
+			require.NoError(t, bs.Save("characters/hero.yaml", backupTestState{Version: version}))
+			require.NoError(t, bs.Save("pcg_state.yaml", backupTestState{Version: version}))
+		}
+
+		backups, err := bs.Backups("")
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		assert.Equal(t, "characters/hero.yaml", backups[0].Key)
+	})
+}
+
+func TestBackupStore_DetectsCorruption(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := NewFileStore(dir)
+	require.NoError(t, err)
+	bs := newBackupTestStore(fs, BackupPolicy{})
+
+	require.NoError(t, bs.Save("gamestate.yaml", backupTestState{Version: 1, Name: "original"}))
+	require.NoError(t, bs.Save("gamestate.yaml", backupTestState{Version: 2, Name: "original"}))
+
+	backups, err := bs.Backups("gamestate.yaml")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	// Tamper with the copied data while leaving the YAML well formed
+	backupPath := filepath.Join(dir, filepath.FromSlash(backups[0].ID))
+	contents, err := os.ReadFile(backupPath)
+	require.NoError(t, err)
+	tampered := []byte(string(contents[:len(contents)-1]) + "x\n")
+	require.NoError(t, os.WriteFile(backupPath, tampered, 0o644))
+
+	checked, err := bs.Verify()
+	assert.Equal(t, 1, checked)
+	assert.True(t, errors.Is(err, ErrBackupCorrupt))
+
+	_, err = bs.Restore(backups[0].ID)
+	assert.True(t, errors.Is(err, ErrBackupCorrupt))
+	_, err = bs.Open(backups[0].ID, &backupTestState{})
+	assert.True(t, errors.Is(err, ErrBackupCorrupt))
+
+	var current backupTestState
+	require.NoError(t, bs.Load("gamestate.yaml", &current))
+	assert.Equal(t, 2, current.Version, "a corrupt backup is never restored")
+
+	_, err = bs.Restore("gamestate.yaml")
+	assert.Error(t, err, "only backup IDs can be restored")
+	_, err = bs.Restore(BackupPrefix + "../gamestate.yaml")
+	assert.Error(t, err)
+}
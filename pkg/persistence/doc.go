@@ -1,12 +1,32 @@
-// Package persistence provides file-based data persistence for the GoldBox RPG Engine.
+// Package persistence provides data persistence for the GoldBox RPG Engine.
 //
 // This package handles game state storage with atomic writes, file locking, and
 // YAML serialization to ensure data integrity and protection against corruption
 // from concurrent access or crashes.
 //
+// # Store Backends
+//
+// Store is the interface implemented by every backend. OpenStore selects one:
+//
+//	store, err := persistence.OpenStore(persistence.StoreOptions{
+//	    Backend: persistence.BackendSQLite,
+//	    DataDir: "/path/to/data",
+//	})
+//
+//	// Save several documents all-or-nothing
+//	err = store.SaveBatch(map[string]interface{}{
+//	    "gamestate.yaml": gameState,
+//	    "pcg_state.yaml": seedState,
+//	})
+//
+// FileStore (BackendFile) is the default. SQLiteStore (BackendSQLite) keeps
+// documents in one database with versioned schema migrations and is safe to
+// share between processes; it uses database/sql, so the program must link a
+// SQLite driver. MemoryStore (BackendMemory) is intended for tests.
+//
 // # FileStore
 //
-// FileStore is the primary interface for persisting game data:
+// FileStore persists each document as a YAML file:
 //
 //	store := persistence.NewFileStore("/path/to/data")
 //
@@ -30,7 +50,8 @@
 //
 // # File Locking
 //
-// FileLock provides cross-process synchronization using flock syscalls:
+// FileLock provides cross-process synchronization using the platform's
+// native file locks:
 //
 //	lock := persistence.NewFileLock("/path/to/lockfile")
 //
@@ -46,6 +67,98 @@
 //	    return errors.New("resource busy")
 //	}
 //
+// # Backups
+//
+// BackupStore wraps any Store and keeps rotated, checksummed copies of each
+// document, taken just before it is overwritten:
+//
+//	store := persistence.NewBackupStore(fileStore, persistence.BackupPolicy{
+//	    MaxCount: 5,
+//	    MaxAge:   7 * 24 * time.Hour,
+//	})
+//
+//	backups, err := store.Backups("gamestate.yaml")
+//	key, err := store.Restore(backups[0].ID)
+//	checked, err := store.Verify()
+//
+// # Snapshots
+//
+// SnapshotManager captures several documents together as one timestamped,
+// checksummed snapshot and restores them in a single batch:
+//
+//	snapshots := persistence.NewSnapshotManager(store, persistence.SnapshotPolicy{
+//	    MaxCount:       24,
+//	    MinInterval:    15 * time.Minute,
+//	    CompactAfter:   6 * time.Hour,
+//	    CompactSpacing: time.Hour,
+//	})
+//
+//	info, err := snapshots.AutoSnapshot([]string{"gamestate.yaml", "pcg_state.yaml"})
+//	keys, err := snapshots.Restore(info.ID)
+//	removed, err := snapshots.Compact()
+//
+// # Schema Versioning
+//
+// VersionedStore wraps any Store and embeds a schema_version field in each
+// document it saves. Migrations registered with MigrateFrom upgrade older
+// documents one version at a time when they are loaded; the upgraded
+// document is saved back and the original kept under MigrationBackupPrefix:
+//
+//	persistence.DefaultMigrations.MigrateFrom(1, func(key string, doc map[string]interface{}) error {
+//	    if key == "gamestate.yaml" {
+//	        doc["state_version"] = doc["version"]
+//	        delete(doc, "version")
+//	    }
+//	    return nil
+//	})
+//
+//	store := persistence.NewVersionedStore(backupStore, persistence.DefaultMigrations)
+//
+// Documents saved before versioning carry no schema_version and are treated
+// as BaseSchemaVersion. Loading a document saved with a newer version than
+// the registry knows fails with ErrSchemaTooNew.
+//
+// # Event Log
+//
+// EventLog is an append-only write-ahead log for the changes made between
+// full saves. Each record is synced before Append returns. Save the log's
+// position with each full save, then drop the records it covers:
+//
+//	events, err := persistence.OpenEventLog("/path/to/data/events.log")
+//	seq, err := events.Append("session_saved", data)
+//
+//	sequence := events.LastSequence()
+//	// ... save the state together with sequence ...
+//	err = events.Compact(sequence)
+//
+//	// On startup, after loading the state saved with sequence
+//	replayed, err := events.Replay(sequence, func(record persistence.EventRecord) error {
+//	    return apply(record.Type, record.Data)
+//	})
+//
+// A final record cut short by a crash is dropped when the log is opened.
+//
+// # Sealed Files
+//
+// A Sealer signs each file FileStore writes with HMAC-SHA256 and, with
+// Encrypt set, encrypts it with AES-256-GCM. Keys are derived from a
+// secret; files sealed with one of the PreviousSecrets still load, and
+// Reseal rewrites them with the current secret:
+//
+//	sealer, err := persistence.NewSealer(persistence.SealOptions{
+//	    Secret:          os.Getenv("PERSISTENCE_SECRET"),
+//	    PreviousSecrets: []string{retiredSecret},
+//	    Encrypt:         true,
+//	})
+//	store, err := persistence.OpenStore(persistence.StoreOptions{DataDir: dir, Sealer: sealer})
+//	resealed, err := store.(*persistence.FileStore).Reseal()
+//
+// Loads verify and decrypt transparently. A file that was modified, sealed
+// with an unknown key or, unless AllowUnsealed is set, not sealed at all
+// fails with ErrTampered, which wraps resilience.ErrIntegrityViolation;
+// verification runs through the file system circuit breaker, whose
+// statistics count these violations.
+//
 // # File Operations
 //
 // Additional file management methods:
@@ -78,6 +191,14 @@
 //
 // # Platform Support
 //
-// File locking uses Unix flock syscalls. The package includes build tags
-// for platform-specific implementations.
+// File locking is selected with build tags:
+//
+//   - Unix (Linux, macOS, BSD): flock syscalls
+//   - Windows: LockFileEx via golang.org/x/sys/windows
+//   - Other platforms: lock files created with O_EXCL; a lock file older
+//     than one minute is assumed to belong to a crashed process and is
+//     reclaimed
+//
+// All three provide the same exclusive, cross-process guarantees to
+// FileStore.
 package persistence
@@ -0,0 +1,316 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrEventLogClosed is returned when using an EventLog after Close.
+var ErrEventLogClosed = errors.New("event log is closed")
+
+// EventRecord is one entry of an EventLog. The log does not interpret Data;
+// its writer chooses the encoding and Type tells readers how to decode it.
+type EventRecord struct {
+	Sequence  uint64    `json:"seq"`  // Position in the log, starting at 1
+	Timestamp time.Time `json:"time"` // When the record was appended
+	Type      string    `json:"type"` // Kind of change recorded
+	Data      []byte    `json:"data"` // Encoded change
+	Checksum  uint32    `json:"crc"`  // CRC-32 of Data
+}
+
+// EventLog is an append-only write-ahead log of game state changes, kept in
+// a single file with one JSON record per line. Each record is synced to disk
+// before Append returns, so changes made between full saves survive a crash.
+//
+// Pair the log with periodic full saves: save the sequence number returned
+// by LastSequence together with the state, then Compact the records that
+// state covers. On startup, load the state and Replay the records after its
+// sequence number.
+//
+// A record cut short by a crash is dropped when the log is opened.
+//
+// EventLog is safe for concurrent use within a single process.
+type EventLog struct {
+	path string
+	file *os.File
+	last uint64
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+// OpenEventLog opens the event log at path, creating it and its directory
+// if needed. A damaged final record, left by a crash during Append, is
+// truncated away; damage anywhere else is an error.
+//
+// Parameters:
+//   - path: The log file
+//
+// Returns:
+//   - *EventLog: The opened log, positioned after its last record
+//   - error: Failure to create, read or repair the file
+func OpenEventLog(path string) (*EventLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+
+	el := &EventLog{path: path, file: file, now: time.Now}
+	valid, last, err := el.scan(func(EventRecord) error { return nil })
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := el.truncate(valid); err != nil {
+		file.Close()
+		return nil, err
+	}
+	el.last = last
+
+	logrus.WithFields(logrus.Fields{
+		"function": "OpenEventLog",
+		"path":     path,
+		"sequence": el.last,
+	}).Info("event log opened")
+
+	return el, nil
+}
+
+// Append writes a record to the end of the log and syncs it to disk.
+//
+// Parameters:
+//   - eventType: The kind of change, stored as the record's Type
+//   - data: The encoded change
+//
+// Returns:
+//   - uint64: The record's sequence number
+//   - error: ErrEventLogClosed, or failure to write or sync the record
+func (el *EventLog) Append(eventType string, data []byte) (uint64, error) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if el.file == nil {
+		return 0, ErrEventLogClosed
+	}
+
+	record := EventRecord{
+		Sequence:  el.last + 1,
+		Timestamp: el.now().UTC(),
+		Type:      eventType,
+		Data:      data,
+		Checksum:  crc32.ChecksumIEEE(data),
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event record: %w", err)
+	}
+	if _, err := el.file.Write(append(line, '\n')); err != nil {
+		return 0, fmt.Errorf("failed to append event record: %w", err)
+	}
+	if err := el.file.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to sync event log: %w", err)
+	}
+
+	el.last = record.Sequence
+	return record.Sequence, nil
+}
+
+// LastSequence returns the sequence number of the newest record appended,
+// or of the newest record covered by a saved state passed to Replay.
+func (el *EventLog) LastSequence() uint64 {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	return el.last
+}
+
+// Replay calls fn with every record after sequence after, oldest first.
+// after is the sequence number saved with the state being restored;
+// records appended later continue after it even if the log holds fewer
+// records, as it does once compacted.
+//
+// Parameters:
+//   - after: The last sequence number the restored state covers
+//   - fn: Called for each record; an error stops the replay. fn must not
+//     use the log.
+//
+// Returns:
+//   - int: The number of records replayed
+//   - error: Failure to read the log, or the error returned by fn
+func (el *EventLog) Replay(after uint64, fn func(EventRecord) error) (int, error) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if el.file == nil {
+		return 0, ErrEventLogClosed
+	}
+
+	replayed := 0
+	if _, _, err := el.scan(func(record EventRecord) error {
+		if record.Sequence <= after {
+			return nil
+		}
+		replayed++
+		return fn(record)
+	}); err != nil {
+		return replayed, err
+	}
+
+	el.last = max(el.last, after)
+	return replayed, nil
+}
+
+// Compact drops the records up to and including sequence through, once
+// the state they lead to has been saved. The remaining records are written
+// to a new file that atomically replaces the log.
+//
+// Parameters:
+//   - through: The last sequence number covered by the saved state
+//
+// Returns:
+//   - error: Failure to read or rewrite the log
+func (el *EventLog) Compact(through uint64) error {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if el.file == nil {
+		return ErrEventLogClosed
+	}
+
+	var kept bytes.Buffer
+	dropped := 0
+	if _, _, err := el.scan(func(record EventRecord) error {
+		if record.Sequence <= through {
+			dropped++
+			return nil
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode event record: %w", err)
+		}
+		kept.Write(append(line, '\n'))
+		return nil
+	}); err != nil {
+		return err
+	}
+	if dropped == 0 {
+		return nil
+	}
+
+	if err := AtomicWriteFile(el.path, kept.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to compact event log: %w", err)
+	}
+	file, err := os.OpenFile(el.path, os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen event log: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to reopen event log: %w", err)
+	}
+	el.file.Close()
+	el.file = file
+
+	logrus.WithFields(logrus.Fields{
+		"function": "Compact",
+		"path":     el.path,
+		"through":  through,
+		"dropped":  dropped,
+	}).Debug("event log compacted")
+
+	return nil
+}
+
+// Close closes the log file. Later calls other than LastSequence fail with
+// ErrEventLogClosed.
+func (el *EventLog) Close() error {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if el.file == nil {
+		return nil
+	}
+	err := el.file.Close()
+	el.file = nil
+	return err
+}
+
+// scan reads the log from the start and calls fn for each intact record.
+// It returns the length of the intact prefix of the file and the newest
+// sequence number in it, leaving the file positioned at its end.
+func (el *EventLog) scan(fn func(EventRecord) error) (int64, uint64, error) {
+	if _, err := el.file.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, fmt.Errorf("failed to read event log: %w", err)
+	}
+	defer el.file.Seek(0, io.SeekEnd)
+
+	reader := bufio.NewReader(el.file)
+	var valid int64
+	var last uint64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A final line without a newline was cut short mid-write
+			return valid, last, nil
+		}
+		if err != nil {
+			return valid, last, fmt.Errorf("failed to read event log: %w", err)
+		}
+
+		var record EventRecord
+		if decodeErr := json.Unmarshal(line, &record); decodeErr != nil || crc32.ChecksumIEEE(record.Data) != record.Checksum {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				return valid, last, nil
+			}
+			return valid, last, fmt.Errorf("event log is corrupt after sequence %d", last)
+		}
+		if record.Sequence <= last {
+			return valid, last, fmt.Errorf("event log sequence %d follows %d", record.Sequence, last)
+		}
+
+		if err := fn(record); err != nil {
+			return valid, last, err
+		}
+		last = record.Sequence
+		valid += int64(len(line))
+	}
+}
+
+// truncate cuts the log file to size, dropping a damaged final record.
+func (el *EventLog) truncate(size int64) error {
+	info, err := el.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read event log: %w", err)
+	}
+	if info.Size() == size {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "truncate",
+		"path":     el.path,
+		"size":     info.Size(),
+		"valid":    size,
+	}).Warn("dropping incomplete final event log record")
+
+	if err := el.file.Truncate(size); err != nil {
+		return fmt.Errorf("failed to repair event log: %w", err)
+	}
+	if _, err := el.file.Seek(size, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to repair event log: %w", err)
+	}
+	return el.file.Sync()
+}
@@ -0,0 +1,119 @@
+package persistence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayAll returns the data of every record after sequence after.
+func replayAll(t *testing.T, el *EventLog, after uint64) []string {
+	t.Helper()
+	var data []string
+	_, err := el.Replay(after, func(record EventRecord) error {
+		data = append(data, string(record.Data))
+		return nil
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func TestEventLog_AppendReplayAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	el, err := OpenEventLog(path)
+	require.NoError(t, err)
+
+	for _, data := range []string{"a", "b", "c"} {
+		_, err := el.Append("test", []byte(data))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, uint64(3), el.LastSequence())
+	assert.Equal(t, []string{"b", "c"}, replayAll(t, el, 1))
+
+	stop := errors.New("stop")
+	replayed, err := el.Replay(0, func(EventRecord) error { return stop })
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, replayed)
+	require.NoError(t, el.Close())
+	_, err = el.Append("test", []byte("d"))
+	assert.ErrorIs(t, err, ErrEventLogClosed)
+
+	el, err = OpenEventLog(path)
+	require.NoError(t, err)
+	defer el.Close()
+	assert.Equal(t, uint64(3), el.LastSequence())
+	seq, err := el.Append("test", []byte("d"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), seq)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, replayAll(t, el, 0))
+}
+
+func TestEventLog_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	el, err := OpenEventLog(path)
+	require.NoError(t, err)
+
+	for _, data := range []string{"a", "b", "c"} {
+		_, err := el.Append("test", []byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, el.Compact(2))
+	assert.Equal(t, []string{"c"}, replayAll(t, el, 0))
+
+	_, err = el.Append("test", []byte("d"))
+	require.NoError(t, err)
+	require.NoError(t, el.Compact(4))
+	assert.Empty(t, replayAll(t, el, 0))
+	require.NoError(t, el.Close())
+
+	// An emptied log continues after the sequence the saved state covers
+	el, err = OpenEventLog(path)
+	require.NoError(t, err)
+	defer el.Close()
+	assert.Zero(t, el.LastSequence())
+	assert.Empty(t, replayAll(t, el, 4))
+	seq, err := el.Append("test", []byte("e"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), seq)
+}
+
+func TestEventLog_DropsTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	el, err := OpenEventLog(path)
+	require.NoError(t, err)
+	for _, data := range []string{"a", "b"} {
+		_, err := el.Append("test", []byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, el.Close())
+
+	intact, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for name, tail := range map[string]string{
+		"cut short":    `{"seq":3,"time":"2024-01-01T00:00:00Z","ty`,
+		"bad checksum": `{"seq":3,"time":"2024-01-01T00:00:00Z","type":"test","data":"Yw==","crc":1}` + "\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, append(append([]byte{}, intact...), tail...), 0o644))
+
+			el, err := OpenEventLog(path)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(2), el.LastSequence())
+			assert.Equal(t, []string{"a", "b"}, replayAll(t, el, 0))
+			require.NoError(t, el.Close())
+
+			repaired, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, intact, repaired)
+		})
+	}
+
+	// Damage before the last record is not a torn write
+	require.NoError(t, os.WriteFile(path, append([]byte("garbage\n"), intact...), 0o644))
+	_, err = OpenEventLog(path)
+	assert.Error(t, err)
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"sync"
 
+	"goldbox-rpg/pkg/tracing"
+
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
@@ -13,10 +15,16 @@ import (
 // FileStore provides file-based persistence for game data using YAML serialization.
 // It supports atomic writes, file locking, and automatic directory management.
 //
+// FileStore implements the Store interface and is the default backend.
+//
+// With a Sealer set, every file is signed, and optionally encrypted, as it
+// is written and verified as it is read.
+//
 // FileStore is thread-safe for concurrent access within a single process.
 // For cross-process safety, use the file locking mechanisms.
 type FileStore struct {
 	dataDir string
+	sealer  *Sealer // nil stores plain YAML
 	mu      sync.RWMutex
 }
 
@@ -44,6 +52,34 @@ func NewFileStore(dataDir string) (*FileStore, error) {
 	}, nil
 }
 
+// SetSealer makes the store seal the files it writes and verify the files
+// it reads with sealer; nil turns sealing off.
+func (fs *FileStore) SetSealer(sealer *Sealer) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.sealer = sealer
+}
+
+// seal seals a marshaled document when a sealer is set.
+func (fs *FileStore) seal(yamlData []byte) ([]byte, error) {
+	if fs.sealer == nil {
+		return yamlData, nil
+	}
+	sealed, err := fs.sealer.Seal(yamlData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal data: %w", err)
+	}
+	return sealed, nil
+}
+
+// unseal verifies and decrypts a file read from disk when a sealer is set.
+func (fs *FileStore) unseal(filename string, data []byte) ([]byte, error) {
+	if fs.sealer == nil {
+		return data, nil
+	}
+	return fs.sealer.OpenChecked(filename, data)
+}
+
 // Save serializes an object to YAML and saves it to a file.
 // The save operation is atomic and uses file locking to prevent corruption.
 //
@@ -53,7 +89,10 @@ func NewFileStore(dataDir string) (*FileStore, error) {
 //
 // Returns:
 //   - error: Any error that occurred during the save operation
-func (fs *FileStore) Save(filename string, data interface{}) error {
+func (fs *FileStore) Save(filename string, data interface{}) (err error) {
+	span := startWriteSpan("save", BackendFile, 1)
+	defer func() { tracing.End(span, err) }()
+
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
@@ -81,6 +120,9 @@ func (fs *FileStore) Save(filename string, data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal data to YAML: %w", err)
 	}
+	if yamlData, err = fs.seal(yamlData); err != nil {
+		return err
+	}
 
 	// Write atomically
 	if err := AtomicWriteFile(fullPath, yamlData, 0o644); err != nil {
@@ -137,6 +179,9 @@ func (fs *FileStore) Load(filename string, data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
+	if yamlData, err = fs.unseal(filename, yamlData); err != nil {
+		return err
+	}
 
 	// Unmarshal YAML
 	if err := yaml.Unmarshal(yamlData, data); err != nil {
@@ -244,6 +289,178 @@ func (fs *FileStore) List(pattern string) ([]string, error) {
 	return relPaths, nil
 }
 
+// SaveBatch serializes several objects and writes each to its own file.
+// All objects are marshaled before anything is written, and files already
+// replaced are restored if a later write fails, so the batch is applied
+// either completely or not at all within this process.
+//
+// Parameters:
+//   - entries: Map of filename (relative to dataDir) to the object to save
+//
+// Returns:
+//   - error: Any error that occurred during the batch save
+func (fs *FileStore) SaveBatch(entries map[string]interface{}) (err error) {
+	span := startWriteSpan("save_batch", BackendFile, len(entries))
+	defer func() { tracing.End(span, err) }()
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	encoded := make(map[string][]byte, len(entries))
+	for filename, data := range entries {
+		yamlData, err := yaml.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s to YAML: %w", filename, err)
+		}
+		if yamlData, err = fs.seal(yamlData); err != nil {
+			return err
+		}
+		encoded[filename] = yamlData
+	}
+
+	// Lock every target first so other processes never observe a partial batch
+	locks := make([]*FileLock, 0, len(encoded))
+	defer func() {
+		for _, lock := range locks {
+			lock.Close()
+		}
+	}()
+	for _, filename := range sortedKeys(encoded) {
+		lock, err := NewFileLock(filepath.Join(fs.dataDir, filename))
+		if err != nil {
+			return fmt.Errorf("failed to create file lock: %w", err)
+		}
+		locks = append(locks, lock)
+		if err := lock.Lock(); err != nil {
+			return fmt.Errorf("failed to acquire file lock: %w", err)
+		}
+	}
+
+	written := make([]string, 0, len(encoded))
+	previous := make(map[string][]byte, len(encoded))
+	for _, filename := range sortedKeys(encoded) {
+		fullPath := filepath.Join(fs.dataDir, filename)
+		if old, err := os.ReadFile(fullPath); err == nil {
+			previous[filename] = old
+		}
+		if err := AtomicWriteFile(fullPath, encoded[filename], 0o644); err != nil {
+			fs.rollback(written, previous)
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+		written = append(written, filename)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "SaveBatch",
+		"files":    len(written),
+	}).Info("batch saved successfully")
+
+	return nil
+}
+
+// rollback restores files replaced by a failed batch to their previous contents.
+func (fs *FileStore) rollback(written []string, previous map[string][]byte) {
+	for _, filename := range written {
+		fullPath := filepath.Join(fs.dataDir, filename)
+		var err error
+		if old, ok := previous[filename]; ok {
+			err = AtomicWriteFile(fullPath, old, 0o644)
+		} else {
+			err = os.Remove(fullPath)
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "rollback",
+				"filename": filename,
+				"error":    err,
+			}).Error("failed to roll back batch write")
+		}
+	}
+}
+
+// Reseal rewrites every YAML file in the data directory that is not sealed
+// with the sealer's current key and mode, such as files written before
+// sealing was enabled or sealed with a rotated-out secret. Each sealed file
+// is verified before it is rewritten; files that fail are left untouched
+// and the rest are still resealed.
+//
+// Returns:
+//   - int: The number of files rewritten
+//   - error: Missing sealer, an I/O error, or ErrTampered for the first
+//     file that failed verification
+func (fs *FileStore) Reseal() (int, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.sealer == nil {
+		return 0, fmt.Errorf("no sealer set")
+	}
+
+	resealed := 0
+	var firstErr error
+	err := filepath.WalkDir(fs.dataDir, func(fullPath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(fullPath)
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+		rel, _ := filepath.Rel(fs.dataDir, fullPath)
+
+		lock, err := NewFileLock(fullPath)
+		if err != nil {
+			return fmt.Errorf("failed to create file lock: %w", err)
+		}
+		defer lock.Close()
+		if err := lock.Lock(); err != nil {
+			return fmt.Errorf("failed to acquire file lock: %w", err)
+		}
+
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		if fs.sealer.IsCurrent(data) {
+			return nil
+		}
+		plain := data
+		if IsSealed(data) {
+			if plain, err = fs.sealer.OpenChecked(rel, data); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return nil
+			}
+		}
+		sealed, err := fs.sealer.Seal(plain)
+		if err != nil {
+			return fmt.Errorf("failed to seal %s: %w", rel, err)
+		}
+		if err := AtomicWriteFile(fullPath, sealed, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		resealed++
+		return nil
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"function": "Reseal",
+		"files":    resealed,
+		"mode":     fs.sealer.Mode(),
+	}).Info("files resealed")
+
+	if err == nil {
+		err = firstErr
+	}
+	return resealed, err
+}
+
+// Close satisfies the Store interface. FileStore holds no open resources.
+func (fs *FileStore) Close() error {
+	return nil
+}
+
 // GetDataDir returns the data directory path.
 func (fs *FileStore) GetDataDir() string {
 	return fs.dataDir
@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"syscall"
 
 	"github.com/sirupsen/logrus"
 )
 
 // FileLock provides file-based locking to prevent concurrent writes.
-// It uses flock system calls to ensure exclusive access to files.
+// The locking primitive depends on the platform:
+//
+//   - Unix: flock system calls on the lock file (lock_unix.go)
+//   - Windows: LockFileEx on the lock file (lock_windows.go)
+//   - Other platforms: an exclusively created lock file with stale-lock
+//     detection (lock_other.go, lockfile.go)
 //
 // This is important for preventing corruption when multiple processes
 // or goroutines attempt to write to the same file simultaneously.
@@ -44,17 +48,15 @@ func NewFileLock(path string) (*FileLock, error) {
 		return nil, fmt.Errorf("failed to create lock directory: %w", err)
 	}
 
-	// Create lock file if it doesn't exist
-	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create lock file: %w", err)
-	}
-
-	return &FileLock{
-		file:     file,
+	fl := &FileLock{
 		path:     lockPath,
 		isLocked: false,
-	}, nil
+	}
+	if err := fl.open(); err != nil {
+		return nil, err
+	}
+
+	return fl, nil
 }
 
 // Lock acquires an exclusive lock on the file.
@@ -73,7 +75,7 @@ func (fl *FileLock) Lock() error {
 	}).Debug("acquiring file lock")
 
 	// Acquire exclusive lock (blocking)
-	if err := syscall.Flock(int(fl.file.Fd()), syscall.LOCK_EX); err != nil {
+	if _, err := fl.lock(true); err != nil {
 		return fmt.Errorf("failed to acquire lock: %w", err)
 	}
 
@@ -99,13 +101,13 @@ func (fl *FileLock) TryLock() (bool, error) {
 	}
 
 	// Try to acquire exclusive lock (non-blocking)
-	err := syscall.Flock(int(fl.file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	acquired, err := fl.lock(false)
 	if err != nil {
-		if err == syscall.EWOULDBLOCK {
-			return false, nil // Lock is held by another process
-		}
 		return false, fmt.Errorf("failed to try lock: %w", err)
 	}
+	if !acquired {
+		return false, nil // Lock is held by another process
+	}
 
 	fl.isLocked = true
 	return true, nil
@@ -126,7 +128,7 @@ func (fl *FileLock) Unlock() error {
 	}).Debug("releasing file lock")
 
 	// Release lock
-	if err := syscall.Flock(int(fl.file.Fd()), syscall.LOCK_UN); err != nil {
+	if err := fl.unlock(); err != nil {
 		return fmt.Errorf("failed to release lock: %w", err)
 	}
 
@@ -0,0 +1,20 @@
+//go:build !unix && !windows
+
+package persistence
+
+// open is a no-op: on platforms without flock or LockFileEx the lock file
+// itself is the lock, created by lock and removed by unlock.
+func (fl *FileLock) open() error {
+	return nil
+}
+
+// lock creates the lock file exclusively. When blocking is false it returns
+// false instead of waiting if another process holds the lock.
+func (fl *FileLock) lock(blocking bool) (bool, error) {
+	return acquireLockFile(fl.path, blocking)
+}
+
+// unlock removes the lock file.
+func (fl *FileLock) unlock() error {
+	return releaseLockFile(fl.path)
+}
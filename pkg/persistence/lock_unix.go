@@ -0,0 +1,41 @@
+//go:build unix
+
+package persistence
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// open creates the lock file if it doesn't exist and keeps it open for
+// flock calls.
+func (fl *FileLock) open() error {
+	file, err := os.OpenFile(fl.path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create lock file: %w", err)
+	}
+	fl.file = file
+	return nil
+}
+
+// lock acquires an exclusive flock on the lock file. When blocking is false
+// it returns false instead of waiting if another process holds the lock.
+func (fl *FileLock) lock(blocking bool) (bool, error) {
+	how := syscall.LOCK_EX
+	if !blocking {
+		how |= syscall.LOCK_NB
+	}
+	if err := syscall.Flock(int(fl.file.Fd()), how); err != nil {
+		if err == syscall.EWOULDBLOCK {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// unlock releases the flock on the lock file.
+func (fl *FileLock) unlock() error {
+	return syscall.Flock(int(fl.file.Fd()), syscall.LOCK_UN)
+}
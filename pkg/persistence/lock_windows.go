@@ -0,0 +1,45 @@
+//go:build windows
+
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// open creates the lock file if it doesn't exist and keeps it open for
+// LockFileEx calls.
+func (fl *FileLock) open() error {
+	file, err := os.OpenFile(fl.path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create lock file: %w", err)
+	}
+	fl.file = file
+	return nil
+}
+
+// lock acquires an exclusive LockFileEx lock on the first byte of the lock
+// file. When blocking is false it returns false instead of waiting if another
+// process holds the lock.
+func (fl *FileLock) lock(blocking bool) (bool, error) {
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK)
+	if !blocking {
+		flags |= windows.LOCKFILE_FAIL_IMMEDIATELY
+	}
+	err := windows.LockFileEx(windows.Handle(fl.file.Fd()), flags, 0, 1, 0, &windows.Overlapped{})
+	if err != nil {
+		if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// unlock releases the LockFileEx lock on the lock file.
+func (fl *FileLock) unlock() error {
+	return windows.UnlockFileEx(windows.Handle(fl.file.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
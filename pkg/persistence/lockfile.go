@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// lockFileStaleAge is how old an exclusive lock file must be before it
+	// is assumed to belong to a crashed process and is reclaimed.
+	lockFileStaleAge = time.Minute
+
+	// lockFilePollInterval is how often a blocking acquisition retries
+	// while another process holds the lock file.
+	lockFilePollInterval = 25 * time.Millisecond
+)
+
+// acquireLockFile takes a lock by creating path with O_EXCL, which works on
+// every platform, including those without flock or LockFileEx. The file
+// records the owner's PID and acquisition time for diagnostics.
+//
+// A lock file older than lockFileStaleAge is treated as abandoned by a
+// crashed process and removed. FileStore holds locks only for the duration
+// of a single save or load, so live locks never come close to that age.
+//
+// Parameters:
+//   - path: The lock file path
+//   - blocking: Wait until the lock is free instead of returning false
+//
+// Returns:
+//   - bool: true if the lock was acquired, false if it is held elsewhere
+//   - error: Any error other than the lock being held
+func acquireLockFile(path string, blocking bool) (bool, error) {
+	for {
+		acquired, err := createLockFile(path)
+		if err != nil || acquired {
+			return acquired, err
+		}
+
+		reclaimed, err := removeStaleLockFile(path)
+		if err != nil {
+			return false, err
+		}
+		if reclaimed {
+			continue
+		}
+
+		if !blocking {
+			return false, nil
+		}
+		time.Sleep(lockFilePollInterval)
+	}
+}
+
+// createLockFile atomically creates the lock file, returning false if it
+// already exists.
+func createLockFile(path string) (bool, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create lock file: %w", err)
+	}
+	defer file.Close()
+
+	// The contents are informational; the file's existence is the lock
+	fmt.Fprintf(file, "%d\n%s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	return true, nil
+}
+
+// removeStaleLockFile deletes the lock file if it is older than
+// lockFileStaleAge. It reports true if the lock is now free, either because
+// the stale file was removed or because its holder released it meanwhile.
+func removeStaleLockFile(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to inspect lock file: %w", err)
+	}
+
+	age := time.Since(info.ModTime())
+	if age < lockFileStaleAge {
+		return false, nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "removeStaleLockFile",
+		"path":     path,
+		"age":      age.String(),
+	}).Warn("removing stale lock file")
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to remove stale lock file: %w", err)
+	}
+	return true, nil
+}
+
+// releaseLockFile removes a lock file created by acquireLockFile. A missing
+// file is not an error, since FileStore.Delete removes lock files itself.
+func releaseLockFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireLockFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("excludes a second holder until released", func(t *testing.T) {
+		lockPath := filepath.Join(tmpDir, "exclusive.lock")
+
+		acquired, err := acquireLockFile(lockPath, false)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		acquired, err = acquireLockFile(lockPath, false)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		require.NoError(t, releaseLockFile(lockPath))
+		acquired, err = acquireLockFile(lockPath, false)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, releaseLockFile(lockPath))
+	})
+
+	t.Run("blocking acquisition waits for release", func(t *testing.T) {
+		lockPath := filepath.Join(tmpDir, "blocking.lock")
+
+		acquired, err := acquireLockFile(lockPath, false)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		go func() {
+			time.Sleep(5 * lockFilePollInterval)
+			releaseLockFile(lockPath)
+		}()
+
+		acquired, err = acquireLockFile(lockPath, true)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, releaseLockFile(lockPath))
+	})
+
+	t.Run("reclaims stale lock files", func(t *testing.T) {
+		lockPath := filepath.Join(tmpDir, "stale.lock")
+		require.NoError(t, os.WriteFile(lockPath, []byte("12345\n"), 0o644))
+
+		old := time.Now().Add(-2 * lockFileStaleAge)
+		require.NoError(t, os.Chtimes(lockPath, old, old))
+
+		acquired, err := acquireLockFile(lockPath, false)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		info, err := os.Stat(lockPath)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), info.ModTime(), time.Minute)
+	})
+
+	t.Run("release tolerates a missing lock file", func(t *testing.T) {
+		assert.NoError(t, releaseLockFile(filepath.Join(tmpDir, "missing.lock")))
+	})
+}
+
+func TestFileLock_ExcludesOtherInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.yaml")
+
+	first, err := NewFileLock(path)
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := NewFileLock(path)
+	require.NoError(t, err)
+	defer second.Close()
+
+	acquired, err := first.TryLock()
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	acquired, err = second.TryLock()
+	require.NoError(t, err)
+	assert.False(t, acquired, "another holder has the lock")
+
+	require.NoError(t, first.Unlock())
+	acquired, err = second.TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
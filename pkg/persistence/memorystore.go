@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MemoryStore keeps serialized values in memory. It uses the same YAML
+// encoding as FileStore, so values round-trip exactly as they would on
+// disk, which makes it a drop-in backend for tests.
+//
+// MemoryStore is thread-safe. Its contents are lost when the process exits.
+type MemoryStore struct {
+	data map[string][]byte
+	mu   sync.RWMutex
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: make(map[string][]byte),
+	}
+}
+
+// Save serializes an object to YAML and stores it under key.
+func (ms *MemoryStore) Save(key string, data interface{}) error {
+	yamlData, err := yaml.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data to YAML: %w", err)
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.data[key] = yamlData
+	return nil
+}
+
+// Load deserializes the value stored under key into data.
+func (ms *MemoryStore) Load(key string, data interface{}) error {
+	ms.mu.RLock()
+	yamlData, ok := ms.data[key]
+	ms.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("key does not exist: %s", key)
+	}
+	if err := yaml.Unmarshal(yamlData, data); err != nil {
+		return fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+	return nil
+}
+
+// Exists reports whether a value is stored under key.
+func (ms *MemoryStore) Exists(key string) bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	_, ok := ms.data[key]
+	return ok
+}
+
+// Delete removes the value stored under key.
+func (ms *MemoryStore) Delete(key string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.data, key)
+	return nil
+}
+
+// List returns the keys matching a glob pattern.
+func (ms *MemoryStore) List(pattern string) ([]string, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return matchKeys(sortedKeys(ms.data), pattern)
+}
+
+// SaveBatch serializes every entry before storing any of them, so a
+// marshaling failure leaves the store unchanged.
+func (ms *MemoryStore) SaveBatch(entries map[string]interface{}) error {
+	encoded := make(map[string][]byte, len(entries))
+	for key, data := range entries {
+		yamlData, err := yaml.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s to YAML: %w", key, err)
+		}
+		encoded[key] = yamlData
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for key, yamlData := range encoded {
+		ms.data[key] = yamlData
+	}
+	return nil
+}
+
+// Close satisfies the Store interface. MemoryStore holds no open resources.
+func (ms *MemoryStore) Close() error {
+	return nil
+}
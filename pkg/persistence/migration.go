@@ -0,0 +1,340 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SchemaVersionKey is the top-level field VersionedStore embeds in every
+	// document it saves.
+	SchemaVersionKey = "schema_version"
+
+	// BaseSchemaVersion is the version of documents saved before versioning
+	// was introduced, which carry no SchemaVersionKey.
+	BaseSchemaVersion = 1
+
+	// MigrationBackupPrefix is the key prefix under which VersionedStore
+	// keeps each document as it was before being migrated.
+	MigrationBackupPrefix = "migrations/"
+)
+
+// ErrSchemaTooNew is returned when loading a document saved by a newer
+// version of the game than this one supports.
+var ErrSchemaTooNew = errors.New("document schema version is newer than supported")
+
+// Migration upgrades a decoded document from one schema version to the
+// next in place. key identifies the document, so a migration can limit
+// itself to the documents whose layout changed.
+type Migration func(key string, doc map[string]interface{}) error
+
+// MigrationRegistry holds the migrations between consecutive schema
+// versions. Its current version is one past the newest registered
+// migration, or BaseSchemaVersion when none are registered.
+//
+// MigrationRegistry is safe for concurrent use.
+type MigrationRegistry struct {
+	migrations map[int]Migration
+	mu         sync.RWMutex
+}
+
+// DefaultMigrations is the registry used by the game's persisted documents.
+var DefaultMigrations = NewMigrationRegistry()
+
+// NewMigrationRegistry creates a registry without migrations.
+func NewMigrationRegistry() *MigrationRegistry {
+	return &MigrationRegistry{
+		migrations: make(map[int]Migration),
+	}
+}
+
+// MigrateFrom registers the migration upgrading documents from version to
+// version+1. Register migrations once, typically from an init function,
+// and never change one that has shipped.
+//
+// Parameters:
+//   - version: The schema version the migration upgrades from
+//   - migration: The upgrade function
+//
+// Returns:
+//   - error: A version below BaseSchemaVersion, a nil migration or one
+//     already registered for version
+func (r *MigrationRegistry) MigrateFrom(version int, migration Migration) error {
+	if version < BaseSchemaVersion {
+		return fmt.Errorf("cannot migrate from schema version %d, the first is %d", version, BaseSchemaVersion)
+	}
+	if migration == nil {
+		return fmt.Errorf("migration from schema version %d is nil", version)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.migrations[version]; exists {
+		return fmt.Errorf("a migration from schema version %d is already registered", version)
+	}
+	r.migrations[version] = migration
+	return nil
+}
+
+// CurrentVersion returns the schema version documents are saved with.
+func (r *MigrationRegistry) CurrentVersion() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	current := BaseSchemaVersion
+	for version := range r.migrations {
+		current = max(current, version+1)
+	}
+	return current
+}
+
+// Migrate upgrades doc from version to the current version, one migration
+// at a time, and records the new version in it.
+//
+// Parameters:
+//   - key: The document's key
+//   - version: The document's schema version
+//   - doc: The decoded document, modified in place
+//
+// Returns:
+//   - int: The version doc was upgraded to
+//   - error: A missing or failing migration, or ErrSchemaTooNew
+func (r *MigrationRegistry) Migrate(key string, version int, doc map[string]interface{}) (int, error) {
+	current := r.CurrentVersion()
+	if version > current {
+		return version, fmt.Errorf("%w: %s is version %d, supported %d", ErrSchemaTooNew, key, version, current)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for ; version < current; version++ {
+		migration, exists := r.migrations[version]
+		if !exists {
+			return version, fmt.Errorf("no migration from schema version %d", version)
+		}
+		if err := migration(key, doc); err != nil {
+			return version, fmt.Errorf("failed to migrate %s from schema version %d: %w", key, version, err)
+		}
+	}
+	doc[SchemaVersionKey] = current
+	return current, nil
+}
+
+// VersionedStore wraps a Store and embeds the current schema version in
+// every document it saves. Documents loaded with an older version are
+// upgraded through the registry's migrations and saved back, after the
+// original is copied under MigrationBackupPrefix. Documents that are not
+// YAML mappings, and keys under BackupPrefix, SnapshotPrefix or
+// MigrationBackupPrefix, are passed through unchanged.
+//
+// Wrap the store last, so backups and snapshots of the wrapped store keep
+// documents with the version they were saved with.
+//
+// VersionedStore is safe for concurrent use if the wrapped store is.
+type VersionedStore struct {
+	Store
+	migrations *MigrationRegistry
+	mu         sync.Mutex // Serializes migrations
+}
+
+var _ Store = (*VersionedStore)(nil)
+
+// NewVersionedStore wraps store with schema versioning.
+//
+// Parameters:
+//   - store: The store holding the documents
+//   - migrations: The migrations to apply; nil uses DefaultMigrations
+//
+// Returns:
+//   - *VersionedStore: The wrapping store
+func NewVersionedStore(store Store, migrations *MigrationRegistry) *VersionedStore {
+	if migrations == nil {
+		migrations = DefaultMigrations
+	}
+	return &VersionedStore{
+		Store:      store,
+		migrations: migrations,
+	}
+}
+
+// Save stores data under key with the current schema version.
+func (vs *VersionedStore) Save(key string, data interface{}) error {
+	stamped, err := vs.stamp(key, data)
+	if err != nil {
+		return err
+	}
+	return vs.Store.Save(key, stamped)
+}
+
+// SaveBatch stores every entry with the current schema version.
+func (vs *VersionedStore) SaveBatch(entries map[string]interface{}) error {
+	stamped := make(map[string]interface{}, len(entries))
+	for key, data := range entries {
+		value, err := vs.stamp(key, data)
+		if err != nil {
+			return err
+		}
+		stamped[key] = value
+	}
+	return vs.Store.SaveBatch(stamped)
+}
+
+// Load deserializes the value stored under key into data, migrating it to
+// the current schema version first if it is older.
+func (vs *VersionedStore) Load(key string, data interface{}) error {
+	if !versioned(key) {
+		return vs.Store.Load(key, data)
+	}
+
+	var node yaml.Node
+	if err := vs.Store.Load(key, &node); err != nil {
+		return err
+	}
+	doc := documentContent(&node)
+	if doc.Kind != yaml.MappingNode {
+		return doc.Decode(data)
+	}
+
+	version, err := removeSchemaVersion(doc)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version of %s: %w", key, err)
+	}
+	if version != vs.migrations.CurrentVersion() {
+		if doc, err = vs.migrate(key, version, doc); err != nil {
+			return err
+		}
+	}
+	return doc.Decode(data)
+}
+
+// migrate upgrades a document loaded at version, backs up the original and
+// saves the upgraded document, returning it without its schema version.
+func (vs *VersionedStore) migrate(key string, version int, doc *yaml.Node) (*yaml.Node, error) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	var values map[string]interface{}
+	if err := doc.Decode(&values); err != nil {
+		return nil, fmt.Errorf("failed to decode %s for migration: %w", key, err)
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	current, err := vs.migrations.Migrate(key, version, values)
+	if err != nil {
+		return nil, err
+	}
+
+	original := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: doc.Content}
+	if version != BaseSchemaVersion {
+		setSchemaVersion(original, version)
+	}
+	backupKey := migrationBackupKey(key, version)
+	if !vs.Store.Exists(backupKey) {
+		if err := vs.Store.Save(backupKey, original); err != nil {
+			return nil, fmt.Errorf("failed to back up %s before migration: %w", key, err)
+		}
+	}
+	if err := vs.Store.Save(key, values); err != nil {
+		return nil, fmt.Errorf("failed to save migrated %s: %w", key, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "migrate",
+		"key":      key,
+		"from":     version,
+		"to":       current,
+		"backup":   backupKey,
+	}).Info("migrated document to current schema version")
+
+	delete(values, SchemaVersionKey)
+	var migrated yaml.Node
+	if err := migrated.Encode(values); err != nil {
+		return nil, fmt.Errorf("failed to encode migrated %s: %w", key, err)
+	}
+	return &migrated, nil
+}
+
+// stamp returns data encoded with the current schema version embedded, or
+// data unchanged when key or the encoded value is not versioned.
+func (vs *VersionedStore) stamp(key string, data interface{}) (interface{}, error) {
+	if !versioned(key) {
+		return data, nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to marshal %s to YAML: %w", key, err)
+	}
+	doc := documentContent(&node)
+	if doc.Kind != yaml.MappingNode {
+		return data, nil
+	}
+	setSchemaVersion(doc, vs.migrations.CurrentVersion())
+	return doc, nil
+}
+
+// versioned reports whether documents stored under key carry a schema
+// version. Backups, snapshots and pre-migration copies are stored as taken.
+func versioned(key string) bool {
+	for _, prefix := range []string{BackupPrefix, SnapshotPrefix, MigrationBackupPrefix} {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// documentContent returns the root value of a decoded YAML document.
+func documentContent(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		return node.Content[0]
+	}
+	return node
+}
+
+// removeSchemaVersion deletes the schema version from a mapping node and
+// returns it, or BaseSchemaVersion when the mapping has none.
+func removeSchemaVersion(mapping *yaml.Node) (int, error) {
+	value := deleteMappingKey(mapping, SchemaVersionKey)
+	if value == nil {
+		return BaseSchemaVersion, nil
+	}
+	return strconv.Atoi(value.Value)
+}
+
+// setSchemaVersion makes version the first field of a mapping node,
+// replacing any version it already has.
+func setSchemaVersion(mapping *yaml.Node, version int) {
+	deleteMappingKey(mapping, SchemaVersionKey)
+	mapping.Content = append([]*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: SchemaVersionKey},
+		{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)},
+	}, mapping.Content...)
+}
+
+// deleteMappingKey removes key from a mapping node and returns its value
+// node, or nil when the mapping has no such key.
+func deleteMappingKey(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i:i], mapping.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// migrationBackupKey returns the key under which the version of key being
+// migrated is kept.
+func migrationBackupKey(key string, version int) string {
+	return fmt.Sprintf("%s%s/v%d.yaml", MigrationBackupPrefix, url.PathEscape(key), version)
+}
@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renameField returns a migration renaming a top-level field
+func renameField(from, to string) Migration {
+	return func(key string, doc map[string]interface{}) error {
+		if value, ok := doc[from]; ok {
+			doc[to] = value
+			delete(doc, from)
+		}
+		return nil
+	}
+}
+
+func TestMigrationRegistry_MigrateFrom(t *testing.T) {
+	registry := NewMigrationRegistry()
+	assert.Equal(t, BaseSchemaVersion, registry.CurrentVersion())
+
+	require.NoError(t, registry.MigrateFrom(1, renameField("a", "b")))
+	require.NoError(t, registry.MigrateFrom(2, renameField("b", "c")))
+	assert.Equal(t, 3, registry.CurrentVersion())
+
+	assert.Error(t, registry.MigrateFrom(1, renameField("a", "b")), "duplicate migration")
+	assert.Error(t, registry.MigrateFrom(0, renameField("a", "b")), "version below the base")
+	assert.Error(t, registry.MigrateFrom(3, nil), "nil migration")
+
+	doc := map[string]interface{}{"a": 1}
+	version, err := registry.Migrate("doc.yaml", 1, doc)
+	require.NoError(t, err)
+	assert.Equal(t, 3, version)
+	assert.Equal(t, map[string]interface{}{"c": 1, SchemaVersionKey: 3}, doc)
+
+	_, err = registry.Migrate("doc.yaml", 4, doc)
+	assert.ErrorIs(t, err, ErrSchemaTooNew)
+
+	gap := NewMigrationRegistry()
+	require.NoError(t, gap.MigrateFrom(2, renameField("b", "c")))
+	_, err = gap.Migrate("doc.yaml", 1, map[string]interface{}{})
+	assert.Error(t, err, "missing migration from version 1")
+}
+
+func TestVersionedStore_StampsVersion(t *testing.T) {
+	raw := NewMemoryStore()
+	store := NewVersionedStore(raw, NewMigrationRegistry())
+
+	require.NoError(t, store.Save("hero.yaml", storeTestData{Name: "hero", Value: 3}))
+	require.NoError(t, store.SaveBatch(map[string]interface{}{"party.yaml": storeTestData{Name: "party"}}))
+
+	for _, key := range []string{"hero.yaml", "party.yaml"} {
+		var doc map[string]interface{}
+		require.NoError(t, raw.Load(key, &doc))
+		assert.Equal(t, BaseSchemaVersion, doc[SchemaVersionKey], key)
+	}
+
+	var loaded storeTestData
+	require.NoError(t, store.Load("hero.yaml", &loaded))
+	assert.Equal(t, storeTestData{Name: "hero", Value: 3}, loaded)
+
+	// The version is not exposed to documents loaded as maps
+	var doc map[string]interface{}
+	require.NoError(t, store.Load("hero.yaml", &doc))
+	assert.NotContains(t, doc, SchemaVersionKey)
+
+	// Non-mapping documents and backups are stored as given
+	require.NoError(t, store.Save("names.yaml", []string{"a", "b"}))
+	var names []string
+	require.NoError(t, store.Load("names.yaml", &names))
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	require.NoError(t, store.Save(BackupPrefix+"hero.yaml/1.yaml", storeTestData{Name: "copy"}))
+	doc = nil
+	require.NoError(t, raw.Load(BackupPrefix+"hero.yaml/1.yaml", &doc))
+	assert.NotContains(t, doc, SchemaVersionKey)
+}
+
+func TestVersionedStore_MigratesOnLoad(t *testing.T) {
+	raw := NewMemoryStore()
+	require.NoError(t, raw.Save("hero.yaml", map[string]interface{}{"title": "hero", "score": 3}))
+
+	registry := NewMigrationRegistry()
+	require.NoError(t, registry.MigrateFrom(1, renameField("title", "label")))
+	require.NoError(t, registry.MigrateFrom(2, renameField("label", "name")))
+	require.NoError(t, registry.MigrateFrom(3, renameField("score", "value")))
+	store := NewVersionedStore(raw, registry)
+
+	var loaded storeTestData
+	require.NoError(t, store.Load("hero.yaml", &loaded))
+	assert.Equal(t, storeTestData{Name: "hero", Value: 3}, loaded)
+
+	// The upgraded document is saved back with the current version
+	var saved map[string]interface{}
+	require.NoError(t, raw.Load("hero.yaml", &saved))
+	assert.Equal(t, map[string]interface{}{"name": "hero", "value": 3, SchemaVersionKey: 4}, saved)
+
+	// The original is kept as it was
+	var original map[string]interface{}
+	require.NoError(t, raw.Load(migrationBackupKey("hero.yaml", 1), &original))
+	assert.Equal(t, map[string]interface{}{"title": "hero", "score": 3}, original)
+
+	// A document at an intermediate version only runs the later migrations
+	require.NoError(t, raw.Save("old.yaml", map[string]interface{}{SchemaVersionKey: 3, "name": "old", "score": 5}))
+	require.NoError(t, store.Load("old.yaml", &loaded))
+	assert.Equal(t, storeTestData{Name: "old", Value: 5}, loaded)
+	original = nil
+	require.NoError(t, raw.Load(migrationBackupKey("old.yaml", 3), &original))
+	assert.Equal(t, 3, original[SchemaVersionKey])
+}
+
+func TestVersionedStore_MigrationErrors(t *testing.T) {
+	raw := NewMemoryStore()
+	registry := NewMigrationRegistry()
+	require.NoError(t, registry.MigrateFrom(1, func(key string, doc map[string]interface{}) error {
+		return errors.New("cannot upgrade")
+	}))
+	store := NewVersionedStore(raw, registry)
+
+	require.NoError(t, raw.Save("hero.yaml", storeTestData{Name: "hero"}))
+	var loaded storeTestData
+	assert.Error(t, store.Load("hero.yaml", &loaded))
+	assert.False(t, raw.Exists(migrationBackupKey("hero.yaml", 1)))
+
+	var doc map[string]interface{}
+	require.NoError(t, raw.Load("hero.yaml", &doc))
+	assert.NotContains(t, doc, SchemaVersionKey, "a failed migration leaves the document untouched")
+
+	require.NoError(t, raw.Save("future.yaml", map[string]interface{}{SchemaVersionKey: 9, "name": "future"}))
+	assert.ErrorIs(t, store.Load("future.yaml", &loaded), ErrSchemaTooNew)
+}
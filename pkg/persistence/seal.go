@@ -0,0 +1,291 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"goldbox-rpg/pkg/resilience"
+)
+
+// Sealed file modes
+const (
+	// SealModeSign signs files with HMAC-SHA256 and leaves the YAML readable
+	SealModeSign = "sign"
+
+	// SealModeEncrypt encrypts files with AES-256-GCM and signs the ciphertext
+	SealModeEncrypt = "encrypt"
+)
+
+// sealMagic starts the header line of every sealed file
+const sealMagic = "GBSEAL1"
+
+// MinSealSecretLength is the shortest secret NewSealer accepts
+const MinSealSecretLength = 16
+
+var (
+	// ErrTampered is returned when a sealed file's signature does not match
+	// its contents, when it was sealed with a key the sealer does not hold,
+	// or when an unsealed file is loaded while unsealed files are refused.
+	// It wraps resilience.ErrIntegrityViolation.
+	ErrTampered = fmt.Errorf("%w: persisted file failed its integrity check", resilience.ErrIntegrityViolation)
+
+	// errMalformedSeal is returned for sealed files whose header cannot be parsed
+	errMalformedSeal = errors.New("malformed seal header")
+)
+
+// SealOptions configures a Sealer.
+type SealOptions struct {
+	// Secret derives the current encryption and signing keys. Files are
+	// always sealed with it.
+	Secret string
+
+	// PreviousSecrets are retired secrets whose files can still be opened,
+	// so the secret can be rotated without losing saves. FileStore.Reseal
+	// rewrites those files with the current secret.
+	PreviousSecrets []string
+
+	// Encrypt selects SealModeEncrypt; otherwise files are only signed.
+	Encrypt bool
+
+	// AllowUnsealed accepts plain YAML files written before sealing was
+	// enabled; they are sealed the next time they are saved.
+	AllowUnsealed bool
+}
+
+// sealKey holds the keys derived from one secret
+type sealKey struct {
+	id  string
+	enc []byte
+	mac []byte
+}
+
+// Sealer signs and optionally encrypts persisted documents, and verifies
+// and decrypts them again on load.
+//
+// A sealed file starts with a header line naming the format, the mode, the
+// ID of the key it was sealed with and an HMAC-SHA256 signature of the rest
+// of the file, followed by the YAML document or, when encrypted, its
+// base64-encoded AES-256-GCM ciphertext. The key ID lets files sealed with
+// a previous secret be opened after the secret is rotated.
+//
+// Sealer is safe for concurrent use.
+type Sealer struct {
+	keys          []sealKey // current key first
+	mode          string
+	allowUnsealed bool
+}
+
+// NewSealer derives the sealing keys from the secrets in opts.
+//
+// Parameters:
+//   - opts: The secrets and sealing mode
+//
+// Returns:
+//   - *Sealer: The sealer
+//   - error: A missing or short secret
+func NewSealer(opts SealOptions) (*Sealer, error) {
+	secrets := append([]string{opts.Secret}, opts.PreviousSecrets...)
+	sealer := &Sealer{
+		keys:          make([]sealKey, 0, len(secrets)),
+		mode:          SealModeSign,
+		allowUnsealed: opts.AllowUnsealed,
+	}
+	if opts.Encrypt {
+		sealer.mode = SealModeEncrypt
+	}
+
+	for _, secret := range secrets {
+		if len(secret) < MinSealSecretLength {
+			return nil, fmt.Errorf("seal secret must be at least %d characters long", MinSealSecretLength)
+		}
+		sealer.keys = append(sealer.keys, deriveSealKey(secret))
+	}
+	return sealer, nil
+}
+
+// deriveSealKey derives independent encryption and signing keys, and a
+// public key ID, from a secret
+func deriveSealKey(secret string) sealKey {
+	derive := func(purpose string) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("goldbox-rpg " + purpose))
+		return mac.Sum(nil)
+	}
+	return sealKey{
+		id:  hex.EncodeToString(derive("seal key id")[:4]),
+		enc: derive("seal encryption"),
+		mac: derive("seal signing"),
+	}
+}
+
+// Mode returns SealModeSign or SealModeEncrypt.
+func (s *Sealer) Mode() string {
+	return s.mode
+}
+
+// Seal signs, and in SealModeEncrypt encrypts, a YAML document with the
+// current key.
+//
+// Parameters:
+//   - plain: The YAML document
+//
+// Returns:
+//   - []byte: The sealed file contents
+//   - error: Any error encrypting the document
+func (s *Sealer) Seal(plain []byte) ([]byte, error) {
+	key := s.keys[0]
+	prefix := fmt.Sprintf("%s %s %s", sealMagic, s.mode, key.id)
+
+	body := plain
+	if s.mode == SealModeEncrypt {
+		gcm, err := newSealGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		ciphertext := gcm.Seal(nonce, nonce, plain, []byte(prefix))
+		body = []byte(base64.StdEncoding.EncodeToString(ciphertext) + "\n")
+	}
+
+	signature := base64.StdEncoding.EncodeToString(signSeal(key, prefix, body))
+	sealed := make([]byte, 0, len(prefix)+len(signature)+len(body)+2)
+	sealed = append(sealed, prefix...)
+	sealed = append(sealed, ' ')
+	sealed = append(sealed, signature...)
+	sealed = append(sealed, '\n')
+	return append(sealed, body...), nil
+}
+
+// Open verifies and, if it is encrypted, decrypts a sealed file. Unsealed
+// files are returned as they are when AllowUnsealed is set.
+//
+// Parameters:
+//   - data: The file contents
+//
+// Returns:
+//   - []byte: The YAML document
+//   - error: ErrTampered if the file fails verification
+func (s *Sealer) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		if s.allowUnsealed {
+			return data, nil
+		}
+		return nil, fmt.Errorf("%w: file is not sealed", ErrTampered)
+	}
+
+	header, body, _ := bytes.Cut(data, []byte("\n"))
+	fields := strings.Fields(string(header))
+	if len(fields) != 4 || (fields[1] != SealModeSign && fields[1] != SealModeEncrypt) {
+		return nil, fmt.Errorf("%w: %v", ErrTampered, errMalformedSeal)
+	}
+	mode, keyID := fields[1], fields[2]
+	signature, err := base64.StdEncoding.DecodeString(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTampered, errMalformedSeal)
+	}
+
+	key, ok := s.key(keyID)
+	if !ok {
+		return nil, fmt.Errorf("%w: sealed with unknown key %s", ErrTampered, keyID)
+	}
+	prefix := strings.Join(fields[:3], " ")
+	if !hmac.Equal(signature, signSeal(key, prefix, body)) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrTampered)
+	}
+	if mode == SealModeSign {
+		return body, nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTampered, err)
+	}
+	gcm, err := newSealGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: ciphertext too short", ErrTampered)
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(prefix))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTampered, err)
+	}
+	return plain, nil
+}
+
+// OpenChecked opens a sealed file through the file system circuit breaker,
+// so tamper detections show up in its integrity violation statistics.
+func (s *Sealer) OpenChecked(filename string, data []byte) (plain []byte, err error) {
+	err = resilience.ExecuteWithFileSystemCircuitBreaker(context.Background(), func(ctx context.Context) error {
+		var openErr error
+		plain, openErr = s.Open(data)
+		if openErr != nil {
+			return fmt.Errorf("%s: %w", filename, openErr)
+		}
+		return nil
+	})
+	return plain, err
+}
+
+// IsCurrent reports whether data is sealed with the current key and mode,
+// that is, whether resealing it would change nothing but the nonce.
+func (s *Sealer) IsCurrent(data []byte) bool {
+	if !IsSealed(data) {
+		return false
+	}
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	fields := strings.Fields(string(header))
+	return len(fields) == 4 && fields[1] == s.mode && fields[2] == s.keys[0].id
+}
+
+// key returns the key with the given ID
+func (s *Sealer) key(id string) (sealKey, bool) {
+	for _, key := range s.keys {
+		if key.id == id {
+			return key, true
+		}
+	}
+	return sealKey{}, false
+}
+
+// IsSealed reports whether data starts with a seal header.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(sealMagic+" "))
+}
+
+// signSeal signs a header prefix and the body that follows it
+func signSeal(key sealKey, prefix string, body []byte) []byte {
+	mac := hmac.New(sha256.New, key.mac)
+	mac.Write([]byte(prefix))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// newSealGCM creates the AES-256-GCM cipher of a key
+func newSealGCM(key sealKey) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.enc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,155 @@
+package persistence
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goldbox-rpg/pkg/resilience"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSealSecret    = "current-secret-0123456789"
+	testOldSealSecret = "retired-secret-0123456789"
+)
+
+func newTestSealer(t *testing.T, opts SealOptions) *Sealer {
+	t.Helper()
+	if opts.Secret == "" {
+		opts.Secret = testSealSecret
+	}
+	sealer, err := NewSealer(opts)
+	require.NoError(t, err)
+	return sealer
+}
+
+func TestNewSealer_ShortSecret(t *testing.T) {
+	_, err := NewSealer(SealOptions{Secret: "short"})
+	assert.Error(t, err)
+
+	_, err = NewSealer(SealOptions{Secret: testSealSecret, PreviousSecrets: []string{"short"}})
+	assert.Error(t, err, "retired secrets must be long enough too")
+}
+
+func TestSealer_RoundTrip(t *testing.T) {
+	plain := []byte("name: Aldric\nlevel: 3\n")
+
+	for _, encrypt := range []bool{false, true} {
+		sealer := newTestSealer(t, SealOptions{Encrypt: encrypt})
+		sealed, err := sealer.Seal(plain)
+		require.NoError(t, err)
+
+		assert.True(t, IsSealed(sealed))
+		assert.True(t, sealer.IsCurrent(sealed))
+		assert.Equal(t, !encrypt, bytes.Contains(sealed, []byte("Aldric")), "only signed files stay readable")
+
+		opened, err := sealer.Open(sealed)
+		require.NoError(t, err)
+		assert.Equal(t, plain, opened)
+	}
+}
+
+func TestSealer_DetectsTampering(t *testing.T) {
+	for _, encrypt := range []bool{false, true} {
+		sealer := newTestSealer(t, SealOptions{Encrypt: encrypt})
+		sealed, err := sealer.Seal([]byte("gold: 10\n"))
+		require.NoError(t, err)
+
+		tampered := append([]byte(nil), sealed...)
+		tampered[len(tampered)-3] ^= 0x01
+		_, err = sealer.Open(tampered)
+		assert.True(t, errors.Is(err, ErrTampered), "a modified body is detected")
+		assert.True(t, errors.Is(err, resilience.ErrIntegrityViolation))
+
+		// Switching modes in the header invalidates the signature
+		swapped := bytes.Replace(sealed, []byte(sealer.Mode()), []byte(SealModeSign+"x"), 1)
+		_, err = sealer.Open(swapped)
+		assert.True(t, errors.Is(err, ErrTampered))
+	}
+
+	_, err := newTestSealer(t, SealOptions{}).Open([]byte("gold: 9999\n"))
+	assert.True(t, errors.Is(err, ErrTampered), "unsealed files are refused by default")
+
+	opened, err := newTestSealer(t, SealOptions{AllowUnsealed: true}).Open([]byte("gold: 10\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("gold: 10\n"), opened)
+}
+
+func TestSealer_KeyRotation(t *testing.T) {
+	old := newTestSealer(t, SealOptions{Secret: testOldSealSecret, Encrypt: true})
+	sealed, err := old.Seal([]byte("gold: 10\n"))
+	require.NoError(t, err)
+
+	_, err = newTestSealer(t, SealOptions{}).Open(sealed)
+	assert.True(t, errors.Is(err, ErrTampered), "files sealed with an unknown key are refused")
+
+	rotated := newTestSealer(t, SealOptions{PreviousSecrets: []string{testOldSealSecret}})
+	opened, err := rotated.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("gold: 10\n"), opened)
+	assert.False(t, rotated.IsCurrent(sealed), "files sealed with a retired key need resealing")
+}
+
+func TestFileStore_Sealed(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(StoreOptions{DataDir: dir, Sealer: newTestSealer(t, SealOptions{Encrypt: true})})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save("game.yaml", storeTestData{Name: "Aldric", Value: 3}))
+	require.NoError(t, store.SaveBatch(map[string]interface{}{"party/one.yaml": storeTestData{Name: "Mira"}}))
+
+	raw, err := os.ReadFile(filepath.Join(dir, "game.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "Aldric", "the file on disk is encrypted")
+
+	var loaded storeTestData
+	require.NoError(t, store.Load("game.yaml", &loaded))
+	assert.Equal(t, storeTestData{Name: "Aldric", Value: 3}, loaded)
+
+	raw[len(raw)-5] ^= 0x01
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "game.yaml"), raw, 0o644))
+	err = store.Load("game.yaml", &loaded)
+	assert.True(t, errors.Is(err, ErrTampered))
+
+	_, err = OpenStore(StoreOptions{Backend: BackendMemory, Sealer: newTestSealer(t, SealOptions{})})
+	assert.Error(t, err, "only the file backend can seal")
+}
+
+func TestFileStore_Reseal(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save("plain.yaml", storeTestData{Name: "Plain"}))
+	store.SetSealer(newTestSealer(t, SealOptions{Secret: testOldSealSecret}))
+	require.NoError(t, store.Save("old.yaml", storeTestData{Name: "Old"}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "events.log"), []byte("not yaml"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "forged.yaml"), []byte(sealMagic+" sign 00000000 AAAA\ngold: 9999\n"), 0o644))
+
+	store.SetSealer(newTestSealer(t, SealOptions{PreviousSecrets: []string{testOldSealSecret}, AllowUnsealed: true, Encrypt: true}))
+	resealed, err := store.Reseal()
+	assert.True(t, errors.Is(err, ErrTampered), "the forged file is reported")
+	assert.Equal(t, 2, resealed, "the other files are still resealed")
+	require.NoError(t, os.Remove(filepath.Join(dir, "forged.yaml")))
+
+	// Only the current secret is needed from now on
+	store.SetSealer(newTestSealer(t, SealOptions{Encrypt: true}))
+	for name, want := range map[string]string{"plain.yaml": "Plain", "old.yaml": "Old"} {
+		var loaded storeTestData
+		require.NoError(t, store.Load(name, &loaded))
+		assert.Equal(t, want, loaded.Name)
+	}
+
+	resealed, err = store.Reseal()
+	require.NoError(t, err)
+	assert.Zero(t, resealed, "current files are left alone")
+
+	log, err := os.ReadFile(filepath.Join(dir, "events.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "not yaml", string(log), "only YAML files are sealed")
+}
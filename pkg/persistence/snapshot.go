@@ -0,0 +1,416 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// SnapshotPrefix is the key prefix under which SnapshotManager keeps its
+// snapshots.
+const SnapshotPrefix = "snapshots/"
+
+// ErrSnapshotCorrupt is returned when a snapshot's contents do not match the
+// checksums recorded when it was taken.
+var ErrSnapshotCorrupt = errors.New("snapshot is corrupt")
+
+// SnapshotPolicy controls how often SnapshotManager takes snapshots and how
+// many it keeps.
+type SnapshotPolicy struct {
+	// MaxCount is the number of snapshots kept; older ones are rotated out.
+	// Zero keeps every snapshot.
+	MaxCount int
+
+	// MinInterval makes AutoSnapshot skip taking a snapshot while the
+	// newest one is younger than this. Zero snapshots on every call.
+	MinInterval time.Duration
+
+	// CompactAfter is the age from which Compact thins out snapshots. Zero
+	// disables compaction.
+	CompactAfter time.Duration
+
+	// CompactSpacing is the minimum time Compact leaves between the
+	// snapshots it keeps once they are older than CompactAfter.
+	CompactSpacing time.Duration
+}
+
+// SnapshotInfo describes one stored snapshot.
+type SnapshotInfo struct {
+	ID        string    `json:"id"`         // Store key of the snapshot, used to restore it
+	CreatedAt time.Time `json:"created_at"` // When the snapshot was taken
+	Keys      []string  `json:"keys"`       // Keys captured by the snapshot
+	Checksum  string    `json:"checksum"`   // SHA-256 over the captured keys and their checksums
+	Size      int       `json:"size"`       // Total size of the captured YAML in bytes
+}
+
+// snapshotRecord is the stored form of a snapshot. Each entry holds the
+// YAML of one key so its checksum covers exactly the bytes that are
+// restored.
+type snapshotRecord struct {
+	CreatedAt time.Time                `yaml:"created_at"`
+	Checksum  string                   `yaml:"checksum"`
+	Entries   map[string]snapshotEntry `yaml:"entries"`
+}
+
+// snapshotEntry is the captured value of one key.
+type snapshotEntry struct {
+	Checksum string `yaml:"checksum"`
+	Data     string `yaml:"data"`
+}
+
+// SnapshotManager captures several keys of a Store together as one
+// timestamped, checksummed snapshot, so related documents such as the game
+// state and the PCG state can be rolled back to the same point in time.
+// Snapshots are stored in the same store under SnapshotPrefix, each written
+// with a single Save, and restored with a single SaveBatch.
+//
+// SnapshotManager is safe for concurrent use if the store is.
+type SnapshotManager struct {
+	store  Store
+	policy SnapshotPolicy
+	now    func() time.Time
+	mu     sync.Mutex // Serializes snapshot rotation and compaction
+}
+
+// NewSnapshotManager creates a snapshot manager for store.
+//
+// Parameters:
+//   - store: The store holding both the live values and the snapshots
+//   - policy: Rotation, interval and compaction settings
+//
+// Returns:
+//   - *SnapshotManager: The snapshot manager
+func NewSnapshotManager(store Store, policy SnapshotPolicy) *SnapshotManager {
+	return &SnapshotManager{
+		store:  store,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Take snapshots the current values of keys. Keys that are not stored are
+// left out of the snapshot. Snapshots beyond MaxCount are then rotated out.
+//
+// Parameters:
+//   - keys: The keys to capture together
+//
+// Returns:
+//   - *SnapshotInfo: The snapshot taken
+//   - error: No key is stored, or any error reading the keys or writing the
+//     snapshot
+func (sm *SnapshotManager) Take(keys []string) (*SnapshotInfo, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	return sm.takeLocked(keys)
+}
+
+// AutoSnapshot is Take for periodic callers: it does nothing while the
+// newest snapshot is younger than the policy's MinInterval.
+//
+// Returns:
+//   - *SnapshotInfo: The snapshot taken, or nil if none was due
+//   - error: Any error taking the snapshot
+func (sm *SnapshotManager) AutoSnapshot(keys []string) (*SnapshotInfo, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.policy.MinInterval > 0 {
+		existing, err := sm.Snapshots()
+		if err != nil {
+			return nil, err
+		}
+		if n := len(existing); n > 0 && sm.now().UTC().Sub(existing[n-1].CreatedAt) < sm.policy.MinInterval {
+			return nil, nil
+		}
+	}
+	return sm.takeLocked(keys)
+}
+
+// Snapshots lists the stored snapshots, oldest first.
+//
+// Returns:
+//   - []SnapshotInfo: The stored snapshots
+//   - error: Any error listing or reading the snapshots
+func (sm *SnapshotManager) Snapshots() ([]SnapshotInfo, error) {
+	ids, err := sm.store.List(SnapshotPrefix + "*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	snapshots := make([]SnapshotInfo, 0, len(ids))
+	for _, id := range ids {
+		id = filepathToKey(id)
+		var record snapshotRecord
+		if err := sm.store.Load(id, &record); err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+		}
+		snapshots = append(snapshots, record.info(id))
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+		}
+		return snapshots[i].ID < snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// Restore verifies a snapshot and writes every key it captured back to the
+// store in one batch, so either all keys are restored or none are.
+//
+// Parameters:
+//   - id: The snapshot ID as returned by Snapshots
+//
+// Returns:
+//   - []string: The keys that were restored, in lexical order
+//   - error: An unknown or corrupt snapshot, or any error writing the keys
+func (sm *SnapshotManager) Restore(id string) ([]string, error) {
+	record, err := sm.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]interface{}, len(record.Entries))
+	for key, entry := range record.Entries {
+		var value yaml.Node
+		if err := yaml.Unmarshal([]byte(entry.Data), &value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %s: %v", ErrSnapshotCorrupt, id, key, err)
+		}
+		entries[key] = &value
+	}
+	if err := sm.store.SaveBatch(entries); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot %s: %w", id, err)
+	}
+
+	keys := sortedKeys(record.Entries)
+	logrus.WithFields(logrus.Fields{
+		"function": "Restore",
+		"snapshot": id,
+		"keys":     keys,
+	}).Info("snapshot restored")
+
+	return keys, nil
+}
+
+// Open verifies a snapshot and decodes the captured keys without restoring
+// them, so callers can check a snapshot is usable before it replaces the
+// live values.
+//
+// Parameters:
+//   - id: The snapshot ID as returned by Snapshots
+//   - target: Returns a pointer to decode a key into, or nil to skip it
+//
+// Returns:
+//   - []string: The keys the snapshot captured, in lexical order
+//   - error: An unknown or corrupt snapshot, or contents a target cannot hold
+func (sm *SnapshotManager) Open(id string, target func(key string) interface{}) ([]string, error) {
+	record, err := sm.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := sortedKeys(record.Entries)
+	for _, key := range keys {
+		data := target(key)
+		if data == nil {
+			continue
+		}
+		if err := yaml.Unmarshal([]byte(record.Entries[key].Data), data); err != nil {
+			return nil, fmt.Errorf("failed to decode %s of snapshot %s: %w", key, id, err)
+		}
+	}
+	return keys, nil
+}
+
+// Verify checks every stored snapshot against its recorded checksums.
+//
+// Returns:
+//   - int: The number of snapshots checked
+//   - error: Nil if every snapshot is intact, otherwise the joined errors
+//     of each corrupt or unreadable snapshot (matching ErrSnapshotCorrupt)
+func (sm *SnapshotManager) Verify() (int, error) {
+	ids, err := sm.store.List(SnapshotPrefix + "*.yaml")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if _, err := sm.readVerified(filepathToKey(id)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(ids), errors.Join(errs...)
+}
+
+// Compact thins out snapshots older than the policy's CompactAfter so that
+// at least CompactSpacing separates the ones kept, letting a bounded number
+// of snapshots cover a longer history. Recent snapshots and the newest
+// snapshot are never removed.
+//
+// Returns:
+//   - int: The number of snapshots removed
+//   - error: Any error listing or removing snapshots
+func (sm *SnapshotManager) Compact() (int, error) {
+	if sm.policy.CompactAfter <= 0 || sm.policy.CompactSpacing <= 0 {
+		return 0, nil
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	snapshots, err := sm.Snapshots()
+	if err != nil || len(snapshots) < 2 {
+		return 0, err
+	}
+
+	now := sm.now().UTC()
+	removed := 0
+	var kept time.Time
+	for _, snapshot := range snapshots[:len(snapshots)-1] {
+		if now.Sub(snapshot.CreatedAt) < sm.policy.CompactAfter {
+			break
+		}
+		if !kept.IsZero() && snapshot.CreatedAt.Sub(kept) < sm.policy.CompactSpacing {
+			if err := sm.store.Delete(snapshot.ID); err != nil {
+				return removed, fmt.Errorf("failed to remove snapshot %s: %w", snapshot.ID, err)
+			}
+			removed++
+			continue
+		}
+		kept = snapshot.CreatedAt
+	}
+
+	if removed > 0 {
+		logrus.WithFields(logrus.Fields{
+			"function": "Compact",
+			"removed":  removed,
+		}).Info("snapshots compacted")
+	}
+	return removed, nil
+}
+
+// takeLocked captures keys into a new snapshot and rotates old ones out.
+// The caller must hold sm.mu.
+func (sm *SnapshotManager) takeLocked(keys []string) (*SnapshotInfo, error) {
+	now := sm.now().UTC()
+	record := snapshotRecord{
+		CreatedAt: now,
+		Entries:   make(map[string]snapshotEntry, len(keys)),
+	}
+	for _, key := range keys {
+		if !sm.store.Exists(key) {
+			continue
+		}
+		var value yaml.Node
+		if err := sm.store.Load(key, &value); err != nil {
+			return nil, fmt.Errorf("failed to read %s for snapshot: %w", key, err)
+		}
+		data, err := yaml.Marshal(&value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s for snapshot: %w", key, err)
+		}
+		record.Entries[key] = snapshotEntry{Checksum: checksum(string(data)), Data: string(data)}
+	}
+	if len(record.Entries) == 0 {
+		return nil, fmt.Errorf("nothing to snapshot: none of %v is stored", keys)
+	}
+	record.Checksum = record.entriesChecksum()
+
+	id := SnapshotPrefix + now.Format(backupTimeFormat) + ".yaml"
+	if err := sm.store.Save(id, &record); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "Take",
+		"snapshot": id,
+		"keys":     len(record.Entries),
+	}).Debug("snapshot taken")
+
+	if err := sm.rotateLocked(); err != nil {
+		return nil, err
+	}
+	info := record.info(id)
+	return &info, nil
+}
+
+// rotateLocked deletes the oldest snapshots beyond MaxCount. The caller
+// must hold sm.mu.
+func (sm *SnapshotManager) rotateLocked() error {
+	if sm.policy.MaxCount <= 0 {
+		return nil
+	}
+	snapshots, err := sm.Snapshots()
+	if err != nil {
+		return err
+	}
+	for len(snapshots) > sm.policy.MaxCount {
+		if err := sm.store.Delete(snapshots[0].ID); err != nil {
+			return fmt.Errorf("failed to remove old snapshot %s: %w", snapshots[0].ID, err)
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// lookup checks id names a stored snapshot and returns its verified record.
+func (sm *SnapshotManager) lookup(id string) (*snapshotRecord, error) {
+	if !strings.HasPrefix(id, SnapshotPrefix) || path.Clean(id) != id || !sm.store.Exists(id) {
+		return nil, fmt.Errorf("snapshot does not exist: %s", id)
+	}
+	return sm.readVerified(id)
+}
+
+// readVerified loads a snapshot record and checks its checksums.
+func (sm *SnapshotManager) readVerified(id string) (*snapshotRecord, error) {
+	var record snapshotRecord
+	if err := sm.store.Load(id, &record); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrSnapshotCorrupt, id, err)
+	}
+	if len(record.Entries) == 0 || record.entriesChecksum() != record.Checksum {
+		return nil, fmt.Errorf("%w: %s: checksum mismatch", ErrSnapshotCorrupt, id)
+	}
+	for key, entry := range record.Entries {
+		if checksum(entry.Data) != entry.Checksum {
+			return nil, fmt.Errorf("%w: %s: checksum mismatch for %s", ErrSnapshotCorrupt, id, key)
+		}
+	}
+	return &record, nil
+}
+
+// entriesChecksum returns the checksum over the record's keys and their
+// entry checksums, so adding, dropping or renaming an entry is detected.
+func (r *snapshotRecord) entriesChecksum() string {
+	var b strings.Builder
+	for _, key := range sortedKeys(r.Entries) {
+		b.WriteString(key)
+		b.WriteByte('\n')
+		b.WriteString(r.Entries[key].Checksum)
+		b.WriteByte('\n')
+	}
+	return checksum(b.String())
+}
+
+// info describes the record stored under id.
+func (r *snapshotRecord) info(id string) SnapshotInfo {
+	info := SnapshotInfo{
+		ID:        id,
+		CreatedAt: r.CreatedAt,
+		Keys:      sortedKeys(r.Entries),
+		Checksum:  r.Checksum,
+	}
+	for _, entry := range r.Entries {
+		info.Size += len(entry.Data)
+	}
+	return info
+}
@@ -0,0 +1,188 @@
+package persistence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSnapshotTestManager returns a SnapshotManager whose clock is moved by
+// the returned function.
+func newSnapshotTestManager(store Store, policy SnapshotPolicy) (*SnapshotManager, func(time.Duration)) {
+	sm := NewSnapshotManager(store, policy)
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sm.now = func() time.Time { return clock }
+	return sm, func(d time.Duration) { clock = clock.Add(d) }
+}
+
+func TestSnapshotManager_RotatesAndRestores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"file": func(t *testing.T) Store {
+			fs, err := NewFileStore(t.TempDir())
+			require.NoError(t, err)
+			return fs
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			sm, advance := newSnapshotTestManager(store, SnapshotPolicy{MaxCount: 2})
+			keys := []string{"gamestate.yaml", "pcg_state.yaml"}
+
+			for version := 1; version <= 3; version++ {
+				require.NoError(t, store.SaveBatch(map[string]interface{}{
+					"gamestate.yaml": backupTestState{Version: version, Name: "world"},
+					"pcg_state.yaml": backupTestState{Version: version, Name: "pcg"},
+				}))
+				info, err := sm.Take(keys)
+				require.NoError(t, err)
+				assert.Equal(t, keys, info.Keys)
+				advance(time.Minute)
+			}
+
+			snapshots, err := sm.Snapshots()
+			require.NoError(t, err)
+			require.Len(t, snapshots, 2, "only the newest MaxCount snapshots are kept")
+			assert.True(t, snapshots[0].CreatedAt.Before(snapshots[1].CreatedAt))
+
+			require.NoError(t, store.Save("gamestate.yaml", backupTestState{Version: 9}))
+
+			// Opening decodes the chosen keys and leaves the store alone
+			var opened backupTestState
+			captured, err := sm.Open(snapshots[0].ID, func(key string) interface{} {
+				if key == "gamestate.yaml" {
+					return &opened
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, keys, captured)
+			assert.Equal(t, 2, opened.Version)
+			var current backupTestState
+			require.NoError(t, store.Load("gamestate.yaml", &current))
+			assert.Equal(t, 9, current.Version)
+
+			restored, err := sm.Restore(snapshots[0].ID)
+			require.NoError(t, err)
+			assert.Equal(t, keys, restored)
+
+			// Both documents are back at version 2
+			for _, key := range keys {
+				var state backupTestState
+				require.NoError(t, store.Load(key, &state))
+				assert.Equal(t, 2, state.Version, key)
+			}
+
+			checked, err := sm.Verify()
+			assert.NoError(t, err)
+			assert.Equal(t, 2, checked)
+		})
+	}
+}
+
+func TestSnapshotManager_AutoSnapshot(t *testing.T) {
+	store := NewMemoryStore()
+	sm, advance := newSnapshotTestManager(store, SnapshotPolicy{MinInterval: 10 * time.Minute})
+	require.NoError(t, store.Save("gamestate.yaml", backupTestState{Version: 1}))
+
+	taken := 0
+	for i := 0; i < 6; i++ {
+		info, err := sm.AutoSnapshot([]string{"gamestate.yaml", "missing.yaml"})
+		require.NoError(t, err)
+		if info != nil {
+			taken++
+			assert.Equal(t, []string{"gamestate.yaml"}, info.Keys, "keys that are not stored are left out")
+		}
+		advance(4 * time.Minute)
+	}
+	assert.Equal(t, 2, taken, "snapshots are only taken every MinInterval")
+
+	_, err := sm.Take([]string{"missing.yaml"})
+	assert.Error(t, err, "a snapshot of nothing is refused")
+}
+
+func TestSnapshotManager_Compact(t *testing.T) {
+	store := NewMemoryStore()
+	sm, advance := newSnapshotTestManager(store, SnapshotPolicy{CompactAfter: time.Hour, CompactSpacing: 30 * time.Minute})
+	require.NoError(t, store.Save("gamestate.yaml", backupTestState{Version: 1}))
+
+	// Twelve snapshots ten minutes apart, spanning two hours
+	for i := 0; i < 12; i++ {
+		_, err := sm.Take([]string{"gamestate.yaml"})
+		require.NoError(t, err)
+		advance(10 * time.Minute)
+	}
+
+	removed, err := sm.Compact()
+	require.NoError(t, err)
+
+	snapshots, err := sm.Snapshots()
+	require.NoError(t, err)
+	assert.Equal(t, 12-removed, len(snapshots))
+
+	// The snapshots of the last hour are untouched, older ones are spaced
+	// at least CompactSpacing apart
+	now := sm.now()
+	var old []SnapshotInfo
+	recent := 0
+	for _, snapshot := range snapshots {
+		if now.Sub(snapshot.CreatedAt) < time.Hour {
+			recent++
+		} else {
+			old = append(old, snapshot)
+		}
+	}
+	assert.Equal(t, 5, recent)
+	require.Len(t, old, 3)
+	for i := 1; i < len(old); i++ {
+		assert.GreaterOrEqual(t, old[i].CreatedAt.Sub(old[i-1].CreatedAt), 30*time.Minute)
+	}
+
+	removed, err = sm.Compact()
+	require.NoError(t, err)
+	assert.Zero(t, removed, "compaction is idempotent")
+}
+
+func TestSnapshotManager_DetectsCorruption(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := NewFileStore(dir)
+	require.NoError(t, err)
+	sm, _ := newSnapshotTestManager(fs, SnapshotPolicy{})
+
+	require.NoError(t, fs.Save("gamestate.yaml", backupTestState{Version: 1, Name: "original"}))
+	info, err := sm.Take([]string{"gamestate.yaml"})
+	require.NoError(t, err)
+	require.NoError(t, fs.Save("gamestate.yaml", backupTestState{Version: 2, Name: "original"}))
+
+	// Tamper with the captured data while leaving the YAML well formed
+	snapshotPath := filepath.Join(dir, filepath.FromSlash(info.ID))
+	contents, err := os.ReadFile(snapshotPath)
+	require.NoError(t, err)
+	tampered := strings.Replace(string(contents), "version: 1", "version: 7", 1)
+	require.NotEqual(t, string(contents), tampered)
+	require.NoError(t, os.WriteFile(snapshotPath, []byte(tampered), 0o644))
+
+	checked, err := sm.Verify()
+	assert.Equal(t, 1, checked)
+	assert.True(t, errors.Is(err, ErrSnapshotCorrupt))
+
+	_, err = sm.Restore(info.ID)
+	assert.True(t, errors.Is(err, ErrSnapshotCorrupt))
+
+	var current backupTestState
+	require.NoError(t, fs.Load("gamestate.yaml", &current))
+	assert.Equal(t, 2, current.Version, "a corrupt snapshot is never restored")
+
+	_, err = sm.Restore("gamestate.yaml")
+	assert.Error(t, err, "only snapshot IDs can be restored")
+	_, err = sm.Restore(SnapshotPrefix + "../gamestate.yaml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,296 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/tracing"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultSQLiteDriver is the database/sql driver name used when none is
+	// configured. It matches the name registered by modernc.org/sqlite;
+	// github.com/mattn/go-sqlite3 registers "sqlite3".
+	DefaultSQLiteDriver = "sqlite"
+
+	// DefaultSQLiteFile is the database file created inside the data
+	// directory when no DSN is configured.
+	DefaultSQLiteFile = "gamestate.db"
+)
+
+// sqliteMigrations are applied in order; the index plus one is the schema
+// version recorded in schema_migrations. Append new migrations, never edit
+// existing ones.
+var sqliteMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS documents (
+		key        TEXT PRIMARY KEY,
+		data       BLOB NOT NULL,
+		updated_at TEXT NOT NULL
+	)`,
+}
+
+// SQLiteStore persists YAML-encoded values in a SQLite database. Unlike
+// FileStore it is safe to share between processes: SQLite serializes
+// writers itself, and SaveBatch writes every entry in one transaction.
+//
+// SQLiteStore only depends on database/sql. The program embedding it must
+// link a SQLite driver, for example:
+//
+//	import _ "modernc.org/sqlite"
+type SQLiteStore struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// OpenSQLiteStore opens a SQLite database and brings its schema up to date.
+//
+// Parameters:
+//   - driverName: Registered database/sql driver name (e.g. "sqlite")
+//   - dsn: Driver-specific data source name, usually a file path
+//
+// Returns:
+//   - *SQLiteStore: A ready-to-use store
+//   - error: Unknown driver, connection or migration failure
+func OpenSQLiteStore(driverName, dsn string) (*SQLiteStore, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "OpenSQLiteStore",
+		"driver":   driverName,
+		"dsn":      dsn,
+	}).Info("opening sqlite store")
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database (is the %q driver linked?): %w", driverName, err)
+	}
+
+	store, err := NewSQLiteStore(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// NewSQLiteStore wraps an already opened database and applies any pending
+// schema migrations. The store takes ownership of db and closes it in Close.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	// SQLite allows a single writer; one connection avoids SQLITE_BUSY
+	// errors between goroutines of this process.
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db}
+	if err := store.migrate(context.Background()); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// SchemaVersion returns the schema version currently applied to the database.
+func (ss *SQLiteStore) SchemaVersion() (int, error) {
+	var version sql.NullInt64
+	err := ss.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// migrate creates the migrations table and applies every migration newer
+// than the recorded schema version, each in its own transaction.
+func (ss *SQLiteStore) migrate(ctx context.Context) error {
+	if _, err := ss.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	current, err := ss.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", current, len(sqliteMigrations))
+	}
+
+	for i := current; i < len(sqliteMigrations); i++ {
+		version := i + 1
+		err = ss.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+				version, time.Now().UTC().Format(time.RFC3339))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply schema migration %d: %w", version, err)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"function": "migrate",
+			"version":  version,
+		}).Info("applied sqlite schema migration")
+	}
+
+	return nil
+}
+
+// inTx runs fn inside a transaction, committing on success and rolling
+// back on error.
+func (ss *SQLiteStore) inTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logrus.WithError(rbErr).Error("failed to roll back sqlite transaction")
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// upsert writes one document within tx.
+func upsert(ctx context.Context, tx *sql.Tx, key string, data []byte, now string) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO documents (key, data, updated_at) VALUES (?, ?, ?)
+		 ON CONFLICT(key) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		key, data, now)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// Save serializes an object to YAML and stores it under key.
+func (ss *SQLiteStore) Save(key string, data interface{}) error {
+	return ss.SaveBatch(map[string]interface{}{key: data})
+}
+
+// SaveBatch serializes every entry and writes them in a single transaction.
+func (ss *SQLiteStore) SaveBatch(entries map[string]interface{}) (err error) {
+	span := startWriteSpan("save_batch", BackendSQLite, len(entries))
+	defer func() { tracing.End(span, err) }()
+
+	encoded := make(map[string][]byte, len(entries))
+	for key, data := range entries {
+		yamlData, err := yaml.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s to YAML: %w", key, err)
+		}
+		encoded[key] = yamlData
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	err = ss.inTx(ctx, func(tx *sql.Tx) error {
+		for _, key := range sortedKeys(encoded) {
+			if err := upsert(ctx, tx, key, encoded[key], now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "SaveBatch",
+		"entries":  len(encoded),
+	}).Debug("sqlite batch saved successfully")
+
+	return nil
+}
+
+// Load deserializes the value stored under key into data.
+func (ss *SQLiteStore) Load(key string, data interface{}) error {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	var yamlData []byte
+	err := ss.db.QueryRow(`SELECT data FROM documents WHERE key = ?`, key).Scan(&yamlData)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("key does not exist: %s", key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+
+	if err := yaml.Unmarshal(yamlData, data); err != nil {
+		return fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+	return nil
+}
+
+// Exists reports whether a value is stored under key.
+func (ss *SQLiteStore) Exists(key string) bool {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	var found int
+	err := ss.db.QueryRow(`SELECT 1 FROM documents WHERE key = ?`, key).Scan(&found)
+	return err == nil
+}
+
+// Delete removes the value stored under key.
+func (ss *SQLiteStore) Delete(key string) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if _, err := ss.db.Exec(`DELETE FROM documents WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// List returns the keys matching a glob pattern.
+func (ss *SQLiteStore) List(pattern string) ([]string, error) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	rows, err := ss.db.Query(`SELECT key FROM documents`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to list keys: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	return matchKeys(keys, pattern)
+}
+
+// Close closes the underlying database.
+func (ss *SQLiteStore) Close() error {
+	return ss.db.Close()
+}
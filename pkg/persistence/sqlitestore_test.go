@@ -0,0 +1,278 @@
+package persistence
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// fakeSQLiteDriver is a minimal in-memory database/sql driver that
+// understands the statements issued by SQLiteStore. It lets the store's
+// migration and transaction logic be tested without linking a real
+// SQLite driver. Databases are shared by DSN so a store can be reopened.
+type fakeSQLiteDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeSQLiteDB
+}
+
+type fakeSQLiteDB struct {
+	mu         sync.Mutex
+	migrations []int64
+	documents  map[string][]byte
+	failKey    string // upserts of this key fail
+}
+
+var fakeSQLite = &fakeSQLiteDriver{dbs: make(map[string]*fakeSQLiteDB)}
+
+func init() {
+	sql.Register("fakesqlite", fakeSQLite)
+}
+
+func (d *fakeSQLiteDriver) database(dsn string) *fakeSQLiteDB {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db, ok := d.dbs[dsn]
+	if !ok {
+		db = &fakeSQLiteDB{documents: make(map[string][]byte)}
+		d.dbs[dsn] = db
+	}
+	return db
+}
+
+func (d *fakeSQLiteDriver) Open(dsn string) (driver.Conn, error) {
+	return &fakeSQLiteConn{db: d.database(dsn)}, nil
+}
+
+type fakeSQLiteConn struct {
+	db *fakeSQLiteDB
+	tx *fakeSQLiteTx
+}
+
+type fakeSQLiteTx struct {
+	conn       *fakeSQLiteConn
+	migrations []int64
+	writes     map[string][]byte
+}
+
+func (c *fakeSQLiteConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLiteStmt{conn: c, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *fakeSQLiteConn) Close() error { return nil }
+
+func (c *fakeSQLiteConn) Begin() (driver.Tx, error) {
+	c.tx = &fakeSQLiteTx{conn: c, writes: map[string][]byte{}}
+	return c.tx, nil
+}
+
+func (tx *fakeSQLiteTx) Commit() error {
+	db := tx.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.migrations = append(db.migrations, tx.migrations...)
+	for key, data := range tx.writes {
+		db.documents[key] = data
+	}
+	tx.conn.tx = nil
+	return nil
+}
+
+func (tx *fakeSQLiteTx) Rollback() error {
+	tx.conn.tx = nil
+	return nil
+}
+
+type fakeSQLiteStmt struct {
+	conn  *fakeSQLiteConn
+	query string
+}
+
+func (s *fakeSQLiteStmt) Close() error  { return nil }
+func (s *fakeSQLiteStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s *fakeSQLiteStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	tx := s.conn.tx
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE"):
+	case strings.HasPrefix(s.query, "INSERT INTO schema_migrations"):
+		if tx == nil {
+			return nil, errors.New("migration outside transaction")
+		}
+		tx.migrations = append(tx.migrations, args[0].(int64))
+	case strings.HasPrefix(s.query, "INSERT INTO documents"):
+		key := args[0].(string)
+		if tx == nil {
+			return nil, errors.New("document write outside transaction")
+		}
+		if key == db.failKey {
+			return nil, fmt.Errorf("disk I/O error writing %s", key)
+		}
+		tx.writes[key] = args[1].([]byte)
+	case strings.HasPrefix(s.query, "DELETE FROM documents"):
+		db.mu.Lock()
+		delete(db.documents, args[0].(string))
+		db.mu.Unlock()
+	default:
+		return nil, fmt.Errorf("unsupported statement: %s", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeSQLiteStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	rows := &fakeSQLiteRows{}
+	switch {
+	case strings.HasPrefix(s.query, "SELECT MAX(version)"):
+		rows.columns = []string{"version"}
+		var max interface{}
+		for _, v := range db.migrations {
+			if max == nil || v > max.(int64) {
+				max = v
+			}
+		}
+		rows.values = [][]driver.Value{{max}}
+	case strings.HasPrefix(s.query, "SELECT data FROM documents"):
+		rows.columns = []string{"data"}
+		if data, ok := db.documents[args[0].(string)]; ok {
+			rows.values = [][]driver.Value{{data}}
+		}
+	case strings.HasPrefix(s.query, "SELECT 1 FROM documents"):
+		rows.columns = []string{"1"}
+		if _, ok := db.documents[args[0].(string)]; ok {
+			rows.values = [][]driver.Value{{int64(1)}}
+		}
+	case strings.HasPrefix(s.query, "SELECT key FROM documents"):
+		rows.columns = []string{"key"}
+		for key := range db.documents {
+			rows.values = append(rows.values, []driver.Value{key})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported query: %s", s.query)
+	}
+	return rows, nil
+}
+
+type fakeSQLiteRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeSQLiteRows) Columns() []string { return r.columns }
+func (r *fakeSQLiteRows) Close() error      { return nil }
+
+func (r *fakeSQLiteRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestSQLiteStore_Migrations(t *testing.T) {
+	store, err := OpenSQLiteStore("fakesqlite", t.Name())
+	require.NoError(t, err)
+
+	version, err := store.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, len(sqliteMigrations), version)
+	require.NoError(t, store.Close())
+
+	// Reopening does not reapply migrations
+	store, err = OpenSQLiteStore("fakesqlite", t.Name())
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Len(t, fakeSQLite.database(t.Name()).migrations, len(sqliteMigrations))
+
+	// A database from a newer release is refused
+	db := fakeSQLite.database(t.Name())
+	db.migrations = append(db.migrations, int64(len(sqliteMigrations)+1))
+	_, err = OpenSQLiteStore("fakesqlite", t.Name())
+	assert.Error(t, err)
+}
+
+func TestSQLiteStore_SaveBatchIsTransactional(t *testing.T) {
+	store, err := OpenSQLiteStore("fakesqlite", t.Name())
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.Save("gamestate.yaml", storeTestData{Name: "v1", Value: 1}))
+
+	fakeSQLite.database(t.Name()).failKey = "sessions.yaml"
+	err = store.SaveBatch(map[string]interface{}{
+		"gamestate.yaml": storeTestData{Name: "v2", Value: 2},
+		"pcg_state.yaml": storeTestData{Name: "seed", Value: 42},
+		"sessions.yaml":  storeTestData{Name: "sessions"},
+	})
+	require.Error(t, err)
+
+	var loaded storeTestData
+	require.NoError(t, store.Load("gamestate.yaml", &loaded))
+	assert.Equal(t, "v1", loaded.Name, "failed batches leave earlier values intact")
+	assert.False(t, store.Exists("pcg_state.yaml"))
+}
+
+// TestSQLiteStore_RealDriver runs the store against the driver the server
+// binaries link, through the backend selection of OpenStore.
+func TestSQLiteStore_RealDriver(t *testing.T) {
+	dataDir := t.TempDir()
+	opened, err := OpenStore(StoreOptions{Backend: BackendSQLite, DataDir: dataDir})
+	require.NoError(t, err)
+	store := opened.(*SQLiteStore)
+
+	version, err := store.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, len(sqliteMigrations), version)
+
+	require.NoError(t, store.Save("gamestate.yaml", storeTestData{Name: "v1", Value: 1}))
+	require.NoError(t, store.Save("gamestate.yaml", storeTestData{Name: "v2", Value: 2}), "saving again replaces the value")
+	require.NoError(t, store.SaveBatch(map[string]interface{}{
+		"pcg_state.yaml":         storeTestData{Name: "seed", Value: 42},
+		"combat_logs/a/1.yaml":   storeTestData{Name: "log"},
+		"combat_logs/b/2.yaml":   storeTestData{Name: "log"},
+		"world_events.yaml":      storeTestData{Name: "events"},
+		"campaign_stats.yaml":    storeTestData{Name: "stats"},
+		"snapshots/20250101.yml": storeTestData{Name: "snapshot"},
+	}))
+	require.NoError(t, store.Delete("world_events.yaml"))
+	assert.False(t, store.Exists("world_events.yaml"))
+
+	keys, err := store.List("combat_logs/*/*.yaml")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"combat_logs/a/1.yaml", "combat_logs/b/2.yaml"}, keys)
+	require.NoError(t, store.Close())
+
+	// The database file keeps the values and schema across reopening
+	store, err = OpenSQLiteStore(DefaultSQLiteDriver, filepath.Join(dataDir, DefaultSQLiteFile))
+	require.NoError(t, err)
+	defer store.Close()
+
+	var loaded storeTestData
+	require.NoError(t, store.Load("gamestate.yaml", &loaded))
+	assert.Equal(t, storeTestData{Name: "v2", Value: 2}, loaded)
+	require.NoError(t, store.Load("pcg_state.yaml", &loaded))
+	assert.Equal(t, 42, loaded.Value)
+	assert.Error(t, store.Load("world_events.yaml", &loaded))
+	version, err = store.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, len(sqliteMigrations), version, "reopening does not reapply migrations")
+}
+
+func TestOpenSQLiteStore_UnknownDriver(t *testing.T) {
+	_, err := OpenSQLiteStore("no-such-driver", ":memory:")
+	assert.Error(t, err)
+}
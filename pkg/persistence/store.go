@@ -0,0 +1,154 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"goldbox-rpg/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Supported persistence backend names.
+const (
+	BackendFile   = "file"
+	BackendSQLite = "sqlite"
+	BackendMemory = "memory"
+)
+
+// Store is the interface implemented by all persistence backends.
+// Keys are slash-separated names such as "gamestate.yaml" or
+// "characters/hero.yaml"; values are serialized with their yaml tags.
+type Store interface {
+	// Save serializes data and stores it under key, replacing any previous value.
+	Save(key string, data interface{}) error
+
+	// Load deserializes the value stored under key into data.
+	Load(key string, data interface{}) error
+
+	// Exists reports whether a value is stored under key.
+	Exists(key string) bool
+
+	// Delete removes the value stored under key. Deleting a missing key is not an error.
+	Delete(key string) error
+
+	// List returns the keys matching a glob pattern (see path/filepath.Match).
+	List(pattern string) ([]string, error)
+
+	// SaveBatch stores several values at once. Either all values are
+	// written or, if any value fails to serialize or store, none are.
+	SaveBatch(entries map[string]interface{}) error
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+var (
+	_ Store = (*FileStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*SQLiteStore)(nil)
+)
+
+// StoreOptions selects and configures a persistence backend.
+type StoreOptions struct {
+	// Backend is one of BackendFile, BackendSQLite or BackendMemory.
+	// An empty value selects BackendFile.
+	Backend string
+
+	// DataDir is the directory used by the file backend and, unless
+	// SQLiteDSN is set, the location of the SQLite database file.
+	DataDir string
+
+	// SQLiteDriver is the database/sql driver name the SQLite backend
+	// opens (DefaultSQLiteDriver when empty).
+	SQLiteDriver string
+
+	// SQLiteDSN is the data source name passed to the driver
+	// (DataDir/gamestate.db when empty).
+	SQLiteDSN string
+
+	// Sealer signs, and optionally encrypts, the files of the file backend
+	// (nil stores plain YAML). Other backends do not support sealing.
+	Sealer *Sealer
+}
+
+// OpenStore creates the persistence backend described by opts.
+//
+// Parameters:
+//   - opts: Backend selection and backend-specific settings
+//
+// Returns:
+//   - Store: The opened store
+//   - error: An unknown backend or any error opening it
+func OpenStore(opts StoreOptions) (Store, error) {
+	if opts.Sealer != nil && opts.Backend != "" && opts.Backend != BackendFile {
+		return nil, fmt.Errorf("sealing is not supported by the %s backend", opts.Backend)
+	}
+
+	switch opts.Backend {
+	case "", BackendFile:
+		store, err := NewFileStore(opts.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		store.SetSealer(opts.Sealer)
+		return store, nil
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendSQLite:
+		driver := opts.SQLiteDriver
+		if driver == "" {
+			driver = DefaultSQLiteDriver
+		}
+		dsn := opts.SQLiteDSN
+		if dsn == "" {
+			if err := os.MkdirAll(opts.DataDir, 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create data directory: %w", err)
+			}
+			dsn = filepath.Join(opts.DataDir, DefaultSQLiteFile)
+		}
+		return OpenSQLiteStore(driver, dsn)
+	default:
+		return nil, fmt.Errorf("unknown persistence backend: %s", opts.Backend)
+	}
+}
+
+// sortedKeys returns the keys of m in lexical order so batch writes
+// happen in a deterministic order.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matchKeys returns the keys matching a glob pattern in lexical order.
+func matchKeys(keys []string, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+	matches := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if ok, _ := filepath.Match(pattern, key); ok {
+			matches = append(matches, key)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// startWriteSpan starts the tracing span of a write of count values to the
+// backend store; end it with tracing.End.
+func startWriteSpan(operation, backend string, count int) trace.Span {
+	_, span := tracing.Start(context.Background(), "persistence."+operation,
+		attribute.String("persistence.backend", backend),
+		attribute.Int("persistence.entries", count),
+	)
+	return span
+}
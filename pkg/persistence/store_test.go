@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storeTestData struct {
+	Name  string `yaml:"name"`
+	Value int    `yaml:"value"`
+}
+
+// failingMarshaler always fails YAML serialization
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalYAML() (interface{}, error) {
+	return nil, errors.New("cannot marshal")
+}
+
+// TestStoreBackends runs the same behaviour checks against every backend.
+func TestStoreBackends(t *testing.T) {
+	backends := map[string]func(t *testing.T) Store{
+		BackendFile: func(t *testing.T) Store {
+			store, err := OpenStore(StoreOptions{Backend: BackendFile, DataDir: t.TempDir()})
+			require.NoError(t, err)
+			return store
+		},
+		BackendMemory: func(t *testing.T) Store {
+			store, err := OpenStore(StoreOptions{Backend: BackendMemory})
+			require.NoError(t, err)
+			return store
+		},
+		BackendSQLite: func(t *testing.T) Store {
+			store, err := OpenStore(StoreOptions{Backend: BackendSQLite, SQLiteDriver: "fakesqlite", SQLiteDSN: t.Name()})
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			require.NoError(t, store.Save("gamestate.yaml", storeTestData{Name: "world", Value: 1}))
+			assert.True(t, store.Exists("gamestate.yaml"))
+			assert.False(t, store.Exists("missing.yaml"))
+
+			var loaded storeTestData
+			require.NoError(t, store.Load("gamestate.yaml", &loaded))
+			assert.Equal(t, storeTestData{Name: "world", Value: 1}, loaded)
+			assert.Error(t, store.Load("missing.yaml", &loaded))
+
+			require.NoError(t, store.SaveBatch(map[string]interface{}{
+				"gamestate.yaml":   storeTestData{Name: "world", Value: 2},
+				"saves/slot1.yaml": storeTestData{Name: "slot", Value: 1},
+				"saves/slot2.yaml": storeTestData{Name: "slot", Value: 2},
+				"pcg_state.yaml":   storeTestData{Name: "seed", Value: 42},
+			}))
+			require.NoError(t, store.Load("gamestate.yaml", &loaded))
+			assert.Equal(t, 2, loaded.Value)
+
+			keys, err := store.List("saves/*.yaml")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"saves/slot1.yaml", "saves/slot2.yaml"}, keys)
+
+			require.NoError(t, store.Delete("saves/slot1.yaml"))
+			require.NoError(t, store.Delete("saves/slot1.yaml"), "deleting a missing key is not an error")
+			assert.False(t, store.Exists("saves/slot1.yaml"))
+		})
+	}
+}
+
+func TestMemoryStore_SaveBatchMarshalFailure(t *testing.T) {
+	store := NewMemoryStore()
+	err := store.SaveBatch(map[string]interface{}{
+		"ok.yaml":  storeTestData{Name: "ok"},
+		"bad.yaml": failingMarshaler{},
+	})
+	assert.Error(t, err)
+	assert.False(t, store.Exists("ok.yaml"), "nothing is stored when any entry fails")
+}
+
+func TestOpenStore_UnknownBackend(t *testing.T) {
+	_, err := OpenStore(StoreOptions{Backend: "postgres"})
+	assert.Error(t, err)
+}
@@ -9,7 +9,10 @@ import (
 	"sync"
 	"time"
 
+	"goldbox-rpg/pkg/tracing"
+
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func init() {
@@ -78,6 +81,7 @@ type CircuitBreaker struct {
 	failures    int
 	requests    int
 	lastFailure time.Time
+	violations  int
 	logger      *logrus.Entry
 }
 
@@ -101,10 +105,23 @@ func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
 // ErrCircuitBreakerOpen is returned when the circuit breaker is open
 var ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
 
+// ErrIntegrityViolation marks data that failed an integrity check, such as
+// a persisted file whose signature does not match. The dependency that
+// produced the data is working, so a breaker counts these errors in its
+// statistics without treating them as failures: retrying cannot repair
+// tampered data and opening the circuit would not protect anything.
+var ErrIntegrityViolation = errors.New("data integrity violation")
+
 // Execute runs the given function with circuit breaker protection.
 // The function is executed synchronously in the calling goroutine for performance.
 // Panics in the wrapped function are recovered and returned as errors.
-func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) error {
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) (err error) {
+	ctx, span := tracing.Start(ctx, "circuit_breaker.execute",
+		attribute.String("circuit_breaker.name", cb.config.Name),
+		attribute.String("circuit_breaker.state", cb.GetState().String()),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	// Check context before attempting execution
 	if err := ctx.Err(); err != nil {
 		cb.afterRequest(err)
@@ -124,7 +141,6 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context)
 	cb.beforeRequest()
 
 	// Execute synchronously with panic recovery
-	var err error
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -189,6 +205,15 @@ func (cb *CircuitBreaker) afterRequest(err error) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	if errors.Is(err, ErrIntegrityViolation) {
+		cb.violations++
+		logrus.WithFields(logrus.Fields{
+			"name":  cb.config.Name,
+			"error": err,
+		}).Error("circuit breaker observed an integrity violation")
+		return
+	}
+
 	if err != nil {
 		cb.onFailure()
 	} else {
@@ -252,14 +277,15 @@ func (cb *CircuitBreaker) GetStats() map[string]interface{} {
 	defer cb.mu.RUnlock()
 
 	return map[string]interface{}{
-		"name":         cb.config.Name,
-		"state":        cb.state.String(),
-		"failures":     cb.failures,
-		"max_failures": cb.config.MaxFailures,
-		"requests":     cb.requests,
-		"max_requests": cb.config.MaxRequests,
-		"last_failure": cb.lastFailure,
-		"timeout":      cb.config.Timeout,
+		"name":                 cb.config.Name,
+		"state":                cb.state.String(),
+		"failures":             cb.failures,
+		"max_failures":         cb.config.MaxFailures,
+		"requests":             cb.requests,
+		"max_requests":         cb.config.MaxRequests,
+		"last_failure":         cb.lastFailure,
+		"timeout":              cb.config.Timeout,
+		"integrity_violations": cb.violations,
 	}
 }
 
@@ -279,4 +305,13 @@ func (cb *CircuitBreaker) Reset() {
 	cb.failures = 0
 	cb.requests = 0
 	cb.lastFailure = time.Time{}
+	cb.violations = 0
+}
+
+// IsFailingFast reports whether the circuit breaker is open and still
+// rejecting requests, before its timeout lets test requests through.
+func (cb *CircuitBreaker) IsFailingFast() bool {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.state == StateOpen && time.Since(cb.lastFailure) <= cb.config.Timeout
 }
@@ -3,6 +3,7 @@ package resilience
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -116,6 +117,33 @@ func TestCircuitBreakerExecuteFailure(t *testing.T) {
 	}
 }
 
+func TestCircuitBreakerIntegrityViolation(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:        "test",
+		MaxFailures: 1,
+		Timeout:     time.Minute,
+		MaxRequests: 1,
+	})
+	tampered := fmt.Errorf("gamestate.yaml: %w", ErrIntegrityViolation)
+
+	for i := 0; i < 3; i++ {
+		err := cb.Execute(context.Background(), func(ctx context.Context) error {
+			return tampered
+		})
+		if !errors.Is(err, ErrIntegrityViolation) {
+			t.Fatalf("Expected the integrity violation to be returned, got %v", err)
+		}
+	}
+
+	if cb.GetState() != StateClosed {
+		t.Errorf("Expected integrity violations to leave the circuit closed, got %s", cb.GetState())
+	}
+	stats := cb.GetStats()
+	if stats["integrity_violations"] != 3 || stats["failures"] != 0 {
+		t.Errorf("Expected 3 violations and no failures, got %v and %v", stats["integrity_violations"], stats["failures"])
+	}
+}
+
 func TestCircuitBreakerHalfOpenTransition(t *testing.T) {
 	config := CircuitBreakerConfig{
 		Name:        "test",
@@ -69,6 +69,25 @@
 //	state := cb.GetState()       // StateClosed, StateOpen, or StateHalfOpen
 //	stats := cb.GetStats()       // Failure counts, request counts, timestamps
 //
+// Errors wrapping ErrIntegrityViolation, such as a tampered save file, are
+// returned unchanged but do not count as failures: the dependency works and
+// failing fast would not repair the data. GetStats reports them as
+// "integrity_violations".
+//
+// # Load Shedding
+//
+// LoadShedder rejects an evenly spread share of requests so a struggling
+// server keeps serving the rest:
+//
+//	shedder := resilience.NewLoadShedder()
+//	shedder.SetRatio(0.25, "degraded") // reject every fourth request
+//	if !shedder.Allow() {
+//	    // respond 503 Service Unavailable
+//	}
+//
+// The server sets the ratio from its component health checks, and
+// IsFailingFast reports the breakers counted as degraded.
+//
 // # Thread Safety
 //
 // All circuit breaker operations are thread-safe via internal mutex protection.
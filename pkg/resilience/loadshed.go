@@ -0,0 +1,86 @@
+package resilience
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoadShedder rejects a configured share of requests so a struggling
+// server keeps serving the rest instead of failing all of them. Rejected
+// requests are spread evenly: with a ratio of 0.25, every fourth request
+// is rejected.
+//
+// The ratio is typically set from health checks, shedding more load the
+// worse the server's health. It is safe for concurrent use.
+type LoadShedder struct {
+	mu       sync.RWMutex
+	ratio    float64
+	reason   string
+	requests atomic.Uint64
+	shed     atomic.Uint64
+}
+
+// NewLoadShedder creates a load shedder that admits every request.
+func NewLoadShedder() *LoadShedder {
+	return &LoadShedder{}
+}
+
+// SetRatio sets the share of requests to reject, clamped to [0, 1], and
+// the reason reported while shedding.
+func (ls *LoadShedder) SetRatio(ratio float64, reason string) {
+	if math.IsNaN(ratio) {
+		ratio = 0
+	}
+	ratio = math.Max(0, math.Min(1, ratio))
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ratio != ls.ratio {
+		logrus.WithFields(logrus.Fields{
+			"function":  "SetRatio",
+			"old_ratio": ls.ratio,
+			"new_ratio": ratio,
+			"reason":    reason,
+		}).Warn("load shedding ratio changed")
+	}
+	ls.ratio = ratio
+	ls.reason = reason
+}
+
+// Ratio returns the share of requests rejected and the reason for it.
+func (ls *LoadShedder) Ratio() (float64, string) {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return ls.ratio, ls.reason
+}
+
+// Allow reports whether a request should be served. It returns false for
+// the share of requests being shed.
+func (ls *LoadShedder) Allow() bool {
+	ratio, _ := ls.Ratio()
+	if ratio <= 0 {
+		return true
+	}
+
+	n := ls.requests.Add(1)
+	if math.Floor(float64(n)*ratio) > math.Floor(float64(n-1)*ratio) {
+		ls.shed.Add(1)
+		return false
+	}
+	return true
+}
+
+// Stats returns the current ratio and the number of requests seen while
+// shedding and rejected.
+func (ls *LoadShedder) Stats() map[string]interface{} {
+	ratio, reason := ls.Ratio()
+	return map[string]interface{}{
+		"ratio":    ratio,
+		"reason":   reason,
+		"requests": ls.requests.Load(),
+		"shed":     ls.shed.Load(),
+	}
+}
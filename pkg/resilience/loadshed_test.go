@@ -0,0 +1,63 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoadShedder_Allow(t *testing.T) {
+	ls := NewLoadShedder()
+	for i := 0; i < 10; i++ {
+		if !ls.Allow() {
+			t.Fatal("a new load shedder should admit every request")
+		}
+	}
+
+	ls.SetRatio(0.25, "degraded")
+	rejected := 0
+	for i := 0; i < 100; i++ {
+		if !ls.Allow() {
+			rejected++
+		}
+	}
+	if rejected != 25 {
+		t.Errorf("rejected %d of 100 requests, want 25", rejected)
+	}
+
+	ls.SetRatio(2, "unhealthy")
+	if ratio, reason := ls.Ratio(); ratio != 1 || reason != "unhealthy" {
+		t.Errorf("Ratio() = %v, %q, want the ratio clamped to 1", ratio, reason)
+	}
+	if ls.Allow() {
+		t.Error("a ratio of 1 should reject every request")
+	}
+
+	stats := ls.Stats()
+	if stats["shed"].(uint64) != 26 || stats["requests"].(uint64) != 101 {
+		t.Errorf("unexpected stats %v", stats)
+	}
+
+	ls.SetRatio(-1, "")
+	if !ls.Allow() {
+		t.Error("a negative ratio should admit every request")
+	}
+}
+
+func TestCircuitBreaker_IsFailingFast(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{Name: "test", MaxFailures: 1, Timeout: 20 * time.Millisecond, MaxRequests: 1})
+	if cb.IsFailingFast() {
+		t.Error("a closed breaker should not fail fast")
+	}
+
+	_ = cb.Execute(context.Background(), func(context.Context) error { return errors.New("boom") })
+	if !cb.IsFailingFast() {
+		t.Error("an open breaker should fail fast")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if cb.IsFailingFast() {
+		t.Error("a breaker past its timeout should let test requests through")
+	}
+}
@@ -64,6 +64,25 @@
 //	    io.ErrUnexpectedEOF,
 //	}
 //
+// # Retry Budget
+//
+// A RetryBudget caps retries at a proportion of the calls made within a
+// sliding window, preventing retry storms when a dependency fails. Share one
+// budget between the retriers calling the same dependency:
+//
+//	config.Budget = retry.NewRetryBudget(0.1, 10*time.Second, 5)
+//
+// A call whose retry the budget refuses fails with ErrRetryBudgetExhausted.
+//
+// # Hedged Attempts
+//
+// For idempotent operations, HedgeDelay starts a second copy of an attempt
+// still running after the delay and uses whichever succeeds first. The
+// other copy is cancelled through its context, and Execute returns once it
+// has exited:
+//
+//	config.HedgeDelay = 200 * time.Millisecond
+//
 // # Context Support
 //
 // Retries respect context cancellation and deadlines:
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -33,6 +34,126 @@ type RetryConfig struct {
 
 	// RetryableErrors are error types that should trigger a retry
 	RetryableErrors []error
+
+	// Budget, when set, limits retries and hedged attempts to a proportion
+	// of the calls made by every Retrier sharing it, so an outage does not
+	// multiply the load on a struggling dependency
+	Budget *RetryBudget
+
+	// HedgeDelay, when above zero, starts a second concurrent attempt if
+	// the first has not finished after this long and uses whichever
+	// succeeds first. The two count as one attempt. Only enable hedging
+	// for idempotent operations that honor context cancellation: Execute
+	// waits for the losing copy to return before it does.
+	HedgeDelay time.Duration
+}
+
+// ErrRetryBudgetExhausted is returned, wrapping the last error, when an
+// operation failed and its RetryBudget allowed no further retry.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// retryBudgetBuckets is the number of buckets a RetryBudget window is
+// divided into; calls expire one bucket at a time.
+const retryBudgetBuckets = 10
+
+// RetryBudget caps retries at a proportion of the calls made within a
+// sliding window. Each Execute counts as a call; each retry or hedged
+// attempt spends from the budget and is refused once retries would exceed
+// ratio times the calls in the window. MinRetries are always allowed per
+// window so that low traffic can still recover from the odd failure.
+//
+// Share one RetryBudget between the retriers calling the same dependency.
+// RetryBudget is safe for concurrent use.
+type RetryBudget struct {
+	ratio      float64
+	minRetries int
+	width      time.Duration // Length of one bucket
+	buckets    [retryBudgetBuckets]retryBudgetBucket
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+// retryBudgetBucket counts the calls and retries of one slice of the window.
+type retryBudgetBucket struct {
+	epoch   int64 // Index of the slice since the Unix epoch
+	calls   int
+	retries int
+}
+
+// NewRetryBudget creates a retry budget.
+//
+// Parameters:
+//   - ratio: The maximum proportion of retries to calls, e.g. 0.1 for 10%
+//   - window: The sliding window calls and retries are counted over;
+//     zero or less uses 10 seconds
+//   - minRetries: Retries allowed per window regardless of ratio
+//
+// Returns:
+//   - *RetryBudget: An empty budget
+func NewRetryBudget(ratio float64, window time.Duration, minRetries int) *RetryBudget {
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+	return &RetryBudget{
+		ratio:      max(ratio, 0),
+		minRetries: max(minRetries, 0),
+		width:      max(window/retryBudgetBuckets, 1),
+		now:        time.Now,
+	}
+}
+
+// Stats returns the calls and retries counted in the current window.
+func (b *RetryBudget) Stats() (calls, retries int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.totals(b.epoch())
+}
+
+// recordCall counts a call made through the budget.
+func (b *RetryBudget) recordCall() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(b.epoch()).calls++
+}
+
+// spend counts a retry if the budget allows one, reporting whether it did.
+func (b *RetryBudget) spend() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	epoch := b.epoch()
+	calls, retries := b.totals(epoch)
+	if retries >= b.minRetries && float64(retries+1) > b.ratio*float64(calls) {
+		return false
+	}
+	b.bucket(epoch).retries++
+	return true
+}
+
+// epoch returns the index of the current bucket-length slice of time.
+func (b *RetryBudget) epoch() int64 {
+	return b.now().UnixNano() / int64(b.width)
+}
+
+// bucket returns the bucket for epoch, clearing it if it last held an
+// older slice.
+func (b *RetryBudget) bucket(epoch int64) *retryBudgetBucket {
+	bucket := &b.buckets[epoch%retryBudgetBuckets]
+	if bucket.epoch != epoch {
+		*bucket = retryBudgetBucket{epoch: epoch}
+	}
+	return bucket
+}
+
+// totals sums the buckets inside the window ending at epoch.
+func (b *RetryBudget) totals(epoch int64) (calls, retries int) {
+	for _, bucket := range b.buckets {
+		if age := epoch - bucket.epoch; age >= 0 && age < retryBudgetBuckets {
+			calls += bucket.calls
+			retries += bucket.retries
+		}
+	}
+	return calls, retries
 }
 
 // DefaultRetryConfig returns a sensible default retry configuration
@@ -120,6 +241,9 @@ func (r *Retrier) ExecuteWithResult(ctx context.Context, operation func(context.
 	}).Debug("entering ExecuteWithResult")
 
 	var lastErr error
+	if r.config.Budget != nil {
+		r.config.Budget.recordCall()
+	}
 
 	for attempt := 1; attempt <= r.config.MaxAttempts; attempt++ {
 		logger := r.createAttemptLogger(attempt)
@@ -170,6 +294,11 @@ func (r *Retrier) ExecuteWithResult(ctx context.Context, operation func(context.
 			break
 		}
 
+		if r.config.Budget != nil && !r.config.Budget.spend() {
+			logger.WithError(lastErr).Warn("Retry budget exhausted, not retrying")
+			return fmt.Errorf("%w after %d attempts: %w", ErrRetryBudgetExhausted, attempt, lastErr)
+		}
+
 		if err := r.waitForRetry(ctx, attempt, logger); err != nil {
 			logrus.WithFields(logrus.Fields{
 				"function": "ExecuteWithResult",
@@ -214,7 +343,7 @@ func (r *Retrier) validateContext(ctx context.Context, logger *logrus.Entry) err
 func (r *Retrier) executeOperation(ctx context.Context, operation func(context.Context) (interface{}, error), logger *logrus.Entry, attempt int, lastErr *error) error {
 	logger.Debug("Executing operation attempt")
 
-	_, err := operation(ctx)
+	err := r.runAttempt(ctx, operation, logger)
 	*lastErr = err
 
 	if err == nil {
@@ -228,6 +357,58 @@ func (r *Retrier) executeOperation(ctx context.Context, operation func(context.C
 	return nil
 }
 
+// runAttempt runs one attempt of the operation. With a HedgeDelay, a second
+// copy is started if the first is still running after the delay and the
+// budget allows it; the first success cancels the other copy, and the
+// attempt fails only once every copy has failed. It returns only after
+// every copy has exited, so a losing copy cannot touch the caller's
+// state once Execute has returned.
+func (r *Retrier) runAttempt(ctx context.Context, operation func(context.Context) (interface{}, error), logger *logrus.Entry) error {
+	if r.config.HedgeDelay <= 0 {
+		_, err := operation(ctx)
+		return err
+	}
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan error, 2)
+	launch := func() {
+		go func() {
+			_, err := operation(hedgeCtx)
+			results <- err
+		}()
+	}
+	launch()
+	running := 1
+
+	timer := time.NewTimer(r.config.HedgeDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case err := <-results:
+			running--
+			if err == nil || running == 0 {
+				cancel()
+				for ; running > 0; running-- {
+					<-results
+				}
+				return err
+			}
+			logger.WithError(err).Debug("Hedged copy failed, waiting for the other")
+		case <-timer.C:
+			if r.config.Budget != nil && !r.config.Budget.spend() {
+				logger.Debug("Retry budget exhausted, not hedging")
+				continue
+			}
+			logger.WithField("hedge_delay", r.config.HedgeDelay).Debug("Starting hedged attempt")
+			launch()
+			running++
+		}
+	}
+}
+
 // shouldStopRetrying determines if retry attempts should stop
 func (r *Retrier) shouldStopRetrying(attempt int, lastErr error, logger *logrus.Entry) bool {
 	if attempt == r.config.MaxAttempts {
@@ -3,6 +3,7 @@ package retry
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -469,3 +470,167 @@ func BenchmarkRetryExecuteWithRetries(b *testing.B) {
 		_ = retrier.Execute(ctx, operation)
 	}
 }
+
+func TestRetryBudget_LimitsRetries(t *testing.T) {
+	budget := NewRetryBudget(0.5, time.Minute, 1)
+	config := RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1, Budget: budget}
+	retrier := NewRetrier(config)
+	failure := errors.New("unavailable")
+
+	// The minimum allows one retry, then the ratio of one call allows none
+	attempts := 0
+	err := retrier.Execute(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return failure
+	})
+	if !errors.Is(err, ErrRetryBudgetExhausted) || !errors.Is(err, failure) {
+		t.Fatalf("Expected budget exhaustion wrapping the failure, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+
+	// Successful calls earn retries back
+	for i := 0; i < 3; i++ {
+		if err := retrier.Execute(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+			t.Fatalf("Expected success, got %v", err)
+		}
+	}
+	attempts = 0
+	retrier.Execute(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return failure
+	})
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts with 5 calls and 1 retry spent, got %d", attempts)
+	}
+
+	calls, retries := budget.Stats()
+	if calls != 5 || retries != 2 {
+		t.Errorf("Expected 5 calls and 2 retries, got %d and %d", calls, retries)
+	}
+}
+
+func TestRetryBudget_WindowExpires(t *testing.T) {
+	now := time.Unix(1000, 0)
+	budget := NewRetryBudget(0.1, 10*time.Second, 0)
+	budget.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		budget.recordCall()
+	}
+	if !budget.spend() {
+		t.Fatal("Expected a retry within 10% of 10 calls")
+	}
+	if budget.spend() {
+		t.Fatal("Expected the budget to be exhausted")
+	}
+
+	now = now.Add(5 * time.Second)
+	budget.recordCall()
+	if calls, retries := budget.Stats(); calls != 11 || retries != 1 {
+		t.Errorf("Expected 11 calls and 1 retry mid-window, got %d and %d", calls, retries)
+	}
+
+	now = now.Add(6 * time.Second)
+	if calls, retries := budget.Stats(); calls != 1 || retries != 0 {
+		t.Errorf("Expected only the newer call after the window, got %d and %d", calls, retries)
+	}
+}
+
+func TestHedgedAttempt_UsesFirstSuccess(t *testing.T) {
+	config := DefaultRetryConfig()
+	config.MaxAttempts = 1
+	config.HedgeDelay = 10 * time.Millisecond
+	retrier := NewRetrier(config)
+
+	var calls int32
+	cancelled := make(chan struct{})
+	start := time.Now()
+	err := retrier.Execute(context.Background(), func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// The first copy stalls until the hedge wins
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the hedged copy to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the hedge to answer promptly, took %v", elapsed)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected the slow copy to be cancelled")
+	}
+}
+
+func TestHedgedAttempt_WaitsForLosingCopy(t *testing.T) {
+	config := DefaultRetryConfig()
+	config.MaxAttempts = 1
+	config.HedgeDelay = 5 * time.Millisecond
+	retrier := NewRetrier(config)
+
+	// Results reach the caller through captured variables, which the
+	// losing copy writes after it is cancelled; run with -race
+	var calls int32
+	var result string
+	finished := 0
+	err := retrier.Execute(context.Background(), func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-ctx.Done()
+			finished++
+			return ctx.Err()
+		}
+		result = "hedge"
+		finished++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the hedged copy to succeed, got %v", err)
+	}
+	if result != "hedge" {
+		t.Errorf("Expected the winning copy's result, got %q", result)
+	}
+	if finished != 2 {
+		t.Errorf("Expected both copies to have exited, got %d", finished)
+	}
+}
+
+func TestHedgedAttempt_FailsWhenBothFail(t *testing.T) {
+	config := DefaultRetryConfig()
+	config.MaxAttempts = 1
+	config.HedgeDelay = 5 * time.Millisecond
+	retrier := NewRetrier(config)
+
+	var calls int32
+	err := retrier.Execute(context.Background(), func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return errors.New("unavailable")
+	})
+	if err == nil {
+		t.Fatal("Expected an error when both copies fail")
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 copies, got %d", calls)
+	}
+
+	// A fast attempt is not hedged, and an empty budget prevents hedging
+	config.Budget = NewRetryBudget(0, time.Minute, 0)
+	retrier = NewRetrier(config)
+	calls = 0
+	retrier.Execute(context.Background(), func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	if calls != 1 {
+		t.Errorf("Expected no hedge without budget, got %d copies", calls)
+	}
+}
@@ -0,0 +1,97 @@
+package scripting
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// builtins are the pure functions every script can call. Host functions
+// with the same name take precedence.
+var builtins = map[string]Func{
+	"len":   builtinLen,
+	"str":   builtinStr,
+	"floor": builtinFloor,
+	"min":   builtinMin,
+	"max":   builtinMax,
+}
+
+// builtinLen returns the length of a string in characters
+func builtinLen(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expects 1 argument, got %d", len(args))
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expects a string, got %s", typeName(args[0]))
+	}
+	return float64(utf8.RuneCountInString(s)), nil
+}
+
+// builtinStr converts a value to a string
+func builtinStr(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expects 1 argument, got %d", len(args))
+	}
+	return toString(args[0]), nil
+}
+
+// builtinFloor rounds a number down
+func builtinFloor(args []Value) (Value, error) {
+	n, err := NumberArg(args, 0)
+	if err != nil || len(args) != 1 {
+		return nil, fmt.Errorf("expects 1 number")
+	}
+	return math.Floor(n), nil
+}
+
+func builtinMin(args []Value) (Value, error) {
+	return fold(args, math.Min)
+}
+
+func builtinMax(args []Value) (Value, error) {
+	return fold(args, math.Max)
+}
+
+// fold combines one or more numbers with f
+func fold(args []Value, f func(a, b float64) float64) (Value, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expects at least 1 number")
+	}
+	result, err := NumberArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(args); i++ {
+		n, err := NumberArg(args, i)
+		if err != nil {
+			return nil, err
+		}
+		result = f(result, n)
+	}
+	return result, nil
+}
+
+// NumberArg returns argument i of a host function call as a number.
+func NumberArg(args []Value, i int) (float64, error) {
+	if i >= len(args) {
+		return 0, fmt.Errorf("missing argument %d", i+1)
+	}
+	n, ok := args[i].(float64)
+	if !ok {
+		return 0, fmt.Errorf("argument %d must be a number, got %s", i+1, typeName(args[i]))
+	}
+	return n, nil
+}
+
+// StringArg returns argument i of a host function call as a string.
+func StringArg(args []Value, i int) (string, error) {
+	if i >= len(args) {
+		return "", fmt.Errorf("missing argument %d", i+1)
+	}
+	s, ok := args[i].(string)
+	if !ok {
+		return "", fmt.Errorf("argument %d must be a string, got %s", i+1, typeName(args[i]))
+	}
+	return s, nil
+}
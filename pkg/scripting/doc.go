@@ -0,0 +1,77 @@
+// Package scripting provides a sandboxed scripting layer for campaign rules.
+//
+// Campaign authors attach small scripts to game event hooks to add custom
+// logic without changing the engine. Scripts are written in a minimal
+// language interpreted by this package; they cannot reach the file system,
+// the network or the Go runtime, only the event being handled and the host
+// functions the server registers.
+//
+// # Hooks
+//
+// Three hooks are supported:
+//
+//   - onQuestComplete: event.player_id, event.quest_id
+//   - onEnterRoom: event.player_id, event.room_id, event.level, event.x, event.y
+//   - onKill: event.victim_id, event.x, event.y
+//
+// # Script Files
+//
+// Scripts are loaded from YAML:
+//
+//	budget:
+//	  max_steps: 10000
+//	  max_duration: 50ms
+//	scripts:
+//	  - name: dragon_bounty
+//	    hook: onKill
+//	    source: |
+//	      if event.victim_id == "red_dragon" {
+//	        set_flag("dragon_slain", true)
+//	      }
+//	  - name: shrine_blessing
+//	    hook: onEnterRoom
+//	    budget:
+//	      max_steps: 500
+//	    source: |
+//	      if event.room_id == "room_3" and not get_flag("blessed") {
+//	        heal(event.player_id, 10)
+//	        set_flag("blessed", true)
+//	      }
+//
+// and fired by the host:
+//
+//	engine := scripting.NewEngine(scripting.DefaultBudget())
+//	engine.RegisterFunc("heal", healFunc)
+//	if err := engine.LoadFromFile("data/scripts/hooks.yaml"); err != nil {
+//	    return err
+//	}
+//	err := engine.Fire(ctx, scripting.HookKill, map[string]scripting.Value{
+//	    "victim_id": "red_dragon",
+//	})
+//
+// # Language
+//
+// Values are nil, booleans, numbers (float64), strings and read-only
+// records such as event. Statements are separated by newlines or
+// semicolons:
+//
+//	let total = event.x + 1     # declare a local variable
+//	total = total * 2           # assign a declared local
+//	if total > 10 { ... } else if total > 5 { ... } else { ... }
+//	while total > 0 { total = total - 1 }
+//	log("total is " + str(total))
+//	return total
+//
+// Operators are + - * / %, == != < <= > >=, and/&&, or/|| and not/!;
+// + concatenates when either side is a string. Built-in functions are
+// len, str, floor, min and max; every engine also provides get_flag and
+// set_flag, whose values are shared by all scripts of the engine.
+//
+// # Budgets
+//
+// Every run is bounded by a Budget: the number of evaluation steps, the
+// wall-clock time and the length of strings it may build. A run that
+// exceeds its budget stops with an error wrapping ErrBudgetExceeded, or
+// context.DeadlineExceeded for the time limit. Effects of host functions
+// called before the failure are kept.
+package scripting
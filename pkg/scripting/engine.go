@@ -0,0 +1,323 @@
+package scripting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Hook names a game event scripts can be attached to.
+type Hook string
+
+// Supported hooks and the event fields scripts receive for them.
+const (
+	// HookQuestComplete runs when a player completes a quest
+	// (event.player_id, event.quest_id)
+	HookQuestComplete Hook = "onQuestComplete"
+
+	// HookEnterRoom runs when a player moves into another room of a level
+	// (event.player_id, event.room_id, event.level, event.x, event.y)
+	HookEnterRoom Hook = "onEnterRoom"
+
+	// HookKill runs when a character dies (event.victim_id, event.x,
+	// event.y)
+	HookKill Hook = "onKill"
+)
+
+// Hooks returns the supported hooks.
+func Hooks() []Hook {
+	return []Hook{HookQuestComplete, HookEnterRoom, HookKill}
+}
+
+// Definition is a script attached to a hook, as written by campaign
+// authors.
+type Definition struct {
+	Name   string  `yaml:"name"`
+	Hook   Hook    `yaml:"hook"`
+	Source string  `yaml:"source"`
+	Budget *Budget `yaml:"budget,omitempty"` // Overrides the engine budget's non-zero fields
+}
+
+// definitionFile is the YAML layout of a script file
+type definitionFile struct {
+	Budget  *Budget      `yaml:"budget,omitempty"`
+	Scripts []Definition `yaml:"scripts"`
+}
+
+// script is a compiled definition
+type script struct {
+	program *Program
+	budget  Budget
+}
+
+// Engine runs the scripts attached to game event hooks. Scripts only see
+// the event being handled, the host functions registered with
+// RegisterFunc, the built-in functions and a set of flags shared by all
+// scripts of the engine; each run is bounded by its budget.
+//
+// Engine is safe for concurrent use.
+type Engine struct {
+	mu      sync.RWMutex
+	budget  Budget
+	funcs   map[string]Func
+	scripts map[Hook][]script
+
+	flagsMu sync.Mutex
+	flags   map[string]Value
+}
+
+// NewEngine creates an engine without scripts.
+//
+// Parameters:
+//   - budget: Default execution budget of each script run
+//
+// Returns:
+//   - *Engine: The engine, with the get_flag and set_flag functions registered
+func NewEngine(budget Budget) *Engine {
+	e := &Engine{
+		budget:  budget,
+		funcs:   make(map[string]Func),
+		scripts: make(map[Hook][]script),
+		flags:   make(map[string]Value),
+	}
+	e.funcs["get_flag"] = e.getFlag
+	e.funcs["set_flag"] = e.setFlag
+	return e
+}
+
+// RegisterFunc makes a host function callable from scripts, replacing any
+// function with the same name.
+func (e *Engine) RegisterFunc(name string, fn Func) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.funcs[name] = fn
+}
+
+// Load compiles definitions and replaces the engine's scripts with them.
+// Nothing is replaced if any definition is invalid.
+//
+// Parameters:
+//   - definitions: Scripts to attach, run in order within each hook
+//
+// Returns:
+//   - error: A duplicate or missing name, an unknown hook, or a syntax error
+func (e *Engine) Load(definitions []Definition) error {
+	e.mu.RLock()
+	budget := e.budget
+	e.mu.RUnlock()
+
+	scripts, err := compileDefinitions(definitions, budget)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.scripts = scripts
+	e.mu.Unlock()
+	return nil
+}
+
+// LoadFromFile reads script definitions from a YAML file and replaces the
+// engine's scripts with them. A budget in the file replaces the engine's
+// default budget.
+//
+// Parameters:
+//   - path: The script file
+//
+// Returns:
+//   - error: The file cannot be read or parsed, or a definition is invalid
+func (e *Engine) LoadFromFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read script file %s: %w", path, err)
+	}
+
+	var file definitionFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse script file %s: %w", path, err)
+	}
+
+	e.mu.RLock()
+	budget := e.budget
+	e.mu.RUnlock()
+	if file.Budget != nil {
+		budget = budget.with(*file.Budget)
+	}
+
+	scripts, err := compileDefinitions(file.Scripts, budget)
+	if err != nil {
+		return fmt.Errorf("invalid script file %s: %w", path, err)
+	}
+
+	e.mu.Lock()
+	e.budget = budget
+	e.scripts = scripts
+	e.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"function": "LoadFromFile",
+		"path":     path,
+		"scripts":  len(file.Scripts),
+	}).Info("loaded scripts")
+	return nil
+}
+
+// Scripts returns the names of the scripts attached to hook, in run order.
+func (e *Engine) Scripts(hook Hook) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	names := make([]string, 0, len(e.scripts[hook]))
+	for _, s := range e.scripts[hook] {
+		names = append(names, s.program.Name())
+	}
+	return names
+}
+
+// Fire runs every script attached to hook with event as its "event"
+// variable. A failing script does not stop the scripts after it.
+//
+// Parameters:
+//   - ctx: Cancels the remaining runs
+//   - hook: The hook that fired
+//   - event: Fields describing the event
+//
+// Returns:
+//   - error: Nil if every script succeeded, otherwise the joined errors of
+//     the scripts that failed
+func (e *Engine) Fire(ctx context.Context, hook Hook, event map[string]Value) error {
+	e.mu.RLock()
+	scripts := e.scripts[hook]
+	funcs := e.funcs
+	e.mu.RUnlock()
+
+	if len(scripts) == 0 {
+		return nil
+	}
+
+	vars := map[string]Value{"event": event}
+	var errs []error
+	for _, s := range scripts {
+		if _, err := s.program.Run(ctx, vars, funcs, s.budget); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "Fire",
+				"hook":     hook,
+				"script":   s.program.Name(),
+			}).WithError(err).Warn("script failed")
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flags returns a copy of the flags set by scripts.
+func (e *Engine) Flags() map[string]Value {
+	e.flagsMu.Lock()
+	defer e.flagsMu.Unlock()
+
+	flags := make(map[string]Value, len(e.flags))
+	for name, value := range e.flags {
+		flags[name] = value
+	}
+	return flags
+}
+
+// getFlag implements get_flag(name): the value of a flag, or nil if unset
+func (e *Engine) getFlag(args []Value) (Value, error) {
+	name, err := StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	e.flagsMu.Lock()
+	defer e.flagsMu.Unlock()
+	return e.flags[name], nil
+}
+
+// setFlag implements set_flag(name, value); a nil value clears the flag
+func (e *Engine) setFlag(args []Value) (Value, error) {
+	name, err := StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expects 2 arguments, got %d", len(args))
+	}
+	switch args[1].(type) {
+	case nil, bool, float64, string:
+	default:
+		return nil, fmt.Errorf("flags hold nil, booleans, numbers or strings, got %s", typeName(args[1]))
+	}
+
+	e.flagsMu.Lock()
+	defer e.flagsMu.Unlock()
+	if args[1] == nil {
+		delete(e.flags, name)
+	} else {
+		e.flags[name] = args[1]
+	}
+	return nil, nil
+}
+
+// compileDefinitions validates and compiles definitions by hook
+func compileDefinitions(definitions []Definition, budget Budget) (map[Hook][]script, error) {
+	valid := make(map[Hook]bool)
+	for _, hook := range Hooks() {
+		valid[hook] = true
+	}
+
+	seen := make(map[string]bool)
+	scripts := make(map[Hook][]script)
+	for i, def := range definitions {
+		if def.Name == "" {
+			return nil, fmt.Errorf("script %d has no name", i+1)
+		}
+		if seen[def.Name] {
+			return nil, fmt.Errorf("duplicate script name %s", def.Name)
+		}
+		seen[def.Name] = true
+		if !valid[def.Hook] {
+			return nil, fmt.Errorf("script %s: unknown hook %q (expected one of %v)", def.Name, def.Hook, sortedHooks())
+		}
+
+		program, err := Compile(def.Name, def.Source)
+		if err != nil {
+			return nil, err
+		}
+		s := script{program: program, budget: budget}
+		if def.Budget != nil {
+			s.budget = budget.with(*def.Budget)
+		}
+		scripts[def.Hook] = append(scripts[def.Hook], s)
+	}
+	return scripts, nil
+}
+
+// with returns the budget with the non-zero fields of override applied
+func (b Budget) with(override Budget) Budget {
+	if override.MaxSteps > 0 {
+		b.MaxSteps = override.MaxSteps
+	}
+	if override.MaxDuration > 0 {
+		b.MaxDuration = override.MaxDuration
+	}
+	if override.MaxStringLength > 0 {
+		b.MaxStringLength = override.MaxStringLength
+	}
+	return b
+}
+
+// sortedHooks returns the hook names in lexical order for messages
+func sortedHooks() []string {
+	names := make([]string, 0, len(Hooks()))
+	for _, hook := range Hooks() {
+		names = append(names, string(hook))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,135 @@
+package scripting
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_FiresHookScriptsInOrder(t *testing.T) {
+	engine := NewEngine(DefaultBudget())
+	var calls []string
+	engine.RegisterFunc("record", func(args []Value) (Value, error) {
+		s, err := StringArg(args, 0)
+		calls = append(calls, s)
+		return nil, err
+	})
+
+	require.NoError(t, engine.Load([]Definition{
+		{Name: "first", Hook: HookKill, Source: `record("first:" + event.victim_id)`},
+		{Name: "quest", Hook: HookQuestComplete, Source: `record("quest")`},
+		{Name: "second", Hook: HookKill, Source: `record("second")`},
+	}))
+
+	assert.Equal(t, []string{"first", "second"}, engine.Scripts(HookKill))
+	assert.Empty(t, engine.Scripts(HookEnterRoom))
+
+	require.NoError(t, engine.Fire(context.Background(), HookKill, map[string]Value{"victim_id": "goblin"}))
+	assert.Equal(t, []string{"first:goblin", "second"}, calls)
+
+	assert.NoError(t, engine.Fire(context.Background(), HookEnterRoom, nil))
+}
+
+func TestEngine_FailingScriptDoesNotStopOthers(t *testing.T) {
+	engine := NewEngine(DefaultBudget())
+	require.NoError(t, engine.Load([]Definition{
+		{Name: "runaway", Hook: HookKill, Source: "while true { }", Budget: &Budget{MaxSteps: 100}},
+		{Name: "flagger", Hook: HookKill, Source: `set_flag("kills", 1)`},
+	}))
+
+	err := engine.Fire(context.Background(), HookKill, map[string]Value{})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Contains(t, err.Error(), "runaway:")
+	assert.Equal(t, map[string]Value{"kills": 1.0}, engine.Flags())
+}
+
+func TestEngine_Flags(t *testing.T) {
+	engine := NewEngine(DefaultBudget())
+	require.NoError(t, engine.Load([]Definition{{
+		Name: "counter",
+		Hook: HookEnterRoom,
+		Source: `
+let visits = get_flag("visits")
+if visits == nil { visits = 0 }
+set_flag("visits", visits + 1)
+if event.room_id == "vault" { set_flag("found_vault", true) }
+`,
+	}}))
+
+	for _, room := range []string{"hall", "vault", "hall"} {
+		require.NoError(t, engine.Fire(context.Background(), HookEnterRoom, map[string]Value{"room_id": room}))
+	}
+	assert.Equal(t, map[string]Value{"visits": 3.0, "found_vault": true}, engine.Flags())
+
+	require.NoError(t, engine.Load([]Definition{{Name: "clear", Hook: HookKill, Source: `set_flag("visits", nil)`}}))
+	require.NoError(t, engine.Fire(context.Background(), HookKill, nil))
+	assert.Equal(t, map[string]Value{"found_vault": true}, engine.Flags())
+}
+
+func TestEngine_LoadRejectsInvalidDefinitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions []Definition
+		message     string
+	}{
+		{"missing name", []Definition{{Hook: HookKill, Source: "return"}}, "script 1 has no name"},
+		{"duplicate name", []Definition{
+			{Name: "a", Hook: HookKill, Source: "return"},
+			{Name: "a", Hook: HookEnterRoom, Source: "return"},
+		}, "duplicate script name a"},
+		{"unknown hook", []Definition{{Name: "a", Hook: "onLevelUp", Source: "return"}}, `unknown hook "onLevelUp"`},
+		{"syntax error", []Definition{{Name: "a", Hook: HookKill, Source: "let = 1"}}, "a:1: expected a name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(DefaultBudget())
+			require.NoError(t, engine.Load([]Definition{{Name: "kept", Hook: HookKill, Source: "return"}}))
+
+			err := engine.Load(tt.definitions)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+			assert.Equal(t, []string{"kept"}, engine.Scripts(HookKill), "failed loads keep the previous scripts")
+		})
+	}
+}
+
+func TestEngine_LoadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hooks.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+budget:
+  max_steps: 50
+  max_duration: 10ms
+scripts:
+  - name: long_loop
+    hook: onQuestComplete
+    budget:
+      max_steps: 5000
+    source: |
+      let i = 0
+      while i < 100 { i = i + 1 }
+  - name: short_loop
+    hook: onQuestComplete
+    source: |
+      let i = 0
+      while i < 100 { i = i + 1 }
+`), 0o644))
+
+	engine := NewEngine(DefaultBudget())
+	require.NoError(t, engine.LoadFromFile(path))
+
+	assert.Equal(t, []string{"long_loop", "short_loop"}, engine.Scripts(HookQuestComplete))
+	err := engine.Fire(context.Background(), HookQuestComplete, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "short_loop:")
+	assert.NotContains(t, err.Error(), "long_loop:")
+
+	assert.Error(t, engine.LoadFromFile(filepath.Join(t.TempDir(), "missing.yaml")))
+}
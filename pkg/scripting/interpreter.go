@@ -0,0 +1,508 @@
+package scripting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Value is a script value: nil, bool, float64, string, or a
+// map[string]Value record. Host functions may also receive and return
+// ints, which are converted to float64.
+type Value = interface{}
+
+// Func is a host function callable from scripts. Scripts can only reach
+// the world through the functions they are given.
+type Func func(args []Value) (Value, error)
+
+// ErrBudgetExceeded is returned when a script uses up its execution budget.
+var ErrBudgetExceeded = errors.New("script execution budget exceeded")
+
+// Budget bounds the resources one script run may use.
+type Budget struct {
+	// MaxSteps is the number of statements, expressions and function
+	// calls a run may evaluate
+	MaxSteps int `yaml:"max_steps"`
+
+	// MaxDuration is the wall-clock time a run may take
+	MaxDuration time.Duration `yaml:"max_duration"`
+
+	// MaxStringLength is the longest string a run may build
+	MaxStringLength int `yaml:"max_string_length"`
+}
+
+// DefaultBudget returns the execution budget used when none is configured.
+func DefaultBudget() Budget {
+	return Budget{
+		MaxSteps:        10000,
+		MaxDuration:     50 * time.Millisecond,
+		MaxStringLength: 4096,
+	}
+}
+
+// ScriptError is a compile or runtime error with the script line it
+// occurred on.
+type ScriptError struct {
+	Script  string
+	Line    int
+	Message string
+	Err     error // Underlying cause, such as ErrBudgetExceeded
+}
+
+// Error formats the error with its script name and line.
+func (e *ScriptError) Error() string {
+	if e.Script != "" {
+		return fmt.Sprintf("%s:%d: %s", e.Script, e.Line, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Unwrap returns the underlying cause.
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// Program is a compiled script.
+type Program struct {
+	name string
+	body []stmt
+}
+
+// Compile parses a script.
+//
+// Parameters:
+//   - name: Name used in error messages
+//   - source: The script source
+//
+// Returns:
+//   - *Program: The compiled script, safe to run concurrently
+//   - error: A *ScriptError describing the first syntax error
+func Compile(name, source string) (*Program, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, withScript(err, name)
+	}
+	body, err := parse(tokens)
+	if err != nil {
+		return nil, withScript(err, name)
+	}
+	return &Program{name: name, body: body}, nil
+}
+
+// Name returns the name the program was compiled with.
+func (p *Program) Name() string {
+	return p.name
+}
+
+// Run executes the program. Scripts see vars as read-only globals and can
+// call funcs and the built-in functions; nothing else is reachable.
+//
+// Parameters:
+//   - ctx: Cancels the run
+//   - vars: Global variables, such as the event being handled
+//   - funcs: Host functions
+//   - budget: Resource limits for the run
+//
+// Returns:
+//   - Value: The value of the script's return statement, or nil
+//   - error: A *ScriptError for runtime errors, wrapping ErrBudgetExceeded
+//     or the context's error when the run was cut short
+func (p *Program) Run(ctx context.Context, vars map[string]Value, funcs map[string]Func, budget Budget) (Value, error) {
+	if budget.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget.MaxDuration)
+		defer cancel()
+	}
+
+	in := &interpreter{
+		ctx:    ctx,
+		vars:   vars,
+		funcs:  funcs,
+		budget: budget,
+		locals: make(map[string]Value),
+	}
+	result, err := in.run(p.body)
+	if err != nil {
+		return nil, withScript(err, p.name)
+	}
+	return result, nil
+}
+
+// withScript records the script name on a *ScriptError
+func withScript(err error, name string) error {
+	var scriptErr *ScriptError
+	if errors.As(err, &scriptErr) {
+		scriptErr.Script = name
+	}
+	return err
+}
+
+// returnSignal unwinds the interpreter on a return statement
+type returnSignal struct {
+	value Value
+}
+
+func (r *returnSignal) Error() string { return "return" }
+
+// interpreter evaluates one run of a program
+type interpreter struct {
+	ctx    context.Context
+	vars   map[string]Value
+	funcs  map[string]Func
+	budget Budget
+	locals map[string]Value
+	steps  int
+}
+
+func (in *interpreter) run(body []stmt) (Value, error) {
+	err := in.execBlock(body)
+	var ret *returnSignal
+	if errors.As(err, &ret) {
+		return ret.value, nil
+	}
+	return nil, err
+}
+
+// step charges one unit of the budget and checks for cancellation
+func (in *interpreter) step(line int) error {
+	in.steps++
+	if in.budget.MaxSteps > 0 && in.steps > in.budget.MaxSteps {
+		return &ScriptError{Line: line, Message: fmt.Sprintf("exceeded %d steps", in.budget.MaxSteps), Err: ErrBudgetExceeded}
+	}
+	if in.steps%64 == 0 {
+		if err := in.ctx.Err(); err != nil {
+			return &ScriptError{Line: line, Message: err.Error(), Err: err}
+		}
+	}
+	return nil
+}
+
+func (in *interpreter) execBlock(body []stmt) error {
+	for _, s := range body {
+		if err := in.exec(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *interpreter) exec(s stmt) error {
+	if err := in.step(s.pos()); err != nil {
+		return err
+	}
+
+	switch s := s.(type) {
+	case *letStmt:
+		if _, exists := in.vars[s.name]; exists {
+			return &ScriptError{Line: s.line, Message: fmt.Sprintf("cannot redefine global %s", s.name)}
+		}
+		value, err := in.eval(s.value)
+		if err != nil {
+			return err
+		}
+		in.locals[s.name] = value
+	case *assignStmt:
+		if _, exists := in.locals[s.name]; !exists {
+			return &ScriptError{Line: s.line, Message: fmt.Sprintf("assignment to undeclared variable %s", s.name)}
+		}
+		value, err := in.eval(s.value)
+		if err != nil {
+			return err
+		}
+		in.locals[s.name] = value
+	case *exprStmt:
+		_, err := in.eval(s.expr)
+		return err
+	case *ifStmt:
+		cond, err := in.eval(s.cond)
+		if err != nil {
+			return err
+		}
+		if truthy(cond) {
+			return in.execBlock(s.then)
+		}
+		return in.execBlock(s.els)
+	case *whileStmt:
+		for {
+			cond, err := in.eval(s.cond)
+			if err != nil {
+				return err
+			}
+			if !truthy(cond) {
+				return nil
+			}
+			if err := in.execBlock(s.body); err != nil {
+				return err
+			}
+		}
+	case *returnStmt:
+		var value Value
+		if s.value != nil {
+			var err error
+			if value, err = in.eval(s.value); err != nil {
+				return err
+			}
+		}
+		return &returnSignal{value: value}
+	}
+	return nil
+}
+
+func (in *interpreter) eval(e expr) (Value, error) {
+	if err := in.step(e.pos()); err != nil {
+		return nil, err
+	}
+
+	switch e := e.(type) {
+	case *literalExpr:
+		return e.value, nil
+	case *identExpr:
+		if value, exists := in.locals[e.name]; exists {
+			return value, nil
+		}
+		if value, exists := in.vars[e.name]; exists {
+			return normalize(value), nil
+		}
+		return nil, &ScriptError{Line: e.line, Message: fmt.Sprintf("undefined variable %s", e.name)}
+	case *memberExpr:
+		object, err := in.eval(e.object)
+		if err != nil {
+			return nil, err
+		}
+		record, ok := object.(map[string]Value)
+		if !ok {
+			return nil, &ScriptError{Line: e.line, Message: fmt.Sprintf("cannot read field %s of %s", e.name, typeName(object))}
+		}
+		return normalize(record[e.name]), nil
+	case *callExpr:
+		return in.call(e)
+	case *unaryExpr:
+		operand, err := in.eval(e.operand)
+		if err != nil {
+			return nil, err
+		}
+		if e.op == "not" {
+			return !truthy(operand), nil
+		}
+		n, ok := operand.(float64)
+		if !ok {
+			return nil, &ScriptError{Line: e.line, Message: fmt.Sprintf("cannot negate %s", typeName(operand))}
+		}
+		return -n, nil
+	case *binaryExpr:
+		return in.binary(e)
+	}
+	return nil, &ScriptError{Line: e.pos(), Message: "unknown expression"}
+}
+
+func (in *interpreter) call(e *callExpr) (Value, error) {
+	fn, exists := in.funcs[e.name]
+	if !exists {
+		if fn, exists = builtins[e.name]; !exists {
+			return nil, &ScriptError{Line: e.line, Message: fmt.Sprintf("undefined function %s", e.name)}
+		}
+	}
+
+	args := make([]Value, len(e.args))
+	for i, arg := range e.args {
+		value, err := in.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	result, err := fn(args)
+	if err != nil {
+		return nil, &ScriptError{Line: e.line, Message: fmt.Sprintf("%s: %v", e.name, err), Err: err}
+	}
+	result = normalize(result)
+	if err := in.checkString(result, e.line); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (in *interpreter) binary(e *binaryExpr) (Value, error) {
+	left, err := in.eval(e.left)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short-circuit and yield booleans
+	switch e.op {
+	case "and":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := in.eval(e.right)
+		return truthy(right), err
+	case "or":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := in.eval(e.right)
+		return truthy(right), err
+	}
+
+	right, err := in.eval(e.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "+":
+		if ls, ok := left.(string); ok {
+			result := ls + toString(right)
+			return result, in.checkString(result, e.line)
+		}
+		if rs, ok := right.(string); ok {
+			result := toString(left) + rs
+			return result, in.checkString(result, e.line)
+		}
+	}
+
+	ln, lok := left.(float64)
+	rn, rok := right.(float64)
+	if !lok || !rok {
+		if ls, ok := left.(string); ok {
+			if rs, ok := right.(string); ok {
+				return compareStrings(e.op, ls, rs, e.line)
+			}
+		}
+		return nil, &ScriptError{Line: e.line, Message: fmt.Sprintf("cannot apply %s to %s and %s", e.op, typeName(left), typeName(right))}
+	}
+
+	switch e.op {
+	case "+":
+		return ln + rn, nil
+	case "-":
+		return ln - rn, nil
+	case "*":
+		return ln * rn, nil
+	case "/", "%":
+		if rn == 0 {
+			return nil, &ScriptError{Line: e.line, Message: "division by zero"}
+		}
+		if e.op == "/" {
+			return ln / rn, nil
+		}
+		return math.Mod(ln, rn), nil
+	case "<":
+		return ln < rn, nil
+	case "<=":
+		return ln <= rn, nil
+	case ">":
+		return ln > rn, nil
+	case ">=":
+		return ln >= rn, nil
+	}
+	return nil, &ScriptError{Line: e.line, Message: fmt.Sprintf("unknown operator %s", e.op)}
+}
+
+// checkString enforces the budget's string length limit
+func (in *interpreter) checkString(value Value, line int) error {
+	s, ok := value.(string)
+	if ok && in.budget.MaxStringLength > 0 && len(s) > in.budget.MaxStringLength {
+		return &ScriptError{
+			Line:    line,
+			Message: fmt.Sprintf("string longer than %d bytes", in.budget.MaxStringLength),
+			Err:     ErrBudgetExceeded,
+		}
+	}
+	return nil
+}
+
+func compareStrings(op, left, right string, line int) (Value, error) {
+	switch op {
+	case "<":
+		return left < right, nil
+	case "<=":
+		return left <= right, nil
+	case ">":
+		return left > right, nil
+	case ">=":
+		return left >= right, nil
+	}
+	return nil, &ScriptError{Line: line, Message: fmt.Sprintf("cannot apply %s to strings", op)}
+}
+
+// normalize converts host values to script values
+func normalize(value Value) Value {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case map[string]interface{}:
+		return v
+	case nil, bool, float64, string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
+// truthy reports whether a value counts as true: everything except nil,
+// false, zero and the empty string
+func truthy(value Value) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+func equal(left, right Value) bool {
+	switch l := left.(type) {
+	case nil, bool, float64, string:
+		return l == right
+	}
+	return false
+}
+
+func toString(value Value) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+func typeName(value Value) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case map[string]Value:
+		return "record"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,211 @@
+package scripting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runScript compiles and runs source with the default budget
+func runScript(t *testing.T, source string, vars map[string]Value, funcs map[string]Func) (Value, error) {
+	t.Helper()
+	program, err := Compile("test", source)
+	require.NoError(t, err)
+	return program.Run(context.Background(), vars, funcs, DefaultBudget())
+}
+
+func TestProgram_Evaluates(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   Value
+	}{
+		{"arithmetic precedence", "return 2 + 3 * 4 - 6 / 2", 11.0},
+		{"parentheses", "return (2 + 3) * 4", 20.0},
+		{"modulo", "return 17 % 5", 2.0},
+		{"unary minus", "return -(3 - 5)", 2.0},
+		{"string concatenation", `return "gold: " + 10`, "gold: 10"},
+		{"comparison", "return 3 >= 3 and 2 < 1", false},
+		{"symbolic logic", "return !false && (false || true)", true},
+		{"string comparison", `return "abc" < "abd"`, true},
+		{"equality across types", `return 1 == "1"`, false},
+		{"nil equality", "return nil == nil", true},
+		{"locals", "let a = 2\nlet b = a * a\na = b + 1\nreturn a", 5.0},
+		{"semicolons", "let a = 1; a = a + 1; return a", 2.0},
+		{"if else chain", "let x = 7\nif x > 10 { return 1 } else if x > 5 { return 2 } else { return 3 }", 2.0},
+		{"while loop", "let i = 0\nlet sum = 0\nwhile i < 5 {\n  i = i + 1\n  sum = sum + i\n}\nreturn sum", 15.0},
+		{"return inside loop", "let i = 0\nwhile true {\n  i = i + 1\n  if i == 3 { return i }\n}", 3.0},
+		{"bare return", "return\nlet unreachable = 1", nil},
+		{"no return", "let a = 1", nil},
+		{"comments", "# setup\nlet a = 4 # four\nreturn a", 4.0},
+		{"builtins", `return len("héllo") + floor(2.7) + min(4, 1, 9) + max(4, 1, 9) + len(str(12))`, 19.0},
+		{"escapes", `return "a\"b\\c\n"`, "a\"b\\c\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runScript(t, tt.source, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProgram_GlobalsAndHostFunctions(t *testing.T) {
+	var granted []Value
+	funcs := map[string]Func{
+		"give_gold": func(args []Value) (Value, error) {
+			granted = append(granted, args...)
+			return 150, nil // ints are returned to scripts as numbers
+		},
+	}
+	vars := map[string]Value{
+		"event": map[string]Value{"player_id": "p1", "level": 3},
+	}
+
+	got, err := runScript(t, `
+if event.player_id == "p1" and event.level >= 3 {
+  return give_gold(event.player_id, event.level * 50)
+}
+return 0
+`, vars, funcs)
+
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, got)
+	assert.Equal(t, []Value{"p1", 150.0}, granted)
+
+	got, err = runScript(t, "return event.missing", vars, nil)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestCompile_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		line    int
+		message string
+	}{
+		{"unterminated string", "let a = 1\nlet b = \"oops", 2, "unterminated string"},
+		{"unexpected character", "let a = 1 @ 2", 1, "unexpected character"},
+		{"missing brace", "if true {\n  log(1)\n", 3, `expected "}"`},
+		{"unused expression", "let a = 1\na + 1", 2, "unused"},
+		{"let without name", "let = 3", 1, "expected a name"},
+		{"call on member", "event.handler(1)", 1, "only named functions"},
+		{"bad escape", `let a = "\q"`, 1, "invalid escape"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile("broken", tt.source)
+			require.Error(t, err)
+			var scriptErr *ScriptError
+			require.True(t, errors.As(err, &scriptErr))
+			assert.Equal(t, "broken", scriptErr.Script)
+			assert.Equal(t, tt.line, scriptErr.Line)
+			assert.Contains(t, scriptErr.Message, tt.message)
+		})
+	}
+}
+
+func TestProgram_RuntimeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		message string
+	}{
+		{"undefined variable", "return missing", "undefined variable missing"},
+		{"undefined function", `os_exec("rm -rf /")`, "undefined function os_exec"},
+		{"undeclared assignment", "x = 1", "undeclared variable x"},
+		{"redefining globals", "let event = 1", "cannot redefine global event"},
+		{"type mismatch", `return 1 - "a"`, "cannot apply -"},
+		{"division by zero", "return 1 / 0", "division by zero"},
+		{"field of non-record", "return event.player_id.name", "cannot read field name of string"},
+		{"builtin arguments", "return floor(\"x\")", "floor: expects 1 number"},
+	}
+
+	vars := map[string]Value{"event": map[string]Value{"player_id": "p1"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runScript(t, tt.source, vars, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+			assert.True(t, strings.HasPrefix(err.Error(), "test:1: "), err.Error())
+		})
+	}
+}
+
+func TestProgram_HostFunctionErrors(t *testing.T) {
+	cause := errors.New("player not found")
+	funcs := map[string]Func{
+		"heal": func(args []Value) (Value, error) { return nil, cause },
+	}
+
+	_, err := runScript(t, "let a = 1\nheal(\"nobody\", 5)", nil, funcs)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "test:2: heal: player not found", err.Error())
+}
+
+func TestProgram_Budget(t *testing.T) {
+	t.Run("step limit stops infinite loops", func(t *testing.T) {
+		program, err := Compile("loop", "let i = 0\nwhile true { i = i + 1 }")
+		require.NoError(t, err)
+
+		_, err = program.Run(context.Background(), nil, nil, Budget{MaxSteps: 1000})
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+	})
+
+	t.Run("string limit stops runaway concatenation", func(t *testing.T) {
+		program, err := Compile("grow", "let s = \"x\"\nwhile true { s = s + s }")
+		require.NoError(t, err)
+
+		_, err = program.Run(context.Background(), nil, nil, Budget{MaxSteps: 10000, MaxStringLength: 1024})
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.Contains(t, err.Error(), "string longer than 1024 bytes")
+	})
+
+	t.Run("string limit applies to host results", func(t *testing.T) {
+		funcs := map[string]Func{
+			"big": func(args []Value) (Value, error) { return strings.Repeat("a", 100), nil },
+		}
+		program, err := Compile("host", "big()")
+		require.NoError(t, err)
+
+		_, err = program.Run(context.Background(), nil, funcs, Budget{MaxStringLength: 10})
+
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+	})
+
+	t.Run("duration limit", func(t *testing.T) {
+		program, err := Compile("slow", "while true { }")
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = program.Run(context.Background(), nil, nil, Budget{MaxDuration: 20 * time.Millisecond})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		program, err := Compile("cancelled", "while true { }")
+		require.NoError(t, err)
+
+		_, err = program.Run(ctx, nil, nil, Budget{})
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
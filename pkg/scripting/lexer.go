@@ -0,0 +1,146 @@
+package scripting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenKeyword
+	tokenOperator
+)
+
+// keywords are the reserved words of the language
+var keywords = map[string]bool{
+	"let": true, "if": true, "else": true, "while": true, "return": true,
+	"true": true, "false": true, "nil": true, "and": true, "or": true, "not": true,
+}
+
+// operators lists the operator and punctuation tokens, longest first so
+// two-character operators win over their prefixes
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"+", "-", "*", "/", "%", "<", ">", "=", "!", "(", ")", "{", "}", ",", ".", ";",
+}
+
+// token is one lexical token of a script
+type token struct {
+	kind   tokenKind
+	text   string  // Identifier, keyword or operator text, or decoded string
+	number float64 // Value of a number token
+	line   int
+}
+
+// lex splits source into tokens. Comments run from # to the end of the
+// line.
+func lex(source string) ([]token, error) {
+	var tokens []token
+	line := 1
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			word := string(runes[start:i])
+			kind := tokenIdent
+			if keywords[word] {
+				kind = tokenKeyword
+			}
+			tokens = append(tokens, token{kind: kind, text: word, line: line})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &ScriptError{Line: line, Message: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, number: value, line: line})
+		case r == '"':
+			text, next, err := lexString(runes, i+1, line)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, line: line})
+			i = next
+		default:
+			op := matchOperator(runes[i:])
+			if op == "" {
+				return nil, &ScriptError{Line: line, Message: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, line: line})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
+
+// lexString decodes a double-quoted string starting after its opening
+// quote and returns the index after the closing quote
+func lexString(runes []rune, i, line int) (string, int, error) {
+	var b strings.Builder
+	for i < len(runes) {
+		switch r := runes[i]; r {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\n':
+			return "", 0, &ScriptError{Line: line, Message: "unterminated string"}
+		case '\\':
+			if i+1 >= len(runes) {
+				return "", 0, &ScriptError{Line: line, Message: "unterminated string"}
+			}
+			switch runes[i+1] {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case '"', '\\':
+				b.WriteRune(runes[i+1])
+			default:
+				return "", 0, &ScriptError{Line: line, Message: fmt.Sprintf("invalid escape \\%c", runes[i+1])}
+			}
+			i += 2
+		default:
+			b.WriteRune(r)
+			i++
+		}
+	}
+	return "", 0, &ScriptError{Line: line, Message: "unterminated string"}
+}
+
+// matchOperator returns the operator at the start of runes, or "" if there
+// is none
+func matchOperator(runes []rune) string {
+	for _, op := range operators {
+		if len(runes) >= len(op) && string(runes[:len(op)]) == op {
+			return op
+		}
+	}
+	return ""
+}
@@ -0,0 +1,429 @@
+package scripting
+
+import "fmt"
+
+// Expression nodes
+type (
+	expr interface{ pos() int }
+
+	literalExpr struct {
+		value Value
+		line  int
+	}
+	identExpr struct {
+		name string
+		line int
+	}
+	memberExpr struct {
+		object expr
+		name   string
+		line   int
+	}
+	callExpr struct {
+		name string
+		args []expr
+		line int
+	}
+	unaryExpr struct {
+		op      string
+		operand expr
+		line    int
+	}
+	binaryExpr struct {
+		op          string
+		left, right expr
+		line        int
+	}
+)
+
+func (e *literalExpr) pos() int { return e.line }
+func (e *identExpr) pos() int   { return e.line }
+func (e *memberExpr) pos() int  { return e.line }
+func (e *callExpr) pos() int    { return e.line }
+func (e *unaryExpr) pos() int   { return e.line }
+func (e *binaryExpr) pos() int  { return e.line }
+
+// Statement nodes
+type (
+	stmt interface{ pos() int }
+
+	letStmt struct {
+		name  string
+		value expr
+		line  int
+	}
+	assignStmt struct {
+		name  string
+		value expr
+		line  int
+	}
+	exprStmt struct {
+		expr expr
+	}
+	ifStmt struct {
+		cond      expr
+		then, els []stmt
+		line      int
+	}
+	whileStmt struct {
+		cond expr
+		body []stmt
+		line int
+	}
+	returnStmt struct {
+		value expr // nil for a bare return
+		line  int
+	}
+)
+
+func (s *letStmt) pos() int    { return s.line }
+func (s *assignStmt) pos() int { return s.line }
+func (s *exprStmt) pos() int   { return s.expr.pos() }
+func (s *ifStmt) pos() int     { return s.line }
+func (s *whileStmt) pos() int  { return s.line }
+func (s *returnStmt) pos() int { return s.line }
+
+// parser builds statements from tokens by recursive descent
+type parser struct {
+	tokens []token
+	next   int
+}
+
+// parse parses the tokens of a whole script
+func parse(tokens []token) ([]stmt, error) {
+	p := &parser{tokens: tokens}
+	var body []stmt
+	for {
+		p.skipSeparators()
+		if p.peek().kind == tokenEOF {
+			return body, nil
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, s)
+	}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+// is reports whether the next token is the keyword or operator text
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokenKeyword || t.kind == tokenOperator) && t.text == text
+}
+
+// accept consumes the next token if it is the keyword or operator text
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) (token, error) {
+	if !p.is(text) {
+		return token{}, p.errorf("expected %q, found %s", text, describe(p.peek()))
+	}
+	return p.advance(), nil
+}
+
+func (p *parser) expectIdent() (token, error) {
+	if p.peek().kind != tokenIdent {
+		return token{}, p.errorf("expected a name, found %s", describe(p.peek()))
+	}
+	return p.advance(), nil
+}
+
+func (p *parser) skipSeparators() {
+	for p.accept(";") {
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &ScriptError{Line: p.peek().line, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) statement() (stmt, error) {
+	t := p.peek()
+	switch {
+	case p.accept("let"):
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return &letStmt{name: name.text, value: value, line: t.line}, nil
+
+	case p.accept("if"):
+		return p.ifStatement(t.line)
+
+	case p.accept("while"):
+		cond, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		return &whileStmt{cond: cond, body: body, line: t.line}, nil
+
+	case p.accept("return"):
+		next := p.peek()
+		if next.kind == tokenEOF || next.line != t.line || p.is("}") || p.is(";") {
+			return &returnStmt{line: t.line}, nil
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return &returnStmt{value: value, line: t.line}, nil
+
+	case t.kind == tokenIdent && p.tokens[p.next+1].kind == tokenOperator && p.tokens[p.next+1].text == "=":
+		p.advance()
+		p.advance()
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return &assignStmt{name: t.text, value: value, line: t.line}, nil
+	}
+
+	e, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := e.(*callExpr); !ok {
+		return nil, &ScriptError{Line: e.pos(), Message: "expression result is unused"}
+	}
+	return &exprStmt{expr: e}, nil
+}
+
+func (p *parser) ifStatement(line int) (stmt, error) {
+	cond, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	then, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	s := &ifStmt{cond: cond, then: then, line: line}
+	if elseTok := p.peek(); p.accept("else") {
+		if p.accept("if") {
+			nested, err := p.ifStatement(elseTok.line)
+			if err != nil {
+				return nil, err
+			}
+			s.els = []stmt{nested}
+		} else if s.els, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) block() ([]stmt, error) {
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var body []stmt
+	for {
+		p.skipSeparators()
+		if p.accept("}") {
+			return body, nil
+		}
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf("expected \"}\", found end of script")
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, s)
+	}
+}
+
+// binaryLevels lists the binary operators by increasing precedence
+var binaryLevels = [][]string{
+	{"or", "||"},
+	{"and", "&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) expression() (expr, error) {
+	return p.binary(0)
+}
+
+func (p *parser) binary(level int) (expr, error) {
+	if level == len(binaryLevels) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.matchAny(binaryLevels[level])
+		if !ok {
+			return left, nil
+		}
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: normalizeOperator(op.text), left: left, right: right, line: op.line}
+	}
+}
+
+func (p *parser) matchAny(ops []string) (token, bool) {
+	for _, op := range ops {
+		if p.is(op) {
+			return p.advance(), true
+		}
+	}
+	return token{}, false
+}
+
+func (p *parser) unary() (expr, error) {
+	if op, ok := p.matchAny([]string{"not", "!", "-"}); ok {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: normalizeOperator(op.text), operand: operand, line: op.line}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (expr, error) {
+	e, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.is("."):
+			p.advance()
+			name, err := p.expectIdent()
+			if err != nil {
+				return nil, err
+			}
+			e = &memberExpr{object: e, name: name.text, line: name.line}
+		case p.is("(") && p.peek().line == p.tokens[p.next-1].line:
+			ident, ok := e.(*identExpr)
+			if !ok {
+				return nil, p.errorf("only named functions can be called")
+			}
+			args, err := p.arguments()
+			if err != nil {
+				return nil, err
+			}
+			e = &callExpr{name: ident.name, args: args, line: ident.line}
+		default:
+			return e, nil
+		}
+	}
+}
+
+func (p *parser) arguments() ([]expr, error) {
+	p.advance() // (
+	var args []expr
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		arg, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(")") {
+			return args, nil
+		}
+		if _, err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.advance()
+	switch t.kind {
+	case tokenNumber:
+		return &literalExpr{value: t.number, line: t.line}, nil
+	case tokenString:
+		return &literalExpr{value: t.text, line: t.line}, nil
+	case tokenIdent:
+		return &identExpr{name: t.text, line: t.line}, nil
+	case tokenKeyword:
+		switch t.text {
+		case "true":
+			return &literalExpr{value: true, line: t.line}, nil
+		case "false":
+			return &literalExpr{value: false, line: t.line}, nil
+		case "nil":
+			return &literalExpr{value: nil, line: t.line}, nil
+		}
+	case tokenOperator:
+		if t.text == "(" {
+			e, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	}
+	return nil, &ScriptError{Line: t.line, Message: fmt.Sprintf("unexpected %s", describe(t))}
+}
+
+// normalizeOperator maps the symbolic spellings of the logical operators
+// to their keyword forms
+func normalizeOperator(op string) string {
+	switch op {
+	case "&&":
+		return "and"
+	case "||":
+		return "or"
+	case "!":
+		return "not"
+	}
+	return op
+}
+
+// describe names a token for error messages
+func describe(t token) string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of script"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
@@ -0,0 +1,565 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// Admin permissions. Each admin method requires one; the admin token is
+// granted those listed in config.AdminPermissions.
+const (
+	AdminPermissionSpawn    = "spawn"
+	AdminPermissionTeleport = "teleport"
+	AdminPermissionGrant    = "grant"
+	AdminPermissionGenerate = "generate"
+	AdminPermissionCombat   = "combat"
+	AdminPermissionInspect  = "inspect"
+	AdminPermissionUndo     = "undo"
+	AdminPermissionRestore  = "restore"
+	AdminPermissionConfig   = "config"
+	AdminPermissionAnnotate = "annotate"
+)
+
+// adminMethodPermissions maps each admin method to the permission it needs
+var adminMethodPermissions = map[RPCMethod]string{
+	MethodAdminListSessions:    AdminPermissionInspect,
+	MethodAdminInspectSession:  AdminPermissionInspect,
+	MethodAdminSpawnEntity:     AdminPermissionSpawn,
+	MethodAdminTeleportPlayer:  AdminPermissionTeleport,
+	MethodAdminGrantXP:         AdminPermissionGrant,
+	MethodAdminGrantItem:       AdminPermissionGrant,
+	MethodAdminGenerate:        AdminPermissionGenerate,
+	MethodAdminEndCombat:       AdminPermissionCombat,
+	MethodAdminGiveItem:        AdminPermissionGrant,
+	MethodAdminTeleport:        AdminPermissionTeleport,
+	MethodAdminUndoLastAction:  AdminPermissionUndo,
+	MethodAdminRestoreBackup:   AdminPermissionRestore,
+	MethodAdminRestoreSnapshot: AdminPermissionRestore,
+	MethodAdminReloadLoot:      AdminPermissionGenerate,
+	MethodAdminReloadPCG:       AdminPermissionGenerate,
+	MethodAdminReloadConfig:    AdminPermissionConfig,
+	MethodAdminListRequests:    AdminPermissionInspect,
+
+	// Methods players call too, where the admin token grants the game
+	// master's view
+	MethodAddAnnotation:  AdminPermissionAnnotate,
+	MethodGetAnnotations: AdminPermissionAnnotate,
+	MethodExportMap:      AdminPermissionAnnotate,
+}
+
+// adminAuditLog records every admin call, allowed or denied
+var adminAuditLog = logrus.WithField("component", "admin_audit")
+
+// isAdminMethod reports whether method belongs to the admin namespace.
+func isAdminMethod(method RPCMethod) bool {
+	return strings.HasPrefix(string(method), AdminMethodPrefix)
+}
+
+// adminConsole authorizes calls to the admin methods of a headless GM
+// console. Calls carry the configured admin token instead of a session and
+// share one rate limit of their own, so they neither use up nor depend on
+// any player's allowance.
+type adminConsole struct {
+	token       []byte
+	permissions map[string]bool
+	limiter     *rate.Limiter // Calls made with the right token
+	failures    *rate.Limiter // Attempts made with a wrong token
+}
+
+// newAdminConsole creates the admin console from the configuration, or
+// returns nil when no admin token is configured.
+func newAdminConsole(cfg *config.Config) *adminConsole {
+	if cfg == nil || cfg.AdminToken == "" {
+		return nil
+	}
+
+	permissions := make(map[string]bool, len(cfg.AdminPermissions))
+	for _, permission := range cfg.AdminPermissions {
+		permissions[permission] = true
+	}
+	limit := rate.Limit(cfg.AdminRateLimitRequestsPerSecond)
+	return &adminConsole{
+		token:       []byte(cfg.AdminToken),
+		permissions: permissions,
+		limiter:     rate.NewLimiter(limit, cfg.AdminRateLimitBurst),
+		failures:    rate.NewLimiter(limit, cfg.AdminRateLimitBurst),
+	}
+}
+
+// authorize checks a call to method made with token. Calls with a wrong
+// token are throttled by their own limit, so guessing tokens cannot use up
+// the limit of the real admin.
+func (c *adminConsole) authorize(method RPCMethod, token string) error {
+	if subtle.ConstantTimeCompare([]byte(token), c.token) != 1 {
+		if !c.failures.Allow() {
+			return adminRateLimited(c.failures)
+		}
+		return ErrAdminUnauthorized
+	}
+	if !c.limiter.Allow() {
+		return adminRateLimited(c.limiter)
+	}
+	permission := adminMethodPermissions[method]
+	if !c.permissions[permission] {
+		return ErrAdminForbidden.WithMessage("the admin token lacks the %s permission", permission).
+			WithData(map[string]interface{}{"permission": permission})
+	}
+	return nil
+}
+
+// adminRateLimited returns the rate limit error for an exhausted limiter
+func adminRateLimited(limiter *rate.Limiter) error {
+	missing := 1 - limiter.Tokens()
+	retryAfter := time.Duration(missing / float64(limiter.Limit()) * float64(time.Second))
+	return NewJSONRPCError(JSONRPCRateLimited, "Admin rate limit exceeded", map[string]interface{}{
+		"retry_after_ms": retryAfter.Milliseconds(),
+	})
+}
+
+// authorizeAdmin checks the admin token of an admin method call. Denied
+// calls are written to the audit log.
+func (s *RPCServer) authorizeAdmin(method RPCMethod, params interface{}) error {
+	paramsMap, _ := params.(map[string]interface{})
+	token, _ := paramsMap["admin_token"].(string)
+
+	var err error
+	if s.admin == nil {
+		err = ErrUnavailable.WithMessage("admin console is disabled")
+	} else {
+		err = s.admin.authorize(method, token)
+	}
+	if err != nil {
+		adminAuditLog.WithFields(logrus.Fields{
+			"method": method,
+			"error":  err.Error(),
+		}).Warn("admin call denied")
+	}
+	return err
+}
+
+// auditAdminCall writes an authorized admin call and its outcome to the
+// audit log. The admin token is left out.
+func (s *RPCServer) auditAdminCall(method RPCMethod, params interface{}, err error) {
+	fields := logrus.Fields{"method": method}
+	if paramsMap, ok := params.(map[string]interface{}); ok {
+		for key, value := range paramsMap {
+			if key != "admin_token" {
+				fields["param_"+key] = value
+			}
+		}
+	}
+
+	entry := adminAuditLog.WithFields(fields)
+	if err != nil {
+		entry.WithError(err).Warn("admin action failed")
+		return
+	}
+	entry.Info("admin action")
+}
+
+// handleAdminListSessions returns a summary of every session on this
+// server, ordered by session ID.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - sessions: session_id, player_id, player_name, level, position,
+//     connected, created_at and last_active of each session
+//   - error: Never, once the call is authorized
+func (s *RPCServer) handleAdminListSessions(params json.RawMessage) (interface{}, error) {
+	s.mu.RLock()
+	sessions := make([]*PlayerSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.RUnlock()
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].SessionID < sessions[j].SessionID })
+
+	summaries := make([]map[string]interface{}, 0, len(sessions))
+	for _, session := range sessions {
+		summary := map[string]interface{}{
+			"session_id":  session.SessionID,
+			"connected":   session.Connected,
+			"created_at":  session.CreatedAt,
+			"last_active": session.LastActive,
+		}
+		if session.Player != nil {
+			player := session.Player.Clone()
+			summary["player_id"] = player.GetID()
+			summary["player_name"] = player.GetName()
+			summary["level"] = player.Level
+			summary["position"] = player.GetPosition()
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return map[string]interface{}{
+		"success":  true,
+		"sessions": summaries,
+	}, nil
+}
+
+// handleAdminInspectSession returns everything the server holds about a
+// session: its player, journal, rate limit bucket and combat status.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - session_id: string - The session to inspect
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - session_id, connected, created_at, last_active: The session
+//   - player: A copy of the session's game.Player
+//   - in_combat: Whether the player is in the initiative order
+//   - journal_size: Admin actions the session can still undo
+//   - rate_limit: The session's rate limit bucket, when per-session rate
+//     limiting is enabled
+//   - error: Invalid parameters or an unknown session
+func (s *RPCServer) handleAdminInspectSession(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid inspect parameters", err.Error())
+	}
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	player := session.Player.Clone()
+	result := map[string]interface{}{
+		"success":      true,
+		"session_id":   session.SessionID,
+		"connected":    session.Connected,
+		"created_at":   session.CreatedAt,
+		"last_active":  session.LastActive,
+		"player":       player,
+		"in_combat":    s.state.TurnManager.IsInCombat && slices.Contains(s.state.TurnManager.Initiative, player.GetID()),
+		"journal_size": s.journal.size(session.SessionID),
+	}
+	if s.sessionLimiter != nil {
+		result["rate_limit"] = s.sessionLimiter.BucketState(session.SessionID)
+	}
+	return result, nil
+}
+
+// handleAdminSpawnEntity generates monsters of a bestiary kind and places
+// them in the world as hostile NPCs, the first at the given position and
+// the rest around it.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - kind: string - Bestiary kind, e.g. "orc"
+//   - count: int - Number of monsters (optional, default 1)
+//   - level: int - Difficulty they are generated at (optional, default 1)
+//   - position: object - x, y and level where they appear
+//   - seed: int64 - Generation seed (optional, random by default)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - spawned: The placed game.NPCs
+//   - error: Invalid parameters, a blocked or out of bounds position, or
+//     a failed generation
+func (s *RPCServer) handleAdminSpawnEntity(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		Kind     string `json:"kind"`
+		Count    int    `json:"count"`
+		Level    int    `json:"level"`
+		Seed     int64  `json:"seed"`
+		Position struct {
+			X     int `json:"x"`
+			Y     int `json:"y"`
+			Level int `json:"level"`
+		} `json:"position"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid spawn parameters", err.Error())
+	}
+	if req.Kind == "" {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "kind parameter required", nil)
+	}
+	count := min(max(req.Count, 1), pcg.MaxMonsterGroupSize)
+	if req.Seed == 0 && s.pcgManager != nil {
+		req.Seed = s.pcgManager.GetSeedManager().DeriveContextSeed(pcg.ContentTypeMonsters, "admin:"+uuid.New().String())
+	}
+
+	pos := game.Position{X: req.Position.X, Y: req.Position.Y, Level: req.Position.Level}
+	world := s.state.WorldState
+	if pos.X < 0 || pos.X >= world.Width || pos.Y < 0 || pos.Y >= world.Height {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid spawn position", fmt.Sprintf("position %d,%d is out of bounds", pos.X, pos.Y))
+	}
+	var spots []game.Position
+	if len(world.GetObjectsAt(pos)) == 0 {
+		spots = append(spots, pos)
+	}
+	spots = append(spots, s.encounterSpawnPoints(pos, count-len(spots))...)
+	if len(spots) == 0 {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid spawn position", fmt.Sprintf("no free space around %d,%d", pos.X, pos.Y))
+	}
+
+	var generator monsterSource = s.pcgManager
+	if s.pcgManager == nil {
+		monsterLogger := logrus.New()
+		monsterLogger.SetLevel(logrus.WarnLevel)
+		generator = pcg.NewMonsterGenerator(monsterLogger)
+	}
+	monsters, err := generator.GenerateMonsters(context.Background(), pcg.MonsterParams{
+		GenerationParams: pcg.GenerationParams{Seed: req.Seed, Difficulty: max(req.Level, 1), PlayerLevel: max(req.Level, 1)},
+		Count:            len(spots),
+		Kinds:            []string{req.Kind},
+	})
+	if err != nil {
+		return nil, ErrGenerationFailed.WithMessage("failed to generate %s: %v", req.Kind, err)
+	}
+
+	spawned := make([]*game.NPC, 0, len(monsters))
+	for i, monster := range monsters {
+		npc := encounterNPC(monster, spots[i])
+		if err := world.AddObject(npc); err != nil {
+			for _, placed := range spawned {
+				_ = world.RemoveObject(placed.GetID())
+			}
+			return nil, ErrInvalidTarget.WithMessage("cannot place %s: %v", npc.GetID(), err)
+		}
+		spawned = append(spawned, npc)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"spawned": spawned,
+	}, nil
+}
+
+// handleAdminTeleportPlayer moves a session's player straight to a
+// position, like teleport but without entering the session's journal.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - session_id: string - The session whose player moves
+//   - position: object - x, y and level of the destination
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - player_id: The moved player
+//   - from, position: The previous and new positions
+//   - error: Invalid parameters, an unknown session, or a destination out
+//     of bounds or blocked by an obstacle
+func (s *RPCServer) handleAdminTeleportPlayer(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+		Position  struct {
+			X     int `json:"x"`
+			Y     int `json:"y"`
+			Level int `json:"level"`
+		} `json:"position"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid teleport parameters", err.Error())
+	}
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	player := session.Player
+	from := player.GetPosition()
+	to := game.Position{X: req.Position.X, Y: req.Position.Y, Level: req.Position.Level, Facing: from.Facing}
+	if err := s.checkDestination(player, to); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid teleport destination", err.Error())
+	}
+	if err := s.teleportObject(player, to); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success":   true,
+		"player_id": player.GetID(),
+		"from":      from,
+		"position":  to,
+	}, nil
+}
+
+// handleAdminGrantXP awards experience to a session's player, levelling
+// them up as usual.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - session_id: string - The session whose player gains experience
+//   - amount: int64 - Experience to award, greater than 0
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - experience, level: The player's experience and level afterwards
+//   - error: Invalid parameters or an unknown session
+func (s *RPCServer) handleAdminGrantXP(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+		Amount    int64  `json:"amount"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid grant parameters", err.Error())
+	}
+	if req.Amount <= 0 {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "amount must be greater than 0", nil)
+	}
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := session.Player.AddExperience(req.Amount); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Cannot grant experience", err.Error())
+	}
+	player := session.Player.Clone()
+
+	return map[string]interface{}{
+		"success":    true,
+		"experience": player.Experience,
+		"level":      player.Level,
+	}, nil
+}
+
+// handleAdminGrantItem creates an item in a session's player's inventory,
+// like admin.giveItem but without entering the session's journal.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - session_id: string - The session whose player receives the item
+//   - item: object - As for admin.giveItem
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - item: The created game.Item with its new ID
+//   - error: Invalid parameters, an unknown session or a full inventory
+func (s *RPCServer) handleAdminGrantItem(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string   `json:"session_id"`
+		Item      itemSpec `json:"item"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid grant parameters", err.Error())
+	}
+	if req.Item.Name == "" || req.Item.Type == "" {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Item name and type are required", nil)
+	}
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := req.Item.newItem()
+	if err := session.Player.AddItemToInventory(item); err != nil {
+		return nil, ErrInvalidTarget.WithMessage("cannot grant item: %v", err)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"item":    item,
+	}, nil
+}
+
+// handleAdminGenerateContent generates terrain, a dungeon level or items
+// and commits them straight into the live world, skipping the preview step
+// of generateContent.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - content_type: string - "terrain", "levels" or "items"
+//   - location_id: string - Where the content is integrated
+//   - difficulty: int - Generation difficulty (optional, default 5)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - content_type, location_id, difficulty: As generated
+//   - content: The integrated content
+//   - error: Invalid parameters, generation disabled, or a generation or
+//     integration failure
+func (s *RPCServer) handleAdminGenerateContent(params json.RawMessage) (interface{}, error) {
+	var req contentGenerationRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid content generation parameters", err.Error())
+	}
+	if err := s.validateContentGenerationParameters(&req); err != nil {
+		return nil, err
+	}
+	if pcg.ContentType(req.ContentType) == pcg.ContentTypeQuests {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "quests belong to a player, use generateQuest", nil)
+	}
+	if s.pcgManager == nil {
+		return nil, ErrUnavailable.WithMessage("content generation is not enabled")
+	}
+	req.SessionID = "" // No session to report progress to
+	s.applyContentGenerationDefaults(&req)
+
+	content, err := s.executeContentGeneration(context.Background(), &req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.integrateContent(req.LocationID, content); err != nil {
+		return nil, ErrContentInvalid.WithMessage("failed to integrate %s: %v", req.ContentType, err)
+	}
+	s.notifyWorldGenerated(pcg.ContentType(req.ContentType), req.LocationID)
+
+	return s.buildContentGenerationResponse(&req, content), nil
+}
+
+// handleAdminEndCombat ends the combat under way at once, whoever is left
+// standing.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - participants: The initiative order of the ended combat
+//   - rounds: Rounds completed
+//   - error: ErrNotInCombat when no combat is under way
+func (s *RPCServer) handleAdminEndCombat(params json.RawMessage) (interface{}, error) {
+	turns := s.state.TurnManager
+	if !turns.IsInCombat {
+		return nil, ErrNotInCombat
+	}
+
+	participants := slices.Clone(turns.Initiative)
+	rounds := turns.CurrentRound
+	s.endCombat()
+
+	return map[string]interface{}{
+		"success":      true,
+		"participants": participants,
+		"rounds":       rounds,
+	}, nil
+}
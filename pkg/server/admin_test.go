@@ -0,0 +1,175 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminToken = "0123456789abcdef0123456789abcdef"
+
+// newTestAdminConsole returns an admin console for testAdminToken with the
+// given permissions and a burst of burst calls
+func newTestAdminConsole(burst int, permissions ...string) *adminConsole {
+	return newAdminConsole(&config.Config{
+		AdminToken:                      testAdminToken,
+		AdminPermissions:                permissions,
+		AdminRateLimitRequestsPerSecond: 0.001,
+		AdminRateLimitBurst:             burst,
+	})
+}
+
+func TestAdminConsole_Authorize(t *testing.T) {
+	assert.Nil(t, newAdminConsole(&config.Config{}), "no token disables the console")
+
+	console := newTestAdminConsole(3, AdminPermissionInspect)
+	assert.NoError(t, console.authorize(MethodAdminListSessions, testAdminToken))
+	assert.ErrorIs(t, console.authorize(MethodAdminListSessions, "wrong"), ErrAdminUnauthorized)
+	assert.ErrorIs(t, console.authorize(MethodAdminGrantXP, testAdminToken), ErrAdminForbidden)
+	assert.NoError(t, console.authorize(MethodAdminInspectSession, testAdminToken))
+
+	// The burst of authorized calls is used up
+	err := console.authorize(MethodAdminListSessions, testAdminToken)
+	require.Error(t, err)
+	assert.Equal(t, JSONRPCRateLimited, err.(*JSONRPCError).Code)
+}
+
+func TestAdminConsole_GuessingDoesNotLockOutAdmin(t *testing.T) {
+	console := newTestAdminConsole(2, AdminPermissionInspect)
+	assert.ErrorIs(t, console.authorize(MethodAdminListSessions, "guess-1"), ErrAdminUnauthorized)
+	assert.ErrorIs(t, console.authorize(MethodAdminListSessions, "guess-2"), ErrAdminUnauthorized)
+
+	// Further guesses are throttled
+	err := console.authorize(MethodAdminListSessions, "guess-3")
+	require.Error(t, err)
+	assert.Equal(t, JSONRPCRateLimited, err.(*JSONRPCError).Code)
+
+	assert.NoError(t, console.authorize(MethodAdminListSessions, testAdminToken))
+	assert.NoError(t, console.authorize(MethodAdminInspectSession, testAdminToken))
+}
+
+func TestHandleMethod_AdminGate(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	call := func(method RPCMethod, params map[string]interface{}) (interface{}, error) {
+		data, err := json.Marshal(params)
+		require.NoError(t, err)
+		return server.handleMethod(method, data)
+	}
+
+	_, err := call(MethodAdminListSessions, map[string]interface{}{"admin_token": testAdminToken})
+	assert.ErrorIs(t, err, ErrUnavailable, "admin methods are disabled without a token")
+
+	server.admin = newTestAdminConsole(10, AdminPermissionInspect)
+	_, err = call(MethodAdminListSessions, map[string]interface{}{"admin_token": "not-the-token"})
+	assert.ErrorIs(t, err, ErrAdminUnauthorized)
+	_, err = call(MethodAdminEndCombat, map[string]interface{}{"admin_token": testAdminToken})
+	assert.ErrorIs(t, err, ErrAdminForbidden)
+
+	result, err := call(MethodAdminListSessions, map[string]interface{}{"admin_token": testAdminToken})
+	require.NoError(t, err)
+	sessions := result.(map[string]interface{})["sessions"].([]map[string]interface{})
+	require.Len(t, sessions, 1)
+	assert.Equal(t, session.SessionID, sessions[0]["session_id"])
+	assert.Equal(t, session.Player.GetID(), sessions[0]["player_id"])
+}
+
+func TestAdminHandlers_PlayerActions(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	server.state.WorldState.Width, server.state.WorldState.Height = 20, 20
+
+	result, err := callAdmin(t, server.handleAdminInspectSession, map[string]interface{}{"session_id": session.SessionID})
+	require.NoError(t, err)
+	assert.Equal(t, session.Player.GetID(), result["player"].(*game.Player).GetID())
+	assert.Equal(t, false, result["in_combat"])
+	_, err = callAdmin(t, server.handleAdminInspectSession, map[string]interface{}{"session_id": "missing"})
+	assert.ErrorIs(t, err, ErrInvalidSession)
+
+	result, err = callAdmin(t, server.handleAdminTeleportPlayer, map[string]interface{}{
+		"session_id": session.SessionID,
+		"position":   map[string]interface{}{"x": 3, "y": 4},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, game.Position{X: 3, Y: 4}, session.Player.GetPosition())
+	assert.Equal(t, 10, result["from"].(game.Position).X)
+	_, err = callAdmin(t, server.handleAdminTeleportPlayer, map[string]interface{}{
+		"session_id": session.SessionID,
+		"position":   map[string]interface{}{"x": 50, "y": 4},
+	})
+	assert.Error(t, err)
+
+	experience := session.Player.Clone().Experience
+	result, err = callAdmin(t, server.handleAdminGrantXP, map[string]interface{}{"session_id": session.SessionID, "amount": 250})
+	require.NoError(t, err)
+	assert.Equal(t, experience+250, result["experience"])
+	_, err = callAdmin(t, server.handleAdminGrantXP, map[string]interface{}{"session_id": session.SessionID, "amount": 0})
+	assert.Error(t, err)
+
+	result, err = callAdmin(t, server.handleAdminGrantItem, map[string]interface{}{
+		"session_id": session.SessionID,
+		"item":       map[string]interface{}{"name": "Healing Potion", "type": "potion", "value": 50},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Healing Potion", result["item"].(game.Item).Name)
+	require.Len(t, session.Player.Inventory, 1)
+	assert.Equal(t, 0, server.journal.size(session.SessionID), "admin grants are not journaled")
+}
+
+func TestAdminHandlers_WorldActions(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+	server.state.WorldState.Width, server.state.WorldState.Height = 20, 20
+
+	result, err := callAdmin(t, server.handleAdminSpawnEntity, map[string]interface{}{
+		"kind":     "orc",
+		"count":    3,
+		"level":    2,
+		"position": map[string]interface{}{"x": 5, "y": 5},
+	})
+	require.NoError(t, err)
+	spawned := result["spawned"].([]*game.NPC)
+	require.Len(t, spawned, 3)
+	assert.Equal(t, game.Position{X: 5, Y: 5}, spawned[0].GetPosition())
+	for _, npc := range spawned {
+		_, exists := server.state.WorldState.Objects[npc.GetID()]
+		assert.True(t, exists)
+		assert.True(t, npc.HasTag("hostile"))
+	}
+	_, err = callAdmin(t, server.handleAdminSpawnEntity, map[string]interface{}{
+		"kind":     "orc",
+		"position": map[string]interface{}{"x": 30, "y": 5},
+	})
+	assert.Error(t, err)
+
+	_, err = callAdmin(t, server.handleAdminEndCombat, map[string]interface{}{})
+	assert.ErrorIs(t, err, ErrNotInCombat)
+	server.state.TurnManager.IsInCombat = true
+	server.state.TurnManager.Initiative = []string{"test-player-001", spawned[0].GetID()}
+	result, err = callAdmin(t, server.handleAdminEndCombat, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test-player-001", spawned[0].GetID()}, result["participants"])
+	assert.False(t, server.state.TurnManager.IsInCombat)
+
+	result, err = callAdmin(t, server.handleAdminGenerateContent, map[string]interface{}{
+		"content_type": "items",
+		"location_id":  "admin_cache",
+		"difficulty":   3,
+	})
+	require.NoError(t, err)
+	items := result["content"].([]*game.Item)
+	require.NotEmpty(t, items)
+	_, exists := server.state.WorldState.Objects[items[0].ID]
+	assert.True(t, exists, "generated items are integrated into the world")
+
+	_, err = callAdmin(t, server.handleAdminGenerateContent, map[string]interface{}{
+		"content_type": "quests",
+		"location_id":  "admin_cache",
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,690 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/retry"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// AnalyticsEventType names a kind of anonymized gameplay event exported to
+// analytics sinks.
+type AnalyticsEventType string
+
+const (
+	// AnalyticsQuestCompleted records a completed quest and how long the
+	// player took to complete it
+	AnalyticsQuestCompleted AnalyticsEventType = "quest_completed"
+	// AnalyticsQuestFailed records a failed quest and how long the player
+	// spent on it
+	AnalyticsQuestFailed AnalyticsEventType = "quest_failed"
+	// AnalyticsPlayerDied records the death of a player character and the
+	// encounter or feature that killed it
+	AnalyticsPlayerDied AnalyticsEventType = "player_died"
+	// AnalyticsFeatureUsed records a call of a player-facing RPC method
+	AnalyticsFeatureUsed AnalyticsEventType = "feature_used"
+)
+
+const (
+	// analyticsHTTPTimeout is the timeout of a single http sink request
+	analyticsHTTPTimeout = 10 * time.Second
+	// analyticsCloseTimeout bounds the final flush when the server stops
+	analyticsCloseTimeout = 5 * time.Second
+	// analyticsMaxOpenQuests bounds the quest start times kept to time
+	// completions; quests started beyond it are reported without a duration
+	analyticsMaxOpenQuests = 10000
+	// analyticsOutsideCombat is the encounter of deaths outside combat
+	analyticsOutsideCombat = "outside combat"
+)
+
+// AnalyticsEvent is one anonymized gameplay event. Player is a salted hash
+// of the player ID, so events of one player can be told apart from those
+// of others without identifying them; events carry no names or session
+// IDs.
+type AnalyticsEvent struct {
+	Type      AnalyticsEventType     `json:"type"`
+	Timestamp time.Time              `json:"timestamp"`
+	Player    string                 `json:"player,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// AnalyticsSink receives batches of analytics events. The exporter writes
+// batches from a single goroutine, so sinks need not be safe for
+// concurrent use.
+type AnalyticsSink interface {
+	// Name identifies the sink in stats and metrics
+	Name() string
+	// Write exports a batch of events. The batch is reused once Write
+	// returns.
+	Write(ctx context.Context, batch []AnalyticsEvent) error
+	// Close releases the sink after the final batch
+	Close() error
+}
+
+// jsonLinesSink writes each event as a line of JSON.
+type jsonLinesSink struct {
+	name   string
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewWriterAnalyticsSink creates a sink writing events as JSON lines to w.
+// The stdout sink is a writer sink for os.Stdout.
+func NewWriterAnalyticsSink(name string, w io.Writer) AnalyticsSink {
+	return &jsonLinesSink{name: name, w: w}
+}
+
+// NewFileAnalyticsSink creates a sink appending events as JSON lines to
+// the file at path, creating it and its directory if needed.
+func NewFileAnalyticsSink(path string) (AnalyticsSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create analytics directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analytics file: %w", err)
+	}
+	return &jsonLinesSink{name: "file", w: file, closer: file}, nil
+}
+
+// Name implements AnalyticsSink.
+func (s *jsonLinesSink) Name() string {
+	return s.name
+}
+
+// Write implements AnalyticsSink. The batch is encoded before it is
+// written, so a batch is written whole or not at all.
+func (s *jsonLinesSink) Write(_ context.Context, batch []AnalyticsEvent) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range batch {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode analytics event: %w", err)
+		}
+	}
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+// Close implements AnalyticsSink.
+func (s *jsonLinesSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// httpSink POSTs each batch as a JSON document.
+type httpSink struct {
+	url     string
+	client  *http.Client
+	retrier *retry.Retrier
+}
+
+// NewHTTPAnalyticsSink creates a sink POSTing each batch to url as
+// {"events": [...]}. Failed requests, including non-2xx responses, are
+// retried as retryConfig allows.
+func NewHTTPAnalyticsSink(url string, retryConfig retry.RetryConfig) AnalyticsSink {
+	return &httpSink{
+		url:     url,
+		client:  &http.Client{Timeout: analyticsHTTPTimeout},
+		retrier: retry.NewRetrier(retryConfig),
+	}
+}
+
+// Name implements AnalyticsSink.
+func (s *httpSink) Name() string {
+	return "http"
+}
+
+// Write implements AnalyticsSink.
+func (s *httpSink) Write(ctx context.Context, batch []AnalyticsEvent) error {
+	body, err := json.Marshal(map[string]interface{}{"events": batch})
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics batch: %w", err)
+	}
+
+	return s.retrier.Execute(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create analytics request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("analytics endpoint returned status %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// Close implements AnalyticsSink.
+func (s *httpSink) Close() error {
+	return nil
+}
+
+// questRecorder takes quest completions into content quality analysis. The
+// PCG manager satisfies it.
+type questRecorder interface {
+	RecordQuestCompletion(questID string, completionTime time.Duration, completed bool)
+}
+
+// qualitySink feeds quest completions and failures back into the PCG
+// quality metrics, where they count towards the engagement score.
+type qualitySink struct {
+	recorder questRecorder
+}
+
+// Name implements AnalyticsSink.
+func (s *qualitySink) Name() string {
+	return "quality"
+}
+
+// Write implements AnalyticsSink.
+func (s *qualitySink) Write(_ context.Context, batch []AnalyticsEvent) error {
+	for _, event := range batch {
+		if event.Type != AnalyticsQuestCompleted && event.Type != AnalyticsQuestFailed {
+			continue
+		}
+		questID, _ := event.Data["quest_id"].(string)
+		seconds, _ := event.Data["duration_seconds"].(float64)
+		s.recorder.RecordQuestCompletion(questID, time.Duration(seconds*float64(time.Second)), event.Type == AnalyticsQuestCompleted)
+	}
+	return nil
+}
+
+// Close implements AnalyticsSink.
+func (s *qualitySink) Close() error {
+	return nil
+}
+
+// AnalyticsConfig configures an AnalyticsExporter.
+type AnalyticsConfig struct {
+	Salt          string        // Key of the player ID hash; empty picks a random one
+	BatchSize     int           // Largest number of events written at once
+	FlushInterval time.Duration // Longest time an event waits for its batch
+	QueueSize     int           // Pending events before new ones are dropped
+}
+
+// AnalyticsStats holds the counters of an AnalyticsExporter.
+type AnalyticsStats struct {
+	Recorded uint64                        `json:"recorded"` // Events queued for export
+	Dropped  uint64                        `json:"dropped"`  // Events discarded because the queue was full
+	Sinks    map[string]AnalyticsSinkStats `json:"sinks"`
+}
+
+// AnalyticsSinkStats holds the counters of a single sink.
+type AnalyticsSinkStats struct {
+	Exported uint64 `json:"exported"` // Events the sink wrote
+	Failed   uint64 `json:"failed"`   // Events of batches the sink failed to write
+}
+
+// AnalyticsExporter streams anonymized gameplay events to analytics sinks,
+// feeding player behavior back into content quality analysis. Events are
+// queued and written in batches by a background goroutine, so game
+// handlers never block on sinks: when slow sinks let the queue fill up,
+// new events are dropped and counted.
+type AnalyticsExporter struct {
+	config AnalyticsConfig
+	sinks  []AnalyticsSink
+	salt   []byte
+	queue  chan AnalyticsEvent
+
+	// stopped is closed once the final batch is written and the sinks are
+	// closed
+	stopped chan struct{}
+
+	mu          sync.Mutex
+	recorded    uint64
+	dropped     uint64
+	sinkStats   map[string]*AnalyticsSinkStats
+	questStarts map[string]time.Time
+}
+
+// NewAnalyticsExporter creates an exporter writing to sinks. It returns an
+// error when cfg cannot batch or queue events.
+func NewAnalyticsExporter(cfg AnalyticsConfig, sinks ...AnalyticsSink) (*AnalyticsExporter, error) {
+	if cfg.BatchSize <= 0 || cfg.FlushInterval <= 0 || cfg.QueueSize <= 0 {
+		return nil, fmt.Errorf("analytics batch size, flush interval and queue size must be positive")
+	}
+
+	salt := []byte(cfg.Salt)
+	if len(salt) == 0 {
+		salt = make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate analytics salt: %w", err)
+		}
+	}
+
+	sinkStats := make(map[string]*AnalyticsSinkStats, len(sinks))
+	for _, sink := range sinks {
+		sinkStats[sink.Name()] = &AnalyticsSinkStats{}
+	}
+
+	return &AnalyticsExporter{
+		config:      cfg,
+		sinks:       sinks,
+		salt:        salt,
+		queue:       make(chan AnalyticsEvent, cfg.QueueSize),
+		stopped:     make(chan struct{}),
+		sinkStats:   sinkStats,
+		questStarts: make(map[string]time.Time),
+	}, nil
+}
+
+// newAnalyticsConfig builds an AnalyticsConfig from the server
+// configuration.
+func newAnalyticsConfig(cfg *config.Config) AnalyticsConfig {
+	return AnalyticsConfig{
+		Salt:          cfg.AnalyticsSalt,
+		BatchSize:     cfg.AnalyticsBatchSize,
+		FlushInterval: cfg.AnalyticsFlushInterval,
+		QueueSize:     cfg.AnalyticsQueueSize,
+	}
+}
+
+// newAnalyticsSinks creates the sinks named in the server configuration.
+// The http sink retries failed batches like webhook deliveries.
+func newAnalyticsSinks(cfg *config.Config) ([]AnalyticsSink, error) {
+	var sinks []AnalyticsSink
+	for _, name := range cfg.AnalyticsSinks {
+		switch name {
+		case "stdout":
+			sinks = append(sinks, NewWriterAnalyticsSink("stdout", os.Stdout))
+		case "file":
+			path := cfg.AnalyticsFile
+			if path == "" {
+				path = filepath.Join(cfg.DataDir, "analytics.jsonl")
+			}
+			sink, err := NewFileAnalyticsSink(path)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case "http":
+			sinks = append(sinks, NewHTTPAnalyticsSink(cfg.AnalyticsURL, newWebhookConfig(cfg).Retry))
+		default:
+			return nil, fmt.Errorf("unknown analytics sink: %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// Anonymize returns the stand-in of playerID in events: the hex encoded
+// HMAC-SHA256 of the ID keyed with the exporter's salt.
+func (e *AnalyticsExporter) Anonymize(playerID string) string {
+	mac := hmac.New(sha256.New, e.salt)
+	mac.Write([]byte(playerID))
+	return "player-" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Record queues an event of playerID, who is anonymized; an empty
+// playerID records an event of no player. Record never blocks: when the
+// queue is full the event is dropped and counted.
+func (e *AnalyticsExporter) Record(eventType AnalyticsEventType, playerID string, data map[string]interface{}) {
+	event := AnalyticsEvent{
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+	if playerID != "" {
+		event.Player = e.Anonymize(playerID)
+	}
+
+	select {
+	case e.queue <- event:
+		e.mu.Lock()
+		e.recorded++
+		e.mu.Unlock()
+	default:
+		e.mu.Lock()
+		e.dropped++
+		e.mu.Unlock()
+		logrus.WithFields(logrus.Fields{
+			"function": "Record",
+			"event":    eventType,
+		}).Warn("analytics queue full, dropping event")
+	}
+}
+
+// QuestStarted notes when playerID started questID, so its completion can
+// be timed.
+func (e *AnalyticsExporter) QuestStarted(playerID, questID string, at time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.questStarts) < analyticsMaxOpenQuests {
+		e.questStarts[playerID+"\x00"+questID] = at
+	}
+}
+
+// QuestEnded records a quest_completed or quest_failed event for questID,
+// with the time since playerID started it when that is known.
+func (e *AnalyticsExporter) QuestEnded(playerID, questID string, completed bool, at time.Time) {
+	key := playerID + "\x00" + questID
+	e.mu.Lock()
+	started, known := e.questStarts[key]
+	delete(e.questStarts, key)
+	e.mu.Unlock()
+
+	data := map[string]interface{}{"quest_id": questID}
+	if known {
+		data["duration_seconds"] = at.Sub(started).Seconds()
+	}
+	eventType := AnalyticsQuestFailed
+	if completed {
+		eventType = AnalyticsQuestCompleted
+	}
+	e.Record(eventType, playerID, data)
+}
+
+// Start launches the export goroutine. It writes a batch when it is full or
+// the flush interval passes; once done is closed it writes the events
+// still queued and closes the sinks.
+func (e *AnalyticsExporter) Start(done <-chan struct{}) {
+	go func() {
+		defer close(e.stopped)
+		ticker := time.NewTicker(e.config.FlushInterval)
+		defer ticker.Stop()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		batch := make([]AnalyticsEvent, 0, e.config.BatchSize)
+
+		for {
+			select {
+			case event := <-e.queue:
+				batch = append(batch, event)
+				if len(batch) >= e.config.BatchSize {
+					e.flush(ctx, batch)
+					batch = batch[:0]
+				}
+			case <-ticker.C:
+				if len(batch) > 0 {
+					e.flush(ctx, batch)
+					batch = batch[:0]
+				}
+			case <-done:
+				e.drain(batch)
+				return
+			}
+		}
+	}()
+}
+
+// drain writes batch and the events still queued within
+// analyticsCloseTimeout, then closes the sinks.
+func (e *AnalyticsExporter) drain(batch []AnalyticsEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), analyticsCloseTimeout)
+	defer cancel()
+
+	// The export goroutine is the only reader, so queued events are
+	// received without blocking
+	for len(e.queue) > 0 {
+		batch = append(batch, <-e.queue)
+		if len(batch) >= e.config.BatchSize {
+			e.flush(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		e.flush(ctx, batch)
+	}
+
+	for _, sink := range e.sinks {
+		if err := sink.Close(); err != nil {
+			logrus.WithError(err).WithField("sink", sink.Name()).Warn("failed to close analytics sink")
+		}
+	}
+}
+
+// flush writes batch to every sink. A sink that fails does not keep the
+// others from receiving the batch.
+func (e *AnalyticsExporter) flush(ctx context.Context, batch []AnalyticsEvent) {
+	for _, sink := range e.sinks {
+		err := sink.Write(ctx, batch)
+
+		e.mu.Lock()
+		stats := e.sinkStats[sink.Name()]
+		if err != nil {
+			stats.Failed += uint64(len(batch))
+		} else {
+			stats.Exported += uint64(len(batch))
+		}
+		e.mu.Unlock()
+
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "flush",
+				"sink":     sink.Name(),
+				"events":   len(batch),
+			}).WithError(err).Warn("analytics sink failed to write batch")
+		}
+	}
+}
+
+// Stats returns a snapshot of the exporter's counters.
+func (e *AnalyticsExporter) Stats() AnalyticsStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats := AnalyticsStats{
+		Recorded: e.recorded,
+		Dropped:  e.dropped,
+		Sinks:    make(map[string]AnalyticsSinkStats, len(e.sinkStats)),
+	}
+	for name, sink := range e.sinkStats {
+		stats.Sinks[name] = *sink
+	}
+	return stats
+}
+
+// attachAnalytics creates the analytics exporter unless analytics are
+// opted out of, and subscribes it to quest updates. Quest completions feed
+// the PCG quality metrics even when no sinks are configured.
+func (s *RPCServer) attachAnalytics() error {
+	if !s.config.AnalyticsEnabled {
+		return nil
+	}
+
+	sinks, err := newAnalyticsSinks(s.config)
+	if err != nil {
+		return fmt.Errorf("failed to configure analytics: %w", err)
+	}
+	if s.pcgManager != nil {
+		sinks = append(sinks, &qualitySink{recorder: s.pcgManager})
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	exporter, err := NewAnalyticsExporter(newAnalyticsConfig(s.config), sinks...)
+	if err != nil {
+		return fmt.Errorf("failed to configure analytics: %w", err)
+	}
+	s.analytics = exporter
+	if s.metrics != nil {
+		if err := s.metrics.RegisterAnalyticsMetrics(exporter); err != nil {
+			logrus.WithError(err).Warn("failed to register analytics metrics")
+		}
+	}
+
+	s.eventSys.Subscribe(game.EventQuestUpdate, func(event game.GameEvent) {
+		questID, _ := event.Data["quest_id"].(string)
+		at := time.Unix(event.Timestamp, 0)
+		switch event.Data["action"] {
+		case "started":
+			exporter.QuestStarted(event.SourceID, questID, at)
+		case "completed":
+			exporter.QuestEnded(event.SourceID, questID, true, at)
+		case "failed":
+			exporter.QuestEnded(event.SourceID, questID, false, at)
+		}
+	})
+
+	exporter.Start(s.done)
+	return nil
+}
+
+// recordFeatureUsage records a call of a player-facing method. Admin
+// methods are the operators' rather than the players' and are left out.
+func (s *RPCServer) recordFeatureUsage(method RPCMethod, params json.RawMessage, result interface{}, err error) {
+	if s.analytics == nil || isAdminMethod(method) {
+		return
+	}
+
+	var playerID string
+	if sessionID := eventSessionID(params, result); sessionID != "" {
+		s.mu.RLock()
+		if session, ok := s.sessions[sessionID]; ok && session.Player != nil {
+			playerID = session.Player.GetID()
+		}
+		s.mu.RUnlock()
+	}
+	s.analytics.Record(AnalyticsFeatureUsed, playerID, map[string]interface{}{
+		"method":  string(method),
+		"success": err == nil,
+	})
+}
+
+// recordCombatDeath records the death of a player character from damage,
+// naming the encounter by the opponents in the combat in progress.
+func (s *RPCServer) recordCombatDeath(target game.GameObject) {
+	player, isPlayer := target.(*game.Player)
+	if s.analytics == nil || !isPlayer {
+		return
+	}
+
+	data := map[string]interface{}{
+		"source":          "combat",
+		"encounter":       analyticsOutsideCombat,
+		"character_level": player.Level,
+	}
+	if id, participants, ok := s.combatLogs.current(); ok {
+		data["encounter_id"] = id
+		data["encounter"] = s.encounterName(participants, player.GetID())
+		if s.state.TurnManager != nil {
+			data["round"] = s.state.TurnManager.CurrentRound
+		}
+	}
+	s.analytics.Record(AnalyticsPlayerDied, player.GetID(), data)
+}
+
+// recordFeatureDeath records the death of a player character to an
+// interactive feature, such as a trap.
+func (s *RPCServer) recordFeatureDeath(player *game.Player, feature game.GameObject) {
+	if s.analytics == nil {
+		return
+	}
+	s.analytics.Record(AnalyticsPlayerDied, player.GetID(), map[string]interface{}{
+		"source":          "feature",
+		"encounter":       feature.GetName(),
+		"character_level": player.Level,
+	})
+}
+
+// encounterName names an encounter by the players' opponents in it, such
+// as "goblin x2, orc". Players and the victim are left out, so the name
+// describes content rather than who took part.
+func (s *RPCServer) encounterName(participants []string, victimID string) string {
+	counts := make(map[string]int)
+	if s.state.WorldState != nil {
+		for _, id := range participants {
+			obj, exists := s.state.WorldState.Objects[id]
+			if !exists || id == victimID {
+				continue
+			}
+			if _, isPlayer := obj.(*game.Player); isPlayer {
+				continue
+			}
+			counts[obj.GetName()]++
+		}
+	}
+	if len(counts) == 0 {
+		return "unknown"
+	}
+
+	names := make([]string, 0, len(counts))
+	for name, count := range counts {
+		if count > 1 {
+			name = fmt.Sprintf("%s x%d", name, count)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// analyticsCollector exports analytics counters, read from the exporter at
+// scrape time.
+type analyticsCollector struct {
+	exporter *AnalyticsExporter
+	events   *prometheus.Desc
+	sinks    *prometheus.Desc
+}
+
+// newAnalyticsCollector creates a collector for exporter.
+func newAnalyticsCollector(exporter *AnalyticsExporter) *analyticsCollector {
+	return &analyticsCollector{
+		exporter: exporter,
+		events: prometheus.NewDesc(
+			"goldbox_analytics_events_total",
+			"Total number of analytics events by result",
+			[]string{"result"}, nil,
+		),
+		sinks: prometheus.NewDesc(
+			"goldbox_analytics_sink_events_total",
+			"Total number of analytics events written by sink and result",
+			[]string{"sink", "result"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *analyticsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.events
+	ch <- c.sinks
+}
+
+// Collect implements prometheus.Collector.
+func (c *analyticsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.exporter.Stats()
+	ch <- prometheus.MustNewConstMetric(c.events, prometheus.CounterValue, float64(stats.Recorded), "recorded")
+	ch <- prometheus.MustNewConstMetric(c.events, prometheus.CounterValue, float64(stats.Dropped), "dropped")
+	for name, sink := range stats.Sinks {
+		ch <- prometheus.MustNewConstMetric(c.sinks, prometheus.CounterValue, float64(sink.Exported), name, "exported")
+		ch <- prometheus.MustNewConstMetric(c.sinks, prometheus.CounterValue, float64(sink.Failed), name, "failed")
+	}
+}
+
+// RegisterAnalyticsMetrics exports analytics counters on the metrics
+// endpoint.
+func (m *Metrics) RegisterAnalyticsMetrics(exporter *AnalyticsExporter) error {
+	return m.registry.Register(newAnalyticsCollector(exporter))
+}
@@ -0,0 +1,304 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/retry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink passes each batch it is written to a channel
+type recordingSink struct {
+	batches chan []AnalyticsEvent
+	closed  atomic.Bool
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{batches: make(chan []AnalyticsEvent, 16)}
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Write(_ context.Context, batch []AnalyticsEvent) error {
+	s.batches <- append([]AnalyticsEvent(nil), batch...)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.closed.Store(true)
+	return nil
+}
+
+// next waits for the next batch written to the sink
+func (s *recordingSink) next(t *testing.T) []AnalyticsEvent {
+	t.Helper()
+	select {
+	case batch := <-s.batches:
+		return batch
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an analytics batch")
+		return nil
+	}
+}
+
+// newTestAnalyticsExporter creates an exporter with a fixed salt
+func newTestAnalyticsExporter(t *testing.T, batchSize, queueSize int, sinks ...AnalyticsSink) *AnalyticsExporter {
+	t.Helper()
+	exporter, err := NewAnalyticsExporter(AnalyticsConfig{
+		Salt:          "pepper",
+		BatchSize:     batchSize,
+		FlushInterval: time.Hour,
+		QueueSize:     queueSize,
+	}, sinks...)
+	require.NoError(t, err)
+	return exporter
+}
+
+// nextQueuedAnalyticsEvent returns the next event recorded by exporter
+func nextQueuedAnalyticsEvent(t *testing.T, exporter *AnalyticsExporter) AnalyticsEvent {
+	t.Helper()
+	select {
+	case event := <-exporter.queue:
+		return event
+	default:
+		t.Fatal("expected a queued analytics event")
+		return AnalyticsEvent{}
+	}
+}
+
+func TestAnalyticsExporter_Batches(t *testing.T) {
+	sink := newRecordingSink()
+	exporter := newTestAnalyticsExporter(t, 2, 16, sink)
+	done := make(chan struct{})
+	exporter.Start(done)
+
+	for _, method := range []string{"move", "attack", "castSpell"} {
+		exporter.Record(AnalyticsFeatureUsed, "hero", map[string]interface{}{"method": method})
+	}
+	batch := sink.next(t)
+	require.Len(t, batch, 2, "a full batch is written at once")
+	assert.Equal(t, "move", batch[0].Data["method"])
+
+	close(done)
+	<-exporter.stopped
+	batch = sink.next(t)
+	require.Len(t, batch, 1, "the partial batch is written when the server stops")
+	assert.Equal(t, "castSpell", batch[0].Data["method"])
+	assert.True(t, sink.closed.Load())
+
+	stats := exporter.Stats()
+	assert.Equal(t, uint64(3), stats.Recorded)
+	assert.Equal(t, uint64(3), stats.Sinks["recording"].Exported)
+}
+
+func TestAnalyticsExporter_FlushInterval(t *testing.T) {
+	sink := newRecordingSink()
+	exporter, err := NewAnalyticsExporter(AnalyticsConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond, QueueSize: 16}, sink)
+	require.NoError(t, err)
+	done := make(chan struct{})
+	defer close(done)
+	exporter.Start(done)
+
+	exporter.Record(AnalyticsFeatureUsed, "hero", nil)
+	assert.Len(t, sink.next(t), 1)
+}
+
+func TestAnalyticsExporter_DropsWhenQueueFull(t *testing.T) {
+	exporter := newTestAnalyticsExporter(t, 10, 2)
+	for i := 0; i < 5; i++ {
+		exporter.Record(AnalyticsFeatureUsed, "hero", nil)
+	}
+	stats := exporter.Stats()
+	assert.Equal(t, uint64(2), stats.Recorded)
+	assert.Equal(t, uint64(3), stats.Dropped, "recording never blocks on a full queue")
+}
+
+func TestAnalyticsExporter_Anonymizes(t *testing.T) {
+	var out bytes.Buffer
+	exporter := newTestAnalyticsExporter(t, 10, 16, NewWriterAnalyticsSink("stdout", &out))
+
+	anonymized := exporter.Anonymize("hero")
+	assert.Equal(t, anonymized, newTestAnalyticsExporter(t, 10, 16).Anonymize("hero"), "the same salt gives the same stand-in")
+	assert.NotEqual(t, anonymized, exporter.Anonymize("villain"))
+	assert.True(t, strings.HasPrefix(anonymized, "player-"))
+
+	random, err := NewAnalyticsExporter(AnalyticsConfig{BatchSize: 1, FlushInterval: time.Second, QueueSize: 1})
+	require.NoError(t, err)
+	assert.NotEqual(t, anonymized, random.Anonymize("hero"), "an empty salt is replaced with a random one")
+
+	exporter.Record(AnalyticsFeatureUsed, "hero", map[string]interface{}{"method": "move"})
+	exporter.flush(context.Background(), []AnalyticsEvent{nextQueuedAnalyticsEvent(t, exporter)})
+	assert.NotContains(t, out.String(), `"hero"`)
+
+	var event AnalyticsEvent
+	require.NoError(t, json.Unmarshal(out.Bytes(), &event))
+	assert.Equal(t, AnalyticsFeatureUsed, event.Type)
+	assert.Equal(t, anonymized, event.Player)
+}
+
+func TestFileAnalyticsSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analytics", "events.jsonl")
+	batch := []AnalyticsEvent{{Type: AnalyticsFeatureUsed}, {Type: AnalyticsPlayerDied}}
+
+	for i := 0; i < 2; i++ {
+		sink, err := NewFileAnalyticsSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(context.Background(), batch))
+		require.NoError(t, sink.Close())
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 4, "reopened files are appended to")
+}
+
+func TestHTTPAnalyticsSink_RetriesFailedBatches(t *testing.T) {
+	var attempts atomic.Int32
+	var received struct {
+		Events []AnalyticsEvent `json:"events"`
+	}
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer endpoint.Close()
+
+	sink := NewHTTPAnalyticsSink(endpoint.URL, retry.RetryConfig{
+		MaxAttempts:       3,
+		InitialDelay:      time.Millisecond,
+		MaxDelay:          5 * time.Millisecond,
+		BackoffMultiplier: 2.0,
+	})
+	require.NoError(t, sink.Write(context.Background(), []AnalyticsEvent{{Type: AnalyticsPlayerDied}}))
+	assert.Equal(t, int32(2), attempts.Load())
+	require.Len(t, received.Events, 1)
+	assert.Equal(t, AnalyticsPlayerDied, received.Events[0].Type)
+}
+
+// questRecording is a questRecorder remembering the last completion
+type questRecording struct {
+	questID   string
+	duration  time.Duration
+	completed bool
+}
+
+func (r *questRecording) RecordQuestCompletion(questID string, completionTime time.Duration, completed bool) {
+	r.questID, r.duration, r.completed = questID, completionTime, completed
+}
+
+func TestAnalyticsExporter_TimesQuests(t *testing.T) {
+	exporter := newTestAnalyticsExporter(t, 10, 16)
+	started := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	exporter.QuestStarted("hero", "rat_cellar", started)
+	exporter.QuestEnded("hero", "rat_cellar", true, started.Add(90*time.Second))
+	completed := nextQueuedAnalyticsEvent(t, exporter)
+	assert.Equal(t, AnalyticsQuestCompleted, completed.Type)
+	assert.Equal(t, 90.0, completed.Data["duration_seconds"])
+
+	exporter.QuestEnded("hero", "lost_ring", false, started)
+	failed := nextQueuedAnalyticsEvent(t, exporter)
+	assert.Equal(t, AnalyticsQuestFailed, failed.Type)
+	assert.NotContains(t, failed.Data, "duration_seconds", "quests started before the server was are not timed")
+
+	recorder := &questRecording{}
+	sink := &qualitySink{recorder: recorder}
+	require.NoError(t, sink.Write(context.Background(), []AnalyticsEvent{completed}))
+	assert.Equal(t, questRecording{questID: "rat_cellar", duration: 90 * time.Second, completed: true}, *recorder)
+}
+
+func TestRecordCombatDeath(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	exporter := newTestAnalyticsExporter(t, 10, 16)
+	server.analytics = exporter
+
+	player := &game.Player{Character: game.Character{ID: "hero", Name: "Hero", HP: 5}, Level: 3}
+	require.NoError(t, server.applyDamage(player, 10))
+	event := nextQueuedAnalyticsEvent(t, exporter)
+	assert.Equal(t, AnalyticsPlayerDied, event.Type)
+	assert.Equal(t, analyticsOutsideCombat, event.Data["encounter"])
+	assert.Equal(t, 3, event.Data["character_level"])
+
+	for _, npc := range []*game.NPC{
+		{Character: game.Character{ID: "goblin-1", Name: "Goblin"}},
+		{Character: game.Character{ID: "goblin-2", Name: "Goblin"}},
+		{Character: game.Character{ID: "orc-1", Name: "Orc"}},
+	} {
+		server.state.WorldState.Objects[npc.ID] = npc
+	}
+	server.state.WorldState.Objects["hero"] = player
+	server.combatLogs.begin(&CombatEncounter{ID: "encounter-1", Participants: []string{"hero", "goblin-1", "goblin-2", "orc-1"}})
+
+	player.HP = 5
+	require.NoError(t, server.applyDamage(player, 10))
+	event = nextQueuedAnalyticsEvent(t, exporter)
+	assert.Equal(t, "Goblin x2, Orc", event.Data["encounter"])
+	assert.Equal(t, "encounter-1", event.Data["encounter_id"])
+	assert.Equal(t, exporter.Anonymize("hero"), event.Player)
+
+	orc := &game.Character{ID: "orc-2", Name: "Orc", HP: 5}
+	require.NoError(t, server.applyDamage(orc, 10))
+	assert.Empty(t, exporter.queue, "monster deaths are not recorded")
+}
+
+func TestRecordFeatureUsage(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	exporter := newTestAnalyticsExporter(t, 10, 16)
+	server.analytics = exporter
+
+	params, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID})
+	require.NoError(t, err)
+	_, err = server.handleMethod(MethodGetCombatLog, params)
+	require.Error(t, err, "the session has fought no combat")
+
+	event := nextQueuedAnalyticsEvent(t, exporter)
+	assert.Equal(t, AnalyticsFeatureUsed, event.Type)
+	assert.Equal(t, string(MethodGetCombatLog), event.Data["method"])
+	assert.Equal(t, false, event.Data["success"])
+	assert.Equal(t, exporter.Anonymize(session.Player.ID), event.Player)
+	assert.NotContains(t, event.Data, "session_id")
+}
+
+func TestAttachAnalytics_OptOut(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	require.NotNil(t, server.analytics, "quest outcomes feed the PCG quality metrics by default")
+
+	server.analytics = nil
+	server.config.AnalyticsEnabled = false
+	require.NoError(t, server.attachAnalytics())
+	assert.Nil(t, server.analytics)
+}
+
+func TestMetrics_RegisterAnalyticsMetrics(t *testing.T) {
+	exporter := newTestAnalyticsExporter(t, 10, 1, newRecordingSink())
+	exporter.Record(AnalyticsFeatureUsed, "hero", nil)
+	exporter.Record(AnalyticsFeatureUsed, "hero", nil)
+	exporter.flush(context.Background(), []AnalyticsEvent{nextQueuedAnalyticsEvent(t, exporter)})
+
+	metrics := NewMetrics()
+	require.NoError(t, metrics.RegisterAnalyticsMetrics(exporter))
+
+	body := scrapeMetrics(t, metrics)
+	assert.Contains(t, body, `goldbox_analytics_events_total{result="recorded"} 1`)
+	assert.Contains(t, body, `goldbox_analytics_events_total{result="dropped"} 1`)
+	assert.Contains(t, body, `goldbox_analytics_sink_events_total{result="exported",sink="recording"} 1`)
+}
@@ -0,0 +1,270 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// AnnotationState is the saved form of the map annotations
+type AnnotationState struct {
+	Levels map[int][]game.MapAnnotation `yaml:"levels"` // Annotations by level index, oldest first
+}
+
+// annotationBook holds the notes, markers and region labels game masters
+// attach to each level of the map. The zero value is ready to use.
+type annotationBook struct {
+	mu     sync.Mutex
+	levels map[int][]game.MapAnnotation
+}
+
+// add appends annotation to its level, refusing it once the level holds
+// MaxAnnotationsPerLevel.
+func (b *annotationBook) add(annotation game.MapAnnotation) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.levels == nil {
+		b.levels = make(map[int][]game.MapAnnotation)
+	}
+	if len(b.levels[annotation.Level]) >= MaxAnnotationsPerLevel {
+		return false
+	}
+	b.levels[annotation.Level] = append(b.levels[annotation.Level], annotation)
+	return true
+}
+
+// onLevel returns the annotations of level the game master (gm true) or
+// players may see, oldest first.
+func (b *annotationBook) onLevel(level int, gm bool) []game.MapAnnotation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	annotations := []game.MapAnnotation{}
+	for _, annotation := range b.levels[level] {
+		if annotation.VisibleTo(gm) {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// Snapshot returns the annotations for saving
+func (b *annotationBook) Snapshot() AnnotationState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := AnnotationState{Levels: make(map[int][]game.MapAnnotation, len(b.levels))}
+	for level, annotations := range b.levels {
+		state.Levels[level] = slices.Clone(annotations)
+	}
+	return state
+}
+
+// Restore replaces the annotations with saved ones
+func (b *annotationBook) Restore(state AnnotationState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.levels = make(map[int][]game.MapAnnotation, len(state.Levels))
+	for level, annotations := range state.Levels {
+		b.levels[level] = slices.Clone(annotations)
+	}
+}
+
+// restoreAnnotations reloads the map annotations saved by persistState, if
+// any.
+func (s *RPCServer) restoreAnnotations() {
+	if s.store == nil || !s.store.Exists(annotationsKey) {
+		return
+	}
+
+	var state AnnotationState
+	if err := s.store.Load(annotationsKey, &state); err != nil {
+		logrus.WithError(err).Warn("failed to load map annotations, starting without them")
+		return
+	}
+	s.annotations.Restore(state)
+
+	logrus.WithFields(logrus.Fields{
+		"function": "restoreAnnotations",
+		"levels":   len(state.Levels),
+	}).Info("map annotations restored")
+}
+
+// authorizeGM checks the admin token a game master passes to a method that
+// players call too, such as getAnnotations, to see or change what only the
+// game master may.
+func (s *RPCServer) authorizeGM(method RPCMethod, token string) error {
+	return s.authorizeAdmin(method, map[string]interface{}{"admin_token": token})
+}
+
+// annotatedLevel returns a copy of level whose "annotations" property holds
+// the level's annotations the game master (gm true) or players may see, for
+// levels.ExportTiled.
+func (s *RPCServer) annotatedLevel(level *game.Level, index int, gm bool) *game.Level {
+	annotations := s.annotations.onLevel(index, gm)
+	if len(annotations) == 0 {
+		return level
+	}
+	annotated := *level
+	annotated.Properties = maps.Clone(level.Properties)
+	if annotated.Properties == nil {
+		annotated.Properties = make(map[string]interface{})
+	}
+	annotated.Properties["annotations"] = annotations
+	return &annotated
+}
+
+// handleAddAnnotation attaches a note, marker or region label to a level of
+// the map. Only the game master may annotate, with the admin token.
+// Annotations visible to players are broadcast as EventAnnotationAdded.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - level: int - Level index (optional, 0)
+//   - kind: string - "note", "marker" or "region"
+//   - x, y: int - The annotated tile, or the top left tile of a region
+//   - width, height: int - Size of a region in tiles
+//   - text: string - The note or label; markers may give a symbol instead
+//   - symbol: string - Marker symbol, such as "skull" (optional)
+//   - visibility: string - "gm" (default) or "players"
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - annotation: The stored game.MapAnnotation with its ID
+//   - error: Invalid parameters, an unauthorized token, an unknown level,
+//     an annotation off the level or a level already holding
+//     MaxAnnotationsPerLevel annotations
+func (s *RPCServer) handleAddAnnotation(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleAddAnnotation",
+	})
+	logger.Debug("entering handleAddAnnotation")
+
+	var req struct {
+		AdminToken string `json:"admin_token"`
+		game.MapAnnotation
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid annotation parameters", err.Error())
+	}
+	if err := s.authorizeGM(MethodAddAnnotation, req.AdminToken); err != nil {
+		return nil, err
+	}
+
+	annotation := req.MapAnnotation
+	if annotation.Visibility == "" {
+		annotation.Visibility = game.AnnotationGMOnly
+	}
+	level, _, err := s.state.WorldState.LevelSnapshot(annotation.Level)
+	if err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Unknown level", err.Error())
+	}
+	if err := annotation.Validate(level); err != nil {
+		return nil, ErrContentInvalid.WithMessage("invalid annotation: %v", err)
+	}
+	annotation.ID = uuid.New().String()
+	annotation.Author = "gm"
+	annotation.CreatedAt = time.Now()
+
+	if !s.annotations.add(annotation) {
+		return nil, ErrContentInvalid.WithMessage("level %d already has %d annotations", annotation.Level, MaxAnnotationsPerLevel).
+			WithData(map[string]interface{}{"level": annotation.Level, "limit": MaxAnnotationsPerLevel})
+	}
+	if annotation.VisibleTo(false) {
+		s.eventSys.Emit(game.GameEvent{
+			Type:      EventAnnotationAdded,
+			SourceID:  annotation.ID,
+			Data:      map[string]interface{}{"annotation": annotation},
+			Timestamp: annotation.CreatedAt.Unix(),
+		})
+	}
+
+	logger.WithFields(logrus.Fields{
+		"annotation_id": annotation.ID,
+		"kind":          annotation.Kind,
+		"level":         annotation.Level,
+		"visibility":    annotation.Visibility,
+	}).Info("map annotation added")
+
+	return map[string]interface{}{
+		"success":    true,
+		"annotation": annotation,
+	}, nil
+}
+
+// handleGetAnnotations lists the annotations of a level. Players see those
+// visible to players; the game master, calling with the admin token
+// instead of a session, sees them all.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The requesting player's session, when no
+//     admin_token is given
+//   - admin_token: string - The admin token (optional)
+//   - level: int - Level index (optional, the player's level, or 0 for
+//     the game master)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - level: int - The level listed
+//   - annotations: []game.MapAnnotation, oldest first
+//   - error: Invalid parameters or session, or an unauthorized token
+func (s *RPCServer) handleGetAnnotations(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleGetAnnotations",
+	})
+	logger.Debug("entering handleGetAnnotations")
+
+	var req struct {
+		SessionID  string `json:"session_id"`
+		AdminToken string `json:"admin_token"`
+		Level      *int   `json:"level,omitempty"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid annotation parameters", err.Error())
+	}
+
+	gm := req.AdminToken != ""
+	level := 0
+	if gm {
+		if err := s.authorizeGM(MethodGetAnnotations, req.AdminToken); err != nil {
+			return nil, err
+		}
+	} else {
+		session, err := s.getPlayerSession(req.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		level = session.Player.GetPosition().Level
+	}
+	if req.Level != nil {
+		level = *req.Level
+	}
+
+	annotations := s.annotations.onLevel(level, gm)
+	logger.WithFields(logrus.Fields{
+		"level":       level,
+		"gm":          gm,
+		"annotations": len(annotations),
+	}).Debug("map annotations listed")
+
+	return map[string]interface{}{
+		"success":     true,
+		"level":       level,
+		"annotations": annotations,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotationBook_SnapshotRestore(t *testing.T) {
+	var book annotationBook
+	require.True(t, book.add(game.MapAnnotation{ID: "a1", Level: 1, Kind: game.AnnotationNote, Text: "Ambush", Visibility: game.AnnotationGMOnly}))
+	require.True(t, book.add(game.MapAnnotation{ID: "a2", Level: 1, Kind: game.AnnotationMarker, Symbol: "chest", Visibility: game.AnnotationPlayers}))
+
+	assert.Len(t, book.onLevel(1, true), 2)
+	players := book.onLevel(1, false)
+	require.Len(t, players, 1, "players see only player-visible annotations")
+	assert.Equal(t, "a2", players[0].ID)
+	assert.Empty(t, book.onLevel(0, true))
+
+	var restored annotationBook
+	restored.Restore(book.Snapshot())
+	assert.Equal(t, book.onLevel(1, true), restored.onLevel(1, true))
+
+	for i := len(book.levels[1]); i < MaxAnnotationsPerLevel; i++ {
+		require.True(t, book.add(game.MapAnnotation{Level: 1}))
+	}
+	assert.False(t, book.add(game.MapAnnotation{Level: 1}), "full levels refuse more annotations")
+}
+
+func TestAnnotationMethods(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	server.admin = newTestAdminConsole(20, AdminPermissionAnnotate)
+	call := func(method RPCMethod, params map[string]interface{}) (interface{}, error) {
+		data, err := json.Marshal(params)
+		require.NoError(t, err)
+		return server.handleMethod(method, data)
+	}
+
+	_, err := call(MethodAddAnnotation, map[string]interface{}{"admin_token": "not-the-token", "kind": "note", "x": 1, "y": 1, "text": "Trap"})
+	assert.ErrorIs(t, err, ErrAdminUnauthorized, "only the game master annotates")
+
+	result, err := call(MethodAddAnnotation, map[string]interface{}{
+		"admin_token": testAdminToken, "kind": "note", "x": 2, "y": 3, "text": "The sergeant is a doppelganger",
+	})
+	require.NoError(t, err)
+	secret := result.(map[string]interface{})["annotation"].(game.MapAnnotation)
+	assert.Equal(t, game.AnnotationGMOnly, secret.Visibility, "annotations are GM-only unless shared")
+	assert.NotEmpty(t, secret.ID)
+
+	_, err = call(MethodAddAnnotation, map[string]interface{}{
+		"admin_token": testAdminToken, "kind": "region", "x": 1, "y": 1, "width": 4, "height": 3,
+		"text": "Barracks", "visibility": "players",
+	})
+	require.NoError(t, err)
+
+	_, err = call(MethodAddAnnotation, map[string]interface{}{"admin_token": testAdminToken, "kind": "region", "x": 1, "y": 1, "text": "Sizeless"})
+	assert.ErrorIs(t, err, ErrContentInvalid)
+	_, err = call(MethodAddAnnotation, map[string]interface{}{"admin_token": testAdminToken, "kind": "note", "x": 5000, "y": 1, "text": "Far away"})
+	assert.ErrorIs(t, err, ErrContentInvalid, "annotations must lie on the level")
+
+	result, err = call(MethodGetAnnotations, map[string]interface{}{"session_id": session.SessionID})
+	require.NoError(t, err)
+	annotations := result.(map[string]interface{})["annotations"].([]game.MapAnnotation)
+	require.Len(t, annotations, 1)
+	assert.Equal(t, "Barracks", annotations[0].Text)
+
+	result, err = call(MethodGetAnnotations, map[string]interface{}{"admin_token": testAdminToken, "level": 0})
+	require.NoError(t, err)
+	assert.Len(t, result.(map[string]interface{})["annotations"].([]game.MapAnnotation), 2)
+
+	exported := func(params map[string]interface{}) []pcg.TiledObject {
+		result, err := call(MethodExportMap, params)
+		require.NoError(t, err)
+		for _, layer := range result.(map[string]interface{})["map"].(*pcg.TiledMap).Layers {
+			if layer.Name == "annotations" {
+				return layer.Objects
+			}
+		}
+		return nil
+	}
+	assert.Len(t, exported(map[string]interface{}{"session_id": session.SessionID}), 1)
+	assert.Len(t, exported(map[string]interface{}{"session_id": session.SessionID, "admin_token": testAdminToken}), 2)
+	assert.NotContains(t, server.state.WorldState.Levels[0].Properties, "annotations", "exports leave the level alone")
+}
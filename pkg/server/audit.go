@@ -0,0 +1,338 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/logging"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// auditLog writes every request the auditor records
+var auditLog = logging.For("audit")
+
+// redactedValue replaces redacted values in audit records
+const redactedValue = "[redacted]"
+
+// AuditRecord is one RPC call recorded by the request auditor: the
+// request, its response and how long the server took. Params and Result
+// hold JSON, redacted and truncated to RequestAuditBodyLimit bytes.
+type AuditRecord struct {
+	CorrelationID string    `json:"correlation_id"`
+	Time          time.Time `json:"time"`
+	Transport     string    `json:"transport"` // http or websocket
+	Method        string    `json:"method"`
+	SessionID     string    `json:"session_id,omitempty"`
+	Params        string    `json:"params,omitempty"`
+	Result        string    `json:"result,omitempty"`
+	ErrorCode     int       `json:"error_code,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	DurationMS    float64   `json:"duration_ms"`
+}
+
+// AuditFilter selects the records admin.listRequests returns. Empty
+// fields match every record.
+type AuditFilter struct {
+	Method        string
+	CorrelationID string
+	FailedOnly    bool
+}
+
+// matches reports whether record passes the filter
+func (f AuditFilter) matches(record AuditRecord) bool {
+	return (f.Method == "" || record.Method == f.Method) &&
+		(f.CorrelationID == "" || record.CorrelationID == f.CorrelationID) &&
+		(!f.FailedOnly || record.ErrorCode != 0)
+}
+
+// requestAuditor keeps a ring buffer of sampled RPC calls for inspection.
+// Each method is sampled at its own rate, falling back to the default
+// rate; failed calls are always recorded. Admin tokens are always
+// redacted, session IDs and player names when configured.
+type requestAuditor struct {
+	mu             sync.Mutex
+	sampleRate     float64
+	methodRates    map[string]float64
+	redactSessions bool
+	redactNames    bool
+	records        []AuditRecord // Ring buffer, next is the oldest once full
+	next           int
+	full           bool
+}
+
+// newRequestAuditor creates the request auditor from the configuration, or
+// returns nil when auditing is disabled.
+func newRequestAuditor(cfg *config.Config) *requestAuditor {
+	if cfg == nil || !cfg.RequestAuditEnabled || cfg.RequestAuditBufferSize < 1 {
+		return nil
+	}
+
+	methodRates := make(map[string]float64, len(cfg.RequestAuditMethodRates))
+	for method, rate := range cfg.RequestAuditMethodRates {
+		methodRates[method] = rate
+	}
+	return &requestAuditor{
+		sampleRate:     cfg.RequestAuditSampleRate,
+		methodRates:    methodRates,
+		redactSessions: cfg.RequestAuditRedactSessionIDs,
+		redactNames:    cfg.RequestAuditRedactPlayerNames,
+		records:        make([]AuditRecord, cfg.RequestAuditBufferSize),
+	}
+}
+
+// sampled decides whether a call to method is recorded
+func (a *requestAuditor) sampled(method string, failed bool) bool {
+	if failed {
+		return true
+	}
+	rate, exists := a.methodRates[method]
+	if !exists {
+		rate = a.sampleRate
+	}
+	return rate > 0 && (rate >= 1 || rand.Float64() < rate)
+}
+
+// add stores record, replacing the oldest once the buffer is full
+func (a *requestAuditor) add(record AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.records[a.next] = record
+	a.next = (a.next + 1) % len(a.records)
+	a.full = a.full || a.next == 0
+}
+
+// recent returns up to limit records passing filter, newest first
+func (a *requestAuditor) recent(filter AuditFilter, limit int) []AuditRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	count := a.next
+	if a.full {
+		count = len(a.records)
+	}
+	matched := make([]AuditRecord, 0, min(count, limit))
+	for i := 1; i <= count && len(matched) < limit; i++ {
+		record := a.records[(a.next-i+len(a.records))%len(a.records)]
+		if filter.matches(record) {
+			matched = append(matched, record)
+		}
+	}
+	return matched
+}
+
+// capacity returns the number of records the buffer holds
+func (a *requestAuditor) capacity() int {
+	return len(a.records)
+}
+
+// pseudonym replaces a session ID with a stable stand-in, so records of
+// one session can still be told apart from those of others
+func pseudonym(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return "session-" + hex.EncodeToString(sum[:6])
+}
+
+// credentialKey reports whether a JSON key holds a credential, such as the
+// admin token or a resume token, which audit records never show
+func credentialKey(key string) bool {
+	key = strings.ToLower(key)
+	return key == "password" || key == "api_key" ||
+		strings.HasSuffix(key, "_token") ||
+		strings.HasSuffix(key, "_secret") ||
+		strings.HasSuffix(key, "_password")
+}
+
+// redact returns a copy of the decoded JSON value with credentials, session
+// IDs and the given player names removed as configured
+func (a *requestAuditor) redact(value interface{}, names []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			switch {
+			case credentialKey(key):
+				redacted[key] = redactedValue
+			case key == "session_id" && a.redactSessions:
+				if id, ok := field.(string); ok {
+					redacted[key] = pseudonym(id)
+				} else {
+					redacted[key] = redactedValue
+				}
+			default:
+				redacted[key] = a.redact(field, names)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = a.redact(item, names)
+		}
+		return redacted
+	case string:
+		for _, name := range names {
+			v = strings.ReplaceAll(v, name, redactedValue)
+		}
+		return v
+	}
+	return value
+}
+
+// encode redacts a JSON document and truncates it to RequestAuditBodyLimit
+// bytes. Documents that are not JSON are left out.
+func (a *requestAuditor) encode(document []byte, names []string) string {
+	if len(document) == 0 {
+		return ""
+	}
+	var value interface{}
+	if err := json.Unmarshal(document, &value); err != nil {
+		return ""
+	}
+	redacted, err := json.Marshal(a.redact(value, names))
+	if err != nil {
+		return ""
+	}
+	if len(redacted) > RequestAuditBodyLimit {
+		return string(redacted[:RequestAuditBodyLimit]) + "…"
+	}
+	return string(redacted)
+}
+
+// withCorrelationID returns ctx carrying the correlation ID of an RPC call
+// and the ID itself. HTTP calls are correlated by the ID of their request;
+// other calls, and contexts that already carry one, keep or get their own.
+func withCorrelationID(ctx context.Context) (context.Context, string) {
+	if id, ok := logging.FieldsFromContext(ctx)[logging.FieldCorrelationID].(string); ok && id != "" {
+		return ctx, id
+	}
+	id := GetRequestID(ctx)
+	if id == "" {
+		id = uuid.New().String()
+	}
+	return logging.ContextWithFields(ctx, logrus.Fields{logging.FieldCorrelationID: id}), id
+}
+
+// correlatedResponse adds the correlation ID to a WebSocket response, which
+// has no headers to carry it
+func correlatedResponse(response interface{}, correlationID string) interface{} {
+	if message, ok := response.(map[string]interface{}); ok {
+		message["correlation_id"] = correlationID
+	}
+	return response
+}
+
+// auditRequest records a call to method if the auditor samples it, and
+// writes the record to the audit log.
+func (s *RPCServer) auditRequest(correlationID, transport string, method RPCMethod, params json.RawMessage, result interface{}, err error, started time.Time) {
+	if s.audit == nil || !s.audit.sampled(string(method), err != nil) {
+		return
+	}
+
+	var names []string
+	if s.audit.redactNames {
+		names = s.sessionPlayerNames()
+	}
+	record := AuditRecord{
+		CorrelationID: correlationID,
+		Time:          started,
+		Transport:     transport,
+		Method:        string(method),
+		Params:        s.audit.encode(params, names),
+		DurationMS:    float64(time.Since(started).Microseconds()) / 1000,
+	}
+
+	var request struct {
+		SessionID string `json:"session_id"`
+	}
+	if json.Unmarshal(params, &request) == nil && request.SessionID != "" {
+		record.SessionID = request.SessionID
+		if s.audit.redactSessions {
+			record.SessionID = pseudonym(request.SessionID)
+		}
+	}
+	if err != nil {
+		rpcErr := toJSONRPCError(err, JSONRPCInternalError)
+		record.ErrorCode = rpcErr.Code
+		record.Error = rpcErr.Message
+	} else if encoded, marshalErr := json.Marshal(result); marshalErr == nil {
+		record.Result = s.audit.encode(encoded, names)
+	}
+	s.audit.add(record)
+
+	auditLog.WithFields(logrus.Fields{
+		logging.FieldCorrelationID: record.CorrelationID,
+		logging.FieldMethod:        record.Method,
+		"transport":                record.Transport,
+		"audit_session":            record.SessionID,
+		"error_code":               record.ErrorCode,
+		"duration_ms":              record.DurationMS,
+	}).Info("request audited")
+}
+
+// sessionPlayerNames returns the names of the players of every session,
+// longest first so that no name is redacted inside a longer one
+func (s *RPCServer) sessionPlayerNames() []string {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if session.Player != nil && session.Player.GetName() != "" {
+			names = append(names, session.Player.GetName())
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return names
+}
+
+// handleAdminListRequests returns the most recent requests recorded by the
+// request auditor, newest first.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - method: string (optional) - Only records of this RPC method
+//   - correlation_id: string (optional) - Only the record of this call
+//   - failed_only: bool (optional) - Only records of failed calls
+//   - limit: number (optional) - Records returned, default
+//     RequestAuditPageSize
+//
+// Returns:
+//   - interface{}: Map containing success, records and capacity, the size
+//     of the ring buffer
+//   - error: Invalid parameters, or auditing is disabled
+func (s *RPCServer) handleAdminListRequests(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		Method        string `json:"method"`
+		CorrelationID string `json:"correlation_id"`
+		FailedOnly    bool   `json:"failed_only"`
+		Limit         int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid list requests parameters", err.Error())
+	}
+	if s.audit == nil {
+		return nil, ErrUnavailable.WithMessage("request auditing is disabled")
+	}
+	if req.Limit <= 0 {
+		req.Limit = RequestAuditPageSize
+	}
+
+	filter := AuditFilter{Method: req.Method, CorrelationID: req.CorrelationID, FailedOnly: req.FailedOnly}
+	return map[string]interface{}{
+		"success":  true,
+		"records":  s.audit.recent(filter, req.Limit),
+		"capacity": s.audit.capacity(),
+	}, nil
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/logging"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAuditor returns a request auditor of size records recording every
+// call and redacting session IDs and player names
+func newTestAuditor(size int) *requestAuditor {
+	return newRequestAuditor(&config.Config{
+		RequestAuditEnabled:           true,
+		RequestAuditSampleRate:        1,
+		RequestAuditBufferSize:        size,
+		RequestAuditRedactSessionIDs:  true,
+		RequestAuditRedactPlayerNames: true,
+	})
+}
+
+func TestRequestAuditor_SamplingAndRing(t *testing.T) {
+	assert.Nil(t, newRequestAuditor(&config.Config{RequestAuditBufferSize: 10}), "disabled auditing keeps no records")
+
+	auditor := newTestAuditor(3)
+	auditor.methodRates["move"] = 0
+	assert.True(t, auditor.sampled("getGameState", false))
+	assert.False(t, auditor.sampled("move", false), "method rates replace the default rate")
+	assert.True(t, auditor.sampled("move", true), "failed calls are always recorded")
+
+	for i := 1; i <= 4; i++ {
+		auditor.add(AuditRecord{CorrelationID: fmt.Sprint(i), Method: "move", ErrorCode: i % 2})
+	}
+	records := auditor.recent(AuditFilter{}, 10)
+	require.Len(t, records, 3, "the oldest record was replaced")
+	assert.Equal(t, "4", records[0].CorrelationID, "newest first")
+	assert.Equal(t, "2", records[2].CorrelationID)
+
+	assert.Len(t, auditor.recent(AuditFilter{}, 2), 2)
+	assert.Len(t, auditor.recent(AuditFilter{FailedOnly: true}, 10), 1)
+	assert.Len(t, auditor.recent(AuditFilter{CorrelationID: "2"}, 10), 1)
+	assert.Empty(t, auditor.recent(AuditFilter{Method: "attack"}, 10))
+}
+
+func TestRequestAuditor_Redact(t *testing.T) {
+	auditor := newTestAuditor(1)
+	document := []byte(`{"admin_token":"secret","session_id":"abc","target":{"names":["Aria the Bold","Borin"]}}`)
+
+	encoded := auditor.encode(document, []string{"Aria the Bold"})
+	assert.NotContains(t, encoded, "secret")
+	assert.NotContains(t, encoded, `"abc"`)
+	assert.Contains(t, encoded, pseudonym("abc"))
+	assert.NotContains(t, encoded, "Aria")
+	assert.Contains(t, encoded, "Borin")
+
+	auditor.redactSessions = false
+	assert.Contains(t, auditor.encode(document, nil), `"abc"`)
+	assert.NotContains(t, auditor.encode(document, nil), "secret", "admin tokens are always redacted")
+
+	credentials := []byte(`{"resume_token":"r3sume","webhook_secret":"hmac","token":{"shape":"round"}}`)
+	encoded = auditor.encode(credentials, nil)
+	assert.NotContains(t, encoded, "r3sume")
+	assert.NotContains(t, encoded, "hmac")
+	assert.Contains(t, encoded, "round", "portrait tokens are not credentials")
+
+	long := []byte(`"` + strings.Repeat("x", RequestAuditBodyLimit) + `"`)
+	assert.Len(t, []rune(auditor.encode(long, nil)), RequestAuditBodyLimit+1, "long documents are truncated")
+}
+
+func TestTracedMethod_AuditsRequests(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	server.audit = newTestAuditor(10)
+
+	ctx := context.WithValue(context.Background(), requestIDKey, "request-1")
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID})
+	_, logger, err := server.tracedMethod(ctx, MethodGetCampaignStats, params, "http", logrus.NewEntry(logrus.New()))
+	require.NoError(t, err)
+	assert.Equal(t, "request-1", logger.Data[logging.FieldCorrelationID], "HTTP calls are correlated by their request ID")
+
+	records := server.audit.recent(AuditFilter{}, 10)
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "request-1", record.CorrelationID)
+	assert.Equal(t, "http", record.Transport)
+	assert.Equal(t, string(MethodGetCampaignStats), record.Method)
+	assert.Equal(t, pseudonym(session.SessionID), record.SessionID)
+	assert.NotContains(t, record.Params, session.SessionID)
+	assert.Contains(t, record.Result, "monsters_slain")
+	assert.NotContains(t, record.Result, session.Player.GetName())
+
+	server.audit.sampleRate = 0
+	_, logger, err = server.tracedMethod(context.Background(), "noSuchMethod", nil, "websocket", logrus.NewEntry(logrus.New()))
+	require.Error(t, err)
+	correlationID, _ := logger.Data[logging.FieldCorrelationID].(string)
+	assert.NotEmpty(t, correlationID, "calls without a request ID get a correlation ID")
+
+	failed := server.audit.recent(AuditFilter{FailedOnly: true}, 10)
+	require.Len(t, failed, 1, "failed calls are recorded whatever the sample rate")
+	assert.Equal(t, correlationID, failed[0].CorrelationID)
+	assert.NotZero(t, failed[0].ErrorCode)
+}
+
+func TestAuditRequest_RedactsResumeTokens(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.audit = newTestAuditor(10)
+	server.audit.redactSessions = false
+
+	params := json.RawMessage(`{"player_name":"Rowan"}`)
+	result, err := server.handleJoinGame(params)
+	require.NoError(t, err)
+	joinToken := result.(map[string]interface{})["resume_token"].(string)
+	server.auditRequest("join", "http", MethodJoinGame, params, result, nil, time.Now())
+
+	client := dialResumeTestSocket(t, server)
+	require.NoError(t, client.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "reconnectSession",
+		"params":  map[string]interface{}{"resume_token": joinToken},
+		"id":      1,
+	}))
+	response := readResumeTestResponse(t, client, 1)
+	require.Nil(t, response["error"])
+	rotatedToken := response["result"].(map[string]interface{})["resume_token"].(string)
+
+	records := server.audit.recent(AuditFilter{}, 10)
+	require.Len(t, records, 2)
+	for _, record := range records {
+		assert.Contains(t, record.Result, "resume_token", record.Method)
+		for _, token := range []string{joinToken, rotatedToken} {
+			assert.NotContains(t, record.Params, token, record.Method)
+			assert.NotContains(t, record.Result, token, record.Method)
+		}
+	}
+}
+
+func TestHandleAdminListRequests(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.admin = newTestAdminConsole(10, AdminPermissionInspect)
+	server.audit = nil
+	call := func(params map[string]interface{}) (interface{}, error) {
+		params["admin_token"] = testAdminToken
+		data, err := json.Marshal(params)
+		require.NoError(t, err)
+		return server.handleMethod(MethodAdminListRequests, data)
+	}
+
+	_, err := call(map[string]interface{}{})
+	assert.ErrorIs(t, err, ErrUnavailable, "auditing is disabled")
+
+	server.audit = newTestAuditor(10)
+	server.audit.add(AuditRecord{CorrelationID: "a", Method: "move"})
+	server.audit.add(AuditRecord{CorrelationID: "b", Method: "attack"})
+	result, err := call(map[string]interface{}{"method": "move"})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	records := response["records"].([]AuditRecord)
+	require.Len(t, records, 1)
+	assert.Equal(t, "a", records[0].CorrelationID)
+	assert.Equal(t, 10, response["capacity"])
+
+	_, err = call(map[string]interface{}{"limit": 0})
+	assert.Error(t, err)
+}
+
+func TestCorrelatedResponse(t *testing.T) {
+	response := correlatedResponse(NewResponse(1, "ok"), "corr-1").(map[string]interface{})
+	assert.Equal(t, "corr-1", response["correlation_id"])
+
+	ctx, id := withCorrelationID(context.Background())
+	again, sameID := withCorrelationID(ctx)
+	assert.Equal(t, id, sameID, "a context keeps its correlation ID")
+	assert.Equal(t, ctx, again)
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// startBackupVerification periodically checks stored backups against their
+// checksums until the server shuts down, so corrupt backups are reported
+// before anyone needs to restore them.
+func (s *RPCServer) startBackupVerification() {
+	if s.backups == nil || s.config == nil || s.config.BackupVerifyInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.BackupVerifyInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.verifyBackups()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// verifyBackups checks every stored backup and logs any that are corrupt.
+func (s *RPCServer) verifyBackups() {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "verifyBackups",
+	})
+
+	checked, err := s.backups.Verify()
+	if err != nil {
+		logger.WithError(err).WithField("checked", checked).Error("backup integrity check failed")
+		return
+	}
+	logger.WithField("checked", checked).Debug("backups verified")
+}
+
+// handleListBackups returns the stored save data backups, oldest first.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - key: string - Only list backups of this document, e.g. "gamestate.yaml" (optional)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating if the query succeeded
+//   - backups: []persistence.BackupInfo with the ID to pass to
+//     admin.restoreBackup
+//   - error: Invalid parameters or session, or backups are disabled
+func (s *RPCServer) handleListBackups(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleListBackups",
+	})
+	logger.Debug("entering handleListBackups")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		Key       string `json:"key"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid list backups parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	if s.backups == nil {
+		return nil, ErrUnavailable.WithMessage("Backups not enabled")
+	}
+
+	backups, err := s.backups.Backups(req.Key)
+	if err != nil {
+		logger.WithError(err).Error("failed to list backups")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to list backups", err.Error())
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"backups": backups,
+	}, nil
+}
+
+// handleAdminRestoreBackup verifies a backup and restores it over the
+// document it was taken from. A game state, PCG state or world events
+// backup is decoded first and rejected if the server could not load it, so
+// the live document is only replaced by one it can reload. The document
+// being replaced is backed up first, so the restore can itself be undone.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - backup_id: string - A backup ID returned by listBackups
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating the backup was restored
+//   - key: The document that was restored
+//   - reloaded: bool indicating the running server loaded the restored data
+//   - error: Invalid parameters, or an unknown, corrupt or unloadable backup
+func (s *RPCServer) handleAdminRestoreBackup(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleAdminRestoreBackup",
+	})
+	logger.Debug("entering handleAdminRestoreBackup")
+
+	var req struct {
+		BackupID string `json:"backup_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid restore backup parameters", err.Error())
+	}
+
+	if s.backups == nil {
+		return nil, ErrUnavailable.WithMessage("Backups not enabled")
+	}
+
+	// Hold off auto-saves so the restored data is not overwritten with the
+	// in-memory state before it has been reloaded
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	var document yaml.Node
+	key, err := s.backups.Open(req.BackupID, &document)
+	if err == nil {
+		if value := reloadedValue(key); value != nil {
+			if err = document.Decode(value); err != nil {
+				err = fmt.Errorf("%s cannot be loaded: %w", key, err)
+			}
+		}
+	}
+	if err == nil {
+		_, err = s.backups.Restore(req.BackupID)
+	}
+	if err != nil {
+		logger.WithError(err).WithField("backup_id", req.BackupID).Warn("backup restore rejected")
+		if errors.Is(err, persistence.ErrBackupCorrupt) {
+			return nil, NewJSONRPCError(JSONRPCInternalError, "Backup failed integrity check", err.Error())
+		}
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Failed to restore backup", err.Error())
+	}
+
+	reloaded := false
+	switch key {
+	case gameStateKey:
+		if err := s.state.LoadFromFile(s.store); err != nil {
+			return nil, NewJSONRPCError(JSONRPCInternalError, "Backup restored but game state failed to reload", err.Error())
+		}
+		s.scheduleShopRestock()
+		reloaded = true
+	case pcgStateKey:
+		s.restorePCGState()
+		reloaded = true
+	case worldEventsKey:
+		s.restoreWorldEvents()
+		reloaded = s.worldEvents != nil
+	case chronicleKey:
+		s.restoreChronicle()
+		reloaded = true
+	case campaignStatsKey:
+		s.restoreCampaignStats()
+		reloaded = true
+	case annotationsKey:
+		s.restoreAnnotations()
+		reloaded = true
+	}
+
+	logger.WithFields(logrus.Fields{
+		"backup_id": req.BackupID,
+		"key":       key,
+		"reloaded":  reloaded,
+	}).Info("backup restored")
+
+	return map[string]interface{}{
+		"success":  true,
+		"key":      key,
+		"reloaded": reloaded,
+	}, nil
+}
+
+// reloadedValue returns an empty value of the type the running server
+// loads the document under key into, or nil for documents it does not load.
+func reloadedValue(key string) interface{} {
+	switch key {
+	case gameStateKey:
+		return &GameState{}
+	case pcgStateKey:
+		return &pcg.SaveableState{}
+	case worldEventsKey:
+		return &WorldEventState{}
+	case chronicleKey:
+		return &ChronicleState{}
+	case campaignStatsKey:
+		return &CampaignStatsState{}
+	case annotationsKey:
+		return &AnnotationState{}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreBackup_ReloadsGameState(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	server.backups = persistence.NewBackupStore(persistence.NewMemoryStore(), persistence.BackupPolicy{MaxCount: 3})
+	server.store = server.backups
+	objects := len(server.state.WorldState.Objects)
+	require.NotZero(t, objects)
+
+	for version := 1; version <= 3; version++ {
+		server.state.Version = version
+		require.NoError(t, server.persistState())
+	}
+
+	result, err := server.handleListBackups(json.RawMessage(`{"session_id":"` + session.SessionID + `","key":"gamestate.yaml"}`))
+	require.NoError(t, err)
+	backups := result.(map[string]interface{})["backups"].([]persistence.BackupInfo)
+	require.Len(t, backups, 2, "versions 1 and 2 were overwritten")
+
+	server.state.WorldState.Objects = map[string]game.GameObject{}
+	result, err = server.handleAdminRestoreBackup(json.RawMessage(`{"backup_id":"` + backups[0].ID + `"}`))
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, gameStateKey, response["key"])
+	assert.Equal(t, true, response["reloaded"])
+	assert.Equal(t, 1, server.state.Version)
+	assert.Len(t, server.state.WorldState.Objects, objects, "world objects are reloaded")
+	_, exists := server.state.WorldState.GetObject(session.Player.GetID())
+	assert.True(t, exists)
+
+	// The state replaced by the restore is kept as the newest backup
+	result, err = server.handleListBackups(json.RawMessage(`{"session_id":"` + session.SessionID + `","key":"gamestate.yaml"}`))
+	require.NoError(t, err)
+	assert.Len(t, result.(map[string]interface{})["backups"], 3)
+
+	_, err = server.handleAdminRestoreBackup(json.RawMessage(`{"backup_id":"backups/missing.yaml"}`))
+	assert.Error(t, err)
+}
+
+func TestRestoreBackup_RequiresAdmin(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	server.backups = persistence.NewBackupStore(persistence.NewMemoryStore(), persistence.BackupPolicy{})
+	server.admin = newTestAdminConsole(10, AdminPermissionInspect)
+	backupID := `"backup_id":"backups/gamestate.yaml/20240101T000100.000000000Z.yaml"`
+
+	_, err := server.handleMethod(MethodAdminRestoreBackup, json.RawMessage(`{"session_id":"`+session.SessionID+`",`+backupID+`}`))
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code, "a player session is not enough")
+
+	_, err = server.handleMethod(MethodAdminRestoreBackup, json.RawMessage(`{"admin_token":"`+testAdminToken+`",`+backupID+`}`))
+	assert.ErrorIs(t, err, ErrAdminForbidden)
+}
+
+func TestListBackups_RequiresBackups(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	server.backups = nil
+
+	_, err := server.handleListBackups(json.RawMessage(`{"session_id":"` + session.SessionID + `"}`))
+	assert.Error(t, err)
+}
+
+func TestRestoreBackup_RejectsUnloadableBackup(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.backups = persistence.NewBackupStore(persistence.NewMemoryStore(), persistence.BackupPolicy{})
+	server.store = server.backups
+
+	server.state.Version = 1
+	require.NoError(t, server.persistState())
+	require.NoError(t, server.backups.Save(gameStateKey, map[string]interface{}{
+		"state_version": 2,
+		"state_world": map[string]interface{}{
+			"world_objects": map[string]interface{}{"dragon-1": map[string]interface{}{"kind": "dragon"}},
+		},
+	}))
+	server.state.Version = 3
+	require.NoError(t, server.persistState())
+
+	backups, err := server.backups.Backups(gameStateKey)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	_, err = server.handleAdminRestoreBackup(json.RawMessage(`{"backup_id":"` + backups[1].ID + `"}`))
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code)
+
+	// The live document was never replaced
+	loaded := &GameState{}
+	require.NoError(t, server.store.Load(gameStateKey, loaded))
+	assert.Equal(t, 3, loaded.Version)
+	assert.Len(t, loaded.WorldState.Objects, len(server.state.WorldState.Objects))
+	after, err := server.backups.Backups(gameStateKey)
+	require.NoError(t, err)
+	assert.Len(t, after, 2, "nothing was restored, so nothing was backed up")
+}
+
+func TestVersionedStore_MigratesLegacyGameState(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.backups = persistence.NewBackupStore(persistence.NewMemoryStore(), persistence.BackupPolicy{})
+	server.state.Version = 7
+
+	// A save written before documents carried a schema version
+	require.NoError(t, server.backups.Save(gameStateKey, server.state))
+
+	migrations := persistence.NewMigrationRegistry()
+	require.NoError(t, migrations.MigrateFrom(1, func(key string, doc map[string]interface{}) error {
+		doc["state_version"] = doc["state_version"].(int) + 1
+		return nil
+	}))
+	server.store = persistence.NewVersionedStore(server.backups, migrations)
+
+	require.NoError(t, server.state.LoadFromFile(server.store))
+	assert.Equal(t, 8, server.state.Version)
+	assert.True(t, server.backups.Exists("migrations/gamestate.yaml/v1.yaml"))
+
+	// Later saves carry the current version and load without migrating
+	require.NoError(t, server.persistState())
+	require.NoError(t, server.state.LoadFromFile(server.store))
+	assert.Equal(t, 8, server.state.Version)
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"goldbox-rpg/pkg/bundle"
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// bundleLocationPrefix prefixes the bundle name in the location ID levels
+// of a content bundle are integrated under
+const bundleLocationPrefix = "bundle:"
+
+// mountContentBundles mounts the configured content bundles into the world.
+// A bundle that cannot be found, fails verification or cannot be mounted is
+// logged and skipped so one broken bundle does not keep the server from
+// starting.
+func (s *RPCServer) mountContentBundles() {
+	if len(s.config.ContentBundles) == 0 {
+		return
+	}
+	logger := logrus.WithField("function", "mountContentBundles")
+
+	opts := bundle.VerifyOptions{AllowUnsigned: s.config.ContentBundlesAllowUnsigned}
+	for _, encoded := range s.config.ContentBundleKeys {
+		key, err := bundle.ParsePublicKey(encoded)
+		if err != nil {
+			logger.WithError(err).Warn("ignoring malformed content bundle key")
+			continue
+		}
+		opts.TrustedKeys = append(opts.TrustedKeys, key)
+	}
+
+	for _, configured := range s.config.ContentBundles {
+		paths, err := bundle.Find([]string{configured})
+		if err != nil {
+			logger.WithError(err).WithField("bundle", configured).Error("failed to find content bundles")
+			continue
+		}
+		for _, path := range paths {
+			manifest, err := s.mountContentBundle(path, opts)
+			if err != nil {
+				logger.WithError(err).WithField("bundle", path).Error("failed to mount content bundle")
+				continue
+			}
+			logger.WithFields(logrus.Fields{
+				"bundle":  manifest.Name,
+				"version": manifest.Version,
+				"signer":  manifest.Signer,
+				"files":   len(manifest.Files),
+			}).Info("mounted content bundle")
+		}
+	}
+}
+
+// mountContentBundle verifies the bundle at path and adds its content to
+// the world. Maps are integrated like generated levels, getting merchants,
+// doors and features; NPCs are placed in the world; dialogue replaces the
+// dialog of the NPC it names; loot tables join the loot table registry; and
+// quests can be started by ID. Levels and NPCs the world already holds, as
+// when it was restored from a save made with the bundle mounted, are kept.
+func (s *RPCServer) mountContentBundle(path string, opts bundle.VerifyOptions) (*bundle.Manifest, error) {
+	b, err := bundle.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Verify(opts); err != nil {
+		return nil, err
+	}
+	content, err := b.Content()
+	if err != nil {
+		return nil, err
+	}
+	name := b.Manifest.Name
+	world := s.state.WorldState
+
+	levels, err := s.mountBundleMaps(name, content.Maps)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content.LootTables) > 0 {
+		if s.lootTables == nil {
+			return nil, fmt.Errorf("bundle %s has loot tables but no loot table registry is loaded", name)
+		}
+		if err := s.lootTables.AddTables(name+bundle.Extension, content.LootTables); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, npc := range content.NPCs {
+		if _, exists := world.GetObject(npc.ID); exists {
+			continue
+		}
+		if err := world.AddObject(npc); err != nil {
+			return nil, fmt.Errorf("failed to place NPC %s: %w", npc.ID, err)
+		}
+	}
+	for npcID, entries := range content.Dialogue {
+		obj, _ := world.GetObject(npcID)
+		if npc, ok := obj.(*game.NPC); ok {
+			npc.Dialog = entries
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"function": "mountContentBundle",
+				"bundle":   name,
+				"npc_id":   npcID,
+			}).Warn("bundle dialogue names no NPC in the world")
+		}
+	}
+
+	s.mu.Lock()
+	if s.bundleQuests == nil {
+		s.bundleQuests = make(map[string]*game.Quest)
+	}
+	for _, quest := range content.Quests {
+		s.bundleQuests[quest.ID] = quest
+	}
+	s.mu.Unlock()
+
+	for _, level := range levels {
+		s.stockLevelMerchants(level)
+		s.placeLevelDoors(level)
+		s.placeLevelFeatures(level)
+	}
+	return &b.Manifest, nil
+}
+
+// mountBundleMaps integrates the maps of a bundle the world does not hold
+// yet in one transaction, so either all of them are added or none.
+//
+// Returns:
+//   - []*game.Level: The levels that were added
+func (s *RPCServer) mountBundleMaps(name string, maps []*game.Level) ([]*game.Level, error) {
+	var added []*game.Level
+	for _, level := range maps {
+		if worldLevelIndex(s.state.WorldState, level.ID) < 0 {
+			added = append(added, level)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	ix := s.pcgManager.BeginWorldIntegration(context.Background(), s.state.WorldState, bundleLocationPrefix+name)
+	defer ix.Rollback()
+	for _, level := range added {
+		if err := ix.Stage(level); err != nil {
+			return nil, fmt.Errorf("map %s: %w", level.ID, err)
+		}
+	}
+	if err := ix.Commit(); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// bundleQuest returns the quest with questID from a mounted content bundle
+func (s *RPCServer) bundleQuest(questID string) (*game.Quest, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	quest, ok := s.bundleQuests[questID]
+	return quest, ok
+}
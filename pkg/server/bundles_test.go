@@ -0,0 +1,99 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"goldbox-rpg/pkg/bundle"
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg/items"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestBundle writes a bundle holding a map, an NPC with separate
+// dialogue, a loot table and a quest
+func writeTestBundle(t *testing.T, dir string, key ed25519.PrivateKey) string {
+	t.Helper()
+	tiles := make([][]game.Tile, 4)
+	for y := range tiles {
+		tiles[y] = make([]game.Tile, 4)
+		for x := range tiles[y] {
+			tiles[y][x] = game.Tile{Type: game.TileFloor, Walkable: true, Transparent: true}
+		}
+	}
+
+	b := bundle.NewBuilder("crypt-of-ages", "1.0.0")
+	require.NoError(t, b.AddMap(&game.Level{ID: "crypt_1", Name: "Crypt", Width: 4, Height: 4, Tiles: tiles}))
+	require.NoError(t, b.AddNPC(&game.NPC{Character: game.Character{ID: "old_tom", Name: "Old Tom", HP: 10, MaxHP: 10}}))
+	require.NoError(t, b.AddDialogue("old_tom", []game.DialogEntry{{ID: "greet", Text: "Mind the bones."}}))
+	require.NoError(t, b.AddLootTable("crypt_hoard", &items.LootTable{
+		Rolls:   items.LootRange{Min: 1, Max: 1},
+		Entries: []items.LootEntry{{Weight: 1}},
+	}))
+	require.NoError(t, b.AddQuest(&game.Quest{ID: "lost_ring", Title: "The Lost Ring", Status: game.QuestNotStarted}))
+
+	path := filepath.Join(dir, "crypt-of-ages"+bundle.Extension)
+	require.NoError(t, b.WriteFile(path, key))
+	return path
+}
+
+func TestMountContentBundle(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	path := writeTestBundle(t, t.TempDir(), private)
+	opts := bundle.VerifyOptions{TrustedKeys: []ed25519.PublicKey{public}}
+
+	levels := len(server.state.WorldState.Levels)
+	manifest, err := server.mountContentBundle(path, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "crypt-of-ages", manifest.Name)
+
+	world := server.state.WorldState
+	assert.GreaterOrEqual(t, worldLevelIndex(world, "crypt_1"), levels)
+	obj, exists := world.GetObject("old_tom")
+	require.True(t, exists)
+	npc := obj.(*game.NPC)
+	require.Len(t, npc.Dialog, 1)
+	assert.Equal(t, "Mind the bones.", npc.Dialog[0].Text)
+	_, ok := server.lootTables.GetTable("crypt_hoard")
+	assert.True(t, ok)
+
+	start, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "quest_id": "lost_ring"})
+	_, err = server.handleStartQuest(start)
+	require.NoError(t, err)
+	quest, err := session.Player.GetQuest("lost_ring")
+	require.NoError(t, err)
+	assert.Equal(t, "The Lost Ring", quest.Title)
+
+	missing, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "quest_id": "no_such_quest"})
+	_, err = server.handleStartQuest(missing)
+	assert.True(t, errors.Is(err, ErrQuestNotFound))
+
+	// Mounting again, as after restoring a save, adds nothing twice
+	count := len(world.Levels)
+	_, err = server.mountContentBundle(path, opts)
+	require.NoError(t, err)
+	assert.Len(t, world.Levels, count)
+}
+
+func TestMountContentBundles_SkipsUntrusted(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	_, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	writeTestBundle(t, dir, private)
+
+	server.config.ContentBundles = []string{dir, filepath.Join(dir, "missing.gbox")}
+	server.mountContentBundles()
+
+	_, exists := server.state.WorldState.GetObject("old_tom")
+	assert.False(t, exists, "bundles signed by an untrusted key are not mounted")
+	assert.Negative(t, worldLevelIndex(server.state.WorldState, "crypt_1"))
+}
@@ -0,0 +1,141 @@
+package server
+
+import "fmt"
+
+// TicksPerDay is the number of game ticks in one in-game day.
+const TicksPerDay = 24 * TicksPerHour
+
+// Calendar lengths. Every month has DaysPerMonth days, so a year has 360.
+const (
+	DaysPerMonth  = 30
+	MonthsPerYear = 12
+	DaysPerYear   = MonthsPerYear * DaysPerMonth
+)
+
+// CalendarStartYear is the year game time starts in. Tick 0 is midnight
+// on the first day of the first month.
+const CalendarStartYear = 1
+
+// Season is one of the four seasons of the calendar year.
+type Season string
+
+const (
+	SeasonWinter Season = "winter"
+	SeasonSpring Season = "spring"
+	SeasonSummer Season = "summer"
+	SeasonAutumn Season = "autumn"
+)
+
+// Month is a month of the calendar year.
+type Month struct {
+	Name   string `json:"name"`
+	Season Season `json:"season"`
+}
+
+// calendarMonths are the months of the year, in order
+var calendarMonths = [MonthsPerYear]Month{
+	{Name: "Deepwinter", Season: SeasonWinter},
+	{Name: "Thaw", Season: SeasonWinter},
+	{Name: "Seedtime", Season: SeasonSpring},
+	{Name: "Rains", Season: SeasonSpring},
+	{Name: "Blossom", Season: SeasonSpring},
+	{Name: "Highsun", Season: SeasonSummer},
+	{Name: "Sunfire", Season: SeasonSummer},
+	{Name: "Goldfield", Season: SeasonSummer},
+	{Name: "Reaping", Season: SeasonAutumn},
+	{Name: "Leaffall", Season: SeasonAutumn},
+	{Name: "Mistmoon", Season: SeasonAutumn},
+	{Name: "Frostfall", Season: SeasonWinter},
+}
+
+// Festival is a holiday held on the same day every year.
+type Festival struct {
+	Name  string `json:"name"`
+	Month int    `json:"month"` // 1-based month of the year
+	Day   int    `json:"day"`   // 1-based day of the month
+}
+
+// calendarFestivals are the festivals of the year, in calendar order
+var calendarFestivals = []Festival{
+	{Name: "Midwinter Night", Month: 1, Day: 15},
+	{Name: "Greengrass", Month: 3, Day: 1},
+	{Name: "Midsummer", Month: 7, Day: 15},
+	{Name: "Harvest Home", Month: 9, Day: 30},
+	{Name: "Feast of the Moon", Month: 11, Day: 30},
+}
+
+// festivalOn returns the festival held on a day of a month, if any.
+func festivalOn(month, day int) (Festival, bool) {
+	for _, festival := range calendarFestivals {
+		if festival.Month == month && festival.Day == day {
+			return festival, true
+		}
+	}
+	return Festival{}, false
+}
+
+// CalendarDate is a moment of game time on the calendar.
+type CalendarDate struct {
+	Year      int       `json:"year"`
+	Month     int       `json:"month"` // 1-based month of the year
+	MonthName string    `json:"month_name"`
+	Day       int       `json:"day"`         // 1-based day of the month
+	DayOfYear int       `json:"day_of_year"` // 1-based day of the year
+	Hour      int       `json:"hour"`
+	Minute    int       `json:"minute"`
+	Season    Season    `json:"season"`
+	TimeOfDay TimeOfDay `json:"time_of_day"`
+	Festival  string    `json:"festival,omitempty"` // Festival held on the day
+}
+
+// String formats the date as "Highsun 15, year 1, 08:30".
+func (d CalendarDate) String() string {
+	return fmt.Sprintf("%s %d, year %d, %02d:%02d", d.MonthName, d.Day, d.Year, d.Hour, d.Minute)
+}
+
+// DateAt returns the calendar date of the given game tick count. Negative
+// tick counts fall on the first moment of the calendar.
+func DateAt(ticks int64) CalendarDate {
+	if ticks < 0 {
+		ticks = 0
+	}
+	days := int(ticks / TicksPerDay)
+	dayOfYear := days % DaysPerYear
+	month := dayOfYear/DaysPerMonth + 1
+	day := dayOfYear%DaysPerMonth + 1
+	secondOfDay := ticks % TicksPerDay
+
+	date := CalendarDate{
+		Year:      CalendarStartYear + days/DaysPerYear,
+		Month:     month,
+		MonthName: calendarMonths[month-1].Name,
+		Day:       day,
+		DayOfYear: dayOfYear + 1,
+		Hour:      int(secondOfDay / TicksPerHour),
+		Minute:    int(secondOfDay % TicksPerHour / 60),
+		Season:    calendarMonths[month-1].Season,
+		TimeOfDay: TimeOfDayAt(ticks),
+	}
+	if festival, ok := festivalOn(month, day); ok {
+		date.Festival = festival.Name
+	}
+	return date
+}
+
+// FestivalsBetween returns the festivals whose day begins after from and
+// no later than to, in the order they begin.
+func FestivalsBetween(from, to int64) []Festival {
+	var festivals []Festival
+	for day := from/TicksPerDay + 1; day*TicksPerDay <= to; day++ {
+		date := DateAt(day * TicksPerDay)
+		if festival, ok := festivalOn(date.Month, date.Day); ok {
+			festivals = append(festivals, festival)
+		}
+	}
+	return festivals
+}
+
+// Date returns the current calendar date.
+func (t *TimeManager) Date() CalendarDate {
+	return DateAt(t.CurrentTime.GameTicks)
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateAt(t *testing.T) {
+	date := DateAt(0)
+	assert.Equal(t, CalendarDate{
+		Year: CalendarStartYear, Month: 1, MonthName: "Deepwinter", Day: 1, DayOfYear: 1,
+		Season: SeasonWinter, TimeOfDay: TimeOfDayNight,
+	}, date)
+
+	date = DateAt(int64(DaysPerMonth*6+14)*TicksPerDay + 8*TicksPerHour + 30*60)
+	assert.Equal(t, "Sunfire 15, year 1, 08:30", date.String())
+	assert.Equal(t, SeasonSummer, date.Season)
+	assert.Equal(t, "Midsummer", date.Festival)
+	assert.Equal(t, 195, date.DayOfYear)
+
+	date = DateAt(DaysPerYear*TicksPerDay - 1)
+	assert.Equal(t, "Frostfall 30, year 1, 23:59", date.String())
+	assert.Equal(t, CalendarStartYear+1, DateAt(DaysPerYear*TicksPerDay).Year)
+}
+
+func TestFestivalsBetween(t *testing.T) {
+	festivals := FestivalsBetween(10*TicksPerDay, 14*TicksPerDay)
+	assert.Equal(t, []Festival{{Name: "Midwinter Night", Month: 1, Day: 15}}, festivals)
+
+	assert.Empty(t, FestivalsBetween(14*TicksPerDay, 15*TicksPerDay-1), "the festival began before the span")
+	assert.Len(t, FestivalsBetween(0, DaysPerYear*TicksPerDay), len(calendarFestivals))
+}
@@ -0,0 +1,497 @@
+package server
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Leaderboard categories ranked by getLeaderboard
+const (
+	LeaderboardMonstersSlain   = "monsters_slain"
+	LeaderboardDungeonsCleared = "dungeons_cleared"
+	LeaderboardDeaths          = "deaths"
+	LeaderboardPlaytime        = "playtime"
+	LeaderboardGold            = "gold"
+)
+
+// leaderboardCategories lists the categories in the order they are ranked
+var leaderboardCategories = []string{
+	LeaderboardMonstersSlain,
+	LeaderboardDungeonsCleared,
+	LeaderboardDeaths,
+	LeaderboardPlaytime,
+	LeaderboardGold,
+}
+
+// PlayerStats are the campaign statistics of one character. Kills, deaths
+// and cleared dungeons are counted as they happen; playtime, gold and
+// level are updated by each aggregation while the player is active.
+type PlayerStats struct {
+	PlayerID        string `yaml:"player_id" json:"player_id"`
+	Name            string `yaml:"name" json:"name"`
+	Level           int    `yaml:"level" json:"level"`
+	MonstersSlain   int    `yaml:"monsters_slain" json:"monsters_slain"`
+	DungeonsCleared int    `yaml:"dungeons_cleared" json:"dungeons_cleared"`
+	Deaths          int    `yaml:"deaths" json:"deaths"`
+	PlaytimeSeconds int64  `yaml:"playtime_seconds" json:"playtime_seconds"`
+	Gold            int    `yaml:"gold" json:"gold"`
+}
+
+// value returns the statistic ranked by a leaderboard category
+func (ps *PlayerStats) value(category string) int64 {
+	switch category {
+	case LeaderboardMonstersSlain:
+		return int64(ps.MonstersSlain)
+	case LeaderboardDungeonsCleared:
+		return int64(ps.DungeonsCleared)
+	case LeaderboardDeaths:
+		return int64(ps.Deaths)
+	case LeaderboardPlaytime:
+		return ps.PlaytimeSeconds
+	case LeaderboardGold:
+		return int64(ps.Gold)
+	}
+	return 0
+}
+
+// LeaderboardEntry is one character's place on a leaderboard. Characters
+// with equal values share a rank.
+type LeaderboardEntry struct {
+	Rank     int    `json:"rank"`
+	PlayerID string `json:"player_id"`
+	Name     string `json:"name"`
+	Value    int64  `json:"value"`
+}
+
+// CampaignStats totals the statistics of every character of the campaign
+// at the last aggregation.
+type CampaignStats struct {
+	Characters       int               `json:"characters"`
+	MonstersSlain    int               `json:"monsters_slain"`
+	DungeonsCleared  int               `json:"dungeons_cleared"` // Dungeon levels cleared, each counted once
+	Deaths           int               `json:"deaths"`
+	PlaytimeSeconds  int64             `json:"playtime_seconds"`
+	RichestCharacter *LeaderboardEntry `json:"richest_character,omitempty"`
+	AggregatedAt     time.Time         `json:"aggregated_at"`
+}
+
+// CampaignStatsState is the saved form of the campaign statistics
+type CampaignStatsState struct {
+	Players       map[string]PlayerStats `yaml:"players"`        // Statistics by player ID
+	ClearedLevels []int                  `yaml:"cleared_levels"` // Dungeon levels cleared of hostiles
+}
+
+// activePlayer is the state of a session player an aggregation reads
+type activePlayer struct {
+	id, name    string
+	level, gold int
+}
+
+// campaignStats keeps the statistics of the campaign's characters. Kills,
+// deaths and cleared dungeons are counted as they happen, while the
+// periodic aggregation adds playtime, refreshes gold and levels, and
+// recomputes the totals and leaderboards that getCampaignStats and
+// getLeaderboard return. The zero value is ready to use.
+type campaignStats struct {
+	mu              sync.Mutex
+	players         map[string]*PlayerStats
+	clearedLevels   map[int]bool
+	lastAggregation time.Time
+	summary         CampaignStats
+	boards          map[string][]LeaderboardEntry
+}
+
+// player returns the statistics of playerID, creating them on first use.
+// The caller must hold cs.mu.
+func (cs *campaignStats) player(playerID, name string) *PlayerStats {
+	if cs.players == nil {
+		cs.players = make(map[string]*PlayerStats)
+	}
+	stats, exists := cs.players[playerID]
+	if !exists {
+		stats = &PlayerStats{PlayerID: playerID}
+		cs.players[playerID] = stats
+	}
+	if name != "" {
+		stats.Name = name
+	}
+	return stats
+}
+
+// recordKill counts a monster slain by the player
+func (cs *campaignStats) recordKill(playerID, name string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.player(playerID, name).MonstersSlain++
+}
+
+// recordDeath counts a death of the player
+func (cs *campaignStats) recordDeath(playerID, name string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.player(playerID, name).Deaths++
+}
+
+// recordClear credits the player with clearing a dungeon level. A level is
+// only cleared once; it reports whether this was the first time.
+func (cs *campaignStats) recordClear(playerID, name string, level int) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.clearedLevels[level] {
+		return false
+	}
+	if cs.clearedLevels == nil {
+		cs.clearedLevels = make(map[int]bool)
+	}
+	cs.clearedLevels[level] = true
+	cs.player(playerID, name).DungeonsCleared++
+	return true
+}
+
+// aggregate adds the time since the previous aggregation to the playtime
+// of the active players, refreshes their names, levels and gold, and
+// recomputes the campaign totals and leaderboards as of now.
+func (cs *campaignStats) aggregate(now time.Time, active []activePlayer) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	var elapsed int64
+	if !cs.lastAggregation.IsZero() && now.After(cs.lastAggregation) {
+		elapsed = int64(now.Sub(cs.lastAggregation) / time.Second)
+	}
+	for _, p := range active {
+		stats := cs.player(p.id, p.name)
+		stats.PlaytimeSeconds += elapsed
+		stats.Level = p.level
+		stats.Gold = p.gold
+	}
+	cs.lastAggregation = now
+
+	summary := CampaignStats{
+		Characters:      len(cs.players),
+		DungeonsCleared: len(cs.clearedLevels),
+		AggregatedAt:    now,
+	}
+	for _, stats := range cs.players {
+		summary.MonstersSlain += stats.MonstersSlain
+		summary.Deaths += stats.Deaths
+		summary.PlaytimeSeconds += stats.PlaytimeSeconds
+	}
+
+	cs.boards = make(map[string][]LeaderboardEntry, len(leaderboardCategories))
+	for _, category := range leaderboardCategories {
+		cs.boards[category] = cs.rankLocked(category)
+	}
+	if richest := cs.boards[LeaderboardGold]; len(richest) > 0 {
+		entry := richest[0]
+		summary.RichestCharacter = &entry
+	}
+	cs.summary = summary
+}
+
+// rankLocked ranks every character by category, highest first and by name
+// among equals. The caller must hold cs.mu.
+func (cs *campaignStats) rankLocked(category string) []LeaderboardEntry {
+	entries := make([]LeaderboardEntry, 0, len(cs.players))
+	for _, stats := range cs.players {
+		entries = append(entries, LeaderboardEntry{PlayerID: stats.PlayerID, Name: stats.Name, Value: stats.value(category)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].PlayerID < entries[j].PlayerID
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].Value == entries[i-1].Value {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+	return entries
+}
+
+// totals returns the campaign totals of the last aggregation
+func (cs *campaignStats) totals() CampaignStats {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	summary := cs.summary
+	if summary.RichestCharacter != nil {
+		richest := *summary.RichestCharacter
+		summary.RichestCharacter = &richest
+	}
+	return summary
+}
+
+// leaderboard returns the first limit entries of the category's
+// leaderboard at the last aggregation
+func (cs *campaignStats) leaderboard(category string, limit int) []LeaderboardEntry {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	entries := cs.boards[category]
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return append([]LeaderboardEntry{}, entries...)
+}
+
+// playerStats returns the current statistics of playerID
+func (cs *campaignStats) playerStats(playerID string) (PlayerStats, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	stats, exists := cs.players[playerID]
+	if !exists {
+		return PlayerStats{}, false
+	}
+	return *stats, true
+}
+
+// Snapshot returns the campaign statistics for saving
+func (cs *campaignStats) Snapshot() CampaignStatsState {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	state := CampaignStatsState{
+		Players:       make(map[string]PlayerStats, len(cs.players)),
+		ClearedLevels: make([]int, 0, len(cs.clearedLevels)),
+	}
+	for id, stats := range cs.players {
+		state.Players[id] = *stats
+	}
+	for level := range cs.clearedLevels {
+		state.ClearedLevels = append(state.ClearedLevels, level)
+	}
+	sort.Ints(state.ClearedLevels)
+	return state
+}
+
+// Restore replaces the campaign statistics with saved ones. Totals and
+// leaderboards are recomputed by the next aggregation.
+func (cs *campaignStats) Restore(state CampaignStatsState) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.players = make(map[string]*PlayerStats, len(state.Players))
+	for id, stats := range state.Players {
+		stats := stats
+		cs.players[id] = &stats
+	}
+	cs.clearedLevels = make(map[int]bool, len(state.ClearedLevels))
+	for _, level := range state.ClearedLevels {
+		cs.clearedLevels[level] = true
+	}
+}
+
+// attachCampaignStats reloads the saved campaign statistics, counts the
+// deaths of session players and aggregates once, so the statistics are
+// available before the first periodic aggregation. Kills and cleared
+// dungeons are counted from the combat log, which knows who struck the
+// final blow.
+func (s *RPCServer) attachCampaignStats() {
+	s.restoreCampaignStats()
+
+	s.eventSys.Subscribe(game.EventDeath, func(event game.GameEvent) {
+		if name, isPlayer := s.sessionPlayerName(event.SourceID); isPlayer {
+			s.campaignStats.recordDeath(event.SourceID, name)
+		}
+	})
+	s.aggregateCampaignStats()
+}
+
+// restoreCampaignStats reloads the campaign statistics saved by
+// persistState, if any.
+func (s *RPCServer) restoreCampaignStats() {
+	if s.store == nil || !s.store.Exists(campaignStatsKey) {
+		return
+	}
+
+	var state CampaignStatsState
+	if err := s.store.Load(campaignStatsKey, &state); err != nil {
+		logrus.WithError(err).Warn("failed to load campaign statistics, starting without them")
+		return
+	}
+	s.campaignStats.Restore(state)
+	s.aggregateCampaignStats()
+
+	logrus.WithFields(logrus.Fields{
+		"function": "restoreCampaignStats",
+		"players":  len(state.Players),
+	}).Info("campaign statistics restored")
+}
+
+// startCampaignStatsAggregation periodically aggregates the campaign
+// statistics until the server shuts down.
+func (s *RPCServer) startCampaignStatsAggregation() {
+	if s.config == nil || s.config.StatsAggregationInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.StatsAggregationInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.aggregateCampaignStats()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// aggregateCampaignStats aggregates the campaign statistics with the
+// players of the sessions active since the previous aggregation.
+func (s *RPCServer) aggregateCampaignStats() {
+	s.campaignStats.mu.Lock()
+	since := s.campaignStats.lastAggregation
+	s.campaignStats.mu.Unlock()
+
+	s.mu.RLock()
+	active := make([]activePlayer, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if session.Player == nil || session.LastActive.Before(since) {
+			continue
+		}
+		active = append(active, activePlayer{
+			id:    session.Player.GetID(),
+			name:  session.Player.GetName(),
+			level: session.Player.GetLevel(),
+			gold:  session.Player.Gold,
+		})
+	}
+	s.mu.RUnlock()
+
+	s.campaignStats.aggregate(time.Now(), active)
+}
+
+// campaignStatsKill counts a combat log entry that killed an NPC as a
+// monster slain by the attacker. A kill leaving no hostile NPC alive on the
+// defender's level clears that dungeon level.
+func (s *RPCServer) campaignStatsKill(entry CombatLogEntry) {
+	if s.state.WorldState == nil {
+		return
+	}
+	npc, isNPC := s.state.WorldState.Objects[entry.DefenderID].(*game.NPC)
+	if !isNPC {
+		return
+	}
+	name, isPlayer := s.sessionPlayerName(entry.AttackerID)
+	if !isPlayer {
+		return
+	}
+	s.campaignStats.recordKill(entry.AttackerID, name)
+
+	killer, isCharacter := s.state.WorldState.Objects[entry.AttackerID].(*game.Player)
+	if !isCharacter {
+		return
+	}
+	level := npc.Position.Level
+	threats := game.DefaultThreatTable()
+	for _, obj := range s.state.WorldState.Objects {
+		other, ok := obj.(*game.NPC)
+		if ok && other.HP > 0 && other.Position.Level == level && threats.IsHostile(&killer.Character, other) {
+			return
+		}
+	}
+	if s.campaignStats.recordClear(entry.AttackerID, name, level) {
+		logrus.WithFields(logrus.Fields{
+			"function":  "campaignStatsKill",
+			"player_id": entry.AttackerID,
+			"level":     level,
+		}).Info("dungeon level cleared")
+	}
+}
+
+// handleGetCampaignStats returns the campaign totals of the last
+// aggregation and the requesting player's own statistics.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//
+// Returns:
+//   - interface{}: Map containing the campaign totals ("campaign") and the
+//     player's statistics ("player")
+//   - error: Invalid parameters or session
+func (s *RPCServer) handleGetCampaignStats(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid campaign statistics parameters", err.Error())
+	}
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	player, exists := s.campaignStats.playerStats(session.Player.GetID())
+	if !exists {
+		player = PlayerStats{PlayerID: session.Player.GetID(), Name: session.Player.GetName(), Level: session.Player.GetLevel()}
+	}
+	return map[string]interface{}{
+		"success":  true,
+		"campaign": s.campaignStats.totals(),
+		"player":   player,
+	}, nil
+}
+
+// handleGetLeaderboard ranks the campaign's characters by one statistic as
+// of the last aggregation.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - category: string - monsters_slain, dungeons_cleared, deaths, playtime
+//     or gold
+//   - limit: number (optional) - Entries returned, default LeaderboardSize
+//
+// Returns:
+//   - interface{}: Map containing the category, the ranked entries and the
+//     time of the aggregation
+//   - error: Invalid parameters, session or category
+func (s *RPCServer) handleGetLeaderboard(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+		Category  string `json:"category"`
+		Limit     int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid leaderboard parameters", err.Error())
+	}
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	known := false
+	for _, category := range leaderboardCategories {
+		known = known || category == req.Category
+	}
+	if !known {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Unknown leaderboard category", req.Category)
+	}
+	if req.Limit <= 0 || req.Limit > LeaderboardMaxSize {
+		req.Limit = LeaderboardSize
+	}
+
+	return map[string]interface{}{
+		"success":       true,
+		"category":      req.Category,
+		"entries":       s.campaignStats.leaderboard(req.Category, req.Limit),
+		"aggregated_at": s.campaignStats.totals().AggregatedAt,
+	}, nil
+}
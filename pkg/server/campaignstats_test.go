@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaignStats_AggregateAndRestore(t *testing.T) {
+	var cs campaignStats
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cs.aggregate(start, nil)
+
+	cs.recordKill("p1", "Aria")
+	cs.recordKill("p1", "Aria")
+	cs.recordKill("p2", "Borin")
+	cs.recordDeath("p2", "Borin")
+	assert.True(t, cs.recordClear("p1", "Aria", 1))
+	assert.False(t, cs.recordClear("p2", "Borin", 1), "a level is only cleared once")
+
+	cs.aggregate(start.Add(90*time.Second), []activePlayer{
+		{id: "p1", name: "Aria", level: 3, gold: 50},
+		{id: "p2", name: "Borin", level: 2, gold: 120},
+	})
+
+	totals := cs.totals()
+	assert.Equal(t, 2, totals.Characters)
+	assert.Equal(t, 3, totals.MonstersSlain)
+	assert.Equal(t, 1, totals.DungeonsCleared)
+	assert.Equal(t, 1, totals.Deaths)
+	assert.Equal(t, int64(180), totals.PlaytimeSeconds)
+	require.NotNil(t, totals.RichestCharacter)
+	assert.Equal(t, "Borin", totals.RichestCharacter.Name)
+	assert.Equal(t, int64(120), totals.RichestCharacter.Value)
+
+	slain := cs.leaderboard(LeaderboardMonstersSlain, 10)
+	require.Len(t, slain, 2)
+	assert.Equal(t, LeaderboardEntry{Rank: 1, PlayerID: "p1", Name: "Aria", Value: 2}, slain[0])
+	playtime := cs.leaderboard(LeaderboardPlaytime, 10)
+	assert.Equal(t, 1, playtime[1].Rank, "equal values share a rank")
+	assert.Len(t, cs.leaderboard(LeaderboardGold, 1), 1)
+
+	var restored campaignStats
+	restored.Restore(cs.Snapshot())
+	restored.aggregate(start.Add(time.Hour), nil)
+	assert.Equal(t, totals.MonstersSlain, restored.totals().MonstersSlain)
+	assert.Equal(t, totals.PlaytimeSeconds, restored.totals().PlaytimeSeconds, "restored statistics gain no playtime before their first aggregation")
+	assert.False(t, restored.recordClear("p2", "Borin", 1), "cleared levels are restored")
+}
+
+func TestCampaignStatsKill(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	playerID := session.Player.GetID()
+
+	rat := &game.NPC{Character: game.Character{ID: "stats-rat", Name: "Rat", Position: game.Position{Level: 2}}}
+	goblin := &game.NPC{Character: game.Character{ID: "stats-goblin", Name: "Goblin", HP: 5, Position: game.Position{Level: 2}}}
+	merchant := &game.NPC{Character: game.Character{ID: "stats-merchant", Name: "Merchant", HP: 10, Position: game.Position{Level: 2}}, Behavior: "merchant"}
+	for _, npc := range []*game.NPC{rat, goblin, merchant} {
+		server.state.WorldState.Objects[npc.ID] = npc
+	}
+
+	server.campaignStatsKill(CombatLogEntry{AttackerID: playerID, DefenderID: rat.ID, Killed: true})
+	server.campaignStatsKill(CombatLogEntry{AttackerID: goblin.ID, DefenderID: playerID, Killed: true})
+	stats, exists := server.campaignStats.playerStats(playerID)
+	require.True(t, exists)
+	assert.Equal(t, 1, stats.MonstersSlain, "only NPCs slain by players count")
+	assert.Zero(t, stats.DungeonsCleared, "the goblin still holds the level")
+
+	goblin.HP = 0
+	server.campaignStatsKill(CombatLogEntry{AttackerID: playerID, DefenderID: goblin.ID, Killed: true})
+	stats, _ = server.campaignStats.playerStats(playerID)
+	assert.Equal(t, 2, stats.MonstersSlain)
+	assert.Equal(t, 1, stats.DungeonsCleared, "peaceful NPCs do not hold a level")
+}
+
+func TestHandleGetCampaignStats(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	playerID := session.Player.GetID()
+	session.Player.Gold = 250
+
+	server.eventSys.Emit(game.GameEvent{Type: game.EventDeath, SourceID: playerID})
+	assert.Eventually(t, func() bool {
+		stats, _ := server.campaignStats.playerStats(playerID)
+		return stats.Deaths == 1
+	}, time.Second, 10*time.Millisecond)
+	server.campaignStats.recordKill(playerID, session.Player.GetName())
+	server.aggregateCampaignStats()
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID})
+	result, err := server.handleGetCampaignStats(params)
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	campaign := response["campaign"].(CampaignStats)
+	assert.Equal(t, 1, campaign.MonstersSlain)
+	assert.Equal(t, 1, campaign.Deaths)
+	require.NotNil(t, campaign.RichestCharacter)
+	assert.Equal(t, playerID, campaign.RichestCharacter.PlayerID)
+	player := response["player"].(PlayerStats)
+	assert.Equal(t, 250, player.Gold)
+	assert.Equal(t, session.Player.GetLevel(), player.Level)
+
+	_, err = server.handleGetCampaignStats([]byte(`{"session_id":"missing"}`))
+	assert.Error(t, err)
+}
+
+func TestHandleGetLeaderboard(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	server.campaignStats.recordKill("other", "Borin")
+	server.campaignStats.recordKill("other", "Borin")
+	server.campaignStats.recordKill(session.Player.GetID(), session.Player.GetName())
+	server.aggregateCampaignStats()
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "category": LeaderboardMonstersSlain, "limit": 1})
+	result, err := server.handleGetLeaderboard(params)
+	require.NoError(t, err)
+	entries := result.(map[string]interface{})["entries"].([]LeaderboardEntry)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Borin", entries[0].Name)
+	assert.Equal(t, int64(2), entries[0].Value)
+
+	params, _ = json.Marshal(map[string]interface{}{"session_id": session.SessionID, "category": "bravery"})
+	_, err = server.handleGetLeaderboard(params)
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code)
+}
+
+func TestCampaignStats_PersistedWithSaves(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.store = persistence.NewMemoryStore()
+	server.campaignStats.recordKill("player-1", "Aria")
+	server.campaignStats.recordClear("player-1", "Aria", 3)
+	require.NoError(t, server.persistState())
+
+	server.campaignStats.Restore(CampaignStatsState{})
+	server.restoreCampaignStats()
+	stats, exists := server.campaignStats.playerStats("player-1")
+	require.True(t, exists)
+	assert.Equal(t, 1, stats.MonstersSlain)
+	assert.Equal(t, 1, server.campaignStats.totals().DungeonsCleared, "restoring aggregates the saved statistics")
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg/quests"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ChronicleState is the saved form of the chronicle
+type ChronicleState struct {
+	Players map[string][]quests.RecapEvent `yaml:"players"` // Moments by player ID, oldest first
+}
+
+// chronicle remembers the notable moments of each player's adventures,
+// such as quests taken up and finished, foes slain and discoveries, from
+// which getSessionRecap tells the story so far. The zero value is ready to
+// use.
+type chronicle struct {
+	mu      sync.Mutex
+	players map[string][]quests.RecapEvent
+}
+
+// record appends event to the player's chronicle, forgetting the oldest
+// moment once it holds ChronicleSize.
+func (c *chronicle) record(playerID string, event quests.RecapEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.players == nil {
+		c.players = make(map[string][]quests.RecapEvent)
+	}
+	events := append(c.players[playerID], event)
+	if len(events) > ChronicleSize {
+		events = events[len(events)-ChronicleSize:]
+	}
+	c.players[playerID] = events
+}
+
+// timeline returns the moments of the given players together
+func (c *chronicle) timeline(playerIDs ...string) []quests.RecapEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var events []quests.RecapEvent
+	for _, id := range playerIDs {
+		events = append(events, c.players[id]...)
+	}
+	return events
+}
+
+// Snapshot returns the chronicle for saving
+func (c *chronicle) Snapshot() ChronicleState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := ChronicleState{Players: make(map[string][]quests.RecapEvent, len(c.players))}
+	for id, events := range c.players {
+		state.Players[id] = append([]quests.RecapEvent(nil), events...)
+	}
+	return state
+}
+
+// Restore replaces the chronicle with a saved one
+func (c *chronicle) Restore(state ChronicleState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.players = make(map[string][]quests.RecapEvent, len(state.Players))
+	for id, events := range state.Players {
+		c.players[id] = append([]quests.RecapEvent(nil), events...)
+	}
+}
+
+// attachChronicle reloads the saved chronicle and records the quest, level
+// and discovery events of players in it. Kills are recorded from the
+// combat log, which knows who struck the final blow.
+func (s *RPCServer) attachChronicle() {
+	s.restoreChronicle()
+
+	s.eventSys.Subscribe(game.EventQuestUpdate, func(event game.GameEvent) {
+		kinds := map[string]string{
+			"started":   quests.RecapQuestStarted,
+			"completed": quests.RecapQuestCompleted,
+			"failed":    quests.RecapQuestFailed,
+		}
+		action, _ := event.Data["action"].(string)
+		kind, notable := kinds[action]
+		if !notable {
+			return
+		}
+		subject, _ := event.Data["title"].(string)
+		if subject == "" {
+			subject, _ = event.Data["quest_id"].(string)
+		}
+		s.recordChronicle(event.TargetID, kind, subject)
+	})
+	s.eventSys.Subscribe(game.EventLevelUp, func(event game.GameEvent) {
+		if level, ok := event.Data["newLevel"].(int); ok {
+			s.recordChronicle(event.SourceID, quests.RecapLevelUp, fmt.Sprintf("level %d", level))
+		}
+	})
+	s.eventSys.Subscribe(EventDoorChanged, func(event game.GameEvent) {
+		if event.Data["change"] == "found" {
+			s.recordChronicle(event.SourceID, quests.RecapDiscovery, "a secret door")
+		}
+	})
+	s.eventSys.Subscribe(game.EventItemPickup, func(event game.GameEvent) {
+		if item, ok := event.Data["item"].(game.Item); ok {
+			s.recordChronicle(event.SourceID, quests.RecapDiscovery, item.Name)
+		}
+	})
+}
+
+// restoreChronicle reloads the chronicle saved by persistState, if any.
+func (s *RPCServer) restoreChronicle() {
+	if s.store == nil || !s.store.Exists(chronicleKey) {
+		return
+	}
+
+	var state ChronicleState
+	if err := s.store.Load(chronicleKey, &state); err != nil {
+		logrus.WithError(err).Warn("failed to load chronicle, starting without it")
+		return
+	}
+	s.chronicle.Restore(state)
+
+	logrus.WithFields(logrus.Fields{
+		"function": "restoreChronicle",
+		"players":  len(state.Players),
+	}).Info("chronicle restored")
+}
+
+// recordChronicle records a moment of a session player's story. Moments of
+// other characters, such as NPCs, are not kept.
+func (s *RPCServer) recordChronicle(playerID, kind, subject string) {
+	name, isPlayer := s.sessionPlayerName(playerID)
+	if !isPlayer {
+		return
+	}
+	s.chronicle.record(playerID, quests.RecapEvent{
+		Kind:    kind,
+		Time:    time.Now(),
+		Actor:   name,
+		Subject: subject,
+	})
+}
+
+// chronicleKill records a combat log entry that killed its defender as a
+// kill by the attacker.
+func (s *RPCServer) chronicleKill(entry CombatLogEntry) {
+	subject := entry.DefenderName
+	if subject == "" {
+		subject = entry.DefenderID
+	}
+	s.recordChronicle(entry.AttackerID, quests.RecapKill, subject)
+}
+
+// sessionPlayerName returns the name of the session player with playerID
+func (s *RPCServer) sessionPlayerName(playerID string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, session := range s.sessions {
+		if session.Player != nil && session.Player.GetID() == playerID {
+			return session.Player.GetName(), true
+		}
+	}
+	return "", false
+}
+
+// handleGetSessionRecap tells the story so far of the session's player and
+// the rest of their party: the quests they took up and finished, the foes
+// they slew, what they discovered and the levels they reached, in
+// chronological order.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//
+// Returns:
+//   - interface{}: Map containing the recap: its title, the timeline of
+//     events, the narrative paragraphs and the formatted text
+//   - error: Invalid parameters or session
+func (s *RPCServer) handleGetSessionRecap(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleGetSessionRecap",
+	})
+	logger.Debug("entering handleGetSessionRecap")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid recap parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	playerIDs := []string{session.Player.GetID()}
+	if party, exists := s.parties.PartyOf(session.Player.GetID()); exists {
+		for _, id := range party.GetMembers() {
+			if id != session.Player.GetID() {
+				playerIDs = append(playerIDs, id)
+			}
+		}
+	}
+
+	// Seeded by the session, so asking again tells the same story
+	seed := fnv.New64a()
+	seed.Write([]byte(req.SessionID))
+	recap := quests.NewNarrativeEngine().GenerateRecap(s.chronicle.timeline(playerIDs...), rand.New(rand.NewSource(int64(seed.Sum64()))))
+
+	logger.WithFields(logrus.Fields{
+		"session_id": req.SessionID,
+		"players":    len(playerIDs),
+		"events":     len(recap.Timeline),
+	}).Debug("session recap generated")
+
+	return map[string]interface{}{
+		"success": true,
+		"recap":   recap,
+	}, nil
+}
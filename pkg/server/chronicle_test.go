@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg/quests"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChronicle_RecordAndRestore(t *testing.T) {
+	var c chronicle
+	for i := 0; i < ChronicleSize+5; i++ {
+		c.record("player-1", quests.RecapEvent{Kind: quests.RecapKill, Subject: fmt.Sprintf("rat-%d", i)})
+	}
+	c.record("player-2", quests.RecapEvent{Kind: quests.RecapDiscovery, Subject: "a secret door"})
+
+	timeline := c.timeline("player-1")
+	require.Len(t, timeline, ChronicleSize)
+	assert.Equal(t, "rat-5", timeline[0].Subject, "the oldest moments are forgotten first")
+	assert.Len(t, c.timeline("player-1", "player-2"), ChronicleSize+1)
+
+	var restored chronicle
+	restored.Restore(c.Snapshot())
+	assert.Equal(t, c.timeline("player-1", "player-2"), restored.timeline("player-1", "player-2"))
+}
+
+func TestHandleGetSessionRecap(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	playerID := session.Player.GetID()
+
+	start, _ := json.Marshal(map[string]interface{}{
+		"session_id": session.SessionID,
+		"quest":      game.Quest{ID: "rats", Title: "Rats in the Cellar", Status: game.QuestActive},
+	})
+	_, err := server.handleStartQuest(start)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(server.chronicle.timeline(playerID)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	server.chronicleKill(CombatLogEntry{AttackerID: playerID, DefenderID: "rat-1", DefenderName: "Giant Rat", Killed: true})
+	server.chronicleKill(CombatLogEntry{AttackerID: "rat-2", DefenderID: playerID, Killed: true})
+
+	complete, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "quest_id": "rats"})
+	_, err = server.handleCompleteQuest(complete)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(server.chronicle.timeline(playerID)) == 3
+	}, time.Second, 10*time.Millisecond, "only the player's kill is a moment of their story")
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID})
+	result, err := server.handleGetSessionRecap(params)
+	require.NoError(t, err)
+	recap := result.(map[string]interface{})["recap"].(*quests.SessionRecap)
+
+	require.Len(t, recap.Timeline, 3)
+	assert.Equal(t, quests.RecapQuestStarted, recap.Timeline[0].Kind)
+	assert.Equal(t, quests.RecapQuestCompleted, recap.Timeline[2].Kind)
+	assert.Contains(t, recap.Text, "Rats in the Cellar")
+	assert.Contains(t, recap.Text, "Giant Rat")
+	assert.Contains(t, recap.Text, session.Player.GetName())
+
+	again, err := server.handleGetSessionRecap(params)
+	require.NoError(t, err)
+	assert.Equal(t, recap.Text, again.(map[string]interface{})["recap"].(*quests.SessionRecap).Text)
+}
+
+func TestChronicle_PersistedWithSaves(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.store = persistence.NewMemoryStore()
+	server.chronicle.record("player-1", quests.RecapEvent{Kind: quests.RecapDiscovery, Actor: "Aria", Subject: "a secret door"})
+	require.NoError(t, server.persistState())
+
+	server.chronicle.Restore(ChronicleState{})
+	server.restoreChronicle()
+	timeline := server.chronicle.timeline("player-1")
+	require.Len(t, timeline, 1)
+	assert.Equal(t, "a secret door", timeline[0].Subject)
+}
@@ -3,6 +3,7 @@ package server
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"goldbox-rpg/pkg/game"
@@ -12,7 +13,7 @@ import (
 
 // DefaultTurnDuration defines the default time limit for combat turns.
 // Players have this amount of time to complete their actions before the turn automatically ends.
-var DefaultTurnDuration = 10 * time.Second
+var DefaultTurnDuration = 60 * time.Second
 
 // CombatState represents the current state of an active combat encounter.
 // It tracks all participating entities, combat progression, and environmental effects.
@@ -56,8 +57,14 @@ type CombatState struct {
 //   - IsInCombat: Flag indicating active combat state
 //   - CombatGroups: Maps entity IDs to their allied group members
 //   - DelayedActions: Queue of actions scheduled for future execution
+//   - Reactions: Held reactions keyed by owning entity ID
+//   - InitiativeMode: When initiative is rolled (fixed or per_round)
+//   - Breakdown: Initiative computations of the current round
 //   - turnTimer: Internal timer for enforcing turn time limits
 //   - turnDuration: Configurable duration for each turn
+//   - warnTimer, turnWarning: Warning sent before a turn times out
+//   - skipLimit, skippedTurns: Escalating policy for idle combatants
+//   - stateListener, stateRevision: Receiver and count of state changes
 type TurnManager struct {
 	// CurrentRound represents the current combat round number
 	CurrentRound int `yaml:"turn_current_round"`
@@ -71,8 +78,31 @@ type TurnManager struct {
 	CombatGroups map[string][]string `yaml:"turn_combat_groups"`
 	// DelayedActions holds actions to be executed at a later time
 	DelayedActions []DelayedAction `yaml:"turn_delayed_actions"`
-	turnTimer      *time.Timer     // Timer for turn timeouts
-	turnDuration   time.Duration   // Duration for turn timeouts
+	// Reactions maps entity IDs to the reactions they are holding
+	Reactions map[string][]Reaction `yaml:"turn_reactions,omitempty"`
+	// InitiativeMode is the initiative mode of the current combat
+	InitiativeMode string `yaml:"turn_initiative_mode,omitempty"`
+	// Breakdown holds the initiative computations of the current round
+	Breakdown    []InitiativeEntry `yaml:"turn_initiative_breakdown,omitempty"`
+	turnTimer    *time.Timer       // Timer for turn timeouts
+	turnDuration time.Duration     // Duration for turn timeouts
+
+	timerMu       sync.Mutex              // Guards turnTimer, turnDuration and the fields below
+	warnTimer     *time.Timer             // Timer for the turn timeout warning
+	turnWarning   time.Duration           // Time left when the warning is sent
+	turnDeadline  time.Time               // When the current turn times out
+	timerSerial   uint64                  // Incremented whenever the timer restarts
+	timerHandlers TurnTimerHandlers       // Receivers of warnings and timeouts
+	skipLimit     int                     // Skipped turns before removal; 0 never removes
+	skippedTurns  map[string]int          // Consecutive timed out turns by entity
+	stateListener func(CombatStateChange) // Receiver of combat state changes
+	stateRevision uint64                  // Revision of the last state change
+
+	reactionMu      sync.Mutex                 // Guards reaction state and prompts
+	reactionsUsed   map[string]int             // Round in which each entity last reacted
+	opportunityCost map[string]int             // Action points owed for opportunity attacks by entity
+	reactionPrompts map[string]*ReactionPrompt // Outstanding prompts by ID
+	reactionTimeout time.Duration              // Prompt timeout; DefaultReactionTimeout if zero
 }
 
 // NewTurnManager creates and initializes a new TurnManager instance.
@@ -98,6 +128,8 @@ func NewTurnManager() *TurnManager {
 		DelayedActions: make([]DelayedAction, 0),
 		turnTimer:      nil, // Initialize as nil, will be set when combat starts
 		turnDuration:   DefaultTurnDuration,
+		turnWarning:    DefaultTurnWarning,
+		skipLimit:      DefaultAFKSkipLimit,
 	}
 }
 
@@ -163,6 +195,8 @@ func (tm *TurnManager) Clone() *TurnManager {
 		Initiative:     make([]string, len(tm.Initiative)),
 		CombatGroups:   make(map[string][]string),
 		DelayedActions: make([]DelayedAction, len(tm.DelayedActions)),
+		InitiativeMode: tm.InitiativeMode,
+		Breakdown:      append([]InitiativeEntry(nil), tm.Breakdown...),
 	}
 
 	// Copy initiative slice
@@ -178,18 +212,30 @@ func (tm *TurnManager) Clone() *TurnManager {
 	// Copy delayed actions
 	copy(clone.DelayedActions, tm.DelayedActions)
 
+	// Deep copy held reactions
+	tm.reactionMu.Lock()
+	if tm.Reactions != nil {
+		clone.Reactions = make(map[string][]Reaction, len(tm.Reactions))
+		for k, v := range tm.Reactions {
+			clone.Reactions[k] = append([]Reaction(nil), v...)
+		}
+	}
+	tm.reactionMu.Unlock()
+
 	return clone
 }
 
 // Serialize returns a map representation of the TurnManager state.
 func (tm *TurnManager) Serialize() map[string]interface{} {
 	return map[string]interface{}{
-		"current_round":    tm.CurrentRound,
-		"initiative_order": tm.Initiative,
-		"current_index":    tm.CurrentIndex,
-		"in_combat":        tm.IsInCombat,
-		"combat_groups":    tm.CombatGroups,
-		"delayed_actions":  tm.DelayedActions,
+		"current_round":        tm.CurrentRound,
+		"initiative_order":     tm.Initiative,
+		"current_index":        tm.CurrentIndex,
+		"in_combat":            tm.IsInCombat,
+		"combat_groups":        tm.CombatGroups,
+		"delayed_actions":      tm.DelayedActions,
+		"initiative_mode":      tm.InitiativeMode,
+		"initiative_breakdown": tm.Breakdown,
 	}
 }
 
@@ -281,6 +327,7 @@ func (tm *TurnManager) StartCombat(initiative []string) error {
 	tm.CurrentIndex = 0
 	tm.CurrentRound = 1
 	tm.startTurnTimer()
+	tm.notifyState(CombatStateChange{Kind: CombatStateInitiative})
 
 	// Initialize the global game tick counter at combat start
 	currentTicks := tm.getCurrentGameTicks()
@@ -294,13 +341,6 @@ func (tm *TurnManager) StartCombat(initiative []string) error {
 	return nil
 }
 
-func (tm *TurnManager) startTurnTimer() {
-	if tm.turnTimer != nil {
-		tm.turnTimer.Stop()
-	}
-	tm.turnTimer = time.AfterFunc(tm.turnDuration, tm.endTurn)
-}
-
 func (tm *TurnManager) endTurn() {
 	// Check if initiative is valid before accessing it
 	if len(tm.Initiative) == 0 || tm.CurrentIndex >= len(tm.Initiative) {
@@ -323,8 +363,11 @@ func (tm *TurnManager) endTurn() {
 		}
 	}
 
+	change := CombatStateChange{Kind: CombatStateTurn}
 	if !actorHasAction {
-		if err := tm.moveToTopOfInitiative(currentActor); err != nil {
+		if err := tm.moveToTopOfInitiative(currentActor); err == nil {
+			change.Kind = CombatStateInitiative
+		} else {
 			logrus.WithFields(logrus.Fields{
 				"function":     "endTurn",
 				"currentActor": currentActor,
@@ -346,6 +389,7 @@ func (tm *TurnManager) endTurn() {
 	if tm.IsInCombat {
 		tm.startTurnTimer()
 	}
+	tm.notifyState(change)
 }
 
 // AdvanceTurn moves to the next entity in the initiative order.
@@ -401,6 +445,8 @@ func (tm *TurnManager) AdvanceTurn() string {
 	game.SetCurrentGameTick(currentTicks)
 
 	nextEntity := tm.Initiative[tm.CurrentIndex]
+	tm.startTurnTimer()
+	tm.notifyState(CombatStateChange{Kind: CombatStateTurn})
 	logrus.WithFields(logrus.Fields{
 		"function":   "AdvanceTurn",
 		"prevIndex":  prevIndex,
@@ -487,6 +533,7 @@ func (s *RPCServer) checkCombatEnd() bool {
 		logrus.WithFields(logrus.Fields{
 			"function": "checkCombatEnd",
 		}).Info("ending combat - only one or no hostile groups remain")
+		s.celebrateVictory(s.state.TurnManager.Initiative)
 		s.endCombat()
 		return true
 	}
@@ -554,14 +601,15 @@ func (s *RPCServer) endCombat() {
 	}).Debug("ending combat")
 
 	// Stop the turn timer if it's running
-	if s.state.TurnManager.turnTimer != nil {
-		s.state.TurnManager.turnTimer.Stop()
-		s.state.TurnManager.turnTimer = nil
-	}
+	s.state.TurnManager.stopTurnTimer()
 
 	s.state.TurnManager.IsInCombat = false
 	s.state.TurnManager.Initiative = nil
+	s.state.TurnManager.Breakdown = nil
 	s.state.TurnManager.CurrentIndex = 0
+	s.state.TurnManager.notifyState(CombatStateChange{Kind: CombatStateEnded})
+	s.finishCombatReplay()
+	s.finishCombatLog()
 
 	logrus.WithFields(logrus.Fields{
 		"function": "endCombat",
@@ -595,14 +643,16 @@ func (s *RPCServer) applyDamage(target game.GameObject, damage int) error {
 		"targetID": target.GetID(),
 	}).Debug("applying damage to target")
 
-	// Handle both Character and Player types
+	// Handle Character, Player and NPC types
 	var char *game.Character
 	if player, ok := target.(*game.Player); ok {
 		char = &player.Character
+	} else if npc, ok := target.(*game.NPC); ok {
+		char = &npc.Character
 	} else if character, ok := target.(*game.Character); ok {
 		char = character
 	} else {
-		err := fmt.Errorf("target cannot receive damage")
+		err := ErrInvalidTarget.WithMessage("target cannot receive damage")
 		logrus.WithFields(logrus.Fields{
 			"function": "applyDamage",
 			"error":    err.Error(),
@@ -629,12 +679,26 @@ func (s *RPCServer) applyDamage(target game.GameObject, damage int) error {
 		"damage":   damage,
 	}).Info("damage applied to character")
 
+	s.eventSys.Emit(game.GameEvent{
+		Type:     game.EventDamage,
+		TargetID: char.GetID(),
+		Data: map[string]interface{}{
+			"damage": damage,
+			"hp":     char.HP,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+
+	s.companionWounded(char, oldHP)
+
 	if char.HP == 0 {
 		logrus.WithFields(logrus.Fields{
 			"function": "applyDamage",
 			"charID":   char.GetID(),
 		}).Info("character died from damage")
 		s.handleCharacterDeath(char)
+		s.notifyDeathWebhook(target)
+		s.recordCombatDeath(target)
 	}
 	return nil
 }
@@ -743,6 +807,7 @@ func (s *RPCServer) handleCharacterDeath(character *game.Character) {
 			"position": dropPosition,
 		},
 	})
+	s.companionDied(character)
 
 	logrus.WithFields(logrus.Fields{
 		"function": "handleCharacterDeath",
@@ -816,7 +881,7 @@ func (s *RPCServer) processCombatAction(player *game.Player, targetID, weaponID
 
 	target, exists := s.state.WorldState.Objects[targetID]
 	if !exists {
-		err := fmt.Errorf("invalid target")
+		err := ErrInvalidTarget
 		logrus.WithFields(logrus.Fields{
 			"function": "processCombatAction",
 			"error":    err.Error(),
@@ -842,11 +907,37 @@ func (s *RPCServer) processCombatAction(player *game.Player, targetID, weaponID
 	}
 
 	damage := calculateWeaponDamage(weapon, player)
+	if isRangedWeapon(weapon) {
+		if penalty := s.weatherModifiersFor(player).RangedAttackPenalty; penalty > 0 {
+			damage -= penalty
+			if damage < 1 {
+				damage = 1
+			}
+		}
+	}
+	missed := missesInDarkness(s.darknessPenalty(&player.Character, target))
+	if missed {
+		damage = 0
+	}
 	logrus.WithFields(logrus.Fields{
 		"function": "processCombatAction",
 		"damage":   damage,
+		"missed":   missed,
 	}).Info("calculated weapon damage")
 
+	var reactions []ReactionOutcome
+	if s.state.TurnManager.IsInCombat {
+		reactions = s.resolveReactions(ReactionEvent{
+			Trigger:  TriggerAttacked,
+			ActorID:  player.GetID(),
+			TargetID: targetID,
+		})
+	}
+	_, blocked := reactionAccepted(reactions, ReactionShieldBlock)
+	if blocked {
+		damage /= 2
+	}
+
 	if err := s.applyDamage(target, damage); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"function": "processCombatAction",
@@ -854,11 +945,40 @@ func (s *RPCServer) processCombatAction(player *game.Player, targetID, weaponID
 		}).Error("failed to apply damage")
 		return nil, err
 	}
+	s.recordAttack(player.GetID(), target)
+
+	entry := CombatLogEntry{
+		Action:     CombatLogAttack,
+		AttackerID: player.GetID(),
+		DefenderID: targetID,
+		Source:     combatSource(weapon),
+		Damage:     damage,
+		Blocked:    blocked,
+	}
+	if weapon != nil && weapon.Damage != "" {
+		entry.Roll = &CombatLogRoll{Expression: weapon.Damage, Result: parseDamageString(weapon.Damage)}
+	}
+	if missed {
+		entry.Effects = append(entry.Effects, "missed_in_darkness")
+	}
+	for _, reaction := range reactions {
+		if reaction.Accepted {
+			entry.Effects = append(entry.Effects, string(reaction.Type))
+		}
+	}
+	s.logCombat(entry)
 
 	result := map[string]interface{}{
 		"success": true,
 		"damage":  damage,
 	}
+	if missed {
+		result["missed"] = true
+	}
+	if len(reactions) > 0 {
+		result["reactions"] = reactions
+		result["blocked"] = blocked
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"function": "processCombatAction",
@@ -877,7 +997,7 @@ func (tm *TurnManager) QueueAction(action DelayedAction) error {
 
 	if !tm.IsCurrentTurn(action.ActorID) {
 		logger.Warn("attempt to queue action on wrong turn")
-		return fmt.Errorf("not actor's turn")
+		return ErrNotYourTurn.WithMessage("not actor's turn")
 	}
 
 	action.TriggerTime = game.GameTime{
@@ -955,14 +1075,14 @@ func (tm *TurnManager) EndCombat() {
 	}).Debug("ending combat via TurnManager")
 
 	// Stop the turn timer if it's running
-	if tm.turnTimer != nil {
-		tm.turnTimer.Stop()
-		tm.turnTimer = nil
-	}
+	tm.stopTurnTimer()
 
 	tm.IsInCombat = false
 	tm.Initiative = nil
+	tm.Breakdown = nil
 	tm.CurrentIndex = 0
+	tm.clearReactions()
+	tm.notifyState(CombatStateChange{Kind: CombatStateEnded})
 
 	logrus.WithFields(logrus.Fields{
 		"function": "EndCombat",
@@ -979,17 +1099,17 @@ func (tm *TurnManager) EndCombat() {
 //   - error: Error if validation fails, nil if valid
 func (tm *TurnManager) validateInitiativeOrder(initiative []string) error {
 	if len(initiative) == 0 {
-		return fmt.Errorf("initiative order cannot be empty when starting combat")
+		return NewJSONRPCError(JSONRPCInvalidParams, "initiative order cannot be empty when starting combat", nil)
 	}
 
 	// Check for duplicate entity IDs
 	seen := make(map[string]bool)
 	for _, entityID := range initiative {
 		if entityID == "" {
-			return fmt.Errorf("initiative order contains empty entity ID")
+			return NewJSONRPCError(JSONRPCInvalidParams, "initiative order contains empty entity ID", nil)
 		}
 		if seen[entityID] {
-			return fmt.Errorf("initiative order contains duplicate entity ID: %s", entityID)
+			return NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("initiative order contains duplicate entity ID: %s", entityID), nil)
 		}
 		seen[entityID] = true
 	}
@@ -0,0 +1,301 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of combat state update pushed to WebSocket clients as
+// combat_state messages
+const (
+	CombatStateInitiative   = "initiative"    // The initiative order changed
+	CombatStateTurn         = "turn"          // Another combatant's turn began
+	CombatStateRound        = "round"         // A new round began
+	CombatStateActionPoints = "action_points" // A combatant's action points changed
+	CombatStateEffects      = "effects"       // A combatant's effects or their durations changed
+	CombatStateEnded        = "ended"         // Combat ended
+)
+
+// CombatStateSchema is the JSON Schema of the combat_state WebSocket
+// messages and the getCombatState result
+//
+//go:embed schemas/combat_state.schema.json
+var CombatStateSchema []byte
+
+// CombatStateChange is an incremental change of the combat state. Every
+// change carries the round, the active combatant and a revision that
+// increases with each change, so clients can drop updates older than the
+// getCombatState snapshot they resynchronized from.
+//
+// Fields:
+//   - Kind: One of the CombatState kinds
+//   - Revision: Position of the change in the sequence of changes
+//   - Round, ActiveID: The round and whose turn it is after the change
+//   - Initiative: The new initiative order of initiative changes
+//   - EntityID: The combatant whose action points or effects changed
+//   - ActionPoints, MaxActionPoints: Action points of action_points changes
+//   - Effects: All active effects of effects changes
+type CombatStateChange struct {
+	Kind            string
+	Revision        uint64
+	Round           int
+	ActiveID        string
+	Initiative      []string
+	EntityID        string
+	ActionPoints    int
+	MaxActionPoints int
+	Effects         []CombatEffectState
+}
+
+// CombatEffectState is an active effect with its remaining duration.
+// Round based effects report the rounds left, timed effects the seconds
+// left; permanent effects report neither.
+type CombatEffectState struct {
+	ID               string          `json:"id"`
+	Type             game.EffectType `json:"type"`
+	Name             string          `json:"name"`
+	Stacks           int             `json:"stacks"`
+	RemainingRounds  int             `json:"remaining_rounds"`
+	RemainingSeconds float64         `json:"remaining_seconds"`
+	Permanent        bool            `json:"permanent"`
+}
+
+// SetStateListener sets the receiver of combat state changes. It is
+// called synchronously by whoever changes the state.
+func (tm *TurnManager) SetStateListener(listener func(CombatStateChange)) {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	tm.stateListener = listener
+}
+
+// StateRevision returns the revision of the last combat state change.
+func (tm *TurnManager) StateRevision() uint64 {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	return tm.stateRevision
+}
+
+// activeID returns whose turn it is, or "" outside combat.
+func (tm *TurnManager) activeID() string {
+	if !tm.IsInCombat || tm.CurrentIndex >= len(tm.Initiative) {
+		return ""
+	}
+	return tm.Initiative[tm.CurrentIndex]
+}
+
+// notifyState stamps change with the next revision and the current round
+// and turn, and hands it to the state listener.
+func (tm *TurnManager) notifyState(change CombatStateChange) {
+	tm.timerMu.Lock()
+	tm.stateRevision++
+	change.Revision = tm.stateRevision
+	listener := tm.stateListener
+	tm.timerMu.Unlock()
+
+	change.Round = tm.CurrentRound
+	change.ActiveID = tm.activeID()
+	if change.Kind == CombatStateInitiative {
+		change.Initiative = append([]string(nil), tm.Initiative...)
+	}
+	if listener != nil {
+		listener(change)
+	}
+}
+
+// pushCombatState sends a combat state change to every WebSocket client
+// as a combat_state message.
+func (s *RPCServer) pushCombatState(change CombatStateChange) {
+	if s.broadcaster == nil {
+		return
+	}
+	message := combatStateMessage(change)
+	s.publishSessionEvent("", message)
+	s.broadcaster.deliver(message)
+}
+
+// combatStateMessage builds the combat_state message of a change.
+func combatStateMessage(change CombatStateChange) map[string]interface{} {
+	message := map[string]interface{}{
+		"type":      "combat_state",
+		"kind":      change.Kind,
+		"revision":  change.Revision,
+		"round":     change.Round,
+		"active_id": change.ActiveID,
+		"timestamp": time.Now().Unix(),
+	}
+	switch change.Kind {
+	case CombatStateInitiative:
+		message["initiative"] = change.Initiative
+	case CombatStateActionPoints:
+		message["entity_id"] = change.EntityID
+		message["action_points"] = change.ActionPoints
+		message["max_action_points"] = change.MaxActionPoints
+	case CombatStateEffects:
+		message["entity_id"] = change.EntityID
+		message["effects"] = change.Effects
+	}
+	return message
+}
+
+// actionPointHolder is implemented by combatants with action points
+type actionPointHolder interface {
+	GetActionPoints() int
+	GetMaxActionPoints() int
+}
+
+// pushActionPoints reports a combatant's action points after they were
+// spent or restored in combat.
+func (s *RPCServer) pushActionPoints(obj game.GameObject) {
+	holder, ok := obj.(actionPointHolder)
+	if !ok || !s.state.TurnManager.IsInCombat {
+		return
+	}
+	s.state.TurnManager.notifyState(CombatStateChange{
+		Kind:            CombatStateActionPoints,
+		EntityID:        obj.GetID(),
+		ActionPoints:    holder.GetActionPoints(),
+		MaxActionPoints: holder.GetMaxActionPoints(),
+	})
+}
+
+// pushEffects reports the effects of a combatant after they were applied,
+// ticked or aged by a new round.
+func (s *RPCServer) pushEffects(obj game.GameObject) {
+	if !s.state.TurnManager.IsInCombat {
+		return
+	}
+	if _, ok := obj.(game.EffectHolder); !ok {
+		return
+	}
+	s.state.TurnManager.notifyState(CombatStateChange{
+		Kind:     CombatStateEffects,
+		EntityID: obj.GetID(),
+		Effects:  s.combatEffects(obj),
+	})
+}
+
+// pushCombatantEffects reports the effects of every combatant that has
+// any, as their round based durations run down when a round begins.
+func (s *RPCServer) pushCombatantEffects() {
+	for _, id := range append([]string(nil), s.state.TurnManager.Initiative...) {
+		obj, exists := s.state.WorldState.Objects[id]
+		if exists && len(s.combatEffects(obj)) > 0 {
+			s.pushEffects(obj)
+		}
+	}
+}
+
+// combatEffects returns the active effects of obj with their remaining
+// durations in the current round.
+func (s *RPCServer) combatEffects(obj game.GameObject) []CombatEffectState {
+	holder, ok := obj.(game.EffectHolder)
+	if !ok {
+		return []CombatEffectState{}
+	}
+
+	now := time.Now()
+	round := s.state.TurnManager.CurrentRound
+	effects := make([]CombatEffectState, 0)
+	for _, effect := range holder.GetEffects() {
+		if effect == nil || !effect.IsActive {
+			continue
+		}
+		state := CombatEffectState{
+			ID:     effect.ID,
+			Type:   effect.Type,
+			Name:   effect.Name,
+			Stacks: effect.Stacks,
+		}
+		duration := effect.Duration
+		switch {
+		case duration.RealTime > 0:
+			state.RemainingSeconds = max(0, effect.StartTime.Add(duration.RealTime).Sub(now).Seconds())
+		case duration.Rounds > 0:
+			state.RemainingRounds = max(0, effect.StartRound+duration.Rounds-round)
+		default:
+			state.Permanent = duration.RealTime < 0 || duration.Rounds < 0 || duration.Turns < 0
+		}
+		effects = append(effects, state)
+	}
+	return effects
+}
+
+// handleGetCombatState returns the complete combat state, for clients to
+// resynchronize from after missing combat_state messages. Updates with a
+// revision at or below the returned one are already part of the snapshot.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The requesting session
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - in_combat, round, revision, active_id, initiative_mode
+//   - turn_deadline: When the active turn times out, if a timer runs
+//   - combatants: The combatants in initiative order with their name,
+//     action points and effects
+//   - error: Invalid parameters or session
+func (s *RPCServer) handleGetCombatState(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleGetCombatState",
+	})
+	logger.Debug("entering handleGetCombatState")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid combat state parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	tm := s.state.TurnManager
+	revision := tm.StateRevision()
+	combatants := make([]map[string]interface{}, 0, len(tm.Initiative))
+	for _, id := range tm.Initiative {
+		combatant := map[string]interface{}{
+			"id":      id,
+			"effects": []CombatEffectState{},
+		}
+		if obj, exists := s.state.WorldState.Objects[id]; exists {
+			combatant["name"] = obj.GetName()
+			combatant["effects"] = s.combatEffects(obj)
+			if holder, ok := obj.(actionPointHolder); ok {
+				combatant["action_points"] = holder.GetActionPoints()
+				combatant["max_action_points"] = holder.GetMaxActionPoints()
+			}
+		}
+		combatants = append(combatants, combatant)
+	}
+
+	result := map[string]interface{}{
+		"success":         true,
+		"in_combat":       tm.IsInCombat,
+		"round":           tm.CurrentRound,
+		"revision":        revision,
+		"active_id":       tm.activeID(),
+		"initiative_mode": tm.InitiativeMode,
+		"combatants":      combatants,
+	}
+	if deadline := tm.TurnDeadline(); !deadline.IsZero() && tm.IsInCombat {
+		result["turn_deadline"] = deadline
+	}
+
+	logger.WithFields(logrus.Fields{
+		"in_combat":  tm.IsInCombat,
+		"revision":   revision,
+		"combatants": len(combatants),
+	}).Debug("combat state assembled")
+
+	return result, nil
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTurnManager_StateListener(t *testing.T) {
+	tm := NewTurnManager()
+	var changes []CombatStateChange
+	tm.SetStateListener(func(change CombatStateChange) { changes = append(changes, change) })
+
+	require.NoError(t, tm.StartCombat([]string{"fighter", "goblin", "orc"}))
+	tm.AdvanceTurn()
+	require.NoError(t, tm.RemoveFromInitiative("orc"))
+	tm.EndCombat()
+
+	require.Len(t, changes, 4)
+	assert.Equal(t, CombatStateInitiative, changes[0].Kind)
+	assert.Equal(t, []string{"fighter", "goblin", "orc"}, changes[0].Initiative)
+	assert.Equal(t, "fighter", changes[0].ActiveID)
+	assert.Equal(t, 1, changes[0].Round)
+
+	assert.Equal(t, CombatStateTurn, changes[1].Kind)
+	assert.Equal(t, "goblin", changes[1].ActiveID)
+	assert.Nil(t, changes[1].Initiative, "turn changes leave the order out")
+
+	assert.Equal(t, []string{"fighter", "goblin"}, changes[2].Initiative)
+	assert.Equal(t, CombatStateEnded, changes[3].Kind)
+	assert.Empty(t, changes[3].ActiveID)
+
+	for i, change := range changes {
+		assert.Equal(t, uint64(i+1), change.Revision)
+	}
+	assert.Equal(t, uint64(4), tm.StateRevision())
+}
+
+// combatStateMessages returns the combat_state messages delivered to
+// session.
+func combatStateMessages(session *PlayerSession) []map[string]interface{} {
+	events, _ := session.events.since(0)
+	var messages []map[string]interface{}
+	for _, event := range events {
+		if event["type"] == "combat_state" {
+			messages = append(messages, event)
+		}
+	}
+	return messages
+}
+
+// schemaRequired returns the required properties of a definition of
+// CombatStateSchema.
+func schemaRequired(t *testing.T, definition string) []string {
+	var schema struct {
+		Defs map[string]struct {
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(CombatStateSchema, &schema))
+	def, ok := schema.Defs[definition]
+	require.True(t, ok, "schema defines %s", definition)
+	return def.Required
+}
+
+func TestCombatState_PushAndResync(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.events = newEventBuffer(ResumeEventBufferSize)
+	playerID := session.Player.GetID()
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "participant_ids": []string{playerID}})
+	_, err := server.handleStartCombat(params)
+	require.NoError(t, err)
+
+	params, _ = json.Marshal(map[string]interface{}{
+		"session_id":  session.SessionID,
+		"effect_type": game.EffectPoison,
+		"target_id":   playerID,
+		"magnitude":   2,
+		"duration":    game.Duration{Rounds: 3},
+	})
+	_, err = server.handleApplyEffect(params)
+	require.NoError(t, err)
+
+	messages := combatStateMessages(session)
+	require.Len(t, messages, 3)
+	assert.Equal(t, CombatStateInitiative, messages[0]["kind"])
+	assert.Equal(t, []string{playerID}, messages[0]["initiative"])
+	assert.Equal(t, CombatStateActionPoints, messages[1]["kind"])
+	assert.Equal(t, session.Player.GetMaxActionPoints(), messages[1]["action_points"])
+	assert.Equal(t, CombatStateEffects, messages[2]["kind"])
+	effects := messages[2]["effects"].([]CombatEffectState)
+	require.Len(t, effects, 1)
+	assert.Equal(t, 3, effects[0].RemainingRounds)
+
+	required := schemaRequired(t, "update")
+	for _, message := range messages {
+		for _, field := range required {
+			assert.Contains(t, message, field, "%s message lacks %s", message["kind"], field)
+		}
+		assert.Contains(t, message, "seq", "combat state is numbered for resume")
+	}
+
+	params, _ = json.Marshal(map[string]interface{}{"session_id": session.SessionID})
+	result, err := server.handleGetCombatState(params)
+	require.NoError(t, err)
+	snapshot := result.(map[string]interface{})
+	for _, field := range schemaRequired(t, "snapshot") {
+		assert.Contains(t, snapshot, field)
+	}
+	assert.Equal(t, true, snapshot["in_combat"])
+	assert.Equal(t, playerID, snapshot["active_id"])
+	assert.Equal(t, messages[2]["revision"], snapshot["revision"], "the snapshot includes every pushed change")
+	combatants := snapshot["combatants"].([]map[string]interface{})
+	require.Len(t, combatants, 1)
+	assert.Equal(t, session.Player.GetName(), combatants[0]["name"])
+	assert.Len(t, combatants[0]["effects"], 1)
+
+	server.endCombat()
+	messages = combatStateMessages(session)
+	assert.Equal(t, CombatStateEnded, messages[len(messages)-1]["kind"])
+}
@@ -0,0 +1,469 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Actions recorded in a combat log.
+const (
+	CombatLogAttack              = "attack"                // Weapon or unarmed attack
+	CombatLogAttackOfOpportunity = "attack_of_opportunity" // Reaction attack
+	CombatLogSpell               = "spell"                 // Spell damage or healing
+	CombatLogEffect              = "effect"                // Effect applied to a target
+)
+
+// combatLogTimeFormat orders stored combat logs by start time within a
+// session's key prefix.
+const combatLogTimeFormat = "20060102T150405.000000000"
+
+// CombatLogEntry is one resolved combat action: who acted on whom, what was
+// rolled, and what it did to the defender.
+type CombatLogEntry struct {
+	Seq          int            `yaml:"seq" json:"seq"`
+	Round        int            `yaml:"round" json:"round"`
+	Timestamp    time.Time      `yaml:"timestamp" json:"timestamp"`
+	Action       string         `yaml:"action" json:"action"`
+	AttackerID   string         `yaml:"attacker_id,omitempty" json:"attacker_id,omitempty"`
+	AttackerName string         `yaml:"attacker_name,omitempty" json:"attacker_name,omitempty"`
+	DefenderID   string         `yaml:"defender_id" json:"defender_id"`
+	DefenderName string         `yaml:"defender_name,omitempty" json:"defender_name,omitempty"`
+	Source       string         `yaml:"source,omitempty" json:"source,omitempty"` // Weapon, spell or effect type used
+	Roll         *CombatLogRoll `yaml:"roll,omitempty" json:"roll,omitempty"`
+	Damage       int            `yaml:"damage,omitempty" json:"damage,omitempty"`
+	Healing      int            `yaml:"healing,omitempty" json:"healing,omitempty"`
+	Blocked      bool           `yaml:"blocked,omitempty" json:"blocked,omitempty"`
+	Effects      []string       `yaml:"effects,omitempty" json:"effects,omitempty"` // Effects applied and reactions triggered
+	DefenderHP   int            `yaml:"defender_hp" json:"defender_hp"`
+	Killed       bool           `yaml:"killed,omitempty" json:"killed,omitempty"`
+}
+
+// CombatLogRoll is the dice expression behind an entry and its result.
+type CombatLogRoll struct {
+	Expression string `yaml:"expression" json:"expression"`
+	Result     int    `yaml:"result" json:"result"`
+}
+
+// CombatEncounter is the combat log of one combat. Its ID is the replay ID
+// returned by startCombat.
+type CombatEncounter struct {
+	ID           string           `yaml:"encounter_id" json:"encounter_id"`
+	StartedAt    time.Time        `yaml:"started_at" json:"started_at"`
+	EndedAt      time.Time        `yaml:"ended_at,omitempty" json:"ended_at,omitempty"`
+	Rounds       int              `yaml:"rounds" json:"rounds"`
+	Participants []string         `yaml:"participants" json:"participants"`
+	SessionIDs   []string         `yaml:"session_ids" json:"-"`
+	Entries      []CombatLogEntry `yaml:"entries" json:"-"`
+}
+
+// hasSession reports whether sessionID took part in the encounter.
+func (e *CombatEncounter) hasSession(sessionID string) bool {
+	for _, id := range e.SessionIDs {
+		if id == sessionID {
+			return true
+		}
+	}
+	return false
+}
+
+// combatLog records the encounter in progress and keeps recent encounters.
+// The zero value is ready to use.
+type combatLog struct {
+	mu     sync.Mutex
+	active *CombatEncounter
+	recent map[string]*CombatEncounter
+	order  []string
+}
+
+// begin starts logging encounter.
+func (l *combatLog) begin(encounter *CombatEncounter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active = encounter
+}
+
+// discard drops the active encounter, used when combat fails to start.
+func (l *combatLog) discard(encounter *CombatEncounter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active == encounter {
+		l.active = nil
+	}
+}
+
+// inProgress reports whether an encounter is being logged.
+func (l *combatLog) inProgress() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active != nil
+}
+
+// current returns the ID and participants of the encounter in progress.
+func (l *combatLog) current() (string, []string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active == nil {
+		return "", nil, false
+	}
+	return l.active.ID, append([]string(nil), l.active.Participants...), true
+}
+
+// record appends entry to the active encounter. Outside combat it does
+// nothing.
+func (l *combatLog) record(entry CombatLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active == nil {
+		return
+	}
+	entry.Seq = len(l.active.Entries) + 1
+	l.active.Entries = append(l.active.Entries, entry)
+}
+
+// finish closes the active encounter and moves it to the recent cache.
+func (l *combatLog) finish(endedAt time.Time, rounds int) *CombatEncounter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	encounter := l.active
+	if encounter == nil {
+		return nil
+	}
+	l.active = nil
+	encounter.EndedAt = endedAt
+	encounter.Rounds = rounds
+
+	if l.recent == nil {
+		l.recent = make(map[string]*CombatEncounter)
+	}
+	l.recent[encounter.ID] = encounter
+	l.order = append(l.order, encounter.ID)
+	if len(l.order) > CombatReplayCacheSize {
+		delete(l.recent, l.order[0])
+		l.order = l.order[1:]
+	}
+	return encounter
+}
+
+// get returns a copy of the active or a recent encounter with id.
+func (l *combatLog) get(id string) (*CombatEncounter, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	encounter, ok := l.recent[id]
+	if !ok && l.active != nil && l.active.ID == id {
+		encounter, ok = l.active, true
+	}
+	if !ok {
+		return nil, false
+	}
+	copied := *encounter
+	copied.Entries = append([]CombatLogEntry(nil), encounter.Entries...)
+	return &copied, true
+}
+
+// forSession returns the active and recent encounters sessionID took part
+// in.
+func (l *combatLog) forSession(sessionID string) []combatLogRef {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var refs []combatLogRef
+	for _, encounter := range l.recent {
+		if encounter.hasSession(sessionID) {
+			refs = append(refs, combatLogRef{ID: encounter.ID, StartedAt: encounter.StartedAt})
+		}
+	}
+	if l.active != nil && l.active.hasSession(sessionID) {
+		refs = append(refs, combatLogRef{ID: l.active.ID, StartedAt: l.active.StartedAt})
+	}
+	return refs
+}
+
+// combatLogRef identifies one encounter in a session's combat history.
+type combatLogRef struct {
+	ID        string    `json:"encounter_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// beginCombatLog starts logging the combat recorded by replay. Sessions
+// controlling a participant, and the session that started the combat, can
+// read the log with getCombatLog.
+func (s *RPCServer) beginCombatLog(replay *CombatReplay, sessionID string) *CombatEncounter {
+	participants := make(map[string]bool, len(replay.Participants))
+	for _, id := range replay.Participants {
+		participants[id] = true
+	}
+
+	var sessionIDs []string
+	s.mu.RLock()
+	for id, session := range s.sessions {
+		if id != sessionID && session.Player != nil && participants[session.Player.GetID()] {
+			sessionIDs = append(sessionIDs, id)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Strings(sessionIDs)
+	if sessionID != "" {
+		sessionIDs = append([]string{sessionID}, sessionIDs...)
+	}
+
+	encounter := &CombatEncounter{
+		ID:           replay.ID,
+		StartedAt:    replay.StartedAt,
+		Participants: append([]string(nil), replay.Participants...),
+		SessionIDs:   sessionIDs,
+	}
+	s.combatLogs.begin(encounter)
+	return encounter
+}
+
+// logCombat records a resolved combat action in the active encounter,
+// filling in the round, names and the defender's remaining hit points.
+func (s *RPCServer) logCombat(entry CombatLogEntry) {
+	if !s.combatLogs.inProgress() {
+		return
+	}
+	entry.Timestamp = time.Now()
+	if s.state.TurnManager != nil {
+		entry.Round = s.state.TurnManager.CurrentRound
+	}
+	if s.state.WorldState != nil {
+		if attacker, exists := s.state.WorldState.Objects[entry.AttackerID]; exists {
+			entry.AttackerName = attacker.GetName()
+		}
+		if defender, exists := s.state.WorldState.Objects[entry.DefenderID]; exists {
+			entry.DefenderName = defender.GetName()
+			if hp, ok := objectHP(defender); ok {
+				entry.DefenderHP = hp
+				entry.Killed = entry.Damage > 0 && hp <= 0
+			}
+		}
+	}
+	s.combatLogs.record(entry)
+	if entry.Killed {
+		s.chronicleKill(entry)
+		s.campaignStatsKill(entry)
+	}
+}
+
+// finishCombatLog closes the active encounter and saves it for each
+// session that took part, if persistence is enabled.
+func (s *RPCServer) finishCombatLog() {
+	rounds := 0
+	if s.state.TurnManager != nil {
+		rounds = s.state.TurnManager.CurrentRound
+	}
+	encounter := s.combatLogs.finish(time.Now(), rounds)
+	if encounter == nil || s.store == nil {
+		return
+	}
+	for _, sessionID := range encounter.SessionIDs {
+		if err := s.store.Save(combatLogKey(sessionID, encounter), encounter); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":    "finishCombatLog",
+				"encounterID": encounter.ID,
+				"sessionID":   sessionID,
+				"error":       err.Error(),
+			}).Error("failed to save combat log")
+		}
+	}
+}
+
+// combatLogKey returns the store key of encounter's log for sessionID.
+func combatLogKey(sessionID string, encounter *CombatEncounter) string {
+	return fmt.Sprintf("%s%s/%s_%s.yaml", combatLogPrefix, sessionID,
+		encounter.StartedAt.UTC().Format(combatLogTimeFormat), encounter.ID)
+}
+
+// parseCombatLogKey extracts the encounter reference from a stored key.
+func parseCombatLogKey(key string) (combatLogRef, bool) {
+	name := strings.TrimSuffix(path.Base(key), ".yaml")
+	stamp, id, found := strings.Cut(name, "_")
+	if !found {
+		return combatLogRef{}, false
+	}
+	startedAt, err := time.Parse(combatLogTimeFormat, stamp)
+	if err != nil {
+		return combatLogRef{}, false
+	}
+	return combatLogRef{ID: id, StartedAt: startedAt}, true
+}
+
+// sessionCombatHistory lists the encounters sessionID took part in, newest
+// first, from memory and the store.
+func (s *RPCServer) sessionCombatHistory(sessionID string) []combatLogRef {
+	seen := make(map[string]bool)
+	var refs []combatLogRef
+	for _, ref := range s.combatLogs.forSession(sessionID) {
+		seen[ref.ID] = true
+		refs = append(refs, ref)
+	}
+
+	if s.store != nil {
+		keys, err := s.store.List(combatLogPrefix + sessionID + "/*.yaml")
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":  "sessionCombatHistory",
+				"sessionID": sessionID,
+				"error":     err.Error(),
+			}).Warn("failed to list stored combat logs")
+		}
+		for _, key := range keys {
+			if ref, ok := parseCombatLogKey(key); ok && !seen[ref.ID] {
+				seen[ref.ID] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].StartedAt.After(refs[j].StartedAt)
+	})
+	return refs
+}
+
+// loadCombatLog returns the log of an encounter sessionID took part in,
+// from memory or the store.
+func (s *RPCServer) loadCombatLog(sessionID string, ref combatLogRef) (*CombatEncounter, error) {
+	if encounter, ok := s.combatLogs.get(ref.ID); ok {
+		if !encounter.hasSession(sessionID) {
+			return nil, combatLogNotFound(ref.ID)
+		}
+		return encounter, nil
+	}
+	if s.store == nil {
+		return nil, combatLogNotFound(ref.ID)
+	}
+	var encounter CombatEncounter
+	key := combatLogKey(sessionID, &CombatEncounter{ID: ref.ID, StartedAt: ref.StartedAt})
+	if err := s.store.Load(key, &encounter); err != nil {
+		return nil, combatLogNotFound(ref.ID).WithData(map[string]interface{}{"cause": err.Error()})
+	}
+	return &encounter, nil
+}
+
+// combatLogNotFound reports an encounter with no log the session can read
+func combatLogNotFound(encounterID string) *JSONRPCError {
+	return ErrCombatLogNotFound.WithMessage("combat log %s not found", encounterID).
+		WithData(map[string]interface{}{"encounter_id": encounterID})
+}
+
+// handleGetCombatLog returns a page of the structured combat log of one
+// encounter the session took part in, for review during or after a game.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - encounter_id: string - The replay_id returned by startCombat
+//     (optional, defaults to the session's latest encounter)
+//   - offset: int - Entries to skip (optional)
+//   - limit: int - Maximum entries to return (optional, capped at
+//     CombatLogPageSize)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating the log was found
+//   - encounter_id, started_at, ended_at, rounds, participants: The
+//     encounter; ended_at is omitted while combat is in progress
+//   - entries: The requested page of entries, oldest first
+//   - total, offset, limit: Pagination of the entries
+//   - encounters: The session's encounters, newest first
+//   - error: Invalid parameters or session, or an unknown encounter
+func (s *RPCServer) handleGetCombatLog(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleGetCombatLog",
+	})
+	logger.Debug("entering handleGetCombatLog")
+
+	var req struct {
+		SessionID   string `json:"session_id"`
+		EncounterID string `json:"encounter_id,omitempty"`
+		Offset      int    `json:"offset,omitempty"`
+		Limit       int    `json:"limit,omitempty"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid combat log parameters", err.Error())
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid combat log parameters", "offset and limit must not be negative")
+	}
+	if req.Limit == 0 || req.Limit > CombatLogPageSize {
+		req.Limit = CombatLogPageSize
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	history := s.sessionCombatHistory(req.SessionID)
+	var ref *combatLogRef
+	for i := range history {
+		if req.EncounterID == "" || history[i].ID == req.EncounterID {
+			ref = &history[i]
+			break
+		}
+	}
+	if ref == nil {
+		if req.EncounterID == "" {
+			return nil, ErrCombatLogNotFound.WithMessage("session has no recorded encounters")
+		}
+		return nil, combatLogNotFound(req.EncounterID)
+	}
+
+	encounter, err := s.loadCombatLog(req.SessionID, *ref)
+	if err != nil {
+		return nil, err
+	}
+
+	start := min(req.Offset, len(encounter.Entries))
+	end := min(start+req.Limit, len(encounter.Entries))
+	entries := encounter.Entries[start:end]
+	if entries == nil {
+		entries = []CombatLogEntry{}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"encounterID": encounter.ID,
+		"total":       len(encounter.Entries),
+		"returned":    len(entries),
+	}).Debug("combat log retrieved")
+
+	result := map[string]interface{}{
+		"success":      true,
+		"encounter_id": encounter.ID,
+		"started_at":   encounter.StartedAt,
+		"rounds":       encounter.Rounds,
+		"participants": encounter.Participants,
+		"entries":      entries,
+		"total":        len(encounter.Entries),
+		"offset":       req.Offset,
+		"limit":        req.Limit,
+		"encounters":   history,
+	}
+	if !encounter.EndedAt.IsZero() {
+		result["ended_at"] = encounter.EndedAt
+	}
+	return result, nil
+}
+
+// combatSource names the weapon used by an attack.
+func combatSource(weapon *game.Item) string {
+	if weapon == nil {
+		return "unarmed"
+	}
+	return weapon.Name
+}
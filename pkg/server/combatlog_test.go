@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getCombatLog calls getCombatLog through handleMethod.
+func getCombatLog(t *testing.T, server *RPCServer, params map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	result, err := server.handleMethod(MethodGetCombatLog, data)
+	require.NoError(t, err)
+	return result.(map[string]interface{})
+}
+
+func TestCombatLog_RecordsEncounter(t *testing.T) {
+	server := newReplayTestServer(t)
+	encounterID := fightRecordedCombat(t, server, 1234)
+
+	result := getCombatLog(t, server, map[string]interface{}{"session_id": replaySessions["bob"]})
+	assert.Equal(t, encounterID, result["encounter_id"])
+	assert.Equal(t, 2, result["total"])
+	assert.Contains(t, result, "ended_at")
+
+	entries := result["entries"].([]CombatLogEntry)
+	require.Len(t, entries, 2)
+	for i, entry := range entries {
+		assert.Equal(t, i+1, entry.Seq)
+		assert.Equal(t, CombatLogSpell, entry.Action)
+		assert.Equal(t, "Replay Bolt", entry.Source)
+		require.NotNil(t, entry.Roll)
+		assert.Equal(t, "2d6", entry.Roll.Expression)
+		assert.Equal(t, entry.Roll.Result, entry.Damage)
+		assert.Equal(t, 100-entry.Damage, entry.DefenderHP)
+		assert.NotEqual(t, entry.AttackerID, entry.DefenderID)
+	}
+}
+
+func TestCombatLog_Pagination(t *testing.T) {
+	server := newReplayTestServer(t)
+	first := fightRecordedCombat(t, server, 1)
+	second := fightRecordedCombat(t, server, 2)
+
+	result := getCombatLog(t, server, map[string]interface{}{
+		"session_id":   replaySessions["alice"],
+		"encounter_id": first,
+		"offset":       1,
+		"limit":        5,
+	})
+	assert.Equal(t, first, result["encounter_id"])
+	assert.Equal(t, 2, result["total"])
+	entries := result["entries"].([]CombatLogEntry)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 2, entries[0].Seq)
+
+	history := result["encounters"].([]combatLogRef)
+	require.Len(t, history, 2)
+	assert.Equal(t, second, history[0].ID, "newest encounter first")
+
+	result = getCombatLog(t, server, map[string]interface{}{
+		"session_id": replaySessions["alice"],
+		"offset":     10,
+	})
+	assert.Equal(t, second, result["encounter_id"])
+	assert.Empty(t, result["entries"])
+	assert.Equal(t, CombatLogPageSize, result["limit"])
+}
+
+func TestCombatLog_LoadsSavedLogs(t *testing.T) {
+	server := newReplayTestServer(t)
+	encounterID := fightRecordedCombat(t, server, 42)
+
+	// Drop the in-memory copy so the log must come from the store
+	server.combatLogs.mu.Lock()
+	delete(server.combatLogs.recent, encounterID)
+	server.combatLogs.mu.Unlock()
+
+	for _, player := range []string{"alice", "bob"} {
+		result := getCombatLog(t, server, map[string]interface{}{
+			"session_id":   replaySessions[player],
+			"encounter_id": encounterID,
+		})
+		assert.Equal(t, 2, result["total"])
+		assert.Len(t, result["entries"], 2)
+	}
+
+	_, err := server.handleGetCombatLog(json.RawMessage(`{"session_id":"11111111-1111-4111-8111-111111111111","encounter_id":"00000000-0000-0000-0000-000000000000"}`))
+	assert.ErrorIs(t, err, ErrCombatLogNotFound)
+}
+
+func TestCombatLog_NoEncounters(t *testing.T) {
+	server := newReplayTestServer(t)
+
+	_, err := server.handleGetCombatLog(json.RawMessage(`{"session_id":"11111111-1111-4111-8111-111111111111"}`))
+	assert.ErrorIs(t, err, ErrCombatLogNotFound)
+
+	// Actions outside combat are not logged
+	server.logCombat(CombatLogEntry{Action: CombatLogAttack, AttackerID: "alice", DefenderID: "bob", Damage: 3})
+	assert.Empty(t, server.sessionCombatHistory(replaySessions["alice"]))
+}
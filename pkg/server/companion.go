@@ -0,0 +1,613 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Companion limits applied when the server has no configuration
+const (
+	DefaultMaxCompanions = 2
+	DefaultMaxPartySize  = 8
+)
+
+// Companion changes reported by EventCompanionChanged
+const (
+	CompanionHired     = "hired"
+	CompanionDismissed = "dismissed"
+	CompanionDeserted  = "deserted"
+	CompanionDied      = "died"
+)
+
+// CompanionManager tracks the companions players have hired. A companion
+// serves one employer at a time.
+//
+// Thread Safety: All methods are safe for concurrent use.
+type CompanionManager struct {
+	mu         sync.RWMutex
+	companions map[string]*game.Companion
+	byEmployer map[string][]string
+	hires      map[string]int
+}
+
+// NewCompanionManager creates an empty companion manager
+func NewCompanionManager() *CompanionManager {
+	return &CompanionManager{
+		companions: make(map[string]*game.Companion),
+		byEmployer: make(map[string][]string),
+		hires:      make(map[string]int),
+	}
+}
+
+// Add records a hired companion under its employer
+func (cm *CompanionManager) Add(companion *game.Companion) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	id, employerID := companion.GetID(), companion.GetEmployerID()
+	cm.companions[id] = companion
+	cm.byEmployer[employerID] = append(cm.byEmployer[employerID], id)
+	cm.hires[employerID]++
+}
+
+// Remove releases a companion from its employer's service
+func (cm *CompanionManager) Remove(companionID string) (*game.Companion, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	companion, exists := cm.companions[companionID]
+	if !exists {
+		return nil, false
+	}
+	delete(cm.companions, companionID)
+
+	employerID := companion.GetEmployerID()
+	ids := cm.byEmployer[employerID]
+	for i, id := range ids {
+		if id == companionID {
+			cm.byEmployer[employerID] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(cm.byEmployer[employerID]) == 0 {
+		delete(cm.byEmployer, employerID)
+	}
+	return companion, true
+}
+
+// Get returns a hired companion by ID
+func (cm *CompanionManager) Get(companionID string) (*game.Companion, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	companion, exists := cm.companions[companionID]
+	return companion, exists
+}
+
+// Of returns the companions serving employerID, in hiring order
+func (cm *CompanionManager) Of(employerID string) []*game.Companion {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	ids := cm.byEmployer[employerID]
+	companions := make([]*game.Companion, 0, len(ids))
+	for _, id := range ids {
+		companions = append(companions, cm.companions[id])
+	}
+	return companions
+}
+
+// Hires returns how many companions employerID has hired so far
+func (cm *CompanionManager) Hires(employerID string) int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.hires[employerID]
+}
+
+// companionsOf returns the companions serving playerID, or none when the
+// server tracks no companions
+func (s *RPCServer) companionsOf(playerID string) []*game.Companion {
+	if s.companions == nil {
+		return nil
+	}
+	return s.companions.Of(playerID)
+}
+
+// hiredCompanion returns the hired companion with the given ID
+func (s *RPCServer) hiredCompanion(id string) (*game.Companion, bool) {
+	if s.companions == nil {
+		return nil, false
+	}
+	return s.companions.Get(id)
+}
+
+// companionLimits returns the configured companion and party size limits
+func (s *RPCServer) companionLimits() (maxCompanions, maxPartySize int) {
+	if s.config == nil {
+		return DefaultMaxCompanions, DefaultMaxPartySize
+	}
+	return s.config.MaxCompanions, s.config.MaxPartySize
+}
+
+// partySize counts the player's party, or the player alone, together with
+// every member's companions
+func (s *RPCServer) partySize(playerID string) int {
+	members := []string{playerID}
+	if party, exists := s.parties.PartyOf(playerID); exists {
+		members = party.GetMembers()
+	}
+
+	size := 0
+	for _, id := range members {
+		size += 1 + len(s.companionsOf(id))
+	}
+	return size
+}
+
+// isPartySide reports whether a combatant fights on the players' side: a
+// player or a hired companion
+func (s *RPCServer) isPartySide(obj game.GameObject) bool {
+	if _, isPlayer := obj.(*game.Player); isPlayer {
+		return true
+	}
+	_, hired := s.hiredCompanion(obj.GetID())
+	return hired
+}
+
+// emitCompanionChanged reports a change to a player's companions
+func (s *RPCServer) emitCompanionChanged(companion *game.Companion, change string) {
+	data := companion.Snapshot()
+	data["change"] = change
+	s.eventSys.Emit(game.GameEvent{
+		Type:      EventCompanionChanged,
+		SourceID:  companion.GetID(),
+		TargetID:  companion.GetEmployerID(),
+		Data:      data,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// releaseCompanion removes a companion from service, from the world and,
+// during combat, from the initiative order
+func (s *RPCServer) releaseCompanion(companion *game.Companion, change string) {
+	id := companion.GetID()
+	s.companions.Remove(id)
+	if change != CompanionDied {
+		if err := s.state.WorldState.RemoveObject(id); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":     "releaseCompanion",
+				"companion_id": id,
+			}).WithError(err).Warn("companion was not in the world")
+		}
+	}
+	if s.state.TurnManager.IsInCombat {
+		_ = s.state.TurnManager.RemoveFromInitiative(id)
+	}
+	s.emitCompanionChanged(companion, change)
+}
+
+// treatCompanions applies a treatment to every companion of employerID
+// except exceptID, and releases those whose loyalty no longer holds
+func (s *RPCServer) treatCompanions(employerID, exceptID string, treatment game.CompanionTreatment) {
+	for _, companion := range s.companionsOf(employerID) {
+		if companion.GetID() == exceptID {
+			continue
+		}
+		companion.Treat(treatment)
+		if companion.Deserts() {
+			logrus.WithFields(logrus.Fields{
+				"function":     "treatCompanions",
+				"companion_id": companion.GetID(),
+				"employer_id":  employerID,
+				"treatment":    treatment,
+			}).Info("companion deserted")
+			s.releaseCompanion(companion, CompanionDeserted)
+		}
+	}
+}
+
+// companionDied handles the death of a combatant: a dead companion leaves
+// service, and its employer's other companions lose heart, as do the
+// companions of a dead player
+func (s *RPCServer) companionDied(character *game.Character) {
+	id := character.GetID()
+	if companion, hired := s.hiredCompanion(id); hired {
+		employerID := companion.GetEmployerID()
+		s.releaseCompanion(companion, CompanionDied)
+		s.treatCompanions(employerID, id, game.TreatmentAllyDied)
+		return
+	}
+	s.treatCompanions(id, "", game.TreatmentAllyDied)
+}
+
+// companionWounded lowers a companion's morale when damage first brings it
+// below half its hit points
+func (s *RPCServer) companionWounded(character *game.Character, oldHP int) {
+	companion, hired := s.hiredCompanion(character.GetID())
+	if !hired || character.HP == 0 {
+		return
+	}
+	half := character.MaxHP / 2
+	if oldHP >= half && character.HP < half {
+		companion.Treat(game.TreatmentWounded)
+	}
+}
+
+// celebrateVictory raises the morale of the companions who fought in a
+// won combat
+func (s *RPCServer) celebrateVictory(participants []string) {
+	for _, id := range participants {
+		if companion, hired := s.hiredCompanion(id); hired && companion.NPC.HP > 0 {
+			companion.Treat(game.TreatmentVictory)
+		}
+	}
+}
+
+// shareReward pays the player's companions their shares of a reward and
+// returns what is left for the player
+func (s *RPCServer) shareReward(playerID string, amount int, experience bool) int {
+	companions := s.companionsOf(playerID)
+	if amount <= 0 || len(companions) == 0 {
+		return amount
+	}
+
+	shares := make([]float64, len(companions))
+	for i, companion := range companions {
+		shares[i] = companion.GetShare()
+	}
+	playerPart, parts := game.SplitReward(amount, shares)
+
+	for i, companion := range companions {
+		var err error
+		if experience {
+			err = companion.ReceiveShare(int64(parts[i]), 0)
+		} else {
+			err = companion.ReceiveShare(0, parts[i])
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":     "shareReward",
+				"companion_id": companion.GetID(),
+			}).WithError(err).Warn("failed to pay companion share")
+			playerPart += parts[i]
+		}
+	}
+	return playerPart
+}
+
+// companionParticipants returns the hired companions of the players among
+// participants that are not already taking part
+func (s *RPCServer) companionParticipants(participants []string) []string {
+	taking := make(map[string]bool, len(participants))
+	for _, id := range participants {
+		taking[id] = true
+	}
+
+	var companions []string
+	for _, id := range participants {
+		for _, companion := range s.companionsOf(id) {
+			companionID := companion.GetID()
+			if !taking[companionID] && companion.NPC.HP > 0 {
+				taking[companionID] = true
+				companions = append(companions, companionID)
+			}
+		}
+	}
+	return companions
+}
+
+// runCompanionTurns plays the turns of companions from the current turn
+// on, until a player or opponent is to act or combat ends. It returns the
+// ID of the entity whose turn it then is.
+func (s *RPCServer) runCompanionTurns(nextTurn string) string {
+	tm := s.state.TurnManager
+	for turns := 0; tm.IsInCombat && turns < len(tm.Initiative); turns++ {
+		companion, hired := s.hiredCompanion(nextTurn)
+		if !hired {
+			break
+		}
+		s.takeCompanionTurn(companion)
+		if !tm.IsInCombat {
+			return ""
+		}
+		if _, serving := s.hiredCompanion(nextTurn); !serving {
+			// The companion fell or deserted and left the initiative order,
+			// which now points at the entity after it
+			if len(tm.Initiative) == 0 {
+				s.endCombat()
+				return ""
+			}
+			nextTurn = tm.Initiative[tm.CurrentIndex]
+			continue
+		}
+		nextTurn = s.advanceTurn(companion.NPC)
+	}
+	return nextTurn
+}
+
+// takeCompanionTurn plays a companion's combat turn. A shaken companion,
+// or one hurt below the retreat health of its behavior modifiers, raises
+// its shield; otherwise it strikes the nearest opponent still standing, or
+// the weakest if its modifiers say so.
+func (s *RPCServer) takeCompanionTurn(companion *game.Companion) {
+	npc := companion.NPC
+	logger := logrus.WithFields(logrus.Fields{
+		"function":     "takeCompanionTurn",
+		"companion_id": npc.GetID(),
+	})
+	if npc.HP <= 0 {
+		return
+	}
+
+	if companion.IsShaken() || npc.Modifiers.Retreats(npc.HP, npc.MaxHP) {
+		if _, err := s.state.TurnManager.RegisterReaction(npc.GetID(), ReactionShieldBlock); err != nil {
+			logger.WithError(err).Debug("shaken companion could not raise its shield")
+		}
+		logger.Info("shaken companion holds back")
+		return
+	}
+
+	target := s.companionTarget(npc)
+	if target == nil {
+		logger.Debug("companion has no opponent to attack")
+		return
+	}
+
+	damage := reactionAttackDamage(npc)
+	reactions := s.resolveReactions(ReactionEvent{
+		Trigger:  TriggerAttacked,
+		ActorID:  npc.GetID(),
+		TargetID: target.GetID(),
+	})
+	_, blocked := reactionAccepted(reactions, ReactionShieldBlock)
+	if blocked {
+		damage /= 2
+	}
+	if err := s.applyDamage(target, damage); err != nil {
+		logger.WithError(err).Error("failed to apply companion attack damage")
+		return
+	}
+
+	entry := CombatLogEntry{
+		Action:     CombatLogAttack,
+		AttackerID: npc.GetID(),
+		DefenderID: target.GetID(),
+		Source:     "companion",
+		Damage:     damage,
+		Blocked:    blocked,
+	}
+	for _, reaction := range reactions {
+		if reaction.Accepted {
+			entry.Effects = append(entry.Effects, string(reaction.Type))
+		}
+	}
+	s.logCombat(entry)
+
+	logger.WithFields(logrus.Fields{
+		"target_id": target.GetID(),
+		"damage":    damage,
+	}).Info("companion attacked")
+}
+
+// companionTarget returns the nearest living combatant opposing the
+// players, surprised or not, or nil if none remain. Companions whose
+// behavior modifiers target the weakest pick the one with the fewest hit
+// points instead.
+func (s *RPCServer) companionTarget(npc *game.NPC) game.GameObject {
+	from := npc.GetPosition()
+	weakest := npc.Modifiers != nil && npc.Modifiers.TargetWeakest
+	var target game.GameObject
+	best := 0
+	for _, entry := range s.state.TurnManager.Breakdown {
+		obj, exists := s.state.WorldState.Objects[entry.EntityID]
+		if !exists || s.isPartySide(obj) {
+			continue
+		}
+		hp, ok := objectHP(obj)
+		if !ok || hp <= 0 {
+			continue
+		}
+		score := tileDistance(from, obj.GetPosition())
+		if weakest {
+			score = hp
+		}
+		if target == nil || score < best {
+			target, best = obj, score
+		}
+	}
+	return target
+}
+
+// tileDistance returns the number of moves between two positions when
+// diagonal steps are allowed
+func tileDistance(a, b game.Position) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+// handleHireCompanion hires a generated companion for the requesting
+// player. The companion stands at the player's side, joins the combats
+// the player starts and takes a share of the player's quest rewards.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the hiring player
+//   - seed: number - Optional seed of the companion to generate
+//
+// Returns:
+//   - interface{}: Map containing the hired companion
+//   - error: Invalid parameters or session, combat in progress, a full
+//     party, too many companions or too little gold for the wage
+func (s *RPCServer) handleHireCompanion(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleHireCompanion",
+	})
+	logger.Debug("entering handleHireCompanion")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		Seed      *int64 `json:"seed,omitempty"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid companion parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.state.TurnManager.IsInCombat {
+		return nil, ErrCombatInProgress.WithMessage("cannot hire companions during combat")
+	}
+
+	player := session.Player
+	maxCompanions, maxPartySize := s.companionLimits()
+	if hired := len(s.companionsOf(player.ID)); hired >= maxCompanions {
+		return nil, ErrCompanionRejected.WithMessage("player %s already has %d of %d companions", player.ID, hired, maxCompanions)
+	}
+	if size := s.partySize(player.ID); size >= maxPartySize {
+		return nil, ErrCompanionRejected.WithMessage("party of %d is full", size)
+	}
+
+	seed := s.pcgManager.GetSeedManager().DeriveContextSeed(pcg.ContentTypeCharacters,
+		fmt.Sprintf("companion:%s:%d", player.ID, s.companions.Hires(player.ID)))
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	companion, err := pcg.GenerateCompanion(context.Background(), seed, player.Level)
+	if err != nil {
+		logger.WithError(err).Error("failed to generate companion")
+		return nil, ErrGenerationFailed.WithMessage("failed to generate companion: %v", err)
+	}
+	if _, hired := s.hiredCompanion(companion.GetID()); hired {
+		return nil, ErrCompanionRejected.WithMessage("companion %s is already hired", companion.GetID())
+	}
+
+	if player.Gold < companion.Wage {
+		return nil, ErrCompanionRejected.WithMessage("wage of %d gold exceeds the %d gold carried", companion.Wage, player.Gold).
+			WithData(map[string]interface{}{"wage": companion.Wage, "gold": player.Gold})
+	}
+
+	npc := companion.NPC
+	npc.Position = player.GetPosition()
+	if err := s.state.WorldState.AddObject(npc); err != nil {
+		logger.WithError(err).Error("failed to place companion")
+		return nil, ErrCompanionRejected.WithMessage("failed to place companion: %v", err)
+	}
+	player.Gold -= companion.Wage
+	companion.Hire(player.ID)
+	s.companions.Add(companion)
+	s.emitCompanionChanged(companion, CompanionHired)
+
+	logger.WithFields(logrus.Fields{
+		"player_id":    player.ID,
+		"companion_id": companion.GetID(),
+		"wage":         companion.Wage,
+	}).Info("companion hired")
+
+	return map[string]interface{}{
+		"success":   true,
+		"companion": companion.Snapshot(),
+		"gold":      player.Gold,
+	}, nil
+}
+
+// handleDismissCompanion releases one of the requesting player's
+// companions from service. The player's other companions think less of
+// an employer who turns allies away.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the employer
+//   - companion_id: string - The companion to dismiss
+func (s *RPCServer) handleDismissCompanion(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleDismissCompanion",
+	})
+	logger.Debug("entering handleDismissCompanion")
+
+	var req struct {
+		SessionID   string `json:"session_id"`
+		CompanionID string `json:"companion_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid companion parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	companion, hired := s.hiredCompanion(req.CompanionID)
+	if !hired || companion.GetEmployerID() != session.Player.ID {
+		return nil, ErrCompanionNotFound.WithMessage("player %s has no companion %s", session.Player.ID, req.CompanionID).
+			WithData(map[string]interface{}{"companion_id": req.CompanionID})
+	}
+	if s.state.TurnManager.IsInCombat && s.state.TurnManager.IsCurrentTurn(req.CompanionID) {
+		return nil, ErrCompanionRejected.WithMessage("cannot dismiss a companion during its turn")
+	}
+
+	s.releaseCompanion(companion, CompanionDismissed)
+	s.treatCompanions(session.Player.ID, "", game.TreatmentAbandoned)
+
+	logger.WithFields(logrus.Fields{
+		"player_id":    session.Player.ID,
+		"companion_id": req.CompanionID,
+	}).Info("companion dismissed")
+
+	return map[string]interface{}{
+		"success":      true,
+		"companion_id": req.CompanionID,
+	}, nil
+}
+
+// handleGetCompanions returns the requesting player's companions with
+// their loyalty, morale and earnings
+func (s *RPCServer) handleGetCompanions(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid companion parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	companions := s.companionsOf(session.Player.ID)
+	snapshots := make([]map[string]interface{}, 0, len(companions))
+	for _, companion := range companions {
+		snapshots = append(snapshots, companion.Snapshot())
+	}
+	maxCompanions, maxPartySize := s.companionLimits()
+
+	return map[string]interface{}{
+		"success":        true,
+		"companions":     snapshots,
+		"max_companions": maxCompanions,
+		"party_size":     s.partySize(session.Player.ID),
+		"max_party_size": maxPartySize,
+	}, nil
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hireTestCompanion hires a companion for the session's player and returns
+// it
+func hireTestCompanion(t *testing.T, server *RPCServer, sessionID string, seed int) *game.Companion {
+	t.Helper()
+	params, _ := json.Marshal(map[string]interface{}{"session_id": sessionID, "seed": seed})
+	result, err := server.handleHireCompanion(params)
+	require.NoError(t, err)
+
+	id := result.(map[string]interface{})["companion"].(map[string]interface{})["companion_id"].(string)
+	companion, hired := server.companions.Get(id)
+	require.True(t, hired)
+	return companion
+}
+
+func TestHireAndDismissCompanion(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Level = 3
+	session.Player.Gold = 10
+
+	params := json.RawMessage(`{"session_id":"test-session-001","seed":11}`)
+	_, err := server.handleHireCompanion(params)
+	assert.ErrorIs(t, err, ErrCompanionRejected, "the wage must be affordable")
+
+	session.Player.Gold = 1000
+	first := hireTestCompanion(t, server, session.SessionID, 11)
+	assert.Equal(t, session.Player.ID, first.GetEmployerID())
+	assert.Equal(t, 1000-first.Wage, session.Player.Gold)
+	assert.Equal(t, session.Player.GetPosition(), first.NPC.GetPosition())
+	_, placed := server.state.WorldState.Objects[first.GetID()]
+	assert.True(t, placed, "the companion joins the world")
+
+	second := hireTestCompanion(t, server, session.SessionID, 12)
+	_, err = server.handleHireCompanion(json.RawMessage(`{"session_id":"test-session-001","seed":13}`))
+	assert.ErrorIs(t, err, ErrCompanionRejected, "the companion limit is two")
+
+	result, err := server.handleGetCompanions(json.RawMessage(`{"session_id":"test-session-001"}`))
+	require.NoError(t, err)
+	listing := result.(map[string]interface{})
+	assert.Len(t, listing["companions"], 2)
+	assert.Equal(t, 3, listing["party_size"])
+
+	loyalty := second.Loyalty
+	_, err = server.handleDismissCompanion(json.RawMessage(`{"session_id":"test-session-001","companion_id":"missing"}`))
+	assert.ErrorIs(t, err, ErrCompanionNotFound)
+
+	_, err = server.handleDismissCompanion(json.RawMessage(`{"session_id":"test-session-001","companion_id":"` + first.GetID() + `"}`))
+	require.NoError(t, err)
+	_, placed = server.state.WorldState.Objects[first.GetID()]
+	assert.False(t, placed, "a dismissed companion leaves the world")
+	assert.Less(t, second.Loyalty, loyalty, "dismissals unsettle the other companions")
+	assert.Len(t, server.companions.Of(session.Player.ID), 1)
+}
+
+func TestHireCompanion_PartySizeLimit(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.config.MaxPartySize = 2
+	alice := addPartyTestPlayer(server, "alice")
+	addPartyTestPlayer(server, "bob")
+	alice.Gold = 1000
+
+	result, err := server.handleCreateParty(json.RawMessage(`{"session_id":"alice-session"}`))
+	require.NoError(t, err)
+	partyID := result.(map[string]interface{})["party"].(map[string]interface{})["party_id"].(string)
+	_, err = server.handleJoinParty(json.RawMessage(`{"session_id":"bob-session","party_id":"` + partyID + `"}`))
+	require.NoError(t, err)
+
+	_, err = server.handleHireCompanion(json.RawMessage(`{"session_id":"alice-session","seed":5}`))
+	assert.ErrorIs(t, err, ErrCompanionRejected, "two players already fill the party")
+}
+
+func TestCompanionRewardShare(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Gold = 1000
+	companion := hireTestCompanion(t, server, session.SessionID, 21)
+	companion.Share = 1
+	gold := session.Player.Gold
+
+	server.applyGoldReward(session.Player, "quest", game.QuestReward{Type: "gold", Value: 100})
+	assert.Equal(t, gold+50, session.Player.Gold)
+	assert.Equal(t, 50, companion.GoldEarned)
+
+	require.NoError(t, server.applyExperienceReward(session.Player, "quest", game.QuestReward{Type: "exp", Value: 300}))
+	assert.Equal(t, int64(150), companion.Experience)
+}
+
+func TestCompanionCombatTurns(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	t.Cleanup(server.state.TurnManager.EndCombat)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Gold = 1000
+	companion := hireTestCompanion(t, server, session.SessionID, 31)
+	fellow := hireTestCompanion(t, server, session.SessionID, 32)
+
+	orc := &game.NPC{Character: game.Character{
+		ID: "orc", Name: "Orc", HP: 200, MaxHP: 200, Strength: 16,
+		Position: game.Position{X: 11, Y: 10},
+	}}
+	require.NoError(t, server.state.WorldState.AddObject(orc))
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"session_id":      session.SessionID,
+		"participant_ids": []string{session.Player.ID, orc.ID},
+	})
+	result, err := server.handleStartCombat(params)
+	require.NoError(t, err)
+	initiative := result.(map[string]interface{})["initiative"].([]string)
+	assert.Contains(t, initiative, companion.GetID(), "companions join their employer's combats")
+
+	// Play turns until the orc has taken companion blows; each player or
+	// orc turn simply passes
+	for i := 0; i < 8 && orc.HP == 200; i++ {
+		tm := server.state.TurnManager
+		if !tm.IsInCombat {
+			break
+		}
+		server.finishTurn(server.state.WorldState.Objects[tm.Initiative[tm.CurrentIndex]])
+	}
+	assert.Less(t, orc.HP, 200, "companions attack the nearest opponent on their turns")
+
+	// A fallen companion leaves service and dismays its fellows
+	morale := fellow.Morale
+	require.NoError(t, server.applyDamage(companion.NPC, companion.NPC.HP))
+	_, hired := server.companions.Get(companion.GetID())
+	assert.False(t, hired)
+	assert.Less(t, fellow.Morale, morale)
+}
+
+func TestCompanionTurn_BehaviorModifiers(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	t.Cleanup(server.state.TurnManager.EndCombat)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Gold = 1000
+	companion := hireTestCompanion(t, server, session.SessionID, 31)
+	companion.NPC.Modifiers = &game.BehaviorModifiers{Bravery: 0.1, RetreatHealth: 0.45, TargetWeakest: true}
+
+	near := &game.NPC{Character: game.Character{ID: "near-orc", Name: "Orc", HP: 200, MaxHP: 200, Position: game.Position{X: 11, Y: 10}}}
+	far := &game.NPC{Character: game.Character{ID: "far-goblin", Name: "Goblin", HP: 30, MaxHP: 30, Position: game.Position{X: 15, Y: 15}}}
+	require.NoError(t, server.state.WorldState.AddObject(near))
+	require.NoError(t, server.state.WorldState.AddObject(far))
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"session_id":      session.SessionID,
+		"participant_ids": []string{session.Player.ID, near.ID, far.ID},
+	})
+	_, err := server.handleStartCombat(params)
+	require.NoError(t, err)
+
+	assert.Equal(t, far, server.companionTarget(companion.NPC), "a coward picks on the weakest")
+
+	server.takeCompanionTurn(companion)
+	assert.Less(t, far.HP, 30)
+	assert.Equal(t, 200, near.HP)
+
+	companion.NPC.HP = companion.NPC.MaxHP / 3
+	hp := far.HP
+	server.takeCompanionTurn(companion)
+	assert.Equal(t, hp, far.HP, "badly hurt, the coward holds back")
+}
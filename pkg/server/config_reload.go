@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// subscribeConfigReloads applies reloaded settings to the running server:
+// log levels, rate limits and the retry policy. The session timeout needs
+// no subscriber, since sessions read it from the configuration each time.
+func (s *RPCServer) subscribeConfigReloads() {
+	if s.config == nil {
+		return
+	}
+	s.config.Subscribe(s.applyConfigChange)
+}
+
+// applyConfigChange is the config.ReloadSubscriber of the server.
+func (s *RPCServer) applyConfigChange(cfg *config.Config, change config.ConfigChange) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "applyConfigChange",
+		"applied":  change.Applied,
+	})
+
+	if change.Has("log_level") || change.Has("log_levels") {
+		if err := logging.Configure(cfg.LogLevel, cfg.LogLevels); err != nil {
+			logger.WithError(err).Warn("failed to apply reloaded log levels")
+		}
+	}
+
+	if s.rateLimiter != nil && (change.Has("rate_limit_requests_per_second") || change.Has("rate_limit_burst")) {
+		s.rateLimiter.SetLimits(cfg)
+	}
+
+	if s.sessionLimiter != nil && (change.Has("session_rate_limit_requests_per_second") ||
+		change.Has("session_rate_limit_burst") || change.Has("session_rate_limit_method_weights")) {
+		s.sessionLimiter.SetLimits(cfg)
+	}
+
+	if s.webhooks != nil && changesRetryPolicy(change) {
+		s.webhooks.SetRetry(newWebhookConfig(cfg).Retry)
+	}
+
+	logger.Info("applied reloaded configuration")
+}
+
+// changesRetryPolicy reports whether change applied any retry setting.
+func changesRetryPolicy(change config.ConfigChange) bool {
+	for _, setting := range []string{
+		"retry_enabled", "retry_max_attempts", "retry_initial_delay",
+		"retry_max_delay", "retry_backoff_multiplier", "retry_jitter_percent",
+	} {
+		if change.Has(setting) {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchConfig reloads the server configuration whenever the process
+// receives SIGHUP, until ctx is cancelled.
+func (s *RPCServer) WatchConfig(ctx context.Context) {
+	if s.config != nil {
+		s.config.Watch(ctx)
+	}
+}
+
+// handleAdminReloadConfig re-reads the environment and config file and
+// applies the reloadable settings, as SIGHUP does. An invalid configuration
+// is rejected and the running one kept.
+//
+// Parameters:
+//   - params: JSON containing admin_token
+//
+// Returns:
+//   - interface{}: Map with the applied settings and those pending a restart
+//   - error: Invalid configuration
+func (s *RPCServer) handleAdminReloadConfig(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleAdminReloadConfig",
+	})
+	logger.Debug("entering handleAdminReloadConfig")
+
+	if s.config == nil {
+		return nil, ErrUnavailable.WithMessage("Configuration not loaded")
+	}
+
+	change, err := s.config.Reload()
+	if err != nil {
+		logger.WithError(err).Warn("configuration reload rejected")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to reload configuration", err.Error())
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"applied": change.Applied,
+		"pending": change.Pending,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleAdminReloadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goldbox.env")
+	require.NoError(t, os.WriteFile(path, []byte("SESSION_RATE_LIMIT_BURST=20\n"), 0o600))
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	server := createTestServerForHandlers(t)
+	server.config = cfg
+	server.sessionLimiter = NewSessionRateLimiter(cfg)
+	t.Cleanup(server.sessionLimiter.Close)
+	server.subscribeConfigReloads()
+	server.admin = newTestAdminConsole(10, AdminPermissionConfig)
+
+	allowed, _ := server.sessionLimiter.Allow("session-1", MethodGetGameState)
+	require.True(t, allowed)
+
+	require.NoError(t, os.WriteFile(path, []byte("SESSION_RATE_LIMIT_BURST=50\nSESSION_TIMEOUT=10m\n"), 0o600))
+	params, err := json.Marshal(map[string]interface{}{"admin_token": testAdminToken})
+	require.NoError(t, err)
+	result, err := server.handleMethod(MethodAdminReloadConfig, params)
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	assert.ElementsMatch(t, []string{"session_timeout", "session_rate_limit_burst"}, response["applied"])
+	assert.Equal(t, 10*time.Minute, server.resumeTimeout(), "sessions use the reloaded timeout")
+	assert.Equal(t, 50, server.sessionLimiter.BucketState("session-1").Burst, "existing buckets take the new burst")
+	assert.Equal(t, 50, server.sessionLimiter.BucketState("session-2").Burst)
+
+	require.NoError(t, os.WriteFile(path, []byte("SESSION_RATE_LIMIT_BURST=5\nLOG_LEVEL=loud\n"), 0o600))
+	_, err = server.handleMethod(MethodAdminReloadConfig, params)
+	assert.Error(t, err, "an invalid configuration is rejected")
+	assert.Equal(t, 50, server.sessionLimiter.BucketState("session-1").Burst)
+}
@@ -20,6 +20,61 @@ const (
 const (
 	sessionCleanupInterval = 5 * time.Minute
 	sessionTimeout         = 30 * time.Minute
+	weatherUpdateInterval  = time.Minute
+	tensionUpdateInterval  = 2 * time.Second
+
+	worldEventUpdateInterval = time.Minute
+)
+
+// Persistence store keys. The game state document holds the world and
+// sessions; PCG seed state, world events, the chronicle, the campaign
+// statistics and the map annotations are saved beside it in the same batch.
+const (
+	gameStateKey     = "gamestate.yaml"
+	pcgStateKey      = "pcg_state.yaml"
+	worldEventsKey   = "world_events.yaml"
+	chronicleKey     = "chronicle.yaml"
+	campaignStatsKey = "campaign_stats.yaml"
+	annotationsKey   = "annotations.yaml"
+)
+
+// combatReplayPrefix is the store key prefix under which finished combat
+// replays are saved, one document per replay ID.
+const combatReplayPrefix = "replays/"
+
+// CombatReplayCacheSize bounds the number of recent combat replays kept in
+// memory for replayCombat. Older replays are only available from the store.
+const CombatReplayCacheSize = 20
+
+// combatLogPrefix is the store key prefix under which finished combat logs
+// are saved, one document per session and encounter.
+const combatLogPrefix = "combat_logs/"
+
+// CombatLogPageSize caps the entries one getCombatLog call returns, and is
+// the limit applied when the caller sets none.
+const CombatLogPageSize = 100
+
+// ChronicleSize caps the moments the chronicle remembers per player for
+// getSessionRecap; the oldest are forgotten first.
+const ChronicleSize = 500
+
+// MaxAnnotationsPerLevel caps the map annotations a level holds;
+// addAnnotation refuses more.
+const MaxAnnotationsPerLevel = 200
+
+// RequestAuditBodyLimit caps the bytes of the parameters and of the result
+// kept in each request audit record; longer documents are truncated.
+const RequestAuditBodyLimit = 4096
+
+// RequestAuditPageSize is the number of records admin.listRequests returns
+// when the caller sets no limit.
+const RequestAuditPageSize = 50
+
+// LeaderboardSize is the number of entries getLeaderboard returns when the
+// caller sets no limit; LeaderboardMaxSize caps the limit a caller may set.
+const (
+	LeaderboardSize    = 10
+	LeaderboardMaxSize = 100
 )
 
 // Session configuration constants
@@ -33,6 +88,37 @@ const (
 	MessageSendTimeout    = 50 * time.Millisecond
 )
 
+// ContentPreviewTTL is how long content generated with generateContent's
+// preview flag can be committed with commitGeneratedContent.
+const ContentPreviewTTL = 10 * time.Minute
+
+// IdempotencyKeyTTL is how long the response of a call made with an
+// idempotency key is replayed to retries of the call.
+const IdempotencyKeyTTL = 10 * time.Minute
+
+// ContentQueryMaxResults caps the entries one queryGeneratedContent call
+// returns, and is the limit applied when the caller sets none.
+const ContentQueryMaxResults = 100
+
+// Content feedback limits. A session may submit FeedbackRateLimit feedback
+// entries per FeedbackRateWindow, and one entry per piece of content every
+// FeedbackDedupTTL. Comments longer than FeedbackMaxCommentLength are
+// rejected.
+const (
+	FeedbackRateLimit        = 10
+	FeedbackRateWindow       = time.Minute
+	FeedbackDedupTTL         = 24 * time.Hour
+	FeedbackMaxCommentLength = 500
+)
+
+// ActionJournalSize bounds the admin actions each session can undo with
+// admin.undoLastAction. Recording another action forgets the oldest.
+const ActionJournalSize = 50
+
+// ResumeEventBufferSize bounds the number of broadcast events kept per joined
+// session for replay by reconnectSession. Older events are discarded.
+const ResumeEventBufferSize = 256
+
 // RPCMethod constants define the available RPC methods for the game server.
 // These methods handle various game actions and state queries.
 // - Character has insufficient movement points
@@ -46,16 +132,34 @@ const (
 	MethodApplyEffect     RPCMethod = "applyEffect"
 	MethodStartCombat     RPCMethod = "startCombat"
 	MethodEndTurn         RPCMethod = "endTurn"
+	MethodGetCombatState  RPCMethod = "getCombatState"
 	MethodGetGameState    RPCMethod = "getGameState"
 	MethodJoinGame        RPCMethod = "joinGame"
 	MethodLeaveGame       RPCMethod = "leaveGame"
 	MethodCreateCharacter RPCMethod = "createCharacter"
+	MethodChangeClass     RPCMethod = "changeClass"
+	MethodLevelUp         RPCMethod = "levelUp"
+	MethodOpenDoor        RPCMethod = "openDoor"
+	MethodPickLock        RPCMethod = "pickLock"
+	MethodInteractObject  RPCMethod = "interactObject"
+	MethodTalkToNPC       RPCMethod = "talkToNPC"
+
+	// Session resumption methods
+	MethodReconnectSession RPCMethod = "reconnectSession"
+
+	// Discovery methods
+	MethodDiscover RPCMethod = "rpc.discover"
 
 	// Equipment management methods
 	MethodEquipItem    RPCMethod = "equipItem"
 	MethodUnequipItem  RPCMethod = "unequipItem"
 	MethodGetEquipment RPCMethod = "getEquipment"
 
+	// Combat reaction methods
+	MethodRegisterReaction RPCMethod = "registerReaction"
+	MethodCancelReaction   RPCMethod = "cancelReaction"
+	MethodRespondReaction  RPCMethod = "respondReaction"
+
 	// Quest management methods
 	MethodStartQuest         RPCMethod = "startQuest"
 	MethodCompleteQuest      RPCMethod = "completeQuest"
@@ -66,28 +170,117 @@ const (
 	MethodGetCompletedQuests RPCMethod = "getCompletedQuests"
 	MethodGetQuestLog        RPCMethod = "getQuestLog"
 
+	// Party methods
+	MethodCreateParty RPCMethod = "createParty"
+	MethodJoinParty   RPCMethod = "joinParty"
+	MethodLeaveParty  RPCMethod = "leaveParty"
+	MethodGetParty    RPCMethod = "getParty"
+	MethodShareQuest  RPCMethod = "shareQuest"
+
+	// Companion methods
+	MethodHireCompanion    RPCMethod = "hireCompanion"
+	MethodDismissCompanion RPCMethod = "dismissCompanion"
+	MethodGetCompanions    RPCMethod = "getCompanions"
+
 	// Spell management methods
-	MethodGetSpell          RPCMethod = "getSpell"
-	MethodGetSpellsByLevel  RPCMethod = "getSpellsByLevel"
-	MethodGetSpellsBySchool RPCMethod = "getSpellsBySchool"
-	MethodGetAllSpells      RPCMethod = "getAllSpells"
-	MethodSearchSpells      RPCMethod = "searchSpells"
+	MethodGetSpell            RPCMethod = "getSpell"
+	MethodGetSpellsByLevel    RPCMethod = "getSpellsByLevel"
+	MethodGetSpellsBySchool   RPCMethod = "getSpellsBySchool"
+	MethodGetAllSpells        RPCMethod = "getAllSpells"
+	MethodSearchSpells        RPCMethod = "searchSpells"
+	MethodMemorizeSpells      RPCMethod = "memorizeSpells"
+	MethodPreviewSpellTargets RPCMethod = "previewSpellTargets"
+	MethodRest                RPCMethod = "rest"
+	MethodPassTime            RPCMethod = "passTime"
+	MethodTravelTo            RPCMethod = "travelTo"
 
 	// Spatial query methods for efficient object retrieval
 	MethodGetObjectsInRange  RPCMethod = "getObjectsInRange"
 	MethodGetObjectsInRadius RPCMethod = "getObjectsInRadius"
 	MethodGetNearestObjects  RPCMethod = "getNearestObjects"
+	MethodGetVisibleEnemies  RPCMethod = "getVisibleEnemies"
 
 	// PCG (Procedural Content Generation) methods
-	MethodGenerateContent   RPCMethod = "generateContent"
-	MethodRegenerateTerrain RPCMethod = "regenerateTerrain"
-	MethodGenerateItems     RPCMethod = "generateItems"
-	MethodGenerateLevel     RPCMethod = "generateLevel"
-	MethodGenerateQuest     RPCMethod = "generateQuest"
-	MethodGetPCGStats       RPCMethod = "getPCGStats"
-	MethodValidateContent   RPCMethod = "validateContent"
+	MethodGenerateContent        RPCMethod = "generateContent"
+	MethodRegenerateTerrain      RPCMethod = "regenerateTerrain"
+	MethodGenerateItems          RPCMethod = "generateItems"
+	MethodGenerateLevel          RPCMethod = "generateLevel"
+	MethodGenerateQuest          RPCMethod = "generateQuest"
+	MethodGetPCGStats            RPCMethod = "getPCGStats"
+	MethodValidateContent        RPCMethod = "validateContent"
+	MethodGetGenerationJobStatus RPCMethod = "getGenerationJobStatus"
+	MethodSubmitContentFeedback  RPCMethod = "submitContentFeedback"
+	MethodQueryGeneratedContent  RPCMethod = "queryGeneratedContent"
+	MethodGetDifficultyHeatmap   RPCMethod = "getDifficultyHeatmap"
+
+	// Content preview methods
+	MethodCommitGeneratedContent RPCMethod = "commitGeneratedContent"
+
+	// Backup administration methods
+	MethodListBackups RPCMethod = "listBackups"
+
+	// Snapshot administration methods
+	MethodListSnapshots RPCMethod = "listSnapshots"
+
+	// Combat replay methods
+	MethodReplayCombat RPCMethod = "replayCombat"
+
+	// Combat log methods
+	MethodGetCombatLog RPCMethod = "getCombatLog"
+
+	// Session recap methods
+	MethodGetSessionRecap RPCMethod = "getSessionRecap"
+
+	// Campaign statistics methods
+	MethodGetCampaignStats RPCMethod = "getCampaignStats"
+	MethodGetLeaderboard   RPCMethod = "getLeaderboard"
+
+	// Map delta methods
+	MethodGetMapDelta RPCMethod = "getMapDelta"
+	MethodExportMap   RPCMethod = "exportMap"
+
+	// Map annotation methods
+	MethodAddAnnotation  RPCMethod = "addAnnotation"
+	MethodGetAnnotations RPCMethod = "getAnnotations"
+
+	// Rate limit diagnostics methods
+	MethodGetRateLimitStats RPCMethod = "getRateLimitStats"
+
+	// Merchant methods
+	MethodGetMerchant RPCMethod = "getMerchant"
+	MethodBuyItem     RPCMethod = "buyItem"
+	MethodSellItem    RPCMethod = "sellItem"
+
+	// Appearance methods
+	MethodGetPortrait RPCMethod = "getPortrait"
+
+	// World event methods
+	MethodGetWorldEvents RPCMethod = "getWorldEvents"
+
+	// Admin console methods, authorized by the admin token rather than a
+	// session
+	MethodAdminListSessions    RPCMethod = "admin.listSessions"
+	MethodAdminInspectSession  RPCMethod = "admin.inspectSession"
+	MethodAdminSpawnEntity     RPCMethod = "admin.spawnEntity"
+	MethodAdminTeleportPlayer  RPCMethod = "admin.teleportPlayer"
+	MethodAdminGrantXP         RPCMethod = "admin.grantXP"
+	MethodAdminGrantItem       RPCMethod = "admin.grantItem"
+	MethodAdminGenerate        RPCMethod = "admin.generateContent"
+	MethodAdminEndCombat       RPCMethod = "admin.endCombat"
+	MethodAdminGiveItem        RPCMethod = "admin.giveItem"
+	MethodAdminTeleport        RPCMethod = "admin.teleport"
+	MethodAdminUndoLastAction  RPCMethod = "admin.undoLastAction"
+	MethodAdminRestoreBackup   RPCMethod = "admin.restoreBackup"
+	MethodAdminRestoreSnapshot RPCMethod = "admin.restoreSnapshot"
+	MethodAdminReloadLoot      RPCMethod = "admin.reloadLootTables"
+	MethodAdminReloadPCG       RPCMethod = "admin.reloadPCGDefinitions"
+	MethodAdminReloadConfig    RPCMethod = "admin.reloadConfig"
+	MethodAdminListRequests    RPCMethod = "admin.listRequests"
 )
 
+// AdminMethodPrefix starts the name of every admin console method
+const AdminMethodPrefix = "admin."
+
 // EventCombatStart represents when combat begins in the game. This event is triggered
 // when characters initiate or are forced into combat.
 // Event number: 100 (base combat event number + iota)
@@ -99,4 +292,23 @@ const (
 	EventTurnStart
 	EventTurnEnd
 	EventMovement
+	EventReactionPrompt
+	EventReactionResolved
+	EventWeatherChange
+	EventTensionChange
+	EventEncounterProposed
+	EventWorldEventStart
+	EventWorldEventEnd
+	EventTurnWarning
+	EventTurnSkipped
+	EventDoorChanged
+	EventFeatureUsed
+	EventCompanionChanged
+	EventLightBurnedOut
+	EventAnnotationAdded
+	EventTravelInterrupted
+	EventOpportunityAttack
+	EventTimePassed
+	EventMerchantsRestocked
+	EventQuestDeadlineWarning
 )
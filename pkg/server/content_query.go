@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// handleQueryGeneratedContent searches the content generated by the PCG
+// manager. Every generated terrain map, item, level, level room and quest
+// is indexed with its biome, theme, difficulty, faction and rarity, so GM
+// tools can ask for, say, all undead-themed boss rooms of difficulty 8 or
+// more. Filters that are left out match everything.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The querying session
+//   - kind: string (optional) - terrain, item, level, room or quest
+//   - content_type: string (optional) - terrain, items, levels or quests
+//   - location_id, biome, theme, faction, rarity: string (optional) - Exact matches
+//   - tags: []string (optional) - Tags the content must all carry, such as a room type
+//   - min_difficulty, max_difficulty: int (optional) - Inclusive difficulty bounds
+//   - text: string (optional) - Words that must all appear in the name, description or tags
+//   - limit: int (optional) - Maximum results, up to ContentQueryMaxResults
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - results: []pcg.ContentEntry - Matching content, most recently generated first
+//   - count: int - Number of results
+//   - error: Invalid parameters or session
+func (s *RPCServer) handleQueryGeneratedContent(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleQueryGeneratedContent",
+	})
+	logger.Debug("entering handleQueryGeneratedContent")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		pcg.ContentQuery
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid content query parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	query := req.ContentQuery
+	if query.MinDifficulty < 0 || query.MaxDifficulty < 0 || query.Limit < 0 {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "difficulty bounds and limit must not be negative", nil)
+	}
+	if query.MaxDifficulty > 0 && query.MinDifficulty > query.MaxDifficulty {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "min_difficulty exceeds max_difficulty", nil)
+	}
+	if query.Limit == 0 || query.Limit > ContentQueryMaxResults {
+		query.Limit = ContentQueryMaxResults
+	}
+
+	results := s.pcgManager.GetContentIndex().Query(query)
+
+	logger.WithFields(logrus.Fields{
+		"sessionID": req.SessionID,
+		"results":   len(results),
+	}).Debug("generated content queried")
+
+	return map[string]interface{}{
+		"results": results,
+		"count":   len(results),
+	}, nil
+}
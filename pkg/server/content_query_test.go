@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryGeneratedContent(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	_, err := server.handleGenerateContent(json.RawMessage(`{"session_id":"test-session-001","content_type":"levels","location_id":"deep_vault","difficulty":10}`))
+	require.NoError(t, err)
+
+	result, err := server.handleQueryGeneratedContent(json.RawMessage(`{"session_id":"test-session-001","kind":"level","location_id":"deep_vault"}`))
+	require.NoError(t, err)
+	levels := result.(map[string]interface{})["results"].([]pcg.ContentEntry)
+	require.Len(t, levels, 1)
+	assert.Equal(t, pcg.ThemeClassic, levels[0].Theme)
+	assert.Equal(t, 10, levels[0].Difficulty)
+
+	result, err = server.handleQueryGeneratedContent(json.RawMessage(`{"session_id":"test-session-001","kind":"room","tags":["boss"],"text":"classic"}`))
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	rooms := response["results"].([]pcg.ContentEntry)
+	require.NotEmpty(t, rooms, "difficulty 10 levels have a boss room")
+	assert.Equal(t, len(rooms), response["count"])
+	for _, room := range rooms {
+		assert.Equal(t, levels[0].ID, room.ParentID)
+		assert.Contains(t, room.Tags, string(pcg.RoomTypeBoss))
+	}
+
+	result, err = server.handleQueryGeneratedContent(json.RawMessage(`{"session_id":"test-session-001","kind":"room","limit":1}`))
+	require.NoError(t, err)
+	assert.Len(t, result.(map[string]interface{})["results"], 1)
+}
+
+func TestQueryGeneratedContent_InvalidParams(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	_, err := server.handleQueryGeneratedContent(json.RawMessage(`{"session_id":"test-session-001","min_difficulty":8,"max_difficulty":3}`))
+	assert.ErrorContains(t, err, "min_difficulty exceeds max_difficulty")
+
+	_, err = server.handleQueryGeneratedContent(json.RawMessage(`{"session_id":"test-session-001","tags":"boss"}`))
+	assert.ErrorContains(t, err, "Invalid content query parameters")
+
+	_, err = server.handleQueryGeneratedContent(json.RawMessage(`{"session_id":"missing"}`))
+	assert.Error(t, err)
+}
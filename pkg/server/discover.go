@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+
+	"goldbox-rpg/pkg/validation"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OpenRPCVersion is the version of the OpenRPC specification rpc.discover
+// documents follow
+const OpenRPCVersion = "1.2.6"
+
+// discoverAPIVersion is the version of the API reported by rpc.discover
+const discoverAPIVersion = "1.0.0"
+
+// unservedMethods are the methods the validator has rules for but the
+// server has no handler for, left out of rpc.discover
+var unservedMethods = map[string]bool{
+	"ping":            true,
+	"createPlayer":    true,
+	"getPlayer":       true,
+	"listPlayers":     true,
+	"getCharacter":    true,
+	"updateCharacter": true,
+	"listCharacters":  true,
+	"getPosition":     true,
+	"getSpells":       true,
+	"getWorld":        true,
+	"getWorldState":   true,
+	"getInventory":    true,
+}
+
+// discoverMethod is an OpenRPC method object: the validator's description
+// of the method's parameters, which are passed by name
+type discoverMethod struct {
+	validation.MethodSchema
+	ParamStructure string           `json:"paramStructure"`
+	Result         validation.Param `json:"result"`
+}
+
+// discoverMethods describes the methods the server answers, sorted by name
+func (s *RPCServer) discoverMethods() []discoverMethod {
+	schemas := s.validator.Methods()
+	methods := make([]discoverMethod, 0, len(schemas))
+	for _, schema := range schemas {
+		if unservedMethods[schema.Name] {
+			continue
+		}
+		methods = append(methods, discoverMethod{
+			MethodSchema:   schema,
+			ParamStructure: "by-name",
+			Result: validation.Param{
+				Name:   "result",
+				Schema: validation.Schema{Type: "object"},
+			},
+		})
+	}
+	return methods
+}
+
+// handleDiscover describes the JSON-RPC API as an OpenRPC document, so
+// clients and tools can be generated from the server they talk to.
+//
+// Parameters:
+//   - params: Unused; the method takes no parameters and needs no session
+//
+// Returns:
+//   - interface{}: OpenRPC document with the methods, their parameter
+//     schemas built from the validation rules, and the game errors keyed by
+//     reason
+//   - error: Always nil
+func (s *RPCServer) handleDiscover(params json.RawMessage) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "handleDiscover",
+	}).Debug("entering handleDiscover")
+
+	errs := make(map[string]*JSONRPCError)
+	for _, entry := range ErrorCatalog() {
+		if data, ok := entry.Data.(map[string]interface{}); ok {
+			if reason, ok := data["reason"].(string); ok {
+				errs[reason] = entry
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"openrpc": OpenRPCVersion,
+		"info": map[string]interface{}{
+			"title":   "GoldBox RPG JSON-RPC API",
+			"version": discoverAPIVersion,
+		},
+		"methods": s.discoverMethods(),
+		"components": map[string]interface{}{
+			"errors": errs,
+		},
+	}, nil
+}
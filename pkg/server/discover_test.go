@@ -0,0 +1,170 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDiscover(t *testing.T) {
+	server := createTestServerForHandlers(t)
+
+	result, err := server.handleMethod(MethodDiscover, nil)
+	require.NoError(t, err, "discovery needs no session")
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	var document struct {
+		OpenRPC string `json:"openrpc"`
+		Methods []struct {
+			Name           string `json:"name"`
+			ParamStructure string `json:"paramStructure"`
+			Params         []struct {
+				Name     string                 `json:"name"`
+				Required bool                   `json:"required"`
+				Schema   map[string]interface{} `json:"schema"`
+			} `json:"params"`
+		} `json:"methods"`
+		Components struct {
+			Errors map[string]JSONRPCError `json:"errors"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(data, &document))
+	assert.Equal(t, OpenRPCVersion, document.OpenRPC)
+
+	methods := make(map[string]int)
+	for i, method := range document.Methods {
+		methods[method.Name] = i
+		assert.Equal(t, "by-name", method.ParamStructure)
+	}
+	assert.Contains(t, methods, string(MethodDiscover))
+	assert.NotContains(t, methods, "ping", "methods without a handler are left out")
+
+	require.Contains(t, methods, string(MethodRest))
+	rest := document.Methods[methods[string(MethodRest)]]
+	require.Len(t, rest.Params, 3)
+	assert.Equal(t, "session_id", rest.Params[0].Name)
+	assert.True(t, rest.Params[0].Required)
+	assert.Equal(t, "hours", rest.Params[1].Name)
+	assert.False(t, rest.Params[1].Required)
+	assert.Equal(t, "integer", rest.Params[1].Schema["type"])
+	assert.Equal(t, 1.0, rest.Params[1].Schema["minimum"])
+	assert.Equal(t, 24.0, rest.Params[1].Schema["maximum"])
+
+	assert.Len(t, document.Components.Errors, len(ErrorCatalog()))
+	assert.Equal(t, ErrCodeInvalidSession, document.Components.Errors["invalid_session"].Code)
+}
+
+// hiddenMethods are served methods deliberately left out of rpc.discover.
+// None are hidden today; list a method here with the reason if one must be.
+var hiddenMethods = map[string]bool{}
+
+// TestDiscoverMethods_MatchHandlers checks rpc.discover against the method
+// constants and the cases handleMethod dispatches: every discovered method
+// is served, every served method is discovered unless hidden, and
+// unservedMethods only lists methods that are not served.
+func TestDiscoverMethods_MatchHandlers(t *testing.T) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "constants.go", nil, 0)
+	require.NoError(t, err)
+
+	// Method constants by identifier, e.g. MethodRest -> "rest"
+	constants := make(map[string]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || len(spec.Values) != 1 {
+			return true
+		}
+		if ident, ok := spec.Type.(*ast.Ident); !ok || ident.Name != "RPCMethod" {
+			return true
+		}
+		if lit, ok := spec.Values[0].(*ast.BasicLit); ok {
+			name, err := strconv.Unquote(lit.Value)
+			require.NoError(t, err)
+			constants[spec.Names[0].Name] = name
+		}
+		return true
+	})
+	require.NotEmpty(t, constants)
+
+	served := make(map[string]bool)
+	for _, name := range constants {
+		served[name] = true
+	}
+
+	// Methods with a case in the handleMethod switch
+	file, err = parser.ParseFile(fileSet, "server.go", nil, 0)
+	require.NoError(t, err)
+	dispatched := make(map[string]bool)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "handleMethod" {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			clause, ok := n.(*ast.CaseClause)
+			if !ok {
+				return true
+			}
+			for _, expr := range clause.List {
+				if ident, ok := expr.(*ast.Ident); ok {
+					if name, ok := constants[ident.Name]; ok {
+						dispatched[name] = true
+					}
+				}
+			}
+			return true
+		})
+	}
+	require.NotEmpty(t, dispatched)
+
+	server := createTestServerForHandlers(t)
+	discovered := make(map[string]bool)
+	for _, method := range server.discoverMethods() {
+		discovered[method.Name] = true
+		assert.True(t, served[method.Name], "%s is discovered but has no handler", method.Name)
+	}
+	for name := range served {
+		if hiddenMethods[name] {
+			assert.False(t, discovered[name], "%s is hidden but discovered", name)
+			continue
+		}
+		assert.True(t, discovered[name], "%s is served but missing from rpc.discover", name)
+	}
+	for name := range dispatched {
+		assert.True(t, discovered[name] || hiddenMethods[name], "%s is dispatched by handleMethod but missing from rpc.discover", name)
+	}
+	for name := range unservedMethods {
+		assert.False(t, served[name], "%s has a handler and should be discovered", name)
+	}
+}
+
+// TestDiscoveredMethods_PassValidation calls methods that were served but
+// had no validator, which handleMethod rejected as unknown methods.
+func TestDiscoveredMethods_PassValidation(t *testing.T) {
+	server := createTestServerForHandlers(t)
+
+	result, err := server.handleMethod(MethodJoinGame, json.RawMessage(`{"player_name":"Aldric"}`))
+	require.NoError(t, err)
+	sessionID := result.(map[string]interface{})["session_id"].(string)
+
+	// The session has no character yet, so some calls fail in the handler,
+	// but none are turned away by validation
+	params := json.RawMessage(`{"session_id":"` + sessionID + `"}`)
+	for _, method := range []RPCMethod{MethodGetGameState, MethodGetActiveQuests, MethodGetEquipment, MethodEndTurn, MethodGetPCGStats} {
+		_, err := server.handleMethod(method, params)
+		var rpcErr *JSONRPCError
+		if errors.As(err, &rpcErr) {
+			assert.NotEqual(t, "Invalid method parameters", rpcErr.Message, "%s: %v", method, rpcErr.Data)
+		}
+	}
+	_, err = server.handleMethod(MethodGetSpellsByLevel, json.RawMessage(`{"level":3}`))
+	assert.NoError(t, err)
+}
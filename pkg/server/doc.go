@@ -31,11 +31,496 @@
 // # JSON-RPC Methods
 //
 // The server handles standard RPG operations via JSON-RPC 2.0:
-//   - Player/Character management: createPlayer, getPlayer, createCharacter
-//   - Movement and positioning: move, getPosition
-//   - Combat actions: attack, castSpell, getSpells
+//   - Player/Character management: createPlayer, getPlayer, createCharacter,
+//     changeClass, levelUp
+//   - Movement and positioning: move, travelTo, getPosition
+//   - Doors: openDoor, pickLock
+//   - Features: interactObject (levers, altars, fountains, bookshelves)
+//   - Conversation: talkToNPC
+//   - Combat actions: attack, castSpell, getSpells, previewSpellTargets
+//   - Combat state: getCombatState
+//   - Spell memorization and resting: memorizeSpells, rest
+//   - Downtime: passTime
+//   - Combat reactions: registerReaction, cancelReaction, respondReaction
 //   - Equipment: equipItem, unequipItem, getInventory
-//   - World state: getWorld, getWorldState
+//   - Merchants: getMerchant, buyItem, sellItem
+//   - World state: getWorld, getWorldState, getMapDelta, exportMap
+//   - Map annotations: addAnnotation, getAnnotations
+//   - Tactical queries: getVisibleEnemies (hostiles in sight with threat estimates)
+//   - Parties: createParty, joinParty, leaveParty, getParty, shareQuest
+//   - Companions: hireCompanion, dismissCompanion, getCompanions
+//   - Content generation: generateContent (optionally as a preview or a
+//     background job), commitGeneratedContent, getGenerationJobStatus
+//   - Content feedback: submitContentFeedback
+//   - Content search: queryGeneratedContent
+//   - Difficulty analysis: getDifficultyHeatmap
+//   - Content administration: admin.reloadLootTables,
+//     admin.reloadPCGDefinitions
+//   - Configuration: admin.reloadConfig (also on SIGHUP)
+//   - Request auditing: admin.listRequests
+//   - Backup administration: listBackups, admin.restoreBackup
+//   - Snapshot administration: listSnapshots, admin.restoreSnapshot
+//   - Combat replays: replayCombat
+//   - Combat logs: getCombatLog
+//   - Session recaps: getSessionRecap
+//   - Campaign statistics: getCampaignStats, getLeaderboard
+//   - Rate limit diagnostics: getRateLimitStats
+//   - Game master actions: applyEffect, admin.giveItem, admin.teleport,
+//     admin.undoLastAction
+//   - Appearance: getPortrait
+//   - World events: getWorldEvents
+//   - Admin console: admin.listSessions, admin.inspectSession,
+//     admin.spawnEntity, admin.teleportPlayer, admin.grantXP,
+//     admin.grantItem, admin.generateContent, admin.endCombat
+//   - Discovery: rpc.discover
+//
+// # Method Discovery
+//
+// rpc.discover answers with an OpenRPC document of the methods the server
+// handles. Their parameter schemas come from the validator's
+// MethodSchema descriptions, so they follow the rules requests are checked
+// against, and the error catalog is listed by reason. Validated methods
+// without a handler are left out.
+//
+// # Errors
+//
+// Handlers report game rule and lookup failures with entries from the error
+// catalog in errors.go, such as ErrNotYourTurn or ErrInsufficientAP. Each
+// has its own JSON-RPC error code and a "reason" in its data, and entries
+// derived with WithMessage or WithData still match the catalog entry with
+// errors.Is. Wrapped catalog errors keep their code over HTTP and
+// WebSocket alike.
+//
+// # Ruleset Profiles
+//
+// The RULESET setting selects a rules profile from data/rulesets (adnd1e,
+// adnd2e, house, or a profile of your own) that supplies attack matrices,
+// saving throws, experience thresholds, class restrictions and the friendly
+// fire rule of area spells through game.SetActiveRuleset. Without it the built-in rules apply; a selected
+// profile that is missing or invalid stops the server from starting.
+//
+// # Initiative and Surprise
+//
+// startCombat rolls Gold Box style initiative: a d10 plus the dexterity
+// reaction adjustment, minus the speed factor of the equipped weapon, with
+// the highest total acting first. When the stealth of one side (players
+// against everyone else) beats the perception of the other, the surprised
+// side sits out the first round. Initiative is rolled once per combat, or
+// at every round when the combat's initiative mode is per_round. The
+// response carries the full breakdown of each roll.
+//
+// # Combat Reactions
+//
+// During combat an entity may hold reactions (attack of opportunity, shield
+// block, counterspell) that fire during other entities' turns. When an action
+// triggers held reactions, the TurnManager orders them by priority and then
+// initiative, and each owner receives a reaction prompt event over WebSocket.
+// The triggering action waits until the owner answers with respondReaction or
+// the prompt times out, which counts as declining. Each entity may react once
+// per round.
+//
+// # Zone of Control
+//
+// With config.ZoneOfControl set, every living combatant threatens the eight
+// squares around it. A player whose step starts in a square an enemy
+// threatens provokes an automatic opportunity attack from that enemy, which
+// uses the enemy's reaction for the round and charges it ActionCostAttack
+// action points on its next turn. Enemies holding an attack of opportunity
+// reaction are prompted instead. Attacks are broadcast as
+// EventOpportunityAttack, and a player struck down stays where it was.
+//
+// # Turn Timer
+//
+// Each combat turn runs against a timer (config TurnTimeout, 60 seconds by
+// default). EventTurnWarning is broadcast TurnWarning before it runs out.
+// A combatant whose turn expires holds a shield block and loses the turn,
+// which is broadcast as EventTurnSkipped; a player who loses
+// AFKSkipLimit turns in a row is removed from the initiative order. Ending
+// a turn with endTurn resets the count.
+//
+// # Combat State Updates
+//
+// The TurnManager reports each change of the combat state to its state
+// listener, which the server forwards to every WebSocket client as a
+// combat_state message: initiative (a new order), turn, round,
+// action_points (spent or restored), effects (applied, ticked or aged a
+// round) and ended. Each message carries the round, the active combatant
+// and a revision that increases with every change. Clients that missed
+// messages resynchronize with getCombatState and drop updates whose
+// revision is at or below the snapshot's. CombatStateSchema embeds the
+// JSON Schema of the messages and the snapshot.
+//
+// # Weather and Time of Day
+//
+// The TimeManager advances game time (one tick per game second) and drives a
+// WeatherSystem that generates weather fronts per region from the PCG seed.
+// Rain, fog and storms penalize ranged attacks; snow and storms raise the
+// action point cost of moving in combat. Weather changes are broadcast to
+// WebSocket clients as they occur.
+//
+// # Calendar and Scheduled Events
+//
+// Game time runs on a calendar of twelve thirty-day months in four seasons,
+// with festivals on fixed days (DateAt). The TimeManager also holds
+// scheduled events, run once game time reaches them: merchants restock
+// every MerchantRestockDays (ScheduledShopRestock) and a quest still active
+// at its deadline fails (ScheduledQuestDeadline). Quests and objectives
+// with a time limit (game.Quest.TimeLimitHours) are tracked as time passes
+// from when they are accepted: EventQuestDeadlineWarning warns when a
+// quarter of the limit is left, and a quest past its deadline fails with
+// its consequences. passTime fast-forwards
+// game time outside combat an hour at a time, running the events that come
+// due and ending effects that run out (game.Effect.ExpiresWithin), and
+// getGameState reports the current date.
+//
+// # Light and Darkness
+//
+// Generated caves and crypts are dark and dungeons dim. Using a torch or
+// lantern lights it: the TimeManager keeps it burning for its burn time in
+// game ticks, and the world lights the tiles around its holder until it
+// burns out (EventLightBurnedOut). Hostiles in the dark are only visible by
+// light or infravision, and attacks at targets the attacker sees poorly or
+// not at all may miss (DimAttackPenalty, DarknessAttackPenalty).
+//
+// # Area Spells
+//
+// Spells with an area template (circle, burst, cone or line) damage every
+// creature the template reaches, resolved by game.World.ResolveArea with
+// line of sight and cover. Whether the caster, its allies and its hired
+// companions are spared follows the ruleset's friendly fire rule.
+// previewSpellTargets resolves the same area without casting, so clients
+// can highlight the tiles and targets first.
+//
+// # Spell Components
+//
+// castSpell checks a spell's components before any counterspell is offered.
+// Stun and silence block verbal components, and stun and bound block somatic
+// ones (ErrSpellComponentBlocked). A material component is met by any item
+// of type SpellComponent, or, with config.StrictSpellComponents set, by the
+// specific materials the spell lists (ErrSpellMaterialsMissing). Strict mode
+// uses up the consumed materials when the spell is cast or countered.
+//
+// # Tension and Music
+//
+// A TensionDirector scores the game every couple of seconds from the
+// proximity and danger of hostiles in the players' sight, the party's
+// health, the phase of any boss in sight (tagged "boss"; phases follow its
+// remaining HP) and the heat of recent damage, deaths and combat starts.
+// The score maps to a TensionLevel (calm, uneasy, tense, climax) with
+// hysteresis, and each level change is broadcast as an EventTensionChange
+// so every client plays the same music layers at the same time.
+//
+// # Random Encounters
+//
+// With RANDOM_ENCOUNTERS_ENABLED, a RandomEncounterSystem rolls every step a
+// player takes outside combat against the encounter table of the biome
+// underfoot. Rolls are derived from the PCG seed, tables are filtered by
+// the average level of the player's party, and a player is safe for
+// ENCOUNTER_COOLDOWN_STEPS steps after an encounter. An encounter is emitted
+// as an EventEncounterProposed; the server places its monsters around the
+// player, tagged "hostile", and starts combat against the party.
+//
+// # Auto-travel
+//
+// travelTo walks a player to a destination outside combat, planning the
+// route on the PCG manager's travel graph: dungeon complexes integrated into
+// the world add their stairs, ladders, pits and portals to it, and
+// overworlds added with AddWorld their roads. Every step passes game time at
+// the pace of its leg, a minute per dungeon step and less per overworld step
+// on a road than off it, and rolls for random encounters like a move.
+// Hostiles coming into sight, an encounter or a blocked way stop travel,
+// and the stop is broadcast as an EventTravelInterrupted with the legs of
+// the route left to travel.
+//
+// # Campaign Scripts
+//
+// Scripts in data/scripts/hooks.yaml run on the onQuestComplete, onKill
+// and onEnterRoom hooks (rooms come from the room_id of generated level
+// tiles) through the sandboxed engine of pkg/scripting. Scripts can change
+// world state only through the host functions log, give_gold,
+// give_experience and heal plus the engine's flags; a failing or runaway
+// script is logged and never fails the action that fired it.
+//
+// # Parties and Shared Quests
+//
+// Players can form parties and share active quests with the other members.
+// Objective progress made by any participant is applied to every
+// participant's quest log, and completing the quest completes it for all of
+// them with rewards divided by the party's reward policy. The conflict rules
+// (members who already finished the quest, diverging progress) are documented
+// on game.Party.
+//
+// # Companions
+//
+// Players can hire generated companions (game.Companion), tracked by the
+// CompanionManager. Companions join the combats their employer takes part
+// in and take their turns automatically, attacking the nearest opponent
+// unless their morale has broken. Their personality's behavior modifiers
+// make cowards hold back once badly hurt and pick on the weakest opponent. They take a share of their employer's
+// quest experience and gold. Loyalty and morale move with pay, victories,
+// rest, wounds and deaths; a companion whose loyalty runs out deserts.
+// MAX_COMPANIONS and MAX_PARTY_SIZE limit how many can be hired.
+//
+// # Combat Replays
+//
+// Each combat started with startCombat is recorded as a CombatReplay: a
+// snapshot of the participants, every combat call until the combat ends and
+// every random draw (initiative, spell dice, reaction answers). Dice come
+// from a roller reseeded each turn with a seed derived from the combat seed,
+// so replayCombat can re-run the calls against a sandboxed copy of the
+// participants and report any divergence. Finished replays are saved under
+// "replays/" in the persistence store. A replay ID can be quoted as the
+// content ID of combat feedback to the PCG quality metrics, so balance
+// complaints come with a reproducible fight.
+//
+// # Combat Logs
+//
+// Alongside the replay, each combat keeps a CombatEncounter of structured
+// CombatLogEntry records: attacker, defender, roll, damage or healing and
+// the effects applied. getCombatLog pages through the log of an encounter
+// the session took part in. Finished logs are saved per session under
+// "combat_logs/" in the persistence store for post-game review.
+//
+// # Session Recaps
+//
+// The chronicle records each player's notable moments from game events:
+// quests started, completed and failed, secret doors and items found, and
+// levels reached, plus kills from the combat log. getSessionRecap merges
+// the timelines of a party and has the quests NarrativeEngine tell them as
+// "the story so far". The chronicle is saved beside the game state.
+//
+// # Campaign Statistics
+//
+// The server counts, per character, the monsters slain, dungeon levels
+// cleared of hostile NPCs and deaths, and aggregates them every
+// STATS_AGGREGATION_INTERVAL: active players gain playtime, their gold and
+// level are refreshed, and the campaign totals and leaderboards are
+// recomputed. getCampaignStats and getLeaderboard return the results of
+// the latest aggregation. The statistics are saved beside the game state.
+//
+// # Map Deltas
+//
+// Each level of the world has a revision counter that game.World bumps on
+// every tile replacement and object addition, move or removal. getMapDelta
+// returns only the tiles and objects changed since the revision a client
+// last saw, or the whole level with full_resync set when that revision is
+// unknown, so clients need not refetch the world state to stay current.
+//
+// # Map Annotations
+//
+// Game masters attach notes, markers and region labels to the levels of the
+// map with addAnnotation, passing the admin token with the annotate
+// permission. Annotations are GM-only unless made visible to players;
+// getAnnotations lists those the caller may see, and exportMap adds them to
+// the Tiled export as an "annotations" object group. Player-visible
+// annotations are broadcast as EventAnnotationAdded. Each level holds up to
+// MaxAnnotationsPerLevel, saved beside the game state.
+//
+// # Multi-classing
+//
+// createCharacter accepts a "classes" list for multi-classed characters,
+// which split experience between their classes and advance each on its own
+// table. changeClass dual-classes a player: the old class stops advancing
+// and is unusable until the new class exceeds its level.
+//
+// # Level Training
+//
+// With config.ManualLevelUp set, single-classed players keep the levels
+// they earn pending until they train for them with levelUp, one level per
+// call. A preview lists the level's hit die, THAC0, new spell slots,
+// proficiency slots and learnable spells; training rolls the hit die or
+// takes its average and applies everything together through
+// game.LevelUpService.
+//
+// # Game Master Actions
+//
+// applyEffect, admin.giveItem and admin.teleport record their inverse in a
+// per-session action journal holding the last ActionJournalSize actions.
+// admin.undoLastAction reverts the latest one, unless the state it changed has changed since,
+// in which case the action is dropped and ErrUndoConflict returned.
+//
+// # Portraits
+//
+// Characters carry a game.Portrait: archetype, palette, feature tags,
+// silhouette hints from their gear and a token frame, which web clients
+// render into the same avatar every time. createCharacter generates the
+// player's from the character seed; getPortrait returns any character's,
+// generating it again from its seed so the silhouette follows their gear.
+//
+// # World Events
+//
+// With WorldEventsEnabled, a WorldEventDirector rolls every
+// WorldEventWindowTicks of game time whether a goblin raid, plague,
+// festival or faction war begins, seeded from the world seed so a world
+// replays the same history. Active events scale merchant prices, add
+// encounters and change the encounter chance, and open or close quest
+// types for generateQuest. Starts and ends are broadcast as
+// EventWorldEventStart and EventWorldEventEnd; getWorldEvents lists the
+// events under way. They are saved with the game state.
+//
+// # Admin Console
+//
+// The admin.* methods serve a headless GM console. They are disabled until
+// config.AdminToken is set, and every call carries that token as
+// admin_token instead of a session ID; session_id, where present, names the
+// session acted on. Each method needs one of the permissions in
+// config.AdminPermissions (spawn, teleport, grant, generate, combat,
+// inspect, undo, restore, config, annotate) and all calls share a rate limit of their own,
+// apart from the per-session limits. Every call, allowed or denied, is
+// written to the admin audit log. Apart from admin.giveItem and admin.teleport, admin
+// actions are not journaled for admin.undoLastAction.
+//
+// # Content Previews
+//
+// generateContent called with "preview": true keeps the generated content in
+// a per-server preview cache for ContentPreviewTTL and returns it with a
+// preview_id. Nothing reaches the world until the same session calls
+// commitGeneratedContent with that ID, so game masters can inspect terrain,
+// levels, items and quests first and simply let unwanted previews expire.
+//
+// # Generator Plugins
+//
+// The executables listed in PCGPlugins are started as PCG plugins (see
+// package plugins) and their generators join the PCG registry next to the
+// built-in ones. generateContent with a "generator" name uses a registered
+// generator directly. A plugin that fails to start is logged and skipped;
+// plugins are shut down with the server.
+//
+// # Content Bundles
+//
+// The .gbox bundles listed in ContentBundles (see package bundle) are
+// verified against ContentBundleKeys and mounted at startup, after a saved
+// world is restored. Their maps are integrated like generated levels under
+// the location "bundle:<name>", their NPCs are placed in the world with
+// their dialogue, their loot tables join the loot table registry, and their
+// quests can be started with startQuest by quest_id. Levels and NPCs a
+// restored world already holds are kept. A bundle that is unsigned,
+// untrusted, tampered with or fails to mount is logged and skipped.
+//
+// # Content Search
+//
+// The PCG manager indexes everything it generates in a pcg.ContentIndex:
+// terrain by biome, items by rarity, levels and each of their rooms by
+// theme, room type and faction, and quests by type, all with their
+// difficulty. queryGeneratedContent filters the index and searches names
+// and descriptions, so GM tools can find content without walking the world.
+//
+// # Merchants
+//
+// When a generated level is committed, the MerchantManager opens each of its
+// shop rooms with a merchant stocked from the item generator. Merchant
+// prices follow the trading player's Charisma and reputation with the
+// merchant's faction, and buyItem and sellItem move gold and the item
+// between both parties atomically.
+//
+// # Doors and Keys
+//
+// Committing a generated level also places its doors and the keys of its
+// locked doors in the world. A player picks up a key by stepping onto it.
+// openDoor opens the door beside the player, using a carried key on a
+// locked door, or searches the wall for a secret door; pickLock picks a
+// lock with a Dexterity check. Door changes are broadcast as
+// EventDoorChanged, and in combat both calls cost the player's action
+// points.
+//
+// # Interactive Features
+//
+// Committing a generated level also places its interactive features
+// (game.Feature), such as levers and fountains. interactObject performs one
+// of a feature's verbs for a player within reach, rolling the verb's check
+// and applying its effects; levers linked to doors work them. Uses are
+// broadcast as EventFeatureUsed and cost action points in combat.
+//
+// # NPC Relationships
+//
+// NPCs remember how players treat them in the world's relationship graph:
+// attacks on an NPC harm its trust and raise its fear, and its faction
+// remembers them too; trades and the outcome of quests it gave move its
+// trust and what it owes the player. talkToNPC opens, or continues, a
+// conversation with the dialog nodes the NPC's feelings allow, and
+// startQuest is rejected when the quest's giver will not offer it.
+//
+// # Loot Tables
+//
+// Item drops are described by YAML loot tables in
+// data/pcg/items/loot_tables.yaml, loaded at startup. Generated combat and
+// treasure rooms roll the "combat" and "treasure" tables, and random
+// encounter monsters carry items from the "combat" table, which they drop
+// when they die. Designers can edit the file and call admin.reloadLootTables
+// to apply changes without a restart; edits that fail validation are
+// rejected and the previous tables stay active.
+//
+// # Biomes and Themes
+//
+// Terrain biomes and level themes are defined in data/pcg/biomes.yaml and
+// data/pcg/themes.yaml, loaded at startup over the built-in definitions in
+// pkg/pcg. The files may redefine built-in biomes and themes or add custom
+// ones. admin.reloadPCGDefinitions applies edits without a restart. Biomes
+// and themes are validated together, so an edit that fails validation in
+// either file leaves both as they were.
+//
+// Wave function collapse rulesets for the wave_function_collapse level
+// generator are loaded from data/pcg/wfc_rulesets.yaml at startup. They are
+// not reloaded at runtime.
+//
+// # Save Data Backups
+//
+// When BACKUP_COUNT is above zero the persistence store is wrapped in a
+// persistence.BackupStore, which copies each saved document aside before
+// it is overwritten and rotates old copies out by count and age. Stored
+// backups are checked against their checksums every BACKUP_VERIFY_INTERVAL.
+// admin.restoreBackup decodes a backup before restoring it, so one the
+// server cannot load never replaces the live document, and reloads a
+// restored game state into the running server.
+//
+// # Save Versioning
+//
+// The outermost layer of the store is a persistence.VersionedStore, which
+// embeds a schema_version in every saved document. Saves from older
+// versions are upgraded by the migrations registered in
+// persistence.DefaultMigrations when they are loaded, and the originals are
+// kept under "migrations/".
+//
+// # Event-sourced Persistence
+//
+// With GOLDBOX_PERSISTENCE_MODE=event-sourced each auto-save is a key frame, and
+// every successful call that changes only sessions (moves, items,
+// equipment, quests and character changes) appends the session's player
+// to a persistence.EventLog in DATA_DIR/events.log in between. The log
+// holds sessions only, so calls that also change shared state (combat,
+// effects, travel and other calls that advance the game clock, trades with
+// merchants, shared quests and the game master actions that move objects
+// or touch other characters) save a key frame of their own instead. The key frame saves the sessions and the log position
+// it covers in "event_checkpoint.yaml"; the records it covers are then
+// compacted away. On startup the server loads the key frame and replays
+// the records after it, so a crash loses no more than the call in flight.
+//
+// # Webhooks
+//
+// When WEBHOOK_URLS is set, selected game events are POSTed as JSON to each
+// URL: boss_defeated (a character tagged "boss" dies), player_died,
+// world_generated and quality_grade_dropped (the PCG quality grade falls
+// below WEBHOOK_QUALITY_GRADE). WEBHOOK_EVENTS restricts the events sent.
+// With WEBHOOK_SECRET set, the X-Goldbox-Signature header carries
+// "sha256=" and the hex HMAC-SHA256 of the body. Failed deliveries are
+// retried using the server retry policy, and delivery counts are exported
+// as goldbox_webhook_* metrics.
+//
+// # Gameplay Analytics
+//
+// Unless ANALYTICS_ENABLED is false, the server records anonymized
+// gameplay events for content quality analysis: quest_completed and
+// quest_failed with the time taken, player_died with the encounter named
+// by its opponents ("goblin x2, orc") or the feature that killed the
+// player, and feature_used for every player-facing RPC call. Player IDs
+// are replaced with a hash keyed with ANALYTICS_SALT, and events carry no
+// names or session IDs. Events are batched (ANALYTICS_BATCH_SIZE,
+// ANALYTICS_FLUSH_INTERVAL) and written to the sinks in ANALYTICS_SINKS:
+// stdout and file write JSON lines, http POSTs {"events": [...]} to
+// ANALYTICS_URL with the server retry policy. Quest outcomes also feed the
+// PCG engagement metrics. Recording never blocks a handler; when slow
+// sinks fill the queue, events are dropped and counted in the
+// goldbox_analytics_* metrics.
 //
 // # Real-time Communication
 //
@@ -43,6 +528,47 @@
 //   - Combat event broadcasting
 //   - Turn notifications
 //   - State synchronization across multiple clients
+//   - Generation progress: long-running generation requests relay
+//     "generation_progress" messages (stage and percentage) to the
+//     requesting session before the RPC response arrives
+//
+// Clients may request the "goldbox.msgpack" subprotocol to receive
+// MessagePack binary frames instead of JSON text, and permessage-deflate
+// compression is negotiated for messages above a configured size.
+//
+// # Session Resumption
+//
+// joinGame returns a resume token. If a client's WebSocket drops, the session
+// stays registered until it times out, and the client can call
+// reconnectSession over a new WebSocket to reattach it. Broadcast game events
+// carry a sequence number and are kept in a bounded per-session buffer, so
+// the reconnect response includes the events the client missed. Tokens are
+// rotated on every resume and expire with the session.
+//
+// # Idempotency Keys
+//
+// Mutating methods accept an idempotency_key, scoped to the call's session,
+// so clients can retry calls lost to network failures without applying them
+// twice. The methods are those the event log records or saves key frames
+// for, plus reactions, parties and commitGeneratedContent. The encoded
+// response of a successful keyed call is kept for IdempotencyKeyTTL and
+// returned to retries; a retry arriving while the call is under way waits
+// for it. Failed calls are forgotten, and reusing a key for a call with
+// other parameters fails with ErrIdempotencyConflict. The key is checked by
+// the validation package. Responses are cached per server instance.
+//
+// # Horizontal Scaling
+//
+// Sessions are shared between server instances through a SessionStore,
+// selected with SESSION_STORE_BACKEND. The default MemorySessionStore keeps
+// them in one process; RedisSessionStore lets several instances serve the
+// same players. Each SessionRecord holds the player and the ID of the
+// instance that owns the session's connection. An instance that receives a
+// request for a session it does not hold takes it over from the store, and
+// the previous owner drops its copy on its next refresh. Broadcast game
+// events and messages for sessions connected elsewhere are published
+// through the store, so every instance relays them to its own WebSocket
+// clients. Resume tokens and replay buffers stay with the owning instance.
 //
 // # Operational Features
 //
@@ -51,6 +577,56 @@
 //   - Request rate limiting with configurable thresholds
 //   - Pprof profiling when enabled
 //   - File-based auto-save with configurable intervals
+//   - Optional OpenTelemetry tracing (see below)
+//
+// # Lock Contention
+//
+// The world, game state and session map locks are lockstat mutexes. In
+// servers built with the lockprofile tag (make build-lockprofile) their wait
+// times are exported as the goldbox_lock_wait_seconds histogram, labelled by
+// lock ("world", "state", "state.world", "state.sessions", "state.turns",
+// "server.sessions") and mode ("read" or "write"). GetState and broadcasts
+// copy what they need under these locks and do the serializing and
+// encoding after releasing them; a broadcast is encoded once per wire
+// format rather than once per client.
+//
+// # Health Checks and Load Shedding
+//
+// /health runs every component check concurrently and reports each one's
+// status, latency and details. Besides the core components, deep probes
+// write and delete a document in the persistence store, measure the event
+// queue (handlers still running), look up a probe session in a shared
+// session store, time the PCG manager and list circuit breaker states. A
+// slow PCG manager, an open breaker or a backed-up event queue degrades
+// the server; a failed check makes it unhealthy.
+//
+// The checks also run every HEALTH_CHECK_INTERVAL. While the server is
+// degraded or unhealthy it rejects LOAD_SHED_DEGRADED_RATIO or
+// LOAD_SHED_UNHEALTHY_RATIO of game requests with 503 Service Unavailable
+// and a Retry-After header; the health, metrics and profiling endpoints
+// are never shed.
+//
+// # Tracing
+//
+// TRACING_EXPORTER enables OpenTelemetry spans, exported to stdout or an
+// OTLP/HTTP collector. Every RPC call runs in an "rpc.<method>" span,
+// continuing any W3C traceparent sent with HTTP requests. Level generation
+// phases, persistence writes and circuit breaker executions get spans of
+// their own. Log lines written for a traced call carry the otel_trace_id
+// and span_id fields next to the request's trace_id.
+//
+// # Request Auditing
+//
+// Every RPC call has a correlation ID: the X-Request-ID of an HTTP request,
+// echoed in the response header, or a new ID for each WebSocket call, sent
+// back as correlation_id in the response. Log lines written for the call
+// carry it in the correlation_id field. With REQUEST_AUDIT_ENABLED, a
+// sample of calls, REQUEST_AUDIT_SAMPLE_RATE or the method's rate in
+// REQUEST_AUDIT_METHOD_RATES, plus every failed call, is recorded with its
+// parameters, result and duration in a ring buffer of
+// REQUEST_AUDIT_BUFFER_SIZE records that admin.listRequests reads.
+// Credentials such as admin and resume tokens are always redacted; session
+// IDs are replaced with pseudonyms and player names removed unless disabled.
 //
 // # Thread Safety
 //
@@ -0,0 +1,331 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// placeLevelDoors places the doors and keys planned for a level that has
+// just been added to the world. Failures are logged; the level stays in
+// the world without the doors and keys that could not be placed.
+func (s *RPCServer) placeLevelDoors(level *game.Level) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "placeLevelDoors",
+		"level_id": level.ID,
+	})
+
+	doors, _ := level.Properties["doors"].([]pcg.DoorSite)
+	keys, _ := level.Properties["keys"].([]pcg.KeySite)
+	if len(doors) == 0 {
+		return
+	}
+
+	world := s.state.WorldState
+	levelIndex := worldLevelIndex(world, level.ID)
+
+	names := make(map[string]string, len(doors))
+	for _, site := range doors {
+		pos := game.Position{X: site.Position.X, Y: site.Position.Y, Level: levelIndex}
+		door := &game.Door{
+			ID:       site.DoorID,
+			Name:     "Door",
+			Position: pos,
+			State:    site.State,
+			LockDC:   site.LockDC,
+			KeyID:    site.KeyID,
+		}
+		if site.State == game.DoorLocked {
+			door.Name = "Locked Door"
+		}
+		names[site.DoorID] = door.Name
+		if err := world.AddObject(door); err != nil {
+			logger.WithError(err).WithField("door_id", door.ID).Warn("failed to place door")
+		}
+	}
+	for _, site := range keys {
+		pos := game.Position{X: site.Position.X, Y: site.Position.Y, Level: levelIndex}
+		if err := world.AddObject(game.NewKeyObject(site.KeyID, names[site.DoorID], pos)); err != nil {
+			logger.WithError(err).WithField("key_id", site.KeyID).Warn("failed to place key")
+		}
+	}
+	logger.WithFields(logrus.Fields{
+		"doors": len(doors),
+		"keys":  len(keys),
+	}).Info("level doors placed")
+}
+
+// worldLevelIndex returns the index of the level with levelID in the
+// world, or -1 if it has none
+func worldLevelIndex(world *game.World, levelID string) int {
+	index := -1
+	for i := range world.Levels {
+		if world.Levels[i].ID == levelID {
+			index = i
+		}
+	}
+	return index
+}
+
+// doorAt returns the door standing at pos, ignoring facing
+func (s *RPCServer) doorAt(pos game.Position) *game.Door {
+	pos.Facing = 0
+	for _, obj := range s.state.WorldState.GetObjectsAt(pos) {
+		if door, ok := obj.(*game.Door); ok {
+			return door
+		}
+	}
+	return nil
+}
+
+// pickUpKeys moves the keys lying at pos into the player's inventory and
+// out of the world, returning the keys picked up.
+func (s *RPCServer) pickUpKeys(player *game.Player, pos game.Position) []game.Item {
+	pos.Facing = 0
+	world := s.state.WorldState
+
+	var keys []game.Item
+	for _, obj := range world.GetObjectsAt(pos) {
+		key, ok := obj.(*game.KeyObject)
+		if !ok {
+			continue
+		}
+		item := key.Item
+		if err := player.AddItemToInventory(item); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "pickUpKeys",
+				"key_id":   item.ID,
+				"error":    err.Error(),
+			}).Warn("failed to pick up key")
+			continue
+		}
+		if err := world.RemoveObject(item.ID); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "pickUpKeys",
+				"key_id":   item.ID,
+				"error":    err.Error(),
+			}).Warn("failed to remove picked up key from the world")
+		}
+
+		keys = append(keys, item)
+		s.eventSys.Emit(game.GameEvent{
+			Type:      game.EventItemPickup,
+			SourceID:  player.GetID(),
+			TargetID:  item.ID,
+			Data:      map[string]interface{}{"item": item},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+	return keys
+}
+
+// doorRequest holds the parameters of openDoor and pickLock
+type doorRequest struct {
+	SessionID string         `json:"session_id"`
+	Direction game.Direction `json:"direction"`
+	Close     bool           `json:"close"`
+}
+
+// sessionDoor resolves the session of a door request and the door on the
+// tile next to its player in the requested direction
+func (s *RPCServer) sessionDoor(req doorRequest) (*PlayerSession, *game.Door, error) {
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pos := calculateNewPositionUnchecked(session.Player.GetPosition(), req.Direction)
+	door := s.doorAt(pos)
+	if door == nil {
+		return nil, nil, ErrDoorNotFound.WithData(map[string]interface{}{"position": pos})
+	}
+	return session, door, nil
+}
+
+// checkActionPoints checks that a player in combat has the turn and
+// action points for an action costing cost, such as using a door
+func (s *RPCServer) checkActionPoints(player *game.Player, action string, cost int) error {
+	if !s.state.TurnManager.IsInCombat {
+		return nil
+	}
+	if !s.state.TurnManager.IsCurrentTurn(player.GetID()) {
+		return ErrNotYourTurn
+	}
+	if player.GetActionPoints() < cost {
+		return insufficientAPError(action, cost, player.GetActionPoints())
+	}
+	return nil
+}
+
+// spendActionPoints consumes the action points of an action taken in
+// combat
+func (s *RPCServer) spendActionPoints(player *game.Player, cost int) {
+	if s.state.TurnManager.IsInCombat {
+		player.ConsumeActionPoints(cost)
+		s.pushActionPoints(player)
+	}
+}
+
+// emitDoorChanged broadcasts a door's new state
+func (s *RPCServer) emitDoorChanged(door *game.Door, actorID, change string) {
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventDoorChanged,
+		SourceID: actorID,
+		TargetID: door.GetID(),
+		Data: map[string]interface{}{
+			"door_id":  door.GetID(),
+			"position": door.GetPosition(),
+			"state":    door.GetState(),
+			"change":   change,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleOpenDoor opens, or with close set closes, the door next to the
+// session's player. A locked door opens only for a player carrying its
+// key. Trying to open a wall that hides a secret door searches it
+// instead, and a successful search reveals the door closed.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - direction: number - The direction of the door from the player
+//   - close: bool - Close the door instead of opening it
+//
+// Returns:
+//   - interface{}: Map containing the door's new state, and the search
+//     check when a secret door was searched for
+//   - error: Invalid parameters or session, ErrDoorNotFound if there is no
+//     door there, ErrDoorLocked if the player lacks its key
+func (s *RPCServer) handleOpenDoor(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleOpenDoor",
+	})
+	logger.Debug("entering handleOpenDoor")
+
+	var req doorRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid door parameters", err.Error())
+	}
+
+	session, door, err := s.sessionDoor(req)
+	if err != nil {
+		return nil, err
+	}
+	player := session.Player
+	if err := s.checkActionPoints(player, "opening a door", game.ActionCostOpenDoor); err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"door_id": door.GetID(),
+	}
+	switch {
+	case door.GetState() == game.DoorSecret:
+		check, err := door.AttemptSearch(&player.Character, game.GlobalDiceRoller)
+		if err != nil {
+			return nil, ErrDoorNotFound.WithMessage("%v", err)
+		}
+		if !check.Success {
+			// A failed search finds nothing, so it reveals no door either
+			s.spendActionPoints(player, game.ActionCostOpenDoor)
+			return nil, ErrDoorNotFound.WithData(map[string]interface{}{"position": door.GetPosition()})
+		}
+		response["found"] = true
+		response["check"] = check
+		s.emitDoorChanged(door, player.GetID(), "found")
+	case req.Close:
+		if err := door.Close(); err != nil {
+			return nil, ErrDoorLocked.WithMessage("%v", err)
+		}
+		s.emitDoorChanged(door, player.GetID(), "closed")
+	default:
+		usedKey, err := door.Open(&player.Character)
+		if err != nil {
+			return nil, ErrDoorLocked.WithMessage("%v", err).WithData(map[string]interface{}{"door_id": door.GetID()})
+		}
+		response["used_key"] = usedKey
+		s.emitDoorChanged(door, player.GetID(), "opened")
+	}
+	s.spendActionPoints(player, game.ActionCostOpenDoor)
+
+	logger.WithFields(logrus.Fields{
+		"player_id": player.GetID(),
+		"door_id":   door.GetID(),
+		"state":     door.GetState(),
+	}).Info("door used")
+
+	response["state"] = door.GetState()
+	return response, nil
+}
+
+// handlePickLock tries to pick the lock of the door next to the session's
+// player. A success unlocks the door, leaving it closed; failing badly
+// jams the lock so only its key will open it.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - direction: number - The direction of the door from the player
+//
+// Returns:
+//   - interface{}: Map containing the check, the door's state and whether
+//     the lock jammed
+//   - error: Invalid parameters or session, ErrDoorNotFound if there is no
+//     door there, ErrDoorLocked if the door is not locked or its lock
+//     cannot be picked
+func (s *RPCServer) handlePickLock(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handlePickLock",
+	})
+	logger.Debug("entering handlePickLock")
+
+	var req doorRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid door parameters", err.Error())
+	}
+
+	session, door, err := s.sessionDoor(req)
+	if err != nil {
+		return nil, err
+	}
+	player := session.Player
+	if door.GetState() == game.DoorSecret {
+		return nil, ErrDoorNotFound.WithData(map[string]interface{}{"position": door.GetPosition()})
+	}
+	if err := s.checkActionPoints(player, "picking a lock", game.ActionCostPickLock); err != nil {
+		return nil, err
+	}
+
+	check, err := door.AttemptPickLock(&player.Character, game.GlobalDiceRoller)
+	if err != nil {
+		return nil, ErrDoorLocked.WithMessage("%v", err).WithData(map[string]interface{}{"door_id": door.GetID()})
+	}
+	s.spendActionPoints(player, game.ActionCostPickLock)
+	if check.Success {
+		s.emitDoorChanged(door, player.GetID(), "unlocked")
+	}
+
+	logger.WithFields(logrus.Fields{
+		"player_id": player.GetID(),
+		"door_id":   door.GetID(),
+		"success":   check.Success,
+		"jammed":    door.IsJammed(),
+	}).Info("lock picking attempted")
+
+	return map[string]interface{}{
+		"success": true,
+		"door_id": door.GetID(),
+		"check":   check,
+		"state":   door.GetState(),
+		"jammed":  door.IsJammed(),
+	}, nil
+}
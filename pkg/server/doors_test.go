@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceLevelDoors(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	level := &game.Level{
+		ID: "vault",
+		Properties: map[string]interface{}{
+			"doors": []pcg.DoorSite{
+				{DoorID: "vault_door_0", Position: game.Position{X: 4, Y: 2}, State: game.DoorLocked, LockDC: 15, KeyID: "vault_door_0_key"},
+				{DoorID: "vault_door_1", Position: game.Position{X: 8, Y: 2}, State: game.DoorClosed},
+			},
+			"keys": []pcg.KeySite{
+				{KeyID: "vault_door_0_key", DoorID: "vault_door_0", RoomID: "room_0", Position: game.Position{X: 1, Y: 1}},
+			},
+		},
+	}
+
+	server.placeLevelDoors(level)
+
+	door := server.doorAt(game.Position{X: 4, Y: 2, Level: -1})
+	require.NotNil(t, door)
+	assert.Equal(t, game.DoorLocked, door.GetState())
+	assert.Equal(t, "vault_door_0_key", door.GetKeyID())
+	assert.NotNil(t, server.doorAt(game.Position{X: 8, Y: 2, Level: -1}))
+
+	key, exists := server.state.WorldState.GetObject("vault_door_0_key")
+	require.True(t, exists)
+	assert.Equal(t, "Key to the Locked Door", key.GetName())
+}
+
+func TestHandleOpenDoor(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	world := server.state.WorldState
+
+	door := &game.Door{ID: "door-1", Name: "Iron Door", Position: game.Position{X: 11, Y: 10}, State: game.DoorLocked, LockDC: 30, KeyID: "door-1_key"}
+	require.NoError(t, world.AddObject(door))
+	require.NoError(t, world.AddObject(game.NewKeyObject(door.KeyID, door.Name, game.Position{X: 10, Y: 11})))
+
+	open, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "direction": game.East})
+	_, err := server.handleOpenDoor(open)
+	assert.True(t, errors.Is(err, ErrDoorLocked), "the door is locked without its key")
+
+	move, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "direction": game.South})
+	result, err := server.handleMove(move)
+	require.NoError(t, err)
+	keys := result.(map[string]interface{})["keys"].([]game.Item)
+	require.Len(t, keys, 1)
+	assert.True(t, session.Player.HasItem(door.KeyID))
+	_, exists := world.GetObject(door.KeyID)
+	assert.False(t, exists, "the key leaves the world when picked up")
+
+	move, _ = json.Marshal(map[string]interface{}{"session_id": session.SessionID, "direction": game.North})
+	_, err = server.handleMove(move)
+	require.NoError(t, err)
+
+	result, err = server.handleOpenDoor(open)
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, true, response["used_key"])
+	assert.Equal(t, game.DoorOpen, response["state"])
+
+	closeDoor, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "direction": game.East, "close": true})
+	result, err = server.handleOpenDoor(closeDoor)
+	require.NoError(t, err)
+	assert.Equal(t, game.DoorClosed, result.(map[string]interface{})["state"])
+
+	north, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "direction": game.North})
+	_, err = server.handleOpenDoor(north)
+	assert.True(t, errors.Is(err, ErrDoorNotFound))
+}
+
+func TestHandlePickLock(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Skills = map[string]int{game.SkillOpenLocks: 20}
+
+	door := &game.Door{ID: "door-2", Name: "Oak Door", Position: game.Position{X: 9, Y: 10}, State: game.DoorLocked, LockDC: 12}
+	require.NoError(t, server.state.WorldState.AddObject(door))
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "direction": game.West})
+	result, err := server.handlePickLock(params)
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.True(t, response["check"].(game.DoorCheck).Success)
+	assert.Equal(t, game.DoorClosed, response["state"])
+	assert.Equal(t, false, response["jammed"])
+
+	_, err = server.handlePickLock(params)
+	assert.True(t, errors.Is(err, ErrDoorLocked), "an unlocked door has no lock to pick")
+}
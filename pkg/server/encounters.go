@@ -0,0 +1,530 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/items"
+	"goldbox-rpg/pkg/pcg/levels"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// encounterTag marks the NPCs spawned by a random encounter
+const encounterTag = "random_encounter"
+
+// encounterSpawnRadius is the farthest, in tiles, encounter monsters are
+// placed from the player who ran into them
+const encounterSpawnRadius = 3
+
+// EncounterEntry is one kind of encounter on an EncounterTable.
+type EncounterEntry struct {
+	Name     string   `json:"name"`                // Description, e.g. "orc patrol"
+	Kinds    []string `json:"kinds"`               // Bestiary kinds the group is drawn from
+	Weight   int      `json:"weight"`              // Relative likelihood among the entries that suit the party
+	MinLevel int      `json:"min_level,omitempty"` // Lowest party level, 0 for none
+	MaxLevel int      `json:"max_level,omitempty"` // Highest party level, 0 for none
+}
+
+// suits reports whether the entry can be rolled for a party of level.
+func (e EncounterEntry) suits(level int) bool {
+	return e.Weight > 0 && level >= e.MinLevel && (e.MaxLevel == 0 || level <= e.MaxLevel)
+}
+
+// EncounterTable lists the random encounters of a biome.
+type EncounterTable struct {
+	Chance  float64          `json:"chance"` // Probability of an encounter on each step
+	Entries []EncounterEntry `json:"entries"`
+}
+
+// defaultEncounterTables are the built-in encounter tables. Dangerous
+// places are checked more often; towns rarely have encounters at all.
+var defaultEncounterTables = map[pcg.BiomeType]EncounterTable{
+	pcg.BiomeDungeon: {Chance: 0.08, Entries: []EncounterEntry{
+		{Name: "vermin", Kinds: []string{"giant_rat"}, Weight: 4, MaxLevel: 3},
+		{Name: "orc patrol", Kinds: []string{"orc", "goblin"}, Weight: 4},
+		{Name: "restless dead", Kinds: []string{"skeleton", "zombie"}, Weight: 3, MinLevel: 2},
+		{Name: "haunting", Kinds: []string{"shadow", "wraith"}, Weight: 2, MinLevel: 5},
+	}},
+	pcg.BiomeCave: {Chance: 0.08, Entries: []EncounterEntry{
+		{Name: "goblin scouts", Kinds: []string{"goblin"}, Weight: 4},
+		{Name: "spider nest", Kinds: []string{"spider"}, Weight: 3, MinLevel: 2},
+		{Name: "troll lair", Kinds: []string{"troll"}, Weight: 1, MinLevel: 6},
+	}},
+	pcg.BiomeForest: {Chance: 0.05, Entries: []EncounterEntry{
+		{Name: "wolf pack", Kinds: []string{"wolf"}, Weight: 4},
+		{Name: "bandit ambush", Kinds: []string{"bandit"}, Weight: 3},
+		{Name: "giant spiders", Kinds: []string{"spider"}, Weight: 2, MinLevel: 2},
+		{Name: "angry bear", Kinds: []string{"bear"}, Weight: 1, MinLevel: 3},
+	}},
+	pcg.BiomeMountain: {Chance: 0.05, Entries: []EncounterEntry{
+		{Name: "orc raiders", Kinds: []string{"orc", "goblin"}, Weight: 4},
+		{Name: "hungry beasts", Kinds: []string{"wolf", "bear"}, Weight: 3},
+		{Name: "mountain troll", Kinds: []string{"troll"}, Weight: 1, MinLevel: 6},
+	}},
+	pcg.BiomePlains: {Chance: 0.04, Entries: []EncounterEntry{
+		{Name: "highwaymen", Kinds: []string{"bandit"}, Weight: 4},
+		{Name: "gnoll hunters", Kinds: []string{"gnoll"}, Weight: 3},
+		{Name: "wolf pack", Kinds: []string{"wolf"}, Weight: 2},
+	}},
+	pcg.BiomeDesert: {Chance: 0.04, Entries: []EncounterEntry{
+		{Name: "raiders", Kinds: []string{"bandit"}, Weight: 3},
+		{Name: "gnoll hunters", Kinds: []string{"gnoll"}, Weight: 3},
+		{Name: "giant scorpions", Kinds: []string{"giant_scorpion"}, Weight: 2, MinLevel: 3},
+	}},
+	pcg.BiomeSwamp: {Chance: 0.06, Entries: []EncounterEntry{
+		{Name: "lizard men", Kinds: []string{"lizard_man"}, Weight: 4},
+		{Name: "bog dead", Kinds: []string{"zombie", "giant_rat"}, Weight: 3},
+		{Name: "swamp troll", Kinds: []string{"troll"}, Weight: 1, MinLevel: 6},
+	}},
+	pcg.BiomeCoastal: {Chance: 0.04, Entries: []EncounterEntry{
+		{Name: "sahuagin raid", Kinds: []string{"sahuagin"}, Weight: 3},
+		{Name: "smugglers", Kinds: []string{"bandit"}, Weight: 3},
+		{Name: "lizard men", Kinds: []string{"lizard_man"}, Weight: 2},
+	}},
+	pcg.BiomeWasteland: {Chance: 0.06, Entries: []EncounterEntry{
+		{Name: "war band", Kinds: []string{"gnoll", "orc"}, Weight: 4},
+		{Name: "restless dead", Kinds: []string{"skeleton", "zombie"}, Weight: 3},
+		{Name: "wraiths", Kinds: []string{"wraith"}, Weight: 1, MinLevel: 5},
+	}},
+	pcg.BiomeUrban: {Chance: 0.02, Entries: []EncounterEntry{
+		{Name: "sewer rats", Kinds: []string{"giant_rat"}, Weight: 2, MaxLevel: 3},
+		{Name: "thugs", Kinds: []string{"bandit"}, Weight: 3},
+	}},
+}
+
+// EncounterProposal is a random encounter rolled for a player. The server
+// turns it into combat between the player's party and its monsters.
+type EncounterProposal struct {
+	ID         string                  `json:"encounter_id"`
+	SessionID  string                  `json:"session_id"`
+	PlayerID   string                  `json:"player_id"`
+	Name       string                  `json:"name"`
+	Biome      pcg.BiomeType           `json:"biome"`
+	Position   game.Position           `json:"position"`
+	PartyLevel int                     `json:"party_level"`
+	Seed       int64                   `json:"seed"`
+	Monsters   []*pcg.MonsterStatBlock `json:"monsters"`
+	Loot       [][]*game.Item          `json:"loot,omitempty"` // Items each monster carries, by monster index
+}
+
+// monsterSource generates the monsters of an encounter. The PCG manager
+// satisfies it and records the generation in its metrics.
+type monsterSource interface {
+	GenerateMonsters(ctx context.Context, params pcg.MonsterParams) ([]*pcg.MonsterStatBlock, error)
+}
+
+// RandomEncounterSystem rolls for random encounters as players explore.
+// Every step a player takes outside combat is checked against the
+// encounter table of the biome underfoot; a hit picks an entry suited to
+// the party's level and generates its monsters. The roll for each step is
+// derived from the seed manager, so the same seed and moves always produce
+// the same encounters. After an encounter a player is safe for the
+// cooldown number of steps.
+type RandomEncounterSystem struct {
+	mu       sync.Mutex
+	seeds    *pcg.SeedManager
+	monsters monsterSource
+	loot     *items.LootTableRegistry
+	tables   map[pcg.BiomeType]EncounterTable
+	cooldown int
+	steps    map[string]int // Steps each player has taken
+	lastHit  map[string]int // Step of each player's last encounter
+	biomeFor func(game.Position) pcg.BiomeType
+	modify   func(pcg.BiomeType, EncounterTable) EncounterTable
+}
+
+// NewRandomEncounterSystem creates an encounter system seeded by seeds,
+// using the built-in encounter tables. Positions are in a dungeon until a
+// biome resolver is set.
+func NewRandomEncounterSystem(seeds *pcg.SeedManager, cooldownSteps int) *RandomEncounterSystem {
+	tables := make(map[pcg.BiomeType]EncounterTable, len(defaultEncounterTables))
+	for biome, table := range defaultEncounterTables {
+		tables[biome] = table
+	}
+
+	monsterLogger := logrus.New()
+	monsterLogger.SetLevel(logrus.WarnLevel)
+
+	return &RandomEncounterSystem{
+		seeds:    seeds,
+		monsters: pcg.NewMonsterGenerator(monsterLogger),
+		tables:   tables,
+		cooldown: max(cooldownSteps, 0),
+		steps:    make(map[string]int),
+		lastHit:  make(map[string]int),
+	}
+}
+
+// SetTable replaces the encounter table of a biome. A table with zero
+// chance disables encounters there.
+func (es *RandomEncounterSystem) SetTable(biome pcg.BiomeType, table EncounterTable) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.tables[biome] = table
+}
+
+// SetLootTables makes encounter monsters carry items rolled from the
+// "combat" loot table, which they drop when they die. Passing nil sends
+// monsters out empty-handed.
+func (es *RandomEncounterSystem) SetLootTables(tables *items.LootTableRegistry) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.loot = tables
+}
+
+// SetBiomeResolver sets the function that maps world positions to biomes.
+func (es *RandomEncounterSystem) SetBiomeResolver(resolve func(game.Position) pcg.BiomeType) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.biomeFor = resolve
+}
+
+// SetTableModifier sets a function that adjusts a biome's encounter table
+// before each roll, such as world events adding their own encounters. It
+// must not change the table it is given.
+func (es *RandomEncounterSystem) SetTableModifier(modify func(pcg.BiomeType, EncounterTable) EncounterTable) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.modify = modify
+}
+
+// BiomeAt returns the biome at pos.
+func (es *RandomEncounterSystem) BiomeAt(pos game.Position) pcg.BiomeType {
+	es.mu.Lock()
+	resolve := es.biomeFor
+	es.mu.Unlock()
+
+	if resolve == nil {
+		return pcg.BiomeDungeon
+	}
+	return resolve(pos)
+}
+
+// Check counts a step by the player to pos and rolls for an encounter.
+//
+// Parameters:
+//   - playerID: The player who moved
+//   - pos: Where the player moved to
+//   - partyLevel: Average level of the player's party, which filters the
+//     table's entries and sets the monsters' difficulty
+//   - partySize: Number of players in the party, which scales the group
+//
+// Returns:
+//   - *EncounterProposal: The encounter, or nil if none occurred
+func (es *RandomEncounterSystem) Check(playerID string, pos game.Position, partyLevel, partySize int) *EncounterProposal {
+	biome := es.BiomeAt(pos)
+	partyLevel, partySize = max(partyLevel, 1), max(partySize, 1)
+
+	es.mu.Lock()
+	es.steps[playerID]++
+	step := es.steps[playerID]
+	if last, hit := es.lastHit[playerID]; hit && step-last <= es.cooldown {
+		es.mu.Unlock()
+		return nil
+	}
+	table := es.tables[biome]
+	if es.modify != nil {
+		table = es.modify(biome, table)
+	}
+
+	seed := es.seeds.DeriveContextSeed(pcg.ContentTypeMonsters, fmt.Sprintf("encounter:%s:%d", playerID, step))
+	rng := pcg.NewRNG(seed, "encounters")
+	if rng.Float64() >= table.Chance {
+		es.mu.Unlock()
+		return nil
+	}
+	entry, ok := pickEncounterEntry(table.Entries, partyLevel, rng)
+	if !ok {
+		es.mu.Unlock()
+		return nil
+	}
+	es.lastHit[playerID] = step
+	loot := es.loot
+	es.mu.Unlock()
+
+	count := partySize + rng.Intn(partySize+1) + partyLevel/5
+	monsters, err := es.monsters.GenerateMonsters(context.Background(), pcg.MonsterParams{
+		GenerationParams: pcg.GenerationParams{Seed: seed, Difficulty: partyLevel, PlayerLevel: partyLevel},
+		Biome:            biome,
+		Count:            count,
+		Kinds:            entry.Kinds,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "Check",
+			"playerID":  playerID,
+			"biome":     biome,
+			"encounter": entry.Name,
+			"error":     err.Error(),
+		}).Warn("failed to generate encounter monsters")
+		return nil
+	}
+
+	return &EncounterProposal{
+		ID:         uuid.New().String(),
+		PlayerID:   playerID,
+		Name:       entry.Name,
+		Biome:      biome,
+		Position:   pos,
+		PartyLevel: partyLevel,
+		Seed:       seed,
+		Monsters:   monsters,
+		Loot:       rollEncounterLoot(loot, len(monsters), partyLevel, rng),
+	}
+}
+
+// rollEncounterLoot rolls the combat loot table once for each of count
+// monsters. It returns nil when no combat table is loaded; a failed roll
+// leaves that monster without items.
+func rollEncounterLoot(tables *items.LootTableRegistry, count, level int, rng *rand.Rand) [][]*game.Item {
+	if tables == nil {
+		return nil
+	}
+	if _, exists := tables.GetTable(levels.CombatLootTable); !exists {
+		return nil
+	}
+
+	loot := make([][]*game.Item, count)
+	for i := range loot {
+		drops, err := tables.ResolveLootTable(context.Background(), levels.CombatLootTable, level, rng)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "rollEncounterLoot",
+				"error":    err.Error(),
+			}).Warn("failed to roll encounter loot")
+			continue
+		}
+		loot[i] = drops
+	}
+	return loot
+}
+
+// Forget drops the step count and cooldown of a player who left.
+func (es *RandomEncounterSystem) Forget(playerID string) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	delete(es.steps, playerID)
+	delete(es.lastHit, playerID)
+}
+
+// pickEncounterEntry rolls a weighted entry among those suiting level.
+func pickEncounterEntry(entries []EncounterEntry, level int, rng *rand.Rand) (EncounterEntry, bool) {
+	total := 0
+	for _, entry := range entries {
+		if entry.suits(level) {
+			total += entry.Weight
+		}
+	}
+	if total == 0 {
+		return EncounterEntry{}, false
+	}
+
+	roll := rng.Intn(total)
+	for _, entry := range entries {
+		if !entry.suits(level) {
+			continue
+		}
+		if roll < entry.Weight {
+			return entry, true
+		}
+		roll -= entry.Weight
+	}
+	return EncounterEntry{}, false
+}
+
+// attachEncounters creates the random encounter system when enabled and
+// starts combat for the encounters it proposes. Like weather, it uses its
+// own seed manager derived from the PCG base seed.
+func (s *RPCServer) attachEncounters() {
+	if s.config == nil || !s.config.RandomEncountersEnabled {
+		return
+	}
+
+	var baseSeed int64
+	if s.pcgManager != nil {
+		baseSeed = s.pcgManager.GetSeedManager().GetBaseSeed()
+	}
+	s.encounters = NewRandomEncounterSystem(pcg.NewSeedManager(baseSeed), s.config.EncounterCooldownSteps)
+	if s.pcgManager != nil {
+		s.encounters.monsters = s.pcgManager
+	}
+	s.encounters.SetLootTables(s.lootTables)
+
+	s.eventSys.Subscribe(EventEncounterProposed, func(event game.GameEvent) {
+		if proposal, ok := event.Data["proposal"].(*EncounterProposal); ok {
+			s.startEncounter(proposal)
+		}
+	})
+}
+
+// checkRandomEncounter rolls for an encounter after a player moved outside
+// combat and emits a proposal if one occurred.
+func (s *RPCServer) checkRandomEncounter(session *PlayerSession, pos game.Position) *EncounterProposal {
+	if s.encounters == nil || s.state.TurnManager.IsInCombat {
+		return nil
+	}
+
+	party := s.encounterParty(session.Player)
+	level := 0
+	for _, player := range party {
+		level += player.GetLevel()
+	}
+
+	proposal := s.encounters.Check(session.Player.GetID(), pos, level/len(party), len(party))
+	if proposal == nil {
+		return nil
+	}
+	proposal.SessionID = session.SessionID
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "checkRandomEncounter",
+		"playerID":  proposal.PlayerID,
+		"encounter": proposal.Name,
+		"biome":     proposal.Biome,
+		"monsters":  len(proposal.Monsters),
+	}).Info("random encounter")
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventEncounterProposed,
+		SourceID: proposal.PlayerID,
+		Data: map[string]interface{}{
+			"proposal": proposal,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+	return proposal
+}
+
+// encounterParty returns the connected players exploring with player: its
+// party, or the player alone.
+func (s *RPCServer) encounterParty(player *game.Player) map[string]*game.Player {
+	players := map[string]*game.Player{player.GetID(): player}
+	if s.parties == nil {
+		return players
+	}
+	if party, exists := s.parties.PartyOf(player.GetID()); exists {
+		for id, member := range s.partyPlayers(party) {
+			players[id] = member
+		}
+	}
+	return players
+}
+
+// startEncounter places a proposal's monsters around the player and starts
+// combat between them and the player's party. The monsters are removed
+// again if combat cannot start, for example because another began first.
+func (s *RPCServer) startEncounter(proposal *EncounterProposal) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function":    "startEncounter",
+		"encounterID": proposal.ID,
+		"playerID":    proposal.PlayerID,
+	})
+
+	session, err := s.getSessionSafely(proposal.SessionID)
+	if err != nil {
+		logger.Warn("encounter player has left")
+		return
+	}
+	defer s.releaseSession(session)
+
+	var participants []string
+	for id := range s.encounterParty(session.Player) {
+		participants = append(participants, id)
+	}
+
+	world := s.state.WorldState
+	spots := s.encounterSpawnPoints(session.Player.GetPosition(), len(proposal.Monsters))
+	var spawned []string
+	for i, spot := range spots {
+		npc := encounterNPC(proposal.Monsters[i], spot)
+		if i < len(proposal.Loot) {
+			for _, item := range proposal.Loot[i] {
+				npc.Inventory = append(npc.Inventory, *item)
+			}
+		}
+		if err := world.AddObject(npc); err != nil {
+			logger.WithError(err).Warn("failed to place encounter monster")
+			continue
+		}
+		spawned = append(spawned, npc.GetID())
+	}
+	if len(spawned) == 0 {
+		logger.Warn("no room to place encounter monsters")
+		return
+	}
+
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id":      proposal.SessionID,
+		"participant_ids": append(participants, spawned...),
+		"seed":            proposal.Seed,
+	})
+	if err == nil {
+		_, err = s.handleStartCombat(params)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("encounter did not start combat")
+		for _, id := range spawned {
+			_ = world.RemoveObject(id)
+		}
+	}
+}
+
+// encounterSpawnPoints returns up to count free positions around center,
+// nearest first.
+func (s *RPCServer) encounterSpawnPoints(center game.Position, count int) []game.Position {
+	world := s.state.WorldState
+	var spots []game.Position
+	for radius := 1; radius <= encounterSpawnRadius && len(spots) < count; radius++ {
+		for dy := -radius; dy <= radius && len(spots) < count; dy++ {
+			for dx := -radius; dx <= radius && len(spots) < count; dx++ {
+				if dx != -radius && dx != radius && dy != -radius && dy != radius {
+					continue // Inner rings were already visited
+				}
+				pos := game.Position{X: center.X + dx, Y: center.Y + dy, Level: center.Level}
+				if pos.X < 0 || pos.Y < 0 || pos.X >= world.Width || pos.Y >= world.Height {
+					continue
+				}
+				if len(world.GetObjectsAt(pos)) == 0 {
+					spots = append(spots, pos)
+				}
+			}
+		}
+	}
+	return spots
+}
+
+// encounterNPC turns a generated monster into a hostile NPC at pos.
+func encounterNPC(monster *pcg.MonsterStatBlock, pos game.Position) *game.NPC {
+	npc := &game.NPC{
+		Character: game.Character{
+			ID:           monster.ID,
+			Name:         monster.Name,
+			Position:     pos,
+			HP:           monster.HitPoints,
+			MaxHP:        monster.HitPoints,
+			ArmorClass:   monster.ArmorClass,
+			THAC0:        monster.THAC0,
+			Level:        monster.HitDice,
+			Strength:     10,
+			Dexterity:    10,
+			Constitution: 10,
+			Intelligence: 10,
+			Wisdom:       10,
+			Charisma:     10,
+			Equipment:    make(map[game.EquipmentSlot]game.Item),
+		},
+		Behavior: "aggressive",
+		Faction:  "monsters",
+	}
+	npc.AddTag("hostile")
+	npc.AddTag(encounterTag)
+	return npc
+}
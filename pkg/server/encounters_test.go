@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// alwaysEncounter is a table that produces an orc encounter on every step
+var alwaysEncounter = EncounterTable{Chance: 1, Entries: []EncounterEntry{
+	{Name: "orc patrol", Kinds: []string{"orc"}, Weight: 1},
+}}
+
+func TestRandomEncounterSystem_CooldownAndDeterminism(t *testing.T) {
+	encounters := NewRandomEncounterSystem(pcg.NewSeedManager(42), 3)
+	encounters.SetTable(pcg.BiomeDungeon, alwaysEncounter)
+	pos := game.Position{X: 5, Y: 5}
+
+	first := encounters.Check("player", pos, 2, 1)
+	require.NotNil(t, first)
+	assert.Equal(t, "orc patrol", first.Name)
+	assert.Equal(t, pcg.BiomeDungeon, first.Biome)
+	require.NotEmpty(t, first.Monsters)
+	for _, monster := range first.Monsters {
+		assert.Equal(t, "orc", monster.Kind)
+	}
+
+	for step := 0; step < 3; step++ {
+		assert.Nil(t, encounters.Check("player", pos, 2, 1), "cooling down")
+	}
+	assert.NotNil(t, encounters.Check("player", pos, 2, 1))
+	assert.NotNil(t, encounters.Check("other", pos, 2, 1), "cooldowns are per player")
+
+	// The same seed and steps roll the same encounter
+	again := NewRandomEncounterSystem(pcg.NewSeedManager(42), 3)
+	again.SetTable(pcg.BiomeDungeon, alwaysEncounter)
+	replayed := again.Check("player", pos, 2, 1)
+	require.NotNil(t, replayed)
+	assert.Equal(t, first.Seed, replayed.Seed)
+	assert.Equal(t, first.Monsters, replayed.Monsters)
+
+	// Forgetting a player resets their steps and cooldown
+	again.Forget("player")
+	assert.NotNil(t, again.Check("player", pos, 2, 1))
+}
+
+func TestRandomEncounterSystem_TablesByBiomeAndLevel(t *testing.T) {
+	encounters := NewRandomEncounterSystem(pcg.NewSeedManager(7), 0)
+	encounters.SetBiomeResolver(func(pos game.Position) pcg.BiomeType {
+		if pos.X < 10 {
+			return pcg.BiomeUrban
+		}
+		return pcg.BiomeForest
+	})
+	encounters.SetTable(pcg.BiomeUrban, EncounterTable{})
+	encounters.SetTable(pcg.BiomeForest, EncounterTable{Chance: 1, Entries: []EncounterEntry{
+		{Name: "wolf pack", Kinds: []string{"wolf"}, Weight: 1, MaxLevel: 4},
+		{Name: "angry bear", Kinds: []string{"bear"}, Weight: 1, MinLevel: 5},
+	}})
+
+	assert.Nil(t, encounters.Check("player", game.Position{X: 2}, 1, 1), "no encounters in town")
+	for level, name := range map[int]string{1: "wolf pack", 8: "angry bear"} {
+		proposal := encounters.Check("player", game.Position{X: 12}, level, 1)
+		require.NotNil(t, proposal)
+		assert.Equal(t, pcg.BiomeForest, proposal.Biome)
+		assert.Equal(t, name, proposal.Name)
+	}
+
+	_, ok := pickEncounterEntry([]EncounterEntry{{Weight: 1, MinLevel: 10}}, 1, rand.New(rand.NewSource(1)))
+	assert.False(t, ok)
+}
+
+func TestRandomEncounter_StartsCombatOnMove(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.config.RandomEncountersEnabled = true
+	server.config.EncounterCooldownSteps = 100
+	server.attachEncounters()
+	server.encounters.SetTable(pcg.BiomeDungeon, alwaysEncounter)
+	session := createTestSessionForHandlers(t, server)
+	server.state.WorldState.Width, server.state.WorldState.Height = 30, 30
+	started := make(chan game.GameEvent, 1)
+	server.eventSys.Subscribe(EventCombatStart, func(event game.GameEvent) { started <- event })
+
+	params, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "direction": game.East})
+	require.NoError(t, err)
+	result, err := server.handleMove(params)
+	require.NoError(t, err)
+	proposal, ok := result.(map[string]interface{})["encounter"].(*EncounterProposal)
+	require.True(t, ok, "the move reports the encounter")
+	assert.Equal(t, session.SessionID, proposal.SessionID)
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the encounter did not start combat")
+	}
+
+	require.True(t, server.state.TurnManager.IsInCombat)
+	participants := server.state.TurnManager.Initiative
+	assert.Contains(t, participants, session.Player.GetID())
+	require.Len(t, proposal.Loot, len(proposal.Monsters), "monsters roll the combat loot table")
+	for i, monster := range proposal.Monsters {
+		obj, exists := server.state.WorldState.Objects[monster.ID]
+		require.True(t, exists, "monster %s was placed", monster.ID)
+		npc := obj.(*game.NPC)
+		assert.True(t, npc.HasTag("hostile"))
+		assert.Len(t, npc.Inventory, len(proposal.Loot[i]), "monsters carry their loot")
+		assert.LessOrEqual(t, distance(npc.GetPosition(), session.Player.GetPosition()), encounterSpawnRadius)
+		assert.Contains(t, participants, monster.ID)
+	}
+
+	// Moves during the fight do not roll more encounters
+	assert.Nil(t, server.checkRandomEncounter(session, session.Player.GetPosition()))
+}
+
+// distance returns the number of king moves between two positions
+func distance(a, b game.Position) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return max(dx, -dx, dy, -dy)
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"goldbox-rpg/pkg/game"
+)
+
+// Game error codes. They sit in the JSON-RPC range reserved for
+// implementation-defined server errors, grouped by subsystem, so clients
+// can tell game rule failures apart without parsing messages.
+const (
+	// Sessions
+	ErrCodeInvalidSession      = -32001
+	ErrCodeNoPlayer            = -32002
+	ErrCodeIdempotencyConflict = -32003
+
+	// Combat and turns
+	ErrCodeNotInCombat       = -32010
+	ErrCodeNotYourTurn       = -32011
+	ErrCodeCombatInProgress  = -32012
+	ErrCodeInsufficientAP    = -32013
+	ErrCodeInvalidTarget     = -32014
+	ErrCodeNoActionAvailable = -32015
+	ErrCodeReactionNotFound  = -32016
+	ErrCodeCombatLogNotFound = -32017
+	ErrCodeReplayNotFound    = -32018
+
+	// Spells
+	ErrCodeSpellNotFound         = -32020
+	ErrCodeSpellUnknown          = -32021
+	ErrCodeSpellRequirements     = -32022
+	ErrCodeSpellComponentBlocked = -32023
+	ErrCodeSpellMaterialsMissing = -32024
+
+	// Items, equipment and trade
+	ErrCodeItemNotFound     = -32030
+	ErrCodeInvalidSlot      = -32031
+	ErrCodeMerchantNotFound = -32032
+	ErrCodeTradeRejected    = -32033
+
+	// Quests
+	ErrCodeQuestNotFound = -32040
+	ErrCodeQuestRejected = -32041
+
+	// Parties
+	ErrCodePartyNotFound     = -32050
+	ErrCodePartyRejected     = -32051
+	ErrCodeCompanionNotFound = -32052
+	ErrCodeCompanionRejected = -32053
+
+	// Content generation
+	ErrCodeGenerationFailed = -32060
+	ErrCodeContentInvalid   = -32061
+	ErrCodeUnavailable      = -32062
+	ErrCodeJobNotFound      = -32063
+	ErrCodeFeedbackRejected = -32064
+
+	// Characters
+	ErrCodeClassChangeDenied = -32070
+	ErrCodeLevelUpDenied     = -32071
+	ErrCodeDoorNotFound      = -32072
+	ErrCodeDoorLocked        = -32073
+	ErrCodeFeatureNotFound   = -32074
+	ErrCodeInteractionFailed = -32075
+	ErrCodeNPCNotFound       = -32076
+	ErrCodeDialogUnavailable = -32077
+
+	// Administration
+	ErrCodeNothingToUndo = -32080
+	ErrCodeUndoConflict  = -32081
+
+	// Admin console
+	ErrCodeAdminUnauthorized = -32090
+	ErrCodeAdminForbidden    = -32091
+)
+
+// Error catalog. Each entry is a JSON-RPC error with a stable code and a
+// "reason" string in its data; handlers return an entry as is, or derive
+// one with WithMessage and WithData to add details. Callers can match an
+// entry, even when wrapped, with errors.Is.
+var (
+	ErrInvalidSession      = newCatalogError(ErrCodeInvalidSession, "invalid_session", "invalid session")
+	ErrNoPlayer            = newCatalogError(ErrCodeNoPlayer, "no_player", "session has no associated player")
+	ErrIdempotencyConflict = newCatalogError(ErrCodeIdempotencyConflict, "idempotency_conflict", "idempotency key reused for a different call")
+
+	ErrNotInCombat       = newCatalogError(ErrCodeNotInCombat, "not_in_combat", "not in combat")
+	ErrNotYourTurn       = newCatalogError(ErrCodeNotYourTurn, "not_your_turn", "not your turn")
+	ErrCombatInProgress  = newCatalogError(ErrCodeCombatInProgress, "combat_in_progress", "combat already in progress")
+	ErrInsufficientAP    = newCatalogError(ErrCodeInsufficientAP, "insufficient_action_points", "insufficient action points")
+	ErrInvalidTarget     = newCatalogError(ErrCodeInvalidTarget, "invalid_target", "invalid target")
+	ErrNoActionAvailable = newCatalogError(ErrCodeNoActionAvailable, "no_action_available", "action is not available")
+	ErrReactionNotFound  = newCatalogError(ErrCodeReactionNotFound, "reaction_not_found", "reaction not found")
+	ErrCombatLogNotFound = newCatalogError(ErrCodeCombatLogNotFound, "combat_log_not_found", "combat log not found")
+	ErrReplayNotFound    = newCatalogError(ErrCodeReplayNotFound, "replay_not_found", "combat replay not found")
+
+	ErrSpellNotFound         = newCatalogError(ErrCodeSpellNotFound, "spell_not_found", "spell not found")
+	ErrSpellUnknown          = newCatalogError(ErrCodeSpellUnknown, "spell_unknown", "spell not known")
+	ErrSpellRequirements     = newCatalogError(ErrCodeSpellRequirements, "spell_requirements", "spell requirements not met")
+	ErrSpellComponentBlocked = newCatalogError(ErrCodeSpellComponentBlocked, "spell_component_blocked", "spell component cannot be provided")
+	ErrSpellMaterialsMissing = newCatalogError(ErrCodeSpellMaterialsMissing, "spell_materials_missing", "spell materials missing")
+
+	ErrItemNotFound     = newCatalogError(ErrCodeItemNotFound, "item_not_found", "item not found")
+	ErrInvalidSlot      = newCatalogError(ErrCodeInvalidSlot, "invalid_slot", "invalid equipment slot")
+	ErrMerchantNotFound = newCatalogError(ErrCodeMerchantNotFound, "merchant_not_found", "unknown merchant")
+	ErrTradeRejected    = newCatalogError(ErrCodeTradeRejected, "trade_rejected", "trade rejected")
+
+	ErrQuestNotFound = newCatalogError(ErrCodeQuestNotFound, "quest_not_found", "quest not found")
+	ErrQuestRejected = newCatalogError(ErrCodeQuestRejected, "quest_rejected", "quest update rejected")
+
+	ErrPartyNotFound     = newCatalogError(ErrCodePartyNotFound, "party_not_found", "party not found")
+	ErrPartyRejected     = newCatalogError(ErrCodePartyRejected, "party_rejected", "party request rejected")
+	ErrCompanionNotFound = newCatalogError(ErrCodeCompanionNotFound, "companion_not_found", "companion not found")
+	ErrCompanionRejected = newCatalogError(ErrCodeCompanionRejected, "companion_rejected", "companion request rejected")
+
+	ErrGenerationFailed = newCatalogError(ErrCodeGenerationFailed, "generation_failed", "content generation failed")
+	ErrContentInvalid   = newCatalogError(ErrCodeContentInvalid, "content_invalid", "content failed validation")
+	ErrUnavailable      = newCatalogError(ErrCodeUnavailable, "unavailable", "service not available")
+	ErrJobNotFound      = newCatalogError(ErrCodeJobNotFound, "job_not_found", "generation job not found")
+	ErrFeedbackRejected = newCatalogError(ErrCodeFeedbackRejected, "feedback_rejected", "content feedback rejected")
+
+	ErrClassChangeDenied = newCatalogError(ErrCodeClassChangeDenied, "class_change_denied", "class change not allowed")
+	ErrLevelUpDenied     = newCatalogError(ErrCodeLevelUpDenied, "level_up_denied", "level up not allowed")
+	ErrDoorNotFound      = newCatalogError(ErrCodeDoorNotFound, "door_not_found", "no door there")
+	ErrDoorLocked        = newCatalogError(ErrCodeDoorLocked, "door_locked", "door cannot be used")
+	ErrFeatureNotFound   = newCatalogError(ErrCodeFeatureNotFound, "feature_not_found", "no feature within reach")
+	ErrInteractionFailed = newCatalogError(ErrCodeInteractionFailed, "interaction_failed", "interaction cannot be performed")
+	ErrNPCNotFound       = newCatalogError(ErrCodeNPCNotFound, "npc_not_found", "no NPC within reach")
+	ErrDialogUnavailable = newCatalogError(ErrCodeDialogUnavailable, "dialog_unavailable", "NPC will not say that")
+
+	ErrNothingToUndo = newCatalogError(ErrCodeNothingToUndo, "nothing_to_undo", "no action to undo")
+	ErrUndoConflict  = newCatalogError(ErrCodeUndoConflict, "undo_conflict", "action can no longer be undone")
+
+	ErrAdminUnauthorized = newCatalogError(ErrCodeAdminUnauthorized, "admin_unauthorized", "invalid admin token")
+	ErrAdminForbidden    = newCatalogError(ErrCodeAdminForbidden, "admin_forbidden", "admin permission denied")
+)
+
+// errorCatalog lists the catalog entries, in code order
+var errorCatalog = []*JSONRPCError{
+	ErrInvalidSession, ErrNoPlayer, ErrIdempotencyConflict,
+	ErrNotInCombat, ErrNotYourTurn, ErrCombatInProgress, ErrInsufficientAP, ErrInvalidTarget, ErrNoActionAvailable, ErrReactionNotFound,
+	ErrCombatLogNotFound, ErrReplayNotFound,
+	ErrSpellNotFound, ErrSpellUnknown, ErrSpellRequirements, ErrSpellComponentBlocked, ErrSpellMaterialsMissing,
+	ErrItemNotFound, ErrInvalidSlot, ErrMerchantNotFound, ErrTradeRejected,
+	ErrQuestNotFound, ErrQuestRejected,
+	ErrPartyNotFound, ErrPartyRejected, ErrCompanionNotFound, ErrCompanionRejected,
+	ErrGenerationFailed, ErrContentInvalid, ErrUnavailable, ErrJobNotFound, ErrFeedbackRejected,
+	ErrClassChangeDenied, ErrLevelUpDenied, ErrDoorNotFound, ErrDoorLocked,
+	ErrFeatureNotFound, ErrInteractionFailed, ErrNPCNotFound, ErrDialogUnavailable,
+	ErrNothingToUndo, ErrUndoConflict,
+	ErrAdminUnauthorized, ErrAdminForbidden,
+}
+
+// ErrorCatalog returns a copy of the catalogued game errors, in code order
+func ErrorCatalog() []*JSONRPCError {
+	catalog := make([]*JSONRPCError, len(errorCatalog))
+	for i, entry := range errorCatalog {
+		catalog[i] = entry.clone()
+	}
+	return catalog
+}
+
+// newCatalogError creates an error catalog entry
+func newCatalogError(code int, reason, message string) *JSONRPCError {
+	return NewJSONRPCError(code, message, map[string]interface{}{"reason": reason})
+}
+
+// Is reports whether target is a JSON-RPC error with the same code, so
+// errors derived from a catalog entry match the entry itself.
+func (e *JSONRPCError) Is(target error) bool {
+	t, ok := target.(*JSONRPCError)
+	return ok && t.Code == e.Code
+}
+
+// WithMessage returns a copy of the error with a formatted message.
+func (e *JSONRPCError) WithMessage(format string, args ...interface{}) *JSONRPCError {
+	derived := e.clone()
+	derived.Message = fmt.Sprintf(format, args...)
+	return derived
+}
+
+// WithData returns a copy of the error with fields added to its data. Data
+// that is not a map, such as a plain detail string, is kept under "detail".
+func (e *JSONRPCError) WithData(fields map[string]interface{}) *JSONRPCError {
+	derived := e.clone()
+	data := make(map[string]interface{}, len(fields)+1)
+	switch existing := e.Data.(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, v := range existing {
+			data[k] = v
+		}
+	default:
+		data["detail"] = existing
+	}
+	for k, v := range fields {
+		data[k] = v
+	}
+	derived.Data = data
+	return derived
+}
+
+// Wrap returns a copy of the error whose message adds err's message and
+// whose data records it as the cause.
+func (e *JSONRPCError) Wrap(err error) *JSONRPCError {
+	return e.WithMessage("%s: %v", e.Message, err).WithData(map[string]interface{}{"cause": err.Error()})
+}
+
+// clone copies the error, including a map of data
+func (e *JSONRPCError) clone() *JSONRPCError {
+	derived := *e
+	if data, ok := e.Data.(map[string]interface{}); ok {
+		derived.Data = make(map[string]interface{}, len(data))
+		for k, v := range data {
+			derived.Data.(map[string]interface{})[k] = v
+		}
+	}
+	return &derived
+}
+
+// insufficientAPError reports an action the player lacks the action points
+// for, with the required and available points as data
+func insufficientAPError(action string, required, available int) *JSONRPCError {
+	return ErrInsufficientAP.WithMessage("insufficient action points for %s (need %d, have %d)", action, required, available).
+		WithData(map[string]interface{}{"required": required, "available": available})
+}
+
+// questError reports a failed quest log operation. Errors that already
+// carry a JSON-RPC code keep it; otherwise a quest missing from the
+// player's log is ErrQuestNotFound and any other refusal ErrQuestRejected.
+func questError(player *game.Player, action, questID string, err error) error {
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	entry := ErrQuestRejected
+	if _, lookupErr := player.GetQuest(questID); lookupErr != nil {
+		entry = ErrQuestNotFound
+	}
+	return entry.WithMessage("failed to %s: %v", action, err).WithData(map[string]interface{}{"quest_id": questID})
+}
+
+// toJSONRPCError converts a handler error into the JSON-RPC error sent to
+// the client. An error that is or wraps a JSONRPCError keeps its code and
+// data; when wrapped, the full message including the added context is
+// sent. Any other error is reported with defaultCode.
+func toJSONRPCError(err error, defaultCode int) *JSONRPCError {
+	var rpcErr *JSONRPCError
+	if !errors.As(err, &rpcErr) {
+		return NewJSONRPCError(defaultCode, err.Error(), nil)
+	}
+	if rpcErr == err {
+		return rpcErr
+	}
+	return &JSONRPCError{Code: rpcErr.Code, Message: err.Error(), Data: rpcErr.Data}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCatalog_UniqueCodesAndReasons(t *testing.T) {
+	codes := make(map[int]string)
+	reasons := make(map[string]int)
+
+	for _, entry := range ErrorCatalog() {
+		reason := entry.Data.(map[string]interface{})["reason"].(string)
+		assert.NotContains(t, codes, entry.Code, "duplicate code for %s", reason)
+		assert.NotContains(t, reasons, reason, "duplicate reason %s", reason)
+		assert.True(t, entry.Code <= -32000 && entry.Code >= -32099, "code %d outside the server error range", entry.Code)
+		assert.NotEqual(t, JSONRPCRateLimited, entry.Code)
+		codes[entry.Code] = reason
+		reasons[reason] = entry.Code
+	}
+}
+
+func TestJSONRPCError_DerivedErrors(t *testing.T) {
+	derived := ErrInsufficientAP.WithMessage("need %d", 3).WithData(map[string]interface{}{"required": 3})
+
+	assert.Equal(t, "need 3", derived.Message)
+	assert.Equal(t, map[string]interface{}{"reason": "insufficient_action_points", "required": 3}, derived.Data)
+	assert.Equal(t, "insufficient action points", ErrInsufficientAP.Message, "catalog entries are not modified")
+	assert.Equal(t, map[string]interface{}{"reason": "insufficient_action_points"}, ErrInsufficientAP.Data)
+
+	wrapped := fmt.Errorf("move failed: %w", derived)
+	assert.ErrorIs(t, wrapped, ErrInsufficientAP)
+	assert.NotErrorIs(t, wrapped, ErrNotYourTurn)
+
+	rpcErr := toJSONRPCError(wrapped, JSONRPCInternalError)
+	assert.Equal(t, ErrCodeInsufficientAP, rpcErr.Code)
+	assert.Equal(t, "move failed: need 3", rpcErr.Message)
+	assert.Equal(t, derived.Data, rpcErr.Data)
+
+	plain := toJSONRPCError(errors.New("boom"), JSONRPCInternalError)
+	assert.Equal(t, JSONRPCInternalError, plain.Code)
+	assert.Nil(t, plain.Data)
+
+	detailed := NewJSONRPCError(JSONRPCInvalidParams, "bad", "detail text").WithData(map[string]interface{}{"field": "x"})
+	assert.Equal(t, map[string]interface{}{"detail": "detail text", "field": "x"}, detailed.Data)
+}
+
+func TestHandlers_ReturnCatalogErrors(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	tests := []struct {
+		name    string
+		handler func(json.RawMessage) (interface{}, error)
+		params  string
+		code    int
+	}{
+		{"unknown session", server.handleEndTurn, `{"session_id":"missing-session"}`, ErrCodeInvalidSession},
+		{"end turn outside combat", server.handleEndTurn, `{"session_id":"test-session-001"}`, ErrCodeNotInCombat},
+		{"unknown merchant", server.handleGetMerchant, `{"session_id":"test-session-001","merchant_id":"nobody"}`, ErrCodeMerchantNotFound},
+		{"unknown quest", server.handleGetQuest, `{"session_id":"test-session-001","quest_id":"no-such-quest"}`, ErrCodeQuestNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.handler(json.RawMessage(tt.params))
+			require.Error(t, err)
+			assert.Equal(t, tt.code, toJSONRPCError(err, JSONRPCInternalError).Code)
+		})
+	}
+}
+
+func TestErrorResponses_CarryCatalogCodes(t *testing.T) {
+	err := fmt.Errorf("session error: %w", ErrInvalidSession)
+
+	response := NewErrorResponse("ws-1", err).(map[string]interface{})
+	errorObj := response["error"].(map[string]interface{})
+	assert.Equal(t, ErrCodeInvalidSession, errorObj["code"])
+	assert.Equal(t, "session error: invalid session", errorObj["message"])
+	assert.Equal(t, map[string]interface{}{"reason": "invalid_session"}, errorObj["data"])
+
+	server := createTestServerForHandlers(t)
+	recorder := httptest.NewRecorder()
+	server.writeJSONRPCError(recorder, err, nil)
+
+	var body struct {
+		Error JSONRPCError `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, ErrCodeInvalidSession, body.Error.Code)
+	assert.Equal(t, map[string]interface{}{"reason": "invalid_session"}, body.Error.Data)
+}
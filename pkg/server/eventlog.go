@@ -0,0 +1,285 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// PersistenceModeSnapshot keeps progress only through full-state
+	// auto-saves.
+	PersistenceModeSnapshot = "snapshot"
+
+	// PersistenceModeEventSourced also appends every significant change to
+	// a write-ahead log between auto-saves, replayed on startup.
+	PersistenceModeEventSourced = "event-sourced"
+)
+
+const (
+	eventLogFile       = "events.log"            // Write-ahead log, relative to the data directory
+	eventCheckpointKey = "event_checkpoint.yaml" // Sessions and log position saved with each key frame
+
+	eventSessionSaved  = "session_saved"  // Data: SessionRecord after the change
+	eventSessionClosed = "session_closed" // Data: the session ID
+)
+
+// eventSourcedMethods are the RPC methods that change nothing but the
+// sessions they name, so their effect is appended to the event log in
+// event-sourced mode as the calling session's new state.
+var eventSourcedMethods = map[RPCMethod]bool{
+	MethodMove:            true,
+	MethodUseItem:         true,
+	MethodJoinGame:        true,
+	MethodLeaveGame:       true,
+	MethodCreateCharacter: true,
+	MethodChangeClass:     true,
+	MethodLevelUp:         true,
+	MethodEquipItem:       true,
+	MethodUnequipItem:     true,
+	MethodStartQuest:      true,
+	MethodUpdateObjective: true,
+	MethodMemorizeSpells:  true,
+
+	// Admin methods name the session they change in session_id
+	MethodAdminTeleportPlayer: true,
+	MethodAdminGrantXP:        true,
+	MethodAdminGrantItem:      true,
+}
+
+// keyFrameMethods also change state the event log does not hold, such as
+// a monster's hit points, a merchant's gold and stock, another player's
+// quests, where a world object stands, the game clock or the NPCs and areas
+// a quest's consequences change. In event-sourced mode a successful call
+// saves a key frame instead, so a later session record can never be
+// replayed over a world that lacks the call's other effects.
+var keyFrameMethods = map[RPCMethod]bool{
+	MethodAttack:              true,
+	MethodCastSpell:           true,
+	MethodApplyEffect:         true,
+	MethodEndTurn:             true,
+	MethodRest:                true,
+	MethodPassTime:            true,
+	MethodTravelTo:            true,
+	MethodShareQuest:          true,
+	MethodHireCompanion:       true,
+	MethodDismissCompanion:    true,
+	MethodCompleteQuest:       true,
+	MethodFailQuest:           true,
+	MethodOpenDoor:            true,
+	MethodPickLock:            true,
+	MethodInteractObject:      true,
+	MethodBuyItem:             true,
+	MethodSellItem:            true,
+	MethodAdminGiveItem:       true,
+	MethodAdminTeleport:       true,
+	MethodAdminUndoLastAction: true,
+}
+
+// eventCheckpoint is saved with every key frame in event-sourced mode. It
+// holds the sessions as they were and the last event log record the key
+// frame covers, so startup replays only the records after it.
+type eventCheckpoint struct {
+	Sequence uint64          `yaml:"sequence"`
+	Sessions []SessionRecord `yaml:"sessions"`
+	SavedAt  time.Time       `yaml:"saved_at"`
+}
+
+// pickedUpKeys reports whether a move picked up keys, taking them out of
+// the world
+func pickedUpKeys(result interface{}) bool {
+	fields, ok := result.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = fields["keys"]
+	return ok
+}
+
+// openEventLog opens the write-ahead log in the data directory.
+func (s *RPCServer) openEventLog(dataDir string) error {
+	events, err := persistence.OpenEventLog(filepath.Join(dataDir, eventLogFile))
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	s.events = events
+	return nil
+}
+
+// recordStateEvent appends the state of the session changed by a
+// successful call to the event log, or saves a key frame for calls that
+// change more than sessions. Failures are logged; the call has already
+// taken effect and is saved with the next key frame.
+func (s *RPCServer) recordStateEvent(method RPCMethod, params json.RawMessage, result interface{}, err error) {
+	if s.events == nil || err != nil {
+		return
+	}
+	if keyFrameMethods[method] || pickedUpKeys(result) {
+		if saveErr := s.persistState(); saveErr != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "recordStateEvent",
+				"method":   method,
+				"error":    saveErr.Error(),
+			}).Error("failed to save key frame")
+		}
+		return
+	}
+	if !eventSourcedMethods[method] {
+		return
+	}
+
+	sessionID := eventSessionID(params, result)
+	if sessionID == "" {
+		return
+	}
+
+	eventType, data := eventSessionClosed, []byte(sessionID)
+	if method != MethodLeaveGame {
+		s.mu.RLock()
+		session := s.sessions[sessionID]
+		s.mu.RUnlock()
+		if session == nil || session.Player == nil {
+			return
+		}
+		record := s.sessionRecord(session)
+
+		encoded, marshalErr := yaml.Marshal(record)
+		if marshalErr != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":  "recordStateEvent",
+				"sessionID": sessionID,
+				"error":     marshalErr.Error(),
+			}).Error("failed to encode session for event log")
+			return
+		}
+		eventType, data = eventSessionSaved, encoded
+	}
+
+	if _, appendErr := s.events.Append(eventType, data); appendErr != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "recordStateEvent",
+			"method":    method,
+			"sessionID": sessionID,
+			"error":     appendErr.Error(),
+		}).Error("failed to append to event log")
+	}
+}
+
+// eventSessionID returns the session a call acted on: the session_id
+// parameter, or for calls that create a session the one they returned.
+func eventSessionID(params json.RawMessage, result interface{}) string {
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if json.Unmarshal(params, &req) == nil && req.SessionID != "" {
+		return req.SessionID
+	}
+	resultMap, _ := result.(map[string]interface{})
+	sessionID, _ := resultMap["session_id"].(string)
+	return sessionID
+}
+
+// eventCheckpointEntry adds the checkpoint of a key frame to the documents
+// saved with it and returns the event log position it covers. Records
+// appended while the key frame is written are replayed over it on startup,
+// which is harmless because each record holds a whole session.
+func (s *RPCServer) eventCheckpointEntry(extra map[string]interface{}) uint64 {
+	sequence := s.events.LastSequence()
+
+	s.mu.RLock()
+	sessions := make([]*PlayerSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if session != nil && session.Player != nil {
+			sessions = append(sessions, session)
+		}
+	}
+	s.mu.RUnlock()
+
+	checkpoint := eventCheckpoint{
+		Sequence: sequence,
+		Sessions: make([]SessionRecord, 0, len(sessions)),
+		SavedAt:  time.Now(),
+	}
+	for _, session := range sessions {
+		checkpoint.Sessions = append(checkpoint.Sessions, s.sessionRecord(session))
+	}
+	extra[eventCheckpointKey] = checkpoint
+	return sequence
+}
+
+// replayEventLog restores the sessions saved with the last key frame, then
+// applies the event log records appended after it.
+func (s *RPCServer) replayEventLog() error {
+	var checkpoint eventCheckpoint
+	if s.store.Exists(eventCheckpointKey) {
+		if err := s.store.Load(eventCheckpointKey, &checkpoint); err != nil {
+			return fmt.Errorf("failed to load event checkpoint: %w", err)
+		}
+	}
+	for _, record := range checkpoint.Sessions {
+		s.restoreSessionRecord(record)
+	}
+
+	replayed, err := s.events.Replay(checkpoint.Sequence, func(event persistence.EventRecord) error {
+		switch event.Type {
+		case eventSessionSaved:
+			var record SessionRecord
+			if err := yaml.Unmarshal(event.Data, &record); err != nil {
+				return fmt.Errorf("failed to decode event %d: %w", event.Sequence, err)
+			}
+			s.restoreSessionRecord(record)
+		case eventSessionClosed:
+			s.mu.Lock()
+			delete(s.sessions, string(event.Data))
+			s.mu.Unlock()
+		default:
+			return fmt.Errorf("unknown event type %q at sequence %d", event.Type, event.Sequence)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "replayEventLog",
+		"checkpoint": checkpoint.Sequence,
+		"sessions":   len(checkpoint.Sessions),
+		"replayed":   replayed,
+	}).Info("event log replayed")
+	return nil
+}
+
+// restoreSessionRecord registers a session from the event log, replacing
+// the player of a session already restored. Restored sessions count as
+// active from now, so their players have a full session timeout to return.
+func (s *RPCServer) restoreSessionRecord(record SessionRecord) {
+	if record.SessionID == "" || record.Player == nil {
+		return
+	}
+
+	s.mu.Lock()
+	session, exists := s.sessions[record.SessionID]
+	if exists {
+		session.Player = record.Player
+	} else {
+		session = &PlayerSession{
+			SessionID:   record.SessionID,
+			Player:      record.Player,
+			CreatedAt:   record.CreatedAt,
+			LastActive:  time.Now(),
+			MessageChan: make(chan []byte, MessageChanBufferSize),
+			events:      newEventBuffer(ResumeEventBufferSize),
+		}
+		s.sessions[record.SessionID] = session
+	}
+	s.mu.Unlock()
+
+	s.state.AddPlayer(session)
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEventSourcedTestServer returns a server persisting to store with its
+// event log in dataDir, as after a restart.
+func newEventSourcedTestServer(t *testing.T, store persistence.Store, dataDir string) *RPCServer {
+	t.Helper()
+	server := createTestServerForHandlers(t)
+	server.store = store
+	require.NoError(t, server.state.LoadFromFile(store))
+	require.NoError(t, server.openEventLog(dataDir))
+	t.Cleanup(func() { server.events.Close() })
+	require.NoError(t, server.replayEventLog())
+	return server
+}
+
+// createEventSourcedTestSession returns a test session under a UUID, which
+// handleMethod's parameter validation requires.
+func createEventSourcedTestSession(t *testing.T, server *RPCServer) *PlayerSession {
+	t.Helper()
+	session := createTestSessionForHandlers(t, server)
+	server.mu.Lock()
+	delete(server.sessions, session.SessionID)
+	session.SessionID = uuid.New().String()
+	server.sessions[session.SessionID] = session
+	server.mu.Unlock()
+	return session
+}
+
+// callLogged calls an RPC method through handleMethod, which records it.
+func callLogged(t *testing.T, server *RPCServer, method RPCMethod, params map[string]interface{}) {
+	t.Helper()
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+	_, err = server.handleMethod(method, data)
+	require.NoError(t, err)
+}
+
+func TestEventSourcing_ReplaysChangesSinceKeyFrame(t *testing.T) {
+	store := persistence.NewMemoryStore()
+	dataDir := t.TempDir()
+	server := newEventSourcedTestServer(t, store, dataDir)
+	session := createEventSourcedTestSession(t, server)
+	server.state.WorldState.Width, server.state.WorldState.Height = 20, 20
+	server.admin = newTestAdminConsole(10, AdminPermissionGrant, AdminPermissionTeleport)
+
+	give := map[string]interface{}{
+		"admin_token": testAdminToken,
+		"session_id":  session.SessionID,
+		"item":        map[string]interface{}{"name": "Long Sword", "type": "weapon"},
+	}
+	callLogged(t, server, MethodAdminGrantItem, give)
+	require.NoError(t, server.persistState())
+	assert.Zero(t, countEvents(t, server), "the key frame covers every record")
+
+	callLogged(t, server, MethodAdminTeleportPlayer, map[string]interface{}{
+		"admin_token": testAdminToken,
+		"session_id":  session.SessionID,
+		"position":    map[string]interface{}{"x": 3, "y": 4},
+	})
+	give["item"] = map[string]interface{}{"name": "Shield", "type": "armor"}
+	callLogged(t, server, MethodAdminGrantItem, give)
+	assert.Equal(t, 2, countEvents(t, server))
+	require.NoError(t, server.events.Close())
+
+	// A restart restores the key frame's sessions and replays the log
+	restarted := newEventSourcedTestServer(t, store, dataDir)
+	restored, exists := restarted.sessions[session.SessionID]
+	require.True(t, exists)
+	assert.Equal(t, game.Position{X: 3, Y: 4}, restored.Player.GetPosition())
+	require.Len(t, restored.Player.Inventory, 2)
+	assert.Equal(t, "Shield", restored.Player.Inventory[1].Name)
+
+	// Records continue after the ones replayed
+	callLogged(t, restarted, MethodLeaveGame, map[string]interface{}{"session_id": session.SessionID})
+	assert.Equal(t, uint64(4), restarted.events.LastSequence())
+	require.NoError(t, restarted.events.Close())
+
+	restarted = newEventSourcedTestServer(t, store, dataDir)
+	assert.NotContains(t, restarted.sessions, session.SessionID)
+}
+
+func TestEventSourcing_SharedChangesSaveKeyFrame(t *testing.T) {
+	store := persistence.NewMemoryStore()
+	dataDir := t.TempDir()
+	server := newEventSourcedTestServer(t, store, dataDir)
+	session := createEventSourcedTestSession(t, server)
+	server.state.WorldState.Width, server.state.WorldState.Height = 20, 20
+	server.admin = newTestAdminConsole(10, AdminPermissionGrant, AdminPermissionTeleport)
+	orc := &game.NPC{Character: game.Character{ID: "eventlog-orc", Name: "Orc", HP: 10, MaxHP: 10}}
+	require.NoError(t, server.state.WorldState.AddObject(orc))
+
+	callLogged(t, server, MethodAdminGrantItem, map[string]interface{}{
+		"admin_token": testAdminToken,
+		"session_id":  session.SessionID,
+		"item":        map[string]interface{}{"name": "Long Sword", "type": "weapon"},
+	})
+	assert.Equal(t, 1, countEvents(t, server))
+
+	// Moving a world object is outside any session, so it saves a key frame
+	callLogged(t, server, MethodAdminTeleport, map[string]interface{}{
+		"admin_token": testAdminToken,
+		"session_id":  session.SessionID,
+		"target_id":   orc.ID,
+		"position":    map[string]interface{}{"x": 7, "y": 8},
+	})
+	assert.Zero(t, countEvents(t, server), "the key frame covers every record")
+	require.NoError(t, server.events.Close())
+
+	restarted := newEventSourcedTestServer(t, store, dataDir)
+	restoredOrc, exists := restarted.state.WorldState.GetObject(orc.ID)
+	require.True(t, exists, "world objects are loaded from the key frame")
+	assert.Equal(t, 7, restoredOrc.GetPosition().X)
+	restored := restarted.sessions[session.SessionID]
+	require.NotNil(t, restored)
+	require.Len(t, restored.Player.Inventory, 1)
+}
+
+func TestEventSourcing_IgnoresReadsAndFailures(t *testing.T) {
+	server := newEventSourcedTestServer(t, persistence.NewMemoryStore(), t.TempDir())
+	session := createEventSourcedTestSession(t, server)
+
+	server.admin = newTestAdminConsole(10, AdminPermissionGrant)
+
+	callLogged(t, server, MethodGetRateLimitStats, map[string]interface{}{"session_id": session.SessionID})
+	_, err := server.handleMethod(MethodAdminGiveItem, json.RawMessage(`{"admin_token":"`+testAdminToken+`","session_id":"`+session.SessionID+`","target_id":"nobody","item":{"name":"Dagger","type":"weapon"}}`))
+	require.Error(t, err)
+	assert.Zero(t, countEvents(t, server))
+}
+
+// countEvents returns the number of records in the server's event log.
+func countEvents(t *testing.T, server *RPCServer) int {
+	t.Helper()
+	count, err := server.events.Replay(0, func(persistence.EventRecord) error { return nil })
+	require.NoError(t, err)
+	return count
+}
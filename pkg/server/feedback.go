@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// feedbackGuard limits how often sessions may submit content feedback and
+// remembers which content each session has already rated. The zero value
+// is ready to use. Expired entries are dropped whenever the guard is
+// accessed.
+type feedbackGuard struct {
+	mu          sync.Mutex
+	submissions map[string][]time.Time // session ID -> recent submission times
+	rated       map[string]time.Time   // feedback key -> submission time
+}
+
+// admit records a feedback submission for key by sessionID. It returns an
+// error, without recording anything, when the session has used up its
+// allowance for the current window or already rated the content.
+func (g *feedbackGuard) admit(sessionID, key string, now time.Time) *JSONRPCError {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.purgeExpiredLocked(now)
+	if g.submissions == nil {
+		g.submissions = make(map[string][]time.Time)
+		g.rated = make(map[string]time.Time)
+	}
+
+	if _, exists := g.rated[key]; exists {
+		return ErrFeedbackRejected.WithMessage("feedback already submitted for this content").
+			WithData(map[string]interface{}{"cause": "duplicate"})
+	}
+	recent := g.submissions[sessionID]
+	if len(recent) >= FeedbackRateLimit {
+		retryAfter := recent[0].Add(FeedbackRateWindow).Sub(now)
+		return ErrFeedbackRejected.WithMessage("feedback rate limit exceeded").
+			WithData(map[string]interface{}{"cause": "rate_limited", "retry_after_ms": retryAfter.Milliseconds()})
+	}
+
+	g.submissions[sessionID] = append(recent, now)
+	g.rated[key] = now
+	return nil
+}
+
+// purgeExpiredLocked drops submissions older than FeedbackRateWindow and
+// ratings older than FeedbackDedupTTL. The caller must hold g.mu.
+func (g *feedbackGuard) purgeExpiredLocked(now time.Time) {
+	for sessionID, times := range g.submissions {
+		kept := times[:0]
+		for _, t := range times {
+			if now.Sub(t) < FeedbackRateWindow {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(g.submissions, sessionID)
+		} else {
+			g.submissions[sessionID] = kept
+		}
+	}
+	for key, t := range g.rated {
+		if now.Sub(t) >= FeedbackDedupTTL {
+			delete(g.rated, key)
+		}
+	}
+}
+
+// feedbackKey identifies one session's feedback on one piece of content
+func feedbackKey(sessionID string, contentType pcg.ContentType, contentID string) string {
+	return sessionID + "|" + string(contentType) + "|" + contentID
+}
+
+// handleSubmitContentFeedback records a player's rating of generated
+// content. Feedback feeds the PCG quality metrics and, through the PCG
+// event manager, runtime difficulty adjustment. Each session may rate a
+// piece of content once every FeedbackDedupTTL and submit at most
+// FeedbackRateLimit ratings per FeedbackRateWindow.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The submitting session
+//   - content_type: string - terrain, items, levels or quests
+//   - content_id: string - ID of the rated content
+//   - rating: int - Overall rating, 1-5
+//   - difficulty: int - Perceived difficulty, 1-5
+//   - enjoyment: int - Enjoyment, 1-5
+//   - comments: string (optional) - Free text, up to FeedbackMaxCommentLength characters
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating the feedback was recorded
+//   - content_type, content_id: The rated content
+//   - error: Invalid parameters or session, or feedback rejected as a
+//     duplicate or for exceeding the rate limit
+func (s *RPCServer) handleSubmitContentFeedback(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID   string `json:"session_id"`
+		ContentType string `json:"content_type"`
+		ContentID   string `json:"content_id"`
+		Rating      int    `json:"rating"`
+		Difficulty  int    `json:"difficulty"`
+		Enjoyment   int    `json:"enjoyment"`
+		Comments    string `json:"comments"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid feedback parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	contentType := pcg.ContentType(req.ContentType)
+	switch contentType {
+	case pcg.ContentTypeTerrain, pcg.ContentTypeItems, pcg.ContentTypeLevels, pcg.ContentTypeQuests:
+	default:
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("unsupported content type: %s", req.ContentType), nil)
+	}
+	if strings.TrimSpace(req.ContentID) == "" {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "content_id is required", nil)
+	}
+	scores := []struct {
+		field string
+		value int
+	}{{"rating", req.Rating}, {"difficulty", req.Difficulty}, {"enjoyment", req.Enjoyment}}
+	for _, score := range scores {
+		if score.value < 1 || score.value > 5 {
+			return nil, NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("%s must be between 1 and 5", score.field), score.value)
+		}
+	}
+	if len(req.Comments) > FeedbackMaxCommentLength {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams,
+			fmt.Sprintf("comments exceed %d characters", FeedbackMaxCommentLength), nil)
+	}
+
+	now := time.Now()
+	if rejection := s.feedback.admit(req.SessionID, feedbackKey(req.SessionID, contentType, req.ContentID), now); rejection != nil {
+		return nil, rejection
+	}
+
+	feedback := pcg.PlayerFeedback{
+		Timestamp:   now,
+		ContentType: contentType,
+		ContentID:   req.ContentID,
+		Rating:      req.Rating,
+		Difficulty:  req.Difficulty,
+		Enjoyment:   req.Enjoyment,
+		Comments:    req.Comments,
+		SessionID:   req.SessionID,
+	}
+	s.pcgManager.RecordPlayerFeedback(feedback)
+	if s.pcgEvents != nil {
+		s.pcgEvents.EmitPlayerFeedback(&feedback)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":    "handleSubmitContentFeedback",
+		"sessionID":   req.SessionID,
+		"contentType": contentType,
+		"contentID":   req.ContentID,
+		"rating":      req.Rating,
+	}).Info("content feedback recorded")
+
+	return map[string]interface{}{
+		"success":      true,
+		"content_type": req.ContentType,
+		"content_id":   req.ContentID,
+	}, nil
+}
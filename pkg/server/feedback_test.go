@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func feedbackParams(contentID string, rating int) json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{"session_id":"test-session-001","content_type":"quests","content_id":%q,"rating":%d,"difficulty":3,"enjoyment":4}`, contentID, rating))
+}
+
+func TestSubmitContentFeedback(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	result, err := server.handleSubmitContentFeedback(feedbackParams("quest_1", 2))
+	require.NoError(t, err)
+	assert.Equal(t, true, result.(map[string]interface{})["success"])
+
+	_, err = server.handleSubmitContentFeedback(feedbackParams("quest_2", 4))
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, server.pcgManager.GetQualityMetrics().GetSatisfactionScore(pcg.ContentTypeQuests))
+}
+
+func TestSubmitContentFeedback_Validation(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	tests := map[string]string{
+		"rating too low":   `{"session_id":"test-session-001","content_type":"quests","content_id":"q","rating":0,"difficulty":3,"enjoyment":3}`,
+		"enjoyment high":   `{"session_id":"test-session-001","content_type":"quests","content_id":"q","rating":3,"difficulty":3,"enjoyment":6}`,
+		"unknown content":  `{"session_id":"test-session-001","content_type":"weather","content_id":"q","rating":3,"difficulty":3,"enjoyment":3}`,
+		"missing content":  `{"session_id":"test-session-001","content_type":"quests","rating":3,"difficulty":3,"enjoyment":3}`,
+		"unknown session":  `{"session_id":"missing","content_type":"quests","content_id":"q","rating":3,"difficulty":3,"enjoyment":3}`,
+		"long comment":     `{"session_id":"test-session-001","content_type":"quests","content_id":"q","rating":3,"difficulty":3,"enjoyment":3,"comments":"` + strings.Repeat("a", FeedbackMaxCommentLength+1) + `"}`,
+		"malformed params": `{"session_id":`,
+	}
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := server.handleSubmitContentFeedback(json.RawMessage(params))
+			assert.Error(t, err)
+		})
+	}
+	assert.Zero(t, server.pcgManager.GetQualityMetrics().GetSatisfactionScore(pcg.ContentTypeQuests), "rejected feedback is not recorded")
+}
+
+func TestSubmitContentFeedback_Duplicate(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	_, err := server.handleSubmitContentFeedback(feedbackParams("quest_1", 5))
+	require.NoError(t, err)
+
+	_, err = server.handleSubmitContentFeedback(feedbackParams("quest_1", 1))
+	assert.ErrorIs(t, err, ErrFeedbackRejected)
+	assert.Equal(t, "duplicate", err.(*JSONRPCError).Data.(map[string]interface{})["cause"])
+	assert.Equal(t, 5.0, server.pcgManager.GetQualityMetrics().GetSatisfactionScore(pcg.ContentTypeQuests))
+}
+
+func TestSubmitContentFeedback_RateLimited(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	for i := 0; i < FeedbackRateLimit; i++ {
+		_, err := server.handleSubmitContentFeedback(feedbackParams(fmt.Sprintf("quest_%d", i), 3))
+		require.NoError(t, err)
+	}
+
+	_, err := server.handleSubmitContentFeedback(feedbackParams("quest_extra", 3))
+	assert.ErrorIs(t, err, ErrFeedbackRejected)
+	assert.Equal(t, "rate_limited", err.(*JSONRPCError).Data.(map[string]interface{})["cause"])
+}
+
+func TestFeedbackGuard_Expiry(t *testing.T) {
+	var guard feedbackGuard
+	start := time.Now()
+
+	for i := 0; i < FeedbackRateLimit; i++ {
+		require.Nil(t, guard.admit("s", fmt.Sprintf("key_%d", i), start))
+	}
+	assert.NotNil(t, guard.admit("s", "key_extra", start), "allowance is used up")
+	assert.Nil(t, guard.admit("other", "other_key", start), "sessions have separate allowances")
+
+	afterWindow := start.Add(FeedbackRateWindow)
+	assert.Nil(t, guard.admit("s", "key_extra", afterWindow), "allowance refills after the window")
+	assert.NotNil(t, guard.admit("s", "key_0", afterWindow), "ratings stay deduplicated within the TTL")
+	assert.Nil(t, guard.admit("s", "key_0", start.Add(FeedbackDedupTTL)), "content can be rated again after the TTL")
+}
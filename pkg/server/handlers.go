@@ -14,9 +14,6 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// ErrInvalidSession is
-var ErrInvalidSession = errors.New("invalid session")
-
 // handleMove processes a player movement request in the game world.
 //
 // Parameters:
@@ -26,8 +23,10 @@ var ErrInvalidSession = errors.New("invalid session")
 //
 // Returns:
 //   - interface{}: Map containing:
-//   - success: bool indicating if move was successful
+//   - success: bool indicating if move was successful; false when an
+//     opportunity attack struck the player down before it could move
 //   - position: Updated position coordinates
+//   - opportunity_attacks: Attacks provoked by leaving an enemy's zone of control
 //   - error: Possible errors:
 //   - "invalid movement parameters" if JSON unmarshaling fails
 //   - "invalid session" if session ID not found
@@ -71,6 +70,29 @@ func (s *RPCServer) handleMove(params json.RawMessage) (interface{}, error) {
 		return nil, err
 	}
 
+	var reactions []ReactionOutcome
+	var attacks []OpportunityAttack
+	if s.state.TurnManager.IsInCombat {
+		from := session.Player.GetPosition()
+		reactions = s.resolveReactions(ReactionEvent{
+			Trigger: TriggerLeaveReach,
+			ActorID: session.Player.GetID(),
+			From:    from,
+			To:      newPos,
+		})
+		attacks = s.resolveOpportunityAttacks(session.Player, from)
+	}
+
+	if (len(reactions) > 0 || len(attacks) > 0) && session.Player.GetHP() <= 0 {
+		// Struck down on the way out, the player stays where it was
+		result := map[string]interface{}{
+			"success":  false,
+			"position": session.Player.GetPosition(),
+		}
+		addMoveAttacks(result, reactions, attacks)
+		return result, nil
+	}
+
 	if err := s.executePlayerMovement(session.Player, newPos); err != nil {
 		return nil, err
 	}
@@ -79,10 +101,29 @@ func (s *RPCServer) handleMove(params json.RawMessage) (interface{}, error) {
 		"function": "handleMove",
 	}).Debug("exiting handleMove")
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"success":  true,
 		"position": newPos,
-	}, nil
+	}
+	addMoveAttacks(result, reactions, attacks)
+	if keys := s.pickUpKeys(session.Player, newPos); len(keys) > 0 {
+		result["keys"] = keys
+	}
+	if encounter := s.checkRandomEncounter(session, newPos); encounter != nil {
+		result["encounter"] = encounter
+	}
+	return result, nil
+}
+
+// addMoveAttacks adds the reactions and opportunity attacks a move provoked
+// to its result.
+func addMoveAttacks(result map[string]interface{}, reactions []ReactionOutcome, attacks []OpportunityAttack) {
+	if len(reactions) > 0 {
+		result["reactions"] = reactions
+	}
+	if len(attacks) > 0 {
+		result["opportunity_attacks"] = attacks
+	}
 }
 
 // parseMoveRequest extracts and validates movement request parameters from JSON.
@@ -115,7 +156,7 @@ func (s *RPCServer) getSessionForMove(sessionID string) (*PlayerSession, error)
 			"function":  "getSessionForMove",
 			"sessionID": sessionID,
 		}).Warn("invalid session ID")
-		return nil, fmt.Errorf("invalid session")
+		return nil, ErrInvalidSession
 	}
 	return session, nil
 }
@@ -131,18 +172,18 @@ func (s *RPCServer) validateCombatConstraints(player *game.Player) error {
 			"function": "validateCombatConstraints",
 			"playerID": player.GetID(),
 		}).Warn("player attempted to move when not their turn")
-		return fmt.Errorf("not your turn")
+		return ErrNotYourTurn
 	}
 
-	if player.GetActionPoints() < game.ActionCostMove {
+	cost := s.movementCost(player)
+	if player.GetActionPoints() < cost {
 		logrus.WithFields(logrus.Fields{
 			"function":   "validateCombatConstraints",
 			"playerID":   player.GetID(),
 			"currentAP":  player.GetActionPoints(),
-			"requiredAP": game.ActionCostMove,
+			"requiredAP": cost,
 		}).Warn("player attempted to move without enough action points")
-		return fmt.Errorf("insufficient action points for movement (need %d, have %d)",
-			game.ActionCostMove, player.GetActionPoints())
+		return insufficientAPError("movement", cost, player.GetActionPoints())
 	}
 
 	return nil
@@ -177,18 +218,20 @@ func (s *RPCServer) consumeMovementActionPoints(player *game.Player) error {
 		return nil
 	}
 
-	if !player.ConsumeActionPoints(game.ActionCostMove) {
+	cost := s.movementCost(player)
+	if !player.ConsumeActionPoints(cost) {
 		logrus.WithFields(logrus.Fields{
 			"function": "consumeMovementActionPoints",
 			"playerID": player.GetID(),
 		}).Error("failed to consume action points before movement")
-		return fmt.Errorf("action point consumption failed")
+		return ErrInsufficientAP.WithMessage("action point consumption failed")
 	}
+	s.pushActionPoints(player)
 
 	logrus.WithFields(logrus.Fields{
 		"function":    "consumeMovementActionPoints",
 		"playerID":    player.GetID(),
-		"consumedAP":  game.ActionCostMove,
+		"consumedAP":  cost,
 		"remainingAP": player.GetActionPoints(),
 	}).Info("consumed action points for movement")
 
@@ -224,6 +267,15 @@ func (s *RPCServer) executePlayerMovement(player *game.Player, newPos game.Posit
 	return nil
 }
 
+// attachSpatialTracking re-indexes objects in the world's spatial indexes
+// as movement events arrive. The world is looked up per event so a world
+// restored from a save keeps being tracked.
+func (s *RPCServer) attachSpatialTracking() {
+	s.eventSys.Subscribe(game.EventMovement, func(event game.GameEvent) {
+		s.state.WorldState.HandleMovement(event)
+	})
+}
+
 // handleAttack processes an attack action during combat in the RPG game.
 //
 // Parameters:
@@ -270,7 +322,7 @@ func (s *RPCServer) handleAttack(params json.RawMessage) (interface{}, error) {
 			"function":  "handleAttack",
 			"sessionID": req.SessionID,
 		}).Warn("invalid session ID")
-		return nil, fmt.Errorf("invalid session")
+		return nil, ErrInvalidSession
 	}
 	defer s.releaseSession(session) // Ensure session is released when handler completes
 
@@ -278,7 +330,7 @@ func (s *RPCServer) handleAttack(params json.RawMessage) (interface{}, error) {
 		logrus.WithFields(logrus.Fields{
 			"function": "handleAttack",
 		}).Warn("attempted attack while not in combat")
-		return nil, fmt.Errorf("not in combat")
+		return nil, ErrNotInCombat
 	}
 
 	if !s.state.TurnManager.IsCurrentTurn(session.Player.GetID()) {
@@ -286,7 +338,7 @@ func (s *RPCServer) handleAttack(params json.RawMessage) (interface{}, error) {
 			"function": "handleAttack",
 			"playerID": session.Player.GetID(),
 		}).Warn("player attempted attack when not their turn")
-		return nil, fmt.Errorf("not your turn")
+		return nil, ErrNotYourTurn
 	}
 
 	// Check if player has enough action points for attack
@@ -297,8 +349,7 @@ func (s *RPCServer) handleAttack(params json.RawMessage) (interface{}, error) {
 			"currentAP":  session.Player.GetActionPoints(),
 			"requiredAP": game.ActionCostAttack,
 		}).Warn("player attempted to attack without enough action points")
-		return nil, fmt.Errorf("insufficient action points for attack (need %d, have %d)",
-			game.ActionCostAttack, session.Player.GetActionPoints())
+		return nil, insufficientAPError("attack", game.ActionCostAttack, session.Player.GetActionPoints())
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -324,8 +375,9 @@ func (s *RPCServer) handleAttack(params json.RawMessage) (interface{}, error) {
 			"function": "handleAttack",
 			"playerID": session.Player.GetID(),
 		}).Error("failed to consume action points after attack validation")
-		return nil, fmt.Errorf("action point consumption failed")
+		return nil, ErrInsufficientAP.WithMessage("action point consumption failed")
 	}
+	s.pushActionPoints(session.Player)
 	logrus.WithFields(logrus.Fields{
 		"function":    "handleAttack",
 		"playerID":    session.Player.GetID(),
@@ -357,6 +409,7 @@ func (s *RPCServer) handleAttack(params json.RawMessage) (interface{}, error) {
 //   - Invalid JSON parameters
 //   - Invalid session ID
 //   - Spell not found in player's known spells
+//   - A verbal or somatic component is blocked, or materials are missing
 //   - Spell casting fails (via processSpellCast)
 //
 // Related:
@@ -387,6 +440,39 @@ func (s *RPCServer) handleCastSpell(params json.RawMessage) (interface{}, error)
 		return nil, err
 	}
 
+	if err := s.validateSpellMemorized(session.Player, spell); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateSpellComponents(session.Player, spell); err != nil {
+		return nil, err
+	}
+
+	if s.state.TurnManager.IsInCombat {
+		reactions := s.resolveReactions(ReactionEvent{
+			Trigger:  TriggerSpellCast,
+			ActorID:  session.Player.GetID(),
+			TargetID: req.TargetID,
+			SpellID:  spell.ID,
+		})
+		if counter, countered := reactionAccepted(reactions, ReactionCounterspell); countered {
+			// A countered spell still costs the caster its action, the
+			// memorized spell and its consumed materials
+			if err := s.consumeSpellCastActionPoints(session.Player); err != nil {
+				return nil, err
+			}
+			s.expendMemorizedSpell(session.Player, spell)
+			s.consumeSpellMaterials(session.Player, spell)
+			return map[string]interface{}{
+				"success":      false,
+				"countered":    true,
+				"countered_by": counter.OwnerID,
+				"spell_id":     spell.ID,
+				"reactions":    reactions,
+			}, nil
+		}
+	}
+
 	result, err := s.executeSpellCast(session.Player, spell, req.TargetID, req.Position)
 	if err != nil {
 		return nil, err
@@ -395,6 +481,8 @@ func (s *RPCServer) handleCastSpell(params json.RawMessage) (interface{}, error)
 	if err := s.consumeSpellCastActionPoints(session.Player); err != nil {
 		return nil, err
 	}
+	s.expendMemorizedSpell(session.Player, spell)
+	s.consumeSpellMaterials(session.Player, spell)
 
 	logrus.WithFields(logrus.Fields{
 		"function": "handleCastSpell",
@@ -437,7 +525,7 @@ func (s *RPCServer) validateSpellCastSession(sessionID string) (*PlayerSession,
 			"function":  "validateSpellCastSession",
 			"sessionID": sessionID,
 		}).Warn("invalid session ID")
-		return nil, fmt.Errorf("invalid session")
+		return nil, ErrInvalidSession
 	}
 	return session, nil
 }
@@ -455,7 +543,7 @@ func (s *RPCServer) validateCombatConstraintsForSpell(player *game.Player) error
 			"function": "validateCombatConstraintsForSpell",
 			"playerID": player.GetID(),
 		}).Warn("player attempted to cast spell when not their turn")
-		return fmt.Errorf("not your turn")
+		return ErrNotYourTurn
 	}
 
 	// Check if player has enough action points for spell casting
@@ -466,8 +554,7 @@ func (s *RPCServer) validateCombatConstraintsForSpell(player *game.Player) error
 			"currentAP":  player.GetActionPoints(),
 			"requiredAP": game.ActionCostSpell,
 		}).Warn("player attempted to cast spell without enough action points")
-		return fmt.Errorf("insufficient action points for spell casting (need %d, have %d)",
-			game.ActionCostSpell, player.GetActionPoints())
+		return insufficientAPError("spell casting", game.ActionCostSpell, player.GetActionPoints())
 	}
 
 	return nil
@@ -482,7 +569,7 @@ func (s *RPCServer) validatePlayerSpellKnowledge(player *game.Player, spellID st
 			"spellID":  spellID,
 			"playerID": player.GetID(),
 		}).Warn("spell not found in spell database")
-		return nil, fmt.Errorf("spell not found: %s", spellID)
+		return nil, ErrSpellNotFound.WithMessage("spell not found: %s", spellID).WithData(map[string]interface{}{"spell_id": spellID})
 	}
 
 	// Check if player knows this spell
@@ -492,12 +579,38 @@ func (s *RPCServer) validatePlayerSpellKnowledge(player *game.Player, spellID st
 			"playerID": player.GetID(),
 			"spellID":  spellID,
 		}).Warn("player does not know this spell")
-		return nil, fmt.Errorf("you do not know this spell: %s", spell.Name)
+		return nil, ErrSpellUnknown.WithMessage("you do not know this spell: %s", spell.Name).WithData(map[string]interface{}{"spell_id": spell.ID})
 	}
 
 	return spell, nil
 }
 
+// validateSpellMemorized checks that the player has a ready memorized copy of
+// the spell. Cantrips need none.
+func (s *RPCServer) validateSpellMemorized(player *game.Player, spell *game.Spell) error {
+	if err := player.CanCastMemorized(*spell); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "validateSpellMemorized",
+			"playerID": player.GetID(),
+			"spellID":  spell.ID,
+		}).Warn("player attempted to cast a spell that is not memorized")
+		return ErrSpellRequirements.WithMessage("%s is not memorized; memorize it and rest first", spell.Name).WithData(map[string]interface{}{"spell_id": spell.ID})
+	}
+	return nil
+}
+
+// expendMemorizedSpell uses up the memorized copy of a spell that was cast.
+func (s *RPCServer) expendMemorizedSpell(player *game.Player, spell *game.Spell) {
+	if err := player.ExpendSpell(*spell); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "expendMemorizedSpell",
+			"playerID": player.GetID(),
+			"spellID":  spell.ID,
+			"error":    err.Error(),
+		}).Warn("failed to expend memorized spell")
+	}
+}
+
 // executeSpellCast performs the actual spell casting operation.
 func (s *RPCServer) executeSpellCast(player *game.Player, spell *game.Spell, targetID string, position game.Position) (interface{}, error) {
 	logrus.WithFields(logrus.Fields{
@@ -533,8 +646,9 @@ func (s *RPCServer) consumeSpellCastActionPoints(player *game.Player) error {
 			"function": "consumeSpellCastActionPoints",
 			"playerID": player.GetID(),
 		}).Error("failed to consume action points after spell validation")
-		return fmt.Errorf("action point consumption failed")
+		return ErrInsufficientAP.WithMessage("action point consumption failed")
 	}
+	s.pushActionPoints(player)
 
 	logrus.WithFields(logrus.Fields{
 		"function":    "consumeSpellCastActionPoints",
@@ -552,27 +666,39 @@ func (s *RPCServer) consumeSpellCastActionPoints(player *game.Player) error {
 //   - params: Raw JSON message containing:
 //   - session_id: Unique identifier for the game session
 //   - participant_ids: Array of string IDs for the combat participants
+//   - seed: Optional combat seed for the recorded dice; random when zero
+//   - initiative_mode: Optional "fixed" or "per_round"; the server's
+//     INITIATIVE_MODE when omitted
 //
 // Returns:
 //   - interface{}: Map containing:
 //   - success: Boolean indicating successful combat start
-//   - initiative: Ordered array of participant IDs based on initiative rolls
+//   - initiative: Ordered array of the participant IDs acting in the first
+//     round; surprised participants are left out
+//   - initiative_breakdown: Each participant's roll, dexterity adjustment,
+//     weapon speed and total, highest total first
+//   - initiative_mode: The initiative mode of the combat
+//   - surprised: IDs of the participants who lose the first round
 //   - first_turn: ID of the participant who goes first
+//   - replay_id, seed: Identify the combat recording for replayCombat
 //   - error: Error if:
-//   - Invalid JSON parameters provided
+//   - Invalid JSON parameters or an unknown initiative mode provided
 //   - Combat is already in progress for this session
 //
 // Related:
 //   - TurnManager.StartCombat(): Handles the actual combat state management
 //   - rollInitiative(): Determines turn order for participants
+//   - markSurprised(): Detects a surprise round
 func (s *RPCServer) handleStartCombat(params json.RawMessage) (interface{}, error) {
 	logrus.WithFields(logrus.Fields{
 		"function": "handleStartCombat",
 	}).Debug("entering handleStartCombat")
 
 	var req struct {
-		SessionID    string   `json:"session_id"`
-		Participants []string `json:"participant_ids"`
+		SessionID      string   `json:"session_id"`
+		Participants   []string `json:"participant_ids"`
+		Seed           int64    `json:"seed,omitempty"`
+		InitiativeMode string   `json:"initiative_mode,omitempty"`
 	}
 
 	if err := json.Unmarshal(params, &req); err != nil {
@@ -587,7 +713,19 @@ func (s *RPCServer) handleStartCombat(params json.RawMessage) (interface{}, erro
 		logrus.WithFields(logrus.Fields{
 			"function": "handleStartCombat",
 		}).Warn("attempted to start combat while already in combat")
-		return nil, fmt.Errorf("combat already in progress")
+		return nil, ErrCombatInProgress
+	}
+
+	mode := req.InitiativeMode
+	if mode == "" {
+		mode = InitiativeModeFixed
+		if s.config != nil && s.config.InitiativeMode != "" {
+			mode = s.config.InitiativeMode
+		}
+	}
+	if mode != InitiativeModeFixed && mode != InitiativeModePerRound {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid combat parameters",
+			fmt.Sprintf("initiative_mode must be %s or %s, got %q", InitiativeModeFixed, InitiativeModePerRound, mode))
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -595,21 +733,35 @@ func (s *RPCServer) handleStartCombat(params json.RawMessage) (interface{}, erro
 		"participants": len(req.Participants),
 	}).Info("rolling initiative for combat participants")
 
-	initiative := s.rollInitiative(req.Participants)
+	req.Participants = append(req.Participants, s.companionParticipants(req.Participants)...)
+	replay := s.beginCombatReplay(req.Seed, req.Participants)
+	encounter := s.beginCombatLog(replay, req.SessionID)
+	breakdown := s.rollInitiative(req.Participants)
+	surprised := s.markSurprised(breakdown)
+	initiative := initiativeOrder(breakdown)
+	s.configureTurnTimer()
 	if err := s.state.TurnManager.StartCombat(initiative); err != nil {
+		s.replays.discard(replay)
+		s.combatLogs.discard(encounter)
 		logrus.WithFields(logrus.Fields{
 			"function": "handleStartCombat",
 			"error":    err.Error(),
 		}).Error("failed to start combat")
-		return nil, fmt.Errorf("failed to start combat: %w", err)
+		return nil, err
 	}
 
+	s.state.TurnManager.InitiativeMode = mode
+	s.state.TurnManager.Breakdown = breakdown
+
 	// Initialize action points for all combat participants
+	restored := make([]*game.Player, 0, len(breakdown))
 	s.mu.RLock()
-	for _, participantID := range initiative {
+	for _, entry := range breakdown {
+		participantID := entry.EntityID
 		for _, session := range s.sessions {
 			if session.Player.GetID() == participantID {
 				session.Player.RestoreActionPoints()
+				restored = append(restored, session.Player)
 				logrus.WithFields(logrus.Fields{
 					"function":      "handleStartCombat",
 					"participantID": participantID,
@@ -620,20 +772,40 @@ func (s *RPCServer) handleStartCombat(params json.RawMessage) (interface{}, erro
 		}
 	}
 	s.mu.RUnlock()
+	for _, player := range restored {
+		s.pushActionPoints(player)
+	}
+
+	firstTurn := s.runCompanionTurns(initiative[0])
 
 	logrus.WithFields(logrus.Fields{
 		"function":  "handleStartCombat",
-		"firstTurn": initiative[0],
+		"firstTurn": firstTurn,
 	}).Info("combat started successfully")
 
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventCombatStart,
+		SourceID: req.SessionID,
+		Data: map[string]interface{}{
+			"initiative": initiative,
+			"surprised":  surprised,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+
 	logrus.WithFields(logrus.Fields{
 		"function": "handleStartCombat",
 	}).Debug("exiting handleStartCombat")
 
 	return map[string]interface{}{
-		"success":    true,
-		"initiative": initiative,
-		"first_turn": initiative[0],
+		"success":              true,
+		"initiative":           initiative,
+		"initiative_breakdown": breakdown,
+		"initiative_mode":      mode,
+		"surprised":            surprised,
+		"first_turn":           firstTurn,
+		"replay_id":            replay.ID,
+		"seed":                 replay.Seed,
 	}, nil
 }
 
@@ -679,7 +851,7 @@ func (s *RPCServer) handleEndTurn(params json.RawMessage) (interface{}, error) {
 			"function":  "handleEndTurn",
 			"sessionID": req.SessionID,
 		}).Warn("invalid session ID")
-		return nil, fmt.Errorf("invalid session")
+		return nil, ErrInvalidSession
 	}
 	defer s.releaseSession(session) // Ensure session is released when handler completes
 
@@ -687,7 +859,7 @@ func (s *RPCServer) handleEndTurn(params json.RawMessage) (interface{}, error) {
 		logrus.WithFields(logrus.Fields{
 			"function": "handleEndTurn",
 		}).Warn("attempted to end turn while not in combat")
-		return nil, fmt.Errorf("not in combat")
+		return nil, ErrNotInCombat
 	}
 
 	if !s.state.TurnManager.IsCurrentTurn(session.Player.GetID()) {
@@ -695,29 +867,71 @@ func (s *RPCServer) handleEndTurn(params json.RawMessage) (interface{}, error) {
 			"function": "handleEndTurn",
 			"playerID": session.Player.GetID(),
 		}).Warn("player attempted to end turn when not their turn")
-		return nil, fmt.Errorf("not your turn")
+		return nil, ErrNotYourTurn
 	}
 
+	s.state.TurnManager.ResetSkippedTurns(session.Player.GetID())
+	nextTurn := s.finishTurn(session.Player)
+
 	logrus.WithFields(logrus.Fields{
 		"function": "handleEndTurn",
-		"playerID": session.Player.GetID(),
-	}).Info("processing end of turn effects")
-	s.processEndTurnEffects(session.Player)
+	}).Debug("exiting handleEndTurn")
+
+	return map[string]interface{}{
+		"success":   true,
+		"next_turn": nextTurn,
+	}, nil
+}
+
+// finishTurn ends the current turn of actor, which may be nil for an
+// actor that is not a world object, and starts the next one: end of turn
+// effects apply, the turn advances, a new round begins when the order
+// wraps and the next player's action points are restored. Companions
+// whose turns come up next act on their own.
+//
+// Returns:
+//   - string: The ID of the entity whose turn begins
+func (s *RPCServer) finishTurn(actor game.GameObject) string {
+	return s.runCompanionTurns(s.advanceTurn(actor))
+}
+
+// advanceTurn ends the current turn of actor and starts the next one, as
+// finishTurn does without playing companion turns.
+func (s *RPCServer) advanceTurn(actor game.GameObject) string {
+	if actor != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "advanceTurn",
+			"actorID":  actor.GetID(),
+		}).Info("processing end of turn effects")
+		s.processEndTurnEffects(actor)
+		s.pushEffects(actor)
+	}
 
 	nextTurn := s.state.TurnManager.AdvanceTurn()
+	s.replays.advanceTurn()
+	if s.state.TurnManager.CurrentIndex == 0 {
+		logrus.WithFields(logrus.Fields{
+			"function": "advanceTurn",
+		}).Info("processing end of round")
+		s.processEndRound()
+		nextTurn = s.startInitiativeRound(nextTurn)
+	}
 	logrus.WithFields(logrus.Fields{
-		"function": "handleEndTurn",
+		"function": "advanceTurn",
 		"nextTurn": nextTurn,
 	}).Info("advanced to next turn")
 
-	// Restore action points for the next player
+	// Restore action points for the next player or NPC
 	if nextTurn != "" {
+		var restored *game.Player
 		s.mu.RLock()
 		for _, nextSession := range s.sessions {
 			if nextSession.Player.GetID() == nextTurn {
 				nextSession.Player.RestoreActionPoints()
+				s.chargeOpportunityCost(nextSession.Player)
+				restored = nextSession.Player
 				logrus.WithFields(logrus.Fields{
-					"function":     "handleEndTurn",
+					"function":     "advanceTurn",
 					"nextPlayerID": nextTurn,
 					"restoredAP":   nextSession.Player.GetActionPoints(),
 				}).Info("restored action points for next player")
@@ -725,23 +939,14 @@ func (s *RPCServer) handleEndTurn(params json.RawMessage) (interface{}, error) {
 			}
 		}
 		s.mu.RUnlock()
+		if restored != nil {
+			s.pushActionPoints(restored)
+		} else {
+			s.startNPCTurn(nextTurn)
+		}
 	}
 
-	if s.state.TurnManager.CurrentIndex == 0 {
-		logrus.WithFields(logrus.Fields{
-			"function": "handleEndTurn",
-		}).Info("processing end of round")
-		s.processEndRound()
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"function": "handleEndTurn",
-	}).Debug("exiting handleEndTurn")
-
-	return map[string]interface{}{
-		"success":   true,
-		"next_turn": nextTurn,
-	}, nil
+	return nextTurn
 }
 
 // handleGetGameState processes a request to retrieve the current game state for a given session.
@@ -789,7 +994,7 @@ func (s *RPCServer) handleEndTurn(params json.RawMessage) (interface{}, error) {
 	// 3. Validate server state
 	if s.state == nil {
 		logger.Error("game state not initialized")
-		return nil, fmt.Errorf("server state not initialized")
+		return nil, ErrUnavailable.WithMessage("Game state not initialized")
 	}
 
 	// 4. Get and validate session
@@ -807,7 +1012,7 @@ func (s *RPCServer) handleEndTurn(params json.RawMessage) (interface{}, error) {
 	// 6. Validate response
 	if state == nil {
 		logger.Error("failed to get game state")
-		return nil, fmt.Errorf("internal server error")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to get game state", nil)
 	}
 
 	logger.Debug("exiting handleGetGameState")
@@ -841,7 +1046,7 @@ func (s *RPCServer) handleGetGameState(params json.RawMessage) (interface{}, err
 	state := s.state.GetState()
 	if state == nil {
 		logger.Error("failed to get game state")
-		return nil, fmt.Errorf("internal server error")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to get game state", nil)
 	}
 
 	logger.Debug("exiting handleGetGameState")
@@ -857,11 +1062,18 @@ func (s *RPCServer) handleGetGameState(params json.RawMessage) (interface{}, err
 //   - target_id: string identifier for the target entity
 //   - magnitude: float64 indicating the strength/amount of the effect
 //   - duration: game.Duration specifying how long the effect lasts
+//   - damage_type: optional game.DamageType the effect deals
+//   - stacking: optional game.StackingPolicy for reapplying the effect type
+//   - max_stacks: optional stack cap under the "stack" policy
 //
 // Returns:
 // - interface{}: A map containing:
-//   - success: bool indicating if effect was applied
-//   - effect_id: string identifier for the created effect
+//   - success: bool indicating if effect took hold on the target
+//   - outcome, resistance, magnitude, stacks: the game.EffectResolution;
+//     an immune target or an ignored reapplication is not an error
+//   - effect_id: string identifier for the target's resulting active effect
+//   - journal_id: string identifier of the journal entry admin.undoLastAction
+//     reverts to remove the effect, when a new effect was added
 //
 // - error: Error if request fails due to:
 //   - Invalid JSON parameters
@@ -881,11 +1093,14 @@ func (s *RPCServer) handleApplyEffect(params json.RawMessage) (interface{}, erro
 	}).Debug("entering handleApplyEffect")
 
 	var req struct {
-		SessionID  string          `json:"session_id"`
-		EffectType game.EffectType `json:"effect_type"`
-		TargetID   string          `json:"target_id"`
-		Magnitude  float64         `json:"magnitude"`
-		Duration   game.Duration   `json:"duration"`
+		SessionID  string              `json:"session_id"`
+		EffectType game.EffectType     `json:"effect_type"`
+		TargetID   string              `json:"target_id"`
+		Magnitude  float64             `json:"magnitude"`
+		Duration   game.Duration       `json:"duration"`
+		DamageType game.DamageType     `json:"damage_type"`
+		Stacking   game.StackingPolicy `json:"stacking"`
+		MaxStacks  int                 `json:"max_stacks"`
 	}
 
 	if err := json.Unmarshal(params, &req); err != nil {
@@ -902,12 +1117,16 @@ func (s *RPCServer) handleApplyEffect(params json.RawMessage) (interface{}, erro
 			"function":  "handleApplyEffect",
 			"sessionID": req.SessionID,
 		}).Warn("invalid session ID")
-		return nil, fmt.Errorf("invalid session")
+		return nil, ErrInvalidSession
 	}
 
 	// Create and apply the effect
 	effect := game.NewEffect(req.EffectType, req.Duration, req.Magnitude)
 	effect.SourceID = session.Player.GetID()
+	effect.DamageType = req.DamageType
+	effect.Stacking = req.Stacking
+	effect.MaxStacks = req.MaxStacks
+	effect.StartRound = s.state.TurnManager.CurrentRound
 
 	logrus.WithFields(logrus.Fields{
 		"function":   "handleApplyEffect",
@@ -921,7 +1140,7 @@ func (s *RPCServer) handleApplyEffect(params json.RawMessage) (interface{}, erro
 			"function": "handleApplyEffect",
 			"targetID": req.TargetID,
 		}).Warn("invalid target ID")
-		return nil, fmt.Errorf("invalid target")
+		return nil, ErrInvalidTarget
 	}
 
 	effectHolder, ok := target.(game.EffectHolder)
@@ -930,10 +1149,11 @@ func (s *RPCServer) handleApplyEffect(params json.RawMessage) (interface{}, erro
 			"function": "handleApplyEffect",
 			"targetID": req.TargetID,
 		}).Warn("target cannot receive effects")
-		return nil, fmt.Errorf("target cannot receive effects")
+		return nil, ErrInvalidTarget.WithMessage("target cannot receive effects")
 	}
 
-	if err := effectHolder.AddEffect(effect); err != nil {
+	resolution, err := resolveEffect(effectHolder, effect)
+	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"function": "handleApplyEffect",
 			"error":    err.Error(),
@@ -943,16 +1163,61 @@ func (s *RPCServer) handleApplyEffect(params json.RawMessage) (interface{}, erro
 
 	logrus.WithFields(logrus.Fields{
 		"function": "handleApplyEffect",
-		"effectID": effect.ID,
-	}).Info("effect successfully applied")
+		"effectID": resolution.EffectID,
+		"outcome":  resolution.Outcome,
+	}).Info("effect resolved")
+
+	response := map[string]interface{}{
+		"success":    resolution.Applied(),
+		"outcome":    resolution.Outcome,
+		"effect_id":  resolution.EffectID,
+		"resistance": resolution.Resistance,
+		"magnitude":  resolution.Magnitude,
+		"stacks":     resolution.Stacks,
+	}
+	if !resolution.Applied() {
+		return response, nil
+	}
+
+	s.logCombat(CombatLogEntry{
+		Action:     CombatLogEffect,
+		AttackerID: effect.SourceID,
+		DefenderID: req.TargetID,
+		Source:     string(req.EffectType),
+		Effects:    []string{string(req.EffectType)},
+	})
+	if resolution.EffectID == effect.ID {
+		response["journal_id"] = s.journalEffect(req.SessionID, req.TargetID, effectHolder, effect)
+	}
+	s.pushEffects(target)
 
 	logrus.WithFields(logrus.Fields{
 		"function": "handleApplyEffect",
 	}).Debug("exiting handleApplyEffect")
 
-	return map[string]interface{}{
-		"success":   true,
-		"effect_id": effect.ID,
+	return response, nil
+}
+
+// effectResolver is implemented by effect holders that report how an
+// effect application was resolved, such as characters.
+type effectResolver interface {
+	ResolveEffect(effect *game.Effect) (game.EffectResolution, error)
+}
+
+// resolveEffect applies effect to holder. Holders that are not an
+// effectResolver report a plain application.
+func resolveEffect(holder game.EffectHolder, effect *game.Effect) (game.EffectResolution, error) {
+	if resolver, ok := holder.(effectResolver); ok {
+		return resolver.ResolveEffect(effect)
+	}
+	if err := holder.AddEffect(effect); err != nil {
+		return game.EffectResolution{}, err
+	}
+	return game.EffectResolution{
+		Outcome:   game.OutcomeApplied,
+		EffectID:  effect.ID,
+		Magnitude: effect.Magnitude,
+		Stacks:    effect.Stacks,
 	}, nil
 }
 
@@ -977,7 +1242,16 @@ func (s *RPCServer) handleJoinGame(params json.RawMessage) (interface{}, error)
 		logrus.WithFields(logrus.Fields{
 			"function": "handleJoinGame",
 		}).Warn("empty player name")
-		return nil, fmt.Errorf("player name is required")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "player name is required", nil)
+	}
+
+	resumeToken, err := newResumeToken()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "handleJoinGame",
+			"error":    err.Error(),
+		}).Error("failed to issue resume token")
+		return nil, err
 	}
 
 	// Create new session
@@ -988,9 +1262,12 @@ func (s *RPCServer) handleJoinGame(params json.RawMessage) (interface{}, error)
 		CreatedAt:   time.Now(),
 		LastActive:  time.Now(),
 		MessageChan: make(chan []byte, MessageChanBufferSize),
+		resumeToken: resumeToken,
+		events:      newEventBuffer(ResumeEventBufferSize),
 	}
 	s.sessions[sessionID] = session
 	s.mu.Unlock()
+	s.shareSession(session)
 
 	logrus.WithFields(logrus.Fields{
 		"function":    "handleJoinGame",
@@ -1006,8 +1283,10 @@ func (s *RPCServer) handleJoinGame(params json.RawMessage) (interface{}, error)
 	}).Debug("exiting handleJoinGame")
 
 	return map[string]interface{}{
-		"success":    true,
-		"session_id": session.SessionID,
+		"success":           true,
+		"session_id":        session.SessionID,
+		"resume_token":      resumeToken,
+		"resume_expires_in": int(s.resumeTimeout().Seconds()),
 	}, nil
 }
 
@@ -1017,10 +1296,16 @@ func (s *RPCServer) handleJoinGame(params json.RawMessage) (interface{}, error)
 //   - params: json.RawMessage containing:
 //   - name: string - Character name
 //   - class: string - Character class ("fighter", "mage", "cleric", "thief", "ranger", "paladin")
+//   - classes: []string - Classes of a multi-classed character, such as
+//     ["fighter", "mage"] (optional, replaces class)
 //   - attribute_method: string - Attribute generation method ("roll", "pointbuy", "standard", "custom")
 //   - custom_attributes: map[string]int - Custom attribute values (optional)
 //   - starting_equipment: bool - Whether to include starting equipment
 //   - starting_gold: int - Starting gold amount (optional)
+//   - background: string - Background ("soldier", "scholar", "outcast", "noble" or "random") (optional)
+//   - deity: string - Patron deity ID from the world's pantheon (optional,
+//     picked for clerics and paladins if omitted)
+//   - seed: int64 - Character seed for reproducible creation (optional, random if omitted)
 //
 // Returns:
 //   - interface{}: Map containing:
@@ -1030,6 +1315,10 @@ func (s *RPCServer) handleJoinGame(params json.RawMessage) (interface{}, error)
 //   - session_id: Session ID for the new character
 //   - errors: List of any error messages
 //   - warnings: List of any warning messages
+//   - seed: The character seed, to recreate the same character
+//   - background: The applied background definition, if any
+//   - hook_quest: The background's personal hook quest, if any
+//   - deity: The patron deity, if any
 //
 // Errors:
 //   - "invalid character creation parameters" if JSON unmarshaling fails
@@ -1050,7 +1339,12 @@ func (s *RPCServer) handleCreateCharacter(params json.RawMessage) (interface{},
 		return nil, err
 	}
 
-	result := s.createNewCharacter(config)
+	seed := req.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	result := s.createNewCharacter(config, seed)
 	if !result.Success {
 		logrus.WithFields(logrus.Fields{
 			"function": "handleCreateCharacter",
@@ -1063,6 +1357,10 @@ func (s *RPCServer) handleCreateCharacter(params json.RawMessage) (interface{},
 		}, nil
 	}
 
+	hookQuest := s.startBackgroundHookQuest(result, seed)
+	portrait := pcg.GeneratePortrait(seed, &result.PlayerData.Character)
+	result.PlayerData.SetPortrait(portrait)
+	result.Character.SetPortrait(portrait)
 	session := s.createAndRegisterSession(result.PlayerData)
 
 	logrus.WithFields(logrus.Fields{
@@ -1070,6 +1368,8 @@ func (s *RPCServer) handleCreateCharacter(params json.RawMessage) (interface{},
 		"sessionID":     session.SessionID,
 		"characterName": req.Name,
 		"class":         req.Class,
+		"classes":       req.Classes,
+		"background":    result.PlayerData.Background,
 	}).Info("character created successfully")
 
 	return map[string]interface{}{
@@ -1082,6 +1382,77 @@ func (s *RPCServer) handleCreateCharacter(params json.RawMessage) (interface{},
 		"creation_time":   result.CreationTime,
 		"generated_stats": result.GeneratedStats,
 		"starting_items":  result.StartingItems,
+		"seed":            seed,
+		"background":      result.Background,
+		"hook_quest":      hookQuest,
+		"deity":           result.Deity,
+		"portrait":        portrait,
+	}, nil
+}
+
+// handleChangeClass dual-classes the session's player into a new class.
+// The current class is kept at its level but earns no more experience and
+// cannot be used until the new class, which starts at level 1, exceeds it.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The requesting session
+//   - class: string - The new class
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - class: The player's new class
+//   - classes: The player's progress in each class
+//   - active_classes: Classes whose abilities can be used
+//   - error: Invalid parameters or session, or ErrClassChangeDenied when
+//     the player does not qualify for the new class
+func (s *RPCServer) handleChangeClass(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleChangeClass",
+	})
+	logger.Debug("entering handleChangeClass")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		Class     string `json:"class"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid change class parameters", err.Error())
+	}
+
+	class, exists := characterClasses[req.Class]
+	if !exists {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("invalid character class: %s", req.Class), nil)
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	player := session.Player
+
+	if err := game.NewCharacterCreator().ChangeClass(player, class); err != nil {
+		logger.WithError(err).WithField("class", req.Class).Info("class change denied")
+		return nil, ErrClassChangeDenied.WithMessage("%v", err)
+	}
+
+	active := make([]string, 0)
+	for _, activeClass := range player.ActiveClasses() {
+		active = append(active, activeClass.String())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"player_id": player.GetID(),
+		"class":     class.String(),
+	}).Info("player changed class")
+
+	return map[string]interface{}{
+		"success":        true,
+		"class":          class.String(),
+		"classes":        player.GetClassProgress(),
+		"active_classes": active,
 	}, nil
 }
 
@@ -1089,10 +1460,14 @@ func (s *RPCServer) handleCreateCharacter(params json.RawMessage) (interface{},
 type createCharacterRequest struct {
 	Name              string         `json:"name"`
 	Class             string         `json:"class"`
+	Classes           []string       `json:"classes,omitempty"`
 	AttributeMethod   string         `json:"attribute_method"`
 	CustomAttributes  map[string]int `json:"custom_attributes,omitempty"`
 	StartingEquipment bool           `json:"starting_equipment"`
 	StartingGold      int            `json:"starting_gold"`
+	Background        string         `json:"background,omitempty"`
+	Deity             string         `json:"deity,omitempty"`
+	Seed              int64          `json:"seed,omitempty"`
 }
 
 // parseCharacterCreationRequest unmarshals the raw JSON into a createCharacterRequest struct.
@@ -1108,24 +1483,42 @@ func (s *RPCServer) parseCharacterCreationRequest(params json.RawMessage) (*crea
 	return &req, nil
 }
 
+// characterClasses maps the class names accepted over RPC to classes.
+var characterClasses = map[string]game.CharacterClass{
+	"fighter": game.ClassFighter,
+	"mage":    game.ClassMage,
+	"cleric":  game.ClassCleric,
+	"thief":   game.ClassThief,
+	"ranger":  game.ClassRanger,
+	"paladin": game.ClassPaladin,
+}
+
 // buildCharacterConfig creates the character configuration from the request.
+// A multi-class request lists its classes in classes; its first class is
+// the character's primary class.
 func (s *RPCServer) buildCharacterConfig(req *createCharacterRequest) (*game.CharacterCreationConfig, error) {
-	classMap := map[string]game.CharacterClass{
-		"fighter": game.ClassFighter,
-		"mage":    game.ClassMage,
-		"cleric":  game.ClassCleric,
-		"thief":   game.ClassThief,
-		"ranger":  game.ClassRanger,
-		"paladin": game.ClassPaladin,
+	var classes []game.CharacterClass
+	for _, name := range req.Classes {
+		class, exists := characterClasses[name]
+		if !exists {
+			return nil, NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("invalid character class: %s", name), nil)
+		}
+		classes = append(classes, class)
+	}
+	if len(req.Classes) > 0 {
+		req.Class = req.Classes[0]
+	}
+	if len(classes) < 2 {
+		classes = nil // A single class is not a multi-class
 	}
 
-	characterClass, exists := classMap[req.Class]
+	characterClass, exists := characterClasses[req.Class]
 	if !exists {
 		logrus.WithFields(logrus.Fields{
 			"function": "buildCharacterConfig",
 			"class":    req.Class,
 		}).Error("invalid character class")
-		return nil, fmt.Errorf("invalid character class: %s", req.Class)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("invalid character class: %s", req.Class), nil)
 	}
 
 	if req.StartingGold == 0 {
@@ -1140,25 +1533,77 @@ func (s *RPCServer) buildCharacterConfig(req *createCharacterRequest) (*game.Cha
 		req.StartingGold = defaultGold[characterClass]
 	}
 
+	background, err := game.ParseBackground(req.Background)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":   "buildCharacterConfig",
+			"background": req.Background,
+		}).Error("invalid character background")
+		return nil, err
+	}
+
 	return &game.CharacterCreationConfig{
 		Name:              req.Name,
 		Class:             characterClass,
+		Classes:           classes,
 		AttributeMethod:   req.AttributeMethod,
 		CustomAttributes:  req.CustomAttributes,
 		StartingEquipment: req.StartingEquipment,
 		StartingGold:      req.StartingGold,
+		Background:        background,
+		Deity:             req.Deity,
+		Pantheon:          s.worldPantheon(),
 	}, nil
 }
 
 // createNewCharacter uses the CharacterCreator to create a new character based on the config.
-func (s *RPCServer) createNewCharacter(config *game.CharacterCreationConfig) *game.CharacterCreationResult {
-	creator := game.NewCharacterCreator()
+// The creator is seeded with seed, so the same config and seed produce the same character.
+func (s *RPCServer) createNewCharacter(config *game.CharacterCreationConfig, seed int64) *game.CharacterCreationResult {
+	creator := game.NewCharacterCreatorWithSeed(seed)
 	result := creator.CreateCharacter(*config)
 	return &result
 }
 
-// createAndRegisterSession creates a new player session and registers it with the server.
+// startBackgroundHookQuest generates the personal hook quest for the new
+// character's background and starts it in the player's quest log. The quest
+// is derived from the character seed. A quest that cannot be generated is
+// reported as a creation warning rather than failing character creation.
+func (s *RPCServer) startBackgroundHookQuest(result *game.CharacterCreationResult, seed int64) *game.Quest {
+	if result.Background == nil || result.Background.HookQuestType == "" || s.pcgManager == nil {
+		return nil
+	}
+
+	quest, err := s.pcgManager.GeneratePersonalQuest(context.Background(), seed,
+		pcg.QuestType(result.Background.HookQuestType), result.PlayerData.Level)
+	if err == nil {
+		err = result.PlayerData.StartQuest(*quest)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":   "startBackgroundHookQuest",
+			"background": result.Background.Background,
+			"error":      err.Error(),
+		}).Warn("failed to create background hook quest")
+		result.Warnings = append(result.Warnings, fmt.Sprintf("background hook quest unavailable: %v", err))
+		return nil
+	}
+
+	// Return the quest as it now appears in the quest log
+	started, _ := result.PlayerData.GetQuest(quest.ID)
+	return started
+}
+
+// createAndRegisterSession creates a new player session, registers it with
+// the server and shares it with the other server instances.
 func (s *RPCServer) createAndRegisterSession(playerData *game.Player) *PlayerSession {
+	session := s.registerNewSession(playerData)
+	s.shareSession(session)
+	return session
+}
+
+// registerNewSession creates a player session under a fresh ID and adds it
+// to the sessions map.
+func (s *RPCServer) registerNewSession(playerData *game.Player) *PlayerSession {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -1222,7 +1667,7 @@ func (s *RPCServer) handleEquipItem(params json.RawMessage) (interface{}, error)
 			"function": "handleEquipItem",
 			"slot":     req.Slot,
 		}).Error("invalid equipment slot")
-		return nil, fmt.Errorf("invalid equipment slot: %s", req.Slot)
+		return nil, ErrInvalidSlot.WithMessage("invalid equipment slot: %s", req.Slot).WithData(map[string]interface{}{"slot": req.Slot})
 	}
 
 	// Check if there's a previously equipped item
@@ -1318,7 +1763,7 @@ func (s *RPCServer) handleUnequipItem(params json.RawMessage) (interface{}, erro
 			"function": "handleUnequipItem",
 			"slot":     req.Slot,
 		}).Error("invalid equipment slot")
-		return nil, fmt.Errorf("invalid equipment slot: %s", req.Slot)
+		return nil, ErrInvalidSlot.WithMessage("invalid equipment slot: %s", req.Slot).WithData(map[string]interface{}{"slot": req.Slot})
 	}
 
 	// Unequip the item
@@ -1418,6 +1863,157 @@ func (s *RPCServer) handleGetEquipment(params json.RawMessage) (interface{}, err
 	}, nil
 }
 
+// handleRegisterReaction holds a combat reaction for the player. Held
+// reactions are offered to the player as prompts when their trigger fires
+// during another entity's turn.
+//
+// Parameters (JSON):
+//   - session_id: string - Player session identifier
+//   - reaction_type: string - attack_of_opportunity, shield_block or counterspell
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating if the reaction was registered
+//   - reaction: the held reaction
+//
+// Errors:
+//   - "invalid session" if session is not found
+//   - "not in combat" if no combat is active
+//   - unknown or duplicate reaction types
+func (s *RPCServer) handleRegisterReaction(params json.RawMessage) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "handleRegisterReaction",
+	}).Debug("entering handleRegisterReaction")
+
+	var req struct {
+		SessionID    string       `json:"session_id"`
+		ReactionType ReactionType `json:"reaction_type"`
+	}
+
+	if err := json.Unmarshal(params, &req); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "handleRegisterReaction",
+			"error":    err.Error(),
+		}).Error("failed to unmarshal reaction parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid reaction parameters", err.Error())
+	}
+
+	session, err := s.getSessionSafely(req.SessionID)
+	if err != nil {
+		return nil, ErrInvalidSession
+	}
+	defer s.releaseSession(session)
+
+	reaction, err := s.state.TurnManager.RegisterReaction(session.Player.GetID(), req.ReactionType)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":     "handleRegisterReaction",
+			"playerID":     session.Player.GetID(),
+			"reactionType": req.ReactionType,
+			"error":        err.Error(),
+		}).Warn("failed to register reaction")
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success":  true,
+		"reaction": reaction,
+	}, nil
+}
+
+// handleCancelReaction drops a reaction the player is holding.
+//
+// Parameters (JSON):
+//   - session_id: string - Player session identifier
+//   - reaction_id: string - ID of the held reaction
+//
+// Returns:
+//   - interface{}: Map containing success: bool
+func (s *RPCServer) handleCancelReaction(params json.RawMessage) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "handleCancelReaction",
+	}).Debug("entering handleCancelReaction")
+
+	var req struct {
+		SessionID  string `json:"session_id"`
+		ReactionID string `json:"reaction_id"`
+	}
+
+	if err := json.Unmarshal(params, &req); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "handleCancelReaction",
+			"error":    err.Error(),
+		}).Error("failed to unmarshal reaction parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid reaction parameters", err.Error())
+	}
+
+	session, err := s.getSessionSafely(req.SessionID)
+	if err != nil {
+		return nil, ErrInvalidSession
+	}
+	defer s.releaseSession(session)
+
+	if err := s.state.TurnManager.CancelReaction(session.Player.GetID(), req.ReactionID); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success": true,
+	}, nil
+}
+
+// handleRespondReaction answers a reaction prompt sent to the player. The
+// triggering action waits for the answer, so this must arrive before the
+// prompt's deadline; late answers are rejected and the reaction is treated as
+// declined.
+//
+// Parameters (JSON):
+//   - session_id: string - Player session identifier
+//   - prompt_id: string - ID from the reaction prompt event
+//   - accept: bool - Whether to use the reaction
+//
+// Returns:
+//   - interface{}: Map containing success: bool
+func (s *RPCServer) handleRespondReaction(params json.RawMessage) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "handleRespondReaction",
+	}).Debug("entering handleRespondReaction")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		PromptID  string `json:"prompt_id"`
+		Accept    bool   `json:"accept"`
+	}
+
+	if err := json.Unmarshal(params, &req); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "handleRespondReaction",
+			"error":    err.Error(),
+		}).Error("failed to unmarshal reaction response parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid reaction response parameters", err.Error())
+	}
+
+	session, err := s.getSessionSafely(req.SessionID)
+	if err != nil {
+		return nil, ErrInvalidSession
+	}
+	defer s.releaseSession(session)
+
+	if err := s.state.TurnManager.RespondToPrompt(session.Player.GetID(), req.PromptID, req.Accept); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "handleRespondReaction",
+			"playerID": session.Player.GetID(),
+			"promptID": req.PromptID,
+			"error":    err.Error(),
+		}).Warn("failed to respond to reaction prompt")
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success": true,
+	}, nil
+}
+
 // parseEquipmentSlot converts a string slot name to an EquipmentSlot enum value
 func parseEquipmentSlot(slotName string) (game.EquipmentSlot, error) {
 	slotMap := map[string]game.EquipmentSlot{
@@ -1438,7 +2034,7 @@ func parseEquipmentSlot(slotName string) (game.EquipmentSlot, error) {
 		return slot, nil
 	}
 
-	return game.SlotHead, fmt.Errorf("unknown equipment slot: %s", slotName)
+	return game.SlotHead, ErrInvalidSlot.WithMessage("unknown equipment slot: %s", slotName).WithData(map[string]interface{}{"slot": slotName})
 }
 
 // equipmentSlotToString converts an EquipmentSlot enum value to a string
@@ -1468,12 +2064,16 @@ func (s *RPCServer) getPlayerSession(sessionID string) (*PlayerSession, error) {
 	session, exists := s.sessions[sessionID]
 	s.mu.RUnlock()
 
+	// Take over sessions shared by other server instances
 	if !exists {
-		return nil, fmt.Errorf("invalid session")
+		session, exists = s.adoptSession(sessionID)
+	}
+	if !exists {
+		return nil, ErrInvalidSession
 	}
 
 	if session.Player == nil {
-		return nil, fmt.Errorf("session has no associated player")
+		return nil, ErrNoPlayer
 	}
 
 	return session, nil
@@ -1488,12 +2088,14 @@ func (s *RPCServer) getPlayerSession(sessionID string) (*PlayerSession, error) {
 //   - quest: Quest object - The quest data to start
 //
 // Returns:
-//   - interface{}: Success response with quest ID if quest started successfully
+//   - interface{}: Success response with quest ID if quest started successfully,
+//     and the deadline tick and deadline_date of a quest with a time limit
 //   - error: Error if request fails due to:
 //   - Invalid request parameters
 //   - Session not found or inactive
 //   - Quest validation failures
 //   - Quest already exists in player's quest log
+//   - Quest giver unwilling to offer the quest to the player
 func (s *RPCServer) handleStartQuest(params json.RawMessage) (interface{}, error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"function": "handleStartQuest",
@@ -1503,13 +2105,14 @@ func (s *RPCServer) handleStartQuest(params json.RawMessage) (interface{}, error
 	var req struct {
 		SessionID string     `json:"session_id"`
 		Quest     game.Quest `json:"quest"`
+		QuestID   string     `json:"quest_id"` // A mounted bundle quest, when quest is omitted
 	}
 
 	if err := json.Unmarshal(params, &req); err != nil {
 		logger.WithError(err).WithFields(logrus.Fields{
 			"function": "handleStartQuest",
 		}).Error("failed to unmarshal request parameters")
-		return nil, fmt.Errorf("invalid request parameters: %w", err)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid request parameters", err.Error())
 	}
 
 	// Get player session
@@ -1519,7 +2122,20 @@ func (s *RPCServer) handleStartQuest(params json.RawMessage) (interface{}, error
 			"function":   "handleStartQuest",
 			"session_id": req.SessionID,
 		}).Error("failed to get player session")
-		return nil, fmt.Errorf("session error: %w", err)
+		return nil, err
+	}
+
+	if req.Quest.ID == "" && req.QuestID != "" {
+		quest, ok := s.bundleQuest(req.QuestID)
+		if !ok {
+			return nil, ErrQuestNotFound.WithMessage("no content bundle provides quest %s", req.QuestID)
+		}
+		req.Quest = *quest
+	}
+
+	// A quest's giver must be willing to offer it
+	if err := s.checkQuestGiver(session.Player, &req.Quest); err != nil {
+		return nil, err
 	}
 
 	// Start quest for player
@@ -1528,19 +2144,28 @@ func (s *RPCServer) handleStartQuest(params json.RawMessage) (interface{}, error
 			"function": "handleStartQuest",
 			"quest_id": req.Quest.ID,
 		}).Error("failed to start quest")
-		return nil, fmt.Errorf("failed to start quest: %w", err)
+		return nil, questError(session.Player, "start quest", req.Quest.ID, err)
 	}
+	s.emitQuestUpdate(session.Player, req.Quest.ID, "started")
+
+	// A time limit runs from acceptance
+	s.trackQuestDeadlines(session.Player)
 
 	logger.WithFields(logrus.Fields{
 		"function": "handleStartQuest",
 		"quest_id": req.Quest.ID,
 	}).Debug("exiting handleStartQuest")
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"success":  true,
 		"quest_id": req.Quest.ID,
 		"message":  "Quest started successfully",
-	}, nil
+	}
+	if quest, err := session.Player.GetQuest(req.Quest.ID); err == nil && quest.Deadline > 0 {
+		result["deadline"] = quest.Deadline
+		result["deadline_date"] = DateAt(quest.Deadline)
+	}
+	return result, nil
 }
 
 // handleCompleteQuest processes a request to complete a quest for a player.
@@ -1572,31 +2197,67 @@ func (s *RPCServer) handleCompleteQuest(params json.RawMessage) (interface{}, er
 	session, err := s.getPlayerSession(req.SessionID)
 	if err != nil {
 		logger.WithError(err).WithField("session_id", req.SessionID).Error("failed to get player session")
-		return nil, fmt.Errorf("session error: %w", err)
+		return nil, err
 	}
 
-	rewards, err := session.Player.CompleteQuest(req.QuestID)
+	if party := s.sharedQuestParty(session.Player.ID, req.QuestID); party != nil {
+		var split map[string][]game.QuestReward
+		consequences, err := s.applyQuestOutcome(session.Player, req.QuestID, game.QuestCompleted, func() error {
+			var err error
+			split, err = s.completeSharedQuest(party, session.Player, req.QuestID)
+			return err
+		})
+		if err != nil {
+			logger.WithError(err).WithField("quest_id", req.QuestID).Error("failed to complete shared quest")
+			return nil, questError(session.Player, "complete quest", req.QuestID, err)
+		}
+
+		logger.WithFields(logrus.Fields{
+			"quest_id": req.QuestID,
+			"party_id": party.ID,
+		}).Info("shared quest completed and rewards split")
+
+		return map[string]interface{}{
+			"success":       true,
+			"quest_id":      req.QuestID,
+			"rewards":       split[session.Player.ID],
+			"party_rewards": split,
+			"consequences":  consequences,
+			"message":       "Quest completed successfully",
+		}, nil
+	}
+
+	var rewards []game.QuestReward
+	consequences, err := s.applyQuestOutcome(session.Player, req.QuestID, game.QuestCompleted, func() error {
+		var err error
+		rewards, err = session.Player.CompleteQuest(req.QuestID)
+		return err
+	})
 	if err != nil {
 		logger.WithError(err).WithField("quest_id", req.QuestID).Error("failed to complete quest")
-		return nil, fmt.Errorf("failed to complete quest: %w", err)
+		return nil, questError(session.Player, "complete quest", req.QuestID, err)
 	}
 
 	if err := s.applyQuestRewards(session.Player, req.QuestID, rewards); err != nil {
 		return nil, err
 	}
+	s.emitQuestUpdate(session.Player, req.QuestID, "completed")
 
 	logger.WithFields(logrus.Fields{
 		"quest_id":     req.QuestID,
 		"reward_count": len(rewards),
 	}).Info("quest completed and all rewards applied")
 
+	s.fireQuestCompleteScripts(req.QuestID, session.Player.ID)
+
 	logger.WithField("quest_id", req.QuestID).Debug("exiting handleCompleteQuest")
 
 	return map[string]interface{}{
-		"success":  true,
-		"quest_id": req.QuestID,
-		"rewards":  rewards,
-		"message":  "Quest completed successfully",
+		"success":      true,
+		"quest_id":     req.QuestID,
+		"rewards":      rewards,
+		"consequences": consequences,
+		"message":      "Quest completed successfully",
 	}, nil
 }
 
@@ -1613,11 +2274,57 @@ func (s *RPCServer) parseCompleteQuestRequest(params json.RawMessage) (*complete
 		logrus.WithError(err).WithFields(logrus.Fields{
 			"function": "parseCompleteQuestRequest",
 		}).Error("failed to unmarshal request parameters")
-		return nil, fmt.Errorf("invalid request parameters: %w", err)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid request parameters", err.Error())
 	}
 	return &req, nil
 }
 
+// emitQuestUpdate notifies the player that one of their quests was
+// started, completed or failed
+func (s *RPCServer) emitQuestUpdate(player *game.Player, questID, action string) {
+	data := map[string]interface{}{
+		"quest_id": questID,
+		"action":   action,
+	}
+	if quest, err := player.GetQuest(questID); err == nil {
+		data["title"] = quest.Title
+	}
+	s.eventSys.Emit(game.GameEvent{
+		Type:      game.EventQuestUpdate,
+		SourceID:  player.GetID(),
+		TargetID:  player.GetID(),
+		Data:      data,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// applyQuestOutcome ends a quest with outcome through commit and executes
+// the quest's world consequences in the same transaction. NPCs killed by a
+// consequence are announced with a death event.
+func (s *RPCServer) applyQuestOutcome(player *game.Player, questID string, outcome game.QuestStatus, commit func() error) ([]game.QuestConsequence, error) {
+	// A missing quest has no consequences; commit reports the error
+	quest, _ := player.GetQuest(questID)
+
+	consequences, err := s.state.WorldState.ApplyQuestOutcome(player, quest, outcome, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, consequence := range consequences {
+		if consequence.Type == game.ConsequenceNPCDeath {
+			s.eventSys.Emit(game.GameEvent{
+				Type:     game.EventDeath,
+				SourceID: consequence.Target,
+				Data: map[string]interface{}{
+					"cause":    "quest_consequence",
+					"quest_id": questID,
+				},
+			})
+		}
+	}
+	return consequences, nil
+}
+
 // applyQuestRewards processes and applies all rewards for a completed quest.
 func (s *RPCServer) applyQuestRewards(player *game.Player, questID string, rewards []game.QuestReward) error {
 	for _, reward := range rewards {
@@ -1652,9 +2359,10 @@ func (s *RPCServer) applyExperienceReward(player *game.Player, questID string, r
 		"value":       reward.Value,
 	})
 
-	if err := player.AddExperience(int64(reward.Value)); err != nil {
+	experience := s.shareReward(player.ID, reward.Value, true)
+	if err := player.AddExperience(int64(experience)); err != nil {
 		logger.WithError(err).Error("failed to apply experience reward")
-		return fmt.Errorf("failed to apply experience reward: %w", err)
+		return ErrQuestRejected.WithMessage("failed to apply experience reward: %v", err).WithData(map[string]interface{}{"quest_id": questID})
 	}
 	logger.Info("applied experience reward")
 	return nil
@@ -1663,11 +2371,12 @@ func (s *RPCServer) applyExperienceReward(player *game.Player, questID string, r
 // applyGoldReward applies a gold reward to the player.
 func (s *RPCServer) applyGoldReward(player *game.Player, questID string, reward game.QuestReward) {
 	previousGold := player.Character.Gold
-	player.Character.Gold += reward.Value
+	gold := s.shareReward(player.ID, reward.Value, false)
+	player.Character.Gold += gold
 	logrus.WithFields(logrus.Fields{
 		"function":      "applyGoldReward",
 		"quest_id":      questID,
-		"gold_added":    reward.Value,
+		"gold_added":    gold,
 		"previous_gold": previousGold,
 		"new_gold":      player.Character.Gold,
 	}).Info("applied gold reward")
@@ -1693,7 +2402,7 @@ func (s *RPCServer) applyItemReward(player *game.Player, questID string, reward
 	}
 	if err := player.Character.AddItemToInventory(item); err != nil {
 		logger.WithError(err).Error("failed to apply item reward")
-		return fmt.Errorf("failed to apply item reward: %w", err)
+		return ErrQuestRejected.WithMessage("failed to apply item reward: %v", err).WithData(map[string]interface{}{"quest_id": questID})
 	}
 	logger.Info("applied item reward")
 	return nil
@@ -1733,7 +2442,7 @@ func (s *RPCServer) handleUpdateObjective(params json.RawMessage) (interface{},
 		logger.WithError(err).WithFields(logrus.Fields{
 			"function": "handleUpdateObjective",
 		}).Error("failed to unmarshal request parameters")
-		return nil, fmt.Errorf("invalid request parameters: %w", err)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid request parameters", err.Error())
 	}
 
 	// Get player session
@@ -1743,7 +2452,27 @@ func (s *RPCServer) handleUpdateObjective(params json.RawMessage) (interface{},
 			"function":   "handleUpdateObjective",
 			"session_id": req.SessionID,
 		}).Error("failed to get player session")
-		return nil, fmt.Errorf("session error: %w", err)
+		return nil, err
+	}
+
+	if party := s.sharedQuestParty(session.Player.ID, req.QuestID); party != nil {
+		progress, err := s.updateSharedObjective(party, session.Player, req.QuestID, req.ObjectiveIndex, req.Progress)
+		if err != nil {
+			logger.WithError(err).WithFields(logrus.Fields{
+				"quest_id":        req.QuestID,
+				"objective_index": req.ObjectiveIndex,
+			}).Error("failed to update shared quest objective")
+			return nil, questError(session.Player, "update quest objective", req.QuestID, err)
+		}
+
+		return map[string]interface{}{
+			"success":         true,
+			"quest_id":        req.QuestID,
+			"objective_index": req.ObjectiveIndex,
+			"progress":        progress,
+			"party_id":        party.ID,
+			"message":         "Shared quest objective updated successfully",
+		}, nil
 	}
 
 	// Update quest objective for player
@@ -1753,7 +2482,7 @@ func (s *RPCServer) handleUpdateObjective(params json.RawMessage) (interface{},
 			"quest_id":        req.QuestID,
 			"objective_index": req.ObjectiveIndex,
 		}).Error("failed to update quest objective")
-		return nil, fmt.Errorf("failed to update quest objective: %w", err)
+		return nil, questError(session.Player, "update quest objective", req.QuestID, err)
 	}
 
 	logger.WithFields(logrus.Fields{
@@ -1801,7 +2530,7 @@ func (s *RPCServer) handleFailQuest(params json.RawMessage) (interface{}, error)
 		logger.WithError(err).WithFields(logrus.Fields{
 			"function": "handleFailQuest",
 		}).Error("failed to unmarshal request parameters")
-		return nil, fmt.Errorf("invalid request parameters: %w", err)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid request parameters", err.Error())
 	}
 
 	// Get player session
@@ -1811,17 +2540,21 @@ func (s *RPCServer) handleFailQuest(params json.RawMessage) (interface{}, error)
 			"function":   "handleFailQuest",
 			"session_id": req.SessionID,
 		}).Error("failed to get player session")
-		return nil, fmt.Errorf("session error: %w", err)
+		return nil, err
 	}
 
-	// Fail quest for player
-	if err := session.Player.FailQuest(req.QuestID); err != nil {
+	// Fail quest for player along with its failure consequences
+	consequences, err := s.applyQuestOutcome(session.Player, req.QuestID, game.QuestFailed, func() error {
+		return session.Player.FailQuest(req.QuestID)
+	})
+	if err != nil {
 		logger.WithError(err).WithFields(logrus.Fields{
 			"function": "handleFailQuest",
 			"quest_id": req.QuestID,
 		}).Error("failed to fail quest")
-		return nil, fmt.Errorf("failed to fail quest: %w", err)
+		return nil, questError(session.Player, "fail quest", req.QuestID, err)
 	}
+	s.emitQuestUpdate(session.Player, req.QuestID, "failed")
 
 	logger.WithFields(logrus.Fields{
 		"function": "handleFailQuest",
@@ -1829,9 +2562,10 @@ func (s *RPCServer) handleFailQuest(params json.RawMessage) (interface{}, error)
 	}).Debug("exiting handleFailQuest")
 
 	return map[string]interface{}{
-		"success":  true,
-		"quest_id": req.QuestID,
-		"message":  "Quest failed successfully",
+		"success":      true,
+		"quest_id":     req.QuestID,
+		"consequences": consequences,
+		"message":      "Quest failed successfully",
 	}, nil
 }
 
@@ -1864,7 +2598,7 @@ func (s *RPCServer) handleGetQuest(params json.RawMessage) (interface{}, error)
 		logger.WithError(err).WithFields(logrus.Fields{
 			"function": "handleGetQuest",
 		}).Error("failed to unmarshal request parameters")
-		return nil, fmt.Errorf("invalid request parameters: %w", err)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid request parameters", err.Error())
 	}
 
 	// Get player session
@@ -1874,7 +2608,7 @@ func (s *RPCServer) handleGetQuest(params json.RawMessage) (interface{}, error)
 			"function":   "handleGetQuest",
 			"session_id": req.SessionID,
 		}).Error("failed to get player session")
-		return nil, fmt.Errorf("session error: %w", err)
+		return nil, err
 	}
 
 	// Get quest from player
@@ -1884,7 +2618,7 @@ func (s *RPCServer) handleGetQuest(params json.RawMessage) (interface{}, error)
 			"function": "handleGetQuest",
 			"quest_id": req.QuestID,
 		}).Error("failed to get quest")
-		return nil, fmt.Errorf("failed to get quest: %w", err)
+		return nil, questError(session.Player, "get quest", req.QuestID, err)
 	}
 
 	logger.WithFields(logrus.Fields{
@@ -1924,7 +2658,7 @@ func (s *RPCServer) handleGetActiveQuests(params json.RawMessage) (interface{},
 		logger.WithError(err).WithFields(logrus.Fields{
 			"function": "handleGetActiveQuests",
 		}).Error("failed to unmarshal request parameters")
-		return nil, fmt.Errorf("invalid request parameters: %w", err)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid request parameters", err.Error())
 	}
 
 	// Get player session
@@ -1934,7 +2668,7 @@ func (s *RPCServer) handleGetActiveQuests(params json.RawMessage) (interface{},
 			"function":   "handleGetActiveQuests",
 			"session_id": req.SessionID,
 		}).Error("failed to get player session")
-		return nil, fmt.Errorf("session error: %w", err)
+		return nil, err
 	}
 
 	// Get active quests from player
@@ -1978,7 +2712,7 @@ func (s *RPCServer) handleGetCompletedQuests(params json.RawMessage) (interface{
 		logger.WithError(err).WithFields(logrus.Fields{
 			"function": "handleGetCompletedQuests",
 		}).Error("failed to unmarshal request parameters")
-		return nil, fmt.Errorf("invalid request parameters: %w", err)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid request parameters", err.Error())
 	}
 
 	// Get player session
@@ -1988,7 +2722,7 @@ func (s *RPCServer) handleGetCompletedQuests(params json.RawMessage) (interface{
 			"function":   "handleGetCompletedQuests",
 			"session_id": req.SessionID,
 		}).Error("failed to get player session")
-		return nil, fmt.Errorf("session error: %w", err)
+		return nil, err
 	}
 
 	// Get completed quests from player
@@ -2032,7 +2766,7 @@ func (s *RPCServer) handleGetQuestLog(params json.RawMessage) (interface{}, erro
 		logger.WithError(err).WithFields(logrus.Fields{
 			"function": "handleGetQuestLog",
 		}).Error("failed to unmarshal request parameters")
-		return nil, fmt.Errorf("invalid request parameters: %w", err)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid request parameters", err.Error())
 	}
 
 	// Get player session
@@ -2042,7 +2776,7 @@ func (s *RPCServer) handleGetQuestLog(params json.RawMessage) (interface{}, erro
 			"function":   "handleGetQuestLog",
 			"session_id": req.SessionID,
 		}).Error("failed to get player session")
-		return nil, fmt.Errorf("session error: %w", err)
+		return nil, err
 	}
 
 	// Get complete quest log from player
@@ -2093,7 +2827,7 @@ func (s *RPCServer) handleGetSpell(params json.RawMessage) (interface{}, error)
 	}
 
 	if req.SpellID == "" {
-		return nil, fmt.Errorf("spell ID cannot be empty")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "spell ID cannot be empty", nil)
 	}
 
 	spell, err := s.spellManager.GetSpell(req.SpellID)
@@ -2141,11 +2875,11 @@ func (s *RPCServer) handleGetSpellsByLevel(params json.RawMessage) (interface{},
 			"function": "handleGetSpellsByLevel",
 			"error":    err.Error(),
 		}).Error("failed to unmarshal get spells by level parameters")
-		return nil, fmt.Errorf("invalid get spells by level parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid get spells by level parameters", nil)
 	}
 
 	if req.Level < 0 {
-		return nil, fmt.Errorf("spell level cannot be negative")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "spell level cannot be negative", nil)
 	}
 
 	spells := s.spellManager.GetSpellsByLevel(req.Level)
@@ -2190,11 +2924,11 @@ func (s *RPCServer) handleGetSpellsBySchool(params json.RawMessage) (interface{}
 			"function": "handleGetSpellsBySchool",
 			"error":    err.Error(),
 		}).Error("failed to unmarshal get spells by school parameters")
-		return nil, fmt.Errorf("invalid get spells by school parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid get spells by school parameters", nil)
 	}
 
 	if req.School == "" {
-		return nil, fmt.Errorf("school cannot be empty")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "school cannot be empty", nil)
 	}
 
 	school := game.ParseSpellSchool(req.School)
@@ -2270,11 +3004,11 @@ func (s *RPCServer) handleSearchSpells(params json.RawMessage) (interface{}, err
 			"function": "handleSearchSpells",
 			"error":    err.Error(),
 		}).Error("failed to unmarshal search spells parameters")
-		return nil, fmt.Errorf("invalid search spells parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "invalid search spells parameters", nil)
 	}
 
 	if req.Query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "search query cannot be empty", nil)
 	}
 
 	spells := s.spellManager.SearchSpells(req.Query)
@@ -2526,7 +3260,7 @@ func (s *RPCServer) parseAndValidateUseItemRequest(params json.RawMessage) (*use
 		logrus.WithFields(logrus.Fields{
 			"function": "parseAndValidateUseItemRequest",
 		}).Warn("empty item ID")
-		return nil, fmt.Errorf("item ID is required")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "item ID is required", nil)
 	}
 
 	return &req, nil
@@ -2540,7 +3274,7 @@ func (s *RPCServer) validateCombatTurnForItemUse(player *game.Player) error {
 				"function": "validateCombatTurnForItemUse",
 				"playerID": player.GetID(),
 			}).Warn("player attempted to use item when not their turn")
-			return fmt.Errorf("not your turn")
+			return ErrNotYourTurn
 		}
 	}
 	return nil
@@ -2554,7 +3288,11 @@ func (s *RPCServer) executeItemUsage(player *game.Player, itemID, targetID strin
 			"function": "executeItemUsage",
 			"itemID":   itemID,
 		}).Error("failed to find item in inventory")
-		return "", fmt.Errorf("item %s not found in inventory", itemID)
+		return "", ErrItemNotFound.WithMessage("item %s not found in inventory", itemID).WithData(map[string]interface{}{"item_id": itemID})
+	}
+
+	if source, ok := game.LightSourceOf(item); ok {
+		return s.kindleLight(player, item, source), nil
 	}
 
 	effect := fmt.Sprintf("Used %s", item.Name)
@@ -2658,7 +3396,7 @@ func (s *RPCServer) parseLeaveGameRequest(params json.RawMessage) (string, error
 			"function": "parseLeaveGameRequest",
 			"error":    err.Error(),
 		}).Error("failed to unmarshal leave game parameters")
-		return "", fmt.Errorf("invalid leave game parameters")
+		return "", NewJSONRPCError(JSONRPCInvalidParams, "invalid leave game parameters", nil)
 	}
 
 	if req.SessionID == "" {
@@ -2719,9 +3457,13 @@ func (s *RPCServer) executeSessionCleanup(sessionID string) error {
 
 	// Remove player from game state
 	s.removePlayerFromGameState(session)
+	if s.encounters != nil && session.Player != nil {
+		s.encounters.Forget(session.Player.GetID())
+	}
 
-	// Remove session from sessions map
+	// Remove session from sessions map and the shared session store
 	delete(s.sessions, sessionID)
+	defer s.unshareSession(sessionID)
 
 	logrus.WithFields(logrus.Fields{
 		"function":  "executeSessionCleanup",
@@ -2768,7 +3510,12 @@ func (s *RPCServer) handleLeaveGame(params json.RawMessage) (interface{}, error)
 
 // PCG (Procedural Content Generation) handlers
 
-// handleGenerateContent generates procedural content on demand
+// handleGenerateContent generates procedural content on demand. With
+// "preview": true the content is held in the preview cache instead, and is
+// only integrated into the world once commitGeneratedContent is called with
+// the returned preview_id. With "async": true the generation runs as a
+// background job and the returned job_id is polled with
+// getGenerationJobStatus, whose result is this method's response.
 func (s *RPCServer) handleGenerateContent(params json.RawMessage) (interface{}, error) {
 	logrus.WithFields(logrus.Fields{
 		"function": "handleGenerateContent",
@@ -2791,32 +3538,50 @@ func (s *RPCServer) handleGenerateContent(params json.RawMessage) (interface{},
 
 	s.applyContentGenerationDefaults(req)
 
-	content, err := s.executeContentGeneration(req)
+	var mode struct {
+		Preview bool `json:"preview"`
+		Async   bool `json:"async"`
+	}
+	_ = json.Unmarshal(params, &mode) // Already parsed successfully above
+	if mode.Async {
+		return s.submitContentGenerationJob(req, mode.Preview)
+	}
+
+	return s.completeContentGeneration(context.Background(), req, mode.Preview)
+}
+
+// completeContentGeneration generates the requested content and builds the
+// generateContent response, holding the content for preview if requested.
+func (s *RPCServer) completeContentGeneration(ctx context.Context, req *contentGenerationRequest, preview bool) (map[string]interface{}, error) {
+	content, err := s.executeContentGeneration(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
 	s.logContentGenerationSuccess(req)
 
+	if preview {
+		return s.previewGeneratedContent(req.SessionID, pcg.ContentType(req.ContentType), req.LocationID, content), nil
+	}
+
+	s.notifyWorldGenerated(pcg.ContentType(req.ContentType), req.LocationID)
+
 	return s.buildContentGenerationResponse(req, content), nil
 }
 
-// parseContentGenerationRequest extracts and validates content generation parameters from JSON.
-func (s *RPCServer) parseContentGenerationRequest(params json.RawMessage) (*struct {
+// contentGenerationRequest holds the parameters of generateContent
+type contentGenerationRequest struct {
 	SessionID   string                 `json:"session_id"`
 	ContentType string                 `json:"content_type"`
 	LocationID  string                 `json:"location_id"`
 	Difficulty  int                    `json:"difficulty"`
 	Constraints map[string]interface{} `json:"constraints"`
-}, error,
-) {
-	var req struct {
-		SessionID   string                 `json:"session_id"`
-		ContentType string                 `json:"content_type"`
-		LocationID  string                 `json:"location_id"`
-		Difficulty  int                    `json:"difficulty"`
-		Constraints map[string]interface{} `json:"constraints"`
-	}
+	Generator   string                 `json:"generator"`
+}
+
+// parseContentGenerationRequest extracts and validates content generation parameters from JSON.
+func (s *RPCServer) parseContentGenerationRequest(params json.RawMessage) (*contentGenerationRequest, error) {
+	var req contentGenerationRequest
 
 	if err := json.Unmarshal(params, &req); err != nil {
 		logrus.WithFields(logrus.Fields{
@@ -2839,52 +3604,40 @@ func (s *RPCServer) validateContentGenerationSession(sessionID string) (*PlayerS
 }
 
 // validateContentGenerationParameters checks that required content generation parameters are present.
-func (s *RPCServer) validateContentGenerationParameters(req *struct {
-	SessionID   string                 `json:"session_id"`
-	ContentType string                 `json:"content_type"`
-	LocationID  string                 `json:"location_id"`
-	Difficulty  int                    `json:"difficulty"`
-	Constraints map[string]interface{} `json:"constraints"`
-},
-) error {
+func (s *RPCServer) validateContentGenerationParameters(req *contentGenerationRequest) error {
 	if req.ContentType == "" {
-		return fmt.Errorf("content_type parameter required")
+		return NewJSONRPCError(JSONRPCInvalidParams, "content_type parameter required", nil)
 	}
 
 	if req.LocationID == "" {
-		return fmt.Errorf("location_id parameter required")
+		return NewJSONRPCError(JSONRPCInvalidParams, "location_id parameter required", nil)
 	}
 
 	return nil
 }
 
 // applyContentGenerationDefaults sets default values for optional content generation parameters.
-func (s *RPCServer) applyContentGenerationDefaults(req *struct {
-	SessionID   string                 `json:"session_id"`
-	ContentType string                 `json:"content_type"`
-	LocationID  string                 `json:"location_id"`
-	Difficulty  int                    `json:"difficulty"`
-	Constraints map[string]interface{} `json:"constraints"`
-},
-) {
+func (s *RPCServer) applyContentGenerationDefaults(req *contentGenerationRequest) {
 	if req.Difficulty == 0 {
 		req.Difficulty = 5 // Default difficulty
 	}
 }
 
-// executeContentGeneration performs the actual content generation based on content type.
-func (s *RPCServer) executeContentGeneration(req *struct {
-	SessionID   string                 `json:"session_id"`
-	ContentType string                 `json:"content_type"`
-	LocationID  string                 `json:"location_id"`
-	Difficulty  int                    `json:"difficulty"`
-	Constraints map[string]interface{} `json:"constraints"`
-},
-) (interface{}, error) {
-	ctx := context.Background()
+// executeContentGeneration performs the actual content generation based on
+// content type, or with the named generator when the request names one.
+func (s *RPCServer) executeContentGeneration(ctx context.Context, req *contentGenerationRequest) (interface{}, error) {
+	ctx = s.withGenerationProgress(ctx, req.SessionID, req.LocationID)
 	var content interface{}
 	var err error
 
+	if req.Generator != "" {
+		content, err = s.pcgManager.GenerateWithGenerator(ctx, pcg.ContentType(req.ContentType), req.Generator, req.LocationID, req.Difficulty, req.Constraints)
+		if err != nil {
+			return nil, ErrGenerationFailed.WithMessage("generation failed: %v", err)
+		}
+		return content, nil
+	}
+
 	switch pcg.ContentType(req.ContentType) {
 	case pcg.ContentTypeTerrain:
 		content, err = s.pcgManager.GenerateTerrainForLevel(ctx, req.LocationID, 50, 50, pcg.BiomeDungeon, req.Difficulty)
@@ -2895,25 +3648,18 @@ func (s *RPCServer) executeContentGeneration(req *struct {
 	case pcg.ContentTypeQuests:
 		content, err = s.pcgManager.GenerateQuestForArea(ctx, req.LocationID, pcg.QuestTypeFetch, req.Difficulty)
 	default:
-		return nil, fmt.Errorf("unsupported content type: %s", req.ContentType)
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("unsupported content type: %s", req.ContentType), nil)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("generation failed: %w", err)
+		return nil, ErrGenerationFailed.WithMessage("generation failed: %v", err)
 	}
 
 	return content, nil
 }
 
 // logContentGenerationSuccess logs successful content generation with relevant details.
-func (s *RPCServer) logContentGenerationSuccess(req *struct {
-	SessionID   string                 `json:"session_id"`
-	ContentType string                 `json:"content_type"`
-	LocationID  string                 `json:"location_id"`
-	Difficulty  int                    `json:"difficulty"`
-	Constraints map[string]interface{} `json:"constraints"`
-},
-) {
+func (s *RPCServer) logContentGenerationSuccess(req *contentGenerationRequest) {
 	logrus.WithFields(logrus.Fields{
 		"function":    "executeContentGeneration",
 		"sessionID":   req.SessionID,
@@ -2924,14 +3670,7 @@ func (s *RPCServer) logContentGenerationSuccess(req *struct {
 }
 
 // buildContentGenerationResponse constructs the response map for successful content generation.
-func (s *RPCServer) buildContentGenerationResponse(req *struct {
-	SessionID   string                 `json:"session_id"`
-	ContentType string                 `json:"content_type"`
-	LocationID  string                 `json:"location_id"`
-	Difficulty  int                    `json:"difficulty"`
-	Constraints map[string]interface{} `json:"constraints"`
-}, content interface{},
-) map[string]interface{} {
+func (s *RPCServer) buildContentGenerationResponse(req *contentGenerationRequest, content interface{}) map[string]interface{} {
 	return map[string]interface{}{
 		"success":      true,
 		"content_type": req.ContentType,
@@ -2977,7 +3716,7 @@ func (s *RPCServer) validateTerrainRegenerationRequest(req *terrainRegenerationR
 	_ = session // Suppress unused variable warning
 
 	if req.LocationID == "" {
-		return fmt.Errorf("location_id parameter required")
+		return NewJSONRPCError(JSONRPCInvalidParams, "location_id parameter required", nil)
 	}
 
 	return nil
@@ -3007,12 +3746,12 @@ func (s *RPCServer) applyTerrainRegenerationDefaults(req *terrainRegenerationReq
 
 // executeTerrainGeneration performs the actual terrain generation using the PCG manager.
 func (s *RPCServer) executeTerrainGeneration(req *terrainRegenerationRequest) (interface{}, error) {
-	ctx := context.Background()
+	ctx := s.withGenerationProgress(context.Background(), req.SessionID, req.LocationID)
 	biomeType := pcg.BiomeType(req.BiomeType)
 
 	gameMap, err := s.pcgManager.GenerateTerrainForLevel(ctx, req.LocationID, req.Width, req.Height, biomeType, 5)
 	if err != nil {
-		return nil, fmt.Errorf("terrain generation failed: %w", err)
+		return nil, ErrGenerationFailed.WithMessage("terrain generation failed: %v", err)
 	}
 
 	return gameMap, nil
@@ -3065,6 +3804,7 @@ func (s *RPCServer) handleRegenerateTerrain(params json.RawMessage) (interface{}
 	}
 
 	s.logTerrainRegenerationSuccess(req)
+	s.notifyWorldGenerated(pcg.ContentTypeTerrain, req.LocationID)
 
 	return s.buildTerrainRegenerationResponse(req, terrain), nil
 }
@@ -3099,7 +3839,7 @@ func (s *RPCServer) handleGenerateItems(params json.RawMessage) (interface{}, er
 	_ = session // Suppress unused variable warning
 
 	if req.LocationID == "" {
-		return nil, fmt.Errorf("location_id parameter required")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "location_id parameter required", nil)
 	}
 
 	// Set defaults
@@ -3124,7 +3864,7 @@ func (s *RPCServer) handleGenerateItems(params json.RawMessage) (interface{}, er
 
 	items, err := s.pcgManager.GenerateItemsForLocation(ctx, req.LocationID, req.Count, minRarity, maxRarity, req.PlayerLevel)
 	if err != nil {
-		return nil, fmt.Errorf("item generation failed: %w", err)
+		return nil, ErrGenerationFailed.WithMessage("item generation failed: %v", err)
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -3153,6 +3893,8 @@ type levelGenerationRequest struct {
 	Theme         string `json:"theme"`
 	Difficulty    int    `json:"difficulty"`
 	CorridorStyle string `json:"corridor_style"`
+
+	Constraints []pcg.LayoutConstraint `json:"constraints,omitempty"` // Hard rules on the room layout
 }
 
 // parseLevelGenerationRequest unmarshals and validates the level generation request parameters.
@@ -3201,13 +3943,26 @@ func (s *RPCServer) applyLevelGenerationDefaults(req *levelGenerationRequest) {
 }
 
 // executeLevelGeneration performs the actual level generation using PCG manager.
+// A level whose layout cannot be repaired to meet its constraints fails with
+// the constraint report in the error data.
 func (s *RPCServer) executeLevelGeneration(req *levelGenerationRequest) (interface{}, error) {
-	ctx := context.Background()
+	for i, constraint := range req.Constraints {
+		if err := constraint.Validate(); err != nil {
+			return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid layout constraint", fmt.Sprintf("constraint %d: %v", i, err))
+		}
+	}
+
+	ctx := s.withGenerationProgress(context.Background(), req.SessionID, "generated_level")
 	theme := pcg.LevelTheme(req.Theme)
 
-	level, err := s.pcgManager.GenerateDungeonLevel(ctx, "generated_level", 5, req.RoomCount, theme, req.Difficulty)
+	level, err := s.pcgManager.GenerateDungeonLevel(ctx, "generated_level", 5, req.RoomCount, theme, req.Difficulty, req.Constraints...)
 	if err != nil {
-		return nil, fmt.Errorf("level generation failed: %w", err)
+		var violated *pcg.ConstraintError
+		if errors.As(err, &violated) {
+			return nil, ErrGenerationFailed.WithMessage("level generation failed: %v", err).
+				WithData(map[string]interface{}{"constraints": violated.Report})
+		}
+		return nil, ErrGenerationFailed.WithMessage("level generation failed: %v", err)
 	}
 
 	return level, nil
@@ -3258,6 +4013,7 @@ func (s *RPCServer) handleGenerateLevel(params json.RawMessage) (interface{}, er
 	if err != nil {
 		return nil, err
 	}
+	s.notifyWorldGenerated(pcg.ContentTypeLevels, "generated_level")
 
 	return s.buildLevelGenerationResponse(req, level), nil
 }
@@ -3289,6 +4045,9 @@ func (s *RPCServer) handleGenerateQuest(params json.RawMessage) (interface{}, er
 	}
 
 	s.applyQuestGenerationDefaults(req)
+	if err := s.checkQuestAvailability(req.QuestType); err != nil {
+		return nil, err
+	}
 
 	quest, err := s.executeQuestGeneration(req)
 	if err != nil {
@@ -3328,7 +4087,7 @@ func (s *RPCServer) validateQuestGenerationSession(sessionID string) error {
 // applyQuestGenerationDefaults sets default values for empty request fields.
 func (s *RPCServer) applyQuestGenerationDefaults(req *generateQuestRequest) {
 	if req.QuestType == "" {
-		req.QuestType = "fetch"
+		req.QuestType = s.defaultQuestType()
 	}
 	if req.Difficulty == 0 {
 		req.Difficulty = 5
@@ -3354,7 +4113,7 @@ func (s *RPCServer) executeQuestGeneration(req *generateQuestRequest) (*game.Que
 
 	quest, err := s.pcgManager.GenerateQuestForArea(ctx, "generated_quest_area", questType, req.Difficulty)
 	if err != nil {
-		return nil, fmt.Errorf("quest generation failed: %w", err)
+		return nil, ErrGenerationFailed.WithMessage("quest generation failed: %v", err)
 	}
 
 	return quest, nil
@@ -3451,17 +4210,17 @@ func (s *RPCServer) handleValidateContent(params json.RawMessage) (interface{},
 	_ = session // Suppress unused variable warning
 
 	if req.ContentType == "" {
-		return nil, fmt.Errorf("content_type parameter required")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "content_type parameter required", nil)
 	}
 
 	if req.Content == nil {
-		return nil, fmt.Errorf("content parameter required")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "content parameter required", nil)
 	}
 
 	// Validate content using PCG validator with type information
 	validationResult, err := s.pcgManager.ValidateGeneratedContentWithType(req.Content, req.ContentType)
 	if err != nil {
-		return nil, fmt.Errorf("content validation failed: %w", err)
+		return nil, ErrContentInvalid.WithMessage("content validation failed: %v", err)
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -3480,3 +4239,72 @@ func (s *RPCServer) handleValidateContent(params json.RawMessage) (interface{},
 		"strict":       req.Strict,
 	}, nil
 }
+
+// handleAdminReloadLootTables re-reads the loot table files so edits take
+// effect without restarting the server. The level generator and random
+// encounters share the registry, so levels generated and monsters met
+// afterwards roll from the new tables. If the edited files fail to parse or
+// validate, the previous tables stay active and the error is returned.
+func (s *RPCServer) handleAdminReloadLootTables(params json.RawMessage) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "handleAdminReloadLootTables",
+	}).Debug("entering handleAdminReloadLootTables")
+
+	if s.lootTables == nil {
+		return nil, ErrUnavailable.WithMessage("Loot tables not configured")
+	}
+
+	if err := s.lootTables.Reload(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "handleAdminReloadLootTables",
+			"error":    err.Error(),
+		}).Warn("loot table reload rejected")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to reload loot tables", err.Error())
+	}
+
+	tables := s.lootTables.TableNames()
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "handleAdminReloadLootTables",
+		"tableCount": len(tables),
+	}).Info("loot tables reloaded successfully")
+
+	return map[string]interface{}{
+		"success": true,
+		"tables":  tables,
+	}, nil
+}
+
+// handleAdminReloadPCGDefinitions re-reads the biome and theme files so
+// edits take effect without restarting the server. Content generated
+// afterwards uses the new definitions. The two registries are reloaded
+// together: if any file fails to parse or validate, the previous biomes and
+// themes both stay active and the error is returned.
+func (s *RPCServer) handleAdminReloadPCGDefinitions(params json.RawMessage) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "handleAdminReloadPCGDefinitions",
+	}).Debug("entering handleAdminReloadPCGDefinitions")
+
+	if err := pcg.ReloadDefinitions(pcg.Biomes(), pcg.Themes()); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "handleAdminReloadPCGDefinitions",
+			"error":    err.Error(),
+		}).Warn("PCG definition reload rejected")
+		return nil, ErrContentInvalid.WithMessage("failed to reload PCG definitions: %v", err)
+	}
+
+	biomes := pcg.Biomes().Types()
+	themes := pcg.Themes().Types()
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "handleAdminReloadPCGDefinitions",
+		"biomeCount": len(biomes),
+		"themeCount": len(themes),
+	}).Info("PCG definitions reloaded successfully")
+
+	return map[string]interface{}{
+		"success": true,
+		"biomes":  biomes,
+		"themes":  themes,
+	}, nil
+}
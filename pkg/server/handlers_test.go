@@ -427,6 +427,98 @@ func TestHandleCreateCharacter(t *testing.T) {
 	}
 }
 
+// TestHandleCreateCharacter_Background tests background selection and the
+// hook quest generated from the character seed
+func TestHandleCreateCharacter_Background(t *testing.T) {
+	create := func(t *testing.T, server *RPCServer, background string, seed int64) map[string]interface{} {
+		paramBytes, err := json.Marshal(map[string]interface{}{
+			"name":             "Heir",
+			"class":            "fighter",
+			"attribute_method": "standard",
+			"background":       background,
+			"seed":             seed,
+		})
+		require.NoError(t, err)
+
+		result, err := server.handleCreateCharacter(paramBytes)
+		require.NoError(t, err)
+		resultMap := result.(map[string]interface{})
+		require.Equal(t, true, resultMap["success"], resultMap["errors"])
+		return resultMap
+	}
+
+	server := createTestServerForHandlers(t)
+	first := create(t, server, "noble", 99)
+	assert.Equal(t, int64(99), first["seed"])
+
+	player := first["player"].(*game.Player)
+	assert.Equal(t, game.BackgroundNoble, player.Background)
+	assert.Equal(t, 20, player.Reputation["noble_houses"])
+
+	hook := first["hook_quest"].(*game.Quest)
+	require.NotNil(t, hook)
+	assert.Equal(t, game.QuestActive, hook.Status)
+	assert.Len(t, player.GetActiveQuests(), 1)
+
+	// The same seed reproduces the same hook quest
+	second := create(t, createTestServerForHandlers(t), "noble", 99)
+	assert.Equal(t, hook, second["hook_quest"])
+
+	random := create(t, server, "random", 99)
+	assert.NotEqual(t, game.BackgroundRandom, random["player"].(*game.Player).Background)
+
+	_, err := server.handleCreateCharacter(json.RawMessage(`{"name":"Heir","class":"fighter","attribute_method":"standard","background":"pirate"}`))
+	assert.Error(t, err)
+}
+
+// TestHandleCreateCharacter_MultiClass tests creating a multi-classed character
+func TestHandleCreateCharacter_MultiClass(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	result, err := server.handleCreateCharacter(json.RawMessage(`{"name":"Tanis","classes":["fighter","mage"],"attribute_method":"custom",` +
+		`"custom_attributes":{"strength":16,"dexterity":14,"constitution":14,"intelligence":16,"wisdom":10,"charisma":10}}`))
+	require.NoError(t, err)
+	resultMap := result.(map[string]interface{})
+	require.Equal(t, true, resultMap["success"], resultMap["errors"])
+
+	player := resultMap["player"].(*game.Player)
+	assert.Equal(t, game.ClassFighter, player.Class)
+	assert.True(t, player.IsMultiClassed())
+	assert.Equal(t, []game.CharacterClass{game.ClassFighter, game.ClassMage}, player.ActiveClasses())
+
+	result, err = server.handleCreateCharacter(json.RawMessage(`{"name":"Tanis","classes":["paladin","mage"],"attribute_method":"standard"}`))
+	require.NoError(t, err)
+	assert.Equal(t, false, result.(map[string]interface{})["success"])
+}
+
+// TestHandleChangeClass tests dual-classing the session's player
+func TestHandleChangeClass(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Class = game.ClassFighter
+	session.Player.Strength = 16
+	session.Player.Level = 5
+
+	_, err := server.handleChangeClass(json.RawMessage(`{"session_id":"test-session-001","class":"mage"}`))
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, ErrCodeClassChangeDenied, rpcErr.Code)
+
+	session.Player.Intelligence = 17
+	result, err := server.handleChangeClass(json.RawMessage(`{"session_id":"test-session-001","class":"mage"}`))
+	require.NoError(t, err)
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, "Mage", resultMap["class"])
+	assert.Equal(t, []string{"Mage"}, resultMap["active_classes"])
+	classes := resultMap["classes"].([]game.ClassProgress)
+	require.Len(t, classes, 2)
+	assert.True(t, classes[0].Former)
+	assert.Equal(t, 1, classes[1].Level)
+
+	_, err = server.handleChangeClass(json.RawMessage(`{"session_id":"test-session-001","class":"bard"}`))
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code)
+}
+
 // TestParseEquipmentSlot tests equipment slot parsing
 func TestParseEquipmentSlot(t *testing.T) {
 	tests := []struct {
@@ -3,8 +3,12 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,13 +23,24 @@ const (
 	HealthStatusUnhealthy HealthStatus = "unhealthy"
 )
 
+// Health check limits
+const (
+	healthCheckTimeout       = 5 * time.Second // Longest a single check may run
+	slowCheckLatency         = time.Second     // A responsive component answers faster
+	eventQueueDegradedDepth  = 1000            // Running event handlers that degrade the server
+	eventQueueUnhealthyDepth = 10000           // Running event handlers that make it unhealthy
+	healthProbeKey           = "health_probe.yaml"
+	healthProbeSessionID     = "health-probe"
+)
+
 // CheckResult represents the result of a single health check
 type CheckResult struct {
-	Name     string        `json:"name"`
-	Status   HealthStatus  `json:"status"`
-	Duration time.Duration `json:"duration"`
-	Error    string        `json:"error,omitempty"`
-	Details  interface{}   `json:"details,omitempty"`
+	Name      string        `json:"name"`
+	Status    HealthStatus  `json:"status"`
+	Duration  time.Duration `json:"duration"`
+	LatencyMS float64       `json:"latency_ms"`
+	Error     string        `json:"error,omitempty"`
+	Details   interface{}   `json:"details,omitempty"`
 }
 
 // HealthResponse represents the complete health check response
@@ -37,16 +52,36 @@ type HealthResponse struct {
 	Version   string        `json:"version,omitempty"`
 }
 
+// DetailedCheck is a health check that reports details about the component
+// alongside its result. Returning an error made with degraded marks the
+// component degraded rather than unhealthy.
+type DetailedCheck func(context.Context) (interface{}, error)
+
+// degradedError marks a health check failure that leaves the component
+// working but impaired
+type degradedError struct {
+	err error
+}
+
+func (e degradedError) Error() string { return e.err.Error() }
+func (e degradedError) Unwrap() error { return e.err }
+
+// degraded returns an error reporting the component degraded
+func degraded(format string, args ...interface{}) error {
+	return degradedError{err: fmt.Errorf(format, args...)}
+}
+
 // HealthChecker manages health checks for various system components
 type HealthChecker struct {
-	checks map[string]func(context.Context) error
+	mu     sync.RWMutex
+	checks map[string]DetailedCheck
 	server *RPCServer
 }
 
 // NewHealthChecker creates a new health checker instance
 func NewHealthChecker(server *RPCServer) *HealthChecker {
 	hc := &HealthChecker{
-		checks: make(map[string]func(context.Context) error),
+		checks: make(map[string]DetailedCheck),
 		server: server,
 	}
 
@@ -57,81 +92,153 @@ func NewHealthChecker(server *RPCServer) *HealthChecker {
 	hc.RegisterCheck("event_system", hc.checkEventSystem)
 
 	// Register comprehensive health checks
-	hc.RegisterCheck("pcg_manager", hc.checkPCGManager)
+	hc.RegisterDetailedCheck("pcg_manager", hc.checkPCGManager)
 	hc.RegisterCheck("validation_system", hc.checkValidationSystem)
-	hc.RegisterCheck("circuit_breakers", hc.checkCircuitBreakers)
+	hc.RegisterDetailedCheck("circuit_breakers", hc.checkCircuitBreakers)
 	hc.RegisterCheck("metrics_system", hc.checkMetricsSystem)
 	hc.RegisterCheck("configuration", hc.checkConfiguration)
 	hc.RegisterCheck("performance_monitor", hc.checkPerformanceMonitor)
 
+	// Register deep probes of the components requests depend on
+	hc.RegisterDetailedCheck("persistence", hc.checkPersistence)
+	hc.RegisterDetailedCheck("event_queue", hc.checkEventQueue)
+	hc.RegisterDetailedCheck("session_store", hc.checkSessionStore)
+
 	return hc
 }
 
 // RegisterCheck adds a new health check with the given name
 func (hc *HealthChecker) RegisterCheck(name string, check func(context.Context) error) {
+	hc.RegisterDetailedCheck(name, func(ctx context.Context) (interface{}, error) {
+		return nil, check(ctx)
+	})
+}
+
+// RegisterDetailedCheck adds a new health check with the given name that
+// reports details about its component
+func (hc *HealthChecker) RegisterDetailedCheck(name string, check DetailedCheck) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
 	hc.checks[name] = check
 }
 
-// RunHealthChecks executes all registered health checks and returns the results
+// RunHealthChecks executes all registered health checks concurrently and
+// returns the results ordered by name. The server is unhealthy if any
+// check fails, degraded if any check reports degraded, and healthy
+// otherwise. The result also sets how much load the server sheds.
 func (hc *HealthChecker) RunHealthChecks(ctx context.Context) HealthResponse {
 	start := time.Now()
+
+	hc.mu.RLock()
+	names := make([]string, 0, len(hc.checks))
+	checks := make(map[string]DetailedCheck, len(hc.checks))
+	for name, check := range hc.checks {
+		names = append(names, name)
+		checks[name] = check
+	}
+	hc.mu.RUnlock()
+	sort.Strings(names)
+
 	response := HealthResponse{
 		Timestamp: start,
-		Checks:    make([]CheckResult, 0, len(hc.checks)),
+		Checks:    make([]CheckResult, len(names)),
 		Version:   "1.0.0", // TODO: Get from build info
 	}
 
-	overallStatus := HealthStatusHealthy
-
-	for name, check := range hc.checks {
-		checkStart := time.Now()
-		result := CheckResult{
-			Name:     name,
-			Duration: 0,
-			Status:   HealthStatusHealthy,
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			response.Checks[i] = hc.runCheck(ctx, name, checks[name])
+		}(i, name)
+	}
+	wg.Wait()
+
+	response.Status = HealthStatusHealthy
+	for _, result := range response.Checks {
+		switch {
+		case result.Status == HealthStatusUnhealthy:
+			response.Status = HealthStatusUnhealthy
+		case result.Status == HealthStatusDegraded && response.Status == HealthStatusHealthy:
+			response.Status = HealthStatusDegraded
 		}
+	}
+	response.Duration = time.Since(start)
 
-		// Run the check with timeout
-		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		err := check(checkCtx)
-		cancel()
-
-		result.Duration = time.Since(checkStart)
-
-		if err != nil {
-			result.Status = HealthStatusUnhealthy
-			result.Error = err.Error()
-			overallStatus = HealthStatusUnhealthy
+	if hc.server != nil {
+		hc.server.updateLoadShedding(response)
+	}
+	return response
+}
 
-			// Record failed health check in metrics
-			if hc.server.metrics != nil {
-				hc.server.metrics.RecordHealthCheck(name, "failure")
-			}
+// runCheck runs one health check, giving up once it exceeds the check
+// timeout, and records its result in the metrics.
+func (hc *HealthChecker) runCheck(ctx context.Context, name string, check DetailedCheck) CheckResult {
+	checkStart := time.Now()
+	result := CheckResult{
+		Name:   name,
+		Status: HealthStatusHealthy,
+	}
 
-			logrus.WithFields(logrus.Fields{
-				"check":    name,
-				"duration": result.Duration,
-				"error":    err,
-			}).Error("health check failed")
-		} else {
-			// Record successful health check in metrics
-			if hc.server.metrics != nil {
-				hc.server.metrics.RecordHealthCheck(name, "success")
-			}
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
 
-			logrus.WithFields(logrus.Fields{
-				"check":    name,
-				"duration": result.Duration,
-			}).Debug("health check passed")
-		}
+	type outcome struct {
+		details interface{}
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := check(checkCtx)
+		done <- outcome{details, err}
+	}()
 
-		response.Checks = append(response.Checks, result)
+	var err error
+	select {
+	case out := <-done:
+		result.Details, err = out.details, out.err
+	case <-checkCtx.Done():
+		err = fmt.Errorf("health check timed out: %w", checkCtx.Err())
 	}
 
-	response.Status = overallStatus
-	response.Duration = time.Since(start)
+	result.Duration = time.Since(checkStart)
+	result.LatencyMS = float64(result.Duration.Microseconds()) / 1000
+
+	metricStatus := "success"
+	var degradedErr degradedError
+	switch {
+	case err == nil:
+		logrus.WithFields(logrus.Fields{
+			"check":    name,
+			"duration": result.Duration,
+		}).Debug("health check passed")
+	case errors.As(err, &degradedErr):
+		result.Status = HealthStatusDegraded
+		result.Error = err.Error()
+		metricStatus = "degraded"
+
+		logrus.WithFields(logrus.Fields{
+			"check":    name,
+			"duration": result.Duration,
+			"error":    err,
+		}).Warn("health check degraded")
+	default:
+		result.Status = HealthStatusUnhealthy
+		result.Error = err.Error()
+		metricStatus = "failure"
+
+		logrus.WithFields(logrus.Fields{
+			"check":    name,
+			"duration": result.Duration,
+			"error":    err,
+		}).Error("health check failed")
+	}
 
-	return response
+	if hc.server != nil && hc.server.metrics != nil {
+		hc.server.metrics.RecordHealthCheck(name, metricStatus)
+	}
+	return result
 }
 
 // HTTP handler for health checks
@@ -247,30 +354,38 @@ func (hc *HealthChecker) checkEventSystem(ctx context.Context) error {
 	return nil
 }
 
-func (hc *HealthChecker) checkPCGManager(ctx context.Context) error {
+// checkPCGManager checks that the PCG manager is set up and answers for
+// its statistics promptly, reporting it degraded when it is slow.
+func (hc *HealthChecker) checkPCGManager(ctx context.Context) (interface{}, error) {
 	if hc.server == nil || hc.server.pcgManager == nil {
-		return fmt.Errorf("PCG manager is not initialized")
+		return nil, fmt.Errorf("PCG manager is not initialized")
 	}
 
 	// Check if PCG manager has registry and generators
 	registry := hc.server.pcgManager.GetRegistry()
 	if registry == nil {
-		return fmt.Errorf("PCG registry is not initialized")
+		return nil, fmt.Errorf("PCG registry is not initialized")
 	}
 
 	// Check if metrics are available
 	metrics := hc.server.pcgManager.GetMetrics()
 	if metrics == nil {
-		return fmt.Errorf("PCG metrics are not initialized")
+		return nil, fmt.Errorf("PCG metrics are not initialized")
 	}
 
 	// Get generation statistics to ensure the system is functional
+	start := time.Now()
 	stats := hc.server.pcgManager.GetGenerationStatistics()
+	elapsed := time.Since(start)
 	if stats == nil {
-		return fmt.Errorf("unable to retrieve PCG statistics")
+		return nil, fmt.Errorf("unable to retrieve PCG statistics")
 	}
 
-	return nil
+	details := map[string]interface{}{"response_ms": float64(elapsed.Microseconds()) / 1000}
+	if elapsed > slowCheckLatency {
+		return details, degraded("PCG manager took %v to respond", elapsed)
+	}
+	return details, nil
 }
 
 func (hc *HealthChecker) checkValidationSystem(ctx context.Context) error {
@@ -291,20 +406,33 @@ func (hc *HealthChecker) checkValidationSystem(ctx context.Context) error {
 	return nil
 }
 
-func (hc *HealthChecker) checkCircuitBreakers(ctx context.Context) error {
+// checkCircuitBreakers reports the state of every circuit breaker, and the
+// server degraded while any of them fails fast.
+func (hc *HealthChecker) checkCircuitBreakers(ctx context.Context) (interface{}, error) {
 	// Use the global circuit breaker manager
 	cbManager := GetCircuitBreakerManager()
 	if cbManager == nil {
-		return fmt.Errorf("circuit breaker manager is not initialized")
+		return nil, fmt.Errorf("circuit breaker manager is not initialized")
 	}
 
-	// Get stats to ensure it's functional
-	stats := cbManager.GetAllStats()
-	if stats == nil {
-		return fmt.Errorf("unable to retrieve circuit breaker statistics")
+	states := make(map[string]string)
+	var open []string
+	for _, name := range cbManager.GetBreakerNames() {
+		breaker, exists := cbManager.Get(name)
+		if !exists {
+			continue
+		}
+		states[name] = breaker.GetState().String()
+		if breaker.IsFailingFast() {
+			open = append(open, name)
+		}
 	}
 
-	return nil
+	if len(open) > 0 {
+		sort.Strings(open)
+		return states, degraded("circuit breakers open: %v", open)
+	}
+	return states, nil
 }
 
 func (hc *HealthChecker) checkMetricsSystem(ctx context.Context) error {
@@ -338,3 +466,138 @@ func (hc *HealthChecker) checkPerformanceMonitor(ctx context.Context) error {
 	// Performance monitor exists, system is healthy
 	return nil
 }
+
+// checkPersistence writes, reads back and deletes a probe document to show
+// the store is writable. It passes without a store when persistence is
+// disabled.
+func (hc *HealthChecker) checkPersistence(ctx context.Context) (interface{}, error) {
+	if hc.server == nil || hc.server.store == nil {
+		return map[string]interface{}{"enabled": false}, nil
+	}
+	store := hc.server.store
+
+	probe := map[string]interface{}{"checked_at": time.Now().UTC().Format(time.RFC3339Nano)}
+	if err := store.Save(healthProbeKey, probe); err != nil {
+		return nil, fmt.Errorf("persistence store is not writable: %w", err)
+	}
+	var readBack map[string]interface{}
+	if err := store.Load(healthProbeKey, &readBack); err != nil {
+		return nil, fmt.Errorf("persistence store is not readable: %w", err)
+	}
+	if readBack["checked_at"] != probe["checked_at"] {
+		return nil, fmt.Errorf("persistence store returned a different probe than was written")
+	}
+	if err := store.Delete(healthProbeKey); err != nil {
+		return nil, fmt.Errorf("failed to delete persistence probe: %w", err)
+	}
+
+	return map[string]interface{}{"enabled": true}, nil
+}
+
+// checkEventQueue reports the number of event handlers still running,
+// degrading and then failing as handlers back up.
+func (hc *HealthChecker) checkEventQueue(ctx context.Context) (interface{}, error) {
+	if hc.server == nil || hc.server.eventSys == nil {
+		return nil, fmt.Errorf("event system is not initialized")
+	}
+
+	depth := hc.server.eventSys.Pending()
+	details := map[string]interface{}{
+		"depth":           depth,
+		"degraded_depth":  eventQueueDegradedDepth,
+		"unhealthy_depth": eventQueueUnhealthyDepth,
+	}
+	switch {
+	case depth >= eventQueueUnhealthyDepth:
+		return details, fmt.Errorf("event queue is backed up with %d pending handlers", depth)
+	case depth >= eventQueueDegradedDepth:
+		return details, degraded("event queue has %d pending handlers", depth)
+	}
+	return details, nil
+}
+
+// checkSessionStore looks up a probe session in the shared session store
+// to show it is reachable. It passes when sessions are not shared.
+func (hc *HealthChecker) checkSessionStore(ctx context.Context) (interface{}, error) {
+	if hc.server == nil || hc.server.sessionStore == nil {
+		return map[string]interface{}{"shared": false}, nil
+	}
+
+	if _, _, err := hc.server.sessionStore.Load(healthProbeSessionID); err != nil {
+		return nil, fmt.Errorf("session store is unreachable: %w", err)
+	}
+	return map[string]interface{}{"shared": true}, nil
+}
+
+// updateLoadShedding sets the share of requests the server sheds from the
+// result of its health checks.
+func (s *RPCServer) updateLoadShedding(response HealthResponse) {
+	if s.loadShedder == nil || s.config == nil {
+		return
+	}
+
+	var reasons []string
+	for _, check := range response.Checks {
+		if check.Status != HealthStatusHealthy {
+			reasons = append(reasons, check.Name)
+		}
+	}
+
+	switch response.Status {
+	case HealthStatusUnhealthy:
+		s.loadShedder.SetRatio(s.config.LoadShedUnhealthyRatio, fmt.Sprintf("unhealthy: %v", reasons))
+	case HealthStatusDegraded:
+		s.loadShedder.SetRatio(s.config.LoadShedDegradedRatio, fmt.Sprintf("degraded: %v", reasons))
+	default:
+		s.loadShedder.SetRatio(0, "")
+	}
+}
+
+// checkLoadShed rejects the request with 503 Service Unavailable if it
+// falls in the share of requests being shed, and returns true if the
+// request should be allowed.
+func (s *RPCServer) checkLoadShed(w http.ResponseWriter, r *http.Request) bool {
+	if s.loadShedder == nil || s.loadShedder.Allow() {
+		return true
+	}
+
+	_, reason := s.loadShedder.Ratio()
+	logrus.WithFields(logrus.Fields{
+		"method":     r.Method,
+		"path":       r.URL.Path,
+		"request_id": GetRequestID(r.Context()),
+		"reason":     reason,
+	}).Warn("request shed")
+
+	retryAfter := 1
+	if s.config != nil && s.config.HealthCheckInterval > 0 {
+		retryAfter = int(s.config.HealthCheckInterval.Round(time.Second) / time.Second)
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	return false
+}
+
+// startHealthMonitor runs the health checks periodically until the server
+// shuts down, so load shedding follows the server's health between
+// requests to /health.
+func (s *RPCServer) startHealthMonitor() {
+	if s.healthChecker == nil || s.config == nil || s.config.HealthCheckInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.HealthCheckInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), s.config.HealthCheckInterval)
+				s.healthChecker.RunHealthChecks(ctx)
+				cancel()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
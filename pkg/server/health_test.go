@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/persistence"
+	"goldbox-rpg/pkg/resilience"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore is a persistence store that cannot be written
+type failingStore struct {
+	persistence.Store
+}
+
+func (failingStore) Save(string, interface{}) error {
+	return errors.New("disk full")
+}
+
+// findCheck returns the named result of a health check run
+func findCheck(t *testing.T, response HealthResponse, name string) CheckResult {
+	for _, check := range response.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("health check %q not found", name)
+	return CheckResult{}
+}
+
+func TestHealthChecker_DeepProbes(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.store = persistence.NewMemoryStore()
+	server.sessionStore = NewMemorySessionStore()
+
+	response := server.healthChecker.RunHealthChecks(context.Background())
+	assert.Equal(t, HealthStatusHealthy, response.Status)
+	for i := 1; i < len(response.Checks); i++ {
+		assert.Less(t, response.Checks[i-1].Name, response.Checks[i].Name, "checks are ordered by name")
+	}
+
+	persistenceCheck := findCheck(t, response, "persistence")
+	assert.Equal(t, map[string]interface{}{"enabled": true}, persistenceCheck.Details)
+	assert.False(t, server.store.Exists(healthProbeKey), "the probe is deleted after the check")
+	assert.Equal(t, map[string]interface{}{"shared": true}, findCheck(t, response, "session_store").Details)
+	assert.Contains(t, findCheck(t, response, "event_queue").Details, "depth")
+	assert.Contains(t, findCheck(t, response, "pcg_manager").Details, "response_ms")
+
+	server.store = failingStore{persistence.NewMemoryStore()}
+	response = server.healthChecker.RunHealthChecks(context.Background())
+	assert.Equal(t, HealthStatusUnhealthy, response.Status)
+	persistenceCheck = findCheck(t, response, "persistence")
+	assert.Equal(t, HealthStatusUnhealthy, persistenceCheck.Status)
+	assert.Contains(t, persistenceCheck.Error, "disk full")
+}
+
+func TestHealthChecker_DegradedChecks(t *testing.T) {
+	server := createTestServerForHandlers(t)
+
+	release := make(chan struct{})
+	server.eventSys.Subscribe(game.EventType(9001), func(game.GameEvent) { <-release })
+	for i := 0; i < eventQueueDegradedDepth; i++ {
+		server.eventSys.Emit(game.GameEvent{Type: game.EventType(9001)})
+	}
+	defer close(release)
+
+	response := server.healthChecker.RunHealthChecks(context.Background())
+	assert.Equal(t, HealthStatusDegraded, response.Status)
+	check := findCheck(t, response, "event_queue")
+	assert.Equal(t, HealthStatusDegraded, check.Status)
+	assert.EqualValues(t, eventQueueDegradedDepth, check.Details.(map[string]interface{})["depth"])
+}
+
+func TestHealthChecker_CircuitBreakers(t *testing.T) {
+	manager := GetCircuitBreakerManager()
+	breaker := manager.GetOrCreate("health_test", &resilience.CircuitBreakerConfig{
+		Name:        "health_test",
+		MaxFailures: 1,
+		Timeout:     time.Minute,
+		MaxRequests: 1,
+	})
+	defer manager.Remove("health_test")
+	_ = breaker.Execute(context.Background(), func(context.Context) error { return errors.New("down") })
+
+	hc := NewHealthChecker(&RPCServer{})
+	details, err := hc.checkCircuitBreakers(context.Background())
+	var degradedErr degradedError
+	assert.True(t, errors.As(err, &degradedErr), "an open breaker degrades the server")
+	assert.Equal(t, "Open", details.(map[string]string)["health_test"])
+}
+
+func TestHealthChecker_CheckTimeout(t *testing.T) {
+	hc := NewHealthChecker(&RPCServer{})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result := hc.runCheck(ctx, "slow", func(ctx context.Context) (interface{}, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+	assert.Contains(t, result.Error, "timed out")
+	assert.Equal(t, HealthStatusUnhealthy, result.Status)
+	assert.Greater(t, result.LatencyMS, 0.0)
+}
+
+func TestLoadShedding(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	require.NotNil(t, server.loadShedder, "load shedding is enabled by default")
+	server.config.LoadShedUnhealthyRatio = 1
+
+	server.updateLoadShedding(HealthResponse{
+		Status: HealthStatusUnhealthy,
+		Checks: []CheckResult{{Name: "persistence", Status: HealthStatusUnhealthy}},
+	})
+	ratio, reason := server.loadShedder.Ratio()
+	assert.Equal(t, 1.0, ratio)
+	assert.Contains(t, reason, "persistence")
+
+	body := `{"jsonrpc":"2.0","method":"getGameState","params":{},"id":1}`
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/live", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "observability endpoints are never shed")
+
+	server.updateLoadShedding(HealthResponse{Status: HealthStatusHealthy})
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	assert.NotEqual(t, http.StatusServiceUnavailable, w.Code)
+}
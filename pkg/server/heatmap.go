@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// heatmapGenerationTimeout bounds the generation of the content analysed
+// by getDifficultyHeatmap
+const heatmapGenerationTimeout = 30 * time.Second
+
+// heatmapRequest holds the parameters of getDifficultyHeatmap
+type heatmapRequest struct {
+	SessionID     string         `json:"session_id"`
+	Source        string         `json:"source"`
+	Seed          *int64         `json:"seed,omitempty"`
+	Difficulty    int            `json:"difficulty"`
+	Levels        int            `json:"levels"`
+	RoomsPerLevel int            `json:"rooms_per_level"`
+	Theme         pcg.LevelTheme `json:"theme"`
+	Regions       int            `json:"regions"`
+}
+
+// applyDefaults fills in the parameters the request left out, seeding
+// generation from the server's base seed
+func (req *heatmapRequest) applyDefaults(baseSeed int64) {
+	if req.Source == "" {
+		req.Source = pcg.HeatmapSourceDungeon
+	}
+	if req.Seed == nil {
+		req.Seed = &baseSeed
+	}
+	if req.Difficulty == 0 {
+		req.Difficulty = 3
+	}
+	if req.Levels == 0 {
+		req.Levels = 3
+	}
+	if req.RoomsPerLevel == 0 {
+		req.RoomsPerLevel = 6
+	}
+	if req.Theme == "" {
+		req.Theme = pcg.ThemeClassic
+	}
+	if req.Regions == 0 {
+		req.Regions = 6
+	}
+}
+
+// generateHeatmapContent generates the dungeon complex or overworld that
+// a heatmap request describes and analyses it
+func generateHeatmapContent(ctx context.Context, req heatmapRequest) (*pcg.DifficultyHeatmap, error) {
+	base := pcg.GenerationParams{
+		Seed:        *req.Seed,
+		Difficulty:  req.Difficulty,
+		PlayerLevel: 1,
+		WorldState:  game.NewWorld(),
+		Timeout:     heatmapGenerationTimeout,
+	}
+
+	params := base
+	switch req.Source {
+	case pcg.HeatmapSourceDungeon:
+		params.Constraints = map[string]interface{}{
+			"dungeon_params": pcg.DungeonParams{
+				GenerationParams: base,
+				LevelCount:       req.Levels,
+				LevelWidth:       40,
+				LevelHeight:      30,
+				RoomsPerLevel:    req.RoomsPerLevel,
+				Theme:            req.Theme,
+				Connectivity:     pcg.ConnectivityModerate,
+				Density:          0.6,
+				Difficulty: pcg.DifficultyProgression{
+					BaseDifficulty:  req.Difficulty,
+					ScalingFactor:   1.5,
+					MaxDifficulty:   10,
+					ProgressionType: "linear",
+				},
+			},
+		}
+		generated, err := pcg.NewDungeonGenerator(logrus.StandardLogger()).Generate(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		dungeon, ok := generated.(*pcg.DungeonComplex)
+		if !ok {
+			return nil, fmt.Errorf("unexpected dungeon type %T", generated)
+		}
+		return pcg.DungeonHeatmap(dungeon), nil
+	case pcg.HeatmapSourceWorld:
+		params.Constraints = map[string]interface{}{
+			"world_params": pcg.WorldParams{
+				GenerationParams:  base,
+				WorldWidth:        100,
+				WorldHeight:       100,
+				RegionCount:       req.Regions,
+				SettlementCount:   req.Regions * 2,
+				LandmarkCount:     4,
+				Climate:           pcg.ClimateTemperate,
+				Connectivity:      pcg.ConnectivityModerate,
+				PopulationDensity: 1.0,
+				MagicLevel:        5,
+				DangerLevel:       req.Difficulty,
+			},
+		}
+		generated, err := pcg.NewWorldGenerator(logrus.StandardLogger()).Generate(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		world, ok := generated.(*pcg.GeneratedWorld)
+		if !ok {
+			return nil, fmt.Errorf("unexpected world type %T", generated)
+		}
+		return pcg.WorldHeatmap(world), nil
+	default:
+		return nil, fmt.Errorf("unknown heatmap source %q", req.Source)
+	}
+}
+
+// handleGetDifficultyHeatmap generates a dungeon complex or overworld and
+// returns its difficulty heatmap: the encounter budget, trap density and
+// loot value of each room or region, and a pacing score of how smoothly
+// difficulty progresses. The pacing score is recorded in the PCG quality
+// metrics, where it appears as the "pacing" component score.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - source: string - "dungeon" (default) or "world"
+//   - seed: number - Optional seed, defaulting to the server's base seed
+//   - difficulty: number - Base difficulty, default 3
+//   - levels: number - Dungeon levels, default 3
+//   - rooms_per_level: number - Dungeon rooms per level, default 6
+//   - theme: string - Dungeon level theme, default "classic"
+//   - regions: number - Overworld regions, default 6
+//
+// Returns:
+//   - interface{}: Map containing the heatmap
+//   - error: Invalid parameters or session, or a generation failure
+func (s *RPCServer) handleGetDifficultyHeatmap(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleGetDifficultyHeatmap",
+	})
+	logger.Debug("entering handleGetDifficultyHeatmap")
+
+	var req heatmapRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid heatmap parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+	req.applyDefaults(s.pcgManager.GetSeedManager().GetBaseSeed())
+
+	ctx, cancel := context.WithTimeout(context.Background(), heatmapGenerationTimeout)
+	defer cancel()
+
+	heatmap, err := generateHeatmapContent(ctx, req)
+	if err != nil {
+		logger.WithError(err).Error("failed to generate heatmap content")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to generate heatmap content", err.Error())
+	}
+	s.pcgManager.GetQualityMetrics().RecordPacing(heatmap.Source+":"+heatmap.ID, heatmap.Pacing.Score)
+
+	logger.WithFields(logrus.Fields{
+		"source":  heatmap.Source,
+		"seed":    *req.Seed,
+		"regions": len(heatmap.Regions),
+		"pacing":  heatmap.Pacing.Score,
+	}).Info("difficulty heatmap computed")
+
+	return map[string]interface{}{
+		"success": true,
+		"seed":    *req.Seed,
+		"heatmap": heatmap,
+	}, nil
+}
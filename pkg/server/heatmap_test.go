@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetDifficultyHeatmap(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"session_id":      session.SessionID,
+		"seed":            7,
+		"levels":          2,
+		"rooms_per_level": 3,
+	})
+	result, err := server.handleGetDifficultyHeatmap(params)
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	heatmap := response["heatmap"].(*pcg.DifficultyHeatmap)
+	assert.Equal(t, pcg.HeatmapSourceDungeon, heatmap.Source)
+	assert.NotEmpty(t, heatmap.Regions)
+	assert.Len(t, heatmap.Pacing.LevelCurve, 2)
+
+	report := server.pcgManager.GetQualityMetrics().GenerateQualityReport()
+	assert.Equal(t, heatmap.Pacing.Score, report.ComponentScores["pacing"])
+}
+
+func TestHandleGetDifficultyHeatmap_World(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "source": "world", "regions": 4})
+	result, err := server.handleGetDifficultyHeatmap(params)
+	require.NoError(t, err)
+
+	heatmap := result.(map[string]interface{})["heatmap"].(*pcg.DifficultyHeatmap)
+	assert.Equal(t, pcg.HeatmapSourceWorld, heatmap.Source)
+	assert.Len(t, heatmap.Regions, 4)
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/validation"
+
+	"github.com/sirupsen/logrus"
+)
+
+// idempotentExtraMethods change state too, but neither the event log nor
+// its key frames cover them. Together with eventSourcedMethods and
+// keyFrameMethods they are the methods that honour an idempotency key.
+var idempotentExtraMethods = map[RPCMethod]bool{
+	MethodRegisterReaction:       true,
+	MethodCancelReaction:         true,
+	MethodRespondReaction:        true,
+	MethodCreateParty:            true,
+	MethodJoinParty:              true,
+	MethodLeaveParty:             true,
+	MethodCommitGeneratedContent: true,
+}
+
+// idempotentMethod reports whether calls to method may carry an
+// idempotency key. Read-only methods ignore the key, as repeating them is
+// harmless.
+func idempotentMethod(method RPCMethod) bool {
+	return eventSourcedMethods[method] || keyFrameMethods[method] || idempotentExtraMethods[method]
+}
+
+// idempotencyScope identifies a keyed call: keys are scoped to the session
+// that sends them, so clients cannot collide with or replay each other's
+// calls.
+type idempotencyScope struct {
+	sessionID string
+	key       string
+}
+
+// idempotentCall is a keyed call that is under way or was applied.
+type idempotentCall struct {
+	scope       idempotencyScope
+	method      RPCMethod
+	fingerprint [sha256.Size]byte
+	done        chan struct{}   // Closed once the call has finished
+	response    json.RawMessage // The encoded result of a successful call
+	expiresAt   time.Time
+}
+
+// idempotencyCache remembers the responses of keyed calls for
+// IdempotencyKeyTTL, so a retried call is answered with the response of
+// the call that was applied rather than being applied again. Failed calls
+// are forgotten, so they can be retried. The zero value is ready to use.
+type idempotencyCache struct {
+	mu    sync.Mutex
+	calls map[idempotencyScope]*idempotentCall
+}
+
+// begin starts the keyed call to method with params. When an earlier call
+// with the same key succeeded, begin returns its response instead; while
+// one is still under way, begin waits for it first. Reusing a key for a
+// different call is an error.
+func (c *idempotencyCache) begin(scope idempotencyScope, method RPCMethod, params interface{}) (*idempotentCall, json.RawMessage, error) {
+	fingerprint := idempotencyFingerprint(method, params)
+	for {
+		c.mu.Lock()
+		c.purgeExpiredLocked(time.Now())
+		if c.calls == nil {
+			c.calls = make(map[idempotencyScope]*idempotentCall)
+		}
+		earlier, exists := c.calls[scope]
+		if !exists {
+			call := &idempotentCall{scope: scope, method: method, fingerprint: fingerprint, done: make(chan struct{})}
+			c.calls[scope] = call
+			c.mu.Unlock()
+			return call, nil, nil
+		}
+		c.mu.Unlock()
+
+		if earlier.fingerprint != fingerprint {
+			return nil, nil, ErrIdempotencyConflict.WithMessage("idempotency key %q was used for a different %s call", scope.key, earlier.method).
+				WithData(map[string]interface{}{"idempotency_key": scope.key, "method": string(earlier.method)})
+		}
+		<-earlier.done
+		if earlier.response != nil {
+			return nil, earlier.response, nil
+		}
+		// The earlier call failed and was forgotten; try again
+	}
+}
+
+// finish records the outcome of call. The response of a successful call is
+// kept until IdempotencyKeyTTL has passed; a failed call, or one whose
+// result cannot be encoded, is forgotten. Calls already finished are left
+// alone, so finish can also be deferred to release calls whose handler
+// panicked.
+func (c *idempotencyCache) finish(call *idempotentCall, result interface{}, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return
+	default:
+	}
+
+	if err == nil {
+		if response, marshalErr := json.Marshal(result); marshalErr == nil {
+			call.response = response
+			call.expiresAt = time.Now().Add(IdempotencyKeyTTL)
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"function": "idempotencyCache.finish",
+				"method":   call.method,
+				"error":    marshalErr.Error(),
+			}).Warn("failed to encode response for idempotent replay")
+		}
+	}
+	if call.response == nil && c.calls[call.scope] == call {
+		delete(c.calls, call.scope)
+	}
+	close(call.done)
+}
+
+// purgeExpiredLocked drops finished calls whose TTL has passed. The caller
+// must hold c.mu.
+func (c *idempotencyCache) purgeExpiredLocked(now time.Time) {
+	for scope, call := range c.calls {
+		if call.response != nil && now.After(call.expiresAt) {
+			delete(c.calls, scope)
+		}
+	}
+}
+
+// idempotencyFingerprint hashes a call, so a key reused with other
+// parameters is told apart from a retry. Parameters are hashed in their
+// canonical JSON encoding, with sorted object keys.
+func idempotencyFingerprint(method RPCMethod, params interface{}) [sha256.Size]byte {
+	encoded, _ := json.Marshal(params)
+	return sha256.Sum256(append([]byte(string(method)+"\x00"), encoded...))
+}
+
+// idempotencyScopeOf returns the scope of a call's idempotency key, if the
+// call carries one, names its session and is to a method that honours
+// keys.
+func idempotencyScopeOf(method RPCMethod, params interface{}) (idempotencyScope, bool) {
+	if !idempotentMethod(method) {
+		return idempotencyScope{}, false
+	}
+	paramsMap, _ := params.(map[string]interface{})
+	key, _ := paramsMap[validation.IdempotencyKeyParam].(string)
+	sessionID, _ := paramsMap["session_id"].(string)
+	if key == "" || sessionID == "" {
+		return idempotencyScope{}, false
+	}
+	return idempotencyScope{sessionID: sessionID, key: key}, true
+}
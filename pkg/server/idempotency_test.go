@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"goldbox-rpg/pkg/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleMethod_IdempotencyKey(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	server.admin = newTestAdminConsole(20, AdminPermissionGrant)
+	call := func(params map[string]interface{}) (interface{}, error) {
+		data, err := json.Marshal(params)
+		require.NoError(t, err)
+		return server.handleMethod(MethodAdminGrantItem, data)
+	}
+	grant := func(item, key string) map[string]interface{} {
+		return map[string]interface{}{
+			"admin_token":                  testAdminToken,
+			"session_id":                   session.SessionID,
+			"item":                         map[string]interface{}{"name": item, "type": "weapon"},
+			validation.IdempotencyKeyParam: key,
+		}
+	}
+	inventory := len(session.Player.Inventory)
+
+	first, err := call(grant("Long Sword", "grant-1"))
+	require.NoError(t, err)
+	retry, err := call(grant("Long Sword", "grant-1"))
+	require.NoError(t, err)
+	assert.Len(t, session.Player.Inventory, inventory+1, "the retry is not applied again")
+	encoded, err := json.Marshal(first)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(encoded), string(retry.(json.RawMessage)), "the retry gets the original response")
+
+	_, err = call(grant("Shield", "grant-1"))
+	assert.ErrorIs(t, err, ErrIdempotencyConflict, "keys cannot be reused for other calls")
+
+	_, err = call(grant("Long Sword", "grant-2"))
+	require.NoError(t, err)
+	params := grant("Long Sword", "")
+	delete(params, validation.IdempotencyKeyParam)
+	_, err = call(params)
+	require.NoError(t, err)
+	assert.Len(t, session.Player.Inventory, inventory+3, "new keys and unkeyed calls are applied")
+}
+
+func TestIdempotencyCache(t *testing.T) {
+	var cache idempotencyCache
+	scope := idempotencyScope{sessionID: "session-1", key: "attack-7"}
+	params := map[string]interface{}{"session_id": "session-1", "target_id": "orc"}
+
+	call, replay, err := cache.begin(scope, MethodAttack, params)
+	require.NoError(t, err)
+	require.Nil(t, replay)
+
+	// A retry arriving while the call is under way waits for its response
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var waited json.RawMessage
+	go func() {
+		defer wg.Done()
+		_, waited, _ = cache.begin(scope, MethodAttack, params)
+	}()
+	cache.finish(call, map[string]interface{}{"success": true, "damage": 4}, nil)
+	wg.Wait()
+	assert.JSONEq(t, `{"success": true, "damage": 4}`, string(waited))
+
+	// Failed calls are forgotten, so they can be retried
+	failed := idempotencyScope{sessionID: "session-1", key: "attack-8"}
+	call, _, err = cache.begin(failed, MethodAttack, params)
+	require.NoError(t, err)
+	cache.finish(call, nil, ErrNotYourTurn)
+	call, replay, err = cache.begin(failed, MethodAttack, params)
+	require.NoError(t, err)
+	assert.Nil(t, replay)
+	assert.NotNil(t, call)
+
+	// Keys are scoped to the session
+	_, replay, err = cache.begin(idempotencyScope{sessionID: "session-2", key: "attack-7"}, MethodAttack, params)
+	require.NoError(t, err)
+	assert.Nil(t, replay)
+
+	_, ok := idempotencyScopeOf(MethodGetGameState, map[string]interface{}{"session_id": "s", validation.IdempotencyKeyParam: "k"})
+	assert.False(t, ok, "read-only methods ignore the key")
+	_, ok = idempotencyScopeOf(MethodAttack, map[string]interface{}{"session_id": "s", validation.IdempotencyKeyParam: "k"})
+	assert.True(t, ok)
+}
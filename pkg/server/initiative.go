@@ -0,0 +1,313 @@
+package server
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Initiative modes select when combat initiative is rolled.
+const (
+	InitiativeModeFixed    = "fixed"     // Rolled once when combat starts
+	InitiativeModePerRound = "per_round" // Re-rolled at the start of every round
+)
+
+// defaultWeaponSpeed is the speed factor of a weapon its name does not
+// identify.
+const defaultWeaponSpeed = 5
+
+// weaponSpeeds holds the speed factors of the weapon kinds, matched against
+// the weapon name. Slower weapons act later in the round.
+var weaponSpeeds = []struct {
+	kind  string
+	speed int
+}{
+	{"dagger", 2},
+	{"wand", 3},
+	{"staff", 4},
+	{"hammer", 4},
+	{"sword", 5},
+	{"spear", 6},
+	{"mace", 7},
+	{"axe", 7},
+	{"bow", 8},
+}
+
+// InitiativeEntry is the initiative computation of one combat participant.
+// Participants act in descending order of Total, which is the d10 roll plus
+// the dexterity reaction adjustment minus the speed factor of the equipped
+// weapon. Surprised participants sit out the first round.
+type InitiativeEntry struct {
+	EntityID      string `yaml:"entity_id" json:"entity_id"`
+	Roll          int    `yaml:"roll" json:"roll"`
+	DexAdjustment int    `yaml:"dex_adjustment" json:"dex_adjustment"`
+	WeaponSpeed   int    `yaml:"weapon_speed" json:"weapon_speed"`
+	Total         int    `yaml:"total" json:"total"`
+	Surprised     bool   `yaml:"surprised,omitempty" json:"surprised,omitempty"`
+}
+
+// rollInitiative rolls initiative for the combat participants in the
+// style of the Gold Box games.
+//
+// Initiative calculation:
+// - Every participant rolls a d10
+// - Characters add their dexterity reaction adjustment (-3 to +3)
+// - Characters subtract the speed factor of their equipped weapon
+//
+// Parameters:
+//   - participants: Slice of entity IDs representing the combatants
+//
+// Returns:
+//   - []InitiativeEntry: The computations, highest total first; ties keep
+//     the participants' order
+//
+// Notes:
+// - Non-existent entities are skipped from results
+// - Rolls come from rollCombatDice, so they are recorded in the combat replay
+func (s *RPCServer) rollInitiative(participants []string) []InitiativeEntry {
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "rollInitiative",
+		"numParticipants": len(participants),
+	})
+	logger.Debug("rolling initiative")
+
+	entries := make([]InitiativeEntry, 0, len(participants))
+	for _, id := range participants {
+		obj, exists := s.state.WorldState.Objects[id]
+		if !exists {
+			logger.WithField("entityID", id).Warn("entity not found in world state")
+			continue
+		}
+
+		entry := InitiativeEntry{EntityID: id, Roll: s.rollInitiativeDie(id)}
+		if char := combatCharacter(obj); char != nil {
+			entry.DexAdjustment = reactionAdjustment(char.Dexterity)
+			entry.WeaponSpeed = weaponSpeedFactor(char)
+		}
+		entry.Total = entry.Roll + entry.DexAdjustment - entry.WeaponSpeed
+		entries = append(entries, entry)
+
+		logger.WithFields(logrus.Fields{
+			"entityID":      id,
+			"roll":          entry.Roll,
+			"dexAdjustment": entry.DexAdjustment,
+			"weaponSpeed":   entry.WeaponSpeed,
+			"total":         entry.Total,
+		}).Info("rolled initiative")
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Total > entries[j].Total
+	})
+	return entries
+}
+
+// rollInitiativeDie rolls the d10 of an initiative roll for entityID.
+func (s *RPCServer) rollInitiativeDie(entityID string) int {
+	roll, err := s.rollCombatDice(drawInitiative, entityID, "1d10")
+	if err != nil {
+		// "1d10" always parses; keep the roll in range regardless
+		return 1
+	}
+	return roll.Final
+}
+
+// markSurprised flags the surprised participants of a combat that is
+// starting. Players and their companions form the party and every other
+// participant is an opponent. A side is surprised when the other side's stealth beats its
+// perception: a side is as stealthy as its least stealthy member and as
+// perceptive as its most perceptive member. When both sides would be
+// surprised, neither is.
+//
+// Returns:
+//   - []string: IDs of the surprised participants, in initiative order
+func (s *RPCServer) markSurprised(entries []InitiativeEntry) []string {
+	var party, opponents []*game.Character
+	for _, entry := range entries {
+		obj := s.state.WorldState.Objects[entry.EntityID]
+		char := combatCharacter(obj)
+		if char == nil {
+			continue
+		}
+		if s.isPartySide(obj) {
+			party = append(party, char)
+		} else {
+			opponents = append(opponents, char)
+		}
+	}
+	if len(party) == 0 || len(opponents) == 0 {
+		return nil
+	}
+
+	partySurprised := sideStealth(opponents) > sidePerception(party)
+	opponentsSurprised := sideStealth(party) > sidePerception(opponents)
+	if partySurprised == opponentsSurprised {
+		return nil
+	}
+
+	var surprised []string
+	for i := range entries {
+		if s.isPartySide(s.state.WorldState.Objects[entries[i].EntityID]) == partySurprised {
+			entries[i].Surprised = true
+			surprised = append(surprised, entries[i].EntityID)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":       "markSurprised",
+		"partySurprised": partySurprised,
+		"surprised":      surprised,
+	}).Info("combat starts with a surprise round")
+	return surprised
+}
+
+// startInitiativeRound prepares the initiative order of a new combat round.
+// Participants surprised in the first round rejoin the order, and in
+// per-round mode everyone re-rolls.
+//
+// Parameters:
+//   - nextTurn: The entity whose turn the plain turn advance selected
+//
+// Returns:
+//   - string: The entity that acts first in the new round
+func (s *RPCServer) startInitiativeRound(nextTurn string) string {
+	tm := s.state.TurnManager
+	if !tm.IsInCombat || len(tm.Breakdown) == 0 {
+		return nextTurn
+	}
+
+	rejoining := false
+	for i := range tm.Breakdown {
+		rejoining = rejoining || tm.Breakdown[i].Surprised
+		tm.Breakdown[i].Surprised = false
+	}
+
+	switch {
+	case tm.InitiativeMode == InitiativeModePerRound:
+		participants := make([]string, len(tm.Breakdown))
+		for i, entry := range tm.Breakdown {
+			participants[i] = entry.EntityID
+		}
+		tm.Breakdown = s.rollInitiative(participants)
+	case !rejoining:
+		return nextTurn
+	}
+
+	order := initiativeOrder(tm.Breakdown)
+	if err := tm.validateInitiativeOrder(order); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "startInitiativeRound",
+			"error":    err.Error(),
+		}).Error("keeping the previous initiative order")
+		return nextTurn
+	}
+	tm.Initiative = order
+	tm.CurrentIndex = 0
+	tm.notifyState(CombatStateChange{Kind: CombatStateInitiative})
+
+	logrus.WithFields(logrus.Fields{
+		"function": "startInitiativeRound",
+		"round":    tm.CurrentRound,
+		"order":    order,
+	}).Info("initiative order set for new round")
+	return order[0]
+}
+
+// initiativeOrder returns the IDs of the participants that act this round,
+// in initiative order.
+func initiativeOrder(entries []InitiativeEntry) []string {
+	order := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Surprised {
+			order = append(order, entry.EntityID)
+		}
+	}
+	return order
+}
+
+// combatCharacter returns the character attributes of a combatant, or nil
+// for objects without them.
+func combatCharacter(obj game.GameObject) *game.Character {
+	switch c := obj.(type) {
+	case *game.Player:
+		return &c.Character
+	case *game.NPC:
+		return &c.Character
+	case *game.Character:
+		return c
+	default:
+		return nil
+	}
+}
+
+// reactionAdjustment returns the initiative adjustment of a dexterity
+// score: -3 at 3, -2 at 4, -1 at 5, none from 6 to 15, then +1 per point
+// up to +3 at 18.
+func reactionAdjustment(score int) int {
+	switch {
+	case score <= 5:
+		return max(score-6, -3)
+	case score <= 15:
+		return 0
+	default:
+		return min(score-15, 3)
+	}
+}
+
+// weaponSpeedFactor returns the speed factor of a character's equipped
+// weapon, or 0 when unarmed. A "speed:N" item property overrides the
+// speed of the weapon kind.
+func weaponSpeedFactor(char *game.Character) int {
+	for _, slot := range []game.EquipmentSlot{game.SlotWeaponMain, game.SlotHands} {
+		weapon, equipped := char.Equipment[slot]
+		if !equipped {
+			continue
+		}
+		for _, property := range weapon.Properties {
+			if value, ok := strings.CutPrefix(property, "speed:"); ok {
+				if speed, err := strconv.Atoi(value); err == nil {
+					return speed
+				}
+			}
+		}
+		name := strings.ToLower(weapon.Name + " " + weapon.ID)
+		for _, w := range weaponSpeeds {
+			if strings.Contains(name, w.kind) {
+				return w.speed
+			}
+		}
+		return defaultWeaponSpeed
+	}
+	return 0
+}
+
+// sideStealth returns the stealth of a side: that of its least stealthy
+// member, from their dexterity reaction adjustment and Stealth skill.
+func sideStealth(side []*game.Character) int {
+	stealth := 0
+	for i, char := range side {
+		score := reactionAdjustment(char.Dexterity) + char.Skills["Stealth"]
+		if i == 0 || score < stealth {
+			stealth = score
+		}
+	}
+	return stealth
+}
+
+// sidePerception returns the perception of a side: that of its most
+// perceptive member, from their wisdom on the reaction adjustment scale and
+// Perception skill.
+func sidePerception(side []*game.Character) int {
+	perception := 0
+	for i, char := range side {
+		score := reactionAdjustment(char.Wisdom) + char.Skills["Perception"]
+		if i == 0 || score > perception {
+			perception = score
+		}
+	}
+	return perception
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addInitiativeGoblin adds an NPC combatant to the test world
+func addInitiativeGoblin(t *testing.T, server *RPCServer, dexterity, stealth int) {
+	t.Helper()
+	goblin := &game.NPC{Character: game.Character{
+		ID:        "goblin",
+		Name:      "Goblin",
+		HP:        10,
+		MaxHP:     10,
+		Dexterity: dexterity,
+		Wisdom:    10,
+		Skills:    map[string]int{"Stealth": stealth},
+		Equipment: make(map[game.EquipmentSlot]game.Item),
+	}}
+	require.NoError(t, server.state.WorldState.AddObject(goblin))
+}
+
+func startInitiativeCombat(t *testing.T, server *RPCServer, params map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	params["session_id"] = "test-session-001"
+	params["participant_ids"] = []string{"test-player-001", "goblin"}
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	result, err := server.handleStartCombat(data)
+	require.NoError(t, err)
+	return result.(map[string]interface{})
+}
+
+func TestReactionAdjustment(t *testing.T) {
+	for score, want := range map[int]int{3: -3, 4: -2, 5: -1, 6: 0, 10: 0, 15: 0, 16: 1, 17: 2, 18: 3, 19: 3} {
+		assert.Equal(t, want, reactionAdjustment(score), "dexterity %d", score)
+	}
+}
+
+func TestWeaponSpeedFactor(t *testing.T) {
+	tests := []struct {
+		name   string
+		slot   game.EquipmentSlot
+		weapon *game.Item
+		want   int
+	}{
+		{"unarmed", game.SlotWeaponMain, nil, 0},
+		{"dagger", game.SlotWeaponMain, &game.Item{ID: "dagger", Name: "Dagger", Type: "weapon"}, 2},
+		{"long bow in hands", game.SlotHands, &game.Item{Name: "Long Bow", Type: "weapon"}, 8},
+		{"unknown weapon", game.SlotWeaponMain, &game.Item{Name: "Flail", Type: "weapon"}, defaultWeaponSpeed},
+		{"speed property", game.SlotWeaponMain, &game.Item{Name: "Sword of Speed", Type: "weapon", Properties: []string{"speed:1"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char := &game.Character{Equipment: make(map[game.EquipmentSlot]game.Item)}
+			if tt.weapon != nil {
+				char.Equipment[tt.slot] = *tt.weapon
+			}
+			assert.Equal(t, tt.want, weaponSpeedFactor(char))
+		})
+	}
+}
+
+func TestStartCombat_InitiativeBreakdownAndSurprise(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Equipment[game.SlotWeaponMain] = game.Item{ID: "dagger", Name: "Dagger", Type: "weapon"}
+	addInitiativeGoblin(t, server, 18, 2)
+
+	result := startInitiativeCombat(t, server, map[string]interface{}{"initiative_mode": InitiativeModeFixed})
+
+	breakdown := result["initiative_breakdown"].([]InitiativeEntry)
+	require.Len(t, breakdown, 2)
+	for _, entry := range breakdown {
+		assert.GreaterOrEqual(t, entry.Roll, 1)
+		assert.LessOrEqual(t, entry.Roll, 10)
+		assert.Equal(t, entry.Roll+entry.DexAdjustment-entry.WeaponSpeed, entry.Total)
+		switch entry.EntityID {
+		case "test-player-001":
+			assert.Equal(t, 0, entry.DexAdjustment)
+			assert.Equal(t, 2, entry.WeaponSpeed)
+			assert.True(t, entry.Surprised, "the goblin's stealth beats the party's perception")
+		case "goblin":
+			assert.Equal(t, 3, entry.DexAdjustment)
+			assert.Equal(t, 0, entry.WeaponSpeed)
+			assert.False(t, entry.Surprised)
+		}
+	}
+	assert.GreaterOrEqual(t, breakdown[0].Total, breakdown[1].Total)
+	assert.Equal(t, []string{"test-player-001"}, result["surprised"])
+	assert.Equal(t, []string{"goblin"}, result["initiative"])
+	assert.Equal(t, "goblin", result["first_turn"])
+	assert.Equal(t, InitiativeModeFixed, result["initiative_mode"])
+
+	// The surprised player rejoins in the second round without re-rolling
+	first := server.startInitiativeRound("goblin")
+	assert.Equal(t, initiativeOrder(breakdown), server.state.TurnManager.Initiative)
+	assert.Equal(t, server.state.TurnManager.Initiative[0], first)
+	for _, entry := range server.state.TurnManager.Breakdown {
+		assert.False(t, entry.Surprised)
+	}
+	assert.Len(t, server.replays.active.Draws, 2)
+}
+
+func TestStartCombat_PerRoundInitiative(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+	addInitiativeGoblin(t, server, 10, 0)
+
+	result := startInitiativeCombat(t, server, map[string]interface{}{"initiative_mode": InitiativeModePerRound})
+
+	assert.Empty(t, result["surprised"])
+	assert.Len(t, result["initiative"], 2)
+	assert.Len(t, server.replays.active.Draws, 2)
+
+	first := server.startInitiativeRound("goblin")
+	assert.Len(t, server.replays.active.Draws, 4, "every participant re-rolls")
+	assert.Len(t, server.state.TurnManager.Breakdown, 2)
+	assert.Equal(t, initiativeOrder(server.state.TurnManager.Breakdown), server.state.TurnManager.Initiative)
+	assert.Equal(t, server.state.TurnManager.Initiative[0], first)
+}
+
+func TestStartCombat_InvalidInitiativeMode(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	_, err := server.handleStartCombat(json.RawMessage(`{"session_id":"test-session-001","participant_ids":["test-player-001"],"initiative_mode":"group"}`))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid combat parameters")
+	assert.False(t, server.state.TurnManager.IsInCombat)
+}
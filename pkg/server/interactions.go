@@ -0,0 +1,175 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// placeLevelFeatures places the interactive features generated for a level
+// that has just been added to the world. Failures are logged; the level
+// stays in the world without the features that could not be placed.
+func (s *RPCServer) placeLevelFeatures(level *game.Level) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "placeLevelFeatures",
+		"level_id": level.ID,
+	})
+
+	features, _ := level.Properties["features"].([]*game.Feature)
+	if len(features) == 0 {
+		return
+	}
+
+	world := s.state.WorldState
+	levelIndex := worldLevelIndex(world, level.ID)
+	for _, site := range features {
+		feature := &game.Feature{
+			ID:           site.ID,
+			Name:         site.Name,
+			Description:  site.Description,
+			Kind:         site.Kind,
+			Position:     game.Position{X: site.Position.X, Y: site.Position.Y, Level: levelIndex},
+			Interactions: site.Interactions,
+		}
+		if err := world.AddObject(feature); err != nil {
+			logger.WithError(err).WithField("feature_id", feature.ID).Warn("failed to place feature")
+		}
+	}
+	logger.WithField("features", len(features)).Info("level features placed")
+}
+
+// triggerMechanisms works the doors an interaction triggered, returning
+// their new states by door ID
+func (s *RPCServer) triggerMechanisms(actorID string, targetIDs []string) map[string]game.DoorState {
+	states := make(map[string]game.DoorState, len(targetIDs))
+	for _, id := range targetIDs {
+		obj, exists := s.state.WorldState.GetObject(id)
+		door, isDoor := obj.(*game.Door)
+		if !exists || !isDoor {
+			logrus.WithFields(logrus.Fields{
+				"function":  "triggerMechanisms",
+				"target_id": id,
+			}).Warn("triggered mechanism is not a door in the world")
+			continue
+		}
+		state, err := door.Trigger()
+		if err != nil {
+			continue
+		}
+		states[id] = state
+		change := "opened"
+		if state == game.DoorClosed {
+			change = "closed"
+		}
+		s.emitDoorChanged(door, actorID, change)
+	}
+	return states
+}
+
+// handleInteractObject performs a verb, such as "pull" or "drink", on an
+// interactive feature on or next to the session's player's tile. The
+// feature rolls any check the verb calls for and applies its effects to
+// the player; a lever linked to a door works the door as well. Without a
+// verb the call lists the verbs the feature offers. In combat it costs
+// game.ActionCostInteract action points.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - object_id: string - The feature to use
+//   - verb: string - What to do with it
+//
+// Returns:
+//   - interface{}: Map containing the interaction result and the states of
+//     any doors it worked
+//   - error: Invalid parameters or session, ErrFeatureNotFound if the
+//     feature is not within reach, ErrInteractionFailed if the verb is
+//     unknown or already spent
+func (s *RPCServer) handleInteractObject(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleInteractObject",
+	})
+	logger.Debug("entering handleInteractObject")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		ObjectID  string `json:"object_id"`
+		Verb      string `json:"verb"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid interaction parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	player := session.Player
+
+	obj, exists := s.state.WorldState.GetObject(req.ObjectID)
+	feature, interactable := obj.(game.Interactable)
+	if !exists || !interactable || !withinReach(player.GetPosition(), feature.GetPosition()) {
+		return nil, ErrFeatureNotFound.WithData(map[string]interface{}{"object_id": req.ObjectID})
+	}
+	if req.Verb == "" {
+		return map[string]interface{}{
+			"success":   true,
+			"object_id": feature.GetID(),
+			"verbs":     feature.Verbs(),
+		}, nil
+	}
+	if err := s.checkActionPoints(player, "using "+feature.GetName(), game.ActionCostInteract); err != nil {
+		return nil, err
+	}
+
+	result, err := feature.Interact(&player.Character, req.Verb, game.GlobalDiceRoller)
+	if err != nil {
+		return nil, ErrInteractionFailed.WithMessage("%v", err).WithData(map[string]interface{}{
+			"object_id": feature.GetID(),
+			"verbs":     feature.Verbs(),
+		})
+	}
+	s.spendActionPoints(player, game.ActionCostInteract)
+
+	response := map[string]interface{}{
+		"success": true,
+		"result":  result,
+		"verbs":   feature.Verbs(),
+	}
+	if doors := s.triggerMechanisms(player.GetID(), result.Triggered); len(doors) > 0 {
+		response["doors"] = doors
+	}
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventFeatureUsed,
+		SourceID: player.GetID(),
+		TargetID: feature.GetID(),
+		Data: map[string]interface{}{
+			"feature_id": feature.GetID(),
+			"name":       feature.GetName(),
+			"position":   feature.GetPosition(),
+			"verb":       req.Verb,
+			"success":    result.Success,
+			"active":     result.Active,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+	if result.Damage > 0 && player.GetHealth() == 0 {
+		s.handleCharacterDeath(&player.Character)
+		s.notifyDeathWebhook(player)
+		s.recordFeatureDeath(player, feature)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"player_id":  player.GetID(),
+		"feature_id": feature.GetID(),
+		"verb":       req.Verb,
+		"success":    result.Success,
+	}).Info("feature used")
+
+	return response, nil
+}
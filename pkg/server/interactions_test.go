@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceLevelFeatures(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	generated := &game.Feature{ID: "crypt_feature_0", Name: "Fountain", Kind: game.FeatureFountain, Position: game.Position{X: 3, Y: 4}}
+	level := &game.Level{
+		ID:         "crypt",
+		Properties: map[string]interface{}{"features": []*game.Feature{generated}},
+	}
+
+	server.placeLevelFeatures(level)
+
+	obj, exists := server.state.WorldState.GetObject("crypt_feature_0")
+	require.True(t, exists)
+	feature := obj.(*game.Feature)
+	assert.NotSame(t, generated, feature, "the world gets its own copy of the feature")
+	assert.Equal(t, game.Position{X: 3, Y: 4, Level: -1}, feature.GetPosition())
+}
+
+func TestHandleInteractObject(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	world := server.state.WorldState
+
+	door := &game.Door{ID: "door-1", Name: "Portcullis", Position: game.Position{X: 14, Y: 10}, State: game.DoorLocked, LockDC: 30}
+	lever := &game.Feature{
+		ID:       "lever-1",
+		Name:     "Lever",
+		Kind:     game.FeatureLever,
+		Position: game.Position{X: 11, Y: 10},
+		Interactions: []game.Interaction{{
+			Verb:       "pull",
+			Repeatable: true,
+			OnSuccess: []game.InteractionEffect{
+				{Type: game.InteractionToggle},
+				{Type: game.InteractionTrigger, TargetID: door.ID},
+			},
+		}},
+	}
+	shelf := &game.Feature{
+		ID:           "shelf-1",
+		Name:         "Bookshelf",
+		Kind:         game.FeatureBookshelf,
+		Position:     game.Position{X: 9, Y: 11},
+		Interactions: []game.Interaction{{Verb: "search", OnSuccess: []game.InteractionEffect{{Type: game.InteractionGold, Amount: 40}}}},
+	}
+	far := &game.Feature{ID: "fountain-1", Name: "Fountain", Position: game.Position{X: 15, Y: 15}}
+	for _, obj := range []game.GameObject{door, lever, shelf, far} {
+		require.NoError(t, world.AddObject(obj))
+	}
+
+	interact := func(objectID, verb string) (interface{}, error) {
+		params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "object_id": objectID, "verb": verb})
+		return server.handleInteractObject(params)
+	}
+
+	result, err := interact(lever.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pull"}, result.(map[string]interface{})["verbs"])
+
+	result, err = interact(lever.ID, "pull")
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.True(t, response["result"].(*game.InteractionResult).Active)
+	assert.Equal(t, map[string]game.DoorState{door.ID: game.DoorOpen}, response["doors"])
+	assert.Equal(t, game.DoorOpen, door.GetState(), "the lever opens the locked door")
+
+	gold := session.Player.Gold
+	_, err = interact(shelf.ID, "search")
+	require.NoError(t, err)
+	assert.Equal(t, gold+40, session.Player.Gold)
+
+	_, err = interact(shelf.ID, "search")
+	assert.True(t, errors.Is(err, ErrInteractionFailed), "a one-time search cannot be repeated")
+
+	_, err = interact(far.ID, "drink")
+	assert.True(t, errors.Is(err, ErrFeatureNotFound), "features out of reach cannot be used")
+
+	_, err = interact(door.ID, "pull")
+	assert.True(t, errors.Is(err, ErrFeatureNotFound), "doors are not interactive features")
+}
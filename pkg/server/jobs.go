@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// contentGenerationJobTimeout bounds a background generateContent job
+const contentGenerationJobTimeout = 5 * time.Minute
+
+// submitContentGenerationJob runs a generateContent request as a background
+// job of the PCG manager. The job's result is the response the request
+// would have returned synchronously.
+func (s *RPCServer) submitContentGenerationJob(req *contentGenerationRequest, preview bool) (interface{}, error) {
+	switch pcg.ContentType(req.ContentType) {
+	case pcg.ContentTypeTerrain, pcg.ContentTypeItems, pcg.ContentTypeLevels, pcg.ContentTypeQuests:
+	default:
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("unsupported content type: %s", req.ContentType), nil)
+	}
+
+	jobID, err := s.pcgManager.SubmitGenerationJob(pcg.JobSpec{
+		ContentType: pcg.ContentType(req.ContentType),
+		LocationID:  req.LocationID,
+		Owner:       req.SessionID,
+		Timeout:     contentGenerationJobTimeout,
+		Run: func(ctx context.Context) (interface{}, error) {
+			return s.completeContentGeneration(ctx, req, preview)
+		},
+	})
+	if err != nil {
+		return nil, ErrUnavailable.Wrap(err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":    "submitContentGenerationJob",
+		"sessionID":   req.SessionID,
+		"jobID":       jobID,
+		"contentType": req.ContentType,
+		"locationID":  req.LocationID,
+	}).Info("content generation job submitted")
+
+	return map[string]interface{}{
+		"success":      true,
+		"async":        true,
+		"job_id":       jobID,
+		"status":       pcg.JobQueued,
+		"content_type": req.ContentType,
+		"location_id":  req.LocationID,
+	}, nil
+}
+
+// handleGetGenerationJobStatus reports the status and progress of a
+// background generation job submitted by the requesting session. Once the
+// job has completed the response includes its result.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session that submitted the job
+//   - job_id: string - The job ID returned by generateContent
+//
+// Returns:
+//   - interface{}: Map containing the job's status, progress, and result or
+//     error once finished
+//   - error: Invalid parameters or session, or a job that does not exist or
+//     belongs to another session
+func (s *RPCServer) handleGetGenerationJobStatus(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+		JobID     string `json:"job_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid generation job parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	job, err := s.pcgManager.GetGenerationJob(req.JobID)
+	if err != nil || job.Owner != req.SessionID {
+		return nil, ErrJobNotFound.WithData(map[string]interface{}{"job_id": req.JobID})
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"job":     job,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateContent_AsyncJob(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	result, err := server.handleGenerateContent(json.RawMessage(`{"session_id":"test-session-001","content_type":"quests","location_id":"async_area","preview":true,"async":true}`))
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, true, response["async"])
+	jobID := response["job_id"].(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = server.pcgManager.GetJobQueue().Wait(ctx, jobID)
+	require.NoError(t, err)
+
+	result, err = server.handleGetGenerationJobStatus(json.RawMessage(`{"session_id":"test-session-001","job_id":"` + jobID + `"}`))
+	require.NoError(t, err)
+	job := result.(map[string]interface{})["job"].(pcg.GenerationJob)
+	require.Equal(t, pcg.JobCompleted, job.Status, job.Error)
+	assert.Equal(t, 100.0, job.Progress.Percent)
+
+	jobResult := job.Result.(map[string]interface{})
+	assert.Equal(t, true, jobResult["preview"], "the job result is the synchronous response")
+	_, err = commitPreview(server, session.SessionID, jobResult["preview_id"].(string))
+	assert.NoError(t, err)
+}
+
+func TestGetGenerationJobStatus_Errors(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+
+	jobID, err := server.pcgManager.SubmitGenerationJob(pcg.JobSpec{
+		Owner: "another-session",
+		Run:   func(ctx context.Context) (interface{}, error) { return nil, nil },
+	})
+	require.NoError(t, err)
+
+	_, err = server.handleGetGenerationJobStatus(json.RawMessage(`{"session_id":"test-session-001","job_id":"` + jobID + `"}`))
+	assert.ErrorIs(t, err, ErrJobNotFound, "jobs of other sessions are not visible")
+
+	_, err = server.handleGetGenerationJobStatus(json.RawMessage(`{"session_id":"test-session-001","job_id":"missing"}`))
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	_, err = server.handleGenerateContent(json.RawMessage(`{"session_id":"test-session-001","content_type":"dialogue","location_id":"x","async":true}`))
+	assert.Error(t, err, "unsupported content types are rejected before a job is queued")
+}
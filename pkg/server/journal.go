@@ -0,0 +1,442 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// JournalEntry is a game master action that admin.undoLastAction can revert.
+type JournalEntry struct {
+	ID          string    `json:"id"`          // Unique entry ID
+	Method      RPCMethod `json:"method"`      // The RPC that made the change
+	TargetID    string    `json:"target_id"`   // The object changed
+	Description string    `json:"description"` // What the action did
+	CreatedAt   time.Time `json:"created_at"`  // When the action was taken
+
+	check func() error // Reports how the state diverged since, or nil
+	undo  func() error // Applies the inverse operation
+}
+
+// actionJournal keeps, per session, the most recent ActionJournalSize
+// admin actions with their inverse operations. The zero value is ready to
+// use.
+type actionJournal struct {
+	mu      sync.Mutex
+	entries map[string][]*JournalEntry
+}
+
+// record appends entry to the session's journal, forgetting the oldest
+// entry once the journal is full, and returns the entry's ID.
+func (j *actionJournal) record(sessionID string, entry *JournalEntry) string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.entries == nil {
+		j.entries = make(map[string][]*JournalEntry)
+	}
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = time.Now()
+	entries := append(j.entries[sessionID], entry)
+	if len(entries) > ActionJournalSize {
+		entries = slices.Delete(entries, 0, len(entries)-ActionJournalSize)
+	}
+	j.entries[sessionID] = entries
+	return entry.ID
+}
+
+// pop removes and returns the session's most recent entry.
+func (j *actionJournal) pop(sessionID string) (*JournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := j.entries[sessionID]
+	if len(entries) == 0 {
+		return nil, false
+	}
+	entry := entries[len(entries)-1]
+	j.entries[sessionID] = entries[:len(entries)-1]
+	return entry, true
+}
+
+// size returns the number of entries the session can still undo.
+func (j *actionJournal) size(sessionID string) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries[sessionID])
+}
+
+// forget drops the journal of an ended session.
+func (j *actionJournal) forget(sessionID string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.entries, sessionID)
+}
+
+// inventoryHolder is implemented by objects that carry items.
+type inventoryHolder interface {
+	AddItemToInventory(item game.Item) error
+	RemoveItemFromInventory(itemID string) (*game.Item, error)
+	FindItemInInventory(itemID string) (*game.Item, int)
+}
+
+// journalEffect records the inverse of an effect applied by applyEffect:
+// removing the effect, unless it has since expired or been removed. It
+// returns the journal entry's ID.
+func (s *RPCServer) journalEffect(sessionID, targetID string, holder game.EffectHolder, effect *game.Effect) string {
+	return s.journal.record(sessionID, &JournalEntry{
+		Method:      MethodApplyEffect,
+		TargetID:    targetID,
+		Description: fmt.Sprintf("applied %s to %s", effect.Type, targetID),
+		check: func() error {
+			if !s.objectExists(targetID, holder) {
+				return fmt.Errorf("%s no longer exists", targetID)
+			}
+			for _, active := range holder.GetEffects() {
+				if active.ID == effect.ID {
+					return nil
+				}
+			}
+			return fmt.Errorf("effect %s is no longer active on %s", effect.ID, targetID)
+		},
+		undo: func() error {
+			return holder.RemoveEffect(effect.ID)
+		},
+	})
+}
+
+// objectExists reports whether obj is still the world object with ID id.
+// Session players need not be world objects and always exist.
+func (s *RPCServer) objectExists(id string, obj interface{}) bool {
+	if _, isPlayer := obj.(*game.Player); isPlayer {
+		return true
+	}
+	current, exists := s.state.WorldState.Objects[id]
+	return exists && current == obj
+}
+
+// adminTarget returns the object an admin action applies to: the
+// session's player when targetID is empty or the player's ID, otherwise
+// the world object with that ID.
+func (s *RPCServer) adminTarget(session *PlayerSession, targetID string) (game.GameObject, error) {
+	if targetID == "" || targetID == session.Player.GetID() {
+		return session.Player, nil
+	}
+	target, exists := s.state.WorldState.Objects[targetID]
+	if !exists {
+		return nil, ErrInvalidTarget.WithMessage("unknown target: %s", targetID)
+	}
+	return target, nil
+}
+
+// itemSpec describes an item created by admin.giveItem or admin.grantItem.
+type itemSpec struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Damage     string   `json:"damage"`
+	ArmorClass int      `json:"armor_class"`
+	Weight     int      `json:"weight"`
+	Value      int      `json:"value"`
+	Properties []string `json:"properties"`
+}
+
+// newItem creates the described item with a new ID.
+func (spec itemSpec) newItem() game.Item {
+	return game.Item{
+		ID:         game.NewUID(),
+		Name:       spec.Name,
+		Type:       spec.Type,
+		Damage:     spec.Damage,
+		AC:         spec.ArmorClass,
+		Weight:     spec.Weight,
+		Value:      spec.Value,
+		Properties: spec.Properties,
+	}
+}
+
+// handleAdminGiveItem creates an item in a character's inventory. The
+// action is recorded in the session's journal, so admin.undoLastAction
+// takes the item back.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - session_id: string - The session whose journal records the action
+//   - target_id: string - The receiving character (optional, defaults to
+//     the session's player)
+//   - item: object - name, type, weight and value, and optionally damage,
+//     armor_class and properties
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - item: The created game.Item with its new ID
+//   - journal_id: The journal entry admin.undoLastAction reverts
+//   - error: Invalid parameters or session, an unknown target or one
+//     without an inventory, or a full inventory
+func (s *RPCServer) handleAdminGiveItem(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleAdminGiveItem",
+	})
+	logger.Debug("entering handleAdminGiveItem")
+
+	var req struct {
+		SessionID string   `json:"session_id"`
+		TargetID  string   `json:"target_id"`
+		Item      itemSpec `json:"item"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid give item parameters", err.Error())
+	}
+	if req.Item.Name == "" || req.Item.Type == "" {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Item name and type are required", nil)
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.adminTarget(session, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	holder, ok := target.(inventoryHolder)
+	if !ok {
+		return nil, ErrInvalidTarget.WithMessage("target cannot carry items")
+	}
+
+	item := req.Item.newItem()
+	if err := holder.AddItemToInventory(item); err != nil {
+		return nil, ErrInvalidTarget.WithMessage("cannot give item: %v", err)
+	}
+
+	targetID := target.GetID()
+	journalID := s.journal.record(req.SessionID, &JournalEntry{
+		Method:      MethodAdminGiveItem,
+		TargetID:    targetID,
+		Description: fmt.Sprintf("gave %s to %s", item.Name, targetID),
+		check: func() error {
+			if !s.objectExists(targetID, target) {
+				return fmt.Errorf("%s no longer exists", targetID)
+			}
+			if found, _ := holder.FindItemInInventory(item.ID); found == nil {
+				return fmt.Errorf("%s no longer carries %s", targetID, item.Name)
+			}
+			return nil
+		},
+		undo: func() error {
+			_, err := holder.RemoveItemFromInventory(item.ID)
+			return err
+		},
+	})
+
+	logger.WithFields(logrus.Fields{
+		"target_id": targetID,
+		"item_id":   item.ID,
+		"item_name": item.Name,
+	}).Info("item given")
+
+	return map[string]interface{}{
+		"success":    true,
+		"item":       item,
+		"journal_id": journalID,
+	}, nil
+}
+
+// handleAdminTeleport moves an object straight to a position, ignoring
+// movement costs and turn order. The action is recorded in the session's
+// journal, so admin.undoLastAction moves the object back.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - session_id: string - The session whose journal records the action
+//   - target_id: string - The object to move (optional, defaults to the
+//     session's player)
+//   - position: object - x, y and level of the destination
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - from, position: The previous and new positions
+//   - journal_id: The journal entry admin.undoLastAction reverts
+//   - error: Invalid parameters or session, an unknown target, or a
+//     destination out of bounds or blocked by an obstacle
+func (s *RPCServer) handleAdminTeleport(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleAdminTeleport",
+	})
+	logger.Debug("entering handleAdminTeleport")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		TargetID  string `json:"target_id"`
+		Position  struct {
+			X     int `json:"x"`
+			Y     int `json:"y"`
+			Level int `json:"level"`
+		} `json:"position"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid teleport parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.adminTarget(session, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := game.Position{X: req.Position.X, Y: req.Position.Y, Level: req.Position.Level, Facing: target.GetPosition().Facing}
+	if err := s.checkDestination(target, to); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid teleport destination", err.Error())
+	}
+	from := target.GetPosition()
+	if err := s.teleportObject(target, to); err != nil {
+		return nil, err
+	}
+
+	targetID := target.GetID()
+	journalID := s.journal.record(req.SessionID, &JournalEntry{
+		Method:      MethodAdminTeleport,
+		TargetID:    targetID,
+		Description: fmt.Sprintf("teleported %s from %d,%d to %d,%d", targetID, from.X, from.Y, to.X, to.Y),
+		check: func() error {
+			if !s.objectExists(targetID, target) {
+				return fmt.Errorf("%s no longer exists", targetID)
+			}
+			if target.GetPosition() != to {
+				return fmt.Errorf("%s has moved since", targetID)
+			}
+			return s.checkDestination(target, from)
+		},
+		undo: func() error {
+			return s.teleportObject(target, from)
+		},
+	})
+
+	logger.WithFields(logrus.Fields{
+		"target_id": targetID,
+		"from":      from,
+		"to":        to,
+	}).Info("object teleported")
+
+	return map[string]interface{}{
+		"success":    true,
+		"from":       from,
+		"position":   to,
+		"journal_id": journalID,
+	}, nil
+}
+
+// checkDestination reports why obj cannot be placed at pos, or nil.
+func (s *RPCServer) checkDestination(obj game.GameObject, pos game.Position) error {
+	world := s.state.WorldState
+	if pos.X < 0 || pos.X >= world.Width || pos.Y < 0 || pos.Y >= world.Height {
+		return fmt.Errorf("position %d,%d is out of bounds", pos.X, pos.Y)
+	}
+	if pos.Level < 0 || (len(world.Levels) > 0 && pos.Level >= len(world.Levels)) {
+		return fmt.Errorf("level %d not found", pos.Level)
+	}
+	for _, other := range world.GetObjectsAt(pos) {
+		if other.GetID() != obj.GetID() && other.IsObstacle() {
+			return fmt.Errorf("position %d,%d is blocked by %s", pos.X, pos.Y, other.GetID())
+		}
+	}
+	return nil
+}
+
+// teleportObject places obj at pos and emits the movement event that keeps
+// the world's spatial indexes and map deltas current.
+func (s *RPCServer) teleportObject(obj game.GameObject, pos game.Position) error {
+	from := obj.GetPosition()
+	if err := obj.SetPosition(pos); err != nil {
+		return ErrInvalidTarget.WithMessage("cannot move %s: %v", obj.GetID(), err)
+	}
+	s.eventSys.Emit(game.GameEvent{
+		Type:     game.EventMovement,
+		SourceID: obj.GetID(),
+		Data: map[string]interface{}{
+			"old_position": from,
+			"new_position": pos,
+			"teleport":     true,
+		},
+	})
+	return nil
+}
+
+// handleAdminUndoLastAction reverts the most recent journaled action of
+// the session: applyEffect, admin.giveItem or admin.teleport. An action whose effects have
+// since changed, such as an item that was dropped or an object that moved
+// on, is not reverted; it is dropped from the journal and reported as a
+// conflict, so the next call undoes the action before it.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - session_id: string - The session whose journal is reverted
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - undone: The reverted JournalEntry
+//   - remaining: Number of actions still in the journal
+//   - error: Invalid parameters or session, ErrNothingToUndo when the
+//     journal is empty, or ErrUndoConflict when the state has diverged
+func (s *RPCServer) handleAdminUndoLastAction(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleAdminUndoLastAction",
+	})
+	logger.Debug("entering handleAdminUndoLastAction")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid undo parameters", err.Error())
+	}
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	entry, ok := s.journal.pop(req.SessionID)
+	if !ok {
+		return nil, ErrNothingToUndo
+	}
+
+	if err := entry.check(); err != nil {
+		logger.WithError(err).WithField("journal_id", entry.ID).Info("undo conflict")
+		return nil, ErrUndoConflict.WithMessage("cannot undo %q: %v", entry.Description, err).
+			WithData(map[string]interface{}{"journal_id": entry.ID, "method": entry.Method})
+	}
+	if err := entry.undo(); err != nil {
+		logger.WithError(err).WithField("journal_id", entry.ID).Error("undo failed")
+		return nil, ErrUndoConflict.WithMessage("failed to undo %q: %v", entry.Description, err).
+			WithData(map[string]interface{}{"journal_id": entry.ID, "method": entry.Method})
+	}
+
+	logger.WithFields(logrus.Fields{
+		"journal_id": entry.ID,
+		"method":     entry.Method,
+		"target_id":  entry.TargetID,
+	}).Info("admin action undone")
+
+	return map[string]interface{}{
+		"success":   true,
+		"undone":    entry,
+		"remaining": s.journal.size(req.SessionID),
+	}, nil
+}
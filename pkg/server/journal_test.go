@@ -0,0 +1,215 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callAdmin marshals params and calls an admin action handler.
+func callAdmin(t *testing.T, handler func(json.RawMessage) (interface{}, error), params map[string]interface{}) (map[string]interface{}, error) {
+	t.Helper()
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+	result, err := handler(data)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]interface{}), nil
+}
+
+func TestUndoLastAction_GiveItem(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	undo := map[string]interface{}{"session_id": session.SessionID}
+
+	_, err := callAdmin(t, server.handleAdminUndoLastAction, undo)
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	give := map[string]interface{}{
+		"session_id": session.SessionID,
+		"item":       map[string]interface{}{"name": "Long Sword", "type": "weapon", "damage": "1d8", "weight": 4},
+	}
+	result, err := callAdmin(t, server.handleAdminGiveItem, give)
+	require.NoError(t, err)
+	assert.Equal(t, "Long Sword", result["item"].(game.Item).Name)
+	require.Len(t, session.Player.Inventory, 1)
+
+	result, err = callAdmin(t, server.handleAdminUndoLastAction, undo)
+	require.NoError(t, err)
+	assert.Equal(t, MethodAdminGiveItem, result["undone"].(*JournalEntry).Method)
+	assert.Equal(t, 0, result["remaining"])
+	assert.Empty(t, session.Player.Inventory)
+
+	// An item that has left the inventory cannot be taken back
+	_, err = callAdmin(t, server.handleAdminGiveItem, give)
+	require.NoError(t, err)
+	item := session.Player.Inventory[0]
+	_, err = session.Player.RemoveItemFromInventory(item.ID)
+	require.NoError(t, err)
+	_, err = callAdmin(t, server.handleAdminUndoLastAction, undo)
+	assert.ErrorIs(t, err, ErrUndoConflict)
+	_, err = callAdmin(t, server.handleAdminUndoLastAction, undo)
+	assert.ErrorIs(t, err, ErrNothingToUndo, "a conflicting action is dropped from the journal")
+
+	_, err = callAdmin(t, server.handleAdminGiveItem, map[string]interface{}{
+		"session_id": session.SessionID,
+		"target_id":  "nobody",
+		"item":       map[string]interface{}{"name": "Dagger", "type": "weapon"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+}
+
+func TestUndoLastAction_Teleport(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	world := server.state.WorldState
+	world.Width, world.Height = 20, 20
+	start := session.Player.GetPosition()
+	undo := map[string]interface{}{"session_id": session.SessionID}
+	teleport := map[string]interface{}{
+		"session_id": session.SessionID,
+		"position":   map[string]interface{}{"x": 3, "y": 4},
+	}
+
+	result, err := callAdmin(t, server.handleAdminTeleport, teleport)
+	require.NoError(t, err)
+	assert.Equal(t, start, result["from"])
+	assert.Equal(t, 3, session.Player.GetPosition().X)
+
+	_, err = callAdmin(t, server.handleAdminUndoLastAction, undo)
+	require.NoError(t, err)
+	assert.Equal(t, start, session.Player.GetPosition())
+
+	// A player who has moved on since is not pulled back
+	_, err = callAdmin(t, server.handleAdminTeleport, teleport)
+	require.NoError(t, err)
+	require.NoError(t, session.Player.SetPosition(game.Position{X: 5, Y: 4}))
+	_, err = callAdmin(t, server.handleAdminUndoLastAction, undo)
+	assert.ErrorIs(t, err, ErrUndoConflict)
+	assert.Equal(t, 5, session.Player.GetPosition().X)
+
+	_, err = callAdmin(t, server.handleAdminTeleport, map[string]interface{}{
+		"session_id": session.SessionID,
+		"position":   map[string]interface{}{"x": 50, "y": 4},
+	})
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code)
+}
+
+func TestUndoLastAction_ApplyEffect(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	orc := &game.Character{ID: "journal-orc", Name: "Orc", HP: 10, MaxHP: 10}
+	server.state.WorldState.Objects[orc.ID] = orc
+
+	result, err := callAdmin(t, server.handleApplyEffect, map[string]interface{}{
+		"session_id":  session.SessionID,
+		"target_id":   orc.ID,
+		"effect_type": "stun",
+		"magnitude":   1,
+		"duration":    map[string]interface{}{"Rounds": 3},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result["journal_id"])
+	require.Len(t, orc.GetEffects(), 1)
+	journalID := result["journal_id"]
+
+	result, err = callAdmin(t, server.handleAdminUndoLastAction, map[string]interface{}{"session_id": session.SessionID})
+	require.NoError(t, err)
+	assert.Equal(t, journalID, result["undone"].(*JournalEntry).ID)
+	assert.Empty(t, orc.GetEffects())
+}
+
+func TestHandleApplyEffect_Resolution(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	golem := &game.Character{ID: "resolution-golem", Name: "Golem", HP: 30, MaxHP: 30, Resistances: game.Resistances{
+		Effects: map[game.EffectType]float64{game.EffectStun: game.ResistanceImmune},
+		Damage:  map[game.DamageType]float64{game.DamageFire: 0.5},
+	}}
+	server.state.WorldState.Objects[golem.ID] = golem
+	apply := func(params map[string]interface{}) map[string]interface{} {
+		params["session_id"] = session.SessionID
+		params["target_id"] = golem.ID
+		params["duration"] = map[string]interface{}{"Rounds": 3}
+		result, err := callAdmin(t, server.handleApplyEffect, params)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := apply(map[string]interface{}{"effect_type": "stun", "magnitude": 1})
+	assert.Equal(t, false, result["success"])
+	assert.Equal(t, game.OutcomeImmune, result["outcome"])
+	assert.Nil(t, result["journal_id"], "nothing to undo")
+
+	result = apply(map[string]interface{}{"effect_type": "burning", "damage_type": "fire", "magnitude": 8, "stacking": "stack"})
+	assert.Equal(t, true, result["success"])
+	assert.Equal(t, game.OutcomeApplied, result["outcome"])
+	assert.Equal(t, 0.5, result["resistance"])
+	assert.Equal(t, 4.0, result["magnitude"])
+	assert.NotEmpty(t, result["journal_id"])
+	effectID := result["effect_id"]
+
+	result = apply(map[string]interface{}{"effect_type": "burning", "damage_type": "fire", "magnitude": 8, "stacking": "stack"})
+	assert.Equal(t, game.OutcomeStacked, result["outcome"])
+	assert.Equal(t, effectID, result["effect_id"])
+	assert.Equal(t, 2, result["stacks"])
+	assert.Nil(t, result["journal_id"], "undo would remove the first application too")
+}
+
+func TestGameMasterActions_RequireAdminToken(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	give := map[string]interface{}{
+		"session_id": session.SessionID,
+		"item":       map[string]interface{}{"name": "Long Sword", "type": "weapon"},
+	}
+	call := func(method RPCMethod, params map[string]interface{}) error {
+		data, err := json.Marshal(params)
+		require.NoError(t, err)
+		_, err = server.handleMethod(method, data)
+		return err
+	}
+
+	// A player session alone cannot reach the game master actions
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, call(MethodAdminGiveItem, give), &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code, "admin_token is required")
+	give["admin_token"] = "not-the-admin-token-0123456789abcdef"
+	assert.ErrorIs(t, call(MethodAdminGiveItem, give), ErrUnavailable, "the admin console is disabled")
+	server.admin = newTestAdminConsole(10, AdminPermissionGrant)
+	assert.ErrorIs(t, call(MethodAdminGiveItem, give), ErrAdminUnauthorized)
+	assert.Empty(t, session.Player.Inventory)
+
+	give["admin_token"] = testAdminToken
+	require.NoError(t, call(MethodAdminGiveItem, give))
+	require.Len(t, session.Player.Inventory, 1)
+
+	// Undoing needs a permission of its own
+	err := call(MethodAdminUndoLastAction, map[string]interface{}{"admin_token": testAdminToken, "session_id": session.SessionID})
+	assert.ErrorIs(t, err, ErrAdminForbidden)
+	assert.Len(t, session.Player.Inventory, 1)
+}
+
+func TestActionJournal_Bounded(t *testing.T) {
+	var journal actionJournal
+	for i := 0; i < ActionJournalSize+5; i++ {
+		journal.record("session", &JournalEntry{Description: fmt.Sprintf("action %d", i)})
+	}
+	assert.Equal(t, ActionJournalSize, journal.size("session"))
+
+	entry, ok := journal.pop("session")
+	require.True(t, ok)
+	assert.Equal(t, fmt.Sprintf("action %d", ActionJournalSize+4), entry.Description)
+
+	journal.forget("session")
+	_, ok = journal.pop("session")
+	assert.False(t, ok)
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newLevelUpService creates the level-up service, offering the spells of
+// spellManager when there is one.
+func newLevelUpService(spellManager *game.SpellManager) *game.LevelUpService {
+	if spellManager == nil {
+		return game.NewLevelUpService(nil, nil)
+	}
+	return game.NewLevelUpService(spellManager, nil)
+}
+
+// handleLevelUp trains the session's player for the next level they have
+// earned. With preview set it only describes the level and its choices;
+// otherwise it applies the level with the chosen hit point method and new
+// spells and returns what changed, along with a preview of the next
+// pending level if there is one.
+//
+// Parameters:
+//   - params: session_id, and optionally preview, hp_method ("roll" or
+//     "average") and spells
+//
+// Returns:
+//   - interface{}: The preview, or the level-up result
+//   - error: ErrLevelUpDenied if no level is pending or a choice is invalid
+func (s *RPCServer) handleLevelUp(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleLevelUp",
+	})
+	logger.Debug("entering handleLevelUp")
+
+	var req struct {
+		SessionID string   `json:"session_id"`
+		Preview   bool     `json:"preview"`
+		HPMethod  string   `json:"hp_method"`
+		Spells    []string `json:"spells"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid level up parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	player := session.Player
+
+	if req.Preview {
+		preview, err := s.levelUps.Preview(player)
+		if err != nil {
+			return nil, ErrLevelUpDenied.WithMessage("%v", err)
+		}
+		return map[string]interface{}{
+			"success": true,
+			"preview": preview,
+		}, nil
+	}
+
+	result, err := s.levelUps.LevelUp(player, game.LevelUpChoices{
+		HPMethod: game.HPMethod(req.HPMethod),
+		Spells:   req.Spells,
+	})
+	if err != nil {
+		logger.WithError(err).WithField("player_id", player.GetID()).Info("level up denied")
+		return nil, ErrLevelUpDenied.WithMessage("%v", err)
+	}
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:     game.EventLevelUp,
+		SourceID: player.GetID(),
+		Data: map[string]interface{}{
+			"oldLevel": result.FromLevel,
+			"newLevel": result.ToLevel,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+
+	logger.WithFields(logrus.Fields{
+		"player_id": player.GetID(),
+		"level":     result.ToLevel,
+		"hp_gained": result.HPGained,
+		"spells":    result.LearnedSpells,
+	}).Info("player trained for a new level")
+
+	response := map[string]interface{}{
+		"success": true,
+		"result":  result,
+	}
+	if result.Pending > 0 {
+		if next, err := s.levelUps.Preview(player); err == nil {
+			response["next"] = next
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleLevelUp(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	game.SetManualLevelUp(true)
+	t.Cleanup(func() { game.SetManualLevelUp(false) })
+
+	session := createTestSessionForHandlers(t, server)
+	player := session.Player
+	player.Level = 1
+	maxHP := player.MaxHP
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID})
+	_, err := server.handleLevelUp(params)
+	assert.True(t, errors.Is(err, ErrLevelUpDenied), "no level is pending yet")
+
+	require.NoError(t, player.AddExperience(4000))
+	assert.Equal(t, 1, player.Level)
+
+	params, _ = json.Marshal(map[string]interface{}{"session_id": session.SessionID, "preview": true})
+	result, err := server.handleLevelUp(params)
+	require.NoError(t, err)
+	preview := result.(map[string]interface{})["preview"].(*game.LevelUpPreview)
+	assert.Equal(t, 2, preview.ToLevel)
+	assert.Equal(t, 2, preview.Pending)
+	assert.Equal(t, 1, player.Level, "a preview changes nothing")
+
+	params, _ = json.Marshal(map[string]interface{}{"session_id": session.SessionID, "hp_method": "average"})
+	result, err = server.handleLevelUp(params)
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	levelUp := response["result"].(*game.LevelUpResult)
+	assert.Equal(t, 2, player.Level)
+	assert.Equal(t, maxHP+levelUp.HPGained, player.MaxHP)
+	assert.Equal(t, 3, response["next"].(*game.LevelUpPreview).ToLevel)
+}
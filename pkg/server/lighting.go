@@ -0,0 +1,158 @@
+package server
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Attack penalties for fighting where the attacker cannot see well. Each
+// point of penalty turns one attack in twenty into a miss.
+const (
+	DimAttackPenalty      = 2 // Target in dim light or seen by infravision
+	DarknessAttackPenalty = 4 // Target unseen in the dark
+)
+
+// LitLight is a light source burning in a character's hands. The
+// TimeManager tracks it until the game tick it burns out.
+type LitLight struct {
+	HolderID  string `yaml:"light_holder_id" json:"holder_id"`
+	ItemName  string `yaml:"light_item_name" json:"item_name"`
+	Radius    int    `yaml:"light_radius" json:"radius"`         // Tiles lit around the holder
+	ExpiresAt int64  `yaml:"light_expires_at" json:"expires_at"` // Game tick it burns out
+}
+
+// Kindle starts light burning, replacing the light its holder already
+// carries.
+func (t *TimeManager) Kindle(light LitLight) {
+	for i, lit := range t.Lights {
+		if lit.HolderID == light.HolderID {
+			t.Lights[i] = light
+			return
+		}
+	}
+	t.Lights = append(t.Lights, light)
+}
+
+// BurnOut removes and returns the lights that have burned out by the
+// current game time.
+func (t *TimeManager) BurnOut() []LitLight {
+	var burned []LitLight
+	burning := t.Lights[:0]
+	for _, light := range t.Lights {
+		if light.ExpiresAt <= t.CurrentTime.GameTicks {
+			burned = append(burned, light)
+		} else {
+			burning = append(burning, light)
+		}
+	}
+	t.Lights = burning
+	return burned
+}
+
+// attachLighting lights the world with the light sources the saved game
+// time left burning.
+func (s *RPCServer) attachLighting() {
+	s.syncLights()
+}
+
+// syncLights registers the lights burning in the TimeManager with the
+// world, so they light the tiles around their holders.
+func (s *RPCServer) syncLights() {
+	s.state.stateMu.Lock()
+	lights := make(map[string]int, len(s.state.TimeManager.Lights))
+	for _, light := range s.state.TimeManager.Lights {
+		lights[light.HolderID] = light.Radius
+	}
+	s.state.stateMu.Unlock()
+
+	s.state.WorldState.SetCarriedLights(lights)
+}
+
+// kindleLight lights a light source from player's inventory. Torches are
+// used up; lanterns stay in the inventory.
+func (s *RPCServer) kindleLight(player *game.Player, item *game.Item, source game.LightSource) string {
+	lit := *item // item points into the inventory, which using it up shifts
+	item = &lit
+
+	s.state.stateMu.Lock()
+	expiresAt := s.state.TimeManager.CurrentTime.GameTicks + source.BurnTicks
+	s.state.TimeManager.Kindle(LitLight{
+		HolderID:  player.GetID(),
+		ItemName:  item.Name,
+		Radius:    source.Radius,
+		ExpiresAt: expiresAt,
+	})
+	s.state.stateMu.Unlock()
+
+	if source.Consumed {
+		if _, err := player.RemoveItemFromInventory(item.ID); err != nil {
+			logrus.WithError(err).WithField("itemID", item.ID).Warn("failed to use up light source")
+		}
+	}
+	s.syncLights()
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "kindleLight",
+		"playerID":  player.GetID(),
+		"item":      item.Name,
+		"radius":    source.Radius,
+		"expiresAt": expiresAt,
+	}).Info("light source lit")
+	return fmt.Sprintf("Lit %s, lighting %d tiles around you for %d minutes", item.Name, source.Radius, source.BurnTicks/60)
+}
+
+// burnOutLights puts out the lights that have burned out by the current
+// game time and broadcasts EventLightBurnedOut for each.
+func (s *RPCServer) burnOutLights() []LitLight {
+	s.state.stateMu.Lock()
+	burned := s.state.TimeManager.BurnOut()
+	s.state.stateMu.Unlock()
+	if len(burned) == 0 {
+		return nil
+	}
+
+	s.syncLights()
+	for _, light := range burned {
+		logrus.WithFields(logrus.Fields{
+			"function": "burnOutLights",
+			"holderID": light.HolderID,
+			"item":     light.ItemName,
+		}).Info("light source burned out")
+
+		s.eventSys.Emit(game.GameEvent{
+			Type:      EventLightBurnedOut,
+			SourceID:  light.HolderID,
+			TargetID:  light.HolderID,
+			Data:      map[string]interface{}{"item_name": light.ItemName},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+	return burned
+}
+
+// darknessPenalty returns the attack penalty for attacker striking at
+// target: none in bright light, DimAttackPenalty when the attacker sees the
+// target only dimly or by infravision, and DarknessAttackPenalty when it
+// cannot see the target at all.
+func (s *RPCServer) darknessPenalty(attacker *game.Character, target game.GameObject) int {
+	world := s.state.WorldState
+	pos := target.GetPosition()
+	if world.LightAt(pos) == game.LightBright {
+		return 0
+	}
+	if world.CanSee(attacker, pos, game.DefaultSightRange) {
+		return DimAttackPenalty
+	}
+	return DarknessAttackPenalty
+}
+
+// missesInDarkness rolls whether an attack with the given darkness penalty
+// misses.
+func missesInDarkness(penalty int) bool {
+	return penalty > 0 && rand.Intn(20) < penalty
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// darkLevel returns a size by size level of dark floor
+func darkLevel(size int) game.Level {
+	level := game.Level{ID: "crypt", Width: size, Height: size, Tiles: make([][]game.Tile, size)}
+	for y := range level.Tiles {
+		level.Tiles[y] = make([]game.Tile, size)
+		for x := range level.Tiles[y] {
+			level.Tiles[y][x] = game.NewFloorTile()
+			level.Tiles[y][x].Light = game.LightDark
+		}
+	}
+	return level
+}
+
+func TestTimeManager_KindleAndBurnOut(t *testing.T) {
+	tm := NewTimeManager()
+	tm.Kindle(LitLight{HolderID: "a", ItemName: "Torch", Radius: 4, ExpiresAt: 100})
+	tm.Kindle(LitLight{HolderID: "b", ItemName: "Lantern", Radius: 6, ExpiresAt: 300})
+	tm.Kindle(LitLight{HolderID: "a", ItemName: "Torch", Radius: 4, ExpiresAt: 200})
+	require.Len(t, tm.Lights, 2, "lighting a new source replaces the holder's old one")
+
+	tm.Skip(150)
+	assert.Empty(t, tm.BurnOut(), "the relit torch burns until tick 200")
+	tm.Skip(100)
+	burned := tm.BurnOut()
+	require.Len(t, burned, 1)
+	assert.Equal(t, "a", burned[0].HolderID)
+
+	data, err := yaml.Marshal(tm)
+	require.NoError(t, err)
+	var restored TimeManager
+	require.NoError(t, yaml.Unmarshal(data, &restored))
+	assert.Equal(t, tm.Lights, restored.Lights, "burning lights are saved with game time")
+}
+
+func TestKindleLight_BurnsOut(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	player := session.Player
+	player.Inventory = []game.Item{{ID: "torch", Name: "Torch"}, {ID: "lantern", Name: "Lantern"}}
+
+	burnedOut := make(chan game.GameEvent, 1)
+	server.eventSys.Subscribe(EventLightBurnedOut, func(event game.GameEvent) { burnedOut <- event })
+
+	effect, err := server.executeItemUsage(player, "torch", "")
+	require.NoError(t, err)
+	assert.Contains(t, effect, "Lit Torch")
+	assert.Len(t, player.Inventory, 1, "lighting a torch uses it up")
+	assert.Equal(t, 4, server.state.WorldState.CarriedLights()[player.GetID()])
+
+	_, err = server.executeItemUsage(player, "lantern", "")
+	require.NoError(t, err)
+	assert.Len(t, player.Inventory, 1, "lanterns are kept")
+	assert.Equal(t, 6, server.state.WorldState.CarriedLights()[player.GetID()])
+
+	server.state.TimeManager.Skip(4 * TicksPerHour)
+	burned := server.burnOutLights()
+	require.Len(t, burned, 1)
+	assert.Empty(t, server.state.WorldState.CarriedLights())
+	select {
+	case event := <-burnedOut:
+		assert.Equal(t, player.GetID(), event.SourceID)
+		assert.Equal(t, "Lantern", event.Data["item_name"])
+	case <-time.After(time.Second):
+		t.Fatal("no light burned out event")
+	}
+}
+
+func TestDarknessPenalty(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	player := session.Player
+	server.state.WorldState.Levels = []game.Level{darkLevel(20)}
+	player.Position = game.Position{X: 5, Y: 5}
+	target := &game.NPC{Character: game.Character{ID: "dark-ghoul", HP: 10, Position: game.Position{X: 7, Y: 5}}}
+	server.state.WorldState.Objects[target.ID] = target
+
+	assert.Equal(t, DarknessAttackPenalty, server.darknessPenalty(&player.Character, target))
+
+	player.AddTag("race:dwarf")
+	assert.Equal(t, DimAttackPenalty, server.darknessPenalty(&player.Character, target), "infravision sees the target, but poorly")
+
+	server.state.WorldState.Objects[player.GetID()] = player
+	server.state.TimeManager.Kindle(LitLight{HolderID: player.GetID(), ItemName: "Torch", Radius: 4, ExpiresAt: TicksPerHour})
+	server.syncLights()
+	assert.Zero(t, server.darknessPenalty(&player.Character, target), "torchlight removes the penalty")
+
+	assert.False(t, missesInDarkness(0))
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"goldbox-rpg/pkg/lockstat"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// lockCollector exports the lock wait time histograms recorded by package
+// lockstat. They are read at scrape time, so every lock acquired since the
+// process started is included.
+type lockCollector struct {
+	waits *prometheus.Desc
+}
+
+// newLockCollector creates a collector for the lockstat wait times
+func newLockCollector() *lockCollector {
+	return &lockCollector{
+		waits: prometheus.NewDesc(
+			"goldbox_lock_wait_seconds",
+			"Time spent waiting to acquire instrumented locks by lock and mode (read or write)",
+			[]string{"lock", "mode"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *lockCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.waits
+}
+
+// Collect implements prometheus.Collector
+func (c *lockCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range lockstat.Snapshot() {
+		buckets := make(map[float64]uint64, len(lockstat.Buckets))
+		for i, bound := range lockstat.Buckets {
+			buckets[bound.Seconds()] = stats.Buckets[i]
+		}
+		ch <- prometheus.MustNewConstHistogram(c.waits, stats.Count, stats.Total.Seconds(), buckets, stats.Lock, stats.Mode)
+	}
+}
+
+// RegisterLockMetrics exports the lock wait time histograms on the metrics
+// endpoint. Wait times are only recorded in builds with the lockprofile
+// tag; other builds register nothing.
+func (m *Metrics) RegisterLockMetrics() error {
+	if !lockstat.Enabled {
+		return nil
+	}
+	return m.registry.Register(newLockCollector())
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"testing"
+
+	"goldbox-rpg/pkg/lockstat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_RegisterLockMetrics(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	metrics := NewMetrics()
+	require.NoError(t, metrics.RegisterLockMetrics())
+
+	server.state.GetState()
+	body := scrapeMetrics(t, metrics)
+
+	if !lockstat.Enabled {
+		assert.NotContains(t, body, "goldbox_lock_wait_seconds", "lock waits are only exported with the lockprofile tag")
+		return
+	}
+	assert.Contains(t, body, `goldbox_lock_wait_seconds_count{lock="state.sessions",mode="read"}`)
+	assert.Contains(t, body, `goldbox_lock_wait_seconds_bucket{lock="world",mode="read",le="1e-06"}`)
+}
+
+func TestGameState_GetStateCopiesWorldObjects(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	server.state.Sessions[session.SessionID] = session
+	server.state.Version++
+
+	state := server.state.GetState()
+	objects := state["world"].(map[string]interface{})["objects"]
+	require.NotNil(t, objects)
+	require.NoError(t, server.state.WorldState.RemoveObject("test-player-001"))
+	assert.Contains(t, objects, "test-player-001", "the serialized state does not change with the world")
+	assert.Contains(t, state["sessions"], "test-session-001")
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain points the data directory of every server built by the tests at
+// a temporary directory, so saved state, combat logs and event logs never
+// land in the package directory.
+func TestMain(m *testing.M) {
+	dataDir, err := os.MkdirTemp("", "goldbox-server-test-*")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create test data directory:", err)
+		os.Exit(1)
+	}
+	os.Setenv("DATA_DIR", dataDir)
+
+	code := m.Run()
+	os.RemoveAll(dataDir)
+	os.Exit(code)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/levels"
+
+	"github.com/sirupsen/logrus"
+)
+
+// handleExportMap exports a level of the world in the JSON map format of
+// the Tiled map editor, so generated content can be inspected or edited in
+// standard map editors. The level's map annotations visible to players are
+// exported as the "annotations" object group; the game master, passing the
+// admin token, also gets the GM-only ones.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The requesting session
+//   - admin_token: string - The admin token, to include GM-only
+//     annotations (optional)
+//   - level: int - Level index (optional, defaults to the player's level)
+//   - tile_width, tile_height: int - Tile size in pixels (optional, 32)
+//   - tileset_image: string - Tileset image path written into the map
+//     (optional, "tiles.png")
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - level: int - The exported level index
+//   - format: string - Always "tiled-json"
+//   - map: The Tiled map, with tile layer, tileset and object groups
+//   - error: Invalid parameters or session, an unauthorized token or an
+//     unknown level
+func (s *RPCServer) handleExportMap(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleExportMap",
+	})
+	logger.Debug("entering handleExportMap")
+
+	var req struct {
+		SessionID  string `json:"session_id"`
+		AdminToken string `json:"admin_token"`
+		Level      *int   `json:"level,omitempty"`
+		pcg.TiledOptions
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid export map parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	gm := req.AdminToken != ""
+	if gm {
+		if err := s.authorizeGM(MethodExportMap, req.AdminToken); err != nil {
+			return nil, err
+		}
+	}
+
+	level := session.Player.GetPosition().Level
+	if req.Level != nil {
+		level = *req.Level
+	}
+
+	snapshot, objects, err := s.state.WorldState.LevelSnapshot(level)
+	if err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Unknown level", err.Error())
+	}
+
+	tiled, err := levels.ExportTiled(s.annotatedLevel(snapshot, level, gm), level, objects, req.TiledOptions)
+	if err != nil {
+		logger.WithError(err).Error("failed to export level")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to export map", err.Error())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"level":  level,
+		"layers": len(tiled.Layers),
+	}).Info("exported map")
+
+	return map[string]interface{}{
+		"success": true,
+		"level":   level,
+		"format":  "tiled-json",
+		"map":     tiled,
+	}, nil
+}
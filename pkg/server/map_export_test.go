@@ -0,0 +1,45 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleExportMap(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	world := server.state.WorldState
+	require.NoError(t, world.SetTile(0, 1, 1, game.Tile{Type: game.TileDoor, Walkable: true}))
+	require.NoError(t, world.AddObject(&game.Trap{ID: "export-trap", Name: "Pit", Position: game.Position{X: 2, Y: 2}}))
+
+	params, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "tile_width": 16})
+	require.NoError(t, err)
+	result, err := server.handleExportMap(params)
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	assert.Equal(t, "tiled-json", response["format"])
+	tiled := response["map"].(*pcg.TiledMap)
+	assert.Equal(t, world.Levels[0].Width, tiled.Width)
+	assert.Equal(t, 16, tiled.TileWidth)
+
+	groups := make(map[string]int)
+	for _, layer := range tiled.Layers {
+		groups[layer.Name] = len(layer.Objects)
+	}
+	assert.Equal(t, 1, groups["doors"])
+	assert.Equal(t, 1, groups["traps"])
+
+	params, err = json.Marshal(map[string]interface{}{"session_id": session.SessionID, "level": 9})
+	require.NoError(t, err)
+	_, err = server.handleExportMap(params)
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code)
+}
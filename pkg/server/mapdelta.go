@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+)
+
+// handleGetMapDelta returns the tiles and objects of a level that changed
+// since the client's last revision, so clients can keep their map current
+// without refetching the whole world state.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The requesting session
+//   - level: int - Level index (optional, defaults to the player's level)
+//   - since_revision: uint64 - Revision the client last saw; 0 requests
+//     the whole level
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - level, revision, since_revision: The level and its current revision
+//   - full_resync: bool set when tiles and objects are the whole level
+//   - tiles: Changed tiles with their x, y coordinates
+//   - objects: Objects added to or changed on the level
+//   - removed: IDs of objects that left the level
+//   - error: Invalid parameters or session, or an unknown level
+func (s *RPCServer) handleGetMapDelta(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleGetMapDelta",
+	})
+	logger.Debug("entering handleGetMapDelta")
+
+	var req struct {
+		SessionID     string `json:"session_id"`
+		Level         *int   `json:"level,omitempty"`
+		SinceRevision uint64 `json:"since_revision"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid map delta parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	level := session.Player.GetPosition().Level
+	if req.Level != nil {
+		level = *req.Level
+	}
+
+	delta, err := s.state.WorldState.MapDelta(level, req.SinceRevision)
+	if err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Unknown level", err.Error())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"level":       level,
+		"since":       req.SinceRevision,
+		"revision":    delta.Revision,
+		"full_resync": delta.FullResync,
+		"tiles":       len(delta.Tiles),
+		"objects":     len(delta.Objects),
+		"removed":     len(delta.Removed),
+	}).Debug("map delta computed")
+
+	return map[string]interface{}{
+		"success":        true,
+		"level":          delta.Level,
+		"revision":       delta.Revision,
+		"since_revision": delta.Since,
+		"full_resync":    delta.FullResync,
+		"tiles":          delta.Tiles,
+		"objects":        delta.Objects,
+		"removed":        delta.Removed,
+	}, nil
+}
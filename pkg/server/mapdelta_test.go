@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getMapDelta calls handleGetMapDelta with params.
+func getMapDelta(t *testing.T, server *RPCServer, params map[string]interface{}) (map[string]interface{}, error) {
+	t.Helper()
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+	result, err := server.handleGetMapDelta(data)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]interface{}), nil
+}
+
+func TestHandleGetMapDelta(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	world := server.state.WorldState
+
+	full, err := getMapDelta(t, server, map[string]interface{}{"session_id": session.SessionID})
+	require.NoError(t, err)
+	assert.True(t, full["full_resync"].(bool))
+	assert.Len(t, full["tiles"], world.Levels[0].Width*world.Levels[0].Height)
+	revision := full["revision"].(uint64)
+
+	require.NoError(t, world.SetTile(0, 1, 1, game.Tile{Type: game.TileDoor, Walkable: true}))
+	require.NoError(t, world.AddObject(&game.Character{ID: "map-delta-orc", Position: game.Position{X: 2, Y: 2}}))
+
+	delta, err := getMapDelta(t, server, map[string]interface{}{
+		"session_id":     session.SessionID,
+		"since_revision": revision,
+	})
+	require.NoError(t, err)
+	assert.False(t, delta["full_resync"].(bool))
+	assert.Equal(t, revision+2, delta["revision"])
+	assert.Equal(t, []game.TileChange{{X: 1, Y: 1, Tile: game.Tile{Type: game.TileDoor, Walkable: true}}}, delta["tiles"])
+	objects := delta["objects"].([]game.GameObject)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "map-delta-orc", objects[0].GetID())
+
+	_, err = getMapDelta(t, server, map[string]interface{}{"session_id": session.SessionID, "level": 7})
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code)
+
+	_, err = getMapDelta(t, server, map[string]interface{}{"session_id": "missing"})
+	assert.ErrorIs(t, err, ErrInvalidSession)
+}
@@ -0,0 +1,359 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/items"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Shop stocking parameters. Each restock round draws one item set of every
+// merchantStockSets type from the item generator.
+const (
+	merchantBaseGold          = 200
+	merchantGoldPerDifficulty = 50
+	merchantBaseStockRounds   = 2
+)
+
+// MerchantRestockDays is how often merchants restock, in game days.
+const MerchantRestockDays = 7
+
+// merchantStockSets lists the item sets merchants carry
+var merchantStockSets = []pcg.ItemSetType{pcg.ItemSetWeapons, pcg.ItemSetArmor, pcg.ItemSetConsumab}
+
+// MerchantManager tracks the merchants living in the shop rooms of levels
+// added to the world.
+//
+// Thread Safety: All methods are safe for concurrent use. Trades lock the
+// merchant itself, see game.Merchant.
+type MerchantManager struct {
+	mu           sync.RWMutex
+	merchants    map[string]*game.Merchant
+	openings     map[string]merchantOpening // Stock and gold each merchant restocks to
+	marketFactor float64                    // Applied to every merchant, see game.Merchant.MarketFactor
+}
+
+// merchantOpening is what a merchant held when it was added
+type merchantOpening struct {
+	stock []game.Item
+	gold  int
+}
+
+// NewMerchantManager creates an empty merchant manager
+func NewMerchantManager() *MerchantManager {
+	return &MerchantManager{
+		merchants: make(map[string]*game.Merchant),
+		openings:  make(map[string]merchantOpening),
+	}
+}
+
+// Add registers a merchant. Restock returns it to the stock and gold it
+// holds now.
+func (mm *MerchantManager) Add(merchant *game.Merchant) error {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	if _, exists := mm.merchants[merchant.ID]; exists {
+		return fmt.Errorf("merchant %s already exists", merchant.ID)
+	}
+	merchant.SetMarketFactor(mm.marketFactor)
+	mm.merchants[merchant.ID] = merchant
+	mm.openings[merchant.ID] = merchantOpening{stock: merchant.GetStock(), gold: merchant.GetGold()}
+	return nil
+}
+
+// Restock puts back the items every merchant has sold since it was added
+// and tops up its gold, see game.Merchant.Restock. It returns the number
+// of items put back.
+func (mm *MerchantManager) Restock() int {
+	mm.mu.RLock()
+	defer mm.mu.RUnlock()
+
+	restocked := 0
+	for id, merchant := range mm.merchants {
+		opening := mm.openings[id]
+		restocked += merchant.Restock(opening.stock, opening.gold)
+	}
+	return restocked
+}
+
+// SetMarketFactor sets the price multiplier of every merchant, including
+// those added later.
+func (mm *MerchantManager) SetMarketFactor(factor float64) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	mm.marketFactor = factor
+	for _, merchant := range mm.merchants {
+		merchant.SetMarketFactor(factor)
+	}
+}
+
+// Get returns the merchant with the given ID
+func (mm *MerchantManager) Get(merchantID string) (*game.Merchant, bool) {
+	mm.mu.RLock()
+	defer mm.mu.RUnlock()
+
+	merchant, exists := mm.merchants[merchantID]
+	return merchant, exists
+}
+
+// StockLevel creates a merchant for every shop room of a level, see
+// pcg.ShopSite. Stock comes from the item generator seeded with the shop's
+// seed, so the same level always opens with the same wares. levelIndex is
+// the level's index in the world, used for the merchants' positions.
+func (mm *MerchantManager) StockLevel(ctx context.Context, level *game.Level, levelIndex int) ([]*game.Merchant, error) {
+	sites, _ := level.Properties["shops"].([]pcg.ShopSite)
+
+	merchants := make([]*game.Merchant, 0, len(sites))
+	for _, site := range sites {
+		merchant, err := newShopMerchant(ctx, level.ID, levelIndex, site)
+		if err != nil {
+			return merchants, err
+		}
+		if err := mm.Add(merchant); err != nil {
+			return merchants, err
+		}
+		merchants = append(merchants, merchant)
+	}
+	return merchants, nil
+}
+
+// newShopMerchant creates the merchant for a shop site
+func newShopMerchant(ctx context.Context, levelID string, levelIndex int, site pcg.ShopSite) (*game.Merchant, error) {
+	merchantID := fmt.Sprintf("%s_%s_merchant", levelID, site.RoomID)
+	difficulty := max(site.Difficulty, 1)
+
+	generator := items.NewTemplateBasedGenerator()
+	generator.SetSeed(site.Seed)
+	params := pcg.ItemParams{
+		GenerationParams: pcg.GenerationParams{
+			Seed:        site.Seed,
+			Difficulty:  difficulty,
+			PlayerLevel: difficulty,
+		},
+		MinRarity:       pcg.RarityCommon,
+		MaxRarity:       pcg.RarityUncommon,
+		EnchantmentRate: 0.2,
+		LevelScaling:    true,
+	}
+	if difficulty >= 10 {
+		params.MaxRarity = pcg.RarityRare
+	}
+
+	var stock []game.Item
+	rounds := merchantBaseStockRounds + difficulty/5
+	for round := 0; round < rounds; round++ {
+		for _, setType := range merchantStockSets {
+			set, err := generator.GenerateItemSet(ctx, setType, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stock merchant %s: %w", merchantID, err)
+			}
+			for _, item := range set {
+				// Generated IDs are random; derive them from the shop instead
+				item.ID = fmt.Sprintf("%s_stock_%d", merchantID, len(stock))
+				stock = append(stock, *item)
+			}
+		}
+	}
+
+	pos := site.Position
+	pos.Level = levelIndex
+	gold := merchantBaseGold + difficulty*merchantGoldPerDifficulty
+
+	return game.NewMerchant(merchantID, "Merchant", site.Faction, pos, gold, stock), nil
+}
+
+// stockLevelMerchants opens the shops of a level that has just been added to
+// the world and places their merchants in it. Failures are logged; the level
+// stays in the world without the merchants that could not be placed.
+func (s *RPCServer) stockLevelMerchants(level *game.Level) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "stockLevelMerchants",
+		"level_id": level.ID,
+	})
+
+	world := s.state.WorldState
+	merchants, err := s.merchants.StockLevel(context.Background(), level, worldLevelIndex(world, level.ID))
+	if err != nil {
+		logger.WithError(err).Warn("failed to stock shop merchants")
+	}
+	for _, merchant := range merchants {
+		if err := world.AddObject(merchant); err != nil {
+			logger.WithError(err).WithField("merchant_id", merchant.ID).Warn("failed to place merchant")
+		}
+	}
+	if len(merchants) > 0 {
+		logger.WithField("merchants", len(merchants)).Info("shop merchants stocked")
+	}
+}
+
+// merchantTradeRequest holds the parameters of buyItem and sellItem
+type merchantTradeRequest struct {
+	SessionID  string `json:"session_id"`
+	MerchantID string `json:"merchant_id"`
+	ItemID     string `json:"item_id"`
+}
+
+// sessionMerchant resolves the session and merchant of a merchant request
+func (s *RPCServer) sessionMerchant(sessionID, merchantID string) (*PlayerSession, *game.Merchant, error) {
+	session, err := s.getPlayerSession(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.Player == nil {
+		return nil, nil, ErrNoPlayer
+	}
+
+	merchant, exists := s.merchants.Get(merchantID)
+	if !exists {
+		return nil, nil, ErrMerchantNotFound.WithData(map[string]interface{}{"merchant_id": merchantID})
+	}
+	return session, merchant, nil
+}
+
+// handleGetMerchant returns a merchant's stock priced for the requesting
+// player, and what the merchant would pay for each item in the player's
+// inventory.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - merchant_id: string - The merchant to browse
+//
+// Returns:
+//   - interface{}: Map containing the merchant's gold, stock and offers
+//   - error: Invalid parameters, session or merchant
+func (s *RPCServer) handleGetMerchant(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID  string `json:"session_id"`
+		MerchantID string `json:"merchant_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid merchant parameters", err.Error())
+	}
+
+	session, merchant, err := s.sessionMerchant(req.SessionID, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	player := session.Player
+
+	stock := make([]map[string]interface{}, 0)
+	for _, item := range merchant.GetStock() {
+		stock = append(stock, map[string]interface{}{
+			"item":  item,
+			"price": merchant.BuyPrice(item, player),
+		})
+	}
+	offers := make([]map[string]interface{}, 0)
+	for _, item := range player.GetInventory() {
+		offers = append(offers, map[string]interface{}{
+			"item_id": item.ID,
+			"price":   merchant.SellPrice(item, player),
+		})
+	}
+
+	return map[string]interface{}{
+		"success":          true,
+		"merchant_id":      merchant.ID,
+		"name":             merchant.GetName(),
+		"faction":          merchant.Faction,
+		"gold":             merchant.GetGold(),
+		"price_adjustment": merchant.PriceAdjustment(player),
+		"stock":            stock,
+		"offers":           offers,
+	}, nil
+}
+
+// handleBuyItem buys an item from a merchant for the requesting player. Gold
+// and the item change hands together or not at all.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the buying player
+//   - merchant_id: string - The merchant selling the item
+//   - item_id: string - The stocked item to buy
+//
+// Returns:
+//   - interface{}: Map containing the completed trade
+//   - error: Invalid parameters, session or merchant, or a trade the player
+//     cannot afford or carry
+func (s *RPCServer) handleBuyItem(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleBuyItem",
+	})
+	logger.Debug("entering handleBuyItem")
+
+	var req merchantTradeRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid trade parameters", err.Error())
+	}
+
+	session, merchant, err := s.sessionMerchant(req.SessionID, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := merchant.SellToPlayer(session.Player, req.ItemID)
+	if err != nil {
+		logger.WithError(err).Warn("purchase failed")
+		return nil, ErrTradeRejected.WithMessage("failed to buy item: %v", err).WithData(map[string]interface{}{"item_id": req.ItemID})
+	}
+	s.recordRelationship(merchant.ID, session.Player.GetID(), game.RelationshipTraded)
+
+	return map[string]interface{}{
+		"success": true,
+		"trade":   trade,
+	}, nil
+}
+
+// handleSellItem sells an item from the requesting player's inventory to a
+// merchant. Gold and the item change hands together or not at all.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the selling player
+//   - merchant_id: string - The merchant buying the item
+//   - item_id: string - The inventory item to sell
+//
+// Returns:
+//   - interface{}: Map containing the completed trade
+//   - error: Invalid parameters, session or merchant, an item the player
+//     does not carry, or a merchant without the gold to pay
+func (s *RPCServer) handleSellItem(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleSellItem",
+	})
+	logger.Debug("entering handleSellItem")
+
+	var req merchantTradeRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid trade parameters", err.Error())
+	}
+
+	session, merchant, err := s.sessionMerchant(req.SessionID, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := merchant.BuyFromPlayer(session.Player, req.ItemID)
+	if err != nil {
+		logger.WithError(err).Warn("sale failed")
+		return nil, ErrTradeRejected.WithMessage("failed to sell item: %v", err).WithData(map[string]interface{}{"item_id": req.ItemID})
+	}
+	s.recordRelationship(merchant.ID, session.Player.GetID(), game.RelationshipTraded)
+
+	return map[string]interface{}{
+		"success": true,
+		"trade":   trade,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/levels"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateShopLevel generates a level made of shop rooms
+func generateShopLevel(t *testing.T) *game.Level {
+	t.Helper()
+	level, err := levels.NewRoomCorridorGeneratorWithSeed(1).GenerateLevel(context.Background(), pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 1357, Difficulty: 3},
+		MinRooms:         3,
+		MaxRooms:         3,
+		RoomTypes:        []pcg.RoomType{pcg.RoomTypeShop},
+		LevelTheme:       pcg.ThemeClassic,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, level.Properties["shops"])
+	return level
+}
+
+func TestMerchantManager_StockLevel(t *testing.T) {
+	level := generateShopLevel(t)
+
+	merchants, err := NewMerchantManager().StockLevel(context.Background(), level, 2)
+	require.NoError(t, err)
+	require.Len(t, merchants, len(level.Properties["shops"].([]pcg.ShopSite)))
+
+	merchant := merchants[0]
+	assert.Equal(t, levels.ShopMerchantFaction, merchant.Faction)
+	assert.Equal(t, 2, merchant.GetPosition().Level)
+	assert.Equal(t, merchantBaseGold+3*merchantGoldPerDifficulty, merchant.GetGold())
+	assert.NotEmpty(t, merchant.GetStock())
+
+	again, err := NewMerchantManager().StockLevel(context.Background(), level, 2)
+	require.NoError(t, err)
+	assert.Equal(t, merchant.GetStock(), again[0].GetStock(), "the same shop opens with the same stock")
+
+	manager := NewMerchantManager()
+	_, err = manager.StockLevel(context.Background(), level, 2)
+	require.NoError(t, err)
+	_, err = manager.StockLevel(context.Background(), level, 2)
+	assert.Error(t, err, "a level's merchants are only stocked once")
+}
+
+func TestCommitGeneratedContent_StocksShopMerchants(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	level := generateShopLevel(t)
+
+	require.NoError(t, server.integratePreview(session, &contentPreview{
+		ContentType: pcg.ContentTypeLevels,
+		LocationID:  level.ID,
+		Content:     level,
+	}))
+
+	site := level.Properties["shops"].([]pcg.ShopSite)[0]
+	merchantID := level.ID + "_" + site.RoomID + "_merchant"
+	merchant, exists := server.merchants.Get(merchantID)
+	require.True(t, exists)
+	assert.Contains(t, server.state.WorldState.Objects, merchantID, "merchants live in the world")
+	assert.Equal(t, len(server.state.WorldState.Levels)-1, merchant.GetPosition().Level)
+}
+
+func TestHandleMerchantTrades(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Gold = 100
+	session.Player.Inventory = []game.Item{{ID: "gem", Name: "Gem", Value: 40}}
+
+	merchant := game.NewMerchant("shop_merchant", "Merchant", "merchants_guild", game.Position{}, 30,
+		[]game.Item{{ID: "sword", Name: "Sword", Value: 60, Weight: 5}, {ID: "crown", Name: "Crown", Value: 500}})
+	require.NoError(t, server.merchants.Add(merchant))
+
+	t.Run("browse", func(t *testing.T) {
+		result, err := server.handleGetMerchant(json.RawMessage(`{"session_id":"test-session-001","merchant_id":"shop_merchant"}`))
+		require.NoError(t, err)
+
+		resultMap := result.(map[string]interface{})
+		assert.Equal(t, 30, resultMap["gold"])
+		stock := resultMap["stock"].([]map[string]interface{})
+		require.Len(t, stock, 2)
+		assert.Equal(t, 60, stock[0]["price"])
+		offers := resultMap["offers"].([]map[string]interface{})
+		require.Len(t, offers, 1)
+		assert.Equal(t, 20, offers[0]["price"])
+	})
+
+	t.Run("buy", func(t *testing.T) {
+		result, err := server.handleBuyItem(json.RawMessage(`{"session_id":"test-session-001","merchant_id":"shop_merchant","item_id":"sword"}`))
+		require.NoError(t, err)
+
+		trade := result.(map[string]interface{})["trade"].(*game.Trade)
+		assert.Equal(t, 60, trade.Price)
+		assert.Equal(t, 40, session.Player.Gold)
+		assert.Equal(t, 90, merchant.GetGold())
+
+		_, err = server.handleBuyItem(json.RawMessage(`{"session_id":"test-session-001","merchant_id":"shop_merchant","item_id":"crown"}`))
+		assert.Error(t, err, "the player cannot afford the crown")
+		assert.Equal(t, 40, session.Player.Gold)
+	})
+
+	t.Run("sell", func(t *testing.T) {
+		result, err := server.handleSellItem(json.RawMessage(`{"session_id":"test-session-001","merchant_id":"shop_merchant","item_id":"gem"}`))
+		require.NoError(t, err)
+
+		trade := result.(map[string]interface{})["trade"].(*game.Trade)
+		assert.Equal(t, 20, trade.Price)
+		assert.Equal(t, 60, session.Player.Gold)
+		assert.Equal(t, 70, merchant.GetGold())
+
+		_, err = server.handleSellItem(json.RawMessage(`{"session_id":"test-session-001","merchant_id":"shop_merchant","item_id":"gem"}`))
+		assert.Error(t, err, "the gem has already been sold")
+	})
+
+	t.Run("unknown merchant", func(t *testing.T) {
+		_, err := server.handleBuyItem(json.RawMessage(`{"session_id":"test-session-001","merchant_id":"missing","item_id":"sword"}`))
+		assert.Error(t, err)
+	})
+}
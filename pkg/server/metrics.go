@@ -29,6 +29,9 @@ type Metrics struct {
 	playerActions  *prometheus.CounterVec
 	gameEvents     *prometheus.CounterVec
 
+	// Procedural content generation metrics
+	pcgGenerationDuration *prometheus.HistogramVec
+
 	// System metrics
 	serverStartTime prometheus.Gauge
 	healthChecks    *prometheus.CounterVec
@@ -131,6 +134,15 @@ func NewMetrics() *Metrics {
 			[]string{"event_type"},
 		),
 
+		pcgGenerationDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "goldbox_pcg_generation_duration_seconds",
+				Help:    "PCG generation duration in seconds by content type and status",
+				Buckets: prometheus.ExponentialBuckets(0.001, 4, 8), // 1ms to ~16s
+			},
+			[]string{"content_type", "status"}, // status: "success", "failure"
+		),
+
 		serverStartTime: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "goldbox_server_start_time_seconds",
@@ -205,6 +217,7 @@ func NewMetrics() *Metrics {
 		m.activeSessions,
 		m.playerActions,
 		m.gameEvents,
+		m.pcgGenerationDuration,
 		m.serverStartTime,
 		m.healthChecks,
 		m.memoryUsage,
@@ -5,10 +5,19 @@ import (
 	"net"
 	"net/http"
 
+	"goldbox-rpg/pkg/logging"
+
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// Subsystem loggers for HTTP transport and RPC dispatch. Their levels can be
+// set separately through the LOG_LEVELS configuration.
+var (
+	httpLog = logging.For("http")
+	rpcLog  = logging.For("rpc")
+)
+
 // RequestIDMiddleware adds request correlation IDs to all HTTP requests
 // If a request already has an X-Request-ID header, it uses that value.
 // Otherwise, it generates a new UUID for the request.
@@ -24,12 +33,14 @@ func RequestIDMiddleware(next http.Handler) http.Handler {
 		// Add request ID to response headers for tracing
 		w.Header().Set("X-Request-ID", requestID)
 
-		// Add request ID to context using the existing context key
+		// Add request ID to context using the existing context key, and use
+		// it as the trace ID of every log line written for the request
 		ctx := context.WithValue(r.Context(), requestIDKey, requestID)
+		ctx = logging.ContextWithFields(ctx, logrus.Fields{logging.FieldTraceID: requestID})
 		r = r.WithContext(ctx)
 
 		// Add request ID to all log entries for this request
-		logger := logrus.WithField("request_id", requestID)
+		logger := httpLog.WithContext(ctx).WithField("request_id", requestID)
 
 		// Store logger in context for use by handlers
 		ctx = context.WithValue(ctx, "logger", logger)
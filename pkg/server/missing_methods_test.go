@@ -260,6 +260,7 @@ func createTestServer() *RPCServer {
 			},
 			TurnManager: NewTurnManager(),
 		},
+		eventSys:  game.NewEventSystem(),
 		validator: validation.NewInputValidator(1024),
 	}
 }
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMsgpack_RoundTrip(t *testing.T) {
+	values := map[string]interface{}{
+		"nil":      nil,
+		"bools":    []interface{}{true, false},
+		"ints":     []interface{}{0, 1, 127, 128, 255, 256, 65535, 65536, math.MaxUint32, math.MaxUint32 + 1, math.MaxInt64},
+		"negative": []interface{}{-1, -32, -33, -128, -129, -32768, -32769, math.MinInt32, math.MinInt32 - 1, math.MinInt64},
+		"large":    uint64(math.MaxUint64),
+		"floats":   []interface{}{0.5, -1.25, 1e300},
+		"strings":  []interface{}{"", strings.Repeat("a", 31), strings.Repeat("b", 32), strings.Repeat("c", 300), strings.Repeat("d", 70000)},
+		"arrays":   []interface{}{make([]interface{}, 15), make([]interface{}, 16), make([]interface{}, 70000)},
+		"nested":   map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"c", 1}}},
+		"struct": struct {
+			Name string `json:"name"`
+			Skip string `json:"-"`
+		}{Name: "Rowan", Skip: "hidden"},
+	}
+
+	data, err := marshalMsgpack(values)
+	require.NoError(t, err)
+
+	var decoded interface{}
+	require.NoError(t, unmarshalMsgpack(data, &decoded))
+
+	want, err := json.Marshal(values)
+	require.NoError(t, err)
+	got, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got))
+}
+
+func TestMarshalMsgpack_CompactForms(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{7, []byte{0x07}},
+		{-3, []byte{0xfd}},
+		{200, []byte{0xcc, 0xc8}},
+		{-100, []byte{0xd0, 0x9c}},
+		{1000, []byte{0xcd, 0x03, 0xe8}},
+		{"hi", []byte{0xa2, 'h', 'i'}},
+		{[]int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+	}
+	for _, tt := range tests {
+		got, err := marshalMsgpack(tt.value)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%v", tt.value)
+	}
+}
+
+func TestUnmarshalMsgpack_RPCRequest(t *testing.T) {
+	data, err := marshalMsgpack(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "move",
+		"params":  map[string]interface{}{"direction": "north"},
+		"id":      7,
+	})
+	require.NoError(t, err)
+
+	var req RPCRequest
+	require.NoError(t, unmarshalMsgpack(data, &req))
+	assert.Equal(t, "2.0", req.JSONRPC)
+	assert.Equal(t, "move", req.Method)
+	assert.Equal(t, "north", req.Params["direction"])
+	assert.Equal(t, float64(7), req.ID)
+}
+
+func TestUnmarshalMsgpack_RejectsMalformed(t *testing.T) {
+	deep := []byte{}
+	for i := 0; i <= maxMsgpackDepth+1; i++ {
+		deep = append(deep, 0x91)
+	}
+	deep = append(deep, 0xc0)
+
+	tests := map[string][]byte{
+		"empty":             {},
+		"truncated string":  {0xa5, 'a', 'b'},
+		"truncated int":     {0xcd, 0x01},
+		"trailing bytes":    {0xc0, 0xc0},
+		"integer key":       {0x81, 0x01, 0x02},
+		"extension type":    {0xd4, 0x01, 0x00},
+		"oversized array":   {0xdd, 0xff, 0xff, 0xff, 0xff},
+		"oversized map":     {0xdf, 0xff, 0xff, 0xff, 0xff},
+		"excessive nesting": deep,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var v interface{}
+			assert.Error(t, unmarshalMsgpack(data, &v))
+		})
+	}
+}
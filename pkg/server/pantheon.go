@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/quests"
+
+	"github.com/sirupsen/logrus"
+)
+
+// attachPantheon gives the world its pantheon and adds the deities' quest
+// hooks to the quest generator. A world restored from persistence keeps
+// the pantheon it was saved with; otherwise one is generated from the PCG
+// seed, so clerics and paladins created later can choose a patron.
+func (s *RPCServer) attachPantheon() {
+	if s.pcgManager == nil || s.state.WorldState == nil {
+		return
+	}
+	logger := logrus.WithField("function", "attachPantheon")
+
+	pantheon := s.state.WorldState.Pantheon
+	if pantheon == nil {
+		generated, err := s.pcgManager.GeneratePantheon(context.Background())
+		if err != nil {
+			logger.WithError(err).Warn("failed to generate pantheon - characters get no patron deity")
+			return
+		}
+		pantheon = generated
+		s.state.WorldState.Pantheon = pantheon
+	}
+
+	generator, err := s.pcgManager.GetRegistry().GetGenerator(pcg.ContentTypeQuests, "objective_based")
+	if err != nil {
+		logger.WithError(err).Warn("quest generator not registered - no deity quest hooks")
+		return
+	}
+	injected := 0
+	if questGen, ok := generator.(*quests.ObjectiveBasedGenerator); ok {
+		injected = questGen.InjectDeityHooks(pantheon)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"genre":       pantheon.Genre,
+		"deities":     len(pantheon.Deities),
+		"quest_hooks": injected,
+	}).Info("attached pantheon")
+}
+
+// worldPantheon returns the pantheon of the world, or nil if it has none.
+func (s *RPCServer) worldPantheon() *game.Pantheon {
+	if s.state == nil || s.state.WorldState == nil {
+		return nil
+	}
+	return s.state.WorldState.Pantheon
+}
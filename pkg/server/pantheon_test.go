@@ -0,0 +1,41 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCreateCharacter_Deity(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	pantheon := server.worldPantheon()
+	require.NotNil(t, pantheon, "the server generates a pantheon at startup")
+	require.NotEmpty(t, pantheon.Deities)
+
+	attributes := `"attribute_method":"custom","custom_attributes":{"strength":14,"dexterity":12,"constitution":14,"intelligence":10,"wisdom":15,"charisma":15}`
+	result, err := server.handleCreateCharacter(json.RawMessage(`{"name":"Pilgrim","class":"paladin",` + attributes + `,"seed":5}`))
+	require.NoError(t, err)
+	resultMap := result.(map[string]interface{})
+	require.Equal(t, true, resultMap["success"], resultMap["errors"])
+	deity := resultMap["deity"].(*game.Deity)
+	assert.Equal(t, game.DeityGood, deity.Alignment, "paladins serve good deities")
+	assert.Equal(t, deity.ID, resultMap["player"].(*game.Player).Deity)
+
+	chosen := pantheon.Deities[len(pantheon.Deities)-1].ID
+	params, _ := json.Marshal(map[string]interface{}{
+		"name": "Acolyte", "class": "cleric", "attribute_method": "custom",
+		"custom_attributes": map[string]int{"strength": 10, "dexterity": 10, "constitution": 10, "intelligence": 10, "wisdom": 15, "charisma": 10},
+		"deity":             chosen,
+	})
+	result, err = server.handleCreateCharacter(params)
+	require.NoError(t, err)
+	assert.Equal(t, chosen, result.(map[string]interface{})["deity"].(*game.Deity).ID)
+
+	result, err = server.handleCreateCharacter(json.RawMessage(`{"name":"Heretic","class":"cleric",` + attributes + `,"deity":"deity_nobody"}`))
+	require.NoError(t, err)
+	assert.Equal(t, false, result.(map[string]interface{})["success"])
+}
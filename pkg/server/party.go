@@ -0,0 +1,420 @@
+package server
+
+import (
+	"encoding/json"
+	"sync"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// PartyManager tracks the parties on the server and which party each player
+// belongs to. A player can be in at most one party at a time.
+//
+// Thread Safety: All methods are safe for concurrent use.
+type PartyManager struct {
+	mu       sync.RWMutex
+	parties  map[string]*game.Party
+	byMember map[string]string
+}
+
+// NewPartyManager creates an empty party manager
+func NewPartyManager() *PartyManager {
+	return &PartyManager{
+		parties:  make(map[string]*game.Party),
+		byMember: make(map[string]string),
+	}
+}
+
+// Create forms a new party led by leaderID
+func (pm *PartyManager) Create(leaderID string, policy game.RewardPolicy) (*game.Party, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if partyID, exists := pm.byMember[leaderID]; exists {
+		return nil, ErrPartyRejected.WithMessage("player %s is already in party %s", leaderID, partyID).WithData(map[string]interface{}{"party_id": partyID})
+	}
+
+	party, err := game.NewParty(uuid.New().String(), leaderID, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.parties[party.ID] = party
+	pm.byMember[leaderID] = party.ID
+	return party, nil
+}
+
+// Join adds a player to an existing party
+func (pm *PartyManager) Join(partyID, playerID string) (*game.Party, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if current, exists := pm.byMember[playerID]; exists {
+		return nil, ErrPartyRejected.WithMessage("player %s is already in party %s", playerID, current).WithData(map[string]interface{}{"party_id": current})
+	}
+
+	party, exists := pm.parties[partyID]
+	if !exists {
+		return nil, ErrPartyNotFound.WithMessage("party %s not found", partyID).WithData(map[string]interface{}{"party_id": partyID})
+	}
+	if err := party.AddMember(playerID); err != nil {
+		return nil, err
+	}
+
+	pm.byMember[playerID] = partyID
+	return party, nil
+}
+
+// Leave removes a player from their party. Empty parties are disbanded.
+func (pm *PartyManager) Leave(playerID string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	partyID, exists := pm.byMember[playerID]
+	if !exists {
+		return ErrPartyNotFound.WithMessage("player %s is not in a party", playerID)
+	}
+
+	party := pm.parties[partyID]
+	if err := party.RemoveMember(playerID); err != nil {
+		return err
+	}
+	delete(pm.byMember, playerID)
+
+	if len(party.GetMembers()) == 0 {
+		delete(pm.parties, partyID)
+	}
+	return nil
+}
+
+// PartyOf returns the party the player belongs to
+func (pm *PartyManager) PartyOf(playerID string) (*game.Party, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	partyID, exists := pm.byMember[playerID]
+	if !exists {
+		return nil, false
+	}
+	return pm.parties[partyID], true
+}
+
+// partySnapshot summarizes a party for RPC responses
+func partySnapshot(party *game.Party) map[string]interface{} {
+	return map[string]interface{}{
+		"party_id":      party.ID,
+		"leader_id":     party.GetLeaderID(),
+		"members":       party.GetMembers(),
+		"reward_policy": party.Policy,
+		"shared_quests": party.GetSharedQuests(),
+	}
+}
+
+// sharedQuestParty returns the player's party if the quest is shared with them
+func (s *RPCServer) sharedQuestParty(playerID, questID string) *game.Party {
+	if s.parties == nil {
+		return nil
+	}
+	party, exists := s.parties.PartyOf(playerID)
+	if !exists || !party.IsSharedWith(questID, playerID) {
+		return nil
+	}
+	return party
+}
+
+// partyPlayers returns the connected players belonging to the party
+func (s *RPCServer) partyPlayers(party *game.Party) map[string]*game.Player {
+	members := make(map[string]bool)
+	for _, id := range party.GetMembers() {
+		members[id] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	players := make(map[string]*game.Player, len(members))
+	for _, session := range s.sessions {
+		if session.Player != nil && members[session.Player.ID] {
+			players[session.Player.ID] = session.Player
+		}
+	}
+	return players
+}
+
+// emitPartyQuestUpdate notifies each listed party member of a shared quest change
+func (s *RPCServer) emitPartyQuestUpdate(party *game.Party, sourceID, questID, action string, recipients []string, data map[string]interface{}) {
+	for _, id := range recipients {
+		eventData := map[string]interface{}{
+			"party_id": party.ID,
+			"quest_id": questID,
+			"action":   action,
+		}
+		for key, value := range data {
+			eventData[key] = value
+		}
+		s.eventSys.Emit(game.GameEvent{
+			Type:     game.EventQuestUpdate,
+			SourceID: sourceID,
+			TargetID: id,
+			Data:     eventData,
+		})
+	}
+}
+
+// handleCreateParty forms a new party led by the requesting player.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - reward_policy: string - Optional "even" (default), "full" or "contribution"
+func (s *RPCServer) handleCreateParty(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleCreateParty",
+	})
+	logger.Debug("entering handleCreateParty")
+
+	var req struct {
+		SessionID    string `json:"session_id"`
+		RewardPolicy string `json:"reward_policy"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid party parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	party, err := s.parties.Create(session.Player.ID, game.RewardPolicy(req.RewardPolicy))
+	if err != nil {
+		logger.WithError(err).Warn("failed to create party")
+		return nil, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"party_id":  party.ID,
+		"leader_id": session.Player.ID,
+	}).Info("party created")
+
+	return map[string]interface{}{
+		"success": true,
+		"party":   partySnapshot(party),
+	}, nil
+}
+
+// handleJoinParty adds the requesting player to an existing party.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - party_id: string - The party to join
+func (s *RPCServer) handleJoinParty(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleJoinParty",
+	})
+	logger.Debug("entering handleJoinParty")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		PartyID   string `json:"party_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid party parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	party, err := s.parties.Join(req.PartyID, session.Player.ID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to join party")
+		return nil, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"party_id":  party.ID,
+		"player_id": session.Player.ID,
+	}).Info("player joined party")
+
+	return map[string]interface{}{
+		"success": true,
+		"party":   partySnapshot(party),
+	}, nil
+}
+
+// handleLeaveParty removes the requesting player from their party. The
+// player keeps their quest log, but shared quests stop synchronizing.
+func (s *RPCServer) handleLeaveParty(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleLeaveParty",
+	})
+	logger.Debug("entering handleLeaveParty")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid party parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.parties.Leave(session.Player.ID); err != nil {
+		return nil, err
+	}
+
+	logger.WithField("player_id", session.Player.ID).Info("player left party")
+
+	return map[string]interface{}{
+		"success": true,
+	}, nil
+}
+
+// handleGetParty returns the requesting player's party and its shared quests
+func (s *RPCServer) handleGetParty(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid party parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	party, exists := s.parties.PartyOf(session.Player.ID)
+	if !exists {
+		return nil, ErrPartyNotFound.WithMessage("player %s is not in a party", session.Player.ID)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"party":   partySnapshot(party),
+	}, nil
+}
+
+// handleShareQuest shares one of the requesting player's active quests with
+// the rest of their party.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the sharing player
+//   - quest_id: string - The active quest to share
+//
+// Returns:
+//   - interface{}: The members who joined, merged, or were skipped
+//   - error: If the player is not in a party or the quest is not active
+func (s *RPCServer) handleShareQuest(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleShareQuest",
+	})
+	logger.Debug("entering handleShareQuest")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		QuestID   string `json:"quest_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid share quest parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	party, exists := s.parties.PartyOf(session.Player.ID)
+	if !exists {
+		return nil, ErrPartyNotFound.WithMessage("player %s is not in a party", session.Player.ID)
+	}
+
+	players := s.partyPlayers(party)
+	members := make([]*game.Player, 0, len(players))
+	for _, player := range players {
+		members = append(members, player)
+	}
+
+	result, err := party.ShareQuest(req.QuestID, session.Player, members)
+	if err != nil {
+		logger.WithError(err).WithField("quest_id", req.QuestID).Warn("failed to share quest")
+		return nil, questError(session.Player, "share quest", req.QuestID, err)
+	}
+
+	s.emitPartyQuestUpdate(party, session.Player.ID, req.QuestID, "shared", append(result.Joined, result.Merged...), nil)
+
+	logger.WithFields(logrus.Fields{
+		"party_id": party.ID,
+		"quest_id": req.QuestID,
+		"joined":   len(result.Joined),
+		"merged":   len(result.Merged),
+		"skipped":  len(result.Skipped),
+	}).Info("quest shared with party")
+
+	return map[string]interface{}{
+		"success":  true,
+		"party_id": party.ID,
+		"quest_id": req.QuestID,
+		"joined":   result.Joined,
+		"merged":   result.Merged,
+		"skipped":  result.Skipped,
+	}, nil
+}
+
+// updateSharedObjective records objective progress on a shared quest and
+// notifies the other participants
+func (s *RPCServer) updateSharedObjective(party *game.Party, player *game.Player, questID string, objectiveIndex, progress int) (int, error) {
+	shared, err := party.RecordProgress(player.ID, questID, objectiveIndex, progress, s.partyPlayers(party))
+	if err != nil {
+		return 0, err
+	}
+
+	if state, exists := party.GetSharedQuest(questID); exists {
+		s.emitPartyQuestUpdate(party, player.ID, questID, "progress", state.Participants, map[string]interface{}{
+			"objective_index": objectiveIndex,
+			"progress":        shared,
+		})
+	}
+	return shared, nil
+}
+
+// completeSharedQuest completes a shared quest for every participant and
+// applies each participant's share of the rewards
+func (s *RPCServer) completeSharedQuest(party *game.Party, player *game.Player, questID string) (map[string][]game.QuestReward, error) {
+	players := s.partyPlayers(party)
+
+	split, err := party.CompleteQuest(player.ID, questID, players)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := make([]string, 0, len(split))
+	for id, rewards := range split {
+		if err := s.applyQuestRewards(players[id], questID, rewards); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, id)
+	}
+
+	data := map[string]interface{}{"rewards": split}
+	if quest, err := player.GetQuest(questID); err == nil {
+		data["title"] = quest.Title
+	}
+	s.emitPartyQuestUpdate(party, player.ID, questID, "completed", recipients, data)
+	s.fireQuestCompleteScripts(questID, recipients...)
+	return split, nil
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addPartyTestPlayer registers a connected session for a new player
+func addPartyTestPlayer(server *RPCServer, id string) *game.Player {
+	player := &game.Player{
+		Character: game.Character{ID: id, Name: id},
+		Level:     1,
+	}
+	server.mu.Lock()
+	server.sessions[id+"-session"] = &PlayerSession{
+		SessionID:   id + "-session",
+		Player:      player,
+		Connected:   true,
+		LastActive:  time.Now(),
+		MessageChan: make(chan []byte, MessageChanBufferSize),
+	}
+	server.mu.Unlock()
+	return player
+}
+
+func TestPartyManager(t *testing.T) {
+	pm := NewPartyManager()
+
+	party, err := pm.Create("alice", game.RewardPolicyEven)
+	require.NoError(t, err)
+
+	_, err = pm.Create("alice", game.RewardPolicyEven)
+	assert.Error(t, err, "players can only lead one party")
+
+	_, err = pm.Join(party.ID, "bob")
+	require.NoError(t, err)
+	_, err = pm.Join("missing", "carol")
+	assert.Error(t, err)
+
+	found, ok := pm.PartyOf("bob")
+	require.True(t, ok)
+	assert.Equal(t, party.ID, found.ID)
+
+	require.NoError(t, pm.Leave("alice"))
+	require.NoError(t, pm.Leave("bob"))
+	_, ok = pm.PartyOf("bob")
+	assert.False(t, ok)
+
+	pm.mu.RLock()
+	assert.Empty(t, pm.parties, "empty parties are disbanded")
+	pm.mu.RUnlock()
+}
+
+func TestSharedQuestFlow(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	alice := addPartyTestPlayer(server, "alice")
+	bob := addPartyTestPlayer(server, "bob")
+
+	updates := make(chan game.GameEvent, 16)
+	server.eventSys.Subscribe(game.EventQuestUpdate, func(event game.GameEvent) {
+		updates <- event
+	})
+
+	result, err := server.handleCreateParty(json.RawMessage(`{"session_id":"alice-session","reward_policy":"even"}`))
+	require.NoError(t, err)
+	partyID := result.(map[string]interface{})["party"].(map[string]interface{})["party_id"].(string)
+
+	_, err = server.handleJoinParty(json.RawMessage(`{"session_id":"bob-session","party_id":"` + partyID + `"}`))
+	require.NoError(t, err)
+
+	require.NoError(t, alice.StartQuest(game.Quest{
+		ID:         "wolves",
+		Objectives: []game.QuestObjective{{Description: "Hunt wolves", Required: 4}},
+		Rewards:    []game.QuestReward{{Type: "gold", Value: 50}},
+	}))
+
+	result, err = server.handleShareQuest(json.RawMessage(`{"session_id":"alice-session","quest_id":"wolves"}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, result.(map[string]interface{})["joined"])
+
+	_, err = server.handleShareQuest(json.RawMessage(`{"session_id":"alice-session","quest_id":"dragons"}`))
+	assert.ErrorIs(t, err, ErrQuestNotFound, "only quests in the sharer's log can be shared")
+
+	// Progress made by bob is shared with alice
+	_, err = server.handleUpdateObjective(json.RawMessage(`{"session_id":"bob-session","quest_id":"wolves","objective_index":0,"progress":4}`))
+	require.NoError(t, err)
+
+	aliceQuest, err := alice.GetQuest("wolves")
+	require.NoError(t, err)
+	assert.True(t, aliceQuest.Objectives[0].Completed)
+
+	result, err = server.handleCompleteQuest(json.RawMessage(`{"session_id":"alice-session","quest_id":"wolves"}`))
+	require.NoError(t, err)
+	assert.Contains(t, result.(map[string]interface{}), "party_rewards")
+
+	assert.Equal(t, 25, alice.Gold)
+	assert.Equal(t, 25, bob.Gold)
+	assert.Len(t, bob.GetCompletedQuests(), 1)
+
+	actions := map[string]int{}
+	for i := 0; i < 5; i++ {
+		select {
+		case event := <-updates:
+			actions[event.Data["action"].(string)]++
+		case <-time.After(time.Second):
+			t.Fatalf("expected 5 quest update events, got %v", actions)
+		}
+	}
+	assert.Equal(t, 1, actions["shared"])
+	assert.Equal(t, 2, actions["progress"])
+	assert.Equal(t, 2, actions["completed"])
+}
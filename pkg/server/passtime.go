@@ -0,0 +1,329 @@
+package server
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Scheduled event types run when their game time comes, see
+// runScheduledEvents.
+const (
+	// ScheduledShopRestock restocks every merchant. It has no parameters.
+	ScheduledShopRestock = "shop_restock"
+	// ScheduledQuestDeadline fails a quest still active when its time runs
+	// out. Parameters: the player ID and the quest ID.
+	ScheduledQuestDeadline = "quest_deadline"
+)
+
+// shopRestockEventID identifies the repeating shop restock event
+const shopRestockEventID = "shop_restock"
+
+// maxPassTimeDays is the most days a single passTime call may pass, on
+// top of up to a day's hours.
+const maxPassTimeDays = 30
+
+// ExpiredEffect identifies an effect that ran out while game time passed.
+type ExpiredEffect struct {
+	HolderID string          `json:"holder_id"`
+	EffectID string          `json:"effect_id"`
+	Type     game.EffectType `json:"type"`
+}
+
+// Schedule adds an event to run once the game time reaches its trigger
+// time.
+func (t *TimeManager) Schedule(event ScheduledEvent) {
+	t.ScheduledEvents = append(t.ScheduledEvents, event)
+}
+
+// IsScheduled reports whether an event with the given ID is pending.
+func (t *TimeManager) IsScheduled(eventID string) bool {
+	for _, event := range t.ScheduledEvents {
+		if event.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// DueEvents removes the events whose trigger time has been reached and
+// returns them in trigger order. A repeating event with an interval is
+// scheduled again for its first trigger after the current time, so it runs
+// once however many intervals have passed.
+func (t *TimeManager) DueEvents() []ScheduledEvent {
+	now := t.CurrentTime.GameTicks
+	var due []ScheduledEvent
+	pending := make([]ScheduledEvent, 0, len(t.ScheduledEvents))
+	for _, event := range t.ScheduledEvents {
+		if !isTimeToExecute(t.CurrentTime, event.TriggerTime) {
+			pending = append(pending, event)
+			continue
+		}
+		due = append(due, event)
+		if event.Repeating && event.Interval > 0 {
+			next := event
+			missed := (now-event.TriggerTime.GameTicks)/event.Interval + 1
+			next.TriggerTime.GameTicks += missed * event.Interval
+			pending = append(pending, next)
+		}
+	}
+	t.ScheduledEvents = pending
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].TriggerTime.GameTicks < due[j].TriggerTime.GameTicks
+	})
+	return due
+}
+
+// scheduleShopRestock schedules merchants to restock every
+// MerchantRestockDays at midnight, unless the game state already has the
+// restock scheduled.
+func (s *RPCServer) scheduleShopRestock() {
+	s.state.stateMu.Lock()
+	defer s.state.stateMu.Unlock()
+
+	tm := s.state.TimeManager
+	if tm.IsScheduled(shopRestockEventID) {
+		return
+	}
+	interval := MerchantRestockDays * TicksPerDay
+	tm.Schedule(ScheduledEvent{
+		EventID:     shopRestockEventID,
+		EventType:   ScheduledShopRestock,
+		TriggerTime: game.GameTime{GameTicks: (tm.CurrentTime.GameTicks/interval + 1) * interval},
+		Repeating:   true,
+		Interval:    interval,
+	})
+}
+
+// runScheduledEvents runs the scheduled events whose game time has come
+// and returns them.
+func (s *RPCServer) runScheduledEvents() []ScheduledEvent {
+	s.state.stateMu.Lock()
+	due := s.state.TimeManager.DueEvents()
+	s.state.stateMu.Unlock()
+
+	for _, event := range due {
+		logger := logrus.WithFields(logrus.Fields{
+			"function":  "runScheduledEvents",
+			"eventID":   event.EventID,
+			"eventType": event.EventType,
+		})
+
+		switch event.EventType {
+		case ScheduledShopRestock:
+			s.restockMerchants()
+		case ScheduledQuestDeadline:
+			if len(event.Parameters) < 2 {
+				logger.Warn("quest deadline without player and quest")
+				continue
+			}
+			s.expireQuestDeadline(event.Parameters[0], event.Parameters[1])
+		default:
+			logger.Warn("unknown scheduled event type")
+			continue
+		}
+		logger.Info("ran scheduled event")
+	}
+	return due
+}
+
+// restockMerchants restocks every merchant and broadcasts
+// EventMerchantsRestocked.
+func (s *RPCServer) restockMerchants() {
+	restocked := s.merchants.Restock()
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "restockMerchants",
+		"restocked": restocked,
+	}).Info("merchants restocked")
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:      EventMerchantsRestocked,
+		SourceID:  "calendar",
+		Data:      map[string]interface{}{"restocked": restocked},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// expireQuestDeadline fails a player's quest whose deadline has passed,
+// with the quest's failure consequences. Quests no longer active, and
+// players who are not connected, are left alone.
+func (s *RPCServer) expireQuestDeadline(playerID, questID string) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "expireQuestDeadline",
+		"playerID": playerID,
+		"questID":  questID,
+	})
+
+	player := s.connectedPlayer(playerID)
+	if player == nil {
+		logger.Warn("quest deadline passed for a player who is not connected")
+		return
+	}
+	quest, err := player.GetQuest(questID)
+	if err != nil || quest.Status != game.QuestActive {
+		return
+	}
+
+	if _, err := s.applyQuestOutcome(player, questID, game.QuestFailed, func() error {
+		return player.FailQuest(questID)
+	}); err != nil {
+		logger.WithError(err).Warn("failed to fail quest past its deadline")
+		return
+	}
+	s.emitQuestUpdate(player, questID, "failed")
+	logger.Info("quest failed past its deadline")
+}
+
+// expireEffects ends the effects of every connected player and their
+// companions that run out when elapsed game time passes at once, see
+// game.Effect.ExpiresWithin.
+func (s *RPCServer) expireEffects(elapsed time.Duration) []ExpiredEffect {
+	s.mu.RLock()
+	var holders []game.EffectHolder
+	var ids []string
+	for _, session := range s.sessions {
+		if session.Player == nil {
+			continue
+		}
+		holders = append(holders, session.Player)
+		ids = append(ids, session.Player.GetID())
+	}
+	s.mu.RUnlock()
+
+	for _, playerID := range append([]string(nil), ids...) {
+		for _, companion := range s.companionsOf(playerID) {
+			holders = append(holders, companion.NPC)
+			ids = append(ids, companion.NPC.GetID())
+		}
+	}
+
+	now := time.Now()
+	var expired []ExpiredEffect
+	for i, holder := range holders {
+		for _, effect := range holder.GetEffects() {
+			if !effect.ExpiresWithin(now, elapsed) {
+				continue
+			}
+			if err := holder.RemoveEffect(effect.ID); err != nil {
+				continue
+			}
+			expired = append(expired, ExpiredEffect{HolderID: ids[i], EffectID: effect.ID, Type: effect.Type})
+		}
+	}
+	return expired
+}
+
+// handlePassTime fast-forwards game time outside combat, as while the party
+// camps or spends downtime in town. Time passes an hour at a time: the
+// weather and world events move on, light sources burn down and scheduled
+// events such as shop restocks and quest deadlines run when their time
+// comes, and quests past their time limits fail. Effects that run out in
+// the time passed end. Unlike rest, passing time heals nobody and readies no
+// spells.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - days: int - Days to pass (optional, 0 to 30)
+//   - hours: int - Hours to pass (optional, 0 to 24)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating if time passed
+//   - hours_passed: int hours passed
+//   - date: CalendarDate after the time passed
+//   - game_time: int64 game ticks after the time passed
+//   - festivals: []Festival whose day began in the time passed
+//   - scheduled_events: id, type and trigger_tick of each scheduled event run
+//   - expired_effects: []ExpiredEffect that ran out
+//   - error: Invalid parameters or session, ErrCombatInProgress during combat
+func (s *RPCServer) handlePassTime(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handlePassTime",
+	})
+	logger.Debug("entering handlePassTime")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		Days      int    `json:"days"`
+		Hours     int    `json:"hours"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid pass time parameters", err.Error())
+	}
+	hours := req.Days*24 + req.Hours
+	if req.Days < 0 || req.Days > maxPassTimeDays || req.Hours < 0 || req.Hours > 24 || hours < 1 {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid pass time parameters", "days must be between 0 and 30 and hours between 0 and 24, passing at least an hour")
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.state.TurnManager.IsInCombat {
+		return nil, ErrCombatInProgress.WithMessage("cannot pass time during combat")
+	}
+
+	s.state.stateMu.RLock()
+	start := s.state.TimeManager.CurrentTime.GameTicks
+	s.state.stateMu.RUnlock()
+
+	scheduled := []map[string]interface{}{}
+	for hour := 0; hour < hours; hour++ {
+		s.state.stateMu.Lock()
+		s.state.TimeManager.Skip(TicksPerHour)
+		s.state.stateMu.Unlock()
+		s.updateWeather()
+		s.updateWorldEvents()
+		for _, event := range s.runScheduledEvents() {
+			scheduled = append(scheduled, map[string]interface{}{
+				"id":           event.EventID,
+				"type":         event.EventType,
+				"trigger_tick": event.TriggerTime.GameTicks,
+			})
+		}
+		s.checkQuestDeadlines()
+	}
+	expired := s.expireEffects(time.Duration(int64(hours)*TicksPerHour) * time.Second)
+
+	s.state.stateMu.RLock()
+	gameTime := s.state.TimeManager.CurrentTime.GameTicks
+	date := s.state.TimeManager.Date()
+	s.state.stateMu.RUnlock()
+	festivals := FestivalsBetween(start, gameTime)
+
+	logger.WithFields(logrus.Fields{
+		"player_id": session.Player.GetID(),
+		"hours":     hours,
+		"date":      date.String(),
+	}).Info("time passed")
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventTimePassed,
+		SourceID: session.Player.GetID(),
+		Data: map[string]interface{}{
+			"hours":     hours,
+			"date":      date,
+			"festivals": festivals,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+
+	return map[string]interface{}{
+		"success":          true,
+		"hours_passed":     hours,
+		"date":             date,
+		"game_time":        gameTime,
+		"festivals":        festivals,
+		"scheduled_events": scheduled,
+		"expired_effects":  expired,
+	}, nil
+}
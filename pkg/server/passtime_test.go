@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeManager_DueEvents(t *testing.T) {
+	tm := NewTimeManager()
+	tm.Schedule(ScheduledEvent{EventID: "later", TriggerTime: game.GameTime{GameTicks: 3 * TicksPerDay}})
+	tm.Schedule(ScheduledEvent{EventID: "daily", TriggerTime: game.GameTime{GameTicks: TicksPerDay}, Repeating: true, Interval: TicksPerDay})
+	tm.Schedule(ScheduledEvent{EventID: "once", TriggerTime: game.GameTime{GameTicks: TicksPerHour}})
+
+	assert.Empty(t, tm.DueEvents())
+
+	tm.Skip(2*TicksPerDay + TicksPerHour)
+	due := tm.DueEvents()
+	require.Len(t, due, 2)
+	assert.Equal(t, "once", due[0].EventID)
+	assert.Equal(t, "daily", due[1].EventID, "a repeating event runs once however many times it came due")
+
+	assert.False(t, tm.IsScheduled("once"))
+	require.True(t, tm.IsScheduled("daily"))
+	assert.Empty(t, tm.DueEvents())
+
+	tm.Skip(TicksPerDay)
+	due = tm.DueEvents()
+	require.Len(t, due, 2)
+	assert.Equal(t, "later", due[0].EventID, "events due at the same time run in the order they were scheduled")
+	assert.Equal(t, "daily", due[1].EventID)
+}
+
+func TestHandlePassTime(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	player := session.Player
+	player.Gold = 100
+	start := server.state.TimeManager.CurrentTime.GameTicks
+
+	merchant := game.NewMerchant("shop_merchant", "Merchant", "merchants_guild", game.Position{}, 30,
+		[]game.Item{{ID: "sword", Name: "Sword", Value: 60, Weight: 5}})
+	require.NoError(t, server.merchants.Add(merchant))
+	_, err := merchant.SellToPlayer(player, "sword")
+	require.NoError(t, err)
+	require.Empty(t, merchant.GetStock())
+
+	require.NoError(t, player.StartQuest(game.Quest{ID: "rats", Title: "Rats in the Cellar", Status: game.QuestActive}))
+	server.state.TimeManager.Schedule(ScheduledEvent{
+		EventID:     "rats_deadline",
+		EventType:   ScheduledQuestDeadline,
+		TriggerTime: game.GameTime{GameTicks: start + 2*TicksPerDay},
+		Parameters:  []string{player.GetID(), "rats"},
+	})
+
+	short := game.NewEffect(game.EffectStatBoost, game.Duration{RealTime: time.Hour}, 1)
+	long := game.NewEffect(game.EffectStatPenalty, game.Duration{Turns: 24 * 6 * 8}, 1)
+	require.NoError(t, player.AddEffect(short))
+	require.NoError(t, player.AddEffect(long))
+
+	params, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "days": MerchantRestockDays})
+	require.NoError(t, err)
+	result, err := server.handlePassTime(params)
+	require.NoError(t, err)
+	passed := result.(map[string]interface{})
+
+	assert.Equal(t, MerchantRestockDays*24, passed["hours_passed"])
+	assert.Equal(t, start+MerchantRestockDays*TicksPerDay, passed["game_time"])
+	assert.Equal(t, DateAt(start+MerchantRestockDays*TicksPerDay), passed["date"])
+	assert.Equal(t, 8, passed["date"].(CalendarDate).Day)
+
+	scheduled := passed["scheduled_events"].([]map[string]interface{})
+	require.Len(t, scheduled, 2)
+	assert.Equal(t, "rats_deadline", scheduled[0]["id"])
+	assert.Equal(t, shopRestockEventID, scheduled[1]["id"])
+	assert.True(t, server.state.TimeManager.IsScheduled(shopRestockEventID), "shops restock every week")
+
+	assert.Len(t, merchant.GetStock(), 1, "the merchant restocked the sword")
+	quest, err := player.GetQuest("rats")
+	require.NoError(t, err)
+	assert.Equal(t, game.QuestFailed, quest.Status)
+
+	assert.Equal(t, []ExpiredEffect{{HolderID: player.GetID(), EffectID: short.ID, Type: game.EffectStatBoost}}, passed["expired_effects"])
+	assert.True(t, player.HasEffect(game.EffectStatPenalty), "effects lasting beyond the time passed remain")
+
+	state := server.state.GetState()["time"].(map[string]interface{})
+	assert.Equal(t, passed["date"], state["date"])
+}
+
+func TestHandlePassTime_Rejected(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	_, err := server.handlePassTime(json.RawMessage(`{"session_id":"` + session.SessionID + `"}`))
+	assert.ErrorContains(t, err, "Invalid pass time parameters")
+	_, err = server.handlePassTime(json.RawMessage(`{"session_id":"` + session.SessionID + `","days":31}`))
+	assert.ErrorContains(t, err, "Invalid pass time parameters")
+
+	server.state.TurnManager.IsInCombat = true
+	t.Cleanup(func() { server.state.TurnManager.IsInCombat = false })
+	_, err = server.handlePassTime(json.RawMessage(`{"session_id":"` + session.SessionID + `","hours":2}`))
+	assert.ErrorIs(t, err, ErrCombatInProgress)
+}
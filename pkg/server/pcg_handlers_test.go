@@ -1,11 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
 	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/items"
+	"goldbox-rpg/pkg/pcg/levels"
 
 	"github.com/sirupsen/logrus"
 )
@@ -222,3 +230,168 @@ func TestPCGMethodConstants(t *testing.T) {
 
 	logrus.Info("PCG method constants test passed successfully")
 }
+
+// writeLootTables writes a loot table file to path
+func writeLootTables(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write loot tables: %v", err)
+	}
+}
+
+// TestAdminReloadLootTables verifies admins can reload loot tables at
+// runtime and that encounters roll from the reloaded tables
+func TestAdminReloadLootTables(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+
+	path := filepath.Join(t.TempDir(), "loot_tables.yaml")
+	writeLootTables(t, path, "loot_tables:\n  weapons:\n    entries:\n      - item: sword\n        weight: 1\n")
+	tables := items.NewLootTableRegistry(nil)
+	if err := tables.LoadFromFile(path); err != nil {
+		t.Fatalf("Failed to load loot tables: %v", err)
+	}
+	server.lootTables = tables
+	server.config.RandomEncountersEnabled = true
+	server.config.EncounterCooldownSteps = 0
+	server.attachEncounters()
+	server.encounters.SetTable(pcg.BiomeDungeon, alwaysEncounter)
+
+	if proposal := server.encounters.Check("looter", game.Position{}, 3, 1); proposal == nil || proposal.Loot != nil {
+		t.Fatalf("Expected an encounter without loot before a combat table exists, got %+v", proposal)
+	}
+
+	var rpcErr *JSONRPCError
+	_, err := server.handleMethod(MethodAdminReloadLoot, json.RawMessage(`{"session_id":"`+session.SessionID+`"}`))
+	if !errors.As(err, &rpcErr) || rpcErr.Code != JSONRPCInvalidParams {
+		t.Fatalf("Expected a player session to be rejected, got %v", err)
+	}
+
+	server.admin = newTestAdminConsole(10, AdminPermissionInspect)
+	_, err = server.handleMethod(MethodAdminReloadLoot, json.RawMessage(`{"admin_token":"`+testAdminToken+`"}`))
+	if !errors.Is(err, ErrAdminForbidden) {
+		t.Fatalf("Expected a token without the generate permission to be forbidden, got %v", err)
+	}
+
+	server.admin = newTestAdminConsole(10, AdminPermissionGenerate)
+	writeLootTables(t, path, "loot_tables:\n  combat:\n    entries:\n      - item: sword\n        weight: 1\n")
+	result, err := server.handleMethod(MethodAdminReloadLoot, json.RawMessage(`{"admin_token":"`+testAdminToken+`"}`))
+	if err != nil {
+		t.Fatalf("admin.reloadLootTables failed: %v", err)
+	}
+	if names := result.(map[string]interface{})["tables"].([]string); !slices.Equal(names, []string{"combat"}) {
+		t.Errorf("Expected only the combat table after reload, got %v", names)
+	}
+
+	proposal := server.encounters.Check("looter", game.Position{}, 3, 1)
+	if proposal == nil || len(proposal.Loot) != len(proposal.Monsters) {
+		t.Fatalf("Expected loot for every encounter monster after reload, got %+v", proposal)
+	}
+	for i, loot := range proposal.Loot {
+		if len(loot) == 0 {
+			t.Errorf("Expected monster %d to carry an item from the combat table", i)
+		}
+	}
+}
+
+// TestSetupPCGManager_UsesLootTables verifies generated levels roll loot
+// from the server's loot tables
+func TestSetupPCGManager_UsesLootTables(t *testing.T) {
+	tables := items.NewLootTableRegistry(nil)
+	if err := tables.LoadFromFile(filepath.Join("..", "..", "data", "pcg", "items", "loot_tables.yaml")); err != nil {
+		t.Fatalf("Failed to load loot tables: %v", err)
+	}
+
+	manager, err := setupPCGManager(logrus.NewEntry(logrus.StandardLogger()), tables)
+	if err != nil {
+		t.Fatalf("setupPCGManager failed: %v", err)
+	}
+	generator, err := manager.GetRegistry().GetGenerator(pcg.ContentTypeLevels, "room_corridor")
+	if err != nil {
+		t.Fatalf("room_corridor generator not registered: %v", err)
+	}
+
+	bounds := pcg.Rectangle{Width: 10, Height: 10}
+	params := pcg.LevelParams{
+		GenerationParams: pcg.GenerationParams{Seed: 7, Difficulty: 8, PlayerLevel: 8},
+		LevelTheme:       pcg.ThemeClassic,
+	}
+	room, err := generator.(*levels.RoomCorridorGenerator).GenerateRoom(context.Background(), bounds, pcg.RoomTypeTreasure, params)
+	if err != nil {
+		t.Fatalf("GenerateRoom failed: %v", err)
+	}
+	for _, feature := range room.Features {
+		if loot, ok := feature.Properties["items"].([]*game.Item); ok && len(loot) > 0 {
+			return
+		}
+	}
+	t.Errorf("Expected a treasure chest holding items from the treasure table, got %+v", room.Features)
+}
+
+// TestAdminReloadPCGDefinitions verifies only admins with the generate
+// permission can reload biomes and themes at runtime
+func TestAdminReloadPCGDefinitions(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+
+	var rpcErr *JSONRPCError
+	_, err := server.handleMethod(MethodAdminReloadPCG, json.RawMessage(`{"session_id":"`+session.SessionID+`"}`))
+	if !errors.As(err, &rpcErr) || rpcErr.Code != JSONRPCInvalidParams {
+		t.Fatalf("Expected a player session to be rejected, got %v", err)
+	}
+
+	server.admin = newTestAdminConsole(10, AdminPermissionInspect)
+	_, err = server.handleMethod(MethodAdminReloadPCG, json.RawMessage(`{"admin_token":"`+testAdminToken+`"}`))
+	if !errors.Is(err, ErrAdminForbidden) {
+		t.Fatalf("Expected a token without the generate permission to be forbidden, got %v", err)
+	}
+
+	server.admin = newTestAdminConsole(10, AdminPermissionGenerate)
+	result, err := server.handleMethod(MethodAdminReloadPCG, json.RawMessage(`{"admin_token":"`+testAdminToken+`"}`))
+	if err != nil {
+		t.Fatalf("admin.reloadPCGDefinitions failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	biomes := resultMap["biomes"].([]pcg.BiomeType)
+	if !slices.Contains(biomes, pcg.BiomeSwamp) {
+		t.Errorf("Expected swamp biome after reload, got %v", biomes)
+	}
+	themes := resultMap["themes"].([]pcg.LevelTheme)
+	if !slices.Contains(themes, pcg.ThemeHorror) {
+		t.Errorf("Expected horror theme after reload, got %v", themes)
+	}
+}
+
+func TestHandleGenerateLevel_LayoutConstraints(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	generate := func(constraints string) (interface{}, error) {
+		return server.handleGenerateLevel(json.RawMessage(`{"session_id":"` + session.SessionID + `","room_count":12,"constraints":` + constraints + `}`))
+	}
+
+	result, err := generate(`[{"kind":"count","room_type":"shop","min":1,"max":1}]`)
+	if err != nil {
+		t.Fatalf("handleGenerateLevel failed: %v", err)
+	}
+	level := result.(map[string]interface{})["level"].(*game.Level)
+	if report, ok := level.Properties["constraints"].(pcg.ConstraintReport); !ok || !report.Satisfied() {
+		t.Errorf("Expected a satisfied constraint report, got %+v", level.Properties["constraints"])
+	}
+
+	_, err = generate(`[{"kind":"count","room_type":"exit","min":2}]`)
+	var rpcErr *JSONRPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != JSONRPCInvalidParams {
+		t.Errorf("Expected invalid params for a constraint on exit rooms, got %v", err)
+	}
+
+	_, err = generate(`[{"kind":"count","room_type":"shop","min":40}]`)
+	if !errors.Is(err, ErrGenerationFailed) {
+		t.Fatalf("Expected ErrGenerationFailed, got %v", err)
+	}
+	errors.As(err, &rpcErr)
+	report, ok := rpcErr.Data.(map[string]interface{})["constraints"].(pcg.ConstraintReport)
+	if !ok || len(report.Violations()) != 1 {
+		t.Errorf("Expected the violation report in the error data, got %+v", rpcErr.Data)
+	}
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"time"
+
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pcgContentTypes lists the content types reported by the PCG collector.
+var pcgContentTypes = []pcg.ContentType{
+	pcg.ContentTypeTerrain,
+	pcg.ContentTypeItems,
+	pcg.ContentTypeLevels,
+	pcg.ContentTypeQuests,
+	pcg.ContentTypeMonsters,
+}
+
+// pcgAdjustmentTypes lists the runtime adjustment types reported by the PCG collector.
+var pcgAdjustmentTypes = []pcg.AdjustmentType{
+	pcg.AdjustmentTypeDifficulty,
+	pcg.AdjustmentTypeVariety,
+	pcg.AdjustmentTypeComplexity,
+	pcg.AdjustmentTypePerformance,
+}
+
+// pcgCollector exports PCG generation and content quality metrics. Values are
+// read from the PCG manager at scrape time so they always reflect the current
+// quality assessment.
+type pcgCollector struct {
+	manager *pcg.PCGManager
+	events  *pcg.PCGEventManager
+
+	generations    *prometheus.Desc
+	failures       *prometheus.Desc
+	cacheHitRatio  *prometheus.Desc
+	cacheBytes     *prometheus.Desc
+	cacheEvictions *prometheus.Desc
+	qualityScore   *prometheus.Desc
+	componentScore *prometheus.Desc
+	gateAttempts   *prometheus.Desc
+	gateRejections *prometheus.Desc
+	adjustments    *prometheus.Desc
+}
+
+// newPCGCollector creates a collector for manager. events may be nil, in which
+// case runtime adjustment counts are not reported.
+func newPCGCollector(manager *pcg.PCGManager, events *pcg.PCGEventManager) *pcgCollector {
+	return &pcgCollector{
+		manager: manager,
+		events:  events,
+		generations: prometheus.NewDesc(
+			"goldbox_pcg_generations_total",
+			"Total number of successful PCG generations by content type",
+			[]string{"content_type"}, nil,
+		),
+		failures: prometheus.NewDesc(
+			"goldbox_pcg_generation_failures_total",
+			"Total number of failed PCG generations by content type",
+			[]string{"content_type"}, nil,
+		),
+		cacheHitRatio: prometheus.NewDesc(
+			"goldbox_pcg_cache_hit_ratio",
+			"Ratio of PCG cache hits to total cache lookups (0-1)",
+			nil, nil,
+		),
+		cacheBytes: prometheus.NewDesc(
+			"goldbox_pcg_cache_bytes",
+			"Encoded size of the generated content held by the PCG content cache",
+			nil, nil,
+		),
+		cacheEvictions: prometheus.NewDesc(
+			"goldbox_pcg_cache_evictions_total",
+			"Total number of PCG content cache entries evicted to stay within the size limit",
+			nil, nil,
+		),
+		qualityScore: prometheus.NewDesc(
+			"goldbox_pcg_quality_score",
+			"Overall weighted PCG content quality score (0-1)",
+			nil, nil,
+		),
+		componentScore: prometheus.NewDesc(
+			"goldbox_pcg_quality_component_score",
+			"PCG content quality score by component (0-1)",
+			[]string{"component"}, nil,
+		),
+		gateAttempts: prometheus.NewDesc(
+			"goldbox_pcg_quality_gate_candidates_total",
+			"Total number of candidates generated through the PCG quality gate by content type",
+			[]string{"content_type"}, nil,
+		),
+		gateRejections: prometheus.NewDesc(
+			"goldbox_pcg_quality_gate_rejections_total",
+			"Total number of candidates scoring below the PCG quality gate threshold by content type",
+			[]string{"content_type"}, nil,
+		),
+		adjustments: prometheus.NewDesc(
+			"goldbox_pcg_adjustments_total",
+			"Total number of PCG runtime adjustments by type and result",
+			[]string{"adjustment_type", "result"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *pcgCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.generations
+	ch <- c.failures
+	ch <- c.cacheHitRatio
+	ch <- c.cacheBytes
+	ch <- c.cacheEvictions
+	ch <- c.qualityScore
+	ch <- c.componentScore
+	ch <- c.gateAttempts
+	ch <- c.gateRejections
+	ch <- c.adjustments
+}
+
+// Collect implements prometheus.Collector.
+func (c *pcgCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := c.manager.GetMetrics()
+	for _, contentType := range pcgContentTypes {
+		label := string(contentType)
+		ch <- prometheus.MustNewConstMetric(c.generations, prometheus.CounterValue,
+			float64(metrics.GetGenerationCount(contentType)), label)
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.CounterValue,
+			float64(metrics.GetErrorCount(contentType)), label)
+	}
+
+	// GetCacheHitRatio reports a percentage; Prometheus ratios are 0-1.
+	ch <- prometheus.MustNewConstMetric(c.cacheHitRatio, prometheus.GaugeValue,
+		metrics.GetCacheHitRatio()/100.0)
+	cache := c.manager.GetContentCache().Stats()
+	ch <- prometheus.MustNewConstMetric(c.cacheBytes, prometheus.GaugeValue, float64(cache.Bytes))
+	ch <- prometheus.MustNewConstMetric(c.cacheEvictions, prometheus.CounterValue, float64(cache.Evictions))
+
+	report := c.manager.GenerateQualityReport()
+	ch <- prometheus.MustNewConstMetric(c.qualityScore, prometheus.GaugeValue, report.OverallScore)
+	for component, score := range report.ComponentScores {
+		ch <- prometheus.MustNewConstMetric(c.componentScore, prometheus.GaugeValue, score, component)
+	}
+
+	gate := c.manager.GetQualityGate().Stats()
+	for _, contentType := range pcgContentTypes {
+		label := string(contentType)
+		ch <- prometheus.MustNewConstMetric(c.gateAttempts, prometheus.CounterValue, float64(gate[contentType].Attempts), label)
+		ch <- prometheus.MustNewConstMetric(c.gateRejections, prometheus.CounterValue, float64(gate[contentType].Rejections), label)
+	}
+
+	if c.events == nil {
+		return
+	}
+
+	counts := make(map[pcg.AdjustmentType][2]int)
+	for _, record := range c.events.GetAdjustmentHistory() {
+		entry := counts[record.AdjustmentType]
+		if record.Success {
+			entry[0]++
+		} else {
+			entry[1]++
+		}
+		counts[record.AdjustmentType] = entry
+	}
+	for _, adjustmentType := range pcgAdjustmentTypes {
+		entry := counts[adjustmentType]
+		label := string(adjustmentType)
+		ch <- prometheus.MustNewConstMetric(c.adjustments, prometheus.CounterValue, float64(entry[0]), label, "success")
+		ch <- prometheus.MustNewConstMetric(c.adjustments, prometheus.CounterValue, float64(entry[1]), label, "failure")
+	}
+}
+
+// RegisterPCGMetrics exports PCG generation durations, failure counts, cache
+// hit ratio, quality scores, quality gate rejections and (when events is non-nil) runtime adjustment
+// counts on the metrics endpoint.
+func (m *Metrics) RegisterPCGMetrics(manager *pcg.PCGManager, events *pcg.PCGEventManager) error {
+	if err := m.registry.Register(newPCGCollector(manager, events)); err != nil {
+		return err
+	}
+
+	manager.AddGenerationObserver(m.RecordPCGGeneration)
+	return nil
+}
+
+// RecordPCGGeneration records the duration of a PCG generation run
+func (m *Metrics) RecordPCGGeneration(contentType pcg.ContentType, duration time.Duration, err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	m.pcgGenerationDuration.WithLabelValues(string(contentType), status).Observe(duration.Seconds())
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/quests"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrapeMetrics returns the text exposition served by the metrics handler
+func scrapeMetrics(t *testing.T, metrics *Metrics) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	metrics.GetHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_RegisterPCGMetrics(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+
+	manager := pcg.NewPCGManager(game.NewWorld(), logger)
+	manager.InitializeWithSeed(42)
+	require.NoError(t, manager.GetRegistry().RegisterGenerator("objective_based", quests.NewObjectiveBasedGenerator()))
+
+	events := pcg.NewPCGEventManager(logger, game.NewEventSystem(), manager)
+	metrics := NewMetrics()
+	require.NoError(t, metrics.RegisterPCGMetrics(manager, events))
+	manager.GetQualityGate().SetConfig(pcg.QualityGateConfig{Enabled: true, MaxAttempts: 1, MinScore: 0})
+
+	_, err := manager.GenerateQuestForArea(context.Background(), "area_1", pcg.QuestTypeFetch, 3)
+	require.NoError(t, err)
+
+	// Terrain generation fails because no terrain generator is registered.
+	_, err = manager.GenerateTerrainForLevel(context.Background(), "level_1", 30, 30, pcg.BiomeCave, 3)
+	require.Error(t, err)
+
+	_, err = manager.GenerateMonsters(context.Background(), pcg.MonsterParams{
+		GenerationParams: pcg.GenerationParams{Seed: 5, Difficulty: 3},
+		Count:            2,
+	})
+	require.NoError(t, err)
+
+	manager.GetMetrics().RecordCacheHit()
+	manager.GetMetrics().RecordCacheMiss()
+
+	body := scrapeMetrics(t, metrics)
+
+	assert.Contains(t, body, `goldbox_pcg_generations_total{content_type="quests"} 1`)
+	assert.Contains(t, body, `goldbox_pcg_generation_failures_total{content_type="terrain"} 1`)
+	assert.Contains(t, body, `goldbox_pcg_generations_total{content_type="monsters"} 1`)
+	assert.Contains(t, body, `goldbox_pcg_generation_duration_seconds_count{content_type="quests",status="success"} 1`)
+	assert.Contains(t, body, `goldbox_pcg_generation_duration_seconds_count{content_type="terrain",status="failure"} 1`)
+	assert.Contains(t, body, "goldbox_pcg_cache_hit_ratio 0.5")
+	assert.Contains(t, body, "goldbox_pcg_cache_bytes 0")
+	assert.Contains(t, body, "goldbox_pcg_cache_evictions_total 0")
+	assert.Contains(t, body, "goldbox_pcg_quality_score ")
+	assert.Contains(t, body, `goldbox_pcg_quality_component_score{component="stability"}`)
+	assert.Contains(t, body, `goldbox_pcg_quality_gate_candidates_total{content_type="quests"} 1`)
+	assert.Contains(t, body, `goldbox_pcg_quality_gate_rejections_total{content_type="quests"} 0`)
+	assert.Contains(t, body, `goldbox_pcg_adjustments_total{adjustment_type="difficulty",result="success"} 0`)
+}
+
+func TestMetrics_RegisterPCGMetricsWithoutEvents(t *testing.T) {
+	metrics := NewMetrics()
+	require.NoError(t, metrics.RegisterPCGMetrics(pcg.NewPCGManager(game.NewWorld(), nil), nil))
+
+	body := scrapeMetrics(t, metrics)
+	assert.Contains(t, body, "goldbox_pcg_quality_score")
+	assert.NotContains(t, body, "goldbox_pcg_adjustments_total")
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/pcg/plugins"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loadPCGPlugins starts the configured PCG plugins and registers their
+// generators with the manager's registry. A plugin that fails to start or
+// register is logged and skipped so one broken plugin does not keep the
+// server from starting.
+//
+// Returns:
+//   - []*plugins.Plugin: The plugins that were loaded, to close on shutdown
+func loadPCGPlugins(pcgManager *pcg.PCGManager, cfg *config.Config, logger *logrus.Entry) []*plugins.Plugin {
+	opts := plugins.Options{
+		Timeout:    cfg.PCGPluginTimeout,
+		MaxTimeout: cfg.PCGPluginMaxTimeout,
+		Logger:     logrus.StandardLogger(),
+	}
+
+	var loaded []*plugins.Plugin
+	for _, path := range cfg.PCGPlugins {
+		plugin, err := plugins.Load(context.Background(), path, opts)
+		if err != nil {
+			logger.WithError(err).WithField("plugin", path).Error("failed to load PCG plugin")
+			continue
+		}
+		if err := plugin.Register(pcgManager.GetRegistry()); err != nil {
+			logger.WithError(err).WithField("plugin", path).Error("failed to register PCG plugin generators")
+			plugin.Close()
+			continue
+		}
+		loaded = append(loaded, plugin)
+	}
+	return loaded
+}
+
+// closePCGPlugins shuts down the loaded PCG plugins.
+func (s *RPCServer) closePCGPlugins() {
+	for _, plugin := range s.pcgPlugins {
+		if err := plugin.Close(); err != nil {
+			logrus.WithError(err).WithField("plugin", plugin.Name()).Warn("failed to close PCG plugin")
+		}
+	}
+	s.pcgPlugins = nil
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingGenerator is a registry generator that returns the parameters
+// it was called with
+type recordingGenerator struct{}
+
+func (recordingGenerator) Generate(ctx context.Context, params pcg.GenerationParams) (interface{}, error) {
+	return map[string]interface{}{"difficulty": params.Difficulty, "constraints": params.Constraints}, nil
+}
+func (recordingGenerator) GetType() pcg.ContentType                   { return pcg.ContentTypeNPCs }
+func (recordingGenerator) GetVersion() string                         { return "1.0.0" }
+func (recordingGenerator) Validate(params pcg.GenerationParams) error { return nil }
+
+func TestGenerateContent_NamedGenerator(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+	require.NoError(t, server.pcgManager.GetRegistry().RegisterGenerator("plugin_npcs", recordingGenerator{}))
+
+	result, err := server.handleGenerateContent(json.RawMessage(`{"session_id":"test-session-001","content_type":"npcs","location_id":"town","difficulty":4,"generator":"plugin_npcs","constraints":{"role":"smith"}}`))
+	require.NoError(t, err)
+	content := result.(map[string]interface{})["content"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"role": "smith"}, content["constraints"])
+
+	_, err = server.handleGenerateContent(json.RawMessage(`{"session_id":"test-session-001","content_type":"npcs","location_id":"town","generator":"missing"}`))
+	assert.Error(t, err)
+}
+
+func TestLoadPCGPlugins_SkipsBrokenPlugins(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	cfg := &config.Config{PCGPlugins: []string{"/nonexistent/plugin"}}
+
+	loaded := loadPCGPlugins(server.pcgManager, cfg, logrus.NewEntry(logrus.StandardLogger()))
+	assert.Empty(t, loaded)
+}
@@ -2,15 +2,19 @@ package server
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"goldbox-rpg/pkg/config"
 	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
 	"goldbox-rpg/pkg/persistence"
 )
 
@@ -198,14 +202,9 @@ func TestPersistenceWithConfig(t *testing.T) {
 	require.NoError(t, err)
 	defer os.RemoveAll(tmpDir)
 
-	os.Setenv("DATA_DIR", tmpDir)
-	os.Setenv("ENABLE_PERSISTENCE", "true")
-	os.Setenv("AUTO_SAVE_INTERVAL", "1s")
-	defer func() {
-		os.Unsetenv("DATA_DIR")
-		os.Unsetenv("ENABLE_PERSISTENCE")
-		os.Unsetenv("AUTO_SAVE_INTERVAL")
-	}()
+	t.Setenv("DATA_DIR", tmpDir)
+	t.Setenv("ENABLE_PERSISTENCE", "true")
+	t.Setenv("AUTO_SAVE_INTERVAL", "1s")
 
 	cfg, err := config.Load()
 	require.NoError(t, err)
@@ -214,3 +213,56 @@ func TestPersistenceWithConfig(t *testing.T) {
 	assert.True(t, cfg.EnablePersistence)
 	assert.Equal(t, 1*time.Second, cfg.AutoSaveInterval)
 }
+
+// TestPersistState_SavesPCGStateWithGameState verifies that the world,
+// sessions and PCG seed state are saved together and the seeds restored.
+func TestPersistState_SavesPCGStateWithGameState(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	store := persistence.NewMemoryStore()
+	server.store = store
+
+	seeds := server.pcgManager.GetSeedManager()
+	seeds.LoadState(pcg.SaveableState{BaseSeed: 1234})
+	forestSeed := seeds.DeriveContextSeed(pcg.ContentTypeTerrain, "forest")
+
+	require.NoError(t, server.persistState())
+	assert.True(t, store.Exists(gameStateKey))
+	assert.True(t, store.Exists(pcgStateKey))
+
+	// A restarted server picks the saved seeds back up
+	seeds.LoadState(pcg.SaveableState{BaseSeed: 99})
+	server.restorePCGState()
+	assert.Equal(t, int64(1234), seeds.GetBaseSeed())
+	assert.Equal(t, forestSeed, seeds.DeriveContextSeed(pcg.ContentTypeTerrain, "forest"))
+}
+
+// TestInitializePersistence_SealedSaves verifies that saves are sealed with
+// the configured secret and that a tampered save stops startup instead of
+// being replaced by a fresh game.
+func TestInitializePersistence_SealedSaves(t *testing.T) {
+	cfg := &config.Config{
+		DataDir:            t.TempDir(),
+		PersistenceBackend: "file",
+		PersistenceMode:    PersistenceModeSnapshot,
+		PersistenceSecret:  "current-secret-0123456789",
+		PersistenceEncrypt: true,
+	}
+	logger := logrus.WithField("test", t.Name())
+
+	server := createTestServerForHandlers(t)
+	require.NoError(t, initializePersistence(server, cfg, logger))
+	require.NoError(t, server.state.SaveToFile(server.store))
+
+	path := filepath.Join(cfg.DataDir, gameStateKey)
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, persistence.IsSealed(raw))
+
+	restarted := createTestServerForHandlers(t)
+	require.NoError(t, initializePersistence(restarted, cfg, logger), "the sealed save loads with the same secret")
+
+	raw[len(raw)-5] ^= 0x01
+	require.NoError(t, os.WriteFile(path, raw, 0o644))
+	err = initializePersistence(createTestServerForHandlers(t), cfg, logger)
+	assert.True(t, errors.Is(err, persistence.ErrTampered))
+}
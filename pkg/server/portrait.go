@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// portraitCharacter returns the character behind a world object, or nil
+// for objects without a portrait such as items.
+func portraitCharacter(obj game.GameObject) *game.Character {
+	switch entity := obj.(type) {
+	case *game.Player:
+		return &entity.Character
+	case *game.NPC:
+		return &entity.Character
+	case *game.Character:
+		return entity
+	}
+	return nil
+}
+
+// characterPortrait generates a character's portrait again from its seed,
+// so its silhouette follows the gear the character now has, and stores it
+// on the character. Characters without a portrait, such as those created
+// before portraits existed, get one seeded from the world seed and their
+// ID.
+func (s *RPCServer) characterPortrait(character *game.Character) *game.Portrait {
+	var seed int64
+	if existing := character.GetPortrait(); existing != nil {
+		seed = existing.Seed
+	} else if s.pcgManager != nil {
+		seed = s.pcgManager.GetSeedManager().DeriveContextSeed(pcg.ContentTypeCharacters, "portrait:"+character.GetID())
+	}
+
+	portrait := pcg.GeneratePortrait(seed, character)
+	character.SetPortrait(portrait)
+	return portrait
+}
+
+// handleGetPortrait returns the portrait and token descriptors of a
+// character, which clients use to draw the same avatar for it every time.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The requesting session
+//   - entity_id: string - The character to describe (optional, defaults to
+//     the session's player)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - entity_id: The described character
+//   - portrait: The game.Portrait
+//   - error: Invalid parameters or session, or an unknown entity or one
+//     that is not a character
+func (s *RPCServer) handleGetPortrait(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleGetPortrait",
+	})
+	logger.Debug("entering handleGetPortrait")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		EntityID  string `json:"entity_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid portrait parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.adminTarget(session, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	character := portraitCharacter(entity)
+	if character == nil {
+		return nil, ErrInvalidTarget.WithMessage("%s has no portrait", entity.GetID())
+	}
+
+	return map[string]interface{}{
+		"success":   true,
+		"entity_id": entity.GetID(),
+		"portrait":  s.characterPortrait(character),
+	}, nil
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getPortrait calls handleGetPortrait for an entity of the test session.
+func getPortrait(t *testing.T, server *RPCServer, entityID string) (*game.Portrait, error) {
+	t.Helper()
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id": "test-session-001",
+		"entity_id":  entityID,
+	})
+	require.NoError(t, err)
+	result, err := server.handleGetPortrait(params)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]interface{})["portrait"].(*game.Portrait), nil
+}
+
+func TestHandleGetPortrait(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	// A player without a portrait gets one seeded from the world seed
+	portrait, err := getPortrait(t, server, "")
+	require.NoError(t, err)
+	assert.Equal(t, portrait, session.Player.GetPortrait())
+	assert.Empty(t, portrait.Silhouette)
+
+	// It is kept, with the silhouette following the player's gear
+	require.NoError(t, session.Player.AddItemToInventory(game.Item{ID: "bow-1", Name: "Bow", Type: "weapon"}))
+	again, err := getPortrait(t, server, session.Player.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, portrait.Seed, again.Seed)
+	assert.Equal(t, portrait.Palette, again.Palette)
+	assert.Equal(t, []string{"bow"}, again.Silhouette)
+
+	npc := &game.NPC{Character: game.Character{ID: "npc-portrait", Class: game.ClassMage}}
+	npc.SetPortrait(&game.Portrait{Seed: 5})
+	server.state.WorldState.Objects[npc.ID] = npc
+	npcPortrait, err := getPortrait(t, server, npc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), npcPortrait.Seed)
+	assert.Equal(t, "robed_caster", npcPortrait.Archetype)
+
+	_, err = getPortrait(t, server, "nobody")
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+}
+
+func TestHandleCreateCharacter_Portrait(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	params := json.RawMessage(`{"name":"Painted","class":"thief","attribute_method":"standard","seed":11}`)
+
+	result, err := server.handleCreateCharacter(params)
+	require.NoError(t, err)
+	resultMap := result.(map[string]interface{})
+	portrait := resultMap["portrait"].(*game.Portrait)
+	assert.Equal(t, int64(11), portrait.Seed)
+	assert.Equal(t, "rogue", portrait.Archetype)
+	assert.Equal(t, portrait, resultMap["player"].(*game.Player).GetPortrait())
+
+	// The same seed paints the same portrait
+	result, err = createTestServerForHandlers(t).handleCreateCharacter(params)
+	require.NoError(t, err)
+	assert.Equal(t, portrait, result.(map[string]interface{})["portrait"])
+}
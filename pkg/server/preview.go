@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// contentPreview is generated content held back from the world until the
+// session that generated it commits it.
+type contentPreview struct {
+	ID          string
+	SessionID   string
+	ContentType pcg.ContentType
+	LocationID  string
+	Content     interface{}
+	ExpiresAt   time.Time
+}
+
+// previewCache holds content generated in preview mode, keyed by preview ID.
+// The zero value is ready to use. Expired previews are dropped whenever the
+// cache is accessed.
+type previewCache struct {
+	mu       sync.Mutex
+	previews map[string]*contentPreview
+}
+
+// put stores preview, assigning its ID and expiry.
+func (c *previewCache) put(preview *contentPreview, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purgeExpiredLocked(time.Now())
+	if c.previews == nil {
+		c.previews = make(map[string]*contentPreview)
+	}
+	preview.ID = uuid.New().String()
+	preview.ExpiresAt = time.Now().Add(ttl)
+	c.previews[preview.ID] = preview
+}
+
+// take removes and returns the preview with id if it belongs to sessionID
+// and has not expired.
+func (c *previewCache) take(id, sessionID string) (*contentPreview, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purgeExpiredLocked(time.Now())
+	preview, ok := c.previews[id]
+	if !ok || preview.SessionID != sessionID {
+		return nil, false
+	}
+	delete(c.previews, id)
+	return preview, true
+}
+
+// purgeExpiredLocked drops previews whose TTL has passed. The caller must
+// hold c.mu.
+func (c *previewCache) purgeExpiredLocked(now time.Time) {
+	for id, preview := range c.previews {
+		if now.After(preview.ExpiresAt) {
+			delete(c.previews, id)
+		}
+	}
+}
+
+// previewGeneratedContent caches generated content instead of handing it to
+// the caller as final, and builds the generateContent preview response.
+func (s *RPCServer) previewGeneratedContent(sessionID string, contentType pcg.ContentType, locationID string, content interface{}) map[string]interface{} {
+	preview := &contentPreview{
+		SessionID:   sessionID,
+		ContentType: contentType,
+		LocationID:  locationID,
+		Content:     content,
+	}
+	s.previews.put(preview, ContentPreviewTTL)
+
+	logrus.WithFields(logrus.Fields{
+		"function":    "previewGeneratedContent",
+		"sessionID":   sessionID,
+		"previewID":   preview.ID,
+		"contentType": contentType,
+		"locationID":  locationID,
+	}).Info("generated content held for preview")
+
+	return map[string]interface{}{
+		"success":      true,
+		"preview":      true,
+		"preview_id":   preview.ID,
+		"expires_at":   preview.ExpiresAt,
+		"content_type": string(contentType),
+		"location_id":  locationID,
+		"content":      content,
+	}
+}
+
+// handleCommitGeneratedContent integrates content previewed with
+// generateContent into the world. Levels, terrain and items are added to the
+// world transactionally; quests are started in the committing player's
+// quest log. A preview can be committed once, only by the session that
+// generated it, and only until ContentPreviewTTL has passed.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session that generated the preview
+//   - preview_id: string - The preview_id returned by generateContent
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating the content was integrated
+//   - preview_id, content_type, location_id: The committed preview
+//   - error: Invalid parameters or session, an unknown or expired preview,
+//     or content that fails validation on integration
+func (s *RPCServer) handleCommitGeneratedContent(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleCommitGeneratedContent",
+	})
+	logger.Debug("entering handleCommitGeneratedContent")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		PreviewID string `json:"preview_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid commit parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview, ok := s.previews.take(req.PreviewID, req.SessionID)
+	if !ok {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Unknown or expired preview", req.PreviewID)
+	}
+
+	if err := s.integratePreview(session, preview); err != nil {
+		logger.WithError(err).WithField("previewID", preview.ID).Warn("preview commit failed")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to commit generated content", err.Error())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"sessionID":   req.SessionID,
+		"previewID":   preview.ID,
+		"contentType": preview.ContentType,
+		"locationID":  preview.LocationID,
+	}).Info("previewed content committed")
+	s.notifyWorldGenerated(preview.ContentType, preview.LocationID)
+
+	return map[string]interface{}{
+		"success":      true,
+		"preview_id":   preview.ID,
+		"content_type": string(preview.ContentType),
+		"location_id":  preview.LocationID,
+	}, nil
+}
+
+// integratePreview applies previewed content to the live world, or to the
+// session's player for quests. Committed levels get merchants in their shop
+// rooms.
+func (s *RPCServer) integratePreview(session *PlayerSession, preview *contentPreview) error {
+	if quest, ok := preview.Content.(*game.Quest); ok {
+		if session.Player == nil {
+			return ErrNoPlayer.WithMessage("session has no player to give the quest to")
+		}
+		return session.Player.StartQuest(*quest)
+	}
+
+	return s.integrateContent(preview.LocationID, preview.Content)
+}
+
+// integrateContent commits generated terrain, levels or items into the
+// live world at locationID, opens the merchants of committed levels and
+// places their doors, keys and interactive features.
+func (s *RPCServer) integrateContent(locationID string, content interface{}) error {
+	ix := s.pcgManager.BeginWorldIntegration(context.Background(), s.state.WorldState, locationID)
+	defer ix.Rollback()
+
+	if err := ix.Stage(content); err != nil {
+		return err
+	}
+	if err := ix.Commit(); err != nil {
+		return err
+	}
+
+	if level, ok := content.(*game.Level); ok {
+		s.stockLevelMerchants(level)
+		s.placeLevelDoors(level)
+		s.placeLevelFeatures(level)
+	}
+	return nil
+}
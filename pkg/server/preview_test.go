@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generatePreview calls generateContent in preview mode and returns the response.
+func generatePreview(t *testing.T, server *RPCServer, sessionID, contentType string) map[string]interface{} {
+	t.Helper()
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id":   sessionID,
+		"content_type": contentType,
+		"location_id":  "preview_location",
+		"preview":      true,
+	})
+	require.NoError(t, err)
+
+	result, err := server.handleGenerateContent(params)
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	require.Equal(t, true, response["preview"])
+	require.NotNil(t, response["content"], "previews include the generated content for inspection")
+	return response
+}
+
+func commitPreview(server *RPCServer, sessionID, previewID string) (interface{}, error) {
+	return server.handleCommitGeneratedContent(json.RawMessage(`{"session_id":"` + sessionID + `","preview_id":"` + previewID + `"}`))
+}
+
+func TestCommitGeneratedContent_IntegratesPreviewedLevel(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	levelsBefore := len(server.state.WorldState.Levels)
+
+	preview := generatePreview(t, server, session.SessionID, "levels")
+	previewID := preview["preview_id"].(string)
+	assert.Len(t, server.state.WorldState.Levels, levelsBefore, "previewing does not touch the world")
+
+	result, err := commitPreview(server, session.SessionID, previewID)
+	require.NoError(t, err)
+	assert.Equal(t, "levels", result.(map[string]interface{})["content_type"])
+	assert.Len(t, server.state.WorldState.Levels, levelsBefore+1)
+
+	_, err = commitPreview(server, session.SessionID, previewID)
+	assert.Error(t, err, "a preview can only be committed once")
+}
+
+func TestCommitGeneratedContent_StartsPreviewedQuest(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	preview := generatePreview(t, server, session.SessionID, "quests")
+	_, err := commitPreview(server, session.SessionID, preview["preview_id"].(string))
+	require.NoError(t, err)
+	assert.Len(t, session.Player.GetActiveQuests(), 1)
+}
+
+func TestCommitGeneratedContent_RejectsForeignAndExpiredPreviews(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	other := &PlayerSession{SessionID: "other-session", Player: session.Player, LastActive: time.Now()}
+	server.setSession(other.SessionID, other)
+
+	preview := generatePreview(t, server, session.SessionID, "items")
+	previewID := preview["preview_id"].(string)
+
+	_, err := commitPreview(server, other.SessionID, previewID)
+	assert.Error(t, err, "only the generating session can commit")
+
+	server.previews.mu.Lock()
+	server.previews.previews[previewID].ExpiresAt = time.Now().Add(-time.Second)
+	server.previews.mu.Unlock()
+
+	_, err = commitPreview(server, session.SessionID, previewID)
+	assert.Error(t, err, "expired previews cannot be committed")
+}
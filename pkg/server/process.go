@@ -45,7 +45,7 @@ func (gs *GameState) processEffectTick(effect *game.Effect) error {
 			"effectID": effect.ID,
 			"type":     effect.Type,
 		}).Warn("unsupported effect type")
-		return fmt.Errorf("unsupported effect type: %v", effect.Type)
+		return NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("unsupported effect type: %v", effect.Type), nil)
 	}
 }
 
@@ -55,7 +55,7 @@ func (gs *GameState) validateEffectNotNil(effect *game.Effect) error {
 		logrus.WithFields(logrus.Fields{
 			"function": "processEffectTick",
 		}).Error("nil effect provided")
-		return fmt.Errorf("effect is nil")
+		return NewJSONRPCError(JSONRPCInvalidParams, "effect is nil", nil)
 	}
 	return nil
 }
@@ -128,7 +128,7 @@ func (gs *GameState) processDamageEffect(effect *game.Effect) error {
 	target, exists := gs.WorldState.Objects[effect.TargetID]
 	if !exists {
 		logger.WithField("targetID", effect.TargetID).Error("invalid effect target")
-		return fmt.Errorf("invalid effect target")
+		return ErrInvalidTarget.WithMessage("invalid effect target")
 	}
 
 	if char, ok := target.(*game.Character); ok {
@@ -151,7 +151,7 @@ func (gs *GameState) processDamageEffect(effect *game.Effect) error {
 	}
 
 	logger.WithField("targetID", effect.TargetID).Error("target cannot receive damage")
-	return fmt.Errorf("target cannot receive damage")
+	return ErrInvalidTarget.WithMessage("target cannot receive damage")
 }
 
 // processHealEffect applies a healing effect to a target character in the game world.
@@ -198,7 +198,7 @@ func (gs *GameState) processHealEffect(effect *game.Effect) error {
 	target, exists := gs.WorldState.Objects[effect.TargetID]
 	if !exists {
 		logger.WithField("targetID", effect.TargetID).Error("invalid effect target")
-		return fmt.Errorf("invalid effect target")
+		return ErrInvalidTarget.WithMessage("invalid effect target")
 	}
 
 	if char, ok := target.(*game.Character); ok {
@@ -215,7 +215,7 @@ func (gs *GameState) processHealEffect(effect *game.Effect) error {
 	}
 
 	logger.WithField("targetID", effect.TargetID).Error("target cannot be healed")
-	return fmt.Errorf("target cannot be healed")
+	return ErrInvalidTarget.WithMessage("target cannot be healed")
 }
 
 // ProcessStatEffect applies a stat modification effect to a character target.
@@ -260,7 +260,7 @@ func (gs *GameState) processStatEffect(effect *game.Effect) error {
 	target, exists := gs.WorldState.Objects[effect.TargetID]
 	if !exists {
 		logger.WithField("targetID", effect.TargetID).Error("invalid effect target")
-		return fmt.Errorf("invalid effect target")
+		return ErrInvalidTarget.WithMessage("invalid effect target")
 	}
 
 	if char, ok := target.(*game.Character); ok {
@@ -287,11 +287,11 @@ func (gs *GameState) processStatEffect(effect *game.Effect) error {
 			char.Charisma += magnitude
 		default:
 			logger.WithField("stat", effect.StatAffected).Error("unknown stat type")
-			return fmt.Errorf("unknown stat type: %s", effect.StatAffected)
+			return NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("unknown stat type: %s", effect.StatAffected), nil)
 		}
 		return nil
 	}
 
 	logger.WithField("targetID", effect.TargetID).Error("target cannot receive stat effects")
-	return fmt.Errorf("target cannot receive stat effects")
+	return ErrInvalidTarget.WithMessage("target cannot receive stat effects")
 }
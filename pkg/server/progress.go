@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/pcg"
+)
+
+// GenerationProgressMessage is the "type" of WebSocket messages that relay
+// procedural generation progress to the session that requested it.
+const GenerationProgressMessage = "generation_progress"
+
+// minProgressStep is the smallest percentage change within a stage that is
+// relayed to clients; smaller steps are dropped to limit WebSocket traffic.
+const minProgressStep = 1.0
+
+// withGenerationProgress returns a context whose PCG progress updates are
+// sent to sessionID over its WebSocket connection. Sessions without a
+// WebSocket connection simply receive the final RPC response.
+//
+// Parameters:
+//   - ctx: The generation context to extend
+//   - sessionID: The session that requested the generation
+//   - locationID: The location being generated, echoed to the client
+func (s *RPCServer) withGenerationProgress(ctx context.Context, sessionID, locationID string) context.Context {
+	if sessionID == "" || s.broadcaster == nil {
+		return ctx
+	}
+
+	var (
+		mu   sync.Mutex
+		last pcg.ProgressUpdate
+		sent bool
+	)
+
+	return pcg.WithProgress(ctx, func(update pcg.ProgressUpdate) {
+		mu.Lock()
+		skip := sent && update.Stage == last.Stage && update.Percent-last.Percent < minProgressStep &&
+			update.Stage != pcg.ProgressStageComplete
+		if !skip {
+			last, sent = update, true
+		}
+		mu.Unlock()
+
+		if skip {
+			return
+		}
+
+		s.broadcaster.sendToSession(sessionID, map[string]interface{}{
+			"type":         GenerationProgressMessage,
+			"content_type": update.ContentType,
+			"location_id":  locationID,
+			"stage":        update.Stage,
+			"percent":      update.Percent,
+			"timestamp":    time.Now(),
+		})
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectProgressTestSession registers a session whose server-side WebSocket
+// connection is backed by a real client connection, which is returned.
+func connectProgressTestSession(t *testing.T, server *RPCServer, sessionID string) *websocket.Conn {
+	t.Helper()
+
+	serverConns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		serverConns <- conn
+	}))
+	t.Cleanup(endpoint.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(endpoint.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	server.mu.Lock()
+	server.sessions[sessionID] = &PlayerSession{
+		SessionID:   sessionID,
+		Player:      &game.Player{Character: game.Character{ID: sessionID, Name: sessionID}},
+		Connected:   true,
+		LastActive:  time.Now(),
+		WSConn:      <-serverConns,
+		MessageChan: make(chan []byte, MessageChanBufferSize),
+	}
+	server.mu.Unlock()
+
+	return client
+}
+
+func TestGenerationProgressRelayedToSession(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	client := connectProgressTestSession(t, server, "builder")
+
+	_, err := server.handleGenerateLevel(json.RawMessage(`{"session_id":"builder","room_count":8}`))
+	require.NoError(t, err)
+
+	var stages []string
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		var message map[string]interface{}
+		require.NoError(t, client.ReadJSON(&message))
+		if message["type"] != GenerationProgressMessage {
+			continue
+		}
+		assert.Equal(t, "levels", message["content_type"])
+		assert.Equal(t, "generated_level", message["location_id"])
+		stages = append(stages, message["stage"].(string))
+		if message["stage"] == pcg.ProgressStageComplete {
+			assert.Equal(t, 100.0, message["percent"])
+			break
+		}
+	}
+	assert.Equal(t, []string{"room_layout", "rooms", "corridors", "special_features", "validation", "conversion", "complete"}, stages)
+}
+
+func TestWithGenerationProgress_ThrottlesSmallSteps(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	client := connectProgressTestSession(t, server, "builder")
+
+	params := pcg.GenerationParams{Progress: pcg.ProgressFromContext(
+		server.withGenerationProgress(context.Background(), "builder", "forest"))}
+	params.ReportProgress(pcg.ContentTypeTerrain, "cellular_automata", 10)
+	params.ReportProgress(pcg.ContentTypeTerrain, "cellular_automata", 10.4)
+	params.ReportProgress(pcg.ContentTypeTerrain, "cellular_automata", 12)
+	params.ReportProgress(pcg.ContentTypeTerrain, pcg.ProgressStageComplete, 100)
+
+	var percents []float64
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for len(percents) < 3 {
+		var message map[string]interface{}
+		require.NoError(t, client.ReadJSON(&message))
+		percents = append(percents, message["percent"].(float64))
+	}
+	assert.Equal(t, []float64{10, 12, 100}, percents)
+
+	// Requests without a session do not carry a progress callback
+	assert.Nil(t, pcg.ProgressFromContext(server.withGenerationProgress(context.Background(), "", "forest")))
+}
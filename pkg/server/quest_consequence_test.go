@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startConsequenceTestQuest gives the session's player a finished quest
+// with consequences and adds the NPC "elder" to the world.
+func startConsequenceTestQuest(t *testing.T, server *RPCServer, session *PlayerSession, consequences ...game.QuestConsequence) {
+	t.Helper()
+	server.state.WorldState.NPCs["elder"] = &game.NPC{Character: game.Character{ID: "elder", Name: "Elder", HP: 8, MaxHP: 8}}
+	require.NoError(t, session.Player.StartQuest(game.Quest{
+		ID:           "defend",
+		Objectives:   []game.QuestObjective{{Description: "Hold the gate", Required: 1, Progress: 1, Completed: true}},
+		Consequences: consequences,
+	}))
+}
+
+func TestHandleCompleteQuest_AppliesConsequences(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	startConsequenceTestQuest(t, server, session,
+		game.QuestConsequence{Type: game.ConsequenceReputation, On: game.QuestCompleted, Target: "city_watch", Amount: 10},
+		game.QuestConsequence{Type: game.ConsequenceUnlockQuest, On: game.QuestCompleted, FollowUp: &game.Quest{ID: "pursue"}},
+		game.QuestConsequence{Type: game.ConsequenceNPCDeath, On: game.QuestFailed, Target: "elder"},
+	)
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "quest_id": "defend"})
+	result, err := server.handleCompleteQuest(params)
+	require.NoError(t, err)
+
+	consequences := result.(map[string]interface{})["consequences"].([]game.QuestConsequence)
+	assert.Len(t, consequences, 2)
+	assert.Equal(t, 10, session.Player.GetReputation("city_watch"))
+	_, err = session.Player.GetQuest("pursue")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, server.state.WorldState.NPCs["elder"].GetHealth())
+}
+
+func TestHandleFailQuest_KillsNPCAndLocksArea(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	startConsequenceTestQuest(t, server, session,
+		game.QuestConsequence{Type: game.ConsequenceNPCDeath, On: game.QuestFailed, Target: "elder"},
+		game.QuestConsequence{Type: game.ConsequenceAreaLockout, On: game.QuestFailed, Target: "old_crypt"},
+	)
+
+	deaths := make(chan game.GameEvent, 1)
+	server.eventSys.Subscribe(game.EventDeath, func(event game.GameEvent) { deaths <- event })
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "quest_id": "defend"})
+	result, err := server.handleFailQuest(params)
+	require.NoError(t, err)
+
+	assert.Len(t, result.(map[string]interface{})["consequences"], 2)
+	assert.Equal(t, 0, server.state.WorldState.NPCs["elder"].GetHealth())
+	assert.True(t, server.state.WorldState.IsAreaLocked("old_crypt"))
+
+	select {
+	case event := <-deaths:
+		assert.Equal(t, "elder", event.SourceID)
+		assert.Equal(t, "quest_consequence", event.Data["cause"])
+	case <-time.After(time.Second):
+		t.Fatal("expected a death event for the elder")
+	}
+}
+
+func TestHandleFailQuest_RollsBackConsequencesOnError(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	startConsequenceTestQuest(t, server, session,
+		game.QuestConsequence{Type: game.ConsequenceAreaLockout, On: game.QuestFailed, Target: "old_crypt"},
+		game.QuestConsequence{Type: game.ConsequenceNPCDeath, On: game.QuestFailed, Target: "missing"},
+	)
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "quest_id": "defend"})
+	_, err := server.handleFailQuest(params)
+	require.Error(t, err)
+
+	assert.False(t, server.state.WorldState.IsAreaLocked("old_crypt"))
+	quest, err := session.Player.GetQuest("defend")
+	require.NoError(t, err)
+	assert.Equal(t, game.QuestActive, quest.Status)
+}
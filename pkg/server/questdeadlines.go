@@ -0,0 +1,63 @@
+package server
+
+import (
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// trackQuestDeadlines checks the time limits of a player's active quests
+// against the game clock, see game.Player.UpdateQuestDeadlines. Deadlines
+// drawing near are announced with EventQuestDeadlineWarning, and quests
+// past a deadline fail with their consequences.
+func (s *RPCServer) trackQuestDeadlines(player *game.Player) {
+	s.state.stateMu.RLock()
+	now := s.state.TimeManager.CurrentTime.GameTicks
+	s.state.stateMu.RUnlock()
+
+	for _, notice := range player.UpdateQuestDeadlines(now, TicksPerHour) {
+		if notice.Kind == game.DeadlineExpired {
+			s.expireQuestDeadline(player.GetID(), notice.QuestID)
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"function":  "trackQuestDeadlines",
+			"playerID":  player.GetID(),
+			"questID":   notice.QuestID,
+			"objective": notice.Objective,
+		}).Info("quest deadline approaching")
+
+		s.eventSys.Emit(game.GameEvent{
+			Type:     EventQuestDeadlineWarning,
+			SourceID: "calendar",
+			TargetID: player.GetID(),
+			Data: map[string]interface{}{
+				"quest_id":   notice.QuestID,
+				"objective":  notice.Objective,
+				"deadline":   notice.Deadline,
+				"date":       DateAt(notice.Deadline),
+				"hours_left": (notice.Deadline - now) / TicksPerHour,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// checkQuestDeadlines tracks the quest deadlines of every connected player.
+func (s *RPCServer) checkQuestDeadlines() {
+	s.mu.RLock()
+	players := make([]*game.Player, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if session.Player != nil {
+			players = append(players, session.Player)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, player := range players {
+		s.trackQuestDeadlines(player)
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestDeadlines(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	player := session.Player
+	start := server.state.TimeManager.CurrentTime.GameTicks
+
+	warnings := make(chan game.GameEvent, 1)
+	server.eventSys.Subscribe(EventQuestDeadlineWarning, func(event game.GameEvent) { warnings <- event })
+
+	quest := game.Quest{
+		ID:             "caravan",
+		Title:          "Guard the Caravan",
+		TimeLimitHours: 8,
+		Objectives:     []game.QuestObjective{{Description: "Reach the pass", Required: 1}},
+		Consequences: []game.QuestConsequence{
+			{Type: game.ConsequenceReputation, On: game.QuestFailed, Target: "merchants_guild", Amount: -10},
+		},
+	}
+	params, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "quest": quest})
+	require.NoError(t, err)
+	result, err := server.handleStartQuest(params)
+	require.NoError(t, err)
+	started := result.(map[string]interface{})
+	assert.Equal(t, start+8*TicksPerHour, started["deadline"], "the clock starts when the quest is accepted")
+	assert.Equal(t, DateAt(start+8*TicksPerHour), started["deadline_date"])
+
+	server.state.TimeManager.Skip(6 * TicksPerHour)
+	server.checkQuestDeadlines()
+	select {
+	case event := <-warnings:
+		assert.Equal(t, player.GetID(), event.TargetID)
+		assert.Equal(t, "caravan", event.Data["quest_id"])
+		assert.Equal(t, -1, event.Data["objective"])
+		assert.Equal(t, int64(2), event.Data["hours_left"])
+	case <-time.After(time.Second):
+		t.Fatal("no deadline warning")
+	}
+
+	server.state.TimeManager.Skip(2 * TicksPerHour)
+	server.checkQuestDeadlines()
+	failed, err := player.GetQuest("caravan")
+	require.NoError(t, err)
+	assert.Equal(t, game.QuestFailed, failed.Status)
+	assert.Equal(t, -10, player.GetReputation("merchants_guild"), "failure consequences apply")
+}
+
+func TestHandlePassTime_ObjectiveDeadline(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	player := session.Player
+
+	require.NoError(t, player.StartQuest(game.Quest{
+		ID: "ferry",
+		Objectives: []game.QuestObjective{
+			{Description: "Catch the ferry", Required: 1, TimeLimitHours: 3},
+			{Description: "Cross the river", Required: 1},
+		},
+	}))
+
+	params, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "hours": 5})
+	require.NoError(t, err)
+	_, err = server.handlePassTime(params)
+	require.NoError(t, err)
+
+	quest, err := player.GetQuest("ferry")
+	require.NoError(t, err)
+	assert.Equal(t, game.QuestFailed, quest.Status, "missing a timed objective fails the quest")
+}
@@ -95,6 +95,20 @@ func (rl *RateLimiter) Allow(ip string) bool {
 	return entry.limiter.Allow()
 }
 
+// SetLimits applies the rate limiting settings of a reloaded configuration
+// to new and existing client limiters.
+func (rl *RateLimiter) SetLimits(cfg *config.Config) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.requestsPerSecond = rate.Limit(cfg.RateLimitRequestsPerSecond)
+	rl.burst = cfg.RateLimitBurst
+	for _, entry := range rl.limiters {
+		entry.limiter.SetLimit(rl.requestsPerSecond)
+		entry.limiter.SetBurst(rl.burst)
+	}
+}
+
 // cleanupLoop runs in the background to remove expired rate limiters.
 // This prevents memory leaks from clients that stop making requests.
 func (rl *RateLimiter) cleanupLoop() {
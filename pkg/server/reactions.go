@@ -0,0 +1,526 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultReactionTimeout is how long a reaction owner has to answer a
+// reaction prompt before it is treated as declined.
+var DefaultReactionTimeout = 5 * time.Second
+
+// ReactionType identifies a kind of held reaction.
+type ReactionType string
+
+const (
+	// ReactionAttackOfOpportunity strikes an enemy leaving the owner's reach.
+	ReactionAttackOfOpportunity ReactionType = "attack_of_opportunity"
+	// ReactionShieldBlock halves the damage of an attack against the owner.
+	ReactionShieldBlock ReactionType = "shield_block"
+	// ReactionCounterspell prevents another entity's spell from taking effect.
+	ReactionCounterspell ReactionType = "counterspell"
+)
+
+// ReactionTrigger identifies the action that can set off a reaction.
+type ReactionTrigger string
+
+const (
+	// TriggerLeaveReach fires when an entity moves out of an adjacent square.
+	TriggerLeaveReach ReactionTrigger = "leave_reach"
+	// TriggerAttacked fires when the reaction owner is targeted by an attack.
+	TriggerAttacked ReactionTrigger = "attacked"
+	// TriggerSpellCast fires when another entity casts a spell.
+	TriggerSpellCast ReactionTrigger = "spell_cast"
+)
+
+// reactionDefinition describes the trigger and resolution priority of a
+// reaction type. Higher priorities resolve first.
+type reactionDefinition struct {
+	trigger  ReactionTrigger
+	priority int
+}
+
+// reactionDefinitions lists the supported reaction types.
+var reactionDefinitions = map[ReactionType]reactionDefinition{
+	ReactionCounterspell:        {trigger: TriggerSpellCast, priority: 30},
+	ReactionShieldBlock:         {trigger: TriggerAttacked, priority: 20},
+	ReactionAttackOfOpportunity: {trigger: TriggerLeaveReach, priority: 10},
+}
+
+// Reaction is a response an entity holds in readiness during combat. It is
+// triggered by another entity's action and consumed when used; each entity
+// may react at most once per round.
+type Reaction struct {
+	ID       string          `yaml:"reaction_id" json:"reaction_id"`
+	OwnerID  string          `yaml:"reaction_owner_id" json:"owner_id"`
+	Type     ReactionType    `yaml:"reaction_type" json:"type"`
+	Trigger  ReactionTrigger `yaml:"reaction_trigger" json:"trigger"`
+	Priority int             `yaml:"reaction_priority" json:"priority"`
+	Round    int             `yaml:"reaction_round" json:"round"`
+}
+
+// ReactionEvent describes an action that may trigger held reactions.
+type ReactionEvent struct {
+	Trigger  ReactionTrigger `json:"trigger"`
+	ActorID  string          `json:"actor_id"`
+	TargetID string          `json:"target_id,omitempty"`
+	SpellID  string          `json:"spell_id,omitempty"`
+	From     game.Position   `json:"from"`
+	To       game.Position   `json:"to"`
+}
+
+// ReactionPrompt is an outstanding offer to a reaction owner to use a held
+// reaction. The owner accepts or declines with respondReaction; if no answer
+// arrives before Deadline the reaction is declined.
+type ReactionPrompt struct {
+	ID       string        `json:"prompt_id"`
+	Reaction Reaction      `json:"reaction"`
+	Event    ReactionEvent `json:"event"`
+	Deadline time.Time     `json:"deadline"`
+	response chan bool
+}
+
+// ReactionOutcome reports how a single reaction prompt was resolved.
+type ReactionOutcome struct {
+	PromptID   string       `json:"prompt_id"`
+	ReactionID string       `json:"reaction_id"`
+	OwnerID    string       `json:"owner_id"`
+	Type       ReactionType `json:"type"`
+	Accepted   bool         `json:"accepted"`
+	Damage     int          `json:"damage,omitempty"`
+}
+
+// SetReactionTimeout overrides how long reaction prompts wait for an answer.
+func (tm *TurnManager) SetReactionTimeout(timeout time.Duration) {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+	tm.reactionTimeout = timeout
+}
+
+// RegisterReaction holds a reaction of the given type for ownerID. The owner
+// must be in the current combat and may hold only one reaction of each type.
+func (tm *TurnManager) RegisterReaction(ownerID string, reactionType ReactionType) (Reaction, error) {
+	def, ok := reactionDefinitions[reactionType]
+	if !ok {
+		return Reaction{}, NewJSONRPCError(JSONRPCInvalidParams, fmt.Sprintf("unknown reaction type: %s", reactionType), nil)
+	}
+	if !tm.IsInCombat {
+		return Reaction{}, ErrNotInCombat
+	}
+	if tm.initiativeIndex(ownerID) < 0 {
+		return Reaction{}, ErrNotInCombat.WithMessage("entity %s is not in combat", ownerID)
+	}
+
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	for _, held := range tm.Reactions[ownerID] {
+		if held.Type == reactionType {
+			return Reaction{}, ErrNoActionAvailable.WithMessage("reaction %s already held", reactionType)
+		}
+	}
+
+	reaction := Reaction{
+		ID:       uuid.New().String(),
+		OwnerID:  ownerID,
+		Type:     reactionType,
+		Trigger:  def.trigger,
+		Priority: def.priority,
+		Round:    tm.CurrentRound,
+	}
+	if tm.Reactions == nil {
+		tm.Reactions = make(map[string][]Reaction)
+	}
+	tm.Reactions[ownerID] = append(tm.Reactions[ownerID], reaction)
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "RegisterReaction",
+		"ownerID":      ownerID,
+		"reactionType": reactionType,
+		"reactionID":   reaction.ID,
+	}).Info("reaction registered")
+
+	return reaction, nil
+}
+
+// CancelReaction drops a held reaction without using it.
+func (tm *TurnManager) CancelReaction(ownerID, reactionID string) error {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	if !tm.removeReactionLocked(ownerID, reactionID) {
+		return ErrReactionNotFound.WithMessage("reaction not found: %s", reactionID).WithData(map[string]interface{}{"reaction_id": reactionID})
+	}
+	return nil
+}
+
+// GetReactions returns the reactions currently held by ownerID.
+func (tm *TurnManager) GetReactions(ownerID string) []Reaction {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+	return append([]Reaction(nil), tm.Reactions[ownerID]...)
+}
+
+// CollectReactions returns the held reactions triggered by event in the order
+// they resolve: highest priority first, then initiative order, then reaction
+// ID. The acting entity never reacts to its own action, and entities that
+// have already reacted this round are skipped. positionOf looks up entity
+// positions for reach-based triggers.
+func (tm *TurnManager) CollectReactions(event ReactionEvent, positionOf func(id string) (game.Position, bool)) []Reaction {
+	if !tm.IsInCombat {
+		return nil
+	}
+
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	var triggered []Reaction
+	for ownerID, held := range tm.Reactions {
+		if ownerID == event.ActorID || tm.hasReactedLocked(ownerID) {
+			continue
+		}
+		for _, reaction := range held {
+			if reaction.Trigger == event.Trigger && tm.reactionApplies(reaction, event, positionOf) {
+				triggered = append(triggered, reaction)
+			}
+		}
+	}
+
+	sort.Slice(triggered, func(i, j int) bool {
+		a, b := triggered[i], triggered[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		ai, bi := tm.initiativeIndex(a.OwnerID), tm.initiativeIndex(b.OwnerID)
+		if ai != bi {
+			return ai < bi
+		}
+		return a.ID < b.ID
+	})
+
+	return triggered
+}
+
+// reactionApplies checks trigger-specific conditions for a reaction.
+func (tm *TurnManager) reactionApplies(reaction Reaction, event ReactionEvent, positionOf func(id string) (game.Position, bool)) bool {
+	switch reaction.Trigger {
+	case TriggerAttacked:
+		return reaction.OwnerID == event.TargetID
+	case TriggerLeaveReach:
+		if positionOf == nil {
+			return false
+		}
+		pos, ok := positionOf(reaction.OwnerID)
+		return ok && withinReach(pos, event.From) && !withinReach(pos, event.To)
+	case TriggerSpellCast:
+		return true
+	default:
+		return false
+	}
+}
+
+// ConsumeReaction removes a reaction that is being used and marks its owner
+// as having reacted this round.
+func (tm *TurnManager) ConsumeReaction(reaction Reaction) error {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	if tm.hasReactedLocked(reaction.OwnerID) {
+		return ErrNoActionAvailable.WithMessage("entity %s has already reacted this round", reaction.OwnerID)
+	}
+	if !tm.removeReactionLocked(reaction.OwnerID, reaction.ID) {
+		return ErrReactionNotFound.WithMessage("reaction not found: %s", reaction.ID).WithData(map[string]interface{}{"reaction_id": reaction.ID})
+	}
+
+	if tm.reactionsUsed == nil {
+		tm.reactionsUsed = make(map[string]int)
+	}
+	tm.reactionsUsed[reaction.OwnerID] = tm.CurrentRound
+	return nil
+}
+
+// OpenReactionPrompt records an outstanding prompt offering reaction to its
+// owner in response to event.
+func (tm *TurnManager) OpenReactionPrompt(reaction Reaction, event ReactionEvent) *ReactionPrompt {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	timeout := tm.reactionTimeout
+	if timeout <= 0 {
+		timeout = DefaultReactionTimeout
+	}
+
+	prompt := &ReactionPrompt{
+		ID:       uuid.New().String(),
+		Reaction: reaction,
+		Event:    event,
+		Deadline: time.Now().Add(timeout),
+		response: make(chan bool, 1),
+	}
+	if tm.reactionPrompts == nil {
+		tm.reactionPrompts = make(map[string]*ReactionPrompt)
+	}
+	tm.reactionPrompts[prompt.ID] = prompt
+	return prompt
+}
+
+// RespondToPrompt answers an outstanding prompt on behalf of ownerID.
+func (tm *TurnManager) RespondToPrompt(ownerID, promptID string, accept bool) error {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	prompt, exists := tm.reactionPrompts[promptID]
+	if !exists {
+		return ErrReactionNotFound.WithMessage("reaction prompt not found or expired: %s", promptID).WithData(map[string]interface{}{"prompt_id": promptID})
+	}
+	if prompt.Reaction.OwnerID != ownerID {
+		return ErrReactionNotFound.WithMessage("reaction prompt %s does not belong to %s", promptID, ownerID).WithData(map[string]interface{}{"prompt_id": promptID})
+	}
+
+	delete(tm.reactionPrompts, promptID)
+	prompt.response <- accept
+	return nil
+}
+
+// AwaitPrompt blocks until prompt is answered or its deadline passes and
+// reports whether the reaction was accepted.
+func (tm *TurnManager) AwaitPrompt(prompt *ReactionPrompt) bool {
+	timer := time.NewTimer(time.Until(prompt.Deadline))
+	defer timer.Stop()
+
+	select {
+	case accepted := <-prompt.response:
+		return accepted
+	case <-timer.C:
+	}
+
+	tm.reactionMu.Lock()
+	delete(tm.reactionPrompts, prompt.ID)
+	tm.reactionMu.Unlock()
+
+	// A response may have raced with the timeout.
+	select {
+	case accepted := <-prompt.response:
+		return accepted
+	default:
+		return false
+	}
+}
+
+// clearReactions drops all held reactions and declines outstanding prompts.
+func (tm *TurnManager) clearReactions() {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	for id, prompt := range tm.reactionPrompts {
+		prompt.response <- false
+		delete(tm.reactionPrompts, id)
+	}
+	tm.Reactions = nil
+	tm.reactionsUsed = nil
+	tm.opportunityCost = nil
+}
+
+// hasReactedLocked reports whether ownerID has reacted this round. Callers
+// must hold tm.reactionMu.
+func (tm *TurnManager) hasReactedLocked(ownerID string) bool {
+	round, used := tm.reactionsUsed[ownerID]
+	return used && round == tm.CurrentRound
+}
+
+// removeReactionLocked deletes a held reaction. Callers must hold tm.reactionMu.
+func (tm *TurnManager) removeReactionLocked(ownerID, reactionID string) bool {
+	held := tm.Reactions[ownerID]
+	for i, reaction := range held {
+		if reaction.ID == reactionID {
+			tm.Reactions[ownerID] = append(held[:i:i], held[i+1:]...)
+			if len(tm.Reactions[ownerID]) == 0 {
+				delete(tm.Reactions, ownerID)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// initiativeIndex returns entityID's position in the initiative order, or -1.
+func (tm *TurnManager) initiativeIndex(entityID string) int {
+	for i, id := range tm.Initiative {
+		if id == entityID {
+			return i
+		}
+	}
+	return -1
+}
+
+// withinReach reports whether two positions are on the same level and at
+// most one square apart.
+func withinReach(a, b game.Position) bool {
+	if a.Level != b.Level {
+		return false
+	}
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx >= -1 && dx <= 1 && dy >= -1 && dy <= 1
+}
+
+// resolveReactions prompts the owners of reactions triggered by event, in
+// resolution order, and applies accepted reactions. Each prompt blocks until
+// the owner answers or the prompt times out. A successful counterspell stops
+// resolution since the triggering spell no longer takes effect.
+func (s *RPCServer) resolveReactions(event ReactionEvent) []ReactionOutcome {
+	tm := s.state.TurnManager
+	candidates := tm.CollectReactions(event, s.entityPosition)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	outcomes := make([]ReactionOutcome, 0, len(candidates))
+	for _, reaction := range candidates {
+		prompt := tm.OpenReactionPrompt(reaction, event)
+		s.eventSys.Emit(game.GameEvent{
+			Type:     EventReactionPrompt,
+			SourceID: event.ActorID,
+			TargetID: reaction.OwnerID,
+			Data: map[string]interface{}{
+				"prompt_id":     prompt.ID,
+				"reaction_id":   reaction.ID,
+				"reaction_type": reaction.Type,
+				"trigger":       event.Trigger,
+				"actor_id":      event.ActorID,
+				"target_id":     event.TargetID,
+				"spell_id":      event.SpellID,
+				"deadline":      prompt.Deadline,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+
+		outcome := ReactionOutcome{
+			PromptID:   prompt.ID,
+			ReactionID: reaction.ID,
+			OwnerID:    reaction.OwnerID,
+			Type:       reaction.Type,
+			Accepted:   s.awaitReactionDecision(prompt, reaction.OwnerID),
+		}
+		if outcome.Accepted {
+			if err := tm.ConsumeReaction(reaction); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"function":   "resolveReactions",
+					"reactionID": reaction.ID,
+					"error":      err.Error(),
+				}).Warn("accepted reaction could not be used")
+				outcome.Accepted = false
+			}
+		}
+		if outcome.Accepted && reaction.Type == ReactionAttackOfOpportunity {
+			outcome.Damage = s.executeAttackOfOpportunity(reaction.OwnerID, event.ActorID)
+		}
+
+		s.eventSys.Emit(game.GameEvent{
+			Type:     EventReactionResolved,
+			SourceID: reaction.OwnerID,
+			TargetID: event.ActorID,
+			Data: map[string]interface{}{
+				"prompt_id":     outcome.PromptID,
+				"reaction_id":   outcome.ReactionID,
+				"reaction_type": outcome.Type,
+				"accepted":      outcome.Accepted,
+				"damage":        outcome.Damage,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+
+		logrus.WithFields(logrus.Fields{
+			"function":     "resolveReactions",
+			"ownerID":      reaction.OwnerID,
+			"reactionType": reaction.Type,
+			"accepted":     outcome.Accepted,
+		}).Info("reaction resolved")
+
+		outcomes = append(outcomes, outcome)
+		if outcome.Accepted && reaction.Type == ReactionCounterspell {
+			break
+		}
+	}
+
+	return outcomes
+}
+
+// executeAttackOfOpportunity has ownerID strike targetID with its equipped
+// weapon and returns the damage dealt.
+func (s *RPCServer) executeAttackOfOpportunity(ownerID, targetID string) int {
+	owner, ownerExists := s.state.WorldState.Objects[ownerID]
+	target, targetExists := s.state.WorldState.Objects[targetID]
+	if !ownerExists || !targetExists {
+		return 0
+	}
+
+	damage := reactionAttackDamage(owner)
+	if damage <= 0 {
+		return 0
+	}
+	if err := s.applyDamage(target, damage); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "executeAttackOfOpportunity",
+			"ownerID":  ownerID,
+			"targetID": targetID,
+			"error":    err.Error(),
+		}).Error("failed to apply attack of opportunity damage")
+		return 0
+	}
+	s.logCombat(CombatLogEntry{
+		Action:     CombatLogAttackOfOpportunity,
+		AttackerID: ownerID,
+		DefenderID: targetID,
+		Source:     string(ReactionAttackOfOpportunity),
+		Damage:     damage,
+	})
+	return damage
+}
+
+// reactionAttackDamage computes the damage of a reaction attack by owner.
+func reactionAttackDamage(owner game.GameObject) int {
+	switch attacker := owner.(type) {
+	case *game.Player:
+		var weapon *game.Item
+		if w, equipped := attacker.Equipment[game.SlotHands]; equipped {
+			weapon = &w
+		}
+		return calculateWeaponDamage(weapon, attacker)
+	case *game.Character:
+		damage := 1 + (attacker.Strength-10)/2
+		if damage < 1 {
+			damage = 1
+		}
+		return damage
+	case *game.NPC:
+		return reactionAttackDamage(&attacker.Character)
+	default:
+		return 0
+	}
+}
+
+// entityPosition looks up the position of a world object by ID.
+func (s *RPCServer) entityPosition(id string) (game.Position, bool) {
+	obj, exists := s.state.WorldState.Objects[id]
+	if !exists {
+		return game.Position{}, false
+	}
+	return obj.GetPosition(), true
+}
+
+// reactionAccepted reports whether an accepted reaction of reactionType is
+// among outcomes.
+func reactionAccepted(outcomes []ReactionOutcome, reactionType ReactionType) (ReactionOutcome, bool) {
+	for _, outcome := range outcomes {
+		if outcome.Accepted && outcome.Type == reactionType {
+			return outcome, true
+		}
+	}
+	return ReactionOutcome{}, false
+}
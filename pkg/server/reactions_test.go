@@ -0,0 +1,243 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReactionTestServer creates a server with two players in combat, with
+// "attacker" acting first and "defender" standing next to them.
+func newReactionTestServer(t *testing.T) (*RPCServer, *game.Player, *game.Player) {
+	server, err := NewRPCServer("../../web")
+	require.NoError(t, err)
+	server.state.WorldState = game.NewWorldWithSize(20, 20, 1)
+
+	newPlayer := func(id string, pos game.Position) *game.Player {
+		return &game.Player{
+			Character: game.Character{
+				ID:              id,
+				Name:            id,
+				Class:           game.ClassMage,
+				Position:        pos,
+				HP:              100,
+				MaxHP:           100,
+				Strength:        14,
+				Intelligence:    16,
+				ActionPoints:    10,
+				MaxActionPoints: 10,
+				Equipment:       make(map[game.EquipmentSlot]game.Item),
+			},
+			Level: 5,
+		}
+	}
+	attacker := newPlayer("attacker", game.Position{X: 5, Y: 5})
+	defender := newPlayer("defender", game.Position{X: 6, Y: 5})
+
+	server.mu.Lock()
+	for _, p := range []*game.Player{attacker, defender} {
+		server.sessions[p.ID+"-session"] = &PlayerSession{
+			SessionID:   p.ID + "-session",
+			Player:      p,
+			Connected:   true,
+			LastActive:  time.Now(),
+			MessageChan: make(chan []byte, 10),
+			WSConn:      &websocket.Conn{},
+		}
+	}
+	server.mu.Unlock()
+	require.NoError(t, server.state.WorldState.AddObject(attacker))
+	require.NoError(t, server.state.WorldState.AddObject(defender))
+
+	require.NoError(t, server.state.TurnManager.StartCombat([]string{"attacker", "defender"}))
+	t.Cleanup(server.state.TurnManager.EndCombat)
+	server.state.TurnManager.SetReactionTimeout(time.Second)
+
+	return server, attacker, defender
+}
+
+// answerPrompts responds to every reaction prompt with accept.
+func answerPrompts(server *RPCServer, accept bool) {
+	server.eventSys.Subscribe(EventReactionPrompt, func(event game.GameEvent) {
+		promptID := event.Data["prompt_id"].(string)
+		go server.state.TurnManager.RespondToPrompt(event.TargetID, promptID, accept)
+	})
+}
+
+func TestRegisterReaction(t *testing.T) {
+	tm := NewTurnManager()
+
+	_, err := tm.RegisterReaction("a", ReactionShieldBlock)
+	assert.EqualError(t, err, "not in combat")
+
+	require.NoError(t, tm.StartCombat([]string{"a", "b"}))
+	defer tm.EndCombat()
+
+	_, err = tm.RegisterReaction("a", ReactionType("dodge"))
+	assert.Error(t, err)
+	_, err = tm.RegisterReaction("c", ReactionShieldBlock)
+	assert.Error(t, err)
+
+	reaction, err := tm.RegisterReaction("a", ReactionShieldBlock)
+	require.NoError(t, err)
+	assert.Equal(t, TriggerAttacked, reaction.Trigger)
+	assert.Equal(t, 1, reaction.Round)
+
+	_, err = tm.RegisterReaction("a", ReactionShieldBlock)
+	assert.Error(t, err, "duplicate reaction types should be rejected")
+
+	require.NoError(t, tm.CancelReaction("a", reaction.ID))
+	assert.Empty(t, tm.GetReactions("a"))
+	assert.Error(t, tm.CancelReaction("a", reaction.ID))
+}
+
+func TestCollectReactionsOrder(t *testing.T) {
+	tm := NewTurnManager()
+	require.NoError(t, tm.StartCombat([]string{"mover", "c", "b", "far"}))
+	defer tm.EndCombat()
+
+	positions := map[string]game.Position{
+		"b":   {X: 1, Y: 0},
+		"c":   {X: 0, Y: 1},
+		"far": {X: 9, Y: 9},
+	}
+	positionOf := func(id string) (game.Position, bool) {
+		pos, ok := positions[id]
+		return pos, ok
+	}
+
+	for _, id := range []string{"b", "c", "far", "mover"} {
+		_, err := tm.RegisterReaction(id, ReactionAttackOfOpportunity)
+		require.NoError(t, err)
+	}
+
+	event := ReactionEvent{
+		Trigger: TriggerLeaveReach,
+		ActorID: "mover",
+		From:    game.Position{X: 0, Y: 0},
+		To:      game.Position{X: -2, Y: -2},
+	}
+	triggered := tm.CollectReactions(event, positionOf)
+	require.Len(t, triggered, 2)
+	assert.Equal(t, "c", triggered[0].OwnerID, "earlier initiative resolves first")
+	assert.Equal(t, "b", triggered[1].OwnerID)
+
+	// Once an entity has reacted it cannot react again this round
+	require.NoError(t, tm.ConsumeReaction(triggered[0]))
+	_, err := tm.RegisterReaction("c", ReactionShieldBlock)
+	require.NoError(t, err)
+	assert.Empty(t, tm.CollectReactions(ReactionEvent{Trigger: TriggerAttacked, ActorID: "mover", TargetID: "c"}, positionOf))
+
+	tm.AdvanceTurn()
+	tm.AdvanceTurn()
+	tm.AdvanceTurn()
+	tm.AdvanceTurn()
+	assert.Len(t, tm.CollectReactions(ReactionEvent{Trigger: TriggerAttacked, ActorID: "mover", TargetID: "c"}, positionOf), 1)
+}
+
+func TestReactionPromptTimeout(t *testing.T) {
+	tm := NewTurnManager()
+	require.NoError(t, tm.StartCombat([]string{"a", "b"}))
+	defer tm.EndCombat()
+	tm.SetReactionTimeout(20 * time.Millisecond)
+
+	reaction, err := tm.RegisterReaction("b", ReactionShieldBlock)
+	require.NoError(t, err)
+
+	prompt := tm.OpenReactionPrompt(reaction, ReactionEvent{Trigger: TriggerAttacked, ActorID: "a", TargetID: "b"})
+	assert.False(t, tm.AwaitPrompt(prompt))
+	assert.Error(t, tm.RespondToPrompt("b", prompt.ID, true), "late responses are rejected")
+
+	prompt = tm.OpenReactionPrompt(reaction, ReactionEvent{Trigger: TriggerAttacked, ActorID: "a", TargetID: "b"})
+	assert.Error(t, tm.RespondToPrompt("a", prompt.ID, true), "only the owner may respond")
+	require.NoError(t, tm.RespondToPrompt("b", prompt.ID, true))
+	assert.True(t, tm.AwaitPrompt(prompt))
+}
+
+func TestShieldBlockHalvesAttackDamage(t *testing.T) {
+	server, _, defender := newReactionTestServer(t)
+	answerPrompts(server, true)
+
+	_, err := server.handleRegisterReaction(json.RawMessage(`{"session_id":"defender-session","reaction_type":"shield_block"}`))
+	require.NoError(t, err)
+
+	result, err := server.handleAttack(json.RawMessage(`{"session_id":"attacker-session","target_id":"defender"}`))
+	require.NoError(t, err)
+
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, true, resultMap["blocked"])
+	assert.Equal(t, 1, resultMap["damage"], "unarmed damage of 3 should be halved")
+	assert.Equal(t, 99, defender.HP)
+	assert.Empty(t, server.state.TurnManager.GetReactions("defender"), "used reactions are consumed")
+}
+
+func TestDeclinedReactionTimesOut(t *testing.T) {
+	server, _, defender := newReactionTestServer(t)
+	server.state.TurnManager.SetReactionTimeout(20 * time.Millisecond)
+
+	_, err := server.handleRegisterReaction(json.RawMessage(`{"session_id":"defender-session","reaction_type":"shield_block"}`))
+	require.NoError(t, err)
+
+	result, err := server.handleAttack(json.RawMessage(`{"session_id":"attacker-session","target_id":"defender"}`))
+	require.NoError(t, err)
+
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, false, resultMap["blocked"])
+	assert.Equal(t, 97, defender.HP)
+	assert.Len(t, server.state.TurnManager.GetReactions("defender"), 1, "declined reactions remain held")
+}
+
+func TestCounterspellPreventsSpell(t *testing.T) {
+	server, attacker, _ := newReactionTestServer(t)
+	answerPrompts(server, true)
+
+	spell, err := server.spellManager.GetSpell("magic_missile")
+	require.NoError(t, err)
+	require.NoError(t, attacker.LearnSpell(*spell))
+	require.NoError(t, attacker.MemorizeSpells([]string{"magic_missile"}))
+	attacker.RestoreSpells()
+
+	_, err = server.handleRegisterReaction(json.RawMessage(`{"session_id":"defender-session","reaction_type":"counterspell"}`))
+	require.NoError(t, err)
+
+	result, err := server.handleCastSpell(json.RawMessage(`{"session_id":"attacker-session","spell_id":"magic_missile","target_id":"defender"}`))
+	require.NoError(t, err)
+
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, false, resultMap["success"])
+	assert.Equal(t, true, resultMap["countered"])
+	assert.Equal(t, "defender", resultMap["countered_by"])
+	assert.Equal(t, 10-game.ActionCostSpell, attacker.GetActionPoints())
+	assert.Error(t, attacker.CanCastMemorized(*spell), "a countered spell is still expended")
+}
+
+func TestAttackOfOpportunityOnMove(t *testing.T) {
+	server, attacker, _ := newReactionTestServer(t)
+	answerPrompts(server, true)
+
+	_, err := server.handleRegisterReaction(json.RawMessage(`{"session_id":"defender-session","reaction_type":"attack_of_opportunity"}`))
+	require.NoError(t, err)
+
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id": "attacker-session",
+		"direction":  game.DirectionWest,
+	})
+	require.NoError(t, err)
+
+	result, err := server.handleMove(params)
+	require.NoError(t, err)
+
+	resultMap := result.(map[string]interface{})
+	reactions := resultMap["reactions"].([]ReactionOutcome)
+	require.Len(t, reactions, 1)
+	assert.True(t, reactions[0].Accepted)
+	assert.Equal(t, 3, reactions[0].Damage)
+	assert.Equal(t, 97, attacker.HP)
+	assert.Equal(t, 4, attacker.GetPosition().X, "movement completes after the reaction")
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordRelationship makes an NPC, or a merchant, remember something the
+// player did
+func (s *RPCServer) recordRelationship(npcID, playerID string, event game.RelationshipEvent) {
+	if s.state.WorldState == nil {
+		return
+	}
+	if _, err := s.state.WorldState.RelationshipGraph().Record(npcID, playerID, event); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "recordRelationship",
+			"npc_id":   npcID,
+			"event":    event,
+		}).WithError(err).Warn("failed to record relationship event")
+	}
+}
+
+// recordAttack makes an attacked NPC and its faction remember the attacker
+func (s *RPCServer) recordAttack(attackerID string, target game.GameObject) {
+	npc, ok := target.(*game.NPC)
+	if !ok || s.state.WorldState == nil {
+		return
+	}
+	s.state.WorldState.RecordAttack(attackerID, npc)
+}
+
+// checkQuestGiver rejects a quest whose giver will not offer it to the
+// player
+func (s *RPCServer) checkQuestGiver(player *game.Player, quest *game.Quest) error {
+	if quest.GiverID == "" || s.state.WorldState == nil {
+		return nil
+	}
+	graph := s.state.WorldState.RelationshipGraph()
+	if graph.QuestAvailable(quest, player.GetID()) {
+		return nil
+	}
+	rel := graph.Get(quest.GiverID, player.GetID())
+	return ErrQuestRejected.WithMessage("%s will not offer this quest", quest.GiverID).WithData(map[string]interface{}{
+		"quest_id": quest.ID,
+		"giver_id": quest.GiverID,
+		"attitude": rel.Attitude(),
+		"trust":    rel.Trust,
+	})
+}
+
+// handleTalkToNPC speaks with a living NPC next to the session's player.
+// Which dialog node the NPC opens with, and which responses the player
+// may give, depend on how the NPC feels about the player: nodes can
+// require a minimum or maximum trust, fear or debt, or an attitude.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - npc_id: string - The NPC to talk to
+//   - dialog_id: string - Optional dialog node to continue with
+//
+// Returns:
+//   - interface{}: Map containing the dialog node, the responses open to
+//     the player and the NPC's relationship with them
+//   - error: Invalid parameters or session, ErrNPCNotFound if no living
+//     NPC is within reach, ErrDialogUnavailable if the NPC will not say
+//     the node
+func (s *RPCServer) handleTalkToNPC(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleTalkToNPC",
+	})
+	logger.Debug("entering handleTalkToNPC")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		NPCID     string `json:"npc_id"`
+		DialogID  string `json:"dialog_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid talk parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	player := session.Player
+
+	obj, exists := s.state.WorldState.GetObject(req.NPCID)
+	npc, isNPC := obj.(*game.NPC)
+	if !exists || !isNPC || !npc.IsActive() || !withinReach(player.GetPosition(), npc.GetPosition()) {
+		return nil, ErrNPCNotFound.WithData(map[string]interface{}{"npc_id": req.NPCID})
+	}
+
+	rel := s.state.WorldState.RelationshipGraph().Get(npc.GetID(), player.GetID())
+	entry, responses, err := npc.SelectDialog(rel, req.DialogID)
+	if err != nil {
+		return nil, ErrDialogUnavailable.WithMessage("%v", err).WithData(map[string]interface{}{
+			"npc_id":    npc.GetID(),
+			"dialog_id": req.DialogID,
+			"attitude":  rel.Attitude(),
+		})
+	}
+
+	options := make([]map[string]string, 0, len(responses))
+	for _, response := range responses {
+		options = append(options, map[string]string{
+			"text":        response.Text,
+			"next_dialog": response.NextDialog,
+			"action":      response.Action,
+		})
+	}
+
+	logger.WithFields(logrus.Fields{
+		"player_id": player.GetID(),
+		"npc_id":    npc.GetID(),
+		"dialog_id": entry.ID,
+		"attitude":  rel.Attitude(),
+	}).Info("player talked to NPC")
+
+	return map[string]interface{}{
+		"success":   true,
+		"npc_id":    npc.GetID(),
+		"name":      npc.GetName(),
+		"dialog_id": entry.ID,
+		"text":      entry.Text,
+		"responses": options,
+		"attitude":  rel.Attitude(),
+		"relationship": map[string]int{
+			"trust": rel.Trust,
+			"fear":  rel.Fear,
+			"debt":  rel.Debt,
+		},
+	}, nil
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleTalkToNPC(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	world := server.state.WorldState
+
+	innkeeper := &game.NPC{
+		Character: game.Character{ID: "innkeeper", Name: "Innkeeper", Position: game.Position{X: 11, Y: 10}, HP: 10},
+		Faction:   "townsfolk",
+		Dialog: []game.DialogEntry{
+			{
+				ID:         "cower",
+				Text:       "Please, no more trouble!",
+				Conditions: []game.DialogCondition{{Type: game.DialogConditionAttitude, Value: "afraid"}},
+			},
+			{
+				ID:        "greet",
+				Text:      "Welcome, traveller.",
+				Responses: []game.DialogResponse{{Text: "Heard any rumours?", NextDialog: "rumour"}, {Text: "Farewell."}},
+			},
+			{
+				ID:         "rumour",
+				Text:       "They say the old mill is haunted.",
+				Conditions: []game.DialogCondition{{Type: game.DialogConditionMinTrust, Value: 10}},
+			},
+		},
+	}
+	innkeeper.SetActive(true)
+	require.NoError(t, world.AddObject(innkeeper))
+
+	talk := func(dialogID string) (map[string]interface{}, error) {
+		params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "npc_id": innkeeper.ID, "dialog_id": dialogID})
+		result, err := server.handleTalkToNPC(params)
+		if err != nil {
+			return nil, err
+		}
+		return result.(map[string]interface{}), nil
+	}
+
+	result, err := talk("")
+	require.NoError(t, err)
+	assert.Equal(t, "greet", result["dialog_id"])
+	assert.Len(t, result["responses"], 1, "a stranger does not hear the rumour")
+	_, err = talk("rumour")
+	assert.True(t, errors.Is(err, ErrDialogUnavailable))
+
+	server.recordRelationship(innkeeper.ID, session.Player.GetID(), game.RelationshipHelped)
+	result, err = talk("rumour")
+	require.NoError(t, err)
+	assert.Equal(t, "They say the old mill is haunted.", result["text"])
+
+	for i := 0; i < 4; i++ {
+		server.recordAttack(session.Player.GetID(), innkeeper)
+	}
+	result, err = talk("")
+	require.NoError(t, err)
+	assert.Equal(t, "cower", result["dialog_id"], "an NPC attacked by the player is afraid of them")
+	assert.Equal(t, game.AttitudeAfraid, result["attitude"])
+
+	innkeeper.SetPosition(game.Position{X: 15, Y: 15})
+	_, err = talk("")
+	assert.True(t, errors.Is(err, ErrNPCNotFound), "NPCs out of reach cannot be talked to")
+}
+
+func TestHandleStartQuest_GiverRelationship(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	graph := server.state.WorldState.RelationshipGraph()
+
+	start := func(id string) error {
+		params, _ := json.Marshal(map[string]interface{}{
+			"session_id": session.SessionID,
+			"quest":      game.Quest{ID: id, Title: "Clear the cellar", GiverID: "innkeeper", MinGiverTrust: 10},
+		})
+		_, err := server.handleStartQuest(params)
+		return err
+	}
+
+	err := start("cellar")
+	assert.True(t, errors.Is(err, ErrQuestRejected), "the innkeeper does not trust a stranger with the quest")
+
+	graph.Set("innkeeper", session.Player.GetID(), game.Relationship{Trust: 20})
+	require.NoError(t, start("cellar"))
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "quest_id": "cellar"})
+	_, err = server.handleFailQuest(params)
+	require.NoError(t, err)
+	rel := graph.Get("innkeeper", session.Player.GetID())
+	assert.Equal(t, 5, rel.Trust, "failing the quest costs the giver's trust")
+	assert.Equal(t, -10, rel.Debt)
+}
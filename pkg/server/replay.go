@@ -0,0 +1,636 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of random draws recorded in a combat replay.
+const (
+	drawInitiative = "initiative" // d10 initiative roll
+	drawDamage     = "damage"     // Spell damage dice
+	drawHealing    = "healing"    // Spell healing dice
+	drawReaction   = "reaction"   // Reaction prompt answer: 1 accepted, 0 declined
+)
+
+// combatReplayMethods are the RPC methods recorded while a combat is in
+// progress. Together with the recorded draws they determine its outcome.
+var combatReplayMethods = map[RPCMethod]bool{
+	MethodStartCombat:      true,
+	MethodAttack:           true,
+	MethodCastSpell:        true,
+	MethodMove:             true,
+	MethodUseItem:          true,
+	MethodApplyEffect:      true,
+	MethodEndTurn:          true,
+	MethodRegisterReaction: true,
+	MethodCancelReaction:   true,
+}
+
+// CombatReplay is a recording of one combat: its participants as they were
+// when combat started, every combat RPC call made until it ended, and every
+// random number drawn to resolve those calls. Draws come from a dice roller
+// reseeded at the start of each turn with a seed derived from the combat
+// seed, so replaying the calls against the snapshot reproduces the combat.
+//
+// The replay ID is returned by startCombat. Balance complaints should quote
+// it as the content ID of pcg.ContentTypeCombat feedback, so the combat can
+// be re-run with replayCombat.
+type CombatReplay struct {
+	ID           string            `yaml:"replay_id" json:"replay_id"`
+	Seed         int64             `yaml:"seed" json:"seed"`
+	StartedAt    time.Time         `yaml:"started_at" json:"started_at"`
+	EndedAt      time.Time         `yaml:"ended_at,omitempty" json:"ended_at,omitempty"`
+	Participants []string          `yaml:"participants" json:"participants"`
+	Players      []ReplayPlayer    `yaml:"players,omitempty" json:"-"`
+	NPCs         []*game.NPC       `yaml:"npcs,omitempty" json:"-"`
+	Characters   []*game.Character `yaml:"characters,omitempty" json:"-"`
+	Calls        []ReplayCall      `yaml:"calls" json:"calls"`
+	Draws        []ReplayDraw      `yaml:"draws" json:"draws"`
+	Outcome      map[string]int    `yaml:"outcome,omitempty" json:"outcome,omitempty"` // Participant HP when combat ended
+}
+
+// ReplayPlayer is a snapshot of a participating player and the session that
+// controlled it.
+type ReplayPlayer struct {
+	SessionID string       `yaml:"session_id,omitempty"`
+	Player    *game.Player `yaml:"player"`
+}
+
+// ReplayCall is one recorded combat RPC call. Params and Result hold JSON.
+type ReplayCall struct {
+	Method string `yaml:"method" json:"method"`
+	Params string `yaml:"params" json:"params"`
+	Result string `yaml:"result,omitempty" json:"result,omitempty"`
+	Error  string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// ReplayDraw is one recorded random draw. Call is the index of the call that
+// made the draw; Turn and TurnSeed identify the dice roller it came from.
+type ReplayDraw struct {
+	Call       int    `yaml:"call" json:"call"`
+	Turn       int    `yaml:"turn" json:"turn"`
+	TurnSeed   int64  `yaml:"turn_seed" json:"turn_seed"`
+	Kind       string `yaml:"kind" json:"kind"`
+	EntityID   string `yaml:"entity_id" json:"entity_id"`
+	Expression string `yaml:"expression,omitempty" json:"expression,omitempty"`
+	Value      int    `yaml:"value" json:"value"`
+}
+
+// combatRecorder records the combat in progress and keeps recent replays.
+// The zero value is ready to use.
+type combatRecorder struct {
+	mu       sync.Mutex
+	active   *CombatReplay
+	ended    bool // active combat has ended; the next recorded call closes it
+	turn     int
+	turnSeed int64
+	seeds    *pcg.SeedManager
+	dice     *game.DiceRoller
+	recent   map[string]*CombatReplay
+	order    []string
+
+	// Replay sandboxes answer reaction prompts from the recording instead of
+	// waiting for players.
+	sandbox   bool
+	decisions []bool
+}
+
+// begin starts recording replay, closing any ended combat still awaiting
+// its final call.
+func (r *combatRecorder) begin(replay *CombatReplay) *CombatReplay {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var closed *CombatReplay
+	if r.active != nil && r.ended {
+		closed = r.closeLocked()
+	}
+	r.active = replay
+	r.ended = false
+	r.seeds = pcg.NewSeedManager(replay.Seed)
+	r.reseedLocked(0)
+	return closed
+}
+
+// discard drops the active recording, used when combat fails to start.
+func (r *combatRecorder) discard(replay *CombatReplay) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active == replay {
+		r.active = nil
+		r.ended = false
+	}
+}
+
+// reseedLocked moves to turn and seeds its dice roller. The caller must hold
+// r.mu.
+func (r *combatRecorder) reseedLocked(turn int) {
+	r.turn = turn
+	r.turnSeed = r.seeds.DeriveContextSeed(pcg.ContentTypeCombat, fmt.Sprintf("turn:%d", turn))
+	r.dice = game.NewDiceRollerWithSeed(r.turnSeed)
+}
+
+// advanceTurn reseeds the dice roller for the next turn.
+func (r *combatRecorder) advanceTurn() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active != nil && !r.ended {
+		r.reseedLocked(r.turn + 1)
+	}
+}
+
+// roll rolls expression with the current turn's dice and records the draw.
+// Outside combat, and for empty expressions that draw nothing, it falls back
+// to the global dice roller.
+func (r *combatRecorder) roll(kind, entityID, expression string) (*game.DiceRoll, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active == nil || r.ended || expression == "" {
+		return game.GlobalDiceRoller.Roll(expression)
+	}
+	roll, err := r.dice.Roll(expression)
+	if err != nil {
+		return nil, err
+	}
+	r.recordDrawLocked(kind, entityID, expression, roll.Final)
+	return roll, nil
+}
+
+// recordDrawLocked appends a draw to the active replay. The caller must hold
+// r.mu.
+func (r *combatRecorder) recordDrawLocked(kind, entityID, expression string, value int) {
+	r.active.Draws = append(r.active.Draws, ReplayDraw{
+		Call:       len(r.active.Calls),
+		Turn:       r.turn,
+		TurnSeed:   r.turnSeed,
+		Kind:       kind,
+		EntityID:   entityID,
+		Expression: expression,
+		Value:      value,
+	})
+}
+
+// recordDecision records a player's answer to a reaction prompt.
+func (r *combatRecorder) recordDecision(ownerID string, accepted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active == nil || r.ended {
+		return
+	}
+	value := 0
+	if accepted {
+		value = 1
+	}
+	r.recordDrawLocked(drawReaction, ownerID, "", value)
+}
+
+// isSandbox reports whether the recorder belongs to a replay sandbox.
+func (r *combatRecorder) isSandbox() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sandbox
+}
+
+// nextDecision returns the next recorded reaction answer in a replay
+// sandbox. ok is false outside a sandbox.
+func (r *combatRecorder) nextDecision() (accepted, ok bool) {
+	r.mu.Lock()
+	if !r.sandbox {
+		r.mu.Unlock()
+		return false, false
+	}
+	if len(r.decisions) > 0 {
+		accepted = r.decisions[0]
+		r.decisions = r.decisions[1:]
+	}
+	r.mu.Unlock()
+	return accepted, true
+}
+
+// markEnded records the outcome of the active combat. The recording stays
+// open until the call that ended the combat has been recorded.
+func (r *combatRecorder) markEnded(outcome map[string]int, endedAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active == nil || r.ended {
+		return
+	}
+	r.active.Outcome = outcome
+	r.active.EndedAt = endedAt
+	r.ended = true
+}
+
+// recordCall appends a combat call to the active replay. It returns the
+// replay if the call ended the combat and the recording is now closed.
+func (r *combatRecorder) recordCall(method RPCMethod, params json.RawMessage, result interface{}, err error) *CombatReplay {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active == nil {
+		return nil
+	}
+
+	call := ReplayCall{Method: string(method), Params: string(params)}
+	if method == MethodStartCombat {
+		// Pin the seed so replaying the call reproduces the recorded draws
+		call.Params = withReplaySeed(params, r.active.Seed)
+	}
+	if err != nil {
+		call.Error = err.Error()
+	} else if data, marshalErr := json.Marshal(result); marshalErr == nil {
+		call.Result = string(data)
+	}
+	r.active.Calls = append(r.active.Calls, call)
+
+	if r.ended {
+		return r.closeLocked()
+	}
+	return nil
+}
+
+// closeLocked moves the active replay to the recent cache. The caller must
+// hold r.mu.
+func (r *combatRecorder) closeLocked() *CombatReplay {
+	replay := r.active
+	r.active = nil
+	r.ended = false
+
+	if r.recent == nil {
+		r.recent = make(map[string]*CombatReplay)
+	}
+	r.recent[replay.ID] = replay
+	r.order = append(r.order, replay.ID)
+	if len(r.order) > CombatReplayCacheSize {
+		delete(r.recent, r.order[0])
+		r.order = r.order[1:]
+	}
+	return replay
+}
+
+// get returns a copy of the active or a recent replay with id.
+func (r *combatRecorder) get(id string) (*CombatReplay, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	replay, ok := r.recent[id]
+	if !ok && r.active != nil && r.active.ID == id {
+		replay, ok = r.active, true
+	}
+	if !ok {
+		return nil, false
+	}
+	copied := *replay
+	copied.Calls = append([]ReplayCall(nil), replay.Calls...)
+	copied.Draws = append([]ReplayDraw(nil), replay.Draws...)
+	return &copied, true
+}
+
+// latest returns the active replay, or the most recently closed one.
+func (r *combatRecorder) latest() *CombatReplay {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active != nil {
+		return r.active
+	}
+	if len(r.order) == 0 {
+		return nil
+	}
+	return r.recent[r.order[len(r.order)-1]]
+}
+
+// withReplaySeed returns params with its seed field set to seed.
+func withReplaySeed(params json.RawMessage, seed int64) string {
+	fields := map[string]interface{}{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &fields); err != nil {
+			return string(params)
+		}
+	}
+	fields["seed"] = seed
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return string(params)
+	}
+	return string(data)
+}
+
+// beginCombatReplay starts recording a combat between participants and
+// snapshots them for replay. A zero seed is replaced with a random one.
+func (s *RPCServer) beginCombatReplay(seed int64, participants []string) *CombatReplay {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	replay := &CombatReplay{
+		ID:           uuid.New().String(),
+		Seed:         seed,
+		StartedAt:    time.Now(),
+		Participants: append([]string(nil), participants...),
+	}
+	s.snapshotCombatants(replay)
+
+	if closed := s.replays.begin(replay); closed != nil {
+		s.saveCombatReplay(closed)
+	}
+	return replay
+}
+
+// snapshotCombatants copies the participants of replay into it.
+func (s *RPCServer) snapshotCombatants(replay *CombatReplay) {
+	sessionIDs := make(map[string]string)
+	s.mu.RLock()
+	for id, session := range s.sessions {
+		if session.Player != nil {
+			sessionIDs[session.Player.GetID()] = id
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, id := range replay.Participants {
+		switch obj := s.state.WorldState.Objects[id].(type) {
+		case *game.Player:
+			replay.Players = append(replay.Players, ReplayPlayer{SessionID: sessionIDs[id], Player: obj.Clone()})
+		case *game.NPC:
+			replay.NPCs = append(replay.NPCs, cloneNPC(obj))
+		case *game.Character:
+			replay.Characters = append(replay.Characters, obj.Clone())
+		}
+	}
+}
+
+// cloneNPC returns a copy of npc that shares no mutable state with it.
+func cloneNPC(npc *game.NPC) *game.NPC {
+	copied := &game.NPC{
+		Behavior:  npc.Behavior,
+		Faction:   npc.Faction,
+		Dialog:    append([]game.DialogEntry(nil), npc.Dialog...),
+		LootTable: append([]game.LootEntry(nil), npc.LootTable...),
+	}
+	copied.Character = *npc.Character.Clone()
+	if npc.Modifiers != nil {
+		modifiers := *npc.Modifiers
+		copied.Modifiers = &modifiers
+	}
+	return copied
+}
+
+// rollCombatDice rolls expression for entityID. During combat the roll comes
+// from the turn's seeded dice and is recorded in the combat replay.
+func (s *RPCServer) rollCombatDice(kind, entityID, expression string) (*game.DiceRoll, error) {
+	return s.replays.roll(kind, entityID, expression)
+}
+
+// awaitReactionDecision waits for the owner's answer to a reaction prompt
+// and records it. Replay sandboxes take the answer from the recording.
+func (s *RPCServer) awaitReactionDecision(prompt *ReactionPrompt, ownerID string) bool {
+	if accepted, ok := s.replays.nextDecision(); ok {
+		s.replays.recordDecision(ownerID, accepted)
+		return accepted
+	}
+	accepted := s.state.TurnManager.AwaitPrompt(prompt)
+	s.replays.recordDecision(ownerID, accepted)
+	return accepted
+}
+
+// finishCombatReplay records the participants' final hit points when combat
+// ends.
+func (s *RPCServer) finishCombatReplay() {
+	replay := s.replays.latest()
+	if replay == nil {
+		return
+	}
+	outcome := make(map[string]int)
+	for _, id := range replay.Participants {
+		if obj, exists := s.state.WorldState.Objects[id]; exists {
+			if hp, ok := objectHP(obj); ok {
+				outcome[id] = hp
+			}
+		}
+	}
+	s.replays.markEnded(outcome, time.Now())
+}
+
+// objectHP returns the current hit points of obj if it has any.
+func objectHP(obj game.GameObject) (int, bool) {
+	switch v := obj.(type) {
+	case *game.Player:
+		return v.HP, true
+	case *game.NPC:
+		return v.HP, true
+	case *game.Character:
+		return v.HP, true
+	}
+	return 0, false
+}
+
+// recordCombatCall records a combat RPC call in the active replay, saving
+// the replay once the call that ended the combat has been recorded.
+func (s *RPCServer) recordCombatCall(method RPCMethod, params json.RawMessage, result interface{}, err error) {
+	if !combatReplayMethods[method] {
+		return
+	}
+	if closed := s.replays.recordCall(method, params, result, err); closed != nil {
+		s.saveCombatReplay(closed)
+	}
+}
+
+// saveCombatReplay writes a finished replay to the store, if persistence is
+// enabled.
+func (s *RPCServer) saveCombatReplay(replay *CombatReplay) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.Save(combatReplayPrefix+replay.ID+".yaml", replay); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "saveCombatReplay",
+			"replayID": replay.ID,
+			"error":    err.Error(),
+		}).Error("failed to save combat replay")
+	}
+}
+
+// loadCombatReplay returns a recent replay, or loads an older one from the
+// store.
+func (s *RPCServer) loadCombatReplay(id string) (*CombatReplay, error) {
+	if replay, ok := s.replays.get(id); ok {
+		return replay, nil
+	}
+	if s.store == nil {
+		return nil, replayNotFound(id)
+	}
+	var replay CombatReplay
+	if err := s.store.Load(combatReplayPrefix+id+".yaml", &replay); err != nil {
+		return nil, replayNotFound(id).WithData(map[string]interface{}{"cause": err.Error()})
+	}
+	return &replay, nil
+}
+
+// replayNotFound reports a combat replay that is neither recent nor stored
+func replayNotFound(id string) *JSONRPCError {
+	return ErrReplayNotFound.WithMessage("combat replay %s not found", id).
+		WithData(map[string]interface{}{"replay_id": id})
+}
+
+// newReplaySandbox builds a server holding only the snapshot in replay, to
+// re-run its calls without touching the live world.
+func (s *RPCServer) newReplaySandbox(replay *CombatReplay) *RPCServer {
+	sandbox := createServerInstance(s.webDir, s.config, s.validator, s.spellManager, s.pcgManager)
+	sandbox.lootTables = s.lootTables
+	sandbox.state.WorldState = game.NewWorld()
+	sandbox.replays.sandbox = true
+	for _, draw := range replay.Draws {
+		if draw.Kind == drawReaction {
+			sandbox.replays.decisions = append(sandbox.replays.decisions, draw.Value == 1)
+		}
+	}
+
+	for _, rp := range replay.Players {
+		player := rp.Player.Clone()
+		sandbox.state.WorldState.AddObject(player)
+		if rp.SessionID != "" {
+			sandbox.sessions[rp.SessionID] = &PlayerSession{
+				SessionID:   rp.SessionID,
+				Player:      player,
+				LastActive:  time.Now(),
+				CreatedAt:   time.Now(),
+				MessageChan: make(chan []byte, MessageChanBufferSize),
+			}
+		}
+	}
+	for _, npc := range replay.NPCs {
+		sandbox.state.WorldState.AddObject(cloneNPC(npc))
+	}
+	for _, char := range replay.Characters {
+		sandbox.state.WorldState.AddObject(char.Clone())
+	}
+	return sandbox
+}
+
+// replayCombat re-runs the calls recorded in replay against a sandbox built
+// from its snapshot and returns how the re-run diverged from the recording.
+// A deterministic combat has no divergences.
+func (s *RPCServer) replayCombat(replay *CombatReplay) (*CombatReplay, []string) {
+	// The turn manager publishes combat time globally; keep the live value
+	tick := game.GetCurrentGameTick()
+	defer game.SetCurrentGameTick(tick)
+
+	sandbox := s.newReplaySandbox(replay)
+	defer func() {
+		sandbox.state.TurnManager.EndCombat()
+		close(sandbox.done)
+	}()
+
+	var divergences []string
+	for i, call := range replay.Calls {
+		_, err := sandbox.handleMethod(RPCMethod(call.Method), json.RawMessage(call.Params))
+		replayedErr := ""
+		if err != nil {
+			replayedErr = err.Error()
+		}
+		if replayedErr != call.Error {
+			divergences = append(divergences, fmt.Sprintf("call %d (%s): recorded error %q, replayed error %q", i, call.Method, call.Error, replayedErr))
+		}
+	}
+
+	replayed := sandbox.replays.latest()
+	if replayed == nil {
+		return nil, append(divergences, "replay did not start combat")
+	}
+
+	for i := 0; i < len(replay.Draws) || i < len(replayed.Draws); i++ {
+		switch {
+		case i >= len(replayed.Draws):
+			divergences = append(divergences, fmt.Sprintf("draw %d (%s): missing from replay", i, replay.Draws[i].Kind))
+		case i >= len(replay.Draws):
+			divergences = append(divergences, fmt.Sprintf("draw %d (%s): not in recording", i, replayed.Draws[i].Kind))
+		case replay.Draws[i] != replayed.Draws[i]:
+			divergences = append(divergences, fmt.Sprintf("draw %d: recorded %+v, replayed %+v", i, replay.Draws[i], replayed.Draws[i]))
+		}
+	}
+
+	if !replay.EndedAt.IsZero() && !reflect.DeepEqual(replay.Outcome, replayed.Outcome) {
+		divergences = append(divergences, fmt.Sprintf("outcome: recorded %v, replayed %v", replay.Outcome, replayed.Outcome))
+	}
+	return replayed, divergences
+}
+
+// handleReplayCombat re-runs a recorded combat in a sandbox and reports
+// whether it reproduced the recording. The live world is not affected.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - replay_id: string - The replay_id returned by startCombat
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating the replay ran
+//   - replay_id, seed: The replayed combat
+//   - deterministic: bool indicating every call, draw and the outcome matched
+//   - divergences: []string describing each mismatch
+//   - calls, draws: The recorded calls and random draws
+//   - outcome: Participant hit points at the end of the replay
+//   - error: Invalid parameters or session, or an unknown replay
+func (s *RPCServer) handleReplayCombat(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleReplayCombat",
+	})
+	logger.Debug("entering handleReplayCombat")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		ReplayID  string `json:"replay_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid replay parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	replay, err := s.loadCombatReplay(req.ReplayID)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed, divergences := s.replayCombat(replay)
+	var outcome map[string]int
+	if replayed != nil {
+		outcome = replayed.Outcome
+	}
+
+	logger.WithFields(logrus.Fields{
+		"replayID":    replay.ID,
+		"calls":       len(replay.Calls),
+		"draws":       len(replay.Draws),
+		"divergences": len(divergences),
+	}).Info("combat replayed")
+
+	return map[string]interface{}{
+		"success":       true,
+		"replay_id":     replay.ID,
+		"seed":          replay.Seed,
+		"deterministic": len(divergences) == 0,
+		"divergences":   divergences,
+		"calls":         replay.Calls,
+		"draws":         replay.Draws,
+		"outcome":       outcome,
+	}, nil
+}
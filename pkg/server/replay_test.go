@@ -0,0 +1,203 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replaySessions maps each test player to its session ID. Session IDs go
+// through input validation and must be UUIDs.
+var replaySessions = map[string]string{
+	"alice": "11111111-1111-4111-8111-111111111111",
+	"bob":   "22222222-2222-4222-8222-222222222222",
+}
+
+// newReplayTestServer creates a server with two mages, "alice" and "bob",
+// who know the evocation spell "replay_bolt" and are not yet in combat.
+func newReplayTestServer(t *testing.T) *RPCServer {
+	server, err := NewRPCServer("../../web")
+	require.NoError(t, err)
+	server.state.WorldState = game.NewWorldWithSize(20, 20, 1)
+	server.store = persistence.NewMemoryStore()
+	t.Cleanup(server.state.TurnManager.EndCombat)
+
+	spell := &game.Spell{
+		ID:         "replay_bolt",
+		Name:       "Replay Bolt",
+		Level:      1,
+		School:     game.SchoolEvocation,
+		DamageDice: "2d6",
+		DamageType: "force",
+	}
+	require.NoError(t, server.spellManager.AddSpell(spell))
+
+	for i, id := range []string{"alice", "bob"} {
+		player := &game.Player{
+			Character: game.Character{
+				ID:              id,
+				Name:            id,
+				Class:           game.ClassMage,
+				Position:        game.Position{X: 5 + i, Y: 5},
+				HP:              100,
+				MaxHP:           100,
+				Intelligence:    16,
+				ActionPoints:    10,
+				MaxActionPoints: 10,
+				Equipment:       make(map[game.EquipmentSlot]game.Item),
+			},
+			Level: 5,
+		}
+		require.NoError(t, player.LearnSpell(*spell))
+		require.NoError(t, player.MemorizeSpells([]string{"replay_bolt", "replay_bolt", "replay_bolt", "replay_bolt"}))
+		player.RestoreSpells()
+		require.NoError(t, server.state.WorldState.AddObject(player))
+		server.setSession(replaySessions[id], &PlayerSession{
+			SessionID:   replaySessions[id],
+			Player:      player,
+			Connected:   true,
+			LastActive:  time.Now(),
+			MessageChan: make(chan []byte, 10),
+			WSConn:      &websocket.Conn{},
+		})
+	}
+	return server
+}
+
+// call invokes the handler for method and records the call the way
+// handleMethod does once params have passed validation. startCombat and
+// endTurn have no registered validators, so the test bypasses handleMethod.
+func call(t *testing.T, server *RPCServer, method RPCMethod, params map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	handlers := map[RPCMethod]func(json.RawMessage) (interface{}, error){
+		MethodStartCombat: server.handleStartCombat,
+		MethodCastSpell:   server.handleCastSpell,
+		MethodEndTurn:     server.handleEndTurn,
+	}
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	result, err := handlers[method](data)
+	server.recordCombatCall(method, data, result, err)
+	require.NoError(t, err)
+	return result.(map[string]interface{})
+}
+
+// replayCombat calls replayCombat through handleMethod.
+func replayCombat(t *testing.T, server *RPCServer, replayID string) map[string]interface{} {
+	t.Helper()
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id": replaySessions["alice"],
+		"replay_id":  replayID,
+	})
+	require.NoError(t, err)
+
+	result, err := server.handleMethod(MethodReplayCombat, params)
+	require.NoError(t, err)
+	return result.(map[string]interface{})
+}
+
+// fightRecordedCombat runs a one-round combat in which each player casts
+// replay_bolt at the other and ends their turn.
+func fightRecordedCombat(t *testing.T, server *RPCServer, seed int64) string {
+	t.Helper()
+	started := call(t, server, MethodStartCombat, map[string]interface{}{
+		"session_id":      replaySessions["alice"],
+		"participant_ids": []string{"alice", "bob"},
+		"seed":            seed,
+	})
+	assert.Equal(t, seed, started["seed"])
+
+	first := started["first_turn"].(string)
+	second := "bob"
+	if first == "bob" {
+		second = "alice"
+	}
+
+	for _, turn := range [][2]string{{first, second}, {second, first}} {
+		call(t, server, MethodCastSpell, map[string]interface{}{
+			"session_id": replaySessions[turn[0]],
+			"spell_id":   "replay_bolt",
+			"target_id":  turn[1],
+		})
+		call(t, server, MethodEndTurn, map[string]interface{}{"session_id": replaySessions[turn[0]]})
+	}
+	require.False(t, server.state.TurnManager.IsInCombat, "combat ends after the first round")
+
+	return started["replay_id"].(string)
+}
+
+func TestReplayCombat_ReproducesRecordedCombat(t *testing.T) {
+	server := newReplayTestServer(t)
+	replayID := fightRecordedCombat(t, server, 1234)
+
+	replay, err := server.loadCombatReplay(replayID)
+	require.NoError(t, err)
+	assert.Len(t, replay.Calls, 5)
+	require.Len(t, replay.Draws, 4, "two initiative rolls and two damage rolls")
+	assert.Equal(t, drawInitiative, replay.Draws[0].Kind)
+	assert.Equal(t, drawDamage, replay.Draws[2].Kind)
+	assert.Equal(t, 1, replay.Draws[3].Turn)
+	assert.NotEqual(t, replay.Draws[2].TurnSeed, replay.Draws[3].TurnSeed, "each turn has its own seed")
+	assert.Len(t, replay.Outcome, 2)
+
+	alice := server.state.WorldState.Objects["alice"].(*game.Player)
+	bob := server.state.WorldState.Objects["bob"].(*game.Player)
+	hpBefore := []int{alice.HP, bob.HP}
+
+	result := replayCombat(t, server, replayID)
+	assert.Equal(t, true, result["deterministic"], "divergences: %v", result["divergences"])
+	assert.Equal(t, replay.Outcome, result["outcome"])
+	assert.Equal(t, hpBefore, []int{alice.HP, bob.HP}, "replaying does not touch the live world")
+	assert.Same(t, server.state.WorldState.Objects["alice"], alice)
+}
+
+func TestReplayCombat_SameSeedSameDraws(t *testing.T) {
+	first := newReplayTestServer(t)
+	second := newReplayTestServer(t)
+
+	firstReplay, err := first.loadCombatReplay(fightRecordedCombat(t, first, 99))
+	require.NoError(t, err)
+	secondReplay, err := second.loadCombatReplay(fightRecordedCombat(t, second, 99))
+	require.NoError(t, err)
+
+	assert.Equal(t, firstReplay.Draws, secondReplay.Draws)
+	assert.Equal(t, firstReplay.Outcome, secondReplay.Outcome)
+}
+
+func TestReplayCombat_ReportsDivergence(t *testing.T) {
+	server := newReplayTestServer(t)
+	replayID := fightRecordedCombat(t, server, 7)
+
+	// Simulate a balance bug: the recorded damage roll no longer matches
+	server.replays.mu.Lock()
+	server.replays.recent[replayID].Draws[2].Value += 100
+	server.replays.mu.Unlock()
+
+	result := replayCombat(t, server, replayID)
+	assert.Equal(t, false, result["deterministic"])
+	assert.NotEmpty(t, result["divergences"])
+}
+
+func TestReplayCombat_LoadsSavedReplays(t *testing.T) {
+	server := newReplayTestServer(t)
+	replayID := fightRecordedCombat(t, server, 42)
+
+	// Drop the in-memory copy so the replay must come from the store
+	server.replays.mu.Lock()
+	delete(server.replays.recent, replayID)
+	server.replays.mu.Unlock()
+
+	result := replayCombat(t, server, replayID)
+	assert.Equal(t, true, result["deterministic"], "divergences: %v", result["divergences"])
+
+	_, err := server.handleReplayCombat(json.RawMessage(`{"session_id":"11111111-1111-4111-8111-111111111111","replay_id":"00000000-0000-0000-0000-000000000000"}`))
+	assert.ErrorIs(t, err, ErrReplayNotFound)
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultRestHours is the length of a full night's rest, which restores
+	// all hit points and readies memorized spells.
+	defaultRestHours = 8
+	// maxRestHours is the longest rest a single call may take.
+	maxRestHours = 24
+)
+
+// handleMemorizeSpells replaces the spells a player has memorized. The new
+// spells fill the player's spell slots but cannot be cast until the player
+// completes a full rest.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - spell_ids: []string - Spells to memorize; repeat an ID to memorize it more than once
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating if the spells were memorized
+//   - memorized: []game.MemorizedSpell now held by the player
+//   - slots: []int spell slots per spell level, starting at first level
+//   - error: Invalid parameters or session, ErrCombatInProgress during
+//     combat, ErrSpellRequirements if the spells do not fit the slots
+func (s *RPCServer) handleMemorizeSpells(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleMemorizeSpells",
+	})
+	logger.Debug("entering handleMemorizeSpells")
+
+	var req struct {
+		SessionID string   `json:"session_id"`
+		SpellIDs  []string `json:"spell_ids"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid memorize spells parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.state.TurnManager.IsInCombat {
+		return nil, ErrCombatInProgress.WithMessage("spells cannot be memorized during combat")
+	}
+
+	if err := session.Player.MemorizeSpells(req.SpellIDs); err != nil {
+		logger.WithFields(logrus.Fields{
+			"player_id": session.Player.GetID(),
+			"error":     err.Error(),
+		}).Warn("failed to memorize spells")
+		return nil, ErrSpellRequirements.WithMessage("%s", err.Error())
+	}
+
+	return map[string]interface{}{
+		"success":   true,
+		"memorized": session.Player.GetMemorizedSpells(),
+		"slots":     session.Player.SpellSlots(),
+	}, nil
+}
+
+// handleRest lets a player rest outside combat. Each hour of rest advances
+// game time by an hour, heals an eighth of the player's maximum hit points
+// and those of their companions, and may be interrupted by a random
+// encounter. A full night of rest without interruption also readies the
+// player's memorized spells, and any rest left uninterrupted restores some
+// of the companions' morale. Scheduled events come due and effects run out
+// as the hours pass, as with passTime.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - hours: int - Hours to rest (optional, 1 to 24, defaults to 8)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating if the rest took place
+//   - hours_rested: int hours rested before any interruption
+//   - interrupted: bool true if an encounter ended the rest early
+//   - encounter: *EncounterProposal that interrupted the rest (if any)
+//   - healed: int hit points restored
+//   - hp: int hit points after the rest
+//   - spells_restored: bool true if memorized spells were readied
+//   - game_time: int64 game ticks after the rest
+//   - error: Invalid parameters or session, ErrCombatInProgress during combat
+func (s *RPCServer) handleRest(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleRest",
+	})
+	logger.Debug("entering handleRest")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		Hours     int    `json:"hours"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid rest parameters", err.Error())
+	}
+	if req.Hours == 0 {
+		req.Hours = defaultRestHours
+	}
+	if req.Hours < 1 || req.Hours > maxRestHours {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid rest parameters", "hours must be between 1 and 24")
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.state.TurnManager.IsInCombat {
+		return nil, ErrCombatInProgress.WithMessage("cannot rest during combat")
+	}
+
+	player := session.Player
+	startHP := player.GetHP()
+	companions := s.companionsOf(player.ID)
+	result := map[string]interface{}{"success": true}
+
+	hours := 0
+	for hours < req.Hours {
+		s.state.stateMu.Lock()
+		s.state.TimeManager.Skip(TicksPerHour)
+		s.state.stateMu.Unlock()
+		s.updateWeather()
+		s.runScheduledEvents()
+		s.checkQuestDeadlines()
+		hours++
+
+		player.SetHP(player.GetHP() + restHealing(player.GetMaxHP(), hours))
+		for _, companion := range companions {
+			companion.Heal(restHealing(companion.NPC.MaxHP, hours))
+		}
+
+		if encounter := s.checkRandomEncounter(session, player.GetPosition()); encounter != nil {
+			result["encounter"] = encounter
+			break
+		}
+	}
+
+	s.expireEffects(time.Duration(int64(hours)*TicksPerHour) * time.Second)
+
+	interrupted := hours < req.Hours
+	spellsRestored := !interrupted && hours >= defaultRestHours
+	if spellsRestored {
+		player.RestoreSpells()
+	}
+	if !interrupted {
+		for _, companion := range companions {
+			companion.Treat(game.TreatmentRested)
+		}
+	}
+
+	s.state.stateMu.RLock()
+	gameTime := s.state.TimeManager.CurrentTime.GameTicks
+	s.state.stateMu.RUnlock()
+
+	logger.WithFields(logrus.Fields{
+		"player_id":   player.GetID(),
+		"hours":       hours,
+		"interrupted": interrupted,
+	}).Info("player rested")
+
+	result["hours_rested"] = hours
+	result["interrupted"] = interrupted
+	result["healed"] = player.GetHP() - startHP
+	result["hp"] = player.GetHP()
+	result["spells_restored"] = spellsRestored
+	result["game_time"] = gameTime
+	return result, nil
+}
+
+// restHealing returns the hit points healed in the given hour of rest, so
+// that eight hours heal maxHP in total without rounding each hour away.
+func restHealing(maxHP, hour int) int {
+	return maxHP*hour/defaultRestHours - maxHP*(hour-1)/defaultRestHours
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createSpellcasterSession creates a third-level mage session that knows the
+// first-level spell "rest_ward".
+func createSpellcasterSession(t *testing.T, server *RPCServer) (*PlayerSession, *game.Spell) {
+	t.Helper()
+	spell := &game.Spell{ID: "rest_ward", Name: "Rest Ward", Level: 1, School: game.SchoolAbjuration}
+	require.NoError(t, server.spellManager.AddSpell(spell))
+
+	session := createEventSourcedTestSession(t, server)
+	session.Player.Class = game.ClassMage
+	session.Player.Level = 3
+	session.Player.Intelligence = 16
+	require.NoError(t, session.Player.LearnSpell(*spell))
+	return session, spell
+}
+
+func TestMemorizeRestAndCast(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session, _ := createSpellcasterSession(t, server)
+	cast, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "spell_id": "rest_ward"})
+	require.NoError(t, err)
+
+	_, err = server.handleCastSpell(cast)
+	assert.ErrorIs(t, err, ErrSpellRequirements, "unmemorized spells cannot be cast")
+
+	params, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "spell_ids": []string{"rest_ward", "rest_ward", "rest_ward"}})
+	require.NoError(t, err)
+	_, err = server.handleMethod(MethodMemorizeSpells, params)
+	assert.ErrorIs(t, err, ErrSpellRequirements, "a third-level mage has two first-level slots")
+
+	params, err = json.Marshal(map[string]interface{}{"session_id": session.SessionID, "spell_ids": []string{"rest_ward", "rest_ward"}})
+	require.NoError(t, err)
+	result, err := server.handleMethod(MethodMemorizeSpells, params)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, result.(map[string]interface{})["slots"])
+
+	_, err = server.handleCastSpell(cast)
+	assert.ErrorIs(t, err, ErrSpellRequirements, "memorized spells are ready after resting")
+
+	session.Player.SetHP(20)
+	before := server.state.TimeManager.CurrentTime.GameTicks
+	params, err = json.Marshal(map[string]interface{}{"session_id": session.SessionID})
+	require.NoError(t, err)
+	result, err = server.handleMethod(MethodRest, params)
+	require.NoError(t, err)
+	rest := result.(map[string]interface{})
+	assert.Equal(t, 8, rest["hours_rested"])
+	assert.Equal(t, false, rest["interrupted"])
+	assert.Equal(t, true, rest["spells_restored"])
+	assert.Equal(t, 80, rest["healed"])
+	assert.Equal(t, 100, session.Player.GetHP())
+	assert.Equal(t, before+8*TicksPerHour, rest["game_time"])
+
+	for i := 0; i < 2; i++ {
+		_, err = server.handleCastSpell(cast)
+		require.NoError(t, err)
+	}
+	_, err = server.handleCastSpell(cast)
+	assert.ErrorIs(t, err, ErrSpellRequirements, "casting uses up the memorized spells")
+}
+
+func TestRest_InterruptedByEncounter(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.config.RandomEncountersEnabled = true
+	server.config.EncounterCooldownSteps = 100
+	server.attachEncounters()
+	server.encounters.SetTable(pcg.BiomeDungeon, alwaysEncounter)
+	session, spell := createSpellcasterSession(t, server)
+	server.state.WorldState.Width, server.state.WorldState.Height = 30, 30
+	t.Cleanup(server.state.TurnManager.EndCombat)
+	require.NoError(t, session.Player.MemorizeSpells([]string{"rest_ward"}))
+	session.Player.SetHP(20)
+
+	_, err := server.handleRest(json.RawMessage(`{"session_id":"` + session.SessionID + `","hours":30}`))
+	assert.ErrorContains(t, err, "Invalid rest parameters")
+
+	params, err := json.Marshal(map[string]interface{}{"session_id": session.SessionID, "hours": 8})
+	require.NoError(t, err)
+	result, err := server.handleRest(params)
+	require.NoError(t, err)
+
+	rest := result.(map[string]interface{})
+	assert.Equal(t, 1, rest["hours_rested"])
+	assert.Equal(t, true, rest["interrupted"])
+	assert.IsType(t, &EncounterProposal{}, rest["encounter"])
+	assert.Equal(t, 12, rest["healed"], "an hour heals an eighth of maximum HP")
+	assert.Equal(t, false, rest["spells_restored"])
+	assert.Error(t, session.Player.CanCastMemorized(*spell))
+}
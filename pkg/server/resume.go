@@ -0,0 +1,262 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// resumeTokenBytes is the number of random bytes in a session resume token.
+const resumeTokenBytes = 32
+
+// bufferedEvent is a broadcast message tagged with its sequence number.
+type bufferedEvent struct {
+	seq     uint64
+	message map[string]interface{}
+}
+
+// eventBuffer keeps the most recent broadcast events for a session so a
+// client that reconnects can replay what it missed. It is a fixed-size
+// ring: once full, the oldest event is discarded for each new one.
+type eventBuffer struct {
+	mu       sync.Mutex
+	events   []bufferedEvent
+	start    int
+	capacity int
+	evicted  uint64 // Sequence number of the newest discarded event
+}
+
+// newEventBuffer creates an empty buffer holding up to capacity events.
+func newEventBuffer(capacity int) *eventBuffer {
+	return &eventBuffer{
+		events:   make([]bufferedEvent, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// add appends an event, evicting the oldest one when the buffer is full.
+func (b *eventBuffer) add(seq uint64, message map[string]interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.capacity <= 0 {
+		return
+	}
+	if len(b.events) < b.capacity {
+		b.events = append(b.events, bufferedEvent{seq: seq, message: message})
+		return
+	}
+	b.evicted = b.events[b.start].seq
+	b.events[b.start] = bufferedEvent{seq: seq, message: message}
+	b.start = (b.start + 1) % b.capacity
+}
+
+// since returns the buffered events with a sequence number greater than
+// lastSeq, oldest first. truncated reports that events after lastSeq were
+// already evicted, so the client cannot fully catch up from the buffer.
+func (b *eventBuffer) since(lastSeq uint64) (events []map[string]interface{}, truncated bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events = make([]map[string]interface{}, 0, len(b.events))
+	for i := range b.events {
+		event := b.events[(b.start+i)%len(b.events)]
+		if event.seq > lastSeq {
+			events = append(events, event.message)
+		}
+	}
+	return events, b.evicted > lastSeq
+}
+
+// newResumeToken returns a random hex-encoded session resume token.
+func newResumeToken() (string, error) {
+	buf := make([]byte, resumeTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate resume token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// resumeTimeout returns how long a disconnected session can be resumed.
+// Resume tokens expire together with the session itself.
+func (s *RPCServer) resumeTimeout() time.Duration {
+	if s.config != nil {
+		if timeout := s.config.GetSessionTimeout(); timeout > 0 {
+			return timeout
+		}
+	}
+	return sessionTimeout
+}
+
+// findSessionByResumeToken returns the session owning token, or nil when
+// no live session matches. The caller must hold s.mu.
+func (s *RPCServer) findSessionByResumeToken(token string) *PlayerSession {
+	for _, session := range s.sessions {
+		if session == nil || session.resumeToken == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(session.resumeToken), []byte(token)) == 1 {
+			return session
+		}
+	}
+	return nil
+}
+
+// handleReconnectSession reattaches the calling WebSocket connection to a
+// session created earlier by joinGame and replays the events it missed.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session of the calling connection (set by the WebSocket layer)
+//   - resume_token: string - Token returned by joinGame or a previous reconnectSession
+//   - last_seq: uint64 - Sequence number of the last event the client received (optional)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating the session was resumed
+//   - session_id: The resumed session ID, used for all further requests on this connection
+//   - resume_token: A fresh token replacing the one just used
+//   - missed_events: Buffered game_event messages newer than last_seq, oldest first
+//   - replay_truncated: true if some missed events were already evicted from the buffer
+//   - error: Invalid parameters, a non-WebSocket caller, or an unknown or expired token
+//
+// Replayed events may overlap with events delivered live while the call is
+// in progress; clients should discard events whose seq they have already seen.
+func (s *RPCServer) handleReconnectSession(params json.RawMessage) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "handleReconnectSession",
+	}).Debug("entering handleReconnectSession")
+
+	var req struct {
+		SessionID   string `json:"session_id"`
+		ResumeToken string `json:"resume_token"`
+		LastSeq     uint64 `json:"last_seq"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "handleReconnectSession",
+			"error":    err.Error(),
+		}).Error("failed to unmarshal reconnect parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid reconnect parameters", err.Error())
+	}
+
+	newToken, err := newResumeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	caller, exists := s.sessions[req.SessionID]
+	if !exists || caller.WSConn == nil {
+		s.mu.Unlock()
+		return nil, NewJSONRPCError(JSONRPCInvalidRequest, "reconnectSession must be called over a WebSocket connection", nil)
+	}
+
+	target := s.findSessionByResumeToken(req.ResumeToken)
+	if target == nil || time.Since(target.LastActive) > s.resumeTimeout() {
+		s.mu.Unlock()
+		logrus.WithFields(logrus.Fields{
+			"function":  "handleReconnectSession",
+			"sessionID": req.SessionID,
+		}).Warn("rejected unknown or expired resume token")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid or expired resume token", nil)
+	}
+
+	conn := caller.WSConn
+	if target != caller {
+		if stale := target.WSConn; stale != nil && stale != conn {
+			// A half-open connection from before the drop; the new one replaces it
+			stale.Close()
+		}
+		target.WSConn = conn
+		caller.WSConn = nil
+		caller.Connected = false
+	}
+	target.Connected = true
+	target.LastActive = time.Now()
+	target.resumeToken = newToken
+	events := target.events
+	s.mu.Unlock()
+
+	missed := []map[string]interface{}{}
+	truncated := false
+	if events != nil {
+		missed, truncated = events.since(req.LastSeq)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":      "handleReconnectSession",
+		"sessionID":     target.SessionID,
+		"missed_events": len(missed),
+		"truncated":     truncated,
+	}).Info("session resumed on new websocket connection")
+
+	return map[string]interface{}{
+		"success":          true,
+		"session_id":       target.SessionID,
+		"resume_token":     newToken,
+		"missed_events":    missed,
+		"replay_truncated": truncated,
+	}, nil
+}
+
+// attachConnection marks conn as the live WebSocket of session and records
+// this instance as the session's owner in the shared session store.
+func (s *RPCServer) attachConnection(session *PlayerSession, conn *websocket.Conn) {
+	s.mu.Lock()
+	session.WSConn = conn
+	session.Connected = true
+	s.mu.Unlock()
+
+	s.shareSession(session)
+}
+
+// detachConnection clears conn from whichever session currently owns it,
+// leaving that session resumable until it times out.
+func (s *RPCServer) detachConnection(conn *websocket.Conn) {
+	var detached []*PlayerSession
+	s.mu.Lock()
+	for _, session := range s.sessions {
+		if session != nil && session.WSConn == conn {
+			session.WSConn = nil
+			session.Connected = false
+			detached = append(detached, session)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, session := range detached {
+		s.shareSession(session)
+	}
+}
+
+// sessionForConn returns the session conn is currently attached to, or nil.
+func (s *RPCServer) sessionForConn(conn *websocket.Conn) *PlayerSession {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, session := range s.sessions {
+		if session != nil && session.WSConn == conn {
+			return session
+		}
+	}
+	return nil
+}
+
+// bufferForResume records a broadcast event in the replay buffer of every
+// resumable session, whether or not it is currently connected.
+func (wb *WebSocketBroadcaster) bufferForResume(seq uint64, message map[string]interface{}) {
+	wb.server.mu.RLock()
+	defer wb.server.mu.RUnlock()
+	for _, session := range wb.server.sessions {
+		if session != nil && session.events != nil {
+			session.events.add(seq, message)
+		}
+	}
+}
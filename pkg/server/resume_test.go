@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialResumeTestSocket opens a WebSocket to server through HandleWebSocket,
+// backed by a fresh anonymous session as the HTTP middleware would create.
+func dialResumeTestSocket(t *testing.T, server *RPCServer) *websocket.Conn {
+	t.Helper()
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := &PlayerSession{
+			SessionID:   uuid.New().String(),
+			CreatedAt:   time.Now(),
+			LastActive:  time.Now(),
+			MessageChan: make(chan []byte, MessageChanBufferSize),
+		}
+		server.setSession(session.SessionID, session)
+		server.HandleWebSocket(w, r.WithContext(context.WithValue(r.Context(), sessionKey, session)))
+	}))
+	t.Cleanup(endpoint.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(endpoint.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	// Session confirmation
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var confirmation map[string]interface{}
+	require.NoError(t, client.ReadJSON(&confirmation))
+	return client
+}
+
+// readResumeTestResponse reads messages until the RPC response with id arrives.
+func readResumeTestResponse(t *testing.T, client *websocket.Conn, id float64) map[string]interface{} {
+	t.Helper()
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		var message map[string]interface{}
+		require.NoError(t, client.ReadJSON(&message))
+		if message["id"] == id {
+			return message
+		}
+	}
+}
+
+func joinResumeTestGame(t *testing.T, server *RPCServer) (sessionID, token string) {
+	t.Helper()
+	result, err := server.handleJoinGame(json.RawMessage(`{"player_name":"Rowan"}`))
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	require.Len(t, response["resume_token"], 2*resumeTokenBytes)
+	return response["session_id"].(string), response["resume_token"].(string)
+}
+
+func TestEventBuffer_EvictsOldestAndReportsTruncation(t *testing.T) {
+	buffer := newEventBuffer(3)
+	for seq := uint64(1); seq <= 5; seq++ {
+		buffer.add(seq, map[string]interface{}{"seq": seq})
+	}
+
+	events, truncated := buffer.since(3)
+	assert.False(t, truncated)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint64(4), events[0]["seq"])
+	assert.Equal(t, uint64(5), events[1]["seq"])
+
+	events, truncated = buffer.since(1)
+	assert.True(t, truncated, "events 2 was evicted")
+	assert.Len(t, events, 3)
+}
+
+func TestReconnectSession_ReplaysMissedEvents(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	sessionID, token := joinResumeTestGame(t, server)
+
+	// Events broadcast while the joined session has no connection
+	for i := 0; i < 3; i++ {
+		server.broadcaster.handleEvent(game.GameEvent{Type: game.EventMovement, SourceID: "goblin", Timestamp: time.Now().Unix()})
+	}
+
+	client := dialResumeTestSocket(t, server)
+	require.NoError(t, client.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "reconnectSession",
+		"params":  map[string]interface{}{"resume_token": token, "last_seq": 1},
+		"id":      1,
+	}))
+	response := readResumeTestResponse(t, client, 1)
+	require.Nil(t, response["error"])
+
+	result := response["result"].(map[string]interface{})
+	assert.Equal(t, sessionID, result["session_id"])
+	assert.NotEqual(t, token, result["resume_token"], "tokens are rotated on use")
+	assert.Equal(t, false, result["replay_truncated"])
+	missed := result["missed_events"].([]interface{})
+	require.Len(t, missed, 2)
+	assert.Equal(t, 2.0, missed[0].(map[string]interface{})["seq"])
+	assert.Equal(t, 3.0, missed[1].(map[string]interface{})["seq"])
+
+	// Live events now reach the resumed session over the new connection
+	server.broadcaster.handleEvent(game.GameEvent{Type: game.EventMovement, SourceID: "goblin", Timestamp: time.Now().Unix()})
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var live map[string]interface{}
+	require.NoError(t, client.ReadJSON(&live))
+	assert.Equal(t, 4.0, live["seq"])
+
+	// Dropping the connection leaves the session resumable
+	client.Close()
+	assert.Eventually(t, func() bool {
+		session, ok := server.getSession(sessionID)
+		if !ok {
+			return false
+		}
+		defer server.releaseSession(session)
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		return session.WSConn == nil && !session.Connected
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestReconnectSession_RejectsStaleAndExpiredTokens(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	sessionID, token := joinResumeTestGame(t, server)
+	client := dialResumeTestSocket(t, server)
+
+	reconnect := func(id int, token string) map[string]interface{} {
+		require.NoError(t, client.WriteJSON(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "reconnectSession",
+			"params":  map[string]interface{}{"resume_token": token},
+			"id":      id,
+		}))
+		return readResumeTestResponse(t, client, float64(id))
+	}
+
+	first := reconnect(1, token)
+	require.Nil(t, first["error"])
+	rotated := first["result"].(map[string]interface{})["resume_token"].(string)
+
+	assert.NotNil(t, reconnect(2, token)["error"], "a used token cannot be replayed")
+
+	server.mu.Lock()
+	server.sessions[sessionID].LastActive = time.Now().Add(-2 * server.resumeTimeout())
+	server.mu.Unlock()
+	assert.NotNil(t, reconnect(3, rotated)["error"], "tokens expire with the session timeout")
+}
+
+func TestReconnectSession_RequiresWebSocket(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	_, token := joinResumeTestGame(t, server)
+
+	_, err := server.handleReconnectSession(json.RawMessage(`{"session_id":"` + uuid.New().String() + `","resume_token":"` + token + `"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"testing"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitializeRuleset(t *testing.T) {
+	t.Cleanup(func() { game.SetActiveRuleset(nil) })
+	logger := logrus.WithField("test", "TestInitializeRuleset")
+
+	require.NoError(t, initializeRuleset(&config.Config{Ruleset: "adnd1e"}, logger))
+	require.NotNil(t, game.ActiveRuleset())
+	assert.Equal(t, "adnd1e", game.ActiveRuleset().Name)
+
+	err := initializeRuleset(&config.Config{Ruleset: "basic"}, logger)
+	assert.ErrorContains(t, err, `ruleset "basic" not found`)
+
+	require.NoError(t, initializeRuleset(&config.Config{}, logger))
+	assert.Nil(t, game.ActiveRuleset(), "no ruleset selects the built-in rules")
+}
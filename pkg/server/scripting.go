@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/scripting"
+
+	"github.com/sirupsen/logrus"
+)
+
+// scriptFile holds the campaign's hook scripts
+const scriptFile = "data/scripts/hooks.yaml"
+
+// attachScripting creates the scripting engine, loads the campaign's hook
+// scripts and fires them from game events. Without a script file the
+// engine runs no scripts.
+func (s *RPCServer) attachScripting() error {
+	s.scripts = scripting.NewEngine(scripting.DefaultBudget())
+	s.registerScriptFuncs()
+
+	if path := findDataFile(scriptFile); path == "" {
+		logrus.WithField("function", "attachScripting").Warn("script file not found - continuing without scripts")
+	} else if err := s.scripts.LoadFromFile(path); err != nil {
+		return fmt.Errorf("failed to load scripts: %w", err)
+	}
+
+	s.eventSys.Subscribe(game.EventDeath, func(event game.GameEvent) {
+		fields := map[string]scripting.Value{"victim_id": event.SourceID}
+		if pos, ok := event.Data["position"].(game.Position); ok {
+			fields["x"], fields["y"], fields["level"] = pos.X, pos.Y, pos.Level
+		}
+		s.fireScripts(scripting.HookKill, fields)
+	})
+	s.eventSys.Subscribe(game.EventMovement, s.handleRoomEntry)
+	return nil
+}
+
+// handleRoomEntry fires onEnterRoom when a movement crosses into a room
+func (s *RPCServer) handleRoomEntry(event game.GameEvent) {
+	oldPos, _ := event.Data["old_position"].(game.Position)
+	newPos, ok := event.Data["new_position"].(game.Position)
+	if !ok {
+		return
+	}
+
+	world := s.state.WorldState
+	roomID := world.RoomAt(newPos)
+	if roomID == "" || (oldPos.Level == newPos.Level && world.RoomAt(oldPos) == roomID) {
+		return
+	}
+	s.fireScripts(scripting.HookEnterRoom, map[string]scripting.Value{
+		"player_id": event.SourceID,
+		"room_id":   roomID,
+		"level":     newPos.Level,
+		"x":         newPos.X,
+		"y":         newPos.Y,
+	})
+}
+
+// fireQuestCompleteScripts fires onQuestComplete for each player, in ID
+// order
+func (s *RPCServer) fireQuestCompleteScripts(questID string, playerIDs ...string) {
+	sort.Strings(playerIDs)
+	for _, id := range playerIDs {
+		s.fireScripts(scripting.HookQuestComplete, map[string]scripting.Value{
+			"player_id": id,
+			"quest_id":  questID,
+		})
+	}
+}
+
+// fireScripts runs the scripts attached to hook. Script failures are
+// logged by the engine and never fail the action that fired the hook.
+func (s *RPCServer) fireScripts(hook scripting.Hook, event map[string]scripting.Value) {
+	if s.scripts == nil {
+		return
+	}
+	_ = s.scripts.Fire(context.Background(), hook, event)
+}
+
+// registerScriptFuncs exposes the host functions scripts may call to
+// affect world state
+func (s *RPCServer) registerScriptFuncs() {
+	s.scripts.RegisterFunc("log", func(args []scripting.Value) (scripting.Value, error) {
+		message, err := scripting.StringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		logrus.WithField("function", "scriptLog").Info(message)
+		return nil, nil
+	})
+
+	s.scripts.RegisterFunc("give_gold", func(args []scripting.Value) (scripting.Value, error) {
+		player, amount, err := s.scriptPlayerAmount(args)
+		if err != nil {
+			return nil, err
+		}
+		if player.Character.Gold+amount < 0 {
+			return nil, fmt.Errorf("player %s has only %d gold", player.ID, player.Character.Gold)
+		}
+		player.Character.Gold += amount
+		logrus.WithFields(logrus.Fields{
+			"function":  "scriptGiveGold",
+			"player_id": player.ID,
+			"amount":    amount,
+			"new_gold":  player.Character.Gold,
+		}).Info("script changed player gold")
+		return player.Character.Gold, nil
+	})
+
+	s.scripts.RegisterFunc("give_experience", func(args []scripting.Value) (scripting.Value, error) {
+		player, amount, err := s.scriptPlayerAmount(args)
+		if err != nil {
+			return nil, err
+		}
+		if amount <= 0 {
+			return nil, fmt.Errorf("experience must be positive, got %d", amount)
+		}
+		if err := player.AddExperience(int64(amount)); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+
+	s.scripts.RegisterFunc("heal", func(args []scripting.Value) (scripting.Value, error) {
+		player, amount, err := s.scriptPlayerAmount(args)
+		if err != nil {
+			return nil, err
+		}
+		if amount <= 0 {
+			return nil, fmt.Errorf("healing must be positive, got %d", amount)
+		}
+		player.SetHealth(player.GetHealth() + amount)
+		return player.GetHealth(), nil
+	})
+}
+
+// scriptPlayerAmount reads the (player_id, amount) arguments shared by the
+// reward host functions
+func (s *RPCServer) scriptPlayerAmount(args []scripting.Value) (*game.Player, int, error) {
+	if len(args) != 2 {
+		return nil, 0, fmt.Errorf("expects 2 arguments, got %d", len(args))
+	}
+	playerID, err := scripting.StringArg(args, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	amount, err := scripting.NumberArg(args, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if amount != math.Trunc(amount) || math.Abs(amount) > math.MaxInt32 {
+		return nil, 0, fmt.Errorf("amount must be a whole number, got %v", amount)
+	}
+
+	player := s.connectedPlayer(playerID)
+	if player == nil {
+		return nil, 0, fmt.Errorf("player %s is not connected", playerID)
+	}
+	return player, int(amount), nil
+}
+
+// connectedPlayer returns the player with the given ID if they have a
+// session, or nil
+func (s *RPCServer) connectedPlayer(playerID string) *game.Player {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, session := range s.sessions {
+		if session.Player != nil && session.Player.ID == playerID {
+			return session.Player
+		}
+	}
+	return nil
+}
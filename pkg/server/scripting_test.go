@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/scripting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScripting_QuestCompleteHook(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Character.Gold = 10
+	session.Player.QuestLog = []game.Quest{{
+		ID:         "rescue",
+		Status:     game.QuestActive,
+		Objectives: []game.QuestObjective{{Progress: 1, Required: 1, Completed: true}},
+		Rewards:    []game.QuestReward{{Type: "gold", Value: 5}},
+	}}
+
+	require.NoError(t, server.scripts.Load([]scripting.Definition{{
+		Name:   "rescue_bonus",
+		Hook:   scripting.HookQuestComplete,
+		Source: `if event.quest_id == "rescue" { give_gold(event.player_id, 100) }`,
+	}}))
+
+	_, err := server.handleCompleteQuest(json.RawMessage(`{"session_id":"test-session-001","quest_id":"rescue"}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, 115, session.Player.Character.Gold)
+}
+
+func TestScripting_EnterRoomHook(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	tiles := make([][]game.Tile, 20)
+	for y := range tiles {
+		tiles[y] = make([]game.Tile, 20)
+		for x := range tiles[y] {
+			tiles[y][x] = game.Tile{Type: game.TileFloor, Walkable: true}
+			if x >= 12 {
+				tiles[y][x].Properties = map[string]interface{}{"room_id": "vault"}
+			}
+		}
+	}
+	server.state.WorldState.Levels = []game.Level{{ID: "test", Width: 20, Height: 20, Tiles: tiles}}
+
+	require.NoError(t, server.scripts.Load([]scripting.Definition{{
+		Name: "vault_entries",
+		Hook: scripting.HookEnterRoom,
+		Source: `
+let entries = get_flag(event.room_id)
+if entries == nil { entries = 0 }
+set_flag(event.room_id, entries + 1)
+`,
+	}}))
+
+	for _, x := range []int{11, 12, 13} {
+		require.NoError(t, server.executePlayerMovement(session.Player, game.Position{X: x, Y: 10}))
+	}
+
+	assert.Eventually(t, func() bool {
+		return server.scripts.Flags()["vault"] == 1.0
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, map[string]scripting.Value{"vault": 1.0}, server.scripts.Flags(), "moving within a room does not re-enter it")
+}
+
+func TestScripting_KillHook(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	require.NoError(t, server.scripts.Load([]scripting.Definition{{
+		Name:   "bounty",
+		Hook:   scripting.HookKill,
+		Source: `set_flag("last_kill", event.victim_id + "@" + event.x + "," + event.y)`,
+	}}))
+
+	server.eventSys.Emit(game.GameEvent{
+		Type:     game.EventDeath,
+		SourceID: "goblin-1",
+		Data:     map[string]interface{}{"position": game.Position{X: 4, Y: 7}},
+	})
+
+	assert.Eventually(t, func() bool {
+		return server.scripts.Flags()["last_kill"] == "goblin-1@4,7"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestScripting_HostFunctions(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.Character.Gold = 20
+	session.Player.SetHealth(50)
+
+	fire := func(source string) error {
+		require.NoError(t, server.scripts.Load([]scripting.Definition{{
+			Name: "test", Hook: scripting.HookKill, Source: source,
+		}}))
+		return server.scripts.Fire(context.Background(), scripting.HookKill, map[string]scripting.Value{})
+	}
+
+	require.NoError(t, fire(`
+set_flag("hp", heal("test-player-001", 20))
+give_gold("test-player-001", -5)
+give_experience("test-player-001", 250)
+log("rewarded")
+`))
+	assert.Equal(t, 70.0, server.scripts.Flags()["hp"])
+	assert.Equal(t, 15, session.Player.Character.Gold)
+	assert.Equal(t, int64(250), session.Player.Experience)
+
+	tests := []struct {
+		source  string
+		message string
+	}{
+		{`give_gold("nobody", 5)`, "player nobody is not connected"},
+		{`give_gold("test-player-001", -100)`, "has only 15 gold"},
+		{`give_gold("test-player-001", 1.5)`, "whole number"},
+		{`heal("test-player-001", 0)`, "healing must be positive"},
+		{`give_experience("test-player-001")`, "expects 2 arguments"},
+	}
+	for _, tt := range tests {
+		err := fire(tt.source)
+		require.Error(t, err, tt.source)
+		assert.Contains(t, err.Error(), tt.message)
+	}
+	assert.Equal(t, 15, session.Player.Character.Gold)
+}
@@ -3,10 +3,12 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -15,10 +17,19 @@ import (
 
 	"goldbox-rpg/pkg/config"
 	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/lockstat"
+	"goldbox-rpg/pkg/logging"
 	"goldbox-rpg/pkg/pcg"
 	"goldbox-rpg/pkg/pcg/items"
+	"goldbox-rpg/pkg/pcg/levels"
+	"goldbox-rpg/pkg/pcg/plugins"
 	"goldbox-rpg/pkg/pcg/quests"
+	"goldbox-rpg/pkg/pcg/terrain"
+	"goldbox-rpg/pkg/pcg/utils"
 	"goldbox-rpg/pkg/persistence"
+	"goldbox-rpg/pkg/resilience"
+	"goldbox-rpg/pkg/scripting"
+	"goldbox-rpg/pkg/tracing"
 	"goldbox-rpg/pkg/validation"
 )
 
@@ -30,6 +41,9 @@ const (
 	JSONRPCMethodNotFound = -32601 // The method does not exist / is not available
 	JSONRPCInvalidParams  = -32602 // Invalid method parameter(s)
 	JSONRPCInternalError  = -32603 // Internal JSON-RPC error
+
+	// Server-defined error codes
+	JSONRPCRateLimited = -32029 // The session exceeded its request rate limit
 )
 
 // Custom error types for JSON-RPC error handling
@@ -79,34 +93,77 @@ func NewJSONRPCError(code int, message string, data interface{}) *JSONRPCError {
 	sessions   map[string]*PlayerSession
 }*/
 
+// serverLock names the RPCServer lock, which guards the session map, in
+// lock contention statistics
+type serverLock struct{}
+
+func (serverLock) LockName() string { return "server.sessions" }
+
 // RPCServer handles RPC requests and maintains game state.
 type RPCServer struct {
-	webDir        string
-	fileServer    http.Handler
-	state         *GameState
-	eventSys      *game.EventSystem
-	mu            sync.RWMutex
-	timekeeper    *TimeManager
-	sessions      map[string]*PlayerSession
-	done          chan struct{}
-	spellManager  *game.SpellManager
-	pcgManager    *pcg.PCGManager            // Procedural content generation manager
-	Addr          net.Addr                   // Address the server is listening on
-	broadcaster   *WebSocketBroadcaster      // WebSocket event broadcaster
-	config        *config.Config             // Server configuration
-	validator     *validation.InputValidator // Input validation
-	healthChecker *HealthChecker             // Health check system
-	metrics       *Metrics                   // Prometheus metrics
-	profiling     *ProfilingServer           // Performance profiling server
-	perfMonitor   *PerformanceMonitor        // Performance metrics monitor
-	perfAlerter   *PerformanceAlerter        // Performance alerting system
-	rateLimiter   *RateLimiter               // Rate limiting system
-	fileStore     interface {                // File-based persistence
-		Save(string, interface{}) error
-		Load(string, interface{}) error
-		Exists(string) bool
-	}
-	autoSaveCancel context.CancelFunc // Auto-save cancellation function
+	webDir         string
+	fileServer     http.Handler
+	state          *GameState
+	eventSys       *game.EventSystem
+	mu             lockstat.RWMutex[serverLock] // Guards sessions and other server fields
+	timekeeper     *TimeManager
+	sessions       map[string]*PlayerSession
+	done           chan struct{}
+	spellManager   *game.SpellManager
+	levelUps       *game.LevelUpService       // Trains players for the levels they earn
+	pcgManager     *pcg.PCGManager            // Procedural content generation manager
+	pcgEvents      *pcg.PCGEventManager       // PCG runtime adjustment tracking
+	pcgPlugins     []*plugins.Plugin          // External generator processes
+	lootTables     *items.LootTableRegistry   // Data-driven loot tables
+	bundleQuests   map[string]*game.Quest     // Quests of mounted content bundles, by ID
+	parties        *PartyManager              // Player parties and shared quests
+	companions     *CompanionManager          // Companions hired by players
+	merchants      *MerchantManager           // Shop merchants of levels in the world
+	webhooks       *WebhookDispatcher         // Outbound game event webhooks (nil when disabled)
+	analytics      *AnalyticsExporter         // Anonymized gameplay analytics (nil when opted out)
+	Addr           net.Addr                   // Address the server is listening on
+	broadcaster    *WebSocketBroadcaster      // WebSocket event broadcaster
+	config         *config.Config             // Server configuration
+	validator      *validation.InputValidator // Input validation
+	healthChecker  *HealthChecker             // Health check system
+	loadShedder    *resilience.LoadShedder    // Sheds requests while unhealthy (nil when disabled)
+	metrics        *Metrics                   // Prometheus metrics
+	profiling      *ProfilingServer           // Performance profiling server
+	perfMonitor    *PerformanceMonitor        // Performance metrics monitor
+	perfAlerter    *PerformanceAlerter        // Performance alerting system
+	rateLimiter    *RateLimiter               // Rate limiting system
+	sessionLimiter *SessionRateLimiter        // Per-session RPC rate limiting
+	admin          *adminConsole              // Admin method authorization, nil when no admin token is configured
+	audit          *requestAuditor            // Sampled request/response records, nil when auditing is disabled
+	connWriters    sync.Map                   // Per-connection WebSocket write locks
+	previews       previewCache               // Generated content awaiting commitGeneratedContent
+	idempotency    idempotencyCache           // Responses of calls made with an idempotency key, for retries
+	feedback       feedbackGuard              // Content feedback rate limiting and deduplication
+	replays        combatRecorder             // Combat replay recording
+	combatLogs     combatLog                  // Structured combat logs
+	journal        actionJournal              // Undoable admin actions per session
+	chronicle      chronicle                  // Notable moments per player, for session recaps
+	campaignStats  campaignStats              // Campaign statistics and leaderboards
+	annotations    annotationBook             // GM notes, markers and region labels per level
+	tension        *TensionDirector           // Shared music/tension pacing
+	encounters     *RandomEncounterSystem     // Random encounters while exploring, nil when disabled
+	worldEvents    *WorldEventDirector        // World events under way, nil when disabled
+	scripts        *scripting.Engine          // Campaign event hook scripts
+
+	tracingShutdown tracing.ShutdownFunc // Flushes OpenTelemetry spans on Close
+
+	// Horizontal scaling
+	sessionStore     SessionStore // Sessions shared with other instances (nil when not shared)
+	instanceID       string       // This instance in session affinity metadata
+	sessionSubCancel func()       // Stops receiving messages from other instances
+
+	// Persistence
+	store          persistence.Store            // Game state persistence backend
+	backups        *persistence.BackupStore     // Save data backups (nil when disabled)
+	snapshots      *persistence.SnapshotManager // Whole-state auto-save snapshots (nil when disabled)
+	events         *persistence.EventLog        // Changes since the last auto-save (nil in snapshot mode)
+	persistMu      sync.Mutex                   // Serializes saves with backup and snapshot restores
+	autoSaveCancel context.CancelFunc           // Auto-save cancellation function
 }
 
 // NewRPCServer creates and initializes a new RPCServer instance with configuration.
@@ -174,8 +231,102 @@ func initializeSpellManager(logger *logrus.Entry) (*game.SpellManager, error) {
 	return spellManager, nil
 }
 
-// setupPCGManager initializes and configures the PCG manager with default generators.
-func setupPCGManager(logger *logrus.Entry) (*pcg.PCGManager, error) {
+// initializeLootTables loads the data-driven loot tables. A missing loot
+// table file is not fatal; the registry starts empty and tables can be
+// added later.
+func initializeLootTables(logger *logrus.Entry) (*items.LootTableRegistry, error) {
+	registry := items.NewLootTableRegistry(nil)
+
+	lootFile := "data/pcg/items/loot_tables.yaml"
+	if _, err := os.Stat(lootFile); os.IsNotExist(err) {
+		lootFile = "../../data/pcg/items/loot_tables.yaml"
+		if _, err := os.Stat(lootFile); os.IsNotExist(err) {
+			logger.Warn("loot table file not found - continuing without loot tables")
+			return registry, nil
+		}
+	}
+
+	if err := registry.LoadFromFile(lootFile); err != nil {
+		logger.WithError(err).Error("failed to load loot tables")
+		return nil, fmt.Errorf("failed to load loot tables: %w", err)
+	}
+
+	logger.WithField("tableCount", len(registry.TableNames())).Info("loaded loot tables from YAML file")
+	return registry, nil
+}
+
+// initializeRuleset activates the rules profile selected by the RULESET
+// setting, or the built-in rules when none is selected, and whether earned
+// levels wait for levelUp. A selected profile that is missing or invalid is
+// fatal.
+func initializeRuleset(cfg *config.Config, logger *logrus.Entry) error {
+	game.SetManualLevelUp(cfg.ManualLevelUp)
+
+	if cfg.Ruleset == "" {
+		game.SetActiveRuleset(nil)
+		logger.Info("using built-in rules")
+		return nil
+	}
+
+	rulesetFile := findDataFile("data/rulesets/" + cfg.Ruleset + ".yaml")
+	if rulesetFile == "" {
+		logger.WithField("ruleset", cfg.Ruleset).Error("ruleset file not found")
+		return fmt.Errorf("ruleset %q not found in data/rulesets", cfg.Ruleset)
+	}
+	profile, err := game.LoadRulesetProfile(rulesetFile)
+	if err != nil {
+		logger.WithError(err).Error("failed to load ruleset")
+		return fmt.Errorf("failed to load ruleset: %w", err)
+	}
+
+	game.SetActiveRuleset(profile)
+	logger.WithField("ruleset", profile.Name).Info("loaded ruleset profile")
+	return nil
+}
+
+// initializePCGDefinitions loads the biome and theme files into the PCG
+// registries. Missing files are not fatal; the built-in definitions are
+// used instead.
+func initializePCGDefinitions(logger *logrus.Entry) error {
+	biomeFile := findDataFile("data/pcg/biomes.yaml")
+	if biomeFile == "" {
+		logger.Warn("biome file not found - using built-in biomes")
+	} else if err := pcg.Biomes().LoadFromFile(biomeFile); err != nil {
+		logger.WithError(err).Error("failed to load biomes")
+		return fmt.Errorf("failed to load biomes: %w", err)
+	}
+
+	themeFile := findDataFile("data/pcg/themes.yaml")
+	if themeFile == "" {
+		logger.Warn("theme file not found - using built-in themes")
+	} else if err := pcg.Themes().LoadFromFile(themeFile); err != nil {
+		logger.WithError(err).Error("failed to load themes")
+		return fmt.Errorf("failed to load themes: %w", err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"biomeCount": len(pcg.Biomes().Types()),
+		"themeCount": len(pcg.Themes().Types()),
+	}).Info("loaded biome and theme definitions")
+	return nil
+}
+
+// findDataFile returns path if it exists, or the same path relative to the
+// repository root when running from a package directory, or "" if neither
+// exists.
+func findDataFile(path string) string {
+	for _, candidate := range []string{path, filepath.Join("..", "..", path)} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// setupPCGManager initializes and configures the PCG manager with default
+// generators. The level generator rolls room loot from lootTables, which
+// may be nil.
+func setupPCGManager(logger *logrus.Entry, lootTables *items.LootTableRegistry) (*pcg.PCGManager, error) {
 	pcgManager := pcg.NewPCGManager(game.CreateDefaultWorld(), logrus.StandardLogger())
 	pcgManager.InitializeWithSeed(time.Now().UnixNano())
 
@@ -191,6 +342,33 @@ func setupPCGManager(logger *logrus.Entry) (*pcg.PCGManager, error) {
 		return nil, fmt.Errorf("failed to register item generator: %w", err)
 	}
 
+	terrainGen := terrain.NewCellularAutomataGenerator()
+	if err := pcgManager.GetRegistry().RegisterGenerator("cellular_automata", terrainGen); err != nil {
+		logger.WithError(err).Error("failed to register terrain generator")
+		return nil, fmt.Errorf("failed to register terrain generator: %w", err)
+	}
+
+	levelGen := levels.NewRoomCorridorGenerator()
+	if lootTables != nil {
+		levelGen.SetLootTables(lootTables)
+	}
+	if err := pcgManager.GetRegistry().RegisterGenerator("room_corridor", levelGen); err != nil {
+		logger.WithError(err).Error("failed to register level generator")
+		return nil, fmt.Errorf("failed to register level generator: %w", err)
+	}
+
+	wfcGen := levels.NewWFCGenerator()
+	if rulesetFile := findDataFile("data/pcg/wfc_rulesets.yaml"); rulesetFile == "" {
+		logger.Warn("WFC ruleset file not found - using built-in rulesets")
+	} else if err := wfcGen.LoadRulesets(rulesetFile); err != nil {
+		logger.WithError(err).Error("failed to load WFC rulesets")
+		return nil, fmt.Errorf("failed to load WFC rulesets: %w", err)
+	}
+	if err := pcgManager.GetRegistry().RegisterGenerator("wave_function_collapse", wfcGen); err != nil {
+		logger.WithError(err).Error("failed to register WFC level generator")
+		return nil, fmt.Errorf("failed to register WFC level generator: %w", err)
+	}
+
 	if err := pcgManager.RegisterDefaultGenerators(); err != nil {
 		logger.WithError(err).Error("failed to register default generators")
 		return nil, fmt.Errorf("failed to register default generators: %w", err)
@@ -200,6 +378,72 @@ func setupPCGManager(logger *logrus.Entry) (*pcg.PCGManager, error) {
 	return pcgManager, nil
 }
 
+// configureContentCache applies the content cache settings to the cache
+// shared by the manager's generations.
+func configureContentCache(pcgManager *pcg.PCGManager, cfg *config.Config, logger *logrus.Entry) {
+	settings := pcg.ContentCacheConfig{
+		MaxBytes: cfg.PCGCacheMaxBytes,
+		TTL:      cfg.PCGCacheTTL,
+		Enabled:  make(map[pcg.ContentType]bool, len(cfg.PCGCacheContentTypes)),
+	}
+	for _, contentType := range cfg.PCGCacheContentTypes {
+		settings.Enabled[pcg.ContentType(contentType)] = true
+	}
+	pcgManager.GetContentCache().SetConfig(settings)
+
+	logger.WithFields(logrus.Fields{
+		"max_bytes":     settings.MaxBytes,
+		"ttl":           settings.TTL,
+		"content_types": cfg.PCGCacheContentTypes,
+	}).Info("configured PCG content cache")
+}
+
+// configureQualityGate applies the quality gate settings to the gate
+// selecting the best of several candidates of the manager's generations.
+func configureQualityGate(pcgManager *pcg.PCGManager, cfg *config.Config, logger *logrus.Entry) {
+	settings := pcg.QualityGateConfig{
+		Enabled:     cfg.PCGQualityGateEnabled,
+		MaxAttempts: cfg.PCGQualityGateMaxAttempts,
+		MinScore:    cfg.PCGQualityGateMinScore,
+		Thresholds:  make(map[pcg.ContentType]float64, len(cfg.PCGQualityGateThresholds)),
+	}
+	for contentType, score := range cfg.PCGQualityGateThresholds {
+		settings.Thresholds[pcg.ContentType(contentType)] = score
+	}
+	pcgManager.GetQualityGate().SetConfig(settings)
+
+	logger.WithFields(logrus.Fields{
+		"enabled":      settings.Enabled,
+		"max_attempts": settings.MaxAttempts,
+		"min_score":    settings.MinScore,
+		"thresholds":   cfg.PCGQualityGateThresholds,
+	}).Info("configured PCG quality gate")
+}
+
+// configureDifficultyDirector applies the difficulty scaling settings to the
+// director that adjusts generation difficulty from player feedback.
+func configureDifficultyDirector(pcgManager *pcg.PCGManager, cfg *config.Config, logger *logrus.Entry) {
+	director := pcgManager.GetDifficultyDirector()
+	settings := director.GetConfig()
+	settings.Enabled = cfg.DifficultyScalingEnabled
+	settings.Min = cfg.DifficultyMin
+	settings.Max = cfg.DifficultyMax
+	settings.MaxAdjustment = cfg.DifficultyMaxAdjustment
+	settings.Overrides = make(map[pcg.ContentType]int, len(cfg.DifficultyOverrides))
+	for contentType, limit := range cfg.DifficultyOverrides {
+		settings.Overrides[pcg.ContentType(contentType)] = limit
+	}
+	director.SetConfig(settings)
+
+	logger.WithFields(logrus.Fields{
+		"enabled":        settings.Enabled,
+		"min":            settings.Min,
+		"max":            settings.Max,
+		"max_adjustment": settings.MaxAdjustment,
+		"overrides":      len(settings.Overrides),
+	}).Info("configured difficulty director")
+}
+
 // createServerInstance constructs the main server instance with core components.
 func createServerInstance(webDir string, cfg *config.Config, validator *validation.InputValidator, spellManager *game.SpellManager, pcgManager *pcg.PCGManager) *RPCServer {
 	return &RPCServer{
@@ -215,8 +459,12 @@ func createServerInstance(webDir string, cfg *config.Config, validator *validati
 		eventSys:     game.NewEventSystem(),
 		sessions:     make(map[string]*PlayerSession),
 		timekeeper:   NewTimeManager(),
+		parties:      NewPartyManager(),
+		companions:   NewCompanionManager(),
+		merchants:    NewMerchantManager(),
 		done:         make(chan struct{}),
 		spellManager: spellManager,
+		levelUps:     newLevelUpService(spellManager),
 		pcgManager:   pcgManager,
 		config:       cfg,
 		validator:    validator,
@@ -227,6 +475,19 @@ func createServerInstance(webDir string, cfg *config.Config, validator *validati
 func configurePerformanceMonitoring(server *RPCServer, cfg *config.Config) {
 	server.metrics = NewMetrics()
 	server.healthChecker = NewHealthChecker(server)
+	if cfg.LoadSheddingEnabled {
+		server.loadShedder = resilience.NewLoadShedder()
+	}
+
+	server.pcgEvents = pcg.NewPCGEventManager(logrus.StandardLogger(), server.eventSys, server.pcgManager)
+	if err := server.metrics.RegisterPCGMetrics(server.pcgManager, server.pcgEvents); err != nil {
+		logrus.WithError(err).Warn("failed to register PCG metrics")
+	}
+	if err := server.metrics.RegisterLockMetrics(); err != nil {
+		logrus.WithError(err).Warn("failed to register lock metrics")
+	} else if lockstat.Enabled {
+		logrus.Info("lock profiling enabled, exporting goldbox_lock_wait_seconds")
+	}
 
 	profilingConfig := ProfilingConfig{
 		Enabled: cfg.EnableProfiling || cfg.EnableDevMode,
@@ -258,25 +519,126 @@ func initializeNetworkComponents(server *RPCServer, cfg *config.Config, logger *
 	} else {
 		logger.Info("rate limiting disabled")
 	}
+
+	if cfg.SessionRateLimitEnabled {
+		server.sessionLimiter = NewSessionRateLimiter(cfg)
+		logger.WithFields(logrus.Fields{
+			"requests_per_second": cfg.SessionRateLimitRequestsPerSecond,
+			"burst":               cfg.SessionRateLimitBurst,
+		}).Info("per-session rate limiting enabled")
+	}
+
+	server.admin = newAdminConsole(cfg)
+	server.audit = newRequestAuditor(cfg)
+	if server.admin != nil {
+		logger.WithFields(logrus.Fields{
+			"permissions":         cfg.AdminPermissions,
+			"requests_per_second": cfg.AdminRateLimitRequestsPerSecond,
+		}).Info("admin console enabled")
+	}
 }
 
-// initializePersistence sets up file-based persistence and loads saved game state.
+// newPersistenceSealer creates the sealer for saved files when a
+// persistence secret is configured, and nil otherwise.
+func newPersistenceSealer(cfg *config.Config) (*persistence.Sealer, error) {
+	if cfg.PersistenceSecret == "" {
+		return nil, nil
+	}
+	sealer, err := persistence.NewSealer(persistence.SealOptions{
+		Secret:          cfg.PersistenceSecret,
+		PreviousSecrets: cfg.PersistencePreviousSecrets,
+		Encrypt:         cfg.PersistenceEncrypt,
+		AllowUnsealed:   cfg.PersistenceAllowUnsealed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create persistence sealer: %w", err)
+	}
+	return sealer, nil
+}
+
+// resealStore rewrites saved files with the current persistence secret
+// while a secret rotation or the move from plain files is in progress.
+// Files that fail verification are left as they are and logged.
+func resealStore(store persistence.Store, cfg *config.Config, logger *logrus.Entry) {
+	fileStore, ok := store.(*persistence.FileStore)
+	if !ok || cfg.PersistenceSecret == "" || (len(cfg.PersistencePreviousSecrets) == 0 && !cfg.PersistenceAllowUnsealed) {
+		return
+	}
+	resealed, err := fileStore.Reseal()
+	if err != nil {
+		logger.WithError(err).Error("failed to reseal saved files")
+		return
+	}
+	logger.WithField("files", resealed).Info("saved files resealed with the current secret")
+}
+
+// initializePersistence opens the configured persistence backend and loads saved game state.
 func initializePersistence(server *RPCServer, cfg *config.Config, logger *logrus.Entry) error {
-	logger.WithField("dataDir", cfg.DataDir).Info("initializing persistence")
+	logger.WithFields(logrus.Fields{
+		"dataDir": cfg.DataDir,
+		"backend": cfg.PersistenceBackend,
+		"mode":    cfg.PersistenceMode,
+	}).Info("initializing persistence")
 
-	// Create file store
-	store, err := persistence.NewFileStore(cfg.DataDir)
+	sealer, err := newPersistenceSealer(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := persistence.OpenStore(persistence.StoreOptions{
+		Backend:      cfg.PersistenceBackend,
+		DataDir:      cfg.DataDir,
+		SQLiteDriver: cfg.SQLiteDriver,
+		SQLiteDSN:    cfg.SQLiteDSN,
+		Sealer:       sealer,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create file store: %w", err)
+		return fmt.Errorf("failed to open persistence store: %w", err)
+	}
+	resealStore(store, cfg, logger)
+
+	server.store = store
+	if cfg.BackupCount > 0 {
+		server.backups = persistence.NewBackupStore(store, persistence.BackupPolicy{
+			MaxCount:    cfg.BackupCount,
+			MaxAge:      cfg.BackupMaxAge,
+			MinInterval: cfg.BackupInterval,
+		})
+		server.store = server.backups
+	}
+	if cfg.SnapshotCount > 0 {
+		server.snapshots = persistence.NewSnapshotManager(server.store, persistence.SnapshotPolicy{
+			MaxCount:       cfg.SnapshotCount,
+			MinInterval:    cfg.SnapshotInterval,
+			CompactAfter:   cfg.SnapshotCompactAfter,
+			CompactSpacing: cfg.SnapshotCompactSpacing,
+		})
 	}
 
-	server.fileStore = store
+	// Versioning wraps the store last so backups and snapshots keep each
+	// document with the schema version it was saved with
+	server.store = persistence.NewVersionedStore(server.store, persistence.DefaultMigrations)
 
-	// Load existing game state if it exists
-	if err := server.state.LoadFromFile(server.fileStore); err != nil {
+	// Load existing game state if it exists. A tampered save stops the
+	// server rather than being overwritten by a fresh game.
+	if err := server.state.LoadFromFile(server.store); errors.Is(err, persistence.ErrTampered) {
+		return fmt.Errorf("saved game state failed its integrity check: %w", err)
+	} else if err != nil {
 		logger.WithError(err).Warn("failed to load game state, starting fresh")
 	} else {
-		logger.Info("game state loaded from file")
+		logger.Info("game state loaded from store")
+	}
+
+	server.restorePCGState()
+
+	// In event-sourced mode the auto-save is a key frame; changes made
+	// after it are replayed from the write-ahead log
+	if cfg.PersistenceMode == PersistenceModeEventSourced {
+		if err := server.openEventLog(cfg.DataDir); err != nil {
+			return err
+		}
+		if err := server.replayEventLog(); err != nil {
+			return fmt.Errorf("failed to replay event log: %w", err)
+		}
 	}
 
 	return nil
@@ -299,10 +661,11 @@ func startAutoSave(server *RPCServer, cfg *config.Config, logger *logrus.Entry)
 				logger.Info("auto-save stopped")
 				return
 			case <-ticker.C:
-				if err := server.state.SaveToFile(server.fileStore); err != nil {
+				if err := server.persistState(); err != nil {
 					logger.WithError(err).Error("auto-save failed")
 				} else {
 					logger.Debug("auto-save completed successfully")
+					server.autoSnapshot()
 				}
 			}
 		}
@@ -326,12 +689,38 @@ func NewRPCServer(webDir string) (*RPCServer, error) {
 		return nil, err
 	}
 
-	pcgManager, err := setupPCGManager(logger)
+	lootTables, err := initializeLootTables(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	pcg.SetRNGAlgorithm(utils.RNGAlgorithm(cfg.PCGRNG))
+	pcgManager, err := setupPCGManager(logger, lootTables)
+	if err != nil {
+		return nil, err
+	}
+	configureDifficultyDirector(pcgManager, cfg, logger)
+	configureContentCache(pcgManager, cfg, logger)
+	configureQualityGate(pcgManager, cfg, logger)
+
+	if err := initializePCGDefinitions(logger); err != nil {
+		return nil, err
+	}
+
+	if err := initializeRuleset(cfg, logger); err != nil {
+		return nil, err
+	}
+
+	tracingShutdown, err := initializeTracing(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
 
 	server := createServerInstance(webDir, cfg, validator, spellManager, pcgManager)
+	server.lootTables = lootTables
+	server.tracingShutdown = tracingShutdown
+	server.pcgPlugins = loadPCGPlugins(pcgManager, cfg, logger)
+	pcgManager.SetEventSystem(server.eventSys)
 
 	// Initialize persistence if enabled
 	if cfg.EnablePersistence {
@@ -340,9 +729,42 @@ func NewRPCServer(webDir string) (*RPCServer, error) {
 		}
 	}
 
+	server.mountContentBundles()
+	server.attachPantheon()
+	server.attachWeather()
+	server.attachLighting()
+	server.attachTension()
+	server.attachEncounters()
+	server.attachWorldEvents()
+	server.scheduleShopRestock()
+	server.attachChronicle()
+	server.attachCampaignStats()
+	server.restoreAnnotations()
+	server.attachSpatialTracking()
+	if err := server.attachScripting(); err != nil {
+		logger.WithError(err).Error("failed to initialize scripting")
+		return nil, err
+	}
+
 	configurePerformanceMonitoring(server, cfg)
 	initializeNetworkComponents(server, cfg, logger)
 
+	if err := server.attachSessionStore(cfg); err != nil {
+		logger.WithError(err).Error("failed to initialize session store")
+		return nil, err
+	}
+
+	if err := server.attachWebhooks(); err != nil {
+		logger.WithError(err).Error("failed to initialize webhooks")
+		return nil, err
+	}
+
+	if err := server.attachAnalytics(); err != nil {
+		logger.WithError(err).Error("failed to initialize analytics")
+		return nil, err
+	}
+	server.subscribeConfigReloads()
+
 	if server.perfMonitor != nil {
 		go server.perfMonitor.Start()
 	}
@@ -351,6 +773,13 @@ func NewRPCServer(webDir string) (*RPCServer, error) {
 	}
 
 	server.startSessionCleanup()
+	server.startWeatherUpdates()
+	server.startTensionUpdates()
+	server.startWorldEventUpdates()
+	server.startCampaignStatsAggregation()
+	server.startBackupVerification()
+	server.startSnapshotCompaction()
+	server.startHealthMonitor()
 
 	// Start auto-save if persistence is enabled
 	if cfg.EnablePersistence {
@@ -368,25 +797,90 @@ func NewRPCServer(webDir string) (*RPCServer, error) {
 // Returns:
 //   - error: Any error that occurred during the save operation
 func (s *RPCServer) SaveState() error {
-	if s.fileStore == nil {
+	if s.store == nil {
 		return fmt.Errorf("persistence not enabled")
 	}
 
+	// Stop auto-save goroutine so it cannot race the final save
+	if s.autoSaveCancel != nil {
+		s.autoSaveCancel()
+	}
+
 	logrus.Info("saving game state to persistent storage")
 
-	if err := s.state.SaveToFile(s.fileStore); err != nil {
+	if err := s.persistState(); err != nil {
 		return fmt.Errorf("failed to save game state: %w", err)
 	}
 
-	// Stop auto-save goroutine if running
-	if s.autoSaveCancel != nil {
-		s.autoSaveCancel()
+	if s.events != nil {
+		if err := s.events.Close(); err != nil {
+			logrus.WithError(err).Warn("failed to close event log")
+		}
+	}
+	if err := s.store.Close(); err != nil {
+		logrus.WithError(err).Warn("failed to close persistence store")
 	}
 
 	logrus.Info("game state saved successfully")
 	return nil
 }
 
+// persistState writes the game state (world and sessions) and the PCG seed
+// state to the store as a single batch, so a crash mid-save never leaves
+// them out of step with each other. In event-sourced mode the batch also
+// holds the event checkpoint, and the event log records it covers are
+// dropped once it is saved.
+func (s *RPCServer) persistState() error {
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	extra := make(map[string]interface{})
+	if s.pcgManager != nil {
+		extra[pcgStateKey] = s.pcgManager.GetSeedManager().GetSaveableState()
+	}
+	if s.worldEvents != nil {
+		extra[worldEventsKey] = s.worldEvents.Snapshot()
+	}
+	extra[chronicleKey] = s.chronicle.Snapshot()
+	extra[campaignStatsKey] = s.campaignStats.Snapshot()
+	extra[annotationsKey] = s.annotations.Snapshot()
+	var sequence uint64
+	if s.events != nil {
+		sequence = s.eventCheckpointEntry(extra)
+	}
+	if err := s.state.SaveSnapshot(s.store, extra); err != nil {
+		return err
+	}
+
+	if s.events != nil {
+		if err := s.events.Compact(sequence); err != nil {
+			logrus.WithError(err).Warn("failed to compact event log")
+		}
+	}
+	return nil
+}
+
+// restorePCGState reloads the PCG seed state saved by persistState, if any,
+// so regenerated content stays reproducible across restarts.
+func (s *RPCServer) restorePCGState() {
+	if s.pcgManager == nil || !s.store.Exists(pcgStateKey) {
+		return
+	}
+
+	var state pcg.SaveableState
+	if err := s.store.Load(pcgStateKey, &state); err != nil {
+		logrus.WithError(err).Warn("failed to load PCG state, keeping configured seed")
+		return
+	}
+	s.pcgManager.GetSeedManager().LoadState(state)
+
+	logrus.WithFields(logrus.Fields{
+		"function": "restorePCGState",
+		"baseSeed": state.BaseSeed,
+		"contexts": len(state.ContextSeeds),
+	}).Info("PCG state restored")
+}
+
 // ServeHTTP handles incoming JSON-RPC requests over HTTP, implementing the http.Handler interface.
 // It processes POST requests only and expects a JSON-RPC 2.0 formatted request body.
 //
@@ -510,13 +1004,12 @@ func (s *RPCServer) handleProfilingEndpoints(w http.ResponseWriter, r *http.Requ
 
 // serveHTTPWithMiddleware handles requests after middleware has been applied
 func (s *RPCServer) serveHTTPWithMiddleware(w http.ResponseWriter, r *http.Request) {
-	logger := logrus.WithFields(logrus.Fields{
-		"function":   "serveHTTPWithMiddleware",
-		"method":     r.Method,
-		"url":        r.URL.String(),
-		"request_id": GetRequestID(r.Context()),
+	logger := httpLog.WithContext(r.Context()).WithFields(logrus.Fields{
+		"function": "serveHTTPWithMiddleware",
+		"method":   r.Method,
+		"url":      r.URL.String(),
 	})
-	logger.Debug("entering serveHTTPWithMiddleware")
+	httpLog.SampleEntry(logger, "serveHTTPWithMiddleware").Debug("entering serveHTTPWithMiddleware")
 
 	// Apply rate limiting after middleware (so we have request ID for logging)
 	if !s.checkRateLimit(w, r) {
@@ -533,6 +1026,11 @@ func (s *RPCServer) serveHTTPWithMiddleware(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Shed game requests while health checks report the server unwell
+	if !s.checkLoadShed(w, r) {
+		return
+	}
+
 	// Apply metrics middleware for all other requests
 	metricsHandler := s.metrics.MetricsMiddleware(http.HandlerFunc(s.handleRequest))
 	metricsHandler.ServeHTTP(w, r)
@@ -540,18 +1038,18 @@ func (s *RPCServer) serveHTTPWithMiddleware(w http.ResponseWriter, r *http.Reque
 
 // handleRequest processes the actual game requests after middleware
 func (s *RPCServer) handleRequest(w http.ResponseWriter, r *http.Request) {
-	logger := logrus.WithFields(logrus.Fields{
-		"function":   "handleRequest",
-		"method":     r.Method,
-		"url":        r.URL.String(),
-		"request_id": GetRequestID(r.Context()),
+	logger := rpcLog.WithContext(r.Context()).WithFields(logrus.Fields{
+		"function": "handleRequest",
+		"method":   r.Method,
+		"url":      r.URL.String(),
 	})
-	logger.Debug("entering handleRequest")
+	rpcLog.SampleEntry(logger, "handleRequest").Debug("entering handleRequest")
 
 	r, err := s.setupSessionContext(w, r, logger)
 	if err != nil {
 		return
 	}
+	logger = logger.WithFields(logging.FieldsFromContext(r.Context()))
 
 	if s.handleNonPOSTRequests(w, r, logger) {
 		return
@@ -568,7 +1066,7 @@ func (s *RPCServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.processRPCMethod(w, rpcRequest, logger)
+	s.processRPCMethod(tracing.Extract(r.Context(), r.Header), w, rpcRequest, logger)
 	logger.Debug("exiting ServeHTTP")
 }
 
@@ -583,6 +1081,7 @@ func (s *RPCServer) setupSessionContext(w http.ResponseWriter, r *http.Request,
 	defer s.releaseSession(session)
 
 	ctx := context.WithValue(r.Context(), sessionKey, session)
+	ctx = logging.ContextWithFields(ctx, logrus.Fields{logging.FieldSessionID: session.SessionID})
 	return r.WithContext(ctx), nil
 }
 
@@ -649,21 +1148,21 @@ func (s *RPCServer) validateJSONRPCRequest(req *JSONRPCRequest, logger *logrus.E
 
 // writeJSONRPCError writes a JSON-RPC error response using the provided error
 func (s *RPCServer) writeJSONRPCError(w http.ResponseWriter, err error, logger *logrus.Entry) {
-	if jsonRPCErr, ok := err.(*JSONRPCError); ok {
-		writeError(w, jsonRPCErr.Code, jsonRPCErr.Message, jsonRPCErr.Data)
-	} else {
-		writeError(w, JSONRPCInternalError, err.Error(), nil)
-	}
+	rpcErr := toJSONRPCError(err, JSONRPCInternalError)
+	writeError(w, rpcErr.Code, rpcErr.Message, rpcErr.Data)
 }
 
-// processRPCMethod handles the execution of an RPC method and writes the response
-func (s *RPCServer) processRPCMethod(w http.ResponseWriter, req *JSONRPCRequest, logger *logrus.Entry) {
-	logger.WithFields(logrus.Fields{
-		"rpcMethod": req.Method,
-		"requestId": req.ID,
-	}).Info("handling RPC method")
+// processRPCMethod handles the execution of an RPC method and writes the response.
+// ctx carries the span context propagated by the caller, if any.
+func (s *RPCServer) processRPCMethod(ctx context.Context, w http.ResponseWriter, req *JSONRPCRequest, logger *logrus.Entry) {
+	logger = logger.WithField(logging.FieldMethod, req.Method)
+	logger.WithField("requestId", req.ID).Info("handling RPC method")
+
+	// The correlation ID is the request ID, which is echoed in this header
+	ctx, correlationID := withCorrelationID(ctx)
+	w.Header().Set("X-Request-ID", correlationID)
 
-	result, err := s.handleMethod(req.Method, req.Params)
+	result, logger, err := s.tracedMethod(ctx, req.Method, req.Params, "http", logger)
 	if err != nil {
 		logger.WithError(err).Error("method handler failed")
 		s.writeJSONRPCError(w, err, logger)
@@ -701,20 +1200,20 @@ func (s *RPCServer) processRPCMethod(w http.ResponseWriter, req *JSONRPCRequest,
 //
 // Supported method categories:
 // - Character actions: move, attack, castSpell, useItem
-// - Combat management: startCombat, endTurn
+// - Combat management: startCombat, endTurn, getCombatState
 // - Equipment: equipItem, unequipItem, getEquipment
 // - Quest system: startQuest, completeQuest, failQuest, etc.
 // - Spell queries: getSpell, getSpellsByLevel, etc.
-// - Spatial queries: getObjectsInRange, getNearestObjects
-// - Game state: getGameState, joinGame, leaveGame
+// - Spatial queries: getObjectsInRange, getNearestObjects, getVisibleEnemies
+// - Game state: getGameState, joinGame, leaveGame, reconnectSession
 //
 // All handlers receive JSON-encoded parameters and return serializable results.
 func (s *RPCServer) handleMethod(method RPCMethod, params json.RawMessage) (interface{}, error) {
-	logger := logrus.WithFields(logrus.Fields{
-		"function": "handleMethod",
-		"method":   method,
+	logger := rpcLog.WithFields(logrus.Fields{
+		"function":          "handleMethod",
+		logging.FieldMethod: method,
 	})
-	logger.Debug("entering handleMethod")
+	rpcLog.SampleEntry(logger, "handleMethod").Debug("entering handleMethod")
 
 	// Parse params into interface{} for validation
 	var paramsInterface interface{}
@@ -724,12 +1223,39 @@ func (s *RPCServer) handleMethod(method RPCMethod, params json.RawMessage) (inte
 		}
 	}
 
-	// Validate input parameters with request size check
+	// Validate input parameters with request size check. Replay sandboxes
+	// re-run calls that were already validated when they were recorded.
 	requestSize := int64(len(params))
-	if err := s.validator.ValidateRPCRequest(string(method), paramsInterface, requestSize); err != nil {
+	if err := s.validator.ValidateRPCRequest(string(method), paramsInterface, requestSize); err != nil && !s.replays.isSandbox() {
 		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid method parameters", err.Error())
 	}
 
+	// Admin methods are authorized by the admin token and limited apart
+	// from the sessions they act on
+	if isAdminMethod(method) {
+		if err := s.authorizeAdmin(method, paramsInterface); err != nil {
+			return nil, err
+		}
+	} else if err := s.checkSessionRateLimit(method, paramsInterface); err != nil {
+		return nil, err
+	}
+
+	// A retried call with an idempotency key is answered with the response
+	// of the call that was applied, rather than being applied again
+	var keyed *idempotentCall
+	if scope, ok := idempotencyScopeOf(method, paramsInterface); ok {
+		call, replay, err := s.idempotency.begin(scope, method, paramsInterface)
+		if err != nil {
+			return nil, err
+		}
+		if replay != nil {
+			logger.WithField("idempotency_key", scope.key).Info("replaying response of idempotent call")
+			return replay, nil
+		}
+		keyed = call
+		defer s.idempotency.finish(keyed, nil, ErrUnavailable)
+	}
+
 	var result interface{}
 	var err error
 
@@ -737,9 +1263,33 @@ func (s *RPCServer) handleMethod(method RPCMethod, params json.RawMessage) (inte
 	case MethodJoinGame:
 		logger.Info("handling join game method")
 		result, err = s.handleJoinGame(params)
+	case MethodDiscover:
+		logger.Info("handling discover method")
+		result, err = s.handleDiscover(params)
+	case MethodReconnectSession:
+		logger.Info("handling reconnect session method")
+		result, err = s.handleReconnectSession(params)
 	case MethodCreateCharacter:
 		logger.Info("handling create character method")
 		result, err = s.handleCreateCharacter(params)
+	case MethodChangeClass:
+		logger.Info("handling change class method")
+		result, err = s.handleChangeClass(params)
+	case MethodLevelUp:
+		logger.Info("handling level up method")
+		result, err = s.handleLevelUp(params)
+	case MethodOpenDoor:
+		logger.Info("handling open door method")
+		result, err = s.handleOpenDoor(params)
+	case MethodPickLock:
+		logger.Info("handling pick lock method")
+		result, err = s.handlePickLock(params)
+	case MethodInteractObject:
+		logger.Info("handling interact object method")
+		result, err = s.handleInteractObject(params)
+	case MethodTalkToNPC:
+		logger.Info("handling talk to NPC method")
+		result, err = s.handleTalkToNPC(params)
 	case MethodMove:
 		logger.Info("handling move method")
 		result, err = s.handleMove(params)
@@ -758,6 +1308,9 @@ func (s *RPCServer) handleMethod(method RPCMethod, params json.RawMessage) (inte
 	case MethodEndTurn:
 		logger.Info("handling end turn method")
 		result, err = s.handleEndTurn(params)
+	case MethodGetCombatState:
+		logger.Info("handling get combat state method")
+		result, err = s.handleGetCombatState(params)
 	case MethodGetGameState:
 		logger.Info("handling get game state method")
 		result, err = s.handleGetGameState(params)
@@ -770,6 +1323,15 @@ func (s *RPCServer) handleMethod(method RPCMethod, params json.RawMessage) (inte
 	case MethodGetEquipment:
 		logger.Info("handling get equipment method")
 		result, err = s.handleGetEquipment(params)
+	case MethodRegisterReaction:
+		logger.Info("handling register reaction method")
+		result, err = s.handleRegisterReaction(params)
+	case MethodCancelReaction:
+		logger.Info("handling cancel reaction method")
+		result, err = s.handleCancelReaction(params)
+	case MethodRespondReaction:
+		logger.Info("handling respond reaction method")
+		result, err = s.handleRespondReaction(params)
 	case MethodStartQuest:
 		logger.Info("handling start quest method")
 		result, err = s.handleStartQuest(params)
@@ -794,6 +1356,21 @@ func (s *RPCServer) handleMethod(method RPCMethod, params json.RawMessage) (inte
 	case MethodGetQuestLog:
 		logger.Info("handling get quest log method")
 		result, err = s.handleGetQuestLog(params)
+	case MethodMemorizeSpells:
+		logger.Info("handling memorize spells method")
+		result, err = s.handleMemorizeSpells(params)
+	case MethodPreviewSpellTargets:
+		logger.Info("handling preview spell targets method")
+		result, err = s.handlePreviewSpellTargets(params)
+	case MethodRest:
+		logger.Info("handling rest method")
+		result, err = s.handleRest(params)
+	case MethodPassTime:
+		logger.Info("handling pass time method")
+		result, err = s.handlePassTime(params)
+	case MethodTravelTo:
+		logger.Info("handling travel to method")
+		result, err = s.handleTravelTo(params)
 	case MethodGetSpell:
 		logger.Info("handling get spell method")
 		result, err = s.handleGetSpell(params)
@@ -818,12 +1395,39 @@ func (s *RPCServer) handleMethod(method RPCMethod, params json.RawMessage) (inte
 	case MethodGetNearestObjects:
 		logger.Info("handling get nearest objects method")
 		result, err = s.handleGetNearestObjects(params)
+	case MethodGetVisibleEnemies:
+		logger.Info("handling get visible enemies method")
+		result, err = s.handleGetVisibleEnemies(params)
 	case MethodUseItem:
 		logger.Info("handling use item method")
 		result, err = s.handleUseItem(params)
 	case MethodLeaveGame:
 		logger.Info("handling leave game method")
 		result, err = s.handleLeaveGame(params)
+	case MethodCreateParty:
+		logger.Info("handling create party method")
+		result, err = s.handleCreateParty(params)
+	case MethodJoinParty:
+		logger.Info("handling join party method")
+		result, err = s.handleJoinParty(params)
+	case MethodLeaveParty:
+		logger.Info("handling leave party method")
+		result, err = s.handleLeaveParty(params)
+	case MethodGetParty:
+		logger.Info("handling get party method")
+		result, err = s.handleGetParty(params)
+	case MethodShareQuest:
+		logger.Info("handling share quest method")
+		result, err = s.handleShareQuest(params)
+	case MethodHireCompanion:
+		logger.Info("handling hire companion method")
+		result, err = s.handleHireCompanion(params)
+	case MethodDismissCompanion:
+		logger.Info("handling dismiss companion method")
+		result, err = s.handleDismissCompanion(params)
+	case MethodGetCompanions:
+		logger.Info("handling get companions method")
+		result, err = s.handleGetCompanions(params)
 	case MethodGenerateContent:
 		logger.Info("handling generate content method")
 		result, err = s.handleGenerateContent(params)
@@ -845,11 +1449,137 @@ func (s *RPCServer) handleMethod(method RPCMethod, params json.RawMessage) (inte
 	case MethodValidateContent:
 		logger.Info("handling validate content method")
 		result, err = s.handleValidateContent(params)
+	case MethodCommitGeneratedContent:
+		logger.Info("handling commit generated content method")
+		result, err = s.handleCommitGeneratedContent(params)
+	case MethodListBackups:
+		logger.Info("handling list backups method")
+		result, err = s.handleListBackups(params)
+	case MethodListSnapshots:
+		logger.Info("handling list snapshots method")
+		result, err = s.handleListSnapshots(params)
+	case MethodReplayCombat:
+		logger.Info("handling replay combat method")
+		result, err = s.handleReplayCombat(params)
+	case MethodGetCombatLog:
+		logger.Info("handling get combat log method")
+		result, err = s.handleGetCombatLog(params)
+	case MethodGetSessionRecap:
+		logger.Info("handling get session recap method")
+		result, err = s.handleGetSessionRecap(params)
+	case MethodGetCampaignStats:
+		logger.Info("handling get campaign stats method")
+		result, err = s.handleGetCampaignStats(params)
+	case MethodGetLeaderboard:
+		logger.Info("handling get leaderboard method")
+		result, err = s.handleGetLeaderboard(params)
+	case MethodGetMapDelta:
+		logger.Info("handling get map delta method")
+		result, err = s.handleGetMapDelta(params)
+	case MethodExportMap:
+		logger.Info("handling export map method")
+		result, err = s.handleExportMap(params)
+	case MethodGetDifficultyHeatmap:
+		logger.Info("handling get difficulty heatmap method")
+		result, err = s.handleGetDifficultyHeatmap(params)
+	case MethodGetRateLimitStats:
+		logger.Info("handling get rate limit stats method")
+		result, err = s.handleGetRateLimitStats(params)
+	case MethodGetGenerationJobStatus:
+		logger.Info("handling get generation job status method")
+		result, err = s.handleGetGenerationJobStatus(params)
+	case MethodSubmitContentFeedback:
+		logger.Info("handling submit content feedback method")
+		result, err = s.handleSubmitContentFeedback(params)
+	case MethodQueryGeneratedContent:
+		logger.Info("handling query generated content method")
+		result, err = s.handleQueryGeneratedContent(params)
+	case MethodGetMerchant:
+		logger.Info("handling get merchant method")
+		result, err = s.handleGetMerchant(params)
+	case MethodBuyItem:
+		logger.Info("handling buy item method")
+		result, err = s.handleBuyItem(params)
+	case MethodSellItem:
+		logger.Info("handling sell item method")
+		result, err = s.handleSellItem(params)
+	case MethodGetPortrait:
+		logger.Info("handling get portrait method")
+		result, err = s.handleGetPortrait(params)
+	case MethodGetWorldEvents:
+		logger.Info("handling get world events method")
+		result, err = s.handleGetWorldEvents(params)
+	case MethodAddAnnotation:
+		logger.Info("handling add annotation method")
+		result, err = s.handleAddAnnotation(params)
+	case MethodGetAnnotations:
+		logger.Info("handling get annotations method")
+		result, err = s.handleGetAnnotations(params)
+	case MethodAdminListSessions:
+		logger.Info("handling admin list sessions method")
+		result, err = s.handleAdminListSessions(params)
+	case MethodAdminInspectSession:
+		logger.Info("handling admin inspect session method")
+		result, err = s.handleAdminInspectSession(params)
+	case MethodAdminSpawnEntity:
+		logger.Info("handling admin spawn entity method")
+		result, err = s.handleAdminSpawnEntity(params)
+	case MethodAdminTeleportPlayer:
+		logger.Info("handling admin teleport player method")
+		result, err = s.handleAdminTeleportPlayer(params)
+	case MethodAdminGrantXP:
+		logger.Info("handling admin grant XP method")
+		result, err = s.handleAdminGrantXP(params)
+	case MethodAdminGrantItem:
+		logger.Info("handling admin grant item method")
+		result, err = s.handleAdminGrantItem(params)
+	case MethodAdminGenerate:
+		logger.Info("handling admin generate content method")
+		result, err = s.handleAdminGenerateContent(params)
+	case MethodAdminEndCombat:
+		logger.Info("handling admin end combat method")
+		result, err = s.handleAdminEndCombat(params)
+	case MethodAdminGiveItem:
+		logger.Info("handling admin give item method")
+		result, err = s.handleAdminGiveItem(params)
+	case MethodAdminTeleport:
+		logger.Info("handling admin teleport method")
+		result, err = s.handleAdminTeleport(params)
+	case MethodAdminUndoLastAction:
+		logger.Info("handling admin undo last action method")
+		result, err = s.handleAdminUndoLastAction(params)
+	case MethodAdminRestoreBackup:
+		logger.Info("handling admin restore backup method")
+		result, err = s.handleAdminRestoreBackup(params)
+	case MethodAdminRestoreSnapshot:
+		logger.Info("handling admin restore snapshot method")
+		result, err = s.handleAdminRestoreSnapshot(params)
+	case MethodAdminReloadLoot:
+		logger.Info("handling admin reload loot tables method")
+		result, err = s.handleAdminReloadLootTables(params)
+	case MethodAdminReloadPCG:
+		logger.Info("handling admin reload PCG definitions method")
+		result, err = s.handleAdminReloadPCGDefinitions(params)
+	case MethodAdminReloadConfig:
+		logger.Info("handling admin reload config method")
+		result, err = s.handleAdminReloadConfig(params)
+	case MethodAdminListRequests:
+		logger.Info("handling admin list requests method")
+		result, err = s.handleAdminListRequests(params)
 	default:
 		err = NewJSONRPCError(JSONRPCMethodNotFound, fmt.Sprintf("Method not found: %s", method), nil)
 		logger.WithError(err).Error("unknown method")
 		return nil, err
 	}
+	s.recordCombatCall(method, params, result, err)
+	s.recordStateEvent(method, params, result, err)
+	s.recordFeatureUsage(method, params, result, err)
+	if keyed != nil {
+		s.idempotency.finish(keyed, result, err)
+	}
+	if isAdminMethod(method) {
+		s.auditAdminCall(method, paramsInterface, err)
+	}
 
 	if err != nil {
 		logger.WithError(err).Error("method handler failed")
@@ -1104,6 +1834,9 @@ func (s *RPCServer) Close() error {
 	logger.Info("shutting down server resources")
 
 	// Stop rate limiter cleanup goroutine
+	if s.sessionLimiter != nil {
+		s.sessionLimiter.Close()
+	}
 	if s.rateLimiter != nil {
 		s.rateLimiter.Close()
 		logger.Debug("rate limiter closed")
@@ -1121,12 +1854,27 @@ func (s *RPCServer) Close() error {
 		logger.Debug("performance alerter stopped")
 	}
 
+	// Cancel background generation jobs
+	if s.pcgManager != nil {
+		s.pcgManager.GetJobQueue().Close()
+		logger.Debug("generation jobs canceled")
+	}
+
+	// Stop external generator processes
+	s.closePCGPlugins()
+
 	// Stop WebSocket broadcaster
 	if s.broadcaster != nil {
 		s.broadcaster.Stop()
 		logger.Debug("websocket broadcaster stopped")
 	}
 
+	// Stop sharing sessions with other instances
+	s.closeSessionStore()
+
+	// Export spans still buffered
+	s.shutdownTracing()
+
 	logger.Info("server shutdown complete")
 	return nil
 }
@@ -132,7 +132,7 @@ func (s *RPCServer) getOrCreateSession(w http.ResponseWriter, r *http.Request) (
 		Value:    sessionID,
 		Path:     "/",
 		HttpOnly: true,
-		MaxAge:   int(s.config.SessionTimeout.Seconds()), // Use configurable session timeout
+		MaxAge:   int(s.config.GetSessionTimeout().Seconds()), // Use configurable session timeout
 		SameSite: http.SameSiteStrictMode,
 		Secure:   isSecure,
 	})
@@ -248,6 +248,7 @@ func (s *RPCServer) startSessionCleanup() {
 					"package":  "server",
 				}).Debug("running cleanup cycle")
 				s.cleanupExpiredSessions()
+				s.refreshSharedSessions()
 			case <-s.done:
 				logrus.WithFields(logrus.Fields{
 					"function": "startSessionCleanup",
@@ -271,16 +272,25 @@ func (s *RPCServer) cleanupExpiredSessions() {
 		"package":  "server",
 	}).Debug("entering cleanupExpiredSessions")
 
+	var expiredIDs []string
+	defer func() {
+		for _, id := range expiredIDs {
+			s.unshareSession(id)
+			s.journal.forget(id)
+		}
+	}()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	now := time.Now()
 	sessionCount := len(s.sessions)
 	expiredCount := 0
+	timeout := s.config.GetSessionTimeout()
 
 	for id, session := range s.sessions {
 		age := now.Sub(session.LastActive)
-		if age > s.config.SessionTimeout {
+		if age > timeout {
 			// Check if session is currently in use by a handler
 			if session.isInUse() {
 				logrus.WithFields(logrus.Fields{
@@ -298,7 +308,7 @@ func (s *RPCServer) cleanupExpiredSessions() {
 				"package":   "server",
 				"sessionID": id,
 				"age":       age,
-				"timeout":   s.config.SessionTimeout,
+				"timeout":   timeout,
 			}).Info("removing expired session")
 
 			if session.WSConn != nil {
@@ -312,6 +322,7 @@ func (s *RPCServer) cleanupExpiredSessions() {
 				}
 			}
 			delete(s.sessions, id)
+			expiredIDs = append(expiredIDs, id)
 			expiredCount++
 
 			// Update metrics for session removal
@@ -339,9 +350,14 @@ func (s *RPCServer) getSession(sessionID string) (*PlayerSession, bool) {
 	}).Debug("entering getSession")
 
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	session, exists := s.sessions[sessionID]
+	s.mu.RUnlock()
+
+	// Take over sessions shared by other server instances
+	if !exists {
+		session, exists = s.adoptSession(sessionID)
+	}
+
 	if exists {
 		session.addRef() // Increment reference count to prevent cleanup
 		logrus.WithFields(logrus.Fields{
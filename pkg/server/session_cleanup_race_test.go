@@ -1,7 +1,6 @@
 package server
 
 import (
-	"sync"
 	"testing"
 	"time"
 
@@ -13,7 +12,6 @@ import (
 func TestSessionCleanupRaceCondition(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute, // Use default timeout
 		},
@@ -70,7 +68,6 @@ func TestSessionCleanupRaceCondition(t *testing.T) {
 func TestSessionCleanupRespectsInUse(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute, // Use default timeout
 		},
@@ -0,0 +1,342 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// defaultMethodWeights is the token cost of RPC methods that are more
+// expensive to serve than a plain state query. Methods not listed cost
+// defaultMethodWeight.
+var defaultMethodWeights = map[RPCMethod]int{
+	MethodCastSpell:              3,
+	MethodAttack:                 2,
+	MethodTravelTo:               3,
+	MethodGenerateContent:        5,
+	MethodRegenerateTerrain:      5,
+	MethodGenerateItems:          4,
+	MethodGenerateLevel:          5,
+	MethodGenerateQuest:          4,
+	MethodCommitGeneratedContent: 3,
+	MethodReplayCombat:           5,
+	MethodExportMap:              3,
+}
+
+const defaultMethodWeight = 1
+
+// SessionRateLimiter limits RPC calls with a token bucket per session, so
+// players sharing an IP address do not use up each other's allowance. Each
+// call takes as many tokens as its method weight.
+type SessionRateLimiter struct {
+	mu                sync.Mutex
+	buckets           map[string]*sessionBucket
+	requestsPerSecond rate.Limit
+	burst             int
+	weights           map[RPCMethod]int
+	maxAge            time.Duration
+	cleanupInterval   time.Duration
+	rejected          int64
+	ctx               context.Context
+	cancel            context.CancelFunc
+}
+
+// sessionBucket is the token bucket of one session.
+type sessionBucket struct {
+	limiter    *rate.Limiter
+	lastAccess time.Time
+	allowed    int64
+	rejected   int64
+}
+
+// SessionBucketState describes a session's token bucket.
+type SessionBucketState struct {
+	Tokens   float64 `json:"tokens"`
+	Burst    int     `json:"burst"`
+	Rate     float64 `json:"rate"`
+	Allowed  int64   `json:"allowed"`
+	Rejected int64   `json:"rejected"`
+}
+
+// SessionRateLimiterStats summarizes all session buckets.
+type SessionRateLimiterStats struct {
+	ActiveBuckets int            `json:"active_buckets"`
+	Rejected      int64          `json:"rejected"`
+	Weights       map[string]int `json:"weights"`
+}
+
+// NewSessionRateLimiter creates a per-session rate limiter from the
+// configuration and starts removing buckets of idle sessions. Configured
+// method weights replace the defaults of the same methods.
+//
+// Parameters:
+//   - cfg: Configuration containing session rate limiting settings
+//
+// Returns:
+//   - *SessionRateLimiter: Configured rate limiter instance
+func NewSessionRateLimiter(cfg *config.Config) *SessionRateLimiter {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cleanupInterval := cfg.RateLimitCleanupInterval
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Minute
+	}
+
+	rl := &SessionRateLimiter{
+		buckets:           make(map[string]*sessionBucket),
+		requestsPerSecond: rate.Limit(cfg.SessionRateLimitRequestsPerSecond),
+		burst:             cfg.SessionRateLimitBurst,
+		weights:           sessionMethodWeights(cfg),
+		maxAge:            cleanupInterval * 5,
+		cleanupInterval:   cleanupInterval,
+		ctx:               ctx,
+		cancel:            cancel,
+	}
+	go rl.cleanupLoop()
+
+	return rl
+}
+
+// sessionMethodWeights returns the default method weights with the
+// configured weights replacing those of the same methods. Weights above the
+// burst are lowered to it, since a bucket never holds more tokens than its
+// burst and such a method could otherwise never be called.
+func sessionMethodWeights(cfg *config.Config) map[RPCMethod]int {
+	weights := make(map[RPCMethod]int, len(defaultMethodWeights)+len(cfg.SessionRateLimitMethodWeights))
+	for method, weight := range defaultMethodWeights {
+		weights[method] = weight
+	}
+	for method, weight := range cfg.SessionRateLimitMethodWeights {
+		weights[RPCMethod(method)] = weight
+	}
+	for method, weight := range weights {
+		if weight > cfg.SessionRateLimitBurst {
+			weights[method] = cfg.SessionRateLimitBurst
+		}
+	}
+	return weights
+}
+
+// SetLimits applies the session rate limiting settings of a reloaded
+// configuration to new and existing buckets.
+func (rl *SessionRateLimiter) SetLimits(cfg *config.Config) {
+	weights := sessionMethodWeights(cfg)
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.requestsPerSecond = rate.Limit(cfg.SessionRateLimitRequestsPerSecond)
+	rl.burst = cfg.SessionRateLimitBurst
+	rl.weights = weights
+	for _, bucket := range rl.buckets {
+		bucket.limiter.SetLimit(rl.requestsPerSecond)
+		bucket.limiter.SetBurst(rl.burst)
+	}
+}
+
+// Weight returns the number of tokens a call to method takes.
+func (rl *SessionRateLimiter) Weight(method RPCMethod) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.weightLocked(method)
+}
+
+// weightLocked implements Weight. The caller must hold rl.mu.
+func (rl *SessionRateLimiter) weightLocked(method RPCMethod) int {
+	if weight, exists := rl.weights[method]; exists {
+		return weight
+	}
+	return defaultMethodWeight
+}
+
+// Allow takes the tokens for a call to method from the session's bucket.
+// When the bucket is short it takes nothing and returns false along with
+// how long until enough tokens are available.
+func (rl *SessionRateLimiter) Allow(sessionID string, method RPCMethod) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	weight := rl.weightLocked(method)
+	if weight <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+	bucket := rl.bucketLocked(sessionID, now)
+	bucket.lastAccess = now
+
+	if bucket.limiter.AllowN(now, weight) {
+		bucket.allowed++
+		return true, 0
+	}
+
+	bucket.rejected++
+	rl.rejected++
+	missing := float64(weight) - bucket.limiter.TokensAt(now)
+	return false, time.Duration(missing / float64(rl.requestsPerSecond) * float64(time.Second))
+}
+
+// bucketLocked returns the bucket of sessionID, creating a full one on first
+// use. The caller must hold rl.mu.
+func (rl *SessionRateLimiter) bucketLocked(sessionID string, now time.Time) *sessionBucket {
+	bucket, exists := rl.buckets[sessionID]
+	if !exists {
+		bucket = &sessionBucket{
+			limiter:    rate.NewLimiter(rl.requestsPerSecond, rl.burst),
+			lastAccess: now,
+		}
+		rl.buckets[sessionID] = bucket
+	}
+	return bucket
+}
+
+// BucketState returns the state of a session's bucket. Sessions that have
+// not made a call yet report a full bucket.
+func (rl *SessionRateLimiter) BucketState(sessionID string) SessionBucketState {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	state := SessionBucketState{
+		Tokens: float64(rl.burst),
+		Burst:  rl.burst,
+		Rate:   float64(rl.requestsPerSecond),
+	}
+	if bucket, exists := rl.buckets[sessionID]; exists {
+		state.Tokens = math.Max(0, bucket.limiter.TokensAt(time.Now()))
+		state.Allowed = bucket.allowed
+		state.Rejected = bucket.rejected
+	}
+	return state
+}
+
+// GetStats returns statistics across all session buckets.
+func (rl *SessionRateLimiter) GetStats() SessionRateLimiterStats {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	weights := make(map[string]int, len(rl.weights))
+	for method, weight := range rl.weights {
+		weights[string(method)] = weight
+	}
+	return SessionRateLimiterStats{
+		ActiveBuckets: len(rl.buckets),
+		Rejected:      rl.rejected,
+		Weights:       weights,
+	}
+}
+
+// cleanupLoop periodically removes the buckets of idle sessions.
+func (rl *SessionRateLimiter) cleanupLoop() {
+	ticker := time.NewTicker(rl.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rl.ctx.Done():
+			return
+		case <-ticker.C:
+			rl.cleanup()
+		}
+	}
+}
+
+// cleanup removes buckets that have not been used for maxAge. A returning
+// session starts again with a full bucket, which is what an idle bucket
+// would have refilled to anyway.
+func (rl *SessionRateLimiter) cleanup() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	for sessionID, bucket := range rl.buckets {
+		if now.Sub(bucket.lastAccess) > rl.maxAge {
+			delete(rl.buckets, sessionID)
+		}
+	}
+}
+
+// Close stops the background cleanup goroutine.
+func (rl *SessionRateLimiter) Close() {
+	if rl.cancel != nil {
+		rl.cancel()
+	}
+}
+
+// checkSessionRateLimit charges a call to method against the session named
+// in its params. Calls without a session ID, such as joinGame, are left to
+// the per-IP limiter.
+func (s *RPCServer) checkSessionRateLimit(method RPCMethod, params interface{}) error {
+	if s.sessionLimiter == nil {
+		return nil
+	}
+	paramsMap, _ := params.(map[string]interface{})
+	sessionID, _ := paramsMap["session_id"].(string)
+	if sessionID == "" {
+		return nil
+	}
+
+	allowed, retryAfter := s.sessionLimiter.Allow(sessionID, method)
+	if allowed {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":    "checkSessionRateLimit",
+		"session_id":  sessionID,
+		"method":      method,
+		"retry_after": retryAfter,
+	}).Warn("session rate limited")
+
+	return NewJSONRPCError(JSONRPCRateLimited, "Rate limit exceeded", map[string]interface{}{
+		"retry_after_ms": retryAfter.Milliseconds(),
+		"weight":         s.sessionLimiter.Weight(method),
+	})
+}
+
+// handleGetRateLimitStats reports the caller's rate limit bucket and how
+// much each method costs.
+//
+// Parameters:
+//   - params: JSON containing session_id
+//
+// Returns:
+//   - interface{}: Map with enabled, and when enabled the caller's bucket and
+//     limiter wide stats
+//   - error: Invalid params or unknown session
+func (s *RPCServer) handleGetRateLimitStats(params json.RawMessage) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "handleGetRateLimitStats",
+	}).Debug("entering handleGetRateLimitStats")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid rate limit stats parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	if s.sessionLimiter == nil {
+		return map[string]interface{}{
+			"success": true,
+			"enabled": false,
+		}, nil
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"enabled": true,
+		"bucket":  s.sessionLimiter.BucketState(req.SessionID),
+		"stats":   s.sessionLimiter.GetStats(),
+	}, nil
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSessionRateLimiter(t *testing.T, burst int, weights map[string]int) *SessionRateLimiter {
+	rl := NewSessionRateLimiter(&config.Config{
+		SessionRateLimitRequestsPerSecond: 0.001,
+		SessionRateLimitBurst:             burst,
+		SessionRateLimitMethodWeights:     weights,
+		RateLimitCleanupInterval:          time.Minute,
+	})
+	t.Cleanup(rl.Close)
+	return rl
+}
+
+func TestSessionRateLimiter_Weights(t *testing.T) {
+	rl := newTestSessionRateLimiter(t, 10, map[string]int{"getGameState": 2})
+
+	assert.Equal(t, 3, rl.Weight(MethodCastSpell))
+	assert.Equal(t, 2, rl.Weight(MethodGetGameState), "configured weight replaces the default")
+	assert.Equal(t, defaultMethodWeight, rl.Weight(MethodMove))
+
+	allowed, _ := rl.Allow("session-a", MethodCastSpell)
+	require.True(t, allowed)
+	assert.InDelta(t, 7, rl.BucketState("session-a").Tokens, 0.1)
+
+	allowed, _ = rl.Allow("session-a", MethodMove)
+	require.True(t, allowed)
+	assert.InDelta(t, 6, rl.BucketState("session-a").Tokens, 0.1)
+}
+
+func TestSessionRateLimiter_WeightsCappedAtBurst(t *testing.T) {
+	// Default generation weights exceed a small burst, which would
+	// otherwise reject those methods forever
+	rl := newTestSessionRateLimiter(t, 2, nil)
+
+	assert.Equal(t, 2, rl.Weight(MethodGenerateLevel))
+	assert.Equal(t, 2, rl.Weight(MethodReplayCombat))
+	assert.Equal(t, defaultMethodWeight, rl.Weight(MethodMove))
+
+	allowed, _ := rl.Allow("session-a", MethodGenerateLevel)
+	assert.True(t, allowed)
+
+	rl.SetLimits(&config.Config{SessionRateLimitRequestsPerSecond: 0.001, SessionRateLimitBurst: 4})
+	assert.Equal(t, 4, rl.Weight(MethodGenerateContent), "a reload recomputes the cap")
+}
+
+func TestSessionRateLimiter_RejectsWithoutTakingTokens(t *testing.T) {
+	rl := newTestSessionRateLimiter(t, 4, nil)
+
+	allowed, _ := rl.Allow("session-a", MethodCastSpell)
+	require.True(t, allowed)
+
+	allowed, retryAfter := rl.Allow("session-a", MethodCastSpell)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+
+	// The rejected spell took nothing, so a cheap call still fits
+	allowed, _ = rl.Allow("session-a", MethodMove)
+	assert.True(t, allowed)
+
+	state := rl.BucketState("session-a")
+	assert.Equal(t, int64(2), state.Allowed)
+	assert.Equal(t, int64(1), state.Rejected)
+	assert.Equal(t, int64(1), rl.GetStats().Rejected)
+}
+
+func TestSessionRateLimiter_SessionsAreIndependent(t *testing.T) {
+	// Two players behind the same NAT share an IP but not a bucket
+	rl := newTestSessionRateLimiter(t, 3, nil)
+
+	allowed, _ := rl.Allow("session-a", MethodCastSpell)
+	require.True(t, allowed)
+	allowed, _ = rl.Allow("session-a", MethodMove)
+	require.False(t, allowed)
+
+	allowed, _ = rl.Allow("session-b", MethodCastSpell)
+	assert.True(t, allowed)
+
+	assert.Equal(t, float64(3), rl.BucketState("session-c").Tokens, "unused sessions report a full bucket")
+	assert.Equal(t, 2, rl.GetStats().ActiveBuckets)
+}
+
+func TestSessionRateLimiter_Cleanup(t *testing.T) {
+	rl := newTestSessionRateLimiter(t, 3, nil)
+
+	rl.Allow("session-a", MethodMove)
+	rl.mu.Lock()
+	rl.buckets["session-a"].lastAccess = time.Now().Add(-2 * rl.maxAge)
+	rl.mu.Unlock()
+	rl.Allow("session-b", MethodMove)
+
+	rl.cleanup()
+
+	assert.Equal(t, 1, rl.GetStats().ActiveBuckets)
+}
+
+func TestHandleMethod_SessionRateLimited(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.sessionLimiter = newTestSessionRateLimiter(t, 2, nil)
+
+	sessionID := "12345678-1234-1234-1234-123456789abc"
+	server.setSession(sessionID, &PlayerSession{
+		SessionID:   sessionID,
+		Player:      &game.Player{Character: game.Character{ID: "player-rl"}},
+		LastActive:  time.Now(),
+		CreatedAt:   time.Now(),
+		Connected:   true,
+		MessageChan: make(chan []byte, 10),
+	})
+	params := json.RawMessage(`{"session_id":"` + sessionID + `"}`)
+
+	for i := 0; i < 2; i++ {
+		_, err := server.handleMethod(MethodGetRateLimitStats, params)
+		require.NoError(t, err)
+	}
+
+	_, err := server.handleMethod(MethodGetRateLimitStats, params)
+	require.Error(t, err)
+	rpcErr, ok := err.(*JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, JSONRPCRateLimited, rpcErr.Code)
+	data, ok := rpcErr.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, 1, data["weight"])
+}
+
+func TestHandleGetRateLimitStats(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	createTestSessionForHandlers(t, server)
+	params := json.RawMessage(`{"session_id":"test-session-001"}`)
+
+	t.Run("disabled", func(t *testing.T) {
+		result, err := server.handleGetRateLimitStats(params)
+		require.NoError(t, err)
+
+		resultMap := result.(map[string]interface{})
+		assert.Equal(t, true, resultMap["success"])
+		assert.Equal(t, false, resultMap["enabled"])
+		assert.NotContains(t, resultMap, "bucket")
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		server.sessionLimiter = newTestSessionRateLimiter(t, 10, nil)
+		server.sessionLimiter.Allow("test-session-001", MethodCastSpell)
+
+		result, err := server.handleGetRateLimitStats(params)
+		require.NoError(t, err)
+
+		resultMap := result.(map[string]interface{})
+		assert.Equal(t, true, resultMap["enabled"])
+		bucket := resultMap["bucket"].(SessionBucketState)
+		assert.InDelta(t, 7, bucket.Tokens, 0.1)
+		assert.Equal(t, 10, bucket.Burst)
+		assert.Equal(t, int64(1), bucket.Allowed)
+		stats := resultMap["stats"].(SessionRateLimiterStats)
+		assert.Equal(t, 3, stats.Weights["castSpell"])
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		_, err := server.handleGetRateLimitStats(json.RawMessage(`{"session_id":"missing"}`))
+		assert.Error(t, err)
+	})
+}
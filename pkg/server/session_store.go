@@ -0,0 +1,533 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported session store backend names.
+const (
+	SessionStoreMemory = "memory"
+	SessionStoreRedis  = "redis"
+)
+
+// SessionRecord is the part of a player session shared between server
+// instances. Connections, message channels and resume buffers stay with the
+// instance that owns the session; InstanceID records which one that is, so
+// load balancers and other instances can route the session to it.
+type SessionRecord struct {
+	SessionID     string       `yaml:"session_id"`       // Unique session identifier
+	Player        *game.Player `yaml:"player,omitempty"` // Associated player, once created
+	InstanceID    string       `yaml:"instance_id"`      // Instance currently holding the session
+	AffinitySince time.Time    `yaml:"affinity_since"`   // When InstanceID took the session over
+	Connected     bool         `yaml:"connected"`        // Whether a WebSocket is attached on InstanceID
+	CreatedAt     time.Time    `yaml:"created_at"`       // Session creation timestamp
+	LastActive    time.Time    `yaml:"last_active"`      // Last activity timestamp
+}
+
+// SessionMessage is a WebSocket message fanned out to the other instances
+// sharing a session store.
+type SessionMessage struct {
+	Origin    string                 `json:"origin"`               // Instance that published the message
+	SessionID string                 `json:"session_id,omitempty"` // Target session; empty broadcasts to all
+	Event     map[string]interface{} `json:"event"`                // WebSocket event, without its sequence number
+}
+
+// SessionStore is the interface implemented by session store backends. It
+// shares session records and WebSocket fan-out between the server
+// instances connected to the same store.
+type SessionStore interface {
+	// Save stores record, replacing any earlier record of the session. The
+	// record expires after ttl unless it is saved again.
+	Save(record SessionRecord, ttl time.Duration) error
+
+	// Load returns the record of a session; ok is false when none is stored.
+	Load(sessionID string) (record SessionRecord, ok bool, err error)
+
+	// Delete removes the record of a session. Deleting a missing record is not an error.
+	Delete(sessionID string) error
+
+	// List returns every stored record, ordered by session ID.
+	List() ([]SessionRecord, error)
+
+	// Publish delivers message to the subscribers of every instance,
+	// including the publishing one.
+	Publish(message SessionMessage) error
+
+	// Subscribe calls handler for each published message, in publish
+	// order, until the returned cancel function is called.
+	Subscribe(handler func(SessionMessage)) (cancel func(), err error)
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+var (
+	_ SessionStore = (*MemorySessionStore)(nil)
+	_ SessionStore = (*RedisSessionStore)(nil)
+)
+
+// SessionStoreOptions selects and configures a session store backend.
+type SessionStoreOptions struct {
+	// Backend is SessionStoreMemory or SessionStoreRedis. An empty value
+	// selects SessionStoreMemory.
+	Backend string
+
+	// Redis settings, used by the redis backend
+	RedisAddr      string
+	RedisPassword  string
+	RedisDB        int
+	RedisKeyPrefix string
+}
+
+// OpenSessionStore opens the session store backend selected by opts.
+//
+// Parameters:
+//   - opts: Backend selection and backend-specific settings
+//
+// Returns:
+//   - SessionStore: The opened store
+//   - error: Unknown backend or failure to reach the backend
+func OpenSessionStore(opts SessionStoreOptions) (SessionStore, error) {
+	switch opts.Backend {
+	case "", SessionStoreMemory:
+		return NewMemorySessionStore(), nil
+	case SessionStoreRedis:
+		return NewRedisSessionStore(RedisOptions{
+			Addr:      opts.RedisAddr,
+			Password:  opts.RedisPassword,
+			DB:        opts.RedisDB,
+			KeyPrefix: opts.RedisKeyPrefix,
+		})
+	default:
+		return nil, fmt.Errorf("unknown session store backend: %s", opts.Backend)
+	}
+}
+
+// MemorySessionStore keeps session records in this process. Records and
+// messages are encoded the same way as by RedisSessionStore, so servers
+// sharing one MemorySessionStore behave like instances sharing Redis.
+//
+// MemorySessionStore is thread-safe. Its contents are lost when the process exits.
+type MemorySessionStore struct {
+	mu          sync.Mutex
+	records     map[string]memorySessionEntry
+	subscribers map[int]chan []byte
+	nextSubID   int
+	now         func() time.Time
+}
+
+// memorySessionEntry is an encoded session record with its expiry time.
+type memorySessionEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemorySessionStore creates an empty MemorySessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		records:     make(map[string]memorySessionEntry),
+		subscribers: make(map[int]chan []byte),
+		now:         time.Now,
+	}
+}
+
+// Save stores record until ttl elapses.
+func (ms *MemorySessionStore) Save(record SessionRecord, ttl time.Duration) error {
+	data, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session record: %w", err)
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.records[record.SessionID] = memorySessionEntry{data: data, expires: ms.now().Add(ttl)}
+	return nil
+}
+
+// Load returns the unexpired record of a session.
+func (ms *MemorySessionStore) Load(sessionID string) (SessionRecord, bool, error) {
+	ms.mu.Lock()
+	entry, ok := ms.records[sessionID]
+	if ok && !ms.now().Before(entry.expires) {
+		delete(ms.records, sessionID)
+		ok = false
+	}
+	ms.mu.Unlock()
+
+	if !ok {
+		return SessionRecord{}, false, nil
+	}
+	var record SessionRecord
+	if err := yaml.Unmarshal(entry.data, &record); err != nil {
+		return SessionRecord{}, false, fmt.Errorf("failed to unmarshal session record: %w", err)
+	}
+	return record, true, nil
+}
+
+// Delete removes the record of a session.
+func (ms *MemorySessionStore) Delete(sessionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.records, sessionID)
+	return nil
+}
+
+// List returns every unexpired record, ordered by session ID.
+func (ms *MemorySessionStore) List() ([]SessionRecord, error) {
+	ms.mu.Lock()
+	ids := make([]string, 0, len(ms.records))
+	for id := range ms.records {
+		ids = append(ids, id)
+	}
+	ms.mu.Unlock()
+	sort.Strings(ids)
+
+	records := make([]SessionRecord, 0, len(ids))
+	for _, id := range ids {
+		record, ok, err := ms.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// Publish queues message for every subscriber. A subscriber whose queue
+// is full misses the message.
+func (ms *MemorySessionStore) Publish(message SessionMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session message: %w", err)
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for _, queue := range ms.subscribers {
+		select {
+		case queue <- data:
+		default:
+			logrus.WithField("origin", message.Origin).Warn("session message dropped: subscriber queue full")
+		}
+	}
+	return nil
+}
+
+// Subscribe delivers published messages to handler from a dedicated goroutine.
+func (ms *MemorySessionStore) Subscribe(handler func(SessionMessage)) (func(), error) {
+	queue := make(chan []byte, MessageChanBufferSize)
+
+	ms.mu.Lock()
+	id := ms.nextSubID
+	ms.nextSubID++
+	ms.subscribers[id] = queue
+	ms.mu.Unlock()
+
+	go func() {
+		for data := range queue {
+			var message SessionMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				logrus.WithError(err).Warn("failed to unmarshal session message")
+				continue
+			}
+			handler(message)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ms.mu.Lock()
+			defer ms.mu.Unlock()
+			if _, ok := ms.subscribers[id]; ok {
+				delete(ms.subscribers, id)
+				close(queue)
+			}
+		})
+	}, nil
+}
+
+// Close drops every subscriber.
+func (ms *MemorySessionStore) Close() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for id, queue := range ms.subscribers {
+		delete(ms.subscribers, id)
+		close(queue)
+	}
+	return nil
+}
+
+// attachSessionStore opens the configured session store, names this
+// instance, and subscribes to messages published by other instances.
+func (s *RPCServer) attachSessionStore(cfg *config.Config) error {
+	store, err := OpenSessionStore(SessionStoreOptions{
+		Backend:        cfg.SessionStoreBackend,
+		RedisAddr:      cfg.RedisAddr,
+		RedisPassword:  cfg.RedisPassword,
+		RedisDB:        cfg.RedisDB,
+		RedisKeyPrefix: cfg.RedisKeyPrefix,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open session store: %w", err)
+	}
+	return s.useSessionStore(store, cfg.InstanceID)
+}
+
+// useSessionStore shares this server's sessions through store, as the
+// instance named instanceID (a random ID when empty).
+func (s *RPCServer) useSessionStore(store SessionStore, instanceID string) error {
+	if instanceID == "" {
+		instanceID = uuid.New().String()
+	}
+
+	cancel, err := store.Subscribe(s.handleSessionMessage)
+	if err != nil {
+		store.Close()
+		return fmt.Errorf("failed to subscribe to session messages: %w", err)
+	}
+
+	s.sessionStore = store
+	s.instanceID = instanceID
+	s.sessionSubCancel = cancel
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "useSessionStore",
+		"instanceID": instanceID,
+	}).Info("session store attached")
+	return nil
+}
+
+// closeSessionStore stops receiving session messages and closes the store.
+func (s *RPCServer) closeSessionStore() {
+	if s.sessionStore == nil {
+		return
+	}
+	if s.sessionSubCancel != nil {
+		s.sessionSubCancel()
+	}
+	if err := s.sessionStore.Close(); err != nil {
+		logrus.WithError(err).Warn("failed to close session store")
+	}
+}
+
+// sessionRecordTTL is how long a shared session record outlives its last
+// save: the session timeout plus one refresh interval, so records of active
+// sessions are refreshed before they expire.
+func (s *RPCServer) sessionRecordTTL() time.Duration {
+	timeout := sessionTimeout
+	if s.config != nil && s.config.GetSessionTimeout() > 0 {
+		timeout = s.config.GetSessionTimeout()
+	}
+	return timeout + sessionCleanupInterval
+}
+
+// shareSession saves the record of a session held by this instance, so
+// other instances can find and take over the session. Failures are logged;
+// the session keeps working on this instance.
+func (s *RPCServer) shareSession(session *PlayerSession) {
+	if s.sessionStore == nil || session == nil {
+		return
+	}
+
+	if err := s.sessionStore.Save(s.sessionRecord(session), s.sessionRecordTTL()); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "shareSession",
+			"sessionID": session.SessionID,
+			"error":     err.Error(),
+		}).Warn("failed to save shared session record")
+	}
+}
+
+// sessionRecord returns the shareable part of a session held by this
+// instance.
+func (s *RPCServer) sessionRecord(session *PlayerSession) SessionRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record := SessionRecord{
+		SessionID:     session.SessionID,
+		Player:        session.Player,
+		InstanceID:    s.instanceID,
+		AffinitySince: session.affinitySince,
+		Connected:     session.Connected,
+		CreatedAt:     session.CreatedAt,
+		LastActive:    session.LastActive,
+	}
+	if record.AffinitySince.IsZero() {
+		record.AffinitySince = session.CreatedAt
+	}
+	return record
+}
+
+// unshareSession removes the shared record of a session that has ended on
+// this instance. Records another instance has taken over are left alone.
+func (s *RPCServer) unshareSession(sessionID string) {
+	if s.sessionStore == nil {
+		return
+	}
+
+	record, ok, err := s.sessionStore.Load(sessionID)
+	if err == nil && ok && record.InstanceID == s.instanceID {
+		err = s.sessionStore.Delete(sessionID)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "unshareSession",
+			"sessionID": sessionID,
+			"error":     err.Error(),
+		}).Warn("failed to delete shared session record")
+	}
+}
+
+// adoptSession takes over a session that another instance shared, for
+// example after a load balancer moved the player or the owning instance
+// stopped. The session is registered locally and its record is updated to
+// point at this instance. It returns false when no record exists.
+func (s *RPCServer) adoptSession(sessionID string) (*PlayerSession, bool) {
+	if s.sessionStore == nil || sessionID == "" {
+		return nil, false
+	}
+
+	record, ok, err := s.sessionStore.Load(sessionID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "adoptSession",
+			"sessionID": sessionID,
+			"error":     err.Error(),
+		}).Warn("failed to load shared session record")
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		session = &PlayerSession{
+			SessionID:     record.SessionID,
+			Player:        record.Player,
+			CreatedAt:     record.CreatedAt,
+			LastActive:    time.Now(),
+			MessageChan:   make(chan []byte, MessageChanBufferSize),
+			events:        newEventBuffer(ResumeEventBufferSize),
+			affinitySince: time.Now(),
+		}
+		s.sessions[sessionID] = session
+	}
+	s.mu.Unlock()
+
+	if exists {
+		return session, true
+	}
+
+	if s.state != nil {
+		s.state.AddPlayer(session)
+	}
+	s.shareSession(session)
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "adoptSession",
+		"sessionID":    sessionID,
+		"fromInstance": record.InstanceID,
+		"toInstance":   s.instanceID,
+	}).Info("took over shared session")
+	return session, true
+}
+
+// refreshSharedSessions saves the records of every session held by this
+// instance, extending their expiry and publishing current player state.
+// Sessions another instance has taken over are dropped here without
+// touching their records.
+func (s *RPCServer) refreshSharedSessions() {
+	if s.sessionStore == nil {
+		return
+	}
+
+	s.mu.RLock()
+	sessions := make([]*PlayerSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if session != nil {
+			sessions = append(sessions, session)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, session := range sessions {
+		record, ok, err := s.sessionStore.Load(session.SessionID)
+		if err == nil && ok && record.InstanceID != s.instanceID && record.AffinitySince.After(session.affinitySince) {
+			s.releaseMovedSession(session, record.InstanceID)
+			continue
+		}
+		s.shareSession(session)
+	}
+}
+
+// releaseMovedSession drops the local copy of a session that another
+// instance has taken over.
+func (s *RPCServer) releaseMovedSession(session *PlayerSession, owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sessions[session.SessionID] != session || session.isInUse() {
+		return
+	}
+	if session.WSConn != nil {
+		session.WSConn.Close()
+	}
+	delete(s.sessions, session.SessionID)
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "releaseMovedSession",
+		"sessionID": session.SessionID,
+		"owner":     owner,
+	}).Info("released session taken over by another instance")
+}
+
+// publishSessionEvent fans a WebSocket event out to the other instances.
+// sessionID targets a single session; empty broadcasts to every session.
+func (s *RPCServer) publishSessionEvent(sessionID string, event map[string]interface{}) {
+	if s.sessionStore == nil {
+		return
+	}
+	err := s.sessionStore.Publish(SessionMessage{
+		Origin:    s.instanceID,
+		SessionID: sessionID,
+		Event:     event,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "publishSessionEvent",
+			"sessionID": sessionID,
+			"error":     err.Error(),
+		}).Warn("failed to publish session event")
+	}
+}
+
+// handleSessionMessage delivers an event published by another instance to
+// the WebSocket clients of this instance.
+func (s *RPCServer) handleSessionMessage(message SessionMessage) {
+	if message.Origin == s.instanceID || s.broadcaster == nil || message.Event == nil {
+		return
+	}
+
+	if message.SessionID != "" {
+		s.broadcaster.sendToLocalSession(message.SessionID, message.Event)
+		return
+	}
+	s.broadcaster.deliver(message.Event)
+}
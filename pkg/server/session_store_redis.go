@@ -0,0 +1,463 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Redis connection settings
+const (
+	redisDialTimeout      = 5 * time.Second
+	redisIOTimeout        = 5 * time.Second
+	redisResubscribeDelay = 500 * time.Millisecond
+	redisScanCount        = 100
+)
+
+// RedisOptions configures a RedisSessionStore.
+type RedisOptions struct {
+	Addr      string // Redis host:port
+	Password  string // AUTH password; empty skips AUTH
+	DB        int    // Database selected after connecting
+	KeyPrefix string // Prefix of every key and channel, e.g. "goldbox:"
+}
+
+// RedisSessionStore shares sessions between server instances through Redis.
+// Records are stored as YAML under "<prefix>session:<id>" with a PX expiry,
+// and WebSocket fan-out uses the "<prefix>session-events" pub/sub channel.
+//
+// It speaks the Redis protocol (RESP) directly over one command connection,
+// plus one connection per subscription. RedisSessionStore is thread-safe.
+type RedisSessionStore struct {
+	opts   RedisOptions
+	mu     sync.Mutex // Serializes commands on conn
+	conn   *redisConn
+	closed bool
+}
+
+// NewRedisSessionStore connects to the Redis server described by opts.
+//
+// Parameters:
+//   - opts: Redis address, credentials, database and key prefix
+//
+// Returns:
+//   - *RedisSessionStore: The connected store
+//   - error: Connection or authentication failure
+func NewRedisSessionStore(opts RedisOptions) (*RedisSessionStore, error) {
+	conn, err := dialRedis(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisSessionStore{opts: opts, conn: conn}, nil
+}
+
+// sessionKey returns the Redis key of a session record.
+func (rs *RedisSessionStore) sessionKey(sessionID string) string {
+	return rs.opts.KeyPrefix + "session:" + sessionID
+}
+
+// eventChannel returns the pub/sub channel used for WebSocket fan-out.
+func (rs *RedisSessionStore) eventChannel() string {
+	return rs.opts.KeyPrefix + "session-events"
+}
+
+// redisRetryableCommands are the commands that may be sent again after the
+// connection was lost. A lost command may already have run, so commands
+// with side effects that must not repeat, such as PUBLISH, are not retried.
+var redisRetryableCommands = map[string]bool{
+	"GET":  true,
+	"SET":  true,
+	"DEL":  true,
+	"SCAN": true,
+}
+
+// do runs a command on the command connection. If the connection was lost
+// it is replaced, and retryable commands are sent once more.
+func (rs *RedisSessionStore) do(args ...string) (interface{}, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.closed {
+		return nil, fmt.Errorf("redis session store is closed")
+	}
+
+	reply, err := rs.conn.do(args...)
+	var redisErr redisError
+	if err == nil || errors.As(err, &redisErr) {
+		return reply, err
+	}
+
+	// The connection failed; replace it and retry once if that is safe
+	rs.conn.close()
+	conn, dialErr := dialRedis(rs.opts)
+	if dialErr != nil {
+		return nil, fmt.Errorf("redis command failed: %w (reconnect: %v)", err, dialErr)
+	}
+	rs.conn = conn
+	if !redisRetryableCommands[strings.ToUpper(args[0])] {
+		return nil, fmt.Errorf("redis %s failed: %w", args[0], err)
+	}
+	return rs.conn.do(args...)
+}
+
+// Save stores record with a PX expiry of ttl.
+func (rs *RedisSessionStore) Save(record SessionRecord, ttl time.Duration) error {
+	data, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session record: %w", err)
+	}
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	_, err = rs.do("SET", rs.sessionKey(record.SessionID), string(data), "PX", strconv.FormatInt(ms, 10))
+	return err
+}
+
+// Load returns the record of a session.
+func (rs *RedisSessionStore) Load(sessionID string) (SessionRecord, bool, error) {
+	reply, err := rs.do("GET", rs.sessionKey(sessionID))
+	if err != nil {
+		return SessionRecord{}, false, err
+	}
+	if reply == nil {
+		return SessionRecord{}, false, nil
+	}
+	return decodeSessionRecord(reply)
+}
+
+// Delete removes the record of a session.
+func (rs *RedisSessionStore) Delete(sessionID string) error {
+	_, err := rs.do("DEL", rs.sessionKey(sessionID))
+	return err
+}
+
+// List scans the session keys and returns their records, ordered by
+// session ID. Records that expire during the scan are skipped.
+func (rs *RedisSessionStore) List() ([]SessionRecord, error) {
+	prefix := rs.sessionKey("")
+	var ids []string
+	cursor := "0"
+	for {
+		reply, err := rs.do("SCAN", cursor, "MATCH", prefix+"*", "COUNT", strconv.Itoa(redisScanCount))
+		if err != nil {
+			return nil, err
+		}
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, fmt.Errorf("unexpected SCAN reply: %v", reply)
+		}
+		next, _ := page[0].([]byte)
+		keys, _ := page[1].([]interface{})
+		for _, key := range keys {
+			if k, ok := key.([]byte); ok {
+				ids = append(ids, strings.TrimPrefix(string(k), prefix))
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			break
+		}
+	}
+
+	records := make([]SessionRecord, 0, len(ids))
+	for _, id := range sortedUnique(ids) {
+		record, ok, err := rs.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// Publish sends message on the session event channel.
+func (rs *RedisSessionStore) Publish(message SessionMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session message: %w", err)
+	}
+	_, err = rs.do("PUBLISH", rs.eventChannel(), string(data))
+	return err
+}
+
+// Subscribe opens a dedicated connection subscribed to the session event
+// channel and calls handler for each message. A lost subscription is
+// re-established until cancel is called; messages published while
+// reconnecting are missed.
+func (rs *RedisSessionStore) Subscribe(handler func(SessionMessage)) (func(), error) {
+	conn, err := rs.subscribe()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &redisSubscription{conn: conn, done: make(chan struct{})}
+	go sub.run(rs, handler)
+	return sub.cancel, nil
+}
+
+// subscribe connects and subscribes to the session event channel.
+func (rs *RedisSessionStore) subscribe() (*redisConn, error) {
+	conn, err := dialRedis(rs.opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.send("SUBSCRIBE", rs.eventChannel()); err != nil {
+		conn.close()
+		return nil, err
+	}
+	// Confirmation: ["subscribe", channel, count]
+	conn.netConn.SetReadDeadline(time.Now().Add(redisIOTimeout))
+	if _, err := conn.readReply(); err != nil {
+		conn.close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", rs.eventChannel(), err)
+	}
+	conn.netConn.SetReadDeadline(time.Time{})
+	return conn, nil
+}
+
+// Close closes the command connection. Subscriptions are closed by their
+// cancel functions.
+func (rs *RedisSessionStore) Close() error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.closed {
+		return nil
+	}
+	rs.closed = true
+	return rs.conn.close()
+}
+
+// redisSubscription is a running Subscribe call.
+type redisSubscription struct {
+	mu   sync.Mutex
+	conn *redisConn
+	done chan struct{}
+	once sync.Once
+}
+
+// run reads published messages until the subscription is canceled.
+func (sub *redisSubscription) run(rs *RedisSessionStore, handler func(SessionMessage)) {
+	for {
+		sub.mu.Lock()
+		conn := sub.conn
+		sub.mu.Unlock()
+
+		err := sub.read(conn, handler)
+		select {
+		case <-sub.done:
+			return
+		default:
+		}
+		logrus.WithError(err).Warn("redis session subscription lost, resubscribing")
+
+		for conn = nil; conn == nil; {
+			select {
+			case <-sub.done:
+				return
+			case <-time.After(redisResubscribeDelay):
+			}
+			if conn, err = rs.subscribe(); err != nil {
+				logrus.WithError(err).Warn("failed to resubscribe to redis session events")
+				conn = nil
+			}
+		}
+
+		sub.mu.Lock()
+		select {
+		case <-sub.done:
+			conn.close()
+			sub.mu.Unlock()
+			return
+		default:
+		}
+		sub.conn = conn
+		sub.mu.Unlock()
+	}
+}
+
+// read delivers messages from conn until it fails.
+func (sub *redisSubscription) read(conn *redisConn, handler func(SessionMessage)) error {
+	for {
+		reply, err := conn.readReply()
+		if err != nil {
+			return err
+		}
+		// Messages are ["message", channel, payload]
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 3 {
+			continue
+		}
+		if kind, _ := parts[0].([]byte); string(kind) != "message" {
+			continue
+		}
+		payload, _ := parts[2].([]byte)
+		var message SessionMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			logrus.WithError(err).Warn("failed to unmarshal session message")
+			continue
+		}
+		handler(message)
+	}
+}
+
+// cancel stops the subscription and closes its connection.
+func (sub *redisSubscription) cancel() {
+	sub.once.Do(func() {
+		sub.mu.Lock()
+		defer sub.mu.Unlock()
+		close(sub.done)
+		sub.conn.close()
+	})
+}
+
+// redisError is an error reply sent by the Redis server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn is a single RESP connection to a Redis server.
+type redisConn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+}
+
+// dialRedis connects to Redis, authenticates and selects the database.
+func dialRedis(opts RedisOptions) (*redisConn, error) {
+	netConn, err := net.DialTimeout("tcp", opts.Addr, redisDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", opts.Addr, err)
+	}
+	conn := &redisConn{netConn: netConn, reader: bufio.NewReader(netConn)}
+
+	if opts.Password != "" {
+		if _, err := conn.do("AUTH", opts.Password); err != nil {
+			conn.close()
+			return nil, fmt.Errorf("redis authentication failed: %w", err)
+		}
+	}
+	if opts.DB != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(opts.DB)); err != nil {
+			conn.close()
+			return nil, fmt.Errorf("failed to select redis database %d: %w", opts.DB, err)
+		}
+	}
+	return conn, nil
+}
+
+// do sends a command and reads its reply within redisIOTimeout.
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	c.netConn.SetDeadline(time.Now().Add(redisIOTimeout))
+	defer c.netConn.SetDeadline(time.Time{})
+
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// send writes a command as a RESP array of bulk strings.
+func (c *redisConn) send(args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(c.netConn, b.String())
+	return err
+}
+
+// readReply reads one RESP reply. Simple strings and bulk strings are
+// returned as []byte, integers as int64, arrays as []interface{}, and nil
+// bulk strings and arrays as nil. Error replies are returned as redisError.
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return []byte(line[1:]), nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply: %q", line)
+	}
+}
+
+// close closes the network connection.
+func (c *redisConn) close() error {
+	return c.netConn.Close()
+}
+
+// decodeSessionRecord parses a session record stored as a bulk string.
+func decodeSessionRecord(reply interface{}) (SessionRecord, bool, error) {
+	data, ok := reply.([]byte)
+	if !ok {
+		return SessionRecord{}, false, fmt.Errorf("unexpected session record reply: %v", reply)
+	}
+	var record SessionRecord
+	if err := yaml.Unmarshal(data, &record); err != nil {
+		return SessionRecord{}, false, fmt.Errorf("failed to unmarshal session record: %w", err)
+	}
+	return record, true, nil
+}
+
+// sortedUnique sorts ids and removes duplicates, which SCAN may return.
+func sortedUnique(ids []string) []string {
+	if len(ids) == 0 {
+		return ids
+	}
+	sort.Strings(ids)
+	unique := ids[:1]
+	for _, id := range ids[1:] {
+		if id != unique[len(unique)-1] {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,356 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSharingTestServer creates a minimal server sharing its sessions
+// through store as instanceID
+func newSharingTestServer(t *testing.T, store SessionStore, instanceID string) *RPCServer {
+	server := &RPCServer{
+		sessions: make(map[string]*PlayerSession),
+		state: &GameState{
+			WorldState: game.NewWorld(),
+			Sessions:   make(map[string]*PlayerSession),
+		},
+		eventSys: game.NewEventSystem(),
+	}
+	server.broadcaster = NewWebSocketBroadcaster(server)
+	require.NoError(t, server.useSessionStore(store, instanceID))
+	t.Cleanup(func() { server.sessionSubCancel() })
+	return server
+}
+
+// testSessionStores runs the same behaviour checks against every backend
+func testSessionStores(t *testing.T) map[string]func(t *testing.T) SessionStore {
+	return map[string]func(t *testing.T) SessionStore{
+		SessionStoreMemory: func(t *testing.T) SessionStore {
+			store, err := OpenSessionStore(SessionStoreOptions{})
+			require.NoError(t, err)
+			return store
+		},
+		SessionStoreRedis: func(t *testing.T) SessionStore {
+			store, err := OpenSessionStore(SessionStoreOptions{
+				Backend:        SessionStoreRedis,
+				RedisAddr:      startFakeRedis(t),
+				RedisKeyPrefix: "test:",
+			})
+			require.NoError(t, err)
+			return store
+		},
+	}
+}
+
+func TestSessionStoreBackends(t *testing.T) {
+	for name, open := range testSessionStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			player := &game.Player{Character: game.Character{ID: "hero", Name: "Hero"}, Level: 3}
+			record := SessionRecord{SessionID: "s1", Player: player, InstanceID: "a", CreatedAt: time.Now().UTC()}
+			require.NoError(t, store.Save(record, time.Minute))
+			require.NoError(t, store.Save(SessionRecord{SessionID: "s0", InstanceID: "b"}, time.Minute))
+
+			loaded, ok, err := store.Load("s1")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, "a", loaded.InstanceID)
+			require.NotNil(t, loaded.Player)
+			assert.Equal(t, "Hero", loaded.Player.Name)
+			assert.Equal(t, 3, loaded.Player.Level)
+
+			records, err := store.List()
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			assert.Equal(t, "s0", records[0].SessionID)
+
+			require.NoError(t, store.Delete("s1"))
+			require.NoError(t, store.Delete("missing"))
+			_, ok, err = store.Load("s1")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			received := make(chan SessionMessage, 1)
+			cancel, err := store.Subscribe(func(message SessionMessage) { received <- message })
+			require.NoError(t, err)
+			defer cancel()
+
+			require.NoError(t, store.Publish(SessionMessage{Origin: "a", SessionID: "s0", Event: map[string]interface{}{"type": "ping"}}))
+			select {
+			case message := <-received:
+				assert.Equal(t, "a", message.Origin)
+				assert.Equal(t, "s0", message.SessionID)
+				assert.Equal(t, "ping", message.Event["type"])
+			case <-time.After(2 * time.Second):
+				t.Fatal("published message not received")
+			}
+		})
+	}
+}
+
+func TestMemorySessionStore_Expiry(t *testing.T) {
+	store := NewMemorySessionStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Save(SessionRecord{SessionID: "s1"}, time.Minute))
+	now = now.Add(2 * time.Minute)
+
+	_, ok, err := store.Load("s1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestOpenSessionStore_UnknownBackend(t *testing.T) {
+	_, err := OpenSessionStore(SessionStoreOptions{Backend: "memcached"})
+	assert.Error(t, err)
+}
+
+func TestSessionStore_AdoptSessionFromOtherInstance(t *testing.T) {
+	store := NewMemorySessionStore()
+	first := newSharingTestServer(t, store, "instance-a")
+	second := newSharingTestServer(t, store, "instance-b")
+
+	player := &game.Player{Character: game.Character{ID: "hero", Name: "Hero"}}
+	session := first.createAndRegisterSession(player)
+
+	record, ok, err := store.Load(session.SessionID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "instance-a", record.InstanceID)
+
+	adopted, err := second.getPlayerSession(session.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hero", adopted.Player.Name)
+	_, inWorld := second.state.WorldState.Objects["hero"]
+	assert.True(t, inWorld)
+
+	record, _, err = store.Load(session.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "instance-b", record.InstanceID)
+
+	// The first instance drops its stale copy instead of reclaiming the session
+	first.refreshSharedSessions()
+	_, exists := first.sessions[session.SessionID]
+	assert.False(t, exists)
+	record, _, err = store.Load(session.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "instance-b", record.InstanceID)
+
+	// Leaving removes the record owned by the second instance
+	require.NoError(t, second.executeSessionCleanup(session.SessionID))
+	_, ok, err = store.Load(session.SessionID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = second.getPlayerSession("unknown")
+	assert.ErrorIs(t, err, ErrInvalidSession)
+}
+
+func TestSessionStore_BroadcastFanOut(t *testing.T) {
+	store := NewMemorySessionStore()
+	first := newSharingTestServer(t, store, "instance-a")
+	second := newSharingTestServer(t, store, "instance-b")
+	first.broadcaster.Start()
+	second.broadcaster.Start()
+
+	remote := &PlayerSession{SessionID: "remote", events: newEventBuffer(ResumeEventBufferSize)}
+	second.setSession(remote.SessionID, remote)
+	local := &PlayerSession{SessionID: "local", events: newEventBuffer(ResumeEventBufferSize)}
+	first.setSession(local.SessionID, local)
+
+	first.eventSys.Emit(game.GameEvent{Type: game.EventMovement, SourceID: "hero"})
+
+	require.Eventually(t, func() bool {
+		events, _ := remote.events.since(0)
+		return len(events) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	events, _ := remote.events.since(0)
+	assert.Equal(t, "hero", events[0]["source"])
+
+	// The publishing instance delivers its own events once
+	events, _ = local.events.since(0)
+	assert.Len(t, events, 1)
+}
+
+func TestRedisSessionStore_RetriesOnlyRetryableCommands(t *testing.T) {
+	fake, addr := newFakeRedis(t)
+	store, err := NewRedisSessionStore(RedisOptions{Addr: addr, KeyPrefix: "test:"})
+	require.NoError(t, err)
+	defer store.Close()
+
+	// The lost PUBLISH already ran, so sending it again would deliver twice
+	fake.mu.Lock()
+	fake.hangUp["PUBLISH"] = true
+	fake.mu.Unlock()
+	_, err = store.do("PUBLISH", "test:session-events", "event")
+	assert.Error(t, err)
+
+	fake.mu.Lock()
+	fake.hangUp["SET"] = true
+	fake.mu.Unlock()
+	_, err = store.do("SET", "test:key", "value")
+	require.NoError(t, err, "SET is retried on a new connection")
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, 1, fake.calls["PUBLISH"])
+	assert.Equal(t, 2, fake.calls["SET"])
+	assert.Equal(t, "value", fake.data["test:key"])
+}
+
+// fakeRedis is a minimal in-process Redis server supporting the commands
+// used by RedisSessionStore
+type fakeRedis struct {
+	mu          sync.Mutex
+	data        map[string]string
+	subscribers map[string][]net.Conn
+	conns       []net.Conn
+	calls       map[string]int  // Commands received by name
+	hangUp      map[string]bool // Commands that run but close the connection instead of replying
+}
+
+// startFakeRedis starts a fake Redis server and returns its address
+func startFakeRedis(t *testing.T) string {
+	_, addr := newFakeRedis(t)
+	return addr
+}
+
+// newFakeRedis starts a fake Redis server and returns it with its address
+func newFakeRedis(t *testing.T) (*fakeRedis, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	fake := &fakeRedis{
+		data:        make(map[string]string),
+		subscribers: make(map[string][]net.Conn),
+		calls:       make(map[string]int),
+		hangUp:      make(map[string]bool),
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		for _, conn := range fake.conns {
+			conn.Close()
+		}
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			fake.mu.Lock()
+			fake.conns = append(fake.conns, conn)
+			fake.mu.Unlock()
+			go fake.serve(conn)
+		}
+	}()
+	return fake, listener.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readFakeRedisCommand(reader)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		command := strings.ToUpper(args[0])
+		f.calls[command]++
+		reply := f.execute(conn, args)
+		hangUp := f.hangUp[command]
+		delete(f.hangUp, command)
+		f.mu.Unlock()
+		if hangUp {
+			conn.Close()
+			return
+		}
+		conn.Write([]byte(reply))
+	}
+}
+
+func (f *fakeRedis) execute(conn net.Conn, args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "SET":
+		f.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case "GET":
+		value, ok := f.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulkString(value)
+	case "DEL":
+		_, ok := f.data[args[1]]
+		delete(f.data, args[1])
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "SCAN":
+		var keys []string
+		for key := range f.data {
+			if matched, _ := path.Match(args[3], key); matched {
+				keys = append(keys, key)
+			}
+		}
+		reply := "*2\r\n" + bulkString("0") + "*" + strconv.Itoa(len(keys)) + "\r\n"
+		for _, key := range keys {
+			reply += bulkString(key)
+		}
+		return reply
+	case "PUBLISH":
+		for _, subscriber := range f.subscribers[args[1]] {
+			subscriber.Write([]byte("*3\r\n" + bulkString("message") + bulkString(args[1]) + bulkString(args[2])))
+		}
+		return ":" + strconv.Itoa(len(f.subscribers[args[1]])) + "\r\n"
+	case "SUBSCRIBE":
+		f.subscribers[args[1]] = append(f.subscribers[args[1]], conn)
+		return "*3\r\n" + bulkString("subscribe") + bulkString(args[1]) + ":1\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func readFakeRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, count)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		arg := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:length])
+	}
+	return args, nil
+}
+
+func bulkString(value string) string {
+	return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+}
@@ -14,7 +14,6 @@ import (
 func TestGetOrCreateSession_CreateNewSession(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute,
 		},
@@ -80,7 +79,6 @@ func TestGetOrCreateSession_CreateNewSession(t *testing.T) {
 func TestGetOrCreateSession_RetrieveExistingSession(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute,
 		},
@@ -137,7 +135,6 @@ func TestGetOrCreateSession_RetrieveExistingSession(t *testing.T) {
 func TestGetOrCreateSession_InvalidSessionCookie(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute,
 		},
@@ -179,7 +176,6 @@ func TestGetOrCreateSession_InvalidSessionCookie(t *testing.T) {
 func TestGetOrCreateSession_ConcurrentAccess(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute,
 		},
@@ -236,7 +232,6 @@ func TestGetOrCreateSession_ConcurrentAccess(t *testing.T) {
 func TestStartSessionCleanup(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		done:     make(chan struct{}),
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute,
@@ -285,7 +280,6 @@ func TestStartSessionCleanup(t *testing.T) {
 func TestCleanupExpiredSessions(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute,
 		},
@@ -327,7 +321,6 @@ func TestCleanupExpiredSessions(t *testing.T) {
 func TestCleanupExpiredSessions_WithWebSocketConnection(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute,
 		},
@@ -394,7 +387,6 @@ func TestGetOrCreateSession_TableDriven(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			server := &RPCServer{
 				sessions: make(map[string]*PlayerSession),
-				mu:       sync.RWMutex{},
 				config: &config.Config{
 					SessionTimeout: 30 * time.Minute,
 				},
@@ -501,7 +493,6 @@ func TestSecureCookieSettings(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			server := &RPCServer{
 				sessions: make(map[string]*PlayerSession),
-				mu:       sync.RWMutex{},
 				config: &config.Config{
 					SessionTimeout: 30 * time.Minute,
 				},
@@ -603,7 +594,6 @@ func TestSafeSendMessage_NilChannel(t *testing.T) {
 func TestHandleCreateCharacter_SessionCollisionDetection(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 		config: &config.Config{
 			SessionTimeout: 30 * time.Minute,
 		},
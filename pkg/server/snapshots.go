@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/sirupsen/logrus"
+)
+
+// snapshotKeys are the documents captured together by an auto-save
+// snapshot, so a restore never pairs a world with another point in time's
+// PCG seeds.
+var snapshotKeys = []string{gameStateKey, pcgStateKey, worldEventsKey, chronicleKey, campaignStatsKey, annotationsKey}
+
+// autoSnapshot takes a snapshot of the saved state if the last one is older
+// than the configured snapshot interval. It is called after every
+// successful auto-save.
+func (s *RPCServer) autoSnapshot() {
+	if s.snapshots == nil {
+		return
+	}
+
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	info, err := s.snapshots.AutoSnapshot(snapshotKeys)
+	if err != nil {
+		logrus.WithError(err).WithField("function", "autoSnapshot").Error("auto-save snapshot failed")
+		return
+	}
+	if info != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "autoSnapshot",
+			"snapshot": info.ID,
+			"size":     info.Size,
+		}).Debug("auto-save snapshot taken")
+	}
+}
+
+// startSnapshotCompaction periodically thins out old snapshots and checks
+// the remaining ones against their checksums until the server shuts down.
+func (s *RPCServer) startSnapshotCompaction() {
+	if s.snapshots == nil || s.config == nil || s.config.SnapshotCompactSpacing <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.SnapshotCompactSpacing)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.compactSnapshots()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// compactSnapshots compacts the stored snapshots and logs any that are
+// corrupt.
+func (s *RPCServer) compactSnapshots() {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "compactSnapshots",
+	})
+
+	removed, err := s.snapshots.Compact()
+	if err != nil {
+		logger.WithError(err).Error("snapshot compaction failed")
+		return
+	}
+	checked, err := s.snapshots.Verify()
+	if err != nil {
+		logger.WithError(err).WithField("checked", checked).Error("snapshot integrity check failed")
+		return
+	}
+	logger.WithFields(logrus.Fields{
+		"removed": removed,
+		"checked": checked,
+	}).Debug("snapshots compacted")
+}
+
+// handleListSnapshots returns the stored auto-save snapshots, oldest first.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating if the query succeeded
+//   - snapshots: []persistence.SnapshotInfo with the ID to pass to
+//     admin.restoreSnapshot
+//   - error: Invalid parameters or session, or snapshots are disabled
+func (s *RPCServer) handleListSnapshots(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleListSnapshots",
+	})
+	logger.Debug("entering handleListSnapshots")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid list snapshots parameters", err.Error())
+	}
+
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	if s.snapshots == nil {
+		return nil, ErrUnavailable.WithMessage("Snapshots not enabled")
+	}
+
+	snapshots, err := s.snapshots.Snapshots()
+	if err != nil {
+		logger.WithError(err).Error("failed to list snapshots")
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to list snapshots", err.Error())
+	}
+
+	return map[string]interface{}{
+		"success":   true,
+		"snapshots": snapshots,
+	}, nil
+}
+
+// handleAdminRestoreSnapshot verifies a snapshot and restores the game
+// state and PCG state it captured together, then reloads them into the
+// running server. Every captured document the server loads is decoded
+// first, and the snapshot is rejected if one fails, so the live documents
+// are only replaced by ones the server can reload. With backups enabled the
+// documents being replaced are backed up first, so the restore can itself
+// be undone.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - admin_token: string - The admin token
+//   - snapshot_id: string - A snapshot ID returned by listSnapshots
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating the snapshot was restored
+//   - keys: The documents that were restored
+//   - error: Invalid parameters, an unknown, corrupt or unloadable
+//     snapshot, or restored state that fails to reload
+func (s *RPCServer) handleAdminRestoreSnapshot(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleAdminRestoreSnapshot",
+	})
+	logger.Debug("entering handleAdminRestoreSnapshot")
+
+	var req struct {
+		SnapshotID string `json:"snapshot_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid restore snapshot parameters", err.Error())
+	}
+
+	if s.snapshots == nil {
+		return nil, ErrUnavailable.WithMessage("Snapshots not enabled")
+	}
+
+	// Hold off auto-saves so the restored data is not overwritten with the
+	// in-memory state before it has been reloaded
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	_, err := s.snapshots.Open(req.SnapshotID, reloadedValue)
+	var keys []string
+	if err == nil {
+		keys, err = s.snapshots.Restore(req.SnapshotID)
+	}
+	if err != nil {
+		logger.WithError(err).WithField("snapshot_id", req.SnapshotID).Warn("snapshot restore rejected")
+		if errors.Is(err, persistence.ErrSnapshotCorrupt) {
+			return nil, NewJSONRPCError(JSONRPCInternalError, "Snapshot failed integrity check", err.Error())
+		}
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Failed to restore snapshot", err.Error())
+	}
+
+	for _, key := range keys {
+		switch key {
+		case gameStateKey:
+			if err := s.state.LoadFromFile(s.store); err != nil {
+				return nil, NewJSONRPCError(JSONRPCInternalError, "Snapshot restored but game state failed to reload", err.Error())
+			}
+			s.scheduleShopRestock()
+		case pcgStateKey:
+			s.restorePCGState()
+		case worldEventsKey:
+			s.restoreWorldEvents()
+		case chronicleKey:
+			s.restoreChronicle()
+		case campaignStatsKey:
+			s.restoreCampaignStats()
+		case annotationsKey:
+			s.restoreAnnotations()
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"snapshot_id": req.SnapshotID,
+		"keys":        keys,
+	}).Info("snapshot restored")
+
+	return map[string]interface{}{
+		"success": true,
+		"keys":    keys,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/persistence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreSnapshot_ReloadsGameAndPCGState(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	server.store = persistence.NewMemoryStore()
+	server.snapshots = persistence.NewSnapshotManager(server.store, persistence.SnapshotPolicy{MaxCount: 5})
+	objects := len(server.state.WorldState.Objects)
+	require.NotZero(t, objects)
+
+	server.state.Version = 1
+	seeds := server.pcgManager.GetSeedManager()
+	baseSeed := seeds.GetBaseSeed()
+	require.NoError(t, server.persistState())
+	server.autoSnapshot()
+
+	server.state.Version = 2
+	seeds.LoadState(pcg.SaveableState{BaseSeed: baseSeed + 1})
+	require.NoError(t, server.persistState())
+
+	result, err := server.handleListSnapshots(json.RawMessage(`{"session_id":"` + session.SessionID + `"}`))
+	require.NoError(t, err)
+	snapshots := result.(map[string]interface{})["snapshots"].([]persistence.SnapshotInfo)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, []string{annotationsKey, campaignStatsKey, chronicleKey, gameStateKey, pcgStateKey}, snapshots[0].Keys)
+
+	server.state.WorldState.Objects = map[string]game.GameObject{}
+	result, err = server.handleAdminRestoreSnapshot(json.RawMessage(`{"snapshot_id":"` + snapshots[0].ID + `"}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{annotationsKey, campaignStatsKey, chronicleKey, gameStateKey, pcgStateKey}, result.(map[string]interface{})["keys"])
+	assert.Equal(t, 1, server.state.Version)
+	assert.Equal(t, baseSeed, seeds.GetBaseSeed(), "the PCG state is restored with the game state")
+	assert.Len(t, server.state.WorldState.Objects, objects, "world objects are reloaded")
+
+	_, err = server.handleAdminRestoreSnapshot(json.RawMessage(`{"snapshot_id":"snapshots/missing.yaml"}`))
+	assert.Error(t, err)
+}
+
+func TestRestoreSnapshot_RejectsUnloadableSnapshot(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.store = persistence.NewMemoryStore()
+	server.snapshots = persistence.NewSnapshotManager(server.store, persistence.SnapshotPolicy{})
+	seeds := server.pcgManager.GetSeedManager()
+	baseSeed := seeds.GetBaseSeed()
+
+	require.NoError(t, server.store.SaveBatch(map[string]interface{}{
+		gameStateKey: map[string]interface{}{
+			"state_world": map[string]interface{}{
+				"world_objects": map[string]interface{}{"dragon-1": map[string]interface{}{"kind": "dragon"}},
+			},
+		},
+		pcgStateKey: pcg.SaveableState{BaseSeed: baseSeed + 1},
+	}))
+	info, err := server.snapshots.Take(snapshotKeys)
+	require.NoError(t, err)
+
+	server.state.Version = 5
+	require.NoError(t, server.persistState())
+
+	_, err = server.handleAdminRestoreSnapshot(json.RawMessage(`{"snapshot_id":"` + info.ID + `"}`))
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code)
+
+	// Neither document was replaced
+	loaded := &GameState{}
+	require.NoError(t, server.store.Load(gameStateKey, loaded))
+	assert.Equal(t, 5, loaded.Version)
+	var pcgState pcg.SaveableState
+	require.NoError(t, server.store.Load(pcgStateKey, &pcgState))
+	assert.Equal(t, baseSeed, pcgState.BaseSeed)
+}
+
+func TestRestoreSnapshot_RequiresAdmin(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	server.snapshots = persistence.NewSnapshotManager(persistence.NewMemoryStore(), persistence.SnapshotPolicy{})
+	server.admin = newTestAdminConsole(10, AdminPermissionInspect)
+
+	_, err := server.handleMethod(MethodAdminRestoreSnapshot, json.RawMessage(`{"session_id":"`+session.SessionID+`","snapshot_id":"snapshots/x.yaml"}`))
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, JSONRPCInvalidParams, rpcErr.Code, "a player session is not enough")
+
+	_, err = server.handleMethod(MethodAdminRestoreSnapshot, json.RawMessage(`{"admin_token":"`+testAdminToken+`","snapshot_id":"snapshots/x.yaml"}`))
+	assert.ErrorIs(t, err, ErrAdminForbidden)
+}
+
+func TestListSnapshots_RequiresSnapshots(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	server.snapshots = nil
+
+	_, err := server.handleListSnapshots(json.RawMessage(`{"session_id":"` + session.SessionID + `"}`))
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = server.handleAdminRestoreSnapshot(json.RawMessage(`{"snapshot_id":"snapshots/x.yaml"}`))
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
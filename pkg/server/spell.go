@@ -7,7 +7,9 @@ import (
 )
 
 // processSpellCast handles the execution of a spell cast by a player.
-// It validates the spell requirements and processes the effects based on the spell school.
+// It validates the spell requirements and processes the effects based on the spell school,
+// through the spell's effect scripts when it declares any, or as area damage
+// over its area template when it has one.
 //
 // Parameters:
 //   - caster: *game.Player - The player casting the spell
@@ -29,6 +31,8 @@ import (
 //   - processEnchantmentSpell
 //   - processIllusionSpell
 //   - processGenericSpell
+//   - processScriptedSpell
+//   - processAreaSpell
 func (s *RPCServer) processSpellCast(caster *game.Player, spell *game.Spell, targetID string, pos game.Position) (interface{}, error) {
 	s.logSpellCastStart(caster, spell, targetID)
 
@@ -37,9 +41,16 @@ func (s *RPCServer) processSpellCast(caster *game.Player, spell *game.Spell, tar
 		return nil, err
 	}
 
-	s.logSpellSchoolProcessing(spell)
-
-	result, err := s.dispatchSpellBySchool(spell, caster, targetID, pos)
+	var result interface{}
+	var err error
+	if len(spell.EffectScripts) > 0 {
+		result, err = s.processScriptedSpell(spell, caster, targetID, pos)
+	} else if spell.Area != nil {
+		result, err = s.processAreaSpell(spell, caster, targetID, pos)
+	} else {
+		s.logSpellSchoolProcessing(spell)
+		result, err = s.dispatchSpellBySchool(spell, caster, targetID, pos)
+	}
 	if err != nil {
 		s.logSpellProcessingError(err)
 	}
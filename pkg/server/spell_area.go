@@ -0,0 +1,132 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// processAreaSpell resolves a spell with an area template but no effect
+// scripts as area damage over its template, aimed at the target's position
+// when targetID names one.
+func (s *RPCServer) processAreaSpell(spell *game.Spell, caster *game.Player, targetID string, pos game.Position) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "processAreaSpell",
+		"spell_id": spell.ID,
+		"shape":    spell.Area.Shape,
+		"size":     spell.Area.Size,
+	}).Debug("processing area spell")
+
+	area := *spell
+	area.EffectScripts = []game.SpellEffectScript{{Handler: game.SpellEffectAreaDamage}}
+	result, err := s.processScriptedSpell(&area, caster, targetID, s.spellTargetPosition(targetID, pos))
+	if err != nil {
+		return nil, err
+	}
+	resultMap := result.(map[string]interface{})
+	resultMap["effect_type"] = "area"
+	return resultMap, nil
+}
+
+// spellTargetPosition returns the position of the object targetID names,
+// or pos when it names none.
+func (s *RPCServer) spellTargetPosition(targetID string, pos game.Position) game.Position {
+	if targetID == "" {
+		return pos
+	}
+	if target, exists := s.state.WorldState.GetObject(targetID); exists {
+		return target.GetPosition()
+	}
+	return pos
+}
+
+// spellAllies returns the friendly fire ally test for caster's area spells:
+// game.AreaAllies, plus the companions caster has hired.
+func (s *RPCServer) spellAllies(caster *game.Player) func(game.GameObject) bool {
+	allies := game.AreaAllies(caster)
+	companions := make(map[string]bool)
+	if s.companions != nil {
+		for _, companion := range s.companions.Of(caster.GetID()) {
+			companions[companion.GetID()] = true
+		}
+	}
+	return func(obj game.GameObject) bool {
+		return companions[obj.GetID()] || allies(obj)
+	}
+}
+
+// handlePreviewSpellTargets resolves a known spell's area of effect without
+// casting it, so clients can highlight the tiles and creatures it would
+// affect. Nothing is spent and the world is not changed.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the caster
+//   - spell_id: string - The spell to preview
+//   - target_id: string - Object to aim at (optional)
+//   - position: game.Position - Point to aim at when target_id is not given
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating if the preview succeeded
+//   - spell_id: The previewed spell
+//   - area: *game.AreaResolution with the origin, reached tiles, targets
+//     with ally and cover flags, and the creatures friendly fire spares
+//   - in_range: Whether the target point is within the spell's range
+//   - error: Invalid parameters or session, an unknown spell, or a spell
+//     without an area template
+func (s *RPCServer) handlePreviewSpellTargets(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handlePreviewSpellTargets",
+	})
+	logger.Debug("entering handlePreviewSpellTargets")
+
+	var req struct {
+		SessionID string        `json:"session_id"`
+		SpellID   string        `json:"spell_id"`
+		TargetID  string        `json:"target_id"`
+		Position  game.Position `json:"position,omitempty"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid spell preview parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	caster := session.Player
+
+	spell, err := s.validatePlayerSpellKnowledge(caster, req.SpellID)
+	if err != nil {
+		return nil, err
+	}
+	if spell.Area == nil {
+		return nil, ErrInvalidTarget.WithMessage("%s has no area of effect", spell.Name).WithData(map[string]interface{}{"spell_id": spell.ID})
+	}
+
+	target := s.spellTargetPosition(req.TargetID, req.Position)
+	area := s.state.WorldState.ResolveArea(*spell.Area, caster, target, game.ActiveFriendlyFire(), s.spellAllies(caster))
+
+	from := caster.GetPosition()
+	distance := math.Hypot(float64(target.X-from.X), float64(target.Y-from.Y))
+	inRange := spell.Range == 0 || (target.Level == from.Level && distance <= float64(spell.Range))
+
+	logger.WithFields(logrus.Fields{
+		"player_id": caster.GetID(),
+		"spell_id":  spell.ID,
+		"tiles":     len(area.Tiles),
+		"targets":   len(area.Targets),
+	}).Debug("spell targets previewed")
+
+	return map[string]interface{}{
+		"success":  true,
+		"spell_id": spell.ID,
+		"area":     area,
+		"in_range": inRange,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addAreaTestNPC places a 30 HP NPC at x, y
+func addAreaTestNPC(t *testing.T, server *RPCServer, id string, x, y int) *game.NPC {
+	t.Helper()
+	npc := &game.NPC{Character: game.Character{ID: id, Name: id, Position: game.Position{X: x, Y: y}, HP: 30, MaxHP: 30}}
+	require.NoError(t, server.state.WorldState.AddObject(npc))
+	return npc
+}
+
+func TestProcessAreaSpell(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	caster := &game.Player{Character: game.Character{ID: "player1", Position: game.Position{X: 5, Y: 5}}, Level: 5}
+	target := addAreaTestNPC(t, server, "orc", 10, 5)
+	neighbour := addAreaTestNPC(t, server, "orc_mate", 11, 6)
+	distant := addAreaTestNPC(t, server, "orc_scout", 15, 5)
+
+	spell := &game.Spell{
+		ID:         "fire_burst",
+		Name:       "Fire Burst",
+		Level:      1,
+		School:     game.SchoolEvocation,
+		DamageDice: "1d4+10",
+		DamageType: "fire",
+		AreaEffect: true,
+		Area:       &game.AreaTemplate{Shape: game.AreaCircle, Size: 2},
+	}
+	result, err := server.processSpellCast(caster, spell, target.ID, game.Position{})
+	require.NoError(t, err)
+
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, "area", resultMap["effect_type"])
+	effects := resultMap["effects"].([]*game.SpellEffectResult)
+	require.Len(t, effects, 1)
+	assert.ElementsMatch(t, []string{"orc", "orc_mate"}, effects[0].Affected, "the area is aimed at the target")
+	assert.Less(t, target.HP, 30)
+	assert.Equal(t, target.HP, neighbour.HP)
+	assert.Equal(t, 30, distant.HP)
+}
+
+func TestPreviewSpellTargets(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session, spell := createSpellcasterSession(t, server)
+	spell.Area = &game.AreaTemplate{Shape: game.AreaCone, Size: 4}
+	orc := addAreaTestNPC(t, server, "orc", 13, 10)
+	addAreaTestNPC(t, server, "orc_behind", 7, 10)
+
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id": session.SessionID,
+		"spell_id":   spell.ID,
+		"position":   game.Position{X: 14, Y: 10},
+	})
+	require.NoError(t, err)
+	result, err := server.handleMethod(MethodPreviewSpellTargets, params)
+	require.NoError(t, err)
+
+	resultMap := result.(map[string]interface{})
+	area := resultMap["area"].(*game.AreaResolution)
+	require.Len(t, area.Targets, 1, "the cone opens east, away from the orc behind the caster")
+	assert.Equal(t, "orc", area.Targets[0].ID)
+	assert.NotEmpty(t, area.Tiles)
+	assert.Equal(t, game.FriendlyFireSpareCaster, area.FriendlyFire)
+	assert.Equal(t, 30, orc.HP, "previews change nothing")
+
+	spell.Area = nil
+	_, err = server.handleMethod(MethodPreviewSpellTargets, params)
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+}
+
+func TestSpellAllies_Companions(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	caster := &game.Player{Character: game.Character{ID: "player1"}}
+	npc := &game.NPC{Character: game.Character{ID: "hireling"}}
+	companion, err := game.NewCompanion(npc, 10, 50, 50, "steady", nil)
+	require.NoError(t, err)
+	companion.Hire(caster.ID)
+	server.companions.Add(companion)
+
+	allies := server.spellAllies(caster)
+	assert.True(t, allies(npc), "hired companions are the caster's allies")
+	assert.False(t, allies(&game.NPC{Character: game.Character{ID: "stranger"}}))
+	assert.True(t, allies(&game.Player{Character: game.Character{ID: "player2"}}))
+}
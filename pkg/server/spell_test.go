@@ -356,3 +356,54 @@ func TestProcessSpellCast_SpellSchoolCodePaths(t *testing.T) {
 		})
 	}
 }
+
+// TestProcessSpellCast_ScriptedSpell tests that effect scripts are resolved
+// through the spell manager's registry and route damage through applyDamage
+func TestProcessSpellCast_ScriptedSpell(t *testing.T) {
+	server := &RPCServer{
+		spellManager: game.NewSpellManager(""),
+		eventSys:     game.NewEventSystem(),
+		state:        &GameState{WorldState: game.NewWorldWithSize(20, 20, 5)},
+	}
+
+	caster := &game.Player{
+		Character: game.Character{ID: "player1", Position: game.Position{X: 5, Y: 5}},
+		Level:     5,
+	}
+	goblin := &game.NPC{Character: game.Character{ID: "goblin", Position: game.Position{X: 6, Y: 6}, HP: 3, MaxHP: 3}}
+	if err := server.state.WorldState.AddObject(goblin); err != nil {
+		t.Fatalf("AddObject failed: %v", err)
+	}
+
+	spell := &game.Spell{
+		ID:         "frost_burst",
+		Name:       "Frost Burst",
+		Level:      1,
+		DamageDice: "1d4+10",
+		EffectScripts: []game.SpellEffectScript{
+			{Handler: game.SpellEffectAreaDamage, Params: map[string]interface{}{"radius": 2}},
+		},
+	}
+
+	result, err := server.processSpellCast(caster, spell, "", game.Position{X: 6, Y: 6})
+	if err != nil {
+		t.Fatalf("processSpellCast failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["effect_type"] != "scripted" {
+		t.Errorf("Expected scripted effect type, got %v", resultMap["effect_type"])
+	}
+	effects := resultMap["effects"].([]*game.SpellEffectResult)
+	if len(effects) != 1 || len(effects[0].Affected) != 1 {
+		t.Fatalf("Expected the goblin to be hit, got %+v", effects)
+	}
+	if goblin.HP != 0 {
+		t.Errorf("Expected the goblin to die, HP is %d", goblin.HP)
+	}
+
+	spell.EffectScripts = []game.SpellEffectScript{{Handler: "undefined"}}
+	if _, err := server.processSpellCast(caster, spell, "", game.Position{}); err == nil {
+		t.Error("Expected unknown effect handlers to fail")
+	}
+}
@@ -2,12 +2,32 @@ package server
 
 import (
 	"fmt"
+	"strings"
 
 	"goldbox-rpg/pkg/game"
 
 	"github.com/sirupsen/logrus"
 )
 
+// componentBlockingEffects lists the effects that keep a caster from
+// providing each component: a silenced caster cannot speak the words of a
+// spell and a bound one cannot make its gestures
+var componentBlockingEffects = map[game.SpellComponent][]game.EffectType{
+	game.ComponentVerbal:  {game.EffectStun, game.EffectSilence},
+	game.ComponentSomatic: {game.EffectStun, game.EffectBound},
+}
+
+// componentBlocker returns the effect that keeps the caster from providing
+// a verbal or somatic component, if any
+func componentBlocker(caster *game.Player, component game.SpellComponent) (game.EffectType, bool) {
+	for _, effect := range componentBlockingEffects[component] {
+		if caster.HasEffect(effect) {
+			return effect, true
+		}
+	}
+	return "", false
+}
+
 func (s *RPCServer) hasSpellComponent(caster *game.Player, component game.SpellComponent) bool {
 	logrus.WithFields(logrus.Fields{
 		"function":  "hasSpellComponent",
@@ -16,24 +36,13 @@ func (s *RPCServer) hasSpellComponent(caster *game.Player, component game.SpellC
 	}).Debug("checking spell component")
 
 	switch component {
-	case game.ComponentVerbal:
-		// Check if character can speak (not silenced or stunned)
-		if caster.HasEffect(game.EffectStun) {
-			logrus.WithFields(logrus.Fields{
-				"function": "hasSpellComponent",
-				"reason":   "character is stunned",
-			}).Debug("verbal component unavailable")
-			return false
-		}
-		return true
-
-	case game.ComponentSomatic:
-		// Check if character can use hands (not paralyzed or hands bound)
-		if caster.HasEffect(game.EffectStun) {
+	case game.ComponentVerbal, game.ComponentSomatic:
+		if effect, blocked := componentBlocker(caster, component); blocked {
 			logrus.WithFields(logrus.Fields{
-				"function": "hasSpellComponent",
-				"reason":   "character is stunned",
-			}).Debug("somatic component unavailable")
+				"function":  "hasSpellComponent",
+				"component": component,
+				"effect":    effect,
+			}).Debug("spell component unavailable")
 			return false
 		}
 		return true
@@ -63,6 +72,92 @@ func (s *RPCServer) hasSpellComponent(caster *game.Player, component game.SpellC
 	}
 }
 
+// strictSpellComponents reports whether spells need the specific materials
+// they list, as set by config.StrictSpellComponents
+func (s *RPCServer) strictSpellComponents() bool {
+	return s.config != nil && s.config.StrictSpellComponents
+}
+
+// validateSpellComponents checks that the caster can provide every component
+// of the spell. Verbal and somatic components are blocked by effects such as
+// silence and bound. A material component is met by any SpellComponent item,
+// or, in strict component mode, by the specific materials the spell lists.
+//
+// Returns:
+//   - error: ErrSpellComponentBlocked naming the component and the effect,
+//     ErrSpellMaterialsMissing, or ErrSpellRequirements for an unknown
+//     component
+func (s *RPCServer) validateSpellComponents(caster *game.Player, spell *game.Spell) error {
+	for _, component := range spell.Components {
+		name := strings.ToLower(component.String())
+		switch component {
+		case game.ComponentVerbal, game.ComponentSomatic:
+			if effect, blocked := componentBlocker(caster, component); blocked {
+				logrus.WithFields(logrus.Fields{
+					"function":  "validateSpellComponents",
+					"component": name,
+					"effect":    effect,
+				}).Warn("spell component blocked")
+				return ErrSpellComponentBlocked.WithMessage("cannot provide the %s component of %s while affected by %s", name, spell.Name, effect).
+					WithData(map[string]interface{}{"component": name, "effect": string(effect)})
+			}
+
+		case game.ComponentMaterial:
+			if s.strictSpellComponents() && len(spell.Materials) > 0 {
+				if missing := caster.MissingMaterials(spell.Materials); len(missing) > 0 {
+					logrus.WithFields(logrus.Fields{
+						"function": "validateSpellComponents",
+						"spell_id": spell.ID,
+						"missing":  len(missing),
+					}).Warn("spell materials missing")
+					return ErrSpellMaterialsMissing.WithMessage("missing material components for %s: %s", spell.Name, missing[0].Item).
+						WithData(map[string]interface{}{"component": name, "missing": missing})
+				}
+			} else if !s.hasSpellComponent(caster, component) {
+				return ErrSpellMaterialsMissing.WithMessage("missing required spell component: %s", name).
+					WithData(map[string]interface{}{"component": name})
+			}
+
+		default:
+			logrus.WithFields(logrus.Fields{
+				"function":  "validateSpellComponents",
+				"component": component,
+			}).Warn("unknown spell component type")
+			return ErrSpellRequirements.WithMessage("unknown spell component: %d", int(component)).WithData(map[string]interface{}{"component": int(component)})
+		}
+	}
+	return nil
+}
+
+// consumeSpellMaterials uses up the consumed materials of a cast spell in
+// strict component mode. Materials that cannot be consumed, which
+// validateSpellComponents ruled out before the cast, are logged.
+func (s *RPCServer) consumeSpellMaterials(caster *game.Player, spell *game.Spell) {
+	if !s.strictSpellComponents() || len(spell.Materials) == 0 {
+		return
+	}
+	removed, err := caster.ConsumeMaterials(spell.Materials)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "consumeSpellMaterials",
+			"caster_id": caster.ID,
+			"spell_id":  spell.ID,
+		}).WithError(err).Error("failed to consume spell materials")
+		return
+	}
+	if len(removed) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"function":  "consumeSpellMaterials",
+			"caster_id": caster.ID,
+			"spell_id":  spell.ID,
+			"consumed":  len(removed),
+		}).Debug("consumed spell materials")
+	}
+}
+
+// validateSpellCast checks that the caster is of high enough level for the
+// spell. Components are checked by handleCastSpell before reactions are
+// offered, so a spell that cannot be cast is never countered.
 func (s *RPCServer) validateSpellCast(caster *game.Player, spell *game.Spell) error {
 	logrus.WithFields(logrus.Fields{
 		"function":  "validateSpellCast",
@@ -71,24 +166,13 @@ func (s *RPCServer) validateSpellCast(caster *game.Player, spell *game.Spell) er
 	}).Debug("validating spell cast")
 
 	// Check level requirements
-	if caster.Level < spell.Level {
+	if casterLevel := caster.CasterLevel(); casterLevel < spell.Level {
 		logrus.WithFields(logrus.Fields{
 			"function":       "validateSpellCast",
-			"caster_level":   caster.Level,
+			"caster_level":   casterLevel,
 			"required_level": spell.Level,
 		}).Warn("insufficient level to cast spell")
-		return fmt.Errorf("insufficient level to cast spell")
-	}
-
-	// Check components
-	for _, component := range spell.Components {
-		if !s.hasSpellComponent(caster, component) {
-			logrus.WithFields(logrus.Fields{
-				"function":  "validateSpellCast",
-				"component": component,
-			}).Warn("missing required spell component")
-			return fmt.Errorf("missing required spell component: %v", component)
-		}
+		return ErrSpellRequirements.WithMessage("insufficient level to cast spell").WithData(map[string]interface{}{"required_level": spell.Level})
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -122,6 +206,8 @@ func (s *RPCServer) processEvocationSpell(spell *game.Spell, caster *game.Player
 		}
 	}
 
+	s.logEvocationSpell(spell, caster, targetID, damage, healing, damageRoll, healingRoll)
+
 	result := s.buildEvocationResult(
 		spell,
 		spellPower,
@@ -148,7 +234,7 @@ func (s *RPCServer) logEvocationSpellStart(spell *game.Spell, caster *game.Playe
 
 // processEvocationDamage rolls damage dice, applies damage, and returns results.
 func (s *RPCServer) processEvocationDamage(spell *game.Spell, targetID string) (int, *game.DiceRoll, []string, error) {
-	roll, err := game.GlobalDiceRoller.Roll(spell.DamageDice)
+	roll, err := s.rollCombatDice(drawDamage, targetID, spell.DamageDice)
 	if err != nil {
 		logrus.WithError(err).Error("failed to roll damage dice")
 		return 0, nil, nil, fmt.Errorf("failed to roll damage dice: %w", err)
@@ -168,7 +254,7 @@ func (s *RPCServer) processEvocationDamage(spell *game.Spell, targetID string) (
 
 // processEvocationHealing rolls healing dice, applies healing, and returns results.
 func (s *RPCServer) processEvocationHealing(spell *game.Spell, targetID string) (int, *game.DiceRoll, []string, error) {
-	roll, err := game.GlobalDiceRoller.Roll(spell.HealingDice)
+	roll, err := s.rollCombatDice(drawHealing, targetID, spell.HealingDice)
 	if err != nil {
 		logrus.WithError(err).Error("failed to roll healing dice")
 		return 0, nil, nil, fmt.Errorf("failed to roll healing dice: %w", err)
@@ -223,6 +309,26 @@ func (s *RPCServer) buildEvocationResult(
 	return result
 }
 
+// logEvocationSpell records the damage and healing of an evocation spell in
+// the combat log.
+func (s *RPCServer) logEvocationSpell(spell *game.Spell, caster *game.Player, targetID string, damage, healing int, damageRoll, healingRoll *game.DiceRoll) {
+	entry := CombatLogEntry{
+		Action:     CombatLogSpell,
+		AttackerID: caster.GetID(),
+		DefenderID: targetID,
+		Source:     spell.Name,
+		Damage:     damage,
+		Healing:    healing,
+	}
+	switch {
+	case damageRoll != nil && spell.DamageDice != "":
+		entry.Roll = &CombatLogRoll{Expression: spell.DamageDice, Result: damageRoll.Final}
+	case healingRoll != nil && spell.HealingDice != "":
+		entry.Roll = &CombatLogRoll{Expression: spell.HealingDice, Result: healingRoll.Final}
+	}
+	s.logCombat(entry)
+}
+
 // logEvocationSpellSuccess logs the successful processing of an evocation spell.
 func (s *RPCServer) logEvocationSpellSuccess(spell *game.Spell, damage, healing, spellPower int) {
 	logrus.WithFields(logrus.Fields{
@@ -300,6 +406,57 @@ func (s *RPCServer) processGenericSpell(spell *game.Spell, caster *game.Player,
 	return result, nil
 }
 
+// processScriptedSpell resolves the effect scripts declared by spell through
+// the spell manager's effect registry. Damage dealt by scripts goes through
+// applyDamage so deaths are handled like any other damage.
+func (s *RPCServer) processScriptedSpell(spell *game.Spell, caster *game.Player, targetID string, pos game.Position) (interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"function": "processScriptedSpell",
+		"spell_id": spell.ID,
+		"caster":   caster.ID,
+		"scripts":  len(spell.EffectScripts),
+	}).Debug("processing scripted spell")
+
+	registry := s.spellManager.EffectRegistry()
+	if registry == nil {
+		return nil, ErrUnavailable.WithMessage("spell effect scripts are not supported")
+	}
+
+	effects, err := registry.Resolve(&game.SpellCast{
+		Spell:    spell,
+		Caster:   caster,
+		TargetID: targetID,
+		Position: pos,
+		World:    s.state.WorldState,
+		Allies:   s.spellAllies(caster),
+		ApplyDamage: func(target game.GameObject, damage int, damageType string) error {
+			if err := s.applyDamage(target, damage); err != nil {
+				return err
+			}
+			s.logCombat(CombatLogEntry{
+				Action:     CombatLogSpell,
+				AttackerID: caster.GetID(),
+				DefenderID: target.GetID(),
+				Source:     spell.Name,
+				Damage:     damage,
+				Effects:    []string{damageType},
+			})
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success":     true,
+		"spell_id":    spell.ID,
+		"spell_name":  spell.Name,
+		"effect_type": "scripted",
+		"effects":     effects,
+	}, nil
+}
+
 // calculateSpellPower computes the effective power of a spell based on caster attributes
 func calculateSpellPower(caster *game.Player, spell *game.Spell) int {
 	// Base power from spell level
@@ -422,7 +579,8 @@ func (s *RPCServer) applySpellDamage(targetID string, damage int, damageType str
 				"target_type": "player",
 			}).Debug("target found as player")
 
-			// Apply damage to player
+			// Apply damage to player, after its resistance to the damage type
+			damage = session.Player.ResistDamage(game.DamageType(damageType), damage)
 			currentHP := session.Player.GetHP()
 			newHP := currentHP - damage
 			if newHP < 0 {
@@ -2,15 +2,28 @@ package server
 
 import (
 	"fmt"
-	"sync"
 	"sync/atomic"
 	"time"
 
 	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/lockstat"
 
 	"github.com/sirupsen/logrus"
 )
 
+// Names of the GameState locks in lock contention statistics
+type (
+	stateLock        struct{}
+	stateWorldLock   struct{}
+	stateSessionLock struct{}
+	stateTurnLock    struct{}
+)
+
+func (stateLock) LockName() string        { return "state" }
+func (stateWorldLock) LockName() string   { return "state.world" }
+func (stateSessionLock) LockName() string { return "state.sessions" }
+func (stateTurnLock) LockName() string    { return "state.turns" }
+
 // GameState represents the core game state container managing all dynamic game elements.
 // It provides thread-safe access to the world state, turn sequencing, time tracking,
 // and player session management.
@@ -40,10 +53,10 @@ type GameState struct {
 	Version     int                       `yaml:"state_version"`
 
 	// Locking implementation
-	stateMu   sync.RWMutex `yaml:"-"` // Primary state mutex
-	worldMu   sync.RWMutex `yaml:"-"` // World state mutex
-	sessionMu sync.RWMutex `yaml:"-"` // Session mutex
-	turnMu    sync.RWMutex `yaml:"-"` // Turn manager mutex
+	stateMu   lockstat.RWMutex[stateLock]        `yaml:"-"` // Primary state mutex
+	worldMu   lockstat.RWMutex[stateWorldLock]   `yaml:"-"` // World state mutex
+	sessionMu lockstat.RWMutex[stateSessionLock] `yaml:"-"` // Session mutex
+	turnMu    lockstat.RWMutex[stateTurnLock]    `yaml:"-"` // Turn manager mutex
 
 	// State caching
 	cachedState  atomic.Value `yaml:"-"` // Cached state data
@@ -93,10 +106,12 @@ func (gs *GameState) GetState() map[string]interface{} {
 
 	state := make(map[string]interface{})
 
-	// Get world state with separate lock
+	// Hold each lock only to read the component it guards; the world
+	// copies its objects under its own lock
 	gs.worldMu.RLock()
-	state["world"] = gs.WorldState.Serialize()
+	world := gs.WorldState
 	gs.worldMu.RUnlock()
+	state["world"] = world.Serialize()
 
 	// Get time state
 	state["time"] = gs.TimeManager.Serialize()
@@ -106,13 +121,21 @@ func (gs *GameState) GetState() map[string]interface{} {
 	state["turns"] = gs.TurnManager.Serialize()
 	gs.turnMu.RUnlock()
 
-	// Get session data with separate lock
+	// Collect the sessions under the lock, then build their public data
+	// without it
 	gs.sessionMu.RLock()
-	sessions := make(map[string]interface{})
+	ids := make([]string, 0, len(gs.Sessions))
+	list := make([]*PlayerSession, 0, len(gs.Sessions))
 	for id, session := range gs.Sessions {
-		sessions[id] = session.PublicData()
+		ids = append(ids, id)
+		list = append(list, session)
 	}
 	gs.sessionMu.RUnlock()
+
+	sessions := make(map[string]interface{}, len(list))
+	for i, session := range list {
+		sessions[ids[i]] = session.PublicData()
+	}
 	state["sessions"] = sessions
 
 	state["version"] = version
@@ -309,6 +332,8 @@ func (gs *GameState) createSnapshot() any {
 			TimeScale:       gs.TimeManager.TimeScale,
 			LastTick:        gs.TimeManager.LastTick,
 			ScheduledEvents: make([]ScheduledEvent, len(gs.TimeManager.ScheduledEvents)),
+			Weather:         gs.TimeManager.Weather,
+			Lights:          append([]LitLight(nil), gs.TimeManager.Lights...),
 		},
 		TurnManager: gs.TurnManager.Clone(), // Assuming TurnManager has a Clone method
 		Sessions:    make(map[string]*PlayerSession),
@@ -334,6 +359,8 @@ func (gs *GameState) createSnapshot() any {
 //   - TimeScale: Multiplier that controls how fast game time progresses relative to real time (e.g. 2.0 = twice as fast)
 //   - LastTick: Real-world timestamp of the most recent time update
 //   - ScheduledEvents: Slice of pending events to be triggered at specific game times
+//   - Weather: Regional weather simulation driven by game time (not persisted; it
+//     is regenerated from the PCG seed)
 //
 // Related types:
 //   - game.GameTime - Represents a point in game time
@@ -343,6 +370,8 @@ type TimeManager struct {
 	TimeScale       float64          `yaml:"time_scale"`            // Time progression rate
 	LastTick        time.Time        `yaml:"time_last_tick"`        // Last update time
 	ScheduledEvents []ScheduledEvent `yaml:"time_scheduled_events"` // Pending events
+	Weather         *WeatherSystem   `yaml:"-"`                     // Regional weather
+	Lights          []LitLight       `yaml:"time_lights,omitempty"` // Light sources burning
 }
 
 // Serialize returns a map representation of the TimeManager state
@@ -368,6 +397,15 @@ func (t *TimeManager) Serialize() map[string]interface{} {
 			}
 			return events
 		}(),
+		"time_of_day": t.TimeOfDay(),
+		"date":        t.Date(),
+		"lights":      t.Lights,
+		"weather": func() map[string]WeatherFront {
+			if t.Weather == nil {
+				return nil
+			}
+			return t.Weather.Fronts()
+		}(),
 	}
 }
 
@@ -380,15 +418,17 @@ func (t *TimeManager) Serialize() map[string]interface{} {
 //   - TriggerTime: The game.GameTime when this event should execute
 //   - Parameters: Additional string data needed for the event execution
 //   - Repeating: If true, the event will reschedule itself after triggering
+//   - Interval: Game ticks between the triggers of a repeating event
 //
 // Related types:
 //   - game.GameTime: Represents the in-game time when event triggers
 type ScheduledEvent struct {
-	EventID     string        `yaml:"event_id"`           // Event identifier
-	EventType   string        `yaml:"event_type"`         // Type of event
-	TriggerTime game.GameTime `yaml:"event_trigger_time"` // When to trigger
-	Parameters  []string      `yaml:"event_parameters"`   // Event data
-	Repeating   bool          `yaml:"event_is_repeating"` // Whether it repeats
+	EventID     string        `yaml:"event_id"`                 // Event identifier
+	EventType   string        `yaml:"event_type"`               // Type of event
+	TriggerTime game.GameTime `yaml:"event_trigger_time"`       // When to trigger
+	Parameters  []string      `yaml:"event_parameters"`         // Event data
+	Repeating   bool          `yaml:"event_is_repeating"`       // Whether it repeats
+	Interval    int64         `yaml:"event_interval,omitempty"` // Ticks between repeats
 }
 
 // ScriptContext represents the execution state and variables of a running script in the game.
@@ -473,7 +513,7 @@ func (gs *GameState) SaveToFile(store interface {
 	}).Info("saving game state to file")
 
 	// Save main game state
-	if err := store.Save("gamestate.yaml", gs); err != nil {
+	if err := store.Save(gameStateKey, gs); err != nil {
 		return fmt.Errorf("failed to save game state: %w", err)
 	}
 
@@ -485,6 +525,41 @@ func (gs *GameState) SaveToFile(store interface {
 	return nil
 }
 
+// SaveSnapshot persists the game state together with additional documents
+// (such as PCG state) in one batch, which the store applies atomically.
+//
+// Parameters:
+//   - store: The persistence backend to write to
+//   - extra: Additional documents keyed by store key, saved alongside the state
+//
+// Returns:
+//   - error: Any error that occurred during the save operation
+func (gs *GameState) SaveSnapshot(store interface {
+	SaveBatch(map[string]interface{}) error
+}, extra map[string]interface{},
+) error {
+	gs.stateMu.RLock()
+	defer gs.stateMu.RUnlock()
+
+	entries := make(map[string]interface{}, len(extra)+1)
+	for key, data := range extra {
+		entries[key] = data
+	}
+	entries[gameStateKey] = gs
+
+	if err := store.SaveBatch(entries); err != nil {
+		return fmt.Errorf("failed to save game state snapshot: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "SaveSnapshot",
+		"version":   gs.Version,
+		"documents": len(entries),
+	}).Info("game state snapshot saved successfully")
+
+	return nil
+}
+
 // LoadFromFile loads the game state from a file using YAML deserialization.
 // This method initializes the game state from persisted data.
 //
@@ -506,13 +581,13 @@ func (gs *GameState) LoadFromFile(store interface {
 	}).Info("loading game state from file")
 
 	// Check if file exists
-	if !store.Exists("gamestate.yaml") {
+	if !store.Exists(gameStateKey) {
 		logrus.WithField("function", "LoadFromFile").Info("no saved game state found, starting fresh")
 		return nil
 	}
 
 	// Load game state
-	if err := store.Load("gamestate.yaml", gs); err != nil {
+	if err := store.Load(gameStateKey, gs); err != nil {
 		return fmt.Errorf("failed to load game state: %w", err)
 	}
 
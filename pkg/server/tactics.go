@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// handleGetVisibleEnemies returns the hostile NPCs the requesting player can
+// see, with threat data computed server-side so clients and bots do not
+// need to re-implement line of sight or danger estimates.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the requesting player
+//   - range: float64 - Sight range in tiles (optional, defaults to game.DefaultSightRange)
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating if the query succeeded
+//   - enemies: []game.ThreatAssessment ordered most dangerous first
+//   - count: Number of visible enemies
+//   - error: Invalid parameters or session
+func (s *RPCServer) handleGetVisibleEnemies(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleGetVisibleEnemies",
+	})
+	logger.Debug("entering handleGetVisibleEnemies")
+
+	var req struct {
+		SessionID string  `json:"session_id"`
+		Range     float64 `json:"range"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid visible enemies parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	enemies := s.state.WorldState.VisibleHostiles(&session.Player.Character, req.Range, game.DefaultThreatTable())
+
+	logger.WithFields(logrus.Fields{
+		"player_id": session.Player.ID,
+		"enemies":   len(enemies),
+	}).Debug("visible enemies assessed")
+
+	return map[string]interface{}{
+		"success": true,
+		"enemies": enemies,
+		"count":   len(enemies),
+	}, nil
+}
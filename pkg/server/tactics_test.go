@@ -0,0 +1,48 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetVisibleEnemies(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	wolf := &game.NPC{
+		Character: game.Character{ID: "wolf", Name: "Wolf", Position: game.Position{X: 12, Y: 10}, Level: 5, HP: 20, MaxHP: 20},
+		Behavior:  "aggressive",
+	}
+	merchant := &game.NPC{
+		Character: game.Character{ID: "merchant", Name: "Merchant", Position: game.Position{X: 11, Y: 10}, Level: 1, HP: 5, MaxHP: 5},
+		Behavior:  "merchant",
+	}
+	require.NoError(t, server.state.WorldState.AddObject(wolf))
+	require.NoError(t, server.state.WorldState.AddObject(merchant))
+
+	result, err := server.handleGetVisibleEnemies(json.RawMessage(`{"session_id":"` + session.SessionID + `"}`))
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	assert.Equal(t, true, response["success"])
+	assert.Equal(t, 1, response["count"])
+	enemies := response["enemies"].([]game.ThreatAssessment)
+	require.Len(t, enemies, 1)
+	assert.Equal(t, "wolf", enemies[0].ID)
+	assert.Equal(t, 2.0, enemies[0].Distance)
+	assert.Equal(t, 1.5, enemies[0].Danger)
+	assert.Equal(t, game.ThreatHigh, enemies[0].ThreatLevel)
+
+	// A short sight range excludes the wolf
+	result, err = server.handleGetVisibleEnemies(json.RawMessage(`{"session_id":"` + session.SessionID + `","range":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["count"])
+
+	_, err = server.handleGetVisibleEnemies(json.RawMessage(`{"session_id":"missing"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,325 @@
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TensionLevel is the dramatic intensity of play, shared by every client.
+// Audio-capable clients map each level to a set of music layers.
+type TensionLevel string
+
+const (
+	TensionCalm   TensionLevel = "calm"   // Exploration, no threats in sight
+	TensionUneasy TensionLevel = "uneasy" // Threats nearby or recent trouble
+	TensionTense  TensionLevel = "tense"  // Fighting, or a dangerous situation
+	TensionClimax TensionLevel = "climax" // Boss fights and desperate moments
+)
+
+// Score thresholds at which tension rises to each level. Tension only falls
+// back below a threshold once the score drops tensionHysteresis beneath it,
+// so small fluctuations do not flip the music back and forth.
+const (
+	tensionUneasyThreshold = 0.2
+	tensionTenseThreshold  = 0.45
+	tensionClimaxThreshold = 0.7
+	tensionHysteresis      = 0.05
+)
+
+// Weights of each factor in the tension score. They sum to 1.
+const (
+	tensionThreatWeight = 0.35
+	tensionHealthWeight = 0.25
+	tensionBossWeight   = 0.25
+	tensionRecentWeight = 0.15
+)
+
+// tensionThreatSaturation is the summed proximity-weighted danger at which
+// the threat factor is maxed out, roughly three even-level enemies adjacent
+// to a player.
+const tensionThreatSaturation = 3.0
+
+// TensionHeatHalfLife is how long it takes the contribution of recent events
+// (damage, deaths, combat starting) to the tension score to halve.
+const TensionHeatHalfLife = 30 * time.Second
+
+// Heat added to the recent events factor by each kind of event.
+const (
+	tensionHeatDamage      = 0.1
+	tensionHeatDeath       = 0.35
+	tensionHeatCombatStart = 0.25
+)
+
+// TensionInputs is a snapshot of the game state that drives tension.
+type TensionInputs struct {
+	Threat      float64 // Summed danger of hostiles in sight, weighted by proximity
+	PartyHealth float64 // Average HP fraction of players, 1 when there are none
+	BossID      string  // Boss in sight of a player, if any
+	BossPhase   int     // 0 without a boss, then 1-3 as the boss loses HP
+}
+
+// TensionReading is the tension score and the factors it was computed from.
+// All factors are in [0, 1].
+type TensionReading struct {
+	Score       float64      `json:"score"`
+	Level       TensionLevel `json:"level"`
+	Threat      float64      `json:"threat"`
+	PartyDanger float64      `json:"party_danger"`
+	Boss        float64      `json:"boss"`
+	Recent      float64      `json:"recent"`
+	BossID      string       `json:"boss_id,omitempty"`
+	BossPhase   int          `json:"boss_phase"`
+}
+
+// TensionDirector scores game state into a tension level. Recent events add
+// heat that decays over TensionHeatHalfLife; Evaluate combines it with a
+// snapshot of threats, party health and boss phase.
+type TensionDirector struct {
+	mu      sync.Mutex
+	heat    float64
+	heatAt  time.Time
+	current TensionReading
+}
+
+// NewTensionDirector creates a director at TensionCalm.
+func NewTensionDirector() *TensionDirector {
+	return &TensionDirector{current: TensionReading{Level: TensionCalm}}
+}
+
+// RecordEvent adds heat for an event that happened at now.
+func (d *TensionDirector) RecordEvent(heat float64, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.heat = math.Min(1, d.decayedHeatLocked(now)+heat)
+	d.heatAt = now
+}
+
+// decayedHeatLocked returns the event heat remaining at now. The caller must
+// hold d.mu.
+func (d *TensionDirector) decayedHeatLocked(now time.Time) float64 {
+	if d.heat == 0 || !now.After(d.heatAt) {
+		return d.heat
+	}
+	halfLives := float64(now.Sub(d.heatAt)) / float64(TensionHeatHalfLife)
+	return d.heat * math.Pow(0.5, halfLives)
+}
+
+// Evaluate scores inputs at now and stores the result as the current
+// reading. It also returns the level of the reading it replaced.
+func (d *TensionDirector) Evaluate(inputs TensionInputs, now time.Time) (reading TensionReading, previous TensionLevel) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	reading = TensionReading{
+		Threat:      clampUnit(inputs.Threat / tensionThreatSaturation),
+		PartyDanger: clampUnit(1 - inputs.PartyHealth),
+		Recent:      d.decayedHeatLocked(now),
+		BossID:      inputs.BossID,
+		BossPhase:   inputs.BossPhase,
+	}
+	if inputs.BossPhase > 0 {
+		reading.Boss = clampUnit(float64(inputs.BossPhase) / 3)
+	}
+	reading.Score = tensionThreatWeight*reading.Threat +
+		tensionHealthWeight*reading.PartyDanger +
+		tensionBossWeight*reading.Boss +
+		tensionRecentWeight*reading.Recent
+	reading.Score = math.Max(reading.Score, bossTensionFloor(inputs.BossPhase))
+	reading.Level = tensionLevelFor(reading.Score, d.current.Level)
+
+	previous = d.current.Level
+	d.current = reading
+	return reading, previous
+}
+
+// Current returns the most recent reading.
+func (d *TensionDirector) Current() TensionReading {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.current
+}
+
+// tensionLevelFor maps score to a level, applying hysteresis relative to the
+// previous level.
+func tensionLevelFor(score float64, previous TensionLevel) TensionLevel {
+	levels := []struct {
+		level     TensionLevel
+		threshold float64
+	}{
+		{TensionClimax, tensionClimaxThreshold},
+		{TensionTense, tensionTenseThreshold},
+		{TensionUneasy, tensionUneasyThreshold},
+	}
+
+	held := false
+	for _, l := range levels {
+		if l.level == previous {
+			held = true
+		}
+		threshold := l.threshold
+		if held {
+			// At or below the previous level: stay until clearly below
+			threshold -= tensionHysteresis
+		}
+		if score >= threshold {
+			return l.level
+		}
+	}
+	return TensionCalm
+}
+
+// bossTensionFloor is the lowest score while a boss in the given phase is in
+// sight: a boss fight is always at least tense, and its final phase is
+// always the climax.
+func bossTensionFloor(phase int) float64 {
+	switch {
+	case phase >= 3:
+		return tensionClimaxThreshold
+	case phase > 0:
+		return tensionTenseThreshold
+	default:
+		return 0
+	}
+}
+
+// bossPhase returns the phase of a boss with hp of maxHP remaining: 1 above
+// two thirds, 2 above one third, and 3 below.
+func bossPhase(hp, maxHP int) int {
+	if maxHP <= 0 {
+		return 1
+	}
+	fraction := float64(hp) / float64(maxHP)
+	switch {
+	case fraction > 2.0/3:
+		return 1
+	case fraction > 1.0/3:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// clampUnit limits v to [0, 1].
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// attachTension creates the tension director and feeds it combat events.
+func (s *RPCServer) attachTension() {
+	s.tension = NewTensionDirector()
+
+	heat := map[game.EventType]float64{
+		game.EventDamage: tensionHeatDamage,
+		game.EventDeath:  tensionHeatDeath,
+		EventCombatStart: tensionHeatCombatStart,
+	}
+	for eventType, amount := range heat {
+		s.eventSys.Subscribe(eventType, func(game.GameEvent) {
+			s.tension.RecordEvent(amount, time.Now())
+		})
+	}
+}
+
+// tensionInputs snapshots the threats, health and boss phase seen by the
+// players currently in the game.
+func (s *RPCServer) tensionInputs() TensionInputs {
+	s.mu.RLock()
+	players := make([]*game.Player, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if session.Player != nil {
+			players = append(players, session.Player)
+		}
+	}
+	s.mu.RUnlock()
+
+	inputs := TensionInputs{PartyHealth: 1}
+	if len(players) == 0 {
+		return inputs
+	}
+
+	table := game.DefaultThreatTable()
+	health := 0.0
+	for _, player := range players {
+		if player.MaxHP > 0 {
+			health += clampUnit(float64(player.HP) / float64(player.MaxHP))
+		} else {
+			health++
+		}
+
+		// The most threatened player sets the pace for everyone
+		threat := 0.0
+		for _, enemy := range s.state.WorldState.VisibleHostiles(&player.Character, game.DefaultSightRange, table) {
+			threat += enemy.Danger * (1 - enemy.Distance/game.DefaultSightRange)
+			if obj, exists := s.state.WorldState.Objects[enemy.ID]; exists && hasTag(obj, bossTag) {
+				if phase := bossPhase(enemy.HP, enemy.MaxHP); phase > inputs.BossPhase {
+					inputs.BossID, inputs.BossPhase = enemy.ID, phase
+				}
+			}
+		}
+		inputs.Threat = math.Max(inputs.Threat, threat)
+	}
+	inputs.PartyHealth = health / float64(len(players))
+	return inputs
+}
+
+// hasTag reports whether obj carries tag.
+func hasTag(obj game.GameObject, tag string) bool {
+	for _, t := range obj.GetTags() {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// updateTension re-scores tension and broadcasts a tension change event if
+// the level changed.
+func (s *RPCServer) updateTension() TensionReading {
+	reading, previous := s.tension.Evaluate(s.tensionInputs(), time.Now())
+	if reading.Level == previous {
+		return reading
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "updateTension",
+		"previous": previous,
+		"level":    reading.Level,
+		"score":    reading.Score,
+	}).Info("tension changed")
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventTensionChange,
+		SourceID: "tension",
+		Data: map[string]interface{}{
+			"previous": previous,
+			"level":    reading.Level,
+			"reading":  reading,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+	return reading
+}
+
+// startTensionUpdates periodically re-scores tension until the server shuts
+// down.
+func (s *RPCServer) startTensionUpdates() {
+	ticker := time.NewTicker(tensionUpdateInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.updateTension()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
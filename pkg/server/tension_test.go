@@ -0,0 +1,118 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTensionLevelFor_Hysteresis(t *testing.T) {
+	tests := []struct {
+		score    float64
+		previous TensionLevel
+		expected TensionLevel
+	}{
+		{0.1, TensionCalm, TensionCalm},
+		{0.5, TensionCalm, TensionTense},
+		{0.9, TensionCalm, TensionClimax},
+		{0.68, TensionClimax, TensionClimax}, // Within the hysteresis band
+		{0.6, TensionClimax, TensionTense},
+		{0.68, TensionTense, TensionTense}, // Rising needs the full threshold
+		{0.17, TensionUneasy, TensionUneasy},
+		{0.1, TensionTense, TensionCalm},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tensionLevelFor(tt.score, tt.previous), "score %.2f from %s", tt.score, tt.previous)
+	}
+}
+
+func TestTensionDirector_EventHeatDecays(t *testing.T) {
+	director := NewTensionDirector()
+	start := time.Now()
+
+	director.RecordEvent(0.8, start)
+	reading, previous := director.Evaluate(TensionInputs{PartyHealth: 1}, start)
+	assert.Equal(t, TensionCalm, previous)
+	assert.InDelta(t, 0.8, reading.Recent, 1e-9)
+
+	reading, _ = director.Evaluate(TensionInputs{PartyHealth: 1}, start.Add(TensionHeatHalfLife))
+	assert.InDelta(t, 0.4, reading.Recent, 1e-9)
+
+	director.RecordEvent(0.8, start.Add(TensionHeatHalfLife))
+	assert.Equal(t, 1.0, director.decayedHeatLocked(start.Add(TensionHeatHalfLife)), "heat is capped")
+}
+
+func TestTensionDirector_Evaluate(t *testing.T) {
+	director := NewTensionDirector()
+	now := time.Now()
+
+	reading, _ := director.Evaluate(TensionInputs{PartyHealth: 1}, now)
+	assert.Equal(t, TensionCalm, reading.Level)
+	assert.Zero(t, reading.Score)
+
+	reading, previous := director.Evaluate(TensionInputs{
+		Threat:      tensionThreatSaturation,
+		PartyHealth: 0.2,
+		BossID:      "ogre",
+		BossPhase:   3,
+	}, now)
+	assert.Equal(t, TensionCalm, previous)
+	assert.Equal(t, TensionClimax, reading.Level)
+	assert.Equal(t, 1.0, reading.Threat)
+	assert.InDelta(t, 0.8, reading.PartyDanger, 1e-9)
+	assert.Equal(t, 1.0, reading.Boss)
+	assert.Equal(t, reading, director.Current())
+}
+
+func TestTensionDirector_BossFloor(t *testing.T) {
+	director := NewTensionDirector()
+	now := time.Now()
+
+	reading, _ := director.Evaluate(TensionInputs{PartyHealth: 1, BossID: "ogre", BossPhase: 1}, now)
+	assert.Equal(t, TensionTense, reading.Level, "a boss in sight is always tense")
+
+	reading, _ = director.Evaluate(TensionInputs{PartyHealth: 1, BossID: "ogre", BossPhase: 3}, now)
+	assert.Equal(t, TensionClimax, reading.Level, "the final boss phase is the climax")
+}
+
+func TestBossPhase(t *testing.T) {
+	assert.Equal(t, 1, bossPhase(90, 100))
+	assert.Equal(t, 2, bossPhase(50, 100))
+	assert.Equal(t, 3, bossPhase(10, 100))
+	assert.Equal(t, 1, bossPhase(0, 0))
+}
+
+func TestUpdateTension_BroadcastsLevelChange(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	session.Player.HP = 20
+
+	changes := make(chan game.GameEvent, 10)
+	server.eventSys.Subscribe(EventTensionChange, func(event game.GameEvent) {
+		changes <- event
+	})
+
+	ogre := &game.NPC{
+		Character: game.Character{ID: "ogre", Name: "Ogre Chief", Position: game.Position{X: 11, Y: 10}, Level: 8, HP: 10, MaxHP: 60},
+		Behavior:  "berserk",
+	}
+	ogre.AddTag(bossTag)
+	require.NoError(t, server.state.WorldState.AddObject(ogre))
+
+	reading := server.updateTension()
+	assert.Equal(t, TensionClimax, reading.Level)
+	assert.Equal(t, "ogre", reading.BossID)
+	assert.Equal(t, 3, reading.BossPhase)
+
+	select {
+	case event := <-changes:
+		assert.Equal(t, TensionClimax, event.Data["level"])
+	case <-time.After(time.Second):
+		t.Fatal("expected a tension change event")
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/logging"
+	"goldbox-rpg/pkg/tracing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tracingShutdownTimeout bounds how long Close waits for buffered spans to
+// be exported
+const tracingShutdownTimeout = 5 * time.Second
+
+// initializeTracing installs the OpenTelemetry exporter selected by the
+// TRACING_* settings. With the none exporter spans cost next to nothing.
+func initializeTracing(cfg *config.Config, logger *logrus.Entry) (tracing.ShutdownFunc, error) {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed to initialize tracing")
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	return shutdown, nil
+}
+
+// shutdownTracing flushes spans still buffered by the exporter
+func (s *RPCServer) shutdownTracing() {
+	if s.tracingShutdown == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := s.tracingShutdown(ctx); err != nil {
+		logrus.WithError(err).Warn("failed to flush traces")
+	}
+}
+
+// tracedMethod runs handleMethod inside an "rpc.<method>" span that is a
+// child of any span in ctx. logger gains the span's trace and span IDs.
+func (s *RPCServer) tracedMethod(ctx context.Context, method RPCMethod, params json.RawMessage, transport string, logger *logrus.Entry) (interface{}, *logrus.Entry, error) {
+	ctx, span := tracing.Start(ctx, "rpc."+string(method),
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", string(method)),
+		attribute.String("rpc.transport", transport),
+	)
+	ctx, correlationID := withCorrelationID(ctx)
+	logger = logger.WithFields(logging.FieldsFromContext(ctx))
+
+	started := time.Now()
+	result, err := s.handleMethod(method, params)
+	tracing.End(span, err)
+	s.auditRequest(correlationID, transport, method, params, result, err, started)
+	return result, logger, err
+}
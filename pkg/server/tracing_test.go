@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"goldbox-rpg/pkg/logging"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedMethod_RecordsSpan(t *testing.T) {
+	server := newReplayTestServer(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	_, logger, err := server.tracedMethod(context.Background(), "noSuchMethod", nil, "http", logrus.NewEntry(logrus.New()))
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "rpc.noSuchMethod", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("rpc.transport", "http"))
+	assert.Equal(t, spans[0].SpanContext().SpanID().String(), logger.Data[logging.FieldSpanID])
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), logger.Data[logging.FieldOTelTraceID])
+}
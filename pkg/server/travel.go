@@ -0,0 +1,313 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxTravelSteps is the longest route a single travelTo call may follow
+const maxTravelSteps = 2000
+
+// Reasons travel stops short of its destination
+const (
+	TravelInterruptHostiles  = "hostiles"
+	TravelInterruptEncounter = "encounter"
+	TravelInterruptBlocked   = "blocked"
+)
+
+// travelInterrupt describes why travel stopped short of its destination
+type travelInterrupt struct {
+	Reason    string                  `json:"reason"`
+	Position  game.Position           `json:"position"`
+	Encounter *EncounterProposal      `json:"encounter,omitempty"`
+	Hostiles  []game.ThreatAssessment `json:"hostiles,omitempty"`
+	Detail    string                  `json:"detail,omitempty"`
+}
+
+// journey tracks a player's progress along a travel route
+type journey struct {
+	session   *PlayerSession
+	route     *pcg.TravelRoute
+	legs      int   // Legs completed
+	steps     int   // Steps taken
+	ticks     int64 // Game ticks passed
+	interrupt *travelInterrupt
+}
+
+// handleTravelTo walks a player to a destination outside combat, across
+// levels through the stairs and other connections of integrated dungeons,
+// and along overworld roads where they are faster. Each step passes game
+// time at the pace of its leg of the route, so an hour of road travel
+// advances the clock an hour, and quest deadlines are checked after each
+// leg. Travel stops when hostiles come into sight, when a random encounter
+// is rolled, or when the way is blocked, and the interruption is broadcast
+// as EventTravelInterrupted.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The session ID of the travelling player
+//   - position: object - x, y and level of the destination
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool indicating the travel took place
+//   - arrived: bool true if the player reached the destination
+//   - interrupted: bool true if travel stopped short of it
+//   - interrupt: The reason, position, encounter or hostiles (if interrupted)
+//   - route: pcg.TravelRoute planned to the destination
+//   - legs_completed: int legs of the route completed
+//   - steps: int steps taken
+//   - position: game.Position where the player stopped
+//   - elapsed: int64 game ticks passed
+//   - game_time: int64 game ticks after travel
+//   - error: Invalid parameters or session, ErrCombatInProgress during
+//     combat, or a destination that is blocked, unreachable or more than
+//     maxTravelSteps away
+func (s *RPCServer) handleTravelTo(params json.RawMessage) (interface{}, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleTravelTo",
+	})
+	logger.Debug("entering handleTravelTo")
+
+	var req struct {
+		SessionID string `json:"session_id"`
+		Position  struct {
+			X     int `json:"x"`
+			Y     int `json:"y"`
+			Level int `json:"level"`
+		} `json:"position"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		logger.WithError(err).Error("failed to unmarshal request parameters")
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid travel parameters", err.Error())
+	}
+
+	session, err := s.getPlayerSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.state.TurnManager.IsInCombat {
+		return nil, ErrCombatInProgress.WithMessage("cannot travel during combat")
+	}
+	if s.pcgManager == nil {
+		return nil, ErrUnavailable.WithMessage("travel routes are not available")
+	}
+
+	player := session.Player
+	from := player.GetPosition()
+	to := game.Position{X: req.Position.X, Y: req.Position.Y, Level: req.Position.Level, Facing: from.Facing}
+	if err := s.checkDestination(player, to); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid travel destination", err.Error())
+	}
+
+	route, err := s.pcgManager.GetTravelGraph().Route(from, to)
+	if errors.Is(err, pcg.ErrNoTravelRoute) {
+		return nil, ErrInvalidTarget.WithMessage("%v", err).
+			WithData(map[string]interface{}{"from": from, "to": to})
+	}
+	if err != nil {
+		return nil, NewJSONRPCError(JSONRPCInternalError, "Failed to plan travel route", err.Error())
+	}
+	if route.Steps > maxTravelSteps {
+		return nil, ErrInvalidTarget.WithMessage("destination is %d steps away, more than %d", route.Steps, maxTravelSteps).
+			WithData(map[string]interface{}{"steps": route.Steps, "limit": maxTravelSteps})
+	}
+
+	trip := &journey{session: session, route: route}
+	s.travel(trip)
+
+	s.state.stateMu.RLock()
+	gameTime := s.state.TimeManager.CurrentTime.GameTicks
+	s.state.stateMu.RUnlock()
+
+	logger.WithFields(logrus.Fields{
+		"player_id":   player.GetID(),
+		"legs":        trip.legs,
+		"steps":       trip.steps,
+		"interrupted": trip.interrupt != nil,
+	}).Info("player travelled")
+
+	result := map[string]interface{}{
+		"success":        true,
+		"arrived":        trip.interrupt == nil,
+		"interrupted":    trip.interrupt != nil,
+		"route":          route,
+		"legs_completed": trip.legs,
+		"steps":          trip.steps,
+		"position":       player.GetPosition(),
+		"elapsed":        trip.ticks,
+		"game_time":      gameTime,
+	}
+	if trip.interrupt != nil {
+		result["interrupt"] = trip.interrupt
+	}
+	return result, nil
+}
+
+// travel follows the trip's route until it ends or is interrupted, which
+// is then broadcast. Hostiles already in sight keep the player from
+// setting out.
+func (s *RPCServer) travel(trip *journey) {
+	player := trip.session.Player
+	if hostiles := s.state.WorldState.VisibleHostiles(&player.Character, game.DefaultSightRange, game.DefaultThreatTable()); len(hostiles) > 0 {
+		trip.interrupt = &travelInterrupt{Reason: TravelInterruptHostiles, Hostiles: hostiles}
+	}
+
+	for _, leg := range trip.route.Legs {
+		if trip.interrupt != nil {
+			break
+		}
+		s.travelLeg(trip, leg)
+		s.updateWeather()
+		s.checkQuestDeadlines()
+		if at := player.GetPosition(); at.X == leg.To.X && at.Y == leg.To.Y && at.Level == leg.To.Level {
+			trip.legs++
+		}
+	}
+
+	if trip.interrupt != nil {
+		s.interruptTravel(trip)
+	}
+}
+
+// travelLeg takes one leg of the trip's route: a transition straight to
+// its end, or a walk through its waypoints
+func (s *RPCServer) travelLeg(trip *journey, leg pcg.TravelLeg) {
+	player := trip.session.Player
+	ticksPerStep := int64(leg.Duration/time.Second) / int64(max(leg.Steps, 1))
+
+	if leg.Transition {
+		if err := s.checkDestination(player, leg.To); err != nil {
+			trip.interrupt = &travelInterrupt{Reason: TravelInterruptBlocked, Detail: err.Error()}
+			return
+		}
+		if err := s.teleportObject(player, leg.To); err != nil {
+			trip.interrupt = &travelInterrupt{Reason: TravelInterruptBlocked, Detail: err.Error()}
+			return
+		}
+		s.travelStep(trip, ticksPerStep)
+		return
+	}
+
+	for _, target := range append(append([]game.Position(nil), leg.Waypoints...), leg.To) {
+		path := s.travelPath(player, player.GetPosition(), target)
+		if path == nil {
+			trip.interrupt = &travelInterrupt{
+				Reason: TravelInterruptBlocked,
+				Detail: fmt.Sprintf("no way through to %d,%d on level %d", target.X, target.Y, target.Level),
+			}
+			return
+		}
+		for _, pos := range path {
+			if err := s.executePlayerMovement(player, pos); err != nil {
+				trip.interrupt = &travelInterrupt{Reason: TravelInterruptBlocked, Detail: err.Error()}
+				return
+			}
+			s.travelStep(trip, ticksPerStep)
+			if trip.interrupt != nil {
+				return
+			}
+		}
+	}
+}
+
+// travelStep passes the game time of a step and checks the player's new
+// position for hostiles in sight and random encounters
+func (s *RPCServer) travelStep(trip *journey, ticks int64) {
+	trip.steps++
+	trip.ticks += ticks
+	s.state.stateMu.Lock()
+	s.state.TimeManager.Skip(ticks)
+	s.state.stateMu.Unlock()
+
+	player := trip.session.Player
+	if hostiles := s.state.WorldState.VisibleHostiles(&player.Character, game.DefaultSightRange, game.DefaultThreatTable()); len(hostiles) > 0 {
+		trip.interrupt = &travelInterrupt{Reason: TravelInterruptHostiles, Hostiles: hostiles}
+		return
+	}
+	if encounter := s.checkRandomEncounter(trip.session, player.GetPosition()); encounter != nil {
+		trip.interrupt = &travelInterrupt{Reason: TravelInterruptEncounter, Encounter: encounter}
+	}
+}
+
+// interruptTravel records where the trip stopped and broadcasts the
+// interruption with the legs of the route left to travel
+func (s *RPCServer) interruptTravel(trip *journey) {
+	player := trip.session.Player
+	trip.interrupt.Position = player.GetPosition()
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "interruptTravel",
+		"player_id": player.GetID(),
+		"reason":    trip.interrupt.Reason,
+		"position":  trip.interrupt.Position,
+	}).Info("travel interrupted")
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventTravelInterrupted,
+		SourceID: player.GetID(),
+		Data: map[string]interface{}{
+			"interrupt":   trip.interrupt,
+			"destination": trip.route.To,
+			"remaining":   trip.route.Legs[min(trip.legs, len(trip.route.Legs)):],
+		},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// travelPath returns the shortest walk on a level from one position to
+// another, excluding from, or nil if there is none. Steps are taken as
+// by move and stay off the walls of the level's tiles.
+func (s *RPCServer) travelPath(player *game.Player, from, to game.Position) []game.Position {
+	if from.Level != to.Level {
+		return nil
+	}
+	world := s.state.WorldState
+	level, _, err := world.LevelSnapshot(from.Level)
+	if err != nil {
+		level = nil
+	}
+
+	passable := func(pos game.Position) bool {
+		if world.ValidateMove(player, pos) != nil {
+			return false
+		}
+		return level == nil || pos.Y >= len(level.Tiles) || pos.X >= len(level.Tiles[pos.Y]) || level.Tiles[pos.Y][pos.X].Walkable
+	}
+
+	start := game.Position{X: from.X, Y: from.Y, Level: from.Level}
+	goal := game.Position{X: to.X, Y: to.Y, Level: to.Level}
+	previous := map[game.Position]game.Position{start: start}
+	queue := []game.Position{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == goal {
+			path := []game.Position{}
+			for pos := goal; pos != start; pos = previous[pos] {
+				path = append(path, game.Position{X: pos.X, Y: pos.Y, Level: pos.Level, Facing: from.Facing})
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+
+		for _, direction := range []game.Direction{game.North, game.East, game.South, game.West} {
+			next := calculateNewPositionUnchecked(current, direction)
+			if _, seen := previous[next]; seen || !passable(next) {
+				continue
+			}
+			previous[next] = current
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTravelTestServer returns a server whose world has the default level
+// and a two-level crypt integrated after it, entered by stairs at 12,10 on
+// the default level. The crypt's stairs lead from 5,5 on level 1 to 1,1 on
+// level 2.
+func newTravelTestServer(t *testing.T) (*RPCServer, *PlayerSession) {
+	t.Helper()
+	server := createTestServerForHandlers(t)
+	session := createEventSourcedTestSession(t, server)
+	server.state.WorldState.Width, server.state.WorldState.Height = 20, 20
+
+	walkable := func() *game.GameMap {
+		tiles := make([][]game.MapTile, 8)
+		for y := range tiles {
+			tiles[y] = make([]game.MapTile, 8)
+			for x := range tiles[y] {
+				tiles[y][x] = game.MapTile{Walkable: true, Transparent: true}
+			}
+		}
+		return &game.GameMap{Width: 8, Height: 8, Tiles: tiles}
+	}
+	crypt := &pcg.DungeonComplex{
+		ID:   "crypt",
+		Name: "Crypt",
+		Levels: map[int]*pcg.DungeonLevel{
+			1: {Level: 1, Map: walkable()},
+			2: {Level: 2, Map: walkable()},
+		},
+		Connections: []pcg.LevelConnection{{
+			FromLevel: 1, ToLevel: 2,
+			FromPosition: game.Position{X: 5, Y: 5}, ToPosition: game.Position{X: 1, Y: 1},
+			Type: pcg.ConnectionStairs,
+		}},
+	}
+	ix := server.pcgManager.BeginWorldIntegration(context.Background(), server.state.WorldState, "crypt")
+	require.NoError(t, ix.Stage(crypt))
+	require.NoError(t, ix.Commit())
+	server.pcgManager.GetTravelGraph().Connect(game.Position{X: 12, Y: 10}, game.Position{X: 1, Y: 1, Level: 1}, string(pcg.ConnectionStairs), time.Minute, false)
+
+	return server, session
+}
+
+func travelTo(t *testing.T, server *RPCServer, session *PlayerSession, x, y, level int) (map[string]interface{}, error) {
+	t.Helper()
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id": session.SessionID,
+		"position":   map[string]interface{}{"x": x, "y": y, "level": level},
+	})
+	require.NoError(t, err)
+	result, err := server.handleMethod(MethodTravelTo, params)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]interface{}), nil
+}
+
+func TestTravelTo_CrossesLevels(t *testing.T) {
+	server, session := newTravelTestServer(t)
+	startTicks := server.state.TimeManager.CurrentTime.GameTicks
+
+	result, err := travelTo(t, server, session, 3, 4, 2)
+	require.NoError(t, err)
+	assert.Equal(t, true, result["arrived"])
+	assert.Equal(t, false, result["interrupted"])
+	assert.Equal(t, game.Position{X: 3, Y: 4, Level: 2}, session.Player.GetPosition())
+
+	route := result["route"].(*pcg.TravelRoute)
+	var kinds []string
+	for _, leg := range route.Legs {
+		kinds = append(kinds, leg.Kind)
+	}
+	assert.Equal(t, []string{pcg.TravelLegWalk, "stairs", pcg.TravelLegWalk, "stairs", pcg.TravelLegWalk}, kinds)
+	assert.Equal(t, len(route.Legs), result["legs_completed"])
+
+	// 2 steps to the crypt, 8 to its stairs and 5 from them, a minute
+	// each, and a minute for each flight of stairs
+	assert.Equal(t, 17, result["steps"])
+	assert.Equal(t, int64(17*60), result["elapsed"])
+	assert.Equal(t, startTicks+17*60, server.state.TimeManager.CurrentTime.GameTicks, "travel advances game time")
+}
+
+func TestTravelTo_InterruptedByHostiles(t *testing.T) {
+	server, session := newTravelTestServer(t)
+	interrupted := make(chan game.GameEvent, 1)
+	server.eventSys.Subscribe(EventTravelInterrupted, func(event game.GameEvent) { interrupted <- event })
+
+	orc := &game.NPC{Character: game.Character{ID: "travel-orc", Name: "Orc", HP: 10, MaxHP: 10, Position: game.Position{X: 3, Y: 6, Level: 2}}}
+	require.NoError(t, server.state.WorldState.AddObject(orc))
+
+	result, err := travelTo(t, server, session, 3, 4, 2)
+	require.NoError(t, err)
+	assert.Equal(t, false, result["arrived"])
+	interrupt := result["interrupt"].(*travelInterrupt)
+	assert.Equal(t, TravelInterruptHostiles, interrupt.Reason)
+	require.NotEmpty(t, interrupt.Hostiles)
+	assert.Equal(t, "travel-orc", interrupt.Hostiles[0].ID)
+	assert.Equal(t, game.Position{X: 1, Y: 1, Level: 2}, session.Player.GetPosition(), "the orc is seen at the foot of the stairs")
+	assert.Equal(t, interrupt.Position, session.Player.GetPosition())
+
+	select {
+	case event := <-interrupted:
+		assert.Equal(t, session.Player.GetID(), event.SourceID)
+		assert.Equal(t, game.Position{X: 3, Y: 4, Level: 2}, event.Data["destination"])
+		remaining := event.Data["remaining"].([]pcg.TravelLeg)
+		require.Len(t, remaining, 1, "only the last walk is left")
+		assert.Equal(t, pcg.TravelLegWalk, remaining[0].Kind)
+	case <-time.After(time.Second):
+		t.Fatal("the interruption was not broadcast")
+	}
+
+	// With the orc in sight the player does not set out at all
+	result, err = travelTo(t, server, session, 1, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result["steps"])
+	assert.Equal(t, game.Position{X: 1, Y: 1, Level: 2}, session.Player.GetPosition())
+}
+
+func TestTravelTo_InterruptedByEncounter(t *testing.T) {
+	server, session := newTravelTestServer(t)
+	server.config.RandomEncountersEnabled = true
+	server.config.EncounterCooldownSteps = 100
+	server.attachEncounters()
+	server.encounters.SetTable(pcg.BiomeDungeon, alwaysEncounter)
+	started := make(chan game.GameEvent, 1)
+	server.eventSys.Subscribe(EventCombatStart, func(event game.GameEvent) { started <- event })
+
+	result, err := travelTo(t, server, session, 3, 4, 2)
+	require.NoError(t, err)
+	interrupt := result["interrupt"].(*travelInterrupt)
+	assert.Equal(t, TravelInterruptEncounter, interrupt.Reason)
+	require.NotNil(t, interrupt.Encounter)
+	assert.Equal(t, 1, result["steps"], "the first step rolls the encounter")
+	assert.Equal(t, 0, result["legs_completed"])
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the encounter did not start combat")
+	}
+
+	_, err = travelTo(t, server, session, 3, 4, 2)
+	assert.ErrorIs(t, err, ErrCombatInProgress)
+}
+
+func TestTravelTo_Unreachable(t *testing.T) {
+	server, session := newTravelTestServer(t)
+	server.state.WorldState.AddLevels(*pcg.LevelFromTerrain("island", &game.GameMap{}))
+
+	_, err := travelTo(t, server, session, 1, 1, 3)
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+
+	_, err = travelTo(t, server, session, 1, 1, 9)
+	assert.Error(t, err, "no such level")
+	assert.Equal(t, game.Position{X: 10, Y: 10}, session.Player.GetPosition())
+}
+
+func TestTravelTo_IdempotencyKey(t *testing.T) {
+	server, session := newTravelTestServer(t)
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id":                   session.SessionID,
+		"position":                     map[string]interface{}{"x": 3, "y": 4, "level": 2},
+		validation.IdempotencyKeyParam: "travel-1",
+	})
+	require.NoError(t, err)
+
+	_, err = server.handleMethod(MethodTravelTo, params)
+	require.NoError(t, err)
+	ticks := server.state.TimeManager.CurrentTime.GameTicks
+	session.Player.SetPosition(game.Position{X: 10, Y: 10})
+
+	_, err = server.handleMethod(MethodTravelTo, params)
+	require.NoError(t, err)
+	assert.Equal(t, game.Position{X: 10, Y: 10}, session.Player.GetPosition(), "the retry is not applied again")
+	assert.Equal(t, ticks, server.state.TimeManager.CurrentTime.GameTicks, "the retry passes no game time")
+}
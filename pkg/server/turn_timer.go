@@ -0,0 +1,285 @@
+package server
+
+import (
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTurnWarning is how long before its turn times out a combatant is
+// warned.
+var DefaultTurnWarning = 15 * time.Second
+
+// DefaultAFKSkipLimit is how many turns in a row a player may lose to the
+// turn timer before being removed from the initiative order.
+const DefaultAFKSkipLimit = 3
+
+// TurnTimerPolicy configures the combat turn timer.
+//
+// Fields:
+//   - Duration: Time a combatant has to act; 0 disables the timer
+//   - Warning: Time left when the combatant is warned; 0 disables warnings
+//   - SkipLimit: Turns in a row a player may time out before removal from
+//     the initiative order; 0 never removes anyone
+type TurnTimerPolicy struct {
+	Duration  time.Duration
+	Warning   time.Duration
+	SkipLimit int
+}
+
+// TurnTimerHandlers receive the turn timer's notifications on the timer's
+// goroutine. Without an Expired handler a timed out turn simply ends.
+type TurnTimerHandlers struct {
+	Warning func(entityID string, remaining time.Duration)
+	Expired func(entityID string)
+}
+
+// SetTurnTimer sets the turn timer policy. It takes effect when the next
+// turn starts.
+func (tm *TurnManager) SetTurnTimer(policy TurnTimerPolicy) {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	tm.turnDuration = policy.Duration
+	tm.turnWarning = policy.Warning
+	tm.skipLimit = policy.SkipLimit
+}
+
+// SetTurnTimerHandlers sets the receivers of turn warnings and timeouts.
+func (tm *TurnManager) SetTurnTimerHandlers(handlers TurnTimerHandlers) {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	tm.timerHandlers = handlers
+}
+
+// TurnDeadline returns when the current turn times out, or the zero time
+// if no turn timer is running.
+func (tm *TurnManager) TurnDeadline() time.Time {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	if tm.turnTimer == nil {
+		return time.Time{}
+	}
+	return tm.turnDeadline
+}
+
+// startTurnTimer restarts the turn timer for the turn that just began.
+func (tm *TurnManager) startTurnTimer() {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+
+	tm.stopTimersLocked()
+	tm.timerSerial++
+	if tm.turnDuration <= 0 {
+		return
+	}
+
+	serial := tm.timerSerial
+	tm.turnDeadline = time.Now().Add(tm.turnDuration)
+	if tm.turnWarning > 0 && tm.turnWarning < tm.turnDuration {
+		tm.warnTimer = time.AfterFunc(tm.turnDuration-tm.turnWarning, func() { tm.fireTurnTimer(serial, true) })
+	}
+	tm.turnTimer = time.AfterFunc(tm.turnDuration, func() { tm.fireTurnTimer(serial, false) })
+}
+
+// stopTurnTimer stops the turn timer, as when combat ends.
+func (tm *TurnManager) stopTurnTimer() {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	tm.stopTimersLocked()
+	tm.timerSerial++
+}
+
+// stopTimersLocked stops the timers. Callers must hold tm.timerMu.
+func (tm *TurnManager) stopTimersLocked() {
+	if tm.turnTimer != nil {
+		tm.turnTimer.Stop()
+		tm.turnTimer = nil
+	}
+	if tm.warnTimer != nil {
+		tm.warnTimer.Stop()
+		tm.warnTimer = nil
+	}
+}
+
+// fireTurnTimer delivers a warning or timeout for the turn the timer with
+// serial was started for. Timers of turns that have since ended do nothing.
+func (tm *TurnManager) fireTurnTimer(serial uint64, warning bool) {
+	tm.timerMu.Lock()
+	if serial != tm.timerSerial {
+		tm.timerMu.Unlock()
+		return
+	}
+	handlers, remaining := tm.timerHandlers, tm.turnWarning
+	tm.timerMu.Unlock()
+
+	if !tm.IsInCombat || tm.CurrentIndex >= len(tm.Initiative) {
+		return
+	}
+	actor := tm.Initiative[tm.CurrentIndex]
+
+	switch {
+	case warning:
+		if handlers.Warning != nil {
+			handlers.Warning(actor, remaining)
+		}
+	case handlers.Expired != nil:
+		handlers.Expired(actor)
+	default:
+		tm.endTurn()
+	}
+}
+
+// RecordSkippedTurn counts a turn entityID lost to the turn timer and
+// returns how many turns in a row it has lost.
+func (tm *TurnManager) RecordSkippedTurn(entityID string) int {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	if tm.skippedTurns == nil {
+		tm.skippedTurns = make(map[string]int)
+	}
+	tm.skippedTurns[entityID]++
+	return tm.skippedTurns[entityID]
+}
+
+// ResetSkippedTurns clears entityID's run of skipped turns, as when it
+// ends a turn itself.
+func (tm *TurnManager) ResetSkippedTurns(entityID string) {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	delete(tm.skippedTurns, entityID)
+}
+
+// SkipLimitReached reports whether entityID has lost as many turns in a
+// row as the policy allows.
+func (tm *TurnManager) SkipLimitReached(entityID string) bool {
+	tm.timerMu.Lock()
+	defer tm.timerMu.Unlock()
+	return tm.skipLimit > 0 && tm.skippedTurns[entityID] >= tm.skipLimit
+}
+
+// RemoveFromInitiative takes entityID out of the initiative order. The
+// current turn stays with the same combatant unless it is the one removed,
+// in which case the turn passes to the next in order.
+func (tm *TurnManager) RemoveFromInitiative(entityID string) error {
+	index := tm.initiativeIndex(entityID)
+	if index < 0 {
+		return ErrNotInCombat.WithMessage("entity %s is not in combat", entityID)
+	}
+
+	tm.Initiative = append(tm.Initiative[:index:index], tm.Initiative[index+1:]...)
+	if index < tm.CurrentIndex {
+		tm.CurrentIndex--
+	}
+	if tm.CurrentIndex >= len(tm.Initiative) {
+		tm.CurrentIndex = 0
+	}
+
+	for i, entry := range tm.Breakdown {
+		if entry.EntityID == entityID {
+			tm.Breakdown = append(tm.Breakdown[:i:i], tm.Breakdown[i+1:]...)
+			break
+		}
+	}
+	tm.ResetSkippedTurns(entityID)
+	tm.notifyState(CombatStateChange{Kind: CombatStateInitiative})
+	return nil
+}
+
+// configureTurnTimer applies the configured turn timer policy to the turn
+// manager and routes its notifications and state changes to the server.
+func (s *RPCServer) configureTurnTimer() {
+	policy := TurnTimerPolicy{Duration: DefaultTurnDuration, Warning: DefaultTurnWarning, SkipLimit: DefaultAFKSkipLimit}
+	if s.config != nil {
+		policy = TurnTimerPolicy{Duration: s.config.TurnTimeout, Warning: s.config.TurnWarning, SkipLimit: s.config.AFKSkipLimit}
+	}
+	s.state.TurnManager.SetTurnTimer(policy)
+	s.state.TurnManager.SetTurnTimerHandlers(TurnTimerHandlers{
+		Warning: s.warnTurnExpiring,
+		Expired: s.handleTurnExpired,
+	})
+	s.state.TurnManager.SetStateListener(s.pushCombatState)
+}
+
+// warnTurnExpiring tells everyone that entityID's turn is about to time
+// out.
+func (s *RPCServer) warnTurnExpiring(entityID string, remaining time.Duration) {
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventTurnWarning,
+		SourceID: entityID,
+		TargetID: entityID,
+		Data: map[string]interface{}{
+			"remaining_seconds": remaining.Seconds(),
+			"deadline":          s.state.TurnManager.TurnDeadline(),
+		},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleTurnExpired skips the turn of a combatant that did not act in
+// time. The combatant takes a defensive stance by holding a shield block,
+// and a player who has timed out as often in a row as the policy allows is
+// removed from the initiative order so the fight can go on without them.
+func (s *RPCServer) handleTurnExpired(entityID string) {
+	tm := s.state.TurnManager
+	if !tm.IsCurrentTurn(entityID) {
+		return
+	}
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "handleTurnExpired",
+		"entityID": entityID,
+	})
+
+	defended := false
+	if _, err := tm.RegisterReaction(entityID, ReactionShieldBlock); err == nil {
+		defended = true
+	} else {
+		for _, reaction := range tm.GetReactions(entityID) {
+			defended = defended || reaction.Type == ReactionShieldBlock
+		}
+	}
+	skipped := tm.RecordSkippedTurn(entityID)
+
+	actor := s.state.WorldState.Objects[entityID]
+	nextTurn := s.finishTurn(actor)
+
+	_, isPlayer := actor.(*game.Player)
+	removed := false
+	if isPlayer && tm.SkipLimitReached(entityID) {
+		if err := tm.RemoveFromInitiative(entityID); err != nil {
+			logger.WithError(err).Warn("failed to remove idle player from initiative")
+		} else {
+			removed = true
+			logger.WithField("skipped_turns", skipped).Info("removed idle player from initiative")
+		}
+	}
+
+	if removed {
+		if len(tm.Initiative) == 0 {
+			s.endCombat()
+			nextTurn = ""
+		} else if nextTurn == entityID {
+			nextTurn = tm.Initiative[tm.CurrentIndex]
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"skipped_turns": skipped,
+		"defended":      defended,
+		"next_turn":     nextTurn,
+	}).Info("turn timed out and was skipped")
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:     EventTurnSkipped,
+		SourceID: entityID,
+		TargetID: entityID,
+		Data: map[string]interface{}{
+			"skipped_turns": skipped,
+			"defended":      defended,
+			"removed":       removed,
+			"next_turn":     nextTurn,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTurnManager_TurnTimerWarnsThenExpires(t *testing.T) {
+	tm := NewTurnManager()
+	tm.SetTurnTimer(TurnTimerPolicy{Duration: 60 * time.Millisecond, Warning: 40 * time.Millisecond})
+
+	warnings := make(chan string, 4)
+	expired := make(chan string, 4)
+	tm.SetTurnTimerHandlers(TurnTimerHandlers{
+		Warning: func(entityID string, remaining time.Duration) {
+			assert.Equal(t, 40*time.Millisecond, remaining)
+			warnings <- entityID
+		},
+		Expired: func(entityID string) { expired <- entityID },
+	})
+	require.NoError(t, tm.StartCombat([]string{"fighter", "goblin"}))
+	defer tm.EndCombat()
+	assert.False(t, tm.TurnDeadline().IsZero())
+
+	select {
+	case id := <-warnings:
+		assert.Equal(t, "fighter", id)
+	case <-time.After(time.Second):
+		t.Fatal("expected a turn warning")
+	}
+	select {
+	case id := <-expired:
+		assert.Equal(t, "fighter", id)
+	case <-time.After(time.Second):
+		t.Fatal("expected the turn to expire")
+	}
+}
+
+func TestTurnManager_TurnTimerIgnoresEndedTurns(t *testing.T) {
+	tm := NewTurnManager()
+	tm.SetTurnTimer(TurnTimerPolicy{Duration: 40 * time.Millisecond})
+
+	expired := make(chan string, 4)
+	tm.SetTurnTimerHandlers(TurnTimerHandlers{Expired: func(entityID string) { expired <- entityID }})
+	require.NoError(t, tm.StartCombat([]string{"fighter", "goblin"}))
+	tm.AdvanceTurn()
+	tm.EndCombat()
+
+	select {
+	case id := <-expired:
+		t.Fatalf("expected no timeout after combat ended, got %s", id)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.True(t, tm.TurnDeadline().IsZero())
+}
+
+func TestTurnManager_RemoveFromInitiative(t *testing.T) {
+	tm := NewTurnManager()
+	require.NoError(t, tm.StartCombat([]string{"a", "b", "c"}))
+	defer tm.EndCombat()
+	tm.AdvanceTurn()
+
+	// Removing an earlier combatant keeps the turn with "b"
+	require.NoError(t, tm.RemoveFromInitiative("a"))
+	assert.Equal(t, []string{"b", "c"}, tm.Initiative)
+	assert.True(t, tm.IsCurrentTurn("b"))
+
+	// Removing the current combatant passes the turn on
+	require.NoError(t, tm.RemoveFromInitiative("b"))
+	assert.True(t, tm.IsCurrentTurn("c"))
+
+	assert.Error(t, tm.RemoveFromInitiative("b"))
+}
+
+func TestTurnManager_SkippedTurns(t *testing.T) {
+	tm := NewTurnManager()
+	tm.SetTurnTimer(TurnTimerPolicy{SkipLimit: 2})
+
+	assert.Equal(t, 1, tm.RecordSkippedTurn("fighter"))
+	assert.False(t, tm.SkipLimitReached("fighter"))
+	assert.Equal(t, 2, tm.RecordSkippedTurn("fighter"))
+	assert.True(t, tm.SkipLimitReached("fighter"))
+
+	tm.ResetSkippedTurns("fighter")
+	assert.False(t, tm.SkipLimitReached("fighter"))
+
+	tm.SetTurnTimer(TurnTimerPolicy{})
+	tm.RecordSkippedTurn("fighter")
+	tm.RecordSkippedTurn("fighter")
+	assert.False(t, tm.SkipLimitReached("fighter"), "a zero limit never removes anyone")
+}
+
+// startTurnTimerTestCombat starts combat between the session player and a
+// goblin with a turn timer that does not fire during the test, and returns
+// the turn manager with the player acting first.
+func startTurnTimerTestCombat(t *testing.T, server *RPCServer, skipLimit int) *TurnManager {
+	t.Helper()
+	createTestSessionForHandlers(t, server)
+	addInitiativeGoblin(t, server, 10, 0)
+	server.config.TurnTimeout = time.Hour
+	server.config.AFKSkipLimit = skipLimit
+
+	startInitiativeCombat(t, server, map[string]interface{}{})
+	tm := server.state.TurnManager
+	tm.CombatGroups = map[string][]string{"test-player-001": {"test-player-001"}, "goblin": {"goblin"}}
+	t.Cleanup(server.endCombat)
+	if !tm.IsCurrentTurn("test-player-001") {
+		tm.AdvanceTurn()
+	}
+	require.True(t, tm.IsCurrentTurn("test-player-001"))
+	return tm
+}
+
+func TestHandleTurnExpired_SkipsTurnDefensively(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	tm := startTurnTimerTestCombat(t, server, 2)
+
+	skips := make(chan game.GameEvent, 1)
+	server.eventSys.Subscribe(EventTurnSkipped, func(event game.GameEvent) { skips <- event })
+
+	server.handleTurnExpired("test-player-001")
+
+	assert.True(t, tm.IsCurrentTurn("goblin"))
+	reactions := tm.GetReactions("test-player-001")
+	require.Len(t, reactions, 1)
+	assert.Equal(t, ReactionShieldBlock, reactions[0].Type)
+
+	select {
+	case event := <-skips:
+		assert.Equal(t, "test-player-001", event.SourceID)
+		assert.Equal(t, 1, event.Data["skipped_turns"])
+		assert.Equal(t, true, event.Data["defended"])
+		assert.Equal(t, false, event.Data["removed"])
+		assert.Equal(t, "goblin", event.Data["next_turn"])
+	case <-time.After(time.Second):
+		t.Fatal("expected a turn skipped event")
+	}
+
+	// A timeout for someone whose turn it is not does nothing
+	server.handleTurnExpired("test-player-001")
+	assert.True(t, tm.IsCurrentTurn("goblin"))
+}
+
+func TestHandleTurnExpired_RemovesIdlePlayer(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	tm := startTurnTimerTestCombat(t, server, 2)
+
+	server.handleTurnExpired("test-player-001")
+	server.handleTurnExpired("goblin")
+	server.handleTurnExpired("test-player-001")
+
+	assert.Equal(t, []string{"goblin"}, tm.Initiative)
+	assert.True(t, tm.IsCurrentTurn("goblin"))
+	assert.True(t, tm.IsInCombat)
+
+	// NPCs are skipped but never removed
+	for i := 0; i < 3; i++ {
+		server.handleTurnExpired("goblin")
+	}
+	assert.Equal(t, []string{"goblin"}, tm.Initiative)
+}
+
+func TestHandleEndTurn_ResetsSkippedTurns(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	tm := startTurnTimerTestCombat(t, server, 2)
+
+	server.handleTurnExpired("test-player-001")
+	server.handleTurnExpired("goblin")
+
+	params, _ := json.Marshal(map[string]interface{}{"session_id": "test-session-001"})
+	_, err := server.handleEndTurn(params)
+	require.NoError(t, err)
+	server.handleTurnExpired("goblin")
+
+	server.handleTurnExpired("test-player-001")
+	assert.Contains(t, tm.Initiative, "test-player-001", "ending a turn resets the run of skipped turns")
+}
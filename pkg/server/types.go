@@ -70,6 +70,10 @@ type StateUpdate struct {
 //   - LastActive: Timestamp of the most recent player activity in this session
 //   - Connected: Boolean flag indicating if the player is currently connected
 //
+// Sessions created by joinGame also carry a resume token and a bounded
+// buffer of broadcast events, which reconnectSession uses to reattach a new
+// WebSocket and replay what the client missed.
+//
 // Related types:
 //   - game.Player: The player entity associated with this session
 type PlayerSession struct {
@@ -81,6 +85,10 @@ type PlayerSession struct {
 	MessageChan chan []byte     `yaml:"-"`           // Channel for sending messages
 	WSConn      *websocket.Conn `yaml:"-"`           // WebSocket connection
 	inUse       int32           `yaml:"-"`           // Atomic counter for active usage (prevents cleanup)
+	resumeToken string          `yaml:"-"`           // Secret that lets a new WebSocket resume this session
+	events      *eventBuffer    `yaml:"-"`           // Recent broadcast events replayed on reconnect
+
+	affinitySince time.Time `yaml:"-"` // When this instance took the session over from another
 }
 
 // Update modifies the player session with the provided updates.
@@ -3,86 +3,13 @@ package server
 import (
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strconv"
 
 	"goldbox-rpg/pkg/game"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/exp/rand"
 )
 
-// ADDED: rollInitiative determines combat turn order by rolling initiative for all participants.
-// It calculates initiative scores and returns participants sorted by highest roll first.
-//
-// Initiative calculation:
-// - Characters: d20 + DEX modifier ((Dexterity-10)/2)
-// - Other entities: d20 only
-//
-// Parameters:
-//   - participants: Slice of entity IDs representing the combatants
-//
-// Returns:
-//   - []string: Entity IDs sorted by initiative roll (highest to lowest)
-//
-// Notes:
-// - Characters must exist in WorldState.Objects to apply DEX bonus
-// - Non-existent entities are skipped from results
-// - Uses golang.org/x/exp/rand for random number generation
-func (s *RPCServer) rollInitiative(participants []string) []string {
-	logger := logrus.WithFields(logrus.Fields{
-		"function":        "rollInitiative",
-		"numParticipants": len(participants),
-	})
-	logger.Debug("rolling initiative")
-
-	type initiativeRoll struct {
-		entityID string
-		roll     int
-	}
-
-	rolls := make([]initiativeRoll, len(participants))
-	for i, id := range participants {
-		logger := logger.WithField("entityID", id)
-		if obj, exists := s.state.WorldState.Objects[id]; exists {
-			if char, ok := obj.(*game.Character); ok {
-				roll := rand.Intn(20) + 1
-				modifier := (char.Dexterity - 10) / 2
-				rolls[i] = initiativeRoll{
-					entityID: id,
-					roll:     roll + modifier,
-				}
-				logger.WithFields(logrus.Fields{
-					"baseRoll": roll,
-					"modifier": modifier,
-					"total":    rolls[i].roll,
-				}).Info("rolled initiative for character")
-			} else {
-				roll := rand.Intn(20) + 1
-				rolls[i] = initiativeRoll{
-					entityID: id,
-					roll:     roll,
-				}
-				logger.WithField("roll", roll).Info("rolled initiative for entity")
-			}
-		} else {
-			logger.Warn("entity not found in world state")
-		}
-	}
-
-	sort.Slice(rolls, func(i, j int) bool {
-		return rolls[i].roll > rolls[j].roll
-	})
-
-	result := make([]string, len(rolls))
-	for i, roll := range rolls {
-		result[i] = roll.entityID
-	}
-
-	logger.WithField("order", result).Info("initiative order determined")
-	return result
-}
-
 // getVisibleObjects returns all game objects that are within the player's visible range.
 // The visibility is determined by the isPositionVisible method which checks if the object's
 // position is within line of sight and range of the player.
@@ -343,6 +270,8 @@ func (s *RPCServer) processEndRound() {
 
 	s.state.TurnManager.CurrentRound++
 	logger.WithField("newRound", s.state.TurnManager.CurrentRound).Info("incremented round counter")
+	s.state.TurnManager.notifyState(CombatStateChange{Kind: CombatStateRound})
+	s.pushCombatantEffects()
 
 	s.processDelayedActions()
 	logger.Debug("processed delayed actions")
@@ -0,0 +1,388 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TicksPerHour is the number of game ticks in one in-game hour. One tick
+// corresponds to one second of game time.
+const TicksPerHour int64 = 3600
+
+// WeatherFrontTicks is how long a weather front lasts, in game ticks.
+const WeatherFrontTicks = 2 * TicksPerHour
+
+// DefaultWeatherRegion is the region used for positions that do not map to a
+// more specific region.
+const DefaultWeatherRegion = "default"
+
+// TimeOfDay is a coarse division of the in-game day.
+type TimeOfDay string
+
+const (
+	TimeOfDayDawn  TimeOfDay = "dawn"
+	TimeOfDayDay   TimeOfDay = "day"
+	TimeOfDayDusk  TimeOfDay = "dusk"
+	TimeOfDayNight TimeOfDay = "night"
+)
+
+// TimeOfDayAt returns the time of day for the given game tick count.
+func TimeOfDayAt(ticks int64) TimeOfDay {
+	hour := (ticks / TicksPerHour) % 24
+	switch {
+	case hour >= 5 && hour < 7:
+		return TimeOfDayDawn
+	case hour >= 7 && hour < 18:
+		return TimeOfDayDay
+	case hour >= 18 && hour < 20:
+		return TimeOfDayDusk
+	default:
+		return TimeOfDayNight
+	}
+}
+
+// WeatherCondition describes the prevailing weather in a region.
+type WeatherCondition string
+
+const (
+	WeatherClear  WeatherCondition = "clear"
+	WeatherCloudy WeatherCondition = "cloudy"
+	WeatherFog    WeatherCondition = "fog"
+	WeatherRain   WeatherCondition = "rain"
+	WeatherStorm  WeatherCondition = "storm"
+	WeatherSnow   WeatherCondition = "snow"
+)
+
+// weatherConditions fixes the iteration order used when rolling weather so
+// generation is reproducible.
+var weatherConditions = []WeatherCondition{
+	WeatherClear, WeatherCloudy, WeatherFog, WeatherRain, WeatherStorm, WeatherSnow,
+}
+
+// climateWeather gives the relative likelihood of each condition by climate.
+var climateWeather = map[pcg.ClimateType]map[WeatherCondition]int{
+	pcg.ClimateTemperate: {WeatherClear: 40, WeatherCloudy: 25, WeatherFog: 10, WeatherRain: 20, WeatherStorm: 5},
+	pcg.ClimateArctic:    {WeatherClear: 25, WeatherCloudy: 20, WeatherFog: 5, WeatherStorm: 10, WeatherSnow: 40},
+	pcg.ClimateTropical:  {WeatherClear: 30, WeatherCloudy: 15, WeatherRain: 35, WeatherStorm: 20},
+	pcg.ClimateArid:      {WeatherClear: 75, WeatherCloudy: 15, WeatherStorm: 10},
+	pcg.ClimateMountain:  {WeatherClear: 30, WeatherCloudy: 20, WeatherFog: 10, WeatherRain: 10, WeatherStorm: 5, WeatherSnow: 25},
+}
+
+// WeatherModifiers are the gameplay effects of the current weather and time
+// of day.
+type WeatherModifiers struct {
+	// RangedAttackPenalty is subtracted from ranged attack damage
+	RangedAttackPenalty int `json:"ranged_attack_penalty"`
+	// MovementCost is added to the action point cost of moving in combat
+	MovementCost int `json:"movement_cost"`
+}
+
+// WeatherFront is the weather affecting a region for a span of game time.
+type WeatherFront struct {
+	Region    string           `json:"region"`
+	Condition WeatherCondition `json:"condition"`
+	Intensity float64          `json:"intensity"`
+	StartTick int64            `json:"start_tick"`
+	EndTick   int64            `json:"end_tick"`
+}
+
+// Modifiers returns the gameplay modifiers imposed by the front.
+func (f WeatherFront) Modifiers() WeatherModifiers {
+	switch f.Condition {
+	case WeatherFog:
+		return WeatherModifiers{RangedAttackPenalty: 2}
+	case WeatherRain:
+		return WeatherModifiers{RangedAttackPenalty: 2}
+	case WeatherStorm:
+		return WeatherModifiers{RangedAttackPenalty: 4, MovementCost: 1}
+	case WeatherSnow:
+		return WeatherModifiers{RangedAttackPenalty: 1, MovementCost: 1}
+	default:
+		return WeatherModifiers{}
+	}
+}
+
+// WeatherChange records a region's weather moving to a new front.
+type WeatherChange struct {
+	Previous WeatherCondition `json:"previous"`
+	Front    WeatherFront     `json:"front"`
+}
+
+// WeatherSystem procedurally generates weather fronts for each region. The
+// front for a region and span of game time is derived from the seed manager,
+// so the same seed always produces the same weather.
+type WeatherSystem struct {
+	mu        sync.RWMutex
+	seeds     *pcg.SeedManager
+	regions   map[string]pcg.ClimateType
+	fronts    map[string]WeatherFront
+	regionFor func(game.Position) string
+}
+
+// NewWeatherSystem creates a weather system seeded by seeds. It starts with
+// a single temperate DefaultWeatherRegion.
+func NewWeatherSystem(seeds *pcg.SeedManager) *WeatherSystem {
+	return &WeatherSystem{
+		seeds:   seeds,
+		regions: map[string]pcg.ClimateType{DefaultWeatherRegion: pcg.ClimateTemperate},
+		fronts:  make(map[string]WeatherFront),
+	}
+}
+
+// AddRegion registers a region with the given climate, replacing any
+// existing climate for the region.
+func (ws *WeatherSystem) AddRegion(regionID string, climate pcg.ClimateType) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.regions[regionID] = climate
+	delete(ws.fronts, regionID)
+}
+
+// SetRegionResolver sets the function that maps world positions to regions.
+// Positions map to DefaultWeatherRegion when no resolver is set.
+func (ws *WeatherSystem) SetRegionResolver(resolve func(game.Position) string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.regionFor = resolve
+}
+
+// RegionAt returns the region containing pos.
+func (ws *WeatherSystem) RegionAt(pos game.Position) string {
+	ws.mu.RLock()
+	resolve := ws.regionFor
+	ws.mu.RUnlock()
+
+	if resolve == nil {
+		return DefaultWeatherRegion
+	}
+	return resolve(pos)
+}
+
+// Current returns the active front for a region. It reports false until the
+// region's weather has been generated by Update.
+func (ws *WeatherSystem) Current(regionID string) (WeatherFront, bool) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	front, ok := ws.fronts[regionID]
+	return front, ok
+}
+
+// Fronts returns the active front of every region.
+func (ws *WeatherSystem) Fronts() map[string]WeatherFront {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	fronts := make(map[string]WeatherFront, len(ws.fronts))
+	for id, front := range ws.fronts {
+		fronts[id] = front
+	}
+	return fronts
+}
+
+// ModifiersAt returns the weather modifiers in effect at pos.
+func (ws *WeatherSystem) ModifiersAt(pos game.Position) WeatherModifiers {
+	front, ok := ws.Current(ws.RegionAt(pos))
+	if !ok {
+		return WeatherModifiers{}
+	}
+	return front.Modifiers()
+}
+
+// Update advances every region's weather to the given game time and returns
+// the regions whose conditions changed, ordered by region ID.
+func (ws *WeatherSystem) Update(ticks int64) []WeatherChange {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	regionIDs := make([]string, 0, len(ws.regions))
+	for id := range ws.regions {
+		regionIDs = append(regionIDs, id)
+	}
+	sort.Strings(regionIDs)
+
+	var changes []WeatherChange
+	for _, id := range regionIDs {
+		previous, exists := ws.fronts[id]
+		if exists && ticks >= previous.StartTick && ticks < previous.EndTick {
+			continue
+		}
+
+		front := ws.generateFront(id, ws.regions[id], ticks)
+		ws.fronts[id] = front
+		if !exists || front.Condition != previous.Condition {
+			changes = append(changes, WeatherChange{Previous: previous.Condition, Front: front})
+		}
+	}
+
+	return changes
+}
+
+// generateFront rolls the front covering ticks for a region. Callers must
+// hold ws.mu.
+func (ws *WeatherSystem) generateFront(regionID string, climate pcg.ClimateType, ticks int64) WeatherFront {
+	window := ticks / WeatherFrontTicks
+	seed := ws.seeds.DeriveContextSeed(pcg.ContentTypeWeather, fmt.Sprintf("%s:%d", regionID, window))
+	rng := pcg.NewRNG(seed, "weather")
+
+	weights, ok := climateWeather[climate]
+	if !ok {
+		weights = climateWeather[pcg.ClimateTemperate]
+	}
+
+	total := 0
+	for _, condition := range weatherConditions {
+		total += weights[condition]
+	}
+
+	condition := WeatherClear
+	roll := rng.Intn(total)
+	for _, c := range weatherConditions {
+		if roll < weights[c] {
+			condition = c
+			break
+		}
+		roll -= weights[c]
+	}
+
+	return WeatherFront{
+		Region:    regionID,
+		Condition: condition,
+		Intensity: 0.25 + rng.Float64()*0.75,
+		StartTick: window * WeatherFrontTicks,
+		EndTick:   (window + 1) * WeatherFrontTicks,
+	}
+}
+
+// Advance moves game time forward by the real time elapsed since the last
+// tick, scaled by TimeScale, and returns the new game tick count.
+func (t *TimeManager) Advance(now time.Time) int64 {
+	elapsed := now.Sub(t.LastTick)
+	if elapsed > 0 {
+		t.CurrentTime.GameTicks += int64(elapsed.Seconds() * t.TimeScale)
+		t.CurrentTime.RealTime = now
+		t.LastTick = now
+	}
+	return t.CurrentTime.GameTicks
+}
+
+// Skip moves game time forward by ticks without waiting for real time to
+// pass, as when the party rests, and returns the new game tick count.
+func (t *TimeManager) Skip(ticks int64) int64 {
+	t.CurrentTime.GameTicks += ticks
+	return t.CurrentTime.GameTicks
+}
+
+// TimeOfDay returns the current in-game time of day.
+func (t *TimeManager) TimeOfDay() TimeOfDay {
+	return TimeOfDayAt(t.CurrentTime.GameTicks)
+}
+
+// attachWeather creates the weather system for the server's game time. It
+// uses its own seed manager, derived from the PCG base seed, so weather
+// generation does not contend with content generation.
+func (s *RPCServer) attachWeather() {
+	var baseSeed int64
+	if s.pcgManager != nil {
+		baseSeed = s.pcgManager.GetSeedManager().GetBaseSeed()
+	}
+	s.state.TimeManager.Weather = NewWeatherSystem(pcg.NewSeedManager(baseSeed))
+}
+
+// updateWeather advances game time, burns out spent light sources, updates
+// regional weather and broadcasts a weather change event for every region
+// whose conditions changed.
+func (s *RPCServer) updateWeather() []WeatherChange {
+	weather := s.state.TimeManager.Weather
+	if weather == nil {
+		return nil
+	}
+
+	s.state.stateMu.Lock()
+	ticks := s.state.TimeManager.Advance(time.Now())
+	s.state.stateMu.Unlock()
+	s.burnOutLights()
+
+	changes := weather.Update(ticks)
+	for _, change := range changes {
+		logrus.WithFields(logrus.Fields{
+			"function":  "updateWeather",
+			"region":    change.Front.Region,
+			"previous":  change.Previous,
+			"condition": change.Front.Condition,
+		}).Info("weather changed")
+
+		s.eventSys.Emit(game.GameEvent{
+			Type:     EventWeatherChange,
+			SourceID: "weather",
+			TargetID: change.Front.Region,
+			Data: map[string]interface{}{
+				"region":      change.Front.Region,
+				"previous":    change.Previous,
+				"condition":   change.Front.Condition,
+				"intensity":   change.Front.Intensity,
+				"modifiers":   change.Front.Modifiers(),
+				"time_of_day": TimeOfDayAt(ticks),
+				"until_tick":  change.Front.EndTick,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+
+	return changes
+}
+
+// startWeatherUpdates periodically advances the weather, runs the scheduled
+// events that have come due and tracks quest deadlines until the server
+// shuts down.
+func (s *RPCServer) startWeatherUpdates() {
+	ticker := time.NewTicker(weatherUpdateInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.updateWeather()
+				s.runScheduledEvents()
+				s.checkQuestDeadlines()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// weatherModifiersFor returns the weather modifiers at obj's position.
+func (s *RPCServer) weatherModifiersFor(obj game.GameObject) WeatherModifiers {
+	weather := s.state.TimeManager.Weather
+	if weather == nil || obj == nil {
+		return WeatherModifiers{}
+	}
+	return weather.ModifiersAt(obj.GetPosition())
+}
+
+// movementCost returns the action point cost for player to move, including
+// weather penalties.
+func (s *RPCServer) movementCost(player *game.Player) int {
+	return game.ActionCostMove + s.weatherModifiersFor(player).MovementCost
+}
+
+// isRangedWeapon reports whether weapon is a ranged weapon.
+func isRangedWeapon(weapon *game.Item) bool {
+	if weapon == nil {
+		return false
+	}
+	for _, property := range weapon.Properties {
+		if property == "ranged" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeOfDayAt(t *testing.T) {
+	tests := []struct {
+		hour int64
+		want TimeOfDay
+	}{
+		{0, TimeOfDayNight},
+		{5, TimeOfDayDawn},
+		{12, TimeOfDayDay},
+		{18, TimeOfDayDusk},
+		{22, TimeOfDayNight},
+		{24 + 6, TimeOfDayDawn},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, TimeOfDayAt(tt.hour*TicksPerHour), "hour %d", tt.hour)
+	}
+}
+
+func TestWeatherSystemIsDeterministic(t *testing.T) {
+	a := NewWeatherSystem(pcg.NewSeedManager(42))
+	b := NewWeatherSystem(pcg.NewSeedManager(42))
+	a.AddRegion("north", pcg.ClimateArctic)
+	b.AddRegion("north", pcg.ClimateArctic)
+
+	for window := int64(0); window < 20; window++ {
+		ticks := window * WeatherFrontTicks
+		assert.Equal(t, a.Update(ticks), b.Update(ticks))
+		assert.Equal(t, a.Fronts(), b.Fronts())
+	}
+}
+
+func TestWeatherSystemUpdate(t *testing.T) {
+	ws := NewWeatherSystem(pcg.NewSeedManager(7))
+
+	changes := ws.Update(0)
+	require.Len(t, changes, 1, "first update generates weather for every region")
+	assert.Equal(t, DefaultWeatherRegion, changes[0].Front.Region)
+	assert.Equal(t, WeatherCondition(""), changes[0].Previous)
+
+	front, ok := ws.Current(DefaultWeatherRegion)
+	require.True(t, ok)
+	assert.Equal(t, int64(0), front.StartTick)
+	assert.Equal(t, WeatherFrontTicks, front.EndTick)
+	assert.GreaterOrEqual(t, front.Intensity, 0.25)
+	assert.LessOrEqual(t, front.Intensity, 1.0)
+
+	assert.Empty(t, ws.Update(WeatherFrontTicks-1), "weather holds until the front ends")
+}
+
+func TestWeatherFollowsClimate(t *testing.T) {
+	ws := NewWeatherSystem(pcg.NewSeedManager(99))
+	ws.AddRegion("desert", pcg.ClimateArid)
+	ws.AddRegion("tundra", pcg.ClimateArctic)
+
+	seen := map[string]map[WeatherCondition]bool{"desert": {}, "tundra": {}}
+	for window := int64(0); window < 200; window++ {
+		ws.Update(window * WeatherFrontTicks)
+		for region := range seen {
+			front, _ := ws.Current(region)
+			seen[region][front.Condition] = true
+		}
+	}
+
+	assert.False(t, seen["desert"][WeatherSnow], "arid regions never see snow")
+	assert.False(t, seen["desert"][WeatherRain], "arid regions never see rain")
+	assert.True(t, seen["tundra"][WeatherSnow], "arctic regions see snow")
+}
+
+func TestWeatherFrontModifiers(t *testing.T) {
+	assert.Equal(t, WeatherModifiers{}, WeatherFront{Condition: WeatherClear}.Modifiers())
+	assert.Equal(t, 2, WeatherFront{Condition: WeatherRain}.Modifiers().RangedAttackPenalty)
+	assert.Equal(t, 1, WeatherFront{Condition: WeatherSnow}.Modifiers().MovementCost)
+}
+
+func TestTimeManagerAdvance(t *testing.T) {
+	tm := NewTimeManager()
+	tm.TimeScale = 2.0
+	start := tm.LastTick
+
+	assert.Equal(t, int64(20), tm.Advance(start.Add(10*time.Second)))
+	assert.Equal(t, int64(20), tm.Advance(start), "time never runs backwards")
+}
+
+func TestUpdateWeatherBroadcastsChanges(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	require.NotNil(t, server.state.TimeManager.Weather)
+
+	events := make(chan game.GameEvent, 4)
+	server.eventSys.Subscribe(EventWeatherChange, func(event game.GameEvent) {
+		events <- event
+	})
+
+	changes := server.updateWeather()
+	require.Len(t, changes, 1)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, DefaultWeatherRegion, event.TargetID)
+		assert.Equal(t, changes[0].Front.Condition, event.Data["condition"])
+		assert.Contains(t, event.Data, "modifiers")
+		assert.Contains(t, event.Data, "time_of_day")
+	case <-time.After(time.Second):
+		t.Fatal("expected a weather change event")
+	}
+
+	serialized := server.state.TimeManager.Serialize()
+	assert.Contains(t, serialized["weather"], DefaultWeatherRegion)
+}
+
+func TestWeatherGameplayModifiers(t *testing.T) {
+	server, attacker, defender := newReactionTestServer(t)
+	weather := server.state.TimeManager.Weather
+	weather.mu.Lock()
+	weather.fronts[DefaultWeatherRegion] = WeatherFront{Region: DefaultWeatherRegion, Condition: WeatherSnow}
+	weather.mu.Unlock()
+
+	assert.Equal(t, game.ActionCostMove+1, server.movementCost(attacker))
+
+	attacker.Inventory = append(attacker.Inventory,
+		game.Item{ID: "bow", Name: "Bow", Type: "weapon", Damage: "5", Properties: []string{"ranged"}},
+		game.Item{ID: "sword", Name: "Sword", Type: "weapon", Damage: "5"},
+	)
+
+	result, err := server.processCombatAction(attacker, defender.ID, "bow")
+	require.NoError(t, err)
+	// 5 base + 2 strength, less 1 for snow
+	assert.Equal(t, 6, result.(map[string]interface{})["damage"])
+
+	result, err = server.processCombatAction(attacker, defender.ID, "sword")
+	require.NoError(t, err)
+	assert.Equal(t, 7, result.(map[string]interface{})["damage"], "melee attacks ignore weather")
+}
@@ -0,0 +1,502 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/retry"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookEvent names a game event that can be delivered to webhooks.
+type WebhookEvent string
+
+const (
+	// WebhookBossDefeated fires when a character tagged "boss" dies
+	WebhookBossDefeated WebhookEvent = "boss_defeated"
+	// WebhookPlayerDied fires when a player character dies
+	WebhookPlayerDied WebhookEvent = "player_died"
+	// WebhookWorldGenerated fires when terrain, levels or an integrated
+	// world area are generated
+	WebhookWorldGenerated WebhookEvent = "world_generated"
+	// WebhookQualityDropped fires when the PCG quality grade drops below
+	// the configured threshold
+	WebhookQualityDropped WebhookEvent = "quality_grade_dropped"
+)
+
+// webhookEvents lists every supported webhook event.
+var webhookEvents = []WebhookEvent{
+	WebhookBossDefeated,
+	WebhookPlayerDied,
+	WebhookWorldGenerated,
+	WebhookQualityDropped,
+}
+
+const (
+	// WebhookSignatureHeader carries "sha256=" followed by the hex encoded
+	// HMAC-SHA256 of the request body, keyed with the webhook secret
+	WebhookSignatureHeader = "X-Goldbox-Signature"
+	// WebhookEventHeader carries the webhook event name
+	WebhookEventHeader = "X-Goldbox-Event"
+	// WebhookDeliveryHeader carries the unique delivery ID, which is stable
+	// across retries so receivers can deduplicate
+	WebhookDeliveryHeader = "X-Goldbox-Delivery"
+
+	// webhookQueueSize bounds the number of pending deliveries
+	webhookQueueSize = 256
+	// webhookWorkers is the number of concurrent delivery workers
+	webhookWorkers = 4
+	// bossTag marks characters whose death fires a boss_defeated webhook
+	bossTag = "boss"
+)
+
+// qualityGradeRank orders PCG quality grades from best to worst.
+var qualityGradeRank = map[string]int{"A": 0, "B": 1, "C": 2, "D": 3, "F": 4}
+
+// WebhookPayload is the JSON body POSTed to webhook endpoints.
+type WebhookPayload struct {
+	ID        string                 `json:"id"`
+	Event     WebhookEvent           `json:"event"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// WebhookConfig configures a WebhookDispatcher.
+type WebhookConfig struct {
+	URLs         []string          // Endpoints receiving every selected event
+	Secret       string            // HMAC-SHA256 signing key; empty sends unsigned payloads
+	Events       []string          // Selected events; empty selects all
+	QualityGrade string            // Lowest acceptable PCG quality grade
+	Timeout      time.Duration     // Timeout of a single delivery attempt
+	Retry        retry.RetryConfig // Retry policy for failed deliveries
+}
+
+// WebhookStats holds delivery counters for a single webhook event.
+type WebhookStats struct {
+	Delivered uint64 `json:"delivered"` // Deliveries acknowledged with a 2xx response
+	Failed    uint64 `json:"failed"`    // Deliveries that exhausted their retries
+	Dropped   uint64 `json:"dropped"`   // Deliveries discarded because the queue was full
+	Attempts  uint64 `json:"attempts"`  // HTTP requests made, including retries
+}
+
+// webhookDelivery is a queued payload for a single endpoint.
+type webhookDelivery struct {
+	url   string
+	id    string
+	event WebhookEvent
+	body  []byte
+}
+
+// WebhookDispatcher delivers selected game events to external HTTP
+// endpoints such as Discord webhooks or automation services. Deliveries are
+// queued and sent by background workers so game handlers never block on
+// network I/O; failed deliveries are retried with exponential backoff.
+type WebhookDispatcher struct {
+	config WebhookConfig
+	events map[WebhookEvent]bool
+	client *http.Client
+	queue  chan webhookDelivery
+
+	mu        sync.Mutex
+	retrier   *retry.Retrier
+	stats     map[WebhookEvent]*WebhookStats
+	lastGrade string
+}
+
+// NewWebhookDispatcher creates a dispatcher for cfg. It returns an error
+// when cfg selects an unknown event or quality grade.
+func NewWebhookDispatcher(cfg WebhookConfig) (*WebhookDispatcher, error) {
+	if _, ok := qualityGradeRank[cfg.QualityGrade]; !ok {
+		return nil, fmt.Errorf("unknown quality grade: %q", cfg.QualityGrade)
+	}
+
+	events := make(map[WebhookEvent]bool)
+	if len(cfg.Events) == 0 {
+		for _, event := range webhookEvents {
+			events[event] = true
+		}
+	}
+	for _, name := range cfg.Events {
+		event := WebhookEvent(name)
+		if !isWebhookEvent(event) {
+			return nil, fmt.Errorf("unknown webhook event: %q", name)
+		}
+		events[event] = true
+	}
+
+	stats := make(map[WebhookEvent]*WebhookStats, len(webhookEvents))
+	for _, event := range webhookEvents {
+		stats[event] = &WebhookStats{}
+	}
+
+	return &WebhookDispatcher{
+		config:  cfg,
+		events:  events,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		retrier: retry.NewRetrier(cfg.Retry),
+		queue:   make(chan webhookDelivery, webhookQueueSize),
+		stats:   stats,
+	}, nil
+}
+
+// newWebhookConfig builds a WebhookConfig from the server configuration.
+// Deliveries use the server retry policy, or a single attempt when retries
+// are disabled.
+func newWebhookConfig(cfg *config.Config) WebhookConfig {
+	retryConfig := cfg.GetRetryConfig()
+	if !cfg.RetryEnabled {
+		retryConfig = retry.DefaultRetryConfig()
+		retryConfig.MaxAttempts = 1
+	}
+
+	return WebhookConfig{
+		URLs:         cfg.WebhookURLs,
+		Secret:       cfg.WebhookSecret,
+		Events:       cfg.WebhookEvents,
+		QualityGrade: cfg.WebhookQualityGrade,
+		Timeout:      cfg.WebhookTimeout,
+		Retry:        retryConfig,
+	}
+}
+
+// isWebhookEvent reports whether event is a supported webhook event.
+func isWebhookEvent(event WebhookEvent) bool {
+	for _, known := range webhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// SignWebhookPayload returns the signature header value for body: "sha256="
+// followed by the hex encoded HMAC-SHA256 of body keyed with secret.
+// Receivers recompute it to verify that a payload came from this server.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Start launches the delivery workers. They stop when done is closed;
+// in-flight retries are cancelled.
+func (d *WebhookDispatcher) Start(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	for i := 0; i < webhookWorkers; i++ {
+		go func() {
+			for {
+				select {
+				case delivery := <-d.queue:
+					d.deliver(ctx, delivery)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Enabled reports whether event is selected for delivery.
+func (d *WebhookDispatcher) Enabled(event WebhookEvent) bool {
+	return len(d.config.URLs) > 0 && d.events[event]
+}
+
+// Dispatch queues event with data for delivery to every configured URL.
+// Events that are not selected are ignored. Dispatch never blocks: when the
+// queue is full the delivery is dropped and counted.
+func (d *WebhookDispatcher) Dispatch(event WebhookEvent, data map[string]interface{}) error {
+	if !d.Enabled(event) {
+		return nil
+	}
+
+	payload := WebhookPayload{
+		ID:        uuid.New().String(),
+		Event:     event,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	for _, url := range d.config.URLs {
+		select {
+		case d.queue <- webhookDelivery{url: url, id: payload.ID, event: event, body: body}:
+		default:
+			d.record(event, func(stats *WebhookStats) { stats.Dropped++ })
+			logrus.WithFields(logrus.Fields{
+				"function": "Dispatch",
+				"event":    event,
+				"url":      url,
+			}).Warn("webhook queue full, dropping delivery")
+		}
+	}
+	return nil
+}
+
+// ObserveQualityGrade compares a PCG quality grade with the configured
+// threshold and dispatches a quality_grade_dropped webhook when the grade
+// falls below it. Only the transition is reported, so a grade that stays
+// low does not fire repeatedly. It reports whether a webhook was dispatched.
+func (d *WebhookDispatcher) ObserveQualityGrade(grade string, score float64) bool {
+	rank, ok := qualityGradeRank[grade]
+	if !ok {
+		return false
+	}
+	threshold := qualityGradeRank[d.config.QualityGrade]
+
+	d.mu.Lock()
+	previous := d.lastGrade
+	d.lastGrade = grade
+	d.mu.Unlock()
+
+	if rank <= threshold {
+		return false
+	}
+	if previousRank, seen := qualityGradeRank[previous]; seen && previousRank > threshold {
+		return false
+	}
+
+	data := map[string]interface{}{
+		"grade":         grade,
+		"previous":      previous,
+		"threshold":     d.config.QualityGrade,
+		"overall_score": score,
+	}
+	if err := d.Dispatch(WebhookQualityDropped, data); err != nil {
+		logrus.WithError(err).Error("failed to dispatch quality webhook")
+		return false
+	}
+	return d.Enabled(WebhookQualityDropped)
+}
+
+// Stats returns a snapshot of the delivery counters for every event.
+func (d *WebhookDispatcher) Stats() map[WebhookEvent]WebhookStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	snapshot := make(map[WebhookEvent]WebhookStats, len(d.stats))
+	for event, stats := range d.stats {
+		snapshot[event] = *stats
+	}
+	return snapshot
+}
+
+// record applies update to the counters of event.
+func (d *WebhookDispatcher) record(event WebhookEvent, update func(*WebhookStats)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	update(d.stats[event])
+}
+
+// SetRetry replaces the retry policy of deliveries started afterwards.
+func (d *WebhookDispatcher) SetRetry(cfg retry.RetryConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.retrier = retry.NewRetrier(cfg)
+}
+
+// deliver POSTs a queued payload, retrying failed attempts. Any non-2xx
+// response counts as a failed attempt.
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery webhookDelivery) {
+	d.mu.Lock()
+	retrier := d.retrier
+	d.mu.Unlock()
+
+	err := retrier.Execute(ctx, func(ctx context.Context) error {
+		d.record(delivery.event, func(stats *WebhookStats) { stats.Attempts++ })
+		return d.post(ctx, delivery)
+	})
+
+	logger := logrus.WithFields(logrus.Fields{
+		"function": "deliver",
+		"event":    delivery.event,
+		"delivery": delivery.id,
+		"url":      delivery.url,
+	})
+	if err != nil {
+		d.record(delivery.event, func(stats *WebhookStats) { stats.Failed++ })
+		logger.WithError(err).Warn("webhook delivery failed")
+		return
+	}
+	d.record(delivery.event, func(stats *WebhookStats) { stats.Delivered++ })
+	logger.Debug("webhook delivered")
+}
+
+// post performs a single delivery attempt.
+func (d *WebhookDispatcher) post(ctx context.Context, delivery webhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(delivery.event))
+	req.Header.Set(WebhookDeliveryHeader, delivery.id)
+	if d.config.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(d.config.Secret, delivery.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// attachWebhooks creates the webhook dispatcher when webhook URLs are
+// configured and subscribes it to the events it reports.
+func (s *RPCServer) attachWebhooks() error {
+	if len(s.config.WebhookURLs) == 0 {
+		return nil
+	}
+
+	dispatcher, err := NewWebhookDispatcher(newWebhookConfig(s.config))
+	if err != nil {
+		return fmt.Errorf("failed to configure webhooks: %w", err)
+	}
+	s.webhooks = dispatcher
+	if s.metrics != nil {
+		if err := s.metrics.RegisterWebhookMetrics(dispatcher); err != nil {
+			logrus.WithError(err).Warn("failed to register webhook metrics")
+		}
+	}
+
+	s.eventSys.Subscribe(pcg.EventPCGContentIntegrated, func(event game.GameEvent) {
+		s.webhooks.Dispatch(WebhookWorldGenerated, map[string]interface{}{
+			"source":      "integration",
+			"location_id": event.TargetID,
+			"level_ids":   event.Data["level_ids"],
+			"item_ids":    event.Data["item_ids"],
+		})
+	})
+	s.eventSys.Subscribe(pcg.EventPCGQualityAssessment, func(event game.GameEvent) {
+		if report, ok := event.Data["quality_report"].(*pcg.QualityReport); ok {
+			s.webhooks.ObserveQualityGrade(report.QualityGrade, report.OverallScore)
+		}
+	})
+	if s.pcgManager != nil && dispatcher.Enabled(WebhookQualityDropped) {
+		s.pcgManager.AddGenerationObserver(func(pcg.ContentType, time.Duration, error) {
+			report := s.pcgManager.GenerateQualityReport()
+			s.webhooks.ObserveQualityGrade(report.QualityGrade, report.OverallScore)
+		})
+	}
+
+	s.webhooks.Start(s.done)
+	return nil
+}
+
+// notifyDeathWebhook reports the death of target: a player_died webhook
+// for players and a boss_defeated webhook for characters tagged "boss".
+func (s *RPCServer) notifyDeathWebhook(target game.GameObject) {
+	if s.webhooks == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"character_id": target.GetID(),
+		"name":         target.GetName(),
+		"position":     target.GetPosition(),
+	}
+	if _, isPlayer := target.(*game.Player); isPlayer {
+		s.webhooks.Dispatch(WebhookPlayerDied, data)
+		return
+	}
+	for _, tag := range target.GetTags() {
+		if tag == bossTag {
+			s.webhooks.Dispatch(WebhookBossDefeated, data)
+			return
+		}
+	}
+}
+
+// notifyWorldGenerated reports generated terrain or levels for locationID.
+func (s *RPCServer) notifyWorldGenerated(contentType pcg.ContentType, locationID string) {
+	if s.webhooks == nil {
+		return
+	}
+	if contentType != pcg.ContentTypeTerrain && contentType != pcg.ContentTypeLevels {
+		return
+	}
+	s.webhooks.Dispatch(WebhookWorldGenerated, map[string]interface{}{
+		"source":       "generation",
+		"content_type": string(contentType),
+		"location_id":  locationID,
+	})
+}
+
+// webhookCollector exports webhook delivery counters, read from the
+// dispatcher at scrape time.
+type webhookCollector struct {
+	dispatcher *WebhookDispatcher
+	deliveries *prometheus.Desc
+	attempts   *prometheus.Desc
+}
+
+// newWebhookCollector creates a collector for dispatcher.
+func newWebhookCollector(dispatcher *WebhookDispatcher) *webhookCollector {
+	return &webhookCollector{
+		dispatcher: dispatcher,
+		deliveries: prometheus.NewDesc(
+			"goldbox_webhook_deliveries_total",
+			"Total number of webhook deliveries by event and result",
+			[]string{"event", "result"}, nil,
+		),
+		attempts: prometheus.NewDesc(
+			"goldbox_webhook_delivery_attempts_total",
+			"Total number of webhook HTTP requests by event, including retries",
+			[]string{"event"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *webhookCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.deliveries
+	ch <- c.attempts
+}
+
+// Collect implements prometheus.Collector.
+func (c *webhookCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.dispatcher.Stats()
+	for _, event := range webhookEvents {
+		label := string(event)
+		entry := stats[event]
+		ch <- prometheus.MustNewConstMetric(c.deliveries, prometheus.CounterValue, float64(entry.Delivered), label, "delivered")
+		ch <- prometheus.MustNewConstMetric(c.deliveries, prometheus.CounterValue, float64(entry.Failed), label, "failed")
+		ch <- prometheus.MustNewConstMetric(c.deliveries, prometheus.CounterValue, float64(entry.Dropped), label, "dropped")
+		ch <- prometheus.MustNewConstMetric(c.attempts, prometheus.CounterValue, float64(entry.Attempts), label)
+	}
+}
+
+// RegisterWebhookMetrics exports webhook delivery counters on the metrics
+// endpoint.
+func (m *Metrics) RegisterWebhookMetrics(dispatcher *WebhookDispatcher) error {
+	return m.registry.Register(newWebhookCollector(dispatcher))
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+	"goldbox-rpg/pkg/retry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWebhookDispatcher creates a dispatcher with fast retries
+func newTestWebhookDispatcher(t *testing.T, urls []string, events ...string) *WebhookDispatcher {
+	t.Helper()
+	dispatcher, err := NewWebhookDispatcher(WebhookConfig{
+		URLs:         urls,
+		Secret:       "s3cret",
+		Events:       events,
+		QualityGrade: "C",
+		Timeout:      time.Second,
+		Retry: retry.RetryConfig{
+			MaxAttempts:       3,
+			InitialDelay:      time.Millisecond,
+			MaxDelay:          5 * time.Millisecond,
+			BackoffMultiplier: 2.0,
+		},
+	})
+	require.NoError(t, err)
+	return dispatcher
+}
+
+// nextQueuedPayload decodes the next delivery queued by dispatcher
+func nextQueuedPayload(t *testing.T, dispatcher *WebhookDispatcher) WebhookPayload {
+	t.Helper()
+	select {
+	case delivery := <-dispatcher.queue:
+		var payload WebhookPayload
+		require.NoError(t, json.Unmarshal(delivery.body, &payload))
+		return payload
+	default:
+		t.Fatal("expected a queued webhook delivery")
+		return WebhookPayload{}
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// RFC 4231 test case 2
+	assert.Equal(t,
+		"sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		SignWebhookPayload("Jefe", []byte("what do ya want for nothing?")))
+}
+
+func TestWebhookDispatcher_DeliversSignedPayload(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer endpoint.Close()
+
+	dispatcher := newTestWebhookDispatcher(t, []string{endpoint.URL})
+	done := make(chan struct{})
+	defer close(done)
+	dispatcher.Start(done)
+
+	require.NoError(t, dispatcher.Dispatch(WebhookPlayerDied, map[string]interface{}{"character_id": "hero"}))
+
+	var req *http.Request
+	select {
+	case req = <-received:
+	case <-time.After(time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	body := <-bodies
+
+	assert.Equal(t, SignWebhookPayload("s3cret", body), req.Header.Get(WebhookSignatureHeader))
+	assert.Equal(t, "player_died", req.Header.Get(WebhookEventHeader))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, WebhookPlayerDied, payload.Event)
+	assert.Equal(t, req.Header.Get(WebhookDeliveryHeader), payload.ID)
+	assert.Equal(t, "hero", payload.Data["character_id"])
+
+	assert.Eventually(t, func() bool {
+		return dispatcher.Stats()[WebhookPlayerDied].Delivered == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWebhookDispatcher_RetriesFailedDeliveries(t *testing.T) {
+	var calls int32
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer endpoint.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	dispatcher := newTestWebhookDispatcher(t, []string{endpoint.URL, failing.URL})
+	done := make(chan struct{})
+	defer close(done)
+	dispatcher.Start(done)
+
+	require.NoError(t, dispatcher.Dispatch(WebhookBossDefeated, map[string]interface{}{"character_id": "dragon"}))
+
+	assert.Eventually(t, func() bool {
+		stats := dispatcher.Stats()[WebhookBossDefeated]
+		return stats.Delivered == 1 && stats.Failed == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// Three attempts against each endpoint
+	assert.Equal(t, uint64(6), dispatcher.Stats()[WebhookBossDefeated].Attempts)
+}
+
+func TestWebhookDispatcher_EventSelection(t *testing.T) {
+	dispatcher := newTestWebhookDispatcher(t, []string{"http://localhost/hook"}, "player_died")
+	assert.True(t, dispatcher.Enabled(WebhookPlayerDied))
+	assert.False(t, dispatcher.Enabled(WebhookBossDefeated))
+
+	require.NoError(t, dispatcher.Dispatch(WebhookBossDefeated, nil))
+	assert.Empty(t, dispatcher.queue, "unselected events are not queued")
+
+	_, err := NewWebhookDispatcher(WebhookConfig{QualityGrade: "C", Events: []string{"server_exploded"}})
+	assert.Error(t, err)
+	_, err = NewWebhookDispatcher(WebhookConfig{QualityGrade: "E"})
+	assert.Error(t, err)
+}
+
+func TestWebhookDispatcher_ObserveQualityGrade(t *testing.T) {
+	dispatcher := newTestWebhookDispatcher(t, []string{"http://localhost/hook"})
+
+	assert.False(t, dispatcher.ObserveQualityGrade("B", 0.85))
+	assert.False(t, dispatcher.ObserveQualityGrade("C", 0.72), "the threshold grade itself is acceptable")
+	assert.True(t, dispatcher.ObserveQualityGrade("D", 0.65))
+	assert.False(t, dispatcher.ObserveQualityGrade("F", 0.4), "only the drop below the threshold is reported")
+	assert.False(t, dispatcher.ObserveQualityGrade("A", 0.95))
+	assert.True(t, dispatcher.ObserveQualityGrade("F", 0.5))
+
+	payload := nextQueuedPayload(t, dispatcher)
+	assert.Equal(t, WebhookQualityDropped, payload.Event)
+	assert.Equal(t, "D", payload.Data["grade"])
+	assert.Equal(t, "C", payload.Data["previous"])
+	assert.Equal(t, "C", payload.Data["threshold"])
+}
+
+func TestNotifyDeathWebhook(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	dispatcher := newTestWebhookDispatcher(t, []string{"http://localhost/hook"})
+	server.webhooks = dispatcher
+
+	player := &game.Player{Character: game.Character{ID: "hero", Name: "Hero", HP: 5}}
+	require.NoError(t, server.applyDamage(player, 10))
+	payload := nextQueuedPayload(t, dispatcher)
+	assert.Equal(t, WebhookPlayerDied, payload.Event)
+	assert.Equal(t, "hero", payload.Data["character_id"])
+
+	boss := &game.Character{ID: "dragon", Name: "Red Dragon", HP: 5}
+	boss.AddTag(bossTag)
+	require.NoError(t, server.applyDamage(boss, 10))
+	payload = nextQueuedPayload(t, dispatcher)
+	assert.Equal(t, WebhookBossDefeated, payload.Event)
+	assert.Equal(t, "Red Dragon", payload.Data["name"])
+
+	goblin := &game.Character{ID: "goblin", Name: "Goblin", HP: 5}
+	require.NoError(t, server.applyDamage(goblin, 10))
+	assert.Empty(t, dispatcher.queue, "ordinary monsters do not trigger webhooks")
+}
+
+func TestNotifyWorldGenerated(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	dispatcher := newTestWebhookDispatcher(t, []string{"http://localhost/hook"})
+	server.webhooks = dispatcher
+
+	server.notifyWorldGenerated(pcg.ContentTypeItems, "town")
+	assert.Empty(t, dispatcher.queue, "item generation is not a world change")
+
+	server.notifyWorldGenerated(pcg.ContentTypeTerrain, "forest")
+	payload := nextQueuedPayload(t, dispatcher)
+	assert.Equal(t, WebhookWorldGenerated, payload.Event)
+	assert.Equal(t, "forest", payload.Data["location_id"])
+	assert.Equal(t, "terrain", payload.Data["content_type"])
+}
+
+func TestMetrics_RegisterWebhookMetrics(t *testing.T) {
+	dispatcher := newTestWebhookDispatcher(t, []string{"http://localhost/hook"})
+	dispatcher.record(WebhookPlayerDied, func(stats *WebhookStats) {
+		stats.Delivered = 2
+		stats.Attempts = 3
+	})
+
+	metrics := NewMetrics()
+	require.NoError(t, metrics.RegisterWebhookMetrics(dispatcher))
+
+	body := scrapeMetrics(t, metrics)
+	assert.Contains(t, body, `goldbox_webhook_deliveries_total{event="player_died",result="delivered"} 2`)
+	assert.Contains(t, body, `goldbox_webhook_deliveries_total{event="boss_defeated",result="failed"} 0`)
+	assert.Contains(t, body, `goldbox_webhook_delivery_attempts_total{event="player_died"} 3`)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -9,9 +10,11 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/logging"
 
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
@@ -133,6 +136,8 @@ func (s *RPCServer) isOriginAllowed(origin string, allowedOrigins []string) bool
 //   - ReadBufferSize: 1024 bytes for incoming WebSocket frames
 //   - WriteBufferSize: 1024 bytes for outgoing WebSocket frames
 //   - CheckOrigin: Validates request origin against allowed origins list
+//   - Subprotocols: The message encodings clients may choose, see wsSubprotocols
+//   - EnableCompression: Offers permessage-deflate unless disabled in the configuration
 //
 // Security: The CheckOrigin function prevents cross-site WebSocket hijacking by validating
 // request origins against the configured allowed origins list.
@@ -140,9 +145,12 @@ func (s *RPCServer) isOriginAllowed(origin string, allowedOrigins []string) bool
 // Returns:
 //   - *websocket.Upgrader: Configured upgrader instance for WebSocket connections
 func (s *RPCServer) upgrader() *websocket.Upgrader {
+	compress, _, _ := s.wsCompression()
 	upgrader := websocket.Upgrader{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
+		ReadBufferSize:    1024,
+		WriteBufferSize:   1024,
+		Subprotocols:      wsSubprotocols,
+		EnableCompression: compress,
 		CheckOrigin: func(r *http.Request) bool {
 			origin := r.Header.Get("Origin")
 
@@ -238,15 +246,22 @@ func NewResponse(id, result interface{}) interface{} {
 //   - err: Error object containing failure details
 //
 // Returns:
-//   - interface{}: JSON-RPC 2.0 formatted error response object with code -32000
+//   - interface{}: JSON-RPC 2.0 formatted error response object. Errors
+//     from the error catalog, or any other JSONRPCError, keep their code and
+//     data; other errors are sent with code -32000
 func NewErrorResponse(id interface{}, err error) interface{} {
+	rpcErr := toJSONRPCError(err, -32000)
+	errorObj := map[string]interface{}{
+		"code":    rpcErr.Code,
+		"message": rpcErr.Message,
+	}
+	if rpcErr.Data != nil {
+		errorObj["data"] = rpcErr.Data
+	}
 	return map[string]interface{}{
 		"jsonrpc": "2.0",
-		"error": map[string]interface{}{
-			"code":    -32000,
-			"message": err.Error(),
-		},
-		"id": id,
+		"error":   errorObj,
+		"id":      id,
 	}
 }
 
@@ -260,6 +275,9 @@ func NewErrorResponse(id interface{}, err error) interface{} {
 // 4. Spawns goroutines for message handling (send/receive)
 // 5. Manages connection lifecycle and cleanup
 //
+// When the connection drops, the session is marked disconnected but kept
+// until it times out, so the client can resume it with reconnectSession.
+//
 // Parameters:
 //   - w: HTTP response writer for the upgrade
 //   - r: HTTP request containing session context
@@ -269,29 +287,60 @@ func NewErrorResponse(id interface{}, err error) interface{} {
 //   - Session state synchronization
 //   - Bidirectional message queuing
 func (s *RPCServer) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	logger := logrus.WithField("function", "HandleWebSocket")
 	session := r.Context().Value(sessionKey).(*PlayerSession)
 	if session == nil {
 		logrus.Error("no session in context")
 		return
 	}
+	logger := rpcLog.WithContext(r.Context()).WithFields(logrus.Fields{
+		"function":             "HandleWebSocket",
+		logging.FieldSessionID: session.SessionID,
+	})
 
 	conn, err := s.upgradeConnection(w, r)
 	if err != nil {
 		return
 	}
 	defer conn.Close()
+	defer s.connWriters.Delete(conn)
 
 	if err := s.sendSessionConfirmation(conn, session); err != nil {
 		return
 	}
 
-	session.WSConn = conn
-	logrus.Info("websocket connection established")
+	s.attachConnection(session, conn)
+	defer s.detachConnection(conn)
+	logger.Info("websocket connection established")
 
 	s.handleWebSocketMessages(conn, session, logger)
 }
 
+// writeJSON writes a message to conn in the encoding negotiated for the
+// connection: JSON text, or MessagePack binary. Gorilla connections allow
+// only one concurrent writer, while RPC responses, broadcasts and progress
+// updates are written from different goroutines, so writes to the same
+// connection are serialized here. Messages smaller than the compression
+// threshold are sent uncompressed, as deflate would barely shrink them.
+func (s *RPCServer) writeJSON(conn *websocket.Conn, message interface{}) error {
+	messageType, data, err := encodeWSMessage(conn, message)
+	if err != nil {
+		return err
+	}
+	return s.writeEncoded(conn, messageType, data)
+}
+
+// writeEncoded writes a message already encoded for conn, serialized with
+// the connection's other writes like writeJSON.
+func (s *RPCServer) writeEncoded(conn *websocket.Conn, messageType int, data []byte) error {
+	_, _, minSize := s.wsCompression()
+
+	mu, _ := s.connWriters.LoadOrStore(conn, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+	conn.EnableWriteCompression(len(data) >= minSize)
+	return conn.WriteMessage(messageType, data)
+}
+
 // upgradeConnection establishes a WebSocket connection from an HTTP request.
 func (s *RPCServer) upgradeConnection(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
 	conn, err := s.upgrader().Upgrade(w, r, nil)
@@ -299,6 +348,10 @@ func (s *RPCServer) upgradeConnection(w http.ResponseWriter, r *http.Request) (*
 		logrus.WithError(err).Error("websocket upgrade failed")
 		return nil, err
 	}
+	// The level applies only if the client negotiated permessage-deflate
+	if _, level, _ := s.wsCompression(); conn.SetCompressionLevel(level) != nil {
+		logrus.WithField("level", level).Warn("invalid websocket compression level, using the default")
+	}
 	return conn, nil
 }
 
@@ -312,7 +365,7 @@ func (s *RPCServer) sendSessionConfirmation(conn *websocket.Conn, session *Playe
 		"id": 0,
 	}
 
-	if err := conn.WriteJSON(confirmationMsg); err != nil {
+	if err := s.writeJSON(conn, confirmationMsg); err != nil {
 		logrus.WithError(err).Error("failed to send session confirmation")
 		return err
 	}
@@ -323,35 +376,47 @@ func (s *RPCServer) sendSessionConfirmation(conn *websocket.Conn, session *Playe
 func (s *RPCServer) handleWebSocketMessages(conn *websocket.Conn, session *PlayerSession, logger *logrus.Entry) {
 	for {
 		var req RPCRequest
-		if err := conn.ReadJSON(&req); err != nil {
+		if err := readWSRequest(conn, &req); err != nil {
 			break
 		}
 
 		if err := s.processWebSocketRequest(conn, session, req, logger); err != nil {
 			break
 		}
+
+		// A successful reconnectSession moves conn to the resumed session
+		if RPCMethod(req.Method) == MethodReconnectSession {
+			if resumed := s.sessionForConn(conn); resumed != nil {
+				session = resumed
+			}
+		}
 	}
 }
 
 // processWebSocketRequest handles a single WebSocket RPC request.
 func (s *RPCServer) processWebSocketRequest(conn *websocket.Conn, session *PlayerSession, req RPCRequest, logger *logrus.Entry) error {
+	logger = logger.WithFields(logrus.Fields{
+		logging.FieldSessionID: session.SessionID,
+		logging.FieldMethod:    req.Method,
+	})
 	enrichedParams := s.enrichRequestParams(req.Params, session.SessionID)
 
 	paramsJSON, err := json.Marshal(enrichedParams)
 	if err != nil {
 		logger.WithError(err).Error("failed to marshal params")
-		conn.WriteJSON(NewErrorResponse(req.ID, err))
+		s.writeJSON(conn, NewErrorResponse(req.ID, err))
 		return nil
 	}
 
-	result, err := s.handleMethod(RPCMethod(req.Method), paramsJSON)
+	ctx, correlationID := withCorrelationID(context.Background())
+	result, logger, err := s.tracedMethod(ctx, RPCMethod(req.Method), paramsJSON, "websocket", logger)
 	if err != nil {
 		logger.WithError(err).Error("RPC method execution failed")
-		conn.WriteJSON(NewErrorResponse(req.ID, err))
+		s.writeJSON(conn, correlatedResponse(NewErrorResponse(req.ID, err), correlationID))
 		return nil
 	}
 
-	if err := conn.WriteJSON(NewResponse(req.ID, result)); err != nil {
+	if err := s.writeJSON(conn, correlatedResponse(NewResponse(req.ID, result), correlationID)); err != nil {
 		logger.WithError(err).Error("failed to write response")
 		return err
 	}
@@ -422,7 +487,7 @@ func (s *RPCServer) sendWSResponse(wsConn *wsConnection, result, id interface{})
 	wsConn.mu.Lock()
 	defer wsConn.mu.Unlock()
 
-	if err := wsConn.conn.WriteJSON(response); err != nil {
+	if err := writeWSMessage(wsConn.conn, response); err != nil {
 		logger.WithError(err).Error("failed to write websocket response")
 	} else {
 		logger.Debug("websocket response sent successfully")
@@ -479,7 +544,7 @@ func (s *RPCServer) sendWSError(wsConn *wsConnection, code int, message string,
 	wsConn.mu.Lock()
 	defer wsConn.mu.Unlock()
 
-	if err := wsConn.conn.WriteJSON(response); err != nil {
+	if err := writeWSMessage(wsConn.conn, response); err != nil {
 		logger.WithError(err).Error("failed to write websocket error response")
 	} else {
 		logger.Debug("websocket error response sent successfully")
@@ -516,8 +581,9 @@ func (s *RPCServer) getSessionSafely(sessionID string) (*PlayerSession, error) {
 		return nil, ErrInvalidSession
 	}
 
-	// Additional validation while still holding the lock
-	if session.WSConn == nil {
+	// Additional validation while still holding the lock. Replay sandboxes
+	// re-run recorded calls for sessions that have no connection.
+	if session.WSConn == nil && !s.replays.isSandbox() {
 		s.mu.RUnlock()
 		return nil, ErrInvalidSession
 	}
@@ -552,6 +618,7 @@ type WebSocketBroadcaster struct {
 	eventTypes map[game.EventType]bool
 	mu         sync.RWMutex
 	active     bool
+	seq        uint64 // Last event sequence number, accessed atomically
 }
 
 // NewWebSocketBroadcaster creates and initializes a new WebSocket event broadcaster.
@@ -594,6 +661,25 @@ func (wb *WebSocketBroadcaster) Start() {
 	wb.eventTypes[game.EventItemDrop] = true
 	wb.eventTypes[EventCombatStart] = true
 	wb.eventTypes[EventCombatEnd] = true
+	wb.eventTypes[EventReactionPrompt] = true
+	wb.eventTypes[EventReactionResolved] = true
+	wb.eventTypes[EventTurnWarning] = true
+	wb.eventTypes[EventTurnSkipped] = true
+	wb.eventTypes[EventDoorChanged] = true
+	wb.eventTypes[EventFeatureUsed] = true
+	wb.eventTypes[EventCompanionChanged] = true
+	wb.eventTypes[EventLightBurnedOut] = true
+	wb.eventTypes[EventAnnotationAdded] = true
+	wb.eventTypes[EventTravelInterrupted] = true
+	wb.eventTypes[EventOpportunityAttack] = true
+	wb.eventTypes[EventTimePassed] = true
+	wb.eventTypes[EventMerchantsRestocked] = true
+	wb.eventTypes[EventQuestDeadlineWarning] = true
+	wb.eventTypes[EventWeatherChange] = true
+	wb.eventTypes[EventTensionChange] = true
+	wb.eventTypes[EventWorldEventStart] = true
+	wb.eventTypes[EventWorldEventEnd] = true
+	wb.eventTypes[game.EventQuestUpdate] = true
 
 	// Register as event handler for each type
 	for eventType := range wb.eventTypes {
@@ -638,7 +724,25 @@ func (wb *WebSocketBroadcaster) handleEvent(event game.GameEvent) {
 		"timestamp": event.Timestamp,
 	}
 
-	// Broadcast to all connected WebSocket clients
+	// Share the event with the clients of other server instances before
+	// delivering it here, since delivery adds this instance's sequence number
+	wb.server.publishSessionEvent("", wsEvent)
+	wb.deliver(wsEvent)
+}
+
+// deliver numbers an event and sends it to the WebSocket clients of this
+// instance. The sequence number lets reconnecting clients request only the
+// events they missed.
+//
+// Parameters:
+//   - wsEvent: The WebSocket event message, without a sequence number
+func (wb *WebSocketBroadcaster) deliver(wsEvent map[string]interface{}) {
+	seq := atomic.AddUint64(&wb.seq, 1)
+	wsEvent["seq"] = seq
+
+	// Keep the event for disconnected sessions, then broadcast to all
+	// connected WebSocket clients
+	wb.bufferForResume(seq, wsEvent)
 	wb.broadcastToAll(wsEvent)
 }
 
@@ -660,30 +764,12 @@ func (wb *WebSocketBroadcaster) broadcastToAll(message interface{}) {
 		return // No active WebSocket connections
 	}
 
+	// Encode the message once per wire format rather than once per client
+	payload := newWSPayload(message)
 	successCount := 0
 	for _, session := range sessions {
-		// Double-check connection is still valid before writing
-		if session.WSConn != nil {
-			// Safely attempt to write, catching any panics from invalid connections
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						logrus.WithFields(logrus.Fields{
-							"sessionID": session.SessionID,
-							"error":     fmt.Sprintf("panic during WebSocket write: %v", r),
-						}).Warn("recovered from WebSocket write panic")
-					}
-				}()
-
-				if err := session.WSConn.WriteJSON(message); err != nil {
-					logrus.WithFields(logrus.Fields{
-						"sessionID": session.SessionID,
-						"error":     err.Error(),
-					}).Warn("failed to broadcast to WebSocket client")
-				} else {
-					successCount++
-				}
-			}()
+		if wb.writePayload(session, payload) {
+			successCount++
 		}
 	}
 
@@ -694,4 +780,76 @@ func (wb *WebSocketBroadcaster) broadcastToAll(message interface{}) {
 	}).Debug("WebSocket broadcast completed")
 }
 
+// sendToSession sends a message to a single session's WebSocket connection.
+// Messages for a session whose connection is not on this instance are
+// published to the other instances sharing the session store. It returns
+// false if the message was not written here.
+//
+// Parameters:
+//   - sessionID: The session to deliver the message to
+//   - message: The message data to send (must be JSON-serializable)
+func (wb *WebSocketBroadcaster) sendToSession(sessionID string, message interface{}) bool {
+	if wb.sendToLocalSession(sessionID, message) {
+		return true
+	}
+	if event, ok := message.(map[string]interface{}); ok {
+		wb.server.publishSessionEvent(sessionID, event)
+	}
+	return false
+}
+
+// sendToLocalSession sends a message to a session's WebSocket connection on
+// this instance. It returns false if the session is unknown here, not
+// connected, or the write fails.
+func (wb *WebSocketBroadcaster) sendToLocalSession(sessionID string, message interface{}) bool {
+	wb.server.mu.RLock()
+	session, exists := wb.server.sessions[sessionID]
+	connected := exists && session != nil && session.WSConn != nil && session.Connected
+	wb.server.mu.RUnlock()
+
+	if !connected {
+		return false
+	}
+	return wb.writeToSession(session, message)
+}
+
+// writeToSession writes a message to a session's WebSocket connection,
+// recovering from panics caused by connections closed mid-write.
+func (wb *WebSocketBroadcaster) writeToSession(session *PlayerSession, message interface{}) bool {
+	return wb.writePayload(session, newWSPayload(message))
+}
+
+// writePayload writes a payload to a session's WebSocket connection,
+// recovering from panics caused by connections closed mid-write.
+func (wb *WebSocketBroadcaster) writePayload(session *PlayerSession, payload *wsPayload) (sent bool) {
+	// Double-check connection is still valid before writing
+	conn := session.WSConn
+	if conn == nil {
+		return false
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.WithFields(logrus.Fields{
+				"sessionID": session.SessionID,
+				"error":     fmt.Sprintf("panic during WebSocket write: %v", r),
+			}).Warn("recovered from WebSocket write panic")
+			sent = false
+		}
+	}()
+
+	messageType, data, err := payload.encode(conn)
+	if err == nil {
+		err = wb.server.writeEncoded(conn, messageType, data)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"sessionID": session.SessionID,
+			"error":     err.Error(),
+		}).Warn("failed to send to WebSocket client")
+		return false
+	}
+	return true
+}
+
 // Package server implements the game server and combat system functionality
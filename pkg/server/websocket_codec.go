@@ -0,0 +1,103 @@
+package server
+
+import (
+	"compress/flate"
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket subprotocols a client may request in Sec-WebSocket-Protocol to
+// choose the encoding of its messages. Clients that request neither get
+// JSON text frames, as before subprotocols were negotiated.
+const (
+	// SubprotocolJSON sends and receives JSON-RPC messages as JSON text frames
+	SubprotocolJSON = "goldbox.json"
+	// SubprotocolMsgpack sends messages as MessagePack binary frames. Text
+	// frames from the client are still read as JSON.
+	SubprotocolMsgpack = "goldbox.msgpack"
+)
+
+// wsSubprotocols lists the supported subprotocols in order of preference
+var wsSubprotocols = []string{SubprotocolMsgpack, SubprotocolJSON}
+
+// Defaults for permessage-deflate when the server has no configuration
+const (
+	defaultWebSocketCompressionLevel = flate.BestSpeed
+	defaultWebSocketCompressionMin   = 256
+)
+
+// wsCompression returns whether permessage-deflate is offered, the
+// compression level and the smallest message worth compressing.
+func (s *RPCServer) wsCompression() (enabled bool, level, minSize int) {
+	if s.config == nil {
+		return true, defaultWebSocketCompressionLevel, defaultWebSocketCompressionMin
+	}
+	return s.config.WebSocketCompression, s.config.WebSocketCompressionLevel, s.config.WebSocketCompressionMinSize
+}
+
+// encodeWSMessage encodes message in the encoding negotiated for conn,
+// returning the WebSocket message type to send it as.
+func encodeWSMessage(conn *websocket.Conn, message interface{}) (int, []byte, error) {
+	if conn.Subprotocol() == SubprotocolMsgpack {
+		data, err := marshalMsgpack(message)
+		return websocket.BinaryMessage, data, err
+	}
+	data, err := json.Marshal(message)
+	return websocket.TextMessage, data, err
+}
+
+// wsPayload is a message sent to several connections, encoded at most once
+// for each wire format. It is not safe for concurrent use.
+type wsPayload struct {
+	message interface{}
+	encoded map[bool]wsEncoding // By whether the connection speaks MessagePack
+}
+
+// wsEncoding is a message encoded for one wire format
+type wsEncoding struct {
+	messageType int
+	data        []byte
+	err         error
+}
+
+// newWSPayload creates a payload for message
+func newWSPayload(message interface{}) *wsPayload {
+	return &wsPayload{message: message, encoded: make(map[bool]wsEncoding, 2)}
+}
+
+// encode returns the payload encoded for conn, as encodeWSMessage would
+func (p *wsPayload) encode(conn *websocket.Conn) (int, []byte, error) {
+	msgpack := conn.Subprotocol() == SubprotocolMsgpack
+	enc, ok := p.encoded[msgpack]
+	if !ok {
+		enc.messageType, enc.data, enc.err = encodeWSMessage(conn, p.message)
+		p.encoded[msgpack] = enc
+	}
+	return enc.messageType, enc.data, enc.err
+}
+
+// writeWSMessage encodes and writes message to conn. The caller must hold
+// the connection's write lock.
+func writeWSMessage(conn *websocket.Conn, message interface{}) error {
+	messageType, data, err := encodeWSMessage(conn, message)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(messageType, data)
+}
+
+// readWSRequest reads the next request from conn. Binary frames on a
+// connection that negotiated MessagePack are decoded as MessagePack; every
+// other frame is JSON.
+func readWSRequest(conn *websocket.Conn, req *RPCRequest) error {
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+
+	if messageType == websocket.BinaryMessage && conn.Subprotocol() == SubprotocolMsgpack {
+		return unmarshalMsgpack(data, req)
+	}
+	return json.Unmarshal(data, req)
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/config"
+	"goldbox-rpg/pkg/game"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialCodecTestSocket opens a WebSocket to server with dialer and returns
+// the connection and the handshake response.
+func dialCodecTestSocket(t *testing.T, server *RPCServer, dialer *websocket.Dialer) (*websocket.Conn, *http.Response) {
+	t.Helper()
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := &PlayerSession{
+			SessionID:   uuid.New().String(),
+			CreatedAt:   time.Now(),
+			LastActive:  time.Now(),
+			MessageChan: make(chan []byte, MessageChanBufferSize),
+		}
+		server.setSession(session.SessionID, session)
+		server.HandleWebSocket(w, r.WithContext(context.WithValue(r.Context(), sessionKey, session)))
+	}))
+	t.Cleanup(endpoint.Close)
+
+	client, response, err := dialer.Dial("ws"+strings.TrimPrefix(endpoint.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	return client, response
+}
+
+func TestWebSocket_DefaultsToJSON(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	client, response := dialCodecTestSocket(t, server, &websocket.Dialer{})
+	assert.Empty(t, response.Header.Get("Sec-WebSocket-Protocol"))
+
+	messageType, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, messageType)
+
+	var confirmation map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &confirmation))
+	assert.Contains(t, confirmation["result"], "session_id")
+}
+
+// readCodecTestResponse reads MessagePack messages until the RPC response
+// with id arrives.
+func readCodecTestResponse(t *testing.T, client *websocket.Conn, id float64) map[string]interface{} {
+	t.Helper()
+	for {
+		messageType, data, err := client.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, websocket.BinaryMessage, messageType)
+
+		var message map[string]interface{}
+		require.NoError(t, unmarshalMsgpack(data, &message))
+		if message["id"] == id {
+			return message
+		}
+	}
+}
+
+func TestWebSocket_NegotiatesMsgpack(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	_, token := joinResumeTestGame(t, server)
+	client, _ := dialCodecTestSocket(t, server, &websocket.Dialer{
+		Subprotocols: []string{SubprotocolMsgpack, SubprotocolJSON},
+	})
+	assert.Equal(t, SubprotocolMsgpack, client.Subprotocol())
+
+	confirmation := readCodecTestResponse(t, client, 0)
+	assert.Contains(t, confirmation["result"], "session_id")
+
+	// A MessagePack request gets a MessagePack response
+	request, err := marshalMsgpack(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "reconnectSession",
+		"params":  map[string]interface{}{"resume_token": token},
+		"id":      1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.WriteMessage(websocket.BinaryMessage, request))
+	reply := readCodecTestResponse(t, client, 1)
+	require.Nil(t, reply["error"])
+	assert.Contains(t, reply["result"], "resume_token")
+
+	// Text frames are still read as JSON
+	rotated := reply["result"].(map[string]interface{})["resume_token"]
+	require.NoError(t, client.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "reconnectSession",
+		"params":  map[string]interface{}{"resume_token": rotated},
+		"id":      2,
+	}))
+	reply = readCodecTestResponse(t, client, 2)
+	assert.Nil(t, reply["error"])
+	assert.Contains(t, reply["result"], "resume_token")
+}
+
+func TestWebSocket_NegotiatesCompression(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	_, token := joinResumeTestGame(t, server)
+	client, response := dialCodecTestSocket(t, server, &websocket.Dialer{EnableCompression: true})
+	assert.Contains(t, response.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+
+	// Compressed responses are inflated transparently
+	require.NoError(t, client.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "reconnectSession",
+		"params":  map[string]interface{}{"resume_token": token},
+		"id":      1,
+	}))
+	reply := readResumeTestResponse(t, client, 1)
+	require.Nil(t, reply["error"])
+	assert.Contains(t, reply["result"], "resume_token")
+}
+
+func TestWebSocket_CompressionDisabled(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	server.config = &config.Config{EnableDevMode: true, WebSocketCompression: false}
+
+	_, response := dialCodecTestSocket(t, server, &websocket.Dialer{EnableCompression: true})
+	assert.Empty(t, response.Header.Get("Sec-WebSocket-Extensions"))
+}
+
+// gameStateBenchmarkPayload returns a getGameState response for a party of
+// eight players with equipped inventories, the largest message clients
+// routinely receive.
+func gameStateBenchmarkPayload(b *testing.B) interface{} {
+	b.Helper()
+	server, err := NewRPCServer("../../web")
+	require.NoError(b, err)
+
+	for i := 0; i < 8; i++ {
+		id := fmt.Sprintf("player-%d", i)
+		player := &game.Player{Character: game.Character{
+			ID: id, Name: fmt.Sprintf("Adventurer %d", i),
+			HP: 30, MaxHP: 30, Level: 5, ActionPoints: 10, MaxActionPoints: 10,
+			Strength: 15, Dexterity: 14, Constitution: 13, Intelligence: 12, Wisdom: 11, Charisma: 10,
+			Position:  game.Position{X: 10 + i, Y: 12, Level: 0},
+			Equipment: make(map[game.EquipmentSlot]game.Item),
+		}}
+		for j := 0; j < 12; j++ {
+			player.Inventory = append(player.Inventory, game.Item{
+				ID: fmt.Sprintf("%s-item-%d", id, j), Name: "Potion of Healing", Type: "potion",
+				Weight: 1, Value: 50, Properties: []string{"consumable", "magical"},
+			})
+		}
+		require.NoError(b, server.state.WorldState.AddObject(player))
+		server.state.Sessions[id] = &PlayerSession{SessionID: id, Player: player, Connected: true, LastActive: time.Now()}
+	}
+	return NewResponse(1, server.state.GetState())
+}
+
+// deflatedSize returns the size of data compressed at the default
+// permessage-deflate level.
+func deflatedSize(b *testing.B, data []byte) int {
+	var buf bytes.Buffer
+	writer, err := flate.NewWriter(&buf, defaultWebSocketCompressionLevel)
+	require.NoError(b, err)
+	_, err = writer.Write(data)
+	require.NoError(b, err)
+	require.NoError(b, writer.Close())
+	return buf.Len()
+}
+
+// BenchmarkWebSocketEncoding_GameState compares the bytes sent for a
+// getGameState response with each encoding, with and without
+// permessage-deflate, reported as the bytes/msg metric.
+func BenchmarkWebSocketEncoding_GameState(b *testing.B) {
+	payload := gameStateBenchmarkPayload(b)
+	encodings := []struct {
+		name   string
+		encode func(interface{}) ([]byte, error)
+	}{
+		{"json", json.Marshal},
+		{"msgpack", marshalMsgpack},
+	}
+
+	for _, encoding := range encodings {
+		encode := encoding.encode
+		for _, compress := range []bool{false, true} {
+			label := encoding.name
+			if compress {
+				label += "+deflate"
+			}
+			b.Run(label, func(b *testing.B) {
+				var size int
+				for i := 0; i < b.N; i++ {
+					data, err := encode(payload)
+					require.NoError(b, err)
+					size = len(data)
+					if compress {
+						size = deflatedSize(b, data)
+					}
+				}
+				b.ReportMetric(float64(size), "bytes/msg")
+			})
+		}
+	}
+}
@@ -561,7 +561,6 @@ func TestIsOriginAllowed(t *testing.T) {
 func TestGetSessionSafely_TOCTOU(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 	}
 
 	// Create a test session
@@ -617,7 +616,6 @@ func TestGetSessionSafely_TOCTOU(t *testing.T) {
 func TestGetSessionSafely_ValidSession(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 	}
 
 	sessionID := "valid-session"
@@ -650,7 +648,6 @@ func TestGetSessionSafely_ValidSession(t *testing.T) {
 func TestGetSessionSafely_InvalidSession(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 	}
 
 	retrievedSession, err := server.getSessionSafely("non-existent")
@@ -667,7 +664,6 @@ func TestGetSessionSafely_InvalidSession(t *testing.T) {
 func TestGetSessionSafely_EmptySessionID(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 	}
 
 	retrievedSession, err := server.getSessionSafely("")
@@ -684,7 +680,6 @@ func TestGetSessionSafely_EmptySessionID(t *testing.T) {
 func TestGetSessionSafely_NoWebSocketConnection(t *testing.T) {
 	server := &RPCServer{
 		sessions: make(map[string]*PlayerSession),
-		mu:       sync.RWMutex{},
 	}
 
 	sessionID := "no-websocket"
@@ -0,0 +1,494 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WorldEventKind names a kind of world event.
+type WorldEventKind string
+
+const (
+	WorldEventGoblinRaid WorldEventKind = "goblin_raid"
+	WorldEventPlague     WorldEventKind = "plague"
+	WorldEventFestival   WorldEventKind = "festival"
+	WorldEventFactionWar WorldEventKind = "faction_war"
+)
+
+// WorldEventWindowTicks is the span of game time in which at most one world
+// event starts. Events last whole windows.
+const WorldEventWindowTicks = 6 * TicksPerHour
+
+// worldEventMaxWindows is the longest a world event lasts, in windows
+const worldEventMaxWindows = 3
+
+// WorldEventEffects are how a world event changes the game while it lasts.
+type WorldEventEffects struct {
+	// PriceFactor multiplies every merchant's prices, 0 for no change
+	PriceFactor float64 `yaml:"price_factor,omitempty" json:"price_factor,omitempty"`
+	// EncounterChance multiplies the random encounter chance everywhere,
+	// 0 for no change
+	EncounterChance float64 `yaml:"encounter_chance,omitempty" json:"encounter_chance,omitempty"`
+	// Encounters are added to every biome's encounter table
+	Encounters []EncounterEntry `yaml:"encounters,omitempty" json:"encounters,omitempty"`
+	// QuestTypes are the quest types the event offers
+	QuestTypes []pcg.QuestType `yaml:"quest_types,omitempty" json:"quest_types,omitempty"`
+	// ClosedQuestTypes cannot be generated while the event lasts
+	ClosedQuestTypes []pcg.QuestType `yaml:"closed_quest_types,omitempty" json:"closed_quest_types,omitempty"`
+}
+
+// WorldEventDefinition describes a kind of world event.
+type WorldEventDefinition struct {
+	Kind         WorldEventKind
+	Name         string
+	Announcement string // Broadcast to every player when the event starts
+	Weight       int    // Relative likelihood among the kinds not already under way
+	Effects      WorldEventEffects
+}
+
+// worldEventDefinitions are the world events the director can start, in
+// the order they are rolled
+var worldEventDefinitions = []WorldEventDefinition{
+	{
+		Kind:         WorldEventGoblinRaid,
+		Name:         "Goblin Raid",
+		Announcement: "Goblin war bands raid the roads. Merchants raise their prices and the militia seeks defenders.",
+		Weight:       3,
+		Effects: WorldEventEffects{
+			PriceFactor:      1.2,
+			EncounterChance:  1.5,
+			Encounters:       []EncounterEntry{{Name: "goblin raiders", Kinds: []string{"goblin"}, Weight: 8}},
+			QuestTypes:       []pcg.QuestType{pcg.QuestTypeDefend, pcg.QuestTypeKill},
+			ClosedQuestTypes: []pcg.QuestType{pcg.QuestTypeDelivery},
+		},
+	},
+	{
+		Kind:         WorldEventPlague,
+		Name:         "Plague",
+		Announcement: "A plague spreads through the land. Healers pay dearly for herbs, and the dead do not rest.",
+		Weight:       1,
+		Effects: WorldEventEffects{
+			PriceFactor:      1.5,
+			Encounters:       []EncounterEntry{{Name: "plague dead", Kinds: []string{"zombie", "skeleton"}, Weight: 6, MinLevel: 2}},
+			QuestTypes:       []pcg.QuestType{pcg.QuestTypeFetch, pcg.QuestTypeSurvival},
+			ClosedQuestTypes: []pcg.QuestType{pcg.QuestTypeEscort},
+		},
+	},
+	{
+		Kind:         WorldEventFestival,
+		Name:         "Harvest Festival",
+		Announcement: "The harvest festival begins! Merchants lower their prices and the roads are quiet.",
+		Weight:       3,
+		Effects: WorldEventEffects{
+			PriceFactor:     0.85,
+			EncounterChance: 0.5,
+			QuestTypes:      []pcg.QuestType{pcg.QuestTypePuzzle, pcg.QuestTypeDelivery},
+		},
+	},
+	{
+		Kind:         WorldEventFactionWar,
+		Name:         "Faction War",
+		Announcement: "War breaks out between the great factions. Arms are scarce and deserters prowl the wilds.",
+		Weight:       2,
+		Effects: WorldEventEffects{
+			PriceFactor:      1.3,
+			EncounterChance:  1.25,
+			Encounters:       []EncounterEntry{{Name: "deserters", Kinds: []string{"bandit"}, Weight: 6}},
+			QuestTypes:       []pcg.QuestType{pcg.QuestTypeKill, pcg.QuestTypeEscort},
+			ClosedQuestTypes: []pcg.QuestType{pcg.QuestTypeExplore},
+		},
+	},
+}
+
+// WorldEvent is a world event under way. It ends at EndTick of game time.
+type WorldEvent struct {
+	ID           string            `yaml:"event_id" json:"event_id"`
+	Kind         WorldEventKind    `yaml:"event_kind" json:"kind"`
+	Name         string            `yaml:"event_name" json:"name"`
+	Announcement string            `yaml:"event_announcement" json:"announcement"`
+	StartTick    int64             `yaml:"event_start_tick" json:"start_tick"`
+	EndTick      int64             `yaml:"event_end_tick" json:"end_tick"`
+	Effects      WorldEventEffects `yaml:"event_effects" json:"effects"`
+}
+
+// WorldEventState is the part of the director saved with the game: the
+// window last rolled and the events under way.
+type WorldEventState struct {
+	Window int64        `yaml:"window"`
+	Active []WorldEvent `yaml:"active"`
+}
+
+// WorldEventDirector starts world events as game time passes. Each
+// WorldEventWindowTicks of game time it rolls, from a seed derived for the
+// window, whether an event starts and which kind, so the same seed always
+// produces the same history. Only one event of each kind is under way at a
+// time; their effects on prices, encounters and quests combine.
+type WorldEventDirector struct {
+	mu     sync.RWMutex
+	seeds  *pcg.SeedManager
+	chance float64
+	window int64 // Last window rolled, -1 before the first
+	active []WorldEvent
+}
+
+// NewWorldEventDirector creates a director seeded by seeds that starts an
+// event in a window with the given probability.
+func NewWorldEventDirector(seeds *pcg.SeedManager, chance float64) *WorldEventDirector {
+	return &WorldEventDirector{
+		seeds:  seeds,
+		chance: chance,
+		window: -1,
+	}
+}
+
+// Update advances the director to the given game time. It ends the events
+// that are over and rolls for a new event when a window begins.
+//
+// Returns:
+//   - started: The event that started, if any
+//   - ended: The events that ended, in the order they started
+func (d *WorldEventDirector) Update(ticks int64) (started, ended []WorldEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	active := make([]WorldEvent, 0, len(d.active))
+	for _, event := range d.active {
+		if ticks >= event.EndTick {
+			ended = append(ended, event)
+			continue
+		}
+		active = append(active, event)
+	}
+	d.active = active
+
+	window := ticks / WorldEventWindowTicks
+	if window > d.window {
+		d.window = window
+		if event, ok := d.rollLocked(window); ok {
+			d.active = append(d.active, event)
+			started = append(started, event)
+		}
+	}
+	return started, ended
+}
+
+// rollLocked rolls for an event starting in window. Callers must hold d.mu.
+func (d *WorldEventDirector) rollLocked(window int64) (WorldEvent, bool) {
+	seed := d.seeds.DeriveContextSeed(pcg.ContentTypeEvents, fmt.Sprintf("world:%d", window))
+	rng := pcg.NewRNG(seed, "world_events")
+	if rng.Float64() >= d.chance {
+		return WorldEvent{}, false
+	}
+
+	underWay := make(map[WorldEventKind]bool, len(d.active))
+	for _, event := range d.active {
+		underWay[event.Kind] = true
+	}
+	total := 0
+	for _, definition := range worldEventDefinitions {
+		if !underWay[definition.Kind] {
+			total += definition.Weight
+		}
+	}
+	if total == 0 {
+		return WorldEvent{}, false
+	}
+
+	roll := rng.Intn(total)
+	for _, definition := range worldEventDefinitions {
+		if underWay[definition.Kind] {
+			continue
+		}
+		if roll >= definition.Weight {
+			roll -= definition.Weight
+			continue
+		}
+
+		windows := 1 + rng.Int63n(worldEventMaxWindows)
+		return WorldEvent{
+			ID:           fmt.Sprintf("%s-%d", definition.Kind, window),
+			Kind:         definition.Kind,
+			Name:         definition.Name,
+			Announcement: definition.Announcement,
+			StartTick:    window * WorldEventWindowTicks,
+			EndTick:      (window + windows) * WorldEventWindowTicks,
+			Effects:      definition.Effects,
+		}, true
+	}
+	return WorldEvent{}, false
+}
+
+// Active returns the events under way, in the order they started.
+func (d *WorldEventDirector) Active() []WorldEvent {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]WorldEvent{}, d.active...)
+}
+
+// PriceFactor returns the combined multiplier of the active events on
+// merchant prices.
+func (d *WorldEventDirector) PriceFactor() float64 {
+	factor := 1.0
+	for _, event := range d.Active() {
+		if event.Effects.PriceFactor > 0 {
+			factor *= event.Effects.PriceFactor
+		}
+	}
+	return factor
+}
+
+// ModifyEncounterTable returns table with the encounter chance and entries
+// of the active events applied. It is the random encounter system's table
+// modifier.
+func (d *WorldEventDirector) ModifyEncounterTable(_ pcg.BiomeType, table EncounterTable) EncounterTable {
+	events := d.Active()
+	if len(events) == 0 {
+		return table
+	}
+
+	modified := EncounterTable{
+		Chance:  table.Chance,
+		Entries: append([]EncounterEntry(nil), table.Entries...),
+	}
+	for _, event := range events {
+		if event.Effects.EncounterChance > 0 {
+			modified.Chance *= event.Effects.EncounterChance
+		}
+		modified.Entries = append(modified.Entries, event.Effects.Encounters...)
+	}
+	modified.Chance = math.Min(modified.Chance, 1)
+	return modified
+}
+
+// QuestClosedBy returns the active event under which quests of questType
+// cannot be generated, if any.
+func (d *WorldEventDirector) QuestClosedBy(questType pcg.QuestType) (WorldEvent, bool) {
+	for _, event := range d.Active() {
+		for _, closed := range event.Effects.ClosedQuestTypes {
+			if closed == questType {
+				return event, true
+			}
+		}
+	}
+	return WorldEvent{}, false
+}
+
+// QuestTypes returns the quest types the active events offer that none of
+// them closes, in the order the events started.
+func (d *WorldEventDirector) QuestTypes() []pcg.QuestType {
+	seen := make(map[pcg.QuestType]bool)
+	questTypes := make([]pcg.QuestType, 0)
+	for _, event := range d.Active() {
+		for _, questType := range event.Effects.QuestTypes {
+			if _, closed := d.QuestClosedBy(questType); closed || seen[questType] {
+				continue
+			}
+			seen[questType] = true
+			questTypes = append(questTypes, questType)
+		}
+	}
+	return questTypes
+}
+
+// Snapshot returns the director's state for saving.
+func (d *WorldEventDirector) Snapshot() WorldEventState {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return WorldEventState{Window: d.window, Active: append([]WorldEvent(nil), d.active...)}
+}
+
+// Restore replaces the director's state with a saved one.
+func (d *WorldEventDirector) Restore(state WorldEventState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.window = state.Window
+	d.active = append([]WorldEvent(nil), state.Active...)
+}
+
+// attachWorldEvents creates the world event director when enabled, restores
+// the events saved with the game and lets them adjust encounter tables and
+// merchant prices. Like weather, it uses its own seed manager derived from
+// the PCG base seed.
+func (s *RPCServer) attachWorldEvents() {
+	if s.config == nil || !s.config.WorldEventsEnabled {
+		return
+	}
+
+	var baseSeed int64
+	if s.pcgManager != nil {
+		baseSeed = s.pcgManager.GetSeedManager().GetBaseSeed()
+	}
+	s.worldEvents = NewWorldEventDirector(pcg.NewSeedManager(baseSeed), s.config.WorldEventChance)
+	s.restoreWorldEvents()
+
+	if s.encounters != nil {
+		s.encounters.SetTableModifier(s.worldEvents.ModifyEncounterTable)
+	}
+	s.merchants.SetMarketFactor(s.worldEvents.PriceFactor())
+}
+
+// restoreWorldEvents reloads the world events saved by persistState, if
+// any.
+func (s *RPCServer) restoreWorldEvents() {
+	if s.worldEvents == nil || s.store == nil || !s.store.Exists(worldEventsKey) {
+		return
+	}
+
+	var state WorldEventState
+	if err := s.store.Load(worldEventsKey, &state); err != nil {
+		logrus.WithError(err).Warn("failed to load world events, starting without them")
+		return
+	}
+	s.worldEvents.Restore(state)
+	s.merchants.SetMarketFactor(s.worldEvents.PriceFactor())
+
+	logrus.WithFields(logrus.Fields{
+		"function": "restoreWorldEvents",
+		"window":   state.Window,
+		"active":   len(state.Active),
+	}).Info("world events restored")
+}
+
+// updateWorldEvents advances game time and the world event director. It
+// reprices merchants when events start or end and broadcasts an
+// announcement for each.
+func (s *RPCServer) updateWorldEvents() {
+	if s.worldEvents == nil {
+		return
+	}
+
+	s.state.stateMu.Lock()
+	ticks := s.state.TimeManager.Advance(time.Now())
+	s.state.stateMu.Unlock()
+
+	started, ended := s.worldEvents.Update(ticks)
+	if len(started) == 0 && len(ended) == 0 {
+		return
+	}
+	s.merchants.SetMarketFactor(s.worldEvents.PriceFactor())
+
+	for _, event := range ended {
+		s.announceWorldEvent(EventWorldEventEnd, event, fmt.Sprintf("The %s is over.", event.Name))
+	}
+	for _, event := range started {
+		s.announceWorldEvent(EventWorldEventStart, event, event.Announcement)
+	}
+}
+
+// announceWorldEvent logs a world event starting or ending and emits it
+// for broadcast.
+func (s *RPCServer) announceWorldEvent(eventType game.EventType, event WorldEvent, announcement string) {
+	logrus.WithFields(logrus.Fields{
+		"function": "announceWorldEvent",
+		"event_id": event.ID,
+		"kind":     event.Kind,
+		"started":  eventType == EventWorldEventStart,
+	}).Info(announcement)
+
+	s.eventSys.Emit(game.GameEvent{
+		Type:     eventType,
+		SourceID: "world",
+		TargetID: event.ID,
+		Data: map[string]interface{}{
+			"event":        event,
+			"announcement": announcement,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// startWorldEventUpdates periodically advances the world event director
+// until the server shuts down.
+func (s *RPCServer) startWorldEventUpdates() {
+	if s.worldEvents == nil {
+		return
+	}
+	ticker := time.NewTicker(worldEventUpdateInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.updateWorldEvents()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// checkQuestAvailability rejects quest types closed by a world event.
+func (s *RPCServer) checkQuestAvailability(questType string) error {
+	if s.worldEvents == nil {
+		return nil
+	}
+	if event, closed := s.worldEvents.QuestClosedBy(pcg.QuestType(questType)); closed {
+		return ErrQuestRejected.WithMessage("%s quests are unavailable during the %s", questType, event.Name)
+	}
+	return nil
+}
+
+// defaultQuestType is the quest type generated when a request names none:
+// the first offered by a world event, or fetch.
+func (s *RPCServer) defaultQuestType() string {
+	if s.worldEvents != nil {
+		if offered := s.worldEvents.QuestTypes(); len(offered) > 0 {
+			return string(offered[0])
+		}
+	}
+	return string(pcg.QuestTypeFetch)
+}
+
+// handleGetWorldEvents returns the world events under way and their
+// combined effects.
+//
+// Parameters:
+//   - params: json.RawMessage containing:
+//   - session_id: string - The requesting session
+//
+// Returns:
+//   - interface{}: Map containing:
+//   - success: bool
+//   - events: The events under way, in the order they started
+//   - price_factor: Combined multiplier on merchant prices
+//   - quest_types: Quest types the events offer
+//   - game_ticks: Current game time, to compare with the events' end_tick
+//   - error: Invalid parameters or session
+func (s *RPCServer) handleGetWorldEvents(params json.RawMessage) (interface{}, error) {
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, NewJSONRPCError(JSONRPCInvalidParams, "Invalid world event parameters", err.Error())
+	}
+	if _, err := s.getPlayerSession(req.SessionID); err != nil {
+		return nil, err
+	}
+
+	s.state.stateMu.RLock()
+	ticks := s.state.TimeManager.CurrentTime.GameTicks
+	s.state.stateMu.RUnlock()
+
+	result := map[string]interface{}{
+		"success":      true,
+		"events":       []WorldEvent{},
+		"price_factor": 1.0,
+		"quest_types":  []pcg.QuestType{},
+		"game_ticks":   ticks,
+	}
+	if s.worldEvents != nil {
+		result["events"] = s.worldEvents.Active()
+		result["price_factor"] = s.worldEvents.PriceFactor()
+		result["quest_types"] = s.worldEvents.QuestTypes()
+	}
+	return result, nil
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goldbox-rpg/pkg/game"
+	"goldbox-rpg/pkg/pcg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testWorldEvent returns an active event of the given kind with its
+// standard effects
+func testWorldEvent(t *testing.T, kind WorldEventKind) WorldEvent {
+	for _, definition := range worldEventDefinitions {
+		if definition.Kind == kind {
+			return WorldEvent{
+				ID:      string(kind) + "-0",
+				Kind:    kind,
+				Name:    definition.Name,
+				EndTick: WorldEventWindowTicks,
+				Effects: definition.Effects,
+			}
+		}
+	}
+	t.Fatalf("no world event %s", kind)
+	return WorldEvent{}
+}
+
+func TestWorldEventDirector_DeterministicHistory(t *testing.T) {
+	history := func() []WorldEvent {
+		director := NewWorldEventDirector(pcg.NewSeedManager(42), 1)
+		var started []WorldEvent
+		for window := int64(0); window < 8; window++ {
+			events, _ := director.Update(window * WorldEventWindowTicks)
+			started = append(started, events...)
+		}
+		return started
+	}
+
+	first := history()
+	require.NotEmpty(t, first)
+	assert.Equal(t, first, history(), "the same seed replays the same events")
+
+	for _, event := range first {
+		assert.Equal(t, int64(0), event.StartTick%WorldEventWindowTicks)
+		windows := (event.EndTick - event.StartTick) / WorldEventWindowTicks
+		assert.True(t, windows >= 1 && windows <= worldEventMaxWindows, "event lasts %d windows", windows)
+	}
+}
+
+func TestWorldEventDirector_UpdateEndsEvents(t *testing.T) {
+	director := NewWorldEventDirector(pcg.NewSeedManager(7), 1)
+
+	started, ended := director.Update(0)
+	require.Len(t, started, 1)
+	assert.Empty(t, ended)
+	event := started[0]
+
+	// Updates within the same window roll nothing new
+	started, ended = director.Update(WorldEventWindowTicks - 1)
+	assert.Empty(t, started)
+	assert.Empty(t, ended)
+	assert.Equal(t, []WorldEvent{event}, director.Active())
+
+	_, ended = director.Update(event.EndTick)
+	assert.Contains(t, ended, event)
+	for _, active := range director.Active() {
+		assert.NotEqual(t, event.ID, active.ID)
+	}
+
+	quiet := NewWorldEventDirector(pcg.NewSeedManager(7), 0)
+	started, _ = quiet.Update(0)
+	assert.Empty(t, started, "no events start with chance 0")
+}
+
+func TestWorldEventDirector_Effects(t *testing.T) {
+	director := NewWorldEventDirector(pcg.NewSeedManager(1), 0)
+	assert.Equal(t, 1.0, director.PriceFactor())
+	assert.Equal(t, alwaysEncounter, director.ModifyEncounterTable(pcg.BiomeDungeon, alwaysEncounter))
+
+	director.Restore(WorldEventState{Window: 0, Active: []WorldEvent{
+		testWorldEvent(t, WorldEventGoblinRaid),
+		testWorldEvent(t, WorldEventFestival),
+	}})
+
+	assert.InDelta(t, 1.2*0.85, director.PriceFactor(), 1e-9)
+
+	table := director.ModifyEncounterTable(pcg.BiomeForest, EncounterTable{Chance: 0.1, Entries: alwaysEncounter.Entries})
+	assert.InDelta(t, 0.1*1.5*0.5, table.Chance, 1e-9)
+	require.Len(t, table.Entries, 2)
+	assert.Equal(t, "goblin raiders", table.Entries[1].Name)
+	assert.Len(t, alwaysEncounter.Entries, 1, "the original table is unchanged")
+
+	raid := NewWorldEventDirector(pcg.NewSeedManager(1), 0)
+	raid.Restore(WorldEventState{Active: []WorldEvent{testWorldEvent(t, WorldEventGoblinRaid)}})
+	assert.Equal(t, 1.0, raid.ModifyEncounterTable(pcg.BiomeForest, alwaysEncounter).Chance, "chance is capped at 1")
+
+	event, closed := director.QuestClosedBy(pcg.QuestTypeDelivery)
+	assert.True(t, closed)
+	assert.Equal(t, WorldEventGoblinRaid, event.Kind)
+	_, closed = director.QuestClosedBy(pcg.QuestTypeFetch)
+	assert.False(t, closed)
+
+	// The festival's delivery quests are closed by the raid
+	assert.Equal(t, []pcg.QuestType{pcg.QuestTypeDefend, pcg.QuestTypeKill, pcg.QuestTypePuzzle}, director.QuestTypes())
+}
+
+func TestWorldEventDirector_SnapshotRestore(t *testing.T) {
+	director := NewWorldEventDirector(pcg.NewSeedManager(3), 1)
+	director.Update(2 * WorldEventWindowTicks)
+	state := director.Snapshot()
+	assert.Equal(t, int64(2), state.Window)
+
+	restored := NewWorldEventDirector(pcg.NewSeedManager(3), 1)
+	restored.Restore(state)
+	assert.Equal(t, director.Active(), restored.Active())
+
+	// The restored director does not roll the saved window again
+	started, _ := restored.Update(2 * WorldEventWindowTicks)
+	assert.Empty(t, started)
+}
+
+func TestWorldEvents_ShapeQuestsAndPrices(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+	server.worldEvents = NewWorldEventDirector(pcg.NewSeedManager(1), 0)
+	server.worldEvents.Restore(WorldEventState{Active: []WorldEvent{testWorldEvent(t, WorldEventGoblinRaid)}})
+
+	merchant := game.NewMerchant("merchant-1", "Trader", "", game.Position{}, 100, nil)
+	require.NoError(t, server.merchants.Add(merchant))
+	server.merchants.SetMarketFactor(server.worldEvents.PriceFactor())
+	item := game.Item{ID: "sword", Name: "Sword", Value: 100}
+	assert.Equal(t, 120, merchant.BuyPrice(item, session.Player))
+
+	params, err := json.Marshal(map[string]interface{}{
+		"session_id": session.SessionID,
+		"quest_type": "delivery",
+	})
+	require.NoError(t, err)
+	_, err = server.handleGenerateQuest(params)
+	assert.ErrorIs(t, err, ErrQuestRejected)
+
+	assert.Equal(t, "defend", server.defaultQuestType())
+
+	result, err := server.handleGetWorldEvents(json.RawMessage(`{"session_id":"` + session.SessionID + `"}`))
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, true, response["success"])
+	assert.InDelta(t, 1.2, response["price_factor"], 1e-9)
+	require.Len(t, response["events"], 1)
+	assert.Equal(t, []pcg.QuestType{pcg.QuestTypeDefend, pcg.QuestTypeKill}, response["quest_types"])
+}
+
+func TestGetWorldEvents_Disabled(t *testing.T) {
+	server := createTestServerForHandlers(t)
+	session := createTestSessionForHandlers(t, server)
+
+	result, err := server.handleGetWorldEvents(json.RawMessage(`{"session_id":"` + session.SessionID + `"}`))
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Empty(t, response["events"])
+	assert.Equal(t, 1.0, response["price_factor"])
+	assert.Equal(t, string(pcg.QuestTypeFetch), server.defaultQuestType())
+
+	_, err = server.handleGetWorldEvents(json.RawMessage(`{"session_id":"missing"}`))
+	assert.Error(t, err)
+}
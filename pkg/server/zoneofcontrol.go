@@ -0,0 +1,226 @@
+package server
+
+import (
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OpportunityAttack is an attack a combatant makes at an enemy moving out of
+// a square in its zone of control.
+type OpportunityAttack struct {
+	AttackerID string `json:"attacker_id"`
+	Damage     int    `json:"damage"`
+	APCost     int    `json:"ap_cost"` // Action points charged to the attacker's next turn
+}
+
+// zoneOfControlEnabled reports whether combatants threaten the squares
+// around them, as set by config.ZoneOfControl
+func (s *RPCServer) zoneOfControlEnabled() bool {
+	return s.config != nil && s.config.ZoneOfControl
+}
+
+// boardSquare returns pos without its facing, for use as a map key
+func boardSquare(pos game.Position) game.Position {
+	pos.Facing = 0
+	return pos
+}
+
+// zoneOfControl returns the squares threatened by the living combatants
+// hostile to mover, each with the IDs of the combatants threatening it in
+// initiative order. A combatant threatens the eight squares around it.
+// Combatants missing from the initiative order, such as those sitting out
+// a surprise round, threaten nothing.
+func (s *RPCServer) zoneOfControl(mover game.GameObject) map[game.Position][]string {
+	zone := make(map[game.Position][]string)
+	tm := s.state.TurnManager
+	if !tm.IsInCombat {
+		return zone
+	}
+
+	moverSide := s.isPartySide(mover)
+	for _, id := range tm.Initiative {
+		obj, exists := s.state.WorldState.Objects[id]
+		if !exists || id == mover.GetID() || s.isPartySide(obj) == moverSide {
+			continue
+		}
+		if hp, ok := objectHP(obj); !ok || hp <= 0 {
+			continue
+		}
+		pos := obj.GetPosition()
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx == 0 && dy == 0 {
+					continue
+				}
+				square := game.Position{X: pos.X + dx, Y: pos.Y + dy, Level: pos.Level}
+				zone[square] = append(zone[square], id)
+			}
+		}
+	}
+	return zone
+}
+
+// resolveOpportunityAttacks has each combatant whose zone of control mover
+// leaves from strike it once. Moving between two squares a combatant
+// threatens still leaves one of them. Combatants holding an attack of
+// opportunity reaction are skipped, since resolveReactions offers them the
+// choice, as are combatants that have already reacted this round. Each
+// attack charges the attacker ActionCostAttack action points on its next
+// turn. Attacks stop once mover is down.
+func (s *RPCServer) resolveOpportunityAttacks(mover game.GameObject, from game.Position) []OpportunityAttack {
+	if !s.zoneOfControlEnabled() || !s.state.TurnManager.IsInCombat {
+		return nil
+	}
+	threats := s.zoneOfControl(mover)[boardSquare(from)]
+	if len(threats) == 0 {
+		return nil
+	}
+
+	tm := s.state.TurnManager
+	var attacks []OpportunityAttack
+	for _, attackerID := range threats {
+		if holdsReaction(tm, attackerID, ReactionAttackOfOpportunity) {
+			continue
+		}
+		if !tm.UseOpportunityAttack(attackerID, game.ActionCostAttack) {
+			continue
+		}
+
+		attack := OpportunityAttack{AttackerID: attackerID, APCost: game.ActionCostAttack}
+		attack.Damage = reactionAttackDamage(s.state.WorldState.Objects[attackerID])
+		if attack.Damage > 0 {
+			if err := s.applyDamage(mover, attack.Damage); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"function":   "resolveOpportunityAttacks",
+					"attackerID": attackerID,
+					"moverID":    mover.GetID(),
+					"error":      err.Error(),
+				}).Error("failed to apply opportunity attack damage")
+				attack.Damage = 0
+			}
+		}
+		s.logCombat(CombatLogEntry{
+			Action:     CombatLogAttackOfOpportunity,
+			AttackerID: attackerID,
+			DefenderID: mover.GetID(),
+			Source:     "zone_of_control",
+			Damage:     attack.Damage,
+		})
+		s.eventSys.Emit(game.GameEvent{
+			Type:     EventOpportunityAttack,
+			SourceID: attackerID,
+			TargetID: mover.GetID(),
+			Data: map[string]interface{}{
+				"from":    from,
+				"damage":  attack.Damage,
+				"ap_cost": attack.APCost,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+
+		logrus.WithFields(logrus.Fields{
+			"function":   "resolveOpportunityAttacks",
+			"attackerID": attackerID,
+			"moverID":    mover.GetID(),
+			"damage":     attack.Damage,
+		}).Info("opportunity attack")
+
+		attacks = append(attacks, attack)
+		if hp, _ := objectHP(mover); hp <= 0 {
+			break
+		}
+	}
+	return attacks
+}
+
+// holdsReaction reports whether ownerID holds a reaction of reactionType
+func holdsReaction(tm *TurnManager, ownerID string, reactionType ReactionType) bool {
+	for _, reaction := range tm.GetReactions(ownerID) {
+		if reaction.Type == reactionType {
+			return true
+		}
+	}
+	return false
+}
+
+// UseOpportunityAttack spends ownerID's reaction for this round on an
+// opportunity attack and charges cost action points to its next turn,
+// player or NPC. It
+// reports false, charging nothing, when the owner has already reacted this
+// round.
+func (tm *TurnManager) UseOpportunityAttack(ownerID string, cost int) bool {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	if tm.hasReactedLocked(ownerID) {
+		return false
+	}
+	if tm.reactionsUsed == nil {
+		tm.reactionsUsed = make(map[string]int)
+	}
+	tm.reactionsUsed[ownerID] = tm.CurrentRound
+	if tm.opportunityCost == nil {
+		tm.opportunityCost = make(map[string]int)
+	}
+	tm.opportunityCost[ownerID] += cost
+	return true
+}
+
+// TakeOpportunityCost returns the action points ownerID owes for the
+// opportunity attacks it made since its last turn and clears the debt.
+func (tm *TurnManager) TakeOpportunityCost(ownerID string) int {
+	tm.reactionMu.Lock()
+	defer tm.reactionMu.Unlock()
+
+	cost := tm.opportunityCost[ownerID]
+	delete(tm.opportunityCost, ownerID)
+	return cost
+}
+
+// actionPointPool is a combatant with action points to spend on its turn,
+// such as a player or an NPC
+type actionPointPool interface {
+	GetID() string
+	GetActionPoints() int
+	GetMaxActionPoints() int
+	ConsumeActionPoints(cost int) bool
+	RestoreActionPoints()
+}
+
+// chargeOpportunityCost takes the action points combatant owes for
+// opportunity attacks out of the turn it is starting.
+func (s *RPCServer) chargeOpportunityCost(combatant actionPointPool) {
+	cost := s.state.TurnManager.TakeOpportunityCost(combatant.GetID())
+	if cost <= 0 {
+		return
+	}
+	if available := combatant.GetActionPoints(); cost > available {
+		cost = available
+	}
+	combatant.ConsumeActionPoints(cost)
+
+	logrus.WithFields(logrus.Fields{
+		"function":    "chargeOpportunityCost",
+		"combatantID": combatant.GetID(),
+		"charged":     cost,
+		"remainingAP": combatant.GetActionPoints(),
+	}).Info("charged action points for opportunity attacks")
+}
+
+// startNPCTurn restores the action points of a combatant without a session,
+// such as a monster or companion, as its turn begins and charges the
+// opportunity attacks it made since its last turn. A combatant without
+// action points has its debt cleared instead.
+func (s *RPCServer) startNPCTurn(entityID string) {
+	obj, _ := s.state.WorldState.GetObject(entityID)
+	combatant, ok := obj.(actionPointPool)
+	if !ok || combatant.GetMaxActionPoints() <= 0 {
+		s.state.TurnManager.TakeOpportunityCost(entityID)
+		return
+	}
+	combatant.RestoreActionPoints()
+	s.chargeOpportunityCost(combatant)
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goldbox-rpg/pkg/game"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newZoneOfControlTestServer creates a server with a player "hero" at (5,5)
+// in combat with an orc at (6,5) and a goblin at (6,6), the hero acting
+// first.
+func newZoneOfControlTestServer(t *testing.T) (*RPCServer, *game.Player) {
+	server, err := NewRPCServer("../../web")
+	require.NoError(t, err)
+	server.config.ZoneOfControl = true
+	server.state.WorldState = game.NewWorldWithSize(20, 20, 1)
+
+	hero := &game.Player{
+		Character: game.Character{
+			ID:              "hero",
+			Name:            "Hero",
+			Class:           game.ClassFighter,
+			Position:        game.Position{X: 5, Y: 5},
+			HP:              100,
+			MaxHP:           100,
+			Strength:        14,
+			ActionPoints:    10,
+			MaxActionPoints: 10,
+			Equipment:       make(map[game.EquipmentSlot]game.Item),
+		},
+		Level: 3,
+	}
+	orc := &game.NPC{Character: game.Character{ID: "orc", Name: "Orc", HP: 20, MaxHP: 20, Strength: 14, Position: game.Position{X: 6, Y: 5}}}
+	goblin := &game.NPC{Character: game.Character{ID: "goblin", Name: "Goblin", HP: 8, MaxHP: 8, Strength: 10, Position: game.Position{X: 6, Y: 6}}}
+
+	server.mu.Lock()
+	server.sessions["hero-session"] = &PlayerSession{
+		SessionID:   "hero-session",
+		Player:      hero,
+		Connected:   true,
+		LastActive:  time.Now(),
+		MessageChan: make(chan []byte, 10),
+		WSConn:      &websocket.Conn{},
+	}
+	server.mu.Unlock()
+	for _, obj := range []game.GameObject{hero, orc, goblin} {
+		require.NoError(t, server.state.WorldState.AddObject(obj))
+	}
+
+	require.NoError(t, server.state.TurnManager.StartCombat([]string{"hero", "orc", "goblin"}))
+	t.Cleanup(server.state.TurnManager.EndCombat)
+	return server, hero
+}
+
+// moveHero moves the hero one square and returns the move result
+func moveHero(t *testing.T, server *RPCServer, direction game.Direction) map[string]interface{} {
+	t.Helper()
+	params, err := json.Marshal(map[string]interface{}{"session_id": "hero-session", "direction": direction})
+	require.NoError(t, err)
+	result, err := server.handleMove(params)
+	require.NoError(t, err)
+	return result.(map[string]interface{})
+}
+
+func TestZoneOfControl(t *testing.T) {
+	server, hero := newZoneOfControlTestServer(t)
+
+	zone := server.zoneOfControl(hero)
+	assert.Equal(t, []string{"orc", "goblin"}, zone[game.Position{X: 5, Y: 5}])
+	assert.Equal(t, []string{"goblin"}, zone[game.Position{X: 6, Y: 5}], "combatants threaten each other's squares")
+	assert.Empty(t, zone[game.Position{X: 8, Y: 8}])
+
+	orc, _ := server.state.WorldState.GetObject("orc")
+	orc.(*game.NPC).HP = 0
+	assert.Equal(t, []string{"goblin"}, server.zoneOfControl(hero)[game.Position{X: 5, Y: 5}], "fallen combatants threaten nothing")
+}
+
+func TestMoveProvokesOpportunityAttacks(t *testing.T) {
+	server, hero := newZoneOfControlTestServer(t)
+
+	result := moveHero(t, server, game.DirectionWest)
+	assert.Equal(t, true, result["success"])
+	assert.Equal(t, 4, hero.GetPosition().X)
+	attacks := result["opportunity_attacks"].([]OpportunityAttack)
+	assert.Equal(t, []OpportunityAttack{
+		{AttackerID: "orc", Damage: 3, APCost: game.ActionCostAttack},
+		{AttackerID: "goblin", Damage: 1, APCost: game.ActionCostAttack},
+	}, attacks)
+	assert.Equal(t, 96, hero.GetHP())
+
+	// Stepping from unthreatened squares back next to the orc and out again
+	// provokes nothing: each combatant strikes once per round
+	for _, direction := range []game.Direction{game.DirectionNorth, game.DirectionEast, game.DirectionNorth} {
+		result = moveHero(t, server, direction)
+		assert.Nil(t, result["opportunity_attacks"])
+	}
+	assert.Equal(t, game.Position{X: 5, Y: 3}, boardSquare(hero.GetPosition()))
+	assert.Equal(t, 96, hero.GetHP())
+
+	assert.Equal(t, game.ActionCostAttack, server.state.TurnManager.TakeOpportunityCost("orc"))
+	assert.Zero(t, server.state.TurnManager.TakeOpportunityCost("orc"), "the cost is charged once")
+}
+
+func TestMoveOpportunityAttacks_StruckDown(t *testing.T) {
+	server, hero := newZoneOfControlTestServer(t)
+	hero.SetHP(2)
+
+	result := moveHero(t, server, game.DirectionWest)
+	assert.Equal(t, false, result["success"])
+	assert.Equal(t, 5, hero.GetPosition().X, "a player struck down does not move")
+	assert.Len(t, result["opportunity_attacks"], 1, "attacks stop once the player is down")
+	assert.Zero(t, hero.GetHP())
+}
+
+func TestMoveOpportunityAttacks_Disabled(t *testing.T) {
+	server, hero := newZoneOfControlTestServer(t)
+	server.config.ZoneOfControl = false
+
+	result := moveHero(t, server, game.DirectionWest)
+	assert.Nil(t, result["opportunity_attacks"])
+	assert.Equal(t, 100, hero.GetHP())
+}
+
+func TestChargeOpportunityCost(t *testing.T) {
+	server, hero := newZoneOfControlTestServer(t)
+	tm := server.state.TurnManager
+
+	require.True(t, tm.UseOpportunityAttack("hero", 1))
+	assert.False(t, tm.UseOpportunityAttack("hero", 1), "one opportunity attack per round")
+
+	server.chargeOpportunityCost(hero)
+	assert.Equal(t, 9, hero.GetActionPoints())
+	server.chargeOpportunityCost(hero)
+	assert.Equal(t, 9, hero.GetActionPoints())
+}
+
+func TestChargeOpportunityCost_NPCAttacker(t *testing.T) {
+	server, hero := newZoneOfControlTestServer(t)
+	orcObj, _ := server.state.WorldState.GetObject("orc")
+	orc := orcObj.(*game.NPC)
+	orc.MaxActionPoints = 4
+
+	moveHero(t, server, game.DirectionWest)
+
+	// The orc pays for its opportunity attack as its turn begins
+	require.Equal(t, "orc", server.advanceTurn(hero))
+	assert.Equal(t, 4-game.ActionCostAttack, orc.GetActionPoints())
+	assert.Zero(t, server.state.TurnManager.TakeOpportunityCost("orc"))
+
+	// The goblin has no action points, so its debt is dropped
+	require.Equal(t, "goblin", server.advanceTurn(orc))
+	assert.Zero(t, server.state.TurnManager.TakeOpportunityCost("goblin"))
+}
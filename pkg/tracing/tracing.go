@@ -0,0 +1,137 @@
+// Package tracing wires optional OpenTelemetry tracing into the server. Spans
+// are started through Start, which also copies the trace and span IDs into
+// the request-scoped log fields so log lines can be matched to traces. With
+// the "none" exporter spans are no-ops and nothing is recorded.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"goldbox-rpg/pkg/logging"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Supported span exporters
+const (
+	ExporterNone   = "none"   // Tracing disabled
+	ExporterStdout = "stdout" // Spans written to standard output as JSON
+	ExporterOTLP   = "otlp"   // Spans sent to an OTLP/HTTP collector
+)
+
+// TracerName is the instrumentation name of every span started by Start
+const TracerName = "goldbox-rpg"
+
+// DefaultServiceName is the service.name resource attribute used when none
+// is configured
+const DefaultServiceName = "goldbox-rpg"
+
+// Config selects the span exporter and sampling
+type Config struct {
+	Exporter    string  // One of ExporterNone, ExporterStdout or ExporterOTLP
+	Endpoint    string  // OTLP collector host:port; empty uses the OTEL_EXPORTER_OTLP_* environment
+	Insecure    bool    // Send OTLP spans over plain HTTP
+	ServiceName string  // service.name resource attribute
+	SampleRatio float64 // Fraction of new traces sampled, 0-1
+}
+
+// ShutdownFunc flushes buffered spans and stops the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup installs the global tracer provider and W3C trace context
+// propagator described by config. The returned function must be called on
+// shutdown to flush spans. The none exporter leaves the no-op provider in
+// place.
+func Setup(ctx context.Context, config Config) (ShutdownFunc, error) {
+	noop := func(context.Context) error { return nil }
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case "", ExporterNone:
+		return noop, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return noop, fmt.Errorf("unknown tracing exporter %q", config.Exporter)
+	}
+	if err != nil {
+		return noop, fmt.Errorf("failed to create %s span exporter: %w", config.Exporter, err)
+	}
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "Setup",
+		"exporter":     config.Exporter,
+		"service_name": serviceName,
+		"sample_ratio": config.SampleRatio,
+	}).Info("tracing enabled")
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of any span in ctx. When the
+// span is recorded, the returned context carries its trace and span IDs as
+// log fields. End the span with End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+
+	if spanCtx := span.SpanContext(); spanCtx.IsValid() {
+		ctx = logging.ContextWithFields(ctx, logrus.Fields{
+			logging.FieldOTelTraceID: spanCtx.TraceID().String(),
+			logging.FieldSpanID:      spanCtx.SpanID().String(),
+		})
+	}
+	return ctx, span
+}
+
+// End ends span, marking it failed with err when err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract returns ctx with the remote span context carried in the
+// traceparent headers of header, if any
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
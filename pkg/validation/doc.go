@@ -25,22 +25,43 @@
 // The validator includes built-in validation for all standard JSON-RPC methods:
 //
 // Session/Player operations:
-//   - ping, createPlayer, getPlayer, listPlayers
+//   - ping, rpc.discover, createPlayer, getPlayer, listPlayers, joinGame,
+//     getGameState
 //
 // Character management:
 //   - createCharacter, getCharacter, updateCharacter, listCharacters
 //
 // Movement:
-//   - move, getPosition
+//   - move, travelTo, getPosition
+//
+// Resting and downtime:
+//   - rest, passTime
 //
 // Combat:
-//   - attack, castSpell, getSpells
+//   - attack, castSpell, getSpells, previewSpellTargets, startCombat, endTurn
+//
+// Quests:
+//   - startQuest, completeQuest, failQuest, updateObjective, getQuest,
+//     getActiveQuests, getCompletedQuests, getQuestLog
+//
+// Spell lookup:
+//   - getSpell, getAllSpells, getSpellsByLevel, getSpellsBySchool, searchSpells
+//
+// Spatial queries:
+//   - getObjectsInRange, getObjectsInRadius, getNearestObjects
+//
+// Content generation:
+//   - generateContent, regenerateTerrain, generateItems, generateLevel,
+//     generateQuest, getPCGStats, validateContent
 //
 // World state:
 //   - getWorld, getWorldState
 //
+// Map annotations:
+//   - addAnnotation, getAnnotations
+//
 // Equipment:
-//   - equipItem, unequipItem, getInventory
+//   - equipItem, unequipItem, getInventory, getEquipment
 //
 // Other:
 //   - useItem, leaveGame
@@ -54,6 +75,19 @@
 //   - Equipment slots: head, chest, main-hand, off-hand, etc.
 //   - Spell IDs: Lowercase identifiers
 //   - Coordinates: Range -10000 to 10000
+//   - Idempotency keys: optional idempotency_key of any method, 1 to
+//     MaxIdempotencyKeyLength letters, digits, '.', '_', ':' or '-'
+//
+// # Method Schemas
+//
+// Each validator is registered together with a description of the method
+// and a JSON Schema of each of its parameters, built from the same patterns,
+// enums and limits the validator checks. Methods returns them sorted by
+// name, for the server's rpc.discover method:
+//
+//	for _, method := range validator.Methods() {
+//	    fmt.Println(method.Name, len(method.Params))
+//	}
 //
 // # Security Features
 //
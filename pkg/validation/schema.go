@@ -0,0 +1,209 @@
+package validation
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Schema is the JSON Schema of a parameter value. Only the keywords the
+// validation rules need are used.
+type Schema struct {
+	Type             string             `json:"type,omitempty"`
+	Pattern          string             `json:"pattern,omitempty"`
+	Enum             []string           `json:"enum,omitempty"`
+	Minimum          *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum *float64           `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64           `json:"maximum,omitempty"`
+	MinLength        *int               `json:"minLength,omitempty"`
+	MaxLength        *int               `json:"maxLength,omitempty"`
+	MaxItems         *int               `json:"maxItems,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	Examples         []interface{}      `json:"examples,omitempty"`
+}
+
+// Param describes a named parameter of a method, in the shape of an
+// OpenRPC content descriptor.
+type Param struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Schema      Schema `json:"schema"`
+}
+
+// MethodSchema describes a JSON-RPC method the validator accepts and the
+// parameters it takes by name.
+type MethodSchema struct {
+	Name    string  `json:"name"`
+	Summary string  `json:"summary,omitempty"`
+	Params  []Param `json:"params"`
+}
+
+// Example values of patterned strings, shown to clients and used to check
+// that the schemas describe what the validators accept
+const (
+	exampleUUID        = "123e4567-e89b-12d3-a456-426614174000"
+	exampleResumeToken = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	exampleBackupID    = "backups/gamestate/20250101T120000.000000000Z.yaml"
+	exampleSnapshotID  = "snapshots/20250101T120000.000000000Z.yaml"
+)
+
+// register adds a method's validator and the schema of its parameters.
+func (v *InputValidator) register(method string, validator func(interface{}) error, summary string, params ...Param) {
+	v.validators[method] = validator
+	v.schemas[method] = MethodSchema{Name: method, Summary: summary, Params: params}
+}
+
+// Methods returns the schemas of the methods the validator accepts, sorted
+// by name. Every method also takes the optional idempotency key.
+func (v *InputValidator) Methods() []MethodSchema {
+	methods := make([]MethodSchema, 0, len(v.schemas))
+	for _, method := range v.schemas {
+		method.Params = append(append([]Param(nil), method.Params...), idempotencyKeyParam)
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return methods
+}
+
+// required describes a parameter every call must pass
+func required(name string, schema Schema, description string) Param {
+	return Param{Name: name, Description: description, Required: true, Schema: schema}
+}
+
+// optional describes a parameter calls may leave out
+func optional(name string, schema Schema, description string) Param {
+	return Param{Name: name, Description: description, Schema: schema}
+}
+
+// Common parameters
+var (
+	sessionParam        = required("session_id", uuidSchema(), "Session ID returned by joinGame")
+	adminTokenParam     = required("admin_token", stringSchema(1, 256), "Admin token of the game master console")
+	idempotencyKeyParam = optional(IdempotencyKeyParam, patternSchema(idempotencyKeyPattern, "client-42:attack:17").length(1, MaxIdempotencyKeyLength),
+		"Key that lets a retried call of a method that changes state be answered without applying it twice")
+	positionParam = required("position", objectSchema(map[string]*Schema{
+		"x":     ptr(integerSchema().atLeast(0)),
+		"y":     ptr(integerSchema().atLeast(0)),
+		"level": ptr(integerSchema().atLeast(0)),
+	}, "x", "y"), "Position; level defaults to the current level")
+	itemParam = required("item", objectSchema(map[string]*Schema{
+		"name":        ptr(stringSchema(1, 100)),
+		"type":        ptr(stringSchema(1, 100)),
+		"weight":      ptr(integerSchema().atLeast(0)),
+		"value":       ptr(integerSchema().atLeast(0)),
+		"armor_class": ptr(integerSchema().atLeast(0)),
+	}, "name", "type"), "Item to create")
+	targetIDParam      = optional("target_id", stringSchema(0, 100), "Target of the action; the session's player when omitted")
+	doorDirectionParam = required("direction", integerSchema().between(0, 3), "Direction of the door: 0 north, 1 east, 2 south, 3 west")
+	merchantIDParam    = required("merchant_id", objectIDSchema(), "Merchant to trade with")
+)
+
+// uuidSchema describes a UUID
+func uuidSchema() Schema {
+	return patternSchema(uuidPattern, exampleUUID)
+}
+
+// objectIDSchema describes a generated object ID
+func objectIDSchema() Schema {
+	return patternSchema(objectIDPattern, "item_longsword_1").length(1, maxObjectIDLength)
+}
+
+// spellIDSchema describes a spell ID
+func spellIDSchema() Schema {
+	return patternSchema(spellIDPattern, "fireball").length(1, maxSpellIDLength)
+}
+
+// nameSchema describes a player or character name
+func nameSchema() Schema {
+	return patternSchema(namePattern, "Aldric").length(1, maxNameLength)
+}
+
+// stringSchema describes a string of minLength to maxLength characters;
+// zero leaves a bound out
+func stringSchema(minLength, maxLength int) Schema {
+	return Schema{Type: "string"}.length(minLength, maxLength)
+}
+
+// patternSchema describes a string matching pattern, such as example
+func patternSchema(pattern *regexp.Regexp, example string) Schema {
+	return Schema{Type: "string", Pattern: pattern.String(), Examples: []interface{}{example}}
+}
+
+// enumSchema describes a string that is one of values
+func enumSchema(values ...string) Schema {
+	return Schema{Type: "string", Enum: values}
+}
+
+// integerSchema describes a whole number
+func integerSchema() Schema {
+	return Schema{Type: "integer"}
+}
+
+// numberSchema describes any number
+func numberSchema() Schema {
+	return Schema{Type: "number"}
+}
+
+// booleanSchema describes true or false
+func booleanSchema() Schema {
+	return Schema{Type: "boolean"}
+}
+
+// arraySchema describes a list of at most maxItems items, zero for any
+// number
+func arraySchema(items Schema, maxItems int) Schema {
+	schema := Schema{Type: "array", Items: &items}
+	if maxItems > 0 {
+		schema.MaxItems = &maxItems
+	}
+	return schema
+}
+
+// objectSchema describes an object with the given properties, of which
+// the named ones are required
+func objectSchema(properties map[string]*Schema, required ...string) Schema {
+	return Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// length bounds the length of a string; zero leaves a bound out
+func (s Schema) length(minLength, maxLength int) Schema {
+	if minLength > 0 {
+		s.MinLength = &minLength
+	}
+	if maxLength > 0 {
+		s.MaxLength = &maxLength
+	}
+	return s
+}
+
+// atLeast sets the minimum of a number
+func (s Schema) atLeast(minimum float64) Schema {
+	s.Minimum = &minimum
+	return s
+}
+
+// above sets a minimum a number must exceed
+func (s Schema) above(minimum float64) Schema {
+	s.ExclusiveMinimum = &minimum
+	return s
+}
+
+// atMost sets the maximum of a number
+func (s Schema) atMost(maximum float64) Schema {
+	s.Maximum = &maximum
+	return s
+}
+
+// between sets the minimum and maximum of a number
+func (s Schema) between(minimum, maximum float64) Schema {
+	s.Minimum = &minimum
+	s.Maximum = &maximum
+	return s
+}
+
+// ptr returns a pointer to a copy of the schema
+func ptr(s Schema) *Schema {
+	return &s
+}
@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exampleValue builds a value the schema describes
+func exampleValue(schema *Schema) interface{} {
+	switch {
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		// The maximum, as some methods need a total above their minimums
+		if schema.Maximum != nil {
+			return *schema.Maximum
+		}
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		if schema.ExclusiveMinimum != nil {
+			return *schema.ExclusiveMinimum + 1
+		}
+		return 1
+	case "boolean":
+		return true
+	case "array":
+		return []interface{}{exampleValue(schema.Items)}
+	case "object":
+		object := make(map[string]interface{})
+		for name, property := range schema.Properties {
+			object[name] = exampleValue(property)
+		}
+		return object
+	default:
+		return "a"
+	}
+}
+
+// exampleParams builds the parameters of a call passing every parameter of
+// the method, decoded from JSON as the server receives them
+func exampleParams(t *testing.T, method MethodSchema) map[string]interface{} {
+	params := make(map[string]interface{})
+	for i := range method.Params {
+		params[method.Params[i].Name] = exampleValue(&method.Params[i].Schema)
+	}
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+	decoded := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	return decoded
+}
+
+func TestMethods_CoverValidators(t *testing.T) {
+	validator := NewInputValidator(1024)
+	methods := validator.Methods()
+
+	assert.Len(t, methods, len(validator.validators))
+	for i, method := range methods {
+		_, exists := validator.validators[method.Name]
+		assert.True(t, exists, "schema of %s has no validator", method.Name)
+		if i > 0 {
+			assert.Less(t, methods[i-1].Name, method.Name, "methods are sorted by name")
+		}
+		last := method.Params[len(method.Params)-1]
+		assert.Equal(t, IdempotencyKeyParam, last.Name, "%s takes an idempotency key", method.Name)
+	}
+}
+
+// TestMethods_MatchValidators checks that the schemas describe what the
+// validators accept: a call passing every parameter is valid, and leaving
+// out a required parameter is not.
+func TestMethods_MatchValidators(t *testing.T) {
+	validator := NewInputValidator(1024)
+
+	for _, method := range validator.Methods() {
+		t.Run(method.Name, func(t *testing.T) {
+			params := exampleParams(t, method)
+			assert.NoError(t, validator.ValidateRPCRequest(method.Name, params, 0))
+
+			for _, param := range method.Params {
+				if !param.Required {
+					continue
+				}
+				partial := exampleParams(t, method)
+				delete(partial, param.Name)
+				assert.Error(t, validator.ValidateRPCRequest(method.Name, partial, 0),
+					"%s is required", param.Name)
+			}
+		})
+	}
+}
@@ -17,12 +17,68 @@ import (
 // done at the application level, not in library packages, to avoid
 // affecting the entire process when the package is imported.
 
+// IdempotencyKeyParam is the optional parameter of mutating methods that
+// lets a client retry a call without applying it twice.
+const IdempotencyKeyParam = "idempotency_key"
+
+// MaxIdempotencyKeyLength caps the length of an idempotency key
+const MaxIdempotencyKeyLength = 128
+
+// idempotencyKeyPattern accepts keys such as UUIDs, ULIDs or
+// "client-42:attack:17"
+var idempotencyKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// Shared validation rules. The validators check parameters against them
+// and the method schemas describe parameters with them, so the two agree.
+var (
+	// uuidPattern matches session, character and other UUIDs
+	// (8-4-4-4-12 hex digits)
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// objectIDPattern matches generated object IDs such as merchant or
+	// item IDs
+	objectIDPattern = regexp.MustCompile(`^[a-zA-Z0-9\-_.]+$`)
+	// namePattern matches player and character names
+	namePattern = regexp.MustCompile(`^[a-zA-Z0-9\s\-_'\.]+$`)
+	// spellIDPattern matches spell IDs
+	spellIDPattern = regexp.MustCompile(`^[a-z0-9\-_]+$`)
+	// resumeTokenPattern matches session resume tokens
+	resumeTokenPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	// backupIDPattern and snapshotIDPattern match the IDs returned by
+	// listBackups and listSnapshots
+	backupIDPattern   = regexp.MustCompile(`^backups/`)
+	snapshotIDPattern = regexp.MustCompile(`^snapshots/`)
+
+	// characterClasses must match the game.CharacterClass constants, see
+	// pkg/game/constants.go
+	characterClasses = []string{"fighter", "mage", "cleric", "thief", "ranger", "paladin"}
+	// characterBackgrounds must match the game.Background constants, see
+	// pkg/game/background.go; "random" lets the server pick one
+	characterBackgrounds = []string{"", "soldier", "scholar", "outcast", "noble", "random"}
+	reactionTypes        = []string{"attack_of_opportunity", "shield_block", "counterspell"}
+	// initiativeModes must match the InitiativeMode constants of
+	// pkg/server/initiative.go
+	initiativeModes = []string{"fixed", "per_round"}
+	equipmentSlots  = []string{
+		"head", "neck", "shoulders", "chest", "waist", "legs", "feet",
+		"hands", "wrists", "ring1", "ring2", "main-hand", "off-hand",
+		"two-hand", "ranged", "ammo",
+	}
+)
+
+// Length limits of shared validation rules
+const (
+	maxNameLength     = 50
+	maxObjectIDLength = 200
+	maxSpellIDLength  = 100
+)
+
 // InputValidator provides comprehensive input validation for JSON-RPC methods.
 // It maintains a registry of validation functions per method and enforces
 // size limits to prevent denial-of-service attacks.
 type InputValidator struct {
 	maxRequestSize int64
 	validators     map[string]func(interface{}) error
+	schemas        map[string]MethodSchema
 }
 
 // NewInputValidator creates a new InputValidator with the specified maximum request size.
@@ -37,6 +93,7 @@ func NewInputValidator(maxRequestSize int64) *InputValidator {
 	validator := &InputValidator{
 		maxRequestSize: maxRequestSize,
 		validators:     make(map[string]func(interface{}) error),
+		schemas:        make(map[string]MethodSchema),
 	}
 
 	// Register validators for all JSON-RPC methods
@@ -85,8 +142,12 @@ func (v *InputValidator) ValidateRPCRequest(method string, params interface{}, r
 		return fmt.Errorf("unknown method: %s", method)
 	}
 
-	// Run method-specific validation
+	// Run method-specific validation, then check the idempotency key any
+	// method may carry
 	err := validator(params)
+	if err == nil {
+		err = validateIdempotencyKey(params)
+	}
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"function": "ValidateRPCRequest",
@@ -104,9 +165,28 @@ func (v *InputValidator) ValidateRPCRequest(method string, params interface{}, r
 	return err
 }
 
+// validateIdempotencyKey checks the optional idempotency key of a request:
+// 1 to MaxIdempotencyKeyLength letters, digits, '.', '_', ':' or '-'.
+func validateIdempotencyKey(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	value, exists := paramMap[IdempotencyKeyParam]
+	if !exists {
+		return nil
+	}
+	key, ok := value.(string)
+	if !ok || len(key) > MaxIdempotencyKeyLength || !idempotencyKeyPattern.MatchString(key) {
+		return fmt.Errorf("%s must be 1 to %d letters, digits, '.', '_', ':' or '-'", IdempotencyKeyParam, MaxIdempotencyKeyLength)
+	}
+	return nil
+}
+
 // registerValidators sets up validation rules for all JSON-RPC methods.
 // Each method gets its own validation function that checks parameter types,
-// ranges, and business logic constraints.
+// ranges, and business logic constraints, and a schema of its parameters
+// built from the same rules.
 func (v *InputValidator) registerValidators() {
 	logrus.WithFields(logrus.Fields{
 		"function": "registerValidators",
@@ -114,38 +194,434 @@ func (v *InputValidator) registerValidators() {
 	}).Debug("entering registerValidators")
 
 	// Game session methods
-	v.validators["ping"] = v.validatePing
-	v.validators["createPlayer"] = v.validateCreatePlayer
-	v.validators["getPlayer"] = v.validateGetPlayer
-	v.validators["listPlayers"] = v.validateListPlayers
+	v.register("ping", v.validatePing, "Checks that the server is responding")
+	v.register("rpc.discover", v.validateDiscover, "Describes the JSON-RPC methods and their parameters as an OpenRPC document")
+	v.register("createPlayer", v.validateCreatePlayer, "Creates a player",
+		required("name", nameSchema(), "Player name"))
+	v.register("getPlayer", v.validateGetPlayer, "Returns the session's player", sessionParam)
+	v.register("listPlayers", v.validateListPlayers, "Lists the players in the game", sessionParam)
+	v.register("joinGame", v.validateJoinGame, "Joins the game, starting a session",
+		required("player_name", nameSchema(), "Player name"))
+	v.register("getGameState", v.validateGetGameState, "Returns the game state the session's player can see", sessionParam)
 
 	// Character management methods
-	v.validators["createCharacter"] = v.validateCreateCharacter
-	v.validators["getCharacter"] = v.validateGetCharacter
-	v.validators["updateCharacter"] = v.validateUpdateCharacter
-	v.validators["listCharacters"] = v.validateListCharacters
+	v.register("createCharacter", v.validateCreateCharacter, "Creates a character for the session's player",
+		sessionParam,
+		required("name", nameSchema(), "Character name"),
+		optional("class", enumSchema(characterClasses...), "Character class; required unless classes is given"),
+		optional("classes", arraySchema(enumSchema(characterClasses...), 3), "One to three classes of a multi-classed character"),
+		optional("background", enumSchema(characterBackgrounds...), "Character background"),
+		optional("deity", stringSchema(0, 64), "Deity the character worships"),
+		optional("seed", numberSchema(), "Seed of the character's generated attributes"))
+	v.register("changeClass", v.validateChangeClass, "Dual-classes the character into a new class",
+		sessionParam,
+		required("class", enumSchema(characterClasses...), "New class"))
+	v.register("levelUp", v.validateLevelUp, "Levels up the character, or previews the level up",
+		sessionParam,
+		optional("preview", booleanSchema(), "Report the level up without applying it"),
+		optional("hp_method", enumSchema("roll", "average"), "How hit points are gained"),
+		optional("spells", arraySchema(spellIDSchema(), 20), "Spells learned at the new level"))
+	v.register("getCharacter", v.validateGetCharacter, "Returns a character",
+		sessionParam,
+		optional("characterId", uuidSchema(), "Character to return; the session's character when omitted"))
+	v.register("updateCharacter", v.validateUpdateCharacter, "Updates a character",
+		sessionParam,
+		required("characterId", uuidSchema(), "Character to update"))
+	v.register("listCharacters", v.validateListCharacters, "Lists the session's characters", sessionParam)
 
 	// Movement and positioning methods
-	v.validators["move"] = v.validateMove
-	v.validators["getPosition"] = v.validateGetPosition
+	v.register("move", v.validateMove, "Moves the player to a position",
+		sessionParam,
+		required("x", numberSchema().between(-10000, 10000), ""),
+		required("y", numberSchema().between(-10000, 10000), ""))
+	v.register("travelTo", v.validateTravelTo, "Walks the player to a destination outside combat, passing game time",
+		sessionParam, positionParam)
+	v.register("getPosition", v.validateGetPosition, "Returns the player's position", sessionParam)
+	v.register("openDoor", v.validateOpenDoor, "Opens or closes the door next to the player",
+		sessionParam, doorDirectionParam,
+		optional("close", booleanSchema(), "Close the door instead"))
+	v.register("pickLock", v.validatePickLock, "Picks the lock of the door next to the player",
+		sessionParam, doorDirectionParam)
+	v.register("interactObject", v.validateInteractObject, "Uses a map feature such as a lever, chest or altar",
+		sessionParam,
+		required("object_id", stringSchema(1, 64), "Feature to use"),
+		required("verb", stringSchema(1, 64), "Interaction the feature offers"))
+	v.register("talkToNPC", v.validateTalkToNPC, "Talks to an NPC next to the player",
+		sessionParam,
+		required("npc_id", stringSchema(1, 64), "NPC to talk to"),
+		optional("dialog_id", stringSchema(0, 64), "Dialog node a response leads to; the NPC opens the conversation when omitted"))
 
 	// Combat methods
-	v.validators["attack"] = v.validateAttack
-	v.validators["castSpell"] = v.validateCastSpell
-	v.validators["getSpells"] = v.validateGetSpells
+	v.register("attack", v.validateAttack, "Attacks a target",
+		sessionParam,
+		required("targetId", uuidSchema(), "Target to attack"))
+	v.register("castSpell", v.validateCastSpell, "Casts a spell",
+		sessionParam,
+		required("spellId", spellIDSchema(), "Spell to cast"))
+	v.register("getSpells", v.validateGetSpells, "Lists the player's spells", sessionParam)
+	v.register("registerReaction", v.validateRegisterReaction, "Readies a reaction for the combat round",
+		sessionParam,
+		required("reaction_type", enumSchema(reactionTypes...), "Reaction to ready"))
+	v.register("cancelReaction", v.validateCancelReaction, "Cancels a readied reaction",
+		sessionParam,
+		required("reaction_id", uuidSchema(), "Reaction to cancel"))
+	v.register("respondReaction", v.validateRespondReaction, "Accepts or declines a reaction prompt",
+		sessionParam,
+		required("prompt_id", uuidSchema(), "Prompt to answer"),
+		required("accept", booleanSchema(), "Take the reaction"))
+	v.register("memorizeSpells", v.validateMemorizeSpells, "Prepares spells for casting",
+		sessionParam,
+		required("spell_ids", arraySchema(spellIDSchema(), 100), "Spells to memorize"))
+	v.register("previewSpellTargets", v.validatePreviewSpellTargets, "Resolves a spell's area and targets without casting it",
+		sessionParam,
+		required("spell_id", spellIDSchema(), "Spell to preview"),
+		optional("target_id", stringSchema(0, 0), "Target creature"),
+		optional("position", objectSchema(map[string]*Schema{
+			"x":     ptr(integerSchema()),
+			"y":     ptr(integerSchema()),
+			"level": ptr(integerSchema()),
+		}), "Target position"))
+	v.register("rest", v.validateRest, "Rests outside combat, healing the party and restoring spells",
+		sessionParam,
+		optional("hours", integerSchema().between(1, 24), "Hours to rest"))
+	v.register("passTime", v.validatePassTime, "Fast-forwards game time outside combat; days and hours together pass at least an hour",
+		sessionParam,
+		optional("days", integerSchema().between(0, 30), "Days to pass"),
+		optional("hours", integerSchema().between(0, 24), "Hours to pass"))
+	v.register("getCombatState", v.validateGetCombatState, "Returns the state of the current combat", sessionParam)
+	v.register("startCombat", v.validateStartCombat, "Starts combat and rolls initiative",
+		sessionParam,
+		optional("participant_ids", arraySchema(stringSchema(1, 100), 100), "Creatures joining the combat"),
+		optional("seed", integerSchema(), "Seed of the initiative rolls"),
+		optional("initiative_mode", enumSchema(initiativeModes...), "Whether initiative is rolled once or every round"))
+	v.register("endTurn", v.validateEndTurn, "Ends the player's combat turn", sessionParam)
 
 	// World interaction methods
-	v.validators["getWorld"] = v.validateGetWorld
-	v.validators["getWorldState"] = v.validateGetWorldState
+	v.register("getWorld", v.validateGetWorld, "Returns the world", sessionParam)
+	v.register("getWorldState", v.validateGetWorldState, "Returns the state of the world", sessionParam)
+	v.register("getVisibleEnemies", v.validateGetVisibleEnemies, "Lists the hostiles the player can see",
+		sessionParam,
+		optional("range", numberSchema().above(0).atMost(50), "Sight range in tiles"))
 
 	// Equipment methods
-	v.validators["equipItem"] = v.validateEquipItem
-	v.validators["unequipItem"] = v.validateUnequipItem
-	v.validators["getInventory"] = v.validateGetInventory
+	v.register("equipItem", v.validateEquipItem, "Equips an item from the inventory",
+		sessionParam,
+		required("item_id", uuidSchema(), "Item to equip"))
+	v.register("unequipItem", v.validateUnequipItem, "Unequips the item in an equipment slot",
+		sessionParam,
+		optional("slot", enumSchema(equipmentSlots...), "Slot to empty"))
+	v.register("getInventory", v.validateGetInventory, "Returns the player's inventory", sessionParam)
+	v.register("getEquipment", v.validateGetEquipment, "Returns the player's equipped items", sessionParam)
 
 	// Additional game methods
-	v.validators["useItem"] = v.validateUseItem
-	v.validators["leaveGame"] = v.validateLeaveGame
+	v.register("useItem", v.validateUseItem, "Uses an item from the inventory",
+		sessionParam,
+		required("item_id", stringSchema(1, 0), "Item to use"),
+		optional("target_id", stringSchema(1, 0), "Target of the item"))
+	v.register("leaveGame", v.validateLeaveGame, "Leaves the game and ends the session", sessionParam)
+	v.register("reconnectSession", v.validateReconnectSession, "Resumes a disconnected session and replays missed events",
+		sessionParam,
+		required("resume_token", patternSchema(resumeTokenPattern, exampleResumeToken), "Resume token issued by joinGame"),
+		optional("last_seq", integerSchema().atLeast(0), "Sequence number of the last event received"))
+
+	// Party methods
+	v.register("createParty", v.validateCreateParty, "Creates a party led by the player",
+		sessionParam,
+		optional("reward_policy", enumSchema("even", "full", "contribution"), "How quest rewards are shared"))
+	v.register("joinParty", v.validateJoinParty, "Joins a party",
+		sessionParam,
+		required("party_id", uuidSchema(), "Party to join"))
+	v.register("leaveParty", v.validateLeaveParty, "Leaves the player's party", sessionParam)
+	v.register("getParty", v.validateGetParty, "Returns the player's party", sessionParam)
+	v.register("shareQuest", v.validateShareQuest, "Shares a quest with the party",
+		sessionParam,
+		required("quest_id", stringSchema(1, 0), "Quest to share"))
+
+	// Companion methods
+	v.register("hireCompanion", v.validateHireCompanion, "Hires a generated companion",
+		sessionParam,
+		optional("seed", integerSchema(), "Seed of the generated companion"))
+	v.register("dismissCompanion", v.validateDismissCompanion, "Dismisses a companion",
+		sessionParam,
+		required("companion_id", stringSchema(1, 0), "Companion to dismiss"))
+	v.register("getCompanions", v.validateGetCompanions, "Lists the player's companions", sessionParam)
+
+	// Quest methods
+	v.register("startQuest", v.validateStartQuest, "Starts a quest, given in full or by the ID of a mounted bundle quest",
+		sessionParam,
+		optional("quest", objectSchema(nil), "Quest to start"),
+		optional("quest_id", stringSchema(1, 0), "Bundle quest to start when quest is omitted"))
+	v.register("completeQuest", v.validateQuestID, "Completes a quest and applies its rewards",
+		sessionParam,
+		required("quest_id", stringSchema(1, 0), "Quest to complete"))
+	v.register("failQuest", v.validateQuestID, "Fails a quest and applies its consequences",
+		sessionParam,
+		required("quest_id", stringSchema(1, 0), "Quest to fail"))
+	v.register("updateObjective", v.validateUpdateObjective, "Records progress on a quest objective",
+		sessionParam,
+		required("quest_id", stringSchema(1, 0), "Quest of the objective"),
+		optional("objective_index", integerSchema().atLeast(0), "Objective to update; the first when omitted"),
+		required("progress", integerSchema(), "Progress made"))
+	v.register("getQuest", v.validateQuestID, "Returns a quest of the player",
+		sessionParam,
+		required("quest_id", stringSchema(1, 0), "Quest to return"))
+	v.register("getActiveQuests", v.validateGetActiveQuests, "Lists the player's active quests", sessionParam)
+	v.register("getCompletedQuests", v.validateGetCompletedQuests, "Lists the player's completed quests", sessionParam)
+	v.register("getQuestLog", v.validateGetQuestLog, "Returns the player's quest log", sessionParam)
+
+	// Spell lookup methods
+	v.register("getSpell", v.validateGetSpell, "Returns a spell",
+		required("spell_id", spellIDSchema(), "Spell to return"))
+	v.register("getAllSpells", v.validateGetAllSpells, "Lists every spell")
+	v.register("getSpellsByLevel", v.validateGetSpellsByLevel, "Lists the spells of a level",
+		required("level", integerSchema().between(0, 9), "Spell level; 0 for cantrips"))
+	v.register("getSpellsBySchool", v.validateGetSpellsBySchool, "Lists the spells of a school of magic",
+		required("school", stringSchema(1, 50), "School of magic, such as evocation"))
+	v.register("searchSpells", v.validateSearchSpells, "Searches the spells by name and description",
+		required("query", stringSchema(1, 100), "Text to search for"))
+
+	// Spatial query methods
+	v.register("getObjectsInRange", v.validateGetObjectsInRange, "Lists the objects in a rectangle",
+		sessionParam,
+		optional("min_x", integerSchema(), ""),
+		optional("min_y", integerSchema(), ""),
+		optional("max_x", integerSchema(), ""),
+		optional("max_y", integerSchema(), ""))
+	v.register("getObjectsInRadius", v.validateGetObjectsInRadius, "Lists the objects within a distance of a point",
+		sessionParam,
+		optional("center_x", integerSchema(), ""),
+		optional("center_y", integerSchema(), ""),
+		optional("radius", numberSchema().atLeast(0), "Distance in tiles"))
+	v.register("getNearestObjects", v.validateGetNearestObjects, "Lists the objects nearest to a point",
+		sessionParam,
+		optional("center_x", integerSchema(), ""),
+		optional("center_y", integerSchema(), ""),
+		optional("k", integerSchema().between(1, 1000), "Number of objects to return"))
+
+	// Content generation methods
+	v.register("generateContent", v.validateGenerateContent, "Generates content for a location",
+		sessionParam,
+		required("content_type", stringSchema(1, 50), "Type of content to generate, such as terrain or items"),
+		required("location_id", objectIDSchema(), "Location to generate content for"),
+		optional("difficulty", integerSchema().between(1, 20), ""),
+		optional("constraints", objectSchema(nil), "Generator specific constraints"),
+		optional("generator", stringSchema(1, 100), "Named generator to use instead of the default of the content type"),
+		optional("preview", booleanSchema(), "Return the content without adding it to the world"),
+		optional("async", booleanSchema(), "Queue the generation as a job and return its ID"))
+	v.register("regenerateTerrain", v.validateRegenerateTerrain, "Regenerates the terrain of a location",
+		sessionParam,
+		required("location_id", objectIDSchema(), "Location to regenerate"),
+		optional("width", integerSchema().between(1, 1000), ""),
+		optional("height", integerSchema().between(1, 1000), ""),
+		optional("biome_type", stringSchema(1, 50), ""),
+		optional("density", numberSchema().between(0, 1), ""),
+		optional("water_level", numberSchema().between(0, 1), ""),
+		optional("connectivity", stringSchema(1, 50), ""))
+	v.register("generateItems", v.validateGenerateItems, "Generates items for a location",
+		sessionParam,
+		required("location_id", objectIDSchema(), "Location to generate items for"),
+		optional("count", integerSchema().between(1, 100), ""),
+		optional("min_rarity", stringSchema(1, 50), ""),
+		optional("max_rarity", stringSchema(1, 50), ""),
+		optional("player_level", integerSchema().between(1, 100), ""),
+		optional("item_types", arraySchema(stringSchema(1, 50), 20), "Item types to generate"))
+	v.register("generateLevel", v.validateGenerateLevel, "Generates a dungeon level",
+		sessionParam,
+		optional("width", integerSchema().between(1, 1000), ""),
+		optional("height", integerSchema().between(1, 1000), ""),
+		optional("room_count", integerSchema().between(1, 100), ""),
+		optional("theme", stringSchema(1, 64), ""),
+		optional("difficulty", integerSchema().between(1, 20), ""),
+		optional("corridor_style", stringSchema(1, 50), ""),
+		optional("constraints", arraySchema(objectSchema(nil), 50), "Layout constraints the level must meet"))
+	v.register("generateQuest", v.validateGenerateQuest, "Generates a quest",
+		sessionParam,
+		optional("quest_type", stringSchema(1, 50), ""),
+		optional("difficulty", integerSchema().between(1, 20), ""),
+		optional("min_objectives", integerSchema().between(1, 20), ""),
+		optional("max_objectives", integerSchema().between(1, 20), ""),
+		optional("reward_tier", stringSchema(1, 50), ""),
+		optional("narrative_type", stringSchema(1, 50), ""))
+	v.register("getPCGStats", v.validateGetPCGStats, "Returns content generation statistics", sessionParam)
+	v.register("validateContent", v.validateValidateContent, "Validates a piece of content",
+		sessionParam,
+		required("content_type", stringSchema(1, 50), "Type of the content"),
+		required("content", Schema{}, "Content to validate"),
+		optional("strict", booleanSchema(), ""))
+	v.register("commitGeneratedContent", v.validateCommitGeneratedContent, "Commits previewed generated content to the world",
+		sessionParam,
+		required("preview_id", uuidSchema(), "Preview to commit"))
+	v.register("getGenerationJobStatus", v.validateGetGenerationJobStatus, "Returns the status of a content generation job",
+		sessionParam,
+		required("job_id", objectIDSchema(), "Job to report"))
+	v.register("submitContentFeedback", v.validateSubmitContentFeedback, "Rates a piece of generated content",
+		sessionParam,
+		required("content_type", stringSchema(0, 0), "Type of the content"),
+		required("content_id", objectIDSchema(), "Content rated"),
+		required("rating", integerSchema().between(1, 5), ""),
+		required("difficulty", integerSchema().between(1, 5), ""),
+		required("enjoyment", integerSchema().between(1, 5), ""),
+		optional("comments", stringSchema(0, 0), ""))
+	v.register("queryGeneratedContent", v.validateQueryGeneratedContent, "Searches the generated content",
+		sessionParam,
+		optional("kind", stringSchema(0, 256), ""),
+		optional("content_type", stringSchema(0, 256), ""),
+		optional("location_id", stringSchema(0, 256), ""),
+		optional("biome", stringSchema(0, 256), ""),
+		optional("theme", stringSchema(0, 256), ""),
+		optional("faction", stringSchema(0, 256), ""),
+		optional("rarity", stringSchema(0, 256), ""),
+		optional("text", stringSchema(0, 256), "Text to search for"),
+		optional("tags", arraySchema(stringSchema(0, 0), 0), "Tags the content must carry"),
+		optional("min_difficulty", integerSchema().atLeast(0), ""),
+		optional("max_difficulty", integerSchema().atLeast(0), ""),
+		optional("limit", integerSchema().atLeast(0), "Most results to return"))
+
+	// Content administration methods
+	v.register("listBackups", v.validateListBackups, "Lists the backups of saved documents",
+		sessionParam,
+		optional("key", stringSchema(0, 0), "Document whose backups to list"))
+	v.register("listSnapshots", v.validateListSnapshots, "Lists the snapshots of the game state", sessionParam)
+
+	// Combat replay methods
+	v.register("replayCombat", v.validateReplayCombat, "Replays a recorded combat",
+		sessionParam,
+		required("replay_id", uuidSchema(), "Replay to play back"))
+
+	// Combat log methods
+	v.register("getCombatLog", v.validateGetCombatLog, "Returns the log of an encounter",
+		sessionParam,
+		optional("encounter_id", uuidSchema(), "Encounter whose log to return; the latest when omitted"),
+		optional("offset", integerSchema().atLeast(0), ""),
+		optional("limit", integerSchema().atLeast(0), ""))
+
+	// Session recap methods
+	v.register("getSessionRecap", v.validateGetSessionRecap, "Tells the story of the play session so far", sessionParam)
+
+	// Campaign statistics methods
+	v.register("getCampaignStats", v.validateGetCampaignStats, "Returns the campaign statistics", sessionParam)
+	v.register("getLeaderboard", v.validateGetLeaderboard, "Ranks the players by a statistic",
+		sessionParam,
+		required("category", stringSchema(1, 0), "Statistic to rank by"),
+		optional("limit", integerSchema().between(1, 100), ""))
+
+	// Map delta methods
+	v.register("getMapDelta", v.validateGetMapDelta, "Returns the map changes since a revision",
+		sessionParam,
+		optional("level", integerSchema().atLeast(0), ""),
+		optional("since_revision", integerSchema().atLeast(0), "Revision the client has"))
+	v.register("exportMap", v.validateExportMap, "Exports a level as a Tiled JSON map",
+		sessionParam,
+		optional("admin_token", stringSchema(1, 256), "Admin token, to include GM-only annotations"),
+		optional("level", integerSchema().atLeast(0), ""),
+		optional("tile_width", integerSchema().between(1, 512), "Tile width in pixels"),
+		optional("tile_height", integerSchema().between(1, 512), "Tile height in pixels"),
+		optional("tileset_image", stringSchema(0, 256), "Path of the tileset image"))
+
+	// Map annotation methods
+	v.register("addAnnotation", v.validateAddAnnotation, "Attaches a note, marker or region label to the map",
+		adminTokenParam,
+		required("kind", enumSchema("note", "marker", "region"), ""),
+		required("x", integerSchema().between(0, 10000), ""),
+		required("y", integerSchema().between(0, 10000), ""),
+		optional("level", integerSchema().between(0, 10000), ""),
+		optional("width", integerSchema().between(0, 10000), "Width of a region in tiles"),
+		optional("height", integerSchema().between(0, 10000), "Height of a region in tiles"),
+		optional("visibility", enumSchema("gm", "players"), ""),
+		optional("text", stringSchema(0, 1000), ""),
+		optional("symbol", stringSchema(0, 32), "Marker symbol"))
+	v.register("getAnnotations", v.validateGetAnnotations, "Lists the map annotations",
+		optional("session_id", uuidSchema(), "Session ID; required unless admin_token is given"),
+		optional("admin_token", stringSchema(1, 256), "Admin token, to list GM-only annotations"),
+		optional("level", integerSchema().atLeast(0), ""))
+
+	// Difficulty analysis methods
+	v.register("getDifficultyHeatmap", v.validateGetDifficultyHeatmap, "Maps the difficulty of generated content",
+		sessionParam,
+		optional("source", enumSchema("dungeon", "world"), ""),
+		optional("seed", integerSchema(), ""),
+		optional("difficulty", integerSchema().between(1, 20), ""),
+		optional("levels", integerSchema().between(1, 10), ""),
+		optional("rooms_per_level", integerSchema().between(1, 20), ""),
+		optional("regions", integerSchema().between(1, 20), ""),
+		optional("theme", stringSchema(0, 64), ""))
+
+	// Rate limit diagnostics methods
+	v.register("getRateLimitStats", v.validateGetRateLimitStats, "Reports the session's rate limit allowance", sessionParam)
+
+	// Merchant methods
+	v.register("getMerchant", v.validateGetMerchant, "Returns a merchant's stock and prices",
+		sessionParam, merchantIDParam)
+	v.register("buyItem", v.validateMerchantTrade, "Buys an item from a merchant",
+		sessionParam, merchantIDParam,
+		required("item_id", objectIDSchema(), "Item to buy"))
+	v.register("sellItem", v.validateMerchantTrade, "Sells an item to a merchant",
+		sessionParam, merchantIDParam,
+		required("item_id", objectIDSchema(), "Item to sell"))
+
+	// Game master action methods
+	v.register("applyEffect", v.validateApplyEffect, "Applies an effect to a target",
+		sessionParam,
+		required("effect_type", stringSchema(1, 0), "Effect to apply"),
+		required("target_id", stringSchema(1, 0), "Target of the effect"),
+		optional("magnitude", numberSchema(), ""),
+		optional("damage_type", stringSchema(0, 50), ""),
+		optional("stacking", enumSchema("refresh", "stack", "ignore"), "How the effect stacks with one already applied"),
+		optional("max_stacks", integerSchema().between(0, 100), ""))
+
+	// Appearance methods
+	v.register("getPortrait", v.validateGetPortrait, "Returns the portrait of an entity",
+		sessionParam,
+		optional("entity_id", objectIDSchema(), "Entity to portray; the player when omitted"))
+
+	// World event methods
+	v.register("getWorldEvents", v.validateGetWorldEvents, "Lists the world events under way", sessionParam)
+
+	// Admin console methods
+	v.register("admin.listSessions", v.validateAdminListSessions, "Lists the sessions", adminTokenParam)
+	v.register("admin.inspectSession", v.validateAdminInspectSession, "Returns the state of a session",
+		adminTokenParam, sessionParam)
+	v.register("admin.spawnEntity", v.validateAdminSpawnEntity, "Spawns generated monsters or NPCs",
+		adminTokenParam,
+		required("kind", stringSchema(1, 50), "Kind of entity to spawn"),
+		optional("count", integerSchema().between(1, 20), ""),
+		optional("level", integerSchema().between(1, 20), ""),
+		optional("seed", numberSchema(), ""),
+		positionParam)
+	v.register("admin.teleportPlayer", v.validateAdminTeleportPlayer, "Moves a session's player",
+		adminTokenParam, sessionParam, positionParam)
+	v.register("admin.grantXP", v.validateAdminGrantXP, "Grants experience to a session's player",
+		adminTokenParam, sessionParam,
+		required("amount", integerSchema().between(1, 1e9), "Experience to grant"))
+	v.register("admin.grantItem", v.validateAdminGrantItem, "Gives an item to a session's player",
+		adminTokenParam, sessionParam, itemParam)
+	v.register("admin.generateContent", v.validateAdminGenerateContent, "Generates content for a location",
+		adminTokenParam,
+		required("content_type", enumSchema("terrain", "levels", "items"), ""),
+		required("location_id", objectIDSchema(), "Location to generate content for"),
+		optional("difficulty", integerSchema().between(1, 20), ""))
+	v.register("admin.endCombat", v.validateAdminEndCombat, "Ends the current combat", adminTokenParam)
+	v.register("admin.giveItem", v.validateAdminGiveItem, "Gives an item to a player or NPC",
+		adminTokenParam, sessionParam, targetIDParam, itemParam)
+	v.register("admin.teleport", v.validateAdminTeleport, "Moves a player or NPC",
+		adminTokenParam, sessionParam, targetIDParam, positionParam)
+	v.register("admin.undoLastAction", v.validateAdminUndoLastAction, "Undoes the last game master action",
+		adminTokenParam, sessionParam)
+	v.register("admin.restoreBackup", v.validateAdminRestoreBackup, "Restores a backup over the document it was taken from",
+		adminTokenParam,
+		required("backup_id", patternSchema(backupIDPattern, exampleBackupID), "Backup ID returned by listBackups"))
+	v.register("admin.restoreSnapshot", v.validateAdminRestoreSnapshot, "Restores a snapshot of the game state",
+		adminTokenParam,
+		required("snapshot_id", patternSchema(snapshotIDPattern, exampleSnapshotID), "Snapshot ID returned by listSnapshots"))
+	v.register("admin.reloadLootTables", v.validateAdminReloadLootTables, "Reloads the loot tables", adminTokenParam)
+	v.register("admin.reloadPCGDefinitions", v.validateAdminReloadPCGDefinitions, "Reloads the biome and theme definitions", adminTokenParam)
+	v.register("admin.reloadConfig", v.validateAdminReloadConfig, "Reloads the server configuration", adminTokenParam)
+	v.register("admin.listRequests", v.validateAdminListRequests, "Lists recent requests from the audit log",
+		adminTokenParam,
+		optional("method", stringSchema(0, 0), "Method to filter by"),
+		optional("correlation_id", stringSchema(0, 0), "Correlation ID to filter by"),
+		optional("failed_only", booleanSchema(), "List failed requests only"),
+		optional("limit", integerSchema().between(1, 1000), ""))
 }
 
 // Validation functions for specific JSON-RPC methods
@@ -155,6 +631,11 @@ func (v *InputValidator) validatePing(params interface{}) error {
 	return nil
 }
 
+func (v *InputValidator) validateDiscover(params interface{}) error {
+	// Discovery takes no parameters and needs no session
+	return nil
+}
+
 func (v *InputValidator) validateCreatePlayer(params interface{}) error {
 	paramMap, ok := params.(map[string]interface{})
 	if !ok {
@@ -209,20 +690,139 @@ func (v *InputValidator) validateCreateCharacter(params interface{}) error {
 		return err
 	}
 
-	// Validate character class
+	// Validate character class, or the classes of a multi-classed character
+	if classes, exists := paramMap["classes"]; exists {
+		classList, ok := classes.([]interface{})
+		if !ok || len(classList) == 0 || len(classList) > 3 {
+			return fmt.Errorf("character classes must be a list of one to three classes")
+		}
+		for _, class := range classList {
+			classStr, ok := class.(string)
+			if !ok {
+				return fmt.Errorf("character class must be a string")
+			}
+			if err := validateCharacterClass(classStr); err != nil {
+				return err
+			}
+		}
+	} else {
+		class, exists := paramMap["class"]
+		if !exists {
+			return fmt.Errorf("createCharacter requires 'class' parameter")
+		}
+
+		classStr, ok := class.(string)
+		if !ok {
+			return fmt.Errorf("character class must be a string")
+		}
+
+		if err := validateCharacterClass(classStr); err != nil {
+			return err
+		}
+	}
+
+	// Validate background (optional)
+	if background, exists := paramMap["background"]; exists {
+		backgroundStr, ok := background.(string)
+		if !ok {
+			return fmt.Errorf("character background must be a string")
+		}
+		if err := validateCharacterBackground(backgroundStr); err != nil {
+			return err
+		}
+	}
+
+	// Validate deity (optional)
+	if deity, exists := paramMap["deity"]; exists {
+		if str, ok := deity.(string); !ok || len(str) > 64 {
+			return fmt.Errorf("character deity must be a string of at most 64 characters")
+		}
+	}
+
+	// Validate seed (optional)
+	if seed, exists := paramMap["seed"]; exists {
+		if _, ok := seed.(float64); !ok {
+			return fmt.Errorf("character seed must be a number")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateChangeClass(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("changeClass expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate the new class
 	class, exists := paramMap["class"]
 	if !exists {
-		return fmt.Errorf("createCharacter requires 'class' parameter")
+		return fmt.Errorf("changeClass requires 'class' parameter")
 	}
-
 	classStr, ok := class.(string)
 	if !ok {
 		return fmt.Errorf("character class must be a string")
 	}
-
 	return validateCharacterClass(classStr)
 }
 
+func (v *InputValidator) validateLevelUp(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("levelUp expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if preview, exists := paramMap["preview"]; exists {
+		if _, ok := preview.(bool); !ok {
+			return fmt.Errorf("preview must be a boolean")
+		}
+	}
+
+	if method, exists := paramMap["hp_method"]; exists {
+		methodStr, ok := method.(string)
+		if !ok {
+			return fmt.Errorf("hp_method must be a string")
+		}
+		if methodStr != "roll" && methodStr != "average" {
+			return fmt.Errorf("hp_method must be roll or average, got %q", methodStr)
+		}
+	}
+
+	// Validate the spells to learn
+	spells, exists := paramMap["spells"]
+	if !exists {
+		return nil
+	}
+	spellList, ok := spells.([]interface{})
+	if !ok {
+		return fmt.Errorf("spells must be an array")
+	}
+	if len(spellList) > 20 {
+		return fmt.Errorf("cannot learn more than 20 spells in one level")
+	}
+	for _, spellID := range spellList {
+		spellIDStr, ok := spellID.(string)
+		if !ok {
+			return fmt.Errorf("spell ID must be a string")
+		}
+		if err := validateSpellID(spellIDStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (v *InputValidator) validateGetCharacter(params interface{}) error {
 	paramMap, ok := params.(map[string]interface{})
 	if !ok {
@@ -312,6 +912,104 @@ func (v *InputValidator) validateMove(params interface{}) error {
 	return nil
 }
 
+func (v *InputValidator) validateOpenDoor(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("openDoor expects object parameters")
+	}
+
+	if err := validateDoorParams(paramMap); err != nil {
+		return err
+	}
+
+	if closeDoor, exists := paramMap["close"]; exists {
+		if _, ok := closeDoor.(bool); !ok {
+			return fmt.Errorf("close must be a boolean")
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validatePickLock(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("pickLock expects object parameters")
+	}
+
+	return validateDoorParams(paramMap)
+}
+
+func (v *InputValidator) validateInteractObject(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("interactObject expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	for _, field := range []string{"object_id", "verb"} {
+		value, exists := paramMap[field]
+		if !exists {
+			return fmt.Errorf("interactObject requires '%s' parameter", field)
+		}
+		str, ok := value.(string)
+		if !ok || str == "" || len(str) > 64 {
+			return fmt.Errorf("%s must be a non-empty string of at most 64 characters", field)
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateTalkToNPC(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("talkToNPC expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	npcID, exists := paramMap["npc_id"]
+	if !exists {
+		return fmt.Errorf("talkToNPC requires 'npc_id' parameter")
+	}
+	if str, ok := npcID.(string); !ok || str == "" || len(str) > 64 {
+		return fmt.Errorf("npc_id must be a non-empty string of at most 64 characters")
+	}
+
+	// Dialog ID is optional; without it the NPC opens the conversation
+	if dialogID, exists := paramMap["dialog_id"]; exists {
+		if str, ok := dialogID.(string); !ok || len(str) > 64 {
+			return fmt.Errorf("dialog_id must be a string of at most 64 characters")
+		}
+	}
+	return nil
+}
+
+// validateDoorParams checks the session and the direction of the door
+// from the player
+func validateDoorParams(paramMap map[string]interface{}) error {
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	direction, exists := paramMap["direction"]
+	if !exists {
+		return fmt.Errorf("direction is required")
+	}
+	dir, ok := direction.(float64)
+	if !ok || dir != float64(int(dir)) || dir < 0 || dir > 3 {
+		return fmt.Errorf("direction must be 0 (north), 1 (east), 2 (south) or 3 (west)")
+	}
+	return nil
+}
+
 func (v *InputValidator) validateGetPosition(params interface{}) error {
 	return validateSessionID(params)
 }
@@ -366,22 +1064,10 @@ func (v *InputValidator) validateCastSpell(params interface{}) error {
 	return validateSpellID(spellIDStr)
 }
 
-func (v *InputValidator) validateGetSpells(params interface{}) error {
-	return validateSessionID(params)
-}
-
-func (v *InputValidator) validateGetWorld(params interface{}) error {
-	return validateSessionID(params)
-}
-
-func (v *InputValidator) validateGetWorldState(params interface{}) error {
-	return validateSessionID(params)
-}
-
-func (v *InputValidator) validateEquipItem(params interface{}) error {
+func (v *InputValidator) validatePreviewSpellTargets(params interface{}) error {
 	paramMap, ok := params.(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("equipItem expects object parameters")
+		return fmt.Errorf("previewSpellTargets expects object parameters")
 	}
 
 	// Validate session ID
@@ -389,24 +1075,38 @@ func (v *InputValidator) validateEquipItem(params interface{}) error {
 		return err
 	}
 
-	// Validate item ID (using snake_case to match server handlers)
-	itemID, exists := paramMap["item_id"]
+	// Validate spell ID
+	spellID, exists := paramMap["spell_id"]
 	if !exists {
-		return fmt.Errorf("equipItem requires 'item_id' parameter")
+		return fmt.Errorf("previewSpellTargets requires 'spell_id' parameter")
 	}
-
-	itemIDStr, ok := itemID.(string)
+	spellIDStr, ok := spellID.(string)
 	if !ok {
-		return fmt.Errorf("item ID must be a string")
+		return fmt.Errorf("spell ID must be a string")
+	}
+	if err := validateSpellID(spellIDStr); err != nil {
+		return err
 	}
 
-	return validateUUID(itemIDStr)
+	// Validate optional target
+	if targetID, exists := paramMap["target_id"]; exists {
+		if _, ok := targetID.(string); !ok {
+			return fmt.Errorf("target_id must be a string")
+		}
+	}
+	if position, exists := paramMap["position"]; exists {
+		if _, ok := position.(map[string]interface{}); !ok {
+			return fmt.Errorf("position must be an object")
+		}
+	}
+
+	return nil
 }
 
-func (v *InputValidator) validateUnequipItem(params interface{}) error {
+func (v *InputValidator) validateMemorizeSpells(params interface{}) error {
 	paramMap, ok := params.(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("unequipItem expects object parameters")
+		return fmt.Errorf("memorizeSpells expects object parameters")
 	}
 
 	// Validate session ID
@@ -414,103 +1114,385 @@ func (v *InputValidator) validateUnequipItem(params interface{}) error {
 		return err
 	}
 
-	// Validate slot (optional parameter)
-	if slot, exists := paramMap["slot"]; exists {
-		slotStr, ok := slot.(string)
+	// Validate spell IDs
+	spellIDs, exists := paramMap["spell_ids"]
+	if !exists {
+		return fmt.Errorf("memorizeSpells requires 'spell_ids' parameter")
+	}
+
+	spellIDList, ok := spellIDs.([]interface{})
+	if !ok {
+		return fmt.Errorf("spell_ids must be an array")
+	}
+	if len(spellIDList) > 100 {
+		return fmt.Errorf("cannot memorize more than 100 spells")
+	}
+
+	for _, spellID := range spellIDList {
+		spellIDStr, ok := spellID.(string)
 		if !ok {
-			return fmt.Errorf("equipment slot must be a string")
+			return fmt.Errorf("spell ID must be a string")
+		}
+		if err := validateSpellID(spellIDStr); err != nil {
+			return err
 		}
-		return validateEquipmentSlot(slotStr)
 	}
 
 	return nil
 }
 
-func (v *InputValidator) validateGetInventory(params interface{}) error {
-	return validateSessionID(params)
-}
-
-// Helper validation functions
-
-func validateSessionID(params interface{}) error {
+func (v *InputValidator) validateRest(params interface{}) error {
 	paramMap, ok := params.(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("invalid parameters: expected object")
+		return fmt.Errorf("rest expects object parameters")
 	}
 
-	return validateSessionIDFromMap(paramMap)
-}
-
-func validateSessionIDFromMap(paramMap map[string]interface{}) error {
-	sessionID, exists := paramMap["session_id"]
-	if !exists {
-		return fmt.Errorf("missing required parameter: session_id")
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
 	}
 
-	sessionIDStr, ok := sessionID.(string)
-	if !ok {
-		return fmt.Errorf("session_id must be a string")
+	// Validate optional rest length
+	if hours, exists := paramMap["hours"]; exists {
+		hoursVal, ok := hours.(float64)
+		if !ok || hoursVal != float64(int(hoursVal)) || hoursVal < 1 || hoursVal > 24 {
+			return fmt.Errorf("hours must be a whole number between 1 and 24")
+		}
 	}
 
-	return validateUUID(sessionIDStr)
-}
-
-func validateUUID(id string) error {
-	// Basic UUID format validation (8-4-4-4-12 hex digits)
-	uuidRegex := regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
-	if !uuidRegex.MatchString(id) {
-		return fmt.Errorf("invalid UUID format: %s", id)
-	}
 	return nil
 }
 
-func validatePlayerName(name string) error {
-	name = strings.TrimSpace(name)
-
-	if len(name) == 0 {
-		return fmt.Errorf("player name cannot be empty")
+func (v *InputValidator) validatePassTime(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("passTime expects object parameters")
 	}
 
-	if len(name) > 50 {
-		return fmt.Errorf("player name cannot exceed 50 characters")
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
 	}
 
-	if !utf8.ValidString(name) {
-		return fmt.Errorf("player name contains invalid UTF-8 characters")
+	// Validate the days and hours to pass; together at least an hour
+	total := 0.0
+	for _, field := range []struct {
+		name  string
+		limit float64
+	}{{"days", 30}, {"hours", 24}} {
+		value, exists := paramMap[field.name]
+		if !exists {
+			continue
+		}
+		number, ok := value.(float64)
+		if !ok || number != float64(int(number)) || number < 0 || number > field.limit {
+			return fmt.Errorf("%s must be a whole number between 0 and %d", field.name, int(field.limit))
+		}
+		total += number
 	}
-
-	// Check for reasonable character set (letters, numbers, spaces, common punctuation)
-	nameRegex := regexp.MustCompile(`^[a-zA-Z0-9\s\-_'\.]+$`)
-	if !nameRegex.MatchString(name) {
-		return fmt.Errorf("player name contains invalid characters")
+	if total < 1 {
+		return fmt.Errorf("passTime requires 'days' or 'hours' to pass at least an hour")
 	}
 
 	return nil
 }
 
-func validateCharacterName(name string) error {
-	// Character names have similar rules to player names
-	return validatePlayerName(name)
-}
+func (v *InputValidator) validateTravelTo(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("travelTo expects object parameters")
+	}
 
-func validateCharacterClass(class string) error {
-	// Define valid character classes - must match game.CharacterClass constants
-	// See pkg/game/constants.go: ClassFighter, ClassMage, ClassCleric, ClassThief, ClassRanger, ClassPaladin
-	validClasses := []string{
-		"fighter", "mage", "cleric", "thief", "ranger", "paladin",
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
 	}
 
-	class = strings.ToLower(strings.TrimSpace(class))
+	return validatePositionParam("travelTo", paramMap)
+}
 
-	for _, validClass := range validClasses {
-		if class == validClass {
-			return nil
+func (v *InputValidator) validateGetCombatState(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateGetSpells(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateRegisterReaction(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("registerReaction expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate reaction type
+	reactionType, exists := paramMap["reaction_type"]
+	if !exists {
+		return fmt.Errorf("registerReaction requires 'reaction_type' parameter")
+	}
+
+	reactionTypeStr, ok := reactionType.(string)
+	if !ok {
+		return fmt.Errorf("reaction type must be a string")
+	}
+
+	return validateReactionType(reactionTypeStr)
+}
+
+func (v *InputValidator) validateCancelReaction(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("cancelReaction expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate reaction ID
+	reactionID, exists := paramMap["reaction_id"]
+	if !exists {
+		return fmt.Errorf("cancelReaction requires 'reaction_id' parameter")
+	}
+
+	reactionIDStr, ok := reactionID.(string)
+	if !ok {
+		return fmt.Errorf("reaction ID must be a string")
+	}
+
+	return validateUUID(reactionIDStr)
+}
+
+func (v *InputValidator) validateRespondReaction(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("respondReaction expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate prompt ID
+	promptID, exists := paramMap["prompt_id"]
+	if !exists {
+		return fmt.Errorf("respondReaction requires 'prompt_id' parameter")
+	}
+
+	promptIDStr, ok := promptID.(string)
+	if !ok {
+		return fmt.Errorf("prompt ID must be a string")
+	}
+
+	if err := validateUUID(promptIDStr); err != nil {
+		return err
+	}
+
+	// Validate accept flag
+	accept, exists := paramMap["accept"]
+	if !exists {
+		return fmt.Errorf("respondReaction requires 'accept' parameter")
+	}
+
+	if _, ok := accept.(bool); !ok {
+		return fmt.Errorf("accept must be a boolean")
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateGetWorld(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateGetWorldState(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateGetVisibleEnemies(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getVisibleEnemies expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate optional sight range
+	if sightRange, exists := paramMap["range"]; exists {
+		rangeVal, ok := sightRange.(float64)
+		if !ok || rangeVal <= 0 || rangeVal > 50 {
+			return fmt.Errorf("range must be a number between 0 and 50")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateEquipItem(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("equipItem expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate item ID (using snake_case to match server handlers)
+	itemID, exists := paramMap["item_id"]
+	if !exists {
+		return fmt.Errorf("equipItem requires 'item_id' parameter")
+	}
+
+	itemIDStr, ok := itemID.(string)
+	if !ok {
+		return fmt.Errorf("item ID must be a string")
+	}
+
+	return validateUUID(itemIDStr)
+}
+
+func (v *InputValidator) validateUnequipItem(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unequipItem expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate slot (optional parameter)
+	if slot, exists := paramMap["slot"]; exists {
+		slotStr, ok := slot.(string)
+		if !ok {
+			return fmt.Errorf("equipment slot must be a string")
+		}
+		return validateEquipmentSlot(slotStr)
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateGetInventory(params interface{}) error {
+	return validateSessionID(params)
+}
+
+// Helper validation functions
+
+func validateSessionID(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid parameters: expected object")
+	}
+
+	return validateSessionIDFromMap(paramMap)
+}
+
+func validateSessionIDFromMap(paramMap map[string]interface{}) error {
+	sessionID, exists := paramMap["session_id"]
+	if !exists {
+		return fmt.Errorf("missing required parameter: session_id")
+	}
+
+	sessionIDStr, ok := sessionID.(string)
+	if !ok {
+		return fmt.Errorf("session_id must be a string")
+	}
+
+	return validateUUID(sessionIDStr)
+}
+
+func validateUUID(id string) error {
+	// Basic UUID format validation (8-4-4-4-12 hex digits)
+	if !uuidPattern.MatchString(id) {
+		return fmt.Errorf("invalid UUID format: %s", id)
+	}
+	return nil
+}
+
+// validateObjectID checks a generated object identifier such as a merchant
+// or item ID
+func validateObjectID(field, id string) error {
+	if len(id) == 0 {
+		return fmt.Errorf("%s cannot be empty", field)
+	}
+
+	if len(id) > maxObjectIDLength {
+		return fmt.Errorf("%s cannot exceed %d characters", field, maxObjectIDLength)
+	}
+
+	if !objectIDPattern.MatchString(id) {
+		return fmt.Errorf("%s contains invalid characters", field)
+	}
+
+	return nil
+}
+
+func validatePlayerName(name string) error {
+	name = strings.TrimSpace(name)
+
+	if len(name) == 0 {
+		return fmt.Errorf("player name cannot be empty")
+	}
+
+	if len(name) > maxNameLength {
+		return fmt.Errorf("player name cannot exceed %d characters", maxNameLength)
+	}
+
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("player name contains invalid UTF-8 characters")
+	}
+
+	// Check for reasonable character set (letters, numbers, spaces, common punctuation)
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("player name contains invalid characters")
+	}
+
+	return nil
+}
+
+func validateCharacterName(name string) error {
+	// Character names have similar rules to player names
+	return validatePlayerName(name)
+}
+
+func validateCharacterClass(class string) error {
+	class = strings.ToLower(strings.TrimSpace(class))
+
+	for _, validClass := range characterClasses {
+		if class == validClass {
+			return nil
 		}
 	}
 
 	return fmt.Errorf("invalid character class: %s", class)
 }
 
+func validateCharacterBackground(background string) error {
+	background = strings.ToLower(strings.TrimSpace(background))
+
+	for _, validBackground := range characterBackgrounds {
+		if background == validBackground {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid character background: %s", background)
+}
+
 func validateSpellID(spellID string) error {
 	// Spell IDs should be valid identifiers (lowercase with dashes/underscores)
 	spellID = strings.TrimSpace(spellID)
@@ -519,29 +1501,31 @@ func validateSpellID(spellID string) error {
 		return fmt.Errorf("spell ID cannot be empty")
 	}
 
-	if len(spellID) > 100 {
-		return fmt.Errorf("spell ID cannot exceed 100 characters")
+	if len(spellID) > maxSpellIDLength {
+		return fmt.Errorf("spell ID cannot exceed %d characters", maxSpellIDLength)
 	}
 
-	spellRegex := regexp.MustCompile(`^[a-z0-9\-_]+$`)
-	if !spellRegex.MatchString(spellID) {
+	if !spellIDPattern.MatchString(spellID) {
 		return fmt.Errorf("spell ID contains invalid characters (use lowercase letters, numbers, hyphens, underscores)")
 	}
 
 	return nil
 }
 
-func validateEquipmentSlot(slot string) error {
-	// Define valid equipment slots
-	validSlots := []string{
-		"head", "neck", "shoulders", "chest", "waist", "legs", "feet",
-		"hands", "wrists", "ring1", "ring2", "main-hand", "off-hand",
-		"two-hand", "ranged", "ammo",
+func validateReactionType(reactionType string) error {
+	for _, valid := range reactionTypes {
+		if reactionType == valid {
+			return nil
+		}
 	}
 
+	return fmt.Errorf("invalid reaction type: %s", reactionType)
+}
+
+func validateEquipmentSlot(slot string) error {
 	slot = strings.ToLower(strings.TrimSpace(slot))
 
-	for _, validSlot := range validSlots {
+	for _, validSlot := range equipmentSlots {
 		if slot == validSlot {
 			return nil
 		}
@@ -593,3 +1577,1517 @@ func (v *InputValidator) validateUseItem(params interface{}) error {
 func (v *InputValidator) validateLeaveGame(params interface{}) error {
 	return validateSessionID(params)
 }
+
+func (v *InputValidator) validateReconnectSession(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("reconnectSession expects object parameters")
+	}
+
+	// Validate session ID of the calling connection
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate resume token (64 hex characters)
+	token, exists := paramMap["resume_token"]
+	if !exists {
+		return fmt.Errorf("reconnectSession requires 'resume_token' parameter")
+	}
+
+	tokenStr, ok := token.(string)
+	if !ok || !resumeTokenPattern.MatchString(tokenStr) {
+		return fmt.Errorf("resume token must be a 64-character hex string")
+	}
+
+	// Validate optional last sequence number
+	if lastSeq, exists := paramMap["last_seq"]; exists {
+		seq, ok := lastSeq.(float64)
+		if !ok || seq < 0 || seq != float64(int64(seq)) {
+			return fmt.Errorf("last_seq must be a non-negative integer")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateCreateParty(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("createParty expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate optional reward policy
+	policy, exists := paramMap["reward_policy"]
+	if !exists {
+		return nil
+	}
+
+	policyStr, ok := policy.(string)
+	if !ok {
+		return fmt.Errorf("reward policy must be a string")
+	}
+
+	switch policyStr {
+	case "", "even", "full", "contribution":
+		return nil
+	default:
+		return fmt.Errorf("invalid reward policy: %s", policyStr)
+	}
+}
+
+func (v *InputValidator) validateJoinParty(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("joinParty expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate party ID
+	partyID, exists := paramMap["party_id"]
+	if !exists {
+		return fmt.Errorf("joinParty requires 'party_id' parameter")
+	}
+
+	partyIDStr, ok := partyID.(string)
+	if !ok {
+		return fmt.Errorf("party ID must be a string")
+	}
+
+	return validateUUID(partyIDStr)
+}
+
+func (v *InputValidator) validateLeaveParty(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateGetParty(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateShareQuest(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("shareQuest expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate quest ID
+	questID, exists := paramMap["quest_id"]
+	if !exists {
+		return fmt.Errorf("shareQuest requires 'quest_id' parameter")
+	}
+
+	questIDStr, ok := questID.(string)
+	if !ok || questIDStr == "" {
+		return fmt.Errorf("quest ID must be a non-empty string")
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateHireCompanion(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("hireCompanion expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate optional seed
+	if value, exists := paramMap["seed"]; exists {
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			return fmt.Errorf("seed must be an integer")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateDismissCompanion(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("dismissCompanion expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate companion ID
+	companionID, exists := paramMap["companion_id"]
+	if !exists {
+		return fmt.Errorf("dismissCompanion requires 'companion_id' parameter")
+	}
+
+	companionIDStr, ok := companionID.(string)
+	if !ok || companionIDStr == "" {
+		return fmt.Errorf("companion ID must be a non-empty string")
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateGetCompanions(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateAdminReloadLootTables(params interface{}) error {
+	_, err := validateAdminParams("admin.reloadLootTables", params)
+	return err
+}
+
+func (v *InputValidator) validateAdminReloadPCGDefinitions(params interface{}) error {
+	_, err := validateAdminParams("admin.reloadPCGDefinitions", params)
+	return err
+}
+
+func (v *InputValidator) validateAdminReloadConfig(params interface{}) error {
+	_, err := validateAdminParams("admin.reloadConfig", params)
+	return err
+}
+
+func (v *InputValidator) validateAdminListRequests(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.listRequests", params)
+	if err != nil {
+		return err
+	}
+	for _, field := range []string{"method", "correlation_id"} {
+		if value, exists := paramMap[field]; exists {
+			if _, ok := value.(string); !ok {
+				return fmt.Errorf("admin.listRequests '%s' must be a string", field)
+			}
+		}
+	}
+	if value, exists := paramMap["failed_only"]; exists {
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("admin.listRequests 'failed_only' must be a boolean")
+		}
+	}
+	return validatePositiveInteger(paramMap, "limit", 1000)
+}
+
+func (v *InputValidator) validateCommitGeneratedContent(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("commitGeneratedContent expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate preview ID
+	previewID, exists := paramMap["preview_id"]
+	if !exists {
+		return fmt.Errorf("missing required parameter: preview_id")
+	}
+	previewIDStr, ok := previewID.(string)
+	if !ok {
+		return fmt.Errorf("preview_id must be a string")
+	}
+
+	return validateUUID(previewIDStr)
+}
+
+func (v *InputValidator) validateListBackups(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("listBackups expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate optional key filter
+	if key, exists := paramMap["key"]; exists {
+		if _, ok := key.(string); !ok {
+			return fmt.Errorf("key must be a string")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateAdminRestoreBackup(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.restoreBackup", params)
+	if err != nil {
+		return err
+	}
+
+	// Validate backup ID
+	backupID, exists := paramMap["backup_id"]
+	if !exists {
+		return fmt.Errorf("missing required parameter: backup_id")
+	}
+	backupIDStr, ok := backupID.(string)
+	if !ok || !backupIDPattern.MatchString(backupIDStr) || strings.Contains(backupIDStr, "..") {
+		return fmt.Errorf("backup_id must be a backup ID returned by listBackups")
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateListSnapshots(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("listSnapshots expects object parameters")
+	}
+
+	// Validate session ID
+	return validateSessionIDFromMap(paramMap)
+}
+
+func (v *InputValidator) validateAdminRestoreSnapshot(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.restoreSnapshot", params)
+	if err != nil {
+		return err
+	}
+
+	// Validate snapshot ID
+	snapshotID, exists := paramMap["snapshot_id"]
+	if !exists {
+		return fmt.Errorf("missing required parameter: snapshot_id")
+	}
+	snapshotIDStr, ok := snapshotID.(string)
+	if !ok || !snapshotIDPattern.MatchString(snapshotIDStr) || strings.Contains(snapshotIDStr, "..") {
+		return fmt.Errorf("snapshot_id must be a snapshot ID returned by listSnapshots")
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateReplayCombat(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("replayCombat expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate replay ID
+	replayID, exists := paramMap["replay_id"]
+	if !exists {
+		return fmt.Errorf("missing required parameter: replay_id")
+	}
+	replayIDStr, ok := replayID.(string)
+	if !ok {
+		return fmt.Errorf("replay_id must be a string")
+	}
+
+	return validateUUID(replayIDStr)
+}
+
+func (v *InputValidator) validateGetCombatLog(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getCombatLog expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate optional encounter ID
+	if encounterID, exists := paramMap["encounter_id"]; exists {
+		encounterIDStr, ok := encounterID.(string)
+		if !ok {
+			return fmt.Errorf("encounter_id must be a string")
+		}
+		if err := validateUUID(encounterIDStr); err != nil {
+			return err
+		}
+	}
+
+	// Validate optional pagination
+	for _, field := range []string{"offset", "limit"} {
+		if value, exists := paramMap[field]; exists {
+			number, ok := value.(float64)
+			if !ok || number < 0 || number != float64(int64(number)) {
+				return fmt.Errorf("%s must be a non-negative integer", field)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateGetMapDelta(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getMapDelta expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate optional level and revision
+	for _, field := range []string{"level", "since_revision"} {
+		if value, exists := paramMap[field]; exists {
+			number, ok := value.(float64)
+			if !ok || number < 0 || number != float64(int64(number)) {
+				return fmt.Errorf("%s must be a non-negative integer", field)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateExportMap(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("exportMap expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+	if _, exists := paramMap["admin_token"]; exists {
+		if _, err := validateAdminParams("exportMap", params); err != nil {
+			return err
+		}
+	}
+
+	if value, exists := paramMap["level"]; exists {
+		number, ok := value.(float64)
+		if !ok || number < 0 || number != float64(int64(number)) {
+			return fmt.Errorf("level must be a non-negative integer")
+		}
+	}
+
+	// Validate optional tile size
+	for _, field := range []string{"tile_width", "tile_height"} {
+		if value, exists := paramMap[field]; exists {
+			number, ok := value.(float64)
+			if !ok || number < 1 || number > 512 || number != float64(int64(number)) {
+				return fmt.Errorf("%s must be an integer between 1 and 512", field)
+			}
+		}
+	}
+
+	if value, exists := paramMap["tileset_image"]; exists {
+		image, ok := value.(string)
+		if !ok || len(image) > 256 {
+			return fmt.Errorf("tileset_image must be a string of at most 256 characters")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateAddAnnotation(params interface{}) error {
+	paramMap, err := validateAdminParams("addAnnotation", params)
+	if err != nil {
+		return err
+	}
+
+	kind, ok := paramMap["kind"].(string)
+	if !ok || (kind != "note" && kind != "marker" && kind != "region") {
+		return fmt.Errorf("kind must be one of note, marker, region")
+	}
+	if value, exists := paramMap["visibility"]; exists {
+		visibility, ok := value.(string)
+		if !ok || (visibility != "gm" && visibility != "players") {
+			return fmt.Errorf("visibility must be gm or players")
+		}
+	}
+
+	// Validate the annotated tile and the optional level and region size
+	for _, field := range []string{"x", "y", "level", "width", "height"} {
+		value, exists := paramMap[field]
+		if !exists && field != "x" && field != "y" {
+			continue
+		}
+		number, ok := value.(float64)
+		if !ok || number < 0 || number > 10000 || number != float64(int64(number)) {
+			return fmt.Errorf("%s must be an integer between 0 and 10000", field)
+		}
+	}
+
+	// Validate text
+	if value, exists := paramMap["text"]; exists {
+		text, ok := value.(string)
+		if !ok || len(text) > 1000 || !utf8.ValidString(text) {
+			return fmt.Errorf("text must be UTF-8 of at most 1000 bytes")
+		}
+	}
+	if value, exists := paramMap["symbol"]; exists {
+		symbol, ok := value.(string)
+		if !ok || len(symbol) > 32 {
+			return fmt.Errorf("symbol must be a string of at most 32 characters")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateGetAnnotations(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getAnnotations expects object parameters")
+	}
+
+	// The game master calls with the admin token instead of a session
+	if _, exists := paramMap["admin_token"]; exists {
+		if _, err := validateAdminParams("getAnnotations", params); err != nil {
+			return err
+		}
+	} else if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if value, exists := paramMap["level"]; exists {
+		number, ok := value.(float64)
+		if !ok || number < 0 || number != float64(int64(number)) {
+			return fmt.Errorf("level must be a non-negative integer")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateGetDifficultyHeatmap(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getDifficultyHeatmap expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if value, exists := paramMap["source"]; exists {
+		if source, ok := value.(string); !ok || (source != "dungeon" && source != "world") {
+			return fmt.Errorf("source must be \"dungeon\" or \"world\"")
+		}
+	}
+
+	if value, exists := paramMap["seed"]; exists {
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			return fmt.Errorf("seed must be an integer")
+		}
+	}
+
+	// Validate optional sizes
+	limits := map[string]int{"difficulty": 20, "levels": 10, "rooms_per_level": 20, "regions": 20}
+	for field, limit := range limits {
+		if value, exists := paramMap[field]; exists {
+			number, ok := value.(float64)
+			if !ok || number < 1 || number > float64(limit) || number != float64(int64(number)) {
+				return fmt.Errorf("%s must be an integer between 1 and %d", field, limit)
+			}
+		}
+	}
+
+	if value, exists := paramMap["theme"]; exists {
+		if theme, ok := value.(string); !ok || len(theme) > 64 {
+			return fmt.Errorf("theme must be a string of at most 64 characters")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateApplyEffect(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("applyEffect expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate effect type and target
+	for _, field := range []string{"effect_type", "target_id"} {
+		value, ok := paramMap[field].(string)
+		if !ok || value == "" {
+			return fmt.Errorf("applyEffect requires a non-empty '%s' parameter", field)
+		}
+	}
+	if magnitude, exists := paramMap["magnitude"]; exists {
+		if _, ok := magnitude.(float64); !ok {
+			return fmt.Errorf("magnitude must be a number")
+		}
+	}
+	if damageType, exists := paramMap["damage_type"]; exists {
+		if value, ok := damageType.(string); !ok || len(value) > 50 {
+			return fmt.Errorf("damage_type must be a string of at most 50 characters")
+		}
+	}
+	if stacking, exists := paramMap["stacking"]; exists {
+		if value, ok := stacking.(string); !ok || (value != "" && value != "refresh" && value != "stack" && value != "ignore") {
+			return fmt.Errorf("stacking must be one of 'refresh', 'stack' or 'ignore'")
+		}
+	}
+	if maxStacks, exists := paramMap["max_stacks"]; exists {
+		if value, ok := maxStacks.(float64); !ok || value < 0 || value > 100 || value != float64(int(value)) {
+			return fmt.Errorf("max_stacks must be an integer between 0 and 100")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateAdminGiveItem(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.giveItem", params)
+	if err != nil {
+		return err
+	}
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+	if err := validateOptionalTargetID(paramMap); err != nil {
+		return err
+	}
+
+	return validateItemSpec("admin.giveItem", paramMap)
+}
+
+// validateItemSpec checks the 'item' object of methods that create items
+func validateItemSpec(method string, paramMap map[string]interface{}) error {
+	item, ok := paramMap["item"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s requires an 'item' object", method)
+	}
+	for _, field := range []string{"name", "type"} {
+		value, ok := item[field].(string)
+		if !ok || value == "" || len(value) > 100 {
+			return fmt.Errorf("item %s must be a string of 1 to 100 characters", field)
+		}
+	}
+	for _, field := range []string{"weight", "value", "armor_class"} {
+		if value, exists := item[field]; exists {
+			number, ok := value.(float64)
+			if !ok || number < 0 || number != float64(int64(number)) {
+				return fmt.Errorf("item %s must be a non-negative integer", field)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateAdminTeleport(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.teleport", params)
+	if err != nil {
+		return err
+	}
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+	if err := validateOptionalTargetID(paramMap); err != nil {
+		return err
+	}
+
+	return validatePositionParam("admin.teleport", paramMap)
+}
+
+// validatePositionParam checks the 'position' object of methods that place
+// objects; its level may be omitted
+func validatePositionParam(method string, paramMap map[string]interface{}) error {
+	position, ok := paramMap["position"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s requires a 'position' object", method)
+	}
+	for _, field := range []string{"x", "y", "level"} {
+		value, exists := position[field]
+		if !exists && field == "level" {
+			continue
+		}
+		number, ok := value.(float64)
+		if !ok || number < 0 || number != float64(int64(number)) {
+			return fmt.Errorf("position %s must be a non-negative integer", field)
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateAdminUndoLastAction(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.undoLastAction", params)
+	if err != nil {
+		return err
+	}
+
+	return validateSessionIDFromMap(paramMap)
+}
+
+func (v *InputValidator) validateGetPortrait(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getPortrait expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	entityID, exists := paramMap["entity_id"]
+	if !exists {
+		return nil
+	}
+	id, ok := entityID.(string)
+	if !ok {
+		return fmt.Errorf("getPortrait 'entity_id' must be a string")
+	}
+	return validateObjectID("entity_id", id)
+}
+
+func (v *InputValidator) validateGetSessionRecap(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getSessionRecap expects object parameters")
+	}
+
+	return validateSessionIDFromMap(paramMap)
+}
+
+func (v *InputValidator) validateGetCampaignStats(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getCampaignStats expects object parameters")
+	}
+
+	return validateSessionIDFromMap(paramMap)
+}
+
+func (v *InputValidator) validateGetLeaderboard(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getLeaderboard expects object parameters")
+	}
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	category, ok := paramMap["category"].(string)
+	if !ok || category == "" {
+		return fmt.Errorf("getLeaderboard requires a 'category' string")
+	}
+	return validatePositiveInteger(paramMap, "limit", 100)
+}
+
+func (v *InputValidator) validateGetWorldEvents(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getWorldEvents expects object parameters")
+	}
+
+	return validateSessionIDFromMap(paramMap)
+}
+
+// validateAdminParams checks the parameters every admin method takes and
+// returns them as a map. The token itself is checked by the server.
+func validateAdminParams(method string, params interface{}) (map[string]interface{}, error) {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s expects object parameters", method)
+	}
+	token, ok := paramMap["admin_token"].(string)
+	if !ok || token == "" || len(token) > 256 {
+		return nil, fmt.Errorf("admin_token must be a string of 1 to 256 characters")
+	}
+	return paramMap, nil
+}
+
+// validatePositiveInteger checks an optional numeric parameter that must be
+// a whole number from 1 to limit
+func validatePositiveInteger(paramMap map[string]interface{}, field string, limit float64) error {
+	value, exists := paramMap[field]
+	if !exists {
+		return nil
+	}
+	number, ok := value.(float64)
+	if !ok || number < 1 || number > limit || number != float64(int64(number)) {
+		return fmt.Errorf("%s must be an integer between 1 and %v", field, limit)
+	}
+	return nil
+}
+
+func (v *InputValidator) validateAdminListSessions(params interface{}) error {
+	_, err := validateAdminParams("admin.listSessions", params)
+	return err
+}
+
+func (v *InputValidator) validateAdminInspectSession(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.inspectSession", params)
+	if err != nil {
+		return err
+	}
+	return validateSessionIDFromMap(paramMap)
+}
+
+func (v *InputValidator) validateAdminSpawnEntity(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.spawnEntity", params)
+	if err != nil {
+		return err
+	}
+
+	kind, ok := paramMap["kind"].(string)
+	if !ok || kind == "" || len(kind) > 50 {
+		return fmt.Errorf("kind must be a string of 1 to 50 characters")
+	}
+	if err := validatePositiveInteger(paramMap, "count", 20); err != nil {
+		return err
+	}
+	if err := validatePositiveInteger(paramMap, "level", 20); err != nil {
+		return err
+	}
+	if seed, exists := paramMap["seed"]; exists {
+		if _, ok := seed.(float64); !ok {
+			return fmt.Errorf("seed must be a number")
+		}
+	}
+	return validatePositionParam("admin.spawnEntity", paramMap)
+}
+
+func (v *InputValidator) validateAdminTeleportPlayer(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.teleportPlayer", params)
+	if err != nil {
+		return err
+	}
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+	return validatePositionParam("admin.teleportPlayer", paramMap)
+}
+
+func (v *InputValidator) validateAdminGrantXP(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.grantXP", params)
+	if err != nil {
+		return err
+	}
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+	if _, exists := paramMap["amount"]; !exists {
+		return fmt.Errorf("missing required parameter: amount")
+	}
+	return validatePositiveInteger(paramMap, "amount", 1e9)
+}
+
+func (v *InputValidator) validateAdminGrantItem(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.grantItem", params)
+	if err != nil {
+		return err
+	}
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+	return validateItemSpec("admin.grantItem", paramMap)
+}
+
+func (v *InputValidator) validateAdminGenerateContent(params interface{}) error {
+	paramMap, err := validateAdminParams("admin.generateContent", params)
+	if err != nil {
+		return err
+	}
+
+	switch paramMap["content_type"] {
+	case "terrain", "levels", "items":
+	default:
+		return fmt.Errorf("content_type must be one of terrain, levels, items")
+	}
+	locationID, ok := paramMap["location_id"].(string)
+	if !ok {
+		return fmt.Errorf("location_id must be a string")
+	}
+	if err := validateObjectID("location_id", locationID); err != nil {
+		return err
+	}
+	return validatePositiveInteger(paramMap, "difficulty", 20)
+}
+
+func (v *InputValidator) validateAdminEndCombat(params interface{}) error {
+	_, err := validateAdminParams("admin.endCombat", params)
+	return err
+}
+
+// validateOptionalTargetID checks the target_id of game master actions,
+// which default to the session's player when it is omitted.
+func validateOptionalTargetID(paramMap map[string]interface{}) error {
+	target, exists := paramMap["target_id"]
+	if !exists {
+		return nil
+	}
+	if id, ok := target.(string); !ok || len(id) > 100 {
+		return fmt.Errorf("target_id must be a string of at most 100 characters")
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGetRateLimitStats(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getRateLimitStats expects object parameters")
+	}
+
+	return validateSessionIDFromMap(paramMap)
+}
+
+func (v *InputValidator) validateGetMerchant(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getMerchant expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	merchantID, ok := paramMap["merchant_id"].(string)
+	if !ok {
+		return fmt.Errorf("getMerchant requires string 'merchant_id' parameter")
+	}
+	return validateObjectID("merchant_id", merchantID)
+}
+
+func (v *InputValidator) validateGetGenerationJobStatus(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getGenerationJobStatus expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	jobID, ok := paramMap["job_id"].(string)
+	if !ok {
+		return fmt.Errorf("getGenerationJobStatus requires string 'job_id' parameter")
+	}
+	return validateObjectID("job_id", jobID)
+}
+
+func (v *InputValidator) validateSubmitContentFeedback(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("submitContentFeedback expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if _, ok := paramMap["content_type"].(string); !ok {
+		return fmt.Errorf("submitContentFeedback requires string 'content_type' parameter")
+	}
+	contentID, ok := paramMap["content_id"].(string)
+	if !ok {
+		return fmt.Errorf("submitContentFeedback requires string 'content_id' parameter")
+	}
+	if err := validateObjectID("content_id", contentID); err != nil {
+		return err
+	}
+
+	// Validate 1-5 scores
+	for _, field := range []string{"rating", "difficulty", "enjoyment"} {
+		score, ok := paramMap[field].(float64)
+		if !ok || score < 1 || score > 5 || score != float64(int64(score)) {
+			return fmt.Errorf("%s must be an integer between 1 and 5", field)
+		}
+	}
+
+	// Validate optional comments
+	if comments, exists := paramMap["comments"]; exists {
+		if _, ok := comments.(string); !ok {
+			return fmt.Errorf("comments must be a string")
+		}
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateQueryGeneratedContent(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("queryGeneratedContent expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Validate optional string filters
+	for _, field := range []string{"kind", "content_type", "location_id", "biome", "theme", "faction", "rarity", "text"} {
+		if value, exists := paramMap[field]; exists {
+			str, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("%s must be a string", field)
+			}
+			if len(str) > 256 {
+				return fmt.Errorf("%s is too long", field)
+			}
+		}
+	}
+
+	// Validate optional tags
+	if tags, exists := paramMap["tags"]; exists {
+		list, ok := tags.([]interface{})
+		if !ok {
+			return fmt.Errorf("tags must be an array of strings")
+		}
+		for _, tag := range list {
+			if _, ok := tag.(string); !ok {
+				return fmt.Errorf("tags must be an array of strings")
+			}
+		}
+	}
+
+	// Validate optional non-negative integers
+	for _, field := range []string{"min_difficulty", "max_difficulty", "limit"} {
+		if value, exists := paramMap[field]; exists {
+			number, ok := value.(float64)
+			if !ok || number < 0 || number != float64(int64(number)) {
+				return fmt.Errorf("%s must be a non-negative integer", field)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateMerchantTrade validates buyItem and sellItem parameters
+func (v *InputValidator) validateMerchantTrade(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("trade expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	for _, field := range []string{"merchant_id", "item_id"} {
+		id, ok := paramMap[field].(string)
+		if !ok {
+			return fmt.Errorf("trade requires string '%s' parameter", field)
+		}
+		if err := validateObjectID(field, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateJoinGame(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("joinGame expects object parameters")
+	}
+
+	name, ok := paramMap["player_name"].(string)
+	if !ok {
+		return fmt.Errorf("joinGame requires string 'player_name' parameter")
+	}
+	return validatePlayerName(name)
+}
+
+func (v *InputValidator) validateGetGameState(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateStartCombat(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("startCombat expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if value, exists := paramMap["participant_ids"]; exists {
+		ids, ok := value.([]interface{})
+		if !ok || len(ids) > 100 {
+			return fmt.Errorf("participant_ids must be a list of at most 100 IDs")
+		}
+		for _, id := range ids {
+			if idStr, ok := id.(string); !ok || idStr == "" || len(idStr) > 100 {
+				return fmt.Errorf("participant IDs must be strings of 1 to 100 characters")
+			}
+		}
+	}
+
+	if err := validateOptionalInteger(paramMap, "seed"); err != nil {
+		return err
+	}
+
+	if value, exists := paramMap["initiative_mode"]; exists {
+		mode, _ := value.(string)
+		for _, valid := range initiativeModes {
+			if mode == valid {
+				return nil
+			}
+		}
+		return fmt.Errorf("initiative_mode must be one of %s", strings.Join(initiativeModes, ", "))
+	}
+
+	return nil
+}
+
+func (v *InputValidator) validateEndTurn(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateGetEquipment(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateStartQuest(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("startQuest expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	// Either a full quest or the ID of a bundle quest
+	if quest, exists := paramMap["quest"]; exists {
+		if _, ok := quest.(map[string]interface{}); !ok {
+			return fmt.Errorf("quest must be an object")
+		}
+		return nil
+	}
+	questID, ok := paramMap["quest_id"].(string)
+	if !ok || questID == "" {
+		return fmt.Errorf("startQuest requires 'quest' or a non-empty 'quest_id' parameter")
+	}
+	return nil
+}
+
+// validateQuestID validates the parameters of quest methods that take a
+// session and a quest ID
+func (v *InputValidator) validateQuestID(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("quest method expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	questID, ok := paramMap["quest_id"].(string)
+	if !ok || questID == "" {
+		return fmt.Errorf("quest ID must be a non-empty string")
+	}
+	return nil
+}
+
+func (v *InputValidator) validateUpdateObjective(params interface{}) error {
+	if err := v.validateQuestID(params); err != nil {
+		return err
+	}
+	paramMap := params.(map[string]interface{})
+
+	if _, exists := paramMap["progress"]; !exists {
+		return fmt.Errorf("updateObjective requires 'progress' parameter")
+	}
+	if err := validateOptionalInteger(paramMap, "progress"); err != nil {
+		return err
+	}
+	if err := validateOptionalInteger(paramMap, "objective_index"); err != nil {
+		return err
+	}
+	if index, _ := paramMap["objective_index"].(float64); index < 0 {
+		return fmt.Errorf("objective_index cannot be negative")
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGetActiveQuests(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateGetCompletedQuests(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateGetQuestLog(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateGetSpell(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getSpell expects object parameters")
+	}
+
+	spellID, ok := paramMap["spell_id"].(string)
+	if !ok {
+		return fmt.Errorf("getSpell requires string 'spell_id' parameter")
+	}
+	return validateSpellID(spellID)
+}
+
+func (v *InputValidator) validateGetAllSpells(params interface{}) error {
+	// Listing the spells takes no parameters and needs no session
+	return nil
+}
+
+func (v *InputValidator) validateGetSpellsByLevel(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getSpellsByLevel expects object parameters")
+	}
+
+	level, ok := paramMap["level"].(float64)
+	if !ok || level < 0 || level > 9 || level != float64(int(level)) {
+		return fmt.Errorf("level must be an integer between 0 and 9")
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGetSpellsBySchool(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("getSpellsBySchool expects object parameters")
+	}
+
+	if _, exists := paramMap["school"]; !exists {
+		return fmt.Errorf("getSpellsBySchool requires 'school' parameter")
+	}
+	return validateOptionalString(paramMap, "school", 50)
+}
+
+func (v *InputValidator) validateSearchSpells(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("searchSpells expects object parameters")
+	}
+
+	if _, exists := paramMap["query"]; !exists {
+		return fmt.Errorf("searchSpells requires 'query' parameter")
+	}
+	return validateOptionalString(paramMap, "query", 100)
+}
+
+func (v *InputValidator) validateGetObjectsInRange(params interface{}) error {
+	return validateSpatialQuery("getObjectsInRange", params, "min_x", "min_y", "max_x", "max_y")
+}
+
+func (v *InputValidator) validateGetObjectsInRadius(params interface{}) error {
+	if err := validateSpatialQuery("getObjectsInRadius", params, "center_x", "center_y"); err != nil {
+		return err
+	}
+	if radius, exists := params.(map[string]interface{})["radius"]; exists {
+		if value, ok := radius.(float64); !ok || value < 0 {
+			return fmt.Errorf("radius must be a number of at least 0")
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGetNearestObjects(params interface{}) error {
+	if err := validateSpatialQuery("getNearestObjects", params, "center_x", "center_y"); err != nil {
+		return err
+	}
+	return validatePositiveInteger(params.(map[string]interface{}), "k", 1000)
+}
+
+// validateSpatialQuery checks the session and the optional integer
+// coordinates of a spatial query
+func validateSpatialQuery(method string, params interface{}, coordinates ...string) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s expects object parameters", method)
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	for _, field := range coordinates {
+		if err := validateOptionalInteger(paramMap, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGenerateContent(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("generateContent expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if _, exists := paramMap["content_type"]; !exists {
+		return fmt.Errorf("generateContent requires 'content_type' parameter")
+	}
+	if err := validateOptionalString(paramMap, "content_type", 50); err != nil {
+		return err
+	}
+	if err := validateLocationID(paramMap); err != nil {
+		return err
+	}
+	if err := validatePositiveInteger(paramMap, "difficulty", 20); err != nil {
+		return err
+	}
+	if constraints, exists := paramMap["constraints"]; exists {
+		if _, ok := constraints.(map[string]interface{}); !ok {
+			return fmt.Errorf("constraints must be an object")
+		}
+	}
+	if err := validateOptionalString(paramMap, "generator", 100); err != nil {
+		return err
+	}
+	for _, field := range []string{"preview", "async"} {
+		if value, exists := paramMap[field]; exists {
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("%s must be a boolean", field)
+			}
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateRegenerateTerrain(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("regenerateTerrain expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if err := validateLocationID(paramMap); err != nil {
+		return err
+	}
+	for _, field := range []string{"width", "height"} {
+		if err := validatePositiveInteger(paramMap, field, 1000); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"density", "water_level"} {
+		if value, exists := paramMap[field]; exists {
+			if number, ok := value.(float64); !ok || number < 0 || number > 1 {
+				return fmt.Errorf("%s must be a number between 0 and 1", field)
+			}
+		}
+	}
+	for _, field := range []string{"biome_type", "connectivity"} {
+		if err := validateOptionalString(paramMap, field, 50); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGenerateItems(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("generateItems expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if err := validateLocationID(paramMap); err != nil {
+		return err
+	}
+	if err := validatePositiveInteger(paramMap, "count", 100); err != nil {
+		return err
+	}
+	if err := validatePositiveInteger(paramMap, "player_level", 100); err != nil {
+		return err
+	}
+	for _, field := range []string{"min_rarity", "max_rarity"} {
+		if err := validateOptionalString(paramMap, field, 50); err != nil {
+			return err
+		}
+	}
+	if value, exists := paramMap["item_types"]; exists {
+		types, ok := value.([]interface{})
+		if !ok || len(types) > 20 {
+			return fmt.Errorf("item_types must be a list of at most 20 types")
+		}
+		for _, itemType := range types {
+			if typeStr, ok := itemType.(string); !ok || typeStr == "" || len(typeStr) > 50 {
+				return fmt.Errorf("item types must be strings of 1 to 50 characters")
+			}
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGenerateLevel(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("generateLevel expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	limits := map[string]float64{"width": 1000, "height": 1000, "room_count": 100, "difficulty": 20}
+	for field, limit := range limits {
+		if err := validatePositiveInteger(paramMap, field, limit); err != nil {
+			return err
+		}
+	}
+	if err := validateOptionalString(paramMap, "theme", 64); err != nil {
+		return err
+	}
+	if err := validateOptionalString(paramMap, "corridor_style", 50); err != nil {
+		return err
+	}
+	if value, exists := paramMap["constraints"]; exists {
+		constraints, ok := value.([]interface{})
+		if !ok || len(constraints) > 50 {
+			return fmt.Errorf("constraints must be a list of at most 50 constraints")
+		}
+		for _, constraint := range constraints {
+			if _, ok := constraint.(map[string]interface{}); !ok {
+				return fmt.Errorf("constraints must be objects")
+			}
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGenerateQuest(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("generateQuest expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	for _, field := range []string{"difficulty", "min_objectives", "max_objectives"} {
+		if err := validatePositiveInteger(paramMap, field, 20); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"quest_type", "reward_tier", "narrative_type"} {
+		if err := validateOptionalString(paramMap, field, 50); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *InputValidator) validateGetPCGStats(params interface{}) error {
+	return validateSessionID(params)
+}
+
+func (v *InputValidator) validateValidateContent(params interface{}) error {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("validateContent expects object parameters")
+	}
+
+	// Validate session ID
+	if err := validateSessionIDFromMap(paramMap); err != nil {
+		return err
+	}
+
+	if _, exists := paramMap["content_type"]; !exists {
+		return fmt.Errorf("validateContent requires 'content_type' parameter")
+	}
+	if err := validateOptionalString(paramMap, "content_type", 50); err != nil {
+		return err
+	}
+	if content, exists := paramMap["content"]; !exists || content == nil {
+		return fmt.Errorf("validateContent requires 'content' parameter")
+	}
+	if strict, exists := paramMap["strict"]; exists {
+		if _, ok := strict.(bool); !ok {
+			return fmt.Errorf("strict must be a boolean")
+		}
+	}
+	return nil
+}
+
+// validateLocationID checks the required location_id of content generation
+// methods
+func validateLocationID(paramMap map[string]interface{}) error {
+	locationID, ok := paramMap["location_id"].(string)
+	if !ok {
+		return fmt.Errorf("location_id must be a string")
+	}
+	return validateObjectID("location_id", locationID)
+}
+
+// validateOptionalInteger checks an optional numeric parameter that must be
+// a whole number
+func validateOptionalInteger(paramMap map[string]interface{}, field string) error {
+	value, exists := paramMap[field]
+	if !exists {
+		return nil
+	}
+	if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+		return fmt.Errorf("%s must be an integer", field)
+	}
+	return nil
+}
+
+// validateOptionalString checks an optional parameter that must be a string
+// of 1 to maxLength characters
+func validateOptionalString(paramMap map[string]interface{}, field string, maxLength int) error {
+	value, exists := paramMap[field]
+	if !exists {
+		return nil
+	}
+	if str, ok := value.(string); !ok || str == "" || len(str) > maxLength {
+		return fmt.Errorf("%s must be a string of 1 to %d characters", field, maxLength)
+	}
+	return nil
+}
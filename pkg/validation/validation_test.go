@@ -16,10 +16,24 @@ func TestNewInputValidator(t *testing.T) {
 
 	// Check that all expected methods are registered
 	expectedMethods := []string{
-		"ping", "createPlayer", "getPlayer", "listPlayers",
+		"ping", "rpc.discover", "createPlayer", "getPlayer", "listPlayers",
 		"createCharacter", "getCharacter", "updateCharacter", "listCharacters",
-		"move", "getPosition", "attack", "castSpell", "getSpells",
+		"move", "travelTo", "getPosition", "attack", "castSpell", "getSpells", "memorizeSpells", "previewSpellTargets", "rest", "passTime", "getCombatState",
 		"getWorld", "getWorldState", "equipItem", "unequipItem", "getInventory",
+		"registerReaction", "cancelReaction", "respondReaction",
+		"createParty", "joinParty", "leaveParty", "getParty", "shareQuest",
+		"hireCompanion", "dismissCompanion", "getCompanions",
+		"reconnectSession", "getVisibleEnemies", "listBackups",
+		"commitGeneratedContent", "replayCombat",
+		"getRateLimitStats", "getMerchant", "buyItem", "sellItem",
+		"getGenerationJobStatus", "submitContentFeedback", "listSnapshots",
+		"queryGeneratedContent", "getCombatLog", "getSessionRecap", "getCampaignStats", "getLeaderboard", "getMapDelta", "exportMap", "addAnnotation", "getAnnotations", "getDifficultyHeatmap", "changeClass",
+		"levelUp", "openDoor", "pickLock", "interactObject", "talkToNPC",
+		"applyEffect", "getPortrait",
+		"getWorldEvents", "admin.listSessions", "admin.inspectSession", "admin.spawnEntity",
+		"admin.teleportPlayer", "admin.grantXP", "admin.grantItem", "admin.generateContent",
+		"admin.endCombat", "admin.giveItem", "admin.teleport", "admin.undoLastAction",
+		"admin.restoreBackup", "admin.restoreSnapshot", "admin.reloadLootTables", "admin.reloadPCGDefinitions", "admin.reloadConfig", "admin.listRequests",
 	}
 
 	for _, method := range expectedMethods {
@@ -204,6 +218,39 @@ func TestValidateCreateCharacter(t *testing.T) {
 			expectError:   true,
 			errorContains: "invalid character class",
 		},
+		{
+			name: "valid background and seed",
+			params: map[string]interface{}{
+				"session_id": validSessionID,
+				"name":       "TestCharacter",
+				"class":      "fighter",
+				"background": "random",
+				"seed":       float64(42),
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid background",
+			params: map[string]interface{}{
+				"session_id": validSessionID,
+				"name":       "TestCharacter",
+				"class":      "fighter",
+				"background": "pirate",
+			},
+			expectError:   true,
+			errorContains: "invalid character background",
+		},
+		{
+			name: "non-numeric seed",
+			params: map[string]interface{}{
+				"session_id": validSessionID,
+				"name":       "TestCharacter",
+				"class":      "fighter",
+				"seed":       "42",
+			},
+			expectError:   true,
+			errorContains: "seed must be a number",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1065,6 +1112,233 @@ func TestValidateCastSpell(t *testing.T) {
 	}
 }
 
+func TestValidateMemorizeSpellsAndRest(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+
+	tests := []struct {
+		name          string
+		validate      func(interface{}) error
+		params        map[string]interface{}
+		errorContains string
+	}{
+		{"valid memorizeSpells", validator.validateMemorizeSpells,
+			map[string]interface{}{"session_id": validSessionID, "spell_ids": []interface{}{"magic_missile", "magic_missile"}}, ""},
+		{"missing spell_ids", validator.validateMemorizeSpells,
+			map[string]interface{}{"session_id": validSessionID}, "'spell_ids' parameter"},
+		{"spell_ids not an array", validator.validateMemorizeSpells,
+			map[string]interface{}{"session_id": validSessionID, "spell_ids": "magic_missile"}, "must be an array"},
+		{"invalid spell ID", validator.validateMemorizeSpells,
+			map[string]interface{}{"session_id": validSessionID, "spell_ids": []interface{}{"Magic Missile"}}, "invalid characters"},
+		{"valid rest", validator.validateRest,
+			map[string]interface{}{"session_id": validSessionID, "hours": float64(8)}, ""},
+		{"rest with default hours", validator.validateRest,
+			map[string]interface{}{"session_id": validSessionID}, ""},
+		{"rest too long", validator.validateRest,
+			map[string]interface{}{"session_id": validSessionID, "hours": float64(25)}, "between 1 and 24"},
+		{"fractional hours", validator.validateRest,
+			map[string]interface{}{"session_id": validSessionID, "hours": 1.5}, "whole number"},
+		{"valid passTime", validator.validatePassTime,
+			map[string]interface{}{"session_id": validSessionID, "days": float64(3), "hours": float64(6)}, ""},
+		{"passTime hours only", validator.validatePassTime,
+			map[string]interface{}{"session_id": validSessionID, "hours": float64(24)}, ""},
+		{"passTime nothing to pass", validator.validatePassTime,
+			map[string]interface{}{"session_id": validSessionID, "days": float64(0)}, "at least an hour"},
+		{"passTime too many days", validator.validatePassTime,
+			map[string]interface{}{"session_id": validSessionID, "days": float64(31)}, "between 0 and 30"},
+		{"passTime fractional hours", validator.validatePassTime,
+			map[string]interface{}{"session_id": validSessionID, "hours": 0.5}, "whole number"},
+		{"valid travelTo", validator.validateTravelTo,
+			map[string]interface{}{"session_id": validSessionID, "position": map[string]interface{}{"x": float64(4), "y": float64(2), "level": float64(1)}}, ""},
+		{"travelTo without position", validator.validateTravelTo,
+			map[string]interface{}{"session_id": validSessionID}, "requires a 'position' object"},
+		{"travelTo negative position", validator.validateTravelTo,
+			map[string]interface{}{"session_id": validSessionID, "position": map[string]interface{}{"x": float64(-1), "y": float64(2)}}, "non-negative integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(tt.params)
+			if tt.errorContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errorContains)
+			}
+		})
+	}
+}
+
+func TestValidateApplyEffect(t *testing.T) {
+	validator := NewInputValidator(1024)
+	params := func(extra map[string]interface{}) map[string]interface{} {
+		paramMap := map[string]interface{}{
+			"session_id":  "12345678-1234-1234-1234-123456789abc",
+			"effect_type": "burning",
+			"target_id":   "npc_1",
+		}
+		for key, value := range extra {
+			paramMap[key] = value
+		}
+		return paramMap
+	}
+
+	assert.NoError(t, validator.validateApplyEffect(params(nil)))
+	assert.NoError(t, validator.validateApplyEffect(params(map[string]interface{}{
+		"magnitude": 3.0, "damage_type": "fire", "stacking": "stack", "max_stacks": 5.0,
+	})))
+	assert.ErrorContains(t, validator.validateApplyEffect(params(map[string]interface{}{"stacking": "merge"})), "stacking")
+	assert.ErrorContains(t, validator.validateApplyEffect(params(map[string]interface{}{"max_stacks": 1.5})), "max_stacks")
+	assert.ErrorContains(t, validator.validateApplyEffect(params(map[string]interface{}{"damage_type": 4.0})), "damage_type")
+	assert.ErrorContains(t, validator.validateApplyEffect(params(map[string]interface{}{"target_id": ""})), "target_id")
+}
+
+func TestValidateExportMap(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+
+	assert.NoError(t, validator.validateExportMap(map[string]interface{}{"session_id": validSessionID}))
+	assert.NoError(t, validator.validateExportMap(map[string]interface{}{
+		"session_id": validSessionID, "level": float64(2), "tile_width": float64(16), "tileset_image": "tiles.png",
+	}))
+	assert.ErrorContains(t, validator.validateExportMap(map[string]interface{}{"session_id": validSessionID, "level": -1.0}),
+		"non-negative integer")
+	assert.ErrorContains(t, validator.validateExportMap(map[string]interface{}{"session_id": validSessionID, "tile_height": 1000.0}),
+		"between 1 and 512")
+	assert.ErrorContains(t, validator.validateExportMap(map[string]interface{}{"session_id": validSessionID, "tileset_image": 3.0}),
+		"tileset_image")
+}
+
+func TestValidateRPCRequest_IdempotencyKey(t *testing.T) {
+	validator := NewInputValidator(1024)
+	params := func(key interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"session_id": "12345678-1234-1234-1234-123456789abc", "x": 1.0, "y": 2.0, IdempotencyKeyParam: key,
+		}
+	}
+
+	assert.NoError(t, validator.ValidateRPCRequest("move", params("client-7:move:42"), 100))
+	assert.NoError(t, validator.ValidateRPCRequest("move", params("01J9Z3Q7X8N4M2K5V6W7Y8Z9AB"), 100))
+	assert.ErrorContains(t, validator.ValidateRPCRequest("move", params(""), 100), IdempotencyKeyParam)
+	assert.ErrorContains(t, validator.ValidateRPCRequest("move", params("retry me"), 100), IdempotencyKeyParam)
+	assert.ErrorContains(t, validator.ValidateRPCRequest("move", params(42.0), 100), IdempotencyKeyParam)
+	assert.ErrorContains(t, validator.ValidateRPCRequest("move", params(strings.Repeat("k", MaxIdempotencyKeyLength+1)), 1024),
+		IdempotencyKeyParam)
+}
+
+func TestValidateGetDifficultyHeatmap(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+
+	assert.NoError(t, validator.validateGetDifficultyHeatmap(map[string]interface{}{"session_id": validSessionID}))
+	assert.NoError(t, validator.validateGetDifficultyHeatmap(map[string]interface{}{
+		"session_id": validSessionID, "source": "world", "seed": float64(42), "regions": float64(8),
+	}))
+	assert.ErrorContains(t, validator.validateGetDifficultyHeatmap(map[string]interface{}{"session_id": validSessionID, "source": "cave"}),
+		"source")
+	assert.ErrorContains(t, validator.validateGetDifficultyHeatmap(map[string]interface{}{"session_id": validSessionID, "levels": 50.0}),
+		"between 1 and 10")
+	assert.ErrorContains(t, validator.validateGetDifficultyHeatmap(map[string]interface{}{"session_id": validSessionID, "seed": 1.5}),
+		"seed")
+}
+
+func TestValidateReactions(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+	validID := "87654321-4321-4321-4321-cba987654321"
+
+	tests := []struct {
+		name          string
+		validate      func(interface{}) error
+		params        interface{}
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:     "valid register",
+			validate: validator.validateRegisterReaction,
+			params: map[string]interface{}{
+				"session_id":    validSessionID,
+				"reaction_type": "shield_block",
+			},
+		},
+		{
+			name:          "register unknown type",
+			validate:      validator.validateRegisterReaction,
+			params:        map[string]interface{}{"session_id": validSessionID, "reaction_type": "dodge"},
+			expectError:   true,
+			errorContains: "invalid reaction type",
+		},
+		{
+			name:          "register missing type",
+			validate:      validator.validateRegisterReaction,
+			params:        map[string]interface{}{"session_id": validSessionID},
+			expectError:   true,
+			errorContains: "'reaction_type' parameter",
+		},
+		{
+			name:     "valid cancel",
+			validate: validator.validateCancelReaction,
+			params:   map[string]interface{}{"session_id": validSessionID, "reaction_id": validID},
+		},
+		{
+			name:          "cancel invalid reaction ID",
+			validate:      validator.validateCancelReaction,
+			params:        map[string]interface{}{"session_id": validSessionID, "reaction_id": "abc"},
+			expectError:   true,
+			errorContains: "invalid UUID",
+		},
+		{
+			name:     "valid respond",
+			validate: validator.validateRespondReaction,
+			params: map[string]interface{}{
+				"session_id": validSessionID,
+				"prompt_id":  validID,
+				"accept":     true,
+			},
+		},
+		{
+			name:          "respond missing accept",
+			validate:      validator.validateRespondReaction,
+			params:        map[string]interface{}{"session_id": validSessionID, "prompt_id": validID},
+			expectError:   true,
+			errorContains: "'accept' parameter",
+		},
+		{
+			name:     "respond accept not boolean",
+			validate: validator.validateRespondReaction,
+			params: map[string]interface{}{
+				"session_id": validSessionID,
+				"prompt_id":  validID,
+				"accept":     "yes",
+			},
+			expectError:   true,
+			errorContains: "must be a boolean",
+		},
+		{
+			name:          "respond missing session",
+			validate:      validator.validateRespondReaction,
+			params:        map[string]interface{}{"prompt_id": validID, "accept": false},
+			expectError:   true,
+			errorContains: "session_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(tt.params)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidateGetSpells(t *testing.T) {
 	validator := NewInputValidator(1024)
 	validSessionID := "12345678-1234-1234-1234-123456789abc"
@@ -1583,3 +1857,304 @@ func TestValidateRPCRequest_MethodValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateParties(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+	validID := "87654321-4321-4321-4321-cba987654321"
+
+	tests := []struct {
+		name          string
+		validate      func(interface{}) error
+		params        interface{}
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:     "create with default policy",
+			validate: validator.validateCreateParty,
+			params:   map[string]interface{}{"session_id": validSessionID},
+		},
+		{
+			name:     "create with contribution policy",
+			validate: validator.validateCreateParty,
+			params:   map[string]interface{}{"session_id": validSessionID, "reward_policy": "contribution"},
+		},
+		{
+			name:          "create with unknown policy",
+			validate:      validator.validateCreateParty,
+			params:        map[string]interface{}{"session_id": validSessionID, "reward_policy": "loot_council"},
+			expectError:   true,
+			errorContains: "invalid reward policy",
+		},
+		{
+			name:     "valid join",
+			validate: validator.validateJoinParty,
+			params:   map[string]interface{}{"session_id": validSessionID, "party_id": validID},
+		},
+		{
+			name:          "join invalid party ID",
+			validate:      validator.validateJoinParty,
+			params:        map[string]interface{}{"session_id": validSessionID, "party_id": "party"},
+			expectError:   true,
+			errorContains: "invalid UUID",
+		},
+		{
+			name:     "valid share",
+			validate: validator.validateShareQuest,
+			params:   map[string]interface{}{"session_id": validSessionID, "quest_id": "rats"},
+		},
+		{
+			name:          "share missing quest",
+			validate:      validator.validateShareQuest,
+			params:        map[string]interface{}{"session_id": validSessionID},
+			expectError:   true,
+			errorContains: "'quest_id' parameter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(tt.params)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateCompanions(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+
+	tests := []struct {
+		name          string
+		validate      func(interface{}) error
+		params        interface{}
+		errorContains string
+	}{
+		{
+			name:     "hire without seed",
+			validate: validator.validateHireCompanion,
+			params:   map[string]interface{}{"session_id": validSessionID},
+		},
+		{
+			name:     "hire with seed",
+			validate: validator.validateHireCompanion,
+			params:   map[string]interface{}{"session_id": validSessionID, "seed": float64(42)},
+		},
+		{
+			name:          "hire with fractional seed",
+			validate:      validator.validateHireCompanion,
+			params:        map[string]interface{}{"session_id": validSessionID, "seed": 4.2},
+			errorContains: "seed must be an integer",
+		},
+		{
+			name:     "valid dismiss",
+			validate: validator.validateDismissCompanion,
+			params:   map[string]interface{}{"session_id": validSessionID, "companion_id": "npc_bran"},
+		},
+		{
+			name:          "dismiss missing companion",
+			validate:      validator.validateDismissCompanion,
+			params:        map[string]interface{}{"session_id": validSessionID},
+			errorContains: "'companion_id' parameter",
+		},
+		{
+			name:          "list without session",
+			validate:      validator.validateGetCompanions,
+			params:        map[string]interface{}{},
+			errorContains: "session_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(tt.params)
+			if tt.errorContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.errorContains)
+			}
+		})
+	}
+}
+
+func TestValidateReconnectSession(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+	validToken := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name          string
+		params        interface{}
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:   "valid reconnect",
+			params: map[string]interface{}{"session_id": validSessionID, "resume_token": validToken},
+		},
+		{
+			name:   "valid reconnect with last sequence",
+			params: map[string]interface{}{"session_id": validSessionID, "resume_token": validToken, "last_seq": float64(42)},
+		},
+		{
+			name:          "missing token",
+			params:        map[string]interface{}{"session_id": validSessionID},
+			expectError:   true,
+			errorContains: "'resume_token' parameter",
+		},
+		{
+			name:          "malformed token",
+			params:        map[string]interface{}{"session_id": validSessionID, "resume_token": "not-a-token"},
+			expectError:   true,
+			errorContains: "64-character hex",
+		},
+		{
+			name:          "negative last sequence",
+			params:        map[string]interface{}{"session_id": validSessionID, "resume_token": validToken, "last_seq": float64(-1)},
+			expectError:   true,
+			errorContains: "last_seq",
+		},
+		{
+			name:          "non-object params",
+			params:        "token",
+			expectError:   true,
+			errorContains: "expects object parameters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateReconnectSession(tt.params)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateGetVisibleEnemies(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+
+	assert.NoError(t, validator.validateGetVisibleEnemies(map[string]interface{}{"session_id": validSessionID}))
+	assert.NoError(t, validator.validateGetVisibleEnemies(map[string]interface{}{"session_id": validSessionID, "range": float64(12)}))
+	assert.Error(t, validator.validateGetVisibleEnemies(map[string]interface{}{"session_id": validSessionID, "range": float64(0)}))
+	assert.Error(t, validator.validateGetVisibleEnemies(map[string]interface{}{"session_id": validSessionID, "range": "far"}))
+	assert.Error(t, validator.validateGetVisibleEnemies(map[string]interface{}{}))
+}
+
+func TestValidateBackupMethods(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+	validBackupID := "backups/gamestate.yaml/20240101T000100.000000000Z.yaml"
+
+	assert.NoError(t, validator.validateListBackups(map[string]interface{}{"session_id": validSessionID}))
+	assert.NoError(t, validator.validateListBackups(map[string]interface{}{"session_id": validSessionID, "key": "gamestate.yaml"}))
+	assert.Error(t, validator.validateListBackups(map[string]interface{}{"session_id": validSessionID, "key": 7.0}))
+
+	token := "0123456789abcdef0123456789abcdef"
+	assert.NoError(t, validator.validateAdminRestoreBackup(map[string]interface{}{"admin_token": token, "backup_id": validBackupID}))
+	assert.Error(t, validator.validateAdminRestoreBackup(map[string]interface{}{"session_id": validSessionID, "backup_id": validBackupID}), "the admin token is required")
+	assert.Error(t, validator.validateAdminRestoreBackup(map[string]interface{}{"admin_token": token}))
+	assert.Error(t, validator.validateAdminRestoreBackup(map[string]interface{}{"admin_token": token, "backup_id": "gamestate.yaml"}))
+	assert.Error(t, validator.validateAdminRestoreBackup(map[string]interface{}{"admin_token": token, "backup_id": "backups/../config.yaml"}))
+}
+
+func TestValidateCommitGeneratedContent(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+	validPreviewID := "87654321-4321-4321-4321-cba987654321"
+
+	assert.NoError(t, validator.validateCommitGeneratedContent(map[string]interface{}{"session_id": validSessionID, "preview_id": validPreviewID}))
+	assert.Error(t, validator.validateCommitGeneratedContent(map[string]interface{}{"session_id": validSessionID}))
+	assert.Error(t, validator.validateCommitGeneratedContent(map[string]interface{}{"session_id": validSessionID, "preview_id": "level-1"}))
+	assert.Error(t, validator.validateCommitGeneratedContent("preview"))
+}
+
+func TestValidateReplayCombat(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+	validReplayID := "87654321-4321-4321-4321-cba987654321"
+
+	assert.NoError(t, validator.validateReplayCombat(map[string]interface{}{"session_id": validSessionID, "replay_id": validReplayID}))
+	assert.Error(t, validator.validateReplayCombat(map[string]interface{}{"session_id": validSessionID}))
+	assert.Error(t, validator.validateReplayCombat(map[string]interface{}{"session_id": validSessionID, "replay_id": 42}))
+	assert.Error(t, validator.validateReplayCombat(map[string]interface{}{"replay_id": validReplayID}))
+}
+
+func TestValidateGetRateLimitStats(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+
+	assert.NoError(t, validator.validateGetRateLimitStats(map[string]interface{}{"session_id": validSessionID}))
+	assert.Error(t, validator.validateGetRateLimitStats(map[string]interface{}{}))
+	assert.Error(t, validator.validateGetRateLimitStats("stats"))
+}
+
+func TestValidateMerchantMethods(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+	merchantID := "generated_level_42_room_3_merchant"
+
+	assert.NoError(t, validator.validateGetMerchant(map[string]interface{}{"session_id": validSessionID, "merchant_id": merchantID}))
+	assert.Error(t, validator.validateGetMerchant(map[string]interface{}{"session_id": validSessionID}))
+	assert.Error(t, validator.validateGetMerchant(map[string]interface{}{"session_id": validSessionID, "merchant_id": "../shop"}))
+
+	trade := map[string]interface{}{"session_id": validSessionID, "merchant_id": merchantID, "item_id": merchantID + "_stock_0"}
+	assert.NoError(t, validator.validateMerchantTrade(trade))
+	assert.Error(t, validator.validateMerchantTrade(map[string]interface{}{"session_id": validSessionID, "merchant_id": merchantID}))
+	assert.Error(t, validator.validateMerchantTrade(map[string]interface{}{"session_id": validSessionID, "merchant_id": merchantID, "item_id": ""}))
+	assert.Error(t, validator.validateMerchantTrade(map[string]interface{}{"merchant_id": merchantID, "item_id": "item_1"}))
+}
+
+func TestValidateAdminMethods(t *testing.T) {
+	validator := NewInputValidator(1024)
+	validSessionID := "12345678-1234-1234-1234-123456789abc"
+	token := "0123456789abcdef0123456789abcdef"
+	position := map[string]interface{}{"x": 3.0, "y": 4.0}
+
+	assert.NoError(t, validator.validateAdminListSessions(map[string]interface{}{"admin_token": token}))
+	assert.Error(t, validator.validateAdminListSessions(map[string]interface{}{}))
+	assert.Error(t, validator.validateAdminEndCombat(map[string]interface{}{"admin_token": 42.0}))
+
+	assert.NoError(t, validator.validateAdminInspectSession(map[string]interface{}{"admin_token": token, "session_id": validSessionID}))
+	assert.Error(t, validator.validateAdminInspectSession(map[string]interface{}{"admin_token": token}))
+
+	spawn := map[string]interface{}{"admin_token": token, "kind": "orc", "count": 3.0, "position": position}
+	assert.NoError(t, validator.validateAdminSpawnEntity(spawn))
+	assert.Error(t, validator.validateAdminSpawnEntity(map[string]interface{}{"admin_token": token, "kind": "orc"}))
+	assert.Error(t, validator.validateAdminSpawnEntity(map[string]interface{}{"admin_token": token, "kind": "orc", "count": 0.0, "position": position}))
+
+	assert.NoError(t, validator.validateAdminTeleportPlayer(map[string]interface{}{"admin_token": token, "session_id": validSessionID, "position": position}))
+	assert.Error(t, validator.validateAdminTeleportPlayer(map[string]interface{}{"admin_token": token, "session_id": validSessionID}))
+
+	assert.NoError(t, validator.validateAdminGrantXP(map[string]interface{}{"admin_token": token, "session_id": validSessionID, "amount": 500.0}))
+	assert.Error(t, validator.validateAdminGrantXP(map[string]interface{}{"admin_token": token, "session_id": validSessionID}))
+	assert.Error(t, validator.validateAdminGrantXP(map[string]interface{}{"admin_token": token, "session_id": validSessionID, "amount": -5.0}))
+
+	item := map[string]interface{}{"name": "Sword", "type": "weapon"}
+	assert.NoError(t, validator.validateAdminGrantItem(map[string]interface{}{"admin_token": token, "session_id": validSessionID, "item": item}))
+	assert.Error(t, validator.validateAdminGrantItem(map[string]interface{}{"admin_token": token, "session_id": validSessionID}))
+
+	generate := map[string]interface{}{"admin_token": token, "content_type": "levels", "location_id": "crypt_1"}
+	assert.NoError(t, validator.validateAdminGenerateContent(generate))
+	assert.Error(t, validator.validateAdminGenerateContent(map[string]interface{}{"admin_token": token, "content_type": "quests", "location_id": "crypt_1"}))
+	assert.Error(t, validator.validateAdminGenerateContent(map[string]interface{}{"admin_token": token, "content_type": "levels"}))
+}